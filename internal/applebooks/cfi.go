@@ -0,0 +1,136 @@
+package applebooks
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCFI is returned for locations that are not EPUB CFIs.
+var ErrInvalidCFI = errors.New("invalid EPUB CFI")
+
+// CFI is the start of an EPUB canonical fragment identifier, as stored in
+// ZANNOTATIONLOCATION, e.g. "epubcfi(/6/24[chap05]!/4/2/14,/1:0,/1:120)".
+// Comparing two CFIs of the same book gives their order in the book.
+type CFI struct {
+	Steps  []int // Path steps, the spine item first
+	Offset int   // Character offset within the last step
+}
+
+// ParseCFI parses the start of an EPUB CFI. Ranges are read as the position
+// they start at; ID assertions, side biases and spatial offsets are ignored.
+func ParseCFI(location string) (CFI, error) {
+	location = strings.TrimSpace(location)
+	if !strings.HasPrefix(location, "epubcfi(") || !strings.HasSuffix(location, ")") {
+		return CFI{}, fmt.Errorf("%w: %q", ErrInvalidCFI, location)
+	}
+	body := location[len("epubcfi(") : len(location)-1]
+
+	// A range is "parent,start,end"; its start is the parent path followed by start
+	parts := splitCFIRange(body)
+	path := parts[0]
+	if len(parts) == 3 {
+		path += parts[1]
+	}
+
+	var cfi CFI
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '/':
+			n, next := readCFIInt(path, i+1)
+			if next == i+1 {
+				return CFI{}, fmt.Errorf("%w: %q", ErrInvalidCFI, location)
+			}
+			cfi.Steps = append(cfi.Steps, n)
+			i = next
+		case ':':
+			n, next := readCFIInt(path, i+1)
+			if next == i+1 {
+				return CFI{}, fmt.Errorf("%w: %q", ErrInvalidCFI, location)
+			}
+			cfi.Offset = n
+			i = next
+		case '[':
+			i = skipCFIAssertion(path, i)
+		case '!':
+			// Indirection into the content document of the spine item
+			i++
+		default:
+			// Temporal and spatial offsets ("~", "@") come last and do not order text
+			if len(cfi.Steps) == 0 {
+				return CFI{}, fmt.Errorf("%w: %q", ErrInvalidCFI, location)
+			}
+			return cfi, nil
+		}
+	}
+
+	if len(cfi.Steps) == 0 {
+		return CFI{}, fmt.Errorf("%w: %q", ErrInvalidCFI, location)
+	}
+	return cfi, nil
+}
+
+// Compare returns -1 if c comes before other in the book, 1 if after and 0
+// for the same position.
+func (c CFI) Compare(other CFI) int {
+	for i := 0; i < len(c.Steps) && i < len(other.Steps); i++ {
+		if c.Steps[i] != other.Steps[i] {
+			if c.Steps[i] < other.Steps[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(c.Steps) < len(other.Steps):
+		return -1
+	case len(c.Steps) > len(other.Steps):
+		return 1
+	case c.Offset < other.Offset:
+		return -1
+	case c.Offset > other.Offset:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// splitCFIRange splits a CFI on the commas outside ID assertions.
+func splitCFIRange(body string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '[':
+			i = skipCFIAssertion(body, i) - 1
+		case ',':
+			parts = append(parts, body[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, body[start:])
+}
+
+// skipCFIAssertion returns the index after the assertion opened at i,
+// honouring "^" escapes.
+func skipCFIAssertion(s string, i int) int {
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '^':
+			i++
+		case ']':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func readCFIInt(s string, i int) (int, int) {
+	end := i
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[i:end])
+	return n, end
+}
@@ -0,0 +1,108 @@
+package applebooks
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseCFI(t *testing.T) {
+	tests := []struct {
+		location string
+		expected CFI
+	}{
+		{"epubcfi(/6/24[chap05]!/4/2/14,/1:0,/1:120)", CFI{Steps: []int{6, 24, 4, 2, 14, 1}, Offset: 0}},
+		{"epubcfi(/6/4[id^]x]!/4/10/3:57)", CFI{Steps: []int{6, 4, 4, 10, 3}, Offset: 57}},
+		{"epubcfi(/6/8!/4/2[p,1]/1:5~12.5)", CFI{Steps: []int{6, 8, 4, 2, 1}, Offset: 5}},
+		{"epubcfi(/6/2)", CFI{Steps: []int{6, 2}}},
+	}
+	for _, tt := range tests {
+		cfi, err := ParseCFI(tt.location)
+		if err != nil {
+			t.Errorf("ParseCFI(%q) failed: %v", tt.location, err)
+			continue
+		}
+		if !reflect.DeepEqual(cfi, tt.expected) {
+			t.Errorf("ParseCFI(%q) = %+v, expected %+v", tt.location, cfi, tt.expected)
+		}
+	}
+
+	for _, invalid := range []string{"", "1234", "epubcfi()", "epubcfi(/x)"} {
+		if _, err := ParseCFI(invalid); !errors.Is(err, ErrInvalidCFI) {
+			t.Errorf("ParseCFI(%q) = %v, expected ErrInvalidCFI", invalid, err)
+		}
+	}
+}
+
+func TestCFI_Compare(t *testing.T) {
+	ordered := []string{
+		"epubcfi(/6/4!/4/2/1:10)",
+		"epubcfi(/6/4!/4/2/1:200)",
+		"epubcfi(/6/4!/4/10/1:0)",
+		"epubcfi(/6/12!/4/2,/1:0,/1:40)",
+		"epubcfi(/6/100!/4/2/1:0)",
+	}
+	for i := 0; i+1 < len(ordered); i++ {
+		a, _ := ParseCFI(ordered[i])
+		b, _ := ParseCFI(ordered[i+1])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("Expected %s before %s", ordered[i], ordered[i+1])
+		}
+	}
+
+	a, _ := ParseCFI(ordered[0])
+	if a.Compare(a) != 0 {
+		t.Errorf("Expected a CFI to equal itself")
+	}
+}
+
+func TestGetBooks_ReadingOrder(t *testing.T) {
+	annotationDBPath, bookDBPath, cleanup := createTestDatabases(t)
+	defer cleanup()
+	insertTestBook(t, bookDBPath, "book-1", "Test Book", "Test Author")
+
+	db, err := sql.Open("sqlite3", annotationDBPath)
+	if err != nil {
+		t.Fatalf("Failed to open annotation database: %v", err)
+	}
+	// Range starts restart in every chapter, so they do not give the reading order
+	_, err = db.Exec(`
+		INSERT INTO ZAEANNOTATION (ZANNOTATIONASSETID, ZANNOTATIONSELECTEDTEXT, ZANNOTATIONLOCATION, ZPLLOCATIONRANGESTART, ZANNOTATIONDELETED)
+		VALUES ('book-1', 'Chapter 3 start', 'epubcfi(/6/12[c3]!/4/2,/1:0,/1:15)', 0, 0),
+			('book-1', 'Chapter 1 end', 'epubcfi(/6/4[c1]!/4/40/1:300)', 300, 0),
+			('book-1', 'No location', NULL, 5, 0),
+			('book-1', 'Chapter 1 start', 'epubcfi(/6/4[c1]!/4/2/1:10)', 10, 0)
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to insert annotations: %v", err)
+	}
+
+	reader, err := NewAppleBooksReader(annotationDBPath, bookDBPath)
+	if err != nil {
+		t.Fatalf("Failed to create reader: %v", err)
+	}
+	books, err := reader.GetBooks()
+	if err != nil {
+		t.Fatalf("Failed to get books: %v", err)
+	}
+	if len(books) != 1 {
+		t.Fatalf("Expected 1 book, got %d", len(books))
+	}
+
+	expected := []string{"Chapter 1 start", "Chapter 1 end", "Chapter 3 start", "No location"}
+	for i, h := range books[0].Highlights {
+		if h.Text != expected[i] {
+			t.Errorf("Highlight %d: expected %q, got %q", i, expected[i], h.Text)
+		}
+		if h.LocationValue != i+1 {
+			t.Errorf("Highlight %d: expected position %d, got %d", i, i+1, h.LocationValue)
+		}
+	}
+
+	// The CFI is kept for deep links into Apple Books
+	if ref := books[0].Highlights[0].LocationRef; ref != "epubcfi(/6/4[c1]!/4/2/1:10)" {
+		t.Errorf("Expected the CFI as location reference, got %q", ref)
+	}
+}
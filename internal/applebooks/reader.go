@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -27,6 +29,19 @@ const (
 	AnnotationStylePurple    AnnotationStyle = 6
 )
 
+// Annotation columns missing from some Apple Books versions
+const (
+	columnRepresentativeText = "ZANNOTATIONREPRESENTATIVETEXT"
+	columnChapter            = "ZFUTUREPROOFING5"
+	columnStyle              = "ZANNOTATIONSTYLE"
+	columnIsUnderline        = "ZANNOTATIONISUNDERLINE"
+)
+
+var optionalAnnotationColumns = []string{columnRepresentativeText, columnChapter, columnStyle, columnIsUnderline}
+
+// maxContextLength matches the size of the highlight context columns
+const maxContextLength = 500
+
 type AppleBooksReader struct {
 	annotationDBPath string
 	bookDBPath       string
@@ -40,8 +55,9 @@ type AppleBooksHighlight struct {
 	SelectedText  string
 	Note          string
 	RepresentText string
-	Chapter       string
-	Style         int
+	Chapter       string // Chapter title, from ZFUTUREPROOFING5
+	Style         int    // ZANNOTATIONSTYLE, the color of the highlight
+	IsUnderline   bool   // Underlines keep the color of their style
 	ModifiedDate  float64
 	LocationStart int
 }
@@ -154,8 +170,17 @@ func (r *AppleBooksReader) GetHighlights() ([]AppleBooksHighlight, error) {
 		return nil, fmt.Errorf("failed to attach book database: %w", err)
 	}
 
+	// Older Apple Books versions lack some columns; those are read as NULL
+	optional := make(map[string]string)
+	for _, column := range optionalAnnotationColumns {
+		optional[column] = "NULL"
+		if annotationColumnExists(annotationDB, column) {
+			optional[column] = column
+		}
+	}
+
 	// Query for highlights joined with book metadata
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
 			ZANNOTATIONASSETID as asset_id,
 			books.ZBKLIBRARYASSET.ZTITLE as title,
@@ -163,9 +188,10 @@ func (r *AppleBooksReader) GetHighlights() ([]AppleBooksHighlight, error) {
 			ZANNOTATIONLOCATION as location,
 			ZANNOTATIONSELECTEDTEXT as selected_text,
 			ZANNOTATIONNOTE as note,
-			ZANNOTATIONREPRESENTATIVETEXT as represent_text,
-			ZFUTUREPROOFING5 as chapter,
-			ZANNOTATIONSTYLE as style,
+			%s as represent_text,
+			%s as chapter,
+			%s as style,
+			%s as is_underline,
 			ZANNOTATIONMODIFICATIONDATE as modified_date,
 			ZPLLOCATIONRANGESTART as location_start
 		FROM ZAEANNOTATION
@@ -175,7 +201,7 @@ func (r *AppleBooksReader) GetHighlights() ([]AppleBooksHighlight, error) {
 			AND (title NOT NULL AND author NOT NULL)
 			AND ((selected_text != '' AND selected_text NOT NULL) OR note NOT NULL)
 		ORDER BY ZANNOTATIONASSETID, ZPLLOCATIONRANGESTART
-	`
+	`, optional[columnRepresentativeText], optional[columnChapter], optional[columnStyle], optional[columnIsUnderline])
 
 	rows, err := annotationDB.Query(query)
 	if err != nil {
@@ -189,7 +215,7 @@ func (r *AppleBooksReader) GetHighlights() ([]AppleBooksHighlight, error) {
 		var h AppleBooksHighlight
 		var location, selectedText, note, representText, chapter sql.NullString
 		var modifiedDate sql.NullFloat64
-		var locationStart sql.NullInt64
+		var style, isUnderline, locationStart sql.NullInt64
 
 		err := rows.Scan(
 			&h.AssetID,
@@ -200,7 +226,8 @@ func (r *AppleBooksReader) GetHighlights() ([]AppleBooksHighlight, error) {
 			&note,
 			&representText,
 			&chapter,
-			&h.Style,
+			&style,
+			&isUnderline,
 			&modifiedDate,
 			&locationStart,
 		)
@@ -212,7 +239,9 @@ func (r *AppleBooksReader) GetHighlights() ([]AppleBooksHighlight, error) {
 		h.SelectedText = selectedText.String
 		h.Note = note.String
 		h.RepresentText = representText.String
-		h.Chapter = chapter.String
+		h.Chapter = strings.TrimSpace(chapter.String)
+		h.Style = int(style.Int64)
+		h.IsUnderline = isUnderline.Int64 == 1
 		if modifiedDate.Valid {
 			h.ModifiedDate = modifiedDate.Float64
 		}
@@ -238,7 +267,8 @@ func (r *AppleBooksReader) GetBooks() ([]entities.Book, error) {
 
 	// Group highlights by book (using AssetID as the grouping key)
 	bookMap := make(map[string]*entities.Book)
-	bookOrder := []string{} // Preserve order
+	bookOrder := []string{}                // Preserve order
+	locations := make(map[string][]string) // ZANNOTATIONLOCATION of each highlight, by book
 
 	for _, h := range highlights {
 		key := h.AssetID
@@ -276,15 +306,24 @@ func (r *AppleBooksReader) GetBooks() ([]entities.Book, error) {
 			continue
 		}
 
+		// The representative text is the passage around the selection
+		var contextPrefix, contextSuffix string
+		if h.SelectedText != "" {
+			contextPrefix, contextSuffix = splitContext(h.RepresentText, h.SelectedText)
+		}
+
 		highlight := entities.Highlight{
 			Text:          text,
 			Note:          h.Note,
 			Chapter:       h.Chapter,
 			LocationType:  entities.LocationTypePosition,
 			LocationValue: h.LocationStart,
+			LocationRef:   h.Location,
 			HighlightedAt: highlightedAt,
 			Style:         convertAnnotationStyle(h.Style),
 			Color:         getColorForStyle(h.Style),
+			ContextPrefix: contextPrefix,
+			ContextSuffix: contextSuffix,
 			ExternalID:    fmt.Sprintf("%s-%d", h.AssetID, h.LocationStart),
 			Source: entities.Source{
 				Name:        "apple_books",
@@ -292,7 +331,12 @@ func (r *AppleBooksReader) GetBooks() ([]entities.Book, error) {
 			},
 		}
 
+		if h.IsUnderline {
+			highlight.Style = entities.HighlightStyleUnderline
+		}
+
 		book.Highlights = append(book.Highlights, highlight)
+		locations[key] = append(locations[key], h.Location)
 	}
 
 	// Convert map to slice in original order
@@ -300,6 +344,7 @@ func (r *AppleBooksReader) GetBooks() ([]entities.Book, error) {
 	for _, key := range bookOrder {
 		book := bookMap[key]
 		if len(book.Highlights) > 0 {
+			orderByLocation(book.Highlights, locations[key])
 			books = append(books, *book)
 		}
 	}
@@ -307,6 +352,42 @@ func (r *AppleBooksReader) GetBooks() ([]entities.Book, error) {
 	return books, nil
 }
 
+// orderByLocation sorts the highlights of a book in reading order by their
+// EPUB CFIs and numbers them 1, 2, ... in LocationValue, as the range start
+// offsets Apple Books keeps restart in every chapter. Highlights without a
+// CFI follow, ordered by their range start.
+func orderByLocation(highlights []entities.Highlight, locations []string) {
+	type positioned struct {
+		highlight entities.Highlight
+		cfi       CFI
+		hasCFI    bool
+	}
+
+	items := make([]positioned, len(highlights))
+	for i := range highlights {
+		cfi, err := ParseCFI(locations[i])
+		items[i] = positioned{highlight: highlights[i], cfi: cfi, hasCFI: err == nil}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.hasCFI != b.hasCFI {
+			return a.hasCFI
+		}
+		if a.hasCFI {
+			if c := a.cfi.Compare(b.cfi); c != 0 {
+				return c < 0
+			}
+		}
+		return a.highlight.LocationValue < b.highlight.LocationValue
+	})
+
+	for i := range items {
+		highlights[i] = items[i].highlight
+		highlights[i].LocationValue = i + 1
+	}
+}
+
 func convertAnnotationStyle(style int) entities.HighlightStyle {
 	switch AnnotationStyle(style) {
 	case AnnotationStyleUnderline:
@@ -334,3 +415,31 @@ func getColorForStyle(style int) string {
 		return "#FFFF00" // Default to yellow
 	}
 }
+
+// splitContext returns the text of passage before and after the selection,
+// trimmed to fit the context columns. Both are empty when the passage does
+// not contain the selection.
+func splitContext(passage, selection string) (prefix, suffix string) {
+	i := strings.Index(passage, selection)
+	if i < 0 {
+		return "", ""
+	}
+	prefix = strings.TrimSpace(passage[:i])
+	suffix = strings.TrimSpace(passage[i+len(selection):])
+
+	// Keep the words nearest to the selection
+	if runes := []rune(prefix); len(runes) > maxContextLength {
+		prefix = string(runes[len(runes)-maxContextLength:])
+	}
+	if runes := []rune(suffix); len(runes) > maxContextLength {
+		suffix = string(runes[:maxContextLength])
+	}
+	return prefix, suffix
+}
+
+// annotationColumnExists reports whether the annotation table has column
+func annotationColumnExists(db *sql.DB, column string) bool {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('ZAEANNOTATION') WHERE name = ?", column).Scan(&count)
+	return err == nil && count > 0
+}
@@ -3,6 +3,7 @@ package applebooks
 import (
 	"database/sql"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -413,3 +414,120 @@ func TestGetBooks_NoteOnlyHighlight(t *testing.T) {
 		t.Errorf("Expected note 'This is just a note', got '%s'", h.Note)
 	}
 }
+
+func TestGetBooks_ContextAndUnderline(t *testing.T) {
+	annotationDBPath, bookDBPath, cleanup := createTestDatabases(t)
+	defer cleanup()
+
+	insertTestBook(t, bookDBPath, "book-1", "Test Book", "Test Author")
+
+	db, err := sql.Open("sqlite3", annotationDBPath)
+	if err != nil {
+		t.Fatalf("Failed to open annotation database: %v", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE ZAEANNOTATION ADD COLUMN ZANNOTATIONISUNDERLINE INTEGER`); err != nil {
+		t.Fatalf("Failed to add underline column: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO ZAEANNOTATION (
+			ZANNOTATIONASSETID, ZANNOTATIONSELECTEDTEXT, ZANNOTATIONREPRESENTATIVETEXT,
+			ZFUTUREPROOFING5, ZANNOTATIONSTYLE, ZANNOTATIONISUNDERLINE, ZPLLOCATIONRANGESTART, ZANNOTATIONDELETED
+		)
+		VALUES ('book-1', 'the selection', 'Some text before the selection and after.', ' Chapter 2 ', 3, 1, 10, 0),
+			('book-1', 'no style', NULL, NULL, NULL, NULL, 20, 0)
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to insert annotations: %v", err)
+	}
+
+	reader, err := NewAppleBooksReader(annotationDBPath, bookDBPath)
+	if err != nil {
+		t.Fatalf("Failed to create reader: %v", err)
+	}
+	books, err := reader.GetBooks()
+	if err != nil {
+		t.Fatalf("Failed to get books: %v", err)
+	}
+	if len(books) != 1 || len(books[0].Highlights) != 2 {
+		t.Fatalf("Expected 1 book with 2 highlights, got %+v", books)
+	}
+
+	h := books[0].Highlights[0]
+	if h.Chapter != "Chapter 2" {
+		t.Errorf("Expected chapter 'Chapter 2', got '%s'", h.Chapter)
+	}
+	if h.ContextPrefix != "Some text before" || h.ContextSuffix != "and after." {
+		t.Errorf("Expected context around the selection, got '%s' / '%s'", h.ContextPrefix, h.ContextSuffix)
+	}
+	if h.Style != entities.HighlightStyleUnderline || h.Color != "#0000FF" {
+		t.Errorf("Expected a blue underline, got %s %s", h.Style, h.Color)
+	}
+
+	// Annotations without a style are read as default highlights
+	h = books[0].Highlights[1]
+	if h.Style != entities.HighlightStyleHighlight || h.Color != "#FFFF00" {
+		t.Errorf("Expected a yellow highlight, got %s %s", h.Style, h.Color)
+	}
+}
+
+func TestGetHighlights_WithoutOptionalColumns(t *testing.T) {
+	tempDir := t.TempDir()
+	annotationDBPath := filepath.Join(tempDir, "annotations.sqlite")
+	_, bookDBPath, cleanup := createTestDatabases(t)
+	defer cleanup()
+	insertTestBook(t, bookDBPath, "book-1", "Test Book", "Test Author")
+
+	db, err := sql.Open("sqlite3", annotationDBPath)
+	if err != nil {
+		t.Fatalf("Failed to create annotation database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE ZAEANNOTATION (
+			Z_PK INTEGER PRIMARY KEY,
+			ZANNOTATIONASSETID TEXT,
+			ZANNOTATIONLOCATION TEXT,
+			ZANNOTATIONSELECTEDTEXT TEXT,
+			ZANNOTATIONNOTE TEXT,
+			ZANNOTATIONMODIFICATIONDATE REAL,
+			ZPLLOCATIONRANGESTART INTEGER,
+			ZANNOTATIONDELETED INTEGER DEFAULT 0
+		);
+		INSERT INTO ZAEANNOTATION (ZANNOTATIONASSETID, ZANNOTATIONSELECTEDTEXT, ZANNOTATIONDELETED)
+		VALUES ('book-1', 'Old highlight', 0);
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to set up annotation database: %v", err)
+	}
+
+	reader, err := NewAppleBooksReader(annotationDBPath, bookDBPath)
+	if err != nil {
+		t.Fatalf("Failed to create reader: %v", err)
+	}
+	highlights, err := reader.GetHighlights()
+	if err != nil {
+		t.Fatalf("Failed to get highlights: %v", err)
+	}
+	if len(highlights) != 1 || highlights[0].Chapter != "" || highlights[0].Style != 0 {
+		t.Errorf("Expected 1 highlight without chapter or style, got %+v", highlights)
+	}
+}
+
+func TestSplitContext(t *testing.T) {
+	prefix, suffix := splitContext("Before it. The quote. After it.", "The quote.")
+	if prefix != "Before it." || suffix != "After it." {
+		t.Errorf("Unexpected context '%s' / '%s'", prefix, suffix)
+	}
+
+	prefix, suffix = splitContext("Unrelated passage", "The quote.")
+	if prefix != "" || suffix != "" {
+		t.Errorf("Expected no context, got '%s' / '%s'", prefix, suffix)
+	}
+
+	long := strings.Repeat("a", maxContextLength+10)
+	prefix, _ = splitContext(long+"quote", "quote")
+	if len(prefix) != maxContextLength {
+		t.Errorf("Expected prefix trimmed to %d, got %d", maxContextLength, len(prefix))
+	}
+}
@@ -98,6 +98,21 @@ func (s *Service) LogDelete(userID uint, entityType string, entityID uint, entit
 	s.LogAsync(event)
 }
 
+// LogRestore records restoring a soft-deleted entity from the trash.
+func (s *Service) LogRestore(userID uint, entityType string, entityID uint, entityName string) {
+	event := &entities.AuditEvent{
+		UserID:      userID,
+		EventType:   entities.AuditEventRestore,
+		Action:      entityType + "_restore",
+		Description: "Restored " + entityType + ": " + entityName,
+		EntityType:  entityType,
+		EntityID:    &entityID,
+		Status:      entities.AuditStatusSuccess,
+	}
+
+	s.LogAsync(event)
+}
+
 // LogAuth records an authentication event.
 func (s *Service) LogAuth(userID uint, action string, ipAddr, userAgent string, success bool) {
 	event := &entities.AuditEvent{
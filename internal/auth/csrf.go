@@ -18,10 +18,11 @@ const CSRFTokenHeader = "X-CSRF-Token"
 // It skips CSRF checks for:
 // - API routes with valid Bearer token authentication
 // - Safe HTTP methods (GET, HEAD, OPTIONS, TRACE)
+// - Paths under exemptPrefixes, which must not accept cookies (see Middleware.WithBasicAuth)
 //
 // The authService parameter is used to validate bearer tokens before skipping CSRF.
 // If nil, bearer tokens are not validated (less secure, for backward compatibility).
-func CSRFMiddleware(secret []byte, secure bool, authService *Service) gin.HandlerFunc {
+func CSRFMiddleware(secret []byte, secure bool, authService *Service, exemptPrefixes ...string) gin.HandlerFunc {
 	csrfProtect := csrf.Protect(
 		secret,
 		csrf.Secure(secure),
@@ -38,6 +39,13 @@ func CSRFMiddleware(secret []byte, secure bool, authService *Service) gin.Handle
 			return
 		}
 
+		for _, prefix := range exemptPrefixes {
+			if path := c.Request.URL.Path; path == prefix || strings.HasPrefix(path, prefix+"/") {
+				c.Next()
+				return
+			}
+		}
+
 		// Apply CSRF protection
 		handler := csrfProtect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Store the CSRF token in the context for templates
@@ -2,7 +2,9 @@ package auth
 
 import (
 	"errors"
+	"fmt"
 	"html/template"
+	"log"
 	"net/http"
 	"path/filepath"
 	"strings"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/mrlokans/assistant/internal/config"
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/qrcode"
 )
 
 // setupMutex serializes setup requests to prevent race conditions.
@@ -62,18 +65,11 @@ type AuthController struct {
 	templates      *template.Template
 	config         config.Auth
 	rateLimiter    *RateLimiter
+	basePath       string
 }
 
 // NewAuthController creates a new authentication controller.
 func NewAuthController(service *Service, sessionManager *SessionManager, templatesPath string, cfg config.Auth) (*AuthController, error) {
-	// Parse auth templates
-	pattern := filepath.Join(templatesPath, "auth", "*.html")
-	tmpl, err := template.ParseGlob(pattern)
-	if err != nil {
-		// Templates might not exist yet, create controller without them
-		tmpl = nil
-	}
-
 	// Initialize rate limiter with configuration
 	rateLimiter := NewRateLimiter(RateLimitConfig{
 		MaxAttempts:     cfg.MaxLoginAttempts,
@@ -81,13 +77,30 @@ func NewAuthController(service *Service, sessionManager *SessionManager, templat
 		LockoutDuration: cfg.LockoutDuration,
 	})
 
-	return &AuthController{
+	ac := &AuthController{
 		service:        service,
 		sessionManager: sessionManager,
-		templates:      tmpl,
 		config:         cfg,
 		rateLimiter:    rateLimiter,
-	}, nil
+	}
+
+	// Parse auth templates; links in them start with the base path
+	pattern := filepath.Join(templatesPath, "auth", "*.html")
+	funcs := template.FuncMap{"base": func() string { return ac.basePath }}
+	tmpl, err := template.New("auth").Funcs(funcs).ParseGlob(pattern)
+	// Templates might not exist yet, create controller without them
+	if err == nil {
+		ac.templates = tmpl
+	}
+
+	return ac, nil
+}
+
+// WithBasePath sets the URL prefix the app is served under, used by links
+// in the auth pages.
+func (ac *AuthController) WithBasePath(basePath string) *AuthController {
+	ac.basePath = basePath
+	return ac
 }
 
 // RegisterRoutes registers authentication routes on the router.
@@ -98,6 +111,10 @@ func (ac *AuthController) RegisterRoutes(router *gin.Engine) {
 	router.GET("/logout", ac.Logout) // Support GET for simple logout links
 	router.GET("/setup", ac.SetupPage)
 	router.POST("/setup", ac.Setup)
+	router.GET("/login/2fa", ac.TwoFactorPage)
+	router.POST("/login/2fa", ac.VerifyTwoFactor)
+	router.GET("/login/2fa/setup", ac.TwoFactorSetupPage)
+	router.POST("/login/2fa/setup", ac.ConfirmTwoFactorSetup)
 }
 
 // Stop cleans up resources (rate limiter background goroutine).
@@ -186,6 +203,26 @@ func (ac *AuthController) Login(c *gin.Context) {
 		ac.rateLimiter.RecordSuccess(clientIP, username)
 	}
 
+	// Users with two-factor, or required by an admin to set it up, need a second step
+	if user.TOTPEnabled || user.TOTPRequired {
+		if ac.sessionManager == nil || ac.sessionManager.BeginTwoFactor(c.Request, user, next) != nil {
+			ac.renderTemplate(c, "login.html", gin.H{
+				"Title":     "Login",
+				"Next":      next,
+				"Username":  username,
+				"CSRFToken": GetCSRFToken(c),
+				"Error":     "Failed to create session",
+			})
+			return
+		}
+		if user.TOTPEnabled {
+			c.Redirect(http.StatusFound, "/login/2fa")
+		} else {
+			c.Redirect(http.StatusFound, "/login/2fa/setup")
+		}
+		return
+	}
+
 	// Create session
 	if ac.sessionManager != nil {
 		if err := ac.sessionManager.CreateSession(c.Request, user); err != nil {
@@ -203,6 +240,174 @@ func (ac *AuthController) Login(c *gin.Context) {
 	c.Redirect(http.StatusFound, next)
 }
 
+// TwoFactorPage renders the authentication code form of the second login step.
+func (ac *AuthController) TwoFactorPage(c *gin.Context) {
+	if userID, _ := ac.pendingTwoFactor(c); userID == 0 {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	ac.renderTemplate(c, "login_2fa.html", gin.H{
+		"Title":     "Two-Factor Authentication",
+		"CSRFToken": GetCSRFToken(c),
+	})
+}
+
+// VerifyTwoFactor checks the authentication or recovery code and completes the login.
+func (ac *AuthController) VerifyTwoFactor(c *gin.Context) {
+	userID, next := ac.pendingTwoFactor(c)
+	if userID == 0 {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+	clientIP := c.ClientIP()
+	rateLimitKey := fmt.Sprintf("2fa:%d", userID)
+
+	if ac.rateLimiter != nil {
+		allowed, retryAfter := ac.rateLimiter.Allow(clientIP, rateLimitKey)
+		if !allowed {
+			c.Header("Retry-After", retryAfter.String())
+			ac.renderTemplate(c, "login_2fa.html", gin.H{
+				"Title":     "Two-Factor Authentication",
+				"CSRFToken": GetCSRFToken(c),
+				"Error":     "Too many attempts. Please try again later.",
+			})
+			return
+		}
+	}
+
+	if err := ac.service.VerifyTOTP(userID, c.PostForm("code")); err != nil {
+		if ac.rateLimiter != nil {
+			ac.rateLimiter.RecordFailure(clientIP, rateLimitKey)
+		}
+
+		errorMsg := "Invalid authentication code"
+		if errors.Is(err, ErrAccountLocked) {
+			errorMsg = "Account is locked. Please try again later."
+		}
+
+		ac.renderTemplate(c, "login_2fa.html", gin.H{
+			"Title":     "Two-Factor Authentication",
+			"CSRFToken": GetCSRFToken(c),
+			"Error":     errorMsg,
+		})
+		return
+	}
+
+	if ac.rateLimiter != nil {
+		ac.rateLimiter.RecordSuccess(clientIP, rateLimitKey)
+	}
+	ac.completeTwoFactorLogin(c, userID, next, nil)
+}
+
+// TwoFactorSetupPage shows a new secret and QR code to users required to enroll.
+func (ac *AuthController) TwoFactorSetupPage(c *gin.Context) {
+	userID, _ := ac.pendingTwoFactor(c)
+	if userID == 0 {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	enrollment, err := ac.service.BeginTOTPEnrollment(userID)
+	if errors.Is(err, ErrTOTPAlreadyEnabled) {
+		c.Redirect(http.StatusFound, "/login/2fa")
+		return
+	}
+	if err != nil {
+		ac.renderTemplate(c, "login_2fa_setup.html", gin.H{
+			"Title":     "Set Up Two-Factor Authentication",
+			"CSRFToken": GetCSRFToken(c),
+			"Error":     "Failed to start two-factor setup",
+		})
+		return
+	}
+
+	ac.renderTwoFactorSetup(c, enrollment, "")
+}
+
+// ConfirmTwoFactorSetup enables two-factor with the first code and completes the login.
+func (ac *AuthController) ConfirmTwoFactorSetup(c *gin.Context) {
+	userID, next := ac.pendingTwoFactor(c)
+	if userID == 0 {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	codes, err := ac.service.ConfirmTOTPEnrollment(userID, c.PostForm("code"))
+	if err != nil {
+		enrollment, enrollErr := ac.service.GetTOTPEnrollment(userID)
+		if enrollErr != nil {
+			c.Redirect(http.StatusFound, "/login/2fa/setup")
+			return
+		}
+		ac.renderTwoFactorSetup(c, enrollment, "Invalid authentication code")
+		return
+	}
+
+	ac.completeTwoFactorLogin(c, userID, next, codes)
+}
+
+// pendingTwoFactor returns the user between the password and two-factor steps.
+func (ac *AuthController) pendingTwoFactor(c *gin.Context) (uint, string) {
+	if ac.sessionManager == nil {
+		return 0, ""
+	}
+	userID, next := ac.sessionManager.PendingTwoFactor(c.Request)
+	return userID, sanitizeRedirectPath(next)
+}
+
+// completeTwoFactorLogin creates the session; new recovery codes are shown before continuing.
+func (ac *AuthController) completeTwoFactorLogin(c *gin.Context, userID uint, next string, recoveryCodes []string) {
+	user, err := ac.service.GetUserByID(userID)
+	if err == nil {
+		err = ac.sessionManager.CreateSession(c.Request, user)
+	}
+	if err != nil {
+		ac.renderTemplate(c, "login.html", gin.H{
+			"Title":     "Login",
+			"Next":      next,
+			"CSRFToken": GetCSRFToken(c),
+			"Error":     "Failed to create session",
+		})
+		return
+	}
+
+	if len(recoveryCodes) > 0 {
+		ac.renderTemplate(c, "login_2fa_codes.html", gin.H{
+			"Title":         "Recovery Codes",
+			"Next":          next,
+			"RecoveryCodes": recoveryCodes,
+		})
+		return
+	}
+	c.Redirect(http.StatusFound, next)
+}
+
+func (ac *AuthController) renderTwoFactorSetup(c *gin.Context, enrollment *TOTPEnrollment, errorMsg string) {
+	qrCode, err := TOTPQRCode(enrollment.URI)
+	if err != nil {
+		log.Printf("Failed to render TOTP QR code: %v", err)
+	}
+
+	ac.renderTemplate(c, "login_2fa_setup.html", gin.H{
+		"Title":     "Set Up Two-Factor Authentication",
+		"CSRFToken": GetCSRFToken(c),
+		"Secret":    enrollment.Secret,
+		"QRCode":    qrCode,
+		"Error":     errorMsg,
+	})
+}
+
+// TOTPQRCode renders an otpauth:// URI as an inline SVG QR code.
+func TOTPQRCode(uri string) (template.HTML, error) {
+	code, err := qrcode.Encode(uri)
+	if err != nil {
+		return "", err
+	}
+	// The SVG is generated entirely by the encoder, no user input is interpolated
+	return template.HTML(code.SVG()), nil
+}
+
 // Logout destroys the session and redirects to login.
 func (ac *AuthController) Logout(c *gin.Context) {
 	if ac.sessionManager != nil {
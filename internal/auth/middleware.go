@@ -15,7 +15,7 @@ const (
 	ContextKeyUserID   = "auth_user_id"
 	ContextKeyUsername = "auth_username"
 	ContextKeyRole     = "auth_role"
-	ContextKeyAuthType = "auth_type" // "session", "bearer", or "none"
+	ContextKeyAuthType = "auth_type" // "session", "bearer", "basic", or "none"
 )
 
 // AuthType indicates how the user was authenticated
@@ -25,8 +25,12 @@ const (
 	AuthTypeNone    AuthType = "none"
 	AuthTypeSession AuthType = "session"
 	AuthTypeBearer  AuthType = "bearer"
+	AuthTypeBasic   AuthType = "basic"
 )
 
+// BasicAuthRealm is announced to clients of paths that use HTTP Basic auth.
+const BasicAuthRealm = "Highlights"
+
 // DefaultUserID is used when authentication is disabled
 const DefaultUserID = uint(0)
 
@@ -36,17 +40,26 @@ type Middleware struct {
 	sessionManager *SessionManager
 	config         config.Auth
 	publicPaths    map[string]bool
+
+	// Path prefixes authenticated with HTTP Basic instead of sessions
+	basicAuthPrefixes []string
+
+	// Path prefixes served to everyone, such as the public library
+	publicPrefixes []string
 }
 
 // NewMiddleware creates a new authentication middleware.
 func NewMiddleware(service *Service, sessionManager *SessionManager, cfg config.Auth) *Middleware {
 	publicPaths := map[string]bool{
-		"/health":      true,
-		"/ping":        true,
-		"/login":       true,
-		"/setup":       true,
-		"/static":      true, // Static files prefix
-		"/favicon.ico": true,
+		"/health":          true,
+		"/ping":            true,
+		"/login":           true,
+		"/setup":           true,
+		"/login/2fa":       true, // Second login step, guarded by the pending session
+		"/login/2fa/setup": true,
+		"/static":          true, // Static files prefix
+		"/favicon.ico":     true,
+		"/sw.js":           true, // Service worker script
 	}
 
 	return &Middleware{
@@ -57,6 +70,22 @@ func NewMiddleware(service *Service, sessionManager *SessionManager, cfg config.
 	}
 }
 
+// WithBasicAuth makes paths under prefix authenticate with HTTP Basic, using the
+// username and an API token as the password. This is for clients such as WebDAV
+// apps that cannot send bearer tokens. Session cookies are not accepted there,
+// so these paths need no CSRF protection.
+func (m *Middleware) WithBasicAuth(prefix string) *Middleware {
+	m.basicAuthPrefixes = append(m.basicAuthPrefixes, prefix)
+	return m
+}
+
+// WithPublicPrefix serves paths under prefix without authentication. Handlers
+// there see DefaultUserID and must only show what is meant to be public.
+func (m *Middleware) WithPublicPrefix(prefix string) *Middleware {
+	m.publicPrefixes = append(m.publicPrefixes, prefix)
+	return m
+}
+
 // Handler returns a Gin middleware handler that authenticates requests.
 func (m *Middleware) Handler() gin.HandlerFunc {
 	// If auth is disabled, inject default user
@@ -87,6 +116,17 @@ func (m *Middleware) authHandler() gin.HandlerFunc {
 			return
 		}
 
+		if m.isBasicAuthPath(c.Request.URL.Path) {
+			if user := m.tryBasicAuth(c); user != nil {
+				m.setUserContext(c, user, AuthTypeBasic)
+				c.Next()
+				return
+			}
+			c.Header("WWW-Authenticate", `Basic realm="`+BasicAuthRealm+`"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
 		// Try Bearer token first (for API clients)
 		if user := m.tryBearerAuth(c); user != nil {
 			m.setUserContext(c, user, AuthTypeBearer)
@@ -137,6 +177,22 @@ func (m *Middleware) tryBearerAuth(c *gin.Context) *entities.User {
 	return user
 }
 
+// tryBasicAuth attempts to authenticate using HTTP Basic credentials,
+// where the password is the user's API token.
+func (m *Middleware) tryBasicAuth(c *gin.Context) *entities.User {
+	username, token, ok := c.Request.BasicAuth()
+	if !ok || username == "" || token == "" {
+		return nil
+	}
+
+	user, err := m.service.ValidateToken(token)
+	if err != nil || user.Username != username {
+		return nil
+	}
+
+	return user
+}
+
 // trySessionAuth attempts to authenticate using session cookie.
 func (m *Middleware) trySessionAuth(c *gin.Context) *entities.User {
 	if m.sessionManager == nil {
@@ -176,6 +232,22 @@ func (m *Middleware) isPublicPath(path string) bool {
 		return true
 	}
 
+	for _, prefix := range m.publicPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBasicAuthPath checks if a path is authenticated with HTTP Basic.
+func (m *Middleware) isBasicAuthPath(path string) bool {
+	for _, prefix := range m.basicAuthPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
 	return false
 }
 
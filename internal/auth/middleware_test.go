@@ -3,6 +3,7 @@ package auth
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -76,6 +77,7 @@ func TestMiddleware_PublicPaths(t *testing.T) {
 		"/setup",
 		"/static/style.css",
 		"/favicon.ico",
+		"/sw.js",
 	}
 
 	for _, path := range publicPaths {
@@ -97,6 +99,40 @@ func TestMiddleware_PublicPaths(t *testing.T) {
 	}
 }
 
+func TestMiddleware_PublicPrefix(t *testing.T) {
+	middleware, _, _ := setupMiddleware(t, config.AuthModeLocal)
+	middleware.WithPublicPrefix("/public")
+
+	router := gin.New()
+	router.Use(middleware.Handler())
+	for _, path := range []string{"/public", "/public/books/1", "/publication"} {
+		router.GET(path, func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	}
+
+	testCases := []struct {
+		path string
+		want int
+	}{
+		{"/public", http.StatusOK},
+		{"/public/books/1", http.StatusOK},
+		{"/publication", http.StatusFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Errorf("Expected %d for %s, got %d", tc.want, tc.path, rr.Code)
+			}
+		})
+	}
+}
+
 func TestMiddleware_ProtectedPath_RedirectsToLogin(t *testing.T) {
 	middleware, _, _ := setupMiddleware(t, config.AuthModeLocal)
 
@@ -224,6 +260,69 @@ func TestMiddleware_BearerAuth_MalformedHeader(t *testing.T) {
 	}
 }
 
+func TestMiddleware_BasicAuth(t *testing.T) {
+	middleware, service, _ := setupMiddleware(t, config.AuthModeLocal)
+	middleware.WithBasicAuth("/dav")
+
+	user, err := service.CreateUser("testuser", "test@example.com", "password12345", entities.UserRoleEditor)
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	token, err := service.GenerateToken(user.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(middleware.Handler())
+	router.PUT("/dav/*path", func(c *gin.Context) {
+		if GetAuthType(c) != AuthTypeBasic || GetUserID(c) != user.ID {
+			t.Errorf("unexpected auth context: type=%s user=%d", GetAuthType(c), GetUserID(c))
+		}
+		c.Status(http.StatusCreated)
+	})
+	router.GET("/api/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name     string
+		path     string
+		username string
+		password string
+		want     int
+	}{
+		{"valid token", "/dav/backup.mrpro", "testuser", token, http.StatusCreated},
+		{"password instead of token", "/dav/backup.mrpro", "testuser", "password12345", http.StatusUnauthorized},
+		{"wrong username", "/dav/backup.mrpro", "someone", token, http.StatusUnauthorized},
+		{"no credentials", "/dav/backup.mrpro", "", "", http.StatusUnauthorized},
+		{"other paths ignore basic auth", "/api/test", "testuser", token, http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := http.MethodPut
+			if strings.HasPrefix(tc.path, "/api/") {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tc.path, nil)
+			if tc.username != "" {
+				req.SetBasicAuth(tc.username, tc.password)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Errorf("Expected %d, got %d", tc.want, rr.Code)
+			}
+			if strings.HasPrefix(tc.path, "/dav/") && rr.Code == http.StatusUnauthorized &&
+				rr.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected a Basic auth challenge")
+			}
+		})
+	}
+}
+
 func TestMiddleware_RequireAuth(t *testing.T) {
 	middleware, service, _ := setupMiddleware(t, config.AuthModeLocal)
 
@@ -4,12 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/crypto"
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/i18n"
+	"github.com/mrlokans/assistant/internal/localtime"
 )
 
 // Validation patterns
@@ -46,6 +50,9 @@ type UserRepository interface {
 type Service struct {
 	db     *gorm.DB
 	config config.Auth
+
+	// encryptor protects TOTP secrets at rest (optional, see twofactor.go)
+	encryptor *crypto.Encryptor
 }
 
 // NewService creates a new authentication service.
@@ -56,6 +63,12 @@ func NewService(db *gorm.DB, cfg config.Auth) *Service {
 	}
 }
 
+// WithEncryptor enables encryption of TOTP secrets.
+func (s *Service) WithEncryptor(encryptor *crypto.Encryptor) *Service {
+	s.encryptor = encryptor
+	return s
+}
+
 // CreateUser creates a new user with password authentication.
 func (s *Service) CreateUser(username, email, password string, role entities.UserRole) (*entities.User, error) {
 	if username == "" {
@@ -135,7 +148,9 @@ func (s *Service) Authenticate(username, password string) (*entities.User, error
 
 	if err := CheckPassword(password, user.PasswordHash); err != nil {
 		// Record failed login attempt
-		s.recordFailedLogin(&user)
+		if recordErr := s.recordFailedLogin(&user); recordErr != nil {
+			return nil, recordErr
+		}
 		return nil, err
 	}
 
@@ -151,7 +166,7 @@ func (s *Service) Authenticate(username, password string) (*entities.User, error
 }
 
 // recordFailedLogin increments the failed login counter and locks the account if threshold reached.
-func (s *Service) recordFailedLogin(user *entities.User) {
+func (s *Service) recordFailedLogin(user *entities.User) error {
 	user.FailedLoginCount++
 
 	updates := map[string]any{
@@ -168,7 +183,10 @@ func (s *Service) recordFailedLogin(user *entities.User) {
 		updates["locked_until"] = lockedUntil
 	}
 
-	s.db.Model(user).Updates(updates)
+	if err := s.db.Model(user).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record failed login: %w", err)
+	}
+	return nil
 }
 
 // GetUserByID retrieves a user by their ID.
@@ -184,6 +202,15 @@ func (s *Service) GetUserByID(id uint) (*entities.User, error) {
 	return &user, nil
 }
 
+// ListUsers returns all users ordered by username.
+func (s *Service) ListUsers() ([]entities.User, error) {
+	var users []entities.User
+	if err := s.db.Order("username").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, nil
+}
+
 // GetUserByTokenHash retrieves a user by their hashed API token.
 func (s *Service) GetUserByTokenHash(tokenHash string) (*entities.User, error) {
 	var user entities.User
@@ -277,6 +304,35 @@ func (s *Service) ChangePassword(userID uint, oldPassword, newPassword string) e
 	return s.db.Model(user).Update("password_hash", newHash).Error
 }
 
+// UpdatePreferences sets the user's timezone (an IANA name such as
+// "Europe/Berlin"), locale (a language tag such as "en-GB") and UI language
+// (one of i18n.Languages). Empty values fall back to the server defaults.
+func (s *Service) UpdatePreferences(userID uint, timezone, locale, language string) error {
+	timezone, locale, language = strings.TrimSpace(timezone), strings.TrimSpace(locale), strings.TrimSpace(language)
+	if err := localtime.ValidateTimezone(timezone); err != nil {
+		return err
+	}
+	if err := localtime.ValidateLocale(locale); err != nil {
+		return err
+	}
+	if err := i18n.Validate(language); err != nil {
+		return err
+	}
+
+	result := s.db.Model(&entities.User{}).Where("id = ?", userID).Updates(map[string]any{
+		"timezone": timezone,
+		"locale":   locale,
+		"language": language,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
 // HasUsers returns true if any users exist in the database.
 func (s *Service) HasUsers() (bool, error) {
 	var count int64
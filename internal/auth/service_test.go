@@ -9,6 +9,8 @@ import (
 
 	"github.com/mrlokans/assistant/internal/config"
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/i18n"
+	"github.com/mrlokans/assistant/internal/localtime"
 )
 
 func setupTestDB(t *testing.T) *gorm.DB {
@@ -326,3 +328,37 @@ func TestService_IsAuthEnabled(t *testing.T) {
 		t.Error("IsAuthEnabled() = false for AuthModeLocal")
 	}
 }
+
+func TestService_UpdatePreferences(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewService(db, config.Auth{BcryptCost: 10})
+
+	user, err := svc.CreateUser("testuser", "test@example.com", "password123456", entities.UserRoleViewer)
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	if err := svc.UpdatePreferences(user.ID, "Mars/Olympus", "", ""); !errors.Is(err, localtime.ErrInvalidTimezone) {
+		t.Errorf("UpdatePreferences(bad timezone) error = %v, want ErrInvalidTimezone", err)
+	}
+	if err := svc.UpdatePreferences(user.ID, "", "not a locale", ""); !errors.Is(err, localtime.ErrInvalidLocale) {
+		t.Errorf("UpdatePreferences(bad locale) error = %v, want ErrInvalidLocale", err)
+	}
+	if err := svc.UpdatePreferences(9999, "Europe/Berlin", "", ""); err != ErrUserNotFound {
+		t.Errorf("UpdatePreferences(missing user) error = %v, want ErrUserNotFound", err)
+	}
+	if err := svc.UpdatePreferences(user.ID, "", "", "fr"); !errors.Is(err, i18n.ErrUnsupportedLanguage) {
+		t.Errorf("UpdatePreferences(bad language) error = %v, want ErrUnsupportedLanguage", err)
+	}
+
+	if err := svc.UpdatePreferences(user.ID, " Europe/Berlin ", "de-DE", "de"); err != nil {
+		t.Fatalf("UpdatePreferences() error = %v", err)
+	}
+	updated, err := svc.GetUserByID(user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	if updated.Timezone != "Europe/Berlin" || updated.Locale != "de-DE" || updated.Language != "de" {
+		t.Errorf("preferences = %q/%q/%q, want Europe/Berlin/de-DE/de", updated.Timezone, updated.Locale, updated.Language)
+	}
+}
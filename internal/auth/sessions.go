@@ -19,8 +19,16 @@ const (
 	SessionKeyUsername = "username"
 	SessionKeyRole     = "role"
 	SessionKeyLoginAt  = "login_at"
+
+	// Set between the password and two-factor steps of a login
+	SessionKeyPendingUserID = "pending_user_id"
+	SessionKeyPendingNext   = "pending_next"
+	SessionKeyPendingAt     = "pending_at"
 )
 
+// twoFactorTimeout is how long a user has to complete the second login step.
+const twoFactorTimeout = 5 * time.Minute
+
 func init() {
 	// Register types that will be stored in sessions
 	gob.Register(entities.UserRole(""))
@@ -79,10 +87,46 @@ func (sm *SessionManager) CreateSession(r *http.Request, user *entities.User) er
 	sm.Put(r.Context(), SessionKeyUsername, user.Username)
 	sm.Put(r.Context(), SessionKeyRole, user.Role)
 	sm.Put(r.Context(), SessionKeyLoginAt, time.Now())
+	sm.clearPendingTwoFactor(r)
+
+	return nil
+}
+
+// BeginTwoFactor records a user who passed the password check but still has to
+// provide a second factor. The user is not authenticated until CreateSession.
+func (sm *SessionManager) BeginTwoFactor(r *http.Request, user *entities.User, next string) error {
+	if err := sm.RenewToken(r.Context()); err != nil {
+		return err
+	}
 
+	sm.Put(r.Context(), SessionKeyPendingUserID, int(user.ID))
+	sm.Put(r.Context(), SessionKeyPendingNext, next)
+	sm.Put(r.Context(), SessionKeyPendingAt, time.Now())
 	return nil
 }
 
+// PendingTwoFactor returns the user awaiting the second login step and the
+// page to continue to. Returns 0 if there is none or it has expired.
+func (sm *SessionManager) PendingTwoFactor(r *http.Request) (uint, string) {
+	userID := sm.GetInt(r.Context(), SessionKeyPendingUserID)
+	if userID == 0 {
+		return 0, ""
+	}
+
+	startedAt, _ := sm.Get(r.Context(), SessionKeyPendingAt).(time.Time)
+	if time.Since(startedAt) > twoFactorTimeout {
+		sm.clearPendingTwoFactor(r)
+		return 0, ""
+	}
+	return uint(userID), sm.GetString(r.Context(), SessionKeyPendingNext)
+}
+
+func (sm *SessionManager) clearPendingTwoFactor(r *http.Request) {
+	sm.Remove(r.Context(), SessionKeyPendingUserID)
+	sm.Remove(r.Context(), SessionKeyPendingNext)
+	sm.Remove(r.Context(), SessionKeyPendingAt)
+}
+
 // DestroySession removes all session data and invalidates the session.
 func (sm *SessionManager) DestroySession(r *http.Request) error {
 	return sm.Destroy(r.Context())
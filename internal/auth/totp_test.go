@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed "12345678901234567890" from RFC 6238 appendix B.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d) error: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, _ := TOTPCode(rfc6238Secret, now)
+	previous, _ := TOTPCode(rfc6238Secret, now.Add(-TOTPPeriod))
+	stale, _ := TOTPCode(rfc6238Secret, now.Add(-3*TOTPPeriod))
+
+	if step, ok := ValidateTOTP(rfc6238Secret, code, now); !ok || step != totpStep(now) {
+		t.Errorf("current code rejected (step %d, ok %v)", step, ok)
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, previous, now); !ok {
+		t.Error("code from the previous period should be accepted")
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, stale, now); ok {
+		t.Error("code from three periods ago should be rejected")
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, code[:3]+" "+code[3:], now); !ok {
+		t.Error("code with a space should be accepted")
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, "12345", now); ok {
+		t.Error("short code should be rejected")
+	}
+	if _, ok := ValidateTOTP("not base32!", code, now); ok {
+		t.Error("invalid secret should be rejected")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret length = %d, want 32", len(secret))
+	}
+	if _, err := TOTPCode(secret, time.Now()); err != nil {
+		t.Errorf("generated secret is not usable: %v", err)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Highlights", "alice", rfc6238Secret)
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("invalid URI: %v", err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		t.Errorf("unexpected URI prefix: %s", uri)
+	}
+	if parsed.Path != "/Highlights:alice" {
+		t.Errorf("label = %q, want /Highlights:alice", parsed.Path)
+	}
+	query := parsed.Query()
+	if query.Get("secret") != rfc6238Secret || query.Get("issuer") != "Highlights" || query.Get("digits") != "6" {
+		t.Errorf("unexpected parameters: %v", query)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), RecoveryCodeCount)
+	}
+
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if len(code) != 9 || code[4] != '-' {
+			t.Errorf("code %q is not in xxxx-xxxx format", code)
+		}
+		if hashes[i] != HashToken(strings.ReplaceAll(code, "-", "")) {
+			t.Errorf("hash of %q does not match", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}
@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// TOTPIssuer is the account issuer shown in authenticator apps.
+const TOTPIssuer = "Highlights"
+
+// encryptedSecretPrefix marks TOTP secrets encrypted with the token encryption key.
+const encryptedSecretPrefix = "enc:v1:"
+
+var (
+	ErrTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnrolling   = errors.New("no two-factor enrollment in progress")
+	ErrTOTPRequired       = errors.New("two-factor authentication is required for this account")
+	ErrInvalidTOTPCode    = errors.New("invalid authentication code")
+)
+
+// TOTPEnrollment is a generated secret awaiting confirmation with a first code.
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// BeginTOTPEnrollment generates a new secret for the user.
+// Two-factor stays disabled until ConfirmTOTPEnrollment succeeds.
+func (s *Service) BeginTOTPEnrollment(userID uint) (*TOTPEnrollment, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	stored, err := s.sealTOTPSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(user).Update("totp_secret", stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    TOTPURI(TOTPIssuer, user.Username, secret),
+	}, nil
+}
+
+// GetTOTPEnrollment returns the enrollment in progress, e.g. to show the QR code again.
+func (s *Service) GetTOTPEnrollment(userID uint) (*TOTPEnrollment, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := s.openTOTPSecret(user.TOTPSecret)
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, ErrTOTPNotEnrolling
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    TOTPURI(TOTPIssuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables two-factor once the user proves their
+// authenticator works. Returns the recovery codes (show to user once).
+func (s *Service) ConfirmTOTPEnrollment(userID uint, code string) ([]string, error) {
+	enrollment, err := s.GetTOTPEnrollment(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := ValidateTOTP(enrollment.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTOTPCode
+	}
+
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	err = s.db.Model(&entities.User{}).Where("id = ?", userID).Updates(map[string]any{
+		"totp_enabled":        true,
+		"totp_recovery_codes": strings.Join(hashes, ","),
+		"totp_last_used_step": step,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	return codes, nil
+}
+
+// VerifyTOTP checks the second login factor: a current authenticator code or
+// an unused recovery code, which is consumed. Failures count towards lockout.
+func (s *Service) VerifyTOTP(userID uint, code string) error {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return ErrAccountLocked
+	}
+
+	secret, err := s.openTOTPSecret(user.TOTPSecret)
+	if err != nil {
+		return err
+	}
+
+	// A code is only accepted once, even though it stays valid for its whole period
+	if step, ok := ValidateTOTP(secret, code, time.Now()); ok && step > user.TOTPLastUsedStep {
+		err := s.db.Model(user).Updates(map[string]any{
+			"totp_last_used_step": step,
+			"failed_login_count":  0,
+			"locked_until":        nil,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to record used two-factor code: %w", err)
+		}
+		return nil
+	}
+
+	used, err := s.consumeRecoveryCode(user, code)
+	if err != nil {
+		return err
+	}
+	if used {
+		err := s.db.Model(user).Updates(map[string]any{
+			"failed_login_count": 0,
+			"locked_until":       nil,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to reset failed logins: %w", err)
+		}
+		return nil
+	}
+
+	if err := s.recordFailedLogin(user); err != nil {
+		return err
+	}
+	return ErrInvalidTOTPCode
+}
+
+// consumeRecoveryCode removes code from the user's recovery codes if present.
+// The update is conditional on the stored list so a code cannot be used twice concurrently.
+func (s *Service) consumeRecoveryCode(user *entities.User, code string) (bool, error) {
+	code = normalizeCode(code)
+	if code == "" || user.TOTPRecoveryCodes == "" {
+		return false, nil
+	}
+
+	hash := HashToken(code)
+	hashes := strings.Split(user.TOTPRecoveryCodes, ",")
+	remaining := make([]string, 0, len(hashes))
+	found := false
+	for _, h := range hashes {
+		if !found && subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			found = true
+			continue
+		}
+		remaining = append(remaining, h)
+	}
+	if !found {
+		return false, nil
+	}
+
+	result := s.db.Model(&entities.User{}).
+		Where("id = ? AND totp_recovery_codes = ?", user.ID, user.TOTPRecoveryCodes).
+		Update("totp_recovery_codes", strings.Join(remaining, ","))
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to consume recovery code: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes with a new set.
+func (s *Service) RegenerateRecoveryCodes(userID uint) ([]string, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTOTPNotEnabled
+	}
+
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+	if err := s.db.Model(user).Update("totp_recovery_codes", strings.Join(hashes, ",")).Error; err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// DisableTOTP turns off two-factor after re-checking the user's password.
+// Not allowed while an admin requires two-factor for the account.
+func (s *Service) DisableTOTP(userID uint, password string) error {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+	if user.TOTPRequired {
+		return ErrTOTPRequired
+	}
+	if err := CheckPassword(password, user.PasswordHash); err != nil {
+		return err
+	}
+	return s.clearTOTP(userID)
+}
+
+// SetTOTPRequired sets the admin policy requiring two-factor for a user.
+// Users without two-factor are asked to enroll at their next login.
+func (s *Service) SetTOTPRequired(userID uint, required bool) error {
+	result := s.db.Model(&entities.User{}).Where("id = ?", userID).Update("totp_required", required)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update two-factor policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ResetTOTP removes a user's two-factor enrollment, e.g. after a lost device.
+// The admin policy is kept, so a required user enrolls again at next login.
+func (s *Service) ResetTOTP(userID uint) error {
+	if _, err := s.GetUserByID(userID); err != nil {
+		return err
+	}
+	return s.clearTOTP(userID)
+}
+
+// RemainingRecoveryCodes returns the number of unused recovery codes.
+func RemainingRecoveryCodes(user *entities.User) int {
+	if user.TOTPRecoveryCodes == "" {
+		return 0
+	}
+	return len(strings.Split(user.TOTPRecoveryCodes, ","))
+}
+
+func (s *Service) clearTOTP(userID uint) error {
+	err := s.db.Model(&entities.User{}).Where("id = ?", userID).Updates(map[string]any{
+		"totp_secret":         "",
+		"totp_enabled":        false,
+		"totp_recovery_codes": "",
+		"totp_last_used_step": 0,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	return nil
+}
+
+// sealTOTPSecret encrypts a secret for storage when an encryptor is configured.
+func (s *Service) sealTOTPSecret(secret string) (string, error) {
+	if s.encryptor == nil {
+		return secret, nil
+	}
+	ciphertext, err := s.encryptor.Encrypt(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+	return encryptedSecretPrefix + ciphertext, nil
+}
+
+// openTOTPSecret reverses sealTOTPSecret; unprefixed values are stored as plaintext.
+func (s *Service) openTOTPSecret(stored string) (string, error) {
+	ciphertext, encrypted := strings.CutPrefix(stored, encryptedSecretPrefix)
+	if !encrypted {
+		return stored, nil
+	}
+	if s.encryptor == nil {
+		return "", errors.New("TOTP secret is encrypted but no encryption key is configured")
+	}
+	secret, err := s.encryptor.Decrypt(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/crypto"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func setupTwoFactorUser(t *testing.T) (*Service, *entities.User) {
+	t.Helper()
+	db := setupTestDB(t)
+	svc := NewService(db, config.Auth{BcryptCost: 10})
+
+	user, err := svc.CreateUser("alice", "alice@example.com", "password12345", entities.UserRoleEditor)
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return svc, user
+}
+
+// enrollTOTP enables two-factor for the user and returns the secret and recovery codes.
+func enrollTOTP(t *testing.T, svc *Service, userID uint) (string, []string) {
+	t.Helper()
+	enrollment, err := svc.BeginTOTPEnrollment(userID)
+	if err != nil {
+		t.Fatalf("BeginTOTPEnrollment() error: %v", err)
+	}
+	// Confirm with the previous period's code so the test can still log in with the current one
+	code, _ := TOTPCode(enrollment.Secret, time.Now().Add(-TOTPPeriod))
+	codes, err := svc.ConfirmTOTPEnrollment(userID, code)
+	if err != nil {
+		t.Fatalf("ConfirmTOTPEnrollment() error: %v", err)
+	}
+	return enrollment.Secret, codes
+}
+
+func TestService_TOTPEnrollment(t *testing.T) {
+	svc, user := setupTwoFactorUser(t)
+
+	if _, err := svc.ConfirmTOTPEnrollment(user.ID, "123456"); !errors.Is(err, ErrTOTPNotEnrolling) {
+		t.Errorf("confirm without enrollment: got %v, want ErrTOTPNotEnrolling", err)
+	}
+
+	enrollment, err := svc.BeginTOTPEnrollment(user.ID)
+	if err != nil {
+		t.Fatalf("BeginTOTPEnrollment() error: %v", err)
+	}
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/Highlights:alice?") {
+		t.Errorf("unexpected URI: %s", enrollment.URI)
+	}
+
+	pending, err := svc.GetTOTPEnrollment(user.ID)
+	if err != nil || pending.Secret != enrollment.Secret {
+		t.Fatalf("GetTOTPEnrollment() = %v, %v; want the pending secret", pending, err)
+	}
+
+	if _, err := svc.ConfirmTOTPEnrollment(user.ID, "000000"); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Errorf("confirm with wrong code: got %v, want ErrInvalidTOTPCode", err)
+	}
+
+	code, _ := TOTPCode(enrollment.Secret, time.Now())
+	codes, err := svc.ConfirmTOTPEnrollment(user.ID, code)
+	if err != nil {
+		t.Fatalf("ConfirmTOTPEnrollment() error: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount {
+		t.Errorf("got %d recovery codes, want %d", len(codes), RecoveryCodeCount)
+	}
+
+	user, _ = svc.GetUserByID(user.ID)
+	if !user.TOTPEnabled {
+		t.Error("two-factor should be enabled")
+	}
+	if RemainingRecoveryCodes(user) != RecoveryCodeCount {
+		t.Errorf("RemainingRecoveryCodes() = %d, want %d", RemainingRecoveryCodes(user), RecoveryCodeCount)
+	}
+	if _, err := svc.BeginTOTPEnrollment(user.ID); !errors.Is(err, ErrTOTPAlreadyEnabled) {
+		t.Errorf("second enrollment: got %v, want ErrTOTPAlreadyEnabled", err)
+	}
+}
+
+func TestService_VerifyTOTP(t *testing.T) {
+	svc, user := setupTwoFactorUser(t)
+
+	if err := svc.VerifyTOTP(user.ID, "123456"); !errors.Is(err, ErrTOTPNotEnabled) {
+		t.Errorf("verify without two-factor: got %v, want ErrTOTPNotEnabled", err)
+	}
+
+	secret, _ := enrollTOTP(t, svc, user.ID)
+	code, _ := TOTPCode(secret, time.Now())
+
+	if err := svc.VerifyTOTP(user.ID, code); err != nil {
+		t.Fatalf("VerifyTOTP() with current code error: %v", err)
+	}
+	if err := svc.VerifyTOTP(user.ID, code); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Errorf("replayed code: got %v, want ErrInvalidTOTPCode", err)
+	}
+	if err := svc.VerifyTOTP(user.ID, "not-a-code"); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Errorf("invalid code: got %v, want ErrInvalidTOTPCode", err)
+	}
+}
+
+func TestService_VerifyTOTP_RecoveryCode(t *testing.T) {
+	svc, user := setupTwoFactorUser(t)
+	_, codes := enrollTOTP(t, svc, user.ID)
+
+	if err := svc.VerifyTOTP(user.ID, strings.ToUpper(codes[0])); err != nil {
+		t.Fatalf("VerifyTOTP() with recovery code error: %v", err)
+	}
+	if err := svc.VerifyTOTP(user.ID, codes[0]); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Errorf("reused recovery code: got %v, want ErrInvalidTOTPCode", err)
+	}
+
+	user, _ = svc.GetUserByID(user.ID)
+	if RemainingRecoveryCodes(user) != RecoveryCodeCount-1 {
+		t.Errorf("RemainingRecoveryCodes() = %d, want %d", RemainingRecoveryCodes(user), RecoveryCodeCount-1)
+	}
+
+	newCodes, err := svc.RegenerateRecoveryCodes(user.ID)
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes() error: %v", err)
+	}
+	if err := svc.VerifyTOTP(user.ID, codes[1]); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Errorf("old recovery code after regeneration: got %v, want ErrInvalidTOTPCode", err)
+	}
+	if err := svc.VerifyTOTP(user.ID, newCodes[1]); err != nil {
+		t.Errorf("new recovery code error: %v", err)
+	}
+}
+
+func TestService_VerifyTOTP_Lockout(t *testing.T) {
+	svc, user := setupTwoFactorUser(t)
+	secret, _ := enrollTOTP(t, svc, user.ID)
+
+	for i := 0; i < 5; i++ {
+		_ = svc.VerifyTOTP(user.ID, "000000")
+	}
+
+	code, _ := TOTPCode(secret, time.Now())
+	if err := svc.VerifyTOTP(user.ID, code); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("after 5 failures: got %v, want ErrAccountLocked", err)
+	}
+}
+
+func TestService_DisableTOTP(t *testing.T) {
+	svc, user := setupTwoFactorUser(t)
+	enrollTOTP(t, svc, user.ID)
+
+	if err := svc.DisableTOTP(user.ID, "wrong-password"); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("disable with wrong password: got %v, want ErrInvalidPassword", err)
+	}
+
+	if err := svc.SetTOTPRequired(user.ID, true); err != nil {
+		t.Fatalf("SetTOTPRequired() error: %v", err)
+	}
+	if err := svc.DisableTOTP(user.ID, "password12345"); !errors.Is(err, ErrTOTPRequired) {
+		t.Errorf("disable while required: got %v, want ErrTOTPRequired", err)
+	}
+
+	if err := svc.SetTOTPRequired(user.ID, false); err != nil {
+		t.Fatalf("SetTOTPRequired() error: %v", err)
+	}
+	if err := svc.DisableTOTP(user.ID, "password12345"); err != nil {
+		t.Fatalf("DisableTOTP() error: %v", err)
+	}
+
+	user, _ = svc.GetUserByID(user.ID)
+	if user.TOTPEnabled || user.TOTPSecret != "" || user.TOTPRecoveryCodes != "" {
+		t.Error("two-factor data should be cleared")
+	}
+}
+
+func TestService_AdminTOTPPolicy(t *testing.T) {
+	svc, user := setupTwoFactorUser(t)
+	enrollTOTP(t, svc, user.ID)
+
+	if err := svc.SetTOTPRequired(9999, true); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("unknown user: got %v, want ErrUserNotFound", err)
+	}
+	if err := svc.SetTOTPRequired(user.ID, true); err != nil {
+		t.Fatalf("SetTOTPRequired() error: %v", err)
+	}
+
+	// Reset keeps the policy so the user has to enroll again
+	if err := svc.ResetTOTP(user.ID); err != nil {
+		t.Fatalf("ResetTOTP() error: %v", err)
+	}
+	user, _ = svc.GetUserByID(user.ID)
+	if user.TOTPEnabled || !user.TOTPRequired {
+		t.Errorf("after reset: enabled=%v required=%v, want false/true", user.TOTPEnabled, user.TOTPRequired)
+	}
+}
+
+func TestService_TOTPSecretEncryption(t *testing.T) {
+	svc, user := setupTwoFactorUser(t)
+	key, err := crypto.GenerateKeyBytes()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	encryptor, err := crypto.NewEncryptor(key)
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+	svc.WithEncryptor(encryptor)
+
+	secret, _ := enrollTOTP(t, svc, user.ID)
+
+	user, _ = svc.GetUserByID(user.ID)
+	if !strings.HasPrefix(user.TOTPSecret, encryptedSecretPrefix) || strings.Contains(user.TOTPSecret, secret) {
+		t.Errorf("secret stored unencrypted: %q", user.TOTPSecret)
+	}
+
+	code, _ := TOTPCode(secret, time.Now())
+	if err := svc.VerifyTOTP(user.ID, code); err != nil {
+		t.Errorf("VerifyTOTP() with encrypted secret error: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Package classify sorts highlights into coarse categories such as advice,
+// definitions, quotes and data points, stored as system tags apart from the
+// tags users add themselves.
+//
+// Two backends are supported:
+//   - rules: built-in patterns, no setup or network access needed
+//   - http: posts the text to a classification web service, e.g. a local model
+package classify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// Backend names accepted in the CLASSIFIER_BACKEND setting.
+const (
+	BackendRules = "rules"
+	BackendHTTP  = "http"
+)
+
+// Classifier assigns categories to highlight text.
+type Classifier interface {
+	// Name identifies the classifier on the categories it assigned.
+	Name() string
+	// Classify returns the categories the text falls in, none if it fits no category.
+	Classify(ctx context.Context, text string) ([]entities.HighlightCategory, error)
+}
+
+// New creates the classifier selected by cfg.Backend.
+// Returns nil without an error when classification is not configured.
+func New(cfg config.Classifier) (Classifier, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	switch strings.ToLower(cfg.Backend) {
+	case "":
+		return nil, nil
+	case BackendRules:
+		return NewRuleClassifier(), nil
+	case BackendHTTP:
+		if cfg.ServiceURL == "" {
+			return nil, fmt.Errorf("CLASSIFIER_SERVICE_URL is required for the %s backend", BackendHTTP)
+		}
+		return NewHTTPClassifier(cfg.ServiceURL, cfg.ServiceToken, timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown classifier backend: %s", cfg.Backend)
+	}
+}
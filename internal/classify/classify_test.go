@@ -0,0 +1,85 @@
+package classify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestRuleClassifier(t *testing.T) {
+	tests := []struct {
+		text string
+		want []entities.HighlightCategory
+	}{
+		{"Never interrupt your enemy when he is making a mistake.", []entities.HighlightCategory{entities.HighlightCategoryAdvice}},
+		{"If you want to write well, you should read a lot.", []entities.HighlightCategory{entities.HighlightCategoryAdvice}},
+		{"Entropy is a measure of the disorder of a system.", []entities.HighlightCategory{entities.HighlightCategoryDefinition}},
+		{"Hysteresis refers to the dependence of a state on its history.", []entities.HighlightCategory{entities.HighlightCategoryDefinition}},
+		{"“The obstacle is the way.” — Marcus Aurelius", []entities.HighlightCategory{entities.HighlightCategoryQuote}},
+		{`As Seneca wrote, "we suffer more often in imagination than in reality."`, []entities.HighlightCategory{entities.HighlightCategoryQuote}},
+		{"Productivity grew by 2.5% a year in the decades after the war.", []entities.HighlightCategory{entities.HighlightCategoryDataPoint}},
+		{"The printing press spread across Europe by 1500.", []entities.HighlightCategory{entities.HighlightCategoryDataPoint}},
+		{"Make sure that at least 80 percent of your time goes to deep work.", []entities.HighlightCategory{entities.HighlightCategoryAdvice, entities.HighlightCategoryDataPoint}},
+		{"The old man looked out over the sea for a long while.", nil},
+		{"   ", nil},
+	}
+
+	classifier := NewRuleClassifier()
+	for _, tt := range tests {
+		got, err := classifier.Classify(context.Background(), tt.text)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.text)
+	}
+}
+
+func TestHTTPClassifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var req struct {
+			Text string `json:"text"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "Some text", req.Text)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"categories": ["quote", "joke", "data_point"]}`))
+	}))
+	defer server.Close()
+
+	classifier := NewHTTPClassifier(server.URL, "secret", 0)
+	got, err := classifier.Classify(context.Background(), "Some text")
+	require.NoError(t, err)
+	// Unknown categories are ignored
+	assert.Equal(t, []entities.HighlightCategory{entities.HighlightCategoryQuote, entities.HighlightCategoryDataPoint}, got)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	_, err = NewHTTPClassifier(failing.URL, "", 0).Classify(context.Background(), "Some text")
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	classifier, err := New(config.Classifier{})
+	require.NoError(t, err)
+	assert.Nil(t, classifier)
+
+	classifier, err = New(config.Classifier{Backend: "rules"})
+	require.NoError(t, err)
+	assert.Equal(t, BackendRules, classifier.Name())
+
+	_, err = New(config.Classifier{Backend: "http"})
+	assert.Error(t, err)
+
+	_, err = New(config.Classifier{Backend: "magic"})
+	assert.Error(t, err)
+}
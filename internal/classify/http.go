@@ -0,0 +1,86 @@
+package classify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// HTTPClassifier sends highlight text to a classification web service. The
+// text is posted as JSON {"text": "..."}; the service responds with
+// {"categories": ["advice", ...]}. Categories the app does not know are ignored.
+type HTTPClassifier struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTPClassifier creates a classifier for the service at url. The token, if
+// set, is sent as a bearer token.
+func NewHTTPClassifier(url, token string, timeout time.Duration) *HTTPClassifier {
+	return &HTTPClassifier{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name implements Classifier.
+func (c *HTTPClassifier) Name() string {
+	return BackendHTTP
+}
+
+// Classify implements Classifier.
+func (c *HTTPClassifier) Classify(ctx context.Context, text string) ([]entities.HighlightCategory, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("classification service request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read classification service response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("classification service returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Categories []string `json:"categories"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("decode classification service response: %w", err)
+	}
+
+	var categories []entities.HighlightCategory
+	for _, name := range result.Categories {
+		if category := entities.HighlightCategory(name); category.Valid() {
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+// Compile-time interface check
+var _ Classifier = (*HTTPClassifier)(nil)
@@ -0,0 +1,75 @@
+package classify
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+var (
+	// Sentences opening with a verb in the imperative, e.g. "Write every day."
+	imperativeStart = regexp.MustCompile(`(?i)(?:^|[.!?:;]\s+)(?:always|never|don'?t|do not|avoid|try|remember|make|start|stop|focus|keep|take|write|read|ask|learn|practi[cs]e|build|choose|spend|say|let|be|do|use|find|think|treat|seek|give|forget|cultivate|embrace)\b`)
+
+	// Phrases recommending a course of action
+	adviceWords = regexp.MustCompile(`(?i)\b(?:you should|you must|you need to|you have to|you ought to|we should|one should|it'?s best to|it is best to|the key is to|the secret is|make sure|the trick is|the best way to|if you want to)\b`)
+
+	// "X is defined as ...", "X refers to ...", "X means ..."
+	definitionWords = regexp.MustCompile(`(?i)\b(?:is defined as|are defined as|refers to|is the term for|is called|are called|is known as|means that|denotes)\b`)
+
+	// A short subject followed by "is a"/"are the", e.g. "Entropy is a measure of ..."
+	definitionStart = regexp.MustCompile(`^\s*(?:(?:a|an|the)\s+)?[\p{L}\-']+(?:\s+[\p{L}\-']+){0,3}\s+(?:is|are)\s+(?:a|an|the|any)\s+`)
+
+	// Text wrapped in quotation marks, optionally followed by an attribution
+	quoted = regexp.MustCompile(`^\s*["“„«'‘].{10,}["”»'’][.,!?]?\s*(?:[—―–-]+\s*\S.*)?$`)
+
+	// A trailing attribution such as "— Marcus Aurelius"
+	attribution = regexp.MustCompile(`[—―–]\s*\p{Lu}[\p{L}.'\-]*(?:\s+\p{Lu}[\p{L}.'\-]*){0,3}\s*,?\s*$`)
+
+	// Speech verbs next to quotation marks, e.g. `as Seneca wrote, "..."`
+	reportedSpeech = regexp.MustCompile(`(?i)\b(?:said|says|wrote|writes|remarked|put it|observed|declared|once told)\b[,:]?\s*["“]`)
+
+	// Figures: percentages, money, large quantities, ratios and years
+	dataPoint = regexp.MustCompile(`(?i)\d+(?:[.,]\d+)?\s*(?:%|percent|per cent|percentage points?|million|billion|trillion|thousand|hundred|times|fold|x\b)|[$€£¥]\s?\d|\b\d+\s+(?:out of|in)\s+(?:every\s+)?\d+\b|\b(?:in|by|since|from|until|of)\s+(?:1[5-9]\d\d|20\d\d)\b`)
+)
+
+// RuleClassifier assigns categories with built-in patterns for English text.
+// It is quick and needs nothing installed, at the cost of missing subtler cases.
+type RuleClassifier struct{}
+
+// NewRuleClassifier creates a rule-based classifier
+func NewRuleClassifier() *RuleClassifier {
+	return &RuleClassifier{}
+}
+
+// Name implements Classifier.
+func (c *RuleClassifier) Name() string {
+	return BackendRules
+}
+
+// Classify implements Classifier.
+func (c *RuleClassifier) Classify(_ context.Context, text string) ([]entities.HighlightCategory, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return nil, nil
+	}
+
+	var categories []entities.HighlightCategory
+	if imperativeStart.MatchString(text) || adviceWords.MatchString(text) {
+		categories = append(categories, entities.HighlightCategoryAdvice)
+	}
+	if definitionWords.MatchString(text) || definitionStart.MatchString(text) {
+		categories = append(categories, entities.HighlightCategoryDefinition)
+	}
+	if quoted.MatchString(text) || attribution.MatchString(text) || reportedSpeech.MatchString(text) {
+		categories = append(categories, entities.HighlightCategoryQuote)
+	}
+	if dataPoint.MatchString(text) {
+		categories = append(categories, entities.HighlightCategoryDataPoint)
+	}
+	return categories, nil
+}
+
+// Compile-time interface check
+var _ Classifier = (*RuleClassifier)(nil)
@@ -139,7 +139,7 @@ func (cmd *DropboxAuthCommand) runManualFlow(ctx context.Context, handler *oauth
 	}
 
 	// Exchange code for tokens
-	result, err := handler.CompleteWebFlow(ctx, code, codeVerifier, "", "", "")
+	result, err := handler.ExchangeCode(ctx, code, codeVerifier, "")
 	if err != nil {
 		return fmt.Errorf("failed to complete flow: %w", err)
 	}
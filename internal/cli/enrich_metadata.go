@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+// EnrichMetadataCommand fetches missing metadata (covers, publishers, years)
+// for the whole library. Progress is stored in the database, so an interrupted
+// run continues after the last finished book.
+type EnrichMetadataCommand struct {
+	DatabasePath string
+	Provider     string
+	Delay        time.Duration
+	Restart      bool
+	Verbose      bool
+}
+
+// NewEnrichMetadataCommand creates a new EnrichMetadataCommand
+func NewEnrichMetadataCommand() *EnrichMetadataCommand {
+	return &EnrichMetadataCommand{}
+}
+
+// ParseFlags parses command line flags
+func (cmd *EnrichMetadataCommand) ParseFlags(args []string) error {
+	fs := flag.NewFlagSet("enrich-metadata", flag.ExitOnError)
+
+	defaultDBPath := config.DefaultDatabasePath
+	if envPath := os.Getenv("DATABASE_PATH"); envPath != "" {
+		defaultDBPath = envPath
+	}
+	fs.StringVar(&cmd.DatabasePath, "db", defaultDBPath, "Path to the database file")
+	fs.StringVar(&cmd.Provider, "provider", metadata.ProviderOpenLibrary, "Metadata provider: "+strings.Join(metadata.Providers, ", "))
+	fs.DurationVar(&cmd.Delay, "delay", time.Second, "Minimum pause between books, to go easy on the provider")
+	fs.BoolVar(&cmd.Restart, "restart", false, "Start from the first book instead of resuming an interrupted run")
+	fs.BoolVar(&cmd.Verbose, "verbose", false, "Print the result for every book")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s enrich-metadata [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Fetch missing covers, publishers and publication years for all books.\n\n")
+		fmt.Fprintf(os.Stderr, "Interrupting the command (Ctrl+C) keeps its position; the next run\n")
+		fmt.Fprintf(os.Stderr, "resumes after the last finished book unless -restart is given.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s enrich-metadata\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s enrich-metadata -delay 3s -verbose\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s enrich-metadata -restart\n", os.Args[0])
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.Delay < 0 {
+		return fmt.Errorf("-delay must not be negative")
+	}
+
+	return nil
+}
+
+// Run executes the enrichment
+func (cmd *EnrichMetadataCommand) Run() error {
+	provider, err := metadata.NewProvider(cmd.Provider)
+	if err != nil {
+		return err
+	}
+
+	absDBPath, err := filepath.Abs(cmd.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for database: %w", err)
+	}
+	if _, err := os.Stat(absDBPath); err != nil {
+		return fmt.Errorf("database not found: %s", absDBPath)
+	}
+
+	db, err := database.NewDatabase(absDBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	enricher := metadata.NewEnricher(provider, database.NewMetadataUpdater(db))
+	enricher.SetProgressReporter(database.NewMetadataSyncProgress(db))
+	enricher.SetCoverSources(metadata.DefaultCoverSources()...)
+	enricher.SetReviewQueue(db)
+
+	var resumeAfterID uint
+	if progress, err := db.GetSyncProgress(entities.SyncTypeMetadata); err == nil && !cmd.Restart {
+		resumeAfterID = progress.LastItemID
+	}
+	if resumeAfterID > 0 {
+		fmt.Printf("Resuming after book #%d (use -restart to start over)\n", resumeAfterID)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	bar := newProgressBar(os.Stderr)
+	result, err := enricher.EnrichMissing(ctx, metadata.BulkEnrichmentOptions{
+		ResumeAfterID: resumeAfterID,
+		Delay:         cmd.Delay,
+		OnBook: func(done, total int, book entities.Book, bookErr error) {
+			// A book cut short by Ctrl+C is retried on the next run
+			if ctx.Err() == nil {
+				if err := db.SetSyncProgressLastItem(entities.SyncTypeMetadata, book.ID); err != nil {
+					bar.Printf("warning: failed to save progress: %v", err)
+				}
+			}
+			if bookErr != nil {
+				bar.Printf("[ERROR] %s: %v", book.Title, bookErr)
+			} else if cmd.Verbose {
+				bar.Printf("[OK] %s", book.Title)
+			}
+			bar.Update(done, total, book.Title)
+		},
+	})
+	bar.Done()
+
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("\nInterrupted. Run the command again to resume.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// The whole list was processed, so the next run starts from the beginning
+	if err := db.SetSyncProgressLastItem(entities.SyncTypeMetadata, 0); err != nil {
+		return fmt.Errorf("failed to reset progress: %w", err)
+	}
+
+	fmt.Println("\n=== Metadata Enrichment Summary ===")
+	fmt.Printf("Books checked: %d\n", result.TotalBooks)
+	fmt.Printf("Enriched: %d\n", result.Enriched)
+	fmt.Printf("Unchanged: %d\n", result.Skipped)
+	fmt.Printf("Needs review: %d\n", result.NeedsReview)
+	fmt.Printf("Failed: %d\n", result.Failed)
+	return nil
+}
+
+// progressBar draws a single updating line on a terminal, e.g.
+// [########------------] 12/30 The Title
+type progressBar struct {
+	out     io.Writer
+	width   int
+	drawn   bool
+	lastLen int
+}
+
+func newProgressBar(out io.Writer) *progressBar {
+	return &progressBar{out: out, width: 30}
+}
+
+// Update redraws the bar
+func (b *progressBar) Update(done, total int, label string) {
+	filled := 0
+	if total > 0 {
+		filled = done * b.width / total
+	}
+	if runes := []rune(label); len(runes) > 40 {
+		label = string(runes[:39]) + "…"
+	}
+	line := fmt.Sprintf("[%s%s] %d/%d %s", strings.Repeat("#", filled), strings.Repeat("-", b.width-filled), done, total, label)
+	padding := ""
+	if n := utf8.RuneCountInString(line); n < b.lastLen {
+		padding = strings.Repeat(" ", b.lastLen-n)
+	}
+	fmt.Fprintf(b.out, "\r%s%s", line, padding)
+	b.drawn = true
+	b.lastLen = utf8.RuneCountInString(line)
+}
+
+// Printf prints a message on its own line above the bar
+func (b *progressBar) Printf(format string, args ...any) {
+	b.clear()
+	fmt.Fprintf(b.out, format+"\n", args...)
+}
+
+// Done moves past the bar so later output starts on a fresh line
+func (b *progressBar) Done() {
+	if b.drawn {
+		fmt.Fprintln(b.out)
+		b.drawn = false
+		b.lastLen = 0
+	}
+}
+
+func (b *progressBar) clear() {
+	if b.drawn {
+		fmt.Fprintf(b.out, "\r%s\r", strings.Repeat(" ", b.lastLen))
+		b.drawn = false
+		b.lastLen = 0
+	}
+}
@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/scheduler"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+)
+
+// ExportTargetsCommand manages named export targets and runs them without the
+// server. Schedules set here are picked up the next time the server starts.
+type ExportTargetsCommand struct {
+	Subcommand   string
+	DatabasePath string
+	Name         string
+	All          bool
+	Target       entities.ExportTarget
+	tags         string
+	collections  string
+	sources      string
+	since        string
+
+	// Out receives results; diagnostics go to stderr
+	Out io.Writer
+}
+
+// NewExportTargetsCommand creates a new ExportTargetsCommand
+func NewExportTargetsCommand() *ExportTargetsCommand {
+	return &ExportTargetsCommand{Out: os.Stdout}
+}
+
+// ParseFlags parses the subcommand and its flags
+func (cmd *ExportTargetsCommand) ParseFlags(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		printExportTargetsUsage()
+		return fmt.Errorf("subcommand required: list, add, remove or run")
+	}
+	cmd.Subcommand = args[0]
+
+	fs := flag.NewFlagSet("export-targets "+cmd.Subcommand, flag.ExitOnError)
+
+	defaultDBPath := config.DefaultDatabasePath
+	if envPath := os.Getenv("DATABASE_PATH"); envPath != "" {
+		defaultDBPath = envPath
+	}
+	fs.StringVar(&cmd.DatabasePath, "db", defaultDBPath, "Path to the database file")
+
+	switch cmd.Subcommand {
+	case "list":
+	case "add":
+		fs.StringVar(&cmd.Target.Name, "name", "", "Unique name of the target (required)")
+		fs.StringVar(&cmd.Target.Path, "path", "", "Directory to export to (required)")
+		fs.StringVar(&cmd.Target.Format, "format", exporters.FormatMarkdown, "Export format: "+strings.Join(exporters.Formats, ", "))
+		fs.BoolVar(&cmd.Target.FavoritesOnly, "favorites", false, "Only export favourite highlights")
+		fs.StringVar(&cmd.tags, "tags", "", "Comma-separated tags; only books or highlights with any of them are exported")
+		fs.StringVar(&cmd.collections, "collections", "", "Comma-separated collections; only books in any of them are exported")
+		fs.StringVar(&cmd.sources, "sources", "", "Comma-separated sources; only highlights from them are exported")
+		fs.StringVar(&cmd.since, "since", "", "Only export highlights made on or after this date (YYYY-MM-DD)")
+		fs.BoolVar(&cmd.Target.IncludeVocabulary, "vocabulary", false, "Also export the vocabulary list")
+		fs.StringVar(&cmd.Target.Schedule, "schedule", "", "Cron schedule for the server to run the target on (empty for on demand only)")
+	case "remove":
+		fs.StringVar(&cmd.Name, "name", "", "Name of the target to remove (required)")
+	case "run":
+		fs.StringVar(&cmd.Name, "name", "", "Name of the target to run")
+		fs.BoolVar(&cmd.All, "all", false, "Run every target")
+	default:
+		printExportTargetsUsage()
+		return fmt.Errorf("unknown subcommand: %s", cmd.Subcommand)
+	}
+
+	fs.Usage = func() {
+		printExportTargetsUsage()
+		fmt.Fprintf(os.Stderr, "\nOptions for %s:\n", cmd.Subcommand)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch cmd.Subcommand {
+	case "add":
+		cmd.Target.Tags = splitList(cmd.tags)
+		cmd.Target.Collections = splitList(cmd.collections)
+		cmd.Target.Sources = splitList(cmd.sources)
+		since, err := parseSinceDate(cmd.since)
+		if err != nil {
+			return err
+		}
+		cmd.Target.Since = since
+		if cmd.Target.Path != "" {
+			absPath, err := filepath.Abs(cmd.Target.Path)
+			if err != nil {
+				return fmt.Errorf("failed to get absolute path for -path: %w", err)
+			}
+			cmd.Target.Path = absPath
+		}
+		if err := exporters.ValidateTarget(&cmd.Target); err != nil {
+			return err
+		}
+		if cmd.Target.Schedule != "" {
+			if err := settingsstore.ValidateCronSchedule(cmd.Target.Schedule); err != nil {
+				return fmt.Errorf("invalid cron schedule: %w", err)
+			}
+		}
+	case "remove":
+		if cmd.Name == "" {
+			return fmt.Errorf("required flag -name not provided")
+		}
+	case "run":
+		if (cmd.Name == "") == !cmd.All {
+			return fmt.Errorf("pass either -name or -all")
+		}
+	}
+
+	return nil
+}
+
+func printExportTargetsUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s export-targets <list|add|remove|run> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Manage named export targets, each with its own path, format and filters.\n\n")
+	fmt.Fprintf(os.Stderr, "Subcommands:\n")
+	fmt.Fprintf(os.Stderr, "  list    List export targets and their last run\n")
+	fmt.Fprintf(os.Stderr, "  add     Add an export target\n")
+	fmt.Fprintf(os.Stderr, "  remove  Remove an export target (exported files are kept)\n")
+	fmt.Fprintf(os.Stderr, "  run     Export to one target, or to all of them\n")
+	fmt.Fprintf(os.Stderr, "\nExamples:\n")
+	fmt.Fprintf(os.Stderr, "  %s export-targets add -name vault -path ~/Obsidian/Highlights -schedule \"0 * * * *\"\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s export-targets add -name logseq -path ~/Logseq/graph -format logseq -favorites\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s export-targets run -all\n", os.Args[0])
+}
+
+// Run executes the subcommand
+func (cmd *ExportTargetsCommand) Run() error {
+	absDBPath, err := filepath.Abs(cmd.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for database: %w", err)
+	}
+	if _, err := os.Stat(absDBPath); err != nil {
+		return fmt.Errorf("database not found: %s", absDBPath)
+	}
+
+	db, err := database.NewDatabase(absDBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	switch cmd.Subcommand {
+	case "list":
+		return cmd.list(db)
+	case "add":
+		if _, err := db.GetExportTargetByName(cmd.Target.Name); err == nil {
+			return fmt.Errorf("an export target named %s already exists", cmd.Target.Name)
+		}
+		if err := db.CreateExportTarget(&cmd.Target); err != nil {
+			return fmt.Errorf("failed to save export target: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Added export target %s (%s to %s)\n", cmd.Target.Name, cmd.Target.Format, cmd.Target.Path)
+		return nil
+	case "remove":
+		target, err := findExportTarget(db, cmd.Name)
+		if err != nil {
+			return err
+		}
+		if err := db.DeleteExportTarget(target.ID); err != nil {
+			return fmt.Errorf("failed to remove export target: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Removed export target %s\n", target.Name)
+		return nil
+	default:
+		return cmd.run(db)
+	}
+}
+
+func (cmd *ExportTargetsCommand) list(db *database.Database) error {
+	targets, err := db.ListExportTargets()
+	if err != nil {
+		return fmt.Errorf("failed to load export targets: %w", err)
+	}
+	if len(targets) == 0 {
+		fmt.Fprintf(os.Stderr, "No export targets configured\n")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "NAME\tFORMAT\tPATH\tFILTERS\tSCHEDULE\tLAST RUN\n")
+	for _, target := range targets {
+		var filters []string
+		if target.FavoritesOnly {
+			filters = append(filters, "favourites")
+		}
+		if len(target.Tags) > 0 {
+			filters = append(filters, "tags: "+strings.Join(target.Tags, ", "))
+		}
+		if len(target.Collections) > 0 {
+			filters = append(filters, "collections: "+strings.Join(target.Collections, ", "))
+		}
+		if len(target.Sources) > 0 {
+			filters = append(filters, "sources: "+strings.Join(target.Sources, ", "))
+		}
+		if target.Since != nil {
+			filters = append(filters, "since "+target.Since.Format("2006-01-02"))
+		}
+		lastRun := "never"
+		if target.LastRunAt != nil {
+			lastRun = fmt.Sprintf("%s (%s)", target.LastRunAt.Format("2006-01-02 15:04"), target.LastStatus)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", target.Name, target.Format, target.Path,
+			orDash(strings.Join(filters, "; ")), orDash(target.Schedule), lastRun)
+	}
+	return w.Flush()
+}
+
+func (cmd *ExportTargetsCommand) run(db *database.Database) error {
+	runner := scheduler.NewExportTargetScheduler(db, nil)
+
+	var runs []scheduler.ExportTargetRun
+	if cmd.All {
+		all, err := runner.RunAll()
+		if err != nil {
+			return fmt.Errorf("failed to load export targets: %w", err)
+		}
+		runs = all
+	} else {
+		target, err := findExportTarget(db, cmd.Name)
+		if err != nil {
+			return err
+		}
+		run, err := runner.RunTarget(target.ID)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+	}
+
+	failed := 0
+	for _, run := range runs {
+		if run.Error != "" {
+			failed++
+			fmt.Fprintf(os.Stderr, "%s: failed: %s\n", run.Name, run.Error)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: exported %d books, %d highlights\n", run.Name, run.Result.BooksProcessed, run.Result.HighlightsProcessed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d export targets failed", failed, len(runs))
+	}
+	return nil
+}
+
+func findExportTarget(db *database.Database, name string) (*entities.ExportTarget, error) {
+	target, err := db.GetExportTargetByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("no export target named %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load export target: %w", err)
+	}
+	return target, nil
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
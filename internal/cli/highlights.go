@@ -0,0 +1,379 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+)
+
+// Output formats for the highlights command
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+	FormatLogseq   = "logseq"
+	FormatOrg      = "org"
+)
+
+// HighlightsCommand queries the database directly, so scripts and cron jobs
+// can use the library without the HTTP server running.
+type HighlightsCommand struct {
+	Subcommand    string
+	DatabasePath  string
+	Query         string
+	Book          string
+	Limit         int
+	Count         int
+	Format        string
+	OutputDir     string
+	Filter        exporters.ExportFilter
+	FilenameStyle string
+	tags          string
+	collections   string
+	sources       string
+	since         string
+
+	// Out receives results; diagnostics go to stderr so output can be piped
+	Out io.Writer
+}
+
+// NewHighlightsCommand creates a new HighlightsCommand
+func NewHighlightsCommand() *HighlightsCommand {
+	return &HighlightsCommand{Out: os.Stdout}
+}
+
+// HighlightRecord is a highlight with its book, as printed in JSON output
+type HighlightRecord struct {
+	ID            uint      `json:"id"`
+	BookID        uint      `json:"book_id"`
+	BookTitle     string    `json:"book_title"`
+	BookAuthor    string    `json:"book_author"`
+	Text          string    `json:"text"`
+	Note          string    `json:"note,omitempty"`
+	Chapter       string    `json:"chapter,omitempty"`
+	Location      string    `json:"location,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	IsFavorite    bool      `json:"is_favorite"`
+	HighlightedAt time.Time `json:"highlighted_at"`
+}
+
+// ParseFlags parses the subcommand and its flags
+func (cmd *HighlightsCommand) ParseFlags(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		printHighlightsUsage()
+		return fmt.Errorf("subcommand required: search, random or export")
+	}
+	cmd.Subcommand = args[0]
+
+	fs := flag.NewFlagSet("highlights "+cmd.Subcommand, flag.ExitOnError)
+
+	// DATABASE_PATH is shared with the server so cron jobs use the same library
+	defaultDBPath := config.DefaultDatabasePath
+	if envPath := os.Getenv("DATABASE_PATH"); envPath != "" {
+		defaultDBPath = envPath
+	}
+	fs.StringVar(&cmd.DatabasePath, "db", defaultDBPath, "Path to the database file")
+	fs.StringVar(&cmd.Book, "book", "", "Only include books whose title or author contains this text")
+
+	switch cmd.Subcommand {
+	case "search":
+		fs.IntVar(&cmd.Limit, "limit", 20, "Maximum number of highlights to print (0 for all)")
+		fs.StringVar(&cmd.Format, "format", FormatText, "Output format: text or json")
+	case "random":
+		fs.IntVar(&cmd.Count, "n", 1, "Number of random highlights to print")
+		fs.StringVar(&cmd.Format, "format", FormatText, "Output format: text or json")
+	case "export":
+		fs.StringVar(&cmd.Format, "format", FormatMarkdown, "Output format: markdown, logseq, org or json")
+		fs.StringVar(&cmd.OutputDir, "output", "", "Output directory for exported files (required unless json)")
+		fs.StringVar(&cmd.tags, "tags", "", "Comma-separated tags; only books or highlights with any of them are exported")
+		fs.StringVar(&cmd.collections, "collections", "", "Comma-separated collections; only books in any of them are exported")
+		fs.StringVar(&cmd.sources, "sources", "", "Comma-separated sources; only highlights from them are exported, e.g. kindle,moonreader")
+		fs.BoolVar(&cmd.Filter.FavoritesOnly, "favorites", false, "Only export favourite highlights")
+		fs.StringVar(&cmd.FilenameStyle, "filename-style", "", "File names: title or slug (default: the export_filename_style setting)")
+		fs.StringVar(&cmd.since, "since", "", "Only export highlights made on or after this date (YYYY-MM-DD)")
+	default:
+		printHighlightsUsage()
+		return fmt.Errorf("unknown subcommand: %s", cmd.Subcommand)
+	}
+
+	fs.Usage = func() {
+		printHighlightsUsage()
+		fmt.Fprintf(os.Stderr, "\nOptions for %s:\n", cmd.Subcommand)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch cmd.Subcommand {
+	case "search":
+		cmd.Query = strings.TrimSpace(strings.Join(fs.Args(), " "))
+		if cmd.Query == "" {
+			return fmt.Errorf("search query required")
+		}
+		if cmd.Format != FormatText && cmd.Format != FormatJSON {
+			return fmt.Errorf("unsupported format for search: %s", cmd.Format)
+		}
+	case "random":
+		if cmd.Count < 1 {
+			return fmt.Errorf("-n must be at least 1")
+		}
+		if cmd.Format != FormatText && cmd.Format != FormatJSON {
+			return fmt.Errorf("unsupported format for random: %s", cmd.Format)
+		}
+	case "export":
+		if cmd.Format != FormatJSON && !slices.Contains(exporters.Formats, cmd.Format) {
+			return fmt.Errorf("unsupported format for export: %s", cmd.Format)
+		}
+		if cmd.Format != FormatJSON && cmd.OutputDir == "" {
+			return fmt.Errorf("required flag -output not provided")
+		}
+		if cmd.FilenameStyle != "" && !slices.Contains(exporters.FilenameStyles, cmd.FilenameStyle) {
+			return fmt.Errorf("unsupported filename style: %s", cmd.FilenameStyle)
+		}
+		cmd.Filter.Tags = splitList(cmd.tags)
+		cmd.Filter.Collections = splitList(cmd.collections)
+		cmd.Filter.Sources = splitList(cmd.sources)
+		since, err := parseSinceDate(cmd.since)
+		if err != nil {
+			return err
+		}
+		cmd.Filter.Since = since
+	}
+
+	return nil
+}
+
+func printHighlightsUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s highlights <search|random|export> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Query the highlights database without running the server.\n\n")
+	fmt.Fprintf(os.Stderr, "Subcommands:\n")
+	fmt.Fprintf(os.Stderr, "  search <query>  Find highlights whose text or note contains the query\n")
+	fmt.Fprintf(os.Stderr, "  random          Print random highlights\n")
+	fmt.Fprintf(os.Stderr, "  export          Export books with their highlights as markdown, Logseq, org-mode or JSON\n")
+	fmt.Fprintf(os.Stderr, "\nExamples:\n")
+	fmt.Fprintf(os.Stderr, "  %s highlights search -book Meditations \"power over your mind\"\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s highlights random -n 3 -format json\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s highlights export -output ~/Obsidian/Highlights\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s highlights export -format logseq -output ~/Logseq/graph\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s highlights export -format json > highlights.json\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s highlights export -output ~/Obsidian -favorites -sources kindle -since 2024-01-01\n", os.Args[0])
+}
+
+// Run executes the subcommand
+func (cmd *HighlightsCommand) Run() error {
+	absDBPath, err := filepath.Abs(cmd.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for database: %w", err)
+	}
+	if _, err := os.Stat(absDBPath); err != nil {
+		return fmt.Errorf("database not found: %s", absDBPath)
+	}
+
+	db, err := database.NewDatabase(absDBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	switch cmd.Subcommand {
+	case "search":
+		return cmd.runSearch(db)
+	case "random":
+		return cmd.runRandom(db)
+	default:
+		return cmd.runExport(db)
+	}
+}
+
+func (cmd *HighlightsCommand) runSearch(db *database.Database) error {
+	highlights, err := db.SearchHighlights(cmd.Query, 0)
+	if err != nil {
+		return fmt.Errorf("failed to search highlights: %w", err)
+	}
+
+	highlights = cmd.filterByBook(highlights)
+	if cmd.Limit > 0 && len(highlights) > cmd.Limit {
+		highlights = highlights[:cmd.Limit]
+	}
+	return cmd.printHighlights(highlights)
+}
+
+func (cmd *HighlightsCommand) runRandom(db *database.Database) error {
+	// Sample from the whole library unless a book filter narrows it down
+	limit := cmd.Count
+	if cmd.Book != "" {
+		limit = 0
+	}
+	highlights, err := db.GetRandomHighlights(limit)
+	if err != nil {
+		return fmt.Errorf("failed to get random highlights: %w", err)
+	}
+
+	highlights = cmd.filterByBook(highlights)
+	if len(highlights) > cmd.Count {
+		highlights = highlights[:cmd.Count]
+	}
+	return cmd.printHighlights(highlights)
+}
+
+func (cmd *HighlightsCommand) runExport(db *database.Database) error {
+	var books []entities.Book
+	var err error
+	if cmd.Book != "" {
+		books, err = db.SearchBooks(cmd.Book)
+	} else {
+		books, err = db.GetAllBooks()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load books: %w", err)
+	}
+
+	if cmd.Format == FormatJSON {
+		encoder := json.NewEncoder(cmd.Out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(cmd.Filter.Apply(books))
+	}
+
+	absOutputDir, err := filepath.Abs(cmd.OutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for output: %w", err)
+	}
+
+	exporter, err := exporters.NewFileExporter(cmd.Format, absOutputDir)
+	if err != nil {
+		return err
+	}
+	exporter.SetFilter(cmd.Filter)
+	settings := settingsstore.New(db)
+	filenameStyle := cmd.FilenameStyle
+	if filenameStyle == "" {
+		filenameStyle = settings.GetExportFilenameStyle()
+	}
+	exporter.SetFilenameStyle(filenameStyle)
+	exporter.SetLanguage(settings.GetDefaultLanguage())
+	result, err := exporter.Export(books)
+	if err != nil {
+		return fmt.Errorf("failed to export to %s: %w", cmd.Format, err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d books to %s\n", result.BooksProcessed, absOutputDir)
+	if result.BooksSkipped > 0 || result.HighlightsSkipped > 0 {
+		fmt.Fprintf(os.Stderr, "Filtered out %d books, %d highlights\n", result.BooksSkipped, result.HighlightsSkipped)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// parseSinceDate parses a YYYY-MM-DD flag value; empty means no date
+func parseSinceDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	since, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -since date %q: expected YYYY-MM-DD", value)
+	}
+	return &since, nil
+}
+
+// filterByBook keeps highlights whose book title or author contains the -book text
+func (cmd *HighlightsCommand) filterByBook(highlights []entities.Highlight) []entities.Highlight {
+	if cmd.Book == "" {
+		return highlights
+	}
+	query := strings.ToLower(cmd.Book)
+	filtered := highlights[:0]
+	for _, h := range highlights {
+		if strings.Contains(strings.ToLower(h.Book.Title), query) || strings.Contains(strings.ToLower(h.Book.Author), query) {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered
+}
+
+func (cmd *HighlightsCommand) printHighlights(highlights []entities.Highlight) error {
+	if cmd.Format == FormatJSON {
+		records := make([]HighlightRecord, 0, len(highlights))
+		for _, h := range highlights {
+			records = append(records, newHighlightRecord(h))
+		}
+		encoder := json.NewEncoder(cmd.Out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+
+	for i, h := range highlights {
+		if i > 0 {
+			fmt.Fprintln(cmd.Out, "---")
+		}
+		fmt.Fprintln(cmd.Out, h.Text)
+		if h.Note != "" {
+			fmt.Fprintf(cmd.Out, "Note: %s\n", h.Note)
+		}
+		source := h.Book.Title
+		if h.Book.Author != "" {
+			source += ", " + h.Book.Author
+		}
+		if location := formatLocation(h); location != "" {
+			source += " (" + location + ")"
+		}
+		fmt.Fprintf(cmd.Out, "— %s\n", source)
+	}
+	return nil
+}
+
+func newHighlightRecord(h entities.Highlight) HighlightRecord {
+	record := HighlightRecord{
+		ID:            h.ID,
+		BookID:        h.BookID,
+		BookTitle:     h.Book.Title,
+		BookAuthor:    h.Book.Author,
+		Text:          h.Text,
+		Note:          h.Note,
+		Chapter:       h.Chapter,
+		Location:      formatLocation(h),
+		IsFavorite:    h.IsFavorite,
+		HighlightedAt: h.HighlightedAt,
+	}
+	for _, tag := range h.Tags {
+		record.Tags = append(record.Tags, tag.Name)
+	}
+	return record
+}
+
+func formatLocation(h entities.Highlight) string {
+	if h.LocationValue == 0 {
+		return ""
+	}
+	switch h.LocationType {
+	case entities.LocationTypePage:
+		return fmt.Sprintf("page %d", h.LocationValue)
+	case entities.LocationTypeLocation:
+		return fmt.Sprintf("location %d", h.LocationValue)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/covers"
+	"github.com/mrlokans/assistant/internal/storage"
+	"github.com/mrlokans/assistant/internal/storage/providers/s3"
+)
+
+// MigrateCoversCommand moves cached cover images from the local cover
+// directory to the configured object storage, keeping their layout.
+type MigrateCoversCommand struct {
+	CoversDir string
+	Keep      bool
+	DryRun    bool
+}
+
+// NewMigrateCoversCommand creates a new MigrateCoversCommand
+func NewMigrateCoversCommand() *MigrateCoversCommand {
+	return &MigrateCoversCommand{}
+}
+
+// ParseFlags parses command line flags
+func (cmd *MigrateCoversCommand) ParseFlags(args []string) error {
+	fs := flag.NewFlagSet("migrate-covers", flag.ExitOnError)
+
+	defaultDBPath := config.DefaultDatabasePath
+	if envPath := os.Getenv("DATABASE_PATH"); envPath != "" {
+		defaultDBPath = envPath
+	}
+	fs.StringVar(&cmd.CoversDir, "dir", filepath.Join(filepath.Dir(defaultDBPath), "covers"), "Local cover directory to migrate")
+	fs.BoolVar(&cmd.Keep, "keep", false, "Keep the local files after copying them")
+	fs.BoolVar(&cmd.DryRun, "dry-run", false, "List the covers that would be migrated without copying them")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s migrate-covers [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Move cached book covers from the local cover directory to the object\n")
+		fmt.Fprintf(os.Stderr, "storage configured with STORAGE_BACKEND=s3 and the S3_* settings.\n\n")
+		fmt.Fprintf(os.Stderr, "The command can be run again after an interruption; covers already\n")
+		fmt.Fprintf(os.Stderr, "in storage are skipped.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  STORAGE_BACKEND=s3 S3_BUCKET=highlights %s migrate-covers\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s migrate-covers -dir ./data/covers -keep\n", os.Args[0])
+	}
+
+	return fs.Parse(args)
+}
+
+// Run executes the migration
+func (cmd *MigrateCoversCommand) Run() error {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return err
+	}
+	store, err := s3.FromConfig(cfg.Storage)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("no object storage configured: set STORAGE_BACKEND=%s and the S3_* settings", s3.Backend)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var migrated, skipped, failed int
+	err = filepath.WalkDir(cmd.CoversDir, func(localPath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !entry.Type().IsRegular() || !strings.HasPrefix(entry.Name(), "cover_") || strings.HasPrefix(entry.Name(), "cover_tmp_") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(cmd.CoversDir, localPath)
+		if err != nil {
+			return err
+		}
+		storePath := path.Join(covers.StorePrefix, filepath.ToSlash(rel))
+
+		if cmd.DryRun {
+			fmt.Printf("%s -> %s\n", localPath, storePath)
+			migrated++
+			return nil
+		}
+
+		exists, err := store.Exists(ctx, storePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] %s: %v\n", rel, err)
+			failed++
+			return nil
+		}
+		if exists {
+			skipped++
+		} else {
+			if err := uploadFile(ctx, store, storePath, localPath); err != nil {
+				fmt.Fprintf(os.Stderr, "[ERROR] %s: %v\n", rel, err)
+				failed++
+				return nil
+			}
+			migrated++
+		}
+
+		if !cmd.Keep {
+			if err := os.Remove(localPath); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to remove %s: %v\n", localPath, err)
+			}
+		}
+		return nil
+	})
+	if ctx.Err() != nil {
+		fmt.Println("\nInterrupted. Run the command again to continue.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cover directory: %w", err)
+	}
+
+	if cmd.DryRun {
+		fmt.Printf("%d covers would be migrated\n", migrated)
+		return nil
+	}
+	fmt.Printf("Migrated %d covers, %d already in storage, %d failed\n", migrated, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d covers could not be migrated", failed)
+	}
+	return nil
+}
+
+func uploadFile(ctx context.Context, store storage.Client, storePath, localPath string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return store.Upload(ctx, storePath, file)
+}
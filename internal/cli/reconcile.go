@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/parsers"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+)
+
+// ReconcileCommand compares the markdown vault with the database and fixes
+// the differences in either direction. Books in the trash or permanently
+// deleted are never imported back from stale markdown files.
+type ReconcileCommand struct {
+	Directory    string
+	DatabasePath string
+	Import       bool // Import books found only in the vault
+	Export       bool // Write markdown files for books found only in the database
+	Interactive  bool // Ask before each fix
+
+	// In answers the confirmation prompts; Out receives the report
+	In  io.Reader
+	Out io.Writer
+}
+
+// NewReconcileCommand creates a new ReconcileCommand
+func NewReconcileCommand() *ReconcileCommand {
+	return &ReconcileCommand{In: os.Stdin, Out: os.Stdout}
+}
+
+// ParseFlags parses command line flags
+func (cmd *ReconcileCommand) ParseFlags(args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+
+	defaultDBPath := config.DefaultDatabasePath
+	if envPath := os.Getenv("DATABASE_PATH"); envPath != "" {
+		defaultDBPath = envPath
+	}
+	defaultDir := os.Getenv("OBSIDIAN_EXPORT_DIR")
+	if defaultDir == "" {
+		defaultDir = os.Getenv("OBSIDIAN_VAULT_DIR")
+	}
+	fs.StringVar(&cmd.Directory, "dir", defaultDir, "Markdown export directory (defaults to OBSIDIAN_EXPORT_DIR)")
+	fs.StringVar(&cmd.DatabasePath, "db", defaultDBPath, "Path to the database file")
+	fs.BoolVar(&cmd.Import, "import", false, "Import books that only exist as markdown files")
+	fs.BoolVar(&cmd.Export, "export", false, "Re-export books that have no markdown file")
+	fs.BoolVar(&cmd.Interactive, "interactive", false, "Ask before importing or exporting each book")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s reconcile [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Compare the markdown export directory with the database. Without -import\n")
+		fmt.Fprintf(os.Stderr, "or -export only a report is printed. Books in the trash or permanently\n")
+		fmt.Fprintf(os.Stderr, "deleted are reported but never imported again. Only markdown vaults are\n")
+		fmt.Fprintf(os.Stderr, "supported; exported files are named by the export_filename_style setting.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s reconcile -dir ~/Obsidian/Highlights\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s reconcile -dir ~/Obsidian/Highlights -import -export\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s reconcile -import -interactive\n", os.Args[0])
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.Directory == "" {
+		fs.Usage()
+		return fmt.Errorf("directory is required")
+	}
+	return nil
+}
+
+// Run compares the vault with the database, prints the differences and
+// applies the requested fixes.
+func (cmd *ReconcileCommand) Run() error {
+	if _, err := os.Stat(cmd.Directory); os.IsNotExist(err) {
+		return fmt.Errorf("directory does not exist: %s", cmd.Directory)
+	}
+	absDir, err := filepath.Abs(cmd.Directory)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	cmd.Directory = absDir
+
+	db, err := database.NewDatabase(cmd.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Printf("Error closing database: %v", err)
+		}
+	}()
+
+	// Only markdown files can be parsed back, and exports must match the
+	// format and file names of the files already in the vault
+	settings := settingsstore.New(db)
+	if format := settings.GetObsidianSyncFormat(); format != exporters.FormatMarkdown {
+		return fmt.Errorf("reconcile only supports markdown vaults, the export format is set to %s", format)
+	}
+
+	parser := parsers.NewMarkdownParser(cmd.Directory)
+	mdBooks, parseResult, err := parser.ParseAllMarkdownFilesRecursive(cmd.Directory)
+	if err != nil {
+		return fmt.Errorf("failed to parse markdown files: %w", err)
+	}
+	// Trashed books are not returned, so they are never exported again
+	dbBooks, err := db.GetAllBooks()
+	if err != nil {
+		return fmt.Errorf("failed to get books from database: %w", err)
+	}
+	comparison := parser.CompareWithDatabase(mdBooks, dbBooks)
+
+	fmt.Fprintf(cmd.Out, "Markdown: %d books (%d files failed to parse)\n", comparison.MarkdownBooks, parseResult.BooksFailed)
+	fmt.Fprintf(cmd.Out, "Database: %d books\n", comparison.DatabaseBooks)
+
+	mismatches := 0
+	for _, match := range comparison.Matches {
+		if match.HighlightsDiff == 0 {
+			continue
+		}
+		if mismatches == 0 {
+			fmt.Fprintf(cmd.Out, "\n=== Highlight count mismatches ===\n")
+		}
+		mismatches++
+		fmt.Fprintf(cmd.Out, "\"%s\" by %s: markdown %d, database %d\n",
+			match.Title, match.Author, match.MarkdownHighlights, match.DatabaseHighlights)
+	}
+
+	toImport, deleted, err := cmd.splitDeleted(db, comparison.OnlyInMarkdown)
+	if err != nil {
+		return err
+	}
+
+	if len(deleted) > 0 {
+		fmt.Fprintf(cmd.Out, "\n=== Only in markdown, deleted from the database (not imported) ===\n")
+		for _, book := range deleted {
+			fmt.Fprintf(cmd.Out, "\"%s\" by %s\n", book.Title, book.Author)
+		}
+	}
+
+	prompt := newConfirmPrompt(cmd.In, cmd.Out, cmd.Interactive)
+
+	if len(toImport) > 0 {
+		fmt.Fprintf(cmd.Out, "\n=== Only in markdown ===\n")
+		for _, book := range toImport {
+			fmt.Fprintf(cmd.Out, "\"%s\" by %s (%d highlights)\n", book.Title, book.Author, len(book.Highlights))
+		}
+		if cmd.Import {
+			if err := cmd.importBooks(db, prompt.filter(toImport, "Import")); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(comparison.OnlyInDatabase) > 0 {
+		fmt.Fprintf(cmd.Out, "\n=== Only in database ===\n")
+		for _, book := range comparison.OnlyInDatabase {
+			fmt.Fprintf(cmd.Out, "\"%s\" by %s (%d highlights)\n", book.Title, book.Author, len(book.Highlights))
+		}
+		if cmd.Export {
+			if err := cmd.exportBooks(settings, prompt.filter(comparison.OnlyInDatabase, "Export")); err != nil {
+				return err
+			}
+		}
+	}
+
+	if mismatches == 0 && len(toImport) == 0 && len(deleted) == 0 && len(comparison.OnlyInDatabase) == 0 {
+		fmt.Fprintf(cmd.Out, "\nThe markdown files and the database are in sync\n")
+	} else if !cmd.Import && !cmd.Export {
+		fmt.Fprintf(cmd.Out, "\nRun with -import and/or -export to fix the differences\n")
+	}
+	return nil
+}
+
+// splitDeleted separates markdown-only books that are in the trash or were
+// permanently deleted from those that may be imported.
+func (cmd *ReconcileCommand) splitDeleted(db *database.Database, books []entities.Book) ([]entities.Book, []entities.Book, error) {
+	trashed, err := db.GetTrashedBooks()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get trashed books: %w", err)
+	}
+	inTrash := make(map[string]bool, len(trashed))
+	for _, t := range trashed {
+		inTrash[reconcileKey(t.Book.Title, t.Book.Author)] = true
+	}
+
+	var toImport, deleted []entities.Book
+	for _, book := range books {
+		if inTrash[reconcileKey(book.Title, book.Author)] {
+			deleted = append(deleted, book)
+			continue
+		}
+		tombstoned, err := db.IsBookDeleted(book.Title, book.Author, book.UserID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check if book was deleted: %w", err)
+		}
+		if tombstoned {
+			deleted = append(deleted, book)
+			continue
+		}
+		toImport = append(toImport, book)
+	}
+	return toImport, deleted, nil
+}
+
+func (cmd *ReconcileCommand) importBooks(db *database.Database, books []entities.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	markdownSource := entities.Source{Name: "markdown", DisplayName: "Markdown"}
+	if _, err := db.EnsureSource(markdownSource.Name, markdownSource.DisplayName); err != nil {
+		return fmt.Errorf("failed to create the markdown source: %w", err)
+	}
+	for i := range books {
+		if books[i].Source.Name == "" {
+			books[i].Source = markdownSource
+		}
+		if err := resolveTags(db, &books[i]); err != nil {
+			return err
+		}
+	}
+	// No export directory: the books are only saved, the files already exist
+	result, err := exporters.NewDatabaseMarkdownExporter(db, "").Export(books)
+	if err != nil {
+		return fmt.Errorf("failed to import books: %w", err)
+	}
+	fmt.Fprintf(cmd.Out, "Imported %d books with %d highlights (%d failed)\n",
+		result.BooksProcessed, result.HighlightsProcessed, result.BooksFailed)
+	return nil
+}
+
+// exportBooks writes the books with the same settings as the Obsidian sync.
+func (cmd *ReconcileCommand) exportBooks(settings *settingsstore.SettingsStore, books []entities.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	exporter := exporters.NewMarkdownExporter(cmd.Directory)
+	exporter.SetFilenameStyle(settings.GetExportFilenameStyle())
+	exporter.SetLanguage(settings.GetDefaultLanguage())
+	result, err := exporter.Export(books)
+	if err != nil {
+		return fmt.Errorf("failed to export books: %w", err)
+	}
+	fmt.Fprintf(cmd.Out, "Exported %d books to %s\n", result.BooksProcessed, cmd.Directory)
+	return nil
+}
+
+// resolveTags points the tags parsed from markdown at existing tags, so
+// saving the book does not create duplicates.
+func resolveTags(db *database.Database, book *entities.Book) error {
+	resolve := func(tags []entities.Tag) error {
+		for i := range tags {
+			tag, err := db.GetOrCreateTag(tags[i].Name, book.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to resolve tag %q: %w", tags[i].Name, err)
+			}
+			tags[i] = *tag
+		}
+		return nil
+	}
+	if err := resolve(book.Tags); err != nil {
+		return err
+	}
+	for i := range book.Highlights {
+		if err := resolve(book.Highlights[i].Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reconcileKey(title, author string) string {
+	return strings.ToLower(strings.TrimSpace(title)) + "|" + strings.ToLower(strings.TrimSpace(author))
+}
+
+// confirmPrompt asks y/N for each book; "a" accepts the rest of the books of
+// the action and "q" declines everything left.
+type confirmPrompt struct {
+	in          *bufio.Reader
+	out         io.Writer
+	interactive bool
+	all         bool
+	quit        bool
+}
+
+func newConfirmPrompt(in io.Reader, out io.Writer, interactive bool) *confirmPrompt {
+	return &confirmPrompt{in: bufio.NewReader(in), out: out, interactive: interactive}
+}
+
+// filter returns the books confirmed for the action.
+func (p *confirmPrompt) filter(books []entities.Book, action string) []entities.Book {
+	if !p.interactive {
+		return books
+	}
+	p.all = false
+	var confirmed []entities.Book
+	for _, book := range books {
+		if p.quit {
+			break
+		}
+		if p.all || p.confirm(fmt.Sprintf("%s \"%s\" by %s?", action, book.Title, book.Author)) {
+			confirmed = append(confirmed, book)
+		}
+	}
+	return confirmed
+}
+
+func (p *confirmPrompt) confirm(question string) bool {
+	fmt.Fprintf(p.out, "%s [y/N/a/q] ", question)
+	answer, err := p.in.ReadString('\n')
+	if err != nil && answer == "" {
+		// No more input: decline the rest
+		p.quit = true
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	case "a", "all":
+		p.all = true
+		return true
+	case "q", "quit":
+		p.quit = true
+	}
+	return false
+}
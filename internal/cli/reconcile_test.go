@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+)
+
+func setupReconcileDB(t *testing.T) (*database.Database, string) {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "reconcile.db")
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, dbPath
+}
+
+func reconcileBook(title, author string) entities.Book {
+	return entities.Book{
+		Title:      title,
+		Author:     author,
+		Source:     entities.Source{Name: "kindle", DisplayName: "Kindle"},
+		Highlights: []entities.Highlight{{Text: "A highlight from " + title, LocationValue: 1}},
+	}
+}
+
+// writeVault exports books as markdown into a new vault directory.
+func writeVault(t *testing.T, books ...entities.Book) string {
+	t.Helper()
+	dir := t.TempDir()
+	_, err := exporters.NewMarkdownExporter(dir).Export(books)
+	require.NoError(t, err)
+	return dir
+}
+
+func bookTitles(books []entities.Book) []string {
+	titles := make([]string, len(books))
+	for i, book := range books {
+		titles[i] = book.Title
+	}
+	return titles
+}
+
+func TestReconcileKey(t *testing.T) {
+	assert.Equal(t, reconcileKey("Dune", "Frank Herbert"), reconcileKey("  dune ", "FRANK HERBERT"))
+	assert.NotEqual(t, reconcileKey("Dune", "Frank Herbert"), reconcileKey("Dune Messiah", "Frank Herbert"))
+	// The separator keeps title and author apart
+	assert.NotEqual(t, reconcileKey("a", "b c"), reconcileKey("a b", "c"))
+}
+
+func TestReconcileCommand_SplitDeleted(t *testing.T) {
+	db, _ := setupReconcileDB(t)
+
+	trashed := reconcileBook("Trashed", "Author")
+	require.NoError(t, db.SaveBook(&trashed))
+	require.NoError(t, db.DeleteBook(trashed.ID))
+
+	purged := reconcileBook("Purged", "Author")
+	require.NoError(t, db.SaveBook(&purged))
+	require.NoError(t, db.DeleteBookPermanently(purged.ID, 0))
+
+	candidates := []entities.Book{
+		reconcileBook("trashed ", "author"),
+		reconcileBook("Purged", "Author"),
+		reconcileBook("New", "Author"),
+	}
+	toImport, deleted, err := (&ReconcileCommand{}).splitDeleted(db, candidates)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"New"}, bookTitles(toImport))
+	assert.Equal(t, []string{"trashed ", "Purged"}, bookTitles(deleted))
+}
+
+func TestReconcileCommand_ImportBooksReusesTags(t *testing.T) {
+	db, _ := setupReconcileDB(t)
+	existing, err := db.GetOrCreateTag("fiction", 0)
+	require.NoError(t, err)
+
+	book := reconcileBook("Dune", "Frank Herbert")
+	book.Source = entities.Source{}
+	book.Tags = []entities.Tag{{Name: "fiction"}}
+	book.Highlights[0].Tags = []entities.Tag{{Name: "fiction"}, {Name: "desert"}}
+
+	var out bytes.Buffer
+	cmd := &ReconcileCommand{Out: &out}
+	require.NoError(t, cmd.importBooks(db, []entities.Book{book}))
+	assert.Contains(t, out.String(), "Imported 1 books with 1 highlights")
+
+	tags, err := db.GetTagsForUser(0)
+	require.NoError(t, err)
+	assert.Len(t, tags, 2)
+
+	saved, err := db.GetAllBooks()
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, "markdown", saved[0].Source.Name)
+	require.Len(t, saved[0].Tags, 1)
+	assert.Equal(t, existing.ID, saved[0].Tags[0].ID)
+}
+
+func TestResolveTags(t *testing.T) {
+	db, _ := setupReconcileDB(t)
+	existing, err := db.GetOrCreateTag("history", 0)
+	require.NoError(t, err)
+
+	book := reconcileBook("SPQR", "Mary Beard")
+	book.Tags = []entities.Tag{{Name: "history"}}
+	book.Highlights[0].Tags = []entities.Tag{{Name: "rome"}}
+	require.NoError(t, resolveTags(db, &book))
+
+	assert.Equal(t, existing.ID, book.Tags[0].ID)
+	assert.NotZero(t, book.Highlights[0].Tags[0].ID)
+	assert.Equal(t, "rome", book.Highlights[0].Tags[0].Name)
+}
+
+func TestConfirmPrompt(t *testing.T) {
+	books := []entities.Book{
+		reconcileBook("One", "Author"),
+		reconcileBook("Two", "Author"),
+		reconcileBook("Three", "Author"),
+	}
+
+	tests := []struct {
+		name        string
+		interactive bool
+		input       string
+		expected    []string
+	}{
+		{"non-interactive accepts everything", false, "", []string{"One", "Two", "Three"}},
+		{"yes and no", true, "y\nn\nyes\n", []string{"One", "Three"}},
+		{"default is no", true, "\n\n\n", nil},
+		{"all accepts the rest", true, "n\na\n", []string{"Two", "Three"}},
+		{"quit declines the rest", true, "y\nq\ny\n", []string{"One"}},
+		{"end of input declines the rest", true, "y\n", []string{"One"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			prompt := newConfirmPrompt(strings.NewReader(tt.input), &out, tt.interactive)
+
+			confirmed := prompt.filter(books, "Import")
+
+			if tt.expected == nil {
+				assert.Empty(t, confirmed)
+			} else {
+				assert.Equal(t, tt.expected, bookTitles(confirmed))
+			}
+			if tt.interactive {
+				assert.Contains(t, out.String(), `Import "One" by Author? [y/N/a/q]`)
+			} else {
+				assert.Empty(t, out.String())
+			}
+		})
+	}
+
+	t.Run("all applies to one action only", func(t *testing.T) {
+		prompt := newConfirmPrompt(strings.NewReader("a\nn\nn\nn\n"), &bytes.Buffer{}, true)
+
+		assert.Len(t, prompt.filter(books, "Import"), 3)
+		assert.Empty(t, prompt.filter(books, "Export"))
+	})
+}
+
+func TestReconcileCommand_Run(t *testing.T) {
+	t.Run("reports differences without changing anything", func(t *testing.T) {
+		db, dbPath := setupReconcileDB(t)
+		dbOnly := reconcileBook("Database Only", "Author")
+		require.NoError(t, db.SaveBook(&dbOnly))
+		vault := writeVault(t, reconcileBook("Vault Only", "Author"))
+
+		var out bytes.Buffer
+		cmd := &ReconcileCommand{Directory: vault, DatabasePath: dbPath, In: strings.NewReader(""), Out: &out}
+		require.NoError(t, cmd.Run())
+
+		assert.Contains(t, out.String(), "=== Only in markdown ===\n\"Vault Only\" by Author")
+		assert.Contains(t, out.String(), "=== Only in database ===\n\"Database Only\" by Author")
+		assert.Contains(t, out.String(), "Run with -import and/or -export")
+		_, err := db.GetBookByTitleAndAuthor("Vault Only", "Author")
+		assert.Error(t, err)
+	})
+
+	t.Run("imports and exports with the configured file names", func(t *testing.T) {
+		db, dbPath := setupReconcileDB(t)
+		require.NoError(t, db.SetSetting(entities.SettingKeyExportFilenameStyle, exporters.FilenameStyleSlug))
+		dbOnly := reconcileBook("Database Only", "Author")
+		require.NoError(t, db.SaveBook(&dbOnly))
+		vault := writeVault(t, reconcileBook("Vault Only", "Author"))
+
+		var out bytes.Buffer
+		cmd := &ReconcileCommand{Directory: vault, DatabasePath: dbPath, Import: true, Export: true, In: strings.NewReader(""), Out: &out}
+		require.NoError(t, cmd.Run())
+
+		imported, err := db.GetBookByTitleAndAuthor("Vault Only", "Author")
+		require.NoError(t, err)
+		assert.Len(t, imported.Highlights, 1)
+		_, err = os.Stat(filepath.Join(vault, "kindle", "database-only.md"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("never imports trashed books", func(t *testing.T) {
+		db, dbPath := setupReconcileDB(t)
+		trashed := reconcileBook("Trashed", "Author")
+		require.NoError(t, db.SaveBook(&trashed))
+		require.NoError(t, db.DeleteBook(trashed.ID))
+		vault := writeVault(t, reconcileBook("Trashed", "Author"))
+
+		var out bytes.Buffer
+		cmd := &ReconcileCommand{Directory: vault, DatabasePath: dbPath, Import: true, In: strings.NewReader(""), Out: &out}
+		require.NoError(t, cmd.Run())
+
+		assert.Contains(t, out.String(), "deleted from the database (not imported) ===\n\"Trashed\" by Author")
+		_, err := db.GetBookByTitleAndAuthor("Trashed", "Author")
+		assert.Error(t, err)
+	})
+
+	t.Run("refuses vaults in other formats", func(t *testing.T) {
+		db, dbPath := setupReconcileDB(t)
+		require.NoError(t, db.SetSetting(entities.SettingKeyObsidianSyncFormat, exporters.FormatLogseq))
+
+		cmd := &ReconcileCommand{Directory: t.TempDir(), DatabasePath: dbPath, Export: true, In: strings.NewReader(""), Out: &bytes.Buffer{}}
+		err := cmd.Run()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only supports markdown vaults")
+	})
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm/logger"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/mcp"
+)
+
+// ServeMCPCommand serves the library to LLM clients over the Model Context
+// Protocol on stdin/stdout. The client starts the command itself, so nothing
+// listens on the network.
+type ServeMCPCommand struct {
+	DatabasePath string
+	Username     string // Whose library is served; required when authentication is enabled
+	ReadOnly     bool
+	Version      string
+}
+
+// NewServeMCPCommand creates a new ServeMCPCommand
+func NewServeMCPCommand(version string) *ServeMCPCommand {
+	return &ServeMCPCommand{Version: version}
+}
+
+// ParseFlags parses command line flags
+func (cmd *ServeMCPCommand) ParseFlags(args []string) error {
+	fs := flag.NewFlagSet("serve-mcp", flag.ExitOnError)
+
+	defaultDBPath := config.DefaultDatabasePath
+	if envPath := os.Getenv("DATABASE_PATH"); envPath != "" {
+		defaultDBPath = envPath
+	}
+	fs.StringVar(&cmd.DatabasePath, "db", defaultDBPath, "Path to the database file")
+	fs.StringVar(&cmd.Username, "user", "", "Serve this user's library only (required when authentication is enabled)")
+	fs.BoolVar(&cmd.ReadOnly, "read-only", false, "Only offer tools that read the library (no add_highlight)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s serve-mcp [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Serve highlights to MCP clients such as Claude Desktop over stdin/stdout.\n")
+		fmt.Fprintf(os.Stderr, "The client runs this command; configure it with the full path to the binary.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample client configuration:\n")
+		fmt.Fprintf(os.Stderr, "  {\"mcpServers\": {\"highlights\": {\"command\": \"/usr/local/bin/highlights-manager\",\n")
+		fmt.Fprintf(os.Stderr, "    \"args\": [\"serve-mcp\", \"-db\", \"/path/to/highlights.db\", \"-user\", \"alice\", \"-read-only\"]}}}\n")
+	}
+
+	return fs.Parse(args)
+}
+
+// Run serves requests until the client closes stdin or the process is interrupted
+func (cmd *ServeMCPCommand) Run() error {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Auth.Mode != config.AuthModeNone && cmd.Username == "" {
+		return fmt.Errorf("-user is required when authentication is enabled (AUTH_MODE=%s)", cfg.Auth.Mode)
+	}
+
+	absDBPath, err := filepath.Abs(cmd.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for database: %w", err)
+	}
+	if _, err := os.Stat(absDBPath); err != nil {
+		return fmt.Errorf("database not found: %s", absDBPath)
+	}
+
+	db, err := database.NewDatabase(absDBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	// Stdout carries the protocol, so database warnings must go to stderr
+	db.DB.Logger = logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel:      logger.Warn,
+	})
+
+	server := mcp.NewServer(db, cmd.Version).WithReadOnly(cmd.ReadOnly)
+	if cmd.Username != "" {
+		user, err := db.GetUserByUsername(cmd.Username)
+		if err != nil {
+			return fmt.Errorf("user %q not found: %w", cmd.Username, err)
+		}
+		server.WithUser(user.ID)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("MCP server ready (database %s, user %q, read-only %t)", absDBPath, cmd.Username, cmd.ReadOnly)
+	return server.Serve(ctx, os.Stdin, os.Stdout)
+}
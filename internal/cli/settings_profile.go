@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+)
+
+// SettingsProfileCommand exports the settings of an instance as a profile and
+// applies profiles to another one. Secrets are never exported.
+type SettingsProfileCommand struct {
+	Subcommand   string
+	DatabasePath string
+	File         string // "-" for stdout/stdin
+	Format       string
+	Options      settingsstore.ProfileOptions
+
+	// Out receives exported profiles written to "-"
+	Out io.Writer
+	// In is read for profiles imported from "-"
+	In io.Reader
+}
+
+// NewSettingsProfileCommand creates a new SettingsProfileCommand
+func NewSettingsProfileCommand() *SettingsProfileCommand {
+	return &SettingsProfileCommand{Out: os.Stdout, In: os.Stdin}
+}
+
+// ParseFlags parses the subcommand and its flags
+func (cmd *SettingsProfileCommand) ParseFlags(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		printSettingsProfileUsage()
+		return fmt.Errorf("subcommand required: export or import")
+	}
+	cmd.Subcommand = args[0]
+
+	fs := flag.NewFlagSet("settings-profile "+cmd.Subcommand, flag.ExitOnError)
+
+	defaultDBPath := config.DefaultDatabasePath
+	if envPath := os.Getenv("DATABASE_PATH"); envPath != "" {
+		defaultDBPath = envPath
+	}
+	fs.StringVar(&cmd.DatabasePath, "db", defaultDBPath, "Path to the database file")
+	fs.StringVar(&cmd.Format, "format", "", "Profile format: "+strings.Join(settingsstore.ProfileFormats, ", ")+" (default: from the file extension, else yaml)")
+
+	switch cmd.Subcommand {
+	case "export":
+		fs.StringVar(&cmd.File, "o", "-", "File to write the profile to (- for stdout)")
+		fs.BoolVar(&cmd.Options.Tags, "tags", false, "Include tag names")
+		fs.BoolVar(&cmd.Options.Sources, "sources", false, "Include highlight sources")
+		fs.BoolVar(&cmd.Options.Tombstones, "tombstones", false, "Include records of deleted books and highlights, which keep them from being re-imported")
+	case "import":
+		fs.StringVar(&cmd.File, "file", "", "Profile to apply (- for stdin, required)")
+	default:
+		printSettingsProfileUsage()
+		return fmt.Errorf("unknown subcommand: %s", cmd.Subcommand)
+	}
+
+	fs.Usage = func() {
+		printSettingsProfileUsage()
+		fmt.Fprintf(os.Stderr, "\nOptions for %s:\n", cmd.Subcommand)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if cmd.File == "" {
+		return fmt.Errorf("required flag -file not provided")
+	}
+	if cmd.Format == "" {
+		cmd.Format = settingsstore.ProfileFormatYAML
+		if strings.EqualFold(filepath.Ext(cmd.File), ".json") {
+			cmd.Format = settingsstore.ProfileFormatJSON
+		}
+	}
+	if !slices.Contains(settingsstore.ProfileFormats, cmd.Format) {
+		return fmt.Errorf("-format must be one of %s", strings.Join(settingsstore.ProfileFormats, ", "))
+	}
+	return nil
+}
+
+func printSettingsProfileUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s settings-profile <export|import> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Copy settings between instances as a YAML or JSON profile. Secrets such as\n")
+	fmt.Fprintf(os.Stderr, "API tokens are never exported. A running server applies imported schedules\n")
+	fmt.Fprintf(os.Stderr, "after a restart.\n\n")
+	fmt.Fprintf(os.Stderr, "Subcommands:\n")
+	fmt.Fprintf(os.Stderr, "  export  Write the settings saved in the database, optionally with tags, sources and deletion records\n")
+	fmt.Fprintf(os.Stderr, "  import  Apply a profile; tags, sources and deletion records are only ever added\n")
+	fmt.Fprintf(os.Stderr, "\nExamples:\n")
+	fmt.Fprintf(os.Stderr, "  %s settings-profile export -tags -sources -o profile.yaml\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s settings-profile import -db ./test.db -file profile.yaml\n", os.Args[0])
+}
+
+// Run executes the subcommand
+func (cmd *SettingsProfileCommand) Run() error {
+	absDBPath, err := filepath.Abs(cmd.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for database: %w", err)
+	}
+	if cmd.Subcommand == "export" {
+		if _, err := os.Stat(absDBPath); err != nil {
+			return fmt.Errorf("database not found: %s", absDBPath)
+		}
+	}
+
+	db, err := database.NewDatabase(absDBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	store := settingsstore.New(db)
+	if cmd.Subcommand == "export" {
+		return cmd.export(store)
+	}
+	return cmd.importProfile(store)
+}
+
+func (cmd *SettingsProfileCommand) export(store *settingsstore.SettingsStore) error {
+	profile, err := store.ExportProfile(0, cmd.Options)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.Out
+	if cmd.File != "-" {
+		file, err := os.Create(cmd.File)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", cmd.File, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := settingsstore.EncodeProfile(out, profile, cmd.Format); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d settings, %d tags, %d sources, %d deletion records\n",
+		len(profile.Settings), len(profile.Tags), len(profile.Sources), len(profile.Tombstones))
+	return nil
+}
+
+func (cmd *SettingsProfileCommand) importProfile(store *settingsstore.SettingsStore) error {
+	in := cmd.In
+	if cmd.File != "-" {
+		file, err := os.Open(cmd.File)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", cmd.File, err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	profile, err := settingsstore.DecodeProfile(in, cmd.Format)
+	if err != nil {
+		return err
+	}
+	result, err := store.ImportProfile(0, profile)
+	if err != nil {
+		return err
+	}
+
+	for _, skipped := range result.Skipped {
+		fmt.Fprintf(os.Stderr, "Skipped %s\n", skipped)
+	}
+	fmt.Fprintf(os.Stderr, "Applied %d settings; added %d tags, %d sources, %d deletion records\n",
+		result.Settings, result.Tags, result.Sources, result.Tombstones)
+	return nil
+}
@@ -1,11 +1,22 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// ConfigFileEnv names the environment variable pointing at a config file.
+// Without it, config.yaml (or .yml/.toml/.json) is looked up in
+// $XDG_CONFIG_HOME/highlights, falling back to ~/.config/highlights.
+const ConfigFileEnv = "CONFIG_FILE"
+
 type AuthMode string
 
 const (
@@ -31,11 +42,26 @@ type (
 		Demo
 		Plausible
 		OAuth2
+		Vocabulary
+		Trash
+		Maintenance
+		Uploads
+		Storage
+		OCR
+		TTS
+		Classifier
+
+		File         string            // Config file that was loaded; empty when configured by env only
+		FileSettings map[string]string // Settings given in the config file, by environment variable name
 	}
 
 	HTTP struct {
 		Port int32
 		Host string
+		// Reverse proxies whose X-Forwarded-* headers are honoured; empty
+		// trusts every client, as when the app is only reachable via a proxy
+		TrustedProxies []netip.Prefix
+		BasePath       string // URL prefix the app is served under, e.g. "/highlights"; empty for the root
 	}
 	Obsidian struct {
 		ExportDir string // Directory for markdown exports
@@ -73,9 +99,13 @@ type (
 		DropboxPath  string
 		DatabasePath string
 		OutputDir    string
+		WebDAVDir    string // Directory served to Moon+ Reader over WebDAV; empty disables the endpoint
 	}
 	Tasks struct {
 		Enabled           bool
+		Backend           string // Where queued tasks are kept, one of tasks.Backends; checked at startup
+		DBPath            string // Task database of the sqlite backend; empty puts it next to the main database
+		RedisURL          string // Redis server of the asynq backend
 		Workers           int
 		MaxRetries        int
 		RetryDelay        time.Duration
@@ -102,6 +132,7 @@ type (
 		DBPath        string        // Path to bundled demo database
 		ResetInterval time.Duration // Interval between database resets
 		UseEmbedded   bool          // Use embedded assets instead of file paths
+		Seed          bool          // Seed the demo dataset into a temporary database
 		CoversPath    string        // Path to covers directory
 	}
 	Plausible struct {
@@ -114,6 +145,59 @@ type (
 		CheckInterval  time.Duration // How often to check for expiring tokens (default: 30m)
 		RefreshMargin  time.Duration // Refresh tokens expiring within this duration (default: 15m)
 	}
+	Vocabulary struct {
+		AutoExtract bool // Suggest rare words from newly imported highlights
+	}
+	Trash struct {
+		RetentionDays int // Days before deleted items are purged permanently (0 disables purging)
+	}
+	Maintenance struct {
+		Interval time.Duration // How often database maintenance runs (default: 24h, 0 disables)
+	}
+	Uploads struct {
+		Dir             string // Directory for partial chunked uploads (default: "uploads" next to the database)
+		MaxSizeMB       int    // Largest file accepted through chunked upload (default: 1024)
+		MaxRequestMB    int    // Largest request body on routes without their own limit (default: 10)
+		MaxJSONImportMB int    // Largest JSON import request, e.g. from the Readwise API (default: 50)
+		MaxBackupMB     int    // Largest Moon+ Reader backup or Apple Books database sent without chunking (default: 50)
+		UserQuotaMB     int    // Space each user's unfinished chunked uploads may take (default: 2048, 0 = unlimited)
+		CoverQuotaMB    int    // Space each user's cached book covers may take (default: 200, 0 = unlimited)
+	}
+	Storage struct {
+		Backend           string // Where cover images and finished uploads are kept: "local" (default) or "s3"
+		S3Endpoint        string // e.g. "http://minio:9000" (default: AWS endpoint of the region)
+		S3Region          string // Bucket region (default: "us-east-1")
+		S3Bucket          string
+		S3Prefix          string // Key prefix inside the bucket, e.g. "highlights/"
+		S3AccessKeyID     string
+		S3SecretAccessKey string
+		S3PathStyle       bool // Address the bucket in the URL path, as MinIO expects (default: true)
+	}
+	OCR struct {
+		Backend        string        // "tesseract" or "http"; empty disables OCR
+		TesseractPath  string        // Tesseract binary (default: "tesseract" from PATH)
+		Language       string        // Tesseract language codes, e.g. "eng+deu" (default: "eng")
+		ServiceURL     string        // OCR web service endpoint for the http backend
+		ServiceToken   string        // Bearer token for the OCR web service (optional)
+		Timeout        time.Duration // Time allowed to recognize one image (default: 1m)
+		MaxImageSizeMB int           // Largest photo accepted for OCR (default: 20)
+	}
+	TTS struct {
+		Backend      string        // "command" or "http"; empty disables audio feeds
+		Command      string        // Shell command reading text on stdin and writing MP3 to stdout
+		ServiceURL   string        // OpenAI-compatible speech endpoint for the http backend
+		ServiceToken string        // Bearer token for the speech service (optional)
+		Model        string        // Speech model for the http backend (default: "tts-1")
+		Voice        string        // Voice for the http backend (default: "alloy")
+		CacheDir     string        // Directory for generated MP3 files (default: "audio" next to the database)
+		Timeout      time.Duration // Time allowed to speak one highlight (default: 2m)
+	}
+	Classifier struct {
+		Backend      string        // "rules" or "http"; empty disables highlight classification
+		ServiceURL   string        // Classification web service endpoint for the http backend
+		ServiceToken string        // Bearer token for the classification service (optional)
+		Timeout      time.Duration // Time allowed to classify one highlight (default: 30s)
+	}
 )
 
 // getObsidianExportDir returns the export directory, checking both new and legacy env vars
@@ -126,12 +210,15 @@ func getObsidianExportDir(v *viper.Viper) string {
 	return v.GetString("OBSIDIAN_VAULT_DIR")
 }
 
-func NewConfig() *Config {
+// NewConfig builds the configuration from defaults, the optional config file
+// and environment variables, in increasing order of priority. Config file keys
+// are the environment variable names in lowercase, e.g. "database_path".
+func NewConfig() (*Config, error) {
 	v := viper.New()
 	v.AutomaticEnv()
 	v.SetDefault("port", 8188)
 	v.SetDefault("host", "0.0.0.0")
-	v.SetDefault("shutdown_timeout_in_seconds", 2)
+	v.SetDefault("shutdown_timeout_in_seconds", 10)
 	v.SetDefault("obsidian_export_dir", "")
 	v.SetDefault("obsidian_sync_enabled", false)
 	v.SetDefault("obsidian_sync_schedule", "0 * * * *") // Hourly at :00
@@ -151,6 +238,7 @@ func NewConfig() *Config {
 	v.SetDefault("demo_db_path", "./demo/demo.db")
 	v.SetDefault("demo_reset_interval", "15m")
 	v.SetDefault("demo_use_embedded", false)
+	v.SetDefault("demo_seed", false)
 	v.SetDefault("demo_covers_path", "./demo/covers")
 
 	// Plausible Analytics defaults
@@ -176,6 +264,9 @@ func NewConfig() *Config {
 
 	// Task queue defaults
 	v.SetDefault("tasks_enabled", true)
+	v.SetDefault("tasks_backend", "sqlite")
+	v.SetDefault("tasks_db_path", "")
+	v.SetDefault("tasks_redis_url", "")
 	v.SetDefault("task_workers", 2)
 	v.SetDefault("task_max_retries", 3)
 	v.SetDefault("task_retry_delay", "1m")
@@ -184,10 +275,61 @@ func NewConfig() *Config {
 	v.SetDefault("task_cleanup_interval", "1h")
 	v.SetDefault("task_retention_duration", "24h")
 
+	// Vocabulary defaults
+	v.SetDefault("vocabulary_auto_extract", false)
+
+	// Trash defaults
+	v.SetDefault("trash_retention_days", 30)
+
+	// Database maintenance defaults
+	v.SetDefault("maintenance_interval", "24h")
+
+	// Upload defaults
+	v.SetDefault("upload_max_size_mb", 1024)
+	v.SetDefault("upload_max_request_mb", 10)
+	v.SetDefault("upload_max_json_import_mb", 50)
+	v.SetDefault("upload_max_backup_mb", 50)
+	v.SetDefault("upload_user_quota_mb", 2048)
+	v.SetDefault("cover_user_quota_mb", 200)
+
+	// Blob storage defaults
+	v.SetDefault("storage_backend", "local")
+	v.SetDefault("s3_region", "us-east-1")
+	v.SetDefault("s3_path_style", true)
+
+	// OCR defaults
+	v.SetDefault("ocr_backend", "")
+	v.SetDefault("ocr_tesseract_path", "tesseract")
+	v.SetDefault("ocr_language", "eng")
+	v.SetDefault("ocr_timeout", "1m")
+	v.SetDefault("ocr_max_image_size_mb", 20)
+
+	// Text-to-speech defaults
+	v.SetDefault("tts_backend", "")
+	v.SetDefault("tts_model", "tts-1")
+	v.SetDefault("tts_voice", "alloy")
+	v.SetDefault("tts_timeout", "2m")
+
+	// Highlight classification defaults
+	v.SetDefault("classifier_backend", "")
+	v.SetDefault("classifier_timeout", "30s")
+
+	configFile, err := readConfigFile(v)
+	if err != nil {
+		return nil, err
+	}
+
+	trustedProxies, err := parseTrustedProxies(v.GetString("TRUSTED_PROXIES"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		HTTP: HTTP{
-			Port: v.GetInt32("PORT"),
-			Host: v.GetString("HOST"),
+			Port:           v.GetInt32("PORT"),
+			Host:           v.GetString("HOST"),
+			TrustedProxies: trustedProxies,
+			BasePath:       normalizeBasePath(v.GetString("BASE_PATH")),
 		},
 		Obsidian: Obsidian{
 			ExportDir: getObsidianExportDir(v),
@@ -224,9 +366,13 @@ func NewConfig() *Config {
 			DropboxPath:  v.GetString("MOONREADER_DROPBOX_PATH"),
 			DatabasePath: v.GetString("MOONREADER_DATABASE_PATH"),
 			OutputDir:    v.GetString("MOONREADER_OUTPUT_DIR"),
+			WebDAVDir:    v.GetString("MOONREADER_WEBDAV_DIR"),
 		},
 		Tasks: Tasks{
 			Enabled:           v.GetBool("TASKS_ENABLED"),
+			Backend:           v.GetString("TASKS_BACKEND"),
+			DBPath:            v.GetString("TASKS_DB_PATH"),
+			RedisURL:          v.GetString("TASKS_REDIS_URL"),
 			Workers:           v.GetInt("TASK_WORKERS"),
 			MaxRetries:        v.GetInt("TASK_MAX_RETRIES"),
 			RetryDelay:        v.GetDuration("TASK_RETRY_DELAY"),
@@ -251,6 +397,7 @@ func NewConfig() *Config {
 			DBPath:        v.GetString("DEMO_DB_PATH"),
 			ResetInterval: v.GetDuration("DEMO_RESET_INTERVAL"),
 			UseEmbedded:   v.GetBool("DEMO_USE_EMBEDDED"),
+			Seed:          v.GetBool("DEMO_SEED"),
 			CoversPath:    v.GetString("DEMO_COVERS_PATH"),
 		},
 		Plausible: Plausible{
@@ -263,5 +410,164 @@ func NewConfig() *Config {
 			CheckInterval:  v.GetDuration("OAUTH2_CHECK_INTERVAL"),
 			RefreshMargin:  v.GetDuration("OAUTH2_REFRESH_MARGIN"),
 		},
+		Vocabulary: Vocabulary{
+			AutoExtract: v.GetBool("VOCABULARY_AUTO_EXTRACT"),
+		},
+		Trash: Trash{
+			RetentionDays: v.GetInt("TRASH_RETENTION_DAYS"),
+		},
+		Maintenance: Maintenance{
+			Interval: v.GetDuration("MAINTENANCE_INTERVAL"),
+		},
+		Uploads: Uploads{
+			Dir:             v.GetString("UPLOADS_DIR"),
+			MaxSizeMB:       v.GetInt("UPLOAD_MAX_SIZE_MB"),
+			MaxRequestMB:    v.GetInt("UPLOAD_MAX_REQUEST_MB"),
+			MaxJSONImportMB: v.GetInt("UPLOAD_MAX_JSON_IMPORT_MB"),
+			MaxBackupMB:     v.GetInt("UPLOAD_MAX_BACKUP_MB"),
+			UserQuotaMB:     v.GetInt("UPLOAD_USER_QUOTA_MB"),
+			CoverQuotaMB:    v.GetInt("COVER_USER_QUOTA_MB"),
+		},
+		Storage: Storage{
+			Backend:           v.GetString("STORAGE_BACKEND"),
+			S3Endpoint:        v.GetString("S3_ENDPOINT"),
+			S3Region:          v.GetString("S3_REGION"),
+			S3Bucket:          v.GetString("S3_BUCKET"),
+			S3Prefix:          v.GetString("S3_PREFIX"),
+			S3AccessKeyID:     v.GetString("S3_ACCESS_KEY_ID"),
+			S3SecretAccessKey: v.GetString("S3_SECRET_ACCESS_KEY"),
+			S3PathStyle:       v.GetBool("S3_PATH_STYLE"),
+		},
+		OCR: OCR{
+			Backend:        v.GetString("OCR_BACKEND"),
+			TesseractPath:  v.GetString("OCR_TESSERACT_PATH"),
+			Language:       v.GetString("OCR_LANGUAGE"),
+			ServiceURL:     v.GetString("OCR_SERVICE_URL"),
+			ServiceToken:   v.GetString("OCR_SERVICE_TOKEN"),
+			Timeout:        v.GetDuration("OCR_TIMEOUT"),
+			MaxImageSizeMB: v.GetInt("OCR_MAX_IMAGE_SIZE_MB"),
+		},
+		TTS: TTS{
+			Backend:      v.GetString("TTS_BACKEND"),
+			Command:      v.GetString("TTS_COMMAND"),
+			ServiceURL:   v.GetString("TTS_SERVICE_URL"),
+			ServiceToken: v.GetString("TTS_SERVICE_TOKEN"),
+			Model:        v.GetString("TTS_MODEL"),
+			Voice:        v.GetString("TTS_VOICE"),
+			CacheDir:     v.GetString("TTS_CACHE_DIR"),
+			Timeout:      v.GetDuration("TTS_TIMEOUT"),
+		},
+		Classifier: Classifier{
+			Backend:      v.GetString("CLASSIFIER_BACKEND"),
+			ServiceURL:   v.GetString("CLASSIFIER_SERVICE_URL"),
+			ServiceToken: v.GetString("CLASSIFIER_SERVICE_TOKEN"),
+			Timeout:      v.GetDuration("CLASSIFIER_TIMEOUT"),
+		},
+		File:         configFile,
+		FileSettings: fileSettings(v),
+	}, nil
+}
+
+// fileSettings returns the settings given in the config file, keyed by their
+// environment variable names
+func fileSettings(v *viper.Viper) map[string]string {
+	settings := make(map[string]string)
+	for _, key := range v.AllKeys() {
+		if strings.Contains(key, ".") || !v.InConfig(key) {
+			continue // Nested keys are no settings; defaults are not from the file
+		}
+		if value := v.GetString(key); value != "" {
+			settings[strings.ToUpper(key)] = value
+		}
+	}
+	return settings
+}
+
+// ExportFileSettings sets the environment variables of the settings given in
+// the config file that the environment leaves unset. Packages that read their
+// settings from the environment themselves, such as the settings store's
+// fallbacks for options editable in the UI and the token store's
+// TOKEN_ENCRYPTION_KEY, then see the file's values as well.
+func (c *Config) ExportFileSettings() error {
+	for key, value := range c.FileSettings {
+		if os.Getenv(key) != "" {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("failed to set %s from the config file: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// parseTrustedProxies parses a comma-separated list of proxy addresses and
+// CIDR ranges, e.g. "10.0.0.0/8, 172.17.0.1". A bare address trusts that
+// address alone.
+func parseTrustedProxies(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: expected an IP address or CIDR range", entry)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// normalizeBasePath turns a URL prefix into the "/highlights" form, with a
+// leading and no trailing slash; the root is the empty string.
+func normalizeBasePath(basePath string) string {
+	basePath = strings.Trim(strings.TrimSpace(basePath), "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// readConfigFile loads the config file into v and returns its path. A missing
+// file in the default location is fine; a missing CONFIG_FILE is an error.
+func readConfigFile(v *viper.Viper) (string, error) {
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return "", fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		return path, nil
+	}
+
+	dir := defaultConfigDir()
+	if dir == "" {
+		return "", nil
+	}
+	v.SetConfigName("config")
+	v.AddConfigPath(dir)
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if errors.As(err, &notFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read config file in %s: %w", dir, err)
+	}
+	return v.ConfigFileUsed(), nil
+}
+
+// defaultConfigDir returns the directory searched for config.yaml
+func defaultConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "highlights")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
 	}
+	return filepath.Join(home, ".config", "highlights")
 }
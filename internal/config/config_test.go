@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestNewConfig_Defaults(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+
+	assert.Empty(t, cfg.File)
+	assert.Equal(t, int32(8188), cfg.HTTP.Port)
+	assert.Equal(t, DefaultDatabasePath, cfg.Database.Path)
+	assert.Equal(t, AuthModeNone, cfg.Auth.Mode)
+	assert.Equal(t, 2, cfg.Tasks.Workers)
+	assert.Equal(t, "sqlite", cfg.Tasks.Backend)
+}
+
+func TestNewConfig_YAMLFileWithEnvOverride(t *testing.T) {
+	path := writeConfigFile(t, t.TempDir(), "highlights.yaml", `
+database_path: /data/library.db
+auth_mode: local
+auth_session_lifetime: 48h
+dropbox_app_key: from-file
+obsidian_export_dir: /vault
+task_workers: 4
+`)
+	t.Setenv(ConfigFileEnv, path)
+	t.Setenv("TASK_WORKERS", "8")
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, path, cfg.File)
+	assert.Equal(t, "/data/library.db", cfg.Database.Path)
+	assert.Equal(t, AuthModeLocal, cfg.Auth.Mode)
+	assert.Equal(t, 48*time.Hour, cfg.Auth.SessionLifetime)
+	assert.Equal(t, "from-file", cfg.Dropbox.AppKey)
+	assert.Equal(t, "/vault", cfg.Obsidian.ExportDir)
+	assert.Equal(t, 8, cfg.Tasks.Workers, "env var should override the config file")
+	assert.Equal(t, 3, cfg.Tasks.MaxRetries, "unset keys keep their defaults")
+}
+
+func TestConfig_ExportFileSettings(t *testing.T) {
+	path := writeConfigFile(t, t.TempDir(), "highlights.yaml", `
+telegram_bot_token: from-file
+token_encryption_key: key-from-file
+readwise_sync_schedule: "0 3 * * *"
+`)
+	t.Setenv(ConfigFileEnv, path)
+	// Registered so the variables set from the file are restored after the test
+	t.Setenv("TELEGRAM_BOT_TOKEN", "")
+	t.Setenv("TOKEN_ENCRYPTION_KEY", "")
+	t.Setenv("READWISE_SYNC_SCHEDULE", "0 * * * *")
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", cfg.FileSettings["TELEGRAM_BOT_TOKEN"])
+	assert.NotContains(t, cfg.FileSettings, "PORT", "defaults are not file settings")
+
+	require.NoError(t, cfg.ExportFileSettings())
+	assert.Equal(t, "from-file", os.Getenv("TELEGRAM_BOT_TOKEN"))
+	assert.Equal(t, "key-from-file", os.Getenv("TOKEN_ENCRYPTION_KEY"))
+	assert.Equal(t, "0 * * * *", os.Getenv("READWISE_SYNC_SCHEDULE"), "env var should override the config file")
+}
+
+func TestNewConfig_TOMLInDefaultLocation(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	path := writeConfigFile(t, configHome, filepath.Join("highlights", "config.toml"), `
+port = 9000
+tasks_enabled = false
+`)
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, path, cfg.File)
+	assert.Equal(t, int32(9000), cfg.HTTP.Port)
+	assert.False(t, cfg.Tasks.Enabled)
+}
+
+func TestNewConfig_ReverseProxy(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 172.17.0.1,")
+	t.Setenv("BASE_PATH", "highlights/")
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+
+	require.Len(t, cfg.HTTP.TrustedProxies, 2)
+	assert.Equal(t, "10.0.0.0/8", cfg.HTTP.TrustedProxies[0].String())
+	assert.Equal(t, "172.17.0.1/32", cfg.HTTP.TrustedProxies[1].String())
+	assert.Equal(t, "/highlights", cfg.HTTP.BasePath)
+}
+
+func TestNewConfig_Errors(t *testing.T) {
+	t.Run("missing CONFIG_FILE", func(t *testing.T) {
+		t.Setenv(ConfigFileEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+		_, err := NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		t.Setenv(ConfigFileEnv, writeConfigFile(t, t.TempDir(), "bad.yaml", "port: [unclosed"))
+		_, err := NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid trusted proxy", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		t.Setenv("TRUSTED_PROXIES", "proxy.local")
+		_, err := NewConfig()
+		assert.Error(t, err)
+	})
+}
@@ -2,18 +2,33 @@ package covers
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/mrlokans/assistant/internal/storage"
 )
 
-// Cache handles local caching of book cover images.
+// maxCoverSize caps a single downloaded cover image (10 MB).
+const maxCoverSize = 10 << 20
+
+var (
+	ErrQuotaExceeded = errors.New("cover cache quota exceeded")
+	ErrCoverTooLarge = errors.New("cover image too large")
+)
+
+// Cache handles caching of book cover images, on the local filesystem or in
+// a storage client such as an S3 bucket. Covers of each user's books are kept
+// in a directory of their own, so their space can be limited.
 type Cache struct {
 	cacheDir   string
 	httpClient *http.Client
+	userQuota  int64          // Space each user's cached covers may take, 0 for no limit
+	store      storage.Client // Keeps the covers instead of cacheDir when set
 }
 
 // NewCache creates a new cover cache at the specified directory.
@@ -30,22 +45,55 @@ func NewCache(cacheDir string) (*Cache, error) {
 	}, nil
 }
 
-// GetCover returns the cached cover for a book, or fetches and caches it if not present.
-// Returns the file path to the cached cover, or empty string if unavailable.
-func (c *Cache) GetCover(bookID uint, coverURL string) (string, error) {
+// WithUserQuota limits how much space each user's cached covers may take.
+// Once a user reaches it, new covers are not cached. 0 removes the limit.
+func (c *Cache) WithUserQuota(quota int64) *Cache {
+	c.userQuota = quota
+	return c
+}
+
+// WithStore keeps covers in a storage client, such as an S3 bucket, instead
+// of the cache directory. Covers are stored under StorePrefix with the same
+// layout as in the cache directory.
+func (c *Cache) WithStore(store storage.Client) *Cache {
+	c.store = store
+	return c
+}
+
+// GetCover returns the cached cover for a user's book, or fetches and caches it if not present.
+// Returns the file path to the cached cover, or the storage path when covers are kept in a
+// storage client, or empty string if unavailable. Returns ErrQuotaExceeded when the user's
+// covers already take up their quota.
+func (c *Cache) GetCover(userID, bookID uint, coverURL string) (string, error) {
 	if coverURL == "" {
 		return "", nil
 	}
+	if c.store != nil {
+		return c.getStoredCover(userID, bookID, coverURL)
+	}
 
-	filename := c.coverFilename(bookID, coverURL)
-	cachePath := filepath.Join(c.cacheDir, filename)
+	dir := c.userDir(userID)
+	cachePath := filepath.Join(dir, c.coverFilename(bookID, coverURL))
 
 	// Check if cached file exists
 	if _, err := os.Stat(cachePath); err == nil {
 		return cachePath, nil
 	}
 
+	if c.userQuota > 0 {
+		used, err := c.usage(dir)
+		if err != nil {
+			return "", err
+		}
+		if used >= c.userQuota {
+			return "", ErrQuotaExceeded
+		}
+	}
+
 	// Fetch and cache the cover
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create cache dir: %w", err)
+	}
 	if err := c.fetchAndCache(coverURL, cachePath); err != nil {
 		return "", err
 	}
@@ -53,12 +101,51 @@ func (c *Cache) GetCover(bookID uint, coverURL string) (string, error) {
 	return cachePath, nil
 }
 
+// OpenCover returns the cached cover for a user's book like GetCover, opened
+// for reading. Returns nil if there is no cover. The caller must close it.
+func (c *Cache) OpenCover(userID, bookID uint, coverURL string) (*Cover, error) {
+	path, err := c.GetCover(userID, bookID, coverURL)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	if c.store != nil {
+		return c.openStoredCover(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Cover{ReadSeekCloser: file, Name: filepath.Base(path), ModTime: info.ModTime()}, nil
+}
+
+// Usage returns how much space a user's cached covers take.
+func (c *Cache) Usage(userID uint) (int64, error) {
+	if c.store != nil {
+		return c.storedUsage(c.storeDir(userID))
+	}
+	return c.usage(c.userDir(userID))
+}
+
 // InvalidateCover removes the cached cover for a book.
 func (c *Cache) InvalidateCover(bookID uint) error {
-	pattern := filepath.Join(c.cacheDir, fmt.Sprintf("cover_%d_*", bookID))
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		return err
+	if c.store != nil {
+		return c.invalidateStoredCover(bookID)
+	}
+
+	name := fmt.Sprintf("cover_%d_*", bookID)
+	var matches []string
+	for _, pattern := range []string{filepath.Join(c.cacheDir, name), filepath.Join(c.cacheDir, "user_*", name)} {
+		found, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		matches = append(matches, found...)
 	}
 
 	for _, match := range matches {
@@ -70,6 +157,37 @@ func (c *Cache) InvalidateCover(bookID uint) error {
 	return nil
 }
 
+// userDir is where a user's covers are cached. Covers of books without an
+// owner stay at the top of the cache, where they were kept before users.
+func (c *Cache) userDir(userID uint) string {
+	if userID == 0 {
+		return c.cacheDir
+	}
+	return filepath.Join(c.cacheDir, fmt.Sprintf("user_%d", userID))
+}
+
+// usage sums the size of the covers cached in dir.
+func (c *Cache) usage(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var used int64
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			used += info.Size()
+		}
+	}
+	return used, nil
+}
+
 // coverFilename generates a unique filename based on book ID and URL hash.
 func (c *Cache) coverFilename(bookID uint, coverURL string) string {
 	hash := sha256.Sum256([]byte(coverURL))
@@ -78,24 +196,13 @@ func (c *Cache) coverFilename(bookID uint, coverURL string) string {
 
 // fetchAndCache downloads a cover image and saves it to the cache.
 func (c *Cache) fetchAndCache(url, cachePath string) error {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	data, err := c.fetch(url)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("User-Agent", "HighlightsManager/1.0")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to fetch cover: status %d", resp.StatusCode)
-	}
 
 	// Create temp file in same directory for atomic write
-	tmpFile, err := os.CreateTemp(c.cacheDir, "cover_tmp_")
+	tmpFile, err := os.CreateTemp(filepath.Dir(cachePath), "cover_tmp_")
 	if err != nil {
 		return err
 	}
@@ -105,18 +212,46 @@ func (c *Cache) fetchAndCache(url, cachePath string) error {
 		os.Remove(tmpPath) // Clean up if we didn't rename
 	}()
 
-	// Copy response body to temp file
-	_, err = io.Copy(tmpFile, resp.Body)
-	if err != nil {
+	if _, err := tmpFile.Write(data); err != nil {
 		return err
 	}
-
 	tmpFile.Close()
 
 	// Atomic rename
 	return os.Rename(tmpPath, cachePath)
 }
 
+// fetch downloads a cover image into memory.
+func (c *Cache) fetch(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "HighlightsManager/1.0")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch cover: status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxCoverSize {
+		return nil, ErrCoverTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCoverSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxCoverSize {
+		return nil, ErrCoverTooLarge
+	}
+	return data, nil
+}
+
 // CacheDir returns the cache directory path.
 func (c *Cache) CacheDir() string {
 	return c.cacheDir
@@ -1,11 +1,19 @@
 package covers
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/mrlokans/assistant/internal/storage"
 )
 
 func TestNewCache(t *testing.T) {
@@ -30,7 +38,7 @@ func TestNewCache(t *testing.T) {
 func TestGetCover_EmptyURL(t *testing.T) {
 	cache, _ := NewCache(t.TempDir())
 
-	path, err := cache.GetCover(1, "")
+	path, err := cache.GetCover(0, 1, "")
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -50,7 +58,7 @@ func TestGetCover_FetchAndCache(t *testing.T) {
 	cache, _ := NewCache(t.TempDir())
 
 	// First request should fetch
-	path1, err := cache.GetCover(1, server.URL+"/cover.jpg")
+	path1, err := cache.GetCover(0, 1, server.URL+"/cover.jpg")
 	if err != nil {
 		t.Fatalf("GetCover failed: %v", err)
 	}
@@ -64,7 +72,7 @@ func TestGetCover_FetchAndCache(t *testing.T) {
 	}
 
 	// Second request should use cache
-	path2, err := cache.GetCover(1, server.URL+"/cover.jpg")
+	path2, err := cache.GetCover(0, 1, server.URL+"/cover.jpg")
 	if err != nil {
 		t.Fatalf("GetCover (cached) failed: %v", err)
 	}
@@ -81,7 +89,7 @@ func TestGetCover_FetchError(t *testing.T) {
 
 	cache, _ := NewCache(t.TempDir())
 
-	_, err := cache.GetCover(1, server.URL+"/notfound.jpg")
+	_, err := cache.GetCover(0, 1, server.URL+"/notfound.jpg")
 	if err == nil {
 		t.Error("expected error for 404 response")
 	}
@@ -97,7 +105,7 @@ func TestInvalidateCover(t *testing.T) {
 	cache, _ := NewCache(t.TempDir())
 
 	// Fetch and cache a cover
-	path, err := cache.GetCover(1, server.URL+"/cover.jpg")
+	path, err := cache.GetCover(0, 1, server.URL+"/cover.jpg")
 	if err != nil {
 		t.Fatalf("GetCover failed: %v", err)
 	}
@@ -119,6 +127,56 @@ func TestInvalidateCover(t *testing.T) {
 	}
 }
 
+func TestGetCover_UserQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write([]byte("fake image data"))
+	}))
+	defer server.Close()
+
+	cache, _ := NewCache(t.TempDir())
+	cache.WithUserQuota(10)
+
+	path, err := cache.GetCover(7, 1, server.URL+"/one.jpg")
+	if err != nil {
+		t.Fatalf("GetCover failed: %v", err)
+	}
+	if filepath.Dir(path) != filepath.Join(cache.CacheDir(), "user_7") {
+		t.Errorf("expected cover in the user's directory, got %s", path)
+	}
+
+	// The user is over quota now; cached covers are still served
+	if _, err := cache.GetCover(7, 2, server.URL+"/two.jpg"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+	if cached, err := cache.GetCover(7, 1, server.URL+"/one.jpg"); err != nil || cached != path {
+		t.Errorf("expected cached cover %s, got %s (%v)", path, cached, err)
+	}
+	if _, err := cache.GetCover(8, 2, server.URL+"/two.jpg"); err != nil {
+		t.Errorf("another user's quota should not be affected: %v", err)
+	}
+
+	// Invalidation finds covers in user directories
+	if err := cache.InvalidateCover(1); err != nil {
+		t.Fatalf("InvalidateCover failed: %v", err)
+	}
+	if used, _ := cache.Usage(7); used != 0 {
+		t.Errorf("expected no usage after invalidation, got %d", used)
+	}
+}
+
+func TestGetCover_TooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, maxCoverSize+1))
+	}))
+	defer server.Close()
+
+	cache, _ := NewCache(t.TempDir())
+	if _, err := cache.GetCover(0, 1, server.URL+"/huge.jpg"); !errors.Is(err, ErrCoverTooLarge) {
+		t.Errorf("expected ErrCoverTooLarge, got %v", err)
+	}
+}
+
 func TestCoverFilename(t *testing.T) {
 	cache, _ := NewCache(t.TempDir())
 
@@ -141,3 +199,111 @@ func TestCoverFilename(t *testing.T) {
 		t.Error("different book IDs should produce different filenames")
 	}
 }
+
+// memoryStorage is a storage.Client keeping files in a map.
+type memoryStorage struct {
+	files map[string][]byte
+}
+
+func (m *memoryStorage) List(ctx context.Context, dir string) ([]storage.FileInfo, error) {
+	var entries []storage.FileInfo
+	seen := map[string]bool{}
+	for name, data := range m.files {
+		rest, ok := strings.CutPrefix(name, dir+"/")
+		if !ok {
+			continue
+		}
+		if sub, _, isDir := strings.Cut(rest, "/"); isDir {
+			if !seen[sub] {
+				seen[sub] = true
+				entries = append(entries, storage.FileInfo{Name: sub, Path: path.Join(dir, sub), IsDir: true})
+			}
+			continue
+		}
+		entries = append(entries, storage.FileInfo{Name: rest, Path: name, Size: int64(len(data))})
+	}
+	return entries, nil
+}
+
+func (m *memoryStorage) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	data, ok := m.files[name]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryStorage) Upload(ctx context.Context, name string, content io.Reader) error {
+	data, err := io.ReadAll(content)
+	m.files[name] = data
+	return err
+}
+
+func (m *memoryStorage) Delete(ctx context.Context, name string) error {
+	delete(m.files, name)
+	return nil
+}
+
+func (m *memoryStorage) Exists(ctx context.Context, name string) (bool, error) {
+	_, ok := m.files[name]
+	return ok, nil
+}
+
+func (m *memoryStorage) GetMetadata(ctx context.Context, name string) (*storage.FileInfo, error) {
+	return nil, storage.ErrNotFound
+}
+
+func TestWithStore(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte("fake image data"))
+	}))
+	defer server.Close()
+
+	remote := &memoryStorage{files: map[string][]byte{}}
+	cacheDir := t.TempDir()
+	cache, _ := NewCache(cacheDir)
+	cache.WithStore(remote).WithUserQuota(10)
+
+	cover, err := cache.OpenCover(7, 1, server.URL+"/one.jpg")
+	if err != nil || cover == nil {
+		t.Fatalf("OpenCover failed: %v", err)
+	}
+	data, _ := io.ReadAll(cover)
+	cover.Close()
+	if string(data) != "fake image data" {
+		t.Errorf("unexpected cover content %q", data)
+	}
+	if !strings.HasSuffix(cover.Name, ".jpg") {
+		t.Errorf("expected a .jpg name, got %s", cover.Name)
+	}
+
+	storePath := "covers/user_7/" + cache.coverFilename(1, server.URL+"/one.jpg")
+	if _, ok := remote.files[storePath]; !ok {
+		t.Errorf("expected cover at %s, got %v", storePath, remote.files)
+	}
+	if entries, _ := os.ReadDir(cacheDir); len(entries) != 0 {
+		t.Error("expected nothing cached on disk")
+	}
+
+	// Stored covers are not fetched again
+	if _, err := cache.GetCover(7, 1, server.URL+"/one.jpg"); err != nil || requests != 1 {
+		t.Errorf("expected stored cover to be reused, %d requests (%v)", requests, err)
+	}
+
+	// The quota counts stored covers
+	if used, _ := cache.Usage(7); used != int64(len("fake image data")) {
+		t.Errorf("unexpected usage %d", used)
+	}
+	if _, err := cache.GetCover(7, 2, server.URL+"/two.jpg"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+
+	if err := cache.InvalidateCover(1); err != nil {
+		t.Fatalf("InvalidateCover failed: %v", err)
+	}
+	if len(remote.files) != 0 {
+		t.Errorf("expected cover to be deleted from storage, got %v", remote.files)
+	}
+}
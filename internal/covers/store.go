@@ -0,0 +1,131 @@
+package covers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/storage"
+)
+
+// StorePrefix is the directory covers are kept in when a storage client is used.
+const StorePrefix = "covers"
+
+// Cover is an opened cover image.
+type Cover struct {
+	io.ReadSeekCloser
+	Name    string    // File name, which tells the image type
+	ModTime time.Time // When the cover was cached, zero if unknown
+}
+
+// storeDir is where a user's covers are kept in the storage client, laid out
+// like the cache directory.
+func (c *Cache) storeDir(userID uint) string {
+	if userID == 0 {
+		return StorePrefix
+	}
+	return path.Join(StorePrefix, fmt.Sprintf("user_%d", userID))
+}
+
+// getStoredCover is GetCover for covers kept in the storage client.
+func (c *Cache) getStoredCover(userID, bookID uint, coverURL string) (string, error) {
+	ctx := context.Background()
+	dir := c.storeDir(userID)
+	storePath := path.Join(dir, c.coverFilename(bookID, coverURL))
+
+	exists, err := c.store.Exists(ctx, storePath)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return storePath, nil
+	}
+
+	if c.userQuota > 0 {
+		used, err := c.storedUsage(dir)
+		if err != nil {
+			return "", err
+		}
+		if used >= c.userQuota {
+			return "", ErrQuotaExceeded
+		}
+	}
+
+	data, err := c.fetch(coverURL)
+	if err != nil {
+		return "", err
+	}
+	if err := c.store.Upload(ctx, storePath, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+	return storePath, nil
+}
+
+// openStoredCover reads a cover from the storage client. Covers are small, so
+// they are read into memory to be seekable.
+func (c *Cache) openStoredCover(storePath string) (*Cover, error) {
+	reader, err := c.store.Download(context.Background(), storePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxCoverSize))
+	if err != nil {
+		return nil, err
+	}
+	return &Cover{ReadSeekCloser: memoryFile{bytes.NewReader(data)}, Name: path.Base(storePath)}, nil
+}
+
+// storedUsage sums the size of the covers kept in a storage directory.
+func (c *Cache) storedUsage(dir string) (int64, error) {
+	entries, err := c.store.List(context.Background(), dir)
+	if storage.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var used int64
+	for _, entry := range entries {
+		if !entry.IsDir {
+			used += entry.Size
+		}
+	}
+	return used, nil
+}
+
+// invalidateStoredCover removes a book's covers from the storage client.
+func (c *Cache) invalidateStoredCover(bookID uint) error {
+	ctx := context.Background()
+	files, err := storage.ListRecursive(ctx, c.store, StorePrefix)
+	if storage.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	prefix := fmt.Sprintf("cover_%d_", bookID)
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name, prefix) {
+			continue
+		}
+		if err := c.store.Delete(ctx, file.Path); err != nil && !storage.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// memoryFile is a cover read into memory.
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error { return nil }
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	stdsync "sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	syncrepo "github.com/mrlokans/assistant/internal/database/sync"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ErrLockHeld is returned when an advisory lock is held by another run.
+var ErrLockHeld = errors.New("lock is held by another run")
+
+const (
+	// LockWaitTimeout is how long AcquireLock waits for a held lock.
+	LockWaitTimeout = time.Minute
+
+	// lockRetryInterval is how often AcquireLock retries a held lock.
+	lockRetryInterval = time.Second
+)
+
+// lockSeq tells apart the locks taken by this process, so two requests of
+// the same process exclude each other like two processes do.
+var lockSeq atomic.Uint64
+
+// Lock is an advisory lock held by this process. It sends heartbeats until
+// released, so a lock whose process died is freed after a while.
+type Lock struct {
+	db      *Database
+	name    string
+	owner   string
+	done    chan struct{}
+	release stdsync.Once
+}
+
+// LockStatus is an advisory lock with whether its holder looks gone.
+type LockStatus struct {
+	entities.AdvisoryLock
+	Abandoned bool `json:"abandoned"`
+}
+
+// TryAcquireLock takes the named advisory lock if it is free or its holder
+// stopped sending heartbeats. Otherwise it returns an error wrapping
+// ErrLockHeld that names the holder. operation describes the run in the
+// lock status.
+func (d *Database) TryAcquireLock(name, operation string) (*Lock, error) {
+	now := time.Now()
+	owner := fmt.Sprintf("%s#%d", syncrepo.WorkerID(), lockSeq.Add(1))
+	row := entities.AdvisoryLock{Name: name, Owner: owner, Operation: operation, AcquiredAt: now, HeartbeatAt: now}
+
+	created := d.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if created.Error != nil {
+		return nil, fmt.Errorf("failed to acquire %s lock: %w", name, created.Error)
+	}
+	if created.RowsAffected == 0 {
+		var holder entities.AdvisoryLock
+		err := d.DB.First(&holder, "name = ?", name).Error
+		if err == gorm.ErrRecordNotFound {
+			// Released in the meantime
+			return nil, fmt.Errorf("%s %w", name, ErrLockHeld)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire %s lock: %w", name, err)
+		}
+		if !lockAbandoned(&holder, now) {
+			return nil, fmt.Errorf("%s %w: %s by %s since %s", name, ErrLockHeld,
+				holder.Operation, holder.Owner, holder.AcquiredAt.Format(time.RFC3339))
+		}
+
+		// Take the lock over, unless another run got to it first
+		takeover := d.DB.Model(&entities.AdvisoryLock{}).
+			Where("name = ? AND owner = ?", name, holder.Owner).
+			Updates(map[string]any{"owner": owner, "operation": operation, "acquired_at": now, "heartbeat_at": now})
+		if takeover.Error != nil {
+			return nil, fmt.Errorf("failed to acquire %s lock: %w", name, takeover.Error)
+		}
+		if takeover.RowsAffected == 0 {
+			return nil, fmt.Errorf("%s %w", name, ErrLockHeld)
+		}
+		log.Printf("Took over abandoned %s lock of %s", name, holder.Owner)
+	}
+
+	lock := &Lock{db: d, name: name, owner: owner, done: make(chan struct{})}
+	go lock.keepAlive()
+	return lock, nil
+}
+
+// AcquireLock takes the named advisory lock, waiting up to LockWaitTimeout
+// while another run holds it.
+func (d *Database) AcquireLock(name, operation string) (*Lock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), LockWaitTimeout)
+	defer cancel()
+	return d.AcquireLockContext(ctx, name, operation)
+}
+
+// AcquireLockContext takes the named advisory lock, waiting until ctx is done
+// while another run holds it.
+func (d *Database) AcquireLockContext(ctx context.Context, name, operation string) (*Lock, error) {
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+	for {
+		lock, err := d.TryAcquireLock(name, operation)
+		if !errors.Is(err, ErrLockHeld) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetLocks returns the advisory locks currently held, by name.
+func (d *Database) GetLocks() ([]LockStatus, error) {
+	var locks []entities.AdvisoryLock
+	if err := d.DB.Order("name ASC").Find(&locks).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	statuses := make([]LockStatus, len(locks))
+	for i := range locks {
+		statuses[i] = LockStatus{AdvisoryLock: locks[i], Abandoned: lockAbandoned(&locks[i], now)}
+	}
+	return statuses, nil
+}
+
+// Release frees the lock. Releasing a lock twice does nothing.
+func (l *Lock) Release() {
+	l.release.Do(func() {
+		close(l.done)
+		err := l.db.DB.Where("name = ? AND owner = ?", l.name, l.owner).Delete(&entities.AdvisoryLock{}).Error
+		if err != nil {
+			log.Printf("WARNING: Failed to release %s lock: %v", l.name, err)
+		}
+	})
+}
+
+func (l *Lock) keepAlive() {
+	ticker := time.NewTicker(syncrepo.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			result := l.db.DB.Model(&entities.AdvisoryLock{}).
+				Where("name = ? AND owner = ?", l.name, l.owner).
+				Update("heartbeat_at", time.Now())
+			if result.Error != nil {
+				log.Printf("WARNING: Failed to send %s lock heartbeat: %v", l.name, result.Error)
+			} else if result.RowsAffected == 0 {
+				log.Printf("WARNING: Lost the %s lock to another run", l.name)
+				return
+			}
+		}
+	}
+}
+
+// lockAbandoned reports whether a lock went without a heartbeat for longer
+// than the sync heartbeat timeout.
+func lockAbandoned(lock *entities.AdvisoryLock, now time.Time) bool {
+	return now.Sub(lock.HeartbeatAt) > syncrepo.HeartbeatTimeout
+}
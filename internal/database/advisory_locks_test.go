@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestAdvisoryLocks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	lock, err := db.TryAcquireLock(entities.LockImport, "kindle import")
+	require.NoError(t, err)
+
+	// Held locks exclude other runs, including ones of the same process
+	_, err = db.TryAcquireLock(entities.LockImport, "readwise import")
+	assert.ErrorIs(t, err, ErrLockHeld)
+	assert.Contains(t, err.Error(), "kindle import")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = db.AcquireLockContext(ctx, entities.LockImport, "readwise import")
+	assert.ErrorIs(t, err, ErrLockHeld)
+
+	// Other locks are independent
+	exportLock, err := db.TryAcquireLock(entities.LockExport, "obsidian sync")
+	require.NoError(t, err)
+	exportLock.Release()
+
+	locks, err := db.GetLocks()
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.Equal(t, entities.LockImport, locks[0].Name)
+	assert.Equal(t, "kindle import", locks[0].Operation)
+	assert.False(t, locks[0].Abandoned)
+
+	lock.Release()
+	lock.Release()
+	locks, err = db.GetLocks()
+	require.NoError(t, err)
+	assert.Empty(t, locks)
+
+	lock, err = db.AcquireLock(entities.LockImport, "readwise import")
+	require.NoError(t, err)
+	defer lock.Release()
+}
+
+func TestAdvisoryLocks_TakeOverAbandoned(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	stale := time.Now().Add(-time.Hour)
+	require.NoError(t, db.DB.Create(&entities.AdvisoryLock{
+		Name: entities.LockImport, Owner: "other-host:42:beef#1", Operation: "import", AcquiredAt: stale, HeartbeatAt: stale,
+	}).Error)
+
+	locks, err := db.GetLocks()
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.True(t, locks[0].Abandoned)
+
+	lock, err := db.TryAcquireLock(entities.LockImport, "kindle import")
+	require.NoError(t, err)
+
+	locks, err = db.GetLocks()
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.NotEqual(t, "other-host:42:beef#1", locks[0].Owner)
+	assert.False(t, locks[0].Abandoned)
+
+	// The previous holder can no longer release the lock it lost
+	lost := &Lock{db: db, name: entities.LockImport, owner: "other-host:42:beef#1", done: make(chan struct{})}
+	lost.Release()
+	locks, err = db.GetLocks()
+	require.NoError(t, err)
+	assert.Len(t, locks, 1)
+
+	lock.Release()
+}
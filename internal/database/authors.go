@@ -0,0 +1,504 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+var (
+	// ErrAuthorsNotMergeable is returned when merging an author into itself or an author of another user.
+	ErrAuthorsNotMergeable = errors.New("authors cannot be merged")
+	// ErrAuthorAliasTaken is returned when an alias is the name of another author.
+	ErrAuthorAliasTaken = errors.New("name belongs to another author")
+)
+
+// authorResolver finds or creates the author record for a book's author name,
+// remembering names already resolved so a batch looks each author up once.
+type authorResolver struct {
+	db  *gorm.DB
+	ids map[string]uint
+}
+
+func (d *Database) newAuthorResolver() *authorResolver {
+	return &authorResolver{db: d.DB, ids: make(map[string]uint)}
+}
+
+// resolve returns the ID of the user's author known by name, either as the
+// canonical name or an alias, creating the author if there is none. Names are
+// matched case-insensitively, as written and normalized (see
+// entities.NormalizeAuthorName); a new author takes the normalized name and
+// keeps the name as written as an alias. An empty name resolves to 0.
+func (r *authorResolver) resolve(userID uint, name string) (uint, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, nil
+	}
+	key := fmt.Sprintf("%d|%s", userID, strings.ToLower(name))
+	if id, ok := r.ids[key]; ok {
+		return id, nil
+	}
+
+	normalized := entities.NormalizeAuthorName(name)
+	id, err := r.find(userID, name)
+	if err == nil && id == 0 && normalized != name {
+		id, err = r.find(userID, normalized)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		if id, err = r.create(userID, normalized, name); err != nil {
+			return 0, err
+		}
+	}
+
+	r.ids[key] = id
+	return id, nil
+}
+
+func (r *authorResolver) create(userID uint, normalized, name string) (uint, error) {
+	author := entities.Author{UserID: userID, Name: normalized}
+	// Another import may have created the author meanwhile
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&author).Error; err != nil {
+		return 0, fmt.Errorf("failed to create author %q: %w", name, err)
+	}
+	if author.ID == 0 {
+		return r.find(userID, normalized)
+	}
+	if err := addAuthorAliases(r.db, author.ID, normalized, []string{name}); err != nil {
+		return 0, err
+	}
+	return author.ID, nil
+}
+
+func (r *authorResolver) find(userID uint, name string) (uint, error) {
+	var ids []uint
+	err := r.db.Model(&entities.Author{}).
+		Where("user_id = ? AND name = ? COLLATE NOCASE", userID, name).
+		Limit(1).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find author %q: %w", name, err)
+	}
+	if len(ids) > 0 {
+		return ids[0], nil
+	}
+
+	err = r.db.Model(&entities.AuthorAlias{}).
+		Joins("JOIN authors ON authors.id = author_aliases.author_id").
+		Where("authors.user_id = ? AND author_aliases.name = ? COLLATE NOCASE", userID, name).
+		Order("author_aliases.author_id ASC").
+		Limit(1).Pluck("author_aliases.author_id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find author %q: %w", name, err)
+	}
+	if len(ids) > 0 {
+		return ids[0], nil
+	}
+	return 0, nil
+}
+
+// linkAuthor sets the book's author ID, keeping the one of the stored book it
+// is merged into when the author name is unchanged.
+func (r *authorResolver) linkAuthor(book *entities.Book, existing *entities.Book) error {
+	if book.AuthorID != 0 {
+		return nil
+	}
+	if existing != nil && existing.AuthorID != 0 {
+		book.AuthorID = existing.AuthorID
+		return nil
+	}
+	id, err := r.resolve(book.UserID, book.Author)
+	if err != nil {
+		return err
+	}
+	book.AuthorID = id
+	return nil
+}
+
+// GetAuthors returns the user's authors that have books, by name.
+func (d *Database) GetAuthors(userID uint) ([]entities.AuthorSummary, error) {
+	type authorCount struct {
+		AuthorID  uint
+		BookCount int64
+	}
+	var counts []authorCount
+	err := d.DB.Model(&entities.Book{}).
+		Select("author_id, COUNT(*) AS book_count").
+		Where("user_id = ? AND author_id <> 0", userID).
+		Group("author_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return []entities.AuthorSummary{}, nil
+	}
+
+	countByAuthor := make(map[uint]int64, len(counts))
+	ids := make([]uint, 0, len(counts))
+	for _, c := range counts {
+		countByAuthor[c.AuthorID] = c.BookCount
+		ids = append(ids, c.AuthorID)
+	}
+
+	var authors []entities.Author
+	if err := d.DB.Where("id IN ?", ids).Order("name COLLATE NOCASE ASC").Find(&authors).Error; err != nil {
+		return nil, err
+	}
+	summaries := make([]entities.AuthorSummary, 0, len(authors))
+	for _, author := range authors {
+		summaries = append(summaries, entities.AuthorSummary{Author: author, BookCount: countByAuthor[author.ID]})
+	}
+	return summaries, nil
+}
+
+// GetAuthorByID returns an author with their aliases.
+func (d *Database) GetAuthorByID(id uint) (*entities.Author, error) {
+	var author entities.Author
+	err := d.DB.Preload("Aliases", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).First(&author, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// GetAuthorBooks returns the books linked to an author, by title, with their highlights.
+func (d *Database) GetAuthorBooks(authorID uint) ([]entities.Book, error) {
+	var books []entities.Book
+	err := d.DB.Preload("Highlights", func(db *gorm.DB) *gorm.DB {
+		return db.Order("location_value ASC, highlighted_at ASC")
+	}).Preload("Source").
+		Where("author_id = ?", authorID).
+		Order("title ASC").
+		Find(&books).Error
+	return books, err
+}
+
+// GetAuthorsToEnrich returns authors that have not been looked up on Wikidata yet.
+func (d *Database) GetAuthorsToEnrich() ([]entities.Author, error) {
+	var authors []entities.Author
+	err := d.DB.Where("enriched_at IS NULL").Order("id ASC").Find(&authors).Error
+	return authors, err
+}
+
+// UpdateAuthorMetadata saves what Wikidata knows about an author. The author
+// takes the Wikidata name unless the user already has an author by that name,
+// and the previous name is kept as an alias so books are still matched by it.
+// Another author of the user with the same Wikidata item or name is the same
+// person, e.g. "Лев Толстой" and "Leo Tolstoy": the author is merged into them.
+// Returns the ID of the author the details were saved to. A nil info only
+// records that the author was looked up.
+func (d *Database) UpdateAuthorMetadata(id uint, info *metadata.AuthorMetadata) (uint, error) {
+	now := time.Now()
+	if info == nil {
+		return id, d.DB.Model(&entities.Author{}).Where("id = ?", id).Update("enriched_at", now).Error
+	}
+
+	canonical := strings.TrimSpace(info.Name)
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		var author entities.Author
+		if err := tx.First(&author, id).Error; err != nil {
+			return err
+		}
+
+		same := tx.Where("name = ? COLLATE NOCASE", canonical)
+		if info.WikidataID != "" {
+			same = same.Or("wikidata_id = ?", info.WikidataID)
+		}
+		var twin entities.Author
+		err := tx.Where("user_id = ? AND id <> ?", author.UserID, author.ID).Where(same).
+			Order("id ASC").First(&twin).Error
+		switch {
+		case err == nil:
+			if err := mergeAuthorInto(tx, &twin, &author); err != nil {
+				return err
+			}
+			author = twin
+			id = twin.ID
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		updates := map[string]any{
+			"wikidata_id":   info.WikidataID,
+			"photo_url":     info.PhotoURL,
+			"birth_year":    info.BirthYear,
+			"death_year":    info.DeathYear,
+			"bio":           info.Bio,
+			"wikipedia_url": info.WikipediaURL,
+			"enriched_at":   now,
+		}
+
+		name := author.Name
+		aliases := append([]string{}, info.Aliases...)
+		if canonical != "" && canonical != author.Name {
+			var taken int64
+			if err := tx.Model(&entities.Author{}).
+				Where("user_id = ? AND name = ? AND id <> ?", author.UserID, canonical, author.ID).
+				Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken == 0 {
+				updates["name"] = canonical
+				aliases = append(aliases, author.Name)
+				name = canonical
+			}
+		}
+
+		if err := tx.Model(&entities.Author{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		return addAuthorAliases(tx, id, name, aliases)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// MergeAuthors merges authors of the same user into the target: their books
+// are linked to the target and their names become its aliases, so later
+// imports under those names are linked to it too. Wikidata details are taken
+// from a merged author when the target has none.
+func (d *Database) MergeAuthors(targetID uint, sourceIDs []uint) (*entities.Author, error) {
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		var target entities.Author
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+		for _, sourceID := range sourceIDs {
+			if sourceID == targetID {
+				return ErrAuthorsNotMergeable
+			}
+			var source entities.Author
+			if err := tx.First(&source, sourceID).Error; err != nil {
+				return err
+			}
+			if source.UserID != target.UserID {
+				return ErrAuthorsNotMergeable
+			}
+			if err := mergeAuthorInto(tx, &target, &source); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.GetAuthorByID(targetID)
+}
+
+// mergeAuthorInto moves the source author's books and names to the target and
+// deletes the source. The target is updated with any details it was missing.
+func mergeAuthorInto(tx *gorm.DB, target, source *entities.Author) error {
+	if err := tx.Model(&entities.Book{}).Unscoped().Where("author_id = ?", source.ID).
+		UpdateColumn("author_id", target.ID).Error; err != nil {
+		return err
+	}
+
+	names := []string{source.Name}
+	var aliases []string
+	if err := tx.Model(&entities.AuthorAlias{}).Where("author_id = ?", source.ID).Pluck("name", &aliases).Error; err != nil {
+		return err
+	}
+	names = append(names, aliases...)
+	if err := tx.Where("author_id = ?", source.ID).Delete(&entities.AuthorAlias{}).Error; err != nil {
+		return err
+	}
+	if err := addAuthorAliases(tx, target.ID, target.Name, names); err != nil {
+		return err
+	}
+
+	if target.WikidataID == "" && source.WikidataID != "" {
+		target.WikidataID = source.WikidataID
+		target.PhotoURL = source.PhotoURL
+		target.BirthYear = source.BirthYear
+		target.DeathYear = source.DeathYear
+		target.Bio = source.Bio
+		target.WikipediaURL = source.WikipediaURL
+		target.EnrichedAt = source.EnrichedAt
+		if err := tx.Model(&entities.Author{}).Where("id = ?", target.ID).Updates(map[string]any{
+			"wikidata_id":   target.WikidataID,
+			"photo_url":     target.PhotoURL,
+			"birth_year":    target.BirthYear,
+			"death_year":    target.DeathYear,
+			"bio":           target.Bio,
+			"wikipedia_url": target.WikipediaURL,
+			"enriched_at":   target.EnrichedAt,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Delete(&entities.Author{}, source.ID).Error
+}
+
+// addAuthorAliases adds names to an author's aliases, skipping empty ones, the
+// author's own name and aliases it already has.
+func addAuthorAliases(tx *gorm.DB, authorID uint, authorName string, names []string) error {
+	for _, alias := range names {
+		alias = strings.TrimSpace(alias)
+		if alias == "" || strings.EqualFold(alias, authorName) {
+			continue
+		}
+		var existing int64
+		if err := tx.Model(&entities.AuthorAlias{}).
+			Where("author_id = ? AND name = ? COLLATE NOCASE", authorID, alias).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			continue
+		}
+		if err := tx.Create(&entities.AuthorAlias{AuthorID: authorID, Name: alias}).Error; err != nil {
+			return fmt.Errorf("failed to save alias %q: %w", alias, err)
+		}
+	}
+	return nil
+}
+
+// AddAuthorAlias adds a name the author is known by, so books imported under it
+// are linked to the author. Returns ErrAuthorAliasTaken when the name belongs
+// to another author of the user; those authors should be merged instead.
+func (d *Database) AddAuthorAlias(authorID uint, name string) (*entities.Author, error) {
+	name = strings.TrimSpace(name)
+	author, err := d.GetAuthorByID(authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := d.newAuthorResolver()
+	for _, candidate := range []string{name, entities.NormalizeAuthorName(name)} {
+		owner, err := resolver.find(author.UserID, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if owner != 0 && owner != author.ID {
+			return nil, ErrAuthorAliasTaken
+		}
+	}
+
+	if err := addAuthorAliases(d.DB, author.ID, author.Name, []string{name}); err != nil {
+		return nil, err
+	}
+	return d.GetAuthorByID(author.ID)
+}
+
+// RemoveAuthorAlias removes one of an author's aliases. Books already linked
+// through it stay linked.
+func (d *Database) RemoveAuthorAlias(authorID, aliasID uint) error {
+	result := d.DB.Where("id = ? AND author_id = ?", aliasID, authorID).Delete(&entities.AuthorAlias{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// backfillBookAuthors links books imported before authors existed to author records.
+func backfillBookAuthors(ctx context.Context, d *Database, report func(processed, total int)) error {
+	const batchSize = 500
+
+	pending := func() *gorm.DB {
+		return d.DB.Model(&entities.Book{}).Unscoped().
+			Where("(author_id IS NULL OR author_id = 0) AND author <> ''")
+	}
+
+	var total int64
+	if err := pending().Count(&total).Error; err != nil {
+		return err
+	}
+	report(0, int(total))
+
+	resolver := d.newAuthorResolver()
+	processed := 0
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var books []entities.Book
+		if err := pending().Where("id > ?", lastID).Order("id ASC").Limit(batchSize).
+			Select("id", "user_id", "author").Find(&books).Error; err != nil {
+			return err
+		}
+		if len(books) == 0 {
+			return nil
+		}
+
+		for _, book := range books {
+			authorID, err := resolver.resolve(book.UserID, book.Author)
+			if err != nil {
+				return err
+			}
+			if err := d.DB.Model(&entities.Book{}).Unscoped().Where("id = ?", book.ID).
+				UpdateColumn("author_id", authorID).Error; err != nil {
+				return err
+			}
+		}
+
+		lastID = books[len(books)-1].ID
+		processed += len(books)
+		report(processed, int(total))
+	}
+}
+
+// backfillAuthorNames brings authors created before name normalization to the
+// normalized form, merging those that turn out to be the same author.
+func backfillAuthorNames(ctx context.Context, d *Database, report func(processed, total int)) error {
+	var authors []entities.Author
+	if err := d.DB.Order("id ASC").Find(&authors).Error; err != nil {
+		return err
+	}
+	report(0, len(authors))
+
+	resolver := d.newAuthorResolver()
+	for i, author := range authors {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		normalized := entities.NormalizeAuthorName(author.Name)
+		if normalized == author.Name {
+			continue
+		}
+
+		owner, err := resolver.find(author.UserID, normalized)
+		if err != nil {
+			return err
+		}
+		err = d.DB.Transaction(func(tx *gorm.DB) error {
+			if owner != 0 && owner != author.ID {
+				var target entities.Author
+				if err := tx.First(&target, owner).Error; err != nil {
+					return err
+				}
+				return mergeAuthorInto(tx, &target, &author)
+			}
+			if err := tx.Model(&entities.Author{}).Where("id = ?", author.ID).Update("name", normalized).Error; err != nil {
+				return err
+			}
+			return addAuthorAliases(tx, author.ID, normalized, []string{author.Name})
+		})
+		if err != nil {
+			return err
+		}
+		report(i+1, len(authors))
+	}
+	report(len(authors), len(authors))
+	return nil
+}
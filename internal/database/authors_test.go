@@ -0,0 +1,252 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+func TestAuthors_LinkedOnSave(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	first := &entities.Book{Title: "War and Peace", Author: "Leo Tolstoy", UserID: 1}
+	require.NoError(t, db.SaveBook(first))
+	require.NotZero(t, first.AuthorID)
+
+	// Same author in another case, and a batch import, share the record
+	second := &entities.Book{Title: "Anna Karenina", Author: "leo tolstoy", UserID: 1}
+	require.NoError(t, db.SaveBook(second))
+	assert.Equal(t, first.AuthorID, second.AuthorID)
+
+	batch := []entities.Book{
+		{Title: "Resurrection", Author: "Leo Tolstoy", UserID: 1},
+		{Title: "Crime and Punishment", Author: "Fyodor Dostoevsky", UserID: 1},
+		{Title: "War and Peace", Author: "Leo Tolstoy", UserID: 2},
+	}
+	require.NoError(t, db.SaveBooks(batch))
+	assert.Equal(t, first.AuthorID, batch[0].AuthorID)
+	assert.NotEqual(t, first.AuthorID, batch[1].AuthorID)
+	assert.NotEqual(t, first.AuthorID, batch[2].AuthorID, "authors are per user")
+
+	// Re-importing keeps the link
+	first.ID = 0
+	first.AuthorID = 0
+	require.NoError(t, db.SaveBook(first))
+	assert.Equal(t, second.AuthorID, first.AuthorID)
+
+	authors, err := db.GetAuthors(1)
+	require.NoError(t, err)
+	require.Len(t, authors, 2)
+	assert.Equal(t, "Fyodor Dostoevsky", authors[0].Author.Name)
+	assert.Equal(t, int64(3), authors[1].BookCount)
+
+	books, err := db.GetAuthorBooks(first.AuthorID)
+	require.NoError(t, err)
+	require.Len(t, books, 3)
+	assert.Equal(t, "Anna Karenina", books[0].Title)
+}
+
+func TestAuthors_UpdateAuthorMetadata(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "War and Peace", Author: "Tolstoy, Leo", UserID: 1}
+	require.NoError(t, db.SaveBook(book))
+
+	savedID, err := db.UpdateAuthorMetadata(book.AuthorID, &metadata.AuthorMetadata{
+		WikidataID: "Q7243",
+		Name:       "Leo Tolstoy",
+		Aliases:    []string{"Lev Tolstoy", "leo tolstoy"},
+		BirthYear:  1828,
+		DeathYear:  1910,
+		Bio:        "Russian writer",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, book.AuthorID, savedID)
+
+	author, err := db.GetAuthorByID(book.AuthorID)
+	require.NoError(t, err)
+	assert.Equal(t, "Leo Tolstoy", author.Name)
+	assert.Equal(t, "Q7243", author.WikidataID)
+	assert.Equal(t, 1828, author.BirthYear)
+	assert.NotNil(t, author.EnrichedAt)
+	var aliases []string
+	for _, alias := range author.Aliases {
+		aliases = append(aliases, alias.Name)
+	}
+	assert.Equal(t, []string{"Lev Tolstoy", "Tolstoy, Leo"}, aliases)
+
+	// Books are matched by alias
+	other := &entities.Book{Title: "Hadji Murat", Author: "Lev Tolstoy", UserID: 1}
+	require.NoError(t, db.SaveBook(other))
+	assert.Equal(t, book.AuthorID, other.AuthorID)
+
+	toEnrich, err := db.GetAuthorsToEnrich()
+	require.NoError(t, err)
+	assert.Empty(t, toEnrich)
+}
+
+func TestAuthors_UpdateBookMetadataRelinks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Dubliners", Author: "J. Joyce", UserID: 1}
+	require.NoError(t, db.SaveBook(book))
+	oldAuthorID := book.AuthorID
+
+	require.NoError(t, db.UpdateBookMetadata(book.ID, map[string]any{"author": "James Joyce"}))
+	updated, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.NotZero(t, updated.AuthorID)
+	assert.NotEqual(t, oldAuthorID, updated.AuthorID)
+}
+
+func TestAuthors_Backfill(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Ulysses", Author: "James Joyce", UserID: 1}
+	require.NoError(t, db.SaveBook(book))
+	// Simulate a book imported before authors existed
+	require.NoError(t, db.DB.Model(&entities.Book{}).Where("id = ?", book.ID).UpdateColumn("author_id", 0).Error)
+
+	require.NoError(t, db.RunPendingBackfills(context.Background()))
+
+	updated, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, book.AuthorID, updated.AuthorID)
+}
+
+func TestAuthors_NormalizedNamesShareAuthor(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	first := &entities.Book{Title: "War and Peace", Author: "Tolstoy, Leo", UserID: 1}
+	second := &entities.Book{Title: "Anna Karenina", Author: "Leo  Tolstoy", UserID: 1}
+	require.NoError(t, db.SaveBook(first))
+	require.NoError(t, db.SaveBook(second))
+	assert.Equal(t, first.AuthorID, second.AuthorID)
+
+	author, err := db.GetAuthorByID(first.AuthorID)
+	require.NoError(t, err)
+	assert.Equal(t, "Leo Tolstoy", author.Name)
+	require.Len(t, author.Aliases, 1)
+	assert.Equal(t, "Tolstoy, Leo", author.Aliases[0].Name)
+
+	// Suffixes are not first names
+	king := &entities.Book{Title: "Strength to Love", Author: "Martin Luther King, Jr.", UserID: 1}
+	require.NoError(t, db.SaveBook(king))
+	kingAuthor, err := db.GetAuthorByID(king.AuthorID)
+	require.NoError(t, err)
+	assert.Equal(t, "Martin Luther King, Jr.", kingAuthor.Name)
+}
+
+func TestAuthors_MergeAuthors(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	english := &entities.Book{Title: "War and Peace", Author: "Leo Tolstoy", UserID: 1}
+	russian := &entities.Book{Title: "Война и мир", Author: "Лев Толстой", UserID: 1}
+	other := &entities.Book{Title: "Ulysses", Author: "James Joyce", UserID: 2}
+	require.NoError(t, db.SaveBooks([]entities.Book{*english, *russian, *other}))
+	english, _ = db.GetBookByTitleAndAuthorForUser("War and Peace", "Leo Tolstoy", 1)
+	russian, _ = db.GetBookByTitleAndAuthorForUser("Война и мир", "Лев Толстой", 1)
+	other, _ = db.GetBookByTitleAndAuthorForUser("Ulysses", "James Joyce", 2)
+	require.NotEqual(t, english.AuthorID, russian.AuthorID)
+
+	_, err := db.MergeAuthors(english.AuthorID, []uint{other.AuthorID})
+	assert.ErrorIs(t, err, ErrAuthorsNotMergeable, "authors of other users are not merged")
+
+	merged, err := db.MergeAuthors(english.AuthorID, []uint{russian.AuthorID})
+	require.NoError(t, err)
+	require.Len(t, merged.Aliases, 1)
+	assert.Equal(t, "Лев Толстой", merged.Aliases[0].Name)
+
+	books, err := db.GetAuthorBooks(english.AuthorID)
+	require.NoError(t, err)
+	assert.Len(t, books, 2)
+	_, err = db.GetAuthorByID(russian.AuthorID)
+	assert.Error(t, err)
+
+	// Later imports under the merged name are linked to the kept author
+	more := &entities.Book{Title: "Детство", Author: "Лев Толстой", UserID: 1}
+	require.NoError(t, db.SaveBook(more))
+	assert.Equal(t, english.AuthorID, more.AuthorID)
+}
+
+func TestAuthors_Aliases(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tolstoy := &entities.Book{Title: "War and Peace", Author: "Leo Tolstoy", UserID: 1}
+	joyce := &entities.Book{Title: "Ulysses", Author: "James Joyce", UserID: 1}
+	require.NoError(t, db.SaveBook(tolstoy))
+	require.NoError(t, db.SaveBook(joyce))
+
+	author, err := db.AddAuthorAlias(tolstoy.AuthorID, "Lev Tolstoy")
+	require.NoError(t, err)
+	require.Len(t, author.Aliases, 1)
+
+	_, err = db.AddAuthorAlias(tolstoy.AuthorID, "Joyce, James")
+	assert.ErrorIs(t, err, ErrAuthorAliasTaken)
+
+	book := &entities.Book{Title: "Hadji Murat", Author: "Lev Tolstoy", UserID: 1}
+	require.NoError(t, db.SaveBook(book))
+	assert.Equal(t, tolstoy.AuthorID, book.AuthorID)
+
+	require.NoError(t, db.RemoveAuthorAlias(tolstoy.AuthorID, author.Aliases[0].ID))
+	assert.Error(t, db.RemoveAuthorAlias(tolstoy.AuthorID, author.Aliases[0].ID))
+}
+
+func TestAuthors_UpdateAuthorMetadataMergesSamePerson(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	english := &entities.Book{Title: "War and Peace", Author: "Leo Tolstoy", UserID: 1}
+	russian := &entities.Book{Title: "Война и мир", Author: "Лев Толстой", UserID: 1}
+	require.NoError(t, db.SaveBook(english))
+	require.NoError(t, db.SaveBook(russian))
+
+	savedID, err := db.UpdateAuthorMetadata(russian.AuthorID, &metadata.AuthorMetadata{WikidataID: "Q7243", Name: "Leo Tolstoy"})
+	require.NoError(t, err)
+	assert.Equal(t, english.AuthorID, savedID)
+
+	author, err := db.GetAuthorByID(english.AuthorID)
+	require.NoError(t, err)
+	assert.Equal(t, "Q7243", author.WikidataID)
+	require.Len(t, author.Aliases, 1)
+	assert.Equal(t, "Лев Толстой", author.Aliases[0].Name)
+}
+
+func TestAuthors_BackfillNames(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Authors created before names were normalized
+	require.NoError(t, db.DB.Create(&[]entities.Author{
+		{UserID: 1, Name: "Tolstoy, Leo"},
+		{UserID: 1, Name: "Leo Tolstoy"},
+		{UserID: 1, Name: "Joyce, James"},
+	}).Error)
+	var authors []entities.Author
+	require.NoError(t, db.DB.Order("id ASC").Find(&authors).Error)
+	require.NoError(t, db.DB.Create(&entities.Book{Title: "War and Peace", Author: "Tolstoy, Leo", UserID: 1, AuthorID: authors[0].ID}).Error)
+
+	require.NoError(t, backfillAuthorNames(context.Background(), db, func(int, int) {}))
+
+	summaries, err := db.GetAuthors(1)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "Leo Tolstoy", summaries[0].Author.Name)
+	assert.Equal(t, authors[1].ID, summaries[0].Author.ID)
+
+	joyce, err := db.GetAuthorByID(authors[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "James Joyce", joyce.Name)
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// Batch loaders fetch the records related to many parents at once, so the GraphQL
+// endpoint loads a nested field with one query per chunk of parents instead of
+// one query per parent. Results are grouped by parent ID; parents without records
+// are absent from the returned map.
+
+// forEachChunk calls fn with consecutive chunks of at most bulkQueryChunkSize IDs.
+func forEachChunk(ids []uint, fn func(chunk []uint) error) error {
+	for start := 0; start < len(ids); start += bulkQueryChunkSize {
+		if err := fn(ids[start:min(start+bulkQueryChunkSize, len(ids))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListBooks returns a page of books ordered by title, with tags and source but
+// without highlights. A zero userID lists every user's books, and a non-empty
+// search matches the title or author.
+func (d *Database) ListBooks(userID uint, search string, limit, offset int) ([]entities.Book, error) {
+	query := d.DB.Preload("Tags").Preload("Source").Order("title ASC, id ASC")
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("LOWER(title) LIKE LOWER(?) OR LOWER(author) LIKE LOWER(?)", pattern, pattern)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var books []entities.Book
+	err := query.Find(&books).Error
+	return books, err
+}
+
+// GetBooksByIDs returns the books with the given IDs, with tags and source but
+// without highlights.
+func (d *Database) GetBooksByIDs(ids []uint) (map[uint]entities.Book, error) {
+	books := make(map[uint]entities.Book, len(ids))
+	err := forEachChunk(ids, func(chunk []uint) error {
+		var found []entities.Book
+		if err := d.DB.Preload("Tags").Preload("Source").Where("id IN ?", chunk).Find(&found).Error; err != nil {
+			return err
+		}
+		for _, book := range found {
+			books[book.ID] = book
+		}
+		return nil
+	})
+	return books, err
+}
+
+// GetHighlightsByBookIDs returns the highlights of each book in reading order,
+// with tags preloaded. A positive limit returns only the first limit highlights
+// of each book.
+func (d *Database) GetHighlightsByBookIDs(bookIDs []uint, limit int) (map[uint][]entities.Highlight, error) {
+	highlights := make(map[uint][]entities.Highlight)
+	err := forEachChunk(bookIDs, func(chunk []uint) error {
+		query := d.DB.Preload("Tags").Where("book_id IN ?", chunk)
+		if limit > 0 {
+			ranked := d.DB.Model(&entities.Highlight{}).
+				Select("id, ROW_NUMBER() OVER (PARTITION BY book_id ORDER BY location_value ASC, highlighted_at ASC) AS position").
+				Where("book_id IN ?", chunk)
+			query = query.Where("id IN (?)", d.DB.Table("(?) AS ranked", ranked).Select("id").Where("position <= ?", limit))
+		}
+
+		var found []entities.Highlight
+		err := query.Order("location_value ASC, highlighted_at ASC").Find(&found).Error
+		if err != nil {
+			return err
+		}
+		for _, highlight := range found {
+			highlights[highlight.BookID] = append(highlights[highlight.BookID], highlight)
+		}
+		return nil
+	})
+	return highlights, err
+}
+
+// CountHighlightsByBookIDs returns the number of highlights of each book.
+func (d *Database) CountHighlightsByBookIDs(bookIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64)
+	err := forEachChunk(bookIDs, func(chunk []uint) error {
+		var rows []struct {
+			BookID uint
+			Count  int64
+		}
+		err := d.DB.Model(&entities.Highlight{}).Select("book_id, COUNT(*) AS count").
+			Where("book_id IN ?", chunk).Group("book_id").Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			counts[row.BookID] = row.Count
+		}
+		return nil
+	})
+	return counts, err
+}
+
+// GetWordsByBookIDs returns the vocabulary words saved from each book, with their
+// definitions. Candidate words awaiting confirmation are left out.
+func (d *Database) GetWordsByBookIDs(bookIDs []uint) (map[uint][]entities.Word, error) {
+	return d.groupWords("book_id", bookIDs, func(word entities.Word) uint { return *word.BookID })
+}
+
+// GetWordsByHighlightIDs returns the vocabulary words saved from each highlight,
+// with their definitions. Candidate words awaiting confirmation are left out.
+func (d *Database) GetWordsByHighlightIDs(highlightIDs []uint) (map[uint][]entities.Word, error) {
+	return d.groupWords("highlight_id", highlightIDs, func(word entities.Word) uint { return *word.HighlightID })
+}
+
+func (d *Database) groupWords(column string, ids []uint, parentID func(entities.Word) uint) (map[uint][]entities.Word, error) {
+	words := make(map[uint][]entities.Word)
+	err := forEachChunk(ids, func(chunk []uint) error {
+		var found []entities.Word
+		err := d.DB.Preload("Definitions", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+			Where(column+" IN ?", chunk).
+			Where("status <> ?", entities.WordStatusCandidate).
+			Order("word ASC").Find(&found).Error
+		if err != nil {
+			return err
+		}
+		for _, word := range found {
+			words[parentID(word)] = append(words[parentID(word)], word)
+		}
+		return nil
+	})
+	return words, err
+}
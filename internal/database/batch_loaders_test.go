@@ -0,0 +1,62 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchLoaders(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	first := saveTrashTestBook(t, db, "First", "One", "Two")
+	second := saveTrashTestBook(t, db, "Second", "Three")
+	empty := saveTrashTestBook(t, db, "Empty")
+	ids := []uint{first.ID, second.ID, empty.ID}
+
+	books, err := db.ListBooks(0, "sec", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, books, 1)
+	assert.Equal(t, "Second", books[0].Title)
+	assert.Empty(t, books[0].Highlights)
+
+	byID, err := db.GetBooksByIDs(ids)
+	require.NoError(t, err)
+	assert.Len(t, byID, 3)
+	assert.Equal(t, "Empty", byID[empty.ID].Title)
+
+	highlights, err := db.GetHighlightsByBookIDs(ids, 0)
+	require.NoError(t, err)
+	require.Len(t, highlights[first.ID], 2)
+	assert.Equal(t, "One", highlights[first.ID][0].Text)
+	assert.Len(t, highlights[second.ID], 1)
+	assert.NotContains(t, highlights, empty.ID)
+
+	highlights, err = db.GetHighlightsByBookIDs(ids, 1)
+	require.NoError(t, err)
+	require.Len(t, highlights[first.ID], 1, "the limit applies to each book")
+	assert.Equal(t, "One", highlights[first.ID][0].Text)
+	assert.Len(t, highlights[second.ID], 1)
+
+	counts, err := db.CountHighlightsByBookIDs(ids)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint]int64{first.ID: 2, second.ID: 1}, counts)
+
+	highlightID := first.Highlights[0].ID
+	require.NoError(t, db.AddWord(&entities.Word{Word: "zephyr", BookID: &first.ID, HighlightID: &highlightID}))
+	require.NoError(t, db.AddWord(&entities.Word{Word: "aplomb", BookID: &first.ID}))
+	require.NoError(t, db.AddWord(&entities.Word{Word: "maybe", BookID: &first.ID, Status: entities.WordStatusCandidate}))
+
+	words, err := db.GetWordsByBookIDs(ids)
+	require.NoError(t, err)
+	require.Len(t, words[first.ID], 2)
+	assert.Equal(t, "aplomb", words[first.ID][0].Word)
+
+	words, err = db.GetWordsByHighlightIDs([]uint{highlightID})
+	require.NoError(t, err)
+	require.Len(t, words[highlightID], 1)
+	assert.Equal(t, "zephyr", words[highlightID][0].Word)
+}
@@ -0,0 +1,22 @@
+package database
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// SetBookArchived archives a book, keeping it out of the default book and
+// highlight lists, search, exports and stats, or brings it back.
+func (d *Database) SetBookArchived(bookID uint, isArchived bool) error {
+	result := d.DB.Model(&entities.Book{}).
+		Where("id = ?", bookID).
+		Update("is_archived", isArchived)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestSetBookArchived(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	reference := &entities.Book{Title: "SQL Cookbook", Author: "Anthony Molinaro", Highlights: []entities.Highlight{{Text: "Use window functions"}}}
+	novel := &entities.Book{Title: "Dune", Author: "Frank Herbert", Highlights: []entities.Highlight{{Text: "Fear is the mind-killer"}}}
+	require.NoError(t, db.SaveBook(reference))
+	require.NoError(t, db.SaveBook(novel))
+
+	require.NoError(t, db.SetBookArchived(reference.ID, true))
+	assert.ErrorIs(t, db.SetBookArchived(9999, true), gorm.ErrRecordNotFound)
+
+	texts := func(filter entities.HighlightFilter) []string {
+		highlights, _, err := db.ListHighlights(filter, 0, 0)
+		require.NoError(t, err)
+		var texts []string
+		for _, h := range highlights {
+			texts = append(texts, h.Text)
+		}
+		return texts
+	}
+	assert.Equal(t, []string{"Fear is the mind-killer"}, texts(entities.HighlightFilter{}))
+	assert.Len(t, texts(entities.HighlightFilter{IncludeArchived: true}), 2)
+	assert.Equal(t, []string{"Use window functions"}, texts(entities.HighlightFilter{BookID: reference.ID}),
+		"an archived book still lists its own highlights")
+
+	// Re-importing the book keeps it archived
+	reimported := &entities.Book{Title: "SQL Cookbook", Author: "Anthony Molinaro", Highlights: []entities.Highlight{{Text: "Use window functions"}}}
+	require.NoError(t, db.SaveBook(reimported))
+	book, err := db.GetBookWithoutHighlights(reference.ID)
+	require.NoError(t, err)
+	assert.True(t, book.IsArchived)
+
+	require.NoError(t, db.SetBookArchived(reference.ID, false))
+	assert.Len(t, texts(entities.HighlightFilter{}), 2)
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// refreshBookCounters recounts the highlights, favourite highlights and latest
+// highlight time stored on books from their highlights outside the trash.
+// Highlights without a time do not count towards the latest one.
+func refreshBookCounters(tx *gorm.DB, bookIDs ...uint) error {
+	const highlightsOfBook = "FROM highlights WHERE highlights.book_id = books.id AND highlights.deleted_at IS NULL"
+	return forEachChunk(uniqueIDs(bookIDs), func(chunk []uint) error {
+		return tx.Unscoped().Model(&entities.Book{}).
+			Where("id IN ?", chunk).
+			UpdateColumns(map[string]any{
+				"highlight_count":     gorm.Expr("(SELECT COUNT(*) " + highlightsOfBook + ")"),
+				"favourite_count":     gorm.Expr("(SELECT COUNT(*) "+highlightsOfBook+" AND highlights.is_favorite = ?)", true),
+				"last_highlighted_at": gorm.Expr("(SELECT MAX(highlights.highlighted_at) "+highlightsOfBook+" AND highlights.highlighted_at > ?)", time.Time{}),
+			}).Error
+	})
+}
+
+// refreshHighlightBookCounters refreshes the counters of the books of the
+// given highlights, including ones in the trash.
+func refreshHighlightBookCounters(tx *gorm.DB, highlightIDs ...uint) error {
+	var bookIDs []uint
+	err := forEachChunk(highlightIDs, func(chunk []uint) error {
+		var ids []uint
+		if err := tx.Unscoped().Model(&entities.Highlight{}).
+			Where("id IN ?", chunk).Distinct().Pluck("book_id", &ids).Error; err != nil {
+			return err
+		}
+		bookIDs = append(bookIDs, ids...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return refreshBookCounters(tx, bookIDs...)
+}
+
+// uniqueIDs returns ids without zeros and repeats, in their original order.
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// GetBooksWithoutHighlights returns all books with their tags and source but
+// without highlights, for lists that only need the books' highlight counters.
+func (d *Database) GetBooksWithoutHighlights() ([]entities.Book, error) {
+	var books []entities.Book
+	err := d.DB.Preload("Tags").Preload("Source").Find(&books).Error
+	return books, err
+}
+
+// backfillBookCounters counts the highlights of existing books.
+func backfillBookCounters(ctx context.Context, d *Database, report func(processed, total int)) error {
+	const batchSize = 500
+
+	var total int64
+	if err := d.DB.Unscoped().Model(&entities.Book{}).Count(&total).Error; err != nil {
+		return err
+	}
+	report(0, int(total))
+
+	processed := 0
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var ids []uint
+		if err := d.DB.Unscoped().Model(&entities.Book{}).Where("id > ?", lastID).Order("id ASC").
+			Limit(batchSize).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := refreshBookCounters(d.DB, ids...); err != nil {
+			return err
+		}
+
+		lastID = ids[len(ids)-1]
+		processed += len(ids)
+		report(processed, int(total))
+	}
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func assertBookCounters(t *testing.T, db *Database, bookID uint, highlights, favourites int, last time.Time) {
+	t.Helper()
+	var book entities.Book
+	require.NoError(t, db.DB.Unscoped().First(&book, bookID).Error)
+	assert.Equal(t, highlights, book.HighlightCount, "highlight_count")
+	assert.Equal(t, favourites, book.FavouriteCount, "favourite_count")
+	if last.IsZero() {
+		assert.Nil(t, book.LastHighlightedAt, "last_highlighted_at")
+	} else if assert.NotNil(t, book.LastHighlightedAt, "last_highlighted_at") {
+		assert.True(t, last.Equal(*book.LastHighlightedAt), "last_highlighted_at = %v, expected %v", *book.LastHighlightedAt, last)
+	}
+}
+
+func TestBookCounters(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	march := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	april := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	book := &entities.Book{Title: "Dune", Author: "Frank Herbert", Highlights: []entities.Highlight{
+		{Text: "Fear is the mind-killer", HighlightedAt: march},
+		{Text: "Undated"},
+	}}
+	require.NoError(t, db.SaveBook(book))
+	assertBookCounters(t, db, book.ID, 2, 0, march)
+
+	// Reimporting adds only the new highlight
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune", Author: "Frank Herbert", Highlights: []entities.Highlight{
+		{Text: "Fear is the mind-killer", HighlightedAt: march},
+		{Text: "The spice must flow", HighlightedAt: april},
+	}}))
+	assertBookCounters(t, db, book.ID, 3, 0, april)
+
+	added := &entities.Highlight{BookID: book.ID, Text: "Typed in by hand"}
+	require.NoError(t, db.CreateHighlight(added))
+	require.NoError(t, db.SetHighlightFavourite(added.ID, true))
+	assertBookCounters(t, db, book.ID, 4, 1, april)
+
+	// Trashed highlights are not counted until restored
+	require.NoError(t, db.DeleteHighlight(added.ID))
+	assertBookCounters(t, db, book.ID, 3, 0, april)
+	_, err := db.RestoreHighlight(added.ID)
+	require.NoError(t, err)
+	assertBookCounters(t, db, book.ID, 4, 1, april)
+
+	saved, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	for _, h := range saved.Highlights {
+		if h.Text == "The spice must flow" {
+			require.NoError(t, db.DeleteHighlightPermanently(h.ID, 0))
+		}
+	}
+	assertBookCounters(t, db, book.ID, 3, 1, march)
+
+	require.NoError(t, db.DeleteBook(book.ID))
+	assertBookCounters(t, db, book.ID, 0, 0, time.Time{})
+	_, err = db.RestoreBook(book.ID)
+	require.NoError(t, err)
+	assertBookCounters(t, db, book.ID, 3, 1, march)
+}
+
+func TestBookCounters_BulkImport(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, db.SaveBooks([]entities.Book{
+		{Title: "Emma", Author: "Jane Austen", Highlights: []entities.Highlight{{Text: "Silly things do cease to be silly"}}},
+		{Title: "Emma", Author: "Jane Austen", Highlights: []entities.Highlight{{Text: "A mind lively and at ease"}}},
+	}))
+
+	books, err := db.GetBooksWithoutHighlights()
+	require.NoError(t, err)
+	require.Len(t, books, 1)
+	assert.Empty(t, books[0].Highlights)
+	assert.Equal(t, 2, books[0].HighlightCount)
+}
+
+func TestBackfillBookCounters(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	march := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	book := &entities.Book{Title: "Dune", Author: "Frank Herbert", Highlights: []entities.Highlight{
+		{Text: "Fear is the mind-killer", HighlightedAt: march, IsFavorite: true},
+		{Text: "The spice must flow"},
+	}}
+	require.NoError(t, db.SaveBook(book))
+
+	// Simulate a book saved before the counters existed
+	require.NoError(t, db.DB.Model(&entities.Book{}).Where("id = ?", book.ID).
+		UpdateColumns(map[string]any{"highlight_count": 0, "favourite_count": 0, "last_highlighted_at": nil}).Error)
+
+	require.NoError(t, backfillBookCounters(context.Background(), db, func(processed, total int) {}))
+	assertBookCounters(t, db, book.ID, 2, 1, march)
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// GetBookWithoutHighlights returns a book with its tags, collections, source
+// and journal, for pages that load its highlights a page at a time.
+func (d *Database) GetBookWithoutHighlights(id uint) (*entities.Book, error) {
+	var book entities.Book
+	err := d.DB.Preload("Tags").Preload("Collections").Preload("Source").Preload("Notes", orderBookNotes).
+		First(&book, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// GetBookHighlightColors counts a book's highlights per stored color,
+// including those without a color under "".
+func (d *Database) GetBookHighlightColors(bookID uint) (map[string]int, error) {
+	var rows []struct {
+		Color string
+		Count int
+	}
+	err := d.DB.Model(&entities.Highlight{}).Select("color, COUNT(*) AS count").
+		Where("book_id = ?", bookID).Group("color").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Color] += row.Count
+	}
+	return counts, nil
+}
+
+// ListBookHighlights returns up to limit highlights matching the filter in
+// reading order, as on the book page, with their tags and links preloaded.
+// The listing continues after the highlight afterID, or starts at the
+// beginning for 0, and hasMore reports whether highlights remain after
+// the returned ones.
+func (d *Database) ListBookHighlights(filter entities.HighlightFilter, afterID uint, limit int) (highlights []entities.Highlight, hasMore bool, err error) {
+	query := d.DB.Preload("Tags").Preload("Links.To.Book").Preload("Backlinks").
+		Scopes(highlightFilterScopes(filter)...).
+		Order("highlights.location_value ASC, highlights.highlighted_at ASC, highlights.id ASC")
+	if afterID > 0 {
+		// Keyset pagination on the sort columns keeps pages fast deep into a
+		// book, and they do not shift when highlights are added or deleted
+		after := d.DB.Session(&gorm.Session{NewDB: true}).Unscoped().Model(&entities.Highlight{}).
+			Select("location_value", "highlighted_at", "id").Where("id = ?", afterID)
+		query = query.Where("(highlights.location_value, highlights.highlighted_at, highlights.id) > (?)", after)
+	}
+	if limit > 0 {
+		query = query.Limit(limit + 1)
+	}
+
+	if err := query.Find(&highlights).Error; err != nil {
+		return nil, false, err
+	}
+	if limit > 0 && len(highlights) > limit {
+		return highlights[:limit], true, nil
+	}
+	return highlights, false, nil
+}
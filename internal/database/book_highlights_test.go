@@ -0,0 +1,67 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestListBookHighlights(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	book := &entities.Book{Title: "Middlemarch", Author: "George Eliot"}
+	for i := 0; i < 7; i++ {
+		book.Highlights = append(book.Highlights, entities.Highlight{
+			Text:          string(rune('a' + i)),
+			LocationValue: 10 * (i / 2), // Pairs share a location
+			HighlightedAt: day.Add(time.Duration(i%2) * time.Hour),
+			Color:         []string{"yellow", "#FFFF00", "blue"}[i%3],
+		})
+	}
+	require.NoError(t, db.SaveBook(book))
+
+	filter := entities.HighlightFilter{BookID: book.ID}
+	var texts []string
+	var afterID uint
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 4)
+		page, hasMore, err := db.ListBookHighlights(filter, afterID, 3)
+		require.NoError(t, err)
+		for _, h := range page {
+			texts = append(texts, h.Text)
+		}
+		if !hasMore {
+			assert.Len(t, page, 1)
+			break
+		}
+		require.Len(t, page, 3)
+		afterID = page[len(page)-1].ID
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g"}, texts)
+
+	all, hasMore, err := db.ListBookHighlights(filter, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 7)
+	assert.False(t, hasMore)
+
+	filter.Colors = []string{"blue"}
+	blue, _, err := db.ListBookHighlights(filter, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, blue, 2)
+	assert.Equal(t, "c", blue[0].Text)
+
+	colors, err := db.GetBookHighlightColors(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"yellow": 3, "#FFFF00": 2, "blue": 2}, colors)
+
+	loaded, err := db.GetBookWithoutHighlights(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Middlemarch", loaded.Title)
+	assert.Empty(t, loaded.Highlights)
+}
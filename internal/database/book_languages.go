@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/langdetect"
+)
+
+// languageSampleSize is the number of characters of highlight text read to
+// detect a book's language; more rarely changes the answer.
+const languageSampleSize = 5000
+
+// DetectBookLanguages detects the language of books from their highlights and
+// returns the number of books whose language changed. Only books without a
+// language are looked at unless redetect is set. Books with too little text
+// to tell keep an empty language.
+func (d *Database) DetectBookLanguages(ctx context.Context, redetect bool) (int, error) {
+	return d.detectBookLanguages(ctx, redetect, func(processed, total int) {})
+}
+
+// backfillBookLanguages detects the language of books imported before
+// language detection.
+func backfillBookLanguages(ctx context.Context, d *Database, report func(processed, total int)) error {
+	_, err := d.detectBookLanguages(ctx, false, report)
+	return err
+}
+
+func (d *Database) detectBookLanguages(ctx context.Context, redetect bool, report func(processed, total int)) (int, error) {
+	const batchSize = 100
+
+	pending := func() *gorm.DB {
+		query := d.DB.Model(&entities.Book{})
+		if !redetect {
+			query = query.Where("language = '' OR language IS NULL")
+		}
+		return query
+	}
+
+	var total int64
+	if err := pending().Count(&total).Error; err != nil {
+		return 0, err
+	}
+	report(0, int(total))
+
+	processed, changed := 0, 0
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return changed, err
+		}
+
+		var books []entities.Book
+		if err := pending().Where("id > ?", lastID).Order("id ASC").Limit(batchSize).
+			Select("id", "language").Find(&books).Error; err != nil {
+			return changed, err
+		}
+		if len(books) == 0 {
+			return changed, nil
+		}
+
+		for _, book := range books {
+			sample, err := d.languageSample(book.ID)
+			if err != nil {
+				return changed, err
+			}
+			language := langdetect.Detect(sample)
+			if language == book.Language {
+				continue
+			}
+			if err := d.DB.Model(&entities.Book{}).Where("id = ?", book.ID).
+				UpdateColumn("language", language).Error; err != nil {
+				return changed, err
+			}
+			changed++
+		}
+
+		lastID = books[len(books)-1].ID
+		processed += len(books)
+		report(processed, int(total))
+	}
+}
+
+// languageSample joins the text of a book's first highlights, up to
+// languageSampleSize characters.
+func (d *Database) languageSample(bookID uint) (string, error) {
+	var texts []string
+	if err := d.DB.Model(&entities.Highlight{}).Where("book_id = ?", bookID).
+		Order("id ASC").Limit(100).Pluck("text", &texts).Error; err != nil {
+		return "", err
+	}
+
+	var sample strings.Builder
+	for _, text := range texts {
+		if sample.Len() >= languageSampleSize {
+			break
+		}
+		sample.WriteString(text)
+		sample.WriteString("\n")
+	}
+	return sample.String(), nil
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestDetectBookLanguages(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	english := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", UserID: 1, Highlights: []entities.Highlight{
+		{Text: "You have power over your mind, not outside events. Realize this, and you will find strength.", LocationValue: 1},
+	}}
+	german := &entities.Book{Title: "Der Steppenwolf", Author: "Hermann Hesse", UserID: 1, Highlights: []entities.Highlight{
+		{Text: "Ich wollte ja nichts als das zu leben versuchen, was von selber aus mir heraus wollte.", LocationValue: 1},
+		{Text: "Warum war das so schwer?", LocationValue: 2},
+	}}
+	empty := &entities.Book{Title: "Untitled", Author: "Unknown", UserID: 1}
+	require.NoError(t, db.SaveBook(english))
+	require.NoError(t, db.SaveBook(german))
+	require.NoError(t, db.SaveBook(empty))
+
+	changed, err := db.DetectBookLanguages(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed)
+
+	for id, want := range map[uint]string{english.ID: "en", german.ID: "de", empty.ID: ""} {
+		book, err := db.GetBookByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, want, book.Language, book.Title)
+	}
+
+	// Detected languages survive a re-import and are only redone on request
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Meditations", Author: "Marcus Aurelius", UserID: 1}))
+	require.NoError(t, db.UpdateBookMetadata(german.ID, map[string]any{"language": "fr"}))
+
+	changed, err = db.DetectBookLanguages(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed)
+
+	changed, err = db.DetectBookLanguages(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	book, err := db.GetBookByID(german.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "de", book.Language)
+	book, err = db.GetBookByID(english.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "en", book.Language)
+}
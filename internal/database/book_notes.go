@@ -0,0 +1,54 @@
+package database
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// orderBookNotes preloads a book's journal in the order it was written.
+func orderBookNotes(db *gorm.DB) *gorm.DB {
+	return db.Order("date ASC, id ASC")
+}
+
+func (d *Database) CreateBookNote(note *entities.BookNote) error {
+	return d.DB.Create(note).Error
+}
+
+// GetBookNotes returns a book's journal entries, newest first.
+func (d *Database) GetBookNotes(bookID uint) ([]entities.BookNote, error) {
+	var notes []entities.BookNote
+	err := d.DB.Where("book_id = ?", bookID).Order("date DESC, id DESC").Find(&notes).Error
+	return notes, err
+}
+
+func (d *Database) GetBookNote(id uint) (*entities.BookNote, error) {
+	var note entities.BookNote
+	if err := d.DB.First(&note, id).Error; err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// UpdateBookNote saves a journal entry's date and text.
+func (d *Database) UpdateBookNote(note *entities.BookNote) error {
+	result := d.DB.Model(note).Select("date", "text").Updates(note)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (d *Database) DeleteBookNote(id uint) error {
+	result := d.DB.Delete(&entities.BookNote{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestBookNotes(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Middlemarch", Author: "George Eliot", Highlights: []entities.Highlight{{Text: "It is a narrow mind"}}}
+	require.NoError(t, db.SaveBook(book))
+
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	later := &entities.BookNote{BookID: book.ID, Date: day(20), Text: "Dorothea's choice makes sense now."}
+	earlier := &entities.BookNote{BookID: book.ID, Date: day(2), Text: "Slow start."}
+	require.NoError(t, db.CreateBookNote(later))
+	require.NoError(t, db.CreateBookNote(earlier))
+
+	notes, err := db.GetBookNotes(book.ID)
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, later.ID, notes[0].ID, "newest first")
+
+	loaded, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	require.Len(t, loaded.Notes, 2)
+	assert.Equal(t, "Slow start.", loaded.Notes[0].Text, "books carry their journal oldest first")
+
+	earlier.Text = "Slow start, but worth it."
+	earlier.Date = day(3)
+	require.NoError(t, db.UpdateBookNote(earlier))
+	note, err := db.GetBookNote(earlier.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Slow start, but worth it.", note.Text)
+	assert.True(t, day(3).Equal(note.Date))
+
+	require.NoError(t, db.DeleteBookNote(later.ID))
+	assert.ErrorIs(t, db.DeleteBookNote(later.ID), gorm.ErrRecordNotFound)
+
+	// Re-importing the book keeps its journal
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Middlemarch", Author: "George Eliot", Highlights: []entities.Highlight{{Text: "It is a narrow mind"}}}))
+	notes, err = db.GetBookNotes(book.ID)
+	require.NoError(t, err)
+	assert.Len(t, notes, 1)
+
+	require.NoError(t, db.DeleteBookPermanently(book.ID, 0))
+	notes, err = db.GetBookNotes(book.ID)
+	require.NoError(t, err)
+	assert.Empty(t, notes, "the journal goes with the book")
+}
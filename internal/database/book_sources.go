@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// BookSource is what one source contributed to a book merged from several.
+type BookSource struct {
+	SourceID        uint      `json:"source_id"`
+	Name            string    `json:"name"`         // Empty for highlights without a source
+	DisplayName     string    `json:"display_name"` // Name when the source has no display name
+	Highlights      int       `json:"highlights"`
+	FirstImportedAt time.Time `json:"first_imported_at"`
+	LastImportedAt  time.Time `json:"last_imported_at"` // When the source last added a highlight
+}
+
+// GetBookSources returns the sources that contributed highlights to a book,
+// the one with most highlights first. A highlight counts for the source that
+// first imported it. Deleted highlights are not counted.
+func (d *Database) GetBookSources(bookID uint) ([]BookSource, error) {
+	var book entities.Book
+	if err := d.DB.Select("id").First(&book, bookID).Error; err != nil {
+		return nil, err
+	}
+
+	var highlights []entities.Highlight
+	err := d.DB.Select("source_id", "created_at").Where("book_id = ?", bookID).Find(&highlights).Error
+	if err != nil {
+		return nil, err
+	}
+
+	bySource := make(map[uint]*BookSource)
+	for _, h := range highlights {
+		source, ok := bySource[h.SourceID]
+		if !ok {
+			source = &BookSource{SourceID: h.SourceID, FirstImportedAt: h.CreatedAt, LastImportedAt: h.CreatedAt}
+			bySource[h.SourceID] = source
+		}
+		source.Highlights++
+		if h.CreatedAt.Before(source.FirstImportedAt) {
+			source.FirstImportedAt = h.CreatedAt
+		}
+		if h.CreatedAt.After(source.LastImportedAt) {
+			source.LastImportedAt = h.CreatedAt
+		}
+	}
+
+	sources := make([]BookSource, 0, len(bySource))
+	for id, source := range bySource {
+		if id != 0 {
+			var s entities.Source
+			if err := d.DB.First(&s, id).Error; err == nil {
+				source.Name = s.Name
+				source.DisplayName = s.DisplayName
+			}
+		}
+		if source.DisplayName == "" {
+			source.DisplayName = source.Name
+		}
+		sources = append(sources, *source)
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].Highlights != sources[j].Highlights {
+			return sources[i].Highlights > sources[j].Highlights
+		}
+		return sources[i].SourceID < sources[j].SourceID
+	})
+	return sources, nil
+}
+
+// backfillHighlightSources attributes highlights imported without a source of
+// their own to the source of their book.
+func backfillHighlightSources(ctx context.Context, d *Database, report func(processed, total int)) error {
+	pending := func() *gorm.DB {
+		return d.DB.Unscoped().Model(&entities.Highlight{}).Where("source_id = 0 OR source_id IS NULL")
+	}
+
+	var total int64
+	if err := pending().Count(&total).Error; err != nil {
+		return err
+	}
+	report(0, int(total))
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := pending().UpdateColumn("source_id",
+		gorm.Expr("COALESCE((SELECT books.source_id FROM books WHERE books.id = highlights.book_id), 0)")).Error
+	if err != nil {
+		return err
+	}
+	report(int(total), int(total))
+	return nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestGetBookSources(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title: "Dune", Author: "Frank Herbert",
+		Source:     entities.Source{Name: "kindle"},
+		Highlights: []entities.Highlight{{Text: "Fear is the mind-killer."}, {Text: "The spice must flow."}},
+	}
+	require.NoError(t, db.SaveBook(book))
+
+	// The same book imported from another source later
+	merged := &entities.Book{
+		Title: "Dune", Author: "Frank Herbert",
+		Source:     entities.Source{Name: "apple_books"},
+		Highlights: []entities.Highlight{{Text: "A beginning is the time for taking the most delicate care."}},
+	}
+	require.NoError(t, db.SaveBook(merged))
+
+	sources, err := db.GetBookSources(book.ID)
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+
+	assert.Equal(t, "kindle", sources[0].Name)
+	assert.Equal(t, 2, sources[0].Highlights)
+	assert.False(t, sources[0].LastImportedAt.IsZero())
+
+	assert.Equal(t, "apple_books", sources[1].Name)
+	assert.Equal(t, "Apple Books", sources[1].DisplayName)
+	assert.Equal(t, 1, sources[1].Highlights)
+
+	// A highlight imported again from another source stays with the first one
+	again := &entities.Book{
+		Title: "Dune", Author: "Frank Herbert",
+		Source:     entities.Source{Name: "apple_books"},
+		Highlights: []entities.Highlight{{Text: "Fear is the mind-killer."}},
+	}
+	require.NoError(t, db.SaveBook(again))
+	sources, err = db.GetBookSources(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sources[0].Highlights)
+
+	_, err = db.GetBookSources(9999)
+	assert.Error(t, err)
+}
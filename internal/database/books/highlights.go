@@ -64,6 +64,13 @@ func (r *Repository) DeleteHighlightPermanently(id uint, userID uint) error {
 	}
 
 	entityKey := fmt.Sprintf("%s|%d|%s", highlight.Text, highlight.LocationValue, highlight.HighlightedAt.Format("2006-01-02 15:04:05"))
+	if highlight.ContentHash == "" {
+		var book entities.Book
+		if err := r.db.Unscoped().Select("title", "author").First(&book, highlight.BookID).Error; err != nil {
+			return err
+		}
+		highlight.ContentHash = entities.HighlightContentHash(book.Title, book.Author, highlight.Text)
+	}
 
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Exec("DELETE FROM highlight_tags WHERE highlight_id = ?", id).Error; err != nil {
@@ -75,22 +82,29 @@ func (r *Repository) DeleteHighlightPermanently(id uint, userID uint) error {
 		}
 
 		deletedEntity := entities.DeletedEntity{
-			UserID:     userID,
-			EntityType: "highlight",
-			EntityKey:  entityKey,
-			SourceID:   highlight.SourceID,
-			DeletedAt:  time.Now(),
+			UserID:      userID,
+			EntityType:  "highlight",
+			EntityKey:   entityKey,
+			ContentHash: highlight.ContentHash,
+			SourceID:    highlight.SourceID,
+			DeletedAt:   time.Now(),
 		}
 		return tx.Create(&deletedEntity).Error
 	})
 }
 
-// IsHighlightDeleted checks if a highlight was permanently deleted.
-func (r *Repository) IsHighlightDeleted(text string, locationValue int, highlightedAt time.Time, userID uint) (bool, error) {
-	entityKey := fmt.Sprintf("%s|%d|%s", text, locationValue, highlightedAt.Format("2006-01-02 15:04:05"))
+// IsHighlightDeleted checks if a highlight was permanently deleted, by content hash
+// or, for deletions recorded before content hashing, by text + location + timestamp.
+func (r *Repository) IsHighlightDeleted(highlight *entities.Highlight, userID uint) (bool, error) {
+	entityKey := fmt.Sprintf("%s|%d|%s", highlight.Text, highlight.LocationValue, highlight.HighlightedAt.Format("2006-01-02 15:04:05"))
+	match := r.db.Where("entity_key = ?", entityKey)
+	if highlight.ContentHash != "" {
+		match = match.Or("content_hash = ?", highlight.ContentHash)
+	}
 	var count int64
 	err := r.db.Model(&entities.DeletedEntity{}).
-		Where("entity_type = ? AND entity_key = ? AND (user_id = ? OR user_id = 0)", "highlight", entityKey, userID).
+		Where("entity_type = ? AND (user_id = ? OR user_id = 0)", "highlight", userID).
+		Where(match).
 		Count(&count).Error
 	return count > 0, err
 }
@@ -101,9 +101,9 @@ func (r *Repository) SearchBooks(query string) ([]entities.Book, error) {
 	return books, err
 }
 
-// SaveBook upserts a book and its highlights, deduplicating by text + location + timestamp.
+// SaveBook upserts a book and its highlights, deduplicating by content hash.
 // Skips books and highlights that have been permanently deleted.
-func (r *Repository) SaveBook(book *entities.Book, getSourceByName func(string) (*entities.Source, error), isBookDeleted func(string, string, uint) (bool, error), isHighlightDeleted func(string, int, time.Time, uint) (bool, error)) error {
+func (r *Repository) SaveBook(book *entities.Book, getSourceByName func(string) (*entities.Source, error), isBookDeleted func(string, string, uint) (bool, error), isHighlightDeleted func(*entities.Highlight, uint) (bool, error)) error {
 	// Check if this book was permanently deleted
 	deleted, err := isBookDeleted(book.Title, book.Author, book.UserID)
 	if err != nil {
@@ -135,7 +135,10 @@ func (r *Repository) SaveBook(book *entities.Book, getSourceByName func(string)
 		}
 
 		h := &book.Highlights[i]
-		highlightDeleted, _ := isHighlightDeleted(h.Text, h.LocationValue, h.HighlightedAt, book.UserID)
+		if h.ContentHash == "" {
+			h.ContentHash = entities.HighlightContentHash(book.Title, book.Author, h.Text)
+		}
+		highlightDeleted, _ := isHighlightDeleted(h, book.UserID)
 		if !highlightDeleted {
 			filteredHighlights = append(filteredHighlights, *h)
 		}
@@ -157,14 +160,16 @@ func (r *Repository) SaveBook(book *entities.Book, getSourceByName func(string)
 		}
 		existingHighlights := make(map[string]existingHighlightInfo)
 		for _, h := range existingBook.Highlights {
-			key := fmt.Sprintf("%s|%d|%s", h.Text, h.LocationValue, h.HighlightedAt.Format("2006-01-02 15:04:05"))
+			key := h.ContentHash
+			if key == "" {
+				key = entities.HighlightContentHash(existingBook.Title, existingBook.Author, h.Text)
+			}
 			existingHighlights[key] = existingHighlightInfo{ID: h.ID, IsFavorite: h.IsFavorite}
 		}
 
 		var newHighlights []entities.Highlight
 		for _, h := range book.Highlights {
-			key := fmt.Sprintf("%s|%d|%s", h.Text, h.LocationValue, h.HighlightedAt.Format("2006-01-02 15:04:05"))
-			if existing, exists := existingHighlights[key]; exists {
+			if existing, exists := existingHighlights[h.ContentHash]; exists {
 				h.ID = existing.ID
 				h.IsFavorite = existing.IsFavorite
 			}
@@ -185,7 +190,7 @@ func (r *Repository) SaveBook(book *entities.Book, getSourceByName func(string)
 }
 
 // SaveBookForUser saves a book for a specific user.
-func (r *Repository) SaveBookForUser(book *entities.Book, userID uint, getSourceByName func(string) (*entities.Source, error), isBookDeleted func(string, string, uint) (bool, error), isHighlightDeleted func(string, int, time.Time, uint) (bool, error)) error {
+func (r *Repository) SaveBookForUser(book *entities.Book, userID uint, getSourceByName func(string) (*entities.Source, error), isBookDeleted func(string, string, uint) (bool, error), isHighlightDeleted func(*entities.Highlight, uint) (bool, error)) error {
 	book.UserID = userID
 	return r.SaveBook(book, getSourceByName, isBookDeleted, isHighlightDeleted)
 }
@@ -0,0 +1,290 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// bulkQueryChunkSize bounds the values bound to a single IN clause, keeping bulk
+// lookups well below SQLite's variable limit.
+const bulkQueryChunkSize = 500
+
+// SaveBooks saves a batch of books in one transaction: if any book fails, none
+// of the batch is stored. The result matches calling SaveBook for each book, but
+// sources, tombstones and existing books are loaded once for the whole batch and
+// highlights are merged in memory, so an import issues a handful of queries per
+// batch instead of several per highlight. Book IDs are set as with SaveBook.
+func (d *Database) SaveBooks(books []entities.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		txDB := &Database{DB: tx}
+		return txDB.importBooks(books)
+	})
+}
+
+func (d *Database) importBooks(books []entities.Book) error {
+	sources, err := d.GetAllSources()
+	if err != nil {
+		return fmt.Errorf("failed to load sources: %w", err)
+	}
+	sourcesByName := make(map[string]entities.Source, len(sources))
+	for _, source := range sources {
+		sourcesByName[source.Name] = source
+	}
+	for i := range books {
+		resolveImportSources(&books[i], sourcesByName)
+		setISBNForms(&books[i])
+		setContentHashes(books[i].Title, books[i].Author, books[i].Highlights)
+	}
+
+	tombstones, err := d.loadImportTombstones(books)
+	if err != nil {
+		return fmt.Errorf("failed to check deleted entities: %w", err)
+	}
+	existing, err := d.loadExistingBooks(books)
+	if err != nil {
+		return fmt.Errorf("failed to load existing books: %w", err)
+	}
+
+	var existingHighlights []entities.Highlight
+	for _, book := range existing {
+		setContentHashes(book.Title, book.Author, book.Highlights)
+		existingHighlights = append(existingHighlights, book.Highlights...)
+	}
+	edited, err := d.locallyEditedHighlightIDs(existingHighlights)
+	if err != nil {
+		return fmt.Errorf("failed to merge highlights: %w", err)
+	}
+
+	// A book listed twice in the batch is saved once, with the highlights of both
+	// entries, as consecutive SaveBook calls would merge the second into the first
+	firstByKey := make(map[string]int)
+	duplicateOf := make(map[int]int)
+	var order []int
+	for i := range books {
+		book := &books[i]
+		if tombstones.has("book", book.UserID, fmt.Sprintf("%s|%s", book.Title, book.Author)) {
+			log.Printf("Skipping book '%s' by %s: permanently deleted", book.Title, book.Author)
+			continue
+		}
+
+		var filtered []entities.Highlight
+		for _, h := range book.Highlights {
+			if !tombstones.has("highlight", book.UserID, highlightKey(&h)) &&
+				!tombstones.has("highlight_hash", book.UserID, h.ContentHash) {
+				filtered = append(filtered, h)
+			}
+		}
+		book.Highlights = filtered
+
+		key := importBookKey(book.UserID, book.Title, book.Author)
+		if first, ok := firstByKey[key]; ok {
+			books[first].Highlights = appendUnseenHighlights(books[first].Highlights, book.Highlights)
+			duplicateOf[i] = first
+			continue
+		}
+		firstByKey[key] = i
+		order = append(order, i)
+	}
+
+	authors := d.newAuthorResolver()
+	var versions []entities.HighlightVersion
+	for _, i := range order {
+		book := &books[i]
+		prev, ok := existing[importBookKey(book.UserID, book.Title, book.Author)]
+		if err := authors.linkAuthor(book, prev); err != nil {
+			return err
+		}
+		if !ok {
+			for j := range book.Highlights {
+				book.Highlights[j].OriginHash = entities.HighlightOriginHash(book.Highlights[j].Text, book.Highlights[j].Note)
+			}
+			continue
+		}
+
+		book.ID = prev.ID
+		keepLocalBookFields(book, prev)
+		merged, bookVersions := mergeHighlights(prev.Highlights, book.Highlights, edited)
+		for j := range merged {
+			merged[j].BookID = book.ID
+		}
+		book.Highlights = merged
+		versions = append(versions, bookVersions...)
+	}
+
+	if len(versions) > 0 {
+		if err := d.DB.CreateInBatches(&versions, bulkQueryChunkSize).Error; err != nil {
+			return fmt.Errorf("failed to save highlight history: %w", err)
+		}
+	}
+
+	for _, i := range order {
+		book := &books[i]
+		// Keep the resolved source for callers; Omit prevents GORM from upserting it
+		source := book.Source
+		var err error
+		if _, ok := existing[importBookKey(book.UserID, book.Title, book.Author)]; ok {
+			err = d.DB.Session(&gorm.Session{FullSaveAssociations: true}).Omit("Source", "Highlights.Source", "Highlights.Links", "Highlights.Backlinks").Save(book).Error
+		} else {
+			err = d.DB.Omit("Source", "Highlights.Source").Create(book).Error
+		}
+		book.Source = source
+		if err != nil {
+			return fmt.Errorf("failed to save book '%s': %w", book.Title, err)
+		}
+	}
+
+	savedIDs := make([]uint, 0, len(order))
+	for _, i := range order {
+		savedIDs = append(savedIDs, books[i].ID)
+	}
+	if err := refreshBookCounters(d.DB, savedIDs...); err != nil {
+		return fmt.Errorf("failed to count highlights: %w", err)
+	}
+
+	for i, first := range duplicateOf {
+		books[i].ID = books[first].ID
+	}
+	return nil
+}
+
+// resolveImportSources sets source IDs from source names, like SaveBook does.
+func resolveImportSources(book *entities.Book, sourcesByName map[string]entities.Source) {
+	if book.SourceID == 0 && book.Source.Name != "" {
+		if source, ok := sourcesByName[book.Source.Name]; ok {
+			book.SourceID = source.ID
+			book.Source = source
+		}
+	}
+	for i := range book.Highlights {
+		h := &book.Highlights[i]
+		if h.SourceID == 0 && h.Source.Name != "" {
+			if source, ok := sourcesByName[h.Source.Name]; ok {
+				h.SourceID = source.ID
+			}
+		}
+		if h.SourceID == 0 {
+			h.SourceID = book.SourceID
+		}
+	}
+}
+
+// importTombstones holds the deleted entity keys relevant to a batch, keyed by
+// type, user and entity key. Highlight content hashes use the "highlight_hash" type.
+type importTombstones map[string]bool
+
+// has reports whether the entity was deleted by the user or globally (user 0).
+func (t importTombstones) has(entityType string, userID uint, entityKey string) bool {
+	return t[fmt.Sprintf("%s|%d|%s", entityType, userID, entityKey)] ||
+		t[fmt.Sprintf("%s|%d|%s", entityType, 0, entityKey)]
+}
+
+func (d *Database) loadImportTombstones(books []entities.Book) (importTombstones, error) {
+	userIDs := []uint{0}
+	seenUsers := map[uint]bool{0: true}
+	var bookKeys, highlightKeys, contentHashes []string
+	for i := range books {
+		book := &books[i]
+		if !seenUsers[book.UserID] {
+			seenUsers[book.UserID] = true
+			userIDs = append(userIDs, book.UserID)
+		}
+		bookKeys = append(bookKeys, fmt.Sprintf("%s|%s", book.Title, book.Author))
+		for j := range book.Highlights {
+			highlightKeys = append(highlightKeys, highlightKey(&book.Highlights[j]))
+			contentHashes = append(contentHashes, book.Highlights[j].ContentHash)
+		}
+	}
+
+	tombstones := make(importTombstones)
+	load := func(entityType, column, kind string, keys []string) error {
+		for start := 0; start < len(keys); start += bulkQueryChunkSize {
+			var deleted []entities.DeletedEntity
+			err := d.DB.Where("entity_type = ? AND "+column+" IN ? AND user_id IN ?",
+				entityType, keys[start:min(start+bulkQueryChunkSize, len(keys))], userIDs).
+				Find(&deleted).Error
+			if err != nil {
+				return err
+			}
+			for _, e := range deleted {
+				key := e.EntityKey
+				if column == "content_hash" {
+					key = e.ContentHash
+				}
+				tombstones[fmt.Sprintf("%s|%d|%s", kind, e.UserID, key)] = true
+			}
+		}
+		return nil
+	}
+	if err := load("book", "entity_key", "book", bookKeys); err != nil {
+		return nil, err
+	}
+	if err := load("highlight", "entity_key", "highlight", highlightKeys); err != nil {
+		return nil, err
+	}
+	if err := load("highlight", "content_hash", "highlight_hash", contentHashes); err != nil {
+		return nil, err
+	}
+	return tombstones, nil
+}
+
+// loadExistingBooks returns the stored books matching the batch by user, title
+// and author, with their highlights, keyed by importBookKey.
+func (d *Database) loadExistingBooks(books []entities.Book) (map[string]*entities.Book, error) {
+	titlesByUser := make(map[uint][]string)
+	seen := make(map[string]bool)
+	for i := range books {
+		key := fmt.Sprintf("%d|%s", books[i].UserID, books[i].Title)
+		if !seen[key] {
+			seen[key] = true
+			titlesByUser[books[i].UserID] = append(titlesByUser[books[i].UserID], books[i].Title)
+		}
+	}
+
+	existing := make(map[string]*entities.Book)
+	for userID, titles := range titlesByUser {
+		for start := 0; start < len(titles); start += bulkQueryChunkSize {
+			var found []entities.Book
+			err := d.DB.Preload("Highlights").
+				Where("user_id = ? AND title IN ?", userID, titles[start:min(start+bulkQueryChunkSize, len(titles))]).
+				Order("id ASC").
+				Find(&found).Error
+			if err != nil {
+				return nil, err
+			}
+			for i := range found {
+				key := importBookKey(found[i].UserID, found[i].Title, found[i].Author)
+				// SaveBook merges into the oldest match
+				if _, ok := existing[key]; !ok {
+					existing[key] = &found[i]
+				}
+			}
+		}
+	}
+	return existing, nil
+}
+
+func importBookKey(userID uint, title, author string) string {
+	return fmt.Sprintf("%d|%s|%s", userID, title, author)
+}
+
+// appendUnseenHighlights appends the highlights whose content hash is not already in existing.
+func appendUnseenHighlights(existing, more []entities.Highlight) []entities.Highlight {
+	seen := make(map[string]bool, len(existing))
+	for i := range existing {
+		seen[existing[i].ContentHash] = true
+	}
+	for i := range more {
+		if !seen[more[i].ContentHash] {
+			seen[more[i].ContentHash] = true
+			existing = append(existing, more[i])
+		}
+	}
+	return existing
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveBooks_MatchesSaveBook(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	existing := &entities.Book{
+		Title:  "Existing",
+		Author: "Author",
+		Highlights: []entities.Highlight{
+			{Text: "Kept", LocationValue: 1, Note: "Old note"},
+			{Text: "Removed", LocationValue: 2},
+		},
+	}
+	require.NoError(t, db.SaveBook(existing))
+	require.NoError(t, db.SetHighlightFavourite(existing.Highlights[0].ID, true))
+	require.NoError(t, db.DeleteHighlightPermanently(existing.Highlights[1].ID, 0))
+
+	gone := &entities.Book{Title: "Gone", Author: "Author", Highlights: []entities.Highlight{{Text: "Gone"}}}
+	require.NoError(t, db.SaveBook(gone))
+	require.NoError(t, db.DeleteBookPermanently(gone.ID, 0))
+
+	books := []entities.Book{
+		{
+			Title:  "Existing",
+			Author: "Author",
+			Source: entities.Source{Name: "kindle"},
+			Highlights: []entities.Highlight{
+				{Text: "Kept", LocationValue: 1, Note: "New note"},
+				{Text: "Removed", LocationValue: 2},
+				{Text: "Added", LocationValue: 3},
+			},
+		},
+		{Title: "Gone", Author: "Author", Highlights: []entities.Highlight{{Text: "Gone"}}},
+		{Title: "Fresh", Author: "Author", Highlights: []entities.Highlight{{Text: "One", LocationValue: 1}}},
+		{Title: "Fresh", Author: "Author", Highlights: []entities.Highlight{{Text: "One", LocationValue: 1}, {Text: "Two", LocationValue: 2}}},
+	}
+	require.NoError(t, db.SaveBooks(books))
+
+	assert.Equal(t, existing.ID, books[0].ID)
+	assert.NotZero(t, books[0].SourceID)
+	assert.Equal(t, "kindle", books[0].Source.Name)
+	assert.Zero(t, books[1].ID, "permanently deleted books are skipped")
+	assert.NotZero(t, books[2].ID)
+	assert.Equal(t, books[2].ID, books[3].ID, "a book listed twice is saved once")
+
+	saved, err := db.GetBookByID(existing.ID)
+	require.NoError(t, err)
+	require.Len(t, saved.Highlights, 2)
+	assert.Equal(t, "Kept", saved.Highlights[0].Text)
+	assert.Equal(t, "New note", saved.Highlights[0].Note)
+	assert.True(t, saved.Highlights[0].IsFavorite)
+	assert.Equal(t, "Added", saved.Highlights[1].Text)
+
+	versions, err := db.GetHighlightHistory(existing.Highlights[0].ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, "Old note", versions[0].Note)
+
+	fresh, err := db.GetBookByID(books[2].ID)
+	require.NoError(t, err)
+	assert.Len(t, fresh.Highlights, 2)
+}
+
+// benchmarkLibrary builds an import of books with highlights each, as returned by a
+// source that resends the whole library on every sync.
+func benchmarkLibrary(books, highlights int) []entities.Book {
+	highlightedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	library := make([]entities.Book, books)
+	for i := range library {
+		library[i] = entities.Book{
+			Title:  fmt.Sprintf("Book %d", i),
+			Author: "Author",
+			Source: entities.Source{Name: "kindle"},
+		}
+		for j := 0; j < highlights; j++ {
+			library[i].Highlights = append(library[i].Highlights, entities.Highlight{
+				Text:          fmt.Sprintf("Highlight %d of book %d", j, i),
+				LocationValue: j,
+				HighlightedAt: highlightedAt.Add(time.Duration(j) * time.Minute),
+				Source:        entities.Source{Name: "kindle"},
+			})
+		}
+	}
+	return library
+}
+
+func benchmarkReimport(b *testing.B, save func(db *Database, books []entities.Book) error) {
+	db, err := NewDatabase(filepath.Join(b.TempDir(), "bench.db"))
+	require.NoError(b, err)
+	defer db.Close()
+
+	require.NoError(b, save(db, benchmarkLibrary(50, 20)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		books := benchmarkLibrary(50, 20)
+		b.StartTimer()
+		if err := save(db, books); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReimport_SaveBook(b *testing.B) {
+	benchmarkReimport(b, func(db *Database, books []entities.Book) error {
+		for i := range books {
+			if err := db.SaveBook(&books[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func BenchmarkReimport_SaveBooks(b *testing.B) {
+	benchmarkReimport(b, func(db *Database, books []entities.Book) error {
+		return db.SaveBooks(books)
+	})
+}
@@ -0,0 +1,245 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ErrCollectionNameTaken is returned when the user already has a collection by that name.
+var ErrCollectionNameTaken = errors.New("a collection with this name already exists")
+
+// CreateCollection creates an empty collection for the user.
+func (d *Database) CreateCollection(userID uint, name, description string) (*entities.Collection, error) {
+	name = strings.TrimSpace(name)
+	if err := d.checkCollectionName(userID, 0, name); err != nil {
+		return nil, err
+	}
+	collection := &entities.Collection{UserID: userID, Name: name, Description: strings.TrimSpace(description)}
+	if err := d.DB.Create(collection).Error; err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// UpdateCollection renames a collection and replaces its description.
+func (d *Database) UpdateCollection(id uint, name, description string) (*entities.Collection, error) {
+	var collection entities.Collection
+	if err := d.DB.First(&collection, id).Error; err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if err := d.checkCollectionName(collection.UserID, collection.ID, name); err != nil {
+		return nil, err
+	}
+	err := d.DB.Model(&collection).Updates(map[string]any{
+		"name":        name,
+		"description": strings.TrimSpace(description),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return d.GetCollectionByID(id)
+}
+
+// checkCollectionName returns ErrCollectionNameTaken when another collection of
+// the user has the name, ignoring case
+func (d *Database) checkCollectionName(userID, id uint, name string) error {
+	var taken int64
+	err := d.DB.Model(&entities.Collection{}).
+		Where("user_id = ? AND name = ? COLLATE NOCASE AND id <> ?", userID, name, id).
+		Count(&taken).Error
+	if err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrCollectionNameTaken
+	}
+	return nil
+}
+
+// DeleteCollection deletes a collection. Its books are kept.
+func (d *Database) DeleteCollection(id uint) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&entities.CollectionBook{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&entities.Collection{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// GetCollections returns the user's collections by name, with their book counts.
+// Books in the trash are not counted.
+func (d *Database) GetCollections(userID uint) ([]entities.CollectionSummary, error) {
+	var collections []entities.Collection
+	if err := d.DB.Where("user_id = ?", userID).Order("name COLLATE NOCASE ASC").Find(&collections).Error; err != nil {
+		return nil, err
+	}
+
+	type collectionCount struct {
+		CollectionID uint
+		BookCount    int64
+	}
+	var counts []collectionCount
+	err := d.DB.Model(&entities.CollectionBook{}).
+		Select("collection_books.collection_id, COUNT(*) AS book_count").
+		Joins("JOIN books ON books.id = collection_books.book_id AND books.deleted_at IS NULL").
+		Joins("JOIN collections ON collections.id = collection_books.collection_id").
+		Where("collections.user_id = ?", userID).
+		Group("collection_books.collection_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	countByCollection := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		countByCollection[c.CollectionID] = c.BookCount
+	}
+
+	summaries := make([]entities.CollectionSummary, 0, len(collections))
+	for _, collection := range collections {
+		summaries = append(summaries, entities.CollectionSummary{Collection: collection, BookCount: countByCollection[collection.ID]})
+	}
+	return summaries, nil
+}
+
+// GetCollectionByID returns a collection with its books in collection order.
+// Books in the trash are left out.
+func (d *Database) GetCollectionByID(id uint) (*entities.Collection, error) {
+	var collection entities.Collection
+	if err := d.DB.First(&collection, id).Error; err != nil {
+		return nil, err
+	}
+	err := d.DB.Preload("Source").
+		Joins("JOIN collection_books ON collection_books.book_id = books.id").
+		Where("collection_books.collection_id = ?", id).
+		Order("collection_books.position ASC, books.title ASC").
+		Find(&collection.Books).Error
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// GetBookCollections returns the collections a book is in, by name.
+func (d *Database) GetBookCollections(bookID uint) ([]entities.Collection, error) {
+	var collections []entities.Collection
+	err := d.DB.Joins("JOIN collection_books ON collection_books.collection_id = collections.id").
+		Where("collection_books.book_id = ?", bookID).
+		Order("collections.name COLLATE NOCASE ASC").
+		Find(&collections).Error
+	return collections, err
+}
+
+// AddBookToCollection appends a book to the end of a collection. Adding a book
+// that is already in the collection keeps its position. The book must belong
+// to the collection's user.
+func (d *Database) AddBookToCollection(collectionID, bookID uint) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		var collection entities.Collection
+		if err := tx.First(&collection, collectionID).Error; err != nil {
+			return err
+		}
+		var book entities.Book
+		if err := tx.Select("id", "user_id").Where("user_id = ?", collection.UserID).First(&book, bookID).Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&entities.CollectionBook{}).
+			Where("collection_id = ? AND book_id = ?", collectionID, bookID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		var next int
+		if err := tx.Model(&entities.CollectionBook{}).
+			Select("COALESCE(MAX(position) + 1, 0)").
+			Where("collection_id = ?", collectionID).
+			Scan(&next).Error; err != nil {
+			return err
+		}
+		return tx.Create(&entities.CollectionBook{CollectionID: collectionID, BookID: bookID, Position: next}).Error
+	})
+}
+
+// RemoveBookFromCollection takes a book out of a collection.
+func (d *Database) RemoveBookFromCollection(collectionID, bookID uint) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("collection_id = ? AND book_id = ?", collectionID, bookID).Delete(&entities.CollectionBook{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return renumberCollection(tx, collectionID, nil)
+	})
+}
+
+// MoveBookInCollection moves a book to a 0-based position in its collection,
+// shifting the books in between. Positions past the end move it to the end.
+func (d *Database) MoveBookInCollection(collectionID, bookID uint, position int) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		var member int64
+		if err := tx.Model(&entities.CollectionBook{}).
+			Where("collection_id = ? AND book_id = ?", collectionID, bookID).
+			Count(&member).Error; err != nil {
+			return err
+		}
+		if member == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return renumberCollection(tx, collectionID, func(order []uint) []uint {
+			order = removeID(order, bookID)
+			position = max(0, min(position, len(order)))
+			return append(order[:position], append([]uint{bookID}, order[position:]...)...)
+		})
+	})
+}
+
+// renumberCollection gives a collection's books consecutive positions, in their
+// current order or the one returned by reorder
+func renumberCollection(tx *gorm.DB, collectionID uint, reorder func(order []uint) []uint) error {
+	var order []uint
+	if err := tx.Model(&entities.CollectionBook{}).
+		Where("collection_id = ?", collectionID).
+		Order("position ASC, created_at ASC").
+		Pluck("book_id", &order).Error; err != nil {
+		return err
+	}
+	if reorder != nil {
+		order = reorder(order)
+	}
+	for i, bookID := range order {
+		if err := tx.Model(&entities.CollectionBook{}).
+			Where("collection_id = ? AND book_id = ?", collectionID, bookID).
+			UpdateColumn("position", i).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeID(ids []uint, id uint) []uint {
+	kept := make([]uint, 0, len(ids))
+	for _, other := range ids {
+		if other != id {
+			kept = append(kept, other)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func collectionTitles(collection *entities.Collection) []string {
+	titles := make([]string, 0, len(collection.Books))
+	for _, book := range collection.Books {
+		titles = append(titles, book.Title)
+	}
+	return titles
+}
+
+func TestCollections_CRUD(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	collection, err := db.CreateCollection(1, " Stoicism starter pack ", "Where to begin")
+	require.NoError(t, err)
+	assert.Equal(t, "Stoicism starter pack", collection.Name)
+
+	_, err = db.CreateCollection(1, "stoicism STARTER pack", "")
+	assert.ErrorIs(t, err, ErrCollectionNameTaken)
+	_, err = db.CreateCollection(2, "Stoicism starter pack", "")
+	assert.NoError(t, err, "names are unique per user")
+
+	other, err := db.CreateCollection(1, "2024 reading", "")
+	require.NoError(t, err)
+	_, err = db.UpdateCollection(other.ID, "Stoicism starter pack", "")
+	assert.ErrorIs(t, err, ErrCollectionNameTaken)
+
+	updated, err := db.UpdateCollection(collection.ID, "Stoics", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Stoics", updated.Name)
+	assert.Empty(t, updated.Description)
+
+	summaries, err := db.GetCollections(1)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "2024 reading", summaries[0].Collection.Name)
+
+	book := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", UserID: 1}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.AddBookToCollection(collection.ID, book.ID))
+	require.NoError(t, db.DeleteCollection(collection.ID))
+	assert.Error(t, db.DeleteCollection(collection.ID))
+
+	kept, err := db.GetBookByID(book.ID)
+	require.NoError(t, err, "books outlive their collections")
+	assert.Empty(t, kept.Collections)
+}
+
+func TestCollections_BookOrder(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	collection, err := db.CreateCollection(1, "Stoicism starter pack", "")
+	require.NoError(t, err)
+
+	var books []*entities.Book
+	for _, title := range []string{"Meditations", "Letters from a Stoic", "Discourses"} {
+		book := &entities.Book{Title: title, Author: "Various", UserID: 1}
+		require.NoError(t, db.SaveBook(book))
+		require.NoError(t, db.AddBookToCollection(collection.ID, book.ID))
+		books = append(books, book)
+	}
+	// Adding again keeps the position
+	require.NoError(t, db.AddBookToCollection(collection.ID, books[0].ID))
+
+	loaded, err := db.GetCollectionByID(collection.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Meditations", "Letters from a Stoic", "Discourses"}, collectionTitles(loaded))
+
+	require.NoError(t, db.MoveBookInCollection(collection.ID, books[2].ID, 0))
+	loaded, err = db.GetCollectionByID(collection.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Discourses", "Meditations", "Letters from a Stoic"}, collectionTitles(loaded))
+
+	require.NoError(t, db.MoveBookInCollection(collection.ID, books[2].ID, 10))
+	require.NoError(t, db.RemoveBookFromCollection(collection.ID, books[0].ID))
+	assert.Error(t, db.RemoveBookFromCollection(collection.ID, books[0].ID))
+	loaded, err = db.GetCollectionByID(collection.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Letters from a Stoic", "Discourses"}, collectionTitles(loaded))
+
+	// Books of another user can't be added
+	foreign := &entities.Book{Title: "Enchiridion", Author: "Epictetus", UserID: 2}
+	require.NoError(t, db.SaveBook(foreign))
+	assert.Error(t, db.AddBookToCollection(collection.ID, foreign.ID))
+
+	// Trashed books are left out until restored
+	require.NoError(t, db.DeleteBook(books[1].ID))
+	summaries, err := db.GetCollections(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), summaries[0].BookCount)
+
+	book, err := db.GetBookByID(books[2].ID)
+	require.NoError(t, err)
+	require.Len(t, book.Collections, 1)
+	assert.Equal(t, "Stoicism starter pack", book.Collections[0].Name)
+}
+
+func TestCollections_PermanentDeleteRemovesMembership(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	collection, err := db.CreateCollection(1, "2024 reading", "")
+	require.NoError(t, err)
+	book := &entities.Book{Title: "Dune", Author: "Frank Herbert", UserID: 1}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.AddBookToCollection(collection.ID, book.ID))
+
+	require.NoError(t, db.DeleteBookPermanently(book.ID, 1))
+
+	var members int64
+	require.NoError(t, db.DB.Model(&entities.CollectionBook{}).Where("collection_id = ?", collection.ID).Count(&members).Error)
+	assert.Zero(t, members)
+}
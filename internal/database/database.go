@@ -11,7 +11,9 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
+	syncrepo "github.com/mrlokans/assistant/internal/database/sync"
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
 )
 
 var defaultSources = []entities.Source{
@@ -26,10 +28,14 @@ var defaultSources = []entities.Source{
 	{Name: "instapaper", DisplayName: "Instapaper"},
 	{Name: "pocket", DisplayName: "Pocket"},
 	{Name: "manual", DisplayName: "Manual Import"},
+	{Name: "goodreads", DisplayName: "Goodreads"},
+	{Name: "storygraph", DisplayName: "StoryGraph"},
 }
 
 type Database struct {
 	DB *gorm.DB
+
+	events *events.Broker
 }
 
 func NewDatabase(dbPath string) (*Database, error) {
@@ -40,22 +46,8 @@ func NewDatabase(dbPath string) (*Database, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Auto-migrate all entities
-	err = db.AutoMigrate(
-		&entities.Source{},
-		&entities.User{},
-		&entities.Book{},
-		&entities.Highlight{},
-		&entities.Tag{},
-		&entities.ImportSession{},
-		&entities.Setting{},
-		&entities.SyncProgress{},
-		&entities.DeletedEntity{},
-		&entities.Word{},
-		&entities.WordDefinition{},
-		&entities.AuditEvent{},
-	)
-	if err != nil {
+	// Auto-migrate all entities, recording schema changes and pending backfills
+	if err := migrate(db); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
@@ -71,6 +63,11 @@ func NewDatabase(dbPath string) (*Database, error) {
 	return database, nil
 }
 
+// SetEventBroker sets where sync progress changes and created highlights are published.
+func (d *Database) SetEventBroker(broker *events.Broker) {
+	d.events = broker
+}
+
 func (d *Database) Close() error {
 	sqlDB, err := d.DB.DB()
 	if err != nil {
@@ -108,6 +105,20 @@ func (d *Database) GetAllSources() ([]entities.Source, error) {
 	return sources, err
 }
 
+// EnsureSource creates a source unless one with its name exists. Reports
+// whether it was created.
+func (d *Database) EnsureSource(name, displayName string) (bool, error) {
+	if _, err := d.GetSourceByName(name); err == nil {
+		return false, nil
+	} else if err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+	if err := d.DB.Create(&entities.Source{Name: name, DisplayName: displayName}).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (d *Database) CreateUser(username, email string) (*entities.User, error) {
 	token, err := generateToken()
 	if err != nil {
@@ -154,98 +165,15 @@ func (d *Database) GetUserByUsername(username string) (*entities.User, error) {
 	return &user, nil
 }
 
-// Upserts a book and its highlights, deduplicating by text + location + timestamp.
+// Upserts a book and its highlights, deduplicating by content hash (see entities.HighlightContentHash).
 // Skips books and highlights that have been permanently deleted.
+// Highlights edited locally keep their text and note; see mergeHighlights.
+// It is SaveBooks for a single book, so both merge reimports the same way.
 func (d *Database) SaveBook(book *entities.Book) error {
-	// Check if this book was permanently deleted
-	deleted, err := d.IsBookDeleted(book.Title, book.Author, book.UserID)
-	if err != nil {
-		return fmt.Errorf("failed to check if book was deleted: %w", err)
-	}
-	if deleted {
-		log.Printf("Skipping book '%s' by %s: permanently deleted", book.Title, book.Author)
-		return nil
-	}
-
-	// If Source.Name is set but SourceID is 0, look up the source
-	// Preserve the original source info for callers who need it after save
-	originalSource := book.Source
-	if book.SourceID == 0 && book.Source.Name != "" {
-		source, err := d.GetSourceByName(book.Source.Name)
-		if err == nil && source != nil {
-			book.SourceID = source.ID
-			originalSource = *source
-		}
-	}
-
-	// Also fix SourceID for all highlights and filter out deleted ones
-	var filteredHighlights []entities.Highlight
-	for i := range book.Highlights {
-		if book.Highlights[i].SourceID == 0 && book.Highlights[i].Source.Name != "" {
-			source, err := d.GetSourceByName(book.Highlights[i].Source.Name)
-			if err == nil && source != nil {
-				book.Highlights[i].SourceID = source.ID
-			}
-		}
-
-		// Check if this highlight was permanently deleted
-		h := &book.Highlights[i]
-		highlightDeleted, _ := d.IsHighlightDeleted(h.Text, h.LocationValue, h.HighlightedAt, book.UserID)
-		if !highlightDeleted {
-			filteredHighlights = append(filteredHighlights, *h)
-		}
-	}
-	book.Highlights = filteredHighlights
-
-	// Check if book already exists by title and author for the same user
-	var existingBook entities.Book
-	result := d.DB.Preload("Highlights").Where("title = ? AND author = ? AND user_id = ?", book.Title, book.Author, book.UserID).First(&existingBook)
-
-	var saveErr error
-	if result.Error == nil {
-		// Book exists, merge highlights (deduplicate by text + location)
-		book.ID = existingBook.ID
-
-		// Build a map of existing highlights for deduplication
-		// key: text+location -> existing highlight (ID + IsFavorite)
-		type existingHighlightInfo struct {
-			ID         uint
-			IsFavorite bool
-		}
-		existingHighlights := make(map[string]existingHighlightInfo)
-		for _, h := range existingBook.Highlights {
-			key := fmt.Sprintf("%s|%d|%s", h.Text, h.LocationValue, h.HighlightedAt.Format("2006-01-02 15:04:05"))
-			existingHighlights[key] = existingHighlightInfo{ID: h.ID, IsFavorite: h.IsFavorite}
-		}
-
-		// Process new highlights: skip duplicates, keep new ones
-		var newHighlights []entities.Highlight
-		for _, h := range book.Highlights {
-			key := fmt.Sprintf("%s|%d|%s", h.Text, h.LocationValue, h.HighlightedAt.Format("2006-01-02 15:04:05"))
-			if existing, exists := existingHighlights[key]; exists {
-				// Highlight already exists, preserve ID and favourite status
-				h.ID = existing.ID
-				h.IsFavorite = existing.IsFavorite
-			}
-			h.BookID = book.ID
-			newHighlights = append(newHighlights, h)
-		}
-		book.Highlights = newHighlights
-
-		// Use Omit to prevent GORM from upserting Source associations
-		saveErr = d.DB.Session(&gorm.Session{FullSaveAssociations: true}).Omit("Source", "Highlights.Source").Save(book).Error
-	} else if result.Error == gorm.ErrRecordNotFound {
-		// Book doesn't exist, create it
-		// Use Omit to prevent GORM from upserting Source associations
-		saveErr = d.DB.Omit("Source", "Highlights.Source").Create(book).Error
-	} else {
-		saveErr = result.Error
-	}
-
-	// Restore the source info for callers
-	book.Source = originalSource
-
-	return saveErr
+	books := []entities.Book{*book}
+	err := d.SaveBooks(books)
+	*book = books[0]
+	return err
 }
 
 func (d *Database) SaveBookForUser(book *entities.Book, userID uint) error {
@@ -281,7 +209,8 @@ func (d *Database) GetBookByID(id uint) (*entities.Book, error) {
 	var book entities.Book
 	err := d.DB.Preload("Highlights", func(db *gorm.DB) *gorm.DB {
 		return db.Order("location_value ASC, highlighted_at ASC")
-	}).Preload("Highlights.Tags").Preload("Tags").Preload("Source").First(&book, id).Error
+	}).Preload("Highlights.Tags").Preload("Highlights.Links.To.Book").Preload("Highlights.Backlinks").
+		Preload("Tags").Preload("Collections").Preload("Source").Preload("Notes", orderBookNotes).First(&book, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -292,10 +221,18 @@ func (d *Database) GetAllBooks() ([]entities.Book, error) {
 	var books []entities.Book
 	err := d.DB.Preload("Highlights", func(db *gorm.DB) *gorm.DB {
 		return db.Order("location_value ASC, highlighted_at ASC")
-	}).Preload("Highlights.Tags").Preload("Tags").Preload("Source").Find(&books).Error
+	}).Preload("Highlights.Tags").Preload("Highlights.Links.To.Book").Preload("Highlights.Backlinks").
+		Preload("Tags").Preload("Collections").Preload("Source").Preload("Notes", orderBookNotes).Find(&books).Error
 	return books, err
 }
 
+// GetBookIDs returns the IDs of all books, in ID order.
+func (d *Database) GetBookIDs() ([]uint, error) {
+	var ids []uint
+	err := d.DB.Model(&entities.Book{}).Order("id ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
 func (d *Database) SearchBooks(query string) ([]entities.Book, error) {
 	var books []entities.Book
 	searchPattern := "%" + query + "%"
@@ -311,7 +248,9 @@ func (d *Database) GetAllBooksForUser(userID uint) ([]entities.Book, error) {
 	var books []entities.Book
 	err := d.DB.Preload("Highlights", func(db *gorm.DB) *gorm.DB {
 		return db.Order("location_value ASC, highlighted_at ASC")
-	}).Preload("Highlights.Tags").Preload("Tags").Preload("Source").Where("user_id = ?", userID).Find(&books).Error
+	}).Preload("Highlights.Tags").Preload("Highlights.Links.To.Book").Preload("Highlights.Backlinks").
+		Preload("Tags").Preload("Collections").Preload("Source").Preload("Notes", orderBookNotes).
+		Where("user_id = ?", userID).Find(&books).Error
 	return books, err
 }
 
@@ -328,7 +267,10 @@ func (d *Database) DeleteBook(id uint) error {
 			return err
 		}
 		// Soft delete the book
-		return tx.Delete(&entities.Book{}, id).Error
+		if err := tx.Delete(&entities.Book{}, id).Error; err != nil {
+			return err
+		}
+		return refreshBookCounters(tx, id)
 	})
 }
 
@@ -348,12 +290,18 @@ func (d *Database) DeleteBookPermanently(id uint, userID uint) error {
 		var highlightIDs []uint
 		tx.Model(&entities.Highlight{}).Unscoped().Where("book_id = ?", id).Pluck("id", &highlightIDs)
 
-		// Delete highlight-tag associations
+		// Delete highlight-tag associations, links and categories
 		if len(highlightIDs) > 0 {
 			if err := tx.Exec("DELETE FROM highlight_tags WHERE highlight_id IN ?", highlightIDs).Error; err != nil {
 				return err
 			}
 		}
+		if err := deleteHighlightLinks(tx, highlightIDs); err != nil {
+			return err
+		}
+		if err := deleteHighlightCategories(tx, highlightIDs); err != nil {
+			return err
+		}
 
 		// Hard delete highlights
 		if err := tx.Unscoped().Where("book_id = ?", id).Delete(&entities.Highlight{}).Error; err != nil {
@@ -364,6 +312,12 @@ func (d *Database) DeleteBookPermanently(id uint, userID uint) error {
 		if err := tx.Exec("DELETE FROM book_tags WHERE book_id = ?", id).Error; err != nil {
 			return err
 		}
+		if err := tx.Exec("DELETE FROM collection_books WHERE book_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("book_id = ?", id).Delete(&entities.BookNote{}).Error; err != nil {
+			return err
+		}
 
 		// Hard delete the book
 		if err := tx.Unscoped().Delete(&entities.Book{}, id).Error; err != nil {
@@ -383,8 +337,37 @@ func (d *Database) DeleteBookPermanently(id uint, userID uint) error {
 }
 
 // UpdateBookMetadata updates specific metadata fields on a book without affecting other data.
+// Changing the author links the book to the author record for the new name, and changing
+// the title or author recomputes the content hashes of the book's highlights.
 func (d *Database) UpdateBookMetadata(id uint, fields map[string]any) error {
-	return d.DB.Model(&entities.Book{}).Where("id = ?", id).Updates(fields).Error
+	if author, ok := fields["author"].(string); ok {
+		var book entities.Book
+		if err := d.DB.Select("id", "user_id").First(&book, id).Error; err != nil {
+			return err
+		}
+		authorID, err := d.newAuthorResolver().resolve(book.UserID, author)
+		if err != nil {
+			return err
+		}
+		fields["author_id"] = authorID
+	}
+	isbnFormUpdates(fields)
+
+	_, titleChanged := fields["title"]
+	_, authorChanged := fields["author"]
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.Book{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+			return err
+		}
+		if !titleChanged && !authorChanged {
+			return nil
+		}
+		var book entities.Book
+		if err := tx.Select("id", "title", "author").First(&book, id).Error; err != nil {
+			return err
+		}
+		return rehashBookHighlights(tx, book.ID, book.Title, book.Author)
+	})
 }
 
 // GetBooksMissingMetadata returns books that have no cover URL, publisher, or publication year.
@@ -396,9 +379,11 @@ func (d *Database) GetBooksMissingMetadata() ([]entities.Book, error) {
 	return books, err
 }
 
+// FindBookByISBN finds a book by ISBN for a user, in either its ISBN-13 or
+// ISBN-10 form.
 func (d *Database) FindBookByISBN(isbn string, userID uint) (*entities.Book, error) {
 	var book entities.Book
-	err := d.DB.Where("isbn = ? AND user_id = ?", isbn, userID).First(&book).Error
+	err := d.DB.Where("isbn IN ? AND user_id = ?", isbnCandidates(isbn), userID).First(&book).Error
 	if err != nil {
 		return nil, err
 	}
@@ -430,6 +415,18 @@ func (d *Database) GetHighlightsForBook(bookID uint) ([]entities.Highlight, erro
 	return highlights, err
 }
 
+// GetHighlightByExternalID returns the highlight in a book with the given
+// external ID, or gorm.ErrRecordNotFound.
+func (d *Database) GetHighlightByExternalID(bookID uint, externalID string) (*entities.Highlight, error) {
+	var highlight entities.Highlight
+	err := d.DB.Preload("Tags").Preload("Source").
+		Where("book_id = ? AND external_id = ?", bookID, externalID).First(&highlight).Error
+	if err != nil {
+		return nil, err
+	}
+	return &highlight, nil
+}
+
 func (d *Database) GetHighlightsForUser(userID uint, limit, offset int) ([]entities.Highlight, error) {
 	var highlights []entities.Highlight
 	query := d.DB.Preload("Tags").Preload("Source").Where("user_id = ?", userID).Order("highlighted_at DESC")
@@ -443,8 +440,117 @@ func (d *Database) GetHighlightsForUser(userID uint, limit, offset int) ([]entit
 	return highlights, err
 }
 
+// GetHighlightsAfterID returns highlights with an ID greater than afterID in ascending
+// ID order, with their books preloaded. Used for incremental scans of new highlights.
+func (d *Database) GetHighlightsAfterID(afterID uint, limit int) ([]entities.Highlight, error) {
+	var highlights []entities.Highlight
+	query := d.DB.Preload("Book").Where("id > ?", afterID).Order("id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&highlights).Error
+	return highlights, err
+}
+
+// SearchHighlights returns up to limit highlights whose text or note contains
+// the query, newest first, with their books preloaded. A limit of 0 returns all.
+func (d *Database) SearchHighlights(query string, limit int) ([]entities.Highlight, error) {
+	var highlights []entities.Highlight
+	searchPattern := "%" + query + "%"
+	q := d.DB.Preload("Book").Preload("Tags").
+		Where("LOWER(text) LIKE LOWER(?) OR LOWER(note) LIKE LOWER(?)", searchPattern, searchPattern).
+		Order("highlighted_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	err := q.Find(&highlights).Error
+	return highlights, err
+}
+
+// SearchHighlightsForUser is SearchHighlights limited to the highlights in a
+// user's books, or in every user's books for a user ID of 0. A non-empty book
+// further limits it to books whose title or author contains that text.
+func (d *Database) SearchHighlightsForUser(userID uint, query, book string, limit int) ([]entities.Highlight, error) {
+	var highlights []entities.Highlight
+	searchPattern := "%" + query + "%"
+	q := d.DB.Preload("Book").Preload("Tags").
+		Joins("JOIN books ON books.id = highlights.book_id AND books.deleted_at IS NULL").
+		Where("LOWER(highlights.text) LIKE LOWER(?) OR LOWER(highlights.note) LIKE LOWER(?)", searchPattern, searchPattern).
+		Order("highlights.highlighted_at DESC")
+	if userID > 0 {
+		q = q.Where("books.user_id = ?", userID)
+	}
+	if book != "" {
+		bookPattern := "%" + book + "%"
+		q = q.Where("LOWER(books.title) LIKE LOWER(?) OR LOWER(books.author) LIKE LOWER(?)", bookPattern, bookPattern)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	err := q.Find(&highlights).Error
+	return highlights, err
+}
+
+// GetRandomHighlights returns up to limit random highlights that have text,
+// with their books preloaded. Discarded highlights are skipped. A limit of 0
+// returns all of them in random order.
+func (d *Database) GetRandomHighlights(limit int) ([]entities.Highlight, error) {
+	var highlights []entities.Highlight
+	q := d.DB.Preload("Book").Preload("Tags").
+		Where("text <> '' AND is_discarded = ?", false).
+		Order("RANDOM()")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	err := q.Find(&highlights).Error
+	return highlights, err
+}
+
+// UpdateHighlight saves a highlight. If its text or note changed, the previous
+// version is recorded in the highlight history.
 func (d *Database) UpdateHighlight(highlight *entities.Highlight) error {
-	return d.DB.Save(highlight).Error
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		var current entities.Highlight
+		err := tx.First(&current, highlight.ID).Error
+		if err == nil && (current.Text != highlight.Text || current.Note != highlight.Note) {
+			if err := recordHighlightVersion(tx, &current, entities.HighlightVersionReasonEdit); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(highlight).Error; err != nil {
+			return err
+		}
+		return refreshBookCounters(tx, highlight.BookID, current.BookID)
+	})
+}
+
+// CreateHighlight adds a single highlight to an existing book, e.g. one typed in by hand,
+// and publishes it as a highlight.created event.
+// The source is looked up by Source.Name when SourceID is not set.
+func (d *Database) CreateHighlight(highlight *entities.Highlight) error {
+	if highlight.SourceID == 0 && highlight.Source.Name != "" {
+		source, err := d.GetSourceByName(highlight.Source.Name)
+		if err == nil && source != nil {
+			highlight.SourceID = source.ID
+		}
+	}
+	highlight.OriginHash = entities.HighlightOriginHash(highlight.Text, highlight.Note)
+	var book entities.Book
+	if err := d.DB.Select("title", "author").First(&book, highlight.BookID).Error; err != nil {
+		return err
+	}
+	highlight.ContentHash = entities.HighlightContentHash(book.Title, book.Author, highlight.Text)
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Source", "Book", "User", "Tags").Create(highlight).Error; err != nil {
+			return err
+		}
+		return refreshBookCounters(tx, highlight.BookID)
+	})
+	if err != nil {
+		return err
+	}
+	d.events.Publish(events.TypeHighlightCreated, highlight)
+	return nil
 }
 
 // DeleteHighlight performs a soft delete (sets DeletedAt timestamp) and clears tag associations.
@@ -455,7 +561,10 @@ func (d *Database) DeleteHighlight(id uint) error {
 			return err
 		}
 		// Soft delete the highlight
-		return tx.Delete(&entities.Highlight{}, id).Error
+		if err := tx.Delete(&entities.Highlight{}, id).Error; err != nil {
+			return err
+		}
+		return refreshHighlightBookCounters(tx, id)
 	})
 }
 
@@ -468,26 +577,42 @@ func (d *Database) DeleteHighlightPermanently(id uint, userID uint) error {
 		return err
 	}
 
-	entityKey := fmt.Sprintf("%s|%d|%s", highlight.Text, highlight.LocationValue, highlight.HighlightedAt.Format("2006-01-02 15:04:05"))
+	if highlight.ContentHash == "" {
+		var book entities.Book
+		if err := d.DB.Unscoped().Select("title", "author").First(&book, highlight.BookID).Error; err != nil {
+			return err
+		}
+		highlight.ContentHash = entities.HighlightContentHash(book.Title, book.Author, highlight.Text)
+	}
 
 	return d.DB.Transaction(func(tx *gorm.DB) error {
-		// Delete highlight-tag associations
+		// Delete highlight-tag associations, links and categories
 		if err := tx.Exec("DELETE FROM highlight_tags WHERE highlight_id = ?", id).Error; err != nil {
 			return err
 		}
+		if err := deleteHighlightLinks(tx, []uint{id}); err != nil {
+			return err
+		}
+		if err := deleteHighlightCategories(tx, []uint{id}); err != nil {
+			return err
+		}
 
 		// Hard delete the highlight
 		if err := tx.Unscoped().Delete(&entities.Highlight{}, id).Error; err != nil {
 			return err
 		}
+		if err := refreshBookCounters(tx, highlight.BookID); err != nil {
+			return err
+		}
 
 		// Record the deletion
 		deletedEntity := entities.DeletedEntity{
-			UserID:     userID,
-			EntityType: "highlight",
-			EntityKey:  entityKey,
-			SourceID:   highlight.SourceID,
-			DeletedAt:  time.Now(),
+			UserID:      userID,
+			EntityType:  "highlight",
+			EntityKey:   highlightKey(&highlight),
+			ContentHash: highlight.ContentHash,
+			SourceID:    highlight.SourceID,
+			DeletedAt:   time.Now(),
 		}
 		return tx.Create(&deletedEntity).Error
 	})
@@ -503,12 +628,17 @@ func (d *Database) IsBookDeleted(title, author string, userID uint) (bool, error
 	return count > 0, err
 }
 
-// IsHighlightDeleted checks if a highlight has been permanently deleted.
-func (d *Database) IsHighlightDeleted(text string, locationValue int, highlightedAt time.Time, userID uint) (bool, error) {
-	entityKey := fmt.Sprintf("%s|%d|%s", text, locationValue, highlightedAt.Format("2006-01-02 15:04:05"))
+// IsHighlightDeleted checks if a highlight has been permanently deleted, by its content
+// hash or, for deletions recorded before content hashing, by text + location + timestamp.
+func (d *Database) IsHighlightDeleted(highlight *entities.Highlight, userID uint) (bool, error) {
+	match := d.DB.Where("entity_key = ?", highlightKey(highlight))
+	if highlight.ContentHash != "" {
+		match = match.Or("content_hash = ?", highlight.ContentHash)
+	}
 	var count int64
 	err := d.DB.Model(&entities.DeletedEntity{}).
-		Where("entity_type = ? AND entity_key = ? AND (user_id = ? OR user_id = 0)", "highlight", entityKey, userID).
+		Where("entity_type = ? AND (user_id = ? OR user_id = 0)", "highlight", userID).
+		Where(match).
 		Count(&count).Error
 	return count > 0, err
 }
@@ -687,6 +817,27 @@ func (d *Database) GetBooksByTag(tagID uint, userID uint) ([]entities.Book, erro
 	return books, err
 }
 
+// GetHighlightsByTag returns the highlights that have the tag themselves or
+// whose book has it, most recent first, with their books preloaded.
+func (d *Database) GetHighlightsByTag(tagID uint, userID uint, limit int) ([]entities.Highlight, error) {
+	var highlights []entities.Highlight
+	query := d.DB.Preload("Book").Preload("Source").
+		Where(`(highlights.id IN (SELECT highlight_id FROM highlight_tags WHERE tag_id = ?)
+			OR highlights.book_id IN (SELECT book_id FROM book_tags WHERE tag_id = ?))`, tagID, tagID).
+		Order("highlighted_at DESC, id DESC")
+
+	// Only filter by user_id if specified (non-zero)
+	if userID > 0 {
+		query = query.Where("highlights.user_id = ?", userID)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Find(&highlights).Error
+	return highlights, err
+}
+
 func (d *Database) GetTagByID(id uint) (*entities.Tag, error) {
 	var tag entities.Tag
 	err := d.DB.First(&tag, id).Error
@@ -709,7 +860,7 @@ func (d *Database) CreateImportSession(userID, sourceID uint) (*entities.ImportS
 }
 
 func (d *Database) UpdateImportSession(session *entities.ImportSession) error {
-	return d.DB.Save(session).Error
+	return d.DB.Omit("User", "Source").Save(session).Error
 }
 
 func (d *Database) GetImportSession(id uint) (*entities.ImportSession, error) {
@@ -778,6 +929,13 @@ func (d *Database) DeleteSetting(key string) error {
 	return d.DB.Where("key = ?", key).Delete(&entities.Setting{}).Error
 }
 
+// GetAllSettings returns every setting saved in the database, by key.
+func (d *Database) GetAllSettings() ([]entities.Setting, error) {
+	var settings []entities.Setting
+	err := d.DB.Order("key ASC").Find(&settings).Error
+	return settings, err
+}
+
 func generateToken() (string, error) {
 	bytes := make([]byte, 32)
 	if _, err := rand.Read(bytes); err != nil {
@@ -804,15 +962,18 @@ func (d *Database) StartSyncProgress(syncType entities.SyncType, totalItems int)
 	now := time.Now()
 	if result.Error == gorm.ErrRecordNotFound {
 		progress = entities.SyncProgress{
-			SyncType:   syncType,
-			Status:     entities.SyncStatusRunning,
-			TotalItems: totalItems,
-			StartedAt:  now,
-			UpdatedAt:  now,
+			SyncType:    syncType,
+			Status:      entities.SyncStatusRunning,
+			TotalItems:  totalItems,
+			StartedAt:   now,
+			UpdatedAt:   now,
+			Owner:       syncrepo.WorkerID(),
+			HeartbeatAt: now,
 		}
 		if err := d.DB.Create(&progress).Error; err != nil {
 			return nil, err
 		}
+		d.publishSyncProgress(syncType)
 		return &progress, nil
 	} else if result.Error != nil {
 		return nil, result.Error
@@ -830,16 +991,21 @@ func (d *Database) StartSyncProgress(syncType entities.SyncType, totalItems int)
 	progress.StartedAt = now
 	progress.UpdatedAt = now
 	progress.CompletedAt = nil
+	progress.Owner = syncrepo.WorkerID()
+	progress.HeartbeatAt = now
 
 	if err := d.DB.Save(&progress).Error; err != nil {
 		return nil, err
 	}
+	d.publishSyncProgress(syncType)
 	return &progress, nil
 }
 
-// UpdateSyncProgress updates the progress of an ongoing sync.
+// UpdateSyncProgress updates the progress of an ongoing sync, which makes
+// this process its owner.
 func (d *Database) UpdateSyncProgress(syncType entities.SyncType, processed, succeeded, failed, skipped int, currentItem string) error {
-	return d.DB.Model(&entities.SyncProgress{}).
+	now := time.Now()
+	err := d.DB.Model(&entities.SyncProgress{}).
 		Where("sync_type = ?", syncType).
 		Updates(map[string]any{
 			"processed":    processed,
@@ -847,8 +1013,22 @@ func (d *Database) UpdateSyncProgress(syncType entities.SyncType, processed, suc
 			"failed":       failed,
 			"skipped":      skipped,
 			"current_item": currentItem,
-			"updated_at":   time.Now(),
+			"updated_at":   now,
+			"owner":        syncrepo.WorkerID(),
+			"heartbeat_at": now,
 		}).Error
+	if err == nil {
+		d.publishSyncProgress(syncType)
+	}
+	return err
+}
+
+// SetSyncProgressLastItem records the last item finished by a resumable sync.
+// Pass 0 once the run has worked through all items.
+func (d *Database) SetSyncProgressLastItem(syncType entities.SyncType, itemID uint) error {
+	return d.DB.Model(&entities.SyncProgress{}).
+		Where("sync_type = ?", syncType).
+		Update("last_item_id", itemID).Error
 }
 
 // CompleteSyncProgress marks a sync as completed or failed.
@@ -863,30 +1043,91 @@ func (d *Database) CompleteSyncProgress(syncType entities.SyncType, status entit
 	if errorMsg != "" {
 		updates["error"] = errorMsg
 	}
-	return d.DB.Model(&entities.SyncProgress{}).
+	err := d.DB.Model(&entities.SyncProgress{}).
 		Where("sync_type = ?", syncType).
 		Updates(updates).Error
+	if err == nil {
+		d.publishSyncProgress(syncType)
+	}
+	return err
 }
 
-// IsMetadataSyncRunning checks if a metadata sync is currently in progress.
-// A sync is considered stale if it hasn't been updated in more than 10 minutes.
-func (d *Database) IsMetadataSyncRunning() (bool, error) {
-	var progress entities.SyncProgress
-	err := d.DB.Where("sync_type = ? AND status = ?", entities.SyncTypeMetadata, entities.SyncStatusRunning).First(&progress).Error
-	if err == gorm.ErrRecordNotFound {
-		return false, nil
+// InterruptSyncProgress fails the sync of the given type if it is still
+// running, keeping its counts and last finished item so that a resumable sync
+// can continue where it stopped. Returns whether a running sync was found.
+func (d *Database) InterruptSyncProgress(syncType entities.SyncType, reason string) (bool, error) {
+	now := time.Now()
+	result := d.DB.Model(&entities.SyncProgress{}).
+		Where("sync_type = ? AND status = ?", syncType, entities.SyncStatusRunning).
+		Updates(map[string]any{
+			"status":       entities.SyncStatusFailed,
+			"error":        reason,
+			"current_item": "",
+			"updated_at":   now,
+			"completed_at": now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	d.publishSyncProgress(syncType)
+	return true, nil
+}
+
+// publishSyncProgress announces the current state of a sync to event
+// subscribers, as a completion once it is no longer running
+func (d *Database) publishSyncProgress(syncType entities.SyncType) {
+	if !d.events.HasSubscribers() {
+		return
 	}
+	progress, err := d.GetSyncProgress(syncType)
 	if err != nil {
-		return false, err
+		return
+	}
+	if progress.Status == entities.SyncStatusRunning {
+		d.events.Publish(events.TypeSyncProgress, progress)
+	} else {
+		d.events.Publish(events.TypeSyncCompleted, progress)
 	}
+}
 
-	// Consider sync stale if not updated in 10 minutes (cleanup interrupted syncs)
-	staleThreshold := time.Now().Add(-10 * time.Minute)
-	if progress.UpdatedAt.Before(staleThreshold) {
-		// Mark the stale sync as failed
-		_ = d.CompleteSyncProgress(entities.SyncTypeMetadata, entities.SyncStatusFailed, "sync was interrupted")
-		return false, nil
+// RecordSyncItem counts one finished item of a running sync whose items are
+// processed independently, e.g. by queued tasks, and completes the sync once
+// every item is counted.
+func (d *Database) RecordSyncItem(syncType entities.SyncType, outcome entities.SyncOutcome, currentItem string) error {
+	switch outcome {
+	case entities.SyncOutcomeSucceeded, entities.SyncOutcomeFailed, entities.SyncOutcomeSkipped:
+	default:
+		return fmt.Errorf("unknown sync outcome %q", outcome)
 	}
 
-	return true, nil
+	var progress entities.SyncProgress
+	now := time.Now()
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.SyncProgress{}).
+			Where("sync_type = ? AND status = ?", syncType, entities.SyncStatusRunning).
+			Updates(map[string]any{
+				"processed":     gorm.Expr("processed + 1"),
+				string(outcome): gorm.Expr(string(outcome) + " + 1"),
+				"current_item":  currentItem,
+				"updated_at":    now,
+				"owner":         syncrepo.WorkerID(),
+				"heartbeat_at":  now,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Where("sync_type = ?", syncType).First(&progress).Error
+	})
+	if err != nil || progress.ID == 0 {
+		return err
+	}
+	if progress.Processed < progress.TotalItems {
+		d.publishSyncProgress(syncType)
+		return nil
+	}
+
+	if progress.Failed > 0 {
+		return d.CompleteSyncProgress(syncType, entities.SyncStatusFailed, fmt.Sprintf("%d of %d items failed", progress.Failed, progress.TotalItems))
+	}
+	return d.CompleteSyncProgress(syncType, entities.SyncStatusCompleted, "")
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
@@ -324,6 +325,53 @@ func TestTagHighlightAssociations(t *testing.T) {
 	})
 }
 
+func TestGetHighlightsByTag(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	user, err := db.CreateUser("taguser", "tag@example.com")
+	require.NoError(t, err)
+
+	tagged := &entities.Book{
+		Title:  "Tagged Book",
+		Author: "Author",
+		UserID: user.ID,
+		Highlights: []entities.Highlight{
+			{Text: "From the tagged book", UserID: user.ID},
+		},
+	}
+	other := &entities.Book{
+		Title:  "Other Book",
+		Author: "Author",
+		UserID: user.ID,
+		Highlights: []entities.Highlight{
+			{Text: "Tagged highlight", UserID: user.ID},
+			{Text: "Untagged highlight", UserID: user.ID},
+		},
+	}
+	require.NoError(t, db.SaveBook(tagged))
+	require.NoError(t, db.SaveBook(other))
+
+	tag, err := db.CreateTag("commute", user.ID)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToBook(tagged.ID, tag.ID))
+	require.NoError(t, db.AddTagToHighlight(other.Highlights[0].ID, tag.ID))
+
+	highlights, err := db.GetHighlightsByTag(tag.ID, user.ID, 0)
+	require.NoError(t, err)
+
+	var texts []string
+	for _, h := range highlights {
+		texts = append(texts, h.Text)
+		assert.NotZero(t, h.Book.ID, "book should be preloaded")
+	}
+	assert.ElementsMatch(t, []string{"From the tagged book", "Tagged highlight"}, texts)
+
+	highlights, err = db.GetHighlightsByTag(tag.ID, user.ID+1, 0)
+	require.NoError(t, err)
+	assert.Empty(t, highlights)
+}
+
 // --- ImportSession Operations Tests ---
 
 func TestImportSessionOperations(t *testing.T) {
@@ -792,6 +840,64 @@ func TestHighlightOperations(t *testing.T) {
 		assert.Equal(t, "Added a note", updated.Note)
 	})
 
+	t.Run("CreateHighlight adds highlight to book", func(t *testing.T) {
+		highlight := &entities.Highlight{
+			BookID:        book.ID,
+			UserID:        user.ID,
+			Text:          "Typed Highlight",
+			LocationType:  entities.LocationTypePage,
+			LocationValue: 42,
+			Source:        entities.Source{Name: "manual"},
+		}
+		broker := events.NewBroker()
+		db.SetEventBroker(broker)
+		defer db.SetEventBroker(nil)
+		ch, unsubscribe := broker.Subscribe()
+		defer unsubscribe()
+
+		err := db.CreateHighlight(highlight)
+		require.NoError(t, err)
+		require.NotZero(t, highlight.ID)
+
+		event := <-ch
+		assert.Equal(t, events.TypeHighlightCreated, event.Type)
+		assert.Equal(t, highlight.ID, event.Data.(*entities.Highlight).ID)
+
+		created, err := db.GetHighlightByID(highlight.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "manual", created.Source.Name)
+		assert.False(t, created.IsLocallyEdited())
+
+		require.NoError(t, db.DeleteHighlightPermanently(highlight.ID, user.ID))
+	})
+
+	t.Run("SearchHighlights matches text and note", func(t *testing.T) {
+		highlights, err := db.SearchHighlights("first", 10)
+		require.NoError(t, err)
+		require.Len(t, highlights, 1)
+		assert.Equal(t, "First Highlight", highlights[0].Text)
+		assert.Equal(t, "Highlight Test Book", highlights[0].Book.Title)
+
+		highlights, err = db.SearchHighlights("ADDED A NOTE", 10)
+		require.NoError(t, err)
+		assert.Len(t, highlights, 1)
+
+		highlights, err = db.SearchHighlights("missing", 10)
+		require.NoError(t, err)
+		assert.Empty(t, highlights)
+	})
+
+	t.Run("GetRandomHighlights returns highlights with books", func(t *testing.T) {
+		highlights, err := db.GetRandomHighlights(5)
+		require.NoError(t, err)
+		require.Len(t, highlights, 2)
+		assert.Equal(t, "Highlight Test Book", highlights[0].Book.Title)
+
+		highlights, err = db.GetRandomHighlights(1)
+		require.NoError(t, err)
+		assert.Len(t, highlights, 1)
+	})
+
 	t.Run("DeleteHighlight soft deletes highlight", func(t *testing.T) {
 		err := db.DeleteHighlight(book.Highlights[1].ID)
 		require.NoError(t, err)
@@ -966,7 +1072,17 @@ func TestPermanentDelete(t *testing.T) {
 		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
 
 		// Check that highlight is marked as deleted
-		isDeleted, err := db.IsHighlightDeleted("Delete me", 200, now, user.ID)
+		isDeleted, err := db.IsHighlightDeleted(&entities.Highlight{Text: "Delete me", LocationValue: 200, HighlightedAt: now}, user.ID)
+		require.NoError(t, err)
+		assert.True(t, isDeleted)
+
+		// The same text with a drifted timestamp matches by content hash
+		isDeleted, err = db.IsHighlightDeleted(&entities.Highlight{
+			Text:          "Delete me",
+			LocationValue: 201,
+			HighlightedAt: now.Add(time.Hour),
+			ContentHash:   entities.HighlightContentHash("Book With Deletable Highlight", "Highlight Author", "Delete me"),
+		}, user.ID)
 		require.NoError(t, err)
 		assert.True(t, isDeleted)
 	})
@@ -1116,3 +1232,208 @@ func TestDatabaseInitialization(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestSaveBooks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	t.Run("saves the batch and sets IDs", func(t *testing.T) {
+		books := []entities.Book{
+			{Title: "Batch One", Author: "Author", Highlights: []entities.Highlight{{Text: "First"}}},
+			{Title: "Batch Two", Author: "Author", Highlights: []entities.Highlight{{Text: "Second"}}},
+		}
+		require.NoError(t, db.SaveBooks(books))
+		assert.NotZero(t, books[0].ID)
+		assert.NotZero(t, books[1].ID)
+
+		// A later batch with the same book merges into it
+		more := []entities.Book{
+			{Title: "Batch One", Author: "Author", Highlights: []entities.Highlight{{Text: "Third", LocationValue: 3}}},
+		}
+		require.NoError(t, db.SaveBooks(more))
+		assert.Equal(t, books[0].ID, more[0].ID)
+
+		saved, err := db.GetBookByID(books[0].ID)
+		require.NoError(t, err)
+		assert.Len(t, saved.Highlights, 2)
+	})
+
+	t.Run("rolls back the batch when a book fails", func(t *testing.T) {
+		require.NoError(t, db.DB.Callback().Create().Before("gorm:create").Register("test:fail_book", func(tx *gorm.DB) {
+			if book, ok := tx.Statement.Dest.(*entities.Book); ok && book.Title == "Broken" {
+				_ = tx.AddError(assert.AnError)
+			}
+		}))
+		defer func() { _ = db.DB.Callback().Create().Remove("test:fail_book") }()
+
+		books := []entities.Book{
+			{Title: "Rolled Back", Author: "Author", Highlights: []entities.Highlight{{Text: "Lost"}}},
+			{Title: "Broken", Author: "Author", Highlights: []entities.Highlight{{Text: "Fails"}}},
+		}
+		err := db.SaveBooks(books)
+		require.ErrorIs(t, err, assert.AnError)
+
+		_, err = db.GetBookByTitleAndAuthor("Rolled Back", "Author")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestSaveBook_DeduplicatesByContentHash(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	highlightedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	book := &entities.Book{
+		Title:      "Hash Book",
+		Author:     "Author",
+		Source:     entities.Source{Name: "kindle"},
+		Highlights: []entities.Highlight{{Text: "It’s a  passage", LocationValue: 10, HighlightedAt: highlightedAt}},
+	}
+	require.NoError(t, db.SaveBook(book))
+	require.NotEmpty(t, book.Highlights[0].ContentHash)
+
+	// Another source sends the passage with a drifted timestamp, another location and plain quotes
+	reimport := &entities.Book{
+		Title:  "Hash Book",
+		Author: "Author",
+		Source: entities.Source{Name: "readwise"},
+		Highlights: []entities.Highlight{
+			{Text: "it's a passage", LocationValue: 12, HighlightedAt: highlightedAt.Add(3 * time.Hour)},
+			{Text: "Another passage", LocationValue: 20},
+		},
+	}
+	require.NoError(t, db.SaveBook(reimport))
+	assert.Equal(t, book.Highlights[0].ID, reimport.Highlights[0].ID)
+
+	saved, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.Len(t, saved.Highlights, 2)
+}
+
+func TestUpdateBookMetadata_RehashesHighlights(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title:  "Old Title",
+		Author: "Author",
+		Highlights: []entities.Highlight{
+			{Text: "First passage", LocationValue: 1},
+			{Text: "Second passage", LocationValue: 2},
+		},
+	}
+	require.NoError(t, db.SaveBook(book))
+
+	// A local edit keeps the hash of the text the highlight was imported with
+	edited := book.Highlights[1]
+	edited.Text = "Second passage, edited"
+	require.NoError(t, db.UpdateHighlight(&edited))
+
+	require.NoError(t, db.UpdateBookMetadata(book.ID, map[string]any{"title": "New Title"}))
+
+	saved, err := db.GetHighlightsForBook(book.ID)
+	require.NoError(t, err)
+	require.Len(t, saved, 2)
+	assert.Equal(t, entities.HighlightContentHash("New Title", "Author", "First passage"), saved[0].ContentHash)
+	assert.Equal(t, entities.HighlightContentHash("New Title", "Author", "Second passage"), saved[1].ContentHash)
+
+	// A highlight added after the rename is recognized as a duplicate of the first one
+	duplicate := &entities.Highlight{BookID: book.ID, Text: "first passage"}
+	require.NoError(t, db.CreateHighlight(duplicate))
+	assert.Equal(t, saved[0].ContentHash, duplicate.ContentHash)
+}
+
+func TestRecordSyncItem(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := db.StartSyncProgress(entities.SyncTypeReenrich, 3)
+	require.NoError(t, err)
+
+	require.NoError(t, db.RecordSyncItem(entities.SyncTypeReenrich, entities.SyncOutcomeSucceeded, "Dune"))
+	require.NoError(t, db.RecordSyncItem(entities.SyncTypeReenrich, entities.SyncOutcomeSkipped, "Emma"))
+
+	progress, err := db.GetSyncProgress(entities.SyncTypeReenrich)
+	require.NoError(t, err)
+	assert.Equal(t, entities.SyncStatusRunning, progress.Status)
+	assert.Equal(t, 2, progress.Processed)
+	assert.Equal(t, "Emma", progress.CurrentItem)
+
+	running, err := db.IsSyncRunning(entities.SyncTypeReenrich)
+	require.NoError(t, err)
+	assert.True(t, running)
+
+	// The last item completes the sync, failed because one book failed
+	require.NoError(t, db.RecordSyncItem(entities.SyncTypeReenrich, entities.SyncOutcomeFailed, "book 3"))
+	progress, err = db.GetSyncProgress(entities.SyncTypeReenrich)
+	require.NoError(t, err)
+	assert.Equal(t, entities.SyncStatusFailed, progress.Status)
+	assert.Equal(t, 1, progress.Succeeded)
+	assert.Equal(t, 1, progress.Skipped)
+	assert.Equal(t, 1, progress.Failed)
+	assert.NotNil(t, progress.CompletedAt)
+
+	// Items of a finished sync are not counted
+	require.NoError(t, db.RecordSyncItem(entities.SyncTypeReenrich, entities.SyncOutcomeSucceeded, "late"))
+	progress, err = db.GetSyncProgress(entities.SyncTypeReenrich)
+	require.NoError(t, err)
+	assert.Equal(t, 3, progress.Processed)
+
+	assert.Error(t, db.RecordSyncItem(entities.SyncTypeReenrich, "processed", "bad"))
+}
+
+func TestInterruptSyncProgress(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := db.StartSyncProgress(entities.SyncTypeMetadata, 10)
+	require.NoError(t, err)
+	require.NoError(t, db.UpdateSyncProgress(entities.SyncTypeMetadata, 4, 3, 1, 0, "Dune"))
+	require.NoError(t, db.SetSyncProgressLastItem(entities.SyncTypeMetadata, 42))
+
+	interrupted, err := db.InterruptSyncProgress(entities.SyncTypeMetadata, "interrupted by shutdown")
+	require.NoError(t, err)
+	assert.True(t, interrupted)
+
+	progress, err := db.GetSyncProgress(entities.SyncTypeMetadata)
+	require.NoError(t, err)
+	assert.Equal(t, entities.SyncStatusFailed, progress.Status)
+	assert.Equal(t, "interrupted by shutdown", progress.Error)
+	assert.Equal(t, 4, progress.Processed)
+	assert.Equal(t, uint(42), progress.LastItemID, "kept for resuming")
+	assert.Empty(t, progress.CurrentItem)
+	assert.NotNil(t, progress.CompletedAt)
+
+	// Only running syncs are interrupted
+	interrupted, err = db.InterruptSyncProgress(entities.SyncTypeMetadata, "again")
+	require.NoError(t, err)
+	assert.False(t, interrupted)
+	interrupted, err = db.InterruptSyncProgress(entities.SyncTypeReenrich, "never started")
+	require.NoError(t, err)
+	assert.False(t, interrupted)
+}
+
+func TestSyncProgressEvents(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	broker := events.NewBroker()
+	ch, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+	db.SetEventBroker(broker)
+
+	_, err := db.StartSyncProgress(entities.SyncTypeReenrich, 2)
+	require.NoError(t, err)
+	require.NoError(t, db.RecordSyncItem(entities.SyncTypeReenrich, entities.SyncOutcomeSucceeded, "Dune"))
+	require.NoError(t, db.RecordSyncItem(entities.SyncTypeReenrich, entities.SyncOutcomeSucceeded, "Emma"))
+
+	var types []string
+	for len(ch) > 0 {
+		event := <-ch
+		types = append(types, event.Type)
+		if event.Type == events.TypeSyncCompleted {
+			assert.Equal(t, entities.SyncStatusCompleted, event.Data.(*entities.SyncProgress).Status)
+		}
+	}
+	assert.Equal(t, []string{events.TypeSyncProgress, events.TypeSyncProgress, events.TypeSyncCompleted}, types)
+}
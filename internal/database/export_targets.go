@@ -0,0 +1,59 @@
+package database
+
+import (
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ListExportTargets returns all export targets ordered by name
+func (d *Database) ListExportTargets() ([]entities.ExportTarget, error) {
+	var targets []entities.ExportTarget
+	err := d.DB.Order("name ASC").Find(&targets).Error
+	return targets, err
+}
+
+// GetExportTarget returns the export target with the given ID
+func (d *Database) GetExportTarget(id uint) (*entities.ExportTarget, error) {
+	var target entities.ExportTarget
+	if err := d.DB.First(&target, id).Error; err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// GetExportTargetByName returns the export target with the given name
+func (d *Database) GetExportTargetByName(name string) (*entities.ExportTarget, error) {
+	var target entities.ExportTarget
+	if err := d.DB.Where("name = ?", name).First(&target).Error; err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// CreateExportTarget saves a new export target
+func (d *Database) CreateExportTarget(target *entities.ExportTarget) error {
+	return d.DB.Create(target).Error
+}
+
+// UpdateExportTarget saves the configuration of an existing export target.
+// The last run status is left unchanged.
+func (d *Database) UpdateExportTarget(target *entities.ExportTarget) error {
+	return d.DB.Model(target).
+		Select("Name", "Path", "Format", "FavoritesOnly", "Tags", "Sources", "Since", "IncludeVocabulary", "Schedule").
+		Updates(target).Error
+}
+
+// DeleteExportTarget removes an export target
+func (d *Database) DeleteExportTarget(id uint) error {
+	return d.DB.Delete(&entities.ExportTarget{}, id).Error
+}
+
+// RecordExportTargetRun stores the outcome of an export target run
+func (d *Database) RecordExportTargetRun(id uint, status, message string, at time.Time) error {
+	return d.DB.Model(&entities.ExportTarget{}).Where("id = ?", id).Updates(map[string]any{
+		"last_run_at":  at,
+		"last_status":  status,
+		"last_message": message,
+	}).Error
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestExportTargets_CRUD(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	vault := &entities.ExportTarget{Name: "vault", Path: "/vault", Format: "markdown", Tags: []string{"stoic", "to read"}}
+	require.NoError(t, db.CreateExportTarget(vault))
+	require.NoError(t, db.CreateExportTarget(&entities.ExportTarget{Name: "graph", Path: "/graph", Format: "logseq", FavoritesOnly: true}))
+
+	targets, err := db.ListExportTargets()
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, "graph", targets[0].Name, "targets are ordered by name")
+	assert.True(t, targets[0].FavoritesOnly)
+	assert.Equal(t, []string{"stoic", "to read"}, targets[1].Tags)
+
+	byName, err := db.GetExportTargetByName("vault")
+	require.NoError(t, err)
+	assert.Equal(t, vault.ID, byName.ID)
+
+	ranAt := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, db.RecordExportTargetRun(vault.ID, entities.ExportTargetStatusSuccess, "Exported 3 books", ranAt))
+
+	vault.Path = "/vault/highlights"
+	vault.Tags = nil
+	vault.Schedule = "0 * * * *"
+	require.NoError(t, db.UpdateExportTarget(vault))
+
+	saved, err := db.GetExportTarget(vault.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "/vault/highlights", saved.Path)
+	assert.Empty(t, saved.Tags)
+	assert.Equal(t, "0 * * * *", saved.Schedule)
+	require.NotNil(t, saved.LastRunAt, "updates keep the last run")
+	assert.True(t, ranAt.Equal(*saved.LastRunAt))
+	assert.Equal(t, "Exported 3 books", saved.LastMessage)
+
+	require.NoError(t, db.DeleteExportTarget(vault.ID))
+	_, err = db.GetExportTarget(vault.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -1,36 +1,63 @@
 package database
 
-import "github.com/mrlokans/assistant/internal/entities"
+import (
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
 
 // SetHighlightFavourite updates the favourite status of a highlight.
 func (d *Database) SetHighlightFavourite(highlightID uint, isFavourite bool) error {
-	return d.DB.Model(&entities.Highlight{}).
-		Where("id = ?", highlightID).
-		Update("is_favorite", isFavourite).Error
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.Highlight{}).
+			Where("id = ?", highlightID).
+			Update("is_favorite", isFavourite).Error; err != nil {
+			return err
+		}
+		return refreshHighlightBookCounters(tx, highlightID)
+	})
 }
 
-// GetFavouriteHighlights returns all favourite highlights for a user with pagination.
-// Returns the highlights, total count, and any error.
-func (d *Database) GetFavouriteHighlights(userID uint, limit, offset int) ([]entities.Highlight, int64, error) {
-	var highlights []entities.Highlight
-	var total int64
+// SetBookFavourite pins a book to the top of the library, or unpins it.
+func (d *Database) SetBookFavourite(bookID uint, isFavourite bool) error {
+	result := d.DB.Model(&entities.Book{}).
+		Where("id = ?", bookID).
+		Update("is_favorite", isFavourite)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
 
-	query := d.DB.Model(&entities.Highlight{}).Where("is_favorite = ?", true)
+// GetFavouriteBooks returns the user's favourite books by title. A userID of 0
+// returns the favourite books of all users.
+func (d *Database) GetFavouriteBooks(userID uint) ([]entities.Book, error) {
+	query := d.DB.Preload("Tags").Preload("Source").Where("is_favorite = ?", true)
 	if userID > 0 {
 		query = query.Where("user_id = ?", userID)
 	}
+	var books []entities.Book
+	err := query.Order("title COLLATE NOCASE ASC").Find(&books).Error
+	return books, err
+}
 
-	if err := query.Count(&total).Error; err != nil {
+// GetFavouriteHighlights returns all favourite highlights for a user with pagination.
+// Returns the highlights, total count, and any error.
+func (d *Database) GetFavouriteHighlights(userID uint, limit, offset int) ([]entities.Highlight, int64, error) {
+	favourite := true
+	scopes := []func(*gorm.DB) *gorm.DB{favouriteHighlights(&favourite), highlightsForUser(userID)}
+
+	var total int64
+	if err := d.DB.Model(&entities.Highlight{}).Scopes(scopes...).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	query = d.DB.Preload("Tags").Preload("Book").Preload("Source").
-		Where("is_favorite = ?", true).
+	query := d.DB.Preload("Tags").Preload("Book").Preload("Source").
+		Scopes(scopes...).
 		Order("updated_at DESC")
-
-	if userID > 0 {
-		query = query.Where("user_id = ?", userID)
-	}
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
@@ -38,15 +65,17 @@ func (d *Database) GetFavouriteHighlights(userID uint, limit, offset int) ([]ent
 		query = query.Offset(offset)
 	}
 
+	var highlights []entities.Highlight
 	err := query.Find(&highlights).Error
 	return highlights, total, err
 }
 
 // GetFavouriteHighlightsByBook returns all favourite highlights for a specific book.
 func (d *Database) GetFavouriteHighlightsByBook(bookID uint) ([]entities.Highlight, error) {
+	favourite := true
 	var highlights []entities.Highlight
 	err := d.DB.Preload("Tags").
-		Where("book_id = ? AND is_favorite = ?", bookID, true).
+		Scopes(highlightsOfBook(bookID), favouriteHighlights(&favourite)).
 		Order("location_value ASC, highlighted_at ASC").
 		Find(&highlights).Error
 	return highlights, err
@@ -54,11 +83,10 @@ func (d *Database) GetFavouriteHighlightsByBook(bookID uint) ([]entities.Highlig
 
 // GetFavouriteCount returns the total number of favourite highlights.
 func (d *Database) GetFavouriteCount(userID uint) (int64, error) {
+	favourite := true
 	var count int64
-	query := d.DB.Model(&entities.Highlight{}).Where("is_favorite = ?", true)
-	if userID > 0 {
-		query = query.Where("user_id = ?", userID)
-	}
-	err := query.Count(&count).Error
+	err := d.DB.Model(&entities.Highlight{}).
+		Scopes(favouriteHighlights(&favourite), highlightsForUser(userID)).
+		Count(&count).Error
 	return count, err
 }
@@ -1,4 +1,5 @@
-// Package favourites provides database operations for favourite highlight management.
+// Package favourites provides database operations for favourite highlight and
+// book management.
 //
 // This package implements the FavouritesStore interface defined in internal/http/favourites.go.
 //
@@ -97,3 +98,38 @@ func (r *Repository) GetHighlightByID(id uint) (*entities.Highlight, error) {
 	}
 	return &highlight, nil
 }
+
+// SetBookFavourite pins a book to the top of the library, or unpins it.
+func (r *Repository) SetBookFavourite(bookID uint, isFavourite bool) error {
+	result := r.db.Model(&entities.Book{}).
+		Where("id = ?", bookID).
+		Update("is_favorite", isFavourite)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetFavouriteBooks returns the user's favourite books by title.
+func (r *Repository) GetFavouriteBooks(userID uint) ([]entities.Book, error) {
+	var books []entities.Book
+	query := r.db.Preload("Tags").Preload("Source").Where("is_favorite = ?", true)
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Order("title COLLATE NOCASE ASC").Find(&books).Error
+	return books, err
+}
+
+// GetBookByID retrieves a book by ID (for FavouritesStore interface).
+func (r *Repository) GetBookByID(id uint) (*entities.Book, error) {
+	var book entities.Book
+	err := r.db.Preload("Tags").Preload("Source").First(&book, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
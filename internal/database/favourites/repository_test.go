@@ -180,3 +180,26 @@ func TestRepository_GetHighlightByID_NotFound(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestRepository_SetBookFavourite(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	createTestBook(t, db, "Walden")
+	book := createTestBook(t, db, "Meditations")
+	createTestBook(t, db, "Essays")
+
+	require.NoError(t, repo.SetBookFavourite(book.ID, true))
+
+	books, err := repo.GetFavouriteBooks(0)
+	require.NoError(t, err)
+	require.Len(t, books, 1)
+	assert.Equal(t, "Meditations", books[0].Title)
+
+	require.NoError(t, repo.SetBookFavourite(book.ID, false))
+	books, err = repo.GetFavouriteBooks(0)
+	require.NoError(t, err)
+	assert.Empty(t, books)
+
+	assert.ErrorIs(t, repo.SetBookFavourite(999, true), gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// SetHighlightCategories replaces the categories the classifier assigned to a
+// highlight. An empty list clears them.
+func (d *Database) SetHighlightCategories(highlightID uint, categories []entities.HighlightCategory, classifier string) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteHighlightCategories(tx, []uint{highlightID}); err != nil {
+			return err
+		}
+		seen := make(map[entities.HighlightCategory]bool, len(categories))
+		for _, category := range categories {
+			if seen[category] {
+				continue
+			}
+			seen[category] = true
+			tag := entities.HighlightCategoryTag{HighlightID: highlightID, Category: category, Classifier: classifier}
+			if err := tx.Create(&tag).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CountHighlightCategories returns how many of a user's highlights are in each
+// category, leaving out trashed highlights and categories without any.
+func (d *Database) CountHighlightCategories(userID uint) (map[entities.HighlightCategory]int64, error) {
+	var rows []struct {
+		Category entities.HighlightCategory
+		Count    int64
+	}
+	err := d.DB.Model(&entities.HighlightCategoryTag{}).
+		Select("highlight_categories.category AS category, COUNT(*) AS count").
+		Joins("JOIN highlights ON highlights.id = highlight_categories.highlight_id AND highlights.deleted_at IS NULL").
+		Scopes(highlightsForUser(userID)).
+		Group("highlight_categories.category").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[entities.HighlightCategory]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Category] = row.Count
+	}
+	return counts, nil
+}
+
+// deleteHighlightCategories removes the categories of the given highlights
+func deleteHighlightCategories(tx *gorm.DB, highlightIDs []uint) error {
+	if len(highlightIDs) == 0 {
+		return nil
+	}
+	return tx.Where("highlight_id IN ?", highlightIDs).Delete(&entities.HighlightCategoryTag{}).Error
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetHighlightCategories(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := saveTrashTestBook(t, db, "Book", "Advice", "Plain")
+	id := book.Highlights[0].ID
+
+	require.NoError(t, db.SetHighlightCategories(id, []entities.HighlightCategory{
+		entities.HighlightCategoryAdvice, entities.HighlightCategoryQuote, entities.HighlightCategoryAdvice,
+	}, "rules"))
+
+	highlights, total, err := db.ListHighlights(entities.HighlightFilter{Category: entities.HighlightCategoryQuote}, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assert.Equal(t, id, highlights[0].ID)
+	require.Len(t, highlights[0].Categories, 2)
+	assert.Equal(t, "rules", highlights[0].Categories[0].Classifier)
+
+	// Classifying again replaces the categories
+	require.NoError(t, db.SetHighlightCategories(id, []entities.HighlightCategory{entities.HighlightCategoryAdvice}, "http"))
+	counts, err := db.CountHighlightCategories(0)
+	require.NoError(t, err)
+	assert.Equal(t, map[entities.HighlightCategory]int64{entities.HighlightCategoryAdvice: 1}, counts)
+
+	// Trashed highlights are not counted, and permanently deleted ones lose their categories
+	require.NoError(t, db.DeleteHighlight(id))
+	counts, err = db.CountHighlightCategories(0)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+
+	require.NoError(t, db.DeleteHighlightPermanently(id, 0))
+	var remaining int64
+	require.NoError(t, db.DB.Model(&entities.HighlightCategoryTag{}).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+}
@@ -0,0 +1,249 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// mergeHighlights matches incoming highlights against a book's existing ones,
+// by content hash first, then by source ID and, for edited highlights, by position.
+// Both slices must have ContentHash set, see setContentHashes. Matched highlights keep their ID and favourite status. Unedited highlights take the
+// source's text and note (recording the previous one as a reimport version); locally
+// edited highlights, whose IDs are in edited (see locallyEditedHighlightIDs), keep
+// theirs, and a differing source copy is recorded as a source version.
+func mergeHighlights(existing, incoming []entities.Highlight, edited map[uint]bool) ([]entities.Highlight, []entities.HighlightVersion) {
+	byHash := make(map[string][]*entities.Highlight) // A book can contain the same passage twice
+	byExternalID := make(map[string]*entities.Highlight)
+	byPosition := make(map[string]*entities.Highlight) // Edited highlights only, their text no longer matches the source
+	for i := range existing {
+		h := &existing[i]
+		byHash[h.ContentHash] = append(byHash[h.ContentHash], h)
+		if h.ExternalID != "" {
+			byExternalID[fmt.Sprintf("%d|%s", h.SourceID, h.ExternalID)] = h
+		}
+		if edited[h.ID] && hasPosition(h) {
+			byPosition[highlightPositionKey(h)] = h
+		}
+	}
+
+	matched := make(map[uint]bool)
+	var merged []entities.Highlight
+	var versions []entities.HighlightVersion
+	for _, h := range incoming {
+		incomingHash := entities.HighlightOriginHash(h.Text, h.Note)
+
+		var match *entities.Highlight
+		for _, candidate := range byHash[h.ContentHash] {
+			if !matched[candidate.ID] {
+				match = candidate
+				break
+			}
+		}
+		if match == nil && h.ExternalID != "" {
+			match = byExternalID[fmt.Sprintf("%d|%s", h.SourceID, h.ExternalID)]
+		}
+		if match == nil && hasPosition(&h) {
+			match = byPosition[highlightPositionKey(&h)]
+		}
+		if match == nil || matched[match.ID] {
+			h.OriginHash = incomingHash
+			merged = append(merged, h)
+			continue
+		}
+		matched[match.ID] = true
+
+		h.ID = match.ID
+		h.IsFavorite = match.IsFavorite
+		if match.SourceID != 0 {
+			// A highlight stays with the source that first imported it
+			h.SourceID = match.SourceID
+		}
+		if h.LocationRef == "" {
+			// Sources without app locations do not drop those of others
+			h.LocationRef = match.LocationRef
+		}
+		if h.URL == "" {
+			h.URL = match.URL
+		}
+
+		if edited[match.ID] {
+			sourceChanged := incomingHash != match.OriginHash
+			if match.OriginHash == "" {
+				sourceChanged = h.Text != match.Text || h.Note != match.Note
+			}
+			if sourceChanged {
+				versions = append(versions, entities.HighlightVersion{
+					HighlightID: match.ID,
+					Text:        h.Text,
+					Note:        h.Note,
+					Reason:      entities.HighlightVersionReasonSource,
+				})
+			}
+			h.Text = match.Text
+			h.Note = match.Note
+		} else if h.Text != match.Text || h.Note != match.Note {
+			// Keep the previous text and note in history before the re-import overwrites them
+			versions = append(versions, entities.HighlightVersion{
+				HighlightID: match.ID,
+				Text:        match.Text,
+				Note:        match.Note,
+				Reason:      entities.HighlightVersionReasonReimport,
+			})
+		}
+
+		h.OriginHash = incomingHash
+		merged = append(merged, h)
+	}
+
+	return merged, versions
+}
+
+// locallyEditedHighlightIDs returns the IDs of highlights changed since they were imported.
+// Highlights imported before origin tracking count as edited if they have edit history.
+func (d *Database) locallyEditedHighlightIDs(highlights []entities.Highlight) (map[uint]bool, error) {
+	edited := make(map[uint]bool)
+	var untracked []uint
+	for i := range highlights {
+		if highlights[i].IsLocallyEdited() {
+			edited[highlights[i].ID] = true
+		} else if highlights[i].OriginHash == "" {
+			untracked = append(untracked, highlights[i].ID)
+		}
+	}
+	if len(untracked) == 0 {
+		return edited, nil
+	}
+
+	for start := 0; start < len(untracked); start += bulkQueryChunkSize {
+		chunk := untracked[start:min(start+bulkQueryChunkSize, len(untracked))]
+		var ids []uint
+		err := d.DB.Model(&entities.HighlightVersion{}).
+			Where("highlight_id IN ? AND reason = ?", chunk, entities.HighlightVersionReasonEdit).
+			Distinct().Pluck("highlight_id", &ids).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			edited[id] = true
+		}
+	}
+	return edited, nil
+}
+
+// GetHighlightConflicts lists locally edited highlights whose latest source copy differs
+// from their current text or note.
+func (d *Database) GetHighlightConflicts() ([]entities.HighlightConflict, error) {
+	var sourceVersions []entities.HighlightVersion
+	err := d.DB.Where("reason = ?", entities.HighlightVersionReasonSource).
+		Order("created_at DESC, id DESC").
+		Find(&sourceVersions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[uint]entities.HighlightVersion)
+	var ids []uint
+	for _, v := range sourceVersions {
+		if _, seen := latest[v.HighlightID]; !seen {
+			latest[v.HighlightID] = v
+			ids = append(ids, v.HighlightID)
+		}
+	}
+	if len(ids) == 0 {
+		return []entities.HighlightConflict{}, nil
+	}
+
+	var highlights []entities.Highlight
+	err = d.DB.Preload("Book").Preload("Tags").
+		Where("id IN ?", ids).
+		Order("book_id ASC, location_value ASC").
+		Find(&highlights).Error
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []entities.HighlightConflict{}
+	for _, h := range highlights {
+		version := latest[h.ID]
+		if h.Text == version.Text && h.Note == version.Note {
+			continue
+		}
+		conflicts = append(conflicts, entities.HighlightConflict{
+			Highlight:     h,
+			BookTitle:     h.Book.Title,
+			BookAuthor:    h.Book.Author,
+			SourceVersion: version,
+		})
+	}
+	return conflicts, nil
+}
+
+// setContentHashes fills in missing content hashes of a book's highlights. Highlights
+// stored before content hashing get theirs here until the backfill has reached them.
+func setContentHashes(title, author string, highlights []entities.Highlight) {
+	for i := range highlights {
+		if highlights[i].ContentHash == "" {
+			highlights[i].ContentHash = entities.HighlightContentHash(title, author, highlights[i].Text)
+		}
+	}
+}
+
+// rehashBookHighlights recomputes the content hashes of a book's highlights, trashed
+// ones included, after its title or author changed. Hashes come from the text the
+// highlight was created with, which is the oldest recorded version's text for
+// highlights changed since.
+func rehashBookHighlights(tx *gorm.DB, bookID uint, title, author string) error {
+	var highlights []entities.Highlight
+	if err := tx.Unscoped().Select("id", "text").Where("book_id = ?", bookID).Find(&highlights).Error; err != nil {
+		return err
+	}
+	if len(highlights) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(highlights))
+	for i, h := range highlights {
+		ids[i] = h.ID
+	}
+	// Source versions hold copies that were never applied, not earlier text
+	var versions []entities.HighlightVersion
+	if err := tx.Select("highlight_id", "text").
+		Where("highlight_id IN ? AND reason <> ?", ids, entities.HighlightVersionReasonSource).
+		Order("id ASC").Find(&versions).Error; err != nil {
+		return err
+	}
+	originalText := make(map[uint]string, len(versions))
+	for _, v := range versions {
+		if _, ok := originalText[v.HighlightID]; !ok {
+			originalText[v.HighlightID] = v.Text
+		}
+	}
+
+	for _, h := range highlights {
+		text, ok := originalText[h.ID]
+		if !ok {
+			text = h.Text
+		}
+		if err := tx.Model(&entities.Highlight{}).Unscoped().Where("id = ?", h.ID).
+			UpdateColumn("content_hash", entities.HighlightContentHash(title, author, text)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// highlightKey is the tombstone key of highlights deleted before content hashing.
+func highlightKey(h *entities.Highlight) string {
+	return fmt.Sprintf("%s|%d|%s", h.Text, h.LocationValue, h.HighlightedAt.Format("2006-01-02 15:04:05"))
+}
+
+func highlightPositionKey(h *entities.Highlight) string {
+	return fmt.Sprintf("%d|%d|%s", h.SourceID, h.LocationValue, h.HighlightedAt.Format("2006-01-02 15:04:05"))
+}
+
+func hasPosition(h *entities.Highlight) bool {
+	return h.LocationValue != 0 || !h.HighlightedAt.IsZero()
+}
@@ -0,0 +1,296 @@
+package database
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// DefaultDuplicateSimilarity is the similarity above which two highlights from
+// different sources are reported as duplicates
+const DefaultDuplicateSimilarity = 0.8
+
+// ErrNotDuplicates is returned when merging highlights that are not of the same book
+var ErrNotDuplicates = errors.New("highlights are not of the same book")
+
+// FindDuplicateHighlights finds highlights of the same book imported from
+// different sources whose normalized text is at least minSimilarity alike, e.g.
+// the same passage from a Kindle import and a Readwise sync. Each highlight is
+// in at most one pair, with its most similar counterpart. Pairs are ordered by
+// book title, most similar first.
+func (d *Database) FindDuplicateHighlights(minSimilarity float64) ([]entities.HighlightDuplicate, error) {
+	// Only books with highlights from more than one source can have duplicates.
+	// Highlights without their own source have the book's.
+	var bookIDs []uint
+	err := d.DB.Model(&entities.Highlight{}).
+		Joins("JOIN books ON books.id = highlights.book_id AND books.deleted_at IS NULL").
+		Group("highlights.book_id").
+		Having("COUNT(DISTINCT CASE WHEN highlights.source_id = 0 THEN books.source_id ELSE highlights.source_id END) > 1").
+		Pluck("highlights.book_id", &bookIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := []entities.HighlightDuplicate{}
+	for start := 0; start < len(bookIDs); start += bulkQueryChunkSize {
+		chunk := bookIDs[start:min(start+bulkQueryChunkSize, len(bookIDs))]
+		var books []entities.Book
+		err := d.DB.Preload("Highlights", func(db *gorm.DB) *gorm.DB {
+			return db.Order("highlights.id ASC")
+		}).Preload("Highlights.Source").Preload("Highlights.Tags").
+			Where("id IN ?", chunk).Find(&books).Error
+		if err != nil {
+			return nil, err
+		}
+		for i := range books {
+			duplicates = append(duplicates, findBookDuplicates(&books[i], minSimilarity)...)
+		}
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		if duplicates[i].BookTitle != duplicates[j].BookTitle {
+			return strings.ToLower(duplicates[i].BookTitle) < strings.ToLower(duplicates[j].BookTitle)
+		}
+		return duplicates[i].Similarity > duplicates[j].Similarity
+	})
+	return duplicates, nil
+}
+
+// findBookDuplicates pairs a book's highlights across sources, most similar first
+func findBookDuplicates(book *entities.Book, minSimilarity float64) []entities.HighlightDuplicate {
+	type candidate struct {
+		a, b       int
+		similarity float64
+	}
+
+	words := make([]map[string]int, len(book.Highlights))
+	for i := range book.Highlights {
+		words[i] = duplicateWords(book.Highlights[i].Text)
+	}
+	sourceOf := func(h *entities.Highlight) uint {
+		if h.SourceID != 0 {
+			return h.SourceID
+		}
+		return book.SourceID
+	}
+
+	var candidates []candidate
+	for i := range book.Highlights {
+		for j := i + 1; j < len(book.Highlights); j++ {
+			if sourceOf(&book.Highlights[i]) == sourceOf(&book.Highlights[j]) {
+				continue
+			}
+			if similarity := wordSimilarity(words[i], words[j]); similarity >= minSimilarity {
+				candidates = append(candidates, candidate{a: i, b: j, similarity: similarity})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].similarity > candidates[j].similarity
+	})
+
+	paired := make(map[int]bool)
+	var duplicates []entities.HighlightDuplicate
+	for _, c := range candidates {
+		if paired[c.a] || paired[c.b] {
+			continue
+		}
+		paired[c.a], paired[c.b] = true, true
+
+		keep, duplicate := book.Highlights[c.a], book.Highlights[c.b]
+		if highlightRichness(&duplicate) > highlightRichness(&keep) {
+			keep, duplicate = duplicate, keep
+		}
+		duplicates = append(duplicates, entities.HighlightDuplicate{
+			BookID:     book.ID,
+			BookTitle:  book.Title,
+			BookAuthor: book.Author,
+			Similarity: float64(int(c.similarity*1000)) / 1000,
+			Keep:       keep,
+			Duplicate:  duplicate,
+		})
+	}
+	return duplicates
+}
+
+// duplicateWords counts the words of a highlight. Case and punctuation are
+// ignored, so typographic quotes and dashes from different sources do not matter.
+func duplicateWords(text string) map[string]int {
+	words := make(map[string]int)
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range fields {
+		words[word]++
+	}
+	return words
+}
+
+// wordSimilarity is the Dice coefficient of two word multisets: 1 when they
+// have the same words, 0 when they share none
+func wordSimilarity(a, b map[string]int) float64 {
+	totalA, totalB := 0, 0
+	for _, n := range a {
+		totalA += n
+	}
+	for _, n := range b {
+		totalB += n
+	}
+	if totalA == 0 || totalB == 0 {
+		return 0
+	}
+
+	shared := 0
+	for word, n := range a {
+		shared += min(n, b[word])
+	}
+	return 2 * float64(shared) / float64(totalA+totalB)
+}
+
+// highlightRichness scores how much a highlight record holds, to decide which
+// of two duplicates to keep. Ties keep the older record.
+func highlightRichness(h *entities.Highlight) int {
+	score := len(h.Text) / 100 // Longer text is usually the untruncated one
+	if h.Note != "" {
+		score += 10
+	}
+	score += 3 * len(h.Tags)
+	if h.IsFavorite {
+		score += 5
+	}
+	if h.IsLocallyEdited() {
+		score += 5
+	}
+	for _, set := range []bool{h.Chapter != "", h.LocationValue != 0, h.Color != "", !h.HighlightedAt.IsZero(), h.ContextPrefix != "" || h.ContextSuffix != ""} {
+		if set {
+			score += 2
+		}
+	}
+	return score
+}
+
+// MergeDuplicateHighlights merges two highlights of the same book into the
+// richer one. Its empty fields are filled from the other, tags are combined and
+// notes kept, then the other is deleted permanently so a re-import of its
+// source does not bring it back. Returns the kept highlight.
+func (d *Database) MergeDuplicateHighlights(firstID, secondID, userID uint) (*entities.Highlight, error) {
+	if firstID == secondID {
+		return nil, ErrNotDuplicates
+	}
+
+	var first, second entities.Highlight
+	if err := d.DB.Preload("Tags").First(&first, firstID).Error; err != nil {
+		return nil, err
+	}
+	if err := d.DB.Preload("Tags").First(&second, secondID).Error; err != nil {
+		return nil, err
+	}
+	if first.BookID != second.BookID {
+		return nil, ErrNotDuplicates
+	}
+
+	keep, duplicate := first, second
+	if highlightRichness(&duplicate) > highlightRichness(&keep) {
+		keep, duplicate = duplicate, keep
+	}
+	original := keep
+	mergeHighlightFields(&keep, &duplicate)
+
+	if duplicate.ContentHash == "" {
+		var book entities.Book
+		if err := d.DB.Select("title", "author").First(&book, duplicate.BookID).Error; err != nil {
+			return nil, err
+		}
+		duplicate.ContentHash = entities.HighlightContentHash(book.Title, book.Author, duplicate.Text)
+	}
+
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if keep.Note != original.Note {
+			if err := recordHighlightVersion(tx, &original, entities.HighlightVersionReasonMerge); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("Source", "Book", "User", "Tags").Save(&keep).Error; err != nil {
+			return err
+		}
+		for _, tag := range duplicate.Tags {
+			if err := tx.Exec("INSERT OR IGNORE INTO highlight_tags (highlight_id, tag_id) VALUES (?, ?)", keep.ID, tag.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec("DELETE FROM highlight_tags WHERE highlight_id = ?", duplicate.ID).Error; err != nil {
+			return err
+		}
+		if err := moveHighlightLinks(tx, duplicate.ID, keep.ID); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&entities.Highlight{}, duplicate.ID).Error; err != nil {
+			return err
+		}
+		if err := refreshBookCounters(tx, keep.BookID, duplicate.BookID); err != nil {
+			return err
+		}
+		// A tombstone with the kept highlight's hash would block its own re-imports;
+		// those match it by hash anyway
+		if duplicate.ContentHash == keep.ContentHash {
+			return nil
+		}
+		return tx.Create(&entities.DeletedEntity{
+			UserID:      userID,
+			EntityType:  "highlight",
+			EntityKey:   highlightKey(&duplicate),
+			ContentHash: duplicate.ContentHash,
+			SourceID:    duplicate.SourceID,
+			DeletedAt:   time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return d.GetHighlightByID(keep.ID)
+}
+
+// mergeHighlightFields fills the kept highlight's empty fields from its duplicate
+func mergeHighlightFields(keep, duplicate *entities.Highlight) {
+	switch note := strings.TrimSpace(duplicate.Note); {
+	case note == "" || strings.Contains(keep.Note, note):
+	case strings.TrimSpace(keep.Note) == "":
+		keep.Note = duplicate.Note
+	default:
+		keep.Note = strings.TrimRight(keep.Note, "\n") + "\n\n" + duplicate.Note
+	}
+
+	keep.IsFavorite = keep.IsFavorite || duplicate.IsFavorite
+	if keep.Chapter == "" {
+		keep.Chapter = duplicate.Chapter
+	}
+	if keep.LocationValue == 0 && duplicate.LocationValue != 0 {
+		keep.LocationType = duplicate.LocationType
+		keep.LocationValue = duplicate.LocationValue
+		keep.LocationEnd = duplicate.LocationEnd
+	}
+	if keep.Percent == 0 {
+		keep.Percent = duplicate.Percent
+	}
+	if keep.Color == "" {
+		keep.Color = duplicate.Color
+	}
+	if keep.HighlightedAt.IsZero() {
+		keep.HighlightedAt = duplicate.HighlightedAt
+	}
+	if keep.ContextPrefix == "" && keep.ContextSuffix == "" {
+		keep.ContextPrefix = duplicate.ContextPrefix
+		keep.ContextSuffix = duplicate.ContextSuffix
+	}
+	if keep.URL == "" {
+		keep.URL = duplicate.URL
+	}
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saveCrossSourceBook saves a book with a Kindle highlight, then the same
+// passage as Readwise exported it, with curly quotes and a note
+func saveCrossSourceBook(t *testing.T, db *Database) (kindle, readwise entities.Highlight) {
+	t.Helper()
+	require.NoError(t, db.SaveBook(&entities.Book{
+		Title:  "Meditations",
+		Author: "Marcus Aurelius",
+		Source: entities.Source{Name: "kindle"},
+		Highlights: []entities.Highlight{
+			{Text: "The impediment to action advances action. What stands in the way becomes the way.", LocationValue: 120, Chapter: "Book V"},
+			{Text: "Waste no more time arguing about what a good man should be. Be one.", LocationValue: 300},
+		},
+	}))
+	require.NoError(t, db.SaveBook(&entities.Book{
+		Title:  "Meditations",
+		Author: "Marcus Aurelius",
+		Highlights: []entities.Highlight{
+			{Text: "The impediment to action advances action; what stands in the way becomes the way…", Note: "Obstacle is the way", IsFavorite: true, Source: entities.Source{Name: "readwise"}},
+			{Text: "Very little is needed to make a happy life.", Source: entities.Source{Name: "readwise"}},
+		},
+	}))
+
+	book, err := db.GetBookByTitleAndAuthor("Meditations", "Marcus Aurelius")
+	require.NoError(t, err)
+	require.Len(t, book.Highlights, 4)
+	for _, h := range book.Highlights {
+		switch {
+		case h.LocationValue == 120:
+			kindle = h
+		case h.Note != "":
+			readwise = h
+		}
+	}
+	require.NotZero(t, kindle.ID)
+	require.NotZero(t, readwise.ID)
+	return kindle, readwise
+}
+
+func TestFindDuplicateHighlights(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	kindle, readwise := saveCrossSourceBook(t, db)
+
+	duplicates, err := db.FindDuplicateHighlights(DefaultDuplicateSimilarity)
+	require.NoError(t, err)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, "Meditations", duplicates[0].BookTitle)
+	assert.InDelta(t, 1.0, duplicates[0].Similarity, 0.001)
+	assert.Equal(t, readwise.ID, duplicates[0].Keep.ID, "the highlight with a note is richer")
+	assert.Equal(t, kindle.ID, duplicates[0].Duplicate.ID)
+}
+
+func TestFindDuplicateHighlights_IgnoresSameSource(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	require.NoError(t, db.SaveBook(&entities.Book{
+		Title:  "Repeated",
+		Author: "Author",
+		Source: entities.Source{Name: "kindle"},
+		Highlights: []entities.Highlight{
+			{Text: "A sentence worth highlighting twice.", LocationValue: 1},
+			{Text: "A sentence worth highlighting twice!", LocationValue: 2},
+		},
+	}))
+
+	duplicates, err := db.FindDuplicateHighlights(DefaultDuplicateSimilarity)
+	require.NoError(t, err)
+	assert.Empty(t, duplicates)
+}
+
+func TestMergeDuplicateHighlights(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	kindle, readwise := saveCrossSourceBook(t, db)
+
+	tag, err := db.GetOrCreateTag("stoicism", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToHighlight(kindle.ID, tag.ID))
+
+	merged, err := db.MergeDuplicateHighlights(kindle.ID, readwise.ID, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, readwise.ID, merged.ID)
+	assert.Equal(t, "Obstacle is the way", merged.Note)
+	assert.True(t, merged.IsFavorite)
+	assert.Equal(t, "Book V", merged.Chapter, "empty fields are filled from the duplicate")
+	assert.Equal(t, 120, merged.LocationValue)
+	require.Len(t, merged.Tags, 1)
+	assert.Equal(t, "stoicism", merged.Tags[0].Name)
+
+	_, err = db.GetHighlightByID(kindle.ID)
+	assert.Error(t, err)
+
+	// The Kindle copy must not come back with the next Kindle import
+	deleted, err := db.IsHighlightDeleted(&kindle, 0)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	_, err = db.MergeDuplicateHighlights(readwise.ID, readwise.ID, 0)
+	assert.ErrorIs(t, err, ErrNotDuplicates)
+}
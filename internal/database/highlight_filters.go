@@ -0,0 +1,253 @@
+package database
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// Highlight query scopes for use with gorm's Scopes. Each one narrows a query on
+// the highlights table and leaves it unchanged when its criterion is unset.
+
+func highlightsForUser(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if userID == 0 {
+			return db
+		}
+		return db.Where("highlights.user_id = ?", userID)
+	}
+}
+
+func highlightsOfBook(bookID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if bookID == 0 {
+			return db
+		}
+		return db.Where("highlights.book_id = ?", bookID)
+	}
+}
+
+func highlightsFromSource(name string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if name == "" {
+			return db
+		}
+		sources := db.Session(&gorm.Session{NewDB: true}).
+			Model(&entities.Source{}).Select("id").Where("name = ?", name)
+		return db.Where("highlights.source_id IN (?)", sources)
+	}
+}
+
+func highlightsColored(colors []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(colors) == 0 {
+			return db
+		}
+		return db.Where("highlights.color IN ?", colors)
+	}
+}
+
+func highlightsTagged(tagIDs []uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(tagIDs) == 0 {
+			return db
+		}
+		tagged := db.Session(&gorm.Session{NewDB: true}).
+			Table("highlight_tags").Select("highlight_id").Where("tag_id IN ?", tagIDs)
+		return db.Where("highlights.id IN (?)", tagged)
+	}
+}
+
+func highlightsMatching(query string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query == "" {
+			return db
+		}
+		pattern := "%" + query + "%"
+		return db.Where("(LOWER(highlights.text) LIKE LOWER(?) OR LOWER(highlights.note) LIKE LOWER(?))", pattern, pattern)
+	}
+}
+
+// highlightsTaggedNamed matches highlights with any of the tags, or whose book
+// has any of them, the way export filters do
+func highlightsTaggedNamed(names []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(names) == 0 {
+			return db
+		}
+		session := db.Session(&gorm.Session{NewDB: true})
+		tags := session.Model(&entities.Tag{}).Select("id").Where("name COLLATE NOCASE IN ?", names)
+		taggedHighlights := session.Table("highlight_tags").Select("highlight_id").Where("tag_id IN (?)", tags)
+		taggedBooks := session.Table("book_tags").Select("book_id").Where("tag_id IN (?)", tags)
+		return db.Where("(highlights.id IN (?) OR highlights.book_id IN (?))", taggedHighlights, taggedBooks)
+	}
+}
+
+func favouriteHighlights(favourite *bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if favourite == nil {
+			return db
+		}
+		return db.Where("highlights.is_favorite = ?", *favourite)
+	}
+}
+
+func highlightsWithNote(hasNote *bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch {
+		case hasNote == nil:
+			return db
+		case *hasNote:
+			return db.Where("highlights.note <> ''")
+		default:
+			return db.Where("(highlights.note IS NULL OR highlights.note = '')")
+		}
+	}
+}
+
+func highlightsInCategory(category entities.HighlightCategory) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if category == "" {
+			return db
+		}
+		return db.Where("highlights.id IN (SELECT highlight_id FROM highlight_categories WHERE category = ?)", category)
+	}
+}
+
+func highlightedBetween(from, to *time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if from != nil {
+			db = db.Where("highlights.highlighted_at >= ?", *from)
+		}
+		if to != nil {
+			db = db.Where("highlights.highlighted_at < ?", *to)
+		}
+		return db
+	}
+}
+
+// highlightsOfUnarchivedBooks leaves out the highlights of archived books
+// unless they are included.
+func highlightsOfUnarchivedBooks(include bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if include {
+			return db
+		}
+		return db.Where("highlights.book_id NOT IN (SELECT id FROM books WHERE is_archived = ?)", true)
+	}
+}
+
+// highlightFilterScopes returns the scopes applying every criterion of the filter.
+func highlightFilterScopes(filter entities.HighlightFilter) []func(*gorm.DB) *gorm.DB {
+	return []func(*gorm.DB) *gorm.DB{
+		highlightsForUser(filter.UserID),
+		highlightsOfBook(filter.BookID),
+		highlightsFromSource(filter.Source),
+		highlightsColored(filter.Colors),
+		highlightsMatching(filter.Query),
+		highlightsTagged(filter.TagIDs),
+		highlightsTaggedNamed(filter.Tags),
+		favouriteHighlights(filter.Favourite),
+		highlightsWithNote(filter.HasNote),
+		highlightsInCategory(filter.Category),
+		highlightedBetween(filter.From, filter.To),
+		// A book's own highlights are listed even when it is archived
+		highlightsOfUnarchivedBooks(filter.IncludeArchived || filter.BookID != 0),
+	}
+}
+
+// highlightOrder returns the ORDER BY clause of one of entities.HighlightSorts.
+// Highlight IDs break ties so pages do not overlap.
+func highlightOrder(sort string) string {
+	switch sort {
+	case entities.HighlightSortImported:
+		return "highlights.created_at DESC, highlights.id DESC"
+	case entities.HighlightSortTitle:
+		return "(SELECT books.title FROM books WHERE books.id = highlights.book_id) COLLATE NOCASE ASC, " +
+			"highlights.book_id ASC, highlights.location_value ASC, highlights.id ASC"
+	case entities.HighlightSortPublished:
+		return "(SELECT NULLIF(books.publication_year, 0) FROM books WHERE books.id = highlights.book_id) DESC NULLS LAST, " +
+			"highlights.book_id ASC, highlights.location_value ASC, highlights.id ASC"
+	default:
+		return "highlights.highlighted_at DESC, highlights.id DESC"
+	}
+}
+
+// ListHighlights returns the highlights matching the filter in the order of
+// filter.Sort, with their books, tags and sources preloaded.
+// Returns the page of highlights, the total number of matches, and any error.
+func (d *Database) ListHighlights(filter entities.HighlightFilter, limit, offset int) ([]entities.Highlight, int64, error) {
+	scopes := highlightFilterScopes(filter)
+
+	var total int64
+	if err := d.DB.Model(&entities.Highlight{}).Scopes(scopes...).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := d.DB.Preload("Book").Preload("Tags").Preload("Source").Preload("Categories").
+		Scopes(scopes...).
+		Order(highlightOrder(filter.Sort))
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var highlights []entities.Highlight
+	err := query.Find(&highlights).Error
+	return highlights, total, err
+}
+
+// readableHighlights skips highlights without text and discarded ones, which make
+// poor random picks.
+func readableHighlights(db *gorm.DB) *gorm.DB {
+	return db.Where("highlights.text <> '' AND highlights.is_discarded = ?", false)
+}
+
+// GetRandomHighlight returns a random highlight matching the filter, with its book
+// and tags preloaded. Returns gorm.ErrRecordNotFound if nothing matches.
+func (d *Database) GetRandomHighlight(filter entities.HighlightFilter) (*entities.Highlight, error) {
+	var highlight entities.Highlight
+	err := d.DB.Preload("Book").Preload("Tags").
+		Scopes(highlightFilterScopes(filter)...).Scopes(readableHighlights).
+		Order("RANDOM()").Limit(1).Take(&highlight).Error
+	if err != nil {
+		return nil, err
+	}
+	return &highlight, nil
+}
+
+// GetHighlightOfTheDay returns a highlight matching the filter that stays the same
+// all day. The pick is seeded by the date and the filter's user, so each user gets
+// their own highlight of the day. The date is taken in day's location, so pass
+// the time in the user's timezone. Returns gorm.ErrRecordNotFound if nothing matches.
+func (d *Database) GetHighlightOfTheDay(filter entities.HighlightFilter, day time.Time) (*entities.Highlight, error) {
+	scopes := append(highlightFilterScopes(filter), readableHighlights)
+
+	var total int64
+	if err := d.DB.Model(&entities.Highlight{}).Scopes(scopes...).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	seed := fnv.New64a()
+	fmt.Fprintf(seed, "%s|%d", day.Format("2006-01-02"), filter.UserID)
+
+	var highlight entities.Highlight
+	err := d.DB.Preload("Book").Preload("Tags").
+		Scopes(scopes...).
+		Order("highlights.id ASC").
+		Offset(int(seed.Sum64() % uint64(total))).Limit(1).
+		Take(&highlight).Error
+	if err != nil {
+		return nil, err
+	}
+	return &highlight, nil
+}
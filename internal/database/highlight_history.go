@@ -0,0 +1,59 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"gorm.io/gorm"
+)
+
+// recordHighlightVersion stores the current text and note of a highlight as a history entry.
+func recordHighlightVersion(tx *gorm.DB, highlight *entities.Highlight, reason entities.HighlightVersionReason) error {
+	version := entities.HighlightVersion{
+		HighlightID: highlight.ID,
+		Text:        highlight.Text,
+		Note:        highlight.Note,
+		Reason:      reason,
+	}
+	return tx.Create(&version).Error
+}
+
+// GetHighlightHistory returns previous versions of a highlight, newest first.
+func (d *Database) GetHighlightHistory(highlightID uint) ([]entities.HighlightVersion, error) {
+	var versions []entities.HighlightVersion
+	err := d.DB.Where("highlight_id = ?", highlightID).
+		Order("created_at DESC, id DESC").
+		Find(&versions).Error
+	return versions, err
+}
+
+// RevertHighlight restores a highlight's text and note from a history entry.
+// The current text and note are recorded as a new version first, so a revert can itself be undone.
+func (d *Database) RevertHighlight(highlightID, versionID uint) (*entities.Highlight, error) {
+	var highlight entities.Highlight
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&highlight, highlightID).Error; err != nil {
+			return err
+		}
+
+		var version entities.HighlightVersion
+		if err := tx.Where("id = ? AND highlight_id = ?", versionID, highlightID).First(&version).Error; err != nil {
+			return fmt.Errorf("version %d not found for highlight %d: %w", versionID, highlightID, err)
+		}
+
+		if err := recordHighlightVersion(tx, &highlight, entities.HighlightVersionReasonRevert); err != nil {
+			return err
+		}
+
+		highlight.Text = version.Text
+		highlight.Note = version.Note
+		return tx.Model(&highlight).Updates(map[string]any{
+			"text": version.Text,
+			"note": version.Note,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &highlight, nil
+}
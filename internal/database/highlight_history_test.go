@@ -0,0 +1,245 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveBook_ReimportRecordsPreviousNote(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title:      "History Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Highlight", LocationValue: 10, Note: "My careful note"}},
+	}
+	require.NoError(t, db.SaveBook(book))
+	highlightID := book.Highlights[0].ID
+
+	reimport := &entities.Book{
+		Title:      "History Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Highlight", LocationValue: 10}},
+	}
+	require.NoError(t, db.SaveBook(reimport))
+
+	highlight, err := db.GetHighlightByID(highlightID)
+	require.NoError(t, err)
+	assert.Empty(t, highlight.Note)
+
+	versions, err := db.GetHighlightHistory(highlightID)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, "My careful note", versions[0].Note)
+	assert.Equal(t, entities.HighlightVersionReasonReimport, versions[0].Reason)
+}
+
+func TestSaveBook_ReimportSameNoteRecordsNothing(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title:      "History Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Highlight", Note: "Same"}},
+	}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.SaveBook(&entities.Book{
+		Title:      "History Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Highlight", Note: "Same"}},
+	}))
+
+	versions, err := db.GetHighlightHistory(book.Highlights[0].ID)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
+
+func TestRevertHighlight(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title:      "History Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Original text", Note: "Original note"}},
+	}
+	require.NoError(t, db.SaveBook(book))
+	highlightID := book.Highlights[0].ID
+
+	highlight, err := db.GetHighlightByID(highlightID)
+	require.NoError(t, err)
+	highlight.Note = "Edited note"
+	require.NoError(t, db.UpdateHighlight(highlight))
+
+	versions, err := db.GetHighlightHistory(highlightID)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, entities.HighlightVersionReasonEdit, versions[0].Reason)
+
+	reverted, err := db.RevertHighlight(highlightID, versions[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Original note", reverted.Note)
+
+	stored, err := db.GetHighlightByID(highlightID)
+	require.NoError(t, err)
+	assert.Equal(t, "Original note", stored.Note)
+
+	// The edited note is kept so the revert can be undone
+	versions, err = db.GetHighlightHistory(highlightID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, entities.HighlightVersionReasonRevert, versions[0].Reason)
+	assert.Equal(t, "Edited note", versions[0].Note)
+}
+
+func TestRevertHighlight_RejectsOtherHighlightVersion(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title:  "History Book",
+		Author: "Author",
+		Highlights: []entities.Highlight{
+			{Text: "First", LocationValue: 1, Note: "a"},
+			{Text: "Second", LocationValue: 2, Note: "b"},
+		},
+	}
+	require.NoError(t, db.SaveBook(book))
+
+	first, err := db.GetHighlightByID(book.Highlights[0].ID)
+	require.NoError(t, err)
+	first.Note = "changed"
+	require.NoError(t, db.UpdateHighlight(first))
+
+	versions, err := db.GetHighlightHistory(first.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+
+	_, err = db.RevertHighlight(book.Highlights[1].ID, versions[0].ID)
+	assert.Error(t, err)
+}
+
+func TestSaveBook_ReimportKeepsLocalNote(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title:      "Conflict Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Highlight", LocationValue: 10}},
+	}
+	require.NoError(t, db.SaveBook(book))
+	highlightID := book.Highlights[0].ID
+
+	highlight, err := db.GetHighlightByID(highlightID)
+	require.NoError(t, err)
+	highlight.Note = "Added locally"
+	require.NoError(t, db.UpdateHighlight(highlight))
+
+	// Same source copy: local note survives and nothing conflicts
+	require.NoError(t, db.SaveBook(&entities.Book{
+		Title:      "Conflict Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Highlight", LocationValue: 10}},
+	}))
+
+	highlight, err = db.GetHighlightByID(highlightID)
+	require.NoError(t, err)
+	assert.Equal(t, "Added locally", highlight.Note)
+
+	conflicts, err := db.GetHighlightConflicts()
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
+func TestSaveBook_ReimportKeepsCorrectedText(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title:      "Conflict Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Teh typo", LocationValue: 42}},
+	}
+	require.NoError(t, db.SaveBook(book))
+	highlightID := book.Highlights[0].ID
+
+	highlight, err := db.GetHighlightByID(highlightID)
+	require.NoError(t, err)
+	highlight.Text = "The typo"
+	require.NoError(t, db.UpdateHighlight(highlight))
+
+	// Source still has the typo and now a note as well
+	require.NoError(t, db.SaveBook(&entities.Book{
+		Title:      "Conflict Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Teh typo", LocationValue: 42, Note: "Source note"}},
+	}))
+
+	saved, err := db.GetBookByTitleAndAuthor("Conflict Book", "Author")
+	require.NoError(t, err)
+	require.Len(t, saved.Highlights, 1, "corrected highlight must not be duplicated")
+	assert.Equal(t, "The typo", saved.Highlights[0].Text)
+	assert.Empty(t, saved.Highlights[0].Note)
+
+	conflicts, err := db.GetHighlightConflicts()
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, highlightID, conflicts[0].Highlight.ID)
+	assert.Equal(t, "Teh typo", conflicts[0].SourceVersion.Text)
+	assert.Equal(t, "Source note", conflicts[0].SourceVersion.Note)
+
+	// Accepting the source copy resolves the conflict
+	_, err = db.RevertHighlight(highlightID, conflicts[0].SourceVersion.ID)
+	require.NoError(t, err)
+	conflicts, err = db.GetHighlightConflicts()
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
+func TestSaveBook_ReimportRepeatedConflictRecordedOnce(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title:      "Conflict Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Highlight", Note: "Source"}},
+	}
+	require.NoError(t, db.SaveBook(book))
+	highlightID := book.Highlights[0].ID
+
+	highlight, err := db.GetHighlightByID(highlightID)
+	require.NoError(t, err)
+	highlight.Note = "Local"
+	require.NoError(t, db.UpdateHighlight(highlight))
+
+	reimport := func() {
+		require.NoError(t, db.SaveBook(&entities.Book{
+			Title:      "Conflict Book",
+			Author:     "Author",
+			Highlights: []entities.Highlight{{Text: "Highlight", Note: "Source changed"}},
+		}))
+	}
+	reimport()
+	reimport()
+
+	versions, err := db.GetHighlightHistory(highlightID)
+	require.NoError(t, err)
+	var sourceVersions int
+	for _, v := range versions {
+		if v.Reason == entities.HighlightVersionReasonSource {
+			sourceVersions++
+		}
+	}
+	assert.Equal(t, 1, sourceVersions)
+
+	highlight, err = db.GetHighlightByID(highlightID)
+	require.NoError(t, err)
+	assert.Equal(t, "Local", highlight.Note)
+}
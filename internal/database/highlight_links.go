@@ -0,0 +1,99 @@
+package database
+
+import (
+	"errors"
+	"slices"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+var (
+	// ErrInvalidLinkType is returned for a link type not in entities.HighlightLinkTypes.
+	ErrInvalidLinkType = errors.New("link type must be one of: supports, contradicts, see_also")
+	// ErrSelfLink is returned when linking a highlight to itself.
+	ErrSelfLink = errors.New("a highlight cannot be linked to itself")
+)
+
+// CreateHighlightLink links one highlight to another of the same user. Creating
+// a link that already exists returns the existing one.
+func (d *Database) CreateHighlightLink(fromID, toID uint, linkType entities.HighlightLinkType) (*entities.HighlightLink, error) {
+	if !slices.Contains(entities.HighlightLinkTypes, linkType) {
+		return nil, ErrInvalidLinkType
+	}
+	if fromID == toID {
+		return nil, ErrSelfLink
+	}
+
+	var from, to entities.Highlight
+	if err := d.DB.Select("id", "user_id").First(&from, fromID).Error; err != nil {
+		return nil, err
+	}
+	if err := d.DB.Select("id", "user_id").Where("user_id = ?", from.UserID).First(&to, toID).Error; err != nil {
+		return nil, err
+	}
+
+	link := entities.HighlightLink{FromHighlightID: fromID, ToHighlightID: toID, Type: linkType}
+	if err := d.DB.Where(&link).FirstOrCreate(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// DeleteHighlightLink removes a link.
+func (d *Database) DeleteHighlightLink(id uint) error {
+	result := d.DB.Delete(&entities.HighlightLink{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetHighlightLinks returns the links from a highlight and the links to it,
+// with the highlights on the other end and their books. Links to highlights
+// in the trash are left out.
+func (d *Database) GetHighlightLinks(highlightID uint) (outgoing, incoming []entities.HighlightLink, err error) {
+	err = d.DB.Preload("To.Book").
+		Joins("JOIN highlights ON highlights.id = highlight_links.to_highlight_id AND highlights.deleted_at IS NULL").
+		Where("highlight_links.from_highlight_id = ?", highlightID).
+		Order("highlight_links.type ASC, highlight_links.created_at ASC").
+		Find(&outgoing).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	err = d.DB.Preload("From.Book").
+		Joins("JOIN highlights ON highlights.id = highlight_links.from_highlight_id AND highlights.deleted_at IS NULL").
+		Where("highlight_links.to_highlight_id = ?", highlightID).
+		Order("highlight_links.type ASC, highlight_links.created_at ASC").
+		Find(&incoming).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return outgoing, incoming, nil
+}
+
+// moveHighlightLinks points the links from and to one highlight at another.
+// Links the other highlight already has, and links it would have to itself,
+// are dropped.
+func moveHighlightLinks(tx *gorm.DB, fromID, toID uint) error {
+	if err := tx.Exec("UPDATE OR IGNORE highlight_links SET from_highlight_id = ? WHERE from_highlight_id = ? AND to_highlight_id <> ?", toID, fromID, toID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("UPDATE OR IGNORE highlight_links SET to_highlight_id = ? WHERE to_highlight_id = ? AND from_highlight_id <> ?", toID, fromID, toID).Error; err != nil {
+		return err
+	}
+	return deleteHighlightLinks(tx, []uint{fromID})
+}
+
+// deleteHighlightLinks removes the links from and to the given highlights
+func deleteHighlightLinks(tx *gorm.DB, highlightIDs []uint) error {
+	if len(highlightIDs) == 0 {
+		return nil
+	}
+	return tx.Where("from_highlight_id IN ? OR to_highlight_id IN ?", highlightIDs, highlightIDs).
+		Delete(&entities.HighlightLink{}).Error
+}
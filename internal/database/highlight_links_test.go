@@ -0,0 +1,76 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestHighlightLinks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	letters := &entities.Book{Title: "Letters from a Stoic", Author: "Seneca", UserID: 1,
+		Highlights: []entities.Highlight{{Text: "Luck is what happens when preparation meets opportunity", UserID: 1}}}
+	meditations := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", UserID: 1,
+		Highlights: []entities.Highlight{{Text: "The obstacle is the way", UserID: 1}, {Text: "Waste no more time", UserID: 1}}}
+	other := &entities.Book{Title: "Ethics", Author: "Spinoza", UserID: 2,
+		Highlights: []entities.Highlight{{Text: "All things excellent are as difficult as they are rare", UserID: 2}}}
+	require.NoError(t, db.SaveBook(letters))
+	require.NoError(t, db.SaveBook(meditations))
+	require.NoError(t, db.SaveBook(other))
+	from, to := letters.Highlights[0].ID, meditations.Highlights[0].ID
+
+	link, err := db.CreateHighlightLink(from, to, entities.HighlightLinkSupports)
+	require.NoError(t, err)
+	again, err := db.CreateHighlightLink(from, to, entities.HighlightLinkSupports)
+	require.NoError(t, err)
+	assert.Equal(t, link.ID, again.ID, "an existing link is returned")
+
+	_, err = db.CreateHighlightLink(from, to, "refutes")
+	assert.ErrorIs(t, err, ErrInvalidLinkType)
+	_, err = db.CreateHighlightLink(from, from, entities.HighlightLinkSeeAlso)
+	assert.ErrorIs(t, err, ErrSelfLink)
+	_, err = db.CreateHighlightLink(from, other.Highlights[0].ID, entities.HighlightLinkSeeAlso)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "highlights of other users cannot be linked")
+
+	outgoing, incoming, err := db.GetHighlightLinks(from)
+	require.NoError(t, err)
+	require.Len(t, outgoing, 1)
+	assert.Empty(t, incoming)
+	assert.Equal(t, "Meditations", outgoing[0].To.Book.Title)
+
+	_, incoming, err = db.GetHighlightLinks(to)
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	assert.Equal(t, from, incoming[0].From.ID)
+
+	book, err := db.GetBookByID(letters.ID)
+	require.NoError(t, err)
+	require.Len(t, book.Highlights[0].Links, 1)
+	assert.Equal(t, "Meditations", book.Highlights[0].Links[0].To.Book.Title)
+
+	// Links survive a re-import of the book
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Letters from a Stoic", Author: "Seneca", UserID: 1,
+		Highlights: []entities.Highlight{{Text: "Luck is what happens when preparation meets opportunity", UserID: 1}}}))
+	outgoing, _, err = db.GetHighlightLinks(from)
+	require.NoError(t, err)
+	assert.Len(t, outgoing, 1)
+
+	require.NoError(t, db.DeleteHighlightLink(link.ID))
+	assert.ErrorIs(t, db.DeleteHighlightLink(link.ID), gorm.ErrRecordNotFound)
+
+	_, err = db.CreateHighlightLink(from, to, entities.HighlightLinkContradicts)
+	require.NoError(t, err)
+	require.NoError(t, db.DeleteHighlightPermanently(to, 1))
+	outgoing, _, err = db.GetHighlightLinks(from)
+	require.NoError(t, err)
+	assert.Empty(t, outgoing, "links go with a permanently deleted highlight")
+	var remaining int64
+	require.NoError(t, db.DB.Model(&entities.HighlightLink{}).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+}
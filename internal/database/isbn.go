@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+// setISBNForms stores a valid ISBN as its ISBN-13 along with its ISBN-10, so
+// books can be looked up and matched by either. ISBNs that fail validation are
+// kept as given, without an ISBN-10.
+func setISBNForms(book *entities.Book) {
+	isbn := metadata.NormalizeISBN(book.ISBN)
+	isbn13, isbn10 := metadata.ISBNForms(isbn)
+	if isbn13 != "" {
+		book.ISBN = isbn13
+	}
+	book.ISBN10 = isbn10
+}
+
+// isbnFormUpdates adds the ISBN-10 to metadata updates that change the ISBN,
+// storing the ISBN itself as its ISBN-13.
+func isbnFormUpdates(fields map[string]any) {
+	isbn, ok := fields["isbn"].(string)
+	if !ok {
+		return
+	}
+	book := entities.Book{ISBN: isbn}
+	setISBNForms(&book)
+	fields["isbn"] = book.ISBN
+	fields["isbn10"] = book.ISBN10
+}
+
+// isbnCandidates returns the values an ISBN may be stored as: as given, and
+// as its ISBN-13 and ISBN-10 when it is valid.
+func isbnCandidates(isbn string) []string {
+	candidates := []string{isbn}
+	isbn = metadata.NormalizeISBN(isbn)
+	isbn13, isbn10 := metadata.ISBNForms(isbn)
+	for _, candidate := range []string{isbn, isbn13, isbn10} {
+		if candidate != "" && candidate != candidates[0] {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// backfillISBNForms stores the ISBNs of existing books as ISBN-13 and adds
+// their ISBN-10.
+func backfillISBNForms(ctx context.Context, d *Database, report func(processed, total int)) error {
+	const batchSize = 500
+
+	pending := func() *gorm.DB {
+		return d.DB.Model(&entities.Book{}).Where("isbn <> '' AND isbn IS NOT NULL")
+	}
+
+	var total int64
+	if err := pending().Count(&total).Error; err != nil {
+		return err
+	}
+	report(0, int(total))
+
+	processed := 0
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var books []entities.Book
+		if err := pending().Where("id > ?", lastID).Order("id ASC").Limit(batchSize).
+			Select("id", "isbn", "isbn10").Find(&books).Error; err != nil {
+			return err
+		}
+		if len(books) == 0 {
+			return nil
+		}
+
+		for _, book := range books {
+			isbn, isbn10 := book.ISBN, book.ISBN10
+			setISBNForms(&book)
+			if book.ISBN == isbn && book.ISBN10 == isbn10 {
+				continue
+			}
+			if err := d.DB.Model(&entities.Book{}).Where("id = ?", book.ID).
+				UpdateColumns(map[string]any{"isbn": book.ISBN, "isbn10": book.ISBN10}).Error; err != nil {
+				return err
+			}
+		}
+
+		lastID = books[len(books)-1].ID
+		processed += len(books)
+		report(processed, int(total))
+	}
+}
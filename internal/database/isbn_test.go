@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestISBNForms(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Effective Java", Author: "Joshua Bloch", ISBN: "0-13-468599-7", UserID: 1}
+	require.NoError(t, db.SaveBook(book))
+
+	stored, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "9780134685991", stored.ISBN)
+	assert.Equal(t, "0134685997", stored.ISBN10)
+
+	for _, isbn := range []string{"9780134685991", "0134685997", "978-0-13-468599-1"} {
+		found, err := db.FindBookByISBN(isbn, 1)
+		require.NoError(t, err, isbn)
+		assert.Equal(t, book.ID, found.ID)
+	}
+
+	// 979 ISBNs have no ISBN-10
+	require.NoError(t, db.UpdateBookMetadata(book.ID, map[string]any{"isbn": "979-10-90636-07-1"}))
+	stored, err = db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "9791090636071", stored.ISBN)
+	assert.Empty(t, stored.ISBN10)
+}
+
+func TestBackfillISBNForms(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	valid := &entities.Book{Title: "Dune", Author: "Frank Herbert", UserID: 1}
+	invalid := &entities.Book{Title: "Typo", Author: "Some Author", UserID: 1}
+	require.NoError(t, db.SaveBook(valid))
+	require.NoError(t, db.SaveBook(invalid))
+
+	// Simulate ISBNs stored before validation
+	require.NoError(t, db.DB.Model(&entities.Book{}).Where("id = ?", valid.ID).UpdateColumn("isbn", "0441172717").Error)
+	require.NoError(t, db.DB.Model(&entities.Book{}).Where("id = ?", invalid.ID).UpdateColumn("isbn", "0441172718").Error)
+
+	require.NoError(t, backfillISBNForms(context.Background(), db, func(processed, total int) {}))
+
+	stored, err := db.GetBookByID(valid.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "9780441172719", stored.ISBN)
+	assert.Equal(t, "0441172717", stored.ISBN10)
+
+	stored, err = db.GetBookByID(invalid.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "0441172718", stored.ISBN)
+	assert.Empty(t, stored.ISBN10)
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// highlightEditReasons are the history reasons recorded when a highlight's text
+// or note actually changes, as opposed to source copies kept for conflicts.
+var highlightEditReasons = []entities.HighlightVersionReason{
+	entities.HighlightVersionReasonEdit,
+	entities.HighlightVersionReasonReimport,
+	entities.HighlightVersionReasonRevert,
+	entities.HighlightVersionReasonMerge,
+}
+
+// changedBetween narrows a query to rows whose column falls in [from, to).
+func changedBetween(column string, from, to time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(column+" >= ? AND "+column+" < ?", from, to)
+	}
+}
+
+// rowsForUser narrows a query on table to a user's rows, or leaves it
+// unchanged for userID 0 (single-user mode).
+func rowsForUser(table string, userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if userID == 0 {
+			return db
+		}
+		return db.Where(table+".user_id = ?", userID)
+	}
+}
+
+// LibraryDiff reports what changed in a user's library between from and to
+// (exclusive): books and highlights added, highlights and journal entries
+// edited, and books and highlights moved to the trash or permanently deleted.
+// Edits are taken from highlight history, so toggling a favourite or tagging
+// a highlight does not count as editing it.
+func (d *Database) LibraryDiff(userID uint, from, to time.Time) (*entities.LibraryDiff, error) {
+	diff := &entities.LibraryDiff{
+		From:              from,
+		To:                to,
+		DeletedBooks:      []entities.DeletedEntity{},
+		DeletedHighlights: []entities.DeletedEntity{},
+	}
+
+	if err := d.DB.Scopes(rowsForUser("books", userID), changedBetween("books.created_at", from, to)).
+		Order("books.created_at ASC, books.id ASC").
+		Find(&diff.NewBooks).Error; err != nil {
+		return nil, err
+	}
+
+	var highlights []entities.Highlight
+	if err := d.DB.Preload("Book").
+		Scopes(rowsForUser("highlights", userID), changedBetween("highlights.created_at", from, to)).
+		Order("highlights.created_at ASC, highlights.id ASC").
+		Find(&highlights).Error; err != nil {
+		return nil, err
+	}
+	diff.NewHighlights = libraryDiffHighlights(highlights)
+
+	edited := d.DB.Model(&entities.HighlightVersion{}).
+		Select("highlight_id").
+		Where("reason IN ?", highlightEditReasons).
+		Scopes(changedBetween("created_at", from, to))
+	highlights = nil
+	if err := d.DB.Preload("Book").
+		Scopes(rowsForUser("highlights", userID)).
+		Where("highlights.id IN (?) AND highlights.created_at < ?", edited, from).
+		Order("highlights.updated_at ASC, highlights.id ASC").
+		Find(&highlights).Error; err != nil {
+		return nil, err
+	}
+	diff.EditedHighlights = libraryDiffHighlights(highlights)
+
+	if err := d.DB.Scopes(rowsForUser("book_notes", userID), changedBetween("book_notes.created_at", from, to)).
+		Order("book_notes.created_at ASC, book_notes.id ASC").
+		Find(&diff.NewNotes).Error; err != nil {
+		return nil, err
+	}
+	if err := d.DB.Scopes(rowsForUser("book_notes", userID), changedBetween("book_notes.updated_at", from, to)).
+		Where("book_notes.created_at < ?", from).
+		Order("book_notes.updated_at ASC, book_notes.id ASC").
+		Find(&diff.EditedNotes).Error; err != nil {
+		return nil, err
+	}
+
+	if err := d.DB.Unscoped().
+		Scopes(rowsForUser("books", userID), changedBetween("books.deleted_at", from, to)).
+		Order("books.deleted_at ASC, books.id ASC").
+		Find(&diff.TrashedBooks).Error; err != nil {
+		return nil, err
+	}
+
+	// Highlights trashed along with their book are counted with the book
+	highlights = nil
+	if err := d.DB.Unscoped().
+		Preload("Book").
+		Joins("JOIN books ON books.id = highlights.book_id AND books.deleted_at IS NULL").
+		Scopes(rowsForUser("highlights", userID), changedBetween("highlights.deleted_at", from, to)).
+		Order("highlights.deleted_at ASC, highlights.id ASC").
+		Find(&highlights).Error; err != nil {
+		return nil, err
+	}
+	diff.TrashedHighlights = libraryDiffHighlights(highlights)
+
+	var tombstones []entities.DeletedEntity
+	if err := d.DB.Scopes(rowsForUser("deleted_entities", userID), changedBetween("deleted_entities.deleted_at", from, to)).
+		Order("deleted_entities.deleted_at ASC, deleted_entities.id ASC").
+		Find(&tombstones).Error; err != nil {
+		return nil, err
+	}
+	for _, tombstone := range tombstones {
+		switch tombstone.EntityType {
+		case entities.DeletedEntityBook:
+			diff.DeletedBooks = append(diff.DeletedBooks, tombstone)
+		case entities.DeletedEntityHighlight:
+			diff.DeletedHighlights = append(diff.DeletedHighlights, tombstone)
+		}
+	}
+
+	return diff, nil
+}
+
+func libraryDiffHighlights(highlights []entities.Highlight) []entities.LibraryDiffHighlight {
+	result := make([]entities.LibraryDiffHighlight, 0, len(highlights))
+	for _, h := range highlights {
+		result = append(result, entities.LibraryDiffHighlight{
+			Highlight:  h,
+			BookTitle:  h.Book.Title,
+			BookAuthor: h.Book.Author,
+		})
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backdate moves a book, its highlights and journal entries to before the diff period.
+func backdate(t *testing.T, db *Database, book *entities.Book, at time.Time) {
+	t.Helper()
+	require.NoError(t, db.DB.Model(&entities.Book{}).Where("id = ?", book.ID).
+		UpdateColumns(map[string]any{"created_at": at, "updated_at": at}).Error)
+	require.NoError(t, db.DB.Model(&entities.Highlight{}).Where("book_id = ?", book.ID).
+		UpdateColumns(map[string]any{"created_at": at, "updated_at": at}).Error)
+	require.NoError(t, db.DB.Model(&entities.BookNote{}).Where("book_id = ?", book.ID).
+		UpdateColumns(map[string]any{"created_at": at, "updated_at": at}).Error)
+}
+
+func TestLibraryDiff(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	from := time.Now().Add(-time.Hour)
+	to := time.Now().Add(time.Hour)
+	before := from.Add(-24 * time.Hour)
+
+	old := saveTrashTestBook(t, db, "Old Book", "Edit me", "Trash me", "Untouched")
+	require.NoError(t, db.CreateBookNote(&entities.BookNote{BookID: old.ID, Date: before, Text: "Old entry"}))
+	require.NoError(t, db.CreateBookNote(&entities.BookNote{BookID: old.ID, Date: before, Text: "Edit entry"}))
+	gone := saveTrashTestBook(t, db, "Gone Book", "Lost")
+	backdate(t, db, old, before)
+	backdate(t, db, gone, before)
+
+	fresh := saveTrashTestBook(t, db, "Fresh Book", "New one", "New two")
+
+	edited, err := db.GetHighlightByID(old.Highlights[0].ID)
+	require.NoError(t, err)
+	edited.Note = "A new thought"
+	require.NoError(t, db.UpdateHighlight(edited))
+
+	// Favouriting is not an edit
+	untouched, err := db.GetHighlightByID(old.Highlights[2].ID)
+	require.NoError(t, err)
+	untouched.IsFavorite = true
+	require.NoError(t, db.UpdateHighlight(untouched))
+
+	require.NoError(t, db.DeleteHighlight(old.Highlights[1].ID))
+	require.NoError(t, db.DeleteBookPermanently(gone.ID, 0))
+
+	notes, err := db.GetBookNotes(old.ID)
+	require.NoError(t, err)
+	for i := range notes {
+		if notes[i].Text == "Edit entry" {
+			notes[i].Text = "Edited entry"
+			require.NoError(t, db.UpdateBookNote(&notes[i]))
+		}
+	}
+	require.NoError(t, db.CreateBookNote(&entities.BookNote{BookID: fresh.ID, Date: from, Text: "First impressions"}))
+
+	diff, err := db.LibraryDiff(0, from, to)
+	require.NoError(t, err)
+
+	require.Len(t, diff.NewBooks, 1)
+	assert.Equal(t, "Fresh Book", diff.NewBooks[0].Title)
+
+	require.Len(t, diff.NewHighlights, 2)
+	assert.Equal(t, "New one", diff.NewHighlights[0].Highlight.Text)
+	assert.Equal(t, "Fresh Book", diff.NewHighlights[0].BookTitle)
+
+	require.Len(t, diff.EditedHighlights, 1)
+	assert.Equal(t, "A new thought", diff.EditedHighlights[0].Highlight.Note)
+	assert.Equal(t, "Old Book", diff.EditedHighlights[0].BookTitle)
+
+	require.Len(t, diff.NewNotes, 1)
+	assert.Equal(t, "First impressions", diff.NewNotes[0].Text)
+	require.Len(t, diff.EditedNotes, 1)
+	assert.Equal(t, "Edited entry", diff.EditedNotes[0].Text)
+
+	assert.Empty(t, diff.TrashedBooks)
+	require.Len(t, diff.TrashedHighlights, 1)
+	assert.Equal(t, "Trash me", diff.TrashedHighlights[0].Highlight.Text)
+
+	require.Len(t, diff.DeletedBooks, 1)
+	assert.Equal(t, "Gone Book|Author", diff.DeletedBooks[0].EntityKey)
+	assert.Empty(t, diff.DeletedHighlights)
+
+	// Nothing changed in an earlier period
+	diff, err = db.LibraryDiff(0, before.Add(-time.Hour), before.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, diff.NewBooks)
+	assert.Empty(t, diff.NewHighlights)
+	assert.Empty(t, diff.DeletedBooks)
+}
+
+func TestLibraryDiff_ScopedToUser(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Mine", Author: "Author", UserID: 1}))
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Theirs", Author: "Author", UserID: 2}))
+
+	diff, err := db.LibraryDiff(1, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, diff.NewBooks, 1)
+	assert.Equal(t, "Mine", diff.NewBooks[0].Title)
+}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+const (
+	// maintenanceRunsKept is how many runs of each maintenance job are kept.
+	maintenanceRunsKept = 30
+
+	// maxIntegrityProblems caps how many problems an integrity check reports.
+	maxIntegrityProblems = 100
+
+	// sqliteAutoVacuumIncremental is the auto_vacuum mode that lets
+	// PRAGMA incremental_vacuum return free pages to the filesystem.
+	sqliteAutoVacuumIncremental = 2
+)
+
+// RunMaintenance runs a database maintenance job and records its outcome.
+// A job that fails, or an integrity check that finds problems, is recorded
+// as failed; the error is only for unknown jobs and failures to record.
+func (d *Database) RunMaintenance(task entities.MaintenanceTask) (*entities.MaintenanceRun, error) {
+	if !slices.Contains(entities.MaintenanceTasks, task) {
+		return nil, fmt.Errorf("unknown maintenance task %q", task)
+	}
+
+	run := &entities.MaintenanceRun{Task: task, Status: entities.MaintenanceStatusOK, StartedAt: time.Now()}
+	result, err := d.runMaintenanceTask(task)
+	run.DurationMs = time.Since(run.StartedAt).Milliseconds()
+	run.Result = result
+	if err != nil {
+		run.Status = entities.MaintenanceStatusFailed
+		run.Result = err.Error()
+	}
+
+	if err := d.DB.Create(run).Error; err != nil {
+		return run, err
+	}
+	// Only the latest runs of each job are kept
+	err = d.DB.Where("task = ? AND id NOT IN (?)", task,
+		d.DB.Model(&entities.MaintenanceRun{}).Select("id").Where("task = ?", task).
+			Order("started_at DESC, id DESC").Limit(maintenanceRunsKept),
+	).Delete(&entities.MaintenanceRun{}).Error
+	return run, err
+}
+
+func (d *Database) runMaintenanceTask(task entities.MaintenanceTask) (string, error) {
+	switch task {
+	case entities.MaintenanceIntegrityCheck:
+		problems, err := d.CheckIntegrity()
+		if err != nil {
+			return "", err
+		}
+		if len(problems) > 0 {
+			return "", fmt.Errorf("integrity check found %d problem(s): %s", len(problems), strings.Join(problems, "; "))
+		}
+		return "No problems found", nil
+	case entities.MaintenanceVacuum:
+		freed, err := d.IncrementalVacuum()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Freed %s", formatBytes(freed)), nil
+	case entities.MaintenanceOrphanTags:
+		deleted, err := d.DeleteOrphanTags()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Deleted %d orphan tags", deleted), nil
+	case entities.MaintenanceSessions:
+		deleted, err := d.DeleteExpiredSessions()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Deleted %d expired sessions", deleted), nil
+	}
+	return "", fmt.Errorf("unknown maintenance task %q", task)
+}
+
+// CheckIntegrity runs SQLite's integrity check and returns the problems it
+// found, none for a healthy database.
+func (d *Database) CheckIntegrity() ([]string, error) {
+	var rows []string
+	if err := d.DB.Raw(fmt.Sprintf("PRAGMA integrity_check(%d)", maxIntegrityProblems)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 1 && rows[0] == "ok" {
+		return nil, nil
+	}
+	return rows, nil
+}
+
+// IncrementalVacuum returns the database's free pages to the filesystem and
+// reports how many bytes were freed. Databases created without incremental
+// auto-vacuum are switched to it with a full VACUUM on the first run.
+func (d *Database) IncrementalVacuum() (int64, error) {
+	var freed int64
+	// PRAGMAs apply to a connection, so everything runs on the same one
+	err := d.DB.Connection(func(conn *gorm.DB) error {
+		var pageSize, pagesBefore, pagesAfter, autoVacuum int64
+		if err := conn.Raw("PRAGMA page_size").Scan(&pageSize).Error; err != nil {
+			return err
+		}
+		if err := conn.Raw("PRAGMA page_count").Scan(&pagesBefore).Error; err != nil {
+			return err
+		}
+		if err := conn.Raw("PRAGMA auto_vacuum").Scan(&autoVacuum).Error; err != nil {
+			return err
+		}
+
+		if autoVacuum != sqliteAutoVacuumIncremental {
+			if err := conn.Exec(fmt.Sprintf("PRAGMA auto_vacuum = %d", sqliteAutoVacuumIncremental)).Error; err != nil {
+				return err
+			}
+			if err := conn.Exec("VACUUM").Error; err != nil {
+				return fmt.Errorf("vacuum: %w", err)
+			}
+		} else if err := conn.Exec("PRAGMA incremental_vacuum").Error; err != nil {
+			return fmt.Errorf("incremental vacuum: %w", err)
+		}
+
+		if err := conn.Raw("PRAGMA page_count").Scan(&pagesAfter).Error; err != nil {
+			return err
+		}
+		freed = max(pagesBefore-pagesAfter, 0) * pageSize
+		return nil
+	})
+	return freed, err
+}
+
+// DeleteExpiredSessions deletes login sessions that have expired. The
+// sessions table only exists once authentication has been enabled.
+func (d *Database) DeleteExpiredSessions() (int64, error) {
+	if !d.DB.Migrator().HasTable("sessions") {
+		return 0, nil
+	}
+	// Session expiry is stored as a Julian day number
+	result := d.DB.Exec("DELETE FROM sessions WHERE expiry < julianday('now')")
+	return result.RowsAffected, result.Error
+}
+
+// GetLatestMaintenanceRuns returns the latest run of each maintenance job
+// that has run, in entities.MaintenanceTasks order.
+func (d *Database) GetLatestMaintenanceRuns() ([]entities.MaintenanceRun, error) {
+	var runs []entities.MaintenanceRun
+	for _, task := range entities.MaintenanceTasks {
+		var run entities.MaintenanceRun
+		err := d.DB.Where("task = ?", task).Order("started_at DESC, id DESC").Limit(1).Find(&run).Error
+		if err != nil {
+			return nil, err
+		}
+		if run.ID != 0 {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// GetMaintenanceRuns returns the recorded maintenance runs, newest first.
+func (d *Database) GetMaintenanceRuns(limit int) ([]entities.MaintenanceRun, error) {
+	var runs []entities.MaintenanceRun
+	query := d.DB.Order("started_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&runs).Error
+	return runs, err
+}
+
+// formatBytes formats a size in bytes for maintenance results.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
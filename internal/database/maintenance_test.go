@@ -0,0 +1,85 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestRunMaintenance(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	run, err := db.RunMaintenance(entities.MaintenanceIntegrityCheck)
+	require.NoError(t, err)
+	assert.Equal(t, entities.MaintenanceStatusOK, run.Status)
+	assert.Equal(t, "No problems found", run.Result)
+
+	// Deleted rows leave free pages that the vacuum returns
+	for i := 0; i < 200; i++ {
+		require.NoError(t, db.SaveBook(&entities.Book{Title: fmt.Sprintf("Filler %d", i), Author: "Nobody", Highlights: []entities.Highlight{
+			{Text: strings.Repeat(fmt.Sprint(i), 1000)},
+		}}))
+	}
+	require.NoError(t, db.DB.Exec("DELETE FROM highlights").Error)
+	freed, err := db.IncrementalVacuum()
+	require.NoError(t, err)
+	assert.Positive(t, freed)
+	var autoVacuum int
+	require.NoError(t, db.DB.Raw("PRAGMA auto_vacuum").Scan(&autoVacuum).Error)
+	assert.Equal(t, sqliteAutoVacuumIncremental, autoVacuum)
+
+	// Later runs vacuum incrementally
+	run, err = db.RunMaintenance(entities.MaintenanceVacuum)
+	require.NoError(t, err)
+	assert.Equal(t, entities.MaintenanceStatusOK, run.Status)
+
+	_, err = db.CreateTag("unused", 0)
+	require.NoError(t, err)
+	run, err = db.RunMaintenance(entities.MaintenanceOrphanTags)
+	require.NoError(t, err)
+	assert.Equal(t, "Deleted 1 orphan tags", run.Result)
+
+	// Without authentication there is no sessions table to clean
+	deleted, err := db.DeleteExpiredSessions()
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	require.NoError(t, db.DB.Exec("CREATE TABLE sessions (token TEXT PRIMARY KEY, data BLOB NOT NULL, expiry REAL NOT NULL)").Error)
+	require.NoError(t, db.DB.Exec(`INSERT INTO sessions VALUES ('old', x'00', julianday('now') - 1), ('new', x'00', julianday('now') + 1)`).Error)
+	run, err = db.RunMaintenance(entities.MaintenanceSessions)
+	require.NoError(t, err)
+	assert.Equal(t, "Deleted 1 expired sessions", run.Result)
+
+	_, err = db.RunMaintenance("reindex")
+	assert.Error(t, err)
+
+	latest, err := db.GetLatestMaintenanceRuns()
+	require.NoError(t, err)
+	require.Len(t, latest, len(entities.MaintenanceTasks))
+	for i, task := range entities.MaintenanceTasks {
+		assert.Equal(t, task, latest[i].Task)
+	}
+}
+
+func TestRunMaintenance_KeepsLatestRuns(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i := 0; i < maintenanceRunsKept+5; i++ {
+		_, err := db.RunMaintenance(entities.MaintenanceSessions)
+		require.NoError(t, err)
+	}
+	_, err := db.RunMaintenance(entities.MaintenanceOrphanTags)
+	require.NoError(t, err)
+
+	runs, err := db.GetMaintenanceRuns(0)
+	require.NoError(t, err)
+	assert.Len(t, runs, maintenanceRunsKept+1)
+	assert.Equal(t, entities.MaintenanceOrphanTags, runs[0].Task)
+}
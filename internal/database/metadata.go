@@ -36,6 +36,12 @@ func (m *MetadataUpdater) UpdateBookMetadata(id uint, fields metadata.BookUpdate
 	if fields.PublicationYear != nil {
 		updates["publication_year"] = *fields.PublicationYear
 	}
+	if fields.Series != nil {
+		updates["series"] = *fields.Series
+	}
+	if fields.SeriesIndex != nil {
+		updates["series_index"] = *fields.SeriesIndex
+	}
 
 	if len(updates) == 0 {
 		return nil
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// QueueMetadataReview holds an uncertain metadata match for review, replacing
+// any earlier pending review of the book. A match already rejected for the
+// book, by its title and author, is not queued again.
+func (d *Database) QueueMetadataReview(review *entities.MetadataReview) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		var rejected int64
+		if err := tx.Model(&entities.MetadataReview{}).
+			Where("book_id = ? AND status = ? AND matched_title = ? AND matched_author = ?",
+				review.BookID, entities.MetadataReviewRejected, review.MatchedTitle, review.MatchedAuthor).
+			Count(&rejected).Error; err != nil {
+			return err
+		}
+		if rejected > 0 {
+			return nil
+		}
+
+		if err := tx.Where("book_id = ? AND status = ?", review.BookID, entities.MetadataReviewPending).
+			Delete(&entities.MetadataReview{}).Error; err != nil {
+			return err
+		}
+		review.Status = entities.MetadataReviewPending
+		return tx.Create(review).Error
+	})
+}
+
+// GetPendingMetadataReviews returns the matches waiting for review with their
+// books, least confident first.
+func (d *Database) GetPendingMetadataReviews() ([]entities.MetadataReview, error) {
+	var reviews []entities.MetadataReview
+	err := d.DB.Preload("Book").
+		Joins("JOIN books ON books.id = metadata_reviews.book_id AND books.deleted_at IS NULL").
+		Where("metadata_reviews.status = ?", entities.MetadataReviewPending).
+		Order("metadata_reviews.confidence ASC, metadata_reviews.id ASC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// AcceptMetadataReview applies the fields proposed by a pending review to its
+// book and returns the updated book with the names of the fields applied.
+// Returns gorm.ErrRecordNotFound unless the review is pending.
+func (d *Database) AcceptMetadataReview(id uint) (*entities.Book, []string, error) {
+	var fields []string
+	var bookID uint
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		var review entities.MetadataReview
+		if err := tx.Where("id = ? AND status = ?", id, entities.MetadataReviewPending).First(&review).Error; err != nil {
+			return err
+		}
+		bookID = review.BookID
+
+		updates := reviewUpdates(&review)
+		for _, field := range []string{"isbn", "cover_url", "publisher", "publication_year", "series", "series_index"} {
+			if _, ok := updates[field]; ok {
+				fields = append(fields, field)
+			}
+		}
+		if len(updates) > 0 {
+			txDB := &Database{DB: tx}
+			if err := txDB.UpdateBookMetadata(review.BookID, updates); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&review).Update("status", entities.MetadataReviewAccepted).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	book, err := d.GetBookByID(bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return book, fields, nil
+}
+
+// RejectMetadataReview discards a pending review, leaving its book unchanged.
+// Returns gorm.ErrRecordNotFound unless the review is pending.
+func (d *Database) RejectMetadataReview(id uint) error {
+	result := d.DB.Model(&entities.MetadataReview{}).
+		Where("id = ? AND status = ?", id, entities.MetadataReviewPending).
+		Update("status", entities.MetadataReviewRejected)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// reviewUpdates returns the book columns a review proposes values for.
+func reviewUpdates(review *entities.MetadataReview) map[string]any {
+	updates := make(map[string]any)
+	if review.ISBN != "" {
+		updates["isbn"] = review.ISBN
+	}
+	if review.CoverURL != "" {
+		updates["cover_url"] = review.CoverURL
+	}
+	if review.Publisher != "" {
+		updates["publisher"] = review.Publisher
+	}
+	if review.PublicationYear > 0 {
+		updates["publication_year"] = review.PublicationYear
+	}
+	if review.Series != "" {
+		updates["series"] = review.Series
+	}
+	if review.SeriesIndex > 0 {
+		updates["series_index"] = review.SeriesIndex
+	}
+	return updates
+}
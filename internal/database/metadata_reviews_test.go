@@ -0,0 +1,74 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestMetadataReviews(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "The Road", Author: "Cormac McCarthy", UserID: 1}
+	require.NoError(t, db.SaveBook(book))
+
+	match := func() *entities.MetadataReview {
+		return &entities.MetadataReview{
+			BookID:          book.ID,
+			UserID:          1,
+			Confidence:      0.4,
+			SearchMethod:    "title",
+			MatchedTitle:    "The Road to Serfdom",
+			MatchedAuthor:   "Friedrich Hayek",
+			ISBN:            "0226320553",
+			Publisher:       "University of Chicago Press",
+			PublicationYear: 1944,
+		}
+	}
+
+	// Queuing a match again replaces the pending one
+	require.NoError(t, db.QueueMetadataReview(match()))
+	require.NoError(t, db.QueueMetadataReview(match()))
+
+	reviews, err := db.GetPendingMetadataReviews()
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, "The Road", reviews[0].Book.Title)
+	assert.Equal(t, entities.MetadataReviewPending, reviews[0].Status)
+
+	updated, fields, err := db.AcceptMetadataReview(reviews[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"isbn", "publisher", "publication_year"}, fields)
+	assert.Equal(t, "University of Chicago Press", updated.Publisher)
+	assert.Equal(t, 1944, updated.PublicationYear)
+	assert.Equal(t, "9780226320557", updated.ISBN)
+
+	_, _, err = db.AcceptMetadataReview(reviews[0].ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	reviews, err = db.GetPendingMetadataReviews()
+	require.NoError(t, err)
+	assert.Empty(t, reviews)
+
+	// A rejected match is not proposed again
+	other := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", UserID: 1}
+	require.NoError(t, db.SaveBook(other))
+	review := &entities.MetadataReview{BookID: other.ID, UserID: 1, MatchedTitle: "Meditations", MatchedAuthor: "Hank Green", Publisher: "Dutton"}
+	require.NoError(t, db.QueueMetadataReview(review))
+	require.NoError(t, db.RejectMetadataReview(review.ID))
+	assert.ErrorIs(t, db.RejectMetadataReview(review.ID), gorm.ErrRecordNotFound)
+
+	require.NoError(t, db.QueueMetadataReview(&entities.MetadataReview{BookID: other.ID, UserID: 1, MatchedTitle: "Meditations", MatchedAuthor: "Hank Green", Publisher: "Dutton"}))
+	reviews, err = db.GetPendingMetadataReviews()
+	require.NoError(t, err)
+	assert.Empty(t, reviews)
+
+	stored, err := db.GetBookByID(other.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Publisher)
+}
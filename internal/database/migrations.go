@@ -0,0 +1,441 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"gorm.io/gorm"
+)
+
+// migratedModels lists every entity managed by AutoMigrate.
+var migratedModels = []any{
+	&entities.Source{},
+	&entities.User{},
+	&entities.Book{},
+	&entities.Highlight{},
+	&entities.Tag{},
+	&entities.ImportSession{},
+	&entities.Setting{},
+	&entities.SyncProgress{},
+	&entities.DeletedEntity{},
+	&entities.Word{},
+	&entities.WordDefinition{},
+	&entities.WordContext{},
+	&entities.AuditEvent{},
+	&entities.HighlightVersion{},
+	&entities.SchemaMigration{},
+	&entities.ExportTarget{},
+	&entities.Author{},
+	&entities.AuthorAlias{},
+	&entities.Collection{},
+	&entities.CollectionBook{},
+	&entities.HighlightLink{},
+	&entities.HighlightCategoryTag{},
+	&entities.SavedView{},
+	&entities.AdvisoryLock{},
+	&entities.UIPreferences{},
+	&entities.BookNote{},
+	&entities.MaintenanceRun{},
+	&entities.MetadataReview{},
+}
+
+// backfill is a data migration that runs in the background after startup.
+// Run reports progress through report and should stop early when ctx is cancelled.
+type backfill struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context, d *Database, report func(processed, total int)) error
+}
+
+// backfills are applied in order, each at most once per database.
+var backfills = []backfill{
+	{
+		Name:        "highlight_origin_hash",
+		Description: "Fingerprint existing highlights so re-imports keep local edits",
+		Run:         backfillHighlightOriginHash,
+	},
+	{
+		Name:        "highlight_content_hash",
+		Description: "Hash existing highlights' text and book for import deduplication",
+		Run:         backfillHighlightContentHash,
+	},
+	{
+		Name:        "book_authors",
+		Description: "Link existing books to author records",
+		Run:         backfillBookAuthors,
+	},
+	{
+		Name:        "author_names",
+		Description: "Normalize author names and merge authors listed as \"Last, First\"",
+		Run:         backfillAuthorNames,
+	},
+	{
+		Name:        "word_difficulty",
+		Description: "Score existing vocabulary words by word frequency",
+		Run:         backfillWordDifficulty,
+	},
+	{
+		Name:        "highlight_sources",
+		Description: "Attribute highlights imported without a source to the source of their book",
+		Run:         backfillHighlightSources,
+	},
+	{
+		Name:        "search_index",
+		Description: "Add existing books, highlights, tags and words to the quick search index",
+		Run:         backfillSearchIndex,
+	},
+	{
+		Name:        "word_lemmas",
+		Description: "Lemmatize existing vocabulary words so other forms of them are recognized",
+		Run:         backfillWordLemmas,
+	},
+	{
+		Name:        "definition_attribution",
+		Description: "Credit existing word definitions to their dictionary's source and license",
+		Run:         backfillDefinitionAttribution,
+	},
+	{
+		Name:        "book_languages",
+		Description: "Detect the language of existing books from their highlights",
+		Run:         backfillBookLanguages,
+	},
+	{
+		Name:        "isbn_forms",
+		Description: "Store existing books' ISBNs as ISBN-13 alongside their ISBN-10",
+		Run:         backfillISBNForms,
+	},
+	{
+		Name:        "book_counters",
+		Description: "Count the highlights and favourites of existing books and when they were last highlighted",
+		Run:         backfillBookCounters,
+	},
+}
+
+// tableColumns maps table names to their column names.
+type tableColumns map[string]map[string]bool
+
+// tableIndexes maps table names to the names of their indexes.
+type tableIndexes map[string]map[string]bool
+
+// snapshotSchema returns the columns and indexes of every migrated table that
+// currently exists.
+func snapshotSchema(db *gorm.DB) (tableColumns, tableIndexes, error) {
+	snapshot := make(tableColumns)
+	indexSnapshot := make(tableIndexes)
+	for _, model := range migratedModels {
+		table, err := tableName(db, model)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !db.Migrator().HasTable(model) {
+			continue
+		}
+		columnTypes, err := db.Migrator().ColumnTypes(model)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+		}
+		columns := make(map[string]bool, len(columnTypes))
+		for _, ct := range columnTypes {
+			columns[ct.Name()] = true
+		}
+		snapshot[table] = columns
+
+		var indexNames []string
+		if err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ?", table).
+			Scan(&indexNames).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to read indexes of %s: %w", table, err)
+		}
+		indexes := make(map[string]bool, len(indexNames))
+		for _, name := range indexNames {
+			indexes[name] = true
+		}
+		indexSnapshot[table] = indexes
+	}
+	return snapshot, indexSnapshot, nil
+}
+
+func tableName(db *gorm.DB, model any) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", fmt.Errorf("failed to parse model %T: %w", model, err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// migrate runs AutoMigrate and records the tables, columns and indexes it
+// added, then registers any backfills that have not been applied yet.
+func migrate(db *gorm.DB) error {
+	before, indexesBefore, err := snapshotSchema(db)
+	if err != nil {
+		return err
+	}
+
+	// Collection membership is a join table with the book's position
+	if err := db.SetupJoinTable(&entities.Book{}, "Collections", &entities.CollectionBook{}); err != nil {
+		return fmt.Errorf("failed to set up collection books: %w", err)
+	}
+	if err := db.AutoMigrate(migratedModels...); err != nil {
+		return err
+	}
+	// Join tables have no model to declare indexes on; tag filters look highlights up by tag
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_highlight_tags_tag_id ON highlight_tags(tag_id)").Error; err != nil {
+		return fmt.Errorf("failed to index highlight tags: %w", err)
+	}
+	if err := createSearchIndex(db); err != nil {
+		return err
+	}
+
+	after, indexesAfter, err := snapshotSchema(db)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var changes []entities.SchemaMigration
+	if len(before) == 0 {
+		changes = append(changes, entities.SchemaMigration{
+			Name:        "schema:initial",
+			Description: fmt.Sprintf("Created database with %d tables", len(after)),
+		})
+	} else {
+		tables := make([]string, 0, len(after))
+		for table := range after {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+
+		for _, table := range tables {
+			oldColumns, existed := before[table]
+			if !existed {
+				changes = append(changes, entities.SchemaMigration{
+					Name:        "schema:" + table,
+					Description: "Created table " + table,
+				})
+				continue
+			}
+			var added []string
+			for column := range after[table] {
+				if !oldColumns[column] {
+					added = append(added, column)
+				}
+			}
+			sort.Strings(added)
+			for _, column := range added {
+				changes = append(changes, entities.SchemaMigration{
+					Name:        "schema:" + table + "." + column,
+					Description: fmt.Sprintf("Added column %s to %s", column, table),
+				})
+			}
+
+			var addedIndexes []string
+			for index := range indexesAfter[table] {
+				if !indexesBefore[table][index] {
+					addedIndexes = append(addedIndexes, index)
+				}
+			}
+			sort.Strings(addedIndexes)
+			for _, index := range addedIndexes {
+				changes = append(changes, entities.SchemaMigration{
+					Name:        "schema:" + table + "." + index,
+					Description: fmt.Sprintf("Added index %s to %s", index, table),
+				})
+			}
+		}
+	}
+
+	for _, change := range changes {
+		change.Kind = entities.MigrationKindSchema
+		change.Status = entities.MigrationStatusCompleted
+		change.StartedAt = &now
+		change.CompletedAt = &now
+		if err := db.Where("name = ?", change.Name).FirstOrCreate(&change).Error; err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", change.Name, err)
+		}
+		log.Printf("Schema migration: %s", change.Description)
+	}
+
+	for _, b := range backfills {
+		record := entities.SchemaMigration{
+			Name:        "backfill:" + b.Name,
+			Kind:        entities.MigrationKindBackfill,
+			Description: b.Description,
+			Status:      entities.MigrationStatusPending,
+		}
+		if err := db.Where("name = ?", record.Name).FirstOrCreate(&record).Error; err != nil {
+			return fmt.Errorf("failed to register backfill %s: %w", b.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// GetMigrations returns recorded schema changes and backfills, newest first.
+func (d *Database) GetMigrations() ([]entities.SchemaMigration, error) {
+	var migrations []entities.SchemaMigration
+	err := d.DB.Order("created_at DESC, id DESC").Find(&migrations).Error
+	return migrations, err
+}
+
+// RunPendingBackfills applies backfills that have not completed yet, recording
+// their progress so a long-running upgrade is visible while the server is up.
+// Backfills interrupted by a shutdown are resumed on the next start.
+func (d *Database) RunPendingBackfills(ctx context.Context) error {
+	for _, b := range backfills {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var record entities.SchemaMigration
+		if err := d.DB.Where("name = ?", "backfill:"+b.Name).First(&record).Error; err != nil {
+			return fmt.Errorf("backfill %s not registered: %w", b.Name, err)
+		}
+		if record.Status == entities.MigrationStatusCompleted {
+			continue
+		}
+
+		started := time.Now()
+		d.DB.Model(&record).Updates(map[string]any{
+			"status":     entities.MigrationStatusRunning,
+			"started_at": started,
+			"error":      "",
+		})
+		log.Printf("Backfill %s: started", b.Name)
+
+		report := func(processed, total int) {
+			d.DB.Model(&record).Updates(map[string]any{
+				"processed":   processed,
+				"total_items": total,
+			})
+		}
+
+		if err := b.Run(ctx, d, report); err != nil {
+			d.DB.Model(&record).Updates(map[string]any{
+				"status": entities.MigrationStatusFailed,
+				"error":  err.Error(),
+			})
+			return fmt.Errorf("backfill %s: %w", b.Name, err)
+		}
+
+		completed := time.Now()
+		d.DB.Model(&record).Updates(map[string]any{
+			"status":       entities.MigrationStatusCompleted,
+			"completed_at": completed,
+		})
+		log.Printf("Backfill %s: completed in %v", b.Name, completed.Sub(started).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// backfillHighlightOriginHash fingerprints highlights imported before origin tracking.
+// Highlights with edit history are skipped since their source text is unknown.
+func backfillHighlightOriginHash(ctx context.Context, d *Database, report func(processed, total int)) error {
+	const batchSize = 500
+
+	pending := func() *gorm.DB {
+		return d.DB.Model(&entities.Highlight{}).Unscoped().
+			Where("(origin_hash IS NULL OR origin_hash = '') AND id NOT IN (?)",
+				d.DB.Model(&entities.HighlightVersion{}).Select("highlight_id").Where("reason = ?", entities.HighlightVersionReasonEdit))
+	}
+
+	var total int64
+	if err := pending().Count(&total).Error; err != nil {
+		return err
+	}
+	report(0, int(total))
+
+	processed := 0
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var highlights []entities.Highlight
+		if err := pending().Where("id > ?", lastID).Order("id ASC").Limit(batchSize).
+			Select("id", "text", "note").Find(&highlights).Error; err != nil {
+			return err
+		}
+		if len(highlights) == 0 {
+			return nil
+		}
+
+		err := d.DB.Transaction(func(tx *gorm.DB) error {
+			for _, h := range highlights {
+				if err := tx.Model(&entities.Highlight{}).Unscoped().Where("id = ?", h.ID).
+					UpdateColumn("origin_hash", entities.HighlightOriginHash(h.Text, h.Note)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		lastID = highlights[len(highlights)-1].ID
+		processed += len(highlights)
+		report(processed, int(total))
+	}
+}
+
+func backfillHighlightContentHash(ctx context.Context, d *Database, report func(processed, total int)) error {
+	const batchSize = 500
+
+	pending := func() *gorm.DB {
+		return d.DB.Model(&entities.Highlight{}).Unscoped().
+			Where("(content_hash IS NULL OR content_hash = '')")
+	}
+
+	var total int64
+	if err := pending().Count(&total).Error; err != nil {
+		return err
+	}
+	report(0, int(total))
+
+	type pendingHighlight struct {
+		ID     uint
+		Text   string
+		Title  string
+		Author string
+	}
+
+	processed := 0
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var highlights []pendingHighlight
+		if err := pending().Where("highlights.id > ?", lastID).Order("highlights.id ASC").Limit(batchSize).
+			Joins("LEFT JOIN books ON books.id = highlights.book_id").
+			Select("highlights.id", "highlights.text", "books.title", "books.author").
+			Scan(&highlights).Error; err != nil {
+			return err
+		}
+		if len(highlights) == 0 {
+			return nil
+		}
+
+		err := d.DB.Transaction(func(tx *gorm.DB) error {
+			for _, h := range highlights {
+				if err := tx.Model(&entities.Highlight{}).Unscoped().Where("id = ?", h.ID).
+					UpdateColumn("content_hash", entities.HighlightContentHash(h.Title, h.Author, h.Text)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		lastID = highlights[len(highlights)-1].ID
+		processed += len(highlights)
+		report(processed, int(total))
+	}
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations_FreshDatabaseRecordsInitialSchema(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	migrations, err := db.GetMigrations()
+	require.NoError(t, err)
+
+	byName := make(map[string]entities.SchemaMigration)
+	for _, m := range migrations {
+		byName[m.Name] = m
+	}
+
+	initial, ok := byName["schema:initial"]
+	require.True(t, ok)
+	assert.Equal(t, entities.MigrationKindSchema, initial.Kind)
+	assert.Equal(t, entities.MigrationStatusCompleted, initial.Status)
+
+	backfill, ok := byName["backfill:highlight_origin_hash"]
+	require.True(t, ok)
+	assert.Equal(t, entities.MigrationKindBackfill, backfill.Kind)
+	assert.Equal(t, entities.MigrationStatusPending, backfill.Status)
+}
+
+func TestMigrations_RecordsAddedColumns(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, db.DB.Migrator().DropColumn(&entities.Highlight{}, "origin_hash"))
+	require.NoError(t, migrate(db.DB))
+
+	var record entities.SchemaMigration
+	require.NoError(t, db.DB.Where("name = ?", "schema:highlights.origin_hash").First(&record).Error)
+	assert.Equal(t, entities.MigrationStatusCompleted, record.Status)
+	assert.True(t, db.DB.Migrator().HasColumn(&entities.Highlight{}, "origin_hash"))
+}
+
+func TestMigrations_RecordsAddedIndexes(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, db.DB.Migrator().DropIndex(&entities.Highlight{}, "idx_highlights_book_location"))
+	require.NoError(t, migrate(db.DB))
+
+	var record entities.SchemaMigration
+	require.NoError(t, db.DB.Where("name = ?", "schema:highlights.idx_highlights_book_location").First(&record).Error)
+	assert.Equal(t, "Added index idx_highlights_book_location to highlights", record.Description)
+	assert.True(t, db.DB.Migrator().HasIndex(&entities.Highlight{}, "idx_highlights_book_location"))
+}
+
+func TestMigrations_RunPendingBackfills(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title:  "Book",
+		Author: "Author",
+		Highlights: []entities.Highlight{
+			{Text: "Untouched", LocationValue: 1},
+			{Text: "Edited", LocationValue: 2},
+		},
+	}
+	require.NoError(t, db.SaveBook(book))
+	edited := book.Highlights[1]
+	edited.Note = "Local note"
+	require.NoError(t, db.UpdateHighlight(&edited))
+
+	// Simulate highlights imported before origin tracking existed
+	require.NoError(t, db.DB.Model(&entities.Highlight{}).Where("1 = 1").UpdateColumn("origin_hash", "").Error)
+
+	require.NoError(t, db.RunPendingBackfills(context.Background()))
+
+	untouched, err := db.GetHighlightByID(book.Highlights[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.HighlightOriginHash("Untouched", ""), untouched.OriginHash)
+
+	// Edited highlights keep no origin so they stay protected from re-imports
+	editedAfter, err := db.GetHighlightByID(edited.ID)
+	require.NoError(t, err)
+	assert.Empty(t, editedAfter.OriginHash)
+
+	var record entities.SchemaMigration
+	require.NoError(t, db.DB.Where("name = ?", "backfill:highlight_origin_hash").First(&record).Error)
+	assert.Equal(t, entities.MigrationStatusCompleted, record.Status)
+	assert.Equal(t, 1, record.TotalItems)
+	assert.Equal(t, 1, record.Processed)
+	assert.NotNil(t, record.CompletedAt)
+
+	// Completed backfills are not run again
+	require.NoError(t, db.DB.Model(&entities.Highlight{}).Where("id = ?", untouched.ID).UpdateColumn("origin_hash", "").Error)
+	require.NoError(t, db.RunPendingBackfills(context.Background()))
+	again, err := db.GetHighlightByID(untouched.ID)
+	require.NoError(t, err)
+	assert.Empty(t, again.OriginHash)
+}
+
+func TestMigrations_BackfillContentHash(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Book", Author: "Author", Highlights: []entities.Highlight{{Text: "Passage"}}}
+	require.NoError(t, db.SaveBook(book))
+
+	// Simulate highlights imported before content hashing existed
+	require.NoError(t, db.DB.Model(&entities.Highlight{}).Where("1 = 1").UpdateColumn("content_hash", "").Error)
+
+	require.NoError(t, db.RunPendingBackfills(context.Background()))
+
+	highlight, err := db.GetHighlightByID(book.Highlights[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.HighlightContentHash("Book", "Author", "Passage"), highlight.ContentHash)
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// SetBookPublic shares a book on the public library, or stops sharing it.
+func (d *Database) SetBookPublic(bookID uint, isPublic bool) error {
+	result := d.DB.Model(&entities.Book{}).
+		Where("id = ?", bookID).
+		Update("is_public", isPublic)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetPublicBooks returns the books on the public library by title: the ones
+// shared one by one and the ones selected by filter.
+func (d *Database) GetPublicBooks(filter entities.PublicLibraryFilter) ([]entities.Book, error) {
+	var books []entities.Book
+	err := d.DB.Scopes(publicBooks(filter)).Order("title COLLATE NOCASE ASC").Find(&books).Error
+	return books, err
+}
+
+// GetPublicBook returns a book on the public library with its highlights, or
+// gorm.ErrRecordNotFound when the book is not public.
+func (d *Database) GetPublicBook(id uint, filter entities.PublicLibraryFilter) (*entities.Book, error) {
+	var book entities.Book
+	err := d.DB.Preload("Highlights", func(db *gorm.DB) *gorm.DB {
+		return db.Order("location_value ASC, highlighted_at ASC")
+	}).Scopes(publicBooks(filter)).First(&book, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// publicBooks restricts a book query to the books on the public library.
+// Archived books stay off the library even when they are public.
+// Tags only select books when they belong to the book's owner or an admin, so
+// users cannot publish each other's books by tagging them.
+func publicBooks(filter entities.PublicLibraryFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		condition := db.Session(&gorm.Session{NewDB: true}).Where("books.is_public = ?", true)
+		if filter.Favourites {
+			condition = condition.Or("books.is_favorite = ?", true)
+		}
+		if len(filter.Tags) > 0 {
+			condition = condition.Or("books.id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+				Table("book_tags").
+				Select("book_tags.book_id").
+				Joins("JOIN tags ON tags.id = book_tags.tag_id").
+				Where("tags.name COLLATE NOCASE IN ?", filter.Tags).
+				Where("tags.user_id = books.user_id OR tags.user_id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+					Model(&entities.User{}).
+					Select("id").
+					Where("role = ?", entities.UserRoleAdmin)))
+		}
+		return db.Where(condition).Where("books.is_archived = ?", false)
+	}
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestPublicLibrary_GetPublicBooks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	shared := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Highlights: []entities.Highlight{{Text: "Waste no more time"}}}
+	favourite := &entities.Book{Title: "Dune", Author: "Frank Herbert", IsFavorite: true}
+	tagged := &entities.Book{Title: "Letters from a Stoic", Author: "Seneca"}
+	private := &entities.Book{Title: "Diary", Author: "Me"}
+	for _, book := range []*entities.Book{shared, favourite, tagged, private} {
+		require.NoError(t, db.SaveBook(book))
+	}
+	require.NoError(t, db.SetBookPublic(shared.ID, true))
+	tag, err := db.CreateTag("Stoicism", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToBook(tagged.ID, tag.ID))
+
+	titles := func(filter entities.PublicLibraryFilter) []string {
+		books, err := db.GetPublicBooks(filter)
+		require.NoError(t, err)
+		var titles []string
+		for _, book := range books {
+			titles = append(titles, book.Title)
+		}
+		return titles
+	}
+
+	assert.Equal(t, []string{"Meditations"}, titles(entities.PublicLibraryFilter{}))
+	assert.Equal(t, []string{"Dune", "Letters from a Stoic", "Meditations"},
+		titles(entities.PublicLibraryFilter{Favourites: true, Tags: []string{"stoicism"}}))
+
+	book, err := db.GetPublicBook(shared.ID, entities.PublicLibraryFilter{})
+	require.NoError(t, err)
+	assert.Len(t, book.Highlights, 1)
+
+	_, err = db.GetPublicBook(private.ID, entities.PublicLibraryFilter{Favourites: true, Tags: []string{"stoicism"}})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	assert.ErrorIs(t, db.SetBookPublic(9999, true), gorm.ErrRecordNotFound)
+}
+
+func TestPublicLibrary_HidesArchivedBooks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", IsFavorite: true}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.SetBookPublic(book.ID, true))
+	require.NoError(t, db.SetBookArchived(book.ID, true))
+
+	filter := entities.PublicLibraryFilter{Favourites: true}
+	books, err := db.GetPublicBooks(filter)
+	require.NoError(t, err)
+	assert.Empty(t, books)
+
+	_, err = db.GetPublicBook(book.ID, filter)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, db.SetBookArchived(book.ID, false))
+	books, err = db.GetPublicBooks(filter)
+	require.NoError(t, err)
+	assert.Len(t, books, 1)
+}
+
+func TestPublicLibrary_KeptOnReimport(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Dune", Author: "Frank Herbert", UserID: 1}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.SetBookPublic(book.ID, true))
+
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune", Author: "Frank Herbert", UserID: 1}))
+	require.NoError(t, db.SaveBooks([]entities.Book{{Title: "Dune", Author: "Frank Herbert", UserID: 1}}))
+
+	updated, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.True(t, updated.IsPublic)
+}
+
+func TestPublicLibrary_TagsFromOtherUsers(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	admin := &entities.User{Username: "admin", Email: "admin@example.com", Role: entities.UserRoleAdmin}
+	other := &entities.User{Username: "other", Email: "other@example.com", Role: entities.UserRoleEditor}
+	require.NoError(t, db.DB.Create(admin).Error)
+	require.NoError(t, db.DB.Create(other).Error)
+
+	owned := &entities.Book{Title: "Owned", Author: "A", UserID: 5}
+	byOther := &entities.Book{Title: "Tagged by other", Author: "B", UserID: 5}
+	byAdmin := &entities.Book{Title: "Tagged by admin", Author: "C", UserID: 5}
+	for _, book := range []*entities.Book{owned, byOther, byAdmin} {
+		require.NoError(t, db.SaveBook(book))
+	}
+	for book, userID := range map[*entities.Book]uint{owned: 5, byOther: other.ID, byAdmin: admin.ID} {
+		tag, err := db.GetOrCreateTag("shared", userID)
+		require.NoError(t, err)
+		require.NoError(t, db.AddTagToBook(book.ID, tag.ID))
+	}
+
+	books, err := db.GetPublicBooks(entities.PublicLibraryFilter{Tags: []string{"shared"}})
+	require.NoError(t, err)
+	var titles []string
+	for _, book := range books {
+		titles = append(titles, book.Title)
+	}
+	assert.Equal(t, []string{"Owned", "Tagged by admin"}, titles)
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// seedQueryPlanLibrary fills the database with two users' books, highlights and
+// tombstones and gathers statistics on them, so the query planner weighs
+// indexes as it would on a real library.
+func seedQueryPlanLibrary(t *testing.T, db *Database) {
+	t.Helper()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var books []entities.Book
+	for i := 0; i < 100; i++ {
+		book := entities.Book{Title: fmt.Sprintf("Book %d", i), Author: fmt.Sprintf("Author %d", i%10), UserID: uint(i%2 + 1)}
+		for j := 0; j < 30; j++ {
+			book.Highlights = append(book.Highlights, entities.Highlight{
+				Text:          fmt.Sprintf("Highlight %d of book %d", j, i),
+				UserID:        book.UserID,
+				LocationValue: j * 10,
+				HighlightedAt: start.Add(time.Duration(i*30+j) * time.Hour),
+			})
+		}
+		books = append(books, book)
+	}
+	require.NoError(t, db.SaveBooks(books))
+
+	var tombstones []entities.DeletedEntity
+	for i := 0; i < 500; i++ {
+		tombstones = append(tombstones, entities.DeletedEntity{
+			UserID:     uint(i%2 + 1),
+			EntityType: []string{"book", "highlight"}[i%2],
+			EntityKey:  fmt.Sprintf("Deleted %d|Author %d", i, i%10),
+			DeletedAt:  start,
+		})
+	}
+	require.NoError(t, db.DB.CreateInBatches(&tombstones, bulkQueryChunkSize).Error)
+	require.NoError(t, db.DB.Exec("ANALYZE").Error)
+}
+
+// queryPlan returns the steps of SQLite's plan for a query, one per line.
+func queryPlan(t *testing.T, db *Database, query func(tx *gorm.DB) *gorm.DB) string {
+	t.Helper()
+	var steps []struct{ Detail string }
+	require.NoError(t, db.DB.Raw("EXPLAIN QUERY PLAN "+db.DB.ToSQL(query)).Scan(&steps).Error)
+	details := make([]string, 0, len(steps))
+	for _, step := range steps {
+		details = append(details, step.Detail)
+	}
+	return strings.Join(details, "\n")
+}
+
+// The hot lookups of imports, book pages and the highlight timeline must stay
+// on their composite indexes; a full scan or sort here is slow on large libraries.
+func TestQueryPlans_UseCompositeIndexes(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	seedQueryPlanLibrary(t, db)
+
+	tests := []struct {
+		name  string
+		query func(tx *gorm.DB) *gorm.DB
+		index string
+		avoid string // Plan step that would show the index is not fully used
+	}{
+		{
+			name: "existing book on import (SaveBook)",
+			query: func(tx *gorm.DB) *gorm.DB {
+				return tx.Where("title = ? AND author = ? AND user_id = ?", "Book 7", "Author 7", 2).First(&entities.Book{})
+			},
+			index: "idx_books_user_title_author (user_id=? AND title=? AND author=?)",
+		},
+		{
+			name: "highlights of a book in reading order (GetBookByID)",
+			query: func(tx *gorm.DB) *gorm.DB {
+				return tx.Where("book_id IN ?", []uint{3}).Order("location_value ASC, highlighted_at ASC").Find(&[]entities.Highlight{})
+			},
+			index: "idx_highlights_book_location (book_id=?)",
+			avoid: "USE TEMP B-TREE FOR ORDER BY",
+		},
+		{
+			name: "latest highlights of a user (GetHighlightsForUser)",
+			query: func(tx *gorm.DB) *gorm.DB {
+				return tx.Where("user_id = ?", 1).Order("highlighted_at DESC").Limit(20).Find(&[]entities.Highlight{})
+			},
+			index: "idx_highlights_user_highlighted (user_id=?)",
+			avoid: "USE TEMP B-TREE",
+		},
+		{
+			name: "permanently deleted book (IsBookDeleted)",
+			query: func(tx *gorm.DB) *gorm.DB {
+				return tx.Model(&entities.DeletedEntity{}).
+					Where("entity_type = ? AND entity_key = ? AND (user_id = ? OR user_id = 0)", "book", "Book 7|Author 7", 1).
+					Count(new(int64))
+			},
+			index: "idx_deleted_entities_type_key (entity_type=? AND entity_key=?)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := queryPlan(t, db, tt.query)
+			assert.Contains(t, plan, "USING INDEX "+tt.index)
+			assert.NotRegexp(t, `(?m)^SCAN `, plan)
+			if tt.avoid != "" {
+				assert.NotContains(t, plan, tt.avoid)
+			}
+		})
+	}
+}
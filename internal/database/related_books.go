@@ -0,0 +1,39 @@
+package database
+
+import (
+	"gorm.io/gorm/clause"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// GetRelatedBooks returns up to limit books of the same user that share the book's
+// author or any of its tags. Books sharing more tags come first, and a shared
+// author counts as one more tag.
+func (d *Database) GetRelatedBooks(bookID uint, limit int) ([]entities.Book, error) {
+	var book entities.Book
+	if err := d.DB.Select("id", "user_id", "author").First(&book, bookID).Error; err != nil {
+		return nil, err
+	}
+
+	bookTags := d.DB.Table("book_tags").Select("tag_id").Where("book_id = ?", book.ID)
+	sharingTags := d.DB.Table("book_tags").Select("book_id").Where("tag_id IN (?)", bookTags)
+
+	related := d.DB.Where("id IN (?)", sharingTags)
+	if book.Author != "" {
+		related = related.Or("author = ?", book.Author)
+	}
+
+	var books []entities.Book
+	err := d.DB.Preload("Tags").
+		Where("user_id = ? AND id <> ?", book.UserID, book.ID).
+		Where(related).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL: "(SELECT COUNT(*) FROM book_tags bt WHERE bt.book_id = books.id AND bt.tag_id IN (SELECT tag_id FROM book_tags WHERE book_id = ?))" +
+				" + (CASE WHEN books.author = ? AND books.author <> '' THEN 1 ELSE 0 END) DESC, books.title ASC",
+			Vars:               []any{book.ID, book.Author},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&books).Error
+	return books, err
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ErrSavedViewNameTaken is returned when the user already has a saved view by that name.
+var ErrSavedViewNameTaken = errors.New("a saved view with this name already exists")
+
+// GetSavedViews returns the user's saved views by name.
+func (d *Database) GetSavedViews(userID uint) ([]entities.SavedView, error) {
+	var views []entities.SavedView
+	err := d.DB.Where("user_id = ?", userID).Order("name COLLATE NOCASE ASC").Find(&views).Error
+	return views, err
+}
+
+// GetSavedView returns a saved view by ID.
+func (d *Database) GetSavedView(id uint) (*entities.SavedView, error) {
+	var view entities.SavedView
+	if err := d.DB.First(&view, id).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// CreateSavedView saves a new view for view.UserID.
+func (d *Database) CreateSavedView(view *entities.SavedView) error {
+	view.ID = 0
+	view.Name = strings.TrimSpace(view.Name)
+	if err := d.checkSavedViewName(view.UserID, 0, view.Name); err != nil {
+		return err
+	}
+	return d.DB.Create(view).Error
+}
+
+// UpdateSavedView replaces the name and criteria of the saved view with view.ID.
+// The view keeps its owner.
+func (d *Database) UpdateSavedView(view *entities.SavedView) error {
+	existing, err := d.GetSavedView(view.ID)
+	if err != nil {
+		return err
+	}
+	view.UserID = existing.UserID
+	view.CreatedAt = existing.CreatedAt
+	view.Name = strings.TrimSpace(view.Name)
+	if err := d.checkSavedViewName(view.UserID, view.ID, view.Name); err != nil {
+		return err
+	}
+	return d.DB.Save(view).Error
+}
+
+// DeleteSavedView deletes a saved view. Its highlights are not affected.
+func (d *Database) DeleteSavedView(id uint) error {
+	result := d.DB.Delete(&entities.SavedView{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// checkSavedViewName returns ErrSavedViewNameTaken when another view of the
+// user has the name, ignoring case
+func (d *Database) checkSavedViewName(userID, id uint, name string) error {
+	var taken int64
+	err := d.DB.Model(&entities.SavedView{}).
+		Where("user_id = ? AND name = ? COLLATE NOCASE AND id <> ?", userID, name, id).
+		Count(&taken).Error
+	if err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrSavedViewNameTaken
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestSavedViews_CRUD(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	view := &entities.SavedView{UserID: 1, Name: " Stoic favourites ", Tags: []string{"stoicism"}, FavouritesOnly: true}
+	require.NoError(t, db.CreateSavedView(view))
+	assert.Equal(t, "Stoic favourites", view.Name)
+
+	assert.ErrorIs(t, db.CreateSavedView(&entities.SavedView{UserID: 1, Name: "stoic FAVOURITES"}), ErrSavedViewNameTaken)
+	require.NoError(t, db.CreateSavedView(&entities.SavedView{UserID: 2, Name: "Stoic favourites"}), "names are unique per user")
+
+	loaded, err := db.GetSavedView(view.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stoicism"}, loaded.Tags)
+
+	require.NoError(t, db.UpdateSavedView(&entities.SavedView{ID: view.ID, Name: "Stoics", Query: "virtue"}))
+	views, err := db.GetSavedViews(1)
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, "Stoics", views[0].Name)
+	assert.Equal(t, uint(1), views[0].UserID, "the view keeps its owner")
+	assert.Empty(t, views[0].Tags)
+
+	require.NoError(t, db.DeleteSavedView(view.ID))
+	assert.Error(t, db.DeleteSavedView(view.ID))
+}
+
+func TestListHighlights_QueryAndTagNames(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	meditations := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Highlights: []entities.Highlight{
+		{Text: "Waste no more time arguing what a good man should be", HighlightedAt: day},
+		{Text: "The soul becomes dyed with the colour of its thoughts", Note: "On VIRTUE", HighlightedAt: day},
+	}}
+	letters := &entities.Book{Title: "Letters", Author: "Seneca", Highlights: []entities.Highlight{
+		{Text: "Luck is what happens when preparation meets opportunity", HighlightedAt: day},
+	}}
+	require.NoError(t, db.SaveBook(meditations))
+	require.NoError(t, db.SaveBook(letters))
+
+	stoicism, err := db.GetOrCreateTag("Stoicism", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToBook(meditations.ID, stoicism.ID))
+	luck, err := db.GetOrCreateTag("luck", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToHighlight(letters.Highlights[0].ID, luck.ID))
+
+	_, total, err := db.ListHighlights(entities.HighlightFilter{Query: "virtue"}, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total, "notes are searched too")
+
+	_, total, err = db.ListHighlights(entities.HighlightFilter{Tags: []string{"stoicism"}}, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total, "book tags cover their highlights")
+
+	_, total, err = db.ListHighlights(entities.HighlightFilter{Tags: []string{"LUCK", "none"}}, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+}
+
+func TestListHighlights_Sort(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	imported := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	dune := &entities.Book{Title: "dune", PublicationYear: 1965, Highlights: []entities.Highlight{
+		{Text: "dune 2", LocationValue: 20, HighlightedAt: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), CreatedAt: imported},
+		{Text: "dune 1", LocationValue: 10, HighlightedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), CreatedAt: imported.AddDate(0, 0, 2)},
+	}}
+	babel := &entities.Book{Title: "Babel", Highlights: []entities.Highlight{
+		{Text: "babel", LocationValue: 5, HighlightedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), CreatedAt: imported.AddDate(0, 0, 1)},
+	}}
+	circe := &entities.Book{Title: "Circe", PublicationYear: 2018, Highlights: []entities.Highlight{
+		{Text: "circe", LocationValue: 1, HighlightedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), CreatedAt: imported.AddDate(0, 0, -1)},
+	}}
+	for _, book := range []*entities.Book{dune, babel, circe} {
+		require.NoError(t, db.SaveBook(book))
+	}
+
+	for sort, want := range map[string][]string{
+		"":                              {"dune 2", "babel", "dune 1", "circe"},
+		entities.HighlightSortRecent:    {"dune 2", "babel", "dune 1", "circe"},
+		entities.HighlightSortImported:  {"dune 1", "babel", "dune 2", "circe"},
+		entities.HighlightSortTitle:     {"babel", "circe", "dune 1", "dune 2"},
+		entities.HighlightSortPublished: {"circe", "dune 1", "dune 2", "babel"},
+	} {
+		highlights, _, err := db.ListHighlights(entities.HighlightFilter{Sort: sort}, 0, 0)
+		require.NoError(t, err)
+		var texts []string
+		for _, h := range highlights {
+			texts = append(texts, h.Text)
+		}
+		assert.Equal(t, want, texts, sort)
+	}
+}
@@ -0,0 +1,237 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// The quick search looks records up in FTS4 tables that triggers keep in
+// sync with books, highlights, tags and vocabulary words. Each kind has its
+// own table, so that a word common in highlights does not slow down finding
+// books, and a record's docid is its ID.
+
+// maxSearchTerms caps the words of a quick search query.
+const maxSearchTerms = 8
+
+// searchCandidates is how many matches of each kind are ranked per result.
+const searchCandidates = 5
+
+// searchSource describes how the rows of a table are indexed. Expressions
+// refer to the row as {row}.
+type searchSource struct {
+	kind   string
+	table  string
+	index  string
+	parent string   // The parent ID, 0 for none
+	title  string   // The text ranked highest
+	detail string   // Further searchable text
+	where  string   // Rows not meeting this are left out
+	watch  []string // Columns whose changes re-index a row
+	weight int      // Added to the score, so that e.g. books outrank highlights
+}
+
+var searchSources = []searchSource{
+	{
+		kind: entities.SearchKindBook, table: "books", index: "search_books",
+		parent: "0", title: "{row}.title", detail: "{row}.author",
+		where:  "{row}.deleted_at IS NULL",
+		watch:  []string{"title", "author", "user_id", "deleted_at"},
+		weight: 15,
+	},
+	{
+		kind: entities.SearchKindHighlight, table: "highlights", index: "search_highlights",
+		parent: "{row}.book_id", title: "{row}.text", detail: "{row}.note",
+		where: "{row}.deleted_at IS NULL",
+		watch: []string{"text", "note", "book_id", "user_id", "deleted_at"},
+	},
+	{
+		kind: entities.SearchKindTag, table: "tags", index: "search_tags",
+		parent: "0", title: "{row}.name", detail: "''",
+		where:  "1",
+		watch:  []string{"name", "user_id"},
+		weight: 10,
+	},
+	{
+		kind: entities.SearchKindWord, table: "words", index: "search_words",
+		parent: "COALESCE({row}.book_id, 0)", title: "{row}.word", detail: "{row}.context",
+		where:  fmt.Sprintf("{row}.status <> '%s'", entities.WordStatusCandidate),
+		watch:  []string{"word", "context", "book_id", "user_id", "status"},
+		weight: 5,
+	},
+}
+
+// insertRows returns an INSERT of the source's rows into its index, for row
+// being NEW in triggers or the table itself.
+func (s searchSource) insertRows(row string) string {
+	expand := func(expr string) string {
+		return strings.ReplaceAll(expr, "{row}", row)
+	}
+	sql := fmt.Sprintf("INSERT INTO %s(docid, user_id, parent_id, title, detail) SELECT %s.id, %s.user_id, %s, %s, %s",
+		s.index, row, row, expand(s.parent), expand(s.title), expand(s.detail))
+	if row != "NEW" {
+		sql += " FROM " + s.table
+	}
+	return sql + " WHERE " + expand(s.where)
+}
+
+// statements returns the statements creating the source's index and the
+// triggers that update it as rows change.
+func (s searchSource) statements() []string {
+	insert := s.insertRows("NEW")
+	remove := fmt.Sprintf("DELETE FROM %s WHERE docid = OLD.id", s.index)
+	return []string{
+		fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts4(
+			user_id, parent_id, title, detail, notindexed=user_id, notindexed=parent_id,
+			prefix="1,2,3", tokenize=unicode61 "remove_diacritics=2")`, s.index),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[1]s_insert AFTER INSERT ON %[2]s BEGIN %[3]s; END",
+			s.index, s.table, insert),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[1]s_update AFTER UPDATE OF %[2]s ON %[3]s BEGIN %[4]s; %[5]s; END",
+			s.index, strings.Join(s.watch, ", "), s.table, remove, insert),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[1]s_delete AFTER DELETE ON %[2]s BEGIN %[3]s; END",
+			s.index, s.table, remove),
+	}
+}
+
+// createSearchIndex creates the quick search indexes and their triggers.
+// Rows that existed before are added by the search_index backfill.
+func createSearchIndex(db *gorm.DB) error {
+	var statements []string
+	for _, source := range searchSources {
+		statements = append(statements, source.statements()...)
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to create search index: %w", err)
+		}
+	}
+	return nil
+}
+
+// RebuildSearchIndex indexes every book, highlight, tag and word again.
+func (d *Database) RebuildSearchIndex() error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		for _, source := range searchSources {
+			if err := tx.Exec("DELETE FROM " + source.index).Error; err != nil {
+				return fmt.Errorf("failed to clear %s: %w", source.index, err)
+			}
+			if err := tx.Exec(source.insertRows(source.table)).Error; err != nil {
+				return fmt.Errorf("failed to index %s: %w", source.table, err)
+			}
+		}
+		return nil
+	})
+}
+
+func backfillSearchIndex(_ context.Context, d *Database, report func(processed, total int)) error {
+	report(0, 1)
+	if err := d.RebuildSearchIndex(); err != nil {
+		return err
+	}
+	report(1, 1)
+	return nil
+}
+
+// QuickSearch returns up to limit books, highlights, tags and words whose
+// words start with those of the query, best matches first. Titles matching
+// the query outrank matches in the detail text, and books outrank tags,
+// words and highlights. A userID of 0 searches every user's records.
+func (d *Database) QuickSearch(query string, userID uint, limit int) ([]entities.SearchHit, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	match := strings.Join(terms, "* ") + "*"
+	var hits []entities.SearchHit
+	for _, source := range searchSources {
+		q := d.DB.Table(source.index).
+			Select("docid", "parent_id", "title", "detail").
+			Where(source.index+" MATCH ?", match)
+		if userID > 0 {
+			q = q.Where("user_id = ?", userID)
+		}
+
+		var rows []struct {
+			Docid    uint
+			ParentID uint
+			Title    string
+			Detail   string
+		}
+		// Newest first, so that common words find recent highlights
+		if err := q.Order("docid DESC").Limit(limit * searchCandidates).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", source.table, err)
+		}
+
+		for _, row := range rows {
+			hits = append(hits, entities.SearchHit{
+				Kind:     source.kind,
+				ID:       row.Docid,
+				ParentID: row.ParentID,
+				Title:    row.Title,
+				Detail:   row.Detail,
+				Score:    searchScore(row.Title, terms) + source.weight,
+			})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return len(hits[i].Title) < len(hits[j].Title)
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// searchTerms splits a query into lowercase words, dropping punctuation and
+// FTS operators.
+func searchTerms(query string) []string {
+	terms := splitWords(query)
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+	return terms
+}
+
+// searchScore rates how well a title matches the query terms: all of it,
+// its start, the start of its words, or not at all when the match was in
+// the detail text.
+func searchScore(title string, terms []string) int {
+	title = strings.ToLower(title)
+	query := strings.Join(terms, " ")
+	switch {
+	case title == query:
+		return 100
+	case strings.HasPrefix(title, query):
+		return 75
+	}
+
+	words := splitWords(title)
+	for _, term := range terms {
+		startsWord := slices.ContainsFunc(words, func(word string) bool {
+			return strings.HasPrefix(word, term)
+		})
+		if !startsWord {
+			return 25
+		}
+	}
+	return 50
+}
+
+// splitWords returns the lowercase words of s
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestQuickSearch(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	dune := &entities.Book{Title: "Dune", Author: "Frank Herbert", Highlights: []entities.Highlight{
+		{Text: "Fear is the mind-killer.", Note: "Litany against fear"},
+	}}
+	require.NoError(t, db.SaveBook(dune))
+	messiah := &entities.Book{Title: "Dune Messiah", Author: "Frank Herbert"}
+	require.NoError(t, db.SaveBook(messiah))
+	tolstoy := &entities.Book{Title: "Война и мир", Author: "Лев Толстой"}
+	require.NoError(t, db.SaveBook(tolstoy))
+	_, err := db.CreateTag("dunes", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.AddWord(&entities.Word{Word: "dunnage", Status: entities.WordStatusPending}))
+	require.NoError(t, db.AddWord(&entities.Word{Word: "dunlin", Status: entities.WordStatusCandidate}))
+
+	kinds := func(hits []entities.SearchHit) []string {
+		var kinds []string
+		for _, hit := range hits {
+			kinds = append(kinds, hit.Kind+":"+hit.Title)
+		}
+		return kinds
+	}
+
+	hits, err := db.QuickSearch("dune", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"book:Dune", "book:Dune Messiah", "tag:dunes"}, kinds(hits))
+
+	hits, err = db.QuickSearch("dun", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"book:Dune", "book:Dune Messiah", "tag:dunes", "word:dunnage"}, kinds(hits),
+		"candidate words are left out")
+
+	hits, err = db.QuickSearch("fear", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, entities.SearchKindHighlight, hits[0].Kind)
+	assert.Equal(t, dune.Highlights[0].ID, hits[0].ID)
+	assert.Equal(t, dune.ID, hits[0].ParentID)
+
+	hits, err = db.QuickSearch("ТОЛСТ", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"book:Война и мир"}, kinds(hits), "authors are searched, ignoring case")
+
+	hits, err = db.QuickSearch(`"Dune"* -(`, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"book:Dune"}, kinds(hits), "FTS syntax is ignored")
+
+	// Changes are indexed as they happen
+	require.NoError(t, db.UpdateBookMetadata(messiah.ID, map[string]any{"title": "Children of Dune"}))
+	require.NoError(t, db.DeleteBook(dune.ID))
+	hits, err = db.QuickSearch("dune", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tag:dunes", "book:Children of Dune"}, kinds(hits))
+
+	_, err = db.RestoreBook(dune.ID)
+	require.NoError(t, err)
+	require.NoError(t, db.RebuildSearchIndex())
+	hits, err = db.QuickSearch("dune", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"book:Dune", "tag:dunes", "book:Children of Dune"}, kinds(hits))
+
+	hits, err = db.QuickSearch("dune", 5, 10)
+	require.NoError(t, err)
+	assert.Empty(t, hits, "other users' records are not found")
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// keepLocalBookFields carries fields of a stored book that sources don't
+// export over to its re-import: the series, set by enrichment or by hand,
+// the detected language, whether the book is a favourite, shared on the
+// public library or archived, and the ASIN and the article address and date,
+// which only some sources know.
+func keepLocalBookFields(book, existing *entities.Book) {
+	if book.URL == "" {
+		book.URL = existing.URL
+	}
+	if book.PublishedAt == nil {
+		book.PublishedAt = existing.PublishedAt
+	}
+	if book.Series == "" {
+		book.Series = existing.Series
+		book.SeriesIndex = existing.SeriesIndex
+	}
+	if book.Language == "" {
+		book.Language = existing.Language
+	}
+	if book.ASIN == "" {
+		book.ASIN = existing.ASIN
+	}
+	book.IsFavorite = book.IsFavorite || existing.IsFavorite
+	book.IsPublic = book.IsPublic || existing.IsPublic
+	book.IsArchived = book.IsArchived || existing.IsArchived
+}
+
+// GetSeries returns the user's series by name, each with its books in reading
+// order. Books without a position come last, by title.
+func (d *Database) GetSeries(userID uint) ([]entities.Series, error) {
+	var books []entities.Book
+	err := d.DB.Where("user_id = ? AND series <> ''", userID).
+		Order("series COLLATE NOCASE ASC, series_index = 0 ASC, series_index ASC, title ASC").
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	series := []entities.Series{}
+	for _, book := range books {
+		if n := len(series); n > 0 && strings.EqualFold(series[n-1].Name, book.Series) {
+			series[n-1].Books = append(series[n-1].Books, book)
+			continue
+		}
+		series = append(series, entities.Series{Name: book.Series, Books: []entities.Book{book}})
+	}
+	return series, nil
+}
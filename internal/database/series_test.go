@@ -0,0 +1,75 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestSeries_GetSeries(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, db.SaveBooks([]entities.Book{
+		{Title: "Dune Messiah", Author: "Frank Herbert", Series: "Dune", SeriesIndex: 2, UserID: 1},
+		{Title: "Dune", Author: "Frank Herbert", Series: "Dune", SeriesIndex: 1, UserID: 1},
+		{Title: "Tales of Dune", Author: "Brian Herbert", Series: "dune", UserID: 1},
+		{Title: "The Last Wish", Author: "Andrzej Sapkowski", Series: "The Witcher", SeriesIndex: 0.5, UserID: 1},
+		{Title: "Ulysses", Author: "James Joyce", UserID: 1},
+		{Title: "Foundation", Author: "Isaac Asimov", Series: "Foundation", SeriesIndex: 1, UserID: 2},
+	}))
+
+	series, err := db.GetSeries(1)
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+	assert.Equal(t, "Dune", series[0].Name)
+	require.Len(t, series[0].Books, 3)
+	assert.Equal(t, "Dune", series[0].Books[0].Title)
+	assert.Equal(t, "Dune Messiah", series[0].Books[1].Title)
+	assert.Equal(t, "Tales of Dune", series[0].Books[2].Title, "books without a position come last")
+	assert.Equal(t, "The Witcher", series[1].Name)
+}
+
+func TestSeries_KeptOnReimport(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Dune", Author: "Frank Herbert", UserID: 1}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.UpdateBookMetadata(book.ID, map[string]any{"series": "Dune", "series_index": 1.0}))
+
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune", Author: "Frank Herbert", UserID: 1}))
+	require.NoError(t, db.SaveBooks([]entities.Book{{Title: "Dune", Author: "Frank Herbert", UserID: 1}}))
+
+	updated, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Dune", updated.Series)
+	assert.Equal(t, 1.0, updated.SeriesIndex)
+}
+
+func TestArticleFields_KeptOnReimport(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	published := time.Date(2019, 3, 13, 0, 0, 0, 0, time.UTC)
+	book := &entities.Book{Title: "The Bitter Lesson", Author: "Rich Sutton", UserID: 1,
+		URL: "http://www.incompleteideas.net/IncIdeas/BitterLesson.html", PublishedAt: &published,
+		Highlights: []entities.Highlight{{Text: "Search and learning", URL: "http://www.incompleteideas.net/IncIdeas/BitterLesson.html#:~:text=Search"}}}
+	require.NoError(t, db.SaveBook(book))
+
+	// A source without article addresses, such as a CSV export
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "The Bitter Lesson", Author: "Rich Sutton", UserID: 1,
+		Highlights: []entities.Highlight{{Text: "Search and learning"}}}))
+
+	updated, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, book.URL, updated.URL)
+	require.NotNil(t, updated.PublishedAt)
+	assert.True(t, published.Equal(*updated.PublishedAt))
+	require.Len(t, updated.Highlights, 1)
+	assert.Equal(t, book.Highlights[0].URL, updated.Highlights[0].URL)
+}
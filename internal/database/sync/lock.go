@@ -0,0 +1,63 @@
+package sync
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	stdsync "sync"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+const (
+	// HeartbeatInterval is how often a worker refreshes the syncs it owns.
+	HeartbeatInterval = 30 * time.Second
+
+	// HeartbeatTimeout is how long a running sync may go without a heartbeat
+	// before it counts as abandoned, e.g. because its worker was killed.
+	HeartbeatTimeout = 2 * time.Minute
+)
+
+var (
+	workerOnce stdsync.Once
+	workerID   string
+)
+
+// WorkerID identifies this process as the owner of the syncs it runs:
+// the host name, process ID and a random suffix, as PID numbers are reused.
+func WorkerID() string {
+	workerOnce.Do(func() {
+		host, err := os.Hostname()
+		if err != nil || host == "" {
+			host = "unknown"
+		}
+		suffix := make([]byte, 4)
+		_, _ = rand.Read(suffix)
+		workerID = fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(suffix))
+	})
+	return workerID
+}
+
+// Abandoned reports whether a running sync has gone without a heartbeat for
+// longer than HeartbeatTimeout. Records from before heartbeats were kept fall
+// back to their last update.
+func Abandoned(progress *entities.SyncProgress, now time.Time) bool {
+	if progress.Status != entities.SyncStatusRunning {
+		return false
+	}
+	heartbeat := progress.HeartbeatAt
+	if heartbeat.IsZero() {
+		heartbeat = progress.UpdatedAt
+	}
+	return now.Sub(heartbeat) > HeartbeatTimeout
+}
+
+// AbandonedError is the error recorded on a sync found abandoned.
+func AbandonedError(progress *entities.SyncProgress) string {
+	if progress.Owner == "" {
+		return "sync was interrupted"
+	}
+	return fmt.Sprintf("sync was abandoned by worker %s", progress.Owner)
+}
@@ -55,11 +55,13 @@ func (r *Repository) StartSync(totalItems int) error {
 	now := time.Now()
 	if result.Error == gorm.ErrRecordNotFound {
 		progress = entities.SyncProgress{
-			SyncType:   r.syncType,
-			Status:     entities.SyncStatusRunning,
-			TotalItems: totalItems,
-			StartedAt:  now,
-			UpdatedAt:  now,
+			SyncType:    r.syncType,
+			Status:      entities.SyncStatusRunning,
+			TotalItems:  totalItems,
+			StartedAt:   now,
+			UpdatedAt:   now,
+			Owner:       WorkerID(),
+			HeartbeatAt: now,
 		}
 		return r.db.Create(&progress).Error
 	} else if result.Error != nil {
@@ -78,6 +80,8 @@ func (r *Repository) StartSync(totalItems int) error {
 	progress.StartedAt = now
 	progress.UpdatedAt = now
 	progress.CompletedAt = nil
+	progress.Owner = WorkerID()
+	progress.HeartbeatAt = now
 
 	return r.db.Save(&progress).Error
 }
@@ -85,6 +89,7 @@ func (r *Repository) StartSync(totalItems int) error {
 // UpdateProgress updates the progress of an ongoing sync.
 // Implements ProgressReporter.UpdateProgress.
 func (r *Repository) UpdateProgress(processed, succeeded, failed, skipped int, currentItem string) error {
+	now := time.Now()
 	return r.db.Model(&entities.SyncProgress{}).
 		Where("sync_type = ?", r.syncType).
 		Updates(map[string]any{
@@ -93,7 +98,9 @@ func (r *Repository) UpdateProgress(processed, succeeded, failed, skipped int, c
 			"failed":       failed,
 			"skipped":      skipped,
 			"current_item": currentItem,
-			"updated_at":   time.Now(),
+			"updated_at":   now,
+			"owner":        WorkerID(),
+			"heartbeat_at": now,
 		}).Error
 }
 
@@ -120,8 +127,8 @@ func (r *Repository) CompleteSync(succeeded bool, errorMsg string) error {
 		Updates(updates).Error
 }
 
-// IsSyncRunning checks if a sync is currently in progress.
-// A sync is considered stale if not updated in 10 minutes.
+// IsSyncRunning checks if a sync is currently in progress. A sync whose
+// owner has not sent a heartbeat within HeartbeatTimeout is failed instead.
 // Implements ProgressReporter.IsSyncRunning.
 func (r *Repository) IsSyncRunning() (bool, error) {
 	var progress entities.SyncProgress
@@ -133,10 +140,8 @@ func (r *Repository) IsSyncRunning() (bool, error) {
 		return false, err
 	}
 
-	// Consider sync stale if not updated in 10 minutes
-	staleThreshold := time.Now().Add(-10 * time.Minute)
-	if progress.UpdatedAt.Before(staleThreshold) {
-		_ = r.CompleteSync(false, "sync was interrupted")
+	if Abandoned(&progress, time.Now()) {
+		_ = r.CompleteSync(false, AbandonedError(&progress))
 		return false, nil
 	}
 
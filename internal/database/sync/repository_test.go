@@ -168,18 +168,39 @@ func TestRepository_IsSyncRunning_StaleSync(t *testing.T) {
 	err := repo.StartSync(10)
 	require.NoError(t, err)
 
-	// Manually set updated_at to 15 minutes ago to simulate stale sync
+	// Manually set the heartbeat to 15 minutes ago to simulate a killed worker
 	repo.db.Model(&entities.SyncProgress{}).
 		Where("sync_type = ?", entities.SyncTypeMetadata).
-		Update("updated_at", time.Now().Add(-15*time.Minute))
+		Update("heartbeat_at", time.Now().Add(-15*time.Minute))
 
-	// Should detect as not running (stale) and mark as failed
+	// Should detect as not running (abandoned) and mark as failed
 	running, err := repo.IsSyncRunning()
 	require.NoError(t, err)
 	assert.False(t, running)
 
-	// Verify it was marked as failed
+	// Verify it was marked as failed, naming the worker
 	progress, err := repo.GetSyncProgress()
 	require.NoError(t, err)
 	assert.Equal(t, entities.SyncStatusFailed, progress.Status)
+	assert.Equal(t, "sync was abandoned by worker "+WorkerID(), progress.Error)
+}
+
+func TestRepository_IsSyncRunning_Heartbeat(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, repo.StartSync(10))
+	progress, err := repo.GetSyncProgress()
+	require.NoError(t, err)
+	assert.Equal(t, WorkerID(), progress.Owner)
+	assert.False(t, progress.HeartbeatAt.IsZero())
+
+	// A slow item without progress updates is fine while the heartbeat is recent
+	repo.db.Model(&entities.SyncProgress{}).
+		Where("sync_type = ?", entities.SyncTypeMetadata).
+		Update("updated_at", time.Now().Add(-15*time.Minute))
+
+	running, err := repo.IsSyncRunning()
+	require.NoError(t, err)
+	assert.True(t, running)
 }
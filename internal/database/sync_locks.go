@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	syncrepo "github.com/mrlokans/assistant/internal/database/sync"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// IsMetadataSyncRunning checks if a metadata sync is currently in progress.
+func (d *Database) IsMetadataSyncRunning() (bool, error) {
+	return d.IsSyncRunning(entities.SyncTypeMetadata)
+}
+
+// IsSyncRunning checks if a sync of the given type is in progress. A sync
+// whose owner has stopped sending heartbeats, e.g. because the process was
+// killed, is failed instead, which releases it for the next run.
+func (d *Database) IsSyncRunning(syncType entities.SyncType) (bool, error) {
+	var progress entities.SyncProgress
+	err := d.DB.Where("sync_type = ? AND status = ?", syncType, entities.SyncStatusRunning).First(&progress).Error
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if syncrepo.Abandoned(&progress, time.Now()) {
+		if _, err := d.InterruptSyncProgress(syncType, syncrepo.AbandonedError(&progress)); err != nil {
+			return false, err
+		}
+		log.Printf("Released abandoned %s sync of worker %s", syncType, progress.Owner)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// GetAllSyncProgress returns the progress records of every sync type that
+// has run, running ones first.
+func (d *Database) GetAllSyncProgress() ([]entities.SyncProgress, error) {
+	var records []entities.SyncProgress
+	err := d.DB.Order("status = 'running' DESC, sync_type ASC").Find(&records).Error
+	return records, err
+}
+
+// HeartbeatSyncs refreshes the heartbeat of the running syncs owned by this
+// process, returning how many it refreshed.
+func (d *Database) HeartbeatSyncs() (int64, error) {
+	result := d.DB.Model(&entities.SyncProgress{}).
+		Where("status = ? AND owner = ?", entities.SyncStatusRunning, syncrepo.WorkerID()).
+		Update("heartbeat_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// KeepSyncsAlive sends heartbeats for the syncs owned by this process until
+// ctx is done, so that a long item does not make its sync look abandoned.
+func (d *Database) KeepSyncsAlive(ctx context.Context) {
+	ticker := time.NewTicker(syncrepo.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.HeartbeatSyncs(); err != nil {
+				log.Printf("WARNING: Failed to send sync heartbeat: %v", err)
+			}
+		}
+	}
+}
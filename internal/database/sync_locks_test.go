@@ -0,0 +1,79 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	syncrepo "github.com/mrlokans/assistant/internal/database/sync"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestIsSyncRunning_Heartbeat(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	progress, err := db.StartSyncProgress(entities.SyncTypeMetadata, 5)
+	require.NoError(t, err)
+	assert.Equal(t, syncrepo.WorkerID(), progress.Owner)
+
+	running, err := db.IsSyncRunning(entities.SyncTypeMetadata)
+	require.NoError(t, err)
+	assert.True(t, running)
+
+	// Another worker's sync that stopped sending heartbeats is released
+	require.NoError(t, db.DB.Model(&entities.SyncProgress{}).
+		Where("sync_type = ?", entities.SyncTypeMetadata).
+		Updates(map[string]any{"owner": "other-host:42:beef", "heartbeat_at": time.Now().Add(-time.Hour)}).Error)
+
+	running, err = db.IsSyncRunning(entities.SyncTypeMetadata)
+	require.NoError(t, err)
+	assert.False(t, running)
+
+	progress, err = db.GetSyncProgress(entities.SyncTypeMetadata)
+	require.NoError(t, err)
+	assert.Equal(t, entities.SyncStatusFailed, progress.Status)
+	assert.Equal(t, "sync was abandoned by worker other-host:42:beef", progress.Error)
+}
+
+func TestHeartbeatSyncs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := db.StartSyncProgress(entities.SyncTypeMetadata, 5)
+	require.NoError(t, err)
+	_, err = db.StartSyncProgress(entities.SyncTypeReenrich, 5)
+	require.NoError(t, err)
+	stale := time.Now().Add(-time.Hour)
+	require.NoError(t, db.DB.Model(&entities.SyncProgress{}).Where("1 = 1").Update("heartbeat_at", stale).Error)
+	require.NoError(t, db.DB.Model(&entities.SyncProgress{}).
+		Where("sync_type = ?", entities.SyncTypeReenrich).
+		Update("owner", "other-host:42:beef").Error)
+
+	// Only the syncs owned by this process are kept alive
+	refreshed, err := db.HeartbeatSyncs()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), refreshed)
+
+	running, err := db.IsSyncRunning(entities.SyncTypeMetadata)
+	require.NoError(t, err)
+	assert.True(t, running)
+	running, err = db.IsSyncRunning(entities.SyncTypeReenrich)
+	require.NoError(t, err)
+	assert.False(t, running)
+
+	// Progress from any worker takes the sync over
+	_, err = db.StartSyncProgress(entities.SyncTypeReenrich, 2)
+	require.NoError(t, err)
+	require.NoError(t, db.RecordSyncItem(entities.SyncTypeReenrich, entities.SyncOutcomeSucceeded, "Dune"))
+	progress, err := db.GetSyncProgress(entities.SyncTypeReenrich)
+	require.NoError(t, err)
+	assert.Equal(t, syncrepo.WorkerID(), progress.Owner)
+
+	syncs, err := db.GetAllSyncProgress()
+	require.NoError(t, err)
+	require.Len(t, syncs, 2)
+	assert.Equal(t, entities.SyncStatusRunning, syncs[0].Status, "running syncs first")
+}
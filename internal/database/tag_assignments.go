@@ -0,0 +1,259 @@
+package database
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// GetTagAssignments lists every tag on the user's books and highlights, book
+// tags first, ordered by book. Items in the trash are left out.
+func (d *Database) GetTagAssignments(userID uint) ([]entities.TagAssignment, error) {
+	type assignmentRow struct {
+		HighlightID   uint
+		HighlightText string
+		Title         string
+		Author        string
+		Tag           string
+	}
+
+	var bookRows []assignmentRow
+	err := d.DB.Table("book_tags").
+		Select("books.title, books.author, tags.name AS tag").
+		Joins("JOIN books ON books.id = book_tags.book_id AND books.deleted_at IS NULL").
+		Joins("JOIN tags ON tags.id = book_tags.tag_id").
+		Where("books.user_id = ?", userID).
+		Order("books.title COLLATE NOCASE ASC, books.author COLLATE NOCASE ASC, tags.name COLLATE NOCASE ASC").
+		Scan(&bookRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var highlightRows []assignmentRow
+	err = d.DB.Table("highlight_tags").
+		Select("highlights.id AS highlight_id, highlights.text AS highlight_text, books.title, books.author, tags.name AS tag").
+		Joins("JOIN highlights ON highlights.id = highlight_tags.highlight_id AND highlights.deleted_at IS NULL").
+		Joins("JOIN books ON books.id = highlights.book_id AND books.deleted_at IS NULL").
+		Joins("JOIN tags ON tags.id = highlight_tags.tag_id").
+		Where("books.user_id = ?", userID).
+		Order("books.title COLLATE NOCASE ASC, books.author COLLATE NOCASE ASC, highlights.location_value ASC, highlights.id ASC, tags.name COLLATE NOCASE ASC").
+		Scan(&highlightRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := make([]entities.TagAssignment, 0, len(bookRows)+len(highlightRows))
+	for _, row := range bookRows {
+		assignments = append(assignments, entities.TagAssignment{
+			Target:     entities.TagTargetBook,
+			BookTitle:  row.Title,
+			BookAuthor: row.Author,
+			Tag:        row.Tag,
+		})
+	}
+	for _, row := range highlightRows {
+		assignments = append(assignments, entities.TagAssignment{
+			Target:        entities.TagTargetHighlight,
+			BookTitle:     row.Title,
+			BookAuthor:    row.Author,
+			HighlightID:   row.HighlightID,
+			HighlightText: row.HighlightText,
+			Tag:           row.Tag,
+		})
+	}
+	return assignments, nil
+}
+
+// tagPair is a tag on a book or highlight
+type tagPair struct {
+	target entities.TagTarget
+	itemID uint
+	tagID  uint
+}
+
+// ApplyTagAssignments tags the user's books and highlights as listed, creating
+// missing tags and matching tag names without regard to case. With replace,
+// tags that are not listed are taken off and tags left unused are deleted, so
+// the list becomes the library's complete set of tags. Assignments whose book
+// or highlight is not found are reported and skipped.
+func (d *Database) ApplyTagAssignments(userID uint, assignments []entities.TagAssignment, replace bool) (*entities.TagAssignmentResult, error) {
+	result := &entities.TagAssignmentResult{}
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		books, highlights, err := userTaggableItems(tx, userID)
+		if err != nil {
+			return err
+		}
+		existing, err := userTagPairs(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		tags := make(map[string]uint)
+		var userTags []entities.Tag
+		if err := tx.Where("user_id = ?", userID).Find(&userTags).Error; err != nil {
+			return err
+		}
+		for _, tag := range userTags {
+			tags[strings.ToLower(tag.Name)] = tag.ID
+		}
+
+		wanted := make(map[tagPair]bool, len(assignments))
+		for i, assignment := range assignments {
+			var itemID uint
+			switch assignment.Target {
+			case entities.TagTargetBook:
+				itemID = books[bookKey(assignment.BookTitle, assignment.BookAuthor)]
+			case entities.TagTargetHighlight:
+				if highlights[assignment.HighlightID] {
+					itemID = assignment.HighlightID
+				}
+			}
+			name := strings.TrimSpace(assignment.Tag)
+			if itemID == 0 || name == "" {
+				result.Unmatched = append(result.Unmatched, i)
+				continue
+			}
+
+			tagID, ok := tags[strings.ToLower(name)]
+			if !ok {
+				tag := &entities.Tag{UserID: userID, Name: name}
+				if err := tx.Create(tag).Error; err != nil {
+					return err
+				}
+				tagID = tag.ID
+				tags[strings.ToLower(name)] = tagID
+			}
+
+			pair := tagPair{target: assignment.Target, itemID: itemID, tagID: tagID}
+			if wanted[pair] {
+				continue
+			}
+			wanted[pair] = true
+			if existing[pair] {
+				continue
+			}
+			if err := insertTagPair(tx, pair); err != nil {
+				return err
+			}
+			result.Added++
+		}
+
+		if !replace {
+			return nil
+		}
+		removedTags := make(map[uint]bool)
+		for pair := range existing {
+			if wanted[pair] {
+				continue
+			}
+			if err := deleteTagPair(tx, pair); err != nil {
+				return err
+			}
+			removedTags[pair.tagID] = true
+			result.Removed++
+		}
+		for tagID := range removedTags {
+			err := tx.Exec(`
+				DELETE FROM tags WHERE id = ?
+				AND id NOT IN (SELECT tag_id FROM book_tags)
+				AND id NOT IN (SELECT tag_id FROM highlight_tags)
+			`, tagID).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// userTaggableItems returns the user's books by title and author and the IDs of
+// their highlights, leaving out items in the trash
+func userTaggableItems(tx *gorm.DB, userID uint) (map[string]uint, map[uint]bool, error) {
+	var books []entities.Book
+	if err := tx.Select("id", "title", "author").Where("user_id = ?", userID).Order("id ASC").Find(&books).Error; err != nil {
+		return nil, nil, err
+	}
+	bookIDs := make(map[string]uint, len(books))
+	for _, book := range books {
+		key := bookKey(book.Title, book.Author)
+		if _, taken := bookIDs[key]; !taken {
+			bookIDs[key] = book.ID
+		}
+	}
+
+	var ids []uint
+	err := tx.Model(&entities.Highlight{}).
+		Joins("JOIN books ON books.id = highlights.book_id AND books.deleted_at IS NULL").
+		Where("books.user_id = ?", userID).
+		Pluck("highlights.id", &ids).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	highlightIDs := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		highlightIDs[id] = true
+	}
+	return bookIDs, highlightIDs, nil
+}
+
+// userTagPairs returns the tags currently on the user's books and highlights
+func userTagPairs(tx *gorm.DB, userID uint) (map[tagPair]bool, error) {
+	type pairRow struct {
+		ItemID uint
+		TagID  uint
+	}
+	pairs := make(map[tagPair]bool)
+
+	var bookRows []pairRow
+	err := tx.Table("book_tags").
+		Select("book_tags.book_id AS item_id, book_tags.tag_id").
+		Joins("JOIN books ON books.id = book_tags.book_id AND books.deleted_at IS NULL").
+		Where("books.user_id = ?", userID).
+		Scan(&bookRows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range bookRows {
+		pairs[tagPair{target: entities.TagTargetBook, itemID: row.ItemID, tagID: row.TagID}] = true
+	}
+
+	var highlightRows []pairRow
+	err = tx.Table("highlight_tags").
+		Select("highlight_tags.highlight_id AS item_id, highlight_tags.tag_id").
+		Joins("JOIN highlights ON highlights.id = highlight_tags.highlight_id AND highlights.deleted_at IS NULL").
+		Joins("JOIN books ON books.id = highlights.book_id AND books.deleted_at IS NULL").
+		Where("books.user_id = ?", userID).
+		Scan(&highlightRows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range highlightRows {
+		pairs[tagPair{target: entities.TagTargetHighlight, itemID: row.ItemID, tagID: row.TagID}] = true
+	}
+	return pairs, nil
+}
+
+func insertTagPair(tx *gorm.DB, pair tagPair) error {
+	if pair.target == entities.TagTargetBook {
+		return tx.Exec("INSERT OR IGNORE INTO book_tags (book_id, tag_id) VALUES (?, ?)", pair.itemID, pair.tagID).Error
+	}
+	return tx.Exec("INSERT OR IGNORE INTO highlight_tags (highlight_id, tag_id) VALUES (?, ?)", pair.itemID, pair.tagID).Error
+}
+
+func deleteTagPair(tx *gorm.DB, pair tagPair) error {
+	if pair.target == entities.TagTargetBook {
+		return tx.Exec("DELETE FROM book_tags WHERE book_id = ? AND tag_id = ?", pair.itemID, pair.tagID).Error
+	}
+	return tx.Exec("DELETE FROM highlight_tags WHERE highlight_id = ? AND tag_id = ?", pair.itemID, pair.tagID).Error
+}
+
+// bookKey matches books by title and author, ignoring case and surrounding spaces
+func bookKey(title, author string) string {
+	return strings.ToLower(strings.TrimSpace(title)) + "\x00" + strings.ToLower(strings.TrimSpace(author))
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestTagAssignments_RoundTrip(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Highlights: []entities.Highlight{{Text: "The obstacle is the way"}}}
+	require.NoError(t, db.SaveBook(book))
+	highlightID := book.Highlights[0].ID
+
+	philosophy, err := db.GetOrCreateTag("Philosophy", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToBook(book.ID, philosophy.ID))
+	stoic, err := db.GetOrCreateTag("stoic", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToHighlight(highlightID, stoic.ID))
+
+	assignments, err := db.GetTagAssignments(0)
+	require.NoError(t, err)
+	require.Len(t, assignments, 2)
+	assert.Equal(t, entities.TagAssignment{Target: entities.TagTargetBook, BookTitle: "Meditations", BookAuthor: "Marcus Aurelius", Tag: "Philosophy"}, assignments[0])
+	assert.Equal(t, highlightID, assignments[1].HighlightID)
+	assert.Equal(t, "stoic", assignments[1].Tag)
+
+	t.Run("merge adds tags", func(t *testing.T) {
+		result, err := db.ApplyTagAssignments(0, []entities.TagAssignment{
+			{Target: entities.TagTargetBook, BookTitle: "meditations ", BookAuthor: "MARCUS AURELIUS", Tag: "philosophy"},
+			{Target: entities.TagTargetBook, BookTitle: "Meditations", BookAuthor: "Marcus Aurelius", Tag: "Stoicism"},
+			{Target: entities.TagTargetBook, BookTitle: "Letters", BookAuthor: "Seneca", Tag: "Stoicism"},
+			{Target: entities.TagTargetHighlight, HighlightID: 9999, Tag: "Stoicism"},
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Added, "existing tags match regardless of case")
+		assert.Equal(t, 0, result.Removed)
+		assert.Equal(t, []int{2, 3}, result.Unmatched)
+
+		book, err := db.GetBookByID(book.ID)
+		require.NoError(t, err)
+		assert.Len(t, book.Tags, 2)
+	})
+
+	t.Run("replace removes unlisted tags", func(t *testing.T) {
+		result, err := db.ApplyTagAssignments(0, []entities.TagAssignment{
+			{Target: entities.TagTargetBook, BookTitle: "Meditations", BookAuthor: "Marcus Aurelius", Tag: "Stoicism"},
+			{Target: entities.TagTargetHighlight, HighlightID: highlightID, Tag: "Stoicism"},
+		}, true)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Added)
+		assert.Equal(t, 2, result.Removed)
+
+		assignments, err := db.GetTagAssignments(0)
+		require.NoError(t, err)
+		require.Len(t, assignments, 2)
+		for _, assignment := range assignments {
+			assert.Equal(t, "Stoicism", assignment.Tag)
+		}
+
+		tags, err := db.GetTagsForUser(0)
+		require.NoError(t, err)
+		require.Len(t, tags, 1, "tags left unused are deleted")
+	})
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ListTombstones returns records of permanently deleted entities, which block
+// re-imports, most recently deleted first. entityType ("book", "highlight" or
+// empty for both) and query (matched against the entity key) narrow the list.
+func (d *Database) ListTombstones(entityType, query string, limit, offset int) ([]entities.DeletedEntity, int64, error) {
+	q := d.DB.Model(&entities.DeletedEntity{})
+	if entityType != "" {
+		q = q.Where("entity_type = ?", entityType)
+	}
+	if query != "" {
+		searchPattern := "%" + query + "%"
+		q = q.Where("LOWER(entity_key) LIKE LOWER(?)", searchPattern)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tombstones []entities.DeletedEntity
+	err := q.Order("deleted_at DESC").Limit(limit).Offset(offset).Find(&tombstones).Error
+	return tombstones, total, err
+}
+
+// DeleteTombstone removes a deletion record so the entity can be imported again.
+// Returns gorm.ErrRecordNotFound if there is no such record.
+func (d *Database) DeleteTombstone(id uint) (*entities.DeletedEntity, error) {
+	var tombstone entities.DeletedEntity
+	if err := d.DB.First(&tombstone, id).Error; err != nil {
+		return nil, err
+	}
+	if err := d.DB.Delete(&tombstone).Error; err != nil {
+		return nil, err
+	}
+	return &tombstone, nil
+}
+
+// GetTombstones returns the deletion records that apply to userID, including
+// ones recorded for every user, oldest first.
+func (d *Database) GetTombstones(userID uint) ([]entities.DeletedEntity, error) {
+	var tombstones []entities.DeletedEntity
+	err := d.DB.Where("user_id = ? OR user_id = 0", userID).Order("deleted_at ASC, id ASC").Find(&tombstones).Error
+	return tombstones, err
+}
+
+// AddTombstone records a deletion, e.g. one copied from another instance,
+// unless an identical record exists. Reports whether it was added.
+func (d *Database) AddTombstone(tombstone *entities.DeletedEntity) (bool, error) {
+	var count int64
+	err := d.DB.Model(&entities.DeletedEntity{}).
+		Where("user_id = ? AND entity_type = ? AND entity_key = ? AND content_hash = ?",
+			tombstone.UserID, tombstone.EntityType, tombstone.EntityKey, tombstone.ContentHash).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return false, err
+	}
+	tombstone.ID = 0
+	if err := d.DB.Create(tombstone).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestTombstones_RemoveAllowsReimport(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := saveTrashTestBook(t, db, "Deleted Book", "One")
+	require.NoError(t, db.DeleteBookPermanently(book.ID, book.UserID))
+
+	tombstones, total, err := db.ListTombstones("", "", 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assert.Equal(t, entities.DeletedEntityBook, tombstones[0].EntityType)
+	assert.Equal(t, "Deleted Book by Author", tombstones[0].Label())
+
+	// The tombstone blocks re-import
+	saveTrashTestBook(t, db, "Deleted Book", "One")
+	books, err := db.GetAllBooks()
+	require.NoError(t, err)
+	assert.Empty(t, books)
+
+	removed, err := db.DeleteTombstone(tombstones[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, tombstones[0].ID, removed.ID)
+
+	saveTrashTestBook(t, db, "Deleted Book", "One")
+	books, err = db.GetAllBooks()
+	require.NoError(t, err)
+	assert.Len(t, books, 1)
+
+	_, err = db.DeleteTombstone(tombstones[0].ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestTombstones_Filter(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := saveTrashTestBook(t, db, "Kept Book", "A memorable line", "Another line")
+	require.NoError(t, db.DeleteHighlightPermanently(book.Highlights[0].ID, book.UserID))
+	other := saveTrashTestBook(t, db, "Gone Book")
+	require.NoError(t, db.DeleteBookPermanently(other.ID, other.UserID))
+
+	highlights, total, err := db.ListTombstones(entities.DeletedEntityHighlight, "", 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assert.Equal(t, "A memorable line", highlights[0].Label())
+
+	matches, total, err := db.ListTombstones("", "gone", 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assert.Equal(t, entities.DeletedEntityBook, matches[0].EntityType)
+
+	page, total, err := db.ListTombstones("", "", 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, page, 1)
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"gorm.io/gorm"
+)
+
+// bookRestoreWindow is how far apart a book's and its highlights' deletion times may be
+// for the highlights to count as deleted together with the book.
+const bookRestoreWindow = 5 * time.Second
+
+// GetTrashedBooks returns soft-deleted books, most recently deleted first.
+func (d *Database) GetTrashedBooks() ([]entities.TrashedBook, error) {
+	var books []entities.Book
+	err := d.DB.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := make([]entities.TrashedBook, 0, len(books))
+	for _, book := range books {
+		var count int64
+		if err := d.DB.Unscoped().Model(&entities.Highlight{}).
+			Where("book_id = ? AND deleted_at IS NOT NULL", book.ID).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		trashed = append(trashed, entities.TrashedBook{Book: book, HighlightCount: count})
+	}
+	return trashed, nil
+}
+
+// GetTrashedHighlights returns individually soft-deleted highlights whose book
+// is not in the trash, most recently deleted first.
+func (d *Database) GetTrashedHighlights() ([]entities.TrashedHighlight, error) {
+	var highlights []entities.Highlight
+	err := d.DB.Unscoped().
+		Preload("Book").
+		Joins("JOIN books ON books.id = highlights.book_id AND books.deleted_at IS NULL").
+		Where("highlights.deleted_at IS NOT NULL").
+		Order("highlights.deleted_at DESC").
+		Find(&highlights).Error
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := make([]entities.TrashedHighlight, 0, len(highlights))
+	for _, h := range highlights {
+		trashed = append(trashed, entities.TrashedHighlight{
+			Highlight:  h,
+			BookTitle:  h.Book.Title,
+			BookAuthor: h.Book.Author,
+		})
+	}
+	return trashed, nil
+}
+
+// RestoreBook moves a soft-deleted book back into the library, along with the
+// highlights that were deleted together with it.
+func (d *Database) RestoreBook(id uint) (*entities.Book, error) {
+	var book entities.Book
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&book, id).Error; err != nil {
+			return err
+		}
+
+		var highlights []entities.Highlight
+		if err := tx.Unscoped().Where("book_id = ? AND deleted_at IS NOT NULL", id).Find(&highlights).Error; err != nil {
+			return err
+		}
+		var highlightIDs []uint
+		for _, h := range highlights {
+			if !h.DeletedAt.Time.Before(book.DeletedAt.Time.Add(-bookRestoreWindow)) {
+				highlightIDs = append(highlightIDs, h.ID)
+			}
+		}
+		if len(highlightIDs) > 0 {
+			if err := tx.Unscoped().Model(&entities.Highlight{}).
+				Where("id IN ?", highlightIDs).
+				Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Model(&entities.Book{}).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return refreshBookCounters(tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	book.DeletedAt = gorm.DeletedAt{}
+	return &book, nil
+}
+
+// RestoreHighlight moves a soft-deleted highlight back into the library.
+// If its book is in the trash as well, the book is restored without its other highlights.
+func (d *Database) RestoreHighlight(id uint) (*entities.Highlight, error) {
+	var highlight entities.Highlight
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&highlight, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&entities.Book{}).
+			Where("id = ? AND deleted_at IS NOT NULL", highlight.BookID).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&entities.Highlight{}).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return refreshBookCounters(tx, highlight.BookID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	highlight.DeletedAt = gorm.DeletedAt{}
+	return &highlight, nil
+}
+
+// PurgeTrash permanently deletes books and highlights that were soft-deleted before
+// olderThan, or everything in the trash if olderThan is zero. Purged entities are
+// recorded like any permanent delete so they are not re-imported.
+func (d *Database) PurgeTrash(olderThan time.Time) (booksPurged, highlightsPurged int64, err error) {
+	bookQuery := d.DB.Unscoped().Model(&entities.Book{}).Where("deleted_at IS NOT NULL")
+	if !olderThan.IsZero() {
+		bookQuery = bookQuery.Where("deleted_at < ?", olderThan)
+	}
+	var books []entities.Book
+	if err := bookQuery.Find(&books).Error; err != nil {
+		return 0, 0, err
+	}
+	for _, book := range books {
+		if err := d.DeleteBookPermanently(book.ID, book.UserID); err != nil {
+			return booksPurged, highlightsPurged, err
+		}
+		booksPurged++
+	}
+
+	highlightQuery := d.DB.Unscoped().Model(&entities.Highlight{}).Where("deleted_at IS NOT NULL")
+	if !olderThan.IsZero() {
+		highlightQuery = highlightQuery.Where("deleted_at < ?", olderThan)
+	}
+	var highlights []entities.Highlight
+	if err := highlightQuery.Find(&highlights).Error; err != nil {
+		return booksPurged, 0, err
+	}
+	for _, h := range highlights {
+		if err := d.DeleteHighlightPermanently(h.ID, h.UserID); err != nil {
+			return booksPurged, highlightsPurged, err
+		}
+		highlightsPurged++
+	}
+
+	return booksPurged, highlightsPurged, nil
+}
+
+// EmptyTrash permanently deletes everything in the trash.
+func (d *Database) EmptyTrash() (booksPurged, highlightsPurged int64, err error) {
+	return d.PurgeTrash(time.Time{})
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func saveTrashTestBook(t *testing.T, db *Database, title string, texts ...string) *entities.Book {
+	t.Helper()
+	book := &entities.Book{Title: title, Author: "Author"}
+	for i, text := range texts {
+		book.Highlights = append(book.Highlights, entities.Highlight{Text: text, LocationValue: i + 1})
+	}
+	require.NoError(t, db.SaveBook(book))
+	return book
+}
+
+func TestTrash_RestoreBookWithHighlights(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := saveTrashTestBook(t, db, "Trashed", "One", "Two")
+	require.NoError(t, db.DeleteBook(book.ID))
+
+	books, err := db.GetTrashedBooks()
+	require.NoError(t, err)
+	require.Len(t, books, 1)
+	assert.Equal(t, "Trashed", books[0].Book.Title)
+	assert.Equal(t, int64(2), books[0].HighlightCount)
+
+	// Highlights of a trashed book are listed with the book, not individually
+	highlights, err := db.GetTrashedHighlights()
+	require.NoError(t, err)
+	assert.Empty(t, highlights)
+
+	restored, err := db.RestoreBook(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Trashed", restored.Title)
+
+	saved, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.Len(t, saved.Highlights, 2)
+
+	books, err = db.GetTrashedBooks()
+	require.NoError(t, err)
+	assert.Empty(t, books)
+}
+
+func TestTrash_RestoreHighlight(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	book := saveTrashTestBook(t, db, "Library Book", "Keep", "Trash me")
+	trashedID := book.Highlights[1].ID
+	require.NoError(t, db.DeleteHighlight(trashedID))
+
+	highlights, err := db.GetTrashedHighlights()
+	require.NoError(t, err)
+	require.Len(t, highlights, 1)
+	assert.Equal(t, "Trash me", highlights[0].Highlight.Text)
+	assert.Equal(t, "Library Book", highlights[0].BookTitle)
+
+	_, err = db.RestoreHighlight(trashedID)
+	require.NoError(t, err)
+
+	_, err = db.GetHighlightByID(trashedID)
+	require.NoError(t, err)
+
+	// Restoring something that is not in the trash fails
+	_, err = db.RestoreHighlight(trashedID)
+	assert.Error(t, err)
+}
+
+func TestTrash_PurgeRespectsCutoff(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	old := saveTrashTestBook(t, db, "Old", "Old highlight")
+	recent := saveTrashTestBook(t, db, "Recent", "Recent highlight")
+	require.NoError(t, db.DeleteBook(old.ID))
+	require.NoError(t, db.DeleteBook(recent.ID))
+
+	longAgo := time.Now().Add(-60 * 24 * time.Hour)
+	require.NoError(t, db.DB.Exec("UPDATE books SET deleted_at = ? WHERE id = ?", longAgo, old.ID).Error)
+	require.NoError(t, db.DB.Exec("UPDATE highlights SET deleted_at = ? WHERE book_id = ?", longAgo, old.ID).Error)
+
+	books, highlights, err := db.PurgeTrash(time.Now().Add(-30 * 24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), books)
+	assert.Equal(t, int64(0), highlights)
+
+	trashed, err := db.GetTrashedBooks()
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, "Recent", trashed[0].Book.Title)
+
+	// Purged books are not re-imported
+	deleted, err := db.IsBookDeleted("Old", "Author", 0)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+}
+
+func TestTrash_Empty(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	trashedBook := saveTrashTestBook(t, db, "Trashed", "A")
+	keptBook := saveTrashTestBook(t, db, "Kept", "B", "C")
+	require.NoError(t, db.DeleteBook(trashedBook.ID))
+	require.NoError(t, db.DeleteHighlight(keptBook.Highlights[0].ID))
+
+	books, highlights, err := db.EmptyTrash()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), books)
+	assert.Equal(t, int64(1), highlights)
+
+	trashedBooks, err := db.GetTrashedBooks()
+	require.NoError(t, err)
+	assert.Empty(t, trashedBooks)
+	trashedHighlights, err := db.GetTrashedHighlights()
+	require.NoError(t, err)
+	assert.Empty(t, trashedHighlights)
+
+	kept, err := db.GetBookByID(keptBook.ID)
+	require.NoError(t, err)
+	assert.Len(t, kept.Highlights, 1)
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ErrInvalidUIPreferences is returned when a UI preference has a value
+// outside its choices
+var ErrInvalidUIPreferences = errors.New("invalid UI preferences")
+
+// GetUIPreferences returns the user's UI settings, the defaults when the user
+// has not changed them.
+func (d *Database) GetUIPreferences(userID uint) (entities.UIPreferences, error) {
+	var prefs entities.UIPreferences
+	err := d.DB.Where("user_id = ?", userID).First(&prefs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.DefaultUIPreferences(userID), nil
+	}
+	if prefs.HighlightSort == "" {
+		// Saved before highlight listings could be sorted
+		prefs.HighlightSort = entities.HighlightSortRecent
+	}
+	return prefs, err
+}
+
+// SaveUIPreferences validates and saves the UI settings of prefs.UserID
+func (d *Database) SaveUIPreferences(prefs *entities.UIPreferences) error {
+	if err := ValidateUIPreferences(prefs); err != nil {
+		return err
+	}
+	// User 0 is a valid owner, so Save would insert it every time
+	return d.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(prefs).Error
+}
+
+// ValidateUIPreferences checks each setting against its choices
+func ValidateUIPreferences(prefs *entities.UIPreferences) error {
+	switch {
+	case !slices.Contains(entities.Themes, prefs.Theme):
+		return fmt.Errorf("%w: theme must be one of %v", ErrInvalidUIPreferences, entities.Themes)
+	case !slices.Contains(entities.BookSorts, prefs.Sort):
+		return fmt.Errorf("%w: sort must be one of %v", ErrInvalidUIPreferences, entities.BookSorts)
+	case !slices.Contains(entities.HighlightSorts, prefs.HighlightSort):
+		return fmt.Errorf("%w: highlight sort must be one of %v", ErrInvalidUIPreferences, entities.HighlightSorts)
+	case !slices.Contains(entities.ViewModes, prefs.ViewMode):
+		return fmt.Errorf("%w: view mode must be one of %v", ErrInvalidUIPreferences, entities.ViewModes)
+	case prefs.PageSize < entities.MinPageSize || prefs.PageSize > entities.MaxPageSize:
+		return fmt.Errorf("%w: page size must be between %d and %d", ErrInvalidUIPreferences, entities.MinPageSize, entities.MaxPageSize)
+	}
+	return nil
+}
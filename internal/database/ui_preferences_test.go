@@ -0,0 +1,49 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestUIPreferences(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	prefs, err := db.GetUIPreferences(0)
+	require.NoError(t, err)
+	assert.Equal(t, entities.DefaultUIPreferences(0), prefs)
+
+	prefs.Theme = entities.ThemeDark
+	prefs.ViewMode = entities.ViewModeGrid
+	require.NoError(t, db.SaveUIPreferences(&prefs))
+
+	prefs.PageSize = 20
+	require.NoError(t, db.SaveUIPreferences(&prefs), "saving again updates the row")
+
+	loaded, err := db.GetUIPreferences(0)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ThemeDark, loaded.Theme)
+	assert.Equal(t, entities.ViewModeGrid, loaded.ViewMode)
+	assert.Equal(t, 20, loaded.PageSize)
+
+	other, err := db.GetUIPreferences(7)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ThemeSystem, other.Theme, "settings are per user")
+
+	invalid := entities.DefaultUIPreferences(7)
+	invalid.Theme = "sepia"
+	assert.ErrorIs(t, db.SaveUIPreferences(&invalid), ErrInvalidUIPreferences)
+	invalid = entities.DefaultUIPreferences(7)
+	invalid.PageSize = 5000
+	assert.ErrorIs(t, db.SaveUIPreferences(&invalid), ErrInvalidUIPreferences)
+	invalid = entities.DefaultUIPreferences(7)
+	invalid.Sort = "random"
+	assert.ErrorIs(t, db.SaveUIPreferences(&invalid), ErrInvalidUIPreferences)
+	invalid = entities.DefaultUIPreferences(7)
+	invalid.HighlightSort = "random"
+	assert.ErrorIs(t, db.SaveUIPreferences(&invalid), ErrInvalidUIPreferences)
+}
@@ -1,21 +1,28 @@
 package database
 
 import (
+	"context"
+
+	"github.com/mrlokans/assistant/internal/dictionary"
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/wordfreq"
 	"gorm.io/gorm"
 )
 
 // AddWord creates a new vocabulary word entry.
 func (d *Database) AddWord(word *entities.Word) error {
+	word.Lemma = wordfreq.Lemma(word.Word)
+	word.Difficulty = wordfreq.Difficulty(word.Word)
 	return d.DB.Create(word).Error
 }
 
 // GetAllWords returns all words for a user with pagination.
+// Unconfirmed candidates are excluded.
 func (d *Database) GetAllWords(userID uint, limit, offset int) ([]entities.Word, int64, error) {
 	var words []entities.Word
 	var total int64
 
-	query := d.DB.Model(&entities.Word{})
+	query := d.DB.Model(&entities.Word{}).Where("status <> ?", entities.WordStatusCandidate)
 	if userID > 0 {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -24,7 +31,8 @@ func (d *Database) GetAllWords(userID uint, limit, offset int) ([]entities.Word,
 		return nil, 0, err
 	}
 
-	query = d.DB.Preload("Definitions").Preload("Book").Preload("Highlight")
+	query = d.DB.Preload("Definitions").Preload("Book").Preload("Highlight").
+		Where("status <> ?", entities.WordStatusCandidate)
 	if userID > 0 {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -41,10 +49,57 @@ func (d *Database) GetAllWords(userID uint, limit, offset int) ([]entities.Word,
 	return words, total, err
 }
 
+// ListWords returns the words matching the filter with pagination.
+func (d *Database) ListWords(filter entities.WordFilter, limit, offset int) ([]entities.Word, int64, error) {
+	var words []entities.Word
+	var total int64
+
+	matching := func(db *gorm.DB) *gorm.DB {
+		if filter.Status != "" {
+			db = db.Where("status = ?", filter.Status)
+		} else {
+			db = db.Where("status <> ?", entities.WordStatusCandidate)
+		}
+		if filter.UserID > 0 {
+			db = db.Where("user_id = ?", filter.UserID)
+		}
+		if filter.MinDifficulty > 0 {
+			db = db.Where("difficulty >= ?", filter.MinDifficulty)
+		}
+		if filter.MaxDifficulty > 0 {
+			db = db.Where("difficulty <= ?", filter.MaxDifficulty)
+		}
+		return db
+	}
+
+	if err := d.DB.Model(&entities.Word{}).Scopes(matching).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := d.DB.Preload("Definitions").Preload("Contexts").Preload("Book").Preload("Highlight").Scopes(matching)
+	switch filter.Sort {
+	case entities.WordSortHardest:
+		query = query.Order("difficulty DESC, word ASC")
+	case entities.WordSortEasiest:
+		query = query.Order("difficulty ASC, word ASC")
+	default:
+		query = query.Order("created_at DESC")
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err := query.Find(&words).Error
+	return words, total, err
+}
+
 // GetWordByID retrieves a word by ID with all relationships.
 func (d *Database) GetWordByID(id uint) (*entities.Word, error) {
 	var word entities.Word
-	err := d.DB.Preload("Definitions").Preload("Book").Preload("Highlight").First(&word, id).Error
+	err := d.DB.Preload("Definitions").Preload("Contexts").Preload("Book").Preload("Highlight").First(&word, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -53,15 +108,20 @@ func (d *Database) GetWordByID(id uint) (*entities.Word, error) {
 
 // UpdateWord updates a word's fields.
 func (d *Database) UpdateWord(word *entities.Word) error {
+	word.Lemma = wordfreq.Lemma(word.Word)
+	word.Difficulty = wordfreq.Difficulty(word.Word)
 	return d.DB.Save(word).Error
 }
 
-// DeleteWord removes a word and its definitions.
+// DeleteWord removes a word with its definitions and contexts.
 func (d *Database) DeleteWord(id uint) error {
 	return d.DB.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("word_id = ?", id).Delete(&entities.WordDefinition{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("word_id = ?", id).Delete(&entities.WordContext{}).Error; err != nil {
+			return err
+		}
 		return tx.Delete(&entities.Word{}, id).Error
 	})
 }
@@ -69,7 +129,7 @@ func (d *Database) DeleteWord(id uint) error {
 // GetPendingWords returns words awaiting enrichment.
 func (d *Database) GetPendingWords(limit int) ([]entities.Word, error) {
 	var words []entities.Word
-	query := d.DB.Where("status = ?", entities.WordStatusPending).Order("created_at ASC")
+	query := d.DB.Preload("Book").Where("status = ?", entities.WordStatusPending).Order("created_at ASC")
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
@@ -95,30 +155,66 @@ func (d *Database) SaveDefinitions(wordID uint, definitions []entities.WordDefin
 	})
 }
 
-// UpdateWordStatus updates the enrichment status of a word.
+// UpdateWordStatus updates the enrichment status of a word, starting its
+// count of failed enrichment attempts over.
 func (d *Database) UpdateWordStatus(id uint, status entities.WordStatus, errorMsg string) error {
+	return d.RecordEnrichmentFailure(id, 0, status, errorMsg)
+}
+
+// RecordEnrichmentFailure sets the enrichment status of a word along with the
+// number of failed attempts to enrich it and the last error.
+func (d *Database) RecordEnrichmentFailure(id uint, attempts int, status entities.WordStatus, errorMsg string) error {
 	updates := map[string]any{
-		"status": status,
-	}
-	if errorMsg != "" {
-		updates["enrichment_error"] = errorMsg
-	} else {
-		updates["enrichment_error"] = ""
+		"status":              status,
+		"enrichment_error":    errorMsg,
+		"enrichment_attempts": attempts,
 	}
 	return d.DB.Model(&entities.Word{}).Where("id = ?", id).Updates(updates).Error
 }
 
-// GetWordsByHighlight returns all words for a specific highlight.
+// RequeueFailedWords sets the user's failed words back to pending with a
+// fresh count of attempts, returning their IDs.
+func (d *Database) RequeueFailedWords(userID uint) ([]uint, error) {
+	var ids []uint
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&entities.Word{}).Where("status = ?", entities.WordStatusFailed)
+		if userID > 0 {
+			query = query.Where("user_id = ?", userID)
+		}
+		if err := query.Order("id ASC").Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&entities.Word{}).Where("id IN ?", ids).Updates(map[string]any{
+			"status":              entities.WordStatusPending,
+			"enrichment_error":    "",
+			"enrichment_attempts": 0,
+		}).Error
+	})
+	return ids, err
+}
+
+// GetWordsByHighlight returns all words for a specific highlight, including
+// words merged with one saved from it.
 func (d *Database) GetWordsByHighlight(highlightID uint) ([]entities.Word, error) {
 	var words []entities.Word
-	err := d.DB.Preload("Definitions").Where("highlight_id = ?", highlightID).Find(&words).Error
+	err := d.DB.Preload("Definitions").
+		Where("highlight_id = ? OR id IN (?)", highlightID,
+			d.DB.Model(&entities.WordContext{}).Select("word_id").Where("highlight_id = ?", highlightID)).
+		Find(&words).Error
 	return words, err
 }
 
-// GetWordsByBook returns all words for a specific book.
+// GetWordsByBook returns all words for a specific book, including words
+// merged with one saved from it.
 func (d *Database) GetWordsByBook(bookID uint) ([]entities.Word, error) {
 	var words []entities.Word
-	err := d.DB.Preload("Definitions").Where("book_id = ?", bookID).Find(&words).Error
+	err := d.DB.Preload("Definitions").
+		Where("book_id = ? OR id IN (?)", bookID,
+			d.DB.Model(&entities.WordContext{}).Select("word_id").Where("book_id = ?", bookID)).
+		Find(&words).Error
 	return words, err
 }
 
@@ -138,11 +234,28 @@ func (d *Database) FindWordBySource(word, sourceBookTitle, sourceBookAuthor, sou
 	return &existing, nil
 }
 
+// FindWordByText returns any existing word with the given text or the same
+// lemma, regardless of status. Used to avoid suggesting words that are already
+// in the vocabulary, including other forms of them.
+func (d *Database) FindWordByText(word string, userID uint) (*entities.Word, error) {
+	var existing entities.Word
+	query := d.DB.Where("LOWER(word) = LOWER(?) OR lemma = ?", word, wordfreq.Lemma(word))
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.First(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
 // SearchWords searches for words by word text.
 func (d *Database) SearchWords(query string, userID uint, limit int) ([]entities.Word, error) {
 	var words []entities.Word
 	searchPattern := "%" + query + "%"
-	q := d.DB.Preload("Definitions").Where("LOWER(word) LIKE LOWER(?)", searchPattern)
+	q := d.DB.Preload("Definitions").Where("LOWER(word) LIKE LOWER(?)", searchPattern).
+		Where("status <> ?", entities.WordStatusCandidate)
 	if userID > 0 {
 		q = q.Where("user_id = ?", userID)
 	}
@@ -154,8 +267,9 @@ func (d *Database) SearchWords(query string, userID uint, limit int) ([]entities
 }
 
 // GetVocabularyStats returns vocabulary statistics.
+// Unconfirmed candidates are not counted in the total.
 func (d *Database) GetVocabularyStats(userID uint) (total, pending, enriched, failed int64, err error) {
-	baseQuery := d.DB.Model(&entities.Word{})
+	baseQuery := d.DB.Model(&entities.Word{}).Where("status <> ?", entities.WordStatusCandidate)
 	if userID > 0 {
 		baseQuery = baseQuery.Where("user_id = ?", userID)
 	}
@@ -219,3 +333,89 @@ func (d *Database) GetWordsByStatus(userID uint, status entities.WordStatus, lim
 	err := query.Find(&words).Error
 	return words, total, err
 }
+
+// backfillWordDifficulty scores the words saved before difficulty scores were added.
+func backfillWordDifficulty(ctx context.Context, d *Database, report func(processed, total int)) error {
+	const batchSize = 500
+
+	pending := func() *gorm.DB {
+		return d.DB.Model(&entities.Word{}).Where("difficulty = 0")
+	}
+
+	var total int64
+	if err := pending().Count(&total).Error; err != nil {
+		return err
+	}
+	report(0, int(total))
+
+	processed := 0
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var words []entities.Word
+		if err := pending().Where("id > ?", lastID).Order("id ASC").Limit(batchSize).
+			Select("id", "word").Find(&words).Error; err != nil {
+			return err
+		}
+		if len(words) == 0 {
+			return nil
+		}
+
+		err := d.DB.Transaction(func(tx *gorm.DB) error {
+			for _, w := range words {
+				if err := tx.Model(&entities.Word{}).Where("id = ?", w.ID).
+					UpdateColumn("difficulty", wordfreq.Difficulty(w.Word)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		lastID = words[len(words)-1].ID
+		processed += len(words)
+		report(processed, int(total))
+	}
+}
+
+// backfillDefinitionAttribution credits the definitions looked up before
+// attribution was stored to their dictionary's source and license.
+func backfillDefinitionAttribution(ctx context.Context, d *Database, report func(processed, total int)) error {
+	pending := func() *gorm.DB {
+		return d.DB.Model(&entities.WordDefinition{}).Where("attribution = '' OR attribution IS NULL")
+	}
+
+	var total int64
+	if err := pending().Count(&total).Error; err != nil {
+		return err
+	}
+	report(0, int(total))
+
+	processed := 0
+	for _, provider := range dictionary.Providers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		info, ok := dictionary.Info(provider)
+		if !ok {
+			continue
+		}
+		result := pending().Where("source = ?", provider).Updates(map[string]any{
+			"attribution": info.Attribution,
+			"license":     info.License,
+			"license_url": info.LicenseURL,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		processed += int(result.RowsAffected)
+		report(processed, int(total))
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/wordfreq"
 )
 
 // Repository handles all vocabulary database operations.
@@ -30,15 +31,18 @@ func NewRepository(db *gorm.DB) *Repository {
 
 // AddWord creates a new vocabulary word entry.
 func (r *Repository) AddWord(word *entities.Word) error {
+	word.Lemma = wordfreq.Lemma(word.Word)
+	word.Difficulty = wordfreq.Difficulty(word.Word)
 	return r.db.Create(word).Error
 }
 
 // GetAllWords returns all words for a user with pagination.
+// Unconfirmed candidates are excluded.
 func (r *Repository) GetAllWords(userID uint, limit, offset int) ([]entities.Word, int64, error) {
 	var words []entities.Word
 	var total int64
 
-	query := r.db.Model(&entities.Word{})
+	query := r.db.Model(&entities.Word{}).Where("status <> ?", entities.WordStatusCandidate)
 	if userID > 0 {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -47,7 +51,8 @@ func (r *Repository) GetAllWords(userID uint, limit, offset int) ([]entities.Wor
 		return nil, 0, err
 	}
 
-	query = r.db.Preload("Definitions").Preload("Book").Preload("Highlight")
+	query = r.db.Preload("Definitions").Preload("Book").Preload("Highlight").
+		Where("status <> ?", entities.WordStatusCandidate)
 	if userID > 0 {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -64,10 +69,57 @@ func (r *Repository) GetAllWords(userID uint, limit, offset int) ([]entities.Wor
 	return words, total, err
 }
 
+// ListWords returns the words matching the filter with pagination.
+func (r *Repository) ListWords(filter entities.WordFilter, limit, offset int) ([]entities.Word, int64, error) {
+	var words []entities.Word
+	var total int64
+
+	matching := func(db *gorm.DB) *gorm.DB {
+		if filter.Status != "" {
+			db = db.Where("status = ?", filter.Status)
+		} else {
+			db = db.Where("status <> ?", entities.WordStatusCandidate)
+		}
+		if filter.UserID > 0 {
+			db = db.Where("user_id = ?", filter.UserID)
+		}
+		if filter.MinDifficulty > 0 {
+			db = db.Where("difficulty >= ?", filter.MinDifficulty)
+		}
+		if filter.MaxDifficulty > 0 {
+			db = db.Where("difficulty <= ?", filter.MaxDifficulty)
+		}
+		return db
+	}
+
+	if err := r.db.Model(&entities.Word{}).Scopes(matching).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.Preload("Definitions").Preload("Contexts").Preload("Book").Preload("Highlight").Scopes(matching)
+	switch filter.Sort {
+	case entities.WordSortHardest:
+		query = query.Order("difficulty DESC, word ASC")
+	case entities.WordSortEasiest:
+		query = query.Order("difficulty ASC, word ASC")
+	default:
+		query = query.Order("created_at DESC")
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err := query.Find(&words).Error
+	return words, total, err
+}
+
 // GetWordByID retrieves a word by ID with all relationships.
 func (r *Repository) GetWordByID(id uint) (*entities.Word, error) {
 	var word entities.Word
-	err := r.db.Preload("Definitions").Preload("Book").Preload("Highlight").First(&word, id).Error
+	err := r.db.Preload("Definitions").Preload("Contexts").Preload("Book").Preload("Highlight").First(&word, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -76,15 +128,20 @@ func (r *Repository) GetWordByID(id uint) (*entities.Word, error) {
 
 // UpdateWord updates a word's fields.
 func (r *Repository) UpdateWord(word *entities.Word) error {
+	word.Lemma = wordfreq.Lemma(word.Word)
+	word.Difficulty = wordfreq.Difficulty(word.Word)
 	return r.db.Save(word).Error
 }
 
-// DeleteWord removes a word and its definitions.
+// DeleteWord removes a word with its definitions and contexts.
 func (r *Repository) DeleteWord(id uint) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("word_id = ?", id).Delete(&entities.WordDefinition{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("word_id = ?", id).Delete(&entities.WordContext{}).Error; err != nil {
+			return err
+		}
 		return tx.Delete(&entities.Word{}, id).Error
 	})
 }
@@ -92,7 +149,7 @@ func (r *Repository) DeleteWord(id uint) error {
 // GetPendingWords returns words awaiting enrichment.
 func (r *Repository) GetPendingWords(limit int) ([]entities.Word, error) {
 	var words []entities.Word
-	query := r.db.Where("status = ?", entities.WordStatusPending).Order("created_at ASC")
+	query := r.db.Preload("Book").Where("status = ?", entities.WordStatusPending).Order("created_at ASC")
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
@@ -118,30 +175,66 @@ func (r *Repository) SaveDefinitions(wordID uint, definitions []entities.WordDef
 	})
 }
 
-// UpdateWordStatus updates the enrichment status of a word.
+// UpdateWordStatus updates the enrichment status of a word, starting its
+// count of failed enrichment attempts over.
 func (r *Repository) UpdateWordStatus(id uint, status entities.WordStatus, errorMsg string) error {
+	return r.RecordEnrichmentFailure(id, 0, status, errorMsg)
+}
+
+// RecordEnrichmentFailure sets the enrichment status of a word along with the
+// number of failed attempts to enrich it and the last error.
+func (r *Repository) RecordEnrichmentFailure(id uint, attempts int, status entities.WordStatus, errorMsg string) error {
 	updates := map[string]any{
-		"status": status,
-	}
-	if errorMsg != "" {
-		updates["enrichment_error"] = errorMsg
-	} else {
-		updates["enrichment_error"] = ""
+		"status":              status,
+		"enrichment_error":    errorMsg,
+		"enrichment_attempts": attempts,
 	}
 	return r.db.Model(&entities.Word{}).Where("id = ?", id).Updates(updates).Error
 }
 
-// GetWordsByHighlight returns all words for a specific highlight.
+// RequeueFailedWords sets the user's failed words back to pending with a
+// fresh count of attempts, returning their IDs.
+func (r *Repository) RequeueFailedWords(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&entities.Word{}).Where("status = ?", entities.WordStatusFailed)
+		if userID > 0 {
+			query = query.Where("user_id = ?", userID)
+		}
+		if err := query.Order("id ASC").Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&entities.Word{}).Where("id IN ?", ids).Updates(map[string]any{
+			"status":              entities.WordStatusPending,
+			"enrichment_error":    "",
+			"enrichment_attempts": 0,
+		}).Error
+	})
+	return ids, err
+}
+
+// GetWordsByHighlight returns all words for a specific highlight, including
+// words merged with one saved from it.
 func (r *Repository) GetWordsByHighlight(highlightID uint) ([]entities.Word, error) {
 	var words []entities.Word
-	err := r.db.Preload("Definitions").Where("highlight_id = ?", highlightID).Find(&words).Error
+	err := r.db.Preload("Definitions").
+		Where("highlight_id = ? OR id IN (?)", highlightID,
+			r.db.Model(&entities.WordContext{}).Select("word_id").Where("highlight_id = ?", highlightID)).
+		Find(&words).Error
 	return words, err
 }
 
-// GetWordsByBook returns all words for a specific book.
+// GetWordsByBook returns all words for a specific book, including words
+// merged with one saved from it.
 func (r *Repository) GetWordsByBook(bookID uint) ([]entities.Word, error) {
 	var words []entities.Word
-	err := r.db.Preload("Definitions").Where("book_id = ?", bookID).Find(&words).Error
+	err := r.db.Preload("Definitions").
+		Where("book_id = ? OR id IN (?)", bookID,
+			r.db.Model(&entities.WordContext{}).Select("word_id").Where("book_id = ?", bookID)).
+		Find(&words).Error
 	return words, err
 }
 
@@ -160,11 +253,28 @@ func (r *Repository) FindWordBySource(word, sourceBookTitle, sourceBookAuthor, s
 	return &existing, nil
 }
 
+// FindWordByText returns any existing word with the given text or the same
+// lemma, regardless of status. Used to avoid suggesting words that are already
+// in the vocabulary, including other forms of them.
+func (r *Repository) FindWordByText(word string, userID uint) (*entities.Word, error) {
+	var existing entities.Word
+	query := r.db.Where("LOWER(word) = LOWER(?) OR lemma = ?", word, wordfreq.Lemma(word))
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.First(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
 // SearchWords searches for words by word text.
 func (r *Repository) SearchWords(query string, userID uint, limit int) ([]entities.Word, error) {
 	var words []entities.Word
 	searchPattern := "%" + query + "%"
-	q := r.db.Preload("Definitions").Where("LOWER(word) LIKE LOWER(?)", searchPattern)
+	q := r.db.Preload("Definitions").Where("LOWER(word) LIKE LOWER(?)", searchPattern).
+		Where("status <> ?", entities.WordStatusCandidate)
 	if userID > 0 {
 		q = q.Where("user_id = ?", userID)
 	}
@@ -176,8 +286,9 @@ func (r *Repository) SearchWords(query string, userID uint, limit int) ([]entiti
 }
 
 // GetVocabularyStats returns vocabulary statistics.
+// Unconfirmed candidates are not counted in the total.
 func (r *Repository) GetVocabularyStats(userID uint) (total, pending, enriched, failed int64, err error) {
-	baseQuery := r.db.Model(&entities.Word{})
+	baseQuery := r.db.Model(&entities.Word{}).Where("status <> ?", entities.WordStatusCandidate)
 	if userID > 0 {
 		baseQuery = baseQuery.Where("user_id = ?", userID)
 	}
@@ -29,6 +29,7 @@ func setupTestDB(t *testing.T) (*gorm.DB, *Repository, func()) {
 		&entities.Tag{},
 		&entities.Word{},
 		&entities.WordDefinition{},
+		&entities.WordContext{},
 	)
 	require.NoError(t, err)
 
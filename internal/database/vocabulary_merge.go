@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/wordfreq"
+	"gorm.io/gorm"
+)
+
+// ErrMergeAcrossUsers is returned when merging words that belong to different users.
+var ErrMergeAcrossUsers = errors.New("cannot merge words of different users")
+
+// FindWordByLemma returns the oldest confirmed word with the given lemma, so a
+// new form of a saved word can be added to it instead of saved separately.
+func (d *Database) FindWordByLemma(lemma string, userID uint) (*entities.Word, error) {
+	var existing entities.Word
+	query := d.DB.Where("lemma = ? AND status <> ?", lemma, entities.WordStatusCandidate)
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Order("id ASC").First(&existing).Error; err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// AddWordContext records another place a word was captured. A context from a
+// highlight the word already has is ignored.
+func (d *Database) AddWordContext(wordContext *entities.WordContext) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		return addWordContext(tx, wordContext)
+	})
+}
+
+func addWordContext(tx *gorm.DB, wordContext *entities.WordContext) error {
+	var word entities.Word
+	if err := tx.Select("id", "source_book_title", "source_highlight_text").First(&word, wordContext.WordID).Error; err != nil {
+		return err
+	}
+	if word.SourceBookTitle == wordContext.SourceBookTitle && word.SourceHighlightText == wordContext.SourceHighlightText {
+		return nil
+	}
+
+	var existing int64
+	if err := tx.Model(&entities.WordContext{}).
+		Where("word_id = ? AND source_book_title = ? AND source_highlight_text = ?",
+			wordContext.WordID, wordContext.SourceBookTitle, wordContext.SourceHighlightText).
+		Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+	return tx.Create(wordContext).Error
+}
+
+// MergeWords folds the given words into the target word and deletes them.
+// Their contexts and sources are kept as contexts of the target. The target
+// takes over definitions and the review schedule of a merged word when it has
+// none of its own.
+func (d *Database) MergeWords(targetID uint, wordIDs []uint) (*entities.Word, error) {
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		return mergeWords(tx, targetID, wordIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.GetWordByID(targetID)
+}
+
+func mergeWords(tx *gorm.DB, targetID uint, wordIDs []uint) error {
+	var target entities.Word
+	if err := tx.First(&target, targetID).Error; err != nil {
+		return err
+	}
+
+	var definitions int64
+	if err := tx.Model(&entities.WordDefinition{}).Where("word_id = ?", target.ID).Count(&definitions).Error; err != nil {
+		return err
+	}
+
+	for _, id := range wordIDs {
+		if id == target.ID {
+			continue
+		}
+		var word entities.Word
+		if err := tx.First(&word, id).Error; err != nil {
+			return fmt.Errorf("word %d: %w", id, err)
+		}
+		if word.UserID != target.UserID {
+			return ErrMergeAcrossUsers
+		}
+
+		err := addWordContext(tx, &entities.WordContext{
+			WordID:              target.ID,
+			HighlightID:         word.HighlightID,
+			BookID:              word.BookID,
+			Form:                word.Word,
+			Context:             word.Context,
+			SourceBookTitle:     word.SourceBookTitle,
+			SourceBookAuthor:    word.SourceBookAuthor,
+			SourceHighlightText: word.SourceHighlightText,
+			CreatedAt:           word.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&entities.WordContext{}).Where("word_id = ?", word.ID).
+			Update("word_id", target.ID).Error; err != nil {
+			return err
+		}
+
+		if definitions == 0 {
+			moved := tx.Model(&entities.WordDefinition{}).Where("word_id = ?", word.ID).Update("word_id", target.ID)
+			if moved.Error != nil {
+				return moved.Error
+			}
+			if moved.RowsAffected > 0 {
+				definitions = moved.RowsAffected
+				target.Status = word.Status
+				target.EnrichmentError = ""
+				target.EnrichmentAttempts = 0
+			}
+		}
+		if target.ReviewedAt == nil && word.ReviewedAt != nil {
+			target.ReviewEase = word.ReviewEase
+			target.ReviewInterval = word.ReviewInterval
+			target.ReviewRepetitions = word.ReviewRepetitions
+			target.ReviewDueAt = word.ReviewDueAt
+			target.ReviewedAt = word.ReviewedAt
+		}
+
+		if err := tx.Where("word_id = ?", word.ID).Delete(&entities.WordDefinition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entities.Word{}, word.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&entities.Word{}).Where("id = ?", target.ID).Updates(map[string]any{
+		"status":              target.Status,
+		"enrichment_error":    target.EnrichmentError,
+		"enrichment_attempts": target.EnrichmentAttempts,
+		"review_ease":         target.ReviewEase,
+		"review_interval":     target.ReviewInterval,
+		"review_repetitions":  target.ReviewRepetitions,
+		"review_due_at":       target.ReviewDueAt,
+		"reviewed_at":         target.ReviewedAt,
+	}).Error
+}
+
+// ConsolidateWords merges the user's words that share a lemma, such as "ran"
+// and "running", returning the number of words merged away. Each group is kept
+// on its enriched word if it has one, otherwise on its oldest word.
+// Unconfirmed candidates are left alone.
+func (d *Database) ConsolidateWords(userID uint) (int, error) {
+	query := d.DB.Model(&entities.Word{}).
+		Where("lemma <> '' AND status <> ?", entities.WordStatusCandidate)
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var words []entities.Word
+	if err := query.Select("id", "user_id", "lemma", "status").Order("id ASC").Find(&words).Error; err != nil {
+		return 0, err
+	}
+
+	type group struct {
+		userID uint
+		lemma  string
+	}
+	groups := make(map[group][]entities.Word)
+	var order []group
+	for _, word := range words {
+		key := group{userID: word.UserID, lemma: word.Lemma}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], word)
+	}
+
+	merged := 0
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		for _, key := range order {
+			members := groups[key]
+			if len(members) < 2 {
+				continue
+			}
+
+			target := members[0]
+			for _, word := range members {
+				if word.Status == entities.WordStatusEnriched {
+					target = word
+					break
+				}
+			}
+			ids := make([]uint, 0, len(members)-1)
+			for _, word := range members {
+				if word.ID != target.ID {
+					ids = append(ids, word.ID)
+				}
+			}
+
+			if err := mergeWords(tx, target.ID, ids); err != nil {
+				return fmt.Errorf("merge words into %d: %w", target.ID, err)
+			}
+			merged += len(ids)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return merged, nil
+}
+
+// backfillWordLemmas sets the lemma of words saved before lemmatization.
+func backfillWordLemmas(ctx context.Context, d *Database, report func(processed, total int)) error {
+	const batchSize = 500
+
+	pending := func() *gorm.DB {
+		return d.DB.Model(&entities.Word{}).Where("lemma = '' OR lemma IS NULL")
+	}
+
+	var total int64
+	if err := pending().Count(&total).Error; err != nil {
+		return err
+	}
+	report(0, int(total))
+
+	processed := 0
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var words []entities.Word
+		if err := pending().Where("id > ?", lastID).Order("id ASC").Limit(batchSize).
+			Select("id", "word").Find(&words).Error; err != nil {
+			return err
+		}
+		if len(words) == 0 {
+			return nil
+		}
+
+		err := d.DB.Transaction(func(tx *gorm.DB) error {
+			for _, w := range words {
+				if err := tx.Model(&entities.Word{}).Where("id = ?", w.ID).
+					UpdateColumn("lemma", wordfreq.Lemma(w.Word)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		lastID = words[len(words)-1].ID
+		processed += len(words)
+		report(processed, int(total))
+	}
+}
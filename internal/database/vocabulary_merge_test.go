@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddWord_Lemma(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	word := &entities.Word{Word: "Obfuscating", Status: entities.WordStatusPending}
+	require.NoError(t, db.AddWord(word))
+	assert.Equal(t, "obfuscate", word.Lemma)
+
+	found, err := db.FindWordByText("obfuscated", 0)
+	require.NoError(t, err)
+	assert.Equal(t, word.ID, found.ID)
+
+	found, err = db.FindWordByLemma("obfuscate", 0)
+	require.NoError(t, err)
+	assert.Equal(t, word.ID, found.ID)
+}
+
+func TestAddWordContext(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	word := &entities.Word{Word: "run", Status: entities.WordStatusPending, SourceBookTitle: "Born to Run", SourceHighlightText: "We run."}
+	require.NoError(t, db.AddWord(word))
+
+	other := entities.WordContext{WordID: word.ID, Form: "running", SourceBookTitle: "Endurance", SourceHighlightText: "Kept running."}
+	require.NoError(t, db.AddWordContext(&other))
+	// The same source is recorded once, and the word's own source is not repeated
+	require.NoError(t, db.AddWordContext(&entities.WordContext{WordID: word.ID, Form: "running", SourceBookTitle: "Endurance", SourceHighlightText: "Kept running."}))
+	require.NoError(t, db.AddWordContext(&entities.WordContext{WordID: word.ID, Form: "ran", SourceBookTitle: "Born to Run", SourceHighlightText: "We run."}))
+
+	retrieved, err := db.GetWordByID(word.ID)
+	require.NoError(t, err)
+	require.Len(t, retrieved.Contexts, 1)
+	assert.Equal(t, "Endurance", retrieved.Contexts[0].SourceBookTitle)
+
+	require.NoError(t, db.DeleteWord(word.ID))
+	var remaining int64
+	require.NoError(t, db.DB.Model(&entities.WordContext{}).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+}
+
+func TestMergeWords(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Endurance", Author: "Alfred Lansing", Highlights: []entities.Highlight{{Text: "They kept running."}}}
+	require.NoError(t, db.SaveBook(book))
+
+	target := &entities.Word{Word: "run", Status: entities.WordStatusPending, SourceBookTitle: "Born to Run"}
+	require.NoError(t, db.AddWord(target))
+
+	reviewedAt := time.Now().Add(-24 * time.Hour)
+	running := &entities.Word{
+		Word: "running", Status: entities.WordStatusEnriched, Context: "They kept running.",
+		HighlightID: &book.Highlights[0].ID, BookID: &book.ID,
+		SourceBookTitle: "Endurance", SourceHighlightText: "They kept running.",
+		ReviewRepetitions: 2, ReviewInterval: 6, ReviewedAt: &reviewedAt,
+	}
+	require.NoError(t, db.AddWord(running))
+	require.NoError(t, db.SaveDefinitions(running.ID, []entities.WordDefinition{{PartOfSpeech: "verb", Definition: "To move swiftly"}}))
+	require.NoError(t, db.AddWordContext(&entities.WordContext{WordID: running.ID, Form: "ran", SourceBookTitle: "Dune"}))
+
+	merged, err := db.MergeWords(target.ID, []uint{running.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "run", merged.Word)
+	assert.Equal(t, entities.WordStatusEnriched, merged.Status)
+	require.Len(t, merged.Definitions, 1)
+	assert.Equal(t, 2, merged.ReviewRepetitions)
+	require.Len(t, merged.Contexts, 2)
+	contexts := map[string]entities.WordContext{}
+	for _, wordContext := range merged.Contexts {
+		contexts[wordContext.Form] = wordContext
+	}
+	assert.Equal(t, "They kept running.", contexts["running"].Context)
+	assert.Equal(t, "Endurance", contexts["running"].SourceBookTitle)
+	assert.Equal(t, "Dune", contexts["ran"].SourceBookTitle)
+
+	_, err = db.GetWordByID(running.ID)
+	assert.Error(t, err)
+
+	// The merged word still shows up among the vocabulary of its book
+	words, err := db.GetWordsByBook(book.ID)
+	require.NoError(t, err)
+	require.Len(t, words, 1)
+	assert.Equal(t, target.ID, words[0].ID)
+}
+
+func TestMergeWords_Errors(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	first := &entities.Word{Word: "run", UserID: 1, Status: entities.WordStatusPending}
+	second := &entities.Word{Word: "running", UserID: 2, Status: entities.WordStatusPending}
+	require.NoError(t, db.AddWord(first))
+	require.NoError(t, db.AddWord(second))
+
+	_, err := db.MergeWords(first.ID, []uint{second.ID})
+	assert.ErrorIs(t, err, ErrMergeAcrossUsers)
+
+	_, err = db.MergeWords(first.ID, []uint{999})
+	assert.Error(t, err)
+
+	// Nothing was merged
+	_, err = db.GetWordByID(second.ID)
+	assert.NoError(t, err)
+}
+
+func TestConsolidateWords(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	for _, word := range []*entities.Word{
+		{Word: "running", Status: entities.WordStatusPending},
+		{Word: "run", Status: entities.WordStatusEnriched},
+		{Word: "ran", Status: entities.WordStatusFailed},
+		{Word: "obfuscate", Status: entities.WordStatusPending},
+		{Word: "obfuscating", Status: entities.WordStatusCandidate},
+		{Word: "runs", UserID: 2, Status: entities.WordStatusPending},
+	} {
+		require.NoError(t, db.AddWord(word))
+	}
+
+	merged, err := db.ConsolidateWords(0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, merged)
+
+	var remaining []string
+	require.NoError(t, db.DB.Model(&entities.Word{}).Order("id ASC").Pluck("word", &remaining).Error)
+	assert.Equal(t, []string{"run", "obfuscate", "obfuscating", "runs"}, remaining)
+
+	merged, err = db.ConsolidateWords(0)
+	require.NoError(t, err)
+	assert.Zero(t, merged)
+}
+
+func TestMigrations_BackfillWordLemmas(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	word := &entities.Word{Word: "Equivocated", Status: entities.WordStatusPending}
+	require.NoError(t, db.AddWord(word))
+	require.NoError(t, db.DB.Model(word).UpdateColumn("lemma", "").Error)
+
+	require.NoError(t, backfillWordLemmas(context.Background(), db, func(int, int) {}))
+
+	retrieved, err := db.GetWordByID(word.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "equivocate", retrieved.Lemma)
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// GetDueWords returns the words due for review at now, overdue ones first and
+// then words never reviewed, oldest first, with the number of words due.
+// Candidates are never due; the filter's status and sort are ignored.
+func (d *Database) GetDueWords(filter entities.WordFilter, now time.Time, limit int) ([]entities.Word, int64, error) {
+	due := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("status <> ?", entities.WordStatusCandidate).
+			Where("(review_due_at IS NULL OR review_due_at <= ?)", now.UTC())
+		if filter.UserID > 0 {
+			db = db.Where("user_id = ?", filter.UserID)
+		}
+		if filter.MinDifficulty > 0 {
+			db = db.Where("difficulty >= ?", filter.MinDifficulty)
+		}
+		if filter.MaxDifficulty > 0 {
+			db = db.Where("difficulty <= ?", filter.MaxDifficulty)
+		}
+		return db
+	}
+
+	var total int64
+	if err := d.DB.Model(&entities.Word{}).Scopes(due).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var words []entities.Word
+	query := d.DB.Preload("Definitions").Scopes(due).
+		Order("review_due_at IS NULL, review_due_at ASC, created_at ASC, id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&words).Error
+	return words, total, err
+}
+
+// SaveWordReview stores a word's review schedule, leaving its other fields alone.
+func (d *Database) SaveWordReview(word *entities.Word) error {
+	result := d.DB.Model(&entities.Word{}).Where("id = ?", word.ID).Updates(map[string]any{
+		"review_ease":        word.ReviewEase,
+		"review_interval":    word.ReviewInterval,
+		"review_repetitions": word.ReviewRepetitions,
+		"review_due_at":      word.ReviewDueAt,
+		"reviewed_at":        word.ReviewedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestGetDueWords(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	add := func(word string, status entities.WordStatus, due *time.Time) *entities.Word {
+		w := &entities.Word{Word: word, Status: status}
+		require.NoError(t, db.AddWord(w))
+		if due != nil {
+			w.ReviewDueAt = due
+			w.ReviewedAt = &now
+			w.ReviewInterval = 1
+			require.NoError(t, db.SaveWordReview(w))
+		}
+		return w
+	}
+	yesterday := now.AddDate(0, 0, -1)
+	lastWeek := now.AddDate(0, 0, -7)
+	tomorrow := now.AddDate(0, 0, 1)
+
+	add("garden", entities.WordStatusEnriched, nil)
+	add("lagniappe", entities.WordStatusEnriched, &yesterday)
+	add("pusillanimous", entities.WordStatusPending, &lastWeek)
+	add("numinous", entities.WordStatusEnriched, &tomorrow)
+	add("ephemeral", entities.WordStatusCandidate, nil)
+
+	words, due, err := db.GetDueWords(entities.WordFilter{}, now, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), due)
+	var order []string
+	for _, w := range words {
+		order = append(order, w.Word)
+	}
+	assert.Equal(t, []string{"pusillanimous", "lagniappe", "garden"}, order, "overdue first, then new words")
+
+	words, due, err = db.GetDueWords(entities.WordFilter{MinDifficulty: 81, MaxDifficulty: 100}, now, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), due)
+	require.Len(t, words, 1)
+	assert.Equal(t, "pusillanimous", words[0].Word)
+
+	assert.Error(t, db.SaveWordReview(&entities.Word{ID: 999}))
+}
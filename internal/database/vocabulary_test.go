@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -69,6 +70,68 @@ func TestGetAllWords(t *testing.T) {
 	assert.Len(t, result, 2)
 }
 
+func TestListWords_Difficulty(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	for _, w := range []string{"garden", "pusillanimous", "the", "lagniappe"} {
+		require.NoError(t, db.AddWord(&entities.Word{Word: w, Status: entities.WordStatusPending}))
+	}
+	require.NoError(t, db.AddWord(&entities.Word{Word: "numinous", Status: entities.WordStatusCandidate}))
+
+	hardest, total, err := db.ListWords(entities.WordFilter{Sort: entities.WordSortHardest}, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total, "candidates are left out")
+	var order []string
+	for _, w := range hardest {
+		assert.NotZero(t, w.Difficulty)
+		order = append(order, w.Word)
+	}
+	assert.Equal(t, []string{"pusillanimous", "lagniappe", "garden", "the"}, order)
+
+	hard, total, err := db.ListWords(entities.WordFilter{MinDifficulty: 81, MaxDifficulty: 100, Sort: entities.WordSortEasiest}, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, hard, 1)
+	assert.Equal(t, "lagniappe", hard[0].Word)
+}
+
+func TestMigrations_BackfillWordDifficulty(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	word := &entities.Word{Word: "lagniappe", Status: entities.WordStatusPending}
+	require.NoError(t, db.AddWord(word))
+	require.NoError(t, db.DB.Model(word).UpdateColumn("difficulty", 0).Error)
+
+	require.NoError(t, backfillWordDifficulty(context.Background(), db, func(int, int) {}))
+
+	retrieved, err := db.GetWordByID(word.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 88, retrieved.Difficulty)
+}
+
+func TestMigrations_BackfillDefinitionAttribution(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	word := &entities.Word{Word: "lagniappe", Status: entities.WordStatusEnriched}
+	require.NoError(t, db.AddWord(word))
+	require.NoError(t, db.SaveDefinitions(word.ID, []entities.WordDefinition{
+		{Definition: "a small gift", Source: "freedictionary"},
+		{Definition: "something extra", Source: "manual"},
+	}))
+
+	require.NoError(t, backfillDefinitionAttribution(context.Background(), db, func(int, int) {}))
+
+	retrieved, err := db.GetWordByID(word.ID)
+	require.NoError(t, err)
+	require.Len(t, retrieved.Definitions, 2)
+	assert.Equal(t, "CC BY-SA 3.0", retrieved.Definitions[0].License)
+	assert.NotEmpty(t, retrieved.Definitions[0].Attribution)
+	assert.Empty(t, retrieved.Definitions[1].License)
+}
+
 func TestUpdateWordStatus(t *testing.T) {
 	db, cleanup := setupVocabularyTestDB(t)
 	defer cleanup()
@@ -98,6 +161,40 @@ func TestUpdateWordStatus(t *testing.T) {
 	assert.Equal(t, "API error", retrieved.EnrichmentError)
 }
 
+func TestRequeueFailedWords(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	failed := &entities.Word{Word: "obscure", UserID: 1, Status: entities.WordStatusPending}
+	other := &entities.Word{Word: "arcane", UserID: 2, Status: entities.WordStatusPending}
+	enriched := &entities.Word{Word: "plain", UserID: 1, Status: entities.WordStatusPending}
+	for _, w := range []*entities.Word{failed, other, enriched} {
+		require.NoError(t, db.AddWord(w))
+	}
+	require.NoError(t, db.RecordEnrichmentFailure(failed.ID, 4, entities.WordStatusFailed, "unexpected status: 503"))
+	require.NoError(t, db.RecordEnrichmentFailure(other.ID, 4, entities.WordStatusFailed, "unexpected status: 503"))
+	require.NoError(t, db.UpdateWordStatus(enriched.ID, entities.WordStatusEnriched, ""))
+
+	ids, err := db.RequeueFailedWords(1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{failed.ID}, ids)
+
+	retrieved, err := db.GetWordByID(failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.WordStatusPending, retrieved.Status)
+	assert.Empty(t, retrieved.EnrichmentError)
+	assert.Zero(t, retrieved.EnrichmentAttempts)
+
+	retrieved, err = db.GetWordByID(other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.WordStatusFailed, retrieved.Status, "another user's word is left alone")
+	assert.Equal(t, 4, retrieved.EnrichmentAttempts)
+
+	ids, err = db.RequeueFailedWords(1)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
 func TestDeleteWord(t *testing.T) {
 	db, cleanup := setupVocabularyTestDB(t)
 	defer cleanup()
@@ -259,3 +356,71 @@ func TestGetWordsByHighlight(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, words, 2)
 }
+
+func TestCandidatesExcludedFromVocabulary(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, db.AddWord(&entities.Word{Word: "confirmed", Status: entities.WordStatusPending}))
+	require.NoError(t, db.AddWord(&entities.Word{Word: "suggested", Status: entities.WordStatusCandidate}))
+
+	words, total, err := db.GetAllWords(0, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, words, 1)
+	assert.Equal(t, "confirmed", words[0].Word)
+
+	results, err := db.SearchWords("suggest", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	total, _, _, _, err = db.GetVocabularyStats(0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	candidates, candidateCount, err := db.GetWordsByStatus(0, entities.WordStatusCandidate, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), candidateCount)
+	assert.Equal(t, "suggested", candidates[0].Word)
+}
+
+func TestFindWordByText(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	word := &entities.Word{Word: "Ephemeral", Status: entities.WordStatusCandidate}
+	require.NoError(t, db.AddWord(word))
+
+	found, err := db.FindWordByText("ephemeral", 0)
+	require.NoError(t, err)
+	assert.Equal(t, word.ID, found.ID)
+
+	_, err = db.FindWordByText("numinous", 0)
+	assert.Error(t, err)
+}
+
+func TestGetHighlightsAfterID(t *testing.T) {
+	db, cleanup := setupVocabularyTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{
+		Title:  "Test Book",
+		Author: "Test Author",
+		Highlights: []entities.Highlight{
+			{Text: "First", LocationValue: 1},
+			{Text: "Second", LocationValue: 2},
+			{Text: "Third", LocationValue: 3},
+		},
+	}
+	require.NoError(t, db.SaveBook(book))
+
+	highlights, err := db.GetHighlightsAfterID(book.Highlights[0].ID, 0)
+	require.NoError(t, err)
+	require.Len(t, highlights, 2)
+	assert.Equal(t, "Second", highlights[0].Text)
+	assert.Equal(t, "Test Book", highlights[0].Book.Title)
+
+	highlights, err = db.GetHighlightsAfterID(0, 1)
+	require.NoError(t, err)
+	assert.Len(t, highlights, 1)
+}
@@ -0,0 +1,102 @@
+// Package deeplink builds "open in app" links that take a highlight back to
+// its place in the reading app it was imported from.
+//
+// Each source has a URL template with placeholders for what the source stored
+// about the book and the highlight:
+//
+//	{asin}              Book.ASIN
+//	{isbn}              Book.ISBN
+//	{book_external_id}  Book.ExternalID, e.g. the Apple Books asset ID
+//	{external_id}       Highlight.ExternalID
+//	{location}          Highlight.LocationValue for Kindle locations and pages
+//	{location_ref}      Highlight.LocationRef, e.g. an EPUB CFI
+//
+// A link is only built when every placeholder of the template has a value.
+package deeplink
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// Defaults are the templates of the sources whose apps have a known URL scheme.
+var Defaults = map[string]string{
+	"kindle":      "kindle://book?action=open&asin={asin}&location={location}",
+	"apple_books": "ibooks://assetid/{book_external_id}#{location_ref}",
+}
+
+// Placeholders lists the placeholders templates may use.
+var Placeholders = []string{"asin", "isbn", "book_external_id", "external_id", "location", "location_ref"}
+
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Validate checks that a template only uses known placeholders.
+func Validate(template string) error {
+	for _, m := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		known := false
+		for _, name := range Placeholders {
+			if m[1] == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown placeholder {%s}, expected one of {%s}", m[1], strings.Join(Placeholders, "}, {"))
+		}
+	}
+	return nil
+}
+
+// Build fills in template for a highlight of book. It returns "" when the
+// template is empty or one of its placeholders has no value.
+func Build(template string, book *entities.Book, highlight *entities.Highlight) string {
+	if template == "" {
+		return ""
+	}
+	values := map[string]string{
+		"asin":             book.ASIN,
+		"isbn":             book.ISBN,
+		"book_external_id": book.ExternalID,
+		"external_id":      highlight.ExternalID,
+		"location_ref":     highlight.LocationRef,
+	}
+	switch highlight.LocationType {
+	case entities.LocationTypeLocation, entities.LocationTypePage:
+		if highlight.LocationValue > 0 {
+			values["location"] = strconv.Itoa(highlight.LocationValue)
+		}
+	}
+
+	missing := false
+	link := placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value := values[placeholder[1:len(placeholder)-1]]
+		if value == "" {
+			missing = true
+		}
+		return escape(value)
+	})
+	if missing {
+		return ""
+	}
+	return link
+}
+
+// escape percent-encodes what cannot appear in a URL as is, keeping the
+// punctuation of EPUB CFIs ("/", "!", ",", ":", "[", "]") readable.
+func escape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c <= ' ' || c >= 0x7f, strings.IndexByte(`"#%&+<>?\^{|}`+"`", c) >= 0:
+			fmt.Fprintf(&b, "%%%02X", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package deeplink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestBuild(t *testing.T) {
+	kindleBook := &entities.Book{ASIN: "B00ABC1234"}
+	kindleHighlight := &entities.Highlight{LocationType: entities.LocationTypeLocation, LocationValue: 1234}
+	assert.Equal(t, "kindle://book?action=open&asin=B00ABC1234&location=1234",
+		Build(Defaults["kindle"], kindleBook, kindleHighlight))
+
+	appleBook := &entities.Book{ExternalID: "9F1A2B3C"}
+	appleHighlight := &entities.Highlight{
+		LocationType: entities.LocationTypePosition,
+		LocationRef:  "epubcfi(/6/24[chap05]!/4/2/14,/1:0,/1:120)",
+	}
+	assert.Equal(t, "ibooks://assetid/9F1A2B3C#epubcfi(/6/24[chap05]!/4/2/14,/1:0,/1:120)",
+		Build(Defaults["apple_books"], appleBook, appleHighlight))
+
+	t.Run("missing values", func(t *testing.T) {
+		// No ASIN, e.g. a book imported from My Clippings.txt
+		assert.Empty(t, Build(Defaults["kindle"], &entities.Book{}, kindleHighlight))
+		// Positions are ordinals, not locations of the app
+		assert.Empty(t, Build(Defaults["kindle"], kindleBook, &entities.Highlight{LocationType: entities.LocationTypePosition, LocationValue: 3}))
+		assert.Empty(t, Build("", kindleBook, kindleHighlight))
+	})
+
+	t.Run("escapes values", func(t *testing.T) {
+		link := Build("app://open?id={external_id}", &entities.Book{}, &entities.Highlight{ExternalID: "a b&c#d"})
+		assert.Equal(t, "app://open?id=a%20b%26c%23d", link)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(Defaults["kindle"]))
+	assert.NoError(t, Validate("moonreader://open"))
+	assert.Error(t, Validate("kindle://book?asin={asn}"))
+}
@@ -0,0 +1,253 @@
+package demo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/covers"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+// ErrLibraryNotEmpty is returned when seeding a database that already has books.
+var ErrLibraryNotEmpty = errors.New("library is not empty")
+
+// SeedResult counts what Seed added.
+type SeedResult struct {
+	Books      int `json:"books"`
+	Highlights int `json:"highlights"`
+	Words      int `json:"words"`
+}
+
+// Seeder fills an empty database with the demo dataset: public domain books
+// with highlights and tags, and vocabulary words linked to them.
+type Seeder struct {
+	db         *database.Database
+	olClient   *metadata.OpenLibraryClient
+	coverCache *covers.Cache
+}
+
+// NewSeeder creates a seeder for db.
+func NewSeeder(db *database.Database) *Seeder {
+	return &Seeder{db: db}
+}
+
+// WithMetadata looks up ISBNs, publishers and covers on OpenLibrary while
+// seeding, caching the covers in coverCache if it is not nil.
+func (s *Seeder) WithMetadata(client *metadata.OpenLibraryClient, coverCache *covers.Cache) *Seeder {
+	s.olClient = client
+	s.coverCache = coverCache
+	return s
+}
+
+// Seed adds the demo dataset. It refuses to touch a database with books, so
+// demo data never mixes with a real library.
+func (s *Seeder) Seed() (SeedResult, error) {
+	var result SeedResult
+
+	totalBooks, _, err := s.db.GetStats()
+	if err != nil {
+		return result, fmt.Errorf("failed to count books: %w", err)
+	}
+	if totalBooks > 0 {
+		return result, ErrLibraryNotEmpty
+	}
+
+	tags := s.createTags()
+
+	// Track book IDs for vocabulary linking
+	booksByTitle := make(map[string]uint)
+
+	for _, cfg := range publicDomainBooks() {
+		// Enrich with OpenLibrary metadata before saving
+		if s.olClient != nil {
+			enrichBookFromOpenLibrary(s.olClient, &cfg.Book)
+		}
+
+		if err := s.db.SaveBook(&cfg.Book); err != nil {
+			log.Printf("Failed to save book %s: %v", cfg.Book.Title, err)
+			continue
+		}
+
+		booksByTitle[cfg.Book.Title] = cfg.Book.ID
+		result.Books++
+		result.Highlights += len(cfg.Book.Highlights)
+		log.Printf("Saved: %s by %s (%d highlights)", cfg.Book.Title, cfg.Book.Author, len(cfg.Book.Highlights))
+
+		// Cache the cover image if available
+		if s.coverCache != nil && cfg.Book.CoverURL != "" {
+			if _, err := s.coverCache.GetCover(cfg.Book.UserID, cfg.Book.ID, cfg.Book.CoverURL); err != nil {
+				log.Printf("  Warning: Failed to cache cover: %v", err)
+			}
+		}
+
+		// Add tags to the book using the proper API to avoid duplicates
+		for _, tagName := range cfg.TagNames {
+			if tag, ok := tags[tagName]; ok {
+				if err := s.db.AddTagToBook(cfg.Book.ID, tag.ID); err != nil {
+					log.Printf("Failed to add tag %s to book %s: %v", tagName, cfg.Book.Title, err)
+				}
+			}
+		}
+	}
+
+	// Add vocabulary words linked to books and highlights
+	highlightsByBook := s.buildHighlightLookup(booksByTitle)
+	result.Words = s.addVocabularyWords(booksByTitle, highlightsByBook)
+
+	log.Printf("Demo dataset seeded: %d books, %d highlights, %d words", result.Books, result.Highlights, result.Words)
+	return result, nil
+}
+
+// enrichBookFromOpenLibrary fetches metadata from OpenLibrary and updates the book.
+func enrichBookFromOpenLibrary(client *metadata.OpenLibraryClient, book *entities.Book) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	log.Printf("Fetching metadata for: %s by %s...", book.Title, book.Author)
+
+	meta, err := client.SearchByTitle(ctx, book.Title, book.Author)
+	if err != nil {
+		log.Printf("  Warning: OpenLibrary lookup failed: %v", err)
+		return
+	}
+
+	// Update book with fetched metadata (only fill empty fields)
+	if book.ISBN == "" && meta.ISBN != "" {
+		book.ISBN = meta.ISBN
+	}
+	if book.CoverURL == "" && meta.CoverURL != "" {
+		book.CoverURL = meta.CoverURL
+	}
+	if book.Publisher == "" && meta.Publisher != "" {
+		book.Publisher = meta.Publisher
+	}
+	// Keep our hardcoded publication year for ancient texts (OpenLibrary may have different editions)
+	if book.PublicationYear == 0 && meta.PublicationYear > 0 {
+		book.PublicationYear = meta.PublicationYear
+	}
+}
+
+// buildHighlightLookup creates a map of book title -> list of highlight IDs.
+func (s *Seeder) buildHighlightLookup(booksByTitle map[string]uint) map[string][]uint {
+	result := make(map[string][]uint)
+
+	for title, bookID := range booksByTitle {
+		book, err := s.db.GetBookByID(bookID)
+		if err != nil {
+			continue
+		}
+		var highlightIDs []uint
+		for _, h := range book.Highlights {
+			highlightIDs = append(highlightIDs, h.ID)
+		}
+		result[title] = highlightIDs
+	}
+
+	return result
+}
+
+func (s *Seeder) createTags() map[string]entities.Tag {
+	tagNames := []string{
+		"philosophy",
+		"fiction",
+		"classic",
+		"science",
+	}
+
+	tags := make(map[string]entities.Tag)
+	for _, name := range tagNames {
+		tag, err := s.db.GetOrCreateTag(name, 0) // userID 0 for demo
+		if err != nil {
+			log.Printf("Failed to create tag %s: %v", name, err)
+			continue
+		}
+		tags[name] = *tag
+	}
+	return tags
+}
+
+// addVocabularyWords adds the demo vocabulary, returning how many words it added.
+func (s *Seeder) addVocabularyWords(booksByTitle map[string]uint, highlightsByBook map[string][]uint) int {
+	added := 0
+	for _, w := range vocabularyWords {
+		word := &entities.Word{
+			Word:    w.word,
+			Status:  w.status,
+			Context: w.context,
+		}
+
+		// Link to source book if available
+		if w.sourceBook != "" {
+			if bookID, ok := booksByTitle[w.sourceBook]; ok {
+				word.BookID = &bookID
+				word.SourceBookTitle = w.sourceBook
+
+				// Get author from book
+				book, err := s.db.GetBookByID(bookID)
+				if err == nil {
+					word.SourceBookAuthor = book.Author
+				}
+
+				// Link to specific highlight if available
+				if highlights, ok := highlightsByBook[w.sourceBook]; ok && len(highlights) > w.highlightID {
+					highlightID := highlights[w.highlightID]
+					word.HighlightID = &highlightID
+					word.SourceHighlightText = w.context
+				}
+			}
+		}
+
+		if err := s.db.AddWord(word); err != nil {
+			log.Printf("Failed to add word %s: %v", w.word, err)
+			continue
+		}
+		added++
+
+		if w.status == entities.WordStatusEnriched && w.definition != "" {
+			defs := []entities.WordDefinition{
+				{
+					WordID:       word.ID,
+					PartOfSpeech: w.pos,
+					Definition:   w.definition,
+					Example:      w.example,
+				},
+			}
+			if err := s.db.SaveDefinitions(word.ID, defs); err != nil {
+				log.Printf("Failed to save definition for %s: %v", w.word, err)
+			}
+		}
+	}
+	return added
+}
+
+// SeedTempDatabase creates a database in a new temporary directory and seeds
+// it, returning the database path and a function removing the directory.
+// Used to run the server in demo mode without a prepared database.
+func SeedTempDatabase() (dbPath string, cleanup func(), err error) {
+	tempDir, err := os.MkdirTemp("", "assistant-demo-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("create temp directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(tempDir) }
+
+	dbPath = filepath.Join(tempDir, "demo.db")
+	db, err := database.NewDatabase(dbPath)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("create demo database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := NewSeeder(db).Seed(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return dbPath, cleanup, nil
+}
@@ -0,0 +1,50 @@
+package demo
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+)
+
+func TestSeeder_Seed(t *testing.T) {
+	db, err := database.NewDatabase(filepath.Join(t.TempDir(), "demo.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	result, err := NewSeeder(db).Seed()
+	require.NoError(t, err)
+	assert.Equal(t, len(publicDomainBooks()), result.Books)
+	assert.Positive(t, result.Highlights)
+	assert.Equal(t, len(vocabularyWords), result.Words)
+
+	books, highlights, err := db.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(result.Books), books)
+	assert.Equal(t, int64(result.Highlights), highlights)
+
+	tags, err := db.GetTagsForUser(0)
+	require.NoError(t, err)
+	assert.Len(t, tags, 4)
+
+	// Seeding again would mix demo data into the library
+	_, err = NewSeeder(db).Seed()
+	assert.ErrorIs(t, err, ErrLibraryNotEmpty)
+}
+
+func TestSeedTempDatabase(t *testing.T) {
+	dbPath, cleanup, err := SeedTempDatabase()
+	require.NoError(t, err)
+	defer cleanup()
+
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	books, _, err := db.GetStats()
+	require.NoError(t, err)
+	assert.Positive(t, books)
+}
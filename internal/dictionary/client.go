@@ -2,10 +2,19 @@ package dictionary
 
 import (
 	"context"
+	"errors"
 
 	"github.com/mrlokans/assistant/internal/entities"
 )
 
+// ErrWordNotFound is returned when the dictionary has no entry for a word.
+// Unlike network or server errors, looking the word up again will not help.
+var ErrWordNotFound = errors.New("word not found")
+
+// ErrUnsupportedLanguage is returned when a provider has no dictionary for the
+// language a word was looked up in.
+var ErrUnsupportedLanguage = errors.New("language not supported by dictionary")
+
 // LookupResult contains the result of a dictionary lookup.
 type LookupResult struct {
 	Word          string
@@ -15,7 +24,9 @@ type LookupResult struct {
 }
 
 // Client defines the interface for dictionary API providers.
+// Lookup takes the ISO 639-1 code of the language the word was read in, such
+// as the language of its book, or "" when it is not known.
 type Client interface {
-	Lookup(ctx context.Context, word string) (*LookupResult, error)
+	Lookup(ctx context.Context, word, language string) (*LookupResult, error)
 	Name() string
 }
@@ -53,14 +53,18 @@ func NewFreeDictionaryClient() *FreeDictionaryClient {
 }
 
 func (c *FreeDictionaryClient) Name() string {
-	return "freedictionary"
+	return ProviderFreeDictionary
 }
 
-// Lookup fetches word definitions from the Free Dictionary API.
-func (c *FreeDictionaryClient) Lookup(ctx context.Context, word string) (*LookupResult, error) {
+// Lookup fetches word definitions from the Free Dictionary API, which only
+// has English entries. Words in other languages fail with ErrUnsupportedLanguage.
+func (c *FreeDictionaryClient) Lookup(ctx context.Context, word, language string) (*LookupResult, error) {
 	word = strings.TrimSpace(strings.ToLower(word))
 	if word == "" {
-		return nil, fmt.Errorf("empty word")
+		return nil, fmt.Errorf("%w: empty word", ErrWordNotFound)
+	}
+	if language != "" && language != "en" {
+		return nil, fmt.Errorf("%w: %s has no %q entries", ErrUnsupportedLanguage, ProviderFreeDictionary, language)
 	}
 
 	c.rateLimiter.wait()
@@ -79,7 +83,7 @@ func (c *FreeDictionaryClient) Lookup(ctx context.Context, word string) (*Lookup
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("word not found: %s", word)
+		return nil, fmt.Errorf("%w: %s", ErrWordNotFound, word)
 	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
@@ -91,7 +95,7 @@ func (c *FreeDictionaryClient) Lookup(ctx context.Context, word string) (*Lookup
 	}
 
 	if len(apiResponse) == 0 {
-		return nil, fmt.Errorf("empty response for word: %s", word)
+		return nil, fmt.Errorf("%w: empty response for %s", ErrWordNotFound, word)
 	}
 
 	return c.convertToLookupResult(word, apiResponse[0]), nil
@@ -112,6 +116,17 @@ func (c *FreeDictionaryClient) convertToLookupResult(word string, resp freeDicti
 		}
 	}
 
+	// Credit the entry's own source and license, which the API passes on from Wiktionary
+	info, _ := Info(ProviderFreeDictionary)
+	license, licenseURL := info.License, info.LicenseURL
+	if resp.License.Name != "" {
+		license, licenseURL = resp.License.Name, resp.License.URL
+	}
+	var sourceURL string
+	if len(resp.SourceURLs) > 0 {
+		sourceURL = resp.SourceURLs[0]
+	}
+
 	// Extract definitions from meanings
 	for _, meaning := range resp.Meanings {
 		for _, def := range meaning.Definitions {
@@ -121,7 +136,11 @@ func (c *FreeDictionaryClient) convertToLookupResult(word string, resp freeDicti
 				Example:       def.Example,
 				Pronunciation: result.Pronunciation,
 				AudioURL:      result.AudioURL,
-				Source:        "freedictionary",
+				Source:        ProviderFreeDictionary,
+				Attribution:   info.Attribution,
+				License:       license,
+				LicenseURL:    licenseURL,
+				SourceURL:     sourceURL,
 			}
 			result.Definitions = append(result.Definitions, wordDef)
 		}
@@ -133,9 +152,16 @@ func (c *FreeDictionaryClient) convertToLookupResult(word string, resp freeDicti
 // Free Dictionary API response types
 
 type freeDictionaryResponse struct {
-	Word      string             `json:"word"`
-	Phonetics []freeDictPhonetic `json:"phonetics"`
-	Meanings  []freeDictMeaning  `json:"meanings"`
+	Word       string             `json:"word"`
+	Phonetics  []freeDictPhonetic `json:"phonetics"`
+	Meanings   []freeDictMeaning  `json:"meanings"`
+	License    freeDictLicense    `json:"license"`
+	SourceURLs []string           `json:"sourceUrls"`
+}
+
+type freeDictLicense struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
 }
 
 type freeDictPhonetic struct {
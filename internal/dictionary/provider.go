@@ -0,0 +1,77 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ProviderFreeDictionary is the name of the Free Dictionary API provider.
+const ProviderFreeDictionary = "freedictionary"
+
+// Providers lists the provider names accepted by NewClient.
+var Providers = []string{ProviderFreeDictionary}
+
+// ErrProviderDisabled is returned by lookups through a provider that was
+// turned off, e.g. because its terms do not allow how definitions are used.
+var ErrProviderDisabled = errors.New("dictionary provider is disabled")
+
+// ProviderInfo describes where a provider's definitions come from, the terms
+// they may be reused under and the languages it covers.
+type ProviderInfo struct {
+	Name        string   `json:"name"`
+	Label       string   `json:"label"`
+	Homepage    string   `json:"homepage"`
+	Attribution string   `json:"attribution"` // Credit to show next to the definitions
+	License     string   `json:"license"`
+	LicenseURL  string   `json:"license_url"`
+	Languages   []string `json:"languages"` // ISO 639-1 codes of the languages words can be looked up in
+}
+
+var providerInfos = map[string]ProviderInfo{
+	ProviderFreeDictionary: {
+		Name:        ProviderFreeDictionary,
+		Label:       "Free Dictionary API",
+		Homepage:    "https://dictionaryapi.dev/",
+		Attribution: "Wiktionary contributors, via the Free Dictionary API",
+		License:     "CC BY-SA 3.0",
+		LicenseURL:  "https://creativecommons.org/licenses/by-sa/3.0",
+		Languages:   []string{"en"},
+	},
+}
+
+// Info returns the description of the provider with the given name.
+func Info(name string) (ProviderInfo, bool) {
+	info, ok := providerInfos[name]
+	return info, ok
+}
+
+// NewClient creates the dictionary client with the given provider name.
+func NewClient(name string) (Client, error) {
+	switch name {
+	case ProviderFreeDictionary:
+		return NewFreeDictionaryClient(), nil
+	default:
+		return nil, fmt.Errorf("unknown dictionary provider %q (available: %s)", name, strings.Join(Providers, ", "))
+	}
+}
+
+// WithEnabled wraps client so lookups fail with ErrProviderDisabled while
+// enabled reports its provider as turned off. enabled is checked on every
+// lookup, so the provider can be turned on and off at runtime.
+func WithEnabled(client Client, enabled func(provider string) bool) Client {
+	return &gatedClient{Client: client, enabled: enabled}
+}
+
+type gatedClient struct {
+	Client
+	enabled func(provider string) bool
+}
+
+func (c *gatedClient) Lookup(ctx context.Context, word, language string) (*LookupResult, error) {
+	if !c.enabled(c.Name()) {
+		return nil, fmt.Errorf("%w: %s", ErrProviderDisabled, c.Name())
+	}
+	return c.Client.Lookup(ctx, word, language)
+}
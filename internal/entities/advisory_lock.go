@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+// Advisory lock names. Imports and exports each take their lock for the whole
+// run, so replicas and CLI commands sharing a database take turns.
+const (
+	LockImport = "import"
+	LockExport = "export"
+)
+
+// LockNames lists every advisory lock.
+var LockNames = []string{LockImport, LockExport}
+
+// AdvisoryLock is a lock held in the database, shared by every process using
+// it. The row exists only while the lock is held; a lock whose holder stopped
+// sending heartbeats can be taken over.
+type AdvisoryLock struct {
+	Name        string    `gorm:"primaryKey;size:64" json:"name"`
+	Owner       string    `gorm:"size:160" json:"owner"`
+	Operation   string    `gorm:"size:255" json:"operation,omitempty"` // What the holder is doing, e.g. "kindle import"
+	AcquiredAt  time.Time `json:"acquired_at"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+}
+
+func (AdvisoryLock) TableName() string {
+	return "advisory_locks"
+}
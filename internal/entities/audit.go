@@ -8,6 +8,7 @@ const (
 	AuditEventImport         AuditEventType = "import"
 	AuditEventExport         AuditEventType = "export"
 	AuditEventDelete         AuditEventType = "delete"
+	AuditEventRestore        AuditEventType = "restore"
 	AuditEventMetadataEnrich AuditEventType = "metadata_enrich"
 	AuditEventSync           AuditEventType = "sync"
 	AuditEventAuth           AuditEventType = "auth"
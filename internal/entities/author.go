@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// Author is a person books are linked to, identified per user by a canonical
+// name. Books keep the author name their source gave them; AuthorID links them
+// to the shared record, which is enriched from Wikidata.
+type Author struct {
+	ID           uint          `gorm:"primaryKey" json:"id"`
+	UserID       uint          `gorm:"uniqueIndex:idx_author_user_name" json:"user_id"`
+	Name         string        `gorm:"uniqueIndex:idx_author_user_name;size:256" json:"name"` // Canonical name
+	WikidataID   string        `gorm:"index;size:32" json:"wikidata_id,omitempty"`
+	PhotoURL     string        `gorm:"size:2048" json:"photo_url,omitempty"`
+	BirthYear    int           `json:"birth_year,omitempty"`
+	DeathYear    int           `json:"death_year,omitempty"`
+	Bio          string        `gorm:"type:text" json:"bio,omitempty"`
+	WikipediaURL string        `gorm:"size:2048" json:"wikipedia_url,omitempty"`
+	EnrichedAt   *time.Time    `json:"enriched_at,omitempty"` // Last Wikidata lookup, also when nothing was found
+	Aliases      []AuthorAlias `gorm:"foreignKey:AuthorID" json:"aliases,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+func (Author) TableName() string {
+	return "authors"
+}
+
+// AuthorAlias is another name an author is known by, e.g. "Tolstoy, Leo" for
+// Leo Tolstoy. Books whose author matches an alias are linked to the author.
+type AuthorAlias struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	AuthorID uint   `gorm:"uniqueIndex:idx_author_alias_name" json:"author_id"`
+	Name     string `gorm:"uniqueIndex:idx_author_alias_name;size:256" json:"name"`
+}
+
+func (AuthorAlias) TableName() string {
+	return "author_aliases"
+}
+
+// AuthorSummary is an author together with the number of books linked to them.
+type AuthorSummary struct {
+	Author    Author `json:"author"`
+	BookCount int64  `json:"book_count"`
+}
+
+// nameSuffixes are parts after a comma that belong to the name rather than
+// being a first name, as in "Martin Luther King, Jr."
+var nameSuffixes = map[string]bool{
+	"jr": true, "jr.": true, "sr": true, "sr.": true,
+	"ii": true, "iii": true, "iv": true, "phd": true, "ph.d.": true,
+}
+
+// NormalizeAuthorName returns the form an author name is stored and matched
+// in: surrounding and repeated whitespace removed, and "Last, First" as
+// "First Last". Names with several commas or a suffix are left as written.
+func NormalizeAuthorName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	last, first, ok := strings.Cut(name, ",")
+	if !ok || strings.Contains(first, ",") {
+		return name
+	}
+	last, first = strings.TrimSpace(last), strings.TrimSpace(first)
+	if last == "" || first == "" || nameSuffixes[strings.ToLower(first)] {
+		return name
+	}
+	return first + " " + last
+}
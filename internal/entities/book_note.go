@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// BookNote is a dated journal entry about a book as a whole, such as thoughts
+// after a reading session, rather than a note on one highlight.
+type BookNote struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	BookID    uint      `gorm:"index" json:"book_id"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	Date      time.Time `json:"date"`                  // The day the entry is about, at midnight UTC
+	Text      string    `gorm:"type:text" json:"text"` // Markdown
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (BookNote) TableName() string {
+	return "book_notes"
+}
@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+// Collection is a user-defined, ordered list of books, such as "2024 reading"
+// or "Stoicism starter pack". Unlike tags, collections keep their books in the
+// order the user arranged them.
+type Collection struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"uniqueIndex:idx_collection_user_name" json:"user_id"`
+	Name        string    `gorm:"uniqueIndex:idx_collection_user_name;size:100" json:"name"`
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	Books       []Book    `gorm:"-" json:"books,omitempty"` // In collection order, when loaded
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// CollectionBook places a book in a collection. It is the join table of
+// Book.Collections, with the book's position in the collection.
+type CollectionBook struct {
+	CollectionID uint      `gorm:"primaryKey" json:"collection_id"`
+	BookID       uint      `gorm:"primaryKey;index" json:"book_id"`
+	Position     int       `gorm:"not null;default:0" json:"position"` // 0-based order within the collection
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (CollectionBook) TableName() string {
+	return "collection_books"
+}
+
+// CollectionSummary is a collection together with the number of books in it.
+type CollectionSummary struct {
+	Collection Collection `json:"collection"`
+	BookCount  int64      `json:"book_count"`
+}
@@ -0,0 +1,37 @@
+package entities
+
+import "time"
+
+// Export target run statuses
+const (
+	ExportTargetStatusSuccess = "success"
+	ExportTargetStatusFailed  = "failed"
+)
+
+// ExportTarget is a named destination for file exports, such as an Obsidian
+// vault, a Logseq graph or a plain folder. Each target has its own format and
+// filters, and can run on its own cron schedule or on demand.
+type ExportTarget struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	Name              string     `gorm:"size:100;uniqueIndex" json:"name"`
+	Path              string     `gorm:"size:1024" json:"path"`
+	Format            string     `gorm:"size:20" json:"format"`                        // One of exporters.Formats
+	FavoritesOnly     bool       `json:"favorites_only"`                               // Only export favourite highlights
+	Tags              []string   `gorm:"serializer:json" json:"tags,omitempty"`        // Only books or highlights with any of these tags
+	Collections       []string   `gorm:"serializer:json" json:"collections,omitempty"` // Only books in any of these collections
+	Sources           []string   `gorm:"serializer:json" json:"sources,omitempty"`     // Only highlights from these sources
+	Since             *time.Time `json:"since,omitempty"`                              // Only highlights made at or after this time
+	IncludeVocabulary bool       `json:"include_vocabulary"`                           // Also write the vocabulary list
+	Schedule          string     `gorm:"size:100" json:"schedule,omitempty"`           // Cron schedule; empty runs only on demand
+
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+	LastStatus  string     `gorm:"size:20" json:"last_status,omitempty"` // ExportTargetStatusSuccess or ExportTargetStatusFailed
+	LastMessage string     `gorm:"type:text" json:"last_message,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ExportTarget) TableName() string {
+	return "export_targets"
+}
@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// HighlightCategory is a coarse kind of highlight assigned by a classifier.
+type HighlightCategory string
+
+const (
+	HighlightCategoryAdvice     HighlightCategory = "advice"     // Tells the reader what to do
+	HighlightCategoryDefinition HighlightCategory = "definition" // Explains what a term means
+	HighlightCategoryQuote      HighlightCategory = "quote"      // Words attributed to someone
+	HighlightCategoryDataPoint  HighlightCategory = "data_point" // A figure, statistic or date
+)
+
+// HighlightCategories lists the supported categories
+var HighlightCategories = []HighlightCategory{
+	HighlightCategoryAdvice,
+	HighlightCategoryDefinition,
+	HighlightCategoryQuote,
+	HighlightCategoryDataPoint,
+}
+
+// Label returns the category as shown to readers, e.g. "data point".
+func (c HighlightCategory) Label() string {
+	return strings.ReplaceAll(string(c), "_", " ")
+}
+
+// Valid reports whether c is one of HighlightCategories.
+func (c HighlightCategory) Valid() bool {
+	for _, category := range HighlightCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// HighlightCategoryTag is a system tag: a category assigned to a highlight by
+// the classifier. They are kept apart from user tags and replaced each time
+// the highlight is classified.
+type HighlightCategoryTag struct {
+	ID          uint              `gorm:"primaryKey" json:"-"`
+	HighlightID uint              `gorm:"uniqueIndex:idx_highlight_category" json:"-"`
+	Category    HighlightCategory `gorm:"uniqueIndex:idx_highlight_category;index;size:20" json:"category"`
+	Classifier  string            `gorm:"size:50" json:"classifier"` // Backend that assigned it, e.g. "rules"
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+func (HighlightCategoryTag) TableName() string {
+	return "highlight_categories"
+}
@@ -0,0 +1,13 @@
+package entities
+
+// HighlightDuplicate pairs two highlights of the same book, imported from
+// different sources, whose text is nearly the same. Keep is the richer record
+// a merge would keep; Duplicate is merged into it and removed.
+type HighlightDuplicate struct {
+	BookID     uint      `json:"book_id"`
+	BookTitle  string    `json:"book_title"`
+	BookAuthor string    `json:"book_author"`
+	Similarity float64   `json:"similarity"` // 0-1, share of words in common after normalization
+	Keep       Highlight `json:"keep"`
+	Duplicate  Highlight `json:"duplicate"`
+}
@@ -0,0 +1,34 @@
+package entities
+
+import "time"
+
+// Orders of highlight listings
+const (
+	HighlightSortRecent    = "recent"    // Most recently highlighted first
+	HighlightSortImported  = "imported"  // Most recently imported first
+	HighlightSortTitle     = "title"     // By book title, each book in reading order
+	HighlightSortPublished = "published" // Books by newest publication year first, each in reading order
+)
+
+var HighlightSorts = []string{HighlightSortRecent, HighlightSortImported, HighlightSortTitle, HighlightSortPublished}
+
+// HighlightFilter narrows a highlight listing. Zero values leave a criterion unset.
+type HighlightFilter struct {
+	UserID    uint
+	BookID    uint
+	Source    string            // Source name, e.g. "kindle"
+	Colors    []string          // Highlights stored with any of these colors
+	Query     string            // Text or note containing this, ignoring case
+	TagIDs    []uint            // Highlights with any of these tags
+	Tags      []string          // Highlights or their books with any of these tag names, ignoring case
+	Favourite *bool             // Only favourites, or only non-favourites
+	HasNote   *bool             // Only highlights with a note, or only those without
+	Category  HighlightCategory // Highlights the classifier put in this category
+	From      *time.Time        // Highlighted at or after
+	To        *time.Time        // Highlighted before
+	Sort      string            // One of HighlightSorts, HighlightSortRecent when unset
+
+	// IncludeArchived also lists highlights of archived books, which are
+	// otherwise left out unless BookID is set
+	IncludeArchived bool
+}
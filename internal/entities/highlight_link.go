@@ -0,0 +1,40 @@
+package entities
+
+import "time"
+
+// HighlightLinkType describes how one highlight relates to another.
+type HighlightLinkType string
+
+const (
+	HighlightLinkSupports    HighlightLinkType = "supports"    // Backs up the linked highlight
+	HighlightLinkContradicts HighlightLinkType = "contradicts" // Argues against the linked highlight
+	HighlightLinkSeeAlso     HighlightLinkType = "see_also"    // Related without taking a side
+)
+
+// HighlightLinkTypes lists the supported link types
+var HighlightLinkTypes = []HighlightLinkType{HighlightLinkSupports, HighlightLinkContradicts, HighlightLinkSeeAlso}
+
+// Label returns the link type as shown to readers, e.g. "see also".
+func (t HighlightLinkType) Label() string {
+	if t == HighlightLinkSeeAlso {
+		return "see also"
+	}
+	return string(t)
+}
+
+// HighlightLink is a typed cross-reference from one highlight to another,
+// possibly in a different book. Links are directed: "A supports B" is stored
+// on A and shown as a backlink on B.
+type HighlightLink struct {
+	ID              uint              `gorm:"primaryKey" json:"id"`
+	FromHighlightID uint              `gorm:"uniqueIndex:idx_highlight_link" json:"from_highlight_id"`
+	ToHighlightID   uint              `gorm:"uniqueIndex:idx_highlight_link;index" json:"to_highlight_id"`
+	Type            HighlightLinkType `gorm:"uniqueIndex:idx_highlight_link;size:20" json:"type"`
+	From            *Highlight        `gorm:"foreignKey:FromHighlightID" json:"from,omitempty"`
+	To              *Highlight        `gorm:"foreignKey:ToHighlightID" json:"to,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+func (HighlightLink) TableName() string {
+	return "highlight_links"
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+)
+
+// HighlightVersionReason describes why a highlight version was recorded.
+type HighlightVersionReason string
+
+const (
+	HighlightVersionReasonEdit     HighlightVersionReason = "edit"     // Changed via UpdateHighlight
+	HighlightVersionReasonReimport HighlightVersionReason = "reimport" // Overwritten by a re-import
+	HighlightVersionReasonRevert   HighlightVersionReason = "revert"   // Replaced by reverting to an older version
+	HighlightVersionReasonSource   HighlightVersionReason = "source"   // Source copy that was not applied because of local edits
+	HighlightVersionReasonMerge    HighlightVersionReason = "merge"    // Note changed by merging a duplicate into the highlight
+)
+
+// HighlightVersion stores a previous version of a highlight's text and note.
+// A version is recorded each time the text or note is about to be overwritten,
+// and when a re-import brings changes that conflict with local edits.
+type HighlightVersion struct {
+	ID          uint                   `gorm:"primaryKey" json:"id"`
+	HighlightID uint                   `gorm:"index" json:"highlight_id"`
+	Text        string                 `gorm:"type:text" json:"text"`
+	Note        string                 `gorm:"type:text" json:"note,omitempty"`
+	Reason      HighlightVersionReason `gorm:"size:20" json:"reason"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+func (HighlightVersion) TableName() string {
+	return "highlight_versions"
+}
+
+// HighlightConflict pairs a locally edited highlight with a diverging copy received from its source.
+type HighlightConflict struct {
+	Highlight     Highlight        `json:"highlight"`
+	BookTitle     string           `json:"book_title"`
+	BookAuthor    string           `json:"book_author"`
+	SourceVersion HighlightVersion `json:"source_version"` // Revert to it to accept the source copy
+}
@@ -1,6 +1,9 @@
 package entities
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -61,6 +64,9 @@ type User struct {
 	TokenHash      string         `gorm:"index;size:64" json:"-"` // Hashed token for secure storage
 	TokenCreatedAt *time.Time     `json:"-"`                      // When the current token was generated
 	LastLoginAt    *time.Time     `json:"last_login_at,omitempty"`
+	Timezone       string         `gorm:"size:64" json:"timezone,omitempty"` // IANA zone such as "Europe/Berlin", empty for the default
+	Locale         string         `gorm:"size:35" json:"locale,omitempty"`   // BCP 47 tag such as "en-GB", empty for the default
+	Language       string         `gorm:"size:10" json:"language,omitempty"` // UI language such as "de", empty to follow the browser
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -68,18 +74,37 @@ type User struct {
 	// Security tracking for account lockout
 	FailedLoginCount int        `gorm:"default:0" json:"-"`
 	LockedUntil      *time.Time `json:"-"`
+
+	// Two-factor authentication (TOTP)
+	TOTPSecret        string `gorm:"size:255" json:"-"`                  // Encrypted when a token encryption key is configured
+	TOTPEnabled       bool   `gorm:"default:false" json:"totp_enabled"`  // Set once enrollment is confirmed
+	TOTPRequired      bool   `gorm:"default:false" json:"totp_required"` // Enforced by an admin; user must enroll at next login
+	TOTPRecoveryCodes string `gorm:"type:text" json:"-"`                 // Comma-separated SHA-256 hashes of unused recovery codes
+	TOTPLastUsedStep  int64  `json:"-"`                                  // Time step of the last accepted code, prevents replay
 }
 
 type Book struct {
 	ID              uint           `gorm:"primaryKey" json:"id"`
-	UserID          uint           `gorm:"index" json:"user_id"`
-	Title           string         `gorm:"index;size:512" json:"title"`
-	Author          string         `gorm:"index;size:256" json:"author"`
-	ISBN            string         `gorm:"index;size:20" json:"isbn,omitempty"`
+	UserID          uint           `gorm:"index;index:idx_books_user_title_author,priority:1" json:"user_id"`
+	Title           string         `gorm:"index;index:idx_books_user_title_author,priority:2;size:512" json:"title"`
+	Author          string         `gorm:"index;index:idx_books_user_title_author,priority:3;size:256" json:"author"`
+	AuthorID        uint           `gorm:"index" json:"author_id,omitempty"`    // Linked Author record, 0 until linked
+	ISBN            string         `gorm:"index;size:20" json:"isbn,omitempty"` // ISBN-13 when valid, otherwise as imported
+	ISBN10          string         `gorm:"size:10" json:"isbn10,omitempty"`     // ISBN-10 form of the ISBN, empty for 979 ISBNs
 	ASIN            string         `gorm:"size:20" json:"asin,omitempty"`
 	CoverURL        string         `gorm:"size:2048" json:"cover_url,omitempty"`
 	Publisher       string         `gorm:"size:256" json:"publisher,omitempty"`
 	PublicationYear int            `json:"publication_year,omitempty"`
+	Series          string         `gorm:"index;size:256" json:"series,omitempty"`
+	SeriesIndex     float64        `json:"series_index,omitempty"`                  // Position in the series, 0 when unknown; fractional for in-between novellas
+	URL             string         `gorm:"size:2048" json:"url,omitempty"`          // Address of an article from a web source such as Instapaper or a browser clip
+	PublishedAt     *time.Time     `json:"published_at,omitempty"`                  // When the article was published, if known
+	Rating          float64        `json:"rating,omitempty"`                        // 0-5 stars from Goodreads/StoryGraph, 0 when unrated
+	Language        string         `gorm:"index;size:10" json:"language,omitempty"` // ISO 639-1 code detected from the highlights, empty until detected
+	DateRead        *time.Time     `json:"date_read,omitempty"`                     // When the book was last finished
+	IsFavorite      bool           `gorm:"index;default:false" json:"is_favorite"`  // Pinned to the top of the library
+	IsPublic        bool           `gorm:"index;default:false" json:"is_public"`    // Shared on the public library
+	IsArchived      bool           `gorm:"index;default:false" json:"is_archived"`  // Kept out of lists, search, exports and stats
 	FilePath        string         `gorm:"size:1024" json:"file_path,omitempty"`
 	FileHash        string         `gorm:"index;size:64" json:"file_hash,omitempty"`
 	ExternalID      string         `gorm:"size:256" json:"external_id,omitempty"`
@@ -88,35 +113,52 @@ type Book struct {
 	User            User           `gorm:"foreignKey:UserID" json:"-"`
 	Highlights      []Highlight    `gorm:"foreignKey:BookID" json:"highlights,omitempty"`
 	Tags            []Tag          `gorm:"many2many:book_tags;" json:"tags,omitempty"`
+	Collections     []Collection   `gorm:"many2many:collection_books;" json:"collections,omitempty"`
+	Notes           []BookNote     `gorm:"foreignKey:BookID" json:"notes,omitempty"` // Reading journal, oldest first
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
+	// Kept up to date by the database as highlights are saved, favourited and
+	// deleted, so lists can show and sort by them without loading highlights.
+	// Highlights in the trash are not counted.
+	HighlightCount    int        `gorm:"default:0" json:"highlight_count"`
+	FavouriteCount    int        `gorm:"default:0" json:"favourite_count"`
+	LastHighlightedAt *time.Time `gorm:"index" json:"last_highlighted_at,omitempty"` // When the latest highlight was made
+
 	// Deprecated: Use FilePath instead. Kept for backward compatibility.
 	File string `gorm:"size:1024" json:"file,omitempty"`
 }
 
+// Series groups the books of a series in reading order.
+type Series struct {
+	Name  string `json:"name"`
+	Books []Book `json:"books"`
+}
+
 type Highlight struct {
 	ID     uint   `gorm:"primaryKey" json:"id"`
-	BookID uint   `gorm:"index" json:"book_id"`
-	UserID uint   `gorm:"index" json:"user_id"`
+	BookID uint   `gorm:"index;index:idx_highlights_book_location,priority:1" json:"book_id"`
+	UserID uint   `gorm:"index;index:idx_highlights_user_highlighted,priority:1" json:"user_id"`
 	Text   string `gorm:"type:text" json:"text"`
 	Note   string `gorm:"type:text" json:"note,omitempty"`
 
 	// Location information
 	LocationType  LocationType `gorm:"size:20;default:'page'" json:"location_type"`
-	LocationValue int          `json:"location_value,omitempty"`
+	LocationValue int          `gorm:"index:idx_highlights_book_location,priority:2" json:"location_value,omitempty"`
 	LocationEnd   int          `json:"location_end,omitempty"` // For ranges
 	Percent       float64      `json:"percent,omitempty"`      // 0.0-1.0 position
 	Chapter       string       `gorm:"size:256" json:"chapter,omitempty"`
+	LocationRef   string       `gorm:"size:512" json:"location_ref,omitempty"` // Location as the source app stores it, e.g. an EPUB CFI, for deep links
+	URL           string       `gorm:"size:2048" json:"url,omitempty"`         // Link to the highlight on its web page, e.g. with a #:~:text= fragment
 
 	// Styling
 	Color string         `gorm:"size:10" json:"color,omitempty"` // Hex color code
 	Style HighlightStyle `gorm:"size:20;default:'highlight'" json:"style,omitempty"`
 
 	// Metadata
-	HighlightedAt time.Time `json:"highlighted_at,omitempty"` // When user made the highlight
-	IsFavorite    bool      `gorm:"default:false" json:"is_favorite"`
+	HighlightedAt time.Time `gorm:"index;index:idx_highlights_user_highlighted,priority:2" json:"highlighted_at,omitempty"` // When user made the highlight
+	IsFavorite    bool      `gorm:"index;default:false" json:"is_favorite"`
 	IsDiscarded   bool      `gorm:"default:false" json:"is_discarded"`
 
 	// Context (W3C Web Annotation inspired)
@@ -127,12 +169,24 @@ type Highlight struct {
 	ExternalID string `gorm:"size:256" json:"external_id,omitempty"`
 	SourceID   uint   `gorm:"index" json:"source_id"`
 	Source     Source `gorm:"foreignKey:SourceID" json:"source,omitempty"`
+	OriginHash string `gorm:"size:64" json:"-"` // Fingerprint of text + note as last received from the source
+
+	// Identity used to deduplicate imports, see HighlightContentHash. Set from the
+	// source text and kept when the highlight is edited locally.
+	ContentHash string `gorm:"index;size:64" json:"-"`
 
 	// Relationships
 	Book Book  `gorm:"foreignKey:BookID" json:"-"`
 	User User  `gorm:"foreignKey:UserID" json:"-"`
 	Tags []Tag `gorm:"many2many:highlight_tags;" json:"tags,omitempty"`
 
+	// Cross-references to and from other highlights, when loaded
+	Links     []HighlightLink `gorm:"foreignKey:FromHighlightID" json:"links,omitempty"`
+	Backlinks []HighlightLink `gorm:"foreignKey:ToHighlightID" json:"backlinks,omitempty"`
+
+	// Categories assigned by the classifier, when loaded
+	Categories []HighlightCategoryTag `gorm:"foreignKey:HighlightID" json:"categories,omitempty"`
+
 	// Timestamps
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -144,6 +198,36 @@ type Highlight struct {
 	Page int `json:"page,omitempty"`
 }
 
+// HighlightOriginHash fingerprints a highlight's text and note for Highlight.OriginHash.
+func HighlightOriginHash(text, note string) string {
+	sum := sha256.Sum256([]byte(text + "\x00" + note))
+	return hex.EncodeToString(sum[:])
+}
+
+// contentNormalizer folds typographic variants that differ between sources.
+var contentNormalizer = strings.NewReplacer(
+	"\u2018", "'", "\u2019", "'", "\u201c", `"`, "\u201d", `"`,
+	"\u2013", "-", "\u2014", "-", "\u2026", "...", "\u00a0", " ",
+)
+
+// HighlightContentHash identifies a highlight by its normalized text and book.
+// Case, whitespace and typographic quotes and dashes are ignored, and location and
+// timestamp are left out entirely, so the same passage imported from another
+// source or with a drifted timestamp hashes the same.
+func HighlightContentHash(bookTitle, bookAuthor, text string) string {
+	normalize := func(s string) string {
+		return strings.Join(strings.Fields(strings.ToLower(contentNormalizer.Replace(s))), " ")
+	}
+	sum := sha256.Sum256([]byte(normalize(bookTitle) + "\x00" + normalize(bookAuthor) + "\x00" + normalize(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsLocallyEdited reports whether the text or note was changed after it was last imported.
+// Highlights imported before origin tracking have no hash and are never considered edited.
+func (h *Highlight) IsLocallyEdited() bool {
+	return h.OriginHash != "" && h.OriginHash != HighlightOriginHash(h.Text, h.Note)
+}
+
 type Tag struct {
 	ID         uint        `gorm:"primaryKey" json:"id"`
 	UserID     uint        `gorm:"uniqueIndex:idx_tag_user_name" json:"user_id"`
@@ -164,12 +248,23 @@ type ImportSession struct {
 	BooksCreated        int          `json:"books_created"`
 	HighlightsCreated   int          `json:"highlights_created"`
 	Errors              string       `gorm:"type:text" json:"errors,omitempty"` // JSON array of errors
+	Files               string       `gorm:"type:text" json:"files,omitempty"`  // JSON array of ImportFileResult, for imports of several files
 	StartedAt           time.Time    `json:"started_at"`
 	CompletedAt         *time.Time   `json:"completed_at,omitempty"`
 	User                User         `gorm:"foreignKey:UserID" json:"-"`
 	Source              Source       `gorm:"foreignKey:SourceID" json:"source,omitempty"`
 }
 
+// ImportFileResult is the outcome of one file of an import made of several
+// files, such as a Readwise library export.
+type ImportFileResult struct {
+	Name       string `json:"name"`
+	Format     string `json:"format"`
+	Books      int    `json:"books"`
+	Highlights int    `json:"highlights"`
+	Error      string `json:"error,omitempty"`
+}
+
 func (Tag) TableName() string {
 	return "tags"
 }
@@ -192,16 +287,50 @@ func (ImportSession) TableName() string {
 type DeletedEntity struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
 	UserID     uint      `gorm:"index" json:"user_id"`
-	EntityType string    `gorm:"index;size:20" json:"entity_type"` // "book" or "highlight"
-	EntityKey  string    `gorm:"index;size:512" json:"entity_key"` // Unique identifier (title+author for books, text+location for highlights)
+	EntityType string    `gorm:"index;index:idx_deleted_entities_type_key,priority:1;size:20" json:"entity_type"` // "book" or "highlight"
+	EntityKey  string    `gorm:"index;index:idx_deleted_entities_type_key,priority:2;size:512" json:"entity_key"` // Unique identifier (title+author for books, text+location for highlights)
 	SourceID   uint      `gorm:"index" json:"source_id"`
 	DeletedAt  time.Time `json:"deleted_at"`
+
+	// Highlight.ContentHash of a deleted highlight. Empty for books and for
+	// highlights deleted before content hashing, which match by EntityKey only.
+	ContentHash string `gorm:"index;size:64" json:"-"`
 }
 
 func (DeletedEntity) TableName() string {
 	return "deleted_entities"
 }
 
+// DeletedEntity types
+const (
+	DeletedEntityBook      = "book"
+	DeletedEntityHighlight = "highlight"
+)
+
+// Label returns a readable description of the deleted entity from its key:
+// "Title by Author" for books and the highlight text for highlights.
+func (d DeletedEntity) Label() string {
+	switch d.EntityType {
+	case DeletedEntityBook:
+		if title, author, ok := strings.Cut(d.EntityKey, "|"); ok {
+			if author == "" {
+				return title
+			}
+			return title + " by " + author
+		}
+	case DeletedEntityHighlight:
+		// Key is text|location|timestamp; the text itself may contain "|"
+		key := d.EntityKey
+		for range 2 {
+			if i := strings.LastIndex(key, "|"); i >= 0 {
+				key = key[:i]
+			}
+		}
+		return key
+	}
+	return d.EntityKey
+}
+
 // WordStatus represents the enrichment status of a vocabulary word.
 type WordStatus string
 
@@ -209,6 +338,10 @@ const (
 	WordStatusPending  WordStatus = "pending"
 	WordStatusEnriched WordStatus = "enriched"
 	WordStatusFailed   WordStatus = "failed"
+
+	// WordStatusCandidate marks a word auto-extracted from a highlight that
+	// awaits user confirmation before it joins the vocabulary.
+	WordStatusCandidate WordStatus = "candidate"
 )
 
 // Word represents a vocabulary word saved from a highlight.
@@ -216,23 +349,34 @@ type Word struct {
 	ID          uint       `gorm:"primaryKey" json:"id"`
 	UserID      uint       `gorm:"index" json:"user_id"`
 	Word        string     `gorm:"index;size:100" json:"word"`
+	Lemma       string     `gorm:"index;size:100" json:"lemma"` // Dictionary form, e.g. "run" for "running"
 	HighlightID *uint      `gorm:"index" json:"highlight_id,omitempty"`
 	BookID      *uint      `gorm:"index" json:"book_id,omitempty"`
 	Context     string     `gorm:"type:text" json:"context,omitempty"`
 	Status      WordStatus `gorm:"size:20;default:'pending'" json:"status"`
+	Difficulty  int        `gorm:"index" json:"difficulty"` // 1 (most common) to 100 by word frequency, 0 if not scored
 
 	// Denormalized source info preserved after highlight/book deletion
 	SourceBookTitle     string `gorm:"size:512" json:"source_book_title,omitempty"`
 	SourceBookAuthor    string `gorm:"size:256" json:"source_book_author,omitempty"`
 	SourceHighlightText string `gorm:"type:text" json:"source_highlight_text,omitempty"`
 
-	EnrichmentError string `gorm:"size:512" json:"enrichment_error,omitempty"`
+	EnrichmentError    string `gorm:"size:512" json:"enrichment_error,omitempty"`
+	EnrichmentAttempts int    `json:"enrichment_attempts,omitempty"` // Failed lookups since the word was last queued
+
+	// Spaced repetition review schedule (SM-2); a word never reviewed is due
+	ReviewEase        float64    `gorm:"default:2.5" json:"review_ease"`
+	ReviewInterval    int        `json:"review_interval"`    // Days from the last review to the next one
+	ReviewRepetitions int        `json:"review_repetitions"` // Reviews in a row the word was recalled
+	ReviewDueAt       *time.Time `gorm:"index" json:"review_due_at,omitempty"`
+	ReviewedAt        *time.Time `json:"reviewed_at,omitempty"`
 
 	// Relationships - ON DELETE SET NULL preserves words after source deletion
 	Highlight   *Highlight       `gorm:"foreignKey:HighlightID;constraint:OnDelete:SET NULL" json:"highlight,omitempty"`
 	Book        *Book            `gorm:"foreignKey:BookID;constraint:OnDelete:SET NULL" json:"book,omitempty"`
 	User        User             `gorm:"foreignKey:UserID" json:"-"`
 	Definitions []WordDefinition `gorm:"foreignKey:WordID" json:"definitions,omitempty"`
+	Contexts    []WordContext    `gorm:"foreignKey:WordID" json:"contexts,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -242,6 +386,28 @@ func (Word) TableName() string {
 	return "words"
 }
 
+// WordContext is an additional place a word was captured, kept when the same
+// word is saved again from another highlight or merged from a duplicate entry.
+// The word's own Context and Source fields hold the first capture.
+type WordContext struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	WordID      uint   `gorm:"index" json:"word_id"`
+	HighlightID *uint  `gorm:"index" json:"highlight_id,omitempty"`
+	BookID      *uint  `gorm:"index" json:"book_id,omitempty"`
+	Form        string `gorm:"size:100" json:"form"` // The word as it appeared, e.g. "running"
+	Context     string `gorm:"type:text" json:"context,omitempty"`
+
+	SourceBookTitle     string `gorm:"size:512" json:"source_book_title,omitempty"`
+	SourceBookAuthor    string `gorm:"size:256" json:"source_book_author,omitempty"`
+	SourceHighlightText string `gorm:"type:text" json:"source_highlight_text,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (WordContext) TableName() string {
+	return "word_contexts"
+}
+
 // WordDefinition contains dictionary definition data for a word.
 type WordDefinition struct {
 	ID            uint   `gorm:"primaryKey" json:"id"`
@@ -251,7 +417,13 @@ type WordDefinition struct {
 	Example       string `gorm:"type:text" json:"example,omitempty"`
 	Pronunciation string `gorm:"size:100" json:"pronunciation,omitempty"`
 	AudioURL      string `gorm:"size:512" json:"audio_url,omitempty"`
-	Source        string `gorm:"size:50" json:"source"`
+	Source        string `gorm:"size:50" json:"source"` // Dictionary provider that produced the definition
+
+	// Terms the definition may be reused under, e.g. in exported decks
+	Attribution string `gorm:"size:512" json:"attribution,omitempty"`
+	License     string `gorm:"size:100" json:"license,omitempty"`
+	LicenseURL  string `gorm:"size:512" json:"license_url,omitempty"`
+	SourceURL   string `gorm:"size:512" json:"source_url,omitempty"` // Page the definition was taken from
 
 	Word Word `gorm:"foreignKey:WordID" json:"-"`
 
@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+// LibraryDiff lists what changed in a library between two points in time,
+// e.g. for a monthly review of what was read.
+type LibraryDiff struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"` // Exclusive
+
+	NewBooks          []Book                 `json:"new_books"`          // Added in the period, without highlights
+	NewHighlights     []LibraryDiffHighlight `json:"new_highlights"`     // Added in the period, including those of new books
+	EditedHighlights  []LibraryDiffHighlight `json:"edited_highlights"`  // Added earlier, text or note changed in the period
+	NewNotes          []BookNote             `json:"new_notes"`          // Journal entries written in the period
+	EditedNotes       []BookNote             `json:"edited_notes"`       // Journal entries written earlier, changed in the period
+	TrashedBooks      []Book                 `json:"trashed_books"`      // Moved to the trash in the period and still there
+	TrashedHighlights []LibraryDiffHighlight `json:"trashed_highlights"` // Moved to the trash on their own
+	DeletedBooks      []DeletedEntity        `json:"deleted_books"`      // Permanently deleted in the period
+	DeletedHighlights []DeletedEntity        `json:"deleted_highlights"`
+}
+
+// LibraryDiffHighlight is a highlight in a LibraryDiff with the book it belongs to.
+type LibraryDiffHighlight struct {
+	Highlight  Highlight `json:"highlight"`
+	BookTitle  string    `json:"book_title"`
+	BookAuthor string    `json:"book_author"`
+}
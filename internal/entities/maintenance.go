@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"time"
+)
+
+// MaintenanceTask names a database maintenance job.
+type MaintenanceTask string
+
+const (
+	MaintenanceIntegrityCheck MaintenanceTask = "integrity_check" // PRAGMA integrity_check
+	MaintenanceVacuum         MaintenanceTask = "vacuum"          // Incremental vacuum returning free pages to the filesystem
+	MaintenanceOrphanTags     MaintenanceTask = "orphan_tags"     // Delete tags without books or highlights
+	MaintenanceSessions       MaintenanceTask = "sessions"        // Delete expired login sessions
+)
+
+// MaintenanceTasks lists every maintenance job in the order they run.
+var MaintenanceTasks = []MaintenanceTask{
+	MaintenanceIntegrityCheck,
+	MaintenanceVacuum,
+	MaintenanceOrphanTags,
+	MaintenanceSessions,
+}
+
+// Description is the job's name on the admin health page.
+func (t MaintenanceTask) Description() string {
+	switch t {
+	case MaintenanceIntegrityCheck:
+		return "Integrity check"
+	case MaintenanceVacuum:
+		return "Incremental vacuum"
+	case MaintenanceOrphanTags:
+		return "Orphan tag cleanup"
+	case MaintenanceSessions:
+		return "Expired session purge"
+	}
+	return string(t)
+}
+
+type MaintenanceStatus string
+
+const (
+	MaintenanceStatusOK     MaintenanceStatus = "ok"
+	MaintenanceStatusFailed MaintenanceStatus = "failed"
+)
+
+// MaintenanceRun records the outcome of a database maintenance job.
+type MaintenanceRun struct {
+	ID         uint              `gorm:"primaryKey" json:"id"`
+	Task       MaintenanceTask   `gorm:"size:32;index" json:"task"`
+	Status     MaintenanceStatus `gorm:"size:20" json:"status"`
+	Result     string            `gorm:"type:text" json:"result"` // Summary, the problems found or the error
+	DurationMs int64             `json:"duration_ms"`
+	StartedAt  time.Time         `gorm:"index" json:"started_at"`
+}
+
+func (MaintenanceRun) TableName() string {
+	return "maintenance_runs"
+}
@@ -0,0 +1,40 @@
+package entities
+
+import "time"
+
+// MetadataReviewStatus tracks whether a held metadata match was reviewed.
+type MetadataReviewStatus string
+
+const (
+	MetadataReviewPending  MetadataReviewStatus = "pending"
+	MetadataReviewAccepted MetadataReviewStatus = "accepted"
+	MetadataReviewRejected MetadataReviewStatus = "rejected"
+)
+
+// MetadataReview holds the metadata of a match too uncertain to apply on its
+// own, such as a title search that found a book with a different author,
+// until it is accepted or rejected. Empty fields were not proposed.
+type MetadataReview struct {
+	ID              uint                 `gorm:"primaryKey" json:"id"`
+	BookID          uint                 `gorm:"index" json:"book_id"`
+	Book            Book                 `gorm:"foreignKey:BookID" json:"book,omitempty"`
+	UserID          uint                 `gorm:"index" json:"user_id"`
+	Status          MetadataReviewStatus `gorm:"index;size:20;default:pending" json:"status"`
+	Confidence      float64              `json:"confidence"`                     // 0-1, how closely the match's title and author agree with the book's
+	Source          string               `gorm:"size:50" json:"source"`          // Metadata provider, e.g. "openlibrary"
+	SearchMethod    string               `gorm:"size:10" json:"search_method"`   // "isbn" or "title"
+	MatchedTitle    string               `gorm:"size:512" json:"matched_title"`  // Title of the book the provider found
+	MatchedAuthor   string               `gorm:"size:256" json:"matched_author"` // Author of the book the provider found
+	ISBN            string               `gorm:"size:20" json:"isbn,omitempty"`
+	CoverURL        string               `gorm:"size:2048" json:"cover_url,omitempty"`
+	Publisher       string               `gorm:"size:256" json:"publisher,omitempty"`
+	PublicationYear int                  `json:"publication_year,omitempty"`
+	Series          string               `gorm:"size:256" json:"series,omitempty"`
+	SeriesIndex     float64              `json:"series_index,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+func (MetadataReview) TableName() string {
+	return "metadata_reviews"
+}
@@ -0,0 +1,8 @@
+package entities
+
+// PublicLibraryFilter selects the books shown on the public library besides
+// the ones shared one by one.
+type PublicLibraryFilter struct {
+	Favourites bool     // Include favourite books
+	Tags       []string // Include books with any of these tags
+}
@@ -0,0 +1,19 @@
+package entities
+
+// Kinds of records found by the quick search.
+const (
+	SearchKindBook      = "book"
+	SearchKindHighlight = "highlight"
+	SearchKindTag       = "tag"
+	SearchKindWord      = "word"
+)
+
+// SearchHit is a record matching a quick search.
+type SearchHit struct {
+	Kind     string // One of the SearchKind constants
+	ID       uint
+	ParentID uint   // The book of a highlight or word, 0 for none
+	Title    string // Book title, highlight text, tag name or word
+	Detail   string // Book author, highlight note or word context
+	Score    int    // Higher is a better match
+}
@@ -0,0 +1,41 @@
+package entities
+
+import "time"
+
+// SavedView is a named highlight search, or smart view, such as "Stoicism
+// notes from Kindle this year". Only the criteria are saved; the matching
+// highlights are looked up each time the view is opened or exported.
+type SavedView struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"uniqueIndex:idx_saved_view_user_name" json:"user_id"`
+	Name           string     `gorm:"uniqueIndex:idx_saved_view_user_name;size:100" json:"name"`
+	Query          string     `gorm:"size:256" json:"query,omitempty"`       // Text or note containing this
+	Tags           []string   `gorm:"serializer:json" json:"tags,omitempty"` // Highlights or books with any of these tags
+	Source         string     `gorm:"size:50" json:"source,omitempty"`       // Source name, e.g. "kindle"
+	FavouritesOnly bool       `json:"favourites_only"`                       // Only favourite highlights
+	From           *time.Time `json:"from,omitempty"`                        // Highlighted at or after
+	To             *time.Time `json:"to,omitempty"`                          // Highlighted before
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (SavedView) TableName() string {
+	return "saved_views"
+}
+
+// HighlightFilter returns the view's criteria as a filter for the user's highlights.
+func (v *SavedView) HighlightFilter() HighlightFilter {
+	filter := HighlightFilter{
+		UserID: v.UserID,
+		Query:  v.Query,
+		Tags:   v.Tags,
+		Source: v.Source,
+		From:   v.From,
+		To:     v.To,
+	}
+	if v.FavouritesOnly {
+		favourite := true
+		filter.Favourite = &favourite
+	}
+	return filter
+}
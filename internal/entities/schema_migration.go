@@ -0,0 +1,52 @@
+package entities
+
+import (
+	"time"
+)
+
+// MigrationKind distinguishes schema changes from data backfills.
+type MigrationKind string
+
+const (
+	MigrationKindSchema   MigrationKind = "schema"   // Table or column added by an upgrade
+	MigrationKindBackfill MigrationKind = "backfill" // Data migration that runs in the background
+)
+
+type MigrationStatus string
+
+const (
+	MigrationStatusPending   MigrationStatus = "pending"
+	MigrationStatusRunning   MigrationStatus = "running"
+	MigrationStatusCompleted MigrationStatus = "completed"
+	MigrationStatusFailed    MigrationStatus = "failed"
+)
+
+// SchemaMigration records a schema change or data backfill applied during an upgrade.
+type SchemaMigration struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	Name        string          `gorm:"uniqueIndex;size:128" json:"name"`
+	Kind        MigrationKind   `gorm:"size:20" json:"kind"`
+	Description string          `gorm:"size:512" json:"description"`
+	Status      MigrationStatus `gorm:"size:20;index" json:"status"`
+	TotalItems  int             `json:"total_items"`
+	Processed   int             `json:"processed"`
+	Error       string          `gorm:"type:text" json:"error,omitempty"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Percent returns backfill progress as 0-100.
+func (m SchemaMigration) Percent() int {
+	if m.Status == MigrationStatusCompleted {
+		return 100
+	}
+	if m.TotalItems <= 0 {
+		return 0
+	}
+	return m.Processed * 100 / m.TotalItems
+}
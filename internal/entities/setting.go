@@ -28,10 +28,15 @@ const (
 	SettingKeyObsidianSyncEnabled     = "obsidian_sync_enabled"
 	SettingKeyObsidianSyncExportDir   = "obsidian_sync_export_dir"
 	SettingKeyObsidianSyncSchedule    = "obsidian_sync_schedule"
+	SettingKeyObsidianSyncFormat      = "obsidian_sync_format"
+	SettingKeyObsidianSyncTwoWay      = "obsidian_sync_two_way"
 	SettingKeyObsidianSyncLastAt      = "obsidian_sync_last_at"
 	SettingKeyObsidianSyncLastStatus  = "obsidian_sync_last_status"
 	SettingKeyObsidianSyncLastMessage = "obsidian_sync_last_message"
 
+	// File export settings
+	SettingKeyExportFilenameStyle = "export_filename_style"
+
 	// Readwise Sync settings
 	SettingKeyReadwiseSyncEnabled          = "readwise_sync_enabled"
 	SettingKeyReadwiseSyncToken            = "readwise_sync_token"
@@ -40,4 +45,49 @@ const (
 	SettingKeyReadwiseSyncLastStatus       = "readwise_sync_last_status"
 	SettingKeyReadwiseSyncLastMessage      = "readwise_sync_last_message"
 	SettingKeyReadwiseSyncHighlightsSynced = "readwise_sync_highlights_synced"
+	SettingKeyReadwiseSyncResumeState      = "readwise_sync_resume_state"
+
+	// Telegram bot settings
+	SettingKeyTelegramEnabled        = "telegram_enabled"
+	SettingKeyTelegramToken          = "telegram_token"
+	SettingKeyTelegramChatID         = "telegram_chat_id"
+	SettingKeyTelegramReviewSchedule = "telegram_review_schedule"
+	SettingKeyTelegramUpdateOffset   = "telegram_update_offset"
+
+	// Moon+ Reader paths
+	SettingKeyMoonReaderDropboxPath  = "moonreader_dropbox_path"
+	SettingKeyMoonReaderDatabasePath = "moonreader_database_path"
+	SettingKeyMoonReaderOutputDir    = "moonreader_output_dir"
+	SettingKeyMoonReaderOutputFormat = "moonreader_output_format"
+
+	// Enrichment settings
+	SettingKeyMetadataAutoEnrich    = "metadata_auto_enrich"
+	SettingKeyVocabularyAutoExtract = "vocabulary_auto_extract"
+	SettingKeyDictionaryProvider    = "dictionary_provider"
+
+	// SettingKeyDictionaryFreeDictionaryEnabled turns lookups through the
+	// Free Dictionary API on or off
+	SettingKeyDictionaryFreeDictionaryEnabled = "dictionary_freedictionary_enabled"
+
+	// Public library settings
+	SettingKeyPublicLibraryEnabled    = "public_library_enabled"
+	SettingKeyPublicLibraryTitle      = "public_library_title"
+	SettingKeyPublicLibraryFavourites = "public_library_favourites"
+	SettingKeyPublicLibraryTags       = "public_library_tags"
+
+	// Timezone, locale and UI language of users without their own
+	SettingKeyDefaultTimezone = "default_timezone"
+	SettingKeyDefaultLocale   = "default_locale"
+	SettingKeyDefaultLanguage = "default_language"
+
+	// "Open in app" link templates, by source
+	SettingKeyDeepLinkKindle     = "deep_link_kindle"
+	SettingKeyDeepLinkAppleBooks = "deep_link_apple_books"
+	SettingKeyDeepLinkMoonReader = "deep_link_moonreader"
+
+	// Vocabulary extraction settings
+	SettingKeyVocabularyExtractLastHighlightID = "vocabulary_extract_last_highlight_id"
+
+	// Highlight classification settings
+	SettingKeyClassifyLastHighlightID = "classify_last_highlight_id"
 )
@@ -8,8 +8,12 @@ type SyncType string
 
 const (
 	SyncTypeMetadata SyncType = "metadata"
+	SyncTypeReenrich SyncType = "metadata_reenrich" // Whole-library re-enrichment, one queued task per book
 )
 
+// SyncTypes lists every sync type.
+var SyncTypes = []SyncType{SyncTypeMetadata, SyncTypeReenrich}
+
 type SyncStatus string
 
 const (
@@ -18,6 +22,15 @@ const (
 	SyncStatusFailed    SyncStatus = "failed"
 )
 
+// SyncOutcome is how one item of a sync ended; it names the counter it increments.
+type SyncOutcome string
+
+const (
+	SyncOutcomeSucceeded SyncOutcome = "succeeded"
+	SyncOutcomeFailed    SyncOutcome = "failed"
+	SyncOutcomeSkipped   SyncOutcome = "skipped"
+)
+
 type SyncProgress struct {
 	ID          uint       `gorm:"primaryKey" json:"id"`
 	SyncType    SyncType   `gorm:"size:50;uniqueIndex" json:"sync_type"`
@@ -28,10 +41,16 @@ type SyncProgress struct {
 	Failed      int        `json:"failed"`
 	Skipped     int        `json:"skipped"`
 	CurrentItem string     `gorm:"size:512" json:"current_item,omitempty"`
+	LastItemID  uint       `json:"last_item_id,omitempty"` // Last item finished by a resumable run; 0 once the run completes
 	Error       string     `gorm:"type:text" json:"error,omitempty"`
 	StartedAt   time.Time  `json:"started_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	// Lock ownership: the worker running the sync and when it last showed it
+	// is alive. A running sync without a recent heartbeat was abandoned.
+	Owner       string    `gorm:"size:128" json:"owner,omitempty"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
 }
 
 func (SyncProgress) TableName() string {
@@ -0,0 +1,27 @@
+package entities
+
+// TagTarget is the kind of item a tag is assigned to.
+type TagTarget string
+
+const (
+	TagTargetBook      TagTarget = "book"
+	TagTargetHighlight TagTarget = "highlight"
+)
+
+// TagAssignment is one tag on a book or highlight. Books are keyed by title and
+// author so the assignment survives a re-import; highlights by their ID.
+type TagAssignment struct {
+	Target        TagTarget
+	BookTitle     string
+	BookAuthor    string
+	HighlightID   uint   // Set for highlight assignments
+	HighlightText string // For reading the export only, ignored when applying
+	Tag           string
+}
+
+// TagAssignmentResult reports what applying a list of tag assignments changed.
+type TagAssignmentResult struct {
+	Added     int   `json:"added"`
+	Removed   int   `json:"removed"`
+	Unmatched []int `json:"-"` // Indexes of the assignments whose book or highlight was not found
+}
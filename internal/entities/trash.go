@@ -0,0 +1,14 @@
+package entities
+
+// TrashedBook is a soft-deleted book together with the number of highlights deleted with it.
+type TrashedBook struct {
+	Book           Book  `json:"book"`
+	HighlightCount int64 `json:"highlight_count"`
+}
+
+// TrashedHighlight is a soft-deleted highlight whose book is still in the library.
+type TrashedHighlight struct {
+	Highlight  Highlight `json:"highlight"`
+	BookTitle  string    `json:"book_title"`
+	BookAuthor string    `json:"book_author"`
+}
@@ -0,0 +1,68 @@
+package entities
+
+import "time"
+
+// Themes of the web UI
+const (
+	ThemeSystem = "system" // Follows the operating system's light or dark mode
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+)
+
+// Orders of the books in the library
+const (
+	BookSortAdded      = "added"    // Oldest import first
+	BookSortImported   = "imported" // Most recently imported first
+	BookSortTitle      = "title"
+	BookSortAuthor     = "author"
+	BookSortRecent     = "recent"     // Most recently highlighted first
+	BookSortHighlights = "highlights" // Most highlights first
+	BookSortPublished  = "published"  // Newest publication year first, unknown years last
+)
+
+// Layouts of the library
+const (
+	ViewModeList = "list"
+	ViewModeGrid = "grid" // Covers in a grid
+)
+
+var (
+	Themes    = []string{ThemeSystem, ThemeLight, ThemeDark}
+	BookSorts = []string{BookSortAdded, BookSortImported, BookSortTitle, BookSortAuthor, BookSortRecent, BookSortHighlights, BookSortPublished}
+	ViewModes = []string{ViewModeList, ViewModeGrid}
+)
+
+// Limits of UIPreferences.PageSize
+const (
+	DefaultPageSize = 50
+	MinPageSize     = 10
+	MaxPageSize     = 500
+)
+
+// UIPreferences are a user's web UI settings. User 0, used when
+// authentication is disabled, has them too.
+type UIPreferences struct {
+	UserID        uint      `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	Theme         string    `gorm:"size:20" json:"theme"`          // One of Themes
+	PageSize      int       `json:"page_size"`                     // Books per library page
+	Sort          string    `gorm:"size:20" json:"sort"`           // One of BookSorts
+	HighlightSort string    `gorm:"size:20" json:"highlight_sort"` // One of HighlightSorts
+	ViewMode      string    `gorm:"size:20" json:"view_mode"`      // One of ViewModes
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (UIPreferences) TableName() string {
+	return "ui_preferences"
+}
+
+// DefaultUIPreferences returns the settings of users who have not changed them
+func DefaultUIPreferences(userID uint) UIPreferences {
+	return UIPreferences{
+		UserID:        userID,
+		Theme:         ThemeSystem,
+		PageSize:      DefaultPageSize,
+		Sort:          BookSortAdded,
+		HighlightSort: HighlightSortRecent,
+		ViewMode:      ViewModeList,
+	}
+}
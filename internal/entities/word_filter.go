@@ -0,0 +1,19 @@
+package entities
+
+// WordSort orders a vocabulary listing.
+type WordSort string
+
+const (
+	WordSortNewest  WordSort = "newest"  // Most recently added first
+	WordSortHardest WordSort = "hardest" // Highest difficulty first
+	WordSortEasiest WordSort = "easiest" // Lowest difficulty first
+)
+
+// WordFilter narrows a vocabulary listing. Zero values leave a criterion unset.
+type WordFilter struct {
+	UserID        uint
+	Status        WordStatus // Only words with this status; unset leaves out candidates
+	MinDifficulty int        // Only words scored at least this
+	MaxDifficulty int        // Only words scored at most this
+	Sort          WordSort   // Newest first when unset
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -16,22 +18,30 @@ import (
 	"github.com/mrlokans/assistant/internal/analytics"
 	"github.com/mrlokans/assistant/internal/audit"
 	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/classify"
 	"github.com/mrlokans/assistant/internal/config"
 	"github.com/mrlokans/assistant/internal/covers"
 	"github.com/mrlokans/assistant/internal/database"
 	auditdb "github.com/mrlokans/assistant/internal/database/audit"
 	"github.com/mrlokans/assistant/internal/demo"
 	"github.com/mrlokans/assistant/internal/dictionary"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
 	"github.com/mrlokans/assistant/internal/exporters"
 	http_controllers "github.com/mrlokans/assistant/internal/http"
 	"github.com/mrlokans/assistant/internal/metadata"
 	"github.com/mrlokans/assistant/internal/oauth2"
 	"github.com/mrlokans/assistant/internal/oauth2/providers"
+	"github.com/mrlokans/assistant/internal/ocr"
 	"github.com/mrlokans/assistant/internal/readwise"
 	"github.com/mrlokans/assistant/internal/scheduler"
 	"github.com/mrlokans/assistant/internal/settingsstore"
+	"github.com/mrlokans/assistant/internal/storage/providers/s3"
 	"github.com/mrlokans/assistant/internal/tasks"
+	"github.com/mrlokans/assistant/internal/telegram"
 	"github.com/mrlokans/assistant/internal/tokenstore"
+	"github.com/mrlokans/assistant/internal/tts"
+	"github.com/mrlokans/assistant/internal/uploads"
 )
 
 // ShutdownFunc is called during graceful shutdown to clean up resources.
@@ -77,38 +87,35 @@ func Serve(router *gin.Engine, cfg *config.Config, onShutdown ShutdownFunc) {
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.HTTP.Port),
-		Handler: router,
+		Handler: http_controllers.BasePathHandler(cfg.HTTP.BasePath, router),
 	}
 
 	go func() {
-		fmt.Printf("Starting server at http://%s:%d\n", cfg.HTTP.Host, cfg.HTTP.Port)
+		fmt.Printf("Starting server at http://%s:%d%s/\n", cfg.HTTP.Host, cfg.HTTP.Port, cfg.HTTP.BasePath)
 		// service connections
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("listen: %s\n", err)
 		}
 	}()
 
-	// Graceful shutdown
-	// Wait for interrupt signal to gracefully shutdown the server with
-	// a timeout of 1 second.
+	// Graceful shutdown: on SIGINT or SIGTERM stop accepting connections and
+	// let in-flight requests, such as imports, finish, then let onShutdown
+	// drain background work. Both share the shutdown timeout.
 	quit := make(chan os.Signal, 1)
-	// kill (no param) default send syscanll.SIGTERM
-	// kill -2 is syscall.SIGINT
-	// kill -9 is syscall. SIGKILL but can"t be catch, so don't need add it
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Printf("Shutdown Server, waiting %v before killing\n", timeout)
+	log.Printf("Shutdown Server, waiting up to %v for running work\n", timeout)
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	// Call shutdown callback first (e.g., to stop task queue)
-	if onShutdown != nil {
-		onShutdown(ctx)
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("WARNING: Server shutdown: %v (requests still running were cut off)", err)
 	}
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server Shutdown:", err)
+	// Then stop background work, e.g. the task queue
+	if onShutdown != nil {
+		onShutdown(ctx)
 	}
 
 	log.Println("Server exiting")
@@ -116,6 +123,9 @@ func Serve(router *gin.Engine, cfg *config.Config, onShutdown ShutdownFunc) {
 
 func Run(cfg *config.Config, version string) {
 	log.Printf("Starting Assistant v%s", version)
+	if cfg.File != "" {
+		log.Printf("Loaded config file %s", cfg.File)
+	}
 
 	// Initialize demo mode middleware and extract embedded assets if needed
 	var demoMiddleware *demo.Middleware
@@ -153,8 +163,23 @@ func Run(cfg *config.Config, version string) {
 				log.Printf("Cleaning up demo assets from %s", tempDir)
 				os.RemoveAll(tempDir)
 			}
-		} else if cfg.Demo.UseEmbedded {
-			log.Printf("Warning: DEMO_USE_EMBEDDED is true but no embedded assets found. Using file paths.")
+		} else if cfg.Demo.Seed || cfg.Demo.UseEmbedded {
+			// Without a prepared database, seed the demo dataset into a throwaway one
+			if cfg.Demo.UseEmbedded {
+				log.Printf("Warning: DEMO_USE_EMBEDDED is true but no embedded assets found. Seeding the demo dataset instead.")
+			}
+			dbPath, cleanup, err := demo.SeedTempDatabase()
+			if err != nil {
+				log.Fatalf("Failed to seed demo database: %v", err)
+			}
+			log.Printf("Seeded demo database at %s", dbPath)
+
+			cfg.Database.Path = dbPath
+			cfg.Demo.DBPath = dbPath
+			demoCleanup = func() {
+				log.Printf("Removing seeded demo database %s", dbPath)
+				cleanup()
+			}
 		}
 	}
 
@@ -169,17 +194,44 @@ func Run(cfg *config.Config, version string) {
 		}
 	}()
 
+	// Keep the syncs this process runs marked alive, so other workers can
+	// tell them from syncs abandoned by a killed process
+	heartbeatCtx, heartbeatCancel := context.WithCancel(context.Background())
+	go db.KeepSyncsAlive(heartbeatCtx)
+
+	// Apply pending data backfills in the background; progress is shown on the upgrade status page
+	backfillCtx, backfillCancel := context.WithCancel(context.Background())
+	go func() {
+		if err := db.RunPendingBackfills(backfillCtx); err != nil && backfillCtx.Err() == nil {
+			log.Printf("WARNING: Data backfill failed: %v", err)
+		}
+	}()
+
+	// Publish import, task and sync progress to the live events stream of the UI
+	eventBroker := events.NewBroker()
+	db.SetEventBroker(eventBroker)
+
 	// Create the combined database + markdown exporter
 	// It implements both BookReader and BookExporter interfaces
 	exporter := exporters.NewDatabaseMarkdownExporter(
 		db,
 		cfg.Obsidian.ExportDir,
 	)
+	exporter.SetEventBroker(eventBroker)
 
 	// Create audit service for logging application events
 	auditRepo := auditdb.NewRepository(db.DB)
 	auditService := audit.NewService(auditRepo)
 
+	// Optional object storage for cover images and finished uploads
+	blobStore, err := s3.FromConfig(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	if blobStore != nil {
+		log.Printf("Covers and finished uploads are kept in %s storage", cfg.Storage.Backend)
+	}
+
 	// Create cover cache for locally caching book covers
 	// In demo mode with embedded assets, use the extracted covers path
 	coverCacheDir := cfg.Demo.CoversPath
@@ -190,14 +242,54 @@ func Run(cfg *config.Config, version string) {
 	if err != nil {
 		log.Printf("WARNING: Failed to initialize cover cache: %v", err)
 	} else {
+		coverCache.WithUserQuota(int64(cfg.Uploads.CoverQuotaMB) * 1024 * 1024)
+		// Demo covers are bundled with the app and stay on disk
+		if blobStore != nil && cfg.Demo.CoversPath == "" {
+			coverCache.WithStore(blobStore)
+		}
 		log.Printf("Cover cache initialized at %s", coverCacheDir)
 	}
 
+	// Create upload store for resumable chunked uploads of large import files
+	uploadDir := cfg.Uploads.Dir
+	if uploadDir == "" {
+		uploadDir = filepath.Join(filepath.Dir(cfg.Database.Path), "uploads")
+	}
+	uploadStore, err := uploads.NewStore(uploadDir, int64(cfg.Uploads.MaxSizeMB)*1024*1024)
+	if err != nil {
+		log.Printf("WARNING: Failed to initialize upload store, chunked uploads disabled: %v", err)
+	} else {
+		uploadStore.WithUserQuota(int64(cfg.Uploads.UserQuotaMB) * 1024 * 1024)
+		if blobStore != nil {
+			uploadStore.WithStore(blobStore)
+		}
+
+		// Abandoned uploads are kept for a day so interrupted transfers can resume
+		go func() {
+			ticker := time.NewTicker(time.Hour)
+			defer ticker.Stop()
+			for {
+				if removed, err := uploadStore.RemoveStale(24 * time.Hour); err != nil {
+					log.Printf("WARNING: Failed to remove stale uploads: %v", err)
+				} else if removed > 0 {
+					log.Printf("Removed %d stale uploads", removed)
+				}
+				<-ticker.C
+			}
+		}()
+	}
+
 	// Create metadata enricher for book enrichment from OpenLibrary
 	openLibraryClient := metadata.NewOpenLibraryClient()
 	metadataUpdater := database.NewMetadataUpdater(db)
 	metadataEnricher := metadata.NewEnricher(openLibraryClient, metadataUpdater)
 
+	// Prefer covers of the edition read, found by Kindle ASIN or Apple Books store ID
+	metadataEnricher.SetCoverSources(metadata.DefaultCoverSources()...)
+
+	// Hold uncertain title matches for review rather than writing them
+	metadataEnricher.SetReviewQueue(db)
+
 	// Create progress reporter for tracking bulk sync operations
 	syncProgress := database.NewMetadataSyncProgress(db)
 	metadataEnricher.SetProgressReporter(syncProgress)
@@ -207,21 +299,99 @@ func Run(cfg *config.Config, version string) {
 		metadataEnricher.SetCoverInvalidator(coverCache)
 	}
 
-	// Create dictionary client for vocabulary enrichment
-	dictClient := dictionary.NewFreeDictionaryClient()
+	// Create author enricher for author pages from Wikidata
+	authorEnricher := metadata.NewAuthorEnricher(metadata.NewWikidataClient(), db)
+
+	ocrEngine, err := ocr.New(cfg.OCR)
+	if err != nil {
+		log.Printf("WARNING: OCR disabled: %v", err)
+	} else if ocrEngine != nil {
+		log.Printf("OCR enabled with %s backend", cfg.OCR.Backend)
+	}
+
+	// Sort highlights into categories such as advice or definitions
+	highlightClassifier, err := classify.New(cfg.Classifier)
+	if err != nil {
+		log.Printf("WARNING: Highlight classification disabled: %v", err)
+	} else if highlightClassifier != nil {
+		log.Printf("Highlight classification enabled with %s backend", cfg.Classifier.Backend)
+	}
+
+	// Spoken highlights for podcast feeds
+	var podcastAudio *tts.Cache
+	ttsEngine, err := tts.New(cfg.TTS)
+	if err != nil {
+		log.Printf("WARNING: Text-to-speech disabled: %v", err)
+	} else if ttsEngine != nil {
+		audioDir := cfg.TTS.CacheDir
+		if audioDir == "" {
+			audioDir = filepath.Join(filepath.Dir(cfg.Database.Path), "audio")
+		}
+		// Audio is spoken again when the voice changes
+		voice := strings.Join([]string{cfg.TTS.Backend, cfg.TTS.Command, cfg.TTS.Model, cfg.TTS.Voice}, "|")
+		podcastAudio, err = tts.NewCache(audioDir, ttsEngine, voice)
+		if err != nil {
+			log.Printf("WARNING: Failed to initialize audio cache, podcast feeds disabled: %v", err)
+		} else {
+			log.Printf("Text-to-speech enabled with %s backend, audio cached at %s", cfg.TTS.Backend, audioDir)
+		}
+	}
 
 	// Create Plausible analytics store
 	plausibleStore := analytics.NewPlausibleStore(db, cfg.Plausible)
 
-	// Create settings store for persistent settings
-	settingsStore := settingsstore.New(db)
+	// Create settings store for persistent settings; secrets (API tokens) are
+	// encrypted with the same key as OAuth tokens
+	settingsStore := settingsstore.New(db).WithConfigValues(map[string]string{
+		entities.SettingKeyVocabularyAutoExtract:  strconv.FormatBool(cfg.Vocabulary.AutoExtract),
+		entities.SettingKeyMoonReaderDropboxPath:  cfg.MoonReader.DropboxPath,
+		entities.SettingKeyMoonReaderDatabasePath: cfg.MoonReader.DatabasePath,
+		entities.SettingKeyMoonReaderOutputDir:    cfg.MoonReader.OutputDir,
+	})
+	exporter.SetFilenameStyleFunc(settingsStore.GetExportFilenameStyle)
+	exporter.SetLanguageFunc(settingsStore.GetDefaultLanguage)
+	secretsEncryptor, err := tokenstore.NewEncryptor(tokenstore.Config{})
+	if err != nil {
+		log.Printf("WARNING: Settings encryption unavailable, API tokens cannot be saved in settings: %v", err)
+	} else {
+		settingsStore.WithEncryptor(secretsEncryptor)
+		if count, err := settingsStore.EncryptPlaintextSecrets(); err != nil {
+			log.Printf("WARNING: Failed to encrypt stored secrets: %v", err)
+		} else if count > 0 {
+			log.Printf("Encrypted %d secret settings stored as plaintext", count)
+		}
+	}
+
+	// Create dictionary client for vocabulary enrichment
+	dictClient, err := dictionary.NewClient(settingsStore.GetDictionaryProvider())
+	if err != nil {
+		log.Printf("WARNING: %v, using %s", err, dictionary.ProviderFreeDictionary)
+		dictClient = dictionary.NewFreeDictionaryClient()
+	}
+	dictClient = dictionary.WithEnabled(dictClient, settingsStore.GetDictionaryProviderEnabled)
 
 	// Create Obsidian sync scheduler
 	obsidianScheduler := scheduler.NewObsidianSyncScheduler(db, settingsStore, auditService)
+	obsidianScheduler.SetEventBroker(eventBroker)
+
+	// Two-way sync: notes edited in the exported files are saved back
+	vaultWatcher := scheduler.NewVaultWatcher(db, settingsStore, auditService)
+	vaultWatcher.SetEventBroker(eventBroker)
+	obsidianScheduler.SetVaultWatcher(vaultWatcher)
+
+	// Create scheduler for named export targets
+	exportTargetScheduler := scheduler.NewExportTargetScheduler(db, auditService)
+	exportTargetScheduler.SetEventBroker(eventBroker)
 
 	// Create Readwise client and sync scheduler
 	readwiseClient := readwise.NewClient()
 	readwiseSyncScheduler := scheduler.NewReadwiseSyncScheduler(db, settingsStore, readwiseClient, auditService)
+	readwiseSyncScheduler.SetEventBroker(eventBroker)
+
+	// Initialize Telegram bot (polls from the task runtime, reviews on a schedule)
+	telegramClient := telegram.NewClient()
+	telegramBot := telegram.NewBot(db, settingsStore, telegramClient)
+	telegramReviewScheduler := scheduler.NewTelegramReviewScheduler(settingsStore, telegramBot)
 
 	// Initialize OAuth2 token refresh scheduler
 	var oauth2Scheduler *oauth2.RefreshScheduler
@@ -253,8 +423,16 @@ func Run(cfg *config.Config, version string) {
 	// Initialize task queue if enabled
 	var taskClient *tasks.Client
 	var taskCtxCancel context.CancelFunc
+	// An unknown backend is reported even while tasks are disabled, rather
+	// than only once the queue is turned on
+	if _, err := tasks.ParseBackend(cfg.Tasks.Backend); err != nil {
+		log.Fatalf("Invalid TASKS_BACKEND: %v", err)
+	}
 	if cfg.Tasks.Enabled {
 		taskCfg := tasks.Config{
+			Backend:           cfg.Tasks.Backend,
+			DBPath:            cfg.Tasks.DBPath,
+			RedisURL:          cfg.Tasks.RedisURL,
 			Workers:           cfg.Tasks.Workers,
 			MaxRetries:        cfg.Tasks.MaxRetries,
 			RetryDelay:        cfg.Tasks.RetryDelay,
@@ -274,20 +452,94 @@ func Run(cfg *config.Config, version string) {
 			}
 		}()
 
-		// Register task queues
+		// Register task queues, announcing finished tasks
+		taskClient.SetEventBroker(eventBroker)
 		taskClient.Register(
-			tasks.NewEnrichBookQueue(metadataEnricher),
-			tasks.NewEnrichAllBooksQueue(metadataEnricher),
+			tasks.NewEnrichBookQueue(metadataEnricher, db),
+			tasks.NewEnrichAllBooksQueue(metadataEnricher, db),
+			tasks.NewEnrichAuthorsQueue(authorEnricher, db),
 			tasks.NewCleanupOrphanTagsQueue(db),
-			tasks.NewEnrichWordQueue(db, dictClient),
-			tasks.NewEnrichAllPendingWordsQueue(db, dictClient),
+			tasks.NewEnrichWordQueue(db, dictClient, taskClient),
+			tasks.NewEnrichAllPendingWordsQueue(db, dictClient, taskClient),
 			tasks.NewCleanupAuditEventsQueue(auditService),
+			tasks.NewExtractVocabularyQueue(db),
+			tasks.NewClassifyHighlightsQueue(db, highlightClassifier),
+			tasks.NewDetectBookLanguagesQueue(db),
+			tasks.NewPurgeTrashQueue(db),
+			tasks.NewDatabaseMaintenanceQueue(db),
 		)
 
+		// Detect the language of new books, enrich them and suggest vocabulary
+		// after imports, as toggled in settings, and classify new highlights
+		// when a classifier is configured
+		exporter.SetBooksSavedHook(func() {
+			if _, err := taskClient.Add(tasks.DetectBookLanguagesTask{}).Save(); err != nil {
+				log.Printf("WARNING: Failed to queue book language detection: %v", err)
+			}
+			if settingsStore.GetMetadataAutoEnrich() {
+				if _, err := taskClient.Add(tasks.EnrichAllBooksTask{}).Save(); err != nil {
+					log.Printf("WARNING: Failed to queue metadata enrichment: %v", err)
+				}
+				if _, err := taskClient.Add(tasks.EnrichAuthorsTask{}).Save(); err != nil {
+					log.Printf("WARNING: Failed to queue author enrichment: %v", err)
+				}
+			}
+			if settingsStore.GetVocabularyAutoExtract() {
+				if _, err := taskClient.Add(tasks.ExtractVocabularyTask{}).Save(); err != nil {
+					log.Printf("WARNING: Failed to queue vocabulary extraction: %v", err)
+				}
+			}
+			if highlightClassifier != nil {
+				if _, err := taskClient.Add(tasks.ClassifyHighlightsTask{}).Save(); err != nil {
+					log.Printf("WARNING: Failed to queue highlight classification: %v", err)
+				}
+			}
+		})
+
 		// Start task workers in background
 		var taskCtx context.Context
 		taskCtx, taskCtxCancel = context.WithCancel(context.Background())
 		go taskClient.Start(taskCtx)
+
+		// Long-poll Telegram for bot commands; idles until the bot is enabled in settings
+		go telegramBot.Run(taskCtx)
+
+		// Purge expired trash on startup and daily afterwards
+		if cfg.Trash.RetentionDays > 0 {
+			go func() {
+				ticker := time.NewTicker(24 * time.Hour)
+				defer ticker.Stop()
+				for {
+					if _, err := taskClient.Add(tasks.PurgeTrashTask{RetentionDays: cfg.Trash.RetentionDays}).Save(); err != nil {
+						log.Printf("WARNING: Failed to queue trash purge: %v", err)
+					}
+					select {
+					case <-taskCtx.Done():
+						return
+					case <-ticker.C:
+					}
+				}
+			}()
+		}
+
+		// Check and compact the database, and clean up orphan tags and expired
+		// sessions, on each interval; the first run waits so startup stays fast
+		if cfg.Maintenance.Interval > 0 {
+			go func() {
+				ticker := time.NewTicker(cfg.Maintenance.Interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-taskCtx.Done():
+						return
+					case <-ticker.C:
+					}
+					if _, err := taskClient.Add(tasks.DatabaseMaintenanceTask{}).Save(); err != nil {
+						log.Printf("WARNING: Failed to queue database maintenance: %v", err)
+					}
+				}
+			}()
+		}
 	}
 
 	// Initialize authentication if enabled
@@ -301,6 +553,10 @@ func Run(cfg *config.Config, version string) {
 
 		// Create auth service
 		authService = auth.NewService(db.DB, cfg.Auth)
+		if secretsEncryptor != nil {
+			// TOTP secrets are encrypted at rest like other secrets
+			authService.WithEncryptor(secretsEncryptor)
+		}
 
 		// Get underlying SQL DB for session store
 		sqlDB, err := db.DB.DB()
@@ -345,42 +601,99 @@ func Run(cfg *config.Config, version string) {
 
 	// Build router configuration with all dependencies
 	routerCfg := http_controllers.RouterConfig{
-		BookReader:             exporter,
-		BookExporter:           exporter,
-		Database:               db,
-		AuditService:           auditService,
-		TagStore:               db,
-		DeleteStore:            db,
-		FavouritesStore:        db,
-		VocabularyStore:        db,
-		DictionaryClient:       dictClient,
-		ReadwiseToken:          cfg.Readwise.Token,
-		TemplatesPath:          cfg.UI.TemplatesPath,
-		StaticPath:             cfg.UI.StaticPath,
-		DatabasePath:           cfg.Database.Path,
-		DropboxAppKey:          cfg.Dropbox.AppKey,
-		MoonReaderDropboxPath:  cfg.MoonReader.DropboxPath,
-		MoonReaderDatabasePath: cfg.MoonReader.DatabasePath,
-		MoonReaderOutputDir:    cfg.MoonReader.OutputDir,
-		Version:                version,
-		MetadataEnricher:       metadataEnricher,
-		SyncProgress:           syncProgress,
-		CoverCache:             coverCache,
-		TaskClient:             taskClient,
-		TaskWorkers:            cfg.Tasks.Workers,
-		AuthService:            authService,
-		AuthMiddleware:         authMiddleware,
-		SessionManager:         sessionManager,
-		AuthConfig:             cfg.Auth,
-		CSRFSecret:             csrfSecret,
-		SecureCookies:          cfg.Auth.SecureCookies,
-		DemoMiddleware:         demoMiddleware,
-		PlausibleStore:         plausibleStore,
-		PlausibleConfig:        cfg.Plausible,
-		SettingsStore:          settingsStore,
-		ObsidianSyncScheduler:  obsidianScheduler,
-		ReadwiseSyncScheduler:  readwiseSyncScheduler,
-		ReadwiseClient:         readwiseClient,
+		BookReader:              exporter,
+		BookExporter:            exporter,
+		Database:                db,
+		AuditService:            auditService,
+		BookDetailsStore:        db,
+		BookHighlightStore:      db,
+		BookListStore:           db,
+		BookArchiveStore:        db,
+		BookNoteStore:           db,
+		CitationStore:           db,
+		BookEditStore:           db,
+		TagStore:                db,
+		DeleteStore:             db,
+		FavouritesStore:         db,
+		VocabularyStore:         db,
+		VocabularyReviewStore:   db,
+		VocabularyMergeStore:    db,
+		HighlightListStore:      db,
+		HighlightHistoryStore:   db,
+		HighlightDuplicateStore: db,
+		NoteStore:               db,
+		HighlightLinkStore:      db,
+		QuoteCardStore:          db,
+		PublicLibraryStore:      db,
+		GraphQLStore:            db,
+		UpgradeStatusStore:      db,
+		MaintenanceStore:        db,
+		TrashStore:              db,
+		MetadataReviewStore:     db,
+		TombstoneStore:          db,
+		LibraryDiffStore:        db,
+		LibraryImportStore:      db,
+		TagCSVStore:             db,
+		ManualBookStore:         db,
+		CaptureStore:            db,
+		AuthorStore:             db,
+		SeriesStore:             db,
+		CollectionStore:         db,
+		BookSourceStore:         db,
+		SavedViewStore:          db,
+		UIPreferencesStore:      db,
+		QuickSearchStore:        db,
+		TrashRetentionDays:      cfg.Trash.RetentionDays,
+		DictionaryClient:        dictClient,
+		ReadwiseToken:           cfg.Readwise.Token,
+		TemplatesPath:           cfg.UI.TemplatesPath,
+		StaticPath:              cfg.UI.StaticPath,
+		DatabasePath:            cfg.Database.Path,
+		TrustedProxies:          cfg.HTTP.TrustedProxies,
+		BasePath:                cfg.HTTP.BasePath,
+		DropboxAppKey:           cfg.Dropbox.AppKey,
+		MoonReaderDropboxPath:   cfg.MoonReader.DropboxPath,
+		MoonReaderDatabasePath:  cfg.MoonReader.DatabasePath,
+		MoonReaderOutputDir:     cfg.MoonReader.OutputDir,
+		MoonReaderWebDAVDir:     cfg.MoonReader.WebDAVDir,
+		Version:                 version,
+		MetadataEnricher:        metadataEnricher,
+		AuthorEnricher:          authorEnricher,
+		SyncProgress:            syncProgress,
+		CoverCache:              coverCache,
+		UploadStore:             uploadStore,
+		MaxRequestSize:          int64(cfg.Uploads.MaxRequestMB) * 1024 * 1024,
+		MaxJSONImportSize:       int64(cfg.Uploads.MaxJSONImportMB) * 1024 * 1024,
+		MaxBackupSize:           int64(cfg.Uploads.MaxBackupMB) * 1024 * 1024,
+		OCREngine:               ocrEngine,
+		OCRMaxImageSize:         int64(cfg.OCR.MaxImageSizeMB) * 1024 * 1024,
+		OCRTimeout:              cfg.OCR.Timeout,
+		PodcastStore:            db,
+		PodcastAudio:            podcastAudio,
+		TaskClient:              taskClient,
+		ReenrichStore:           db,
+		SyncLockStore:           db,
+		DemoSeeder:              demo.NewSeeder(db),
+		TaskWorkers:             cfg.Tasks.Workers,
+		EventBroker:             eventBroker,
+		AuthService:             authService,
+		AuthMiddleware:          authMiddleware,
+		SessionManager:          sessionManager,
+		AuthConfig:              cfg.Auth,
+		CSRFSecret:              csrfSecret,
+		SecureCookies:           cfg.Auth.SecureCookies,
+		DemoMiddleware:          demoMiddleware,
+		PlausibleStore:          plausibleStore,
+		PlausibleConfig:         cfg.Plausible,
+		SettingsStore:           settingsStore,
+		ObsidianSyncScheduler:   obsidianScheduler,
+		VaultWatcher:            vaultWatcher,
+		ExportTargetStore:       db,
+		ExportTargetScheduler:   exportTargetScheduler,
+		ReadwiseSyncScheduler:   readwiseSyncScheduler,
+		ReadwiseClient:          readwiseClient,
+		TelegramReviewScheduler: telegramReviewScheduler,
+		TelegramClient:          telegramClient,
 	}
 
 	router := http_controllers.NewRouter(routerCfg)
@@ -390,11 +703,26 @@ func Run(cfg *config.Config, version string) {
 		log.Printf("WARNING: Failed to start Obsidian sync scheduler: %v", err)
 	}
 
+	// Start watching the vault for edited notes if two-way sync is enabled
+	if err := vaultWatcher.Start(context.Background()); err != nil {
+		log.Printf("WARNING: Failed to start vault watcher: %v", err)
+	}
+
+	// Start scheduled export targets
+	if err := exportTargetScheduler.Start(context.Background()); err != nil {
+		log.Printf("WARNING: Failed to start export targets scheduler: %v", err)
+	}
+
 	// Start Readwise sync scheduler if enabled
 	if err := readwiseSyncScheduler.Start(context.Background()); err != nil {
 		log.Printf("WARNING: Failed to start Readwise sync scheduler: %v", err)
 	}
 
+	// Start Telegram daily review scheduler if enabled
+	if err := telegramReviewScheduler.Start(context.Background()); err != nil {
+		log.Printf("WARNING: Failed to start Telegram review scheduler: %v", err)
+	}
+
 	// Start OAuth2 token refresh scheduler
 	var oauth2Ctx context.Context
 	var oauth2Cancel context.CancelFunc
@@ -405,22 +733,50 @@ func Run(cfg *config.Config, version string) {
 
 	// Shutdown callback for graceful cleanup
 	onShutdown := func(ctx context.Context) {
+		// Interrupt running backfills; they resume on the next start
+		backfillCancel()
+
 		// Stop Obsidian sync scheduler
 		obsidianScheduler.Stop()
 
+		// Stop vault watcher
+		vaultWatcher.Stop()
+
+		// Stop export targets scheduler
+		exportTargetScheduler.Stop()
+
 		// Stop Readwise sync scheduler
 		readwiseSyncScheduler.Stop()
 
+		// Stop Telegram review scheduler
+		telegramReviewScheduler.Stop()
+
 		// Stop OAuth2 token refresh scheduler
 		if oauth2Scheduler != nil && oauth2Cancel != nil {
 			oauth2Scheduler.Stop()
 			oauth2Cancel()
 		}
 
+		// Wait for running tasks until the shutdown deadline; tasks cut short
+		// stay in the task database and run again after the restart
 		if taskClient != nil && taskCtxCancel != nil {
-			taskClient.Stop(ctx)
+			if !taskClient.Stop(ctx) {
+				log.Printf("WARNING: Background tasks still running at the shutdown deadline were interrupted")
+			}
 			taskCtxCancel()
 		}
+
+		heartbeatCancel()
+
+		// Leave no sync marked running; an interrupted metadata sync resumes
+		// after its last finished book. Re-enrichment runs as queued tasks that
+		// survive the restart, so it stays running.
+		if interrupted, err := db.InterruptSyncProgress(entities.SyncTypeMetadata, "interrupted by shutdown"); err != nil {
+			log.Printf("WARNING: Failed to checkpoint metadata sync: %v", err)
+		} else if interrupted {
+			log.Printf("Metadata sync interrupted by shutdown; it resumes on the next run")
+		}
+
 		if demoCleanup != nil {
 			demoCleanup()
 		}
@@ -0,0 +1,103 @@
+// Package events broadcasts application events, such as import progress,
+// finished background tasks and sync updates, to live subscribers like the
+// Server-Sent Events stream of the web UI.
+//
+// Events are not stored: a subscriber only receives events published while it
+// is subscribed, and a subscriber that falls behind misses events rather than
+// blocking the publisher.
+//
+// # Usage
+//
+//	broker := events.NewBroker()
+//	ch, unsubscribe := broker.Subscribe()
+//	defer unsubscribe()
+//
+//	broker.Publish(events.TypeImportCompleted, progress)
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types. The prefix before the dot names the area, so subscribers can
+// filter by it.
+const (
+	TypeImportProgress   = "import.progress"
+	TypeImportCompleted  = "import.completed"
+	TypeHighlightCreated = "highlight.created"
+	TypeTaskCompleted    = "task.completed"
+	TypeTaskFailed       = "task.failed"
+	TypeSyncProgress     = "sync.progress"
+	TypeSyncCompleted    = "sync.completed"
+)
+
+// subscriberBuffer is how many events a subscriber can fall behind before
+// further events are dropped for it
+const subscriberBuffer = 64
+
+// Event is one published event.
+type Event struct {
+	Type string    `json:"type"`
+	Data any       `json:"data"`
+	Time time.Time `json:"time"`
+}
+
+// Broker fans published events out to every subscriber. A nil *Broker is
+// valid and discards events, so publishers need not check whether live
+// updates are enabled.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[chan Event]struct{})}
+}
+
+// Publish sends an event to all subscribers without waiting for them.
+func (b *Broker) Publish(eventType string, data any) {
+	if b == nil {
+		return
+	}
+	event := Event{Type: eventType, Data: data, Time: time.Now()}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default: // Subscriber is not keeping up
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every event published from now on,
+// and a function that ends the subscription and closes the channel.
+func (b *Broker) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// HasSubscribers reports whether anyone is listening, so publishers can skip
+// building costly payloads.
+func (b *Broker) HasSubscribers() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers) > 0
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker_PublishSubscribe(t *testing.T) {
+	broker := NewBroker()
+	assert.False(t, broker.HasSubscribers())
+
+	first, unsubscribeFirst := broker.Subscribe()
+	second, unsubscribeSecond := broker.Subscribe()
+	defer unsubscribeSecond()
+	assert.True(t, broker.HasSubscribers())
+
+	broker.Publish(TypeImportCompleted, map[string]int{"books": 2})
+
+	for _, ch := range []<-chan Event{first, second} {
+		event := <-ch
+		assert.Equal(t, TypeImportCompleted, event.Type)
+		assert.Equal(t, map[string]int{"books": 2}, event.Data)
+		assert.False(t, event.Time.IsZero())
+	}
+
+	unsubscribeFirst()
+	unsubscribeFirst() // Safe to call twice
+	_, open := <-first
+	assert.False(t, open, "unsubscribing closes the channel")
+
+	broker.Publish(TypeTaskCompleted, nil)
+	event := <-second
+	assert.Equal(t, TypeTaskCompleted, event.Type)
+}
+
+func TestBroker_SlowSubscriberDoesNotBlock(t *testing.T) {
+	broker := NewBroker()
+	ch, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer*2; i++ {
+		broker.Publish(TypeSyncProgress, i)
+	}
+	require.Len(t, ch, subscriberBuffer)
+	assert.Equal(t, 0, (<-ch).Data, "the oldest events are kept")
+}
+
+func TestBroker_Nil(t *testing.T) {
+	var broker *Broker
+	broker.Publish(TypeImportProgress, nil)
+	assert.False(t, broker.HasSubscribers())
+}
@@ -0,0 +1,240 @@
+package exporters
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// Citation formats for reference managers
+const (
+	CitationBibTeX  = "bibtex"   // BibTeX @book entries, for LaTeX
+	CitationCSLJSON = "csl-json" // CSL-JSON items, for Zotero, Pandoc and Citation Style Language processors
+)
+
+// CitationFormats lists the supported citation formats
+var CitationFormats = []string{CitationBibTeX, CitationCSLJSON}
+
+// citationAuthorSeparators split a book's author field into its authors.
+// Commas are not among them, since "Tolkien, J. R. R." is a single author.
+var citationAuthorSeparators = []string{";", " & ", " and "}
+
+// bibTeXEscaper escapes the characters LaTeX treats specially
+var bibTeXEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"&", `\&`,
+	"%", `\%`,
+	"$", `\$`,
+	"#", `\#`,
+	"_", `\_`,
+	"~", `\textasciitilde{}`,
+	"^", `\textasciicircum{}`,
+)
+
+// citationName is an author's name split the way citation styles need it
+type citationName struct {
+	Family string `json:"family,omitempty"`
+	Given  string `json:"given,omitempty"`
+}
+
+// cslItem is a CSL-JSON item, as in https://citeproc-js.readthedocs.io/en/latest/csl-json/markup.html
+type cslItem struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Author    []citationName `json:"author,omitempty"`
+	Publisher string         `json:"publisher,omitempty"`
+	Issued    *cslDate       `json:"issued,omitempty"`
+	ISBN      string         `json:"ISBN,omitempty"`
+	Series    string         `json:"collection-title,omitempty"`
+	Number    string         `json:"collection-number,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// GenerateCitations renders citation entries for books in format
+func GenerateCitations(format string, books []entities.Book) (string, error) {
+	switch format {
+	case CitationBibTeX, "":
+		return GenerateBibTeX(books), nil
+	case CitationCSLJSON:
+		return GenerateCSLJSON(books)
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+// GenerateBibTeX renders a BibTeX @book entry per book, with keys made
+// unique across the books.
+func GenerateBibTeX(books []entities.Book) string {
+	var builder strings.Builder
+	keys := citationKeys(books)
+	for i, book := range books {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		fmt.Fprintf(&builder, "@book{%s,\n", keys[i])
+		writeBibTeXField(&builder, "author", bibTeXAuthors(book.Author))
+		writeBibTeXField(&builder, "title", book.Title)
+		writeBibTeXField(&builder, "publisher", book.Publisher)
+		if book.PublicationYear > 0 {
+			writeBibTeXField(&builder, "year", strconv.Itoa(book.PublicationYear))
+		}
+		writeBibTeXField(&builder, "isbn", book.ISBN)
+		writeBibTeXField(&builder, "series", book.Series)
+		if book.Series != "" && book.SeriesIndex > 0 {
+			writeBibTeXField(&builder, "number", strconv.FormatFloat(book.SeriesIndex, 'f', -1, 64))
+		}
+		builder.WriteString("}\n")
+	}
+	return builder.String()
+}
+
+func writeBibTeXField(builder *strings.Builder, name, value string) {
+	if value = strings.TrimSpace(value); value == "" {
+		return
+	}
+	fmt.Fprintf(builder, "  %s = {%s},\n", name, bibTeXEscaper.Replace(value))
+}
+
+// bibTeXAuthors joins a book's authors as "Family, Given and Family, Given"
+func bibTeXAuthors(author string) string {
+	names := citationAuthors(author)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		if name.Given == "" {
+			parts = append(parts, name.Family)
+			continue
+		}
+		parts = append(parts, name.Family+", "+name.Given)
+	}
+	return strings.Join(parts, " and ")
+}
+
+// GenerateCSLJSON renders the books as a CSL-JSON array, with the same
+// unique keys as the BibTeX entries as item IDs.
+func GenerateCSLJSON(books []entities.Book) (string, error) {
+	keys := citationKeys(books)
+	items := make([]cslItem, 0, len(books))
+	for i, book := range books {
+		item := cslItem{
+			ID:        keys[i],
+			Type:      "book",
+			Title:     book.Title,
+			Author:    citationAuthors(book.Author),
+			Publisher: book.Publisher,
+			ISBN:      book.ISBN,
+			Series:    book.Series,
+		}
+		if book.PublicationYear > 0 {
+			item.Issued = &cslDate{DateParts: [][]int{{book.PublicationYear}}}
+		}
+		if book.Series != "" && book.SeriesIndex > 0 {
+			item.Number = strconv.FormatFloat(book.SeriesIndex, 'f', -1, 64)
+		}
+		items = append(items, item)
+	}
+
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// citationAuthors splits a book's author field into names. "Family, Given"
+// is kept as written; otherwise the last word is taken as the family name.
+func citationAuthors(author string) []citationName {
+	authors := []string{author}
+	for _, separator := range citationAuthorSeparators {
+		var split []string
+		for _, a := range authors {
+			split = append(split, strings.Split(a, separator)...)
+		}
+		authors = split
+	}
+
+	var names []citationName
+	for _, a := range authors {
+		a = strings.Join(strings.Fields(a), " ")
+		if a == "" {
+			continue
+		}
+		if family, given, ok := strings.Cut(a, ","); ok {
+			names = append(names, citationName{Family: strings.TrimSpace(family), Given: strings.TrimSpace(given)})
+			continue
+		}
+		if i := strings.LastIndex(a, " "); i > 0 {
+			names = append(names, citationName{Family: a[i+1:], Given: a[:i]})
+			continue
+		}
+		names = append(names, citationName{Family: a})
+	}
+	return names
+}
+
+// citationKeys returns a key per book, such as "herbert1965dune", from the
+// first author's family name, the year and the first word of the title.
+// Books that would share a key get a, b, c... appended.
+func citationKeys(books []entities.Book) []string {
+	keys := make([]string, len(books))
+	counts := make(map[string]int, len(books))
+	for i, book := range books {
+		var key strings.Builder
+		if names := citationAuthors(book.Author); len(names) > 0 {
+			key.WriteString(citationKeyPart(names[0].Family))
+		}
+		if book.PublicationYear > 0 {
+			key.WriteString(strconv.Itoa(book.PublicationYear))
+		}
+		for _, word := range strings.Fields(book.Title) {
+			if part := citationKeyPart(word); part != "" {
+				key.WriteString(part)
+				break
+			}
+		}
+		keys[i] = key.String()
+		if keys[i] == "" {
+			keys[i] = fmt.Sprintf("book%d", book.ID)
+		}
+		counts[keys[i]]++
+	}
+
+	seen := make(map[string]int, len(counts))
+	for i, key := range keys {
+		if counts[key] < 2 {
+			continue
+		}
+		keys[i] = key + citationKeySuffix(seen[key])
+		seen[key]++
+	}
+	return keys
+}
+
+// citationKeyPart lowercases a word and drops everything but letters and
+// digits, such as the punctuation and braces BibTeX keys cannot contain.
+func citationKeyPart(word string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// citationKeySuffix returns a, b, ..., z, aa, ab... for the nth duplicate key
+func citationKeySuffix(n int) string {
+	suffix := string(rune('a' + n%26))
+	for n /= 26; n > 0; n = n/26 - 1 {
+		suffix = string(rune('a'+(n-1)%26)) + suffix
+	}
+	return suffix
+}
@@ -0,0 +1,64 @@
+package exporters
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestGenerateBibTeX(t *testing.T) {
+	books := []entities.Book{
+		{Title: "Dune", Author: "Frank Herbert", Publisher: "Chilton Books", PublicationYear: 1965, ISBN: "9780441013593", Series: "Dune", SeriesIndex: 1},
+		{Title: "The Pragmatic Programmer", Author: "Andrew Hunt & David Thomas", Publisher: "Addison-Wesley"},
+		{Title: "The Hobbit", Author: "Tolkien, J. R. R.", PublicationYear: 1937},
+		{Title: "Dune Messiah", Author: "Frank Herbert", PublicationYear: 1965},
+		{Title: "100% Wrong_Title {draft}", Author: "Anonymous"},
+	}
+
+	bibtex := GenerateBibTeX(books)
+
+	assert.Contains(t, bibtex, "@book{herbert1965dunea,\n"+
+		"  author = {Herbert, Frank},\n"+
+		"  title = {Dune},\n"+
+		"  publisher = {Chilton Books},\n"+
+		"  year = {1965},\n"+
+		"  isbn = {9780441013593},\n"+
+		"  series = {Dune},\n"+
+		"  number = {1},\n"+
+		"}\n")
+	assert.Contains(t, bibtex, "@book{herbert1965duneb,\n", "keys are unique")
+	assert.Contains(t, bibtex, "  author = {Hunt, Andrew and Thomas, David},\n")
+	assert.Contains(t, bibtex, "@book{tolkien1937the,\n  author = {Tolkien, J. R. R.},\n")
+	assert.Contains(t, bibtex, `  title = {100\% Wrong\_Title \{draft\}},`)
+	assert.NotContains(t, bibtex, "year = {0}")
+}
+
+func TestGenerateCSLJSON(t *testing.T) {
+	content, err := GenerateCSLJSON([]entities.Book{
+		{Title: "Sapiens", Author: "Yuval Noah Harari", Publisher: "Harper", PublicationYear: 2014, ISBN: "9780062316097"},
+		{Title: "Untitled notes"},
+	})
+	require.NoError(t, err)
+
+	var items []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(content), &items))
+	require.Len(t, items, 2)
+	assert.Equal(t, map[string]any{
+		"id":        "harari2014sapiens",
+		"type":      "book",
+		"title":     "Sapiens",
+		"author":    []any{map[string]any{"family": "Harari", "given": "Yuval Noah"}},
+		"publisher": "Harper",
+		"issued":    map[string]any{"date-parts": []any{[]any{float64(2014)}}},
+		"ISBN":      "9780062316097",
+	}, items[0])
+	assert.Equal(t, "untitled", items[1]["id"])
+	assert.NotContains(t, items[1], "author")
+
+	_, err = GenerateCitations("ris", nil)
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -6,11 +6,16 @@ import (
 
 	"github.com/mrlokans/assistant/internal/database"
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
 )
 
 type DatabaseMarkdownExporter struct {
 	db               *database.Database
 	markdownExporter *MarkdownExporter
+	booksSavedHook   func()
+	filenameStyle    func() string
+	language         func() string
+	events           *events.Broker
 }
 
 func NewDatabaseMarkdownExporter(db *database.Database, exportDir string) *DatabaseMarkdownExporter {
@@ -20,24 +25,90 @@ func NewDatabaseMarkdownExporter(db *database.Database, exportDir string) *Datab
 	}
 }
 
+// SetBooksSavedHook registers a callback invoked after an export saved at least one book.
+// Used to trigger follow-up processing of newly imported highlights.
+func (exporter *DatabaseMarkdownExporter) SetBooksSavedHook(hook func()) {
+	exporter.booksSavedHook = hook
+}
+
+// SetFilenameStyleFunc registers where the file name style of markdown exports
+// comes from. It is read on every export, so a changed setting applies at once.
+func (exporter *DatabaseMarkdownExporter) SetFilenameStyleFunc(style func() string) {
+	exporter.filenameStyle = style
+}
+
+// SetLanguageFunc registers where the language of the index files comes
+// from. Like the file name style, it is read on every export.
+func (exporter *DatabaseMarkdownExporter) SetLanguageFunc(language func() string) {
+	exporter.language = language
+}
+
+func (exporter *DatabaseMarkdownExporter) applySettings() {
+	if exporter.filenameStyle != nil {
+		exporter.markdownExporter.SetFilenameStyle(exporter.filenameStyle())
+	}
+	if exporter.language != nil {
+		exporter.markdownExporter.SetLanguage(exporter.language())
+	}
+}
+
+// SetFilter sets the filter applied to the markdown files written by later
+// exports. Books are always saved to the database in full.
+func (exporter *DatabaseMarkdownExporter) SetFilter(filter ExportFilter) {
+	exporter.markdownExporter.SetFilter(filter)
+}
+
 func (exporter *DatabaseMarkdownExporter) Export(books []entities.Book) (ExportResult, error) {
-	result := ExportResult{}
+	return exporter.ExportFiles(books, nil)
+}
 
-	// First, save all books to the database
-	for i := range books {
-		book := &books[i]
-		err := exporter.db.SaveBook(book)
-		if err != nil {
-			log.Printf("Failed to save book '%s' by %s to database: %v", book.Title, book.Author, err)
-			result.BooksFailed++
-			continue
+// ExportFiles exports the books read from several files as one import,
+// recording the per-file results with its import session.
+func (exporter *DatabaseMarkdownExporter) ExportFiles(books []entities.Book, files []entities.ImportFileResult) (result ExportResult, err error) {
+	var userID uint
+	if len(books) > 0 {
+		userID = books[0].UserID
+	}
+
+	release, err := exporter.LockImports()
+	if err != nil {
+		return result, err
+	}
+	defer release()
+
+	// Recorded as an import session, with progress published as books are saved
+	run := exporter.BeginImport(userID, len(books))
+	run.SetFiles(files)
+	defer func() { run.Finish(result, err) }()
+
+	// First, save all books to the database in one transaction
+	original := cloneBooks(books)
+	if err := exporter.db.SaveBooks(books); err != nil {
+		// Nothing of the batch was stored; save the books one by one, as they
+		// were before the failed attempt, to report the failing ones and keep the rest
+		log.Printf("Failed to save %d books to database at once, saving them one by one: %v", len(books), err)
+		copy(books, original)
+		for i := range books {
+			book := &books[i]
+			if err := exporter.db.SaveBook(book); err != nil {
+				log.Printf("Failed to save book '%s' by %s to database: %v", book.Title, book.Author, err)
+				result.BooksFailed++
+				continue
+			}
+			exporter.recordSaved(run, &result, book)
+		}
+	} else {
+		for i := range books {
+			exporter.recordSaved(run, &result, &books[i])
 		}
-		result.BooksProcessed++
-		result.HighlightsProcessed += len(book.Highlights)
-		log.Printf("Successfully saved book '%s' by %s to database with ID %d", book.Title, book.Author, book.ID)
+	}
+
+	if result.BooksProcessed > 0 && exporter.booksSavedHook != nil {
+		exporter.booksSavedHook()
 	}
 
 	// Then export to markdown files (skip if export dir not configured)
+	exporter.applySettings()
 	markdownResult, err := exporter.markdownExporter.Export(books)
 	if err != nil {
 		// If export directory is not configured, just log a warning and continue
@@ -55,6 +126,8 @@ func (exporter *DatabaseMarkdownExporter) Export(books []entities.Book) (ExportR
 		if markdownResult.HighlightsFailed > 0 {
 			result.HighlightsFailed += markdownResult.HighlightsFailed
 		}
+		result.BooksSkipped = markdownResult.BooksSkipped
+		result.HighlightsSkipped = markdownResult.HighlightsSkipped
 	}
 
 	log.Printf("Export completed: %d books processed, %d highlights processed, %d books failed, %d highlights failed",
@@ -63,6 +136,84 @@ func (exporter *DatabaseMarkdownExporter) Export(books []entities.Book) (ExportR
 	return result, nil
 }
 
+// recordSaved counts a book saved by an export and publishes the progress.
+func (exporter *DatabaseMarkdownExporter) recordSaved(run *ImportRun, result *ExportResult, book *entities.Book) {
+	result.BooksProcessed++
+	result.HighlightsProcessed += len(book.Highlights)
+	log.Printf("Successfully saved book '%s' by %s to database with ID %d", book.Title, book.Author, book.ID)
+	run.setSource(book.SourceID)
+	run.Progress(*result)
+}
+
+// cloneBooks copies books and their highlights, which saving modifies in place.
+func cloneBooks(books []entities.Book) []entities.Book {
+	clone := make([]entities.Book, len(books))
+	for i, book := range books {
+		book.Highlights = append([]entities.Highlight(nil), book.Highlights...)
+		clone[i] = book
+	}
+	return clone
+}
+
+// LockImports takes the import lock shared by every process using the
+// database, waiting while another import runs, so concurrent imports never
+// merge into the same books at once. Call release once the import is done.
+// Export takes the lock itself; streamed imports hold it for the whole stream.
+func (exporter *DatabaseMarkdownExporter) LockImports() (release func(), err error) {
+	lock, err := exporter.db.AcquireLock(entities.LockImport, "import")
+	if err != nil {
+		return nil, err
+	}
+	return lock.Release, nil
+}
+
+// SaveBatch saves one batch of a streamed import to the database in a single
+// transaction. Markdown is written by ExportSaved once the whole stream is saved,
+// since a book can be spread over several batches.
+func (exporter *DatabaseMarkdownExporter) SaveBatch(books []entities.Book) (ExportResult, error) {
+	if err := exporter.db.SaveBooks(books); err != nil {
+		return ExportResult{BooksFailed: len(books)}, err
+	}
+
+	result := ExportResult{BooksProcessed: len(books)}
+	for _, book := range books {
+		result.HighlightsProcessed += len(book.Highlights)
+	}
+	return result, nil
+}
+
+// ExportSaved writes markdown for books saved by SaveBatch, reading them back
+// from the database one at a time so each file has all of the book's highlights.
+// The index files are regenerated once at the end.
+func (exporter *DatabaseMarkdownExporter) ExportSaved(bookIDs []uint) error {
+	if len(bookIDs) > 0 && exporter.booksSavedHook != nil {
+		exporter.booksSavedHook()
+	}
+
+	exporter.applySettings()
+	for _, id := range bookIDs {
+		book, err := exporter.db.GetBookByID(id)
+		if err != nil {
+			return fmt.Errorf("failed to load book %d for markdown export: %w", id, err)
+		}
+		if _, err := exporter.markdownExporter.export([]entities.Book{*book}); err != nil {
+			if err == ErrExportDirNotConfigured {
+				log.Printf("Markdown export skipped: export directory not configured")
+				return nil
+			}
+			return fmt.Errorf("failed to export to markdown: %w", err)
+		}
+	}
+	if len(bookIDs) > 0 {
+		if err := exporter.markdownExporter.WriteIndex(); err != nil {
+			return fmt.Errorf("failed to write markdown index: %w", err)
+		}
+	}
+
+	log.Printf("Streamed import completed: %d books saved", len(bookIDs))
+	return nil
+}
+
 // GetAllBooks retrieves all books from the database.
 // Implements BookReader interface.
 func (exporter *DatabaseMarkdownExporter) GetAllBooks() ([]entities.Book, error) {
@@ -0,0 +1,87 @@
+package exporters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// setNotes records the notes written into a markdown file, so a vault sync can
+// tell notes edited in the file from notes edited in the app since the export
+func (f *exportFiles) setNotes(outputPath string, highlights []entities.Highlight) {
+	rel, err := filepath.Rel(f.exportDir, outputPath)
+	if err != nil {
+		return
+	}
+	notes := make(map[string]string, len(highlights))
+	for _, h := range highlights {
+		notes[strings.TrimSpace(h.Text)] = strings.TrimSpace(h.Note)
+	}
+	if f.manifest.Notes == nil {
+		f.manifest.Notes = make(map[string]map[string]string)
+	}
+	f.manifest.Notes[filepath.ToSlash(rel)] = notes
+}
+
+// ExportedNotes returns the notes written by the markdown exports to exportDir,
+// by file path relative to exportDir (with forward slashes), then by trimmed
+// highlight text. Files exported before notes were recorded are missing.
+func ExportedNotes(exportDir string) (map[string]map[string]string, error) {
+	manifest, err := readManifest(exportDir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Notes == nil {
+		manifest.Notes = make(map[string]map[string]string)
+	}
+	return manifest.Notes, nil
+}
+
+// UpdateExportedNotes records notes as exported for the given highlights, after
+// a vault sync copied notes edited in the files into the database. Only files
+// whose notes were recorded by an export are updated. The caller holds the
+// export lock.
+func UpdateExportedNotes(exportDir string, notes map[string]map[string]string) error {
+	manifest, err := readManifest(exportDir)
+	if err != nil {
+		return err
+	}
+	updated := false
+	for rel, byText := range notes {
+		recorded, ok := manifest.Notes[rel]
+		if !ok {
+			continue
+		}
+		for text, note := range byText {
+			recorded[text] = note
+			updated = true
+		}
+	}
+	if !updated {
+		return nil
+	}
+	files := &exportFiles{exportDir: exportDir, manifest: manifest}
+	return files.save()
+}
+
+// readManifest reads the manifest of an export directory; a directory without
+// one has an empty manifest
+func readManifest(exportDir string) (exportManifest, error) {
+	var manifest exportManifest
+	data, err := os.ReadFile(filepath.Join(exportDir, manifestFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return manifest, fmt.Errorf("failed to read export manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("failed to parse export manifest %s: %w", manifestFileName, err)
+	}
+	return manifest, nil
+}
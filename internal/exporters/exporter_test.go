@@ -9,6 +9,7 @@ import (
 
 	"github.com/mrlokans/assistant/internal/database"
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -99,6 +100,23 @@ func TestGenerateMarkdown(t *testing.T) {
 		assert.Contains(t, markdown, "**Note:** My personal note")
 	})
 
+	t.Run("keeps multi-line markdown notes inside the callout", func(t *testing.T) {
+		book := &entities.Book{
+			Title:  "Notes Book",
+			Author: "Author",
+			Highlights: []entities.Highlight{
+				{
+					Text: "Highlighted text",
+					Note: "First thought\n\n- point one\n- point **two**",
+				},
+			},
+		}
+
+		markdown := GenerateMarkdown(book)
+
+		assert.Contains(t, markdown, "> **Note:** First thought\n> \n> - point one\n> - point **two**\n")
+	})
+
 	t.Run("escapes quotes in title and author", func(t *testing.T) {
 		book := &entities.Book{
 			Title:  `Book with "Quotes"`,
@@ -146,6 +164,123 @@ func TestGenerateMarkdown(t *testing.T) {
 		assert.Contains(t, markdown, "> Third highlight")
 	})
 
+	t.Run("lists highlight colors in frontmatter", func(t *testing.T) {
+		book := &entities.Book{
+			Title:  "Color Book",
+			Author: "Author",
+			Highlights: []entities.Highlight{
+				{Text: "Action item", Color: "#FF0000FF"},
+				{Text: "Quote", Color: "#FFFF00"},
+				{Text: "Another quote", Color: "yellow"},
+				{Text: "No color"},
+			},
+		}
+
+		markdown := GenerateMarkdown(book)
+
+		assert.Contains(t, markdown, "colors: [yellow, blue]\n")
+	})
+
+	t.Run("includes rating and read date when set", func(t *testing.T) {
+		dateRead := time.Date(2023, 3, 14, 0, 0, 0, 0, time.UTC)
+		book := &entities.Book{
+			Title:    "Rated Book",
+			Author:   "Author",
+			Rating:   4.5,
+			DateRead: &dateRead,
+		}
+
+		markdown := GenerateMarkdown(book)
+
+		assert.Contains(t, markdown, "rating: 4.5\n")
+		assert.Contains(t, markdown, "date_read: 2023-03-14\n")
+	})
+
+	t.Run("includes series when set", func(t *testing.T) {
+		book := &entities.Book{
+			Title:       "Foundation and Empire",
+			Author:      "Isaac Asimov",
+			Series:      `The "Foundation" Series`,
+			SeriesIndex: 2,
+		}
+
+		markdown := GenerateMarkdown(book)
+
+		assert.Contains(t, markdown, "series: \"The \\\"Foundation\\\" Series\"\n")
+		assert.Contains(t, markdown, "series_index: 2\n")
+		assert.NotContains(t, GenerateMarkdown(&entities.Book{Title: "Standalone", Author: "Author"}), "series")
+	})
+
+	t.Run("renders highlight links as wikilinks", func(t *testing.T) {
+		target := &entities.Highlight{ID: 7, Text: "Virtue is the only good", Book: entities.Book{Title: "Meditations: Book I"}}
+		book := &entities.Book{
+			Title:  "Letters",
+			Author: "Seneca",
+			Highlights: []entities.Highlight{
+				{ID: 3, Text: "Live according to nature", Links: []entities.HighlightLink{
+					{Type: entities.HighlightLinkSupports, To: target},
+					{Type: entities.HighlightLinkSeeAlso, To: nil}, // Target in the trash
+				}},
+				{ID: 4, Text: "Linked to", Backlinks: []entities.HighlightLink{{FromHighlightID: 9, ToHighlightID: 4}}},
+			},
+		}
+
+		markdown := GenerateMarkdown(book)
+
+		assert.Contains(t, markdown, "> Supports: [[Meditations- Book I#^hl-7|Meditations: Book I]]\n")
+		assert.NotContains(t, markdown, "See also")
+		assert.Contains(t, markdown, "> Linked to\n\n^hl-4\n\n")
+		assert.NotContains(t, markdown, "^hl-3")
+	})
+
+	t.Run("includes article address and date", func(t *testing.T) {
+		published := time.Date(2023, 11, 5, 0, 0, 0, 0, time.UTC)
+		book := &entities.Book{
+			Title:       "How to Do Great Work",
+			Author:      "Paul Graham",
+			URL:         "http://paulgraham.com/greatwork.html",
+			PublishedAt: &published,
+			Highlights: []entities.Highlight{
+				{Text: "Develop a habit of working on your own projects.", URL: "http://paulgraham.com/greatwork.html#:~:text=Develop"},
+			},
+		}
+
+		markdown := GenerateMarkdown(book)
+
+		assert.Contains(t, markdown, "url: \"http://paulgraham.com/greatwork.html\"\npublished: 2023-11-05\n")
+		assert.Contains(t, markdown, "> \n> [View on page](<http://paulgraham.com/greatwork.html#:~:text=Develop>)\n")
+		assert.NotContains(t, GenerateMarkdown(&entities.Book{Title: "Paper Book", Author: "Author"}), "url:")
+	})
+
+	t.Run("adds journal notes after the highlights", func(t *testing.T) {
+		book := &entities.Book{
+			Title:      "Journal Book",
+			Author:     "Author",
+			Highlights: []entities.Highlight{{Text: "Highlighted"}},
+			Notes: []entities.BookNote{
+				{Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Text: "Started reading.\n"},
+				{Date: time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), Text: "The ending is *rushed*."},
+			},
+		}
+
+		markdown := GenerateMarkdown(book)
+
+		assert.Contains(t, markdown, "> Highlighted\n\n## Notes\n\n### 2024-03-01\n\nStarted reading.\n\n### 2024-03-09\n\nThe ending is *rushed*.\n\n")
+		assert.NotContains(t, GenerateMarkdown(&entities.Book{Title: "No Journal", Author: "Author"}), "## Notes")
+	})
+
+	t.Run("omits colors when highlights have none", func(t *testing.T) {
+		book := &entities.Book{
+			Title:      "Plain Book",
+			Author:     "Author",
+			Highlights: []entities.Highlight{{Text: "Plain"}},
+		}
+
+		markdown := GenerateMarkdown(book)
+
+		assert.NotContains(t, markdown, "colors:")
+	})
+
 	t.Run("includes created_at date", func(t *testing.T) {
 		book := &entities.Book{
 			Title:  "Date Book",
@@ -460,6 +595,76 @@ func TestDatabaseMarkdownExporter(t *testing.T) {
 
 		assert.Error(t, err)
 	})
+
+	t.Run("SaveBatch and ExportSaved export books spread over batches", func(t *testing.T) {
+		db, cleanup := setupTestDatabase(t)
+		defer cleanup()
+
+		tempDir := t.TempDir()
+		exporter := NewDatabaseMarkdownExporter(db, tempDir)
+
+		var bookID uint
+		for _, text := range []string{"First batch highlight", "Second batch highlight"} {
+			batch := []entities.Book{{
+				Title:      "Streamed Book",
+				Author:     "Stream Author",
+				Source:     entities.Source{Name: "kindle"},
+				Highlights: []entities.Highlight{{Text: text, LocationValue: len(text)}},
+			}}
+			result, err := exporter.SaveBatch(batch)
+			require.NoError(t, err)
+			assert.Equal(t, 1, result.HighlightsProcessed)
+			bookID = batch[0].ID
+		}
+
+		// Nothing is written until the stream is finished
+		expectedPath := filepath.Join(tempDir, "kindle", "Streamed Book.md")
+		assert.NoFileExists(t, expectedPath)
+
+		require.NoError(t, exporter.ExportSaved([]uint{bookID}))
+		content, err := os.ReadFile(expectedPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "First batch highlight")
+		assert.Contains(t, string(content), "Second batch highlight")
+	})
+
+	t.Run("Export records an import session and publishes its progress", func(t *testing.T) {
+		db, cleanup := setupTestDatabase(t)
+		defer cleanup()
+
+		broker := events.NewBroker()
+		ch, unsubscribe := broker.Subscribe()
+		defer unsubscribe()
+
+		exporter := NewDatabaseMarkdownExporter(db, "")
+		exporter.SetEventBroker(broker)
+
+		result, err := exporter.Export([]entities.Book{
+			{Title: "Session Book", Author: "Author", Source: entities.Source{Name: "kindle"}, Highlights: []entities.Highlight{{Text: "One"}, {Text: "Two"}}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.BooksProcessed)
+
+		var received []events.Event
+		for len(ch) > 0 {
+			received = append(received, <-ch)
+		}
+		require.Len(t, received, 3, "started, one book saved, completed")
+		assert.Equal(t, events.TypeImportProgress, received[0].Type)
+		assert.Equal(t, events.TypeImportCompleted, received[2].Type)
+		final := received[2].Data.(ImportProgress)
+		assert.Equal(t, entities.ImportStatusCompleted, final.Status)
+		assert.Equal(t, 1, final.TotalBooks)
+		assert.Equal(t, 2, final.HighlightsProcessed)
+
+		session, err := db.GetImportSession(final.SessionID)
+		require.NoError(t, err)
+		assert.Equal(t, entities.ImportStatusCompleted, session.Status)
+		assert.Equal(t, 1, session.BooksProcessed)
+		assert.Equal(t, 2, session.HighlightsProcessed)
+		assert.Equal(t, "kindle", session.Source.Name)
+		assert.NotNil(t, session.CompletedAt)
+	})
 }
 
 // --- ExportResult Tests ---
@@ -0,0 +1,377 @@
+package exporters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// How book titles become file names
+const (
+	FilenameStyleTitle = "title" // The title as written, minus characters file systems reject
+	FilenameStyleSlug  = "slug"  // Lower-case words joined by hyphens, e.g. "why-we-sleep"
+)
+
+// FilenameStyles lists the supported file name styles
+var FilenameStyles = []string{FilenameStyleTitle, FilenameStyleSlug}
+
+// maxFilenameLength caps a file name in bytes, leaving room for a
+// disambiguating suffix and the extension within the usual 255-byte limit
+const maxFilenameLength = 120
+
+// manifestFileName records which file each exported book was written to, so
+// renamed or disambiguated books keep their files across exports
+const manifestFileName = ".highlights-export.json"
+
+// windowsReservedNames cannot be used as file names on Windows, with or without an extension
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SanitizeFilename turns a title into a file name without an extension that is
+// safe on Linux, macOS and Windows. Path separators and reserved characters are
+// replaced, emoji and control characters dropped, and long names shortened.
+// An unknown style is treated as FilenameStyleTitle.
+func SanitizeFilename(name, style string) string {
+	var sanitized string
+	if style == FilenameStyleSlug {
+		sanitized = slugFilename(name)
+	} else {
+		sanitized = titleFilename(name)
+	}
+
+	sanitized = truncateFilename(sanitized, maxFilenameLength)
+	if sanitized == "" {
+		return "untitled"
+	}
+	if base, _, _ := strings.Cut(sanitized, "."); windowsReservedNames[strings.ToUpper(base)] {
+		sanitized = "_" + sanitized
+	}
+	return sanitized
+}
+
+func titleFilename(name string) string {
+	var builder strings.Builder
+	for _, r := range name {
+		switch {
+		case r == '/' || r == '\\' || r == ':' || r == '|':
+			builder.WriteRune('-')
+		case r == '"':
+			builder.WriteRune('\'')
+		case r == '*' || r == '?' || r == '<' || r == '>':
+		case unicode.IsSpace(r):
+			builder.WriteRune(' ')
+		case isDroppedRune(r):
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return trimFilename(strings.Join(strings.Fields(builder.String()), " "))
+}
+
+func slugFilename(name string) string {
+	var builder strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if separate && builder.Len() > 0 {
+				builder.WriteRune('-')
+			}
+			builder.WriteRune(r)
+			separate = false
+			continue
+		}
+		// Apostrophes join words ("don't" becomes "dont") rather than splitting them
+		if r != '\'' && r != '’' && !unicode.Is(unicode.Mn, r) {
+			separate = true
+		}
+	}
+	return builder.String()
+}
+
+// isDroppedRune reports whether r is left out of file names: control and format
+// characters, emoji and other pictographs, and the modifiers that combine them
+func isDroppedRune(r rune) bool {
+	switch {
+	case r == utf8.RuneError:
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F: // variation selectors
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // skin tone modifiers
+		return true
+	}
+	return unicode.In(r, unicode.Cc, unicode.Cf, unicode.Co, unicode.Cs, unicode.So)
+}
+
+// trimFilename removes leading and trailing characters that hide files or that
+// Windows strips: dots, spaces and separator hyphens
+func trimFilename(name string) string {
+	return strings.Trim(name, " .-")
+}
+
+// truncateFilename shortens name to at most limit bytes on a rune boundary
+func truncateFilename(name string, limit int) string {
+	if len(name) <= limit {
+		return name
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+	return trimFilename(name[:cut])
+}
+
+// legacySanitizeFilename is how file names were built before styles and
+// collision handling; it finds files written by earlier exports so they can be moved
+func legacySanitizeFilename(name string) string {
+	replacer := strings.NewReplacer(
+		"/", "-",
+		"\\", "-",
+		":", "-",
+		"*", "",
+		"?", "",
+		"\"", "'",
+		"<", "",
+		">", "",
+		"|", "-",
+	)
+	return replacer.Replace(name)
+}
+
+// bookFilePath joins a file name with the folder and extension of format
+func bookFilePath(format string, book *entities.Book, name string) string {
+	switch format {
+	case FormatLogseq:
+		return filepath.Join("pages", name+".md")
+	case FormatOrg:
+		return filepath.Join(sourceFolderName(book), name+".org")
+	default:
+		return filepath.Join(sourceFolderName(book), name+".md")
+	}
+}
+
+// bookKey identifies a book across exports: by ID once saved, otherwise by title and author
+func bookKey(book *entities.Book) string {
+	if book.ID != 0 {
+		return "id:" + strconv.FormatUint(uint64(book.ID), 10)
+	}
+	return "book:" + strings.ToLower(book.Title) + "|" + strings.ToLower(book.Author)
+}
+
+// bookPaths gives each book in an export a file path no other book uses.
+// When two titles sanitize to the same name, later books get the author added,
+// then a number. Paths are compared ignoring case, as macOS and Windows do.
+type bookPaths struct {
+	format string
+	style  string
+	taken  map[string]string // lower-cased relative path -> book key
+}
+
+func newBookPaths(format, style string) *bookPaths {
+	return &bookPaths{format: format, style: style, taken: make(map[string]string)}
+}
+
+// claim returns the path for book, relative to the export directory
+func (p *bookPaths) claim(book *entities.Book) string {
+	key := bookKey(book)
+	candidates := []string{book.Title}
+	if book.Author != "" {
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", book.Title, book.Author))
+	}
+
+	base := ""
+	for i := 0; ; i++ {
+		var name string
+		if i < len(candidates) {
+			name = SanitizeFilename(candidates[i], p.style)
+			base = name
+		} else {
+			separator := " "
+			if p.style == FilenameStyleSlug {
+				separator = "-"
+			}
+			name = base + separator + strconv.Itoa(i-len(candidates)+2)
+		}
+
+		// Index files share the folders with books
+		if strings.EqualFold(name, "index") {
+			continue
+		}
+
+		rel := bookFilePath(p.format, book, name)
+		if owner, ok := p.taken[strings.ToLower(rel)]; !ok || owner == key {
+			p.taken[strings.ToLower(rel)] = key
+			return rel
+		}
+	}
+}
+
+// release frees a path claimed by key, e.g. after its file was moved
+func (p *bookPaths) release(rel, key string) {
+	if p.taken[strings.ToLower(rel)] == key {
+		delete(p.taken, strings.ToLower(rel))
+	}
+}
+
+// owner returns the book key holding a path, if any
+func (p *bookPaths) owner(rel string) (string, bool) {
+	key, ok := p.taken[strings.ToLower(rel)]
+	return key, ok
+}
+
+// BookFilePaths returns unique file paths for books, in order, relative to an
+// export directory, using the title file name style
+func BookFilePaths(format string, books []entities.Book) []string {
+	paths := newBookPaths(format, FilenameStyleTitle)
+	result := make([]string, len(books))
+	for i := range books {
+		result[i] = paths.claim(&books[i])
+	}
+	return result
+}
+
+// exportManifest is the manifest file kept in an export directory
+type exportManifest struct {
+	Version int                          `json:"version"`
+	Files   map[string]map[string]string `json:"files"`           // format -> book key -> relative path
+	Notes   map[string]map[string]string `json:"notes,omitempty"` // markdown relative path -> highlight text -> note, see ExportedNotes
+}
+
+// exportFiles resolves and migrates book file paths for one export into a
+// directory. Books keep the path recorded by earlier exports unless their title
+// or the file name style changed, in which case their file is moved.
+type exportFiles struct {
+	exportDir string
+	format    string
+	manifest  exportManifest
+	legacy    bool // no manifest yet, so files may be at legacy paths
+	paths     *bookPaths
+}
+
+func openExportFiles(exportDir, format, style string) (*exportFiles, error) {
+	if format == "" {
+		format = FormatMarkdown
+	}
+	files := &exportFiles{exportDir: exportDir, format: format, paths: newBookPaths(format, style)}
+
+	data, err := os.ReadFile(filepath.Join(exportDir, manifestFileName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		files.legacy = true
+	case err != nil:
+		return nil, fmt.Errorf("failed to read export manifest: %w", err)
+	default:
+		if err := json.Unmarshal(data, &files.manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse export manifest %s: %w", manifestFileName, err)
+		}
+	}
+	if files.manifest.Files == nil {
+		files.manifest.Files = make(map[string]map[string]string)
+	}
+	if files.manifest.Files[format] == nil {
+		files.manifest.Files[format] = make(map[string]string)
+	}
+
+	// Paths of books exported earlier stay reserved, so a new book with the same
+	// title is disambiguated instead of overwriting them. Deleted files are forgotten.
+	for key, rel := range files.manifest.Files[format] {
+		if _, err := os.Stat(filepath.Join(exportDir, rel)); err != nil {
+			delete(files.manifest.Files[format], key)
+			delete(files.manifest.Notes, rel)
+			continue
+		}
+		files.paths.taken[strings.ToLower(rel)] = key
+	}
+	return files, nil
+}
+
+// path returns the absolute path to write book to, moving its file from an
+// earlier export if the path changed
+func (f *exportFiles) path(book *entities.Book) (string, error) {
+	key := bookKey(book)
+	rel := f.paths.claim(book)
+
+	previous, known := f.manifest.Files[f.format][key]
+	if !known && f.legacy {
+		previous = bookFilePath(f.format, book, legacySanitizeFilename(book.Title))
+		if owner, taken := f.paths.owner(previous); taken && owner != key {
+			previous = "" // already written for another book in this export
+		}
+	}
+
+	if previous != "" && previous != rel {
+		if err := f.move(previous, rel); err != nil {
+			return "", err
+		}
+		if !strings.EqualFold(previous, rel) {
+			f.paths.release(previous, key)
+		}
+		delete(f.manifest.Notes, previous)
+	}
+
+	f.manifest.Files[f.format][key] = rel
+	return filepath.Join(f.exportDir, rel), nil
+}
+
+// move renames a file from an earlier export, unless it is gone or its new
+// path already exists
+func (f *exportFiles) move(from, to string) error {
+	oldPath := filepath.Join(f.exportDir, from)
+	newPath := filepath.Join(f.exportDir, to)
+	if _, err := os.Stat(oldPath); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(newPath); err == nil && !strings.EqualFold(from, to) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+	}
+	fmt.Printf("Moved exported file: %s to %s\n", from, to)
+	return nil
+}
+
+// save writes the manifest back to the export directory
+func (f *exportFiles) save() error {
+	f.manifest.Version = 1
+	data, err := json.MarshalIndent(f.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(f.exportDir, manifestFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write export manifest: %w", err)
+	}
+	return nil
+}
+
+// ExportedBookPath returns where the last export to exportDir wrote book, or
+// where it would be written if it has not been exported yet
+func ExportedBookPath(exportDir, format string, book *entities.Book) string {
+	if format == "" {
+		format = FormatMarkdown
+	}
+	data, err := os.ReadFile(filepath.Join(exportDir, manifestFileName))
+	if err == nil {
+		var manifest exportManifest
+		if json.Unmarshal(data, &manifest) == nil {
+			if rel, ok := manifest.Files[format][bookKey(book)]; ok {
+				return filepath.Join(exportDir, rel)
+			}
+		}
+	}
+	return filepath.Join(exportDir, BookFilePath(format, book))
+}
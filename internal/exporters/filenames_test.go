@@ -0,0 +1,141 @@
+package exporters
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		style string
+		want  string
+	}{
+		{"separators", "Either/Or: A Fragment of Life", FilenameStyleTitle, "Either-Or- A Fragment of Life"},
+		{"reserved characters", `What Is "Real"? <A> *Guide* | Notes`, FilenameStyleTitle, "What Is 'Real' A Guide - Notes"},
+		{"emoji", "Atomic Habits 📚✨ (2nd ed.)", FilenameStyleTitle, "Atomic Habits (2nd ed.)"},
+		{"emoji sequences", "Family 👨‍👩‍👧 and 👍🏽 Stories", FilenameStyleTitle, "Family and Stories"},
+		{"whitespace and control characters", "  Line\none\ttwo\x00  ", FilenameStyleTitle, "Line one two"},
+		{"leading and trailing dots", "...And Then There Were None.", FilenameStyleTitle, "And Then There Were None"},
+		{"non-latin titles are kept", "Преступление и наказание", FilenameStyleTitle, "Преступление и наказание"},
+		{"windows reserved name", "Con", FilenameStyleTitle, "_Con"},
+		{"nothing left", "📚 ?*", FilenameStyleTitle, "untitled"},
+		{"slug", "Why We Sleep: Unlocking the Power of Sleep", FilenameStyleSlug, "why-we-sleep-unlocking-the-power-of-sleep"},
+		{"slug apostrophes and emoji", "Don't Panic! 🚀 The Guide", FilenameStyleSlug, "dont-panic-the-guide"},
+		{"slug non-latin", "Война и мир", FilenameStyleSlug, "война-и-мир"},
+		{"unknown style falls back to title", "A/B", "camel", "A-B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SanitizeFilename(tt.input, tt.style))
+		})
+	}
+
+	t.Run("long titles are shortened on a rune boundary", func(t *testing.T) {
+		name := SanitizeFilename(strings.Repeat("ж", 200), FilenameStyleTitle)
+		assert.LessOrEqual(t, len(name), maxFilenameLength)
+		assert.Equal(t, strings.Repeat("ж", maxFilenameLength/2), name)
+	})
+}
+
+func TestBookFilePaths(t *testing.T) {
+	books := []entities.Book{
+		{ID: 1, Title: "Dune", Author: "Frank Herbert"},
+		{ID: 2, Title: "Dune", Author: "Someone Else"},
+		{ID: 3, Title: "DUNE", Author: "Someone Else"},
+		{ID: 4, Title: "Either/Or", Author: "Kierkegaard"},
+	}
+
+	assert.Equal(t, []string{
+		filepath.Join("unknown", "Dune.md"),
+		filepath.Join("unknown", "Dune (Someone Else).md"),
+		filepath.Join("unknown", "DUNE (Someone Else) 2.md"),
+		filepath.Join("unknown", "Either-Or.md"),
+	}, BookFilePaths(FormatMarkdown, books))
+}
+
+func exportedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() == manifestFileName || d.Name() == "index.md" {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
+func TestExport_FileNames(t *testing.T) {
+	herbert := entities.Book{ID: 1, Title: "Dune", Author: "Frank Herbert", Source: entities.Source{Name: "kindle"}}
+	other := entities.Book{ID: 2, Title: "Dune", Author: "Someone Else", Source: entities.Source{Name: "kindle"}}
+
+	t.Run("books with the same title get the author added", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := NewMarkdownExporter(dir).Export([]entities.Book{herbert, other})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"kindle/Dune.md", "kindle/Dune (Someone Else).md"}, exportedFiles(t, dir))
+	})
+
+	t.Run("paths are kept across exports", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := NewMarkdownExporter(dir).Export([]entities.Book{herbert, other})
+		require.NoError(t, err)
+
+		// Exported alone, the second book must not take over the first book's file
+		_, err = NewMarkdownExporter(dir).Export([]entities.Book{other})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"kindle/Dune.md", "kindle/Dune (Someone Else).md"}, exportedFiles(t, dir))
+
+		content, err := os.ReadFile(filepath.Join(dir, "kindle", "Dune.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "Frank Herbert")
+		assert.Equal(t, filepath.Join(dir, "kindle", "Dune (Someone Else).md"), ExportedBookPath(dir, FormatMarkdown, &other))
+	})
+
+	t.Run("renamed books move their file", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := NewMarkdownExporter(dir).Export([]entities.Book{herbert})
+		require.NoError(t, err)
+
+		renamed := herbert
+		renamed.Title = "Dune Messiah"
+		_, err = NewMarkdownExporter(dir).Export([]entities.Book{renamed})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kindle/Dune Messiah.md"}, exportedFiles(t, dir))
+	})
+
+	t.Run("changing the style renames existing files", func(t *testing.T) {
+		dir := t.TempDir()
+		exporter, err := NewFileExporter(FormatOrg, dir)
+		require.NoError(t, err)
+		_, err = exporter.Export([]entities.Book{herbert, other})
+		require.NoError(t, err)
+
+		exporter.SetFilenameStyle(FilenameStyleSlug)
+		_, err = exporter.Export([]entities.Book{herbert, other})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"kindle/dune.org", "kindle/dune-someone-else.org"}, exportedFiles(t, dir))
+	})
+
+	t.Run("files from exports before the manifest are migrated", func(t *testing.T) {
+		dir := t.TempDir()
+		emoji := entities.Book{ID: 3, Title: "Atomic Habits 📚", Source: entities.Source{Name: "kindle"}}
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "kindle"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "kindle", "Atomic Habits 📚.md"), []byte("old"), 0644))
+
+		_, err := NewMarkdownExporter(dir).Export([]entities.Book{emoji})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kindle/Atomic Habits.md"}, exportedFiles(t, dir))
+	})
+}
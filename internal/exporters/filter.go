@@ -0,0 +1,144 @@
+package exporters
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ExportFilter narrows the books and highlights an export writes. Zero values
+// leave a criterion unset; set criteria must all match.
+type ExportFilter struct {
+	FavoritesOnly bool       // Only favourite highlights
+	Tags          []string   // Only books with any of these tags, or highlights with any of them
+	Collections   []string   // Only books in any of these collections
+	Sources       []string   // Only highlights from these sources, e.g. "kindle"
+	Since         *time.Time // Only highlights made at or after this time
+	Until         *time.Time // Only highlights made before this time
+	Query         string     // Only highlights whose text or note contains this, ignoring case
+	// IncludeArchived also exports archived books, which are left out otherwise
+	IncludeArchived bool
+}
+
+// IsEmpty reports whether the filter keeps everything
+func (f ExportFilter) IsEmpty() bool {
+	return f.IncludeArchived && f.keepsAllHighlights()
+}
+
+// keepsAllHighlights reports whether the filter keeps every book that is not
+// archived with all its highlights
+func (f ExportFilter) keepsAllHighlights() bool {
+	return !f.FavoritesOnly && len(f.Tags) == 0 && len(f.Collections) == 0 && len(f.Sources) == 0 &&
+		f.Since == nil && f.Until == nil && f.Query == ""
+}
+
+// Apply returns the books with only the highlights matching the filter.
+// Books left without highlights are dropped. The input is not modified.
+func (f ExportFilter) Apply(books []entities.Book) []entities.Book {
+	filtered, _ := f.apply(books)
+	return filtered
+}
+
+// apply filters books and records what was left out in an ExportResult
+func (f ExportFilter) apply(books []entities.Book) ([]entities.Book, ExportResult) {
+	var skipped ExportResult
+	if f.IsEmpty() {
+		return books, skipped
+	}
+
+	filtered := make([]entities.Book, 0, len(books))
+	keepsAllHighlights := f.keepsAllHighlights()
+	for _, book := range books {
+		if (book.IsArchived && !f.IncludeArchived) ||
+			(len(f.Collections) > 0 && !inAnyCollection(book.Collections, f.Collections)) {
+			skipped.HighlightsSkipped += len(book.Highlights)
+			skipped.BooksSkipped++
+			continue
+		}
+		if keepsAllHighlights {
+			filtered = append(filtered, book)
+			continue
+		}
+		bookTagged := len(f.Tags) == 0 || hasAnyTag(book.Tags, f.Tags)
+
+		var highlights []entities.Highlight
+		for _, highlight := range book.Highlights {
+			if f.matches(&book, &highlight, bookTagged) {
+				highlights = append(highlights, highlight)
+			}
+		}
+		skipped.HighlightsSkipped += len(book.Highlights) - len(highlights)
+		if len(highlights) == 0 {
+			skipped.BooksSkipped++
+			continue
+		}
+
+		book.Highlights = highlights
+		filtered = append(filtered, book)
+	}
+	return filtered, skipped
+}
+
+func (f ExportFilter) matches(book *entities.Book, highlight *entities.Highlight, bookTagged bool) bool {
+	if f.FavoritesOnly && !highlight.IsFavorite {
+		return false
+	}
+	if !bookTagged && !hasAnyTag(highlight.Tags, f.Tags) {
+		return false
+	}
+	if len(f.Sources) > 0 {
+		// Highlights merged from another source keep their own; otherwise use the book's
+		source := highlight.Source.Name
+		if source == "" {
+			source = book.Source.Name
+		}
+		if !containsFold(f.Sources, source) {
+			return false
+		}
+	}
+	if f.Since != nil && highlight.HighlightedAt.Before(*f.Since) {
+		return false
+	}
+	if f.Until != nil && !highlight.HighlightedAt.Before(*f.Until) {
+		return false
+	}
+	if f.Query != "" && !containsText(highlight.Text, f.Query) && !containsText(highlight.Note, f.Query) {
+		return false
+	}
+	return true
+}
+
+// hasAnyTag reports whether tags include any of names, ignoring case
+func hasAnyTag(tags []entities.Tag, names []string) bool {
+	for _, tag := range tags {
+		if containsFold(names, tag.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// inAnyCollection reports whether collections include any of names, ignoring case
+func inAnyCollection(collections []entities.Collection, names []string) bool {
+	for _, collection := range collections {
+		if containsFold(names, collection.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsText reports whether s contains substr, ignoring case
+func containsText(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package exporters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func filterTestBooks() []entities.Book {
+	return []entities.Book{
+		{
+			Title: "Tagged Book",
+			Tags:  []entities.Tag{{Name: "Stoic"}},
+			Highlights: []entities.Highlight{
+				{Text: "plain"},
+				{Text: "favourite", IsFavorite: true},
+			},
+		},
+		{
+			Title: "Untagged Book",
+			Highlights: []entities.Highlight{
+				{Text: "tagged highlight", Tags: []entities.Tag{{Name: "stoic"}}},
+				{Text: "other", IsFavorite: true},
+			},
+		},
+		{
+			Title:      "Plain Book",
+			Highlights: []entities.Highlight{{Text: "nothing special"}},
+		},
+	}
+}
+
+func highlightTexts(books []entities.Book) map[string][]string {
+	texts := make(map[string][]string)
+	for _, book := range books {
+		for _, h := range book.Highlights {
+			texts[book.Title] = append(texts[book.Title], h.Text)
+		}
+	}
+	return texts
+}
+
+func TestExportFilter_Apply(t *testing.T) {
+	t.Run("empty filter keeps everything", func(t *testing.T) {
+		books := filterTestBooks()
+		assert.Equal(t, books, ExportFilter{}.Apply(books))
+	})
+
+	t.Run("archived books are left out unless included", func(t *testing.T) {
+		books := filterTestBooks()
+		books[2].IsArchived = true
+		books = append(books, entities.Book{Title: "Empty Book"})
+
+		filtered := ExportFilter{}.Apply(books)
+		require.Len(t, filtered, 3)
+		assert.Equal(t, "Empty Book", filtered[2].Title, "books without highlights are kept as before")
+
+		assert.Equal(t, books, ExportFilter{IncludeArchived: true}.Apply(books))
+		assert.Empty(t, ExportFilter{IncludeArchived: false, Query: "nothing"}.Apply(books))
+	})
+
+	t.Run("favourites only", func(t *testing.T) {
+		filtered := ExportFilter{FavoritesOnly: true}.Apply(filterTestBooks())
+		assert.Equal(t, map[string][]string{
+			"Tagged Book":   {"favourite"},
+			"Untagged Book": {"other"},
+		}, highlightTexts(filtered))
+	})
+
+	t.Run("tags match books and highlights ignoring case", func(t *testing.T) {
+		filtered := ExportFilter{Tags: []string{"stoic"}}.Apply(filterTestBooks())
+		assert.Equal(t, map[string][]string{
+			"Tagged Book":   {"plain", "favourite"},
+			"Untagged Book": {"tagged highlight"},
+		}, highlightTexts(filtered))
+	})
+
+	t.Run("criteria combine", func(t *testing.T) {
+		filtered := ExportFilter{FavoritesOnly: true, Tags: []string{"stoic"}}.Apply(filterTestBooks())
+		assert.Equal(t, map[string][]string{"Tagged Book": {"favourite"}}, highlightTexts(filtered))
+	})
+
+	t.Run("collections keep only their books", func(t *testing.T) {
+		books := filterTestBooks()
+		books[2].Collections = []entities.Collection{{Name: "2024 Reading"}}
+		filtered := ExportFilter{Collections: []string{"2024 reading"}, FavoritesOnly: true}.Apply(books)
+		assert.Empty(t, filtered, "the collection's book has no favourites")
+
+		filtered = ExportFilter{Collections: []string{"2024 reading"}}.Apply(books)
+		assert.Equal(t, map[string][]string{"Plain Book": {"nothing special"}}, highlightTexts(filtered))
+	})
+
+	t.Run("sources fall back to the book source", func(t *testing.T) {
+		books := []entities.Book{{
+			Title:  "Merged Book",
+			Source: entities.Source{Name: "kindle"},
+			Highlights: []entities.Highlight{
+				{Text: "from kindle"},
+				{Text: "from moonreader", Source: entities.Source{Name: "moonreader"}},
+			},
+		}}
+		filtered := ExportFilter{Sources: []string{"Kindle"}}.Apply(books)
+		assert.Equal(t, map[string][]string{"Merged Book": {"from kindle"}}, highlightTexts(filtered))
+	})
+
+	t.Run("since keeps highlights made on or after the date", func(t *testing.T) {
+		since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		books := []entities.Book{{
+			Title: "Dated Book",
+			Highlights: []entities.Highlight{
+				{Text: "before", HighlightedAt: since.Add(-time.Minute)},
+				{Text: "on", HighlightedAt: since},
+				{Text: "after", HighlightedAt: since.AddDate(0, 1, 0)},
+			},
+		}}
+		filtered := ExportFilter{Since: &since}.Apply(books)
+		assert.Equal(t, map[string][]string{"Dated Book": {"on", "after"}}, highlightTexts(filtered))
+	})
+
+	t.Run("does not modify the input", func(t *testing.T) {
+		books := filterTestBooks()
+		ExportFilter{FavoritesOnly: true}.Apply(books)
+		assert.Len(t, books[0].Highlights, 2)
+	})
+}
+
+func TestExporters_SetFilter(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			exporter, err := NewFileExporter(format, t.TempDir())
+			require.NoError(t, err)
+			exporter.SetFilter(ExportFilter{FavoritesOnly: true})
+
+			result, err := exporter.Export(filterTestBooks())
+			require.NoError(t, err)
+			assert.Equal(t, 2, result.BooksProcessed)
+			assert.Equal(t, 2, result.HighlightsProcessed)
+			assert.Equal(t, 1, result.BooksSkipped)
+			assert.Equal(t, 3, result.HighlightsSkipped)
+		})
+	}
+}
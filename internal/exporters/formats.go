@@ -0,0 +1,193 @@
+package exporters
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// File export formats an export target can be set to
+const (
+	FormatMarkdown = "markdown" // Obsidian-flavoured markdown with YAML frontmatter
+	FormatLogseq   = "logseq"   // Logseq pages with page properties and block bullets
+	FormatOrg      = "org"      // Org-mode files with PROPERTIES drawers, for org-roam
+)
+
+// Formats lists the supported file export formats
+var Formats = []string{FormatMarkdown, FormatLogseq, FormatOrg}
+
+// ErrUnknownFormat is returned for export formats that are not in Formats
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// FileExporter writes books and vocabulary as files in an export directory
+type FileExporter interface {
+	BookExporter
+	ExportVocabulary(words []entities.Word) error
+	SetFilter(filter ExportFilter)
+	SetFilenameStyle(style string)
+	SetLanguage(language string)
+}
+
+// NewFileExporter returns the exporter writing format to exportDir.
+// An empty format means markdown.
+func NewFileExporter(format, exportDir string) (FileExporter, error) {
+	switch format {
+	case FormatMarkdown, "":
+		return NewMarkdownExporter(exportDir), nil
+	case FormatLogseq:
+		return NewLogseqExporter(exportDir), nil
+	case FormatOrg:
+		return NewOrgExporter(exportDir), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+// GenerateBook renders a book in format
+func GenerateBook(format string, book *entities.Book) (string, error) {
+	switch format {
+	case FormatMarkdown, "":
+		return GenerateMarkdown(book), nil
+	case FormatLogseq:
+		return GenerateLogseq(book), nil
+	case FormatOrg:
+		return GenerateOrg(book), nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+// GenerateVocabulary renders the vocabulary list in format
+func GenerateVocabulary(format string, words []entities.Word) (string, error) {
+	switch format {
+	case FormatMarkdown, "":
+		return GenerateVocabularyMarkdown(words), nil
+	case FormatLogseq:
+		return GenerateVocabularyLogseq(words), nil
+	case FormatOrg:
+		return GenerateVocabularyOrg(words), nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+// BookFilePath returns where a book is written, relative to the export directory,
+// with the title file name style. Logseq keeps every page in pages/; the other
+// formats group books by source. Exports into a directory disambiguate books
+// with the same title, so use ExportedBookPath to find an exported file.
+func BookFilePath(format string, book *entities.Book) string {
+	return bookFilePath(format, book, SanitizeFilename(book.Title, FilenameStyleTitle))
+}
+
+// VocabularyFilePath returns where the vocabulary list is written, relative to the export directory
+func VocabularyFilePath(format string) string {
+	switch format {
+	case FormatLogseq:
+		return filepath.Join("pages", "Vocabulary.md")
+	case FormatOrg:
+		return "vocabulary.org"
+	default:
+		return "vocabulary.md"
+	}
+}
+
+// FormatContentType returns the Content-Type for a downloaded file in format
+func FormatContentType(format string) string {
+	if format == FormatOrg {
+		return "text/org; charset=utf-8"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+func sourceFolderName(book *entities.Book) string {
+	if book.Source.Name != "" {
+		return book.Source.Name
+	}
+	return "unknown"
+}
+
+// formatWriter writes one file per book for the formats without a dedicated writer
+type formatWriter struct {
+	exportDir     string
+	format        string
+	filter        ExportFilter
+	filenameStyle string
+}
+
+func (w formatWriter) export(books []entities.Book) (ExportResult, error) {
+	if err := checkExportDir(w.exportDir); err != nil {
+		return ExportResult{}, err
+	}
+
+	files, err := openExportFiles(w.exportDir, w.format, w.filenameStyle)
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	books, result := w.filter.apply(books)
+	for _, book := range books {
+		content, err := GenerateBook(w.format, &book)
+		if err != nil {
+			return ExportResult{}, err
+		}
+		outputPath, err := files.path(&book)
+		if err != nil {
+			return ExportResult{}, err
+		}
+		fmt.Printf("Exporting book: %s to %s\n", book.Title, outputPath)
+		if err := writeExportFile(outputPath, content); err != nil {
+			return ExportResult{}, err
+		}
+		result.BooksProcessed++
+		result.HighlightsProcessed += len(book.Highlights)
+	}
+	return result, files.save()
+}
+
+func (w formatWriter) exportVocabulary(words []entities.Word) error {
+	if err := checkExportDir(w.exportDir); err != nil {
+		return err
+	}
+
+	content, err := GenerateVocabulary(w.format, words)
+	if err != nil {
+		return err
+	}
+	outputPath := filepath.Join(w.exportDir, VocabularyFilePath(w.format))
+	fmt.Printf("Exporting vocabulary (%d words) to %s\n", len(words), outputPath)
+	if err := writeExportFile(outputPath, content); err != nil {
+		return fmt.Errorf("failed to write vocabulary file: %w", err)
+	}
+	return nil
+}
+
+func checkExportDir(exportDir string) error {
+	if exportDir == "" {
+		return ErrExportDirNotConfigured
+	}
+	if _, err := os.Stat(exportDir); os.IsNotExist(err) {
+		return fmt.Errorf("export directory does not exist: %s", exportDir)
+	}
+	return nil
+}
+
+func writeExportFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// describeLocation returns a readable location such as "page 12", or "" if unknown
+func describeLocation(h *entities.Highlight) string {
+	if h.LocationValue == 0 {
+		return ""
+	}
+	switch h.LocationType {
+	case entities.LocationTypePage:
+		return fmt.Sprintf("page %d", h.LocationValue)
+	case entities.LocationTypeLocation:
+		return fmt.Sprintf("location %d", h.LocationValue)
+	}
+	return ""
+}
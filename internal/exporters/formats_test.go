@@ -0,0 +1,135 @@
+package exporters
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func formatTestBook() *entities.Book {
+	dateRead := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	return &entities.Book{
+		Title:    "Meditations",
+		Author:   "Marcus Aurelius",
+		Source:   entities.Source{Name: "kindle"},
+		Rating:   4.5,
+		DateRead: &dateRead,
+		Tags:     []entities.Tag{{Name: "stoic philosophy"}},
+		Highlights: []entities.Highlight{
+			{
+				Text:          "You have power over your mind.\n* not outside events",
+				Note:          "Stoic core",
+				Chapter:       "Book II",
+				LocationType:  entities.LocationTypePage,
+				LocationValue: 12,
+				Color:         "#ffeb3b",
+				IsFavorite:    true,
+				HighlightedAt: time.Date(2024, 6, 2, 14, 30, 0, 0, time.UTC),
+				Tags:          []entities.Tag{{Name: "mind"}},
+			},
+		},
+	}
+}
+
+func TestGenerateLogseq(t *testing.T) {
+	content := GenerateLogseq(formatTestBook())
+
+	assert.Contains(t, content, "title:: Meditations\n")
+	assert.Contains(t, content, "author:: [[Marcus Aurelius]]\n")
+	assert.Contains(t, content, "tags:: books, highlights, mind, stoic philosophy\n")
+	assert.Contains(t, content, "date-read:: [[Jul 1st, 2024]]\n")
+	assert.Contains(t, content, "- You have power over your mind.\n  highlighted:: [[Jun 2nd, 2024]]\n")
+	assert.Contains(t, content, "  location:: page 12\n")
+	assert.Contains(t, content, "  chapter:: Book II\n")
+	assert.Contains(t, content, "  favorite:: true\n")
+	assert.Contains(t, content, "  tags:: mind\n  * not outside events\n")
+	assert.Contains(t, content, "\t- **Note:** Stoic core\n")
+}
+
+func TestLogseqDate(t *testing.T) {
+	for day, want := range map[int]string{1: "Jan 1st, 2024", 2: "Jan 2nd, 2024", 3: "Jan 3rd, 2024", 11: "Jan 11th, 2024", 12: "Jan 12th, 2024", 22: "Jan 22nd, 2024", 31: "Jan 31st, 2024"} {
+		assert.Equal(t, want, logseqDate(time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)))
+	}
+}
+
+func TestGenerateOrg(t *testing.T) {
+	book := formatTestBook()
+	content := GenerateOrg(book)
+
+	assert.Contains(t, content, ":PROPERTIES:\n:ID:       "+orgID("book", "Meditations", "Marcus Aurelius")+"\n")
+	assert.Contains(t, content, ":DATE_READ: [2024-07-01 Mon]\n")
+	assert.Contains(t, content, "#+title: Meditations\n")
+	assert.Contains(t, content, "#+filetags: :books:highlights:mind:stoic_philosophy:\n")
+	assert.Contains(t, content, "** Book II :mind:\n:PROPERTIES:\n:HIGHLIGHTED_AT: [2024-06-02 Sun 14:30]\n:LOCATION: page 12\n")
+	assert.Contains(t, content, ":FAVORITE: t\n:END:\n")
+	assert.Contains(t, content, "#+begin_quote\nYou have power over your mind.\n,* not outside events\n#+end_quote\n\nStoic core\n")
+
+	assert.Equal(t, orgID("book", "Meditations", "Marcus Aurelius"), orgID("book", book.Title, book.Author), "IDs are stable across exports")
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, orgID("book", "Dune", ""))
+}
+
+func TestVocabularyCredits(t *testing.T) {
+	definition := entities.WordDefinition{
+		Definition:  "lasting a very short time",
+		Source:      "freedictionary",
+		Attribution: "Wiktionary contributors, via the Free Dictionary API",
+		License:     "CC BY-SA 3.0",
+		LicenseURL:  "https://creativecommons.org/licenses/by-sa/3.0",
+	}
+	words := []entities.Word{
+		{Word: "ephemeral", Definitions: []entities.WordDefinition{definition, definition}},
+		{Word: "numinous", Definitions: []entities.WordDefinition{definition}},
+		{Word: "lagniappe", Definitions: []entities.WordDefinition{{Definition: "a small gift"}}},
+	}
+
+	credit := "Wiktionary contributors, via the Free Dictionary API. Licensed under CC BY-SA 3.0: https://creativecommons.org/licenses/by-sa/3.0"
+	assert.Equal(t, []string{credit}, definitionCredits(words))
+	assert.Contains(t, GenerateVocabularyMarkdown(words), "## Credits\n\n- "+credit+"\n")
+	assert.Contains(t, GenerateVocabularyOrg(words), "* Credits\n- "+credit+"\n")
+	assert.Contains(t, GenerateVocabularyLogseq(words), "- Credits\n\t- "+credit+"\n")
+
+	assert.NotContains(t, GenerateVocabularyMarkdown(words[2:]), "Credits")
+}
+
+func TestNewFileExporter(t *testing.T) {
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		_, err := NewFileExporter("docx", t.TempDir())
+		assert.ErrorIs(t, err, ErrUnknownFormat)
+	})
+
+	t.Run("writes files where each format expects them", func(t *testing.T) {
+		expected := map[string][]string{
+			FormatMarkdown: {"kindle/Meditations.md", "vocabulary.md"},
+			FormatLogseq:   {"pages/Meditations.md", "pages/Vocabulary.md"},
+			FormatOrg:      {"kindle/Meditations.org", "vocabulary.org"},
+		}
+		for _, format := range Formats {
+			dir := t.TempDir()
+			exporter, err := NewFileExporter(format, dir)
+			require.NoError(t, err)
+
+			result, err := exporter.Export([]entities.Book{*formatTestBook()})
+			require.NoError(t, err)
+			assert.Equal(t, 1, result.BooksProcessed)
+			assert.Equal(t, 1, result.HighlightsProcessed)
+			require.NoError(t, exporter.ExportVocabulary([]entities.Word{{Word: "ephemeral", SourceBookTitle: "Meditations"}}))
+
+			for _, file := range expected[format] {
+				_, err := os.Stat(filepath.Join(dir, file))
+				assert.NoError(t, err, "%s export should write %s", format, file)
+			}
+		}
+	})
+
+	t.Run("fails without an export directory", func(t *testing.T) {
+		exporter, err := NewFileExporter(FormatOrg, "")
+		require.NoError(t, err)
+		_, err = exporter.Export([]entities.Book{*formatTestBook()})
+		assert.ErrorIs(t, err, ErrExportDirNotConfigured)
+	})
+}
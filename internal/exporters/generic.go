@@ -17,10 +17,18 @@ type BookExporter interface {
 	Export(books []entities.Book) (ExportResult, error)
 }
 
+// MultiFileExporter exports the books read from several files as one import,
+// recording what each file yielded. Implemented by DatabaseMarkdownExporter.
+type MultiFileExporter interface {
+	ExportFiles(books []entities.Book, files []entities.ImportFileResult) (ExportResult, error)
+}
+
 // ExportResult contains the outcome of an export operation.
 type ExportResult struct {
 	BooksProcessed      int `json:"books_processed"`
 	HighlightsProcessed int `json:"highlights_processed"`
 	BooksFailed         int `json:"books_failed"`
 	HighlightsFailed    int `json:"highlights_failed"`
+	BooksSkipped        int `json:"books_skipped"`      // Left out by the export filter
+	HighlightsSkipped   int `json:"highlights_skipped"` // Left out by the export filter
 }
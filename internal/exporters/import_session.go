@@ -0,0 +1,118 @@
+package exporters
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
+)
+
+// ImportProgress is the payload of import events.
+type ImportProgress struct {
+	SessionID  uint                  `json:"session_id"`
+	Status     entities.ImportStatus `json:"status"`
+	TotalBooks int                   `json:"total_books,omitempty"` // 0 when not known up front, e.g. for streamed imports
+	ExportResult
+	Error string `json:"error,omitempty"`
+}
+
+// ImportRun records one import as an import session and publishes its
+// progress. A nil *ImportRun does nothing, so optional tracking needs no checks.
+type ImportRun struct {
+	exporter *DatabaseMarkdownExporter
+	session  *entities.ImportSession // nil when the session could not be recorded
+	progress ImportProgress
+	files    []entities.ImportFileResult
+}
+
+// SetEventBroker sets where import progress is published.
+func (exporter *DatabaseMarkdownExporter) SetEventBroker(broker *events.Broker) {
+	exporter.events = broker
+}
+
+// BeginImport starts recording an import of totalBooks books, 0 if unknown.
+// Export tracks its own imports; callers saving batches with SaveBatch use it
+// to report the progress of the whole stream.
+func (exporter *DatabaseMarkdownExporter) BeginImport(userID uint, totalBooks int) *ImportRun {
+	run := &ImportRun{
+		exporter: exporter,
+		progress: ImportProgress{Status: entities.ImportStatusRunning, TotalBooks: totalBooks},
+	}
+
+	session, err := exporter.db.CreateImportSession(userID, 0)
+	if err != nil {
+		log.Printf("Failed to record import session: %v", err)
+	} else {
+		session.Status = entities.ImportStatusRunning
+		session.StartedAt = time.Now()
+		if err := exporter.db.UpdateImportSession(session); err != nil {
+			log.Printf("Failed to start import session %d: %v", session.ID, err)
+		}
+		run.session = session
+		run.progress.SessionID = session.ID
+	}
+
+	exporter.events.Publish(events.TypeImportProgress, run.progress)
+	return run
+}
+
+// setSource records where the import came from, once known from a saved book
+func (r *ImportRun) setSource(sourceID uint) {
+	if r != nil && r.session != nil && r.session.SourceID == 0 {
+		r.session.SourceID = sourceID
+	}
+}
+
+// SetFiles records the per-file results of an import made of several files
+// with the session once it finishes.
+func (r *ImportRun) SetFiles(files []entities.ImportFileResult) {
+	if r != nil {
+		r.files = files
+	}
+}
+
+// Progress publishes the counts of the import so far.
+func (r *ImportRun) Progress(result ExportResult) {
+	if r == nil {
+		return
+	}
+	r.progress.ExportResult = result
+	r.exporter.events.Publish(events.TypeImportProgress, r.progress)
+}
+
+// Finish completes the import session with the final counts, failed if err
+// is not nil, and publishes the outcome.
+func (r *ImportRun) Finish(result ExportResult, err error) {
+	if r == nil {
+		return
+	}
+	r.progress.ExportResult = result
+	r.progress.Status = entities.ImportStatusCompleted
+	if err != nil {
+		r.progress.Status = entities.ImportStatusFailed
+		r.progress.Error = err.Error()
+	}
+
+	if r.session != nil {
+		now := time.Now()
+		r.session.Status = r.progress.Status
+		r.session.BooksProcessed = result.BooksProcessed
+		r.session.HighlightsProcessed = result.HighlightsProcessed
+		r.session.CompletedAt = &now
+		if err != nil {
+			errs, _ := json.Marshal([]string{err.Error()})
+			r.session.Errors = string(errs)
+		}
+		if len(r.files) > 0 {
+			files, _ := json.Marshal(r.files)
+			r.session.Files = string(files)
+		}
+		if err := r.exporter.db.UpdateImportSession(r.session); err != nil {
+			log.Printf("Failed to complete import session %d: %v", r.session.ID, err)
+		}
+	}
+
+	r.exporter.events.Publish(events.TypeImportCompleted, r.progress)
+}
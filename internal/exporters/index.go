@@ -0,0 +1,253 @@
+package exporters
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/i18n"
+)
+
+// indexEntry is an exported book as listed in the index files
+type indexEntry struct {
+	Title      string
+	Author     string
+	Source     string
+	File       string // Relative to the source folder
+	Highlights int
+	UpdatedAt  time.Time
+}
+
+// WriteIndex regenerates the index file at the top of the export directory and
+// one in each source folder. The indexes list every exported book file in the
+// directory, including ones written by earlier exports, so they stay complete
+// when only some books are exported.
+func (exporter *MarkdownExporter) WriteIndex() error {
+	if exporter.IndexFileName == "" {
+		return nil
+	}
+	exportDir, err := exporter.ensureDirs()
+	if err != nil {
+		return err
+	}
+
+	entries, err := scanExportedBooks(exportDir, exporter.IndexFileName)
+	if err != nil {
+		return fmt.Errorf("failed to scan exported books: %w", err)
+	}
+
+	bySource := make(map[string][]indexEntry)
+	for _, entry := range entries {
+		bySource[entry.Source] = append(bySource[entry.Source], entry)
+	}
+	sources := make([]string, 0, len(bySource))
+	for source := range bySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		content := generateIndex(source, map[string][]indexEntry{source: bySource[source]}, exporter.IndexFileName, exporter.Language)
+		if err := writeExportFile(filepath.Join(exportDir, source, exporter.IndexFileName), content); err != nil {
+			return fmt.Errorf("failed to write %s index: %w", source, err)
+		}
+	}
+
+	content := generateIndex("", bySource, exporter.IndexFileName, exporter.Language)
+	if err := writeExportFile(filepath.Join(exportDir, exporter.IndexFileName), content); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// generateIndex renders an index of the given sources. The top-level index has
+// an empty source and links into the source folders; a source index links to
+// the files next to it. The prose and dates are in language; an empty language
+// writes English with ISO 8601 dates.
+func generateIndex(source string, bySource map[string][]indexEntry, indexFileName, language string) string {
+	tr := i18n.For(language)
+	formatDate := func(t time.Time) string {
+		if language == "" {
+			return t.Format("2006-01-02")
+		}
+		return tr.FormatDate(t)
+	}
+
+	sources := make([]string, 0, len(bySource))
+	books, highlights := 0, 0
+	var updatedAt time.Time
+	for name, entries := range bySource {
+		sources = append(sources, name)
+		for _, entry := range entries {
+			books++
+			highlights += entry.Highlights
+			if entry.UpdatedAt.After(updatedAt) {
+				updatedAt = entry.UpdatedAt
+			}
+		}
+	}
+	sort.Strings(sources)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "---\n")
+	fmt.Fprintf(&builder, "content_type: highlights_index\n")
+	if source != "" {
+		fmt.Fprintf(&builder, "content_source: %s\n", source)
+	}
+	fmt.Fprintf(&builder, "books_count: %d\n", books)
+	fmt.Fprintf(&builder, "highlights_count: %d\n", highlights)
+	if !updatedAt.IsZero() {
+		fmt.Fprintf(&builder, "updated_at: %s\n", updatedAt.Format("2006-01-02"))
+	}
+	fmt.Fprintf(&builder, "---\n\n")
+
+	if source != "" {
+		fmt.Fprintf(&builder, "# %s\n\n", source)
+	} else {
+		fmt.Fprintf(&builder, "# %s\n\n", tr.T("export.title"))
+	}
+	builder.WriteString(tr.T("export.summary", tr.N("common.books", books), tr.N("common.highlights", highlights)))
+	if source == "" && len(sources) > 1 {
+		builder.WriteString(tr.N("export.from_sources", len(sources)))
+	}
+	if !updatedAt.IsZero() {
+		builder.WriteString(tr.T("export.last_updated", formatDate(updatedAt)))
+	}
+	fmt.Fprintf(&builder, ".\n")
+
+	for _, name := range sources {
+		entries := bySource[name]
+		sort.Slice(entries, func(i, j int) bool {
+			return strings.ToLower(entries[i].Title) < strings.ToLower(entries[j].Title)
+		})
+
+		prefix := ""
+		if source == "" {
+			prefix = name + "/"
+			fmt.Fprintf(&builder, "\n## [%s](%s)\n", indexLinkText(name), indexLinkTarget(prefix+indexFileName))
+		}
+
+		fmt.Fprintf(&builder, "\n| %s | %s | %s | %s |\n", tr.T("export.column_book"), tr.T("export.column_author"),
+			tr.T("export.column_highlights"), tr.T("export.column_updated"))
+		fmt.Fprintf(&builder, "|------|--------|-----------:|---------|\n")
+		for _, entry := range entries {
+			updated := ""
+			if !entry.UpdatedAt.IsZero() {
+				updated = formatDate(entry.UpdatedAt)
+			}
+			fmt.Fprintf(&builder, "| [%s](%s) | %s | %d | %s |\n",
+				indexCell(indexLinkText(entry.Title)), indexLinkTarget(prefix+entry.File),
+				indexCell(entry.Author), entry.Highlights, updated)
+		}
+	}
+
+	return builder.String()
+}
+
+// scanExportedBooks reads the frontmatter of the book files in each source folder
+func scanExportedBooks(exportDir, indexFileName string) ([]indexEntry, error) {
+	folders, err := os.ReadDir(exportDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []indexEntry
+	for _, folder := range folders {
+		if !folder.IsDir() || strings.HasPrefix(folder.Name(), ".") {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(exportDir, folder.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.IsDir() || filepath.Ext(file.Name()) != ".md" || file.Name() == indexFileName {
+				continue
+			}
+			entry, ok := readIndexEntry(filepath.Join(exportDir, folder.Name(), file.Name()))
+			if !ok {
+				continue
+			}
+			entry.Source = folder.Name()
+			entry.File = file.Name()
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// readIndexEntry reads a book file's frontmatter. Files that are not book
+// highlight exports, such as the user's own notes, are skipped.
+func readIndexEntry(path string) (indexEntry, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return indexEntry{}, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() || scanner.Text() != "---" {
+		return indexEntry{}, false
+	}
+
+	fields := make(map[string]string)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "---" {
+			break
+		}
+		if key, value, ok := strings.Cut(line, ": "); ok {
+			fields[key] = frontmatterValue(value)
+		}
+	}
+	if fields["content_type"] != "book_highlights" {
+		return indexEntry{}, false
+	}
+
+	entry := indexEntry{Title: fields["title"], Author: fields["author"]}
+	entry.Highlights, _ = strconv.Atoi(fields["highlights_count"])
+	if updated, err := time.Parse("2006-01-02", fields["last_highlight_at"]); err == nil {
+		entry.UpdatedAt = updated
+	} else if info, err := file.Stat(); err == nil {
+		entry.UpdatedAt = info.ModTime()
+	}
+	if entry.Title == "" {
+		entry.Title = strings.TrimSuffix(filepath.Base(path), ".md")
+	}
+	return entry, true
+}
+
+// frontmatterValue unquotes a value written by GenerateMarkdown
+func frontmatterValue(value string) string {
+	if strings.HasPrefix(value, "\"") {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return strings.Trim(value, "\"")
+	}
+	return value
+}
+
+func indexLinkText(text string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]").Replace(text)
+}
+
+// indexLinkTarget escapes a relative path for a markdown link
+func indexLinkTarget(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// indexCell keeps a value from breaking out of its table cell
+func indexCell(text string) string {
+	return strings.ReplaceAll(text, "|", "\\|")
+}
@@ -0,0 +1,100 @@
+package exporters
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownExporter_WriteIndex(t *testing.T) {
+	june := time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC)
+	books := []entities.Book{
+		{
+			ID: 1, Title: "Dune", Author: "Frank Herbert", Source: entities.Source{Name: "kindle"},
+			Highlights: []entities.Highlight{{Text: "Fear is the mind-killer.", HighlightedAt: june}, {Text: "Second"}},
+		},
+		{
+			ID: 2, Title: "Meditations [Annotated]", Author: "Marcus Aurelius", Source: entities.Source{Name: "apple_books"},
+			Highlights: []entities.Highlight{{Text: "Waste no more time", HighlightedAt: june.AddDate(0, -1, 0)}},
+		},
+	}
+
+	t.Run("writes a top-level and per-source indexes", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := NewMarkdownExporter(dir).Export(books)
+		require.NoError(t, err)
+
+		index, err := os.ReadFile(filepath.Join(dir, "index.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(index), "content_type: highlights_index\nbooks_count: 2\nhighlights_count: 3\nupdated_at: 2024-06-15\n")
+		assert.Contains(t, string(index), "2 books, 3 highlights from 2 sources. Last updated 2024-06-15.")
+		assert.Contains(t, string(index), "## [kindle](kindle/index.md)")
+		assert.Contains(t, string(index), "| [Dune](kindle/Dune.md) | Frank Herbert | 2 | 2024-06-15 |")
+		assert.Contains(t, string(index), `| [Meditations \[Annotated\]](apple_books/Meditations%20%5BAnnotated%5D.md) | Marcus Aurelius | 1 | 2024-05-15 |`)
+
+		kindle, err := os.ReadFile(filepath.Join(dir, "kindle", "index.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(kindle), "content_source: kindle\n")
+		assert.Contains(t, string(kindle), "| [Dune](Dune.md) | Frank Herbert | 2 | 2024-06-15 |")
+		assert.NotContains(t, string(kindle), "Meditations")
+	})
+
+	t.Run("writes the prose and dates in the set language", func(t *testing.T) {
+		dir := t.TempDir()
+		exporter := NewMarkdownExporter(dir)
+		exporter.SetLanguage("de")
+		_, err := exporter.Export(books)
+		require.NoError(t, err)
+
+		index, err := os.ReadFile(filepath.Join(dir, "index.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(index), "updated_at: 2024-06-15\n", "frontmatter stays machine-readable")
+		assert.Contains(t, string(index), "# Markierungen\n")
+		assert.Contains(t, string(index), "2 Bücher, 3 Markierungen aus 2 Quellen. Zuletzt aktualisiert am 15.06.2024.")
+		assert.Contains(t, string(index), "| Buch | Autor | Markierungen | Aktualisiert |")
+		assert.Contains(t, string(index), "| Frank Herbert | 2 | 15.06.2024 |")
+	})
+
+	t.Run("lists books from earlier exports and skips other notes", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := NewMarkdownExporter(dir).Export(books[:1])
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "kindle", "My notes.md"), []byte("# Just notes\n"), 0644))
+
+		_, err = NewMarkdownExporter(dir).Export(books[1:])
+		require.NoError(t, err)
+
+		index, err := os.ReadFile(filepath.Join(dir, "index.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(index), "[Dune](kindle/Dune.md)")
+		assert.Contains(t, string(index), "Meditations")
+		assert.NotContains(t, string(index), "My notes")
+	})
+
+	t.Run("books titled index do not replace the index", func(t *testing.T) {
+		dir := t.TempDir()
+		book := entities.Book{ID: 3, Title: "Index", Source: entities.Source{Name: "kindle"}, Highlights: []entities.Highlight{{Text: "a"}}}
+		_, err := NewMarkdownExporter(dir).Export([]entities.Book{book})
+		require.NoError(t, err)
+
+		kindle, err := os.ReadFile(filepath.Join(dir, "kindle", "index.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(kindle), "| [Index](Index%202.md) |")
+	})
+
+	t.Run("an empty index file name disables indexes", func(t *testing.T) {
+		dir := t.TempDir()
+		exporter := NewMarkdownExporter(dir)
+		exporter.IndexFileName = ""
+		_, err := exporter.Export(books)
+		require.NoError(t, err)
+
+		_, err = os.Stat(filepath.Join(dir, "index.md"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
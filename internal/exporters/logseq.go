@@ -0,0 +1,187 @@
+package exporters
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/utils"
+)
+
+// LogseqExporter writes one Logseq page per book into <dir>/pages, so the
+// export directory can be a Logseq graph. Highlights are blocks whose dates
+// link to journal pages, which puts them in the journal's linked references.
+type LogseqExporter struct {
+	ExportDir     string
+	Filter        ExportFilter // Applied to every export; empty exports everything
+	FilenameStyle string       // One of FilenameStyles; empty means FilenameStyleTitle
+}
+
+func NewLogseqExporter(exportDir string) *LogseqExporter {
+	return &LogseqExporter{ExportDir: exportDir}
+}
+
+func (exporter *LogseqExporter) Export(books []entities.Book) (ExportResult, error) {
+	return formatWriter{exportDir: exporter.ExportDir, format: FormatLogseq, filter: exporter.Filter, filenameStyle: exporter.FilenameStyle}.export(books)
+}
+
+// SetFilter sets the filter applied to subsequent exports
+func (exporter *LogseqExporter) SetFilter(filter ExportFilter) {
+	exporter.Filter = filter
+}
+
+// SetFilenameStyle sets how book titles become file names
+func (exporter *LogseqExporter) SetFilenameStyle(style string) {
+	exporter.FilenameStyle = style
+}
+
+// SetLanguage does nothing; only markdown exports have index files
+func (exporter *LogseqExporter) SetLanguage(language string) {}
+
+// ExportVocabulary writes all vocabulary words to the Vocabulary page
+func (exporter *LogseqExporter) ExportVocabulary(words []entities.Word) error {
+	return formatWriter{exportDir: exporter.ExportDir, format: FormatLogseq}.exportVocabulary(words)
+}
+
+// GenerateLogseq renders a book as a Logseq page: page properties in the first
+// block, then one top-level block per highlight with its note as a child block.
+func GenerateLogseq(book *entities.Book) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "title:: %s\n", logseqValue(book.Title))
+	if book.Author != "" {
+		fmt.Fprintf(&builder, "author:: [[%s]]\n", logseqValue(book.Author))
+	}
+	fmt.Fprintf(&builder, "type:: [[book]]\n")
+	fmt.Fprintf(&builder, "source:: %s\n", sourceFolderName(book))
+	tags := collectAllTags(book)
+	sort.Strings(tags)
+	fmt.Fprintf(&builder, "tags:: %s\n", strings.Join(tags, ", "))
+	fmt.Fprintf(&builder, "highlights-count:: %d\n", len(book.Highlights))
+	if book.Rating > 0 {
+		fmt.Fprintf(&builder, "rating:: %g\n", book.Rating)
+	}
+	if book.DateRead != nil {
+		fmt.Fprintf(&builder, "date-read:: [[%s]]\n", logseqDate(*book.DateRead))
+	}
+	fmt.Fprintf(&builder, "\n")
+
+	for _, highlight := range book.Highlights {
+		renderLogseqHighlight(&builder, &highlight)
+	}
+
+	return builder.String()
+}
+
+// renderLogseqHighlight writes a highlight block. Logseq reads block properties
+// from the lines right after the first line, so longer text continues below them.
+func renderLogseqHighlight(builder *strings.Builder, highlight *entities.Highlight) {
+	lines := strings.Split(strings.TrimSpace(highlight.Text), "\n")
+	fmt.Fprintf(builder, "- %s\n", lines[0])
+
+	if !highlight.HighlightedAt.IsZero() {
+		fmt.Fprintf(builder, "  highlighted:: [[%s]]\n", logseqDate(highlight.HighlightedAt))
+	}
+	if location := describeLocation(highlight); location != "" {
+		fmt.Fprintf(builder, "  location:: %s\n", location)
+	}
+	if highlight.Chapter != "" {
+		fmt.Fprintf(builder, "  chapter:: %s\n", logseqValue(highlight.Chapter))
+	}
+	if color := utils.ColorName(highlight.Color); color != "" {
+		fmt.Fprintf(builder, "  color:: %s\n", color)
+	}
+	if highlight.Style == entities.HighlightStyleUnderline || highlight.Style == entities.HighlightStyleStrikethrough {
+		fmt.Fprintf(builder, "  style:: %s\n", highlight.Style)
+	}
+	if highlight.IsFavorite {
+		fmt.Fprintf(builder, "  favorite:: true\n")
+	}
+	if len(highlight.Tags) > 0 {
+		names := make([]string, len(highlight.Tags))
+		for i, tag := range highlight.Tags {
+			names[i] = tag.Name
+		}
+		fmt.Fprintf(builder, "  tags:: %s\n", strings.Join(names, ", "))
+	}
+
+	for _, line := range lines[1:] {
+		fmt.Fprintf(builder, "  %s\n", line)
+	}
+
+	if note := strings.TrimSpace(highlight.Note); note != "" {
+		for i, line := range strings.Split(note, "\n") {
+			if i == 0 {
+				fmt.Fprintf(builder, "\t- **Note:** %s\n", line)
+				continue
+			}
+			fmt.Fprintf(builder, "\t  %s\n", line)
+		}
+	}
+}
+
+// GenerateVocabularyLogseq renders the vocabulary list as a Logseq page with a
+// block per word and its definitions as child blocks
+func GenerateVocabularyLogseq(words []entities.Word) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "title:: Vocabulary\n")
+	fmt.Fprintf(&builder, "tags:: vocabulary, words\n")
+	fmt.Fprintf(&builder, "word-count:: %d\n\n", len(words))
+
+	for _, word := range words {
+		fmt.Fprintf(&builder, "- **%s**\n", word.Word)
+		if word.SourceBookTitle != "" {
+			fmt.Fprintf(&builder, "  source:: [[%s]]\n", logseqValue(word.SourceBookTitle))
+		}
+		if word.Context != "" {
+			fmt.Fprintf(&builder, "\t- > %s\n", strings.ReplaceAll(word.Context, "\n", " "))
+		}
+		for _, def := range word.Definitions {
+			if def.PartOfSpeech != "" {
+				fmt.Fprintf(&builder, "\t- *%s* %s\n", def.PartOfSpeech, def.Definition)
+			} else {
+				fmt.Fprintf(&builder, "\t- %s\n", def.Definition)
+			}
+			if def.Example != "" {
+				fmt.Fprintf(&builder, "\t\t- *Example: %s*\n", def.Example)
+			}
+		}
+	}
+
+	if credits := definitionCredits(words); len(credits) > 0 {
+		fmt.Fprintf(&builder, "- Credits\n")
+		for _, credit := range credits {
+			fmt.Fprintf(&builder, "\t- %s\n", credit)
+		}
+	}
+
+	return builder.String()
+}
+
+// logseqDate formats t as a journal page name in Logseq's default
+// "MMM do, yyyy" format, e.g. "Jun 15th, 2024"
+func logseqDate(t time.Time) string {
+	day := t.Day()
+	suffix := "th"
+	if day < 11 || day > 13 {
+		switch day % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%s %d%s, %d", t.Format("Jan"), day, suffix, t.Year())
+}
+
+// logseqValue keeps a property value on one line and stops brackets from
+// closing a page reference early
+func logseqValue(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	return strings.NewReplacer("[[", "(", "]]", ")").Replace(value)
+}
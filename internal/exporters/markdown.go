@@ -3,6 +3,7 @@ package exporters
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -15,6 +16,9 @@ type MarkdownExporter struct {
 	IndexFileName string
 	currentBook   entities.Book
 	Result        ExportResult
+	Filter        ExportFilter // Applied to every export; empty exports everything
+	FilenameStyle string       // One of FilenameStyles; empty means FilenameStyleTitle
+	Language      string       // UI language of the index files; empty writes English with ISO dates
 }
 
 func NewMarkdownExporter(exportDir string) *MarkdownExporter {
@@ -42,23 +46,15 @@ func (exporter *MarkdownExporter) ensureDirs() (string, error) {
 	return exporter.ExportDir, nil
 }
 
-func (exporter *MarkdownExporter) exportBook(book entities.Book, exportDir string) (string, error) {
-	// Determine source folder
-	sourceFolder := "unknown"
-	if book.Source.Name != "" {
-		sourceFolder = book.Source.Name
+func (exporter *MarkdownExporter) exportBook(book entities.Book, files *exportFiles) (string, error) {
+	outputPath, err := files.path(&book)
+	if err != nil {
+		return "", err
 	}
-
-	// Create source subdirectory
-	sourceDir := fmt.Sprintf("%s/%s", exportDir, sourceFolder)
-	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create source directory: %w", err)
 	}
 
-	// Sanitize title for filename
-	safeTitle := sanitizeFilename(book.Title)
-	outputPath := fmt.Sprintf("%s/%s.md", sourceDir, safeTitle)
-
 	fmt.Printf("Exporting book: %s to %s\n", book.Title, outputPath)
 
 	outpotBookFile, err := os.Create(outputPath)
@@ -75,26 +71,10 @@ func (exporter *MarkdownExporter) exportBook(book entities.Book, exportDir strin
 	if writeError != nil {
 		return "", writeError
 	}
+	files.setNotes(outputPath, book.Highlights)
 	return outputPath, nil
 }
 
-// sanitizeFilename removes/replaces characters that are invalid in filenames
-func sanitizeFilename(name string) string {
-	// Replace problematic characters with safe alternatives
-	replacer := strings.NewReplacer(
-		"/", "-",
-		"\\", "-",
-		":", "-",
-		"*", "",
-		"?", "",
-		"\"", "'",
-		"<", "",
-		">", "",
-		"|", "-",
-	)
-	return replacer.Replace(name)
-}
-
 func GenerateMarkdown(book *entities.Book) string {
 	var builder strings.Builder
 
@@ -110,7 +90,28 @@ func GenerateMarkdown(book *entities.Book) string {
 	fmt.Fprintf(&builder, "created_at: %s\n", currentDateTime)
 	fmt.Fprintf(&builder, "title: \"%s\"\n", strings.ReplaceAll(book.Title, "\"", "\\\""))
 	fmt.Fprintf(&builder, "author: \"%s\"\n", strings.ReplaceAll(book.Author, "\"", "\\\""))
+	if book.Series != "" {
+		fmt.Fprintf(&builder, "series: \"%s\"\n", strings.ReplaceAll(book.Series, "\"", "\\\""))
+		if book.SeriesIndex > 0 {
+			fmt.Fprintf(&builder, "series_index: %g\n", book.SeriesIndex)
+		}
+	}
+	if book.URL != "" {
+		fmt.Fprintf(&builder, "url: \"%s\"\n", strings.ReplaceAll(book.URL, "\"", "%22"))
+	}
+	if book.PublishedAt != nil {
+		fmt.Fprintf(&builder, "published: %s\n", book.PublishedAt.Format("2006-01-02"))
+	}
 	fmt.Fprintf(&builder, "highlights_count: %d\n", len(book.Highlights))
+	if book.Rating > 0 {
+		fmt.Fprintf(&builder, "rating: %g\n", book.Rating)
+	}
+	if book.DateRead != nil {
+		fmt.Fprintf(&builder, "date_read: %s\n", book.DateRead.Format("2006-01-02"))
+	}
+	if last := lastHighlightAt(book.Highlights); !last.IsZero() {
+		fmt.Fprintf(&builder, "last_highlight_at: %s\n", last.Format("2006-01-02"))
+	}
 
 	// Include book tags in YAML frontmatter
 	tags := collectAllTags(book)
@@ -120,6 +121,12 @@ func GenerateMarkdown(book *entities.Book) string {
 		fmt.Fprintf(&builder, "tags: [highlights, books]\n")
 	}
 
+	// Include highlight colors so notes can be queried by color meaning
+	colors := collectColors(book.Highlights)
+	if len(colors) > 0 {
+		fmt.Fprintf(&builder, "colors: [%s]\n", strings.Join(colors, ", "))
+	}
+
 	// Count favorites for summary
 	favoriteCount := countFavorites(book.Highlights)
 	if favoriteCount > 0 {
@@ -142,6 +149,13 @@ func GenerateMarkdown(book *entities.Book) string {
 		renderHighlight(&builder, &highlight)
 	}
 
+	if len(book.Notes) > 0 {
+		fmt.Fprintf(&builder, "## Notes\n\n")
+		for _, note := range book.Notes {
+			fmt.Fprintf(&builder, "### %s\n\n%s\n\n", note.Date.Format("2006-01-02"), strings.TrimSpace(note.Text))
+		}
+	}
+
 	return builder.String()
 }
 
@@ -172,10 +186,23 @@ func renderHighlight(builder *strings.Builder, highlight *entities.Highlight) {
 		fmt.Fprintf(builder, "> %s\n", line)
 	}
 
-	// Add note if present
-	if highlight.Note != "" {
+	// Add note if present. Notes are Markdown, so every line stays in the callout
+	// for Obsidian to render.
+	if note := strings.TrimSpace(highlight.Note); note != "" {
+		fmt.Fprintf(builder, "> \n")
+		for i, line := range strings.Split(note, "\n") {
+			if i == 0 {
+				fmt.Fprintf(builder, "> **Note:** %s\n", line)
+				continue
+			}
+			fmt.Fprintf(builder, "> %s\n", line)
+		}
+	}
+
+	// Link to the highlight on its web page
+	if highlight.URL != "" {
 		fmt.Fprintf(builder, "> \n")
-		fmt.Fprintf(builder, "> **Note:** %s\n", highlight.Note)
+		fmt.Fprintf(builder, "> [View on page](<%s>)\n", highlight.URL)
 	}
 
 	// Add style indicators for underline/strikethrough
@@ -201,7 +228,39 @@ func renderHighlight(builder *strings.Builder, highlight *entities.Highlight) {
 		fmt.Fprintf(builder, "> Tags: %s\n", strings.Join(highlightTags, " "))
 	}
 
+	// Add links to other highlights as wikilinks to their block IDs
+	var links []string
+	for _, link := range highlight.Links {
+		if link.To == nil {
+			continue
+		}
+		label := link.Type.Label()
+		links = append(links, fmt.Sprintf("> %s%s: %s\n", strings.ToUpper(label[:1]), label[1:], highlightWikilink(link.To)))
+	}
+	if len(links) > 0 {
+		fmt.Fprintf(builder, "> \n")
+		builder.WriteString(strings.Join(links, ""))
+	}
+
 	fmt.Fprintf(builder, "\n")
+
+	// Linked-to highlights get a block ID so wikilinks can point at them.
+	// Obsidian needs it on its own line after a callout.
+	if len(highlight.Backlinks) > 0 {
+		fmt.Fprintf(builder, "^%s\n\n", highlightBlockID(highlight.ID))
+	}
+}
+
+// highlightBlockID is the Obsidian block ID of an exported highlight
+func highlightBlockID(id uint) string {
+	return fmt.Sprintf("hl-%d", id)
+}
+
+// highlightWikilink links to a highlight's block in its book's note. The note
+// is named after the book title as with FilenameStyleTitle.
+func highlightWikilink(highlight *entities.Highlight) string {
+	title := highlight.Book.Title
+	return fmt.Sprintf("[[%s#^%s|%s]]", SanitizeFilename(title, FilenameStyleTitle), highlightBlockID(highlight.ID), strings.ReplaceAll(title, "|", "-"))
 }
 
 // getCalloutType determines the Obsidian callout type based on highlight properties
@@ -261,7 +320,36 @@ func collectAllTags(book *entities.Book) []string {
 	return tags
 }
 
+// collectColors returns the color names used by highlights, in utils.HighlightColorNames order
+func collectColors(highlights []entities.Highlight) []string {
+	used := make(map[string]bool)
+	for _, h := range highlights {
+		if name := utils.ColorName(h.Color); name != "" {
+			used[name] = true
+		}
+	}
+
+	colors := make([]string, 0, len(used))
+	for _, name := range utils.HighlightColorNames {
+		if used[name] {
+			colors = append(colors, name)
+		}
+	}
+	return colors
+}
+
 // countFavorites counts how many highlights are marked as favorites
+// lastHighlightAt returns when the most recent highlight was made, or the zero time
+func lastHighlightAt(highlights []entities.Highlight) time.Time {
+	var last time.Time
+	for _, h := range highlights {
+		if h.HighlightedAt.After(last) {
+			last = h.HighlightedAt
+		}
+	}
+	return last
+}
+
 func countFavorites(highlights []entities.Highlight) int {
 	count := 0
 	for _, h := range highlights {
@@ -330,9 +418,45 @@ func GenerateVocabularyMarkdown(words []entities.Word) string {
 		fmt.Fprintf(&builder, "---\n\n")
 	}
 
+	if credits := definitionCredits(words); len(credits) > 0 {
+		fmt.Fprintf(&builder, "## Credits\n\n")
+		for _, credit := range credits {
+			fmt.Fprintf(&builder, "- %s\n", credit)
+		}
+	}
+
 	return builder.String()
 }
 
+// definitionCredits returns the attribution owed for the words' definitions,
+// once per dictionary and license, in order of first use
+func definitionCredits(words []entities.Word) []string {
+	var credits []string
+	seen := make(map[string]bool)
+	for _, word := range words {
+		for _, def := range word.Definitions {
+			if def.Attribution == "" && def.License == "" {
+				continue
+			}
+			credit := def.Attribution
+			if def.License != "" {
+				if credit != "" {
+					credit += ". "
+				}
+				credit += "Licensed under " + def.License
+				if def.LicenseURL != "" {
+					credit += ": " + def.LicenseURL
+				}
+			}
+			if !seen[credit] {
+				seen[credit] = true
+				credits = append(credits, credit)
+			}
+		}
+	}
+	return credits
+}
+
 // ExportVocabulary exports all vocabulary words to a single markdown file
 func (exporter *MarkdownExporter) ExportVocabulary(words []entities.Word) error {
 	// Check if export directory is configured
@@ -363,7 +487,35 @@ func (exporter *MarkdownExporter) ExportVocabulary(words []entities.Word) error
 	return nil
 }
 
+// SetFilter sets the filter applied to subsequent exports
+func (exporter *MarkdownExporter) SetFilter(filter ExportFilter) {
+	exporter.Filter = filter
+}
+
+// SetFilenameStyle sets how book titles become file names
+func (exporter *MarkdownExporter) SetFilenameStyle(style string) {
+	exporter.FilenameStyle = style
+}
+
+// SetLanguage sets the language the index files are written in
+func (exporter *MarkdownExporter) SetLanguage(language string) {
+	exporter.Language = language
+}
+
+// Export writes one file per book and regenerates the index files
 func (exporter *MarkdownExporter) Export(books []entities.Book) (ExportResult, error) {
+	result, err := exporter.export(books)
+	if err != nil {
+		return result, err
+	}
+	if err := exporter.WriteIndex(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// export writes one file per book without touching the index files
+func (exporter *MarkdownExporter) export(books []entities.Book) (ExportResult, error) {
 	// Reset result state for each export
 	exporter.Result = ExportResult{}
 
@@ -372,9 +524,18 @@ func (exporter *MarkdownExporter) Export(books []entities.Book) (ExportResult, e
 		return ExportResult{}, dirsErr
 	}
 
+	files, err := openExportFiles(exportDir, FormatMarkdown, exporter.FilenameStyle)
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	books, skipped := exporter.Filter.apply(books)
+	exporter.Result.BooksSkipped = skipped.BooksSkipped
+	exporter.Result.HighlightsSkipped = skipped.HighlightsSkipped
+
 	for _, book := range books {
 		exporter.currentBook = book
-		_, err := exporter.exportBook(book, exportDir)
+		_, err := exporter.exportBook(book, files)
 		// TODO: log error instead and continue
 		if err != nil {
 			return ExportResult{}, err
@@ -382,5 +543,8 @@ func (exporter *MarkdownExporter) Export(books []entities.Book) (ExportResult, e
 		exporter.Result.BooksProcessed++
 	}
 
+	if err := files.save(); err != nil {
+		return ExportResult{}, err
+	}
 	return exporter.Result, nil
 }
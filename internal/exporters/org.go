@@ -0,0 +1,230 @@
+package exporters
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/utils"
+)
+
+// OrgExporter writes one org-mode file per book into <dir>/<source>. Each
+// file carries an :ID: property, so org-roam picks the books up as nodes.
+type OrgExporter struct {
+	ExportDir     string
+	Filter        ExportFilter // Applied to every export; empty exports everything
+	FilenameStyle string       // One of FilenameStyles; empty means FilenameStyleTitle
+}
+
+func NewOrgExporter(exportDir string) *OrgExporter {
+	return &OrgExporter{ExportDir: exportDir}
+}
+
+func (exporter *OrgExporter) Export(books []entities.Book) (ExportResult, error) {
+	return formatWriter{exportDir: exporter.ExportDir, format: FormatOrg, filter: exporter.Filter, filenameStyle: exporter.FilenameStyle}.export(books)
+}
+
+// SetFilter sets the filter applied to subsequent exports
+func (exporter *OrgExporter) SetFilter(filter ExportFilter) {
+	exporter.Filter = filter
+}
+
+// SetFilenameStyle sets how book titles become file names
+func (exporter *OrgExporter) SetFilenameStyle(style string) {
+	exporter.FilenameStyle = style
+}
+
+// SetLanguage does nothing; only markdown exports have index files
+func (exporter *OrgExporter) SetLanguage(language string) {}
+
+// ExportVocabulary writes all vocabulary words to vocabulary.org
+func (exporter *OrgExporter) ExportVocabulary(words []entities.Word) error {
+	return formatWriter{exportDir: exporter.ExportDir, format: FormatOrg}.exportVocabulary(words)
+}
+
+// GenerateOrg renders a book as an org-mode file: a file-level PROPERTIES
+// drawer and keywords, then a heading per highlight with its own drawer.
+func GenerateOrg(book *entities.Book) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, ":PROPERTIES:\n")
+	fmt.Fprintf(&builder, ":ID:       %s\n", orgID("book", book.Title, book.Author))
+	if book.Author != "" {
+		fmt.Fprintf(&builder, ":AUTHOR:   %s\n", orgLine(book.Author))
+	}
+	fmt.Fprintf(&builder, ":SOURCE:   %s\n", sourceFolderName(book))
+	fmt.Fprintf(&builder, ":HIGHLIGHTS_COUNT: %d\n", len(book.Highlights))
+	if book.Rating > 0 {
+		fmt.Fprintf(&builder, ":RATING:   %g\n", book.Rating)
+	}
+	if book.DateRead != nil {
+		fmt.Fprintf(&builder, ":DATE_READ: %s\n", orgDate(*book.DateRead))
+	}
+	fmt.Fprintf(&builder, ":END:\n")
+	fmt.Fprintf(&builder, "#+title: %s\n", orgLine(book.Title))
+	if book.Author != "" {
+		fmt.Fprintf(&builder, "#+author: %s\n", orgLine(book.Author))
+	}
+	fmt.Fprintf(&builder, "#+filetags: %s\n", orgTags(collectAllTags(book)))
+	fmt.Fprintf(&builder, "\n* Highlights\n")
+
+	for _, highlight := range book.Highlights {
+		renderOrgHighlight(&builder, &highlight)
+	}
+
+	return builder.String()
+}
+
+// renderOrgHighlight writes a highlight as a second-level heading named after its
+// chapter or location, with the text in a quote block and the note below it
+func renderOrgHighlight(builder *strings.Builder, highlight *entities.Highlight) {
+	title := highlight.Chapter
+	if title == "" {
+		title = describeLocation(highlight)
+	}
+	if title == "" {
+		title = formatHighlightTime(highlight)
+	}
+	heading := "** " + orgLine(title)
+	if len(highlight.Tags) > 0 {
+		names := make([]string, len(highlight.Tags))
+		for i, tag := range highlight.Tags {
+			names[i] = tag.Name
+		}
+		heading += " " + orgTags(names)
+	}
+	fmt.Fprintf(builder, "%s\n", heading)
+
+	fmt.Fprintf(builder, ":PROPERTIES:\n")
+	if !highlight.HighlightedAt.IsZero() {
+		fmt.Fprintf(builder, ":HIGHLIGHTED_AT: %s\n", orgTimestamp(highlight.HighlightedAt))
+	}
+	if location := describeLocation(highlight); location != "" {
+		fmt.Fprintf(builder, ":LOCATION: %s\n", location)
+	}
+	if highlight.Chapter != "" {
+		fmt.Fprintf(builder, ":CHAPTER:  %s\n", orgLine(highlight.Chapter))
+	}
+	if color := utils.ColorName(highlight.Color); color != "" {
+		fmt.Fprintf(builder, ":COLOR:    %s\n", color)
+	}
+	if highlight.Style == entities.HighlightStyleUnderline || highlight.Style == entities.HighlightStyleStrikethrough {
+		fmt.Fprintf(builder, ":STYLE:    %s\n", highlight.Style)
+	}
+	if highlight.IsFavorite {
+		fmt.Fprintf(builder, ":FAVORITE: t\n")
+	}
+	fmt.Fprintf(builder, ":END:\n")
+
+	fmt.Fprintf(builder, "#+begin_quote\n%s\n#+end_quote\n", orgEscape(strings.TrimSpace(highlight.Text)))
+	if note := strings.TrimSpace(highlight.Note); note != "" {
+		fmt.Fprintf(builder, "\n%s\n", orgEscape(note))
+	}
+	fmt.Fprintf(builder, "\n")
+}
+
+// GenerateVocabularyOrg renders the vocabulary list as an org-mode file with a
+// heading per word
+func GenerateVocabularyOrg(words []entities.Word) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, ":PROPERTIES:\n")
+	fmt.Fprintf(&builder, ":ID:       %s\n", orgID("vocabulary"))
+	fmt.Fprintf(&builder, ":WORD_COUNT: %d\n", len(words))
+	fmt.Fprintf(&builder, ":END:\n")
+	fmt.Fprintf(&builder, "#+title: Vocabulary\n")
+	fmt.Fprintf(&builder, "#+filetags: :vocabulary:words:\n\n")
+
+	for _, word := range words {
+		fmt.Fprintf(&builder, "* %s\n", orgLine(word.Word))
+		if word.SourceBookTitle != "" {
+			fmt.Fprintf(&builder, ":PROPERTIES:\n:SOURCE_BOOK: %s\n", orgLine(word.SourceBookTitle))
+			if word.SourceBookAuthor != "" {
+				fmt.Fprintf(&builder, ":SOURCE_AUTHOR: %s\n", orgLine(word.SourceBookAuthor))
+			}
+			fmt.Fprintf(&builder, ":END:\n")
+		}
+		if word.Context != "" {
+			fmt.Fprintf(&builder, "#+begin_quote\n%s\n#+end_quote\n", orgEscape(word.Context))
+		}
+		for _, def := range word.Definitions {
+			if def.PartOfSpeech != "" {
+				fmt.Fprintf(&builder, "- /%s/ %s\n", def.PartOfSpeech, def.Definition)
+			} else {
+				fmt.Fprintf(&builder, "- %s\n", def.Definition)
+			}
+			if def.Example != "" {
+				fmt.Fprintf(&builder, "  - Example: /%s/\n", def.Example)
+			}
+		}
+		fmt.Fprintf(&builder, "\n")
+	}
+
+	if credits := definitionCredits(words); len(credits) > 0 {
+		fmt.Fprintf(&builder, "* Credits\n")
+		for _, credit := range credits {
+			fmt.Fprintf(&builder, "- %s\n", orgLine(credit))
+		}
+	}
+
+	return builder.String()
+}
+
+// orgID derives a stable UUID from parts, so re-exports keep org-roam links working
+func orgID(parts ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "\x00")))
+	sum[6] = (sum[6] & 0x0f) | 0x50 // version 5
+	sum[8] = (sum[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// orgTags formats names as an org tag list such as ":books:stoic:". Org tags
+// only allow letters, digits, _ and @, so anything else becomes _.
+func orgTags(names []string) string {
+	seen := make(map[string]bool)
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag := strings.Map(func(r rune) rune {
+			if r == '_' || r == '@' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127 {
+				return r
+			}
+			return '_'
+		}, strings.TrimSpace(name))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return ":" + strings.Join(tags, ":") + ":"
+}
+
+// orgEscape prefixes lines that org would read as headings or keywords with a comma
+func orgEscape(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(line, "*") || strings.HasPrefix(trimmed, "#+") || strings.HasPrefix(trimmed, ",*") || strings.HasPrefix(trimmed, ",#+") {
+			lines[i] = "," + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// orgLine collapses whitespace so a value fits on a heading or property line
+func orgLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// orgDate formats t as an inactive org date, e.g. [2024-06-15 Sat]
+func orgDate(t time.Time) string {
+	return t.Format("[2006-01-02 Mon]")
+}
+
+// orgTimestamp formats t as an inactive org timestamp, e.g. [2024-06-15 Sat 14:30]
+func orgTimestamp(t time.Time) string {
+	return t.Format("[2006-01-02 Mon 15:04]")
+}
@@ -0,0 +1,102 @@
+package exporters
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ErrInvalidExportTarget is returned when an export target fails validation
+var ErrInvalidExportTarget = errors.New("invalid export target")
+
+// TargetFilter returns the filter configured on an export target
+func TargetFilter(target *entities.ExportTarget) ExportFilter {
+	return ExportFilter{
+		FavoritesOnly: target.FavoritesOnly,
+		Tags:          target.Tags,
+		Collections:   target.Collections,
+		Sources:       target.Sources,
+		Since:         target.Since,
+	}
+}
+
+// ViewFilter returns the criteria of a saved view as an export filter, so a
+// view exports the highlights it shows
+func ViewFilter(view *entities.SavedView) ExportFilter {
+	filter := ExportFilter{
+		FavoritesOnly: view.FavouritesOnly,
+		Tags:          view.Tags,
+		Since:         view.From,
+		Until:         view.To,
+		Query:         view.Query,
+	}
+	if view.Source != "" {
+		filter.Sources = []string{view.Source}
+	}
+	return filter
+}
+
+// ValidateTarget checks an export target's name, path and format.
+// The cron schedule is checked by the scheduler that runs it.
+func ValidateTarget(target *entities.ExportTarget) error {
+	target.Name = strings.TrimSpace(target.Name)
+	target.Path = strings.TrimSpace(target.Path)
+	if target.Format == "" {
+		target.Format = FormatMarkdown
+	}
+
+	switch {
+	case target.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidExportTarget)
+	case target.Path == "":
+		return fmt.Errorf("%w: path is required", ErrInvalidExportTarget)
+	case !slices.Contains(Formats, target.Format):
+		return fmt.Errorf("%w: format must be one of %s", ErrInvalidExportTarget, strings.Join(Formats, ", "))
+	}
+	return nil
+}
+
+// TargetLibrary provides the books and vocabulary written by export targets
+type TargetLibrary interface {
+	GetAllBooks() ([]entities.Book, error)
+	GetAllWords(userID uint, limit, offset int) ([]entities.Word, int64, error)
+}
+
+// RunTarget exports the library to an export target, applying its format and
+// filters, naming files in filenameStyle and writing index files in language
+func RunTarget(library TargetLibrary, target *entities.ExportTarget, filenameStyle, language string) (ExportResult, error) {
+	exporter, err := NewFileExporter(target.Format, target.Path)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	exporter.SetFilter(TargetFilter(target))
+	exporter.SetFilenameStyle(filenameStyle)
+	exporter.SetLanguage(language)
+
+	books, err := library.GetAllBooks()
+	if err != nil {
+		return ExportResult{}, fmt.Errorf("failed to load books: %w", err)
+	}
+
+	result, err := exporter.Export(books)
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	if target.IncludeVocabulary {
+		words, _, err := library.GetAllWords(0, 0, 0)
+		if err != nil {
+			return result, fmt.Errorf("failed to load vocabulary: %w", err)
+		}
+		if len(words) > 0 {
+			if err := exporter.ExportVocabulary(words); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package exporters
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTarget(t *testing.T) {
+	target := &entities.ExportTarget{Name: " vault ", Path: "/vault"}
+	require.NoError(t, ValidateTarget(target))
+	assert.Equal(t, "vault", target.Name)
+	assert.Equal(t, FormatMarkdown, target.Format, "format defaults to markdown")
+
+	for _, invalid := range []entities.ExportTarget{
+		{Path: "/vault"},
+		{Name: "vault"},
+		{Name: "vault", Path: "/vault", Format: "docx"},
+	} {
+		assert.ErrorIs(t, ValidateTarget(&invalid), ErrInvalidExportTarget)
+	}
+}
+
+type stubLibrary struct {
+	books []entities.Book
+	words []entities.Word
+}
+
+func (s stubLibrary) GetAllBooks() ([]entities.Book, error) { return s.books, nil }
+
+func (s stubLibrary) GetAllWords(userID uint, limit, offset int) ([]entities.Word, int64, error) {
+	return s.words, int64(len(s.words)), nil
+}
+
+func TestRunTarget(t *testing.T) {
+	dir := t.TempDir()
+	library := stubLibrary{books: filterTestBooks(), words: []entities.Word{{Word: "ataraxia"}}}
+
+	result, err := RunTarget(library, &entities.ExportTarget{Path: dir, Format: FormatOrg, FavoritesOnly: true, IncludeVocabulary: true}, FilenameStyleTitle, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.BooksProcessed)
+	assert.Equal(t, 2, result.HighlightsProcessed)
+
+	for _, file := range []string{"unknown/Tagged Book.org", "unknown/Untagged Book.org", "vocabulary.org"} {
+		_, err := os.Stat(filepath.Join(dir, file))
+		assert.NoError(t, err, file)
+	}
+	_, err = os.Stat(filepath.Join(dir, "unknown", "Plain Book.org"))
+	assert.True(t, os.IsNotExist(err), "books without matching highlights are skipped")
+}
@@ -0,0 +1,210 @@
+package goodreads
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+// Store provides the book and tag operations needed by the importer.
+type Store interface {
+	GetAllBooks() ([]entities.Book, error)
+	SaveBook(book *entities.Book) error
+	UpdateBookMetadata(id uint, fields map[string]any) error
+	GetOrCreateTag(name string, userID uint) (*entities.Tag, error)
+	AddTagToBook(bookID, tagID uint) error
+}
+
+// Result summarizes a library import.
+type Result struct {
+	Format  Format   `json:"format"`
+	Total   int      `json:"total"`
+	Matched int      `json:"matched"`
+	Created int      `json:"created"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// Importer applies library export entries to the book collection.
+type Importer struct {
+	store  Store
+	userID uint
+}
+
+// NewImporter creates an importer. Shelf tags are created for userID.
+func NewImporter(store Store, userID uint) *Importer {
+	return &Importer{store: store, userID: userID}
+}
+
+// bookIndex finds existing books by ISBN, by title and author, or by title alone
+// when only one book has that title.
+type bookIndex struct {
+	byISBN        map[string]*entities.Book
+	byTitleAuthor map[string]*entities.Book
+	byTitle       map[string][]*entities.Book
+}
+
+// Import matches each entry against existing books and fills in rating, read
+// date and shelves. Entries without a match are created as books without highlights.
+func (i *Importer) Import(entries []Entry, format Format) (*Result, error) {
+	books, err := i.store.GetAllBooks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load books: %w", err)
+	}
+
+	index := newBookIndex()
+	for j := range books {
+		index.add(&books[j])
+	}
+
+	result := &Result{Format: format, Total: len(entries)}
+	for _, entry := range entries {
+		book := index.find(entry)
+		if book != nil {
+			if fields := entryFields(entry, book); len(fields) > 0 {
+				if err := i.store.UpdateBookMetadata(book.ID, fields); err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry.Title, err))
+					continue
+				}
+			}
+			result.Matched++
+		} else {
+			book = newStubBook(entry, format)
+			if err := i.store.SaveBook(book); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry.Title, err))
+				continue
+			}
+			if book.ID == 0 {
+				// SaveBook skips permanently deleted books
+				continue
+			}
+			index.add(book)
+			result.Created++
+		}
+
+		for _, shelf := range entry.Shelves {
+			tag, err := i.store.GetOrCreateTag(shelf, i.userID)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: shelf %q: %v", entry.Title, shelf, err))
+				continue
+			}
+			if err := i.store.AddTagToBook(book.ID, tag.ID); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: shelf %q: %v", entry.Title, shelf, err))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// entryFields returns the metadata updates for a matched book.
+// The ISBN and series are only filled in when the book has none.
+func entryFields(entry Entry, book *entities.Book) map[string]any {
+	fields := make(map[string]any)
+	if entry.Rating > 0 {
+		fields["rating"] = entry.Rating
+	}
+	if !entry.DateRead.IsZero() {
+		fields["date_read"] = entry.DateRead
+	}
+	if book.ISBN == "" && entry.ISBN != "" {
+		fields["isbn"] = entry.ISBN
+	}
+	if _, series, index := metadata.SplitTitleSeries(entry.Title); book.Series == "" && series != "" {
+		fields["series"] = series
+		fields["series_index"] = index
+	}
+	return fields
+}
+
+func newStubBook(entry Entry, format Format) *entities.Book {
+	book := &entities.Book{
+		Title:           entry.Title,
+		Author:          entry.Author,
+		ISBN:            entry.ISBN,
+		Publisher:       entry.Publisher,
+		PublicationYear: entry.PublicationYear,
+		Rating:          entry.Rating,
+		Source:          entities.Source{Name: string(format)},
+	}
+	if !entry.DateRead.IsZero() {
+		dateRead := entry.DateRead
+		book.DateRead = &dateRead
+	}
+	_, book.Series, book.SeriesIndex = metadata.SplitTitleSeries(entry.Title)
+	return book
+}
+
+func newBookIndex() *bookIndex {
+	return &bookIndex{
+		byISBN:        make(map[string]*entities.Book),
+		byTitleAuthor: make(map[string]*entities.Book),
+		byTitle:       make(map[string][]*entities.Book),
+	}
+}
+
+func (idx *bookIndex) add(book *entities.Book) {
+	if isbn := normalizeISBN(book.ISBN); isbn != "" {
+		idx.byISBN[isbn] = book
+	}
+	title := normalizeTitle(book.Title)
+	idx.byTitleAuthor[title+"|"+normalizeAuthor(book.Author)] = book
+	idx.byTitle[title] = append(idx.byTitle[title], book)
+}
+
+func (idx *bookIndex) find(entry Entry) *entities.Book {
+	if isbn := normalizeISBN(entry.ISBN); isbn != "" {
+		if book, ok := idx.byISBN[isbn]; ok {
+			return book
+		}
+	}
+
+	title := normalizeTitle(entry.Title)
+	if book, ok := idx.byTitleAuthor[title+"|"+normalizeAuthor(entry.Author)]; ok {
+		return book
+	}
+	if candidates := idx.byTitle[title]; len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// normalizeTitle drops subtitles and series suffixes such as "Dune (Dune, #1)"
+// and keeps only lowercase letters and digits.
+func normalizeTitle(title string) string {
+	if i := strings.IndexAny(title, ":("); i > 0 {
+		title = title[:i]
+	}
+	return strings.Join(words(title), " ")
+}
+
+// normalizeAuthor sorts name parts so "Harari, Yuval Noah" matches "Yuval Noah Harari".
+// Only the first author of a comma-separated list of full names is used.
+func normalizeAuthor(author string) string {
+	parts := strings.Split(author, ",")
+	if len(parts) > 1 && len(words(parts[0])) > 1 {
+		// "Author One, Author Two" rather than "Last, First"
+		author = parts[0]
+	}
+	names := words(author)
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// normalizeISBN converts ISBN-10 to ISBN-13 so both forms match.
+func normalizeISBN(isbn string) string {
+	isbn = cleanISBN(isbn)
+	if isbn13, _ := metadata.ISBNForms(isbn); isbn13 != "" {
+		return isbn13
+	}
+	return isbn
+}
@@ -0,0 +1,135 @@
+package goodreads
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+type mockStore struct {
+	books    []*entities.Book
+	updates  map[uint]map[string]any
+	tags     map[string]*entities.Tag
+	bookTags map[uint][]string
+}
+
+func newMockStore(books ...*entities.Book) *mockStore {
+	for i, b := range books {
+		b.ID = uint(i + 1)
+	}
+	return &mockStore{
+		books:    books,
+		updates:  make(map[uint]map[string]any),
+		tags:     make(map[string]*entities.Tag),
+		bookTags: make(map[uint][]string),
+	}
+}
+
+func (m *mockStore) GetAllBooks() ([]entities.Book, error) {
+	books := make([]entities.Book, len(m.books))
+	for i, b := range m.books {
+		books[i] = *b
+	}
+	return books, nil
+}
+
+func (m *mockStore) SaveBook(book *entities.Book) error {
+	book.ID = uint(len(m.books) + 1)
+	m.books = append(m.books, book)
+	return nil
+}
+
+func (m *mockStore) UpdateBookMetadata(id uint, fields map[string]any) error {
+	m.updates[id] = fields
+	return nil
+}
+
+func (m *mockStore) GetOrCreateTag(name string, userID uint) (*entities.Tag, error) {
+	key := strings.ToLower(name)
+	if tag, ok := m.tags[key]; ok {
+		return tag, nil
+	}
+	tag := &entities.Tag{ID: uint(len(m.tags) + 1), Name: name, UserID: userID}
+	m.tags[key] = tag
+	return tag, nil
+}
+
+func (m *mockStore) AddTagToBook(bookID, tagID uint) error {
+	for _, tag := range m.tags {
+		if tag.ID == tagID {
+			m.bookTags[bookID] = append(m.bookTags[bookID], tag.Name)
+		}
+	}
+	return nil
+}
+
+func TestImporter_MatchesExistingBooks(t *testing.T) {
+	store := newMockStore(
+		&entities.Book{Title: "Sapiens", Author: "Harari, Yuval Noah"},
+		&entities.Book{Title: "Dune", Author: "Frank Herbert"},
+		&entities.Book{Title: "Some Book", Author: "Someone", ISBN: "0735211299"},
+	)
+	dateRead := time.Date(2023, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	entries := []Entry{
+		{Title: "Sapiens: A Brief History of Humankind", Author: "Yuval Noah Harari", ISBN: "9780062316097", Rating: 5, DateRead: dateRead, Shelves: []string{"read", "favorites"}},
+		{Title: "Dune (Dune, #1)", Author: "Frank Herbert", Rating: 4},
+		// ISBN-10 on the existing book matches the ISBN-13 from the export
+		{Title: "Atomic Habits", Author: "James Clear", ISBN: "9780735211292", Shelves: []string{"to-read"}},
+	}
+
+	result, err := NewImporter(store, 1).Import(entries, FormatGoodreads)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 3, result.Matched)
+	assert.Zero(t, result.Created)
+
+	assert.Equal(t, map[string]any{"rating": 5.0, "date_read": dateRead, "isbn": "9780062316097"}, store.updates[1])
+	assert.Equal(t, map[string]any{"rating": 4.0, "series": "Dune", "series_index": 1.0}, store.updates[2], "series comes from the title")
+	assert.NotContains(t, store.updates, uint(3), "existing ISBN is kept and nothing else changed")
+	assert.Equal(t, []string{"read", "favorites"}, store.bookTags[1])
+	assert.Equal(t, []string{"to-read"}, store.bookTags[3])
+}
+
+func TestImporter_CreatesStubBooks(t *testing.T) {
+	store := newMockStore()
+	dateRead := time.Date(2023, 6, 20, 0, 0, 0, 0, time.UTC)
+
+	entries := []Entry{
+		{Title: "The Left Hand of Darkness", Author: "Ursula K. Le Guin", ISBN: "9780441478125", Rating: 4.25, DateRead: dateRead, Shelves: []string{"read"}},
+		// Listed twice in one export: the second row matches the stub created for the first
+		{Title: "The Left Hand of Darkness", Author: "Ursula K. Le Guin"},
+	}
+
+	result, err := NewImporter(store, 1).Import(entries, FormatStoryGraph)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Matched)
+	require.Len(t, store.books, 1)
+
+	stub := store.books[0]
+	assert.Equal(t, "storygraph", stub.Source.Name)
+	assert.Equal(t, 4.25, stub.Rating)
+	require.NotNil(t, stub.DateRead)
+	assert.Equal(t, dateRead, *stub.DateRead)
+	assert.Empty(t, stub.Highlights)
+	assert.Equal(t, []string{"read"}, store.bookTags[stub.ID])
+}
+
+func TestNormalizeISBN(t *testing.T) {
+	assert.Equal(t, "9780062316097", normalizeISBN("0062316095"))
+	assert.Equal(t, "9780062316097", normalizeISBN("978-0-06-231609-7"))
+	assert.Empty(t, normalizeISBN("not-an-isbn"))
+}
+
+func TestNormalizeAuthor(t *testing.T) {
+	assert.Equal(t, normalizeAuthor("Yuval Noah Harari"), normalizeAuthor("Harari, Yuval Noah"))
+	assert.Equal(t, normalizeAuthor("Terry Pratchett"), normalizeAuthor("Terry Pratchett, Neil Gaiman"))
+}
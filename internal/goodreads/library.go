@@ -0,0 +1,214 @@
+// Package goodreads imports library exports from Goodreads and StoryGraph.
+//
+// Both services export a CSV of every shelved book with ratings, shelves and
+// read dates. The importer matches rows against existing books and fills in
+// that metadata, creating stub books for titles without highlights.
+package goodreads
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+// Format identifies the service that produced a library export.
+type Format string
+
+const (
+	FormatGoodreads  Format = "goodreads"
+	FormatStoryGraph Format = "storygraph"
+)
+
+// ErrUnknownFormat is returned when the CSV header matches neither Goodreads nor StoryGraph.
+var ErrUnknownFormat = errors.New("not a Goodreads or StoryGraph library export")
+
+// Entry is a single book from a library export.
+type Entry struct {
+	Title           string
+	Author          string
+	ISBN            string // ISBN-13, digits only; empty when missing or invalid
+	Publisher       string
+	PublicationYear int
+	Rating          float64 // 0 when unrated
+	Shelves         []string
+	DateRead        time.Time
+}
+
+// Date formats used by the exports ("2023/01/15" on both services)
+var exportDateFormats = []string{"2006/01/02", "2006-01-02", "01/02/2006"}
+
+// ParseCSV reads a Goodreads or StoryGraph library export.
+// Rows that cannot be read are reported in the returned error list and skipped.
+// ISBNs whose check digit does not match are reported there too and dropped,
+// so the book is matched by title instead.
+func ParseCSV(r io.Reader) ([]Entry, Format, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Allow variable number of fields
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	headerIndex := make(map[string]int)
+	for i, h := range header {
+		headerIndex[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+
+	format, err := detectFormat(headerIndex)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var entries []Entry
+	var rowErrors []string
+	lineNum := 1 // Header already read
+
+	for {
+		lineNum++
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, fmt.Sprintf("Line %d: %v", lineNum, err))
+			continue
+		}
+
+		var entry Entry
+		if format == FormatGoodreads {
+			entry = goodreadsEntry(record, headerIndex)
+		} else {
+			entry = storyGraphEntry(record, headerIndex)
+		}
+
+		if entry.Title == "" {
+			rowErrors = append(rowErrors, fmt.Sprintf("Line %d: missing title", lineNum))
+			continue
+		}
+		if entry.ISBN != "" {
+			isbn, err := metadata.ParseISBN(entry.ISBN)
+			if err != nil {
+				rowErrors = append(rowErrors, fmt.Sprintf("Line %d: ISBN %s ignored: %v", lineNum, entry.ISBN, err))
+			}
+			entry.ISBN, _ = metadata.ISBNForms(isbn)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, format, rowErrors, nil
+}
+
+func detectFormat(headerIndex map[string]int) (Format, error) {
+	has := func(names ...string) bool {
+		for _, name := range names {
+			if _, ok := headerIndex[name]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case has("title", "author", "my rating", "exclusive shelf"):
+		return FormatGoodreads, nil
+	case has("title", "authors", "read status", "star rating"):
+		return FormatStoryGraph, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+func goodreadsEntry(record []string, headerIndex map[string]int) Entry {
+	get := func(name string) string { return field(record, headerIndex, name) }
+
+	entry := Entry{
+		Title:     get("title"),
+		Author:    get("author"),
+		Publisher: get("publisher"),
+		ISBN:      cleanISBN(get("isbn13")),
+		DateRead:  parseExportDate(get("date read")),
+	}
+	if entry.ISBN == "" {
+		entry.ISBN = cleanISBN(get("isbn"))
+	}
+	entry.PublicationYear, _ = strconv.Atoi(get("original publication year"))
+	if entry.PublicationYear == 0 {
+		entry.PublicationYear, _ = strconv.Atoi(get("year published"))
+	}
+	entry.Rating, _ = strconv.ParseFloat(get("my rating"), 64)
+	entry.Shelves = mergeShelves(get("exclusive shelf"), get("bookshelves"))
+
+	return entry
+}
+
+func storyGraphEntry(record []string, headerIndex map[string]int) Entry {
+	get := func(name string) string { return field(record, headerIndex, name) }
+
+	entry := Entry{
+		Title:    get("title"),
+		Author:   get("authors"),
+		ISBN:     cleanISBN(get("isbn/uid")),
+		DateRead: parseExportDate(get("last date read")),
+	}
+	entry.Rating, _ = strconv.ParseFloat(get("star rating"), 64)
+	entry.Shelves = mergeShelves(get("read status"), get("tags"))
+
+	return entry
+}
+
+func field(record []string, headerIndex map[string]int, name string) string {
+	idx, ok := headerIndex[name]
+	if !ok || idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
+
+// cleanISBN strips Goodreads' spreadsheet quoting (="0143127748") and separators.
+// StoryGraph UIDs that are not ISBNs are dropped.
+func cleanISBN(value string) string {
+	var digits strings.Builder
+	for _, r := range value {
+		if (r >= '0' && r <= '9') || r == 'X' || r == 'x' {
+			digits.WriteRune(r)
+		}
+	}
+	isbn := strings.ToUpper(digits.String())
+	if len(isbn) != 10 && len(isbn) != 13 {
+		return ""
+	}
+	return isbn
+}
+
+// mergeShelves combines the exclusive shelf/read status with additional shelves or tags.
+func mergeShelves(primary, others string) []string {
+	seen := make(map[string]bool)
+	var shelves []string
+	for _, shelf := range append([]string{primary}, strings.Split(others, ",")...) {
+		shelf = strings.TrimSpace(shelf)
+		key := strings.ToLower(shelf)
+		if shelf == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		shelves = append(shelves, shelf)
+	}
+	return shelves
+}
+
+func parseExportDate(value string) time.Time {
+	for _, format := range exportDateFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
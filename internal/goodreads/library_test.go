@@ -0,0 +1,86 @@
+package goodreads
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV_Goodreads(t *testing.T) {
+	file, err := os.Open("testdata/goodreads_library_export.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	entries, format, rowErrors, err := ParseCSV(file)
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	assert.Equal(t, FormatGoodreads, format)
+	require.Len(t, entries, 3)
+
+	sapiens := entries[0]
+	assert.Equal(t, "Sapiens: A Brief History of Humankind", sapiens.Title)
+	assert.Equal(t, "Yuval Noah Harari", sapiens.Author)
+	assert.Equal(t, "9780062316097", sapiens.ISBN)
+	assert.Equal(t, 5.0, sapiens.Rating)
+	assert.Equal(t, 2011, sapiens.PublicationYear)
+	assert.Equal(t, "Harper", sapiens.Publisher)
+	assert.Equal(t, time.Date(2023, 3, 14, 0, 0, 0, 0, time.UTC), sapiens.DateRead)
+	assert.Equal(t, []string{"read", "favorites", "history"}, sapiens.Shelves)
+
+	dune := entries[1]
+	assert.Empty(t, dune.ISBN)
+	assert.Equal(t, []string{"read"}, dune.Shelves)
+
+	atomic := entries[2]
+	assert.Zero(t, atomic.Rating)
+	assert.True(t, atomic.DateRead.IsZero())
+	assert.Equal(t, []string{"to-read"}, atomic.Shelves)
+}
+
+func TestParseCSV_StoryGraph(t *testing.T) {
+	file, err := os.Open("testdata/storygraph_library_export.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	entries, format, _, err := ParseCSV(file)
+	require.NoError(t, err)
+	assert.Equal(t, FormatStoryGraph, format)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "Ursula K. Le Guin", entries[0].Author)
+	assert.Equal(t, "9780441478125", entries[0].ISBN)
+	assert.Equal(t, 4.25, entries[0].Rating)
+	assert.Equal(t, time.Date(2023, 6, 20, 0, 0, 0, 0, time.UTC), entries[0].DateRead)
+	assert.Equal(t, []string{"read", "sci-fi", "classics"}, entries[0].Shelves)
+
+	// StoryGraph IDs that are not ISBNs are dropped
+	assert.Empty(t, entries[1].ISBN)
+	assert.Equal(t, []string{"currently-reading"}, entries[1].Shelves)
+}
+
+func TestParseCSV_ValidatesISBNs(t *testing.T) {
+	csv := "Title,Author,ISBN,ISBN13,My Rating,Exclusive Shelf\n" +
+		"Sapiens,Yuval Noah Harari,\"=\"\"0062316095\"\"\",\"=\"\"\"\"\",5,read\n" +
+		"Typo,Some Author,,\"=\"\"9780062316098\"\"\",3,read\n"
+
+	entries, _, rowErrors, err := ParseCSV(strings.NewReader(csv))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// ISBN-10s are stored as their ISBN-13
+	assert.Equal(t, "9780062316097", entries[0].ISBN)
+
+	// Mistyped ISBNs are dropped and reported, the book is still imported
+	assert.Empty(t, entries[1].ISBN)
+	require.Len(t, rowErrors, 1)
+	assert.Contains(t, rowErrors[0], "Line 3: ISBN 9780062316098")
+}
+
+func TestParseCSV_UnknownFormat(t *testing.T) {
+	_, _, _, err := ParseCSV(strings.NewReader("Highlight,Book Title,Book Author\nText,Title,Author\n"))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,657 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Query limits used when the schema does not set them.
+const (
+	defaultMaxDepth = 10
+	defaultMaxCost  = 100000
+	defaultListSize = 10
+)
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the result of executing a request. Data is omitted when the
+// request could not be executed at all, e.g. because of a syntax error.
+type Response struct {
+	Data   any     `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Error is a request or field error. Path locates the field in the response.
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// Execute parses, validates and runs a query against the schema.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	v := &validator{schema: s, doc: doc, variables: make(map[string]bool), values: req.Variables}
+	for _, def := range op.variables {
+		v.variables[def.name] = true
+	}
+	cost := v.selections(s.Query, op.selectionSet, 1, nil)
+	maxCost := s.MaxCost
+	if maxCost == 0 {
+		maxCost = defaultMaxCost
+	}
+	if len(v.errors) == 0 && cost > maxCost {
+		v.errorf("query is too expensive: estimated cost %d exceeds %d, request fewer items", cost, maxCost)
+	}
+	if len(v.errors) > 0 {
+		return &Response{Errors: v.errors}
+	}
+
+	e := &executor{ctx: ctx, doc: doc, variables: make(map[string]any)}
+	for _, def := range op.variables {
+		if value, ok := req.Variables[def.name]; ok {
+			e.variables[def.name] = value
+		} else if def.hasDefault {
+			e.variables[def.name] = def.defaultValue
+		}
+	}
+
+	data := e.executeObjects(s.Query, []any{nil}, [][]any{{}}, op.selectionSet)
+	return &Response{Data: data[0], Errors: e.errors}
+}
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	var op *operation
+	switch {
+	case name != "":
+		for _, candidate := range doc.operations {
+			if candidate.name == name {
+				op = candidate
+			}
+		}
+		if op == nil {
+			return nil, fmt.Errorf("unknown operation %q", name)
+		}
+	case len(doc.operations) == 1:
+		op = doc.operations[0]
+	default:
+		return nil, fmt.Errorf("operationName is required when the query contains several operations")
+	}
+
+	if op.kind != "query" {
+		return nil, fmt.Errorf("%s operations are not supported", op.kind)
+	}
+	return op, nil
+}
+
+// validator checks a query against the schema before anything is resolved and
+// estimates its cost.
+type validator struct {
+	schema    *Schema
+	doc       *document
+	variables map[string]bool
+	values    map[string]any // Variable values of the request
+	errors    []Error
+}
+
+func (v *validator) errorf(format string, args ...any) {
+	v.errors = append(v.errors, Error{Message: fmt.Sprintf(format, args...)})
+}
+
+// selections validates a selection set and returns its cost for one object.
+func (v *validator) selections(typ *Object, set []selection, depth int, fragments []string) int {
+	maxDepth := v.schema.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = defaultMaxDepth
+	}
+	if depth > maxDepth {
+		v.errorf("query is nested more than %d levels deep", maxDepth)
+		return 0
+	}
+
+	cost := 0
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *field:
+			v.directives(sel.directives)
+			cost = addCost(cost, v.field(typ, sel, depth, fragments))
+
+		case *fragmentSpread:
+			v.directives(sel.directives)
+			frag, ok := v.doc.fragments[sel.name]
+			if !ok {
+				v.errorf("unknown fragment %q", sel.name)
+				continue
+			}
+			cycle := false
+			for _, name := range fragments {
+				cycle = cycle || name == sel.name
+			}
+			if cycle {
+				v.errorf("fragment %q spreads itself", sel.name)
+				continue
+			}
+			if frag.typeCondition != typ.Name {
+				v.errorf("fragment %q on %q cannot be spread on %q", sel.name, frag.typeCondition, typ.Name)
+				continue
+			}
+			cost = addCost(cost, v.selections(typ, frag.selectionSet, depth, append(fragments, sel.name)))
+
+		case *inlineFragment:
+			v.directives(sel.directives)
+			if sel.typeCondition != "" && sel.typeCondition != typ.Name {
+				v.errorf("inline fragment on %q cannot be used on %q", sel.typeCondition, typ.Name)
+				continue
+			}
+			cost = addCost(cost, v.selections(typ, sel.selectionSet, depth, fragments))
+		}
+	}
+	return cost
+}
+
+// field validates a field and returns its cost for one parent object: one for
+// the field plus the cost of its selections for each object it resolves to.
+func (v *validator) field(typ *Object, f *field, depth int, fragments []string) int {
+	if f.name == "__typename" {
+		if f.selectionSet != nil {
+			v.errorf("field \"__typename\" must not have a selection")
+		}
+		return 1
+	}
+
+	def, ok := typ.Fields[f.name]
+	if !ok {
+		v.errorf("cannot query field %q on type %q", f.name, typ.Name)
+		return 0
+	}
+
+	for _, arg := range f.arguments {
+		argType, ok := def.Args[arg.name]
+		if !ok {
+			v.errorf("unknown argument %q on field \"%s.%s\"", arg.name, typ.Name, f.name)
+			continue
+		}
+		v.value(arg.value)
+		if !containsVariable(arg.value) {
+			if _, err := coerceValue(argType, arg.value); err != nil {
+				v.errorf("argument %q on field \"%s.%s\": %v", arg.name, typ.Name, f.name, err)
+			}
+		}
+	}
+
+	object, isObject := namedType(def.Type).(*Object)
+	switch {
+	case isObject && f.selectionSet == nil:
+		v.errorf("field %q of type %q must have a selection of subfields", f.name, def.Type)
+	case !isObject && f.selectionSet != nil:
+		v.errorf("field %q of type %q must not have a selection", f.name, def.Type)
+	case isObject:
+		items := 1
+		if _, isList := def.Type.(*List); isList {
+			items = v.listSize(def, f)
+		}
+		return addCost(1, mulCost(items, v.selections(object, f.selectionSet, depth+1, fragments)))
+	}
+	return 1
+}
+
+// listSize is the number of items a list field is assumed to return: its
+// limit argument when given, otherwise its ListSize.
+func (v *validator) listSize(def *Field, f *field) int {
+	for _, arg := range f.arguments {
+		if arg.name != "limit" {
+			continue
+		}
+		value := arg.value
+		if name, ok := value.(variable); ok {
+			value = v.values[string(name)]
+		}
+		if limit, err := coerceValue(Int, value); err == nil && limit != nil {
+			return max(limit.(int), 0)
+		}
+	}
+	if def.ListSize > 0 {
+		return def.ListSize
+	}
+	return defaultListSize
+}
+
+// addCost and mulCost saturate instead of overflowing on absurd limits.
+func addCost(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+func mulCost(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
+
+func (v *validator) directives(directives []directive) {
+	for _, d := range directives {
+		if d.name != "include" && d.name != "skip" {
+			v.errorf("unknown directive @%s", d.name)
+			continue
+		}
+		if len(d.arguments) != 1 || d.arguments[0].name != "if" {
+			v.errorf("directive @%s requires a single \"if\" argument", d.name)
+			continue
+		}
+		v.value(d.arguments[0].value)
+	}
+}
+
+// value reports variables that the operation does not define.
+func (v *validator) value(value any) {
+	switch value := value.(type) {
+	case variable:
+		if !v.variables[string(value)] {
+			v.errorf("variable $%s is not defined", value)
+		}
+	case []any:
+		for _, item := range value {
+			v.value(item)
+		}
+	case map[string]any:
+		for _, item := range value {
+			v.value(item)
+		}
+	}
+}
+
+func containsVariable(value any) bool {
+	switch value := value.(type) {
+	case variable:
+		return true
+	case []any:
+		for _, item := range value {
+			if containsVariable(item) {
+				return true
+			}
+		}
+	case map[string]any:
+		for _, item := range value {
+			if containsVariable(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// namedType unwraps list types.
+func namedType(t Type) Type {
+	for {
+		list, ok := t.(*List)
+		if !ok {
+			return t
+		}
+		t = list.Of
+	}
+}
+
+// coerceValue converts an argument value to the Go value resolvers receive.
+// A single value is accepted where a list is expected.
+func coerceValue(t Type, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]any)
+		if !ok {
+			items = []any{value}
+		}
+		list := make([]any, len(items))
+		for i, item := range items {
+			coerced, err := coerceValue(t.Of, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = coerced
+		}
+		return list, nil
+	case *Scalar:
+		return t.Coerce(value)
+	}
+	return nil, fmt.Errorf("%s cannot be used as an argument type", t)
+}
+
+type executor struct {
+	ctx       context.Context
+	doc       *document
+	variables map[string]any
+	errors    []Error
+}
+
+func (e *executor) fieldError(path []any, key string, err error) {
+	e.errors = append(e.errors, Error{Message: err.Error(), Path: appendPath(path, key)})
+}
+
+// collectedField is a response key and the query fields merged into it.
+type collectedField struct {
+	key    string
+	fields []*field
+}
+
+// collectFields flattens fragments and applies directives, merging fields with
+// the same response key in the order they first appear.
+func (e *executor) collectFields(set []selection, collected []*collectedField, visited map[string]bool) []*collectedField {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *field:
+			if !e.included(sel.directives) {
+				continue
+			}
+			key := sel.responseKey()
+			merged := false
+			for _, c := range collected {
+				if c.key == key {
+					c.fields = append(c.fields, sel)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				collected = append(collected, &collectedField{key: key, fields: []*field{sel}})
+			}
+		case *fragmentSpread:
+			if visited[sel.name] || !e.included(sel.directives) {
+				continue
+			}
+			visited[sel.name] = true
+			collected = e.collectFields(e.doc.fragments[sel.name].selectionSet, collected, visited)
+		case *inlineFragment:
+			if e.included(sel.directives) {
+				collected = e.collectFields(sel.selectionSet, collected, visited)
+			}
+		}
+	}
+	return collected
+}
+
+func (e *executor) included(directives []directive) bool {
+	for _, d := range directives {
+		condition, _ := e.resolveVariables(d.arguments[0].value).(bool)
+		if d.name == "skip" && condition || d.name == "include" && !condition {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveVariables replaces variables in an argument value. Variables that were
+// not provided and have no default become nil.
+func (e *executor) resolveVariables(value any) any {
+	switch value := value.(type) {
+	case variable:
+		return e.variables[string(value)]
+	case []any:
+		list := make([]any, len(value))
+		for i, item := range value {
+			list[i] = e.resolveVariables(item)
+		}
+		return list
+	case map[string]any:
+		object := make(map[string]any, len(value))
+		for name, item := range value {
+			object[name] = e.resolveVariables(item)
+		}
+		return object
+	}
+	return value
+}
+
+func (e *executor) arguments(def *Field, f *field) (Args, error) {
+	args := make(Args, len(f.arguments))
+	for _, arg := range f.arguments {
+		if name, ok := arg.value.(variable); ok {
+			if _, provided := e.variables[string(name)]; !provided {
+				continue
+			}
+		}
+		value, err := coerceValue(def.Args[arg.name], e.resolveVariables(arg.value))
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", arg.name, err)
+		}
+		args[arg.name] = value
+	}
+	return args, nil
+}
+
+// executeObjects resolves the selection set for every source object of the same
+// type at once. paths holds the response path of each source.
+func (e *executor) executeObjects(typ *Object, sources []any, paths [][]any, set []selection) []*orderedMap {
+	results := make([]*orderedMap, len(sources))
+	for i := range results {
+		results[i] = &orderedMap{}
+	}
+
+	for _, collected := range e.collectFields(set, nil, make(map[string]bool)) {
+		f := collected.fields[0]
+		if f.name == "__typename" {
+			for _, result := range results {
+				result.set(collected.key, typ.Name)
+			}
+			continue
+		}
+
+		def := typ.Fields[f.name]
+		values, err := e.resolve(def, f, sources, paths, collected.key)
+		if err != nil {
+			// One error for the whole batch rather than one per source
+			e.fieldError(paths[0], collected.key, err)
+			for _, result := range results {
+				result.set(collected.key, nil)
+			}
+			continue
+		}
+
+		var subselection []selection
+		for _, merged := range collected.fields {
+			subselection = append(subselection, merged.selectionSet...)
+		}
+		fieldPaths := make([][]any, len(paths))
+		for i, path := range paths {
+			fieldPaths[i] = appendPath(path, collected.key)
+		}
+		completed := e.complete(def.Type, values, fieldPaths, subselection)
+		for i, result := range results {
+			result.set(collected.key, completed[i])
+		}
+	}
+	return results
+}
+
+func (e *executor) resolve(def *Field, f *field, sources []any, paths [][]any, key string) ([]any, error) {
+	args, err := e.arguments(def, f)
+	if err != nil {
+		return nil, err
+	}
+
+	if def.Batch != nil {
+		values, err := def.Batch(e.ctx, sources, args)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != len(sources) {
+			return nil, fmt.Errorf("batch resolver returned %d values for %d objects", len(values), len(sources))
+		}
+		return values, nil
+	}
+
+	values := make([]any, len(sources))
+	for i, source := range sources {
+		value, err := def.Resolve(e.ctx, source, args)
+		if err != nil {
+			e.fieldError(paths[i], key, err)
+			continue
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// complete turns resolved values into response values of type t.
+func (e *executor) complete(t Type, values []any, paths [][]any, set []selection) []any {
+	completed := make([]any, len(values))
+
+	switch t := t.(type) {
+	case *Scalar:
+		for i, value := range values {
+			if !isNil(value) {
+				completed[i] = t.Serialize(deref(value))
+			}
+		}
+
+	case *Object:
+		var objects []any
+		var objectPaths [][]any
+		var indexes []int
+		for i, value := range values {
+			if !isNil(value) {
+				objects = append(objects, pointerTo(value))
+				objectPaths = append(objectPaths, paths[i])
+				indexes = append(indexes, i)
+			}
+		}
+		if len(objects) > 0 {
+			for j, result := range e.executeObjects(t, objects, objectPaths, set) {
+				completed[indexes[j]] = result
+			}
+		}
+
+	case *List:
+		var items []any
+		var itemPaths [][]any
+		counts := make([]int, len(values))
+		for i, value := range values {
+			if isNil(value) {
+				counts[i] = -1
+				continue
+			}
+			rv := reflect.ValueOf(value)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				e.errors = append(e.errors, Error{Message: fmt.Sprintf("expected a list, got %T", value), Path: paths[i]})
+				counts[i] = -1
+				continue
+			}
+			counts[i] = rv.Len()
+			for j := 0; j < rv.Len(); j++ {
+				item := rv.Index(j)
+				if item.Kind() == reflect.Struct && item.CanAddr() {
+					item = item.Addr()
+				}
+				items = append(items, item.Interface())
+				itemPaths = append(itemPaths, appendPath(paths[i], j))
+			}
+		}
+
+		completedItems := e.complete(t.Of, items, itemPaths, set)
+		next := 0
+		for i, count := range counts {
+			if count < 0 {
+				continue
+			}
+			completed[i] = completedItems[next : next+count : next+count]
+			next += count
+		}
+	}
+	return completed
+}
+
+func appendPath(path []any, element any) []any {
+	next := make([]any, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, element)
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(value); rv.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func deref(value any) any {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Pointer {
+		// Scalars such as DateTime handle pointers to their own types
+		if _, ok := value.(interface{ IsZero() bool }); ok {
+			return value
+		}
+		return rv.Elem().Interface()
+	}
+	return value
+}
+
+// pointerTo returns a pointer to a struct value, so resolvers always receive
+// pointers to objects.
+func pointerTo(value any) any {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Struct {
+		return value
+	}
+	ptr := reflect.New(rv.Type())
+	ptr.Elem().Set(rv)
+	return ptr.Interface()
+}
+
+// orderedMap is a response object that keeps its fields in query order.
+type orderedMap struct {
+	keys   []string
+	values []any
+}
+
+func (m *orderedMap) set(key string, value any) {
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,272 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAuthor struct {
+	ID   uint
+	Name string
+}
+
+type testBook struct {
+	ID       uint
+	Title    string
+	AuthorID uint
+	Added    time.Time
+}
+
+// testSchema returns a schema over a small library and counts the calls made to
+// the author loader.
+func testSchema(batches *int) *Schema {
+	authors := map[uint]testAuthor{1: {ID: 1, Name: "Le Guin"}, 2: {ID: 2, Name: "Herbert"}}
+	books := []testBook{
+		{ID: 1, Title: "The Dispossessed", AuthorID: 1, Added: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Title: "Dune", AuthorID: 2},
+		{ID: 3, Title: "The Lathe of Heaven", AuthorID: 1},
+	}
+
+	author := &Object{Name: "Author", Fields: map[string]*Field{
+		"name": {Type: String, Resolve: func(_ context.Context, source any, _ Args) (any, error) {
+			return source.(*testAuthor).Name, nil
+		}},
+	}}
+	book := &Object{Name: "Book", Fields: map[string]*Field{
+		"id": {Type: ID, Resolve: func(_ context.Context, source any, _ Args) (any, error) {
+			return source.(*testBook).ID, nil
+		}},
+		"title": {Type: String, Resolve: func(_ context.Context, source any, _ Args) (any, error) {
+			return source.(*testBook).Title, nil
+		}},
+		"added": {Type: DateTime, Resolve: func(_ context.Context, source any, _ Args) (any, error) {
+			return source.(*testBook).Added, nil
+		}},
+		"author": {Type: author, Batch: func(_ context.Context, sources []any, _ Args) ([]any, error) {
+			*batches++
+			values := make([]any, len(sources))
+			for i, source := range sources {
+				values[i] = authors[source.(*testBook).AuthorID]
+			}
+			return values, nil
+		}},
+		"broken": {Type: String, Resolve: func(_ context.Context, source any, _ Args) (any, error) {
+			if source.(*testBook).ID == 2 {
+				return nil, errors.New("cannot load")
+			}
+			return "ok", nil
+		}},
+	}}
+
+	return &Schema{Query: &Object{Name: "Query", Fields: map[string]*Field{
+		"books": {
+			Type: ListOf(book),
+			Args: map[string]Type{"limit": Int, "ids": ListOf(ID)},
+			Resolve: func(_ context.Context, _ any, args Args) (any, error) {
+				result := books
+				if args.Has("ids") {
+					result = nil
+					for _, id := range args.IDs("ids") {
+						result = append(result, books[id-1])
+					}
+				}
+				return result[:min(args.Int("limit", len(result)), len(result))], nil
+			},
+		},
+		"book": {
+			Type: book,
+			Args: map[string]Type{"id": ID},
+			Resolve: func(_ context.Context, _ any, args Args) (any, error) {
+				id := args.ID("id")
+				if id == 0 || int(id) > len(books) {
+					return nil, nil
+				}
+				return books[id-1], nil
+			},
+		},
+	}}}
+}
+
+func execute(t *testing.T, schema *Schema, req Request) (string, []Error) {
+	t.Helper()
+	resp := schema.Execute(context.Background(), req)
+	if resp.Data == nil {
+		return "", resp.Errors
+	}
+	data, err := json.Marshal(resp.Data)
+	require.NoError(t, err)
+	return string(data), resp.Errors
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		expected  string
+	}{
+		{
+			name:     "fields keep query order",
+			query:    `{ books(limit: 2) { title id } }`,
+			expected: `{"books":[{"title":"The Dispossessed","id":"1"},{"title":"Dune","id":"2"}]}`,
+		},
+		{
+			name:     "aliases and nested objects",
+			query:    `query { first: book(id: 1) { t: title author { name } } missing: book(id: 9) { title } }`,
+			expected: `{"first":{"t":"The Dispossessed","author":{"name":"Le Guin"}},"missing":null}`,
+		},
+		{
+			name:      "variables and defaults",
+			query:     `query Books($limit: Int = 1, $ids: [ID!]) { books(limit: $limit, ids: $ids) { title } }`,
+			variables: map[string]any{"ids": []any{"3", "2"}},
+			expected:  `{"books":[{"title":"The Lathe of Heaven"}]}`,
+		},
+		{
+			name:     "single values are accepted for lists",
+			query:    `{ books(ids: 2) { title } }`,
+			expected: `{"books":[{"title":"Dune"}]}`,
+		},
+		{
+			name: "fragments merge into one object",
+			query: `
+				query { book(id: 2) { ...Basics ... on Book { author { name } } title } }
+				fragment Basics on Book { id title }`,
+			expected: `{"book":{"id":"2","title":"Dune","author":{"name":"Herbert"}}}`,
+		},
+		{
+			name:      "skip and include",
+			query:     `query ($full: Boolean!) { book(id: 1) { title @skip(if: true) id added @include(if: $full) } }`,
+			variables: map[string]any{"full": true},
+			expected:  `{"book":{"id":"1","added":"2024-05-01T00:00:00Z"}}`,
+		},
+		{
+			name:     "typename and zero times",
+			query:    `{ book(id: 2) { __typename added } }`,
+			expected: `{"book":{"__typename":"Book","added":null}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var batches int
+			data, errs := execute(t, testSchema(&batches), Request{Query: tt.query, Variables: tt.variables})
+			assert.Empty(t, errs)
+			assert.JSONEq(t, tt.expected, data)
+			assert.Equal(t, tt.expected, data, "field order")
+		})
+	}
+}
+
+func TestExecute_BatchesOncePerLevel(t *testing.T) {
+	var batches int
+	data, errs := execute(t, testSchema(&batches), Request{Query: `{ books { author { name } } }`})
+
+	assert.Empty(t, errs)
+	assert.Equal(t, `{"books":[{"author":{"name":"Le Guin"}},{"author":{"name":"Herbert"}},{"author":{"name":"Le Guin"}}]}`, data)
+	assert.Equal(t, 1, batches)
+}
+
+func TestExecute_FieldErrors(t *testing.T) {
+	var batches int
+	data, errs := execute(t, testSchema(&batches), Request{Query: `{ books { broken } }`})
+
+	assert.Equal(t, `{"books":[{"broken":"ok"},{"broken":null},{"broken":"ok"}]}`, data)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "cannot load", errs[0].Message)
+	assert.Equal(t, []any{"books", 1, "broken"}, errs[0].Path)
+}
+
+func TestExecute_RequestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		message string
+	}{
+		{"syntax error", Request{Query: `{ books { title }`}, "syntax error at 1:18"},
+		{"unknown field", Request{Query: `{ books { isbn } }`}, `cannot query field "isbn" on type "Book"`},
+		{"unknown argument", Request{Query: `{ books(first: 1) { title } }`}, `unknown argument "first"`},
+		{"bad literal", Request{Query: `{ books(limit: "ten") { title } }`}, "expected Int"},
+		{"missing subfields", Request{Query: `{ book(id: 1) }`}, "must have a selection of subfields"},
+		{"subfields on scalar", Request{Query: `{ book(id: 1) { title { x } } }`}, "must not have a selection"},
+		{"unknown fragment", Request{Query: `{ book(id: 1) { ...Missing } }`}, `unknown fragment "Missing"`},
+		{"fragment cycle", Request{Query: `{ book(id: 1) { ...A } } fragment A on Book { ...B } fragment B on Book { ...A }`}, "spreads itself"},
+		{"undefined variable", Request{Query: `{ books(limit: $n) { title } }`}, "variable $n is not defined"},
+		{"unknown directive", Request{Query: `{ books @cached { title } }`}, "unknown directive @cached"},
+		{"mutation", Request{Query: `mutation { books { title } }`}, "mutation operations are not supported"},
+		{"ambiguous operation", Request{Query: `query A { books { title } } query B { books { id } }`}, "operationName is required"},
+		{"unknown operation", Request{Query: `query A { books { title } }`, OperationName: "B"}, `unknown operation "B"`},
+		{"too deep", Request{Query: `{ book(id: 1) { author { name } } }`}, "nested more than 2 levels"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var batches int
+			schema := testSchema(&batches)
+			schema.MaxDepth = 2
+
+			data, errs := execute(t, schema, tt.req)
+			assert.Empty(t, data)
+			require.NotEmpty(t, errs)
+			assert.Contains(t, errs[0].Message, tt.message)
+		})
+	}
+}
+
+func TestExecute_MaxCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      Request
+		rejected bool
+	}{
+		{"small list", Request{Query: `{ books(limit: 2) { title } }`}, false},
+		{"list without limit", Request{Query: `{ books { title } }`}, false},
+		{"large list", Request{Query: `{ books(limit: 10) { title author { name } } }`}, true},
+		{"large limit variable", Request{Query: `query ($n: Int) { books(limit: $n) { title } }`, Variables: map[string]any{"n": float64(100)}}, true},
+		{"absurd limit", Request{Query: `{ books(limit: 2147483647) { author { name } } }`}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var batches int
+			schema := testSchema(&batches)
+			schema.MaxCost = 30
+
+			data, errs := execute(t, schema, tt.req)
+			if !tt.rejected {
+				assert.NotEmpty(t, data)
+				assert.Empty(t, errs)
+				return
+			}
+			assert.Empty(t, data)
+			require.NotEmpty(t, errs)
+			assert.Contains(t, errs[0].Message, "query is too expensive")
+		})
+	}
+}
+
+func TestExecute_VariableCoercionError(t *testing.T) {
+	var batches int
+	data, errs := execute(t, testSchema(&batches), Request{
+		Query:     `query ($limit: Int) { books(limit: $limit) { title } }`,
+		Variables: map[string]any{"limit": "ten"},
+	})
+
+	assert.Equal(t, `{"books":null}`, data)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, `argument "limit": expected Int`)
+}
+
+func TestParse_Strings(t *testing.T) {
+	doc, err := parse("{ books(search: \"a\\\"b\\u00e9\", note: \"\"\"\n    first\n      second\n  \"\"\") { title } }")
+	require.NoError(t, err)
+
+	args := doc.operations[0].selectionSet[0].(*field).arguments
+	assert.Equal(t, "a\"bé", args[0].value)
+	assert.Equal(t, "first\n  second", args[1].value)
+}
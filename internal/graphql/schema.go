@@ -0,0 +1,251 @@
+// Package graphql executes GraphQL queries against a schema defined in Go.
+//
+// Only what the application needs is implemented: queries with variables,
+// aliases, fragments and the @include/@skip directives. Mutations,
+// subscriptions, interfaces, unions and introspection are not supported.
+//
+// Fields are resolved breadth-first: each field is resolved once for all the
+// objects at its level of the response, so a field with a Batch resolver loads
+// its data for every parent in a single call, as a dataloader would.
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Type is the type of a field: a *Scalar, *Object or *List.
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type.
+type Scalar struct {
+	Name string
+	// Serialize converts a resolved value to its JSON representation
+	Serialize func(value any) any
+	// Coerce converts an argument or variable value to the Go value resolvers get
+	Coerce func(value any) (any, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// Object is a type with fields, each resolved from the parent object.
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+func (o *Object) String() string { return o.Name }
+
+// List is a list of another type.
+type List struct {
+	Of Type
+}
+
+func (l *List) String() string { return "[" + l.Of.String() + "]" }
+
+// ListOf returns the type of a list of t.
+func ListOf(t Type) *List {
+	return &List{Of: t}
+}
+
+// Field describes an object field. Exactly one of Resolve and Batch is set.
+type Field struct {
+	Type        Type
+	Description string
+	// Args lists the accepted arguments and their types. Arguments are optional;
+	// defaults are applied by the resolvers.
+	Args map[string]Type
+	// Resolve returns the field's value for one parent object.
+	Resolve func(ctx context.Context, source any, args Args) (any, error)
+	// Batch returns the field's values for all parent objects at once, in order.
+	Batch func(ctx context.Context, sources []any, args Args) ([]any, error)
+	// ListSize is the number of items a list field is assumed to return when
+	// the query gives no limit argument, for the query cost (0 means 10).
+	ListSize int
+}
+
+// Schema is the root of a GraphQL schema.
+type Schema struct {
+	Query *Object
+	// MaxDepth limits how deeply selections can nest (0 means 10).
+	MaxDepth int
+	// MaxCost limits the estimated number of values a query resolves (0 means
+	// 100000). Each field counts once per parent object; the selections of a
+	// list field count once per item, its limit argument or ListSize.
+	MaxCost int
+}
+
+// Args holds a field's coerced argument values.
+type Args map[string]any
+
+// Has reports whether the argument was given (possibly as null).
+func (a Args) Has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+// Int returns an Int argument, or def if it was not given or is null.
+func (a Args) Int(name string, def int) int {
+	if v, ok := a[name].(int); ok {
+		return v
+	}
+	return def
+}
+
+// String returns a String or ID argument, or "" if it was not given or is null.
+func (a Args) String(name string) string {
+	v, _ := a[name].(string)
+	return v
+}
+
+// Bool returns a Boolean argument, or nil if it was not given or is null.
+func (a Args) Bool(name string) *bool {
+	if v, ok := a[name].(bool); ok {
+		return &v
+	}
+	return nil
+}
+
+// ID returns an ID argument as a number, or 0 if it was not given, is null or is
+// not numeric.
+func (a Args) ID(name string) uint {
+	id, err := strconv.ParseUint(a.String(name), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+// IDs returns a [ID] argument as numbers, skipping values that are not numeric.
+func (a Args) IDs(name string) []uint {
+	list, _ := a[name].([]any)
+	ids := make([]uint, 0, len(list))
+	for _, v := range list {
+		s, _ := v.(string)
+		if id, err := strconv.ParseUint(s, 10, 32); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// Time returns a DateTime argument, or nil if it was not given or is null.
+func (a Args) Time(name string) *time.Time {
+	if v, ok := a[name].(time.Time); ok {
+		return &v
+	}
+	return nil
+}
+
+// Built-in scalars. Integers in variables arrive from JSON as float64 and are
+// accepted when they are whole numbers.
+var (
+	Int = &Scalar{
+		Name:      "Int",
+		Serialize: func(v any) any { return v },
+		Coerce: func(v any) (any, error) {
+			switch n := v.(type) {
+			case int64:
+				if n >= math.MinInt32 && n <= math.MaxInt32 {
+					return int(n), nil
+				}
+			case float64:
+				if n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+					return int(n), nil
+				}
+			}
+			return nil, fmt.Errorf("expected Int, found %v", v)
+		},
+	}
+
+	Float = &Scalar{
+		Name:      "Float",
+		Serialize: func(v any) any { return v },
+		Coerce: func(v any) (any, error) {
+			switch n := v.(type) {
+			case int64:
+				return float64(n), nil
+			case float64:
+				return n, nil
+			}
+			return nil, fmt.Errorf("expected Float, found %v", v)
+		},
+	}
+
+	String = &Scalar{
+		Name:      "String",
+		Serialize: func(v any) any { return v },
+		Coerce: func(v any) (any, error) {
+			if s, ok := v.(string); ok {
+				return s, nil
+			}
+			return nil, fmt.Errorf("expected String, found %v", v)
+		},
+	}
+
+	Boolean = &Scalar{
+		Name:      "Boolean",
+		Serialize: func(v any) any { return v },
+		Coerce: func(v any) (any, error) {
+			if b, ok := v.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("expected Boolean, found %v", v)
+		},
+	}
+
+	// ID values are serialized as strings and accept strings or integers.
+	ID = &Scalar{
+		Name:      "ID",
+		Serialize: func(v any) any { return fmt.Sprint(v) },
+		Coerce: func(v any) (any, error) {
+			switch id := v.(type) {
+			case string:
+				return id, nil
+			case int64:
+				return strconv.FormatInt(id, 10), nil
+			case float64:
+				if id == math.Trunc(id) {
+					return strconv.FormatInt(int64(id), 10), nil
+				}
+			}
+			return nil, fmt.Errorf("expected ID, found %v", v)
+		},
+	}
+
+	// DateTime is an RFC 3339 timestamp. Arguments also accept YYYY-MM-DD dates.
+	DateTime = &Scalar{
+		Name: "DateTime",
+		Serialize: func(v any) any {
+			switch t := v.(type) {
+			case time.Time:
+				if t.IsZero() {
+					return nil
+				}
+				return t.Format(time.RFC3339)
+			case *time.Time:
+				if t == nil || t.IsZero() {
+					return nil
+				}
+				return t.Format(time.RFC3339)
+			}
+			return v
+		},
+		Coerce: func(v any) (any, error) {
+			if s, ok := v.(string); ok {
+				if t, err := time.Parse(time.RFC3339, s); err == nil {
+					return t, nil
+				}
+				if t, err := time.Parse("2006-01-02", s); err == nil {
+					return t, nil
+				}
+			}
+			return nil, fmt.Errorf("expected DateTime (RFC 3339 or YYYY-MM-DD), found %v", v)
+		},
+	}
+)
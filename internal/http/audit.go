@@ -63,6 +63,7 @@ func (ac *AuditController) AuditLogPage(c *gin.Context) {
 		"TotalEvents": total,
 		"EventType":   eventType,
 		"EventTypes":  getEventTypes(),
+		"UI":          GetUIPreferences(c),
 	})
 }
 
@@ -120,6 +121,7 @@ func getEventTypes() []EventTypeOption {
 		{Value: string(entities.AuditEventImport), Label: "Import"},
 		{Value: string(entities.AuditEventExport), Label: "Export"},
 		{Value: string(entities.AuditEventDelete), Label: "Delete"},
+		{Value: string(entities.AuditEventRestore), Label: "Restore"},
 		{Value: string(entities.AuditEventMetadataEnrich), Label: "Metadata Enrichment"},
 		{Value: string(entities.AuditEventSync), Label: "Sync"},
 		{Value: string(entities.AuditEventAuth), Label: "Authentication"},
@@ -0,0 +1,285 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+// AuthorStore defines database operations for author pages.
+type AuthorStore interface {
+	GetAuthors(userID uint) ([]entities.AuthorSummary, error)
+	GetAuthorByID(id uint) (*entities.Author, error)
+	GetAuthorBooks(authorID uint) ([]entities.Book, error)
+	MergeAuthors(targetID uint, sourceIDs []uint) (*entities.Author, error)
+	AddAuthorAlias(authorID uint, name string) (*entities.Author, error)
+	RemoveAuthorAlias(authorID, aliasID uint) error
+}
+
+// AuthorsController serves authors with their books and highlights.
+type AuthorsController struct {
+	store    AuthorStore
+	enricher *metadata.AuthorEnricher
+}
+
+func NewAuthorsController(store AuthorStore) *AuthorsController {
+	return &AuthorsController{store: store}
+}
+
+// WithEnricher enables looking authors up on Wikidata on demand.
+func (ac *AuthorsController) WithEnricher(enricher *metadata.AuthorEnricher) *AuthorsController {
+	ac.enricher = enricher
+	return ac
+}
+
+// AuthorDetailsResponse is an author with their books and highlights.
+type AuthorDetailsResponse struct {
+	Author *entities.Author `json:"author"`
+	Books  []entities.Book  `json:"books"`
+}
+
+// ListAuthors returns the user's authors with their book counts.
+// GET /api/authors
+func (ac *AuthorsController) ListAuthors(c *gin.Context) {
+	authors, err := ac.store.GetAuthors(auth.GetUserID(c))
+	if err != nil {
+		respondInternalError(c, err, "list authors")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"authors": authors, "count": len(authors)})
+}
+
+// GetAuthor returns an author with all their books and highlights.
+// GET /api/authors/:id
+func (ac *AuthorsController) GetAuthor(c *gin.Context) {
+	details, ok := ac.loadAuthor(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, details)
+}
+
+// EnrichAuthor looks the author up on Wikidata again.
+// POST /api/authors/:id/enrich
+func (ac *AuthorsController) EnrichAuthor(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	author, err := ac.enricher.EnrichAuthor(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "author")
+		return
+	}
+	if errors.Is(err, metadata.ErrAuthorNotFound) {
+		respondError(c, http.StatusNotFound, "No matching author found on Wikidata")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusBadGateway, "Wikidata lookup failed: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, author)
+}
+
+// AuthorPage renders an author with their books and highlights.
+// GET /ui/authors/:id
+func (ac *AuthorsController) AuthorPage(c *gin.Context) {
+	details, ok := ac.loadAuthor(c)
+	if !ok {
+		return
+	}
+
+	totalHighlights := 0
+	for _, book := range details.Books {
+		totalHighlights += len(book.Highlights)
+	}
+
+	otherAuthors, err := ac.otherAuthors(details.Author)
+	if err != nil {
+		respondInternalError(c, err, "list authors")
+		return
+	}
+
+	c.HTML(http.StatusOK, "author", gin.H{
+		"Author":          details.Author,
+		"Books":           details.Books,
+		"OtherAuthors":    otherAuthors,
+		"TotalHighlights": totalHighlights,
+		"CanEnrich":       ac.enricher != nil,
+		"Auth":            GetAuthTemplateData(c),
+		"UI":              GetUIPreferences(c),
+		"Demo":            GetDemoTemplateData(c),
+		"Analytics":       GetAnalyticsTemplateData(c),
+	})
+}
+
+// MergeAuthorsRequest lists the authors to merge into the author of the URL.
+// Form submissions send a single author_id.
+type MergeAuthorsRequest struct {
+	AuthorIDs []uint `json:"author_ids" form:"author_id"`
+}
+
+// MergeAuthors merges other authors into this one, e.g. the same person
+// imported under a transliterated name. Their books move to this author and
+// their names become its aliases.
+// POST /api/authors/:id/merge
+func (ac *AuthorsController) MergeAuthors(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req MergeAuthorsRequest
+	if err := c.ShouldBind(&req); err != nil || len(req.AuthorIDs) == 0 {
+		respondBadRequest(c, "author_ids is required")
+		return
+	}
+
+	author, err := ac.store.MergeAuthors(id, req.AuthorIDs)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondNotFound(c, "author")
+		return
+	case errors.Is(err, database.ErrAuthorsNotMergeable):
+		respondBadRequest(c, err.Error())
+		return
+	case err != nil:
+		respondInternalError(c, err, "merge authors")
+		return
+	}
+	c.JSON(http.StatusOK, author)
+}
+
+// AddAliasRequest is the request body for adding an author alias.
+type AddAliasRequest struct {
+	Name string `json:"name" form:"name"`
+}
+
+// AddAlias adds a name the author is known by; books imported under it are
+// linked to the author.
+// POST /api/authors/:id/aliases
+func (ac *AuthorsController) AddAlias(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req AddAliasRequest
+	if err := c.ShouldBind(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		respondBadRequest(c, "name is required")
+		return
+	}
+
+	author, err := ac.store.AddAuthorAlias(id, req.Name)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondNotFound(c, "author")
+		return
+	case errors.Is(err, database.ErrAuthorAliasTaken):
+		respondError(c, http.StatusConflict, "This name belongs to another author; merge the authors instead")
+		return
+	case err != nil:
+		respondInternalError(c, err, "add author alias")
+		return
+	}
+	ac.respondAuthorNames(c, author)
+}
+
+// RemoveAlias removes an author alias.
+// DELETE /api/authors/:id/aliases/:aliasId
+func (ac *AuthorsController) RemoveAlias(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	aliasID, ok := parseIDParam(c, "aliasId")
+	if !ok {
+		return
+	}
+
+	err := ac.store.RemoveAuthorAlias(id, aliasID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "alias")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "remove author alias")
+		return
+	}
+
+	author, err := ac.store.GetAuthorByID(id)
+	if err != nil {
+		respondInternalError(c, err, "get author")
+		return
+	}
+	ac.respondAuthorNames(c, author)
+}
+
+// respondAuthorNames renders the aliases and merge form for HTMX requests,
+// or the author as JSON
+func (ac *AuthorsController) respondAuthorNames(c *gin.Context, author *entities.Author) {
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, author)
+		return
+	}
+	otherAuthors, err := ac.otherAuthors(author)
+	if err != nil {
+		respondInternalError(c, err, "list authors")
+		return
+	}
+	c.HTML(http.StatusOK, "author-names", gin.H{
+		"Author":       author,
+		"OtherAuthors": otherAuthors,
+		"Demo":         GetDemoTemplateData(c),
+	})
+}
+
+// otherAuthors lists the user's other authors, which can be merged into this one
+func (ac *AuthorsController) otherAuthors(author *entities.Author) ([]entities.AuthorSummary, error) {
+	authors, err := ac.store.GetAuthors(author.UserID)
+	if err != nil {
+		return nil, err
+	}
+	others := authors[:0]
+	for _, other := range authors {
+		if other.Author.ID != author.ID {
+			others = append(others, other)
+		}
+	}
+	return others, nil
+}
+
+func (ac *AuthorsController) loadAuthor(c *gin.Context) (*AuthorDetailsResponse, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	author, err := ac.store.GetAuthorByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "author")
+		return nil, false
+	}
+	if err != nil {
+		respondInternalError(c, err, "get author")
+		return nil, false
+	}
+
+	books, err := ac.store.GetAuthorBooks(author.ID)
+	if err != nil {
+		respondInternalError(c, err, "get author books")
+		return nil, false
+	}
+	return &AuthorDetailsResponse{Author: author, Books: books}, true
+}
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+type fakeAuthorProvider struct {
+	info *metadata.AuthorMetadata
+	err  error
+}
+
+func (p *fakeAuthorProvider) SearchAuthor(ctx context.Context, name string) (*metadata.AuthorMetadata, error) {
+	return p.info, p.err
+}
+
+func setupAuthorsRouter(t *testing.T, provider metadata.AuthorProvider) (*gin.Engine, *database.Database) {
+	t.Helper()
+	db, _, cleanup := setupBooksTestDB(t)
+	t.Cleanup(cleanup)
+
+	controller := NewAuthorsController(db).WithEnricher(metadata.NewAuthorEnricher(provider, db))
+	router := gin.New()
+	router.GET("/api/authors", controller.ListAuthors)
+	router.GET("/api/authors/:id", controller.GetAuthor)
+	router.POST("/api/authors/:id/enrich", controller.EnrichAuthor)
+	router.POST("/api/authors/:id/merge", controller.MergeAuthors)
+	router.POST("/api/authors/:id/aliases", controller.AddAlias)
+	router.DELETE("/api/authors/:id/aliases/:aliasId", controller.RemoveAlias)
+	return router, db
+}
+
+func TestAuthorsController_GetAuthor(t *testing.T) {
+	router, db := setupAuthorsRouter(t, &fakeAuthorProvider{})
+
+	book := &entities.Book{Title: "Ulysses", Author: "James Joyce", Highlights: []entities.Highlight{{Text: "Stately, plump Buck Mulligan"}}}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Dubliners", Author: "James Joyce"}))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/authors", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Authors []entities.AuthorSummary `json:"authors"`
+		Count   int                      `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, 1, list.Count)
+	assert.Equal(t, int64(2), list.Authors[0].BookCount)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/api/authors/%d", book.AuthorID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var details AuthorDetailsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+	assert.Equal(t, "James Joyce", details.Author.Name)
+	require.Len(t, details.Books, 2)
+	assert.Equal(t, "Dubliners", details.Books[0].Title)
+	require.Len(t, details.Books[1].Highlights, 1)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/authors/9999", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAuthorsController_EnrichAuthor(t *testing.T) {
+	t.Run("saves Wikidata details", func(t *testing.T) {
+		router, db := setupAuthorsRouter(t, &fakeAuthorProvider{info: &metadata.AuthorMetadata{
+			WikidataID: "Q6882",
+			Name:       "James Joyce",
+			Aliases:    []string{"James Augustine Aloysius Joyce"},
+			BirthYear:  1882,
+			DeathYear:  1941,
+		}})
+		book := &entities.Book{Title: "Ulysses", Author: "Joyce, James"}
+		require.NoError(t, db.SaveBook(book))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/authors/%d/enrich", book.AuthorID), nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var author entities.Author
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &author))
+		assert.Equal(t, "James Joyce", author.Name)
+		assert.Equal(t, 1882, author.BirthYear)
+		assert.Len(t, author.Aliases, 2, "the previous name is kept as an alias")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		router, db := setupAuthorsRouter(t, &fakeAuthorProvider{err: metadata.ErrAuthorNotFound})
+		book := &entities.Book{Title: "Notes", Author: "Anonymous Scribe"}
+		require.NoError(t, db.SaveBook(book))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/authors/%d/enrich", book.AuthorID), nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		author, err := db.GetAuthorByID(book.AuthorID)
+		require.NoError(t, err)
+		assert.NotNil(t, author.EnrichedAt, "the lookup is recorded")
+	})
+}
+
+func TestAuthorsController_MergeAuthors(t *testing.T) {
+	router, db := setupAuthorsRouter(t, &fakeAuthorProvider{})
+
+	english := &entities.Book{Title: "War and Peace", Author: "Leo Tolstoy"}
+	russian := &entities.Book{Title: "Война и мир", Author: "Лев Толстой"}
+	require.NoError(t, db.SaveBook(english))
+	require.NoError(t, db.SaveBook(russian))
+
+	form := url.Values{"author_id": {fmt.Sprint(russian.AuthorID)}}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/authors/%d/merge", english.AuthorID), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	books, err := db.GetAuthorBooks(english.AuthorID)
+	require.NoError(t, err)
+	assert.Len(t, books, 2)
+
+	// Merging an author into itself is rejected
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/api/authors/%d/merge", english.AuthorID),
+		strings.NewReader(fmt.Sprintf(`{"author_ids": [%d]}`, english.AuthorID)))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAuthorsController_Aliases(t *testing.T) {
+	router, db := setupAuthorsRouter(t, &fakeAuthorProvider{})
+
+	tolstoy := &entities.Book{Title: "War and Peace", Author: "Leo Tolstoy"}
+	joyce := &entities.Book{Title: "Ulysses", Author: "James Joyce"}
+	require.NoError(t, db.SaveBook(tolstoy))
+	require.NoError(t, db.SaveBook(joyce))
+
+	addAlias := func(name string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/authors/%d/aliases", tolstoy.AuthorID),
+			strings.NewReader(fmt.Sprintf(`{"name": %q}`, name)))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := addAlias("Lev Tolstoy")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var author entities.Author
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &author))
+	require.Len(t, author.Aliases, 1)
+
+	assert.Equal(t, http.StatusConflict, addAlias("James Joyce").Code)
+	assert.Equal(t, http.StatusBadRequest, addAlias(" ").Code)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/api/authors/%d/aliases/%d", tolstoy.AuthorID, author.Aliases[0].ID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var updated entities.Author
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Empty(t, updated.Aliases)
+}
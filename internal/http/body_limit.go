@@ -0,0 +1,108 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is room for the multipart envelope and other form fields
+// on top of the largest file a form route accepts.
+const multipartOverhead = 1 << 20
+
+// BodyLimits caps request body sizes so a huge upload is refused with
+// 413 Request Entity Too Large instead of exhausting memory or disk.
+type BodyLimits struct {
+	Default int64            // Routes without a limit of their own; 0 for no limit
+	Routes  map[string]int64 // By route path, e.g. "/api/v2/highlights"; 0 for no limit
+}
+
+// limitFor returns the body limit of a route path.
+func (l BodyLimits) limitFor(path string) int64 {
+	if limit, ok := l.Routes[path]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+// BodyLimitMiddleware refuses requests declaring a body larger than their
+// route's limit before any of it is read. Bodies sent without a declared
+// length fail to read once they pass the limit, which respondBindError
+// reports as 413.
+func BodyLimitMiddleware(limits BodyLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := limits.limitFor(c.FullPath())
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			respondBodyTooLarge(c, limit)
+			c.Abort()
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// bodyLimits returns the body limit of each route: the configured sizes for
+// JSON imports and backups, each file import's own file limit, and none for
+// upload chunks, which the upload store bounds by the declared upload size.
+func bodyLimits(cfg RouterConfig) BodyLimits {
+	backup := cfg.MaxBackupSize
+	if backup <= 0 {
+		backup = maxMoonReaderBackupSize
+	}
+	fileRoute := func(maxFileSize int64) int64 {
+		return maxFileSize + multipartOverhead
+	}
+
+	routes := map[string]int64{
+		"/import/moonreader":          cfg.MaxJSONImportSize,
+		"/api/v2/highlights":          cfg.MaxJSONImportSize,
+		"/api/settings/profile":       cfg.MaxJSONImportSize,
+		"/api/uploads/:id":            0,
+		"/api/ocr":                    fileRoute(cfg.OCRMaxImageSize),
+		"/settings/moonreader/upload": fileRoute(backup),
+		// Apple Books imports carry both the annotation and the book database
+		"/settings/applebooks/import":       fileRoute(2 * backup),
+		MoonReaderWebDAVPrefix + "/*path":   backup,
+		"/settings/kindle/import":           fileRoute(maxKindleClippingsSize),
+		"/import/kindle":                    fileRoute(maxKindleClippingsSize),
+		"/settings/kindle/import-notebook":  fileRoute(maxKindleFileSize),
+		"/import/kindle/notebook":           fileRoute(maxKindleFileSize),
+		"/settings/readwise/import-csv":     fileRoute(maxReadwiseLibrarySize),
+		"/settings/readwise/import-library": fileRoute(maxReadwiseLibrarySize),
+		"/import/readwise/library":          fileRoute(maxReadwiseLibrarySize),
+		"/settings/library/import":          fileRoute(maxLibraryCSVSize),
+		"/import/library":                   fileRoute(maxLibraryCSVSize),
+		"/settings/tags/import":             fileRoute(maxTagCSVSize),
+		"/api/tags/import":                  fileRoute(maxTagCSVSize),
+	}
+	return BodyLimits{Default: cfg.MaxRequestSize, Routes: routes}
+}
+
+// respondBodyTooLarge sends a 413 Request Entity Too Large response naming the limit.
+func respondBodyTooLarge(c *gin.Context, limit int64) {
+	respondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body too large (max %s)", formatMegabytes(limit)))
+}
+
+// respondBindError responds to a request body that failed to bind: 413 when
+// it is over the route's limit, 400 otherwise.
+func respondBindError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondBodyTooLarge(c, maxBytesErr.Limit)
+		return
+	}
+	respondBadRequest(c, err.Error())
+}
+
+// formatMegabytes formats a size limit in whole megabytes, e.g. "50 MB".
+func formatMegabytes(size int64) string {
+	return fmt.Sprintf("%d MB", size/(1024*1024))
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(BodyLimitMiddleware(BodyLimits{
+		Default: 16,
+		Routes:  map[string]int64{"/import": 64, "/chunks/:id": 0},
+	}))
+	bind := func(c *gin.Context) {
+		var req map[string]any
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+	router.POST("/settings", bind)
+	router.POST("/import", bind)
+	router.PATCH("/chunks/:id", func(c *gin.Context) {
+		data, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%d", len(data))
+	})
+
+	send := func(method, path, body string, declareLength bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if !declareLength {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	large := `{"text":"` + strings.Repeat("a", 100) + `"}`
+
+	assert.Equal(t, http.StatusNoContent, send(http.MethodPost, "/settings", `{"a":1}`, true).Code)
+
+	w := send(http.MethodPost, "/settings", large, true)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "request body too large")
+
+	// Bodies without a declared length are cut off once they pass the limit
+	w = send(http.MethodPost, "/settings", large, false)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Routes have limits of their own
+	assert.Equal(t, http.StatusNoContent, send(http.MethodPost, "/import", `{"text":"`+strings.Repeat("a", 40)+`"}`, true).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send(http.MethodPost, "/import", large, false).Code)
+	w = send(http.MethodPatch, "/chunks/1", strings.Repeat("a", 1000), true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1000", w.Body.String())
+}
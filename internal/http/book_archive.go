@@ -0,0 +1,69 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// BookArchiveStore defines database operations for archiving books.
+type BookArchiveStore interface {
+	SetBookArchived(bookID uint, isArchived bool) error
+	GetBookWithoutHighlights(id uint) (*entities.Book, error)
+}
+
+// BookArchiveController archives books that should be out of the way without
+// being deleted, such as finished reference books.
+type BookArchiveController struct {
+	store BookArchiveStore
+}
+
+func NewBookArchiveController(store BookArchiveStore) *BookArchiveController {
+	return &BookArchiveController{store: store}
+}
+
+// ArchiveBook keeps a book out of the default book and highlight lists,
+// search, exports and stats. Its page and highlights stay available.
+// POST /api/books/:id/archive
+func (ac *BookArchiveController) ArchiveBook(c *gin.Context) {
+	ac.setBookArchived(c, true)
+}
+
+// UnarchiveBook brings an archived book back.
+// DELETE /api/books/:id/archive
+func (ac *BookArchiveController) UnarchiveBook(c *gin.Context) {
+	ac.setBookArchived(c, false)
+}
+
+func (ac *BookArchiveController) setBookArchived(c *gin.Context, isArchived bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	book, err := ac.store.GetBookWithoutHighlights(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ownedByUser(c, book.UserID)) {
+		respondNotFound(c, "book")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "get book")
+		return
+	}
+
+	if err := ac.store.SetBookArchived(id, isArchived); err != nil {
+		respondInternalError(c, err, "set book archived")
+		return
+	}
+	book.IsArchived = isArchived
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "book-archive-button", book)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": book.ID, "is_archived": book.IsArchived})
+}
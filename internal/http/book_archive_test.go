@@ -0,0 +1,70 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestBookArchiveController(t *testing.T) {
+	db, exporter, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	reference := &entities.Book{Title: "SQL Cookbook", Author: "Anthony Molinaro", Highlights: []entities.Highlight{{Text: "Use window functions"}}}
+	novel := &entities.Book{Title: "Dune", Author: "Frank Herbert", Highlights: []entities.Highlight{{Text: "Fear is the mind-killer"}}}
+	require.NoError(t, db.SaveBook(reference))
+	require.NoError(t, db.SaveBook(novel))
+
+	archive := NewBookArchiveController(db)
+	books := NewBooksController(exporter)
+	router := gin.New()
+	router.POST("/api/books/:id/archive", archive.ArchiveBook)
+	router.DELETE("/api/books/:id/archive", archive.UnarchiveBook)
+	router.GET("/api/books", books.GetAllBooks)
+	router.GET("/api/books/stats", books.GetBookStats)
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	titles := func(path string) []string {
+		w := send(http.MethodGet, path)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Books []entities.Book `json:"books"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		var titles []string
+		for _, b := range response.Books {
+			titles = append(titles, b.Title)
+		}
+		return titles
+	}
+
+	w := send(http.MethodPost, fmt.Sprintf("/api/books/%d/archive", reference.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, fmt.Sprintf(`{"id": %d, "is_archived": true}`, reference.ID), w.Body.String())
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/api/books/9999/archive").Code)
+
+	assert.Equal(t, []string{"Dune"}, titles("/api/books"))
+	assert.Equal(t, []string{"SQL Cookbook", "Dune"}, titles("/api/books?include_archived=true"))
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodGet, "/api/books?include_archived=maybe").Code)
+
+	w = send(http.MethodGet, "/api/books/stats")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"total_books": 1, "total_highlights": 1, "archived_books": 1}`, w.Body.String())
+
+	w = send(http.MethodDelete, fmt.Sprintf("/api/books/%d/archive", reference.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"SQL Cookbook", "Dune"}, titles("/api/books"))
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// relatedBooksLimit caps the related books returned with a book's details.
+const relatedBooksLimit = 5
+
+// BookDetailsStore defines database operations for the aggregated book details.
+type BookDetailsStore interface {
+	GetBookByID(id uint) (*entities.Book, error)
+	GetWordsByBook(bookID uint) ([]entities.Word, error)
+	GetRelatedBooks(bookID uint, limit int) ([]entities.Book, error)
+}
+
+type BookDetailsController struct {
+	store        BookDetailsStore
+	cachedCovers bool
+}
+
+func NewBookDetailsController(store BookDetailsStore) *BookDetailsController {
+	return &BookDetailsController{store: store}
+}
+
+// WithCachedCovers points cover URLs at the local cover cache endpoint.
+func (bc *BookDetailsController) WithCachedCovers(enabled bool) *BookDetailsController {
+	bc.cachedCovers = enabled
+	return bc
+}
+
+// BookDetailCounts summarizes a book's highlights and words.
+type BookDetailCounts struct {
+	Highlights int `json:"highlights"`
+	Notes      int `json:"notes"`
+	Favourites int `json:"favourites"`
+	Tags       int `json:"tags"`
+	Vocabulary int `json:"vocabulary"`
+}
+
+// BookDetailsResponse is everything the book detail page shows, in one response.
+type BookDetailsResponse struct {
+	Book         *entities.Book       `json:"book"`
+	Counts       BookDetailCounts     `json:"counts"`
+	Tags         []TagInfo            `json:"tags"`
+	Favourites   []entities.Highlight `json:"favourites"`
+	Vocabulary   []entities.Word      `json:"vocabulary"`
+	RelatedBooks []entities.Book      `json:"related_books"`
+	CoverURL     string               `json:"cover_url,omitempty"`
+}
+
+// GetBookDetails returns a book with its highlights, counts, tags (of the book and
+// its highlights), favourite highlights, vocabulary words, related books and cover URL.
+// GET /api/books/:id/full
+func (bc *BookDetailsController) GetBookDetails(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	book, err := bc.store.GetBookByID(id)
+	if err != nil {
+		respondNotFound(c, "book")
+		return
+	}
+
+	words, err := bc.store.GetWordsByBook(id)
+	if err != nil {
+		respondInternalError(c, err, "get book vocabulary")
+		return
+	}
+	vocabulary := make([]entities.Word, 0, len(words))
+	for _, word := range words {
+		if word.Status != entities.WordStatusCandidate {
+			vocabulary = append(vocabulary, word)
+		}
+	}
+
+	related, err := bc.store.GetRelatedBooks(id, relatedBooksLimit)
+	if err != nil {
+		respondInternalError(c, err, "get related books")
+		return
+	}
+
+	favourites := []entities.Highlight{}
+	notes := 0
+	for _, highlight := range book.Highlights {
+		if highlight.IsFavorite {
+			favourites = append(favourites, highlight)
+		}
+		if highlight.Note != "" {
+			notes++
+		}
+	}
+
+	tags := collectBookTags(*book)
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	coverURL := book.CoverURL
+	if coverURL != "" && bc.cachedCovers {
+		coverURL = fmt.Sprintf("%s/api/books/%d/cover", basePath(c), book.ID)
+	}
+
+	c.JSON(http.StatusOK, BookDetailsResponse{
+		Book: book,
+		Counts: BookDetailCounts{
+			Highlights: len(book.Highlights),
+			Notes:      notes,
+			Favourites: len(favourites),
+			Tags:       len(tags),
+			Vocabulary: len(vocabulary),
+		},
+		Tags:         tags,
+		Favourites:   favourites,
+		Vocabulary:   vocabulary,
+		RelatedBooks: related,
+		CoverURL:     coverURL,
+	})
+}
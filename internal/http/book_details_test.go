@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestBookDetailsController_GetBookDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dbPath := "./test_book_details_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	book := &entities.Book{
+		Title:    "Dune",
+		Author:   "Frank Herbert",
+		CoverURL: "https://covers.example.com/dune.jpg",
+		Highlights: []entities.Highlight{
+			{Text: "Fear is the mind-killer", Note: "Litany", LocationValue: 1},
+			{Text: "The spice must flow", LocationValue: 2},
+		},
+	}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.SetHighlightFavourite(book.Highlights[0].ID, true))
+
+	sameAuthor := &entities.Book{Title: "Dune Messiah", Author: "Frank Herbert"}
+	sharedTags := &entities.Book{Title: "Foundation", Author: "Isaac Asimov"}
+	unrelated := &entities.Book{Title: "Cookbook", Author: "Chef"}
+	for _, b := range []*entities.Book{sameAuthor, sharedTags, unrelated} {
+		require.NoError(t, db.SaveBook(b))
+	}
+
+	scifi, err := db.CreateTag("sci-fi", 0)
+	require.NoError(t, err)
+	classic, err := db.CreateTag("classic", 0)
+	require.NoError(t, err)
+	quote, err := db.CreateTag("quote", 0)
+	require.NoError(t, err)
+	for _, tagID := range []uint{scifi.ID, classic.ID} {
+		require.NoError(t, db.AddTagToBook(book.ID, tagID))
+		require.NoError(t, db.AddTagToBook(sharedTags.ID, tagID))
+	}
+	require.NoError(t, db.AddTagToHighlight(book.Highlights[1].ID, quote.ID))
+
+	highlightID := book.Highlights[0].ID
+	require.NoError(t, db.AddWord(&entities.Word{Word: "litany", BookID: &book.ID, HighlightID: &highlightID}))
+	require.NoError(t, db.AddWord(&entities.Word{Word: "spice", BookID: &book.ID, Status: entities.WordStatusCandidate}))
+
+	router := gin.New()
+	router.GET("/api/books/:id/full", NewBookDetailsController(db).WithCachedCovers(true).GetBookDetails)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/books/%d/full", book.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp BookDetailsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.Equal(t, "Dune", resp.Book.Title)
+	assert.Len(t, resp.Book.Highlights, 2)
+	assert.Equal(t, BookDetailCounts{Highlights: 2, Notes: 1, Favourites: 1, Tags: 3, Vocabulary: 1}, resp.Counts)
+	assert.Equal(t, []TagInfo{{classic.ID, "classic"}, {quote.ID, "quote"}, {scifi.ID, "sci-fi"}}, resp.Tags)
+	require.Len(t, resp.Favourites, 1)
+	assert.Equal(t, "Fear is the mind-killer", resp.Favourites[0].Text)
+	require.Len(t, resp.Vocabulary, 1)
+	assert.Equal(t, "litany", resp.Vocabulary[0].Word)
+	assert.Equal(t, fmt.Sprintf("/api/books/%d/cover", book.ID), resp.CoverURL)
+
+	related := make([]string, len(resp.RelatedBooks))
+	for i, b := range resp.RelatedBooks {
+		related[i] = b.Title
+	}
+	assert.Equal(t, []string{"Foundation", "Dune Messiah"}, related)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/books/9999/full", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,254 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+	"github.com/mrlokans/assistant/internal/utils"
+)
+
+// BookEditStore defines database operations for editing book metadata by hand.
+type BookEditStore interface {
+	GetBookByID(id uint) (*entities.Book, error)
+	GetBookByTitleAndAuthorForUser(title, author string, userID uint) (*entities.Book, error)
+	UpdateBookMetadata(id uint, fields map[string]any) error
+}
+
+// BookCoverInvalidator drops a cached cover after the cover URL changes.
+type BookCoverInvalidator interface {
+	InvalidateCover(bookID uint) error
+}
+
+// BookEditController handles manual book metadata edits and provider suggestions.
+type BookEditController struct {
+	store            BookEditStore
+	enricher         *metadata.Enricher
+	coverInvalidator BookCoverInvalidator
+}
+
+func NewBookEditController(store BookEditStore) *BookEditController {
+	return &BookEditController{store: store}
+}
+
+// WithEnricher enables metadata suggestions from the provider.
+func (bc *BookEditController) WithEnricher(enricher *metadata.Enricher) *BookEditController {
+	bc.enricher = enricher
+	return bc
+}
+
+// WithCoverInvalidator clears cached covers when the cover URL is edited.
+func (bc *BookEditController) WithCoverInvalidator(invalidator BookCoverInvalidator) *BookEditController {
+	bc.coverInvalidator = invalidator
+	return bc
+}
+
+// UpdateBookRequest is the request body for editing a book. Only fields present
+// in the body are changed; an empty string or zero clears a field, except the title.
+type UpdateBookRequest struct {
+	Title           *string  `json:"title"`
+	Author          *string  `json:"author"`
+	ISBN            *string  `json:"isbn"`
+	ASIN            *string  `json:"asin"`
+	CoverURL        *string  `json:"cover_url"`
+	Publisher       *string  `json:"publisher"`
+	PublicationYear *int     `json:"publication_year"`
+	Series          *string  `json:"series"`
+	SeriesIndex     *float64 `json:"series_index"`
+	URL             *string  `json:"url"`
+	PublishedAt     *string  `json:"published_at"` // YYYY-MM-DD
+}
+
+// UpdateBook edits a book's metadata. Fields accepted from GetSuggestions can
+// be sent as they were proposed.
+// PATCH /api/books/:id
+func (bc *BookEditController) UpdateBook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req UpdateBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "invalid request body")
+		return
+	}
+
+	book, err := bc.store.GetBookByID(id)
+	if err != nil {
+		respondNotFound(c, "book")
+		return
+	}
+
+	updates, err := bookUpdates(book, &req)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	// Title and author identify a book on import, so they must stay unique
+	title, titleChanged := updates["title"].(string)
+	author, authorChanged := updates["author"].(string)
+	if titleChanged || authorChanged {
+		if !titleChanged {
+			title = book.Title
+		}
+		if !authorChanged {
+			author = book.Author
+		}
+		if existing, err := bc.store.GetBookByTitleAndAuthorForUser(title, author, book.UserID); err == nil && existing.ID != book.ID {
+			c.JSON(http.StatusConflict, gin.H{"error": "another book has this title and author", "book_id": existing.ID})
+			return
+		}
+	}
+
+	fieldsUpdated := make([]string, 0, len(updates))
+	for _, field := range []string{"title", "author", "isbn", "asin", "cover_url", "publisher", "publication_year", "series", "series_index", "url", "published_at"} {
+		if _, ok := updates[field]; ok {
+			fieldsUpdated = append(fieldsUpdated, field)
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := bc.store.UpdateBookMetadata(id, updates); err != nil {
+			respondInternalError(c, err, "update book")
+			return
+		}
+		if _, ok := updates["cover_url"]; ok && bc.coverInvalidator != nil {
+			_ = bc.coverInvalidator.InvalidateCover(id)
+		}
+		if book, err = bc.store.GetBookByID(id); err != nil {
+			respondInternalError(c, err, "reload book")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"book":           book,
+		"fields_updated": fieldsUpdated,
+	})
+}
+
+// bookUpdates validates an edit and returns the columns that change
+func bookUpdates(book *entities.Book, req *UpdateBookRequest) (map[string]any, error) {
+	updates := make(map[string]any)
+	setText := func(column string, value *string, current string) {
+		if value != nil && strings.TrimSpace(*value) != current {
+			updates[column] = strings.TrimSpace(*value)
+		}
+	}
+
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		return nil, fmt.Errorf("title cannot be empty")
+	}
+	setText("title", req.Title, book.Title)
+	setText("author", req.Author, book.Author)
+	setText("asin", req.ASIN, book.ASIN)
+	setText("publisher", req.Publisher, book.Publisher)
+	setText("series", req.Series, book.Series)
+
+	if req.ISBN != nil {
+		var isbn string
+		if strings.TrimSpace(*req.ISBN) != "" {
+			parsed, err := metadata.ParseISBN(*req.ISBN)
+			if err != nil {
+				return nil, isbnError(err)
+			}
+			isbn, _ = metadata.ISBNForms(parsed)
+		}
+		setText("isbn", &isbn, book.ISBN)
+	}
+
+	if req.CoverURL != nil {
+		if cover := strings.TrimSpace(*req.CoverURL); cover != "" && !utils.IsWebURL(cover) {
+			return nil, fmt.Errorf("cover_url must be an http or https URL")
+		}
+		setText("cover_url", req.CoverURL, book.CoverURL)
+	}
+
+	if req.URL != nil {
+		if address := strings.TrimSpace(*req.URL); address != "" && !utils.IsWebURL(address) {
+			return nil, fmt.Errorf("url must be an http or https URL")
+		}
+		setText("url", req.URL, book.URL)
+	}
+
+	if req.PublishedAt != nil {
+		var publishedAt *time.Time
+		if value := strings.TrimSpace(*req.PublishedAt); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return nil, fmt.Errorf("published_at must be YYYY-MM-DD")
+			}
+			publishedAt = &date
+		}
+		if !sameDate(publishedAt, book.PublishedAt) {
+			updates["published_at"] = publishedAt
+		}
+	}
+
+	if req.PublicationYear != nil {
+		year := *req.PublicationYear
+		if year < 0 || year > time.Now().Year()+1 {
+			return nil, fmt.Errorf("publication_year must be between 0 and %d", time.Now().Year()+1)
+		}
+		if year != book.PublicationYear {
+			updates["publication_year"] = year
+		}
+	}
+
+	if req.SeriesIndex != nil {
+		index := *req.SeriesIndex
+		if index < 0 {
+			return nil, fmt.Errorf("series_index cannot be negative")
+		}
+		if index != book.SeriesIndex {
+			updates["series_index"] = index
+		}
+	}
+
+	return updates, nil
+}
+
+// sameDate reports whether two optional dates are both unset or the same day
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// GetSuggestions looks the book up with the metadata provider and returns the
+// fields it would change, with current and proposed values, without saving
+// anything. Accepted fields are applied with UpdateBook.
+// GET /api/books/:id/suggestions
+func (bc *BookEditController) GetSuggestions(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if bc.enricher == nil {
+		respondError(c, http.StatusServiceUnavailable, "metadata enrichment is not enabled")
+		return
+	}
+	if _, err := bc.store.GetBookByID(id); err != nil {
+		respondNotFound(c, "book")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := bc.enricher.SuggestMetadata(ctx, id)
+	if err != nil {
+		respondError(c, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+type fakeTitleProvider struct {
+	book *metadata.BookMetadata
+}
+
+func (p *fakeTitleProvider) SearchByISBN(ctx context.Context, isbn string) (*metadata.BookMetadata, error) {
+	return p.book, nil
+}
+
+func (p *fakeTitleProvider) SearchByTitle(ctx context.Context, title, author string) (*metadata.BookMetadata, error) {
+	return p.book, nil
+}
+
+func patchBook(router *gin.Engine, id uint, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/books/%d", id), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBookEditController(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Dune", Author: "Frank Herbert", Publisher: "Chilton"}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune Messiah", Author: "Frank Herbert"}))
+
+	provider := &fakeTitleProvider{book: &metadata.BookMetadata{
+		Title:           "Dune",
+		Author:          "Frank Herbert",
+		ISBN:            "9780441172719",
+		Publisher:       "Ace",
+		PublicationYear: 1990,
+	}}
+	controller := NewBookEditController(db).WithEnricher(metadata.NewEnricher(provider, database.NewMetadataUpdater(db)))
+	router := gin.New()
+	router.PATCH("/api/books/:id", controller.UpdateBook)
+	router.GET("/api/books/:id/suggestions", controller.GetSuggestions)
+
+	t.Run("suggests changes without saving them", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/books/%d/suggestions", book.ID), nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var result metadata.SuggestionResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		fields := make(map[string]metadata.FieldSuggestion)
+		for _, s := range result.Suggestions {
+			fields[s.Field] = s
+		}
+		assert.Len(t, fields, 3)
+		assert.Equal(t, "Chilton", fields["publisher"].Current)
+		assert.Equal(t, "Ace", fields["publisher"].Proposed)
+		assert.Contains(t, fields, "isbn")
+		assert.Contains(t, fields, "publication_year")
+
+		stored, err := db.GetBookByID(book.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Chilton", stored.Publisher)
+	})
+
+	t.Run("applies only the accepted fields", func(t *testing.T) {
+		w := patchBook(router, book.ID, `{"isbn": "978-0-441-17271-9", "publication_year": 1990, "publisher": "Chilton"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Book          entities.Book `json:"book"`
+			FieldsUpdated []string      `json:"fields_updated"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"isbn", "publication_year"}, resp.FieldsUpdated)
+		assert.Equal(t, "9780441172719", resp.Book.ISBN)
+		assert.Equal(t, "0441172717", resp.Book.ISBN10)
+		assert.Equal(t, "Chilton", resp.Book.Publisher)
+
+		// The ISBN-10 of the same edition changes nothing
+		w = patchBook(router, book.ID, `{"isbn": "0-441-17271-7"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Empty(t, resp.FieldsUpdated)
+	})
+
+	t.Run("sets the series by hand", func(t *testing.T) {
+		w := patchBook(router, book.ID, `{"series": " Dune Chronicles ", "series_index": 1}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		stored, err := db.GetBookByID(book.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Dune Chronicles", stored.Series)
+		assert.Equal(t, 1.0, stored.SeriesIndex)
+	})
+
+	t.Run("sets the article address and date", func(t *testing.T) {
+		w := patchBook(router, book.ID, `{"url": "https://example.com/dune-review", "published_at": "2021-10-22"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		stored, err := db.GetBookByID(book.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/dune-review", stored.URL)
+		require.NotNil(t, stored.PublishedAt)
+		assert.Equal(t, "2021-10-22", stored.PublishedAt.Format("2006-01-02"))
+
+		require.Equal(t, http.StatusOK, patchBook(router, book.ID, `{"url": "", "published_at": ""}`).Code)
+		stored, err = db.GetBookByID(book.ID)
+		require.NoError(t, err)
+		assert.Empty(t, stored.URL)
+		assert.Nil(t, stored.PublishedAt)
+	})
+
+	t.Run("rejects invalid values", func(t *testing.T) {
+		for _, body := range []string{
+			`{"title": "  "}`,
+			`{"isbn": "12345"}`,
+			`{"isbn": "978-0-441-17271-8"}`,
+			`{"cover_url": "javascript:alert(1)"}`,
+			`{"url": "kindle://book"}`,
+			`{"published_at": "22.10.2021"}`,
+			`{"publication_year": -5}`,
+			`{"series_index": -1}`,
+		} {
+			assert.Equal(t, http.StatusBadRequest, patchBook(router, book.ID, body).Code, body)
+		}
+	})
+
+	t.Run("rejects a title and author used by another book", func(t *testing.T) {
+		w := patchBook(router, book.ID, `{"title": "Dune Messiah"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("unknown book", func(t *testing.T) {
+		w := patchBook(router, 9999, `{"title": "Anything"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,199 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/utils"
+)
+
+const (
+	// bookHighlightsPageSize is how many highlights the book page loads at a time.
+	bookHighlightsPageSize = 50
+	maxBookHighlightsLimit = 200
+)
+
+// BookHighlightStore defines database operations for loading a book's
+// highlights a page at a time.
+type BookHighlightStore interface {
+	GetBookWithoutHighlights(id uint) (*entities.Book, error)
+	GetBookHighlightColors(bookID uint) (map[string]int, error)
+	ListBookHighlights(filter entities.HighlightFilter, afterID uint, limit int) ([]entities.Highlight, bool, error)
+}
+
+// BookHighlightsController pages through the highlights of a book, as JSON
+// and as the HTML the book page appends while scrolling.
+type BookHighlightsController struct {
+	store           BookHighlightStore
+	vocabularyStore VocabularyStore
+}
+
+func NewBookHighlightsController(store BookHighlightStore, vocabularyStore VocabularyStore) *BookHighlightsController {
+	return &BookHighlightsController{store: store, vocabularyStore: vocabularyStore}
+}
+
+// bookHighlightPage is a page of a book's highlights in reading order.
+type bookHighlightPage struct {
+	Highlights []entities.Highlight
+	NextCursor string // Continues after the page, "" on the last page
+}
+
+// ListHighlights returns a page of a book's highlights in reading order. Pass
+// the returned next_cursor as cursor to get the next page.
+// GET /api/books/:id/highlights?cursor=&limit=50&color=yellow
+func (bc *BookHighlightsController) ListHighlights(c *gin.Context) {
+	book, page, ok := bc.loadPage(c)
+	if !ok {
+		return
+	}
+	if page.Highlights == nil {
+		page.Highlights = []entities.Highlight{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"book_id":     book.ID,
+		"data":        page.Highlights,
+		"next_cursor": page.NextCursor,
+		"has_more":    page.NextCursor != "",
+	})
+}
+
+// HighlightsPage renders the next page of a book's highlights, followed by
+// a placeholder that loads the page after it once scrolled into view.
+// GET /ui/books/:id/highlights?cursor=&color=yellow
+func (bc *BookHighlightsController) HighlightsPage(c *gin.Context) {
+	book, page, ok := bc.loadPage(c)
+	if !ok {
+		return
+	}
+	c.HTML(http.StatusOK, "book-highlights-page",
+		bookHighlightsTemplateData(c, book.ID, page, strings.ToLower(c.Query("color")), bookWords(bc.vocabularyStore, book.ID)))
+}
+
+// loadPage responds with an error and returns false for unknown books and
+// malformed parameters.
+func (bc *BookHighlightsController) loadPage(c *gin.Context) (*entities.Book, bookHighlightPage, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, bookHighlightPage{}, false
+	}
+
+	limit := bookHighlightsPageSize
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= maxBookHighlightsLimit {
+			limit = l
+		}
+	}
+	var afterID uint
+	if cursor := c.Query("cursor"); cursor != "" {
+		parsed, err := strconv.ParseUint(cursor, 10, 32)
+		if err != nil {
+			respondBadRequest(c, "invalid cursor")
+			return nil, bookHighlightPage{}, false
+		}
+		afterID = uint(parsed)
+	}
+
+	book, err := bc.store.GetBookWithoutHighlights(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ownedByUser(c, book.UserID)) {
+		respondNotFound(c, "book")
+		return nil, bookHighlightPage{}, false
+	}
+	if err != nil {
+		respondInternalError(c, err, "get book")
+		return nil, bookHighlightPage{}, false
+	}
+
+	page, err := loadBookHighlightPage(bc.store, book.ID, strings.ToLower(c.Query("color")), afterID, limit)
+	if err != nil {
+		respondInternalError(c, err, "list book highlights")
+		return nil, bookHighlightPage{}, false
+	}
+	return book, page, true
+}
+
+// bookWords returns the words saved from a book, which are listed on its
+// vocabulary tab and marked wherever they appear in its highlights. The
+// store may be nil.
+func bookWords(store VocabularyStore, bookID uint) []entities.Word {
+	if store == nil {
+		return nil
+	}
+	words, err := store.GetWordsByBook(bookID)
+	if err != nil {
+		return nil
+	}
+	return confirmedWords(words)
+}
+
+// loadBookHighlightPage returns the page of a book's highlights after the
+// highlight afterID, only those of the named color unless color is "".
+func loadBookHighlightPage(store BookHighlightStore, bookID uint, color string, afterID uint, limit int) (bookHighlightPage, error) {
+	filter := entities.HighlightFilter{BookID: bookID}
+	if color != "" {
+		// Sources store colors differently, so the name is matched against
+		// every stored color of the book
+		colors, err := store.GetBookHighlightColors(bookID)
+		if err != nil {
+			return bookHighlightPage{}, err
+		}
+		for stored := range colors {
+			if utils.ColorName(stored) == color {
+				filter.Colors = append(filter.Colors, stored)
+			}
+		}
+		if len(filter.Colors) == 0 {
+			return bookHighlightPage{}, nil
+		}
+	}
+
+	highlights, hasMore, err := store.ListBookHighlights(filter, afterID, limit)
+	if err != nil {
+		return bookHighlightPage{}, err
+	}
+	page := bookHighlightPage{Highlights: highlights}
+	if hasMore {
+		page.NextCursor = strconv.FormatUint(uint64(highlights[len(highlights)-1].ID), 10)
+	}
+	return page, nil
+}
+
+// bookHighlightColorCounts counts a book's highlights per color name, in
+// utils.HighlightColorNames order, and returns the total count.
+func bookHighlightColorCounts(store BookHighlightStore, bookID uint) ([]HighlightColorCount, int, error) {
+	stored, err := store.GetBookHighlightColors(bookID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := 0
+	for _, count := range stored {
+		total += count
+	}
+	return namedColorCounts(stored), total, nil
+}
+
+// bookHighlightsTemplateData is the data of the book-highlights-page template.
+func bookHighlightsTemplateData(c *gin.Context, bookID uint, page bookHighlightPage, color string, words []entities.Word) gin.H {
+	var nextURL string
+	if page.NextCursor != "" {
+		query := url.Values{"cursor": {page.NextCursor}}
+		if color != "" {
+			query.Set("color", color)
+		}
+		nextURL = fmt.Sprintf("/ui/books/%d/highlights?%s", bookID, query.Encode())
+	}
+	return gin.H{
+		"Highlights":  page.Highlights,
+		"MarkedText":  markVocabulary(page.Highlights, words),
+		"Preferences": GetUserPreferences(c),
+		"NextURL":     nextURL,
+	}
+}
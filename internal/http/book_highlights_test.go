@@ -0,0 +1,100 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestBookHighlightsController(t *testing.T) {
+	db, exporter, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "War and Peace", Author: "Leo Tolstoy"}
+	for i := 0; i < bookHighlightsPageSize+10; i++ {
+		color := "yellow"
+		if i%10 == 0 {
+			color = "#0000FF"
+		}
+		book.Highlights = append(book.Highlights, entities.Highlight{
+			Text:          fmt.Sprintf("Passage %03d", i),
+			LocationValue: i,
+			HighlightedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Color:         color,
+		})
+	}
+	require.NoError(t, db.SaveBook(book))
+
+	renderer, err := newLocalizedHTML("../../templates/*.html", templateFuncs(NewStaticAssets("../../static"), ""))
+	require.NoError(t, err)
+	controller := NewBookHighlightsController(db, nil)
+	ui := NewUIController(exporter, nil, nil).WithBookHighlights(db)
+
+	router := gin.New()
+	router.HTMLRender = renderer
+	router.GET("/api/books/:id/highlights", controller.ListHighlights)
+	router.GET("/ui/books/:id/highlights", controller.HighlightsPage)
+	router.GET("/ui/books/:id", ui.BookPage)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	type page struct {
+		Data       []entities.Highlight `json:"data"`
+		NextCursor string               `json:"next_cursor"`
+		HasMore    bool                 `json:"has_more"`
+	}
+	list := func(path string) page {
+		w := get(path)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var p page
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		return p
+	}
+
+	first := list(fmt.Sprintf("/api/books/%d/highlights?limit=40", book.ID))
+	require.Len(t, first.Data, 40)
+	assert.Equal(t, "Passage 000", first.Data[0].Text)
+	assert.True(t, first.HasMore)
+
+	second := list(fmt.Sprintf("/api/books/%d/highlights?limit=40&cursor=%s", book.ID, first.NextCursor))
+	require.Len(t, second.Data, 20)
+	assert.Equal(t, "Passage 040", second.Data[0].Text)
+	assert.False(t, second.HasMore)
+	assert.Empty(t, second.NextCursor)
+
+	blue := list(fmt.Sprintf("/api/books/%d/highlights?color=blue", book.ID))
+	assert.Len(t, blue.Data, 6)
+	assert.Empty(t, list(fmt.Sprintf("/api/books/%d/highlights?color=green", book.ID)).Data)
+
+	assert.Equal(t, http.StatusBadRequest, get(fmt.Sprintf("/api/books/%d/highlights?cursor=abc", book.ID)).Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/books/9999/highlights").Code)
+
+	// The book page shows the first page and loads the rest when scrolled to
+	w := get(fmt.Sprintf("/ui/books/%d", book.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Passage 049")
+	assert.NotContains(t, w.Body.String(), "Passage 050")
+	assert.Contains(t, w.Body.String(), "60 highlights")
+	next := fmt.Sprintf(`hx-get="/ui/books/%d/highlights?cursor=%d"`, book.ID, book.Highlights[49].ID)
+	assert.Contains(t, w.Body.String(), next)
+
+	w = get(fmt.Sprintf("/ui/books/%d/highlights?cursor=%d", book.ID, book.Highlights[49].ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Passage 050")
+	assert.Contains(t, w.Body.String(), "Passage 059")
+	assert.NotContains(t, w.Body.String(), "highlights-more", "the last page loads nothing more")
+}
@@ -0,0 +1,229 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// bookNoteDateLayout is the format of journal entry dates in requests.
+const bookNoteDateLayout = "2006-01-02"
+
+// BookNoteStore defines database operations for a book's journal.
+type BookNoteStore interface {
+	GetBookWithoutHighlights(id uint) (*entities.Book, error)
+	GetBookNotes(bookID uint) ([]entities.BookNote, error)
+	GetBookNote(id uint) (*entities.BookNote, error)
+	CreateBookNote(note *entities.BookNote) error
+	UpdateBookNote(note *entities.BookNote) error
+	DeleteBookNote(id uint) error
+}
+
+// BookNotesController keeps a reading journal per book: dated notes about
+// the book as a whole rather than about one highlight.
+type BookNotesController struct {
+	store BookNoteStore
+}
+
+func NewBookNotesController(store BookNoteStore) *BookNotesController {
+	return &BookNotesController{store: store}
+}
+
+// BookNoteRequest is the request body for writing a journal entry. Date is
+// YYYY-MM-DD; fields left out are not changed on update.
+type BookNoteRequest struct {
+	Text *string `json:"text" form:"text"`
+	Date *string `json:"date" form:"date"`
+}
+
+// ListNotes returns a book's journal entries, newest first.
+// GET /api/books/:id/notes
+func (nc *BookNotesController) ListNotes(c *gin.Context) {
+	book, ok := nc.loadBook(c)
+	if !ok {
+		return
+	}
+
+	notes, err := nc.store.GetBookNotes(book.ID)
+	if err != nil {
+		respondInternalError(c, err, "list book notes")
+		return
+	}
+	if notes == nil {
+		notes = []entities.BookNote{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"book_id": book.ID,
+		"notes":   notes,
+		"count":   len(notes),
+	})
+}
+
+// CreateNote adds a journal entry to a book, dated today unless a date is
+// given.
+// POST /api/books/:id/notes
+func (nc *BookNotesController) CreateNote(c *gin.Context) {
+	book, ok := nc.loadBook(c)
+	if !ok {
+		return
+	}
+
+	var req BookNoteRequest
+	if err := c.ShouldBind(&req); err != nil || req.Text == nil || strings.TrimSpace(*req.Text) == "" {
+		respondBadRequest(c, "text is required")
+		return
+	}
+
+	now := GetUserPreferences(c).Now()
+	note := &entities.BookNote{
+		BookID: book.ID,
+		UserID: book.UserID,
+		Date:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		Text:   strings.TrimSpace(*req.Text),
+	}
+	if !applyBookNoteDate(c, note, req.Date) {
+		return
+	}
+
+	if err := nc.store.CreateBookNote(note); err != nil {
+		respondInternalError(c, err, "create book note")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		nc.renderJournal(c, book.ID)
+		return
+	}
+	respondCreated(c, note)
+}
+
+// UpdateNote changes the text or date of a journal entry.
+// PATCH /api/books/:id/notes/:noteId
+func (nc *BookNotesController) UpdateNote(c *gin.Context) {
+	book, note, ok := nc.loadNote(c)
+	if !ok {
+		return
+	}
+
+	var req BookNoteRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondBadRequest(c, "invalid request body")
+		return
+	}
+	if req.Text != nil {
+		if strings.TrimSpace(*req.Text) == "" {
+			respondBadRequest(c, "text must not be empty")
+			return
+		}
+		note.Text = strings.TrimSpace(*req.Text)
+	}
+	if !applyBookNoteDate(c, note, req.Date) {
+		return
+	}
+
+	if err := nc.store.UpdateBookNote(note); err != nil {
+		respondInternalError(c, err, "update book note")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		nc.renderJournal(c, book.ID)
+		return
+	}
+	c.JSON(http.StatusOK, note)
+}
+
+// DeleteNote removes a journal entry.
+// DELETE /api/books/:id/notes/:noteId
+func (nc *BookNotesController) DeleteNote(c *gin.Context) {
+	book, note, ok := nc.loadNote(c)
+	if !ok {
+		return
+	}
+
+	if err := nc.store.DeleteBookNote(note.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		respondInternalError(c, err, "delete book note")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		nc.renderJournal(c, book.ID)
+		return
+	}
+	respondSuccess(c, "Note deleted")
+}
+
+// loadBook responds with an error and returns false for unknown books and
+// books of other users.
+func (nc *BookNotesController) loadBook(c *gin.Context) (*entities.Book, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	book, err := nc.store.GetBookWithoutHighlights(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ownedByUser(c, book.UserID)) {
+		respondNotFound(c, "book")
+		return nil, false
+	}
+	if err != nil {
+		respondInternalError(c, err, "get book")
+		return nil, false
+	}
+	return book, true
+}
+
+// loadNote responds with an error and returns false unless the note belongs
+// to the book in the path.
+func (nc *BookNotesController) loadNote(c *gin.Context) (*entities.Book, *entities.BookNote, bool) {
+	book, ok := nc.loadBook(c)
+	if !ok {
+		return nil, nil, false
+	}
+	noteID, ok := parseIDParam(c, "noteId")
+	if !ok {
+		return nil, nil, false
+	}
+
+	note, err := nc.store.GetBookNote(noteID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && note.BookID != book.ID) {
+		respondNotFound(c, "note")
+		return nil, nil, false
+	}
+	if err != nil {
+		respondInternalError(c, err, "get book note")
+		return nil, nil, false
+	}
+	return book, note, true
+}
+
+// renderJournal renders the journal tab of the book page after a change.
+func (nc *BookNotesController) renderJournal(c *gin.Context, bookID uint) {
+	book, err := nc.store.GetBookWithoutHighlights(bookID)
+	if err != nil {
+		respondInternalError(c, err, "get book")
+		return
+	}
+	c.HTML(http.StatusOK, "book-journal", gin.H{"Book": book})
+}
+
+// applyBookNoteDate sets the note's date from a YYYY-MM-DD value, if given,
+// and responds with an error and returns false when it is malformed.
+func applyBookNoteDate(c *gin.Context, note *entities.BookNote, value *string) bool {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return true
+	}
+	date, err := time.Parse(bookNoteDateLayout, strings.TrimSpace(*value))
+	if err != nil {
+		respondBadRequest(c, "date must be YYYY-MM-DD")
+		return false
+	}
+	note.Date = date
+	return true
+}
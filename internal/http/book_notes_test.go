@@ -0,0 +1,106 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestBookNotesController(t *testing.T) {
+	db, exporter, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Moby-Dick", Author: "Herman Melville", Highlights: []entities.Highlight{{Text: "Call me Ishmael."}}}
+	other := &entities.Book{Title: "Typee", Author: "Herman Melville"}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.SaveBook(other))
+
+	renderer, err := newLocalizedHTML("../../templates/*.html", templateFuncs(NewStaticAssets("../../static"), ""))
+	require.NoError(t, err)
+	controller := NewBookNotesController(db)
+	ui := NewUIController(exporter, nil, nil).WithBookHighlights(db).WithJournal(true)
+
+	router := gin.New()
+	router.HTMLRender = renderer
+	router.GET("/api/books/:id/notes", controller.ListNotes)
+	router.POST("/api/books/:id/notes", controller.CreateNote)
+	router.PATCH("/api/books/:id/notes/:noteId", controller.UpdateNote)
+	router.DELETE("/api/books/:id/notes/:noteId", controller.DeleteNote)
+	router.GET("/ui/books/:id", ui.BookPage)
+
+	send := func(method, path, body string, htmx bool) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	notesURL := fmt.Sprintf("/api/books/%d/notes", book.ID)
+
+	w := send(http.MethodPost, notesURL, `{"text": "The whiteness of the whale chapter.", "date": "2024-02-10"}`, false)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created entities.BookNote
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, book.ID, created.BookID)
+	assert.True(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC).Equal(created.Date))
+
+	w = send(http.MethodPost, notesURL, `{"text": "Finished."}`, false)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var today entities.BookNote
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &today))
+	assert.Equal(t, time.Now().UTC().Format(bookNoteDateLayout), today.Date.Format(bookNoteDateLayout), "dated today by default")
+
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, notesURL, `{"text": "  "}`, false).Code)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, notesURL, `{"text": "x", "date": "10/02/2024"}`, false).Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/api/books/9999/notes", `{"text": "x"}`, false).Code)
+
+	w = send(http.MethodPatch, fmt.Sprintf("%s/%d", notesURL, created.ID), `{"text": "The *whiteness* of the whale."}`, false)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "2024-02-10", "the date is kept when left out")
+	assert.Equal(t, http.StatusNotFound,
+		send(http.MethodPatch, fmt.Sprintf("/api/books/%d/notes/%d", other.ID, created.ID), `{"text": "x"}`, false).Code,
+		"notes are only reachable through their own book")
+
+	w = send(http.MethodGet, notesURL, "", false)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Notes []entities.BookNote `json:"notes"`
+		Count int                 `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 2, list.Count)
+	assert.Equal(t, today.ID, list.Notes[0].ID)
+
+	// The book page has a journal tab that HTMX forms re-render
+	w = send(http.MethodGet, fmt.Sprintf("/ui/books/%d", book.ID), "", false)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `data-tab="journal"`)
+	assert.Contains(t, w.Body.String(), "<em>whiteness</em>")
+
+	form := url.Values{"text": {"Reread the sermon."}, "date": {"2024-03-01"}}
+	w = send(http.MethodPost, notesURL, form.Encode(), true)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Reread the sermon.")
+	assert.Contains(t, w.Body.String(), `class="journal-form"`)
+
+	w = send(http.MethodDelete, fmt.Sprintf("%s/%d", notesURL, created.ID), "", true)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), fmt.Sprintf(`id="book-note-%d"`, created.ID))
+	assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, fmt.Sprintf("%s/%d", notesURL, created.ID), "", false).Code)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/database"
+)
+
+// BookSourceStore defines database operations for the sources of merged books.
+type BookSourceStore interface {
+	GetBookSources(bookID uint) ([]database.BookSource, error)
+}
+
+// BookSourcesController shows which sources contributed the highlights of a
+// book imported from several of them.
+type BookSourcesController struct {
+	store BookSourceStore
+}
+
+func NewBookSourcesController(store BookSourceStore) *BookSourcesController {
+	return &BookSourcesController{store: store}
+}
+
+// GetBookSources returns the highlight count and last import of every source
+// of the book.
+// GET /api/books/:id/sources
+func (bc *BookSourcesController) GetBookSources(c *gin.Context) {
+	sources, ok := bc.loadSources(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sources": sources, "count": len(sources)})
+}
+
+// BookSources renders the sources section of a book page.
+// GET /ui/books/:id/sources
+func (bc *BookSourcesController) BookSources(c *gin.Context) {
+	sources, ok := bc.loadSources(c)
+	if !ok {
+		return
+	}
+	c.HTML(http.StatusOK, "book-sources", gin.H{"Sources": sources})
+}
+
+func (bc *BookSourcesController) loadSources(c *gin.Context) ([]database.BookSource, bool) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+	sources, err := bc.store.GetBookSources(bookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "book")
+		return nil, false
+	}
+	if err != nil {
+		respondInternalError(c, err, "get book sources")
+		return nil, false
+	}
+	return sources, true
+}
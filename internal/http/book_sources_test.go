@@ -0,0 +1,51 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestBookSourcesController_GetBookSources(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Dune", Author: "Frank Herbert", Source: entities.Source{Name: "kindle"},
+		Highlights: []entities.Highlight{{Text: "Fear is the mind-killer."}}}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune", Author: "Frank Herbert", Source: entities.Source{Name: "readwise"},
+		Highlights: []entities.Highlight{{Text: "The spice must flow."}, {Text: "Walk without rhythm."}}}))
+
+	router := gin.New()
+	router.GET("/api/books/:id/sources", NewBookSourcesController(db).GetBookSources)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/books/%d/sources", book.ID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Sources []database.BookSource `json:"sources"`
+		Count   int                   `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 2, resp.Count)
+	assert.Equal(t, "readwise", resp.Sources[0].Name)
+	assert.Equal(t, 2, resp.Sources[0].Highlights)
+	assert.Equal(t, "kindle", resp.Sources[1].Name)
+	assert.Equal(t, 1, resp.Sources[1].Highlights)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/books/9999/sources", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -2,13 +2,18 @@ package http
 
 import (
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
 	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/utils"
 )
 
 type BooksController struct {
-	reader exporters.BookReader
+	reader   exporters.BookReader
+	bookList BookListStore
 }
 
 func NewBooksController(reader exporters.BookReader) *BooksController {
@@ -17,12 +22,77 @@ func NewBooksController(reader exporters.BookReader) *BooksController {
 	}
 }
 
+// WithBookList makes book stats count highlights without loading them.
+func (controller *BooksController) WithBookList(store BookListStore) *BooksController {
+	controller.bookList = store
+	return controller
+}
+
+// GetAllBooks returns all books with their highlights.
+// The optional color query parameter (e.g. ?color=yellow) keeps only highlights
+// of that color and omits books without any. The optional favourite query
+// parameter (?favourite=true) keeps only favourite books, or only the others.
+// The optional sort query parameter (one of entities.BookSorts) orders the
+// books, which otherwise follow the user's saved library order. The optional
+// language query parameter (an ISO 639-1 code such as ?language=de) keeps only
+// books detected to be in that language. Archived books are left out unless
+// include_archived=true.
 func (controller *BooksController) GetAllBooks(c *gin.Context) {
+	color := strings.ToLower(c.Query("color"))
+	if color != "" && !slices.Contains(utils.HighlightColorNames, color) {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "color must be one of: " + strings.Join(utils.HighlightColorNames, ", ")})
+		return
+	}
+	favourite, err := parseOptionalBool(c, "favourite")
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	order, err := parseSort(c, GetUIPreferences(c).Sort, entities.BookSorts)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	includeArchived, err := parseIncludeArchived(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	books, err := controller.reader.GetAllBooks()
 	if err != nil {
 		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if !includeArchived {
+		books, _ = withoutArchived(books)
+	}
+	if language := strings.ToLower(c.Query("language")); language != "" {
+		books = withLanguage(books, language)
+	}
+
+	if color != "" {
+		filtered := make([]entities.Book, 0, len(books))
+		for _, book := range books {
+			book.Highlights = filterHighlightsByColor(book.Highlights, color)
+			if len(book.Highlights) > 0 {
+				filtered = append(filtered, book)
+			}
+		}
+		books = filtered
+	}
+
+	if favourite != nil {
+		filtered := make([]entities.Book, 0, len(books))
+		for _, book := range books {
+			if book.IsFavorite == *favourite {
+				filtered = append(filtered, book)
+			}
+		}
+		books = filtered
+	}
+
+	sortBooks(books, order)
 	c.IndentedJSON(http.StatusOK, gin.H{"books": books, "count": len(books)})
 }
 
@@ -44,22 +114,109 @@ func (controller *BooksController) GetBookByTitleAndAuthor(c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, book)
 }
 
+// GetBookStats counts the books and their highlights, leaving out archived
+// books unless include_archived=true.
 func (controller *BooksController) GetBookStats(c *gin.Context) {
-	books, err := controller.reader.GetAllBooks()
+	includeArchived, err := parseIncludeArchived(c)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var books []entities.Book
+	if controller.bookList != nil {
+		books, err = controller.bookList.GetBooksWithoutHighlights()
+	} else {
+		books, err = controller.reader.GetAllBooks()
+	}
 	if err != nil {
 		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	unarchived, archived := withoutArchived(books)
+	if !includeArchived {
+		books = unarchived
+	}
 
 	totalHighlights := 0
 	for _, book := range books {
-		totalHighlights += len(book.Highlights)
+		totalHighlights += book.HighlightCount
 	}
 
 	stats := gin.H{
 		"total_books":      len(books),
 		"total_highlights": totalHighlights,
+		"archived_books":   archived,
 	}
 
 	c.IndentedJSON(http.StatusOK, stats)
 }
+
+// withoutArchived returns the books that are not archived, and how many
+// were left out
+func withoutArchived(books []entities.Book) ([]entities.Book, int) {
+	kept := make([]entities.Book, 0, len(books))
+	for _, book := range books {
+		if !book.IsArchived {
+			kept = append(kept, book)
+		}
+	}
+	return kept, len(books) - len(kept)
+}
+
+// withLanguage returns the books detected to be in the given language.
+func withLanguage(books []entities.Book, language string) []entities.Book {
+	kept := make([]entities.Book, 0, len(books))
+	for _, book := range books {
+		if book.Language == language {
+			kept = append(kept, book)
+		}
+	}
+	return kept
+}
+
+// filterHighlightsByColor returns the highlights whose color maps to the given color name.
+func filterHighlightsByColor(highlights []entities.Highlight, color string) []entities.Highlight {
+	var filtered []entities.Highlight
+	for _, h := range highlights {
+		if utils.ColorName(h.Color) == color {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered
+}
+
+// highlightColorCounts counts highlights per color name, in utils.HighlightColorNames order.
+// Colors without highlights are omitted.
+func highlightColorCounts(highlights []entities.Highlight) []HighlightColorCount {
+	stored := make(map[string]int)
+	for _, h := range highlights {
+		stored[h.Color]++
+	}
+	return namedColorCounts(stored)
+}
+
+// namedColorCounts sums counts per stored color into counts per color name,
+// in utils.HighlightColorNames order. Colors without highlights are omitted.
+func namedColorCounts(stored map[string]int) []HighlightColorCount {
+	counts := make(map[string]int)
+	for color, count := range stored {
+		if name := utils.ColorName(color); name != "" {
+			counts[name] += count
+		}
+	}
+
+	var result []HighlightColorCount
+	for _, name := range utils.HighlightColorNames {
+		if counts[name] > 0 {
+			result = append(result, HighlightColorCount{Name: name, Count: counts[name]})
+		}
+	}
+	return result
+}
+
+// HighlightColorCount is the number of highlights of one color in a book.
+type HighlightColorCount struct {
+	Name  string
+	Count int
+}
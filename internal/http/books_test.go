@@ -85,6 +85,127 @@ func TestBooksController_GetAllBooks(t *testing.T) {
 		books := response["books"].([]interface{})
 		assert.Len(t, books, 2)
 	})
+
+	t.Run("filters highlights by color", func(t *testing.T) {
+		db, exporter, cleanup := setupBooksTestDB(t)
+		defer cleanup()
+
+		require.NoError(t, db.SaveBook(&entities.Book{Title: "Book 1", Author: "Author 1", Highlights: []entities.Highlight{
+			{Text: "Quote", Color: "#FFFF00"},
+			{Text: "Action item", Color: "#FF0000FF"},
+		}}))
+		require.NoError(t, db.SaveBook(&entities.Book{Title: "Book 2", Author: "Author 2", Highlights: []entities.Highlight{
+			{Text: "Another quote", Color: "yellow"},
+		}}))
+
+		controller := NewBooksController(exporter)
+
+		router := gin.New()
+		router.GET("/api/books", controller.GetAllBooks)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/books?color=blue", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Books []entities.Book `json:"books"`
+			Count int             `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		require.Equal(t, 1, response.Count)
+		require.Len(t, response.Books[0].Highlights, 1)
+		assert.Equal(t, "Action item", response.Books[0].Highlights[0].Text)
+	})
+
+	t.Run("rejects unknown color", func(t *testing.T) {
+		_, exporter, cleanup := setupBooksTestDB(t)
+		defer cleanup()
+
+		controller := NewBooksController(exporter)
+
+		router := gin.New()
+		router.GET("/api/books", controller.GetAllBooks)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/books?color=teal", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("sorts by the sort parameter or the saved order", func(t *testing.T) {
+		db, exporter, cleanup := setupBooksTestDB(t)
+		defer cleanup()
+
+		require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune", Author: "Frank Herbert", PublicationYear: 1965}))
+		require.NoError(t, db.SaveBook(&entities.Book{Title: "Babel", Author: "R. F. Kuang", PublicationYear: 2022}))
+		prefs := entities.DefaultUIPreferences(0)
+		prefs.Sort = entities.BookSortTitle
+		require.NoError(t, db.SaveUIPreferences(&prefs))
+
+		controller := NewBooksController(exporter)
+
+		router := gin.New()
+		router.Use(UIPreferencesContextMiddleware(db))
+		router.GET("/api/books", controller.GetAllBooks)
+
+		titles := func(path string) []string {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", path, nil)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var response struct {
+				Books []entities.Book `json:"books"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			var titles []string
+			for _, b := range response.Books {
+				titles = append(titles, b.Title)
+			}
+			return titles
+		}
+
+		assert.Equal(t, []string{"Babel", "Dune"}, titles("/api/books"))
+		assert.Equal(t, []string{"Dune", "Babel"}, titles("/api/books?sort=added"))
+		assert.Equal(t, []string{"Babel", "Dune"}, titles("/api/books?sort=published"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/books?sort=random", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("filters books by language", func(t *testing.T) {
+		db, exporter, cleanup := setupBooksTestDB(t)
+		defer cleanup()
+
+		require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune", Author: "Frank Herbert", Language: "en"}))
+		require.NoError(t, db.SaveBook(&entities.Book{Title: "Der Prozess", Author: "Franz Kafka", Language: "de"}))
+		require.NoError(t, db.SaveBook(&entities.Book{Title: "Untitled", Author: "Unknown"}))
+
+		controller := NewBooksController(exporter)
+
+		router := gin.New()
+		router.GET("/api/books", controller.GetAllBooks)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/books?language=DE", nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Books []entities.Book `json:"books"`
+			Count int             `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, 1, response.Count)
+		assert.Equal(t, "Der Prozess", response.Books[0].Title)
+		assert.Equal(t, "de", response.Books[0].Language)
+	})
 }
 
 func TestBooksController_GetBookByTitleAndAuthor(t *testing.T) {
@@ -225,6 +346,31 @@ func TestBooksController_GetBookStats(t *testing.T) {
 		assert.Equal(t, float64(2), response["total_books"])
 		assert.Equal(t, float64(3), response["total_highlights"])
 	})
+
+	t.Run("counts highlights without loading them", func(t *testing.T) {
+		db, exporter, cleanup := setupBooksTestDB(t)
+		defer cleanup()
+
+		book := &entities.Book{
+			Title:      "Counted Book",
+			Author:     "Author",
+			Highlights: []entities.Highlight{{Text: "Highlight 1"}, {Text: "Highlight 2"}},
+		}
+		require.NoError(t, db.SaveBook(book))
+		require.NoError(t, db.DeleteHighlight(book.Highlights[0].ID))
+
+		controller := NewBooksController(exporter).WithBookList(db)
+
+		router := gin.New()
+		router.GET("/api/books/stats", controller.GetBookStats)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/books/stats", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"total_books": 1, "total_highlights": 1, "archived_books": 0}`, w.Body.String())
+	})
 }
 
 func TestNewBooksController(t *testing.T) {
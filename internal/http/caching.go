@@ -0,0 +1,134 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConditionalGetMiddleware lets clients revalidate cached responses instead of
+// downloading them again. Successful GET responses are buffered and tagged with an
+// ETag computed from the body (unless the handler set one), and a request whose
+// If-None-Match matches gets 304 Not Modified with no body. When the handler sets
+// Last-Modified, e.g. for files, If-Modified-Since is honored as well. Responses
+// without a Cache-Control header get "private, no-cache" so browsers revalidate
+// every time rather than using a stale copy.
+func ConditionalGetMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		buffered := &bufferedResponseWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		if buffered.status != http.StatusOK {
+			original.WriteHeader(buffered.status)
+			_, _ = original.Write(buffered.body.Bytes())
+			return
+		}
+
+		header := original.Header()
+		etag := header.Get("ETag")
+		if etag == "" {
+			sum := sha256.Sum256(buffered.body.Bytes())
+			etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+			header.Set("ETag", etag)
+		}
+		if header.Get("Cache-Control") == "" {
+			header.Set("Cache-Control", "private, no-cache")
+		}
+
+		if isNotModified(c.Request, etag, header.Get("Last-Modified")) {
+			for _, name := range []string{"Content-Type", "Content-Length", "Content-Disposition"} {
+				header.Del(name)
+			}
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+
+		original.WriteHeader(http.StatusOK)
+		_, _ = original.Write(buffered.body.Bytes())
+	}
+}
+
+// isNotModified evaluates the request's conditional headers. If-None-Match takes
+// precedence over If-Modified-Since, as in RFC 9110.
+func isNotModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// etagMatches reports whether an If-None-Match value matches the ETag, using the
+// weak comparison required for GET requests.
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponseWriter holds the response until the handler has finished, so
+// headers derived from the body can still be set.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+func (w *bufferedResponseWriter) Flush() {}
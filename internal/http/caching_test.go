@@ -0,0 +1,91 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalGetMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	title := "Dune"
+	coverPath := filepath.Join(t.TempDir(), "cover.jpg")
+	require.NoError(t, os.WriteFile(coverPath, []byte("jpeg"), 0o644))
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(coverPath, modTime, modTime))
+
+	router := gin.New()
+	router.GET("/books", ConditionalGetMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"title": title})
+	})
+	router.GET("/missing", ConditionalGetMiddleware(), func(c *gin.Context) {
+		respondNotFound(c, "book")
+	})
+	router.GET("/cover", ConditionalGetMiddleware(), func(c *gin.Context) {
+		c.File(coverPath)
+	})
+
+	get := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("tags responses and answers matching requests with 304", func(t *testing.T) {
+		w := get("/books", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"title": "Dune"}`, w.Body.String())
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+
+		w = get("/books", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+
+		w = get("/books", map[string]string{"If-None-Match": `"other", W/` + etag})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+
+		title = "Dune Messiah"
+		w = get("/books", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"title": "Dune Messiah"}`, w.Body.String())
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("passes errors through untagged", func(t *testing.T) {
+		w := get("/missing", map[string]string{"If-None-Match": "*"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), "book not found")
+	})
+
+	t.Run("honors If-Modified-Since for files", func(t *testing.T) {
+		w := get("/cover", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "jpeg", w.Body.String())
+		assert.Equal(t, modTime.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+
+		w = get("/cover", map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+
+		w = get("/cover", map[string]string{"If-Modified-Since": modTime.Add(-time.Hour).Format(http.TimeFormat)})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "jpeg", w.Body.String())
+	})
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/utils"
+)
+
+// CaptureStore defines database operations for highlights entered by hand.
+type CaptureStore interface {
+	GetAllBooks() ([]entities.Book, error)
+	GetBookByID(id uint) (*entities.Book, error)
+	CreateHighlight(highlight *entities.Highlight) error
+	GetHighlightByID(id uint) (*entities.Highlight, error)
+	GetHighlightByExternalID(bookID uint, externalID string) (*entities.Highlight, error)
+	GetOrCreateTag(name string, userID uint) (*entities.Tag, error)
+	AddTagToHighlight(highlightID, tagID uint) error
+}
+
+// CaptureController handles manual highlight entry, e.g. from paper books.
+type CaptureController struct {
+	store      CaptureStore
+	ocrEnabled bool
+}
+
+func NewCaptureController(store CaptureStore) *CaptureController {
+	return &CaptureController{store: store}
+}
+
+// WithOCR shows the photo capture option on the capture page.
+func (cc *CaptureController) WithOCR(enabled bool) *CaptureController {
+	cc.ocrEnabled = enabled
+	return cc
+}
+
+// CreateHighlightRequest is the request body for adding a highlight by hand.
+// Form submissions send tags as a comma-separated string. HighlightedAt defaults
+// to now; the offline capture queue sends the time the highlight was typed.
+type CreateHighlightRequest struct {
+	Text          string     `json:"text"`
+	Note          string     `json:"note,omitempty"`
+	Page          int        `json:"page,omitempty"`
+	Chapter       string     `json:"chapter,omitempty"`
+	URL           string     `json:"url,omitempty"` // Web page the highlight was clipped from
+	Tags          []string   `json:"tags,omitempty"`
+	HighlightedAt *time.Time `json:"highlighted_at,omitempty"`
+}
+
+// maxIdempotencyKeyLength keeps the stored external ID within its column size.
+const maxIdempotencyKeyLength = 200
+
+// captureExternalID is the external ID of a highlight created with an
+// Idempotency-Key header.
+func captureExternalID(key string) string {
+	return "capture:" + key
+}
+
+// CreateHighlight adds a highlight to a book. A request with an
+// Idempotency-Key header that was already used for the book returns the
+// highlight created the first time, so the offline queue can retry safely.
+// POST /api/books/:id/highlights
+func (cc *CaptureController) CreateHighlight(c *gin.Context) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	req, err := bindCreateHighlightRequest(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	idempotencyKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		respondBadRequest(c, "Idempotency-Key is too long")
+		return
+	}
+
+	book, err := cc.store.GetBookByID(bookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "book")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "get book for capture")
+		return
+	}
+
+	if idempotencyKey != "" {
+		existing, err := cc.store.GetHighlightByExternalID(book.ID, captureExternalID(idempotencyKey))
+		if err == nil {
+			cc.respondHighlight(c, http.StatusOK, book, existing)
+			return
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			respondInternalError(c, err, "find captured highlight")
+			return
+		}
+	}
+
+	highlight := &entities.Highlight{
+		BookID:        book.ID,
+		UserID:        book.UserID,
+		Text:          req.Text,
+		Note:          req.Note,
+		Chapter:       req.Chapter,
+		URL:           req.URL,
+		Style:         entities.HighlightStyleHighlight,
+		LocationType:  entities.LocationTypeNone,
+		HighlightedAt: time.Now(),
+		Source:        entities.Source{Name: "manual"},
+	}
+	if idempotencyKey != "" {
+		highlight.ExternalID = captureExternalID(idempotencyKey)
+	}
+	if req.Text == "" {
+		highlight.Style = entities.HighlightStyleNoteOnly
+	}
+	if req.HighlightedAt != nil && !req.HighlightedAt.After(highlight.HighlightedAt) {
+		highlight.HighlightedAt = *req.HighlightedAt
+	}
+	if req.Page > 0 {
+		highlight.LocationType = entities.LocationTypePage
+		highlight.LocationValue = req.Page
+	}
+
+	if err := cc.store.CreateHighlight(highlight); err != nil {
+		respondInternalError(c, err, "create highlight")
+		return
+	}
+
+	for _, name := range req.Tags {
+		tag, err := cc.store.GetOrCreateTag(name, DefaultUserID)
+		if err != nil {
+			log.Printf("Failed to create tag %q for highlight %d: %v", name, highlight.ID, err)
+			continue
+		}
+		if err := cc.store.AddTagToHighlight(highlight.ID, tag.ID); err != nil {
+			log.Printf("Failed to tag highlight %d with %q: %v", highlight.ID, name, err)
+		}
+	}
+
+	if saved, err := cc.store.GetHighlightByID(highlight.ID); err == nil {
+		highlight = saved
+	}
+
+	cc.respondHighlight(c, http.StatusCreated, book, highlight)
+}
+
+func (cc *CaptureController) respondHighlight(c *gin.Context, status int, book *entities.Book, highlight *entities.Highlight) {
+	if isHTMXRequest(c) {
+		c.HTML(status, "capture-saved", gin.H{
+			"Book":      book,
+			"Highlight": highlight,
+		})
+		return
+	}
+	c.JSON(status, highlight)
+}
+
+// CapturePage renders the quick-capture form for typing or pasting highlights.
+// GET /capture
+func (cc *CaptureController) CapturePage(c *gin.Context) {
+	books, err := cc.store.GetAllBooks()
+	if err != nil {
+		respondInternalError(c, err, "load capture page")
+		return
+	}
+	sort.Slice(books, func(i, j int) bool {
+		return strings.ToLower(books[i].Title) < strings.ToLower(books[j].Title)
+	})
+
+	var selectedBookID uint
+	if id, err := strconv.ParseUint(c.Query("book"), 10, 32); err == nil {
+		selectedBookID = uint(id)
+	}
+
+	c.HTML(http.StatusOK, "capture", gin.H{
+		"Books":          books,
+		"SelectedBookID": selectedBookID,
+		"OCREnabled":     cc.ocrEnabled,
+		"Auth":           GetAuthTemplateData(c),
+		"UI":             GetUIPreferences(c),
+		"Demo":           GetDemoTemplateData(c),
+		"Analytics":      GetAnalyticsTemplateData(c),
+	})
+}
+
+func bindCreateHighlightRequest(c *gin.Context) (*CreateHighlightRequest, error) {
+	req := &CreateHighlightRequest{}
+	if c.ContentType() == "application/json" {
+		if err := c.ShouldBindJSON(req); err != nil {
+			return nil, errors.New("invalid request body")
+		}
+	} else {
+		req.Text = c.PostForm("text")
+		req.Note = c.PostForm("note")
+		req.Chapter = c.PostForm("chapter")
+		req.URL = c.PostForm("url")
+		if page := strings.TrimSpace(c.PostForm("page")); page != "" {
+			n, err := strconv.Atoi(page)
+			if err != nil {
+				return nil, errors.New("page must be a number")
+			}
+			req.Page = n
+		}
+		req.Tags = strings.Split(c.PostForm("tags"), ",")
+	}
+
+	req.Text = strings.TrimSpace(req.Text)
+	req.Note = strings.TrimSpace(req.Note)
+	req.Chapter = strings.TrimSpace(req.Chapter)
+	req.URL = strings.TrimSpace(req.URL)
+	if req.Text == "" && req.Note == "" {
+		return nil, errors.New("text or note is required")
+	}
+	if req.URL != "" && !utils.IsWebURL(req.URL) {
+		return nil, errors.New("url must be an http or https URL")
+	}
+	if req.Page < 0 {
+		return nil, errors.New("page must not be negative")
+	}
+
+	tags := req.Tags[:0]
+	for _, tag := range req.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	req.Tags = tags
+
+	return req, nil
+}
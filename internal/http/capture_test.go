@@ -0,0 +1,146 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func setupCaptureRouter(t *testing.T) (*gin.Engine, *entities.Book, func(uint) *entities.Highlight) {
+	t.Helper()
+	db, _, cleanup := setupBooksTestDB(t)
+	t.Cleanup(cleanup)
+
+	book := &entities.Book{Title: "Paper Book", Author: "Some Author", Source: entities.Source{Name: "manual"}}
+	require.NoError(t, db.SaveBook(book))
+
+	controller := NewCaptureController(db)
+	router := gin.New()
+	router.POST("/api/books/:id/highlights", controller.CreateHighlight)
+
+	getHighlight := func(id uint) *entities.Highlight {
+		highlight, err := db.GetHighlightByID(id)
+		require.NoError(t, err)
+		return highlight
+	}
+	return router, book, getHighlight
+}
+
+func TestCaptureController_CreateHighlightJSON(t *testing.T) {
+	router, book, getHighlight := setupCaptureRouter(t)
+
+	capturedAt := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	body, _ := json.Marshal(CreateHighlightRequest{
+		Text:          "  A passage typed from a paper book.  ",
+		Note:          "Worth rereading",
+		Page:          42,
+		Tags:          []string{"philosophy", " "},
+		HighlightedAt: &capturedAt,
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/books/%d/highlights", book.ID), bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created entities.Highlight
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	highlight := getHighlight(created.ID)
+	assert.Equal(t, book.ID, highlight.BookID)
+	assert.Equal(t, "A passage typed from a paper book.", highlight.Text)
+	assert.Equal(t, "Worth rereading", highlight.Note)
+	assert.Equal(t, entities.LocationTypePage, highlight.LocationType)
+	assert.Equal(t, 42, highlight.LocationValue)
+	assert.Equal(t, "manual", highlight.Source.Name)
+	assert.True(t, capturedAt.Equal(highlight.HighlightedAt), "queued highlights keep their capture time")
+	require.Len(t, highlight.Tags, 1)
+	assert.Equal(t, "philosophy", highlight.Tags[0].Name)
+}
+
+func TestCaptureController_CreateHighlightForm(t *testing.T) {
+	router, book, getHighlight := setupCaptureRouter(t)
+
+	form := url.Values{"note": {"Only a thought"}, "page": {""}, "tags": {"ideas, todo"}, "url": {"https://example.com/post"}}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/books/%d/highlights", book.ID), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created entities.Highlight
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	highlight := getHighlight(created.ID)
+	assert.Equal(t, entities.HighlightStyleNoteOnly, highlight.Style)
+	assert.Equal(t, entities.LocationTypeNone, highlight.LocationType)
+	assert.Len(t, highlight.Tags, 2)
+	assert.Equal(t, "https://example.com/post", highlight.URL)
+}
+
+func TestCaptureController_CreateHighlightValidation(t *testing.T) {
+	router, book, _ := setupCaptureRouter(t)
+
+	tests := []struct {
+		name           string
+		path           string
+		form           url.Values
+		expectedStatus int
+	}{
+		{"missing text and note", fmt.Sprintf("/api/books/%d/highlights", book.ID), url.Values{"text": {"  "}}, http.StatusBadRequest},
+		{"invalid page", fmt.Sprintf("/api/books/%d/highlights", book.ID), url.Values{"text": {"x"}, "page": {"ten"}}, http.StatusBadRequest},
+		{"invalid url", fmt.Sprintf("/api/books/%d/highlights", book.ID), url.Values{"text": {"x"}, "url": {"javascript:alert(1)"}}, http.StatusBadRequest},
+		{"unknown book", "/api/books/99999/highlights", url.Values{"text": {"x"}}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestCaptureController_CreateHighlightIdempotencyKey(t *testing.T) {
+	router, book, _ := setupCaptureRouter(t)
+
+	post := func(key string) (int, entities.Highlight) {
+		body, _ := json.Marshal(CreateHighlightRequest{Text: "Queued offline"})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/books/%d/highlights", book.ID), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		router.ServeHTTP(w, req)
+
+		var highlight entities.Highlight
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &highlight), w.Body.String())
+		return w.Code, highlight
+	}
+
+	status, first := post("entry-1")
+	require.Equal(t, http.StatusCreated, status)
+
+	status, retried := post("entry-1")
+	assert.Equal(t, http.StatusOK, status, "a retry returns the highlight saved the first time")
+	assert.Equal(t, first.ID, retried.ID)
+
+	status, other := post("entry-2")
+	assert.Equal(t, http.StatusCreated, status)
+	assert.NotEqual(t, first.ID, other.ID)
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+)
+
+// CitationStore defines database operations for citation exports.
+type CitationStore interface {
+	GetBookWithoutHighlights(id uint) (*entities.Book, error)
+	GetBooksByTag(tagID uint, userID uint) ([]entities.Book, error)
+	GetCollectionByID(id uint) (*entities.Collection, error)
+}
+
+// CitationsController renders books as BibTeX or CSL-JSON, for quoting
+// highlights in papers managed with LaTeX, Zotero or Pandoc.
+type CitationsController struct {
+	store CitationStore
+}
+
+func NewCitationsController(store CitationStore) *CitationsController {
+	return &CitationsController{store: store}
+}
+
+// BookCitation returns the citation of a book.
+// GET /api/books/:id/citation?format=bibtex|csl-json
+func (cc *CitationsController) BookCitation(c *gin.Context) {
+	format, ok := parseCitationFormat(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	book, err := cc.store.GetBookWithoutHighlights(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ownedByUser(c, book.UserID)) {
+		respondNotFound(c, "book")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "get book")
+		return
+	}
+
+	respondCitations(c, format, []entities.Book{*book}, "")
+}
+
+// ExportCitations downloads the citations of the books with a tag or in a
+// collection. Archived books are left out unless include_archived=true.
+// GET /api/citations?format=bibtex|csl-json&tag=ID
+// GET /api/citations?format=bibtex|csl-json&collection=ID
+func (cc *CitationsController) ExportCitations(c *gin.Context) {
+	format, ok := parseCitationFormat(c)
+	if !ok {
+		return
+	}
+	includeArchived, err := parseIncludeArchived(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	var books []entities.Book
+	var name string
+	switch tagID, collectionID := c.Query("tag"), c.Query("collection"); {
+	case tagID != "":
+		id, err := strconv.ParseUint(tagID, 10, 32)
+		if err != nil {
+			respondBadRequest(c, "invalid tag")
+			return
+		}
+		books, err = cc.store.GetBooksByTag(uint(id), GetUserID(c))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondNotFound(c, "tag")
+			return
+		}
+		if err != nil {
+			respondInternalError(c, err, "get books by tag")
+			return
+		}
+		name = fmt.Sprintf("tag-%d", id)
+	case collectionID != "":
+		id, err := strconv.ParseUint(collectionID, 10, 32)
+		if err != nil {
+			respondBadRequest(c, "invalid collection")
+			return
+		}
+		collection, err := cc.store.GetCollectionByID(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ownedByUser(c, collection.UserID)) {
+			respondNotFound(c, "collection")
+			return
+		}
+		if err != nil {
+			respondInternalError(c, err, "get collection")
+			return
+		}
+		books = collection.Books
+		name = fmt.Sprintf("collection-%d", id)
+	default:
+		respondBadRequest(c, "tag or collection is required")
+		return
+	}
+
+	if !includeArchived {
+		books, _ = withoutArchived(books)
+	}
+	respondCitations(c, format, books, name)
+}
+
+// parseCitationFormat responds with an error and returns false for formats
+// other than exporters.CitationFormats. The default is BibTeX.
+func parseCitationFormat(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", exporters.CitationBibTeX)
+	if !slices.Contains(exporters.CitationFormats, format) {
+		respondBadRequest(c, "format must be bibtex or csl-json")
+		return "", false
+	}
+	return format, true
+}
+
+// respondCitations writes the citations of books, as a download named after
+// name unless it is "".
+func respondCitations(c *gin.Context, format string, books []entities.Book, name string) {
+	content, err := exporters.GenerateCitations(format, books)
+	if err != nil {
+		respondInternalError(c, err, "generate citations")
+		return
+	}
+
+	contentType, extension := "application/x-bibtex; charset=utf-8", "bib"
+	if format == exporters.CitationCSLJSON {
+		contentType, extension = "application/vnd.citationstyles.csl+json; charset=utf-8", "json"
+	}
+	if name != "" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("citations-%s.%s", name, extension)))
+	}
+	c.Data(http.StatusOK, contentType, []byte(content))
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestCitationsController(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	thinking := &entities.Book{Title: "Thinking, Fast and Slow", Author: "Daniel Kahneman", Publisher: "Farrar, Straus and Giroux", PublicationYear: 2011}
+	nudge := &entities.Book{Title: "Nudge", Author: "Richard Thaler and Cass Sunstein", PublicationYear: 2008}
+	require.NoError(t, db.SaveBook(thinking))
+	require.NoError(t, db.SaveBook(nudge))
+	require.NoError(t, db.SetBookArchived(nudge.ID, true))
+
+	tag, err := db.CreateTag("behavioral-economics", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToBook(thinking.ID, tag.ID))
+	require.NoError(t, db.AddTagToBook(nudge.ID, tag.ID))
+	collection, err := db.CreateCollection(0, "Thesis", "")
+	require.NoError(t, err)
+	require.NoError(t, db.AddBookToCollection(collection.ID, nudge.ID))
+
+	controller := NewCitationsController(db)
+	router := gin.New()
+	router.GET("/api/books/:id/citation", controller.BookCitation)
+	router.GET("/api/citations", controller.ExportCitations)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(fmt.Sprintf("/api/books/%d/citation", thinking.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/x-bibtex; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "@book{kahneman2011thinking,\n")
+	assert.Contains(t, w.Body.String(), "  publisher = {Farrar, Straus and Giroux},\n")
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+
+	w = get(fmt.Sprintf("/api/books/%d/citation?format=csl-json", thinking.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"family": "Kahneman"`)
+
+	assert.Equal(t, http.StatusBadRequest, get(fmt.Sprintf("/api/books/%d/citation?format=ris", thinking.ID)).Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/books/9999/citation").Code)
+
+	// Bulk exports leave archived books out unless asked for
+	w = get(fmt.Sprintf("/api/citations?tag=%d", tag.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), fmt.Sprintf(`filename="citations-tag-%d.bib"`, tag.ID))
+	assert.Contains(t, w.Body.String(), "kahneman2011thinking")
+	assert.NotContains(t, w.Body.String(), "Nudge")
+
+	w = get(fmt.Sprintf("/api/citations?tag=%d&include_archived=true", tag.ID))
+	assert.Contains(t, w.Body.String(), "  author = {Thaler, Richard and Sunstein, Cass},\n")
+
+	w = get(fmt.Sprintf("/api/citations?collection=%d&format=csl-json&include_archived=true", collection.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".json")
+	assert.Contains(t, w.Body.String(), `"id": "thaler2008nudge"`)
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/citations").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/citations?collection=9999").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/citations?tag=9999").Code)
+}
@@ -0,0 +1,352 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// CollectionStore defines database operations for book collections.
+type CollectionStore interface {
+	GetCollections(userID uint) ([]entities.CollectionSummary, error)
+	GetCollectionByID(id uint) (*entities.Collection, error)
+	CreateCollection(userID uint, name, description string) (*entities.Collection, error)
+	UpdateCollection(id uint, name, description string) (*entities.Collection, error)
+	DeleteCollection(id uint) error
+	GetBookCollections(bookID uint) ([]entities.Collection, error)
+	AddBookToCollection(collectionID, bookID uint) error
+	RemoveBookFromCollection(collectionID, bookID uint) error
+	MoveBookInCollection(collectionID, bookID uint, position int) error
+	GetBookByID(id uint) (*entities.Book, error)
+}
+
+// CollectionsController manages user-defined, ordered lists of books.
+type CollectionsController struct {
+	store CollectionStore
+}
+
+func NewCollectionsController(store CollectionStore) *CollectionsController {
+	return &CollectionsController{store: store}
+}
+
+// CollectionRequest is the request body for creating or updating a collection.
+type CollectionRequest struct {
+	Name        string `json:"name" form:"name"`
+	Description string `json:"description" form:"description"`
+}
+
+// ListCollections returns the user's collections with their book counts.
+// GET /api/collections
+func (cc *CollectionsController) ListCollections(c *gin.Context) {
+	collections, err := cc.store.GetCollections(auth.GetUserID(c))
+	if err != nil {
+		respondInternalError(c, err, "list collections")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"collections": collections, "count": len(collections)})
+}
+
+// CreateCollection creates an empty collection.
+// POST /api/collections
+func (cc *CollectionsController) CreateCollection(c *gin.Context) {
+	var req CollectionRequest
+	if err := c.ShouldBind(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		respondBadRequest(c, "name is required")
+		return
+	}
+
+	collection, err := cc.store.CreateCollection(auth.GetUserID(c), req.Name, req.Description)
+	if errors.Is(err, database.ErrCollectionNameTaken) {
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "create collection")
+		return
+	}
+	respondCreated(c, collection)
+}
+
+// GetCollection returns a collection with its books in order.
+// GET /api/collections/:id
+func (cc *CollectionsController) GetCollection(c *gin.Context) {
+	collection, ok := cc.loadCollection(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, collection)
+}
+
+// UpdateCollection renames a collection or changes its description.
+// PATCH /api/collections/:id
+func (cc *CollectionsController) UpdateCollection(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CollectionRequest
+	if err := c.ShouldBind(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		respondBadRequest(c, "name is required")
+		return
+	}
+
+	collection, err := cc.store.UpdateCollection(id, req.Name, req.Description)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondNotFound(c, "collection")
+		return
+	case errors.Is(err, database.ErrCollectionNameTaken):
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		respondInternalError(c, err, "update collection")
+		return
+	}
+	c.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection deletes a collection; its books are kept.
+// DELETE /api/collections/:id
+func (cc *CollectionsController) DeleteCollection(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := cc.store.DeleteCollection(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "collection")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "delete collection")
+		return
+	}
+	respondSuccess(c, "collection deleted")
+}
+
+// MoveBookRequest is the request body for moving a book within a collection.
+type MoveBookRequest struct {
+	Position *int `json:"position" form:"position"`
+}
+
+// MoveBook moves a book to a 0-based position in the collection.
+// POST /api/collections/:id/books/:bookId/move
+func (cc *CollectionsController) MoveBook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	bookID, ok := parseIDParam(c, "bookId")
+	if !ok {
+		return
+	}
+
+	var req MoveBookRequest
+	if err := c.ShouldBind(&req); err != nil || req.Position == nil || *req.Position < 0 {
+		respondBadRequest(c, "position must be 0 or more")
+		return
+	}
+
+	err := cc.store.MoveBookInCollection(id, bookID, *req.Position)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "book in collection")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "move book in collection")
+		return
+	}
+
+	collection, err := cc.store.GetCollectionByID(id)
+	if err != nil {
+		respondInternalError(c, err, "get collection")
+		return
+	}
+	c.JSON(http.StatusOK, collection)
+}
+
+// AddBookToCollectionRequest is the request body for adding a book to a collection.
+type AddBookToCollectionRequest struct {
+	CollectionID uint `json:"collection_id" form:"collection_id"`
+}
+
+// AddBookToCollection appends the book to the end of a collection.
+// POST /api/books/:id/collections
+func (cc *CollectionsController) AddBookToCollection(c *gin.Context) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req AddBookToCollectionRequest
+	if err := c.ShouldBind(&req); err != nil || req.CollectionID == 0 {
+		respondBadRequest(c, "collection_id is required")
+		return
+	}
+
+	err := cc.store.AddBookToCollection(req.CollectionID, bookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "book or collection")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "add book to collection")
+		return
+	}
+	cc.respondBookCollections(c, bookID)
+}
+
+// RemoveBookFromCollection takes the book out of a collection.
+// DELETE /api/books/:id/collections/:collectionId
+func (cc *CollectionsController) RemoveBookFromCollection(c *gin.Context) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	collectionID, ok := parseIDParam(c, "collectionId")
+	if !ok {
+		return
+	}
+
+	err := cc.store.RemoveBookFromCollection(collectionID, bookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "book in collection")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "remove book from collection")
+		return
+	}
+	cc.respondBookCollections(c, bookID)
+}
+
+// BookCollections renders the collections section of a book page.
+// GET /ui/books/:id/collections
+func (cc *CollectionsController) BookCollections(c *gin.Context) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	data, err := cc.bookCollectionsData(c, bookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "book")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "get book collections")
+		return
+	}
+	c.HTML(http.StatusOK, "book-collections", data)
+}
+
+// CollectionsPage lists the user's collections.
+// GET /collections
+func (cc *CollectionsController) CollectionsPage(c *gin.Context) {
+	collections, err := cc.store.GetCollections(auth.GetUserID(c))
+	if err != nil {
+		respondInternalError(c, err, "list collections")
+		return
+	}
+
+	c.HTML(http.StatusOK, "collections", gin.H{
+		"Collections": collections,
+		"Auth":        GetAuthTemplateData(c),
+		"UI":          GetUIPreferences(c),
+		"Demo":        GetDemoTemplateData(c),
+		"Analytics":   GetAnalyticsTemplateData(c),
+	})
+}
+
+// CollectionPage renders a collection with its books in order.
+// GET /ui/collections/:id
+func (cc *CollectionsController) CollectionPage(c *gin.Context) {
+	collection, ok := cc.loadCollection(c)
+	if !ok {
+		return
+	}
+
+	c.HTML(http.StatusOK, "collection", gin.H{
+		"Collection": collection,
+		"Auth":       GetAuthTemplateData(c),
+		"UI":         GetUIPreferences(c),
+		"Demo":       GetDemoTemplateData(c),
+		"Analytics":  GetAnalyticsTemplateData(c),
+	})
+}
+
+// respondBookCollections renders the book's collections section for HTMX
+// requests, or lists the book's collections as JSON
+func (cc *CollectionsController) respondBookCollections(c *gin.Context, bookID uint) {
+	data, err := cc.bookCollectionsData(c, bookID)
+	if err != nil {
+		respondInternalError(c, err, "get book collections")
+		return
+	}
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "book-collections", data)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"collections": data["Collections"]})
+}
+
+// bookCollectionsData returns the collections a book is in and the user's
+// other collections it can be added to
+func (cc *CollectionsController) bookCollectionsData(c *gin.Context, bookID uint) (gin.H, error) {
+	book, err := cc.store.GetBookByID(bookID)
+	if err != nil {
+		return nil, err
+	}
+	collections, err := cc.store.GetBookCollections(bookID)
+	if err != nil {
+		return nil, err
+	}
+	all, err := cc.store.GetCollections(book.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	member := make(map[uint]bool, len(collections))
+	for _, collection := range collections {
+		member[collection.ID] = true
+	}
+	var others []entities.Collection
+	for _, summary := range all {
+		if !member[summary.Collection.ID] {
+			others = append(others, summary.Collection)
+		}
+	}
+
+	return gin.H{
+		"Book":             book,
+		"Collections":      collections,
+		"OtherCollections": others,
+		"Demo":             GetDemoTemplateData(c),
+	}, nil
+}
+
+func (cc *CollectionsController) loadCollection(c *gin.Context) (*entities.Collection, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	collection, err := cc.store.GetCollectionByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "collection")
+		return nil, false
+	}
+	if err != nil {
+		respondInternalError(c, err, "get collection")
+		return nil, false
+	}
+	return collection, true
+}
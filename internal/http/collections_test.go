@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestCollectionsController(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	meditations := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius"}
+	letters := &entities.Book{Title: "Letters from a Stoic", Author: "Seneca"}
+	require.NoError(t, db.SaveBook(meditations))
+	require.NoError(t, db.SaveBook(letters))
+
+	controller := NewCollectionsController(db)
+	router := gin.New()
+	router.GET("/api/collections", controller.ListCollections)
+	router.POST("/api/collections", controller.CreateCollection)
+	router.GET("/api/collections/:id", controller.GetCollection)
+	router.PATCH("/api/collections/:id", controller.UpdateCollection)
+	router.DELETE("/api/collections/:id", controller.DeleteCollection)
+	router.POST("/api/collections/:id/books/:bookId/move", controller.MoveBook)
+	router.POST("/api/books/:id/collections", controller.AddBookToCollection)
+	router.DELETE("/api/books/:id/collections/:collectionId", controller.RemoveBookFromCollection)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(http.MethodPost, "/api/collections", `{"name": "Stoicism starter pack"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var collection entities.Collection
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &collection))
+
+	assert.Equal(t, http.StatusConflict, send(http.MethodPost, "/api/collections", `{"name": "stoicism starter pack"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/api/collections", `{"name": " "}`).Code)
+
+	for _, book := range []*entities.Book{meditations, letters} {
+		w = send(http.MethodPost, fmt.Sprintf("/api/books/%d/collections", book.ID), fmt.Sprintf(`{"collection_id": %d}`, collection.ID))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/api/books/9999/collections", fmt.Sprintf(`{"collection_id": %d}`, collection.ID)).Code)
+
+	// Form submissions from the collection page
+	form := url.Values{"position": {"0"}}
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/collections/%d/books/%d/move", collection.ID, letters.ID), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var moved entities.Collection
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved))
+	require.Len(t, moved.Books, 2)
+	assert.Equal(t, "Letters from a Stoic", moved.Books[0].Title)
+
+	w = send(http.MethodGet, "/api/collections", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Collections []entities.CollectionSummary `json:"collections"`
+		Count       int                          `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, 1, list.Count)
+	assert.Equal(t, int64(2), list.Collections[0].BookCount)
+
+	w = send(http.MethodDelete, fmt.Sprintf("/api/books/%d/collections/%d", letters.ID, collection.ID), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, fmt.Sprintf("/api/books/%d/collections/%d", letters.ID, collection.ID), "").Code)
+
+	w = send(http.MethodPatch, fmt.Sprintf("/api/collections/%d", collection.ID), `{"name": "Stoics", "description": "Start here"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var updated entities.Collection
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "Stoics", updated.Name)
+	require.Len(t, updated.Books, 1)
+
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, fmt.Sprintf("/api/collections/%d", collection.ID), "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, fmt.Sprintf("/api/collections/%d", collection.ID), "").Code)
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// minCompressSize is the smallest response worth compressing, in bytes.
+const minCompressSize = 1024
+
+// compressibleTypes are the media types compressed when the client accepts gzip.
+// Images other than SVG, archives and event streams are sent as is.
+var compressibleTypes = []string{
+	"text/html",
+	"text/css",
+	"text/plain",
+	"text/markdown",
+	"text/csv",
+	"text/javascript",
+	"application/javascript",
+	"application/json",
+	"application/manifest+json",
+	"application/xml",
+	"text/xml",
+	"image/svg+xml",
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
+// CompressionMiddleware gzips responses for clients that send Accept-Encoding: gzip.
+// Only text-like content types of at least minCompressSize bytes are compressed;
+// partial content and responses that already set Content-Encoding are left alone.
+// Every response that qualifies gets Vary: Accept-Encoding, compressed or not, so
+// caches keep the gzip and plain copies apart.
+// Brotli is not offered: the standard library has no encoder, and gzip gets
+// most of the gain for the small HTML and JSON responses served here.
+func CompressionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &gzipResponseWriter{
+			ResponseWriter: c.Writer,
+			status:         c.Writer.Status(),
+			acceptsGzip:    c.Request.Method != http.MethodHead && acceptsGzip(c.GetHeader("Accept-Encoding")),
+		}
+		c.Writer = writer
+		defer writer.finish()
+		c.Next()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// addVary adds a field to the Vary header unless it is listed already.
+func addVary(header http.Header, field string) {
+	for _, value := range header.Values("Vary") {
+		for _, existing := range strings.Split(value, ",") {
+			existing = strings.TrimSpace(existing)
+			if existing == "*" || strings.EqualFold(existing, field) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", field)
+}
+
+func isCompressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, t := range compressibleTypes {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter holds back the status line until the first write, when the
+// headers and the size of the body so far decide whether to compress.
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	gz          *gzip.Writer
+	status      int
+	decided     bool
+	acceptsGzip bool // The client accepts gzip and the request is not HEAD
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if !w.decided {
+		w.status = code
+	}
+}
+
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide(0)
+	}
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.decide(len(data))
+	}
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipResponseWriter) Status() int {
+	if !w.decided {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *gzipResponseWriter) Written() bool {
+	return w.decided
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		w.decide(0)
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide sends the headers, switching to gzip when the response qualifies and
+// the client accepts it. firstWrite is the size of the first chunk of the body,
+// used when the handler did not set Content-Length.
+func (w *gzipResponseWriter) decide(firstWrite int) {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+
+	size := firstWrite
+	if cl, err := strconv.Atoi(header.Get("Content-Length")); err == nil {
+		size = cl
+	}
+	qualifies := w.status == http.StatusOK &&
+		header.Get("Content-Encoding") == "" &&
+		header.Get("Content-Range") == "" &&
+		size >= minCompressSize &&
+		isCompressible(header.Get("Content-Type"))
+	if qualifies {
+		addVary(header, "Accept-Encoding")
+	}
+	if qualifies && w.acceptsGzip {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		// The compressed body is a different representation, so a strong ETag
+		// computed from the original bytes becomes weak
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		w.gz = gzipWriterPool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// finish flushes the compressed stream. If nothing was written, only the status
+// is passed on, leaving gin to send the headers as usual.
+func (w *gzipResponseWriter) finish() {
+	if !w.decided {
+		w.ResponseWriter.WriteHeader(w.status)
+		return
+	}
+	if w.gz != nil {
+		_ = w.gz.Close()
+		gzipWriterPool.Put(w.gz)
+		w.gz = nil
+	}
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	large := strings.Repeat("highlight ", 500)
+	router := gin.New()
+	router.Use(CompressionMiddleware())
+	router.GET("/large", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"text": large})
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"text": "short"})
+	})
+	router.GET("/image", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(large))
+	})
+	router.GET("/cached", ConditionalGetMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, large)
+	})
+	router.GET("/vary", func(c *gin.Context) {
+		c.Header("Vary", "accept-encoding, Cookie")
+		c.String(http.StatusOK, large)
+	})
+
+	get := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	gunzip := func(t *testing.T, body io.Reader) string {
+		t.Helper()
+		r, err := gzip.NewReader(body)
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(data)
+	}
+	acceptGzip := map[string]string{"Accept-Encoding": "br, gzip;q=0.8"}
+
+	t.Run("compresses large JSON", func(t *testing.T) {
+		w := get("/large", acceptGzip)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Less(t, w.Body.Len(), len(large))
+		assert.Contains(t, gunzip(t, w.Body), large)
+	})
+
+	t.Run("leaves responses alone when gzip is not accepted", func(t *testing.T) {
+		for _, headers := range []map[string]string{nil, {"Accept-Encoding": "gzip;q=0, br"}} {
+			w := get("/large", headers)
+			assert.Empty(t, w.Header().Get("Content-Encoding"))
+			// Caches must not hand this copy to clients that accept gzip
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Contains(t, w.Body.String(), large)
+		}
+	})
+
+	t.Run("does not repeat Vary set by the handler", func(t *testing.T) {
+		w := get("/vary", acceptGzip)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, []string{"accept-encoding, Cookie"}, w.Header().Values("Vary"))
+	})
+
+	t.Run("skips small and binary responses", func(t *testing.T) {
+		w := get("/small", acceptGzip)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Empty(t, w.Header().Get("Vary"))
+		assert.JSONEq(t, `{"text": "short"}`, w.Body.String())
+
+		w = get("/image", acceptGzip)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Empty(t, w.Header().Get("Vary"))
+		assert.Equal(t, large, w.Body.String())
+	})
+
+	t.Run("weakens ETags of compressed responses and still revalidates", func(t *testing.T) {
+		w := get("/cached", acceptGzip)
+		require.Equal(t, http.StatusOK, w.Code)
+		etag := w.Header().Get("ETag")
+		assert.True(t, strings.HasPrefix(etag, `W/"`), etag)
+		assert.Equal(t, large, gunzip(t, w.Body))
+
+		w = get("/cached", map[string]string{"Accept-Encoding": "gzip", "If-None-Match": etag})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("keeps the status of unmatched routes", func(t *testing.T) {
+		w := get("/missing", acceptGzip)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "404 page not found", w.Body.String())
+	})
+}
+
+func TestStaticAssets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "icons"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "style.css"), []byte("body {}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "icons", "icon.svg"), []byte("<svg/>"), 0o644))
+
+	assets := NewStaticAssets(root)
+	styleURL := assets.URL("style.css")
+	assert.Regexp(t, `^/static/style\.css\?v=[0-9a-f]{12}$`, styleURL)
+	assert.Regexp(t, `^/static/icons/icon\.svg\?v=[0-9a-f]{12}$`, assets.URL("/icons/icon.svg"))
+	assert.Equal(t, "/static/missing.js", assets.URL("missing.js"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "style.css"), []byte("body { color: red }"), 0o644))
+	assert.NotEqual(t, styleURL, NewStaticAssets(root).URL("style.css"))
+
+	router := gin.New()
+	router.Group("/static", assets.CacheMiddleware()).Static("/", root)
+
+	for path, cacheControl := range map[string]string{
+		styleURL:                 "public, max-age=31536000, immutable",
+		"/static/style.css":      "no-cache",
+		"/static/style.css?v=00": "no-cache",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, cacheControl, w.Header().Get("Cache-Control"), path)
+	}
+}
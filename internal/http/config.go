@@ -1,6 +1,9 @@
 package http
 
 import (
+	"net/netip"
+	"time"
+
 	"github.com/mrlokans/assistant/internal/analytics"
 	"github.com/mrlokans/assistant/internal/audit"
 	"github.com/mrlokans/assistant/internal/auth"
@@ -9,12 +12,17 @@ import (
 	"github.com/mrlokans/assistant/internal/database"
 	"github.com/mrlokans/assistant/internal/demo"
 	"github.com/mrlokans/assistant/internal/dictionary"
+	"github.com/mrlokans/assistant/internal/events"
 	"github.com/mrlokans/assistant/internal/exporters"
 	"github.com/mrlokans/assistant/internal/metadata"
+	"github.com/mrlokans/assistant/internal/ocr"
 	"github.com/mrlokans/assistant/internal/readwise"
 	"github.com/mrlokans/assistant/internal/scheduler"
 	"github.com/mrlokans/assistant/internal/settingsstore"
 	"github.com/mrlokans/assistant/internal/tasks"
+	"github.com/mrlokans/assistant/internal/telegram"
+	"github.com/mrlokans/assistant/internal/tts"
+	"github.com/mrlokans/assistant/internal/uploads"
 )
 
 // RouterConfig contains all dependencies and configuration needed
@@ -23,12 +31,55 @@ import (
 //
 // Optional features: Set the corresponding field to nil to disable endpoints:
 //   - TagStore: nil disables /api/tags/* endpoints
+//   - TagCSVStore: nil disables tag CSV export and import
+//   - BookDetailsStore: nil disables GET /api/books/:id/full
+//   - BookHighlightStore: nil disables GET /api/books/:id/highlights; book pages load all highlights at once
+//   - BookListStore: nil makes the library page and GET /api/books/stats load every book's highlights
+//   - BookArchiveStore: nil disables /api/books/:id/archive
+//   - BookNoteStore: nil disables /api/books/:id/notes and the journal tab of book pages
+//   - CitationStore: nil disables GET /api/books/:id/citation and GET /api/citations
+//   - BookEditStore: nil disables PATCH /api/books/:id and GET /api/books/:id/suggestions (which also needs MetadataEnricher)
 //   - DeleteStore: nil disables DELETE /api/books/* and /api/highlights/*
-//   - FavouritesStore: nil disables /api/highlights/*/favourite endpoints
+//   - FavouritesStore: nil disables /api/highlights/*/favourite and /api/books/*/favourite endpoints
 //   - VocabularyStore: nil disables /api/vocabulary/* endpoints
+//   - VocabularyReviewStore: nil disables vocabulary review endpoints and the /vocabulary/review page (also needs VocabularyStore)
+//   - VocabularyMergeStore: nil disables merging vocabulary words and adding new forms of a saved word to it (also needs VocabularyStore)
+//   - UpgradeStatusStore: nil disables /api/upgrade/status and the /upgrade page
+//   - MaintenanceStore: nil disables /api/admin/maintenance/* endpoints and the /admin/health page
+//   - TrashStore: nil disables /api/trash/* endpoints and the /trash page
+//   - TombstoneStore: nil disables /api/tombstones/* endpoints
+//   - LibraryDiffStore: nil disables GET /api/library/diff
+//   - LibraryImportStore: nil disables Goodreads/StoryGraph library CSV import
+//   - CaptureStore: nil disables POST /api/books/:id/highlights and the /capture page
+//   - AuthorStore: nil disables /api/authors/* endpoints and author pages (lookups also need AuthorEnricher)
+//   - SeriesStore: nil disables GET /api/series and the /ui/series page
+//   - CollectionStore: nil disables /api/collections/* endpoints and collection pages
+//   - BookSourceStore: nil disables GET /api/books/:id/sources and the sources panel of book pages
+//   - SavedViewStore: nil disables /api/views/* endpoints, saved view pages and the view export filter
+//   - UIPreferencesStore: nil disables /api/preferences; pages use the default theme, sort and layout
+//   - QuickSearchStore: nil disables GET /api/quicksearch
+//   - OCREngine: nil disables POST /api/ocr and photo capture
+//   - PodcastStore: nil (or no PodcastAudio) disables the /podcast feeds of spoken highlights
+//   - HighlightListStore: nil disables GET /api/highlights, /api/highlights/random and the highlight of the day card
+//   - HighlightHistoryStore: nil disables /api/highlights/:id/history and /api/highlights/conflicts endpoints
+//   - HighlightDuplicateStore: nil disables /api/admin/duplicates/* endpoints
+//   - NoteStore: nil disables the note editor and PUT /api/highlights/:id/note
+//   - HighlightLinkStore: nil disables /api/highlights/:id/links/* endpoints and highlight pages
+//   - QuoteCardStore: nil disables GET /api/highlights/:id/card (covers on cards also need CoverCache)
+//   - PublicLibraryStore: nil disables /public pages and /api/books/:id/public (also needs SettingsStore)
+//   - GraphQLStore: nil disables the /graphql endpoint
+//   - ExportTargetStore: nil (or no ExportTargetScheduler) disables /api/export-targets/* endpoints
 //   - MetadataEnricher: nil disables /api/books/:id/enrich endpoints
+//   - ManualBookStore: nil (or no MetadataEnricher) disables POST /api/books/manual
+//   - MetadataReviewStore: nil disables /api/metadata/reviews/* endpoints and the /metadata/reviews page
 //   - CoverCache: nil disables /api/books/:id/cover endpoint
+//   - UploadStore: nil disables /api/uploads/* chunked upload endpoints
 //   - TaskClient: nil disables /api/tasks/* endpoints
+//   - ReenrichStore: nil (or no TaskClient or MetadataEnricher) disables /api/books/re-enrich endpoints
+//   - SyncLockStore: nil disables /api/admin/syncs/* and /api/admin/locks endpoints
+//   - DemoSeeder: nil disables POST /api/admin/seed-demo
+//   - EventBroker: nil disables the GET /api/events stream and the event simulator
+//   - MoonReaderWebDAVDir: empty disables the /moonreader/webdav share
 type RouterConfig struct {
 	// --- Core Dependencies ---
 
@@ -47,18 +98,117 @@ type RouterConfig struct {
 	// --- Store Interfaces ---
 	// Each store interface enables a feature area. Set to nil to disable.
 
+	// BookDetailsStore loads a book with its vocabulary and related books.
+	BookDetailsStore BookDetailsStore
+
+	// BookHighlightStore pages through a book's highlights in reading order.
+	BookHighlightStore BookHighlightStore
+
+	// BookListStore lists books without their highlights.
+	BookListStore BookListStore
+
+	// BookArchiveStore archives books without deleting them.
+	BookArchiveStore BookArchiveStore
+
+	// BookNoteStore keeps dated journal notes about books.
+	BookNoteStore BookNoteStore
+
+	// CitationStore loads books for BibTeX and CSL-JSON citations.
+	CitationStore CitationStore
+
+	// BookEditStore edits book metadata by hand.
+	BookEditStore BookEditStore
+
 	// TagStore provides tag CRUD operations.
 	TagStore TagStore
 
+	// TagCSVStore lists and applies tag assignments for CSV export and import.
+	TagCSVStore TagCSVStore
+
 	// DeleteStore provides soft/permanent delete operations.
 	DeleteStore DeleteStore
 
-	// FavouritesStore provides highlight favouriting operations.
+	// FavouritesStore provides highlight and book favouriting operations.
 	FavouritesStore FavouritesStore
 
 	// VocabularyStore provides vocabulary word management.
 	VocabularyStore VocabularyStore
 
+	// VocabularyReviewStore schedules spaced repetition reviews of vocabulary words.
+	VocabularyReviewStore VocabularyReviewStore
+
+	// VocabularyMergeStore merges vocabulary words saved in several forms or from several books.
+	VocabularyMergeStore VocabularyMergeStore
+
+	// UpgradeStatusStore lists schema changes and data backfill progress.
+	UpgradeStatusStore UpgradeStatusStore
+
+	// MaintenanceStore runs database maintenance jobs and lists their recorded runs.
+	MaintenanceStore MaintenanceStore
+
+	// TrashStore lists, restores and purges soft-deleted books and highlights.
+	TrashStore TrashStore
+
+	// TombstoneStore lists and removes records of permanently deleted entities that block re-import.
+	TombstoneStore TombstoneStore
+
+	// LibraryDiffStore reports what changed in the library between two dates.
+	LibraryDiffStore LibraryDiffStore
+
+	// LibraryImportStore matches Goodreads/StoryGraph exports to books and applies ratings and shelves.
+	LibraryImportStore LibraryImportStore
+
+	// CaptureStore adds highlights typed in by hand.
+	CaptureStore CaptureStore
+
+	// AuthorStore lists authors with their books and highlights.
+	AuthorStore AuthorStore
+
+	// SeriesStore groups books by series.
+	SeriesStore SeriesStore
+
+	// CollectionStore manages user-defined, ordered lists of books.
+	CollectionStore CollectionStore
+
+	// BookSourceStore counts the highlights each source contributed to a book.
+	BookSourceStore BookSourceStore
+
+	// SavedViewStore saves highlight searches as named smart views.
+	SavedViewStore SavedViewStore
+
+	// UIPreferencesStore keeps each user's theme, page size, sort and view mode.
+	UIPreferencesStore UIPreferencesStore
+
+	// QuickSearchStore searches the full-text index for the command palette.
+	QuickSearchStore QuickSearchStore
+
+	// TrashRetentionDays is shown on the trash page (0 means items are kept until emptied).
+	TrashRetentionDays int
+
+	// HighlightListStore lists highlights with filters and picks random ones.
+	HighlightListStore HighlightListStore
+
+	// HighlightHistoryStore provides highlight edit history, revert and re-import conflicts.
+	HighlightHistoryStore HighlightHistoryStore
+
+	// HighlightDuplicateStore finds and merges duplicate highlights imported from different sources.
+	HighlightDuplicateStore HighlightDuplicateStore
+
+	// NoteStore edits highlight notes from the Markdown note editor.
+	NoteStore NoteStore
+
+	// HighlightLinkStore manages typed links between highlights.
+	HighlightLinkStore HighlightLinkStore
+
+	// QuoteCardStore loads highlights and books for shareable quote card images.
+	QuoteCardStore QuoteCardStore
+
+	// PublicLibraryStore lists the books shared on the read-only public library.
+	PublicLibraryStore PublicLibraryStore
+
+	// GraphQLStore queries books, highlights, tags and vocabulary for /graphql.
+	GraphQLStore GraphQLStore
+
 	// --- Authentication ---
 
 	// ReadwiseToken authenticates Readwise API import requests.
@@ -78,6 +228,15 @@ type RouterConfig struct {
 	// DatabasePath is used by settings controller for its own connection.
 	DatabasePath string
 
+	// --- Reverse Proxy ---
+
+	// TrustedProxies are the proxies whose X-Forwarded-* headers are honoured; empty trusts all.
+	TrustedProxies []netip.Prefix
+
+	// BasePath is the URL prefix the app is served under, e.g. "/highlights"; empty for the root.
+	// The server strips it with BasePathHandler; links and redirects add it back.
+	BasePath string
+
 	// --- MoonReader Configuration ---
 
 	// MoonReaderDropboxPath is the path to MoonReader backup in Dropbox.
@@ -89,25 +248,84 @@ type RouterConfig struct {
 	// MoonReaderOutputDir is the output directory for processed highlights.
 	MoonReaderOutputDir string
 
+	// MoonReaderWebDAVDir is served to Moon+ Reader over WebDAV; uploaded backups are imported.
+	MoonReaderWebDAVDir string
+
 	// --- Metadata Enrichment ---
 
 	// MetadataEnricher enriches books with OpenLibrary data (optional).
 	MetadataEnricher *metadata.Enricher
 
+	// AuthorEnricher looks authors up on Wikidata (optional).
+	AuthorEnricher *metadata.AuthorEnricher
+
+	// MetadataReviewStore lists, accepts and rejects metadata matches held for review.
+	MetadataReviewStore MetadataReviewStore
+
+	// ManualBookStore creates books from an ISBN lookup (requires MetadataEnricher).
+	ManualBookStore ManualBookStore
+
 	// SyncProgress tracks metadata sync progress.
 	SyncProgress *database.MetadataSyncProgress
 
 	// CoverCache caches book cover images (optional).
 	CoverCache *covers.Cache
 
+	// --- OCR ---
+
+	// OCREngine recognizes text in photographed book pages (optional).
+	OCREngine ocr.Engine
+
+	// OCRMaxImageSize is the largest photo accepted for OCR, in bytes.
+	OCRMaxImageSize int64
+
+	// OCRTimeout is the time allowed to recognize one photo.
+	OCRTimeout time.Duration
+
+	// --- Text-to-speech ---
+
+	// PodcastStore loads books, tags and highlights for podcast feeds (optional).
+	PodcastStore PodcastStore
+
+	// PodcastAudio speaks highlights and caches the MP3 files (optional).
+	PodcastAudio *tts.Cache
+
+	// --- Uploads ---
+
+	// UploadStore keeps resumable chunked uploads of large import files (optional).
+	UploadStore *uploads.Store
+
+	// MaxRequestSize caps request bodies of routes without a limit of their own, in bytes (0 for no limit).
+	MaxRequestSize int64
+
+	// MaxJSONImportSize caps JSON import requests such as the Readwise API, in bytes (0 for no limit).
+	MaxJSONImportSize int64
+
+	// MaxBackupSize is the largest Moon+ Reader backup or Apple Books database
+	// accepted without chunked upload, in bytes (0 for the 50 MB default).
+	MaxBackupSize int64
+
 	// --- Background Tasks ---
 
 	// TaskClient provides background task queue (optional).
 	TaskClient *tasks.Client
 
+	// ReenrichStore tracks whole-library re-enrichment runs (requires TaskClient and MetadataEnricher).
+	ReenrichStore ReenrichStore
+
+	// SyncLockStore lists sync owners, force-releases stuck syncs and lists
+	// import/export locks.
+	SyncLockStore SyncLockStore
+
+	// DemoSeeder seeds the demo dataset into an empty library.
+	DemoSeeder DemoSeeder
+
 	// TaskWorkers is the number of concurrent task workers.
 	TaskWorkers int
 
+	// EventBroker publishes import, highlight, task and sync events to live subscribers (optional).
+	EventBroker *events.Broker
+
 	// --- Dictionary ---
 
 	// DictionaryClient provides word definition lookups.
@@ -156,6 +374,15 @@ type RouterConfig struct {
 	// ObsidianSyncScheduler manages periodic Obsidian exports (optional).
 	ObsidianSyncScheduler *scheduler.ObsidianSyncScheduler
 
+	// VaultWatcher syncs notes edited in the exported files back (optional).
+	VaultWatcher *scheduler.VaultWatcher
+
+	// ExportTargetStore manages named export targets (optional).
+	ExportTargetStore ExportTargetStore
+
+	// ExportTargetScheduler runs export targets on demand and on their schedules (optional).
+	ExportTargetScheduler ExportTargetRunner
+
 	// SettingsStore provides access to persistent settings.
 	SettingsStore *settingsstore.SettingsStore
 
@@ -166,4 +393,13 @@ type RouterConfig struct {
 
 	// ReadwiseClient interfaces with the Readwise API (optional).
 	ReadwiseClient *readwise.Client
+
+	// --- Telegram Bot ---
+
+	// TelegramReviewScheduler sends the scheduled daily review (optional).
+	TelegramReviewScheduler *scheduler.TelegramReviewScheduler
+
+	// TelegramClient interfaces with the Telegram Bot API (optional).
+	// nil disables the /settings/telegram endpoints.
+	TelegramClient *telegram.Client
 }
@@ -45,13 +45,14 @@ func (cc *CoversController) GetCover(c *gin.Context) {
 	}
 
 	// Get cached cover (will fetch if not cached)
-	cachePath, err := cc.cache.GetCover(uint(id), book.CoverURL)
-	if err != nil || cachePath == "" {
+	cover, err := cc.cache.OpenCover(book.UserID, uint(id), book.CoverURL)
+	if err != nil || cover == nil {
 		// Fallback: redirect to original URL
 		c.Redirect(http.StatusTemporaryRedirect, book.CoverURL)
 		return
 	}
+	defer cover.Close()
 
-	// Serve the cached file
-	c.File(cachePath)
+	// Serve the cached cover
+	http.ServeContent(c.Writer, c.Request, cover.Name, cover.ModTime, cover)
 }
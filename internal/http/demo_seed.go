@@ -0,0 +1,42 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/demo"
+)
+
+// DemoSeeder fills an empty library with the demo dataset.
+// Implemented by demo.Seeder.
+type DemoSeeder interface {
+	Seed() (demo.SeedResult, error)
+}
+
+// DemoSeedController seeds the demo dataset into a running instance, so the
+// app can be tried out without importing anything first.
+type DemoSeedController struct {
+	seeder DemoSeeder
+}
+
+func NewDemoSeedController(seeder DemoSeeder) *DemoSeedController {
+	return &DemoSeedController{seeder: seeder}
+}
+
+// SeedDemo adds the demo books, highlights, tags and vocabulary. It is refused
+// with 409 once the library has books, so demo data never mixes with real data.
+// POST /api/admin/seed-demo
+func (dc *DemoSeedController) SeedDemo(c *gin.Context) {
+	result, err := dc.seeder.Seed()
+	if errors.Is(err, demo.ErrLibraryNotEmpty) {
+		respondError(c, http.StatusConflict, "the demo dataset can only be seeded into an empty library")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "seed demo dataset")
+		return
+	}
+	respondCreated(c, SuccessResponse{Message: "demo dataset seeded", Data: result})
+}
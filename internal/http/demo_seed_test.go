@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/demo"
+)
+
+func TestDemoSeedController_SeedDemo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dbPath := "./test_demo_seed.db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	router := gin.New()
+	router.POST("/api/admin/seed-demo", NewDemoSeedController(demo.NewSeeder(db)).SeedDemo)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/seed-demo", nil))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"books":`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/seed-demo", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
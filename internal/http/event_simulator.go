@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
+	"github.com/mrlokans/assistant/internal/exporters"
+)
+
+// simulatedEvents builds a sample payload, shaped like the real one, for each
+// event type the simulator can publish.
+var simulatedEvents = map[string]func() any{
+	events.TypeImportCompleted: func() any {
+		return exporters.ImportProgress{
+			Status:       entities.ImportStatusCompleted,
+			TotalBooks:   1,
+			ExportResult: exporters.ExportResult{BooksProcessed: 1, HighlightsProcessed: 2},
+		}
+	},
+	events.TypeHighlightCreated: func() any {
+		return entities.Highlight{
+			Text:          "Simulated highlight to test an event receiver.",
+			Note:          "Published by the event simulator",
+			LocationType:  entities.LocationTypeLocation,
+			LocationValue: 42,
+			HighlightedAt: time.Now(),
+		}
+	},
+}
+
+// EventSimulatorController publishes synthetic events to the live event stream,
+// so integrators can test their receivers without running a real import.
+type EventSimulatorController struct {
+	broker *events.Broker
+}
+
+func NewEventSimulatorController(broker *events.Broker) *EventSimulatorController {
+	return &EventSimulatorController{broker: broker}
+}
+
+type simulateEventRequest struct {
+	Type string          `json:"type" binding:"required"`
+	Data json.RawMessage `json:"data"` // Replaces the sample payload when set
+}
+
+// Simulate publishes one event of the requested type to GET /api/events subscribers.
+// POST /api/admin/events/simulate
+func (sc *EventSimulatorController) Simulate(c *gin.Context) {
+	var req simulateEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "type is required")
+		return
+	}
+
+	sample, ok := simulatedEvents[req.Type]
+	if !ok {
+		respondBadRequest(c, fmt.Sprintf("unsupported event type %q (supported: %s)", req.Type, strings.Join(simulatedEventTypes(), ", ")))
+		return
+	}
+
+	var data any = sample()
+	if len(req.Data) > 0 {
+		data = req.Data
+	}
+	sc.broker.Publish(req.Type, data)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "event published",
+		"type":    req.Type,
+		"data":    data,
+	})
+}
+
+func simulatedEventTypes() []string {
+	types := make([]string, 0, len(simulatedEvents))
+	for eventType := range simulatedEvents {
+		types = append(types, eventType)
+	}
+	slices.Sort(types)
+	return types
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
+	"github.com/mrlokans/assistant/internal/exporters"
+)
+
+func simulateEvent(t *testing.T, broker *events.Broker, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/admin/events/simulate", NewEventSimulatorController(broker).Simulate)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/admin/events/simulate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestEventSimulatorController_Simulate(t *testing.T) {
+	t.Run("publishes sample payloads", func(t *testing.T) {
+		broker := events.NewBroker()
+		ch, unsubscribe := broker.Subscribe()
+		defer unsubscribe()
+
+		w := simulateEvent(t, broker, `{"type": "import.completed"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		event := <-ch
+		assert.Equal(t, events.TypeImportCompleted, event.Type)
+		progress := event.Data.(exporters.ImportProgress)
+		assert.Equal(t, entities.ImportStatusCompleted, progress.Status)
+		assert.Equal(t, 1, progress.BooksProcessed)
+
+		w = simulateEvent(t, broker, `{"type": "highlight.created"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		event = <-ch
+		assert.Equal(t, events.TypeHighlightCreated, event.Type)
+		assert.NotEmpty(t, event.Data.(entities.Highlight).Text)
+		assert.Contains(t, w.Body.String(), `"type":"highlight.created"`)
+	})
+
+	t.Run("custom data replaces the sample", func(t *testing.T) {
+		broker := events.NewBroker()
+		ch, unsubscribe := broker.Subscribe()
+		defer unsubscribe()
+
+		w := simulateEvent(t, broker, `{"type": "highlight.created", "data": {"text": "Custom"}}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		event := <-ch
+		assert.JSONEq(t, `{"text": "Custom"}`, string(event.Data.(json.RawMessage)))
+	})
+
+	t.Run("rejects other event types", func(t *testing.T) {
+		broker := events.NewBroker()
+		w := simulateEvent(t, broker, `{"type": "task.completed"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "highlight.created, import.completed")
+
+		w = simulateEvent(t, broker, `{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
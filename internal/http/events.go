@@ -0,0 +1,83 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/events"
+)
+
+// EventsController streams application events to the browser with
+// Server-Sent Events, so the UI can update without a refresh.
+type EventsController struct {
+	broker    *events.Broker
+	keepAlive time.Duration
+}
+
+func NewEventsController(broker *events.Broker) *EventsController {
+	return &EventsController{broker: broker, keepAlive: 15 * time.Second}
+}
+
+// Stream handles GET /api/events?types=import,task
+// Every published event is sent as an SSE event named after its type, e.g.
+// "import.completed", with the event as JSON data. The optional types
+// parameter keeps only the listed areas: import, highlight, task or sync. A comment is
+// sent periodically so idle connections are not closed by proxies.
+func (ec *EventsController) Stream(c *gin.Context) {
+	var areas []string
+	if types := c.Query("types"); types != "" {
+		for _, area := range strings.Split(types, ",") {
+			if area = strings.TrimSpace(area); area != "" {
+				areas = append(areas, area+".")
+			}
+		}
+	}
+
+	ch, unsubscribe := ec.broker.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable proxy buffering, e.g. nginx
+	fmt.Fprint(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(ec.keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(c.Writer, ": keepalive\n\n")
+			c.Writer.Flush()
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !eventInAreas(event.Type, areas) {
+				continue
+			}
+			c.SSEvent(event.Type, event)
+			c.Writer.Flush()
+		}
+	}
+}
+
+// eventInAreas reports whether an event type is in one of the area prefixes,
+// or any area when none are given
+func eventInAreas(eventType string, areas []string) bool {
+	if len(areas) == 0 {
+		return true
+	}
+	for _, area := range areas {
+		if strings.HasPrefix(eventType, area) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/events"
+)
+
+// streamEvents requests the events stream, publishes while it is connected and
+// returns what was streamed
+func streamEvents(t *testing.T, query string, publish func(broker *events.Broker)) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	broker := events.NewBroker()
+	router := gin.New()
+	router.GET("/api/events", NewEventsController(broker).Stream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/api/events"+query, nil)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.ServeHTTP(w, req)
+	}()
+
+	require.Eventually(t, broker.HasSubscribers, time.Second, 5*time.Millisecond)
+	publish(broker)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+	return w
+}
+
+func TestEventsController_Stream(t *testing.T) {
+	t.Run("streams published events", func(t *testing.T) {
+		w := streamEvents(t, "", func(broker *events.Broker) {
+			broker.Publish(events.TypeImportCompleted, map[string]any{"session_id": 3, "status": "completed"})
+			broker.Publish(events.TypeTaskCompleted, map[string]string{"queue": "enrich_book"})
+		})
+
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		assert.Contains(t, body, "event:import.completed\n")
+		assert.Contains(t, body, `"session_id":3`)
+		assert.Contains(t, body, "event:task.completed\n")
+		assert.Less(t, strings.Index(body, "event:import.completed"), strings.Index(body, "event:task.completed"))
+	})
+
+	t.Run("filters by area", func(t *testing.T) {
+		w := streamEvents(t, "?types=sync,import", func(broker *events.Broker) {
+			broker.Publish(events.TypeTaskFailed, nil)
+			broker.Publish(events.TypeSyncCompleted, map[string]string{"sync": "readwise_sync"})
+		})
+
+		body := w.Body.String()
+		assert.NotContains(t, body, "task.failed")
+		assert.Contains(t, body, "event:sync.completed\n")
+	})
+}
@@ -0,0 +1,251 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/scheduler"
+)
+
+// ExportTargetStore defines database operations for named export targets.
+type ExportTargetStore interface {
+	ListExportTargets() ([]entities.ExportTarget, error)
+	GetExportTarget(id uint) (*entities.ExportTarget, error)
+	GetExportTargetByName(name string) (*entities.ExportTarget, error)
+	CreateExportTarget(target *entities.ExportTarget) error
+	UpdateExportTarget(target *entities.ExportTarget) error
+	DeleteExportTarget(id uint) error
+}
+
+// ExportTargetRunner runs export targets and keeps their schedules up to date.
+// Implemented by scheduler.ExportTargetScheduler.
+type ExportTargetRunner interface {
+	RunTarget(id uint) (scheduler.ExportTargetRun, error)
+	RunAll() ([]scheduler.ExportTargetRun, error)
+	Reschedule() error
+	ValidateSchedule(schedule string) error
+	GetNextRunTime(targetID uint) *time.Time
+}
+
+// ExportTargetRequest is the body of create and update requests
+type ExportTargetRequest struct {
+	Name              string     `json:"name"`
+	Path              string     `json:"path"`
+	Format            string     `json:"format"`
+	FavoritesOnly     bool       `json:"favorites_only"`
+	Tags              []string   `json:"tags"`
+	Collections       []string   `json:"collections"`
+	Sources           []string   `json:"sources"`
+	Since             *time.Time `json:"since"`
+	IncludeVocabulary bool       `json:"include_vocabulary"`
+	Schedule          string     `json:"schedule"`
+}
+
+// ExportTargetResponse is an export target with its next scheduled run
+type ExportTargetResponse struct {
+	entities.ExportTarget
+	NextRun *time.Time `json:"next_run,omitempty"`
+}
+
+type ExportTargetsController struct {
+	store  ExportTargetStore
+	runner ExportTargetRunner
+}
+
+func NewExportTargetsController(store ExportTargetStore, runner ExportTargetRunner) *ExportTargetsController {
+	return &ExportTargetsController{store: store, runner: runner}
+}
+
+func (ec *ExportTargetsController) response(target entities.ExportTarget) ExportTargetResponse {
+	return ExportTargetResponse{ExportTarget: target, NextRun: ec.runner.GetNextRunTime(target.ID)}
+}
+
+// ListTargets returns all export targets.
+// GET /api/export-targets
+func (ec *ExportTargetsController) ListTargets(c *gin.Context) {
+	targets, err := ec.store.ListExportTargets()
+	if err != nil {
+		respondInternalError(c, err, "list export targets")
+		return
+	}
+
+	responses := make([]ExportTargetResponse, 0, len(targets))
+	for _, target := range targets {
+		responses = append(responses, ec.response(target))
+	}
+	c.JSON(http.StatusOK, gin.H{"targets": responses})
+}
+
+// GetTarget returns one export target.
+// GET /api/export-targets/:id
+func (ec *ExportTargetsController) GetTarget(c *gin.Context) {
+	target, ok := ec.loadTarget(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, ec.response(*target))
+}
+
+// CreateTarget adds an export target.
+// POST /api/export-targets
+func (ec *ExportTargetsController) CreateTarget(c *gin.Context) {
+	var req ExportTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "invalid request body")
+		return
+	}
+
+	target := &entities.ExportTarget{}
+	applyExportTargetRequest(target, req)
+	if !ec.validate(c, target) {
+		return
+	}
+
+	if err := ec.store.CreateExportTarget(target); err != nil {
+		respondInternalError(c, err, "create export target")
+		return
+	}
+	ec.reschedule()
+	respondCreated(c, ec.response(*target))
+}
+
+// UpdateTarget replaces the configuration of an export target.
+// PUT /api/export-targets/:id
+func (ec *ExportTargetsController) UpdateTarget(c *gin.Context) {
+	target, ok := ec.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	var req ExportTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "invalid request body")
+		return
+	}
+
+	applyExportTargetRequest(target, req)
+	if !ec.validate(c, target) {
+		return
+	}
+
+	if err := ec.store.UpdateExportTarget(target); err != nil {
+		respondInternalError(c, err, "update export target")
+		return
+	}
+	ec.reschedule()
+	c.JSON(http.StatusOK, ec.response(*target))
+}
+
+// DeleteTarget removes an export target. Files it already wrote are kept.
+// DELETE /api/export-targets/:id
+func (ec *ExportTargetsController) DeleteTarget(c *gin.Context) {
+	target, ok := ec.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := ec.store.DeleteExportTarget(target.ID); err != nil {
+		respondInternalError(c, err, "delete export target")
+		return
+	}
+	ec.reschedule()
+	respondSuccess(c, "Export target deleted")
+}
+
+// RunTarget exports to one target now.
+// POST /api/export-targets/:id/run
+func (ec *ExportTargetsController) RunTarget(c *gin.Context) {
+	target, ok := ec.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	run, err := ec.runner.RunTarget(target.ID)
+	if err != nil {
+		respondInternalError(c, err, "run export target")
+		return
+	}
+	if run.Error != "" {
+		c.JSON(http.StatusUnprocessableEntity, run)
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// RunAll exports to every target now.
+// POST /api/export-targets/run
+func (ec *ExportTargetsController) RunAll(c *gin.Context) {
+	runs, err := ec.runner.RunAll()
+	if err != nil {
+		respondInternalError(c, err, "run export targets")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+func (ec *ExportTargetsController) loadTarget(c *gin.Context) (*entities.ExportTarget, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	target, err := ec.store.GetExportTarget(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "export target")
+		return nil, false
+	}
+	if err != nil {
+		respondInternalError(c, err, "get export target")
+		return nil, false
+	}
+	return target, true
+}
+
+// validate checks the target and that no other target has its name
+func (ec *ExportTargetsController) validate(c *gin.Context, target *entities.ExportTarget) bool {
+	if err := exporters.ValidateTarget(target); err != nil {
+		respondBadRequest(c, err.Error())
+		return false
+	}
+	if err := ec.runner.ValidateSchedule(target.Schedule); err != nil {
+		respondBadRequest(c, "invalid cron schedule: "+err.Error())
+		return false
+	}
+
+	existing, err := ec.store.GetExportTargetByName(target.Name)
+	if err == nil && existing.ID != target.ID {
+		respondError(c, http.StatusConflict, "an export target named "+target.Name+" already exists")
+		return false
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		respondInternalError(c, err, "check export target name")
+		return false
+	}
+	return true
+}
+
+func (ec *ExportTargetsController) reschedule() {
+	if err := ec.runner.Reschedule(); err != nil {
+		log.Printf("Failed to reschedule export targets: %v", err)
+	}
+}
+
+func applyExportTargetRequest(target *entities.ExportTarget, req ExportTargetRequest) {
+	target.Name = req.Name
+	target.Path = req.Path
+	target.Format = req.Format
+	target.FavoritesOnly = req.FavoritesOnly
+	target.Tags = req.Tags
+	target.Collections = req.Collections
+	target.Sources = req.Sources
+	target.Since = req.Since
+	target.IncludeVocabulary = req.IncludeVocabulary
+	target.Schedule = req.Schedule
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupExportTargetsRouter(t *testing.T) (*gin.Engine, *database.Database) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dbPath := "./test_export_targets_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(dbPath)
+	})
+
+	sched := scheduler.NewExportTargetScheduler(db, nil)
+	t.Cleanup(sched.Stop)
+
+	controller := NewExportTargetsController(db, sched)
+	router := gin.New()
+	router.GET("/api/export-targets", controller.ListTargets)
+	router.POST("/api/export-targets", controller.CreateTarget)
+	router.POST("/api/export-targets/run", controller.RunAll)
+	router.GET("/api/export-targets/:id", controller.GetTarget)
+	router.PUT("/api/export-targets/:id", controller.UpdateTarget)
+	router.DELETE("/api/export-targets/:id", controller.DeleteTarget)
+	router.POST("/api/export-targets/:id/run", controller.RunTarget)
+	return router, db
+}
+
+func sendExportTargetRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExportTargetsController(t *testing.T) {
+	t.Run("creates, updates and schedules targets", func(t *testing.T) {
+		router, _ := setupExportTargetsRouter(t)
+
+		w := sendExportTargetRequest(router, "POST", "/api/export-targets",
+			`{"name":"vault","path":"/vault","tags":["stoic"],"schedule":"0 * * * *"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var created ExportTargetResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		assert.Equal(t, "markdown", created.Format)
+		assert.Equal(t, []string{"stoic"}, created.Tags)
+		assert.NotNil(t, created.NextRun, "scheduled targets report their next run")
+
+		w = sendExportTargetRequest(router, "POST", "/api/export-targets", `{"name":"vault","path":"/other"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = sendExportTargetRequest(router, "PUT", "/api/export-targets/"+jsonID(created.ID),
+			`{"name":"vault","path":"/vault","format":"org"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var updated ExportTargetResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+		assert.Equal(t, "org", updated.Format)
+		assert.Nil(t, updated.NextRun, "clearing the schedule unschedules the target")
+
+		w = sendExportTargetRequest(router, "GET", "/api/export-targets", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"vault"`)
+	})
+
+	t.Run("rejects invalid targets", func(t *testing.T) {
+		router, _ := setupExportTargetsRouter(t)
+
+		for _, body := range []string{
+			`{"path":"/vault"}`,
+			`{"name":"vault","path":"/vault","format":"docx"}`,
+			`{"name":"vault","path":"/vault","schedule":"every day"}`,
+		} {
+			w := sendExportTargetRequest(router, "POST", "/api/export-targets", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+
+		w := sendExportTargetRequest(router, "GET", "/api/export-targets/42", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("runs targets individually and together", func(t *testing.T) {
+		router, db := setupExportTargetsRouter(t)
+		require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune", Author: "Frank Herbert", Highlights: []entities.Highlight{
+			{Text: "Fear is the mind-killer.", IsFavorite: true},
+			{Text: "The spice must flow."},
+		}}))
+
+		dir := t.TempDir()
+		favourites := &entities.ExportTarget{Name: "favourites", Path: dir, Format: "logseq", FavoritesOnly: true}
+		require.NoError(t, db.CreateExportTarget(favourites))
+		require.NoError(t, db.CreateExportTarget(&entities.ExportTarget{Name: "missing", Path: filepath.Join(dir, "missing"), Format: "markdown"}))
+
+		w := sendExportTargetRequest(router, "POST", "/api/export-targets/"+jsonID(favourites.ID)+"/run", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var run scheduler.ExportTargetRun
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+		assert.Equal(t, 1, run.Result.HighlightsProcessed)
+
+		page, err := os.ReadFile(filepath.Join(dir, "pages", "Dune.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(page), "Fear is the mind-killer.")
+		assert.NotContains(t, string(page), "The spice must flow.")
+
+		saved, err := db.GetExportTarget(favourites.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entities.ExportTargetStatusSuccess, saved.LastStatus)
+
+		w = sendExportTargetRequest(router, "POST", "/api/export-targets/run", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var all struct {
+			Runs []scheduler.ExportTargetRun `json:"runs"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &all))
+		require.Len(t, all.Runs, 2)
+		assert.Empty(t, all.Runs[0].Error)
+		assert.Contains(t, all.Runs[1].Error, "does not exist")
+	})
+}
+
+func jsonID(id uint) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
@@ -1,10 +1,13 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
 	"github.com/mrlokans/assistant/internal/entities"
 )
 
@@ -15,6 +18,9 @@ type FavouritesStore interface {
 	GetFavouriteHighlightsByBook(bookID uint) ([]entities.Highlight, error)
 	GetFavouriteCount(userID uint) (int64, error)
 	GetHighlightByID(id uint) (*entities.Highlight, error)
+	SetBookFavourite(bookID uint, isFavourite bool) error
+	GetFavouriteBooks(userID uint) ([]entities.Book, error)
+	GetBookByID(id uint) (*entities.Book, error)
 }
 
 type FavouritesController struct {
@@ -79,6 +85,65 @@ func (fc *FavouritesController) RemoveFavourite(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "favourite removed", "highlight": highlight})
 }
 
+// AddBookFavourite pins a book to the top of the library.
+// POST /api/books/:id/favourite
+func (fc *FavouritesController) AddBookFavourite(c *gin.Context) {
+	fc.setBookFavourite(c, true)
+}
+
+// RemoveBookFavourite unpins a book.
+// DELETE /api/books/:id/favourite
+func (fc *FavouritesController) RemoveBookFavourite(c *gin.Context) {
+	fc.setBookFavourite(c, false)
+}
+
+func (fc *FavouritesController) setBookFavourite(c *gin.Context, isFavourite bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := fc.store.SetBookFavourite(id, isFavourite)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "book")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "set book favourite")
+		return
+	}
+
+	message := "favourite added"
+	if !isFavourite {
+		message = "favourite removed"
+	}
+
+	book, err := fc.store.GetBookByID(id)
+	if err != nil {
+		respondSuccess(c, message)
+		return
+	}
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "book-favourite-button", book)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message, "book": book})
+}
+
+// ListFavouriteBooks returns the favourite books by title.
+// GET /api/books/favourites
+func (fc *FavouritesController) ListFavouriteBooks(c *gin.Context) {
+	books, err := fc.store.GetFavouriteBooks(DefaultUserID)
+	if err != nil {
+		respondInternalError(c, err, "list favourite books")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"books": books, "count": len(books)})
+}
+
 // ListFavourites returns all favourite highlights with pagination.
 // GET /api/highlights/favourites
 func (fc *FavouritesController) ListFavourites(c *gin.Context) {
@@ -144,12 +209,20 @@ func (fc *FavouritesController) FavouritesPage(c *gin.Context) {
 		return
 	}
 
+	books, err := fc.store.GetFavouriteBooks(DefaultUserID)
+	if err != nil {
+		respondInternalError(c, err, "load favourites page")
+		return
+	}
+
 	c.HTML(http.StatusOK, "favourites", gin.H{
+		"Books":      books,
 		"Highlights": highlights,
 		"Total":      total,
 		"Limit":      100,
 		"Offset":     0,
 		"Auth":       GetAuthTemplateData(c),
+		"UI":         GetUIPreferences(c),
 		"Demo":       GetDemoTemplateData(c),
 		"Analytics":  GetAnalyticsTemplateData(c),
 	})
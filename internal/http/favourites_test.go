@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -237,3 +238,82 @@ func TestFavouritesController_GetFavouriteCount(t *testing.T) {
 		assert.Equal(t, int64(2), response.Count)
 	})
 }
+
+func TestFavouritesController_BookFavourites(t *testing.T) {
+	db, cleanup := setupFavouritesTestDB(t)
+	defer cleanup()
+
+	walden := &entities.Book{Title: "Walden", Author: "Henry David Thoreau"}
+	meditations := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius"}
+	require.NoError(t, db.SaveBook(walden))
+	require.NoError(t, db.SaveBook(meditations))
+
+	controller := NewFavouritesController(db)
+	router := gin.New()
+	router.POST("/api/books/:id/favourite", controller.AddBookFavourite)
+	router.DELETE("/api/books/:id/favourite", controller.RemoveBookFavourite)
+	router.GET("/api/books/favourites", controller.ListFavouriteBooks)
+	router.GET("/api/books", NewBooksController(db).GetAllBooks)
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(http.MethodPost, fmt.Sprintf("/api/books/%d/favourite", walden.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var added struct {
+		Book entities.Book `json:"book"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &added))
+	assert.True(t, added.Book.IsFavorite)
+
+	w = send(http.MethodGet, "/api/books/favourites")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Books []entities.Book `json:"books"`
+		Count int             `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, 1, list.Count)
+	assert.Equal(t, "Walden", list.Books[0].Title)
+
+	w = send(http.MethodGet, "/api/books?favourite=false")
+	require.Equal(t, http.StatusOK, w.Code)
+	list.Books = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Books, 1)
+	assert.Equal(t, "Meditations", list.Books[0].Title)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodGet, "/api/books?favourite=maybe").Code)
+
+	// Re-importing the book keeps it pinned
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Walden", Author: "Henry David Thoreau"}))
+	book, err := db.GetBookByID(walden.ID)
+	require.NoError(t, err)
+	assert.True(t, book.IsFavorite)
+
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, fmt.Sprintf("/api/books/%d/favourite", walden.ID)).Code)
+	book, err = db.GetBookByID(walden.ID)
+	require.NoError(t, err)
+	assert.False(t, book.IsFavorite)
+
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/api/books/999/favourite").Code)
+}
+
+func TestFavouritesFirst(t *testing.T) {
+	books := []entities.Book{
+		{Title: "A"},
+		{Title: "B", IsFavorite: true},
+		{Title: "C"},
+		{Title: "D", IsFavorite: true},
+	}
+	favouritesFirst(books)
+
+	var titles []string
+	for _, book := range books {
+		titles = append(titles, book.Title)
+	}
+	assert.Equal(t, []string{"B", "D", "A", "C"}, titles)
+}
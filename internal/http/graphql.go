@@ -0,0 +1,376 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/graphql"
+)
+
+// GraphQL list limits: lists return graphqlDefaultLimit items unless a limit is
+// given, and never more than graphqlMaxLimit.
+const (
+	graphqlDefaultLimit = 50
+	graphqlMaxLimit     = 500
+)
+
+// GraphQLStore defines database operations for the GraphQL endpoint. The batch
+// loaders fetch a nested field for every parent in the response at once.
+type GraphQLStore interface {
+	ListBooks(userID uint, search string, limit, offset int) ([]entities.Book, error)
+	GetBookByID(id uint) (*entities.Book, error)
+	ListHighlights(filter entities.HighlightFilter, limit, offset int) ([]entities.Highlight, int64, error)
+	GetHighlightByID(id uint) (*entities.Highlight, error)
+	GetTagsForUser(userID uint) ([]entities.Tag, error)
+	GetAllWords(userID uint, limit, offset int) ([]entities.Word, int64, error)
+	GetWordsByStatus(userID uint, status entities.WordStatus, limit, offset int) ([]entities.Word, int64, error)
+
+	GetBooksByIDs(ids []uint) (map[uint]entities.Book, error)
+	GetHighlightsByBookIDs(bookIDs []uint, limit int) (map[uint][]entities.Highlight, error)
+	GetWordsByBookIDs(bookIDs []uint) (map[uint][]entities.Word, error)
+	GetWordsByHighlightIDs(highlightIDs []uint) (map[uint][]entities.Word, error)
+}
+
+type GraphQLController struct {
+	schema *graphql.Schema
+}
+
+func NewGraphQLController(store GraphQLStore) *GraphQLController {
+	return &GraphQLController{schema: newGraphQLSchema(store)}
+}
+
+// Query executes a GraphQL query. Field errors are returned next to the data with
+// status 200; a query that cannot be executed at all gets status 400.
+// POST /graphql {"query": "...", "operationName": "...", "variables": {...}}
+// GET /graphql?query=&operationName=&variables=
+func (gc *GraphQLController) Query(c *gin.Context) {
+	var req graphql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				respondBadRequest(c, "variables must be a JSON object")
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body")
+		return
+	}
+	if req.Query == "" {
+		respondBadRequest(c, "query is required")
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), graphqlUserKey{}, GetUserID(c))
+	resp := gc.schema.Execute(ctx, req)
+
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, resp)
+}
+
+// graphqlUserKey carries the requesting user's ID to the resolvers.
+type graphqlUserKey struct{}
+
+// graphqlUser returns the requesting user's ID, 0 when authentication is off.
+func graphqlUser(ctx context.Context) uint {
+	userID, _ := ctx.Value(graphqlUserKey{}).(uint)
+	return userID
+}
+
+// ownedBy reports whether a record of ownerID is visible to the requesting user.
+func ownedBy(ctx context.Context, ownerID uint) bool {
+	userID := graphqlUser(ctx)
+	return userID == 0 || userID == ownerID
+}
+
+func graphqlLimit(args graphql.Args) int {
+	return min(max(args.Int("limit", graphqlDefaultLimit), 1), graphqlMaxLimit)
+}
+
+func graphqlOffset(args graphql.Args) int {
+	return max(args.Int("offset", 0), 0)
+}
+
+// property returns a field resolved from the parent object alone.
+func property[T any](t graphql.Type, get func(*T) any) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(_ context.Context, source any, _ graphql.Args) (any, error) {
+		return get(source.(*T)), nil
+	}}
+}
+
+// parentIDs returns the IDs of the parent objects of a batch.
+func parentIDs[T any](sources []any, id func(*T) uint) []uint {
+	ids := make([]uint, len(sources))
+	for i, source := range sources {
+		ids[i] = id(source.(*T))
+	}
+	return ids
+}
+
+// orEmpty keeps lists without items from resolving to null.
+func orEmpty[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func newGraphQLSchema(store GraphQLStore) *graphql.Schema {
+	tag := &graphql.Object{Name: "Tag", Fields: map[string]*graphql.Field{
+		"id":   property(graphql.ID, func(t *entities.Tag) any { return t.ID }),
+		"name": property(graphql.String, func(t *entities.Tag) any { return t.Name }),
+	}}
+
+	definition := &graphql.Object{Name: "Definition", Fields: map[string]*graphql.Field{
+		"partOfSpeech":  property(graphql.String, func(d *entities.WordDefinition) any { return d.PartOfSpeech }),
+		"definition":    property(graphql.String, func(d *entities.WordDefinition) any { return d.Definition }),
+		"example":       property(graphql.String, func(d *entities.WordDefinition) any { return d.Example }),
+		"pronunciation": property(graphql.String, func(d *entities.WordDefinition) any { return d.Pronunciation }),
+		"source":        property(graphql.String, func(d *entities.WordDefinition) any { return d.Source }),
+		"attribution":   property(graphql.String, func(d *entities.WordDefinition) any { return d.Attribution }),
+		"license":       property(graphql.String, func(d *entities.WordDefinition) any { return d.License }),
+		"licenseUrl":    property(graphql.String, func(d *entities.WordDefinition) any { return d.LicenseURL }),
+		"sourceUrl":     property(graphql.String, func(d *entities.WordDefinition) any { return d.SourceURL }),
+	}}
+
+	book := &graphql.Object{Name: "Book", Fields: map[string]*graphql.Field{}}
+	highlight := &graphql.Object{Name: "Highlight", Fields: map[string]*graphql.Field{}}
+	word := &graphql.Object{Name: "Word", Fields: map[string]*graphql.Field{}}
+
+	// loadBooks resolves the book of each parent, using the preloaded book when
+	// the parent has one.
+	loadBooks := func(sources []any, preloaded func(source any) *entities.Book, bookID func(source any) uint) ([]any, error) {
+		var missing []uint
+		for _, source := range sources {
+			if preloaded(source) == nil && bookID(source) != 0 {
+				missing = append(missing, bookID(source))
+			}
+		}
+		books, err := store.GetBooksByIDs(missing)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]any, len(sources))
+		for i, source := range sources {
+			if b := preloaded(source); b != nil {
+				values[i] = b
+			} else if b, ok := books[bookID(source)]; ok {
+				values[i] = b
+			}
+		}
+		return values, nil
+	}
+
+	book.Fields = map[string]*graphql.Field{
+		"id":              property(graphql.ID, func(b *entities.Book) any { return b.ID }),
+		"title":           property(graphql.String, func(b *entities.Book) any { return b.Title }),
+		"author":          property(graphql.String, func(b *entities.Book) any { return b.Author }),
+		"isbn":            property(graphql.String, func(b *entities.Book) any { return b.ISBN }),
+		"isbn10":          property(graphql.String, func(b *entities.Book) any { return b.ISBN10 }),
+		"publisher":       property(graphql.String, func(b *entities.Book) any { return b.Publisher }),
+		"publicationYear": property(graphql.Int, func(b *entities.Book) any { return b.PublicationYear }),
+		"rating":          property(graphql.Float, func(b *entities.Book) any { return b.Rating }),
+		"language":        property(graphql.String, func(b *entities.Book) any { return b.Language }),
+		"dateRead":        property(graphql.DateTime, func(b *entities.Book) any { return b.DateRead }),
+		"coverUrl":        property(graphql.String, func(b *entities.Book) any { return b.CoverURL }),
+		"source":          property(graphql.String, func(b *entities.Book) any { return b.Source.Name }),
+		"createdAt":       property(graphql.DateTime, func(b *entities.Book) any { return b.CreatedAt }),
+		"tags":            property(graphql.ListOf(tag), func(b *entities.Book) any { return orEmpty(b.Tags) }),
+		"highlightCount":  property(graphql.Int, func(b *entities.Book) any { return b.HighlightCount }),
+		"highlights": {
+			Type:        graphql.ListOf(highlight),
+			Description: "Highlights in reading order, optionally only the first limit ones",
+			Args:        map[string]graphql.Type{"limit": graphql.Int},
+			ListSize:    100,
+			Batch: func(_ context.Context, sources []any, args graphql.Args) ([]any, error) {
+				values := make([]any, len(sources))
+				limit := args.Int("limit", 0)
+				if args.Has("limit") && limit <= 0 {
+					for i := range sources {
+						values[i] = []entities.Highlight{}
+					}
+					return values, nil
+				}
+
+				highlights, err := store.GetHighlightsByBookIDs(parentIDs(sources, func(b *entities.Book) uint { return b.ID }), limit)
+				if err != nil {
+					return nil, err
+				}
+				for i, source := range sources {
+					values[i] = orEmpty(highlights[source.(*entities.Book).ID])
+				}
+				return values, nil
+			},
+		},
+		"vocabulary": {
+			Type: graphql.ListOf(word),
+			Batch: func(_ context.Context, sources []any, _ graphql.Args) ([]any, error) {
+				words, err := store.GetWordsByBookIDs(parentIDs(sources, func(b *entities.Book) uint { return b.ID }))
+				if err != nil {
+					return nil, err
+				}
+				values := make([]any, len(sources))
+				for i, source := range sources {
+					values[i] = orEmpty(words[source.(*entities.Book).ID])
+				}
+				return values, nil
+			},
+		},
+	}
+
+	highlight.Fields = map[string]*graphql.Field{
+		"id":            property(graphql.ID, func(h *entities.Highlight) any { return h.ID }),
+		"text":          property(graphql.String, func(h *entities.Highlight) any { return h.Text }),
+		"note":          property(graphql.String, func(h *entities.Highlight) any { return h.Note }),
+		"chapter":       property(graphql.String, func(h *entities.Highlight) any { return h.Chapter }),
+		"location":      property(graphql.Int, func(h *entities.Highlight) any { return h.LocationValue }),
+		"color":         property(graphql.String, func(h *entities.Highlight) any { return h.Color }),
+		"favourite":     property(graphql.Boolean, func(h *entities.Highlight) any { return h.IsFavorite }),
+		"highlightedAt": property(graphql.DateTime, func(h *entities.Highlight) any { return h.HighlightedAt }),
+		"tags":          property(graphql.ListOf(tag), func(h *entities.Highlight) any { return orEmpty(h.Tags) }),
+		"book": {
+			Type: book,
+			Batch: func(_ context.Context, sources []any, _ graphql.Args) ([]any, error) {
+				return loadBooks(sources,
+					func(source any) *entities.Book {
+						if h := source.(*entities.Highlight); h.Book.ID != 0 {
+							return &h.Book
+						}
+						return nil
+					},
+					func(source any) uint { return source.(*entities.Highlight).BookID })
+			},
+		},
+		"words": {
+			Type: graphql.ListOf(word),
+			Batch: func(_ context.Context, sources []any, _ graphql.Args) ([]any, error) {
+				words, err := store.GetWordsByHighlightIDs(parentIDs(sources, func(h *entities.Highlight) uint { return h.ID }))
+				if err != nil {
+					return nil, err
+				}
+				values := make([]any, len(sources))
+				for i, source := range sources {
+					values[i] = orEmpty(words[source.(*entities.Highlight).ID])
+				}
+				return values, nil
+			},
+		},
+	}
+
+	word.Fields = map[string]*graphql.Field{
+		"id":          property(graphql.ID, func(w *entities.Word) any { return w.ID }),
+		"word":        property(graphql.String, func(w *entities.Word) any { return w.Word }),
+		"status":      property(graphql.String, func(w *entities.Word) any { return string(w.Status) }),
+		"context":     property(graphql.String, func(w *entities.Word) any { return w.Context }),
+		"difficulty":  property(graphql.Int, func(w *entities.Word) any { return w.Difficulty }),
+		"createdAt":   property(graphql.DateTime, func(w *entities.Word) any { return w.CreatedAt }),
+		"definitions": property(graphql.ListOf(definition), func(w *entities.Word) any { return orEmpty(w.Definitions) }),
+		"book": {
+			Type: book,
+			Batch: func(_ context.Context, sources []any, _ graphql.Args) ([]any, error) {
+				return loadBooks(sources,
+					func(source any) *entities.Book { return source.(*entities.Word).Book },
+					func(source any) uint {
+						if w := source.(*entities.Word); w.BookID != nil {
+							return *w.BookID
+						}
+						return 0
+					})
+			},
+		},
+	}
+
+	query := &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+		"books": {
+			Type:     graphql.ListOf(book),
+			Args:     map[string]graphql.Type{"search": graphql.String, "limit": graphql.Int, "offset": graphql.Int},
+			ListSize: graphqlDefaultLimit,
+			Resolve: func(ctx context.Context, _ any, args graphql.Args) (any, error) {
+				return store.ListBooks(graphqlUser(ctx), args.String("search"), graphqlLimit(args), graphqlOffset(args))
+			},
+		},
+		"book": {
+			Type: book,
+			Args: map[string]graphql.Type{"id": graphql.ID},
+			Resolve: func(ctx context.Context, _ any, args graphql.Args) (any, error) {
+				b, err := store.GetBookByID(args.ID("id"))
+				if err != nil || !ownedBy(ctx, b.UserID) {
+					return nil, nil
+				}
+				return b, nil
+			},
+		},
+		"highlights": {
+			Type: graphql.ListOf(highlight),
+			Args: map[string]graphql.Type{
+				"bookId":    graphql.ID,
+				"tags":      graphql.ListOf(graphql.ID),
+				"source":    graphql.String,
+				"favourite": graphql.Boolean,
+				"hasNote":   graphql.Boolean,
+				"from":      graphql.DateTime,
+				"to":        graphql.DateTime,
+				"limit":     graphql.Int,
+				"offset":    graphql.Int,
+			},
+			ListSize: graphqlDefaultLimit,
+			Resolve: func(ctx context.Context, _ any, args graphql.Args) (any, error) {
+				filter := entities.HighlightFilter{
+					UserID:    graphqlUser(ctx),
+					BookID:    args.ID("bookId"),
+					Source:    args.String("source"),
+					TagIDs:    args.IDs("tags"),
+					Favourite: args.Bool("favourite"),
+					HasNote:   args.Bool("hasNote"),
+					From:      args.Time("from"),
+					To:        args.Time("to"),
+				}
+				highlights, _, err := store.ListHighlights(filter, graphqlLimit(args), graphqlOffset(args))
+				return highlights, err
+			},
+		},
+		"highlight": {
+			Type: highlight,
+			Args: map[string]graphql.Type{"id": graphql.ID},
+			Resolve: func(ctx context.Context, _ any, args graphql.Args) (any, error) {
+				h, err := store.GetHighlightByID(args.ID("id"))
+				if err != nil || !ownedBy(ctx, h.UserID) {
+					return nil, nil
+				}
+				return h, nil
+			},
+		},
+		"tags": {
+			Type: graphql.ListOf(tag),
+			Resolve: func(ctx context.Context, _ any, _ graphql.Args) (any, error) {
+				return store.GetTagsForUser(graphqlUser(ctx))
+			},
+		},
+		"vocabulary": {
+			Type:     graphql.ListOf(word),
+			Args:     map[string]graphql.Type{"status": graphql.String, "limit": graphql.Int, "offset": graphql.Int},
+			ListSize: graphqlDefaultLimit,
+			Resolve: func(ctx context.Context, _ any, args graphql.Args) (any, error) {
+				var words []entities.Word
+				var err error
+				if status := args.String("status"); status != "" {
+					words, _, err = store.GetWordsByStatus(graphqlUser(ctx), entities.WordStatus(status), graphqlLimit(args), graphqlOffset(args))
+				} else {
+					words, _, err = store.GetAllWords(graphqlUser(ctx), graphqlLimit(args), graphqlOffset(args))
+				}
+				return words, err
+			},
+		},
+	}}
+
+	return &graphql.Schema{Query: query}
+}
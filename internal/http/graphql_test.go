@@ -0,0 +1,99 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func setupGraphQLTest(t *testing.T) (*gin.Engine, *database.Database) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	dbPath := "./test_graphql_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(dbPath)
+	})
+
+	controller := NewGraphQLController(db)
+	router := gin.New()
+	router.GET("/graphql", controller.Query)
+	router.POST("/graphql", controller.Query)
+	return router, db
+}
+
+func TestGraphQLController_Query(t *testing.T) {
+	router, db := setupGraphQLTest(t)
+
+	dune := &entities.Book{Title: "Dune", Author: "Frank Herbert", Highlights: []entities.Highlight{
+		{Text: "Fear is the mind-killer", LocationValue: 1},
+		{Text: "The spice must flow", LocationValue: 2},
+	}}
+	emma := &entities.Book{Title: "Emma", Author: "Jane Austen", Highlights: []entities.Highlight{
+		{Text: "Silly things do cease to be silly", LocationValue: 1},
+	}}
+	require.NoError(t, db.SaveBook(dune))
+	require.NoError(t, db.SaveBook(emma))
+	require.NoError(t, db.SetHighlightFavourite(dune.Highlights[1].ID, true))
+	require.NoError(t, db.AddWord(&entities.Word{Word: "litany", BookID: &dune.ID, Status: entities.WordStatusEnriched}))
+
+	body, err := json.Marshal(map[string]any{
+		"query": `query Library($first: Int) {
+			books { title highlightCount highlights(limit: $first) { text } vocabulary { word } }
+			favourites: highlights(favourite: true) { text book { title } }
+		}`,
+		"variables": map[string]any{"first": 1},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {
+		"books": [
+			{"title": "Dune", "highlightCount": 2, "highlights": [{"text": "Fear is the mind-killer"}], "vocabulary": [{"word": "litany"}]},
+			{"title": "Emma", "highlightCount": 1, "highlights": [{"text": "Silly things do cease to be silly"}], "vocabulary": []}
+		],
+		"favourites": [{"text": "The spice must flow", "book": {"title": "Dune"}}]
+	}}`, w.Body.String())
+}
+
+func TestGraphQLController_GetAndErrors(t *testing.T) {
+	router, db := setupGraphQLTest(t)
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune", Author: "Frank Herbert"}))
+
+	query := url.Values{"query": {`query ($id: ID) { book(id: $id) { id title } }`}, "variables": {`{"id": 1}`}}
+	req := httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {"book": {"id": "1", "title": "Dune"}}}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`{ books { isbn13 } }`), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `cannot query field \"isbn13\" on type \"Book\"`)
+
+	req = httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -20,6 +20,13 @@ func GetUserID(c *gin.Context) uint {
 	return auth.GetUserID(c)
 }
 
+// ownedByUser reports whether the signed-in user may see a record of ownerID.
+// Without authentication every record is visible.
+func ownedByUser(c *gin.Context, ownerID uint) bool {
+	userID := GetUserID(c)
+	return userID == DefaultUserID || userID == ownerID
+}
+
 // --- Response Types ---
 
 // ErrorResponse is the standard error response format for all API errors.
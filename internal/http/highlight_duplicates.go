@@ -0,0 +1,101 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/audit"
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"gorm.io/gorm"
+)
+
+// HighlightDuplicateStore defines database operations for the cross-source duplicates report.
+type HighlightDuplicateStore interface {
+	FindDuplicateHighlights(minSimilarity float64) ([]entities.HighlightDuplicate, error)
+	MergeDuplicateHighlights(firstID, secondID, userID uint) (*entities.Highlight, error)
+}
+
+type HighlightDuplicatesController struct {
+	store        HighlightDuplicateStore
+	auditService *audit.Service
+}
+
+func NewHighlightDuplicatesController(store HighlightDuplicateStore, auditService *audit.Service) *HighlightDuplicatesController {
+	return &HighlightDuplicatesController{store: store, auditService: auditService}
+}
+
+// ListDuplicates reports highlights of the same book imported from different
+// sources with nearly the same text. The optional threshold (0-1) sets how alike
+// they must be.
+// GET /api/admin/duplicates?threshold=0.8
+func (dc *HighlightDuplicatesController) ListDuplicates(c *gin.Context) {
+	threshold := database.DefaultDuplicateSimilarity
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			respondBadRequest(c, "threshold must be a number greater than 0 and at most 1")
+			return
+		}
+		threshold = parsed
+	}
+
+	duplicates, err := dc.store.FindDuplicateHighlights(threshold)
+	if err != nil {
+		respondInternalError(c, err, "find duplicate highlights")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"duplicates": duplicates,
+		"count":      len(duplicates),
+		"threshold":  threshold,
+	})
+}
+
+// MergeDuplicatesRequest names the two highlights to merge, in any order.
+type MergeDuplicatesRequest struct {
+	HighlightIDs []uint `json:"highlight_ids" binding:"required"`
+}
+
+// MergeDuplicates merges two duplicate highlights into the richer record and
+// permanently deletes the other.
+// POST /api/admin/duplicates/merge
+func (dc *HighlightDuplicatesController) MergeDuplicates(c *gin.Context) {
+	var req MergeDuplicatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.HighlightIDs) != 2 {
+		respondBadRequest(c, "highlight_ids must list two highlights")
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	kept, err := dc.store.MergeDuplicateHighlights(req.HighlightIDs[0], req.HighlightIDs[1], userID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondNotFound(c, "highlight")
+		return
+	case errors.Is(err, database.ErrNotDuplicates):
+		respondBadRequest(c, err.Error())
+		return
+	case err != nil:
+		respondInternalError(c, err, "merge duplicate highlights")
+		return
+	}
+
+	removedID := req.HighlightIDs[0]
+	if removedID == kept.ID {
+		removedID = req.HighlightIDs[1]
+	}
+	if dc.auditService != nil {
+		dc.auditService.LogDelete(userID, "highlight", removedID, "merged into highlight "+strconv.FormatUint(uint64(kept.ID), 10), true)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":              "highlights merged",
+		"highlight":            kept,
+		"removed_highlight_id": removedID,
+	})
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDuplicatesTest(t *testing.T) (*database.Database, *gin.Engine) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dbPath := "./test_duplicates_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(dbPath)
+	})
+
+	controller := NewHighlightDuplicatesController(db, nil)
+	router := gin.New()
+	router.GET("/api/admin/duplicates", controller.ListDuplicates)
+	router.POST("/api/admin/duplicates/merge", controller.MergeDuplicates)
+	return db, router
+}
+
+func TestHighlightDuplicatesController(t *testing.T) {
+	t.Run("lists and merges duplicates across sources", func(t *testing.T) {
+		db, router := setupDuplicatesTest(t)
+		require.NoError(t, db.SaveBook(&entities.Book{
+			Title:      "Dune",
+			Author:     "Frank Herbert",
+			Source:     entities.Source{Name: "kindle"},
+			Highlights: []entities.Highlight{{Text: "Fear is the mind-killer.", LocationValue: 42}},
+		}))
+		require.NoError(t, db.SaveBook(&entities.Book{
+			Title:  "Dune",
+			Author: "Frank Herbert",
+			Highlights: []entities.Highlight{
+				{Text: "Fear is the mind killer", Note: "Litany", Source: entities.Source{Name: "readwise"}},
+			},
+		}))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/admin/duplicates", nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var report struct {
+			Duplicates []entities.HighlightDuplicate `json:"duplicates"`
+			Count      int                           `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		require.Equal(t, 1, report.Count)
+		pair := report.Duplicates[0]
+		assert.Equal(t, "Litany", pair.Keep.Note)
+
+		body, _ := json.Marshal(MergeDuplicatesRequest{HighlightIDs: []uint{pair.Duplicate.ID, pair.Keep.ID}})
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/admin/duplicates/merge", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		merged, err := db.GetHighlightByID(pair.Keep.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 42, merged.LocationValue)
+		_, err = db.GetHighlightByID(pair.Duplicate.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects an invalid threshold", func(t *testing.T) {
+		_, router := setupDuplicatesTest(t)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/admin/duplicates?threshold=2", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("merge requires two existing highlights", func(t *testing.T) {
+		_, router := setupDuplicatesTest(t)
+
+		for body, status := range map[string]int{
+			`{"highlight_ids":[1]}`:       http.StatusBadRequest,
+			`{"highlight_ids":[998,999]}`: http.StatusNotFound,
+		} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/admin/duplicates/merge", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			assert.Equal(t, status, w.Code, body)
+		}
+	})
+}
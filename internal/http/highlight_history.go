@@ -0,0 +1,105 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
+	"gorm.io/gorm"
+)
+
+// HighlightHistoryStore defines database operations for highlight edit history.
+type HighlightHistoryStore interface {
+	GetHighlightByID(id uint) (*entities.Highlight, error)
+	GetHighlightHistory(highlightID uint) ([]entities.HighlightVersion, error)
+	RevertHighlight(highlightID, versionID uint) (*entities.Highlight, error)
+	GetHighlightConflicts() ([]entities.HighlightConflict, error)
+}
+
+type HighlightHistoryController struct {
+	store HighlightHistoryStore
+}
+
+func NewHighlightHistoryController(store HighlightHistoryStore) *HighlightHistoryController {
+	return &HighlightHistoryController{store: store}
+}
+
+// GetHistory returns previous versions of a highlight's text and note, newest first.
+// GET /api/highlights/:id/history
+func (hc *HighlightHistoryController) GetHistory(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	highlight, err := hc.store.GetHighlightByID(id)
+	if err != nil {
+		respondNotFound(c, "highlight")
+		return
+	}
+
+	versions, err := hc.store.GetHighlightHistory(id)
+	if err != nil {
+		respondInternalError(c, err, "get highlight history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"highlight": highlight,
+		"versions":  versions,
+	})
+}
+
+// RevertToVersion restores a highlight's text and note from a previous version.
+// POST /api/highlights/:id/history/:versionId/revert
+func (hc *HighlightHistoryController) RevertToVersion(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	versionID, ok := parseIDParam(c, "versionId")
+	if !ok {
+		return
+	}
+
+	if _, err := hc.store.GetHighlightByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondNotFound(c, "highlight")
+			return
+		}
+		respondInternalError(c, err, "get highlight")
+		return
+	}
+
+	highlight, err := hc.store.RevertHighlight(id, versionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "version")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "revert highlight")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "highlight reverted",
+		"highlight": highlight,
+	})
+}
+
+// ListConflicts returns locally edited highlights whose source copy has since diverged.
+// Reverting to the returned source version accepts the source's text and note.
+// GET /api/highlights/conflicts
+func (hc *HighlightHistoryController) ListConflicts(c *gin.Context) {
+	conflicts, err := hc.store.GetHighlightConflicts()
+	if err != nil {
+		respondInternalError(c, err, "get highlight conflicts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"conflicts": conflicts,
+		"count":     len(conflicts),
+	})
+}
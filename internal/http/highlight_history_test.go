@@ -0,0 +1,172 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupHistoryTestDB(t *testing.T) (*database.Database, func()) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dbPath := "./test_history_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+
+	cleanup := func() {
+		db.Close()
+		os.Remove(dbPath)
+	}
+	return db, cleanup
+}
+
+func setupHistoryRouter(db *database.Database) *gin.Engine {
+	controller := NewHighlightHistoryController(db)
+	router := gin.New()
+	router.GET("/api/highlights/conflicts", controller.ListConflicts)
+	router.GET("/api/highlights/:id/history", controller.GetHistory)
+	router.POST("/api/highlights/:id/history/:versionId/revert", controller.RevertToVersion)
+	return router
+}
+
+// failingRevertStore wraps a real database but fails reverts with a non-lookup error.
+type failingRevertStore struct {
+	*database.Database
+}
+
+func (s failingRevertStore) RevertHighlight(highlightID, versionID uint) (*entities.Highlight, error) {
+	return nil, errors.New("database is locked")
+}
+
+func TestHighlightHistoryController(t *testing.T) {
+	t.Run("returns history and reverts to a version", func(t *testing.T) {
+		db, cleanup := setupHistoryTestDB(t)
+		defer cleanup()
+
+		book := &entities.Book{
+			Title:      "Book",
+			Author:     "Author",
+			Highlights: []entities.Highlight{{Text: "Text", Note: "Lost note"}},
+		}
+		require.NoError(t, db.SaveBook(book))
+		require.NoError(t, db.SaveBook(&entities.Book{
+			Title:      "Book",
+			Author:     "Author",
+			Highlights: []entities.Highlight{{Text: "Text"}},
+		}))
+		highlightID := book.Highlights[0].ID
+		router := setupHistoryRouter(db)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/highlights/%d/history", highlightID), nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var history struct {
+			Versions []entities.HighlightVersion `json:"versions"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		require.Len(t, history.Versions, 1)
+		assert.Equal(t, "Lost note", history.Versions[0].Note)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", fmt.Sprintf("/api/highlights/%d/history/%d/revert", highlightID, history.Versions[0].ID), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		highlight, err := db.GetHighlightByID(highlightID)
+		require.NoError(t, err)
+		assert.Equal(t, "Lost note", highlight.Note)
+	})
+
+	t.Run("returns 404 for unknown highlight", func(t *testing.T) {
+		db, cleanup := setupHistoryTestDB(t)
+		defer cleanup()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/highlights/999/history", nil)
+		setupHistoryRouter(db).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("returns 404 for unknown version", func(t *testing.T) {
+		db, cleanup := setupHistoryTestDB(t)
+		defer cleanup()
+
+		book := &entities.Book{Title: "Book", Author: "Author", Highlights: []entities.Highlight{{Text: "Text"}}}
+		require.NoError(t, db.SaveBook(book))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/api/highlights/%d/history/999/revert", book.Highlights[0].ID), nil)
+		setupHistoryRouter(db).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("returns 500 when the revert fails", func(t *testing.T) {
+		db, cleanup := setupHistoryTestDB(t)
+		defer cleanup()
+
+		book := &entities.Book{Title: "Book", Author: "Author", Highlights: []entities.Highlight{{Text: "Text"}}}
+		require.NoError(t, db.SaveBook(book))
+
+		controller := NewHighlightHistoryController(failingRevertStore{db})
+		router := gin.New()
+		router.POST("/api/highlights/:id/history/:versionId/revert", controller.RevertToVersion)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/api/highlights/%d/history/1/revert", book.Highlights[0].ID), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("lists re-import conflicts with local edits", func(t *testing.T) {
+		db, cleanup := setupHistoryTestDB(t)
+		defer cleanup()
+
+		book := &entities.Book{
+			Title:      "Book",
+			Author:     "Author",
+			Highlights: []entities.Highlight{{Text: "Text", LocationValue: 5, Note: "Source note"}},
+		}
+		require.NoError(t, db.SaveBook(book))
+		highlight, err := db.GetHighlightByID(book.Highlights[0].ID)
+		require.NoError(t, err)
+		highlight.Note = "My note"
+		require.NoError(t, db.UpdateHighlight(highlight))
+		require.NoError(t, db.SaveBook(&entities.Book{
+			Title:      "Book",
+			Author:     "Author",
+			Highlights: []entities.Highlight{{Text: "Text", LocationValue: 5, Note: "Updated source note"}},
+		}))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/highlights/conflicts", nil)
+		setupHistoryRouter(db).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Conflicts []entities.HighlightConflict `json:"conflicts"`
+			Count     int                          `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, 1, response.Count)
+		assert.Equal(t, "My note", response.Conflicts[0].Highlight.Note)
+		assert.Equal(t, "Updated source note", response.Conflicts[0].SourceVersion.Note)
+		assert.Equal(t, "Book", response.Conflicts[0].BookTitle)
+	})
+}
@@ -0,0 +1,263 @@
+package http
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/deeplink"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/quotecard"
+)
+
+// maxLinkCandidates caps the highlights offered when searching for a link target.
+const maxLinkCandidates = 20
+
+// HighlightLinkStore defines database operations for links between highlights.
+type HighlightLinkStore interface {
+	GetHighlightByID(id uint) (*entities.Highlight, error)
+	GetBookByID(id uint) (*entities.Book, error)
+	GetHighlightLinks(highlightID uint) (outgoing, incoming []entities.HighlightLink, err error)
+	CreateHighlightLink(fromID, toID uint, linkType entities.HighlightLinkType) (*entities.HighlightLink, error)
+	DeleteHighlightLink(id uint) error
+	SearchHighlights(query string, limit int) ([]entities.Highlight, error)
+}
+
+// HighlightLinksController manages typed cross-references between highlights
+// ("supports", "contradicts", "see also") and the highlight detail page.
+type HighlightLinksController struct {
+	store      HighlightLinkStore
+	quoteCards bool
+	deepLinks  func(source string) string
+}
+
+func NewHighlightLinksController(store HighlightLinkStore) *HighlightLinksController {
+	return &HighlightLinksController{store: store}
+}
+
+// WithQuoteCards offers quote card images for sharing on the highlight page.
+func (hc *HighlightLinksController) WithQuoteCards(enabled bool) *HighlightLinksController {
+	hc.quoteCards = enabled
+	return hc
+}
+
+// WithDeepLinks links the highlight page back to the highlight in its reading
+// app, using the URL template the function returns for a source.
+func (hc *HighlightLinksController) WithDeepLinks(templates func(source string) string) *HighlightLinksController {
+	hc.deepLinks = templates
+	return hc
+}
+
+// HighlightLinkRequest is the request body for linking a highlight to another.
+type HighlightLinkRequest struct {
+	ToHighlightID uint                       `json:"to_highlight_id" form:"to_highlight_id"`
+	Type          entities.HighlightLinkType `json:"type" form:"type"`
+}
+
+// HighlightLinksResponse lists the links from and to a highlight.
+type HighlightLinksResponse struct {
+	Outgoing []entities.HighlightLink `json:"outgoing"`
+	Incoming []entities.HighlightLink `json:"incoming"`
+}
+
+// GetLinks returns the links from and to a highlight.
+// GET /api/highlights/:id/links
+func (hc *HighlightLinksController) GetLinks(c *gin.Context) {
+	highlight, ok := hc.loadHighlight(c)
+	if !ok {
+		return
+	}
+	outgoing, incoming, err := hc.store.GetHighlightLinks(highlight.ID)
+	if err != nil {
+		respondInternalError(c, err, "get highlight links")
+		return
+	}
+	c.JSON(http.StatusOK, HighlightLinksResponse{Outgoing: outgoing, Incoming: incoming})
+}
+
+// CreateLink links the highlight to another one.
+// POST /api/highlights/:id/links
+func (hc *HighlightLinksController) CreateLink(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req HighlightLinkRequest
+	if err := c.ShouldBind(&req); err != nil || req.ToHighlightID == 0 {
+		respondBadRequest(c, "to_highlight_id is required")
+		return
+	}
+
+	link, err := hc.store.CreateHighlightLink(id, req.ToHighlightID, req.Type)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondNotFound(c, "highlight")
+		return
+	case errors.Is(err, database.ErrInvalidLinkType), errors.Is(err, database.ErrSelfLink):
+		respondBadRequest(c, err.Error())
+		return
+	case err != nil:
+		respondInternalError(c, err, "create highlight link")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		hc.renderLinks(c, id)
+		return
+	}
+	respondCreated(c, link)
+}
+
+// DeleteLink removes one of the highlight's links.
+// DELETE /api/highlights/:id/links/:linkId
+func (hc *HighlightLinksController) DeleteLink(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	linkID, ok := parseIDParam(c, "linkId")
+	if !ok {
+		return
+	}
+
+	err := hc.store.DeleteHighlightLink(linkID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "link")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "delete highlight link")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		hc.renderLinks(c, id)
+		return
+	}
+	respondSuccess(c, "link deleted")
+}
+
+// LinkCandidates renders the highlights matching a search that the highlight
+// can be linked to.
+// GET /ui/highlights/:id/links/candidates?q=
+func (hc *HighlightLinksController) LinkCandidates(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var candidates []entities.Highlight
+	if query := strings.TrimSpace(c.Query("q")); query != "" {
+		found, err := hc.store.SearchHighlights(query, maxLinkCandidates+1)
+		if err != nil {
+			respondInternalError(c, err, "search highlights")
+			return
+		}
+		for _, highlight := range found {
+			if highlight.ID != id && len(candidates) < maxLinkCandidates {
+				candidates = append(candidates, highlight)
+			}
+		}
+	}
+
+	c.HTML(http.StatusOK, "highlight-link-candidates", gin.H{
+		"HighlightID": id,
+		"Candidates":  candidates,
+		"LinkTypes":   entities.HighlightLinkTypes,
+	})
+}
+
+// HighlightPage renders a highlight with its book, note and links.
+// GET /ui/highlights/:id
+func (hc *HighlightLinksController) HighlightPage(c *gin.Context) {
+	highlight, ok := hc.loadHighlight(c)
+	if !ok {
+		return
+	}
+	book, err := hc.store.GetBookByID(highlight.BookID)
+	if err != nil {
+		respondInternalError(c, err, "get book")
+		return
+	}
+	outgoing, incoming, err := hc.store.GetHighlightLinks(highlight.ID)
+	if err != nil {
+		respondInternalError(c, err, "get highlight links")
+		return
+	}
+
+	c.HTML(http.StatusOK, "highlight", gin.H{
+		"Highlight":   highlight,
+		"HighlightID": highlight.ID,
+		"Book":        book,
+		"Outgoing":    outgoing,
+		"Incoming":    incoming,
+		"QuoteCards":  hc.quoteCardTemplates(),
+		"DeepLink":    hc.deepLink(book, highlight),
+		"Auth":        GetAuthTemplateData(c),
+		"UI":          GetUIPreferences(c),
+		"Demo":        GetDemoTemplateData(c),
+		"Analytics":   GetAnalyticsTemplateData(c),
+	})
+}
+
+// quoteCardTemplates lists the quote card templates to offer, none when quote
+// cards are disabled
+func (hc *HighlightLinksController) quoteCardTemplates() []string {
+	if !hc.quoteCards {
+		return nil
+	}
+	return quotecard.TemplateNames()
+}
+
+// deepLink returns the "open in app" link of a highlight, "" when its source
+// has no template or the highlight lacks a value the template needs
+func (hc *HighlightLinksController) deepLink(book *entities.Book, highlight *entities.Highlight) template.URL {
+	if hc.deepLinks == nil {
+		return ""
+	}
+	source := highlight.Source.Name
+	if source == "" {
+		source = book.Source.Name
+	}
+	// Templates are set by the admin and use app schemes html/template would reject
+	return template.URL(deeplink.Build(hc.deepLinks(source), book, highlight))
+}
+
+// renderLinks renders the links section of the highlight page
+func (hc *HighlightLinksController) renderLinks(c *gin.Context, id uint) {
+	outgoing, incoming, err := hc.store.GetHighlightLinks(id)
+	if err != nil {
+		respondInternalError(c, err, "get highlight links")
+		return
+	}
+	c.HTML(http.StatusOK, "highlight-links", gin.H{
+		"HighlightID": id,
+		"Outgoing":    outgoing,
+		"Incoming":    incoming,
+		"Demo":        GetDemoTemplateData(c),
+	})
+}
+
+func (hc *HighlightLinksController) loadHighlight(c *gin.Context) (*entities.Highlight, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	highlight, err := hc.store.GetHighlightByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "highlight")
+		return nil, false
+	}
+	if err != nil {
+		respondInternalError(c, err, "get highlight")
+		return nil, false
+	}
+	return highlight, true
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestHighlightLinksController(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	letters := &entities.Book{Title: "Letters from a Stoic", Author: "Seneca",
+		Highlights: []entities.Highlight{{Text: "We suffer more in imagination than in reality"}}}
+	meditations := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius",
+		Highlights: []entities.Highlight{{Text: "You have power over your mind, not outside events"}}}
+	require.NoError(t, db.SaveBook(letters))
+	require.NoError(t, db.SaveBook(meditations))
+	from, to := letters.Highlights[0].ID, meditations.Highlights[0].ID
+
+	controller := NewHighlightLinksController(db)
+	router := gin.New()
+	router.GET("/api/highlights/:id/links", controller.GetLinks)
+	router.POST("/api/highlights/:id/links", controller.CreateLink)
+	router.DELETE("/api/highlights/:id/links/:linkId", controller.DeleteLink)
+
+	createLink := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/highlights/%d/links", from), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := createLink(fmt.Sprintf(`{"to_highlight_id": %d, "type": "supports"}`, to))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var link entities.HighlightLink
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &link))
+	assert.Equal(t, entities.HighlightLinkSupports, link.Type)
+
+	assert.Equal(t, http.StatusBadRequest, createLink(fmt.Sprintf(`{"to_highlight_id": %d, "type": "agrees"}`, to)).Code)
+	assert.Equal(t, http.StatusBadRequest, createLink(fmt.Sprintf(`{"to_highlight_id": %d, "type": "see_also"}`, from)).Code)
+	assert.Equal(t, http.StatusBadRequest, createLink(`{"type": "see_also"}`).Code)
+	assert.Equal(t, http.StatusNotFound, createLink(`{"to_highlight_id": 999, "type": "see_also"}`).Code)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/highlights/%d/links", to), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var links HighlightLinksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &links))
+	assert.Empty(t, links.Outgoing)
+	require.Len(t, links.Incoming, 1)
+	assert.Equal(t, from, links.Incoming[0].FromHighlightID)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/api/highlights/%d/links/%d", from, link.ID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/api/highlights/%d/links/%d", from, link.ID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
+	"gorm.io/gorm"
+)
+
+// HighlightListStore defines database operations for filtered highlight listings
+// and random picks.
+type HighlightListStore interface {
+	ListHighlights(filter entities.HighlightFilter, limit, offset int) ([]entities.Highlight, int64, error)
+	GetRandomHighlight(filter entities.HighlightFilter) (*entities.Highlight, error)
+	GetHighlightOfTheDay(filter entities.HighlightFilter, day time.Time) (*entities.Highlight, error)
+	CountHighlightCategories(userID uint) (map[entities.HighlightCategory]int64, error)
+}
+
+type HighlightsController struct {
+	store HighlightListStore
+}
+
+func NewHighlightsController(store HighlightListStore) *HighlightsController {
+	return &HighlightsController{store: store}
+}
+
+// ListHighlights returns highlights matching the query filters, sorted by sort
+// (one of entities.HighlightSorts) or else the user's saved highlight order.
+// GET /api/highlights?q=&from=&to=&source=&tag=&favourite=&has_note=&category=&book_id=&include_archived=&sort=&limit=&offset=
+func (hc *HighlightsController) ListHighlights(c *gin.Context) {
+	filter, err := parseHighlightFilter(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	filter.UserID = GetUserID(c)
+	if filter.Sort, err = parseSort(c, GetUIPreferences(c).HighlightSort, entities.HighlightSorts); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	highlights, total, err := hc.store.ListHighlights(filter, limit, offset)
+	if err != nil {
+		respondInternalError(c, err, "list highlights")
+		return
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:       highlights,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		HasMore:    int64(offset+len(highlights)) < total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	})
+}
+
+// RandomHighlight returns a random highlight matching the query filters. With
+// daily=true it returns the user's highlight of the day instead.
+// GET /api/highlights/random?tag=&book_id=&source=&daily=
+func (hc *HighlightsController) RandomHighlight(c *gin.Context) {
+	filter, err := parseHighlightFilter(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	filter.UserID = GetUserID(c)
+
+	var highlight *entities.Highlight
+	if c.Query("daily") == "true" {
+		highlight, err = hc.store.GetHighlightOfTheDay(filter, GetUserPreferences(c).Now())
+	} else {
+		highlight, err = hc.store.GetRandomHighlight(filter)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "highlight")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "get random highlight")
+		return
+	}
+
+	c.JSON(http.StatusOK, highlight)
+}
+
+// ListCategories returns the classifier categories with how many highlights
+// are in each, for filtering listings by category.
+// GET /api/highlights/categories
+func (hc *HighlightsController) ListCategories(c *gin.Context) {
+	counts, err := hc.store.CountHighlightCategories(GetUserID(c))
+	if err != nil {
+		respondInternalError(c, err, "count highlight categories")
+		return
+	}
+
+	type categoryCount struct {
+		Category entities.HighlightCategory `json:"category"`
+		Label    string                     `json:"label"`
+		Count    int64                      `json:"count"`
+	}
+	categories := make([]categoryCount, 0, len(entities.HighlightCategories))
+	for _, category := range entities.HighlightCategories {
+		categories = append(categories, categoryCount{Category: category, Label: category.Label(), Count: counts[category]})
+	}
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+// HighlightOfTheDay renders the highlight of the day card for the home page.
+// Renders nothing when there are no highlights yet. The highlight changes at
+// the user's local midnight.
+// GET /ui/highlights/daily
+func (hc *HighlightsController) HighlightOfTheDay(c *gin.Context) {
+	highlight, err := hc.store.GetHighlightOfTheDay(entities.HighlightFilter{UserID: GetUserID(c)}, GetUserPreferences(c).Now())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Failed to load highlight of the day: %v", err)
+	}
+
+	c.HTML(http.StatusOK, "highlight-of-the-day", gin.H{"Highlight": highlight})
+}
+
+// parseHighlightFilter reads the filter query parameters. q searches text and
+// notes; category is one of entities.HighlightCategories. Dates are YYYY-MM-DD or
+// RFC 3339; a plain "to" date includes the whole day, in the user's timezone. Tags are given as repeated
+// tag parameters or a comma-separated list. Highlights of archived books are
+// left out unless include_archived=true.
+func parseHighlightFilter(c *gin.Context) (entities.HighlightFilter, error) {
+	filter := entities.HighlightFilter{Source: c.Query("source"), Query: strings.TrimSpace(c.Query("q"))}
+
+	if v := c.Query("book_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, errors.New("invalid book_id")
+		}
+		filter.BookID = uint(id)
+	}
+
+	for _, v := range c.QueryArray("tag") {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(part, 10, 32)
+			if err != nil {
+				return filter, fmt.Errorf("invalid tag %q", part)
+			}
+			filter.TagIDs = append(filter.TagIDs, uint(id))
+		}
+	}
+
+	if v := c.Query("category"); v != "" {
+		filter.Category = entities.HighlightCategory(v)
+		if !filter.Category.Valid() {
+			return filter, fmt.Errorf("invalid category %q", v)
+		}
+	}
+
+	var err error
+	if filter.Favourite, err = parseOptionalBool(c, "favourite"); err != nil {
+		return filter, err
+	}
+	if filter.HasNote, err = parseOptionalBool(c, "has_note"); err != nil {
+		return filter, err
+	}
+	if filter.IncludeArchived, err = parseIncludeArchived(c); err != nil {
+		return filter, err
+	}
+	if filter.From, err = parseFilterDate(c, "from", false); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseFilterDate(c, "to", true); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func parseOptionalBool(c *gin.Context, name string) (*bool, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: expected true or false", name)
+	}
+	return &b, nil
+}
+
+// parseIncludeArchived reads the include_archived query parameter, which adds
+// archived books, or their highlights, to a listing.
+func parseIncludeArchived(c *gin.Context) (bool, error) {
+	include, err := parseOptionalBool(c, "include_archived")
+	return include != nil && *include, err
+}
+
+// parseSort reads the sort query parameter, one of choices, falling back to
+// the user's saved order when it is omitted.
+func parseSort(c *gin.Context, saved string, choices []string) (string, error) {
+	sort := c.DefaultQuery("sort", saved)
+	if !slices.Contains(choices, sort) {
+		return "", fmt.Errorf("sort must be one of: %s", strings.Join(choices, ", "))
+	}
+	return sort, nil
+}
+
+// parseFilterDate parses a date bound from the query. A plain date is midnight
+// in the user's timezone; with endOfDay it becomes the start of the following
+// day so the bound is inclusive of that day.
+func parseFilterDate(c *gin.Context, name string, endOfDay bool) (*time.Time, error) {
+	return parseDateBound(c, name, c.Query(name), endOfDay)
+}
+
+// parseDateBound parses the date bound v as parseFilterDate does, naming it
+// name in errors
+func parseDateBound(c *gin.Context, name, v string, endOfDay bool) (*time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, GetUserPreferences(c).Location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: expected YYYY-MM-DD or RFC 3339", name)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
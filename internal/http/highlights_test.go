@@ -0,0 +1,211 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlightsController_ListHighlights(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dbPath := "./test_highlights_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	kindle := &entities.Book{
+		Title:  "Kindle Book",
+		Author: "Author",
+		Source: entities.Source{Name: "kindle"},
+		Highlights: []entities.Highlight{
+			{Text: "January first", HighlightedAt: day(1), Source: entities.Source{Name: "kindle"}},
+			{Text: "January tenth", HighlightedAt: day(10), Note: "A note", Source: entities.Source{Name: "kindle"}},
+		},
+	}
+	require.NoError(t, db.SaveBook(kindle))
+	apple := &entities.Book{
+		Title:  "Apple Book",
+		Author: "Author",
+		Source: entities.Source{Name: "apple_books"},
+		Highlights: []entities.Highlight{
+			{Text: "January twentieth", HighlightedAt: day(20), Source: entities.Source{Name: "apple_books"}},
+		},
+	}
+	require.NoError(t, db.SaveBook(apple))
+
+	require.NoError(t, db.SetHighlightFavourite(kindle.Highlights[0].ID, true))
+	tag, err := db.CreateTag("ideas", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToHighlight(apple.Highlights[0].ID, tag.ID))
+	require.NoError(t, db.SetHighlightCategories(kindle.Highlights[1].ID, []entities.HighlightCategory{entities.HighlightCategoryAdvice}, "rules"))
+
+	router := gin.New()
+	router.GET("/api/highlights", NewHighlightsController(db).ListHighlights)
+
+	list := func(query string) (int, []string) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/highlights?"+query, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			return w.Code, nil
+		}
+		var resp struct {
+			Data  []entities.Highlight `json:"data"`
+			Total int64                `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Equal(t, int64(len(resp.Data)), resp.Total)
+		texts := make([]string, len(resp.Data))
+		for i, h := range resp.Data {
+			texts[i] = h.Text
+		}
+		return w.Code, texts
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"January twentieth", "January tenth", "January first"}},
+		{"from=2024-01-05&to=2024-01-10", []string{"January tenth"}},
+		{"to=2024-01-09T00:00:00Z", []string{"January first"}},
+		{"source=apple_books", []string{"January twentieth"}},
+		{fmt.Sprintf("tag=%d", tag.ID), []string{"January twentieth"}},
+		{"favourite=true", []string{"January first"}},
+		{"favourite=false&source=kindle", []string{"January tenth"}},
+		{"has_note=true", []string{"January tenth"}},
+		{"has_note=false", []string{"January twentieth", "January first"}},
+		{"category=advice", []string{"January tenth"}},
+		{"category=quote", []string{}},
+		{fmt.Sprintf("book_id=%d", kindle.ID), []string{"January tenth", "January first"}},
+		{"sort=title", []string{"January twentieth", "January first", "January tenth"}},
+	}
+	for _, tt := range tests {
+		code, texts := list(tt.query)
+		assert.Equal(t, http.StatusOK, code, tt.query)
+		assert.Equal(t, tt.want, texts, tt.query)
+	}
+
+	for _, query := range []string{"from=yesterday", "favourite=maybe", "tag=abc", "book_id=x", "sort=random", "category=joke"} {
+		code, _ := list(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
+
+func TestHighlightsController_RandomHighlight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dbPath := "./test_highlights_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	router := gin.New()
+	controller := NewHighlightsController(db)
+	router.GET("/api/highlights/random", controller.RandomHighlight)
+
+	get := func(query string) (int, entities.Highlight) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/highlights/random?"+query, nil))
+		var highlight entities.Highlight
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &highlight))
+		}
+		return w.Code, highlight
+	}
+
+	code, _ := get("")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	book := &entities.Book{
+		Title:  "Random Book",
+		Author: "Author",
+		Highlights: []entities.Highlight{
+			{Text: "One", LocationValue: 1},
+			{Text: "Two", LocationValue: 2},
+			{Text: "Three", LocationValue: 3},
+		},
+	}
+	require.NoError(t, db.SaveBook(book))
+	other := &entities.Book{Title: "Other Book", Author: "Author", Highlights: []entities.Highlight{{Text: "Elsewhere"}}}
+	require.NoError(t, db.SaveBook(other))
+
+	code, highlight := get(fmt.Sprintf("book_id=%d", other.ID))
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Elsewhere", highlight.Text)
+
+	// The highlight of the day does not change between requests
+	code, daily := get("daily=true")
+	require.Equal(t, http.StatusOK, code)
+	for range 5 {
+		_, again := get("daily=true")
+		assert.Equal(t, daily.ID, again.ID)
+	}
+
+	picked, err := db.GetHighlightOfTheDay(entities.HighlightFilter{}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, daily.ID, picked.ID)
+}
+
+func TestHighlightsController_ListCategories(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dbPath := "./test_highlights_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	book := &entities.Book{
+		Title:      "Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "One"}, {Text: "Two"}},
+	}
+	require.NoError(t, db.SaveBook(book))
+	for _, h := range book.Highlights {
+		require.NoError(t, db.SetHighlightCategories(h.ID, []entities.HighlightCategory{entities.HighlightCategoryQuote}, "rules"))
+	}
+	require.NoError(t, db.SetHighlightCategories(book.Highlights[0].ID,
+		[]entities.HighlightCategory{entities.HighlightCategoryQuote, entities.HighlightCategoryDataPoint}, "rules"))
+
+	router := gin.New()
+	router.GET("/api/highlights/categories", NewHighlightsController(db).ListCategories)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/highlights/categories", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Categories []struct {
+			Category string `json:"category"`
+			Label    string `json:"label"`
+			Count    int64  `json:"count"`
+		} `json:"categories"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Categories, len(entities.HighlightCategories))
+	counts := map[string]int64{}
+	for _, category := range resp.Categories {
+		counts[category.Category] = category.Count
+	}
+	assert.Equal(t, map[string]int64{"advice": 0, "definition": 0, "quote": 2, "data_point": 1}, counts)
+	assert.Equal(t, "data point", resp.Categories[3].Label)
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/i18n"
+)
+
+const languageContextKey = "language"
+
+// LanguageStore resolves the UI language of a user, "" to follow the browser.
+// Implemented by settingsstore.SettingsStore.
+type LanguageStore interface {
+	GetUserLanguage(userID uint) string
+}
+
+// LanguageMiddleware picks the language pages are rendered in: the user's
+// own, the server default, the browser's Accept-Language, then English. Must
+// run after authentication. The store may be nil.
+func LanguageMiddleware(store LanguageStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		language := ""
+		if store != nil {
+			language = store.GetUserLanguage(auth.GetUserID(c))
+		}
+		if language == "" {
+			language = i18n.MatchAcceptLanguage(c.GetHeader("Accept-Language"))
+			c.Writer.Header().Add("Vary", "Accept-Language")
+		}
+		if language == "" {
+			language = i18n.DefaultLanguage
+		}
+
+		c.Set(languageContextKey, language)
+		// The HTML renderer only sees the response, so it reads the language
+		// from this header
+		c.Header("Content-Language", language)
+		c.Next()
+	}
+}
+
+// GetLanguage returns the language of the current request, English without
+// LanguageMiddleware
+func GetLanguage(c *gin.Context) string {
+	if language, ok := c.Get(languageContextKey); ok {
+		if lang, ok := language.(string); ok {
+			return lang
+		}
+	}
+	return i18n.DefaultLanguage
+}
+
+// GetTranslator returns the translator of the current request's language, for
+// messages rendered by handlers rather than templates
+func GetTranslator(c *gin.Context) *i18n.Translator {
+	return i18n.For(GetLanguage(c))
+}
+
+// localizedHTML renders templates in the language of the request. Each
+// language has its own copy of the templates whose t, tn, lang, date and
+// datetime functions are bound to it:
+//
+//	{{ t "nav.books" }}                  Bücher
+//	{{ tn "common.highlights" 3 }}       3 Markierungen
+//	{{ date .CreatedAt }}                09.03.2024
+type localizedHTML struct {
+	templates map[string]*template.Template
+}
+
+// newLocalizedHTML parses the templates matching pattern once per language
+func newLocalizedHTML(pattern string, funcs template.FuncMap) (*localizedHTML, error) {
+	renderer := &localizedHTML{templates: make(map[string]*template.Template, len(i18n.Languages))}
+	for _, lang := range i18n.Languages {
+		tmpl, err := template.New("").Funcs(funcs).Funcs(languageFuncs(lang.Code)).ParseGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s templates: %w", lang.Code, err)
+		}
+		renderer.templates[lang.Code] = tmpl
+	}
+	return renderer, nil
+}
+
+func languageFuncs(language string) template.FuncMap {
+	translator := i18n.For(language)
+	return template.FuncMap{
+		"t":        translator.T,
+		"tn":       translator.N,
+		"date":     translator.FormatDate,
+		"datetime": translator.FormatDateTime,
+		"lang": func() string {
+			return language
+		},
+		"languages": func() []i18n.Language {
+			return i18n.Languages
+		},
+	}
+}
+
+// Instance implements render.HTMLRender. Instance does not see the request,
+// so the template is picked in Render from the response's language.
+func (r *localizedHTML) Instance(name string, data any) render.Render {
+	return localizedRender{renderer: r, name: name, data: data}
+}
+
+type localizedRender struct {
+	renderer *localizedHTML
+	name     string
+	data     any
+}
+
+func (r localizedRender) Render(w http.ResponseWriter) error {
+	tmpl, ok := r.renderer.templates[w.Header().Get("Content-Language")]
+	if !ok {
+		tmpl = r.renderer.templates[i18n.DefaultLanguage]
+	}
+	return render.HTML{Template: tmpl, Name: r.name, Data: r.data}.Render(w)
+}
+
+func (r localizedRender) WriteContentType(w http.ResponseWriter) {
+	render.HTML{}.WriteContentType(w)
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/i18n"
+)
+
+type fakeLanguageStore map[uint]string
+
+func (s fakeLanguageStore) GetUserLanguage(userID uint) string {
+	return s[userID]
+}
+
+func TestLanguageMiddleware(t *testing.T) {
+	renderer, err := newLocalizedHTML("../../templates/*.html", templateFuncs(NewStaticAssets("../../static"), ""))
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.HTMLRender = renderer
+	router.Use(func(c *gin.Context) {
+		if c.GetHeader("X-User") == "7" {
+			c.Set(auth.ContextKeyUserID, uint(7))
+		}
+		c.Next()
+	})
+	router.Use(LanguageMiddleware(fakeLanguageStore{7: "ru"}))
+	router.GET("/books", func(c *gin.Context) {
+		c.HTML(http.StatusOK, "book-list", []entities.Book{
+			{ID: 1, Title: "Dune", HighlightCount: 3},
+		})
+	})
+
+	get := func(acceptLanguage, user string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/books", nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		req.Header.Set("X-User", user)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+	assert.Contains(t, w.Body.String(), "3 highlights")
+
+	w = get("fr-FR,de;q=0.8", "")
+	assert.Equal(t, "de", w.Header().Get("Content-Language"))
+	assert.Contains(t, w.Body.String(), "3 Markierungen")
+	assert.Contains(t, w.Body.String(), "Endgültig löschen")
+
+	// The user's own language wins over the browser's
+	w = get("de", "7")
+	assert.Equal(t, "ru", w.Header().Get("Content-Language"))
+	assert.Contains(t, w.Body.String(), "3 цитаты")
+}
+
+func TestTemplateMessagesExist(t *testing.T) {
+	files, err := filepath.Glob("../../templates/*.html")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	messageCall := regexp.MustCompile(`\{\{-?\s*\(?\s*tn?\s+"([^"]+)"`)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		for _, match := range messageCall.FindAllStringSubmatch(string(content), -1) {
+			assert.True(t, i18n.Has(match[1]), "%s uses unknown message %s", filepath.Base(file), match[1])
+		}
+	}
+}
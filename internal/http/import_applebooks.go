@@ -3,7 +3,6 @@ package http
 import (
 	"database/sql"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -15,10 +14,12 @@ import (
 	"github.com/mrlokans/assistant/internal/audit"
 	"github.com/mrlokans/assistant/internal/auth"
 	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/uploads"
 )
 
 const (
-	// Maximum file size for uploads (50 MB)
+	// Default maximum file size for regular multipart uploads (50 MB); larger
+	// databases go through the chunked upload API
 	maxAppleBooksFileSize = 50 * 1024 * 1024
 
 	// Expected table names for Apple Books databases
@@ -29,15 +30,34 @@ const (
 type AppleBooksImportController struct {
 	exporter     exporters.BookExporter
 	auditService *audit.Service
+	uploads      *uploads.Store
+	maxFileSize  int64
 }
 
 func NewAppleBooksImportController(exporter exporters.BookExporter, auditService *audit.Service) *AppleBooksImportController {
 	return &AppleBooksImportController{
 		exporter:     exporter,
 		auditService: auditService,
+		maxFileSize:  maxAppleBooksFileSize,
 	}
 }
 
+// WithUploads allows the databases to be provided as completed chunked uploads
+// via the annotation_db_upload_id and book_db_upload_id form values.
+func (c *AppleBooksImportController) WithUploads(store *uploads.Store) *AppleBooksImportController {
+	c.uploads = store
+	return c
+}
+
+// WithMaxFileSize sets the largest database accepted as a regular multipart
+// upload. Sizes of 0 or less keep the default.
+func (c *AppleBooksImportController) WithMaxFileSize(size int64) *AppleBooksImportController {
+	if size > 0 {
+		c.maxFileSize = size
+	}
+	return c
+}
+
 type AppleBooksImportResult struct {
 	Success            bool     `json:"success"`
 	Error              string   `json:"error,omitempty"`
@@ -61,7 +81,7 @@ func (c *AppleBooksImportController) Import(ctx *gin.Context) {
 	// Process annotation database
 	annotationPath, err := c.processUploadedFile(ctx, "annotation_db", tempDir, "annotation.sqlite")
 	if err != nil {
-		ctx.HTML(http.StatusBadRequest, "applebooks-import-result", &AppleBooksImportResult{
+		ctx.HTML(importFileStatus(err), "applebooks-import-result", &AppleBooksImportResult{
 			Success: false,
 			Error:   fmt.Sprintf("Annotation database: %v", err),
 		})
@@ -80,7 +100,7 @@ func (c *AppleBooksImportController) Import(ctx *gin.Context) {
 	// Process book database
 	bookPath, err := c.processUploadedFile(ctx, "book_db", tempDir, "book.sqlite")
 	if err != nil {
-		ctx.HTML(http.StatusBadRequest, "applebooks-import-result", &AppleBooksImportResult{
+		ctx.HTML(importFileStatus(err), "applebooks-import-result", &AppleBooksImportResult{
 			Success: false,
 			Error:   fmt.Sprintf("Book database: %v", err),
 		})
@@ -150,41 +170,9 @@ func (c *AppleBooksImportController) Import(ctx *gin.Context) {
 }
 
 func (c *AppleBooksImportController) processUploadedFile(ctx *gin.Context, fieldName, tempDir, filename string) (string, error) {
-	file, header, err := ctx.Request.FormFile(fieldName)
-	if err != nil {
-		return "", fmt.Errorf("file not provided")
-	}
-	defer file.Close()
-
-	// Check file size
-	if header.Size > maxAppleBooksFileSize {
-		return "", fmt.Errorf("file too large (max %d MB)", maxAppleBooksFileSize/(1024*1024))
-	}
-
-	// Validate file extension
-	ext := filepath.Ext(header.Filename)
-	if ext != ".sqlite" && ext != ".db" && ext != "" {
-		return "", fmt.Errorf("invalid file type: expected .sqlite or .db file")
-	}
-
-	// Create destination path
 	destPath := filepath.Join(tempDir, filename)
-
-	// Create destination file
-	destFile, err := os.Create(destPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp file")
-	}
-	defer destFile.Close()
-
-	// Copy with size limit
-	limitedReader := io.LimitReader(file, maxAppleBooksFileSize+1)
-	written, err := io.Copy(destFile, limitedReader)
-	if err != nil {
-		return "", fmt.Errorf("failed to save file")
-	}
-	if written > maxAppleBooksFileSize {
-		return "", fmt.Errorf("file too large (max %d MB)", maxAppleBooksFileSize/(1024*1024))
+	if _, err := receiveImportFile(ctx, c.uploads, fieldName, UploadKindAppleBooksDatabase, destPath, c.maxFileSize); err != nil {
+		return "", err
 	}
 
 	// Validate it's a valid SQLite file
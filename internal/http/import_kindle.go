@@ -10,11 +10,15 @@ import (
 	"github.com/mrlokans/assistant/internal/audit"
 	"github.com/mrlokans/assistant/internal/auth"
 	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/importers"
 	"github.com/mrlokans/assistant/internal/kindle"
 )
 
 const (
 	maxKindleFileSize = 10 * 1024 * 1024 // 10 MB
+
+	// Clippings are streamed in batches, so much larger files are accepted
+	maxKindleClippingsSize = 100 * 1024 * 1024 // 100 MB
 )
 
 type KindleImportController struct {
@@ -37,135 +41,156 @@ type KindleImportResult struct {
 	Errors             []string `json:"errors,omitempty"`
 }
 
+// Import imports a My Clippings.txt file.
+// POST /settings/kindle/import
 func (c *KindleImportController) Import(ctx *gin.Context) {
+	status, result := c.importClippings(ctx, "Kindle")
+	ctx.HTML(status, "kindle-import-result", result)
+}
+
+// ImportJSON is the JSON API variant of Import.
+// POST /import/kindle
+func (c *KindleImportController) ImportJSON(ctx *gin.Context) {
+	status, result := c.importClippings(ctx, "Kindle (JSON)")
+	ctx.JSON(status, result)
+}
+
+func (c *KindleImportController) importClippings(ctx *gin.Context, sourceLabel string) (int, *KindleImportResult) {
 	file, header, err := ctx.Request.FormFile("clippings_file")
 	if err != nil {
-		ctx.HTML(http.StatusBadRequest, "kindle-import-result", &KindleImportResult{
+		return http.StatusBadRequest, &KindleImportResult{
 			Success: false,
 			Error:   "Clippings file not provided",
-		})
-		return
+		}
 	}
 	defer file.Close()
 
 	// Check file size
-	if header.Size > maxKindleFileSize {
-		ctx.HTML(http.StatusBadRequest, "kindle-import-result", &KindleImportResult{
+	if header.Size > maxKindleClippingsSize {
+		return http.StatusRequestEntityTooLarge, &KindleImportResult{
 			Success: false,
-			Error:   fmt.Sprintf("File too large (max %d MB)", maxKindleFileSize/(1024*1024)),
-		})
-		return
+			Error:   fmt.Sprintf("File too large (max %d MB)", maxKindleClippingsSize/(1024*1024)),
+		}
 	}
 
 	// Read file with size limit
-	limitedReader := io.LimitReader(file, maxKindleFileSize+1)
-
-	// Parse clippings
-	parser := kindle.NewParser()
-	books, err := parser.Parse(limitedReader)
-	if err != nil {
-		ctx.HTML(http.StatusBadRequest, "kindle-import-result", &KindleImportResult{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to parse clippings: %v", err),
-		})
-		return
+	limitedReader := io.LimitReader(file, maxKindleClippingsSize+1)
+
+	// Stream the clippings in batches when the exporter supports it, so memory
+	// use does not grow with the size of the file
+	var result exporters.ExportResult
+	var exportErr error
+	if batchExporter, ok := c.exporter.(importers.BatchExporter); ok {
+		// Clipping dates are the Kindle's local time, taken to be the user's
+		loc := GetUserPreferences(ctx).Location
+		stream := importers.KindleClippingsStream(limitedReader, kindle.DefaultBatchSize, loc)
+		importResult, err := importers.NewStreamPipeline(batchExporter).Import(stream)
+		result, exportErr = exporters.ExportResult(importResult), err
+	} else {
+		books, err := kindle.NewParser().WithLocation(GetUserPreferences(ctx).Location).Parse(limitedReader)
+		if err != nil {
+			return http.StatusBadRequest, &KindleImportResult{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to parse clippings: %v", err),
+			}
+		}
+		if len(books) > 0 {
+			result, exportErr = c.exporter.Export(books)
+		}
 	}
 
-	if len(books) == 0 {
-		ctx.HTML(http.StatusOK, "kindle-import-result", &KindleImportResult{
-			Success:            true,
-			BooksImported:      0,
-			HighlightsImported: 0,
-			Errors:             []string{"No books with highlights found in the clippings file"},
-		})
-		return
+	if exportErr == nil && result.BooksProcessed == 0 {
+		return http.StatusOK, &KindleImportResult{
+			Success: true,
+			Errors:  []string{"No books with highlights found in the clippings file"},
+		}
 	}
 
-	// Export to database (and optionally markdown)
-	result, exportErr := c.exporter.Export(books)
-
 	// Log the import event
 	if c.auditService != nil {
-		desc := fmt.Sprintf("Imported %d books with %d highlights from Kindle", result.BooksProcessed, result.HighlightsProcessed)
+		desc := fmt.Sprintf("Imported %d books with %d highlights from %s", result.BooksProcessed, result.HighlightsProcessed, sourceLabel)
 		c.auditService.LogImport(auth.GetUserID(ctx), "kindle", desc, result.BooksProcessed, result.HighlightsProcessed, exportErr)
 	}
 
 	if exportErr != nil {
-		ctx.HTML(http.StatusInternalServerError, "kindle-import-result", &KindleImportResult{
+		return http.StatusInternalServerError, &KindleImportResult{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to export: %v", exportErr),
-		})
-		return
+		}
 	}
 
-	ctx.HTML(http.StatusOK, "kindle-import-result", &KindleImportResult{
+	return http.StatusOK, &KindleImportResult{
 		Success:            true,
 		BooksImported:      result.BooksProcessed,
 		HighlightsImported: result.HighlightsProcessed,
-	})
+	}
 }
 
-// ImportJSON handles JSON API requests (for potential future use)
-func (c *KindleImportController) ImportJSON(ctx *gin.Context) {
-	file, header, err := ctx.Request.FormFile("clippings_file")
+// ImportNotebook imports a "Notebook export" HTML file from the Kindle iOS/Android apps.
+// POST /settings/kindle/import-notebook
+func (c *KindleImportController) ImportNotebook(ctx *gin.Context) {
+	status, result := c.importNotebook(ctx, "Kindle notebook")
+	ctx.HTML(status, "kindle-import-result", result)
+}
+
+// ImportNotebookJSON is the JSON API variant of ImportNotebook.
+// POST /import/kindle/notebook
+func (c *KindleImportController) ImportNotebookJSON(ctx *gin.Context) {
+	status, result := c.importNotebook(ctx, "Kindle notebook (JSON)")
+	ctx.JSON(status, result)
+}
+
+func (c *KindleImportController) importNotebook(ctx *gin.Context, sourceLabel string) (int, *KindleImportResult) {
+	file, header, err := ctx.Request.FormFile("notebook_file")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, &KindleImportResult{
+		return http.StatusBadRequest, &KindleImportResult{
 			Success: false,
-			Error:   "Clippings file not provided",
-		})
-		return
+			Error:   "Notebook file not provided",
+		}
 	}
 	defer file.Close()
 
 	if header.Size > maxKindleFileSize {
-		ctx.JSON(http.StatusBadRequest, &KindleImportResult{
+		return http.StatusRequestEntityTooLarge, &KindleImportResult{
 			Success: false,
 			Error:   fmt.Sprintf("File too large (max %d MB)", maxKindleFileSize/(1024*1024)),
-		})
-		return
+		}
 	}
 
-	limitedReader := io.LimitReader(file, maxKindleFileSize+1)
-
-	parser := kindle.NewParser()
-	books, err := parser.Parse(limitedReader)
+	notebook, err := kindle.NewNotebookParser().Parse(io.LimitReader(file, maxKindleFileSize+1))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, &KindleImportResult{
+		return http.StatusBadRequest, &KindleImportResult{
 			Success: false,
-			Error:   fmt.Sprintf("Failed to parse clippings: %v", err),
-		})
-		return
+			Error:   fmt.Sprintf("Failed to parse notebook: %v", err),
+		}
 	}
 
+	books := importers.ConvertToBooks(importers.NewKindleNotebookConverter(notebook))
 	if len(books) == 0 {
-		ctx.JSON(http.StatusOK, &KindleImportResult{
-			Success:            true,
-			BooksImported:      0,
-			HighlightsImported: 0,
-			Errors:             []string{"No books with highlights found"},
-		})
-		return
+		return http.StatusOK, &KindleImportResult{
+			Success: true,
+			Errors:  []string{"No highlights found in the notebook file"},
+		}
 	}
 
 	result, exportErr := c.exporter.Export(books)
 
 	// Log the import event
 	if c.auditService != nil {
-		desc := fmt.Sprintf("Imported %d books with %d highlights from Kindle (JSON)", result.BooksProcessed, result.HighlightsProcessed)
+		desc := fmt.Sprintf("Imported %d books with %d highlights from %s", result.BooksProcessed, result.HighlightsProcessed, sourceLabel)
 		c.auditService.LogImport(auth.GetUserID(ctx), "kindle", desc, result.BooksProcessed, result.HighlightsProcessed, exportErr)
 	}
 
 	if exportErr != nil {
-		ctx.JSON(http.StatusInternalServerError, &KindleImportResult{
+		return http.StatusInternalServerError, &KindleImportResult{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to export: %v", exportErr),
-		})
-		return
+		}
 	}
 
-	ctx.JSON(http.StatusOK, &KindleImportResult{
+	return http.StatusOK, &KindleImportResult{
 		Success:            true,
 		BooksImported:      result.BooksProcessed,
 		HighlightsImported: result.HighlightsProcessed,
-	})
+	}
 }
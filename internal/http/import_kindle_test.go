@@ -0,0 +1,80 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/exporters"
+)
+
+func postClippings(t *testing.T, router *gin.Engine, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("clippings_file", "My Clippings.txt")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/import/kindle", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestKindleImportController_ImportJSON_Streams(t *testing.T) {
+	dbPath := "./test_kindle_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	exporter := exporters.NewDatabaseMarkdownExporter(db, t.TempDir())
+	router := gin.New()
+	router.POST("/import/kindle", NewKindleImportController(exporter, nil).ImportJSON)
+
+	clippings := `Book A (Author 1)
+- Your Highlight at location 10-11 | Added on Saturday, 26 March 2016 14:59:39
+
+First
+==========
+Book A (Author 1)
+- Your Highlight at location 20-21 | Added on Saturday, 26 March 2016 15:00:00
+
+Second
+==========
+`
+	w := postClippings(t, router, clippings)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result KindleImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	assert.Equal(t, 1, result.BooksImported)
+	assert.Equal(t, 2, result.HighlightsImported)
+
+	book, err := db.GetBookByTitleAndAuthor("Book A", "Author 1")
+	require.NoError(t, err)
+	assert.Len(t, book.Highlights, 2)
+
+	// A file without clippings reports that nothing was found
+	w = postClippings(t, router, "not a clippings file")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.NotEmpty(t, result.Errors)
+}
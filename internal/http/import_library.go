@@ -0,0 +1,112 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/audit"
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/goodreads"
+)
+
+const maxLibraryCSVSize = 20 * 1024 * 1024 // 20 MB
+
+// LibraryImportStore provides the book and tag operations for Goodreads/StoryGraph imports.
+type LibraryImportStore interface {
+	GetAllBooks() ([]entities.Book, error)
+	SaveBook(book *entities.Book) error
+	UpdateBookMetadata(id uint, fields map[string]any) error
+	GetOrCreateTag(name string, userID uint) (*entities.Tag, error)
+	AddTagToBook(bookID, tagID uint) error
+}
+
+// LibraryImportController imports Goodreads and StoryGraph library CSV exports,
+// filling ratings, shelves and read dates on matching books.
+type LibraryImportController struct {
+	store        LibraryImportStore
+	auditService *audit.Service
+}
+
+func NewLibraryImportController(store LibraryImportStore, auditService *audit.Service) *LibraryImportController {
+	return &LibraryImportController{
+		store:        store,
+		auditService: auditService,
+	}
+}
+
+type LibraryImportResult struct {
+	Success bool     `json:"success"`
+	Error   string   `json:"error,omitempty"`
+	Source  string   `json:"source,omitempty"`
+	Total   int      `json:"total"`
+	Matched int      `json:"matched"`
+	Created int      `json:"created"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// Import handles the settings page upload.
+// POST /settings/library/import
+func (c *LibraryImportController) Import(ctx *gin.Context) {
+	status, result := c.importLibrary(ctx)
+	ctx.HTML(status, "library-import-result", result)
+}
+
+// ImportJSON is the JSON API variant of Import.
+// POST /import/library
+func (c *LibraryImportController) ImportJSON(ctx *gin.Context) {
+	status, result := c.importLibrary(ctx)
+	ctx.JSON(status, result)
+}
+
+func (c *LibraryImportController) importLibrary(ctx *gin.Context) (int, *LibraryImportResult) {
+	file, header, err := ctx.Request.FormFile("csv_file")
+	if err != nil {
+		return http.StatusBadRequest, &LibraryImportResult{Error: "No CSV file provided"}
+	}
+	defer file.Close()
+
+	if header.Size > maxLibraryCSVSize {
+		return http.StatusRequestEntityTooLarge, &LibraryImportResult{
+			Error: fmt.Sprintf("File too large (max %d MB)", maxLibraryCSVSize/(1024*1024)),
+		}
+	}
+
+	entries, format, parseErrors, err := goodreads.ParseCSV(io.LimitReader(file, maxLibraryCSVSize+1))
+	if err != nil {
+		if errors.Is(err, goodreads.ErrUnknownFormat) {
+			return http.StatusBadRequest, &LibraryImportResult{Error: "Unrecognized CSV: expected a Goodreads or StoryGraph library export"}
+		}
+		return http.StatusBadRequest, &LibraryImportResult{Error: fmt.Sprintf("Failed to parse CSV: %v", err)}
+	}
+
+	imported, importErr := goodreads.NewImporter(c.store, DefaultUserID).Import(entries, format)
+
+	booksCount := 0
+	if imported != nil {
+		booksCount = imported.Matched + imported.Created
+	}
+
+	// Log the import event
+	if c.auditService != nil {
+		desc := fmt.Sprintf("Imported %d books from %s library export", booksCount, format)
+		c.auditService.LogImport(auth.GetUserID(ctx), string(format), desc, booksCount, 0, importErr)
+	}
+
+	if importErr != nil {
+		return http.StatusInternalServerError, &LibraryImportResult{Error: fmt.Sprintf("Failed to import: %v", importErr)}
+	}
+
+	return http.StatusOK, &LibraryImportResult{
+		Success: true,
+		Source:  string(format),
+		Total:   imported.Total,
+		Matched: imported.Matched,
+		Created: imported.Created,
+		Errors:  append(parseErrors, imported.Errors...),
+	}
+}
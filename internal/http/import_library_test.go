@@ -0,0 +1,93 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func postLibraryCSV(t *testing.T, router *gin.Engine, path string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("csv_file", "library.csv")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, path, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLibraryImportController_ImportJSON(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, db.SaveBook(&entities.Book{
+		Title:      "Sapiens",
+		Author:     "Yuval Noah Harari",
+		Highlights: []entities.Highlight{{Text: "A highlight"}},
+	}))
+
+	router := gin.New()
+	router.POST("/import/library", NewLibraryImportController(db, nil).ImportJSON)
+
+	data, err := os.ReadFile("../goodreads/testdata/goodreads_library_export.csv")
+	require.NoError(t, err)
+	w := postLibraryCSV(t, router, "/import/library", data)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result LibraryImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	assert.Equal(t, "goodreads", result.Source)
+	assert.Equal(t, 1, result.Matched)
+	assert.Equal(t, 2, result.Created)
+
+	book, err := db.GetBookByTitleAndAuthor("Sapiens", "Yuval Noah Harari")
+	require.NoError(t, err)
+	book, err = db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, book.Rating)
+	assert.Equal(t, "9780062316097", book.ISBN)
+	require.NotNil(t, book.DateRead)
+	assert.Len(t, book.Highlights, 1)
+
+	var tagNames []string
+	for _, tag := range book.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+	assert.ElementsMatch(t, []string{"read", "favorites", "history"}, tagNames)
+
+	stub, err := db.GetBookByTitleAndAuthor("Dune (Dune, #1)", "Frank Herbert")
+	require.NoError(t, err)
+	assert.Equal(t, "goodreads", stub.Source.Name)
+	assert.Equal(t, 4.0, stub.Rating)
+}
+
+func TestLibraryImportController_RejectsUnknownCSV(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	router := gin.New()
+	router.POST("/import/library", NewLibraryImportController(db, nil).ImportJSON)
+
+	w := postLibraryCSV(t, router, "/import/library", []byte("Highlight,Book Title,Book Author\nText,Title,Author\n"))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Goodreads or StoryGraph")
+}
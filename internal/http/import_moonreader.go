@@ -53,7 +53,7 @@ func NewMoonReaderImportController(exporter exporters.BookExporter, auditService
 func (controller *MoonReaderImportController) Import(c *gin.Context) {
 	var req MoonReaderImportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
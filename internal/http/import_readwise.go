@@ -3,12 +3,15 @@ package http
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mrlokans/assistant/internal/audit"
 	"github.com/mrlokans/assistant/internal/auth"
 	"github.com/mrlokans/assistant/internal/entities"
 	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/utils"
 )
 
 type ReadwiseSingleHighlight struct {
@@ -22,6 +25,9 @@ type ReadwiseSingleHighlight struct {
 	LocationType  string `json:"location_type"`
 	HighlightedAt string `json:"highlighted_at"`
 	Id            string `json:"id"`
+	SourceURL     string `json:"source_url"`     // Article address, for web sources
+	HighlightURL  string `json:"highlight_url"`  // Link to the highlight on the page
+	PublishedDate string `json:"published_date"` // Article publication date, YYYY-MM-DD or RFC 3339
 }
 
 func (highlight ReadwiseSingleHighlight) GroupKey() string {
@@ -55,11 +61,20 @@ func asBooks(req ReadwiseImportRequest) []entities.Book {
 			}
 			bookMap[key] = book
 		}
+		if book.URL == "" && utils.IsWebURL(highlight.SourceURL) {
+			book.URL = strings.TrimSpace(highlight.SourceURL)
+		}
+		if book.PublishedAt == nil {
+			book.PublishedAt = parseArticleDate(highlight.PublishedDate)
+		}
 		newHighlight := entities.Highlight{
 			Time: highlight.HighlightedAt,
 			Text: highlight.Text,
 			Page: highlight.Page,
 		}
+		if utils.IsWebURL(highlight.HighlightURL) {
+			newHighlight.URL = strings.TrimSpace(highlight.HighlightURL)
+		}
 		book.Highlights = append(book.Highlights, newHighlight)
 		bookMap[key] = book
 	}
@@ -69,8 +84,25 @@ func asBooks(req ReadwiseImportRequest) []entities.Book {
 	return books
 }
 
+// parseArticleDate parses a publication date sent as a date or a timestamp,
+// returning nil when it is missing or malformed.
+func parseArticleDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return &date
+		}
+	}
+	return nil
+}
+
 func asResponse(result exporters.ExportResult) ReadwiseImportResponse {
-	return ReadwiseImportResponse(result)
+	return ReadwiseImportResponse{
+		BooksProcessed:      result.BooksProcessed,
+		HighlightsProcessed: result.HighlightsProcessed,
+		BooksFailed:         result.BooksFailed,
+		HighlightsFailed:    result.HighlightsFailed,
+	}
 }
 
 type ReadwiseAPIImportController struct {
@@ -107,7 +139,7 @@ func (controller ReadwiseAPIImportController) Import(c *gin.Context) {
 
 	var req ReadwiseImportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
@@ -14,6 +14,7 @@ import (
 	"github.com/mrlokans/assistant/internal/auth"
 	"github.com/mrlokans/assistant/internal/entities"
 	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/importers"
 )
 
 type ReadwiseCSVImportController struct {
@@ -286,3 +287,94 @@ func parseReadwiseTimestamp(ts string) (time.Time, error) {
 
 	return time.Time{}, fmt.Errorf("unable to parse timestamp: %s", ts)
 }
+
+// maxReadwiseLibrarySize caps uploads of Readwise library exports
+const maxReadwiseLibrarySize = 200 * 1024 * 1024 // 200 MB
+
+// ReadwiseLibraryImportResult is the outcome of importing a Readwise library export.
+type ReadwiseLibraryImportResult struct {
+	Success            bool                        `json:"success"`
+	Error              string                      `json:"error,omitempty"`
+	BooksImported      int                         `json:"books_imported"`
+	HighlightsImported int                         `json:"highlights_imported"`
+	Files              []entities.ImportFileResult `json:"files,omitempty"`
+	Errors             []string                    `json:"errors,omitempty"`
+}
+
+// ImportLibrary imports a Readwise "Full Library" export: a zip of markdown
+// and CSV files, imported together as one import session.
+// POST /settings/readwise/import-library
+func (c *ReadwiseCSVImportController) ImportLibrary(ctx *gin.Context) {
+	status, result := c.importLibrary(ctx, "Readwise library export")
+	ctx.HTML(status, "readwise-library-import-result", result)
+}
+
+// ImportLibraryJSON is the JSON API variant of ImportLibrary.
+// POST /import/readwise/library
+func (c *ReadwiseCSVImportController) ImportLibraryJSON(ctx *gin.Context) {
+	status, result := c.importLibrary(ctx, "Readwise library export (JSON)")
+	ctx.JSON(status, result)
+}
+
+func (c *ReadwiseCSVImportController) importLibrary(ctx *gin.Context, sourceLabel string) (int, *ReadwiseLibraryImportResult) {
+	file, header, err := ctx.Request.FormFile("library_file")
+	if err != nil {
+		return http.StatusBadRequest, &ReadwiseLibraryImportResult{
+			Success: false,
+			Error:   "No library export provided",
+		}
+	}
+	defer file.Close()
+
+	if header.Size > maxReadwiseLibrarySize {
+		return http.StatusRequestEntityTooLarge, &ReadwiseLibraryImportResult{
+			Success: false,
+			Error:   fmt.Sprintf("File too large (max %d MB)", maxReadwiseLibrarySize/(1024*1024)),
+		}
+	}
+
+	library, err := importers.ParseReadwiseLibrary(file, header.Size)
+	if err != nil {
+		return http.StatusBadRequest, &ReadwiseLibraryImportResult{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to read library export: %v", err),
+		}
+	}
+
+	result := &ReadwiseLibraryImportResult{
+		Success: true,
+		Files:   library.Files,
+		Errors:  library.Warnings,
+	}
+	if len(library.Books) == 0 {
+		result.Errors = append(result.Errors, "No highlights found in the library export")
+		return http.StatusOK, result
+	}
+
+	// All files form one import session, which keeps the per-file results
+	var exportResult exporters.ExportResult
+	var exportErr error
+	if fileExporter, ok := c.exporter.(exporters.MultiFileExporter); ok {
+		exportResult, exportErr = fileExporter.ExportFiles(library.Books, library.Files)
+	} else {
+		exportResult, exportErr = c.exporter.Export(library.Books)
+	}
+
+	// Log the import event
+	if c.auditService != nil {
+		desc := fmt.Sprintf("Imported %d books with %d highlights from %d files of a %s", exportResult.BooksProcessed, exportResult.HighlightsProcessed, len(library.Files), sourceLabel)
+		c.auditService.LogImport(auth.GetUserID(ctx), "readwise_library", desc, exportResult.BooksProcessed, exportResult.HighlightsProcessed, exportErr)
+	}
+
+	if exportErr != nil {
+		return http.StatusInternalServerError, &ReadwiseLibraryImportResult{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to export: %v", exportErr),
+			Files:   library.Files,
+		}
+	}
+
+	result.BooksImported = exportResult.BooksProcessed
+	result.HighlightsImported = exportResult.HighlightsProcessed
+	return http.StatusOK, result
+}
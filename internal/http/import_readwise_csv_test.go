@@ -0,0 +1,93 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+)
+
+func postReadwiseLibrary(t *testing.T, router *gin.Engine, files map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("library_file", "readwise.zip")
+	require.NoError(t, err)
+	_, err = part.Write(archive.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/import/readwise/library", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestReadwiseCSVImportController_ImportLibraryJSON(t *testing.T) {
+	dbPath := "./test_readwise_library.db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	exporter := exporters.NewDatabaseMarkdownExporter(db, "")
+	router := gin.New()
+	router.POST("/import/readwise/library", NewReadwiseCSVImportController(exporter, nil).ImportLibraryJSON)
+
+	w := postReadwiseLibrary(t, router, map[string]string{
+		"Books/Meditations.md": "# Meditations\n\n## Metadata\n- Author: [[Marcus Aurelius]]\n\n## Highlights\n" +
+			"- You have power over your mind. ([Location 12](https://readwise.io))\n- Waste no more time.\n",
+		"Books/Empty.md": "nothing to see\n",
+		"export.csv":     "Highlight,Book Title,Book Author\nStay hungry,Speeches,Steve Jobs\n",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result ReadwiseLibraryImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, result.BooksImported)
+	assert.Equal(t, 3, result.HighlightsImported)
+	assert.Len(t, result.Files, 3)
+
+	book, err := db.GetBookByTitleAndAuthor("Meditations", "Marcus Aurelius")
+	require.NoError(t, err)
+	assert.Len(t, book.Highlights, 2)
+
+	// The whole archive is one import session keeping the per-file results
+	sessions, err := db.GetImportSessionsForUser(0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, 3, sessions[0].HighlightsProcessed)
+	var files []entities.ImportFileResult
+	require.NoError(t, json.Unmarshal([]byte(sessions[0].Files), &files))
+	assert.Len(t, files, 3)
+
+	// An archive without markdown or CSV files is rejected
+	w = postReadwiseLibrary(t, router, map[string]string{"cover.png": "binary"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
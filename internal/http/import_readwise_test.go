@@ -177,3 +177,29 @@ func TestReadwiseHandler(t *testing.T) {
 	})
 
 }
+
+func TestAsBooks_ArticleFields(t *testing.T) {
+	books := asBooks(ReadwiseImportRequest{Highlights: []ReadwiseSingleHighlight{
+		{Text: "First", Title: "The Bitter Lesson", Author: "Rich Sutton", Category: "articles",
+			SourceURL: "http://www.incompleteideas.net/IncIdeas/BitterLesson.html", PublishedDate: "2019-03-13",
+			HighlightURL: "http://www.incompleteideas.net/IncIdeas/BitterLesson.html#:~:text=First"},
+		{Text: "Second", Title: "The Bitter Lesson", Author: "Rich Sutton", HighlightURL: "javascript:alert(1)"},
+		{Text: "Kindle", Title: "Dune", Author: "Frank Herbert", SourceURL: "kindle://book?action=open", PublishedDate: "soon"},
+	}})
+
+	byTitle := make(map[string]entities.Book)
+	for _, book := range books {
+		byTitle[book.Title] = book
+	}
+
+	article := byTitle["The Bitter Lesson"]
+	assert.Equal(t, "http://www.incompleteideas.net/IncIdeas/BitterLesson.html", article.URL)
+	if assert.NotNil(t, article.PublishedAt) {
+		assert.Equal(t, "2019-03-13", article.PublishedAt.Format("2006-01-02"))
+	}
+	assert.Equal(t, "http://www.incompleteideas.net/IncIdeas/BitterLesson.html#:~:text=First", article.Highlights[0].URL)
+	assert.Empty(t, article.Highlights[1].URL, "only web links are kept")
+
+	assert.Empty(t, byTitle["Dune"].URL)
+	assert.Nil(t, byTitle["Dune"].PublishedAt)
+}
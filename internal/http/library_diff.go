@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// LibraryDiffStore defines database operations for comparing a library between two dates.
+type LibraryDiffStore interface {
+	LibraryDiff(userID uint, from, to time.Time) (*entities.LibraryDiff, error)
+}
+
+// LibraryDiffController reports what changed in the library over a period,
+// e.g. for a monthly review of what was read.
+type LibraryDiffController struct {
+	store LibraryDiffStore
+}
+
+func NewLibraryDiffController(store LibraryDiffStore) *LibraryDiffController {
+	return &LibraryDiffController{store: store}
+}
+
+// GetDiff returns the books and highlights added, the highlights and journal
+// entries edited, and the books and highlights trashed or permanently deleted
+// between from and to. Dates are YYYY-MM-DD or RFC 3339; a plain "to" date
+// includes the whole day. The period defaults to the current month so far.
+// GET /api/library/diff
+func (dc *LibraryDiffController) GetDiff(c *gin.Context) {
+	from, err := parseFilterDate(c, "from", false)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	to, err := parseFilterDate(c, "to", true)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	now := time.Now().In(GetUserPreferences(c).Location)
+	if from == nil {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		from = &start
+	}
+	if to == nil {
+		to = &now
+	}
+	if !from.Before(*to) {
+		respondBadRequest(c, "from must be before to")
+		return
+	}
+
+	diff, err := dc.store.LibraryDiff(GetUserID(c), *from, *to)
+	if err != nil {
+		respondInternalError(c, err, "compare library")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"diff": diff,
+		"counts": gin.H{
+			"new_books":          len(diff.NewBooks),
+			"new_highlights":     len(diff.NewHighlights),
+			"edited_highlights":  len(diff.EditedHighlights),
+			"new_notes":          len(diff.NewNotes),
+			"edited_notes":       len(diff.EditedNotes),
+			"trashed_books":      len(diff.TrashedBooks),
+			"trashed_highlights": len(diff.TrashedHighlights),
+			"deleted_books":      len(diff.DeletedBooks),
+			"deleted_highlights": len(diff.DeletedHighlights),
+		},
+	})
+}
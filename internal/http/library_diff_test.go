@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLibraryDiffTest(t *testing.T) (*database.Database, *gin.Engine) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dbPath := "./test_library_diff_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(dbPath)
+	})
+
+	controller := NewLibraryDiffController(db)
+	router := gin.New()
+	router.GET("/api/library/diff", controller.GetDiff)
+	return db, router
+}
+
+func TestLibraryDiffController(t *testing.T) {
+	t.Run("defaults to the current month", func(t *testing.T) {
+		db, router := setupLibraryDiffTest(t)
+		require.NoError(t, db.SaveBook(&entities.Book{
+			Title:      "Book",
+			Author:     "Author",
+			Highlights: []entities.Highlight{{Text: "One"}, {Text: "Two"}},
+		}))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/library/diff", nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Diff   entities.LibraryDiff `json:"diff"`
+			Counts map[string]int       `json:"counts"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 1, resp.Counts["new_books"])
+		assert.Equal(t, 2, resp.Counts["new_highlights"])
+		require.Len(t, resp.Diff.NewHighlights, 2)
+		assert.Equal(t, "Book", resp.Diff.NewHighlights[0].BookTitle)
+		assert.Equal(t, 1, resp.Diff.From.Day())
+	})
+
+	t.Run("includes the whole of a plain to date", func(t *testing.T) {
+		db, router := setupLibraryDiffTest(t)
+		require.NoError(t, db.SaveBook(&entities.Book{Title: "Book", Author: "Author"}))
+
+		today := time.Now().Format("2006-01-02")
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/library/diff?from="+today+"&to="+today, nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"new_books":1`)
+	})
+
+	t.Run("rejects invalid periods", func(t *testing.T) {
+		_, router := setupLibraryDiffTest(t)
+
+		for _, query := range []string{"from=yesterday", "from=2024-02-01&to=2024-01-01"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/library/diff?"+query, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// maintenanceHistorySize is how many past runs the health page lists.
+const maintenanceHistorySize = 20
+
+// MaintenanceStore defines database operations for maintenance jobs and
+// their recorded runs.
+type MaintenanceStore interface {
+	RunMaintenance(task entities.MaintenanceTask) (*entities.MaintenanceRun, error)
+	GetLatestMaintenanceRuns() ([]entities.MaintenanceRun, error)
+	GetMaintenanceRuns(limit int) ([]entities.MaintenanceRun, error)
+}
+
+// MaintenanceJob is a maintenance job with its latest run, nil if it has
+// never run.
+type MaintenanceJob struct {
+	Task        entities.MaintenanceTask `json:"task"`
+	Description string                   `json:"description"`
+	LastRun     *entities.MaintenanceRun `json:"last_run"`
+}
+
+// MaintenanceController shows database health and runs maintenance jobs
+// on demand, besides the scheduled runs.
+type MaintenanceController struct {
+	store MaintenanceStore
+}
+
+func NewMaintenanceController(store MaintenanceStore) *MaintenanceController {
+	return &MaintenanceController{store: store}
+}
+
+func (mc *MaintenanceController) loadStatus() (gin.H, error) {
+	latest, err := mc.store.GetLatestMaintenanceRuns()
+	if err != nil {
+		return nil, err
+	}
+	history, err := mc.store.GetMaintenanceRuns(maintenanceHistorySize)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]MaintenanceJob, 0, len(entities.MaintenanceTasks))
+	failing := 0
+	for _, task := range entities.MaintenanceTasks {
+		job := MaintenanceJob{Task: task, Description: task.Description()}
+		for i := range latest {
+			if latest[i].Task == task {
+				job.LastRun = &latest[i]
+			}
+		}
+		if job.LastRun != nil && job.LastRun.Status == entities.MaintenanceStatusFailed {
+			failing++
+		}
+		jobs = append(jobs, job)
+	}
+
+	return gin.H{
+		"Jobs":    jobs,
+		"History": history,
+		"Failing": failing,
+	}, nil
+}
+
+// GetStatus returns the latest run of each maintenance job and the recent runs.
+// GET /api/admin/maintenance
+func (mc *MaintenanceController) GetStatus(c *gin.Context) {
+	data, err := mc.loadStatus()
+	if err != nil {
+		respondInternalError(c, err, "get maintenance status")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "admin-health-list", data)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":    data["Jobs"],
+		"history": data["History"],
+		"failing": data["Failing"],
+	})
+}
+
+// RunTask runs a maintenance job now and returns its recorded run. A job
+// that fails is still recorded and returned, with status "failed".
+// POST /api/admin/maintenance/:task
+func (mc *MaintenanceController) RunTask(c *gin.Context) {
+	task := entities.MaintenanceTask(c.Param("task"))
+	if !slices.Contains(entities.MaintenanceTasks, task) {
+		respondBadRequest(c, fmt.Sprintf("unknown maintenance task %q", task))
+		return
+	}
+
+	run, err := mc.store.RunMaintenance(task)
+	if err != nil {
+		respondInternalError(c, err, "run maintenance")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		mc.GetStatus(c)
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// HealthPage renders the admin health page.
+// GET /admin/health
+func (mc *MaintenanceController) HealthPage(c *gin.Context) {
+	data, err := mc.loadStatus()
+	if err != nil {
+		respondInternalError(c, err, "load health page")
+		return
+	}
+
+	data["Auth"] = GetAuthTemplateData(c)
+	data["UI"] = GetUIPreferences(c)
+	data["Demo"] = GetDemoTemplateData(c)
+	data["Analytics"] = GetAnalyticsTemplateData(c)
+	c.HTML(http.StatusOK, "admin-health", data)
+}
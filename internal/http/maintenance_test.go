@@ -0,0 +1,79 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestMaintenanceController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dbPath := "./test_maintenance.db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	renderer, err := newLocalizedHTML("../../templates/*.html", templateFuncs(NewStaticAssets("../../static"), ""))
+	require.NoError(t, err)
+	controller := NewMaintenanceController(db)
+	router := gin.New()
+	router.HTMLRender = renderer
+	router.GET("/admin/health", controller.HealthPage)
+	router.GET("/api/admin/maintenance", controller.GetStatus)
+	router.POST("/api/admin/maintenance/:task", controller.RunTask)
+
+	request := func(method, path string, htmx bool) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/api/admin/maintenance/reindex", false).Code)
+
+	w := request(http.MethodPost, "/api/admin/maintenance/integrity_check", false)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var run entities.MaintenanceRun
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+	assert.Equal(t, entities.MaintenanceIntegrityCheck, run.Task)
+	assert.Equal(t, entities.MaintenanceStatusOK, run.Status)
+
+	w = request(http.MethodGet, "/api/admin/maintenance", false)
+	require.Equal(t, http.StatusOK, w.Code)
+	var status struct {
+		Jobs    []MaintenanceJob          `json:"jobs"`
+		History []entities.MaintenanceRun `json:"history"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Len(t, status.Jobs, len(entities.MaintenanceTasks))
+	require.NotNil(t, status.Jobs[0].LastRun)
+	assert.Equal(t, "No problems found", status.Jobs[0].LastRun.Result)
+	assert.Nil(t, status.Jobs[1].LastRun, "the vacuum has not run yet")
+	assert.Len(t, status.History, 1)
+
+	// Running a job from the health page refreshes its list
+	w = request(http.MethodPost, "/api/admin/maintenance/orphan_tags", true)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Deleted 0 orphan tags")
+
+	w = request(http.MethodGet, "/admin/health", false)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Database Health")
+	assert.Contains(t, w.Body.String(), `hx-post="/api/admin/maintenance/vacuum"`)
+	assert.Contains(t, w.Body.String(), "never run")
+}
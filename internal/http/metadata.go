@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,17 +11,26 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
 	"github.com/mrlokans/assistant/internal/database"
 	"github.com/mrlokans/assistant/internal/entities"
 	"github.com/mrlokans/assistant/internal/metadata"
 	"github.com/mrlokans/assistant/internal/tasks"
 )
 
+// ManualBookStore creates books added by hand.
+type ManualBookStore interface {
+	FindBookByISBN(isbn string, userID uint) (*entities.Book, error)
+	SaveBook(book *entities.Book) error
+}
+
 // MetadataController handles book metadata enrichment endpoints.
 type MetadataController struct {
 	enricher     *metadata.Enricher
 	syncProgress *database.MetadataSyncProgress
 	taskClient   *tasks.Client
+	bookStore    ManualBookStore
 }
 
 // NewMetadataController creates a new MetadataController.
@@ -32,6 +42,12 @@ func NewMetadataController(enricher *metadata.Enricher, syncProgress *database.M
 	}
 }
 
+// WithManualBookStore enables creating books from an ISBN lookup.
+func (mc *MetadataController) WithManualBookStore(store ManualBookStore) *MetadataController {
+	mc.bookStore = store
+	return mc
+}
+
 // EnrichBookRequest is the request body for enriching a book.
 type EnrichBookRequest struct {
 	ISBN string `json:"isbn,omitempty"`
@@ -44,6 +60,8 @@ type EnrichBookResponse struct {
 	FieldsUpdated []string `json:"fields_updated,omitempty"`
 	Source        string   `json:"source,omitempty"`
 	SearchMethod  string   `json:"search_method,omitempty"`
+	Confidence    float64  `json:"confidence,omitempty"`
+	ReviewFields  []string `json:"review_fields,omitempty"` // Held for review on the /metadata/reviews page
 	Error         string   `json:"error,omitempty"`
 }
 
@@ -81,6 +99,9 @@ func (mc *MetadataController) EnrichBook(c *gin.Context) {
 		result, err = mc.enricher.EnrichBook(ctx, uint(id))
 	}
 
+	if errors.Is(err, metadata.ErrInvalidISBN) || errors.Is(err, metadata.ErrISBNChecksum) {
+		err = isbnError(err)
+	}
 	if err != nil {
 		mc.respondError(c, err.Error())
 		return
@@ -112,6 +133,9 @@ func (mc *MetadataController) respondSuccess(c *gin.Context, result *metadata.En
 		if len(result.FieldsUpdated) > 0 {
 			fieldsMsg = fmt.Sprintf("Updated: %s", strings.Join(result.FieldsUpdated, ", "))
 		}
+		if len(result.ReviewFields) > 0 {
+			fieldsMsg += fmt.Sprintf("; uncertain match, %s held for review", strings.Join(result.ReviewFields, ", "))
+		}
 
 		html := fmt.Sprintf(`<div class="enrichment-success">
 			<svg xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round"><path d="M22 11.08V12a10 10 0 1 1-5.93-9.14"/><polyline points="22 4 12 14.01 9 11.01"/></svg>
@@ -132,6 +156,8 @@ func (mc *MetadataController) respondSuccess(c *gin.Context, result *metadata.En
 		FieldsUpdated: result.FieldsUpdated,
 		Source:        result.Source,
 		SearchMethod:  result.SearchMethod,
+		Confidence:    result.Confidence,
+		ReviewFields:  result.ReviewFields,
 	})
 }
 
@@ -227,6 +253,10 @@ func (mc *MetadataController) UpdateISBN(c *gin.Context) {
 	defer cancel()
 
 	result, err := mc.enricher.EnrichBookWithISBN(ctx, uint(id), req.ISBN)
+	if errors.Is(err, metadata.ErrInvalidISBN) || errors.Is(err, metadata.ErrISBNChecksum) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": isbnError(err).Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -239,6 +269,87 @@ func (mc *MetadataController) UpdateISBN(c *gin.Context) {
 	})
 }
 
+// isbnError explains why an ISBN failed validation.
+func isbnError(err error) error {
+	if errors.Is(err, metadata.ErrISBNChecksum) {
+		return fmt.Errorf("isbn check digit does not match, check it for typos")
+	}
+	return fmt.Errorf("isbn must have 10 or 13 digits")
+}
+
+// CreateManualBookRequest is the request body for adding a book by ISBN.
+// Title and author are only used when no metadata is found for the ISBN.
+type CreateManualBookRequest struct {
+	ISBN   string `json:"isbn" form:"isbn"`
+	Title  string `json:"title,omitempty" form:"title"`
+	Author string `json:"author,omitempty" form:"author"`
+}
+
+// CreateManualBook handles POST /api/books/manual
+// It creates a book from an ISBN (e.g. scanned from a barcode), pre-filled with
+// metadata from the provider, so highlights from paper books can be added by hand.
+func (mc *MetadataController) CreateManualBook(c *gin.Context) {
+	var req CreateManualBookRequest
+	if err := c.ShouldBind(&req); err != nil || strings.TrimSpace(req.ISBN) == "" {
+		respondBadRequest(c, "isbn is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	book := &entities.Book{
+		Title:  strings.TrimSpace(req.Title),
+		Author: strings.TrimSpace(req.Author),
+		Source: entities.Source{Name: "manual"},
+	}
+
+	meta, err := mc.enricher.LookupISBN(ctx, req.ISBN)
+	switch {
+	case errors.Is(err, metadata.ErrInvalidISBN), errors.Is(err, metadata.ErrISBNChecksum):
+		respondBadRequest(c, isbnError(err).Error())
+		return
+	case err != nil && book.Title == "":
+		respondError(c, http.StatusNotFound, "no metadata found for this ISBN, provide title and author")
+		return
+	case err != nil:
+		log.Printf("ISBN lookup failed for manual book %q: %v", book.Title, err)
+		book.ISBN, _ = metadata.ParseISBN(req.ISBN)
+	default:
+		book.ISBN = meta.ISBN
+		if meta.Title != "" {
+			book.Title = meta.Title
+		}
+		if meta.Author != "" {
+			book.Author = meta.Author
+		}
+		book.CoverURL = meta.CoverURL
+		book.Publisher = meta.Publisher
+		book.PublicationYear = meta.PublicationYear
+	}
+
+	existing, err := mc.bookStore.FindBookByISBN(book.ISBN, DefaultUserID)
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "a book with this ISBN already exists", "book": existing})
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		respondInternalError(c, err, "find book by ISBN")
+		return
+	}
+
+	if err := mc.bookStore.SaveBook(book); err != nil {
+		respondInternalError(c, err, "create manual book")
+		return
+	}
+	if book.ID == 0 {
+		respondError(c, http.StatusConflict, "this book was permanently deleted")
+		return
+	}
+
+	respondCreated(c, book)
+}
+
 // SyncStatusResponse represents the metadata sync status.
 type SyncStatusResponse struct {
 	Running     bool    `json:"running"`
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
+	"gorm.io/gorm"
+)
+
+// MetadataReviewStore defines database operations for metadata matches held
+// for review.
+type MetadataReviewStore interface {
+	GetPendingMetadataReviews() ([]entities.MetadataReview, error)
+	AcceptMetadataReview(id uint) (*entities.Book, []string, error)
+	RejectMetadataReview(id uint) error
+}
+
+type MetadataReviewController struct {
+	store            MetadataReviewStore
+	coverInvalidator BookCoverInvalidator
+}
+
+func NewMetadataReviewController(store MetadataReviewStore) *MetadataReviewController {
+	return &MetadataReviewController{store: store}
+}
+
+// WithCoverInvalidator clears cached covers when an accepted review changes the cover URL.
+func (rc *MetadataReviewController) WithCoverInvalidator(invalidator BookCoverInvalidator) *MetadataReviewController {
+	rc.coverInvalidator = invalidator
+	return rc
+}
+
+// ListReviews returns the metadata matches waiting for review.
+// GET /api/metadata/reviews
+func (rc *MetadataReviewController) ListReviews(c *gin.Context) {
+	reviews, err := rc.store.GetPendingMetadataReviews()
+	if err != nil {
+		respondInternalError(c, err, "list metadata reviews")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "metadata-review-list", gin.H{"Reviews": reviews})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reviews": reviews})
+}
+
+// AcceptReview applies the metadata of a held match to its book.
+// POST /api/metadata/reviews/:id/accept
+func (rc *MetadataReviewController) AcceptReview(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	book, fields, err := rc.store.AcceptMetadataReview(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondNotFound(c, "pending metadata review")
+			return
+		}
+		respondInternalError(c, err, "accept metadata review")
+		return
+	}
+
+	if rc.coverInvalidator != nil {
+		for _, field := range fields {
+			if field == "cover_url" {
+				_ = rc.coverInvalidator.InvalidateCover(book.ID)
+			}
+		}
+	}
+
+	rc.respondUpdated(c, "Metadata applied to "+book.Title, gin.H{
+		"message":        "metadata applied",
+		"book":           book,
+		"fields_updated": fields,
+	})
+}
+
+// RejectReview discards a held match, leaving its book unchanged. The same
+// match is not proposed for the book again.
+// POST /api/metadata/reviews/:id/reject
+func (rc *MetadataReviewController) RejectReview(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := rc.store.RejectMetadataReview(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondNotFound(c, "pending metadata review")
+			return
+		}
+		respondInternalError(c, err, "reject metadata review")
+		return
+	}
+
+	rc.respondUpdated(c, "Match rejected", gin.H{"message": "match rejected"})
+}
+
+// ReviewsPage renders the list of metadata matches waiting for review.
+// GET /metadata/reviews
+func (rc *MetadataReviewController) ReviewsPage(c *gin.Context) {
+	reviews, err := rc.store.GetPendingMetadataReviews()
+	if err != nil {
+		respondInternalError(c, err, "load metadata reviews page")
+		return
+	}
+
+	c.HTML(http.StatusOK, "metadata-reviews", gin.H{
+		"Reviews":   reviews,
+		"Auth":      GetAuthTemplateData(c),
+		"UI":        GetUIPreferences(c),
+		"Demo":      GetDemoTemplateData(c),
+		"Analytics": GetAnalyticsTemplateData(c),
+	})
+}
+
+// respondUpdated re-renders the review list for HTMX requests, or returns the JSON payload.
+func (rc *MetadataReviewController) respondUpdated(c *gin.Context, message string, payload gin.H) {
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, payload)
+		return
+	}
+
+	reviews, err := rc.store.GetPendingMetadataReviews()
+	if err != nil {
+		respondInternalError(c, err, "list metadata reviews")
+		return
+	}
+	c.HTML(http.StatusOK, "metadata-review-list", gin.H{"Reviews": reviews, "Message": message})
+}
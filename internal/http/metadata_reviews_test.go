@@ -0,0 +1,75 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestMetadataReviewController(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "The Road", Author: "Cormac McCarthy", UserID: DefaultUserID}
+	require.NoError(t, db.SaveBook(book))
+	queue := func(publisher string) *entities.MetadataReview {
+		review := &entities.MetadataReview{BookID: book.ID, UserID: DefaultUserID, Confidence: 0.4, SearchMethod: "title",
+			MatchedTitle: "The Road to " + publisher, MatchedAuthor: "Friedrich Hayek", Publisher: publisher, PublicationYear: 1944}
+		require.NoError(t, db.QueueMetadataReview(review))
+		return review
+	}
+
+	renderer, err := newLocalizedHTML("../../templates/*.html", templateFuncs(NewStaticAssets("../../static"), ""))
+	require.NoError(t, err)
+	router := gin.New()
+	router.HTMLRender = renderer
+	controller := NewMetadataReviewController(db)
+	router.GET("/api/metadata/reviews", controller.ListReviews)
+	router.POST("/api/metadata/reviews/:id/accept", controller.AcceptReview)
+	router.POST("/api/metadata/reviews/:id/reject", controller.RejectReview)
+
+	post := func(url string, htmx bool) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	rejected := queue("Serfdom")
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/metadata/reviews", nil)
+	req.Header.Set("HX-Request", "true")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "The Road to Serfdom")
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`hx-post="/api/metadata/reviews/%d/accept"`, rejected.ID))
+
+	w = post(fmt.Sprintf("/api/metadata/reviews/%d/reject", rejected.ID), true)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Nothing to review")
+
+	accepted := queue("Chicago")
+	w = post(fmt.Sprintf("/api/metadata/reviews/%d/accept", accepted.ID), false)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Book          entities.Book `json:"book"`
+		FieldsUpdated []string      `json:"fields_updated"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Chicago", resp.Book.Publisher)
+	assert.Equal(t, []string{"publisher", "publication_year"}, resp.FieldsUpdated)
+
+	assert.Equal(t, http.StatusNotFound, post(fmt.Sprintf("/api/metadata/reviews/%d/accept", accepted.ID), false).Code)
+	assert.Equal(t, http.StatusNotFound, post(fmt.Sprintf("/api/metadata/reviews/%d/reject", rejected.ID), false).Code)
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+type fakeISBNProvider struct {
+	books map[string]*metadata.BookMetadata
+}
+
+func (p *fakeISBNProvider) SearchByISBN(ctx context.Context, isbn string) (*metadata.BookMetadata, error) {
+	if book, ok := p.books[isbn]; ok {
+		return book, nil
+	}
+	return nil, errors.New("ISBN not found")
+}
+
+func (p *fakeISBNProvider) SearchByTitle(ctx context.Context, title, author string) (*metadata.BookMetadata, error) {
+	return nil, errors.New("no results found")
+}
+
+func postManualBook(router *gin.Engine, body map[string]string) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/books/manual", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateManualBook(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	provider := &fakeISBNProvider{books: map[string]*metadata.BookMetadata{
+		"9780441172719": {
+			Title:           "Dune",
+			Author:          "Frank Herbert",
+			Publisher:       "Ace",
+			PublicationYear: 1990,
+			CoverURL:        "https://covers.openlibrary.org/b/isbn/9780441172719-L.jpg",
+		},
+	}}
+	controller := NewMetadataController(metadata.NewEnricher(provider, nil), nil, nil).WithManualBookStore(db)
+	router := gin.New()
+	router.POST("/api/books/manual", controller.CreateManualBook)
+
+	t.Run("creates book from ISBN metadata", func(t *testing.T) {
+		w := postManualBook(router, map[string]string{"isbn": "978-0-441-17271-9"})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var book entities.Book
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &book))
+		assert.NotZero(t, book.ID)
+		assert.Equal(t, "Dune", book.Title)
+		assert.Equal(t, "Frank Herbert", book.Author)
+		assert.Equal(t, "9780441172719", book.ISBN)
+		assert.Equal(t, "Ace", book.Publisher)
+		assert.Equal(t, 1990, book.PublicationYear)
+	})
+
+	t.Run("rejects duplicate ISBN", func(t *testing.T) {
+		w := postManualBook(router, map[string]string{"isbn": "9780441172719"})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("unknown ISBN without title", func(t *testing.T) {
+		w := postManualBook(router, map[string]string{"isbn": "9780000000002"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("unknown ISBN falls back to given title", func(t *testing.T) {
+		w := postManualBook(router, map[string]string{"isbn": "9780000000002", "title": "Local Zine", "author": "Anonymous"})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		book, err := db.FindBookByISBN("9780000000002", DefaultUserID)
+		require.NoError(t, err)
+		assert.Equal(t, "Local Zine", book.Title)
+	})
+
+	t.Run("invalid ISBN", func(t *testing.T) {
+		w := postManualBook(router, map[string]string{"isbn": "12345"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+type failingManualBookStore struct{}
+
+func (failingManualBookStore) FindBookByISBN(string, uint) (*entities.Book, error) {
+	return nil, errors.New("database is locked")
+}
+
+func (failingManualBookStore) SaveBook(*entities.Book) error {
+	return errors.New("should not be called")
+}
+
+func TestCreateManualBook_LookupError(t *testing.T) {
+	provider := &fakeISBNProvider{books: map[string]*metadata.BookMetadata{
+		"9780441172719": {Title: "Dune", Author: "Frank Herbert"},
+	}}
+	controller := NewMetadataController(metadata.NewEnricher(provider, nil), nil, nil).WithManualBookStore(failingManualBookStore{})
+	router := gin.New()
+	router.POST("/api/books/manual", controller.CreateManualBook)
+
+	w := postManualBook(router, map[string]string{"isbn": "9780441172719"})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/webdav"
+
+	"github.com/mrlokans/assistant/internal/audit"
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/moonreader"
+)
+
+// MoonReaderWebDAVPrefix is the WebDAV address Moon+ Reader backs up to.
+const MoonReaderWebDAVPrefix = "/moonreader/webdav"
+
+// webDAVMethods are the HTTP methods used by WebDAV clients.
+var webDAVMethods = []string{
+	http.MethodOptions, http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete,
+	"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK",
+}
+
+// MoonReaderBackupImporter imports a Moon+ Reader backup file and returns the
+// result with the HTTP status describing it.
+type MoonReaderBackupImporter func(backupPath string) (*MoonReaderImportResult, int)
+
+type webDAVUserKey struct{}
+
+// MoonReaderWebDAVController serves a directory over WebDAV so Moon+ Reader Pro can
+// sync straight to the server, and imports every backup file the app uploads.
+type MoonReaderWebDAVController struct {
+	dir          string
+	handler      *webdav.Handler
+	importer     MoonReaderBackupImporter
+	auditService *audit.Service
+
+	// Imports run in the background one at a time; the app uploads backups
+	// and expects a quick response
+	importMu sync.Mutex
+	imports  sync.WaitGroup
+}
+
+func NewMoonReaderWebDAVController(dir string, importer MoonReaderBackupImporter, auditService *audit.Service) (*MoonReaderWebDAVController, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create WebDAV directory: %w", err)
+	}
+
+	c := &MoonReaderWebDAVController{
+		dir:          dir,
+		importer:     importer,
+		auditService: auditService,
+	}
+	c.handler = &webdav.Handler{
+		Prefix:     MoonReaderWebDAVPrefix,
+		FileSystem: webdav.Dir(dir),
+		LockSystem: webdav.NewMemLS(),
+		Logger:     c.afterRequest,
+	}
+	return c, nil
+}
+
+// RegisterRoutes mounts the WebDAV share for every WebDAV method.
+func (c *MoonReaderWebDAVController) RegisterRoutes(router gin.IRoutes) {
+	for _, method := range webDAVMethods {
+		router.Handle(method, MoonReaderWebDAVPrefix, c.ServeWebDAV)
+		router.Handle(method, MoonReaderWebDAVPrefix+"/*path", c.ServeWebDAV)
+	}
+}
+
+// ServeWebDAV handles a WebDAV request.
+// ANY /moonreader/webdav/*path
+func (c *MoonReaderWebDAVController) ServeWebDAV(ctx *gin.Context) {
+	// The user is needed for the audit log once the request has been handled
+	r := ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), webDAVUserKey{}, auth.GetUserID(ctx)))
+	c.handler.ServeHTTP(ctx.Writer, r)
+}
+
+// afterRequest starts an import when a backup file has been written, either
+// uploaded directly or renamed from a temporary upload.
+func (c *MoonReaderWebDAVController) afterRequest(r *http.Request, err error) {
+	if err != nil {
+		return
+	}
+
+	var name string
+	switch r.Method {
+	case http.MethodPut:
+		name = r.URL.Path
+	case "MOVE", "COPY":
+		dest, err := url.Parse(r.Header.Get("Destination"))
+		if err != nil {
+			return
+		}
+		name = dest.Path
+	default:
+		return
+	}
+
+	name, ok := strings.CutPrefix(name, MoonReaderWebDAVPrefix)
+	if !ok || !moonreader.IsBackupFile(name) {
+		return
+	}
+
+	userID, _ := r.Context().Value(webDAVUserKey{}).(uint)
+	backupPath := filepath.Join(c.dir, filepath.FromSlash(path.Clean("/"+name)))
+
+	c.imports.Add(1)
+	go func() {
+		defer c.imports.Done()
+		c.importBackup(backupPath, userID)
+	}()
+}
+
+func (c *MoonReaderWebDAVController) importBackup(backupPath string, userID uint) {
+	c.importMu.Lock()
+	defer c.importMu.Unlock()
+
+	result, _ := c.importer(backupPath)
+
+	var importErr error
+	if !result.Success {
+		importErr = errors.New(result.Error)
+		log.Printf("Moon+ Reader WebDAV import of %s failed: %s", filepath.Base(backupPath), result.Error)
+	} else {
+		log.Printf("Moon+ Reader WebDAV import of %s: %d highlights from %d books",
+			filepath.Base(backupPath), result.Highlights, result.BooksImported)
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogImport(userID, "moonreader", "WebDAV backup "+filepath.Base(backupPath),
+			result.BooksImported, result.Highlights, importErr)
+	}
+}
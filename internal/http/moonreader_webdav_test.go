@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingBackupImporter struct {
+	mu      sync.Mutex
+	imports []string
+}
+
+func (r *recordingBackupImporter) importBackup(backupPath string) (*MoonReaderImportResult, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	content, err := os.ReadFile(backupPath)
+	if err != nil {
+		return &MoonReaderImportResult{Error: err.Error()}, http.StatusBadRequest
+	}
+	r.imports = append(r.imports, filepath.Base(backupPath)+":"+string(content))
+	return &MoonReaderImportResult{Success: true, BooksImported: 1, Highlights: 2}, http.StatusOK
+}
+
+func setupMoonReaderWebDAV(t *testing.T) (*gin.Engine, *MoonReaderWebDAVController, *recordingBackupImporter, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	importer := &recordingBackupImporter{}
+	controller, err := NewMoonReaderWebDAVController(dir, importer.importBackup, nil)
+	require.NoError(t, err)
+
+	router := gin.New()
+	controller.RegisterRoutes(router)
+	return router, controller, importer, dir
+}
+
+func webDAVRequest(router *gin.Engine, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMoonReaderWebDAV_ImportsUploadedBackups(t *testing.T) {
+	router, controller, importer, dir := setupMoonReaderWebDAV(t)
+
+	w := webDAVRequest(router, "MKCOL", "/moonreader/webdav/Backup", "", nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = webDAVRequest(router, http.MethodPut, "/moonreader/webdav/Backup/2024-01-15_120000.mrpro", "backup", nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// Files other than backups are stored without importing
+	w = webDAVRequest(router, http.MethodPut, "/moonreader/webdav/Backup/positions.po", "position", nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// Uploads renamed into place are imported as well
+	w = webDAVRequest(router, http.MethodPut, "/moonreader/webdav/Backup/upload.tmp", "renamed", nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = webDAVRequest(router, "MOVE", "/moonreader/webdav/Backup/upload.tmp", "", map[string]string{
+		"Destination": "http://example.com/moonreader/webdav/Backup/2024-01-16_120000.mrstd",
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	controller.imports.Wait()
+	assert.ElementsMatch(t, []string{"2024-01-15_120000.mrpro:backup", "2024-01-16_120000.mrstd:renamed"}, importer.imports)
+	assert.FileExists(t, filepath.Join(dir, "Backup", "positions.po"))
+
+	w = webDAVRequest(router, "PROPFIND", "/moonreader/webdav/Backup/", "", map[string]string{"Depth": "1"})
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Contains(t, w.Body.String(), "2024-01-15_120000.mrpro")
+}
+
+func TestMoonReaderWebDAV_Root(t *testing.T) {
+	router, _, _, _ := setupMoonReaderWebDAV(t)
+
+	for _, path := range []string{"/moonreader/webdav", "/moonreader/webdav/"} {
+		w := webDAVRequest(router, "PROPFIND", path, "", map[string]string{"Depth": "0"})
+		assert.Equal(t, http.StatusMultiStatus, w.Code, path)
+	}
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/markdown"
+)
+
+// maxNoteLength caps a highlight note, in characters.
+const maxNoteLength = 20000
+
+// NoteStore defines database operations for editing highlight notes.
+type NoteStore interface {
+	GetHighlightByID(id uint) (*entities.Highlight, error)
+	UpdateHighlight(highlight *entities.Highlight) error
+}
+
+// NotesController handles the Markdown note editor for highlights.
+type NotesController struct {
+	store NoteStore
+}
+
+func NewNotesController(store NoteStore) *NotesController {
+	return &NotesController{store: store}
+}
+
+// NoteRequest is the request body for saving or previewing a note.
+type NoteRequest struct {
+	Note string `json:"note"`
+}
+
+// NoteEditor renders the note editor for a highlight.
+// GET /ui/highlights/:id/note
+func (nc *NotesController) NoteEditor(c *gin.Context) {
+	highlight, ok := nc.getHighlight(c)
+	if !ok {
+		return
+	}
+	c.HTML(http.StatusOK, "note-editor", highlight)
+}
+
+// NoteView renders a highlight's note, e.g. when the editor is closed.
+// GET /ui/highlights/:id/note/view
+func (nc *NotesController) NoteView(c *gin.Context) {
+	highlight, ok := nc.getHighlight(c)
+	if !ok {
+		return
+	}
+	c.HTML(http.StatusOK, "highlight-note", highlight)
+}
+
+// UpdateNote replaces a highlight's note. The previous note is kept in the
+// highlight's edit history. Accepts JSON or form data.
+// PUT /api/highlights/:id/note
+func (nc *NotesController) UpdateNote(c *gin.Context) {
+	highlight, ok := nc.getHighlight(c)
+	if !ok {
+		return
+	}
+	note, ok := bindNote(c)
+	if !ok {
+		return
+	}
+
+	highlight.Note = note
+	if err := nc.store.UpdateHighlight(highlight); err != nil {
+		respondInternalError(c, err, "update note")
+		return
+	}
+
+	respondHTMXOrJSON(c, http.StatusOK, "highlight-note", highlight)
+}
+
+// PreviewNote renders a note as it will be displayed, without saving it.
+// HTMX requests get the HTML fragment, others a JSON object with an "html" field.
+// POST /api/notes/preview
+func (nc *NotesController) PreviewNote(c *gin.Context) {
+	note, ok := bindNote(c)
+	if !ok {
+		return
+	}
+
+	rendered := markdown.ToHTML(note)
+	if isHTMXRequest(c) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(rendered))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"html": rendered})
+}
+
+func (nc *NotesController) getHighlight(c *gin.Context) (*entities.Highlight, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+	highlight, err := nc.store.GetHighlightByID(id)
+	if err != nil {
+		respondNotFound(c, "highlight")
+		return nil, false
+	}
+	return highlight, true
+}
+
+// bindNote reads and normalizes the note from a JSON or form request,
+// responding with an error if it is invalid.
+func bindNote(c *gin.Context) (string, bool) {
+	var req NoteRequest
+	if c.ContentType() == "application/json" {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "invalid request body")
+			return "", false
+		}
+	} else {
+		req.Note = c.PostForm("note")
+	}
+
+	note := markdown.Normalize(req.Note)
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		respondBadRequest(c, fmt.Sprintf("note must be at most %d characters", maxNoteLength))
+		return "", false
+	}
+	return note, true
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestNotesController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dbPath := "./test_notes_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	book := &entities.Book{
+		Title:      "Book",
+		Author:     "Author",
+		Highlights: []entities.Highlight{{Text: "Text", Note: "old note"}},
+	}
+	require.NoError(t, db.SaveBook(book))
+	highlightID := book.Highlights[0].ID
+
+	controller := NewNotesController(db)
+	router := gin.New()
+	router.PUT("/api/highlights/:id/note", controller.UpdateNote)
+	router.POST("/api/notes/preview", controller.PreviewNote)
+
+	t.Run("saves a normalized note and keeps the previous one in history", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/highlights/%d/note", highlightID),
+			strings.NewReader(`{"note": "**new** note  \r\n- item\r\n"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		highlight, err := db.GetHighlightByID(highlightID)
+		require.NoError(t, err)
+		assert.Equal(t, "**new** note\n- item", highlight.Note)
+
+		versions, err := db.GetHighlightHistory(highlightID)
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		assert.Equal(t, "old note", versions[0].Note)
+	})
+
+	t.Run("rejects notes that are too long", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		form := url.Values{"note": {strings.Repeat("a", maxNoteLength+1)}}
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/highlights/%d/note", highlightID),
+			strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("returns 404 for unknown highlights", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/api/highlights/9999/note", strings.NewReader(`{"note": "x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("previews sanitized HTML", func(t *testing.T) {
+		form := url.Values{"note": {"*hi* <script>alert(1)</script>"}}
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/notes/preview", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "<p><em>hi</em> &lt;script&gt;alert(1)&lt;/script&gt;</p>\n", w.Body.String())
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/api/notes/preview", strings.NewReader(`{"note": "**bold**"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			HTML string `json:"html"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "<p><strong>bold</strong></p>\n", resp.HTML)
+	})
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/ocr"
+)
+
+// OCRController recognizes text in photos of book pages. The recognized text is
+// returned for the user to trim and confirm on the capture page, which saves it
+// as a manual highlight.
+type OCRController struct {
+	engine  ocr.Engine
+	maxSize int64
+	timeout time.Duration
+}
+
+func NewOCRController(engine ocr.Engine, maxSize int64) *OCRController {
+	return &OCRController{engine: engine, maxSize: maxSize}
+}
+
+// WithTimeout limits the time spent recognizing one image. Zero leaves it to
+// the engine.
+func (oc *OCRController) WithTimeout(timeout time.Duration) *OCRController {
+	oc.timeout = timeout
+	return oc
+}
+
+// OCRResponse is the response for a recognized page.
+type OCRResponse struct {
+	Text string `json:"text"`
+}
+
+// Recognize runs OCR on an uploaded photo, optionally cropped to the passage.
+// POST /api/ocr
+func (oc *OCRController) Recognize(c *gin.Context) {
+	file, header, err := c.Request.FormFile("image")
+	if err != nil {
+		respondBadRequest(c, "image file is required")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > oc.maxSize {
+		respondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("image too large (max %d MB)", oc.maxSize/(1024*1024)))
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, oc.maxSize))
+	if err != nil {
+		respondInternalError(c, err, "read OCR image")
+		return
+	}
+	if !strings.HasPrefix(http.DetectContentType(data), "image/") {
+		respondBadRequest(c, "file is not an image")
+		return
+	}
+
+	crop, err := parseCropRect(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if data, err = ocr.Crop(data, crop); err != nil {
+		if errors.Is(err, ocr.ErrImageTooLarge) {
+			respondError(c, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	if oc.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, oc.timeout)
+		defer cancel()
+	}
+
+	text, err := oc.engine.Recognize(ctx, data)
+	if errors.Is(err, ocr.ErrNoText) {
+		respondError(c, http.StatusUnprocessableEntity, "no text recognized, try a sharper photo or a tighter crop")
+		return
+	}
+	if err != nil {
+		log.Printf("OCR failed for %s: %v", header.Filename, err)
+		respondError(c, http.StatusBadGateway, "text recognition failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, OCRResponse{Text: text})
+}
+
+// parseCropRect reads the optional crop_x, crop_y, crop_width and crop_height
+// form values, in image pixels. Returns an empty rectangle when no crop is given.
+func parseCropRect(c *gin.Context) (image.Rectangle, error) {
+	fields := []string{"crop_x", "crop_y", "crop_width", "crop_height"}
+	values := make([]int, len(fields))
+	given := 0
+	for i, field := range fields {
+		raw := c.PostForm(field)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return image.Rectangle{}, fmt.Errorf("%s must be a non-negative number", field)
+		}
+		values[i] = n
+		given++
+	}
+
+	if given == 0 {
+		return image.Rectangle{}, nil
+	}
+	if given != len(fields) || values[2] == 0 || values[3] == 0 {
+		return image.Rectangle{}, errors.New("crop needs crop_x, crop_y, crop_width and crop_height")
+	}
+	return image.Rect(values[0], values[1], values[0]+values[2], values[1]+values[3]), nil
+}
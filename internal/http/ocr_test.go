@@ -0,0 +1,96 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/ocr"
+)
+
+type fakeOCREngine struct {
+	text     string
+	err      error
+	received image.Point
+}
+
+func (e *fakeOCREngine) Recognize(ctx context.Context, data []byte) (string, error) {
+	if img, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+		e.received = img.Bounds().Size()
+	}
+	return e.text, e.err
+}
+
+func postOCRImage(t *testing.T, engine ocr.Engine, image []byte, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/ocr", NewOCRController(engine, 1024*1024).Recognize)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("image", "page.png")
+	require.NoError(t, err)
+	_, _ = part.Write(image)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/ocr", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func testPagePNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 200, 100))))
+	return buf.Bytes()
+}
+
+func TestOCRController_Recognize(t *testing.T) {
+	engine := &fakeOCREngine{text: "A recognized passage."}
+
+	w := postOCRImage(t, engine, testPagePNG(t), map[string]string{
+		"crop_x": "10", "crop_y": "20", "crop_width": "50", "crop_height": "30",
+	})
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"text": "A recognized passage."}`, w.Body.String())
+	assert.Equal(t, image.Pt(50, 30), engine.received)
+}
+
+func TestOCRController_RecognizeErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		engine         *fakeOCREngine
+		image          []byte
+		fields         map[string]string
+		expectedStatus int
+	}{
+		{"not an image", &fakeOCREngine{text: "x"}, []byte("plain text"), nil, http.StatusBadRequest},
+		{"partial crop", &fakeOCREngine{text: "x"}, testPagePNG(t), map[string]string{"crop_x": "10"}, http.StatusBadRequest},
+		{"no text", &fakeOCREngine{err: ocr.ErrNoText}, testPagePNG(t), nil, http.StatusUnprocessableEntity},
+		{"backend failure", &fakeOCREngine{err: errors.New("tesseract crashed")}, testPagePNG(t), nil, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postOCRImage(t, tt.engine, tt.image, tt.fields)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/tts"
+)
+
+// PodcastPrefix is where the podcast feeds and their audio are served. Podcast
+// apps authenticate with HTTP Basic, using an API token as the password.
+const PodcastPrefix = "/podcast"
+
+// maxPodcastEpisodes caps the episodes of a tag feed, newest highlights first.
+const maxPodcastEpisodes = 500
+
+// podcastTitleLength is how much of a highlight is used as the episode title.
+const podcastTitleLength = 80
+
+// PodcastStore defines database operations for the podcast feeds.
+type PodcastStore interface {
+	GetBookByID(id uint) (*entities.Book, error)
+	GetTagByID(id uint) (*entities.Tag, error)
+	GetHighlightByID(id uint) (*entities.Highlight, error)
+	GetHighlightsByTag(tagID uint, userID uint, limit int) ([]entities.Highlight, error)
+}
+
+// PodcastController serves highlights as podcast feeds whose episodes are
+// the highlights read out by the text-to-speech backend.
+type PodcastController struct {
+	store PodcastStore
+	audio *tts.Cache
+}
+
+func NewPodcastController(store PodcastStore, audio *tts.Cache) *PodcastController {
+	return &PodcastController{store: store, audio: audio}
+}
+
+// BookFeed returns the podcast feed of a book's highlights in reading order.
+// GET /podcast/books/:id/feed.xml
+func (pc *PodcastController) BookFeed(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	book, err := pc.store.GetBookByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ownedByUser(c, book.UserID)) {
+		respondNotFound(c, "book")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "get book")
+		return
+	}
+
+	baseURL := requestBaseURL(c)
+	feed := &tts.Feed{
+		Title:       book.Title,
+		Description: fmt.Sprintf("Highlights of %s", book.Title),
+		Link:        fmt.Sprintf("%s/ui/books/%d", baseURL, book.ID),
+		ImageURL:    book.CoverURL,
+		Author:      book.Author,
+	}
+	if book.Author != "" {
+		feed.Description += " by " + book.Author
+	}
+	for i := range book.Highlights {
+		feed.Episodes = append(feed.Episodes, pc.episode(baseURL, &book.Highlights[i]))
+	}
+
+	pc.writeFeed(c, feed)
+}
+
+// TagFeed returns the podcast feed of the highlights with a tag, including
+// the highlights of books with the tag, newest first.
+// GET /podcast/tags/:id/feed.xml
+func (pc *PodcastController) TagFeed(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	tag, err := pc.store.GetTagByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ownedByUser(c, tag.UserID)) {
+		respondNotFound(c, "tag")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "get tag")
+		return
+	}
+
+	highlights, err := pc.store.GetHighlightsByTag(tag.ID, GetUserID(c), maxPodcastEpisodes)
+	if err != nil {
+		respondInternalError(c, err, "get highlights by tag")
+		return
+	}
+
+	baseURL := requestBaseURL(c)
+	feed := &tts.Feed{
+		Title:       "Highlights tagged " + tag.Name,
+		Description: fmt.Sprintf("Highlights tagged %s", tag.Name),
+		Link:        fmt.Sprintf("%s/?tag=%d", baseURL, tag.ID),
+	}
+	for i := range highlights {
+		episode := pc.episode(baseURL, &highlights[i])
+		if book := highlights[i].Book; book.Title != "" {
+			episode.Description = book.Title
+			if book.Author != "" {
+				episode.Description += " by " + book.Author
+			}
+		}
+		feed.Episodes = append(feed.Episodes, episode)
+	}
+
+	pc.writeFeed(c, feed)
+}
+
+// Audio returns a highlight read out as MP3, speaking it first when it has
+// not been spoken since it was last edited.
+// GET /podcast/highlights/:id/audio.mp3
+func (pc *PodcastController) Audio(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	highlight, err := pc.store.GetHighlightByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ownedByUser(c, highlight.UserID)) {
+		respondNotFound(c, "highlight")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "get highlight")
+		return
+	}
+
+	path, err := pc.audio.Audio(c.Request.Context(), highlight.ID, tts.Script(highlight))
+	if err != nil {
+		respondError(c, http.StatusBadGateway, "text-to-speech failed: "+err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "audio/mpeg")
+	c.File(path)
+}
+
+// episode describes a highlight as a feed episode. Highlights not spoken yet
+// have a length of 0; podcast apps take the length from the download.
+func (pc *PodcastController) episode(baseURL string, highlight *entities.Highlight) tts.Episode {
+	published := highlight.HighlightedAt
+	if published.IsZero() {
+		published = highlight.CreatedAt
+	}
+	return tts.Episode{
+		GUID:        fmt.Sprintf("highlight-%d", highlight.ID),
+		Title:       episodeTitle(highlight),
+		Description: highlight.Chapter,
+		AudioURL:    fmt.Sprintf("%s%s/highlights/%d/audio.mp3", baseURL, PodcastPrefix, highlight.ID),
+		Size:        pc.audio.Size(highlight.ID, tts.Script(highlight)),
+		Published:   published,
+	}
+}
+
+func (pc *PodcastController) writeFeed(c *gin.Context, feed *tts.Feed) {
+	var buf bytes.Buffer
+	if err := feed.WriteRSS(&buf); err != nil {
+		respondInternalError(c, err, "write podcast feed")
+		return
+	}
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", buf.Bytes())
+}
+
+// episodeTitle is the start of the highlight, cut at a word boundary.
+func episodeTitle(highlight *entities.Highlight) string {
+	text := []rune(strings.Join(strings.Fields(highlight.Text), " "))
+	if len(text) <= podcastTitleLength {
+		return string(text)
+	}
+	cut := string(text[:podcastTitleLength])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, ",;:.-") + "…"
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/tts"
+)
+
+type echoSpeech struct{}
+
+func (echoSpeech) Synthesize(_ context.Context, text string) ([]byte, error) {
+	return []byte("ID3" + text), nil
+}
+
+func TestPodcastController(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Highlights: []entities.Highlight{
+		{Text: "The impediment to action advances action.", Note: "Obstacles"},
+		{Text: "What stands in the way becomes the way."},
+	}}
+	require.NoError(t, db.SaveBook(book))
+	tag, err := db.CreateTag("commute", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToHighlight(book.Highlights[1].ID, tag.ID))
+
+	audio, err := tts.NewCache(t.TempDir(), echoSpeech{}, "")
+	require.NoError(t, err)
+	controller := NewPodcastController(db, audio)
+
+	router := gin.New()
+	router.GET(PodcastPrefix+"/books/:id/feed.xml", controller.BookFeed)
+	router.GET(PodcastPrefix+"/tags/:id/feed.xml", controller.TagFeed)
+	router.GET(PodcastPrefix+"/highlights/:id/audio.mp3", controller.Audio)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.Host = "books.example.com"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(fmt.Sprintf("/podcast/books/%d/feed.xml", book.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/rss+xml")
+	assert.Contains(t, w.Body.String(), "<title>Meditations</title>")
+	assert.Contains(t, w.Body.String(), "<title>The impediment to action advances action.</title>")
+	firstAudio := fmt.Sprintf("http://books.example.com/podcast/highlights/%d/audio.mp3", book.Highlights[0].ID)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`<enclosure url="%s" length="0"`, firstAudio))
+
+	w = get(fmt.Sprintf("/podcast/highlights/%d/audio.mp3", book.Highlights[0].ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "audio/mpeg", w.Header().Get("Content-Type"))
+	assert.Equal(t, "ID3The impediment to action advances action.\n\nNote: Obstacles", w.Body.String())
+
+	// Spoken episodes have their length in the feed
+	w = get(fmt.Sprintf("/podcast/books/%d/feed.xml", book.ID))
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`<enclosure url="%s" length="61"`, firstAudio))
+
+	w = get(fmt.Sprintf("/podcast/tags/%d/feed.xml", tag.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "<title>What stands in the way becomes the way.</title>")
+	assert.NotContains(t, w.Body.String(), "The impediment")
+	assert.Contains(t, w.Body.String(), "<description>Meditations by Marcus Aurelius</description>")
+
+	assert.Equal(t, http.StatusNotFound, get("/podcast/books/9999/feed.xml").Code)
+	assert.Equal(t, http.StatusNotFound, get("/podcast/tags/9999/feed.xml").Code)
+	assert.Equal(t, http.StatusNotFound, get("/podcast/highlights/9999/audio.mp3").Code)
+}
+
+func TestEpisodeTitle(t *testing.T) {
+	assert.Equal(t, "Short highlight", episodeTitle(&entities.Highlight{Text: "Short  highlight"}))
+
+	long := "It is not that we have a short time to live, but that we waste a lot of it. Life is long enough."
+	assert.Equal(t, "It is not that we have a short time to live, but that we waste a lot of it…", episodeTitle(&entities.Highlight{Text: long}))
+}
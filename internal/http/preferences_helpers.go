@@ -0,0 +1,48 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/localtime"
+)
+
+const (
+	preferencesStoreContextKey = "preferences_store"
+	preferencesContextKey      = "preferences"
+)
+
+// PreferencesStore resolves the timezone and locale of a user.
+// Implemented by settingsstore.SettingsStore.
+type PreferencesStore interface {
+	GetUserPreferences(userID uint) localtime.Preferences
+}
+
+// PreferencesContextMiddleware lets handlers look up the current user's
+// timezone and locale with GetUserPreferences. Must run after authentication.
+func PreferencesContextMiddleware(store PreferencesStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(preferencesStoreContextKey, store)
+		c.Next()
+	}
+}
+
+// GetUserPreferences returns the current user's timezone and locale, looked up
+// once per request. Without PreferencesContextMiddleware it returns UTC and
+// ISO 8601 dates.
+func GetUserPreferences(c *gin.Context) localtime.Preferences {
+	if prefs, exists := c.Get(preferencesContextKey); exists {
+		if p, ok := prefs.(localtime.Preferences); ok {
+			return p
+		}
+	}
+
+	prefs := localtime.New("", "")
+	if value, exists := c.Get(preferencesStoreContextKey); exists {
+		if store, ok := value.(PreferencesStore); ok {
+			prefs = store.GetUserPreferences(auth.GetUserID(c))
+		}
+	}
+	c.Set(preferencesContextKey, prefs)
+	return prefs
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKeyBasePath is the Gin context key for the URL prefix the app is
+// served under. Set by BasePathMiddleware, read by basePath.
+const ContextKeyBasePath = "base_path"
+
+// forwardedHeaders are the reverse proxy headers that describe the client's
+// request: its address, scheme, host and path prefix.
+var forwardedHeaders = []string{
+	"Forwarded",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Prefix",
+	"X-Forwarded-Proto",
+	"X-Real-IP",
+}
+
+// ForwardedHeadersMiddleware drops the reverse proxy headers of requests that
+// do not come from a trusted proxy, so clients cannot fake their address,
+// scheme or host. Without trusted proxies every request keeps its headers.
+func ForwardedHeadersMiddleware(trusted []netip.Prefix) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(trusted) > 0 && !isTrustedProxy(trusted, c.RemoteIP()) {
+			for _, header := range forwardedHeaders {
+				c.Request.Header.Del(header)
+			}
+		}
+		c.Next()
+	}
+}
+
+func isTrustedProxy(trusted []netip.Prefix, remoteIP string) bool {
+	addr, err := netip.ParseAddr(remoteIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedProxyList returns the trusted proxies in the form gin's
+// SetTrustedProxies expects.
+func trustedProxyList(trusted []netip.Prefix) []string {
+	list := make([]string, 0, len(trusted))
+	for _, prefix := range trusted {
+		list = append(list, prefix.String())
+	}
+	return list
+}
+
+// BasePathHandler serves next under a URL prefix such as "/highlights". The
+// prefix is stripped before routing, so routes stay registered at the root,
+// and added back to redirects. Requests without the prefix are served as
+// they are, for proxies that strip it themselves.
+func BasePathHandler(basePath string, next http.Handler) http.Handler {
+	if basePath == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == basePath {
+			http.Redirect(w, r, basePath+"/", http.StatusMovedPermanently)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		if rest, ok := strings.CutPrefix(r.URL.Path, basePath+"/"); ok {
+			r2.URL.Path = "/" + rest
+			r2.URL.RawPath = ""
+		}
+		// Redirects get the configured prefix, so gin must not add the proxy's too
+		r2.Header.Del("X-Forwarded-Prefix")
+		next.ServeHTTP(&basePathWriter{ResponseWriter: w, basePath: basePath}, r2)
+	})
+}
+
+// basePathWriter adds the base path to root-relative Location headers.
+type basePathWriter struct {
+	http.ResponseWriter
+	basePath string
+}
+
+func (w *basePathWriter) WriteHeader(code int) {
+	location := w.Header().Get("Location")
+	if strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "//") {
+		w.Header().Set("Location", w.basePath+location)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush keeps server-sent events streaming through the wrapper.
+func (w *basePathWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the wrapped writer to http.ResponseController.
+func (w *basePathWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// BasePathMiddleware makes the URL prefix the app is served under available
+// to handlers that build links.
+func BasePathMiddleware(basePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ContextKeyBasePath, basePath)
+		c.Next()
+	}
+}
+
+// basePath returns the URL prefix the app is served under, empty for the root.
+func basePath(c *gin.Context) string {
+	return c.GetString(ContextKeyBasePath)
+}
+
+// requestBaseURL returns the scheme, host and base path the client used,
+// respecting reverse proxy headers.
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, getEffectiveHost(c), basePath(c))
+}
+
+// getEffectiveHost returns the host that the client sees, considering reverse proxy headers.
+func getEffectiveHost(c *gin.Context) string {
+	if forwardedHost := c.GetHeader("X-Forwarded-Host"); forwardedHost != "" {
+		if idx := strings.Index(forwardedHost, ","); idx != -1 {
+			forwardedHost = strings.TrimSpace(forwardedHost[:idx])
+		}
+		return forwardedHost
+	}
+	return c.Request.Host
+}
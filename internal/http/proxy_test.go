@@ -0,0 +1,103 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardedHeadersMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(trusted ...netip.Prefix) *gin.Engine {
+		router := gin.New()
+		if len(trusted) > 0 {
+			_ = router.SetTrustedProxies(trustedProxyList(trusted))
+		}
+		router.Use(ForwardedHeadersMiddleware(trusted))
+		router.GET("/whoami", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"base_url": requestBaseURL(c), "client_ip": c.ClientIP()})
+		})
+		return router
+	}
+	request := func(router *gin.Engine, remoteAddr string) string {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		req.RemoteAddr = remoteAddr
+		req.Host = "app.internal:8188"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "books.example.com")
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	t.Run("without trusted proxies every request is trusted", func(t *testing.T) {
+		body := request(newRouter(), "198.51.100.1:4000")
+		assert.Contains(t, body, `"base_url":"https://books.example.com"`)
+		assert.Contains(t, body, `"client_ip":"203.0.113.7"`)
+	})
+
+	router := newRouter(netip.MustParsePrefix("10.0.0.0/8"))
+
+	t.Run("trusted proxy", func(t *testing.T) {
+		body := request(router, "10.1.2.3:4000")
+		assert.Contains(t, body, `"base_url":"https://books.example.com"`)
+		assert.Contains(t, body, `"client_ip":"203.0.113.7"`)
+	})
+
+	t.Run("untrusted client cannot fake its address, scheme or host", func(t *testing.T) {
+		body := request(router, "198.51.100.1:4000")
+		assert.Contains(t, body, `"base_url":"http://app.internal:8188"`)
+		assert.Contains(t, body, `"client_ip":"198.51.100.1"`)
+	})
+}
+
+func TestBasePathHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(BasePathMiddleware("/highlights"))
+	router.GET("/settings", func(c *gin.Context) {
+		c.String(http.StatusOK, "settings at "+requestBaseURL(c))
+	})
+	router.GET("/profile", func(c *gin.Context) {
+		c.Redirect(http.StatusFound, "/login?next=/profile")
+	})
+	router.GET("/cover", func(c *gin.Context) {
+		c.Redirect(http.StatusTemporaryRedirect, "https://covers.example.com/1.jpg")
+	})
+	handler := BasePathHandler("/highlights", router)
+
+	request := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "example.com"
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("/highlights/settings")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "settings at http://example.com/highlights", w.Body.String())
+
+	// Proxies that strip the prefix themselves
+	assert.Equal(t, http.StatusOK, request("/settings").Code)
+
+	w = request("/highlights")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/highlights/", w.Header().Get("Location"))
+
+	w = request("/highlights/profile")
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/highlights/login?next=/profile", w.Header().Get("Location"))
+
+	w = request("/highlights/cover")
+	assert.Equal(t, "https://covers.example.com/1.jpg", w.Header().Get("Location"))
+
+	assert.Same(t, router, BasePathHandler("", router), "no base path serves the router as is")
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// PublicLibraryPrefix is the path prefix of the public library, served
+// without authentication.
+const PublicLibraryPrefix = "/public"
+
+// PublicLibraryStore defines database operations for the public library.
+type PublicLibraryStore interface {
+	SetBookPublic(bookID uint, isPublic bool) error
+	GetPublicBooks(filter entities.PublicLibraryFilter) ([]entities.Book, error)
+	GetPublicBook(id uint, filter entities.PublicLibraryFilter) (*entities.Book, error)
+	GetBookByID(id uint) (*entities.Book, error)
+}
+
+// PublicLibrarySettings tells whether the public library is shown and which
+// books it lists.
+type PublicLibrarySettings interface {
+	GetPublicLibraryEnabled() bool
+	GetPublicLibraryTitle() string
+	GetPublicLibraryFilter() entities.PublicLibraryFilter
+}
+
+// PublicLibraryController serves a read-only selection of books to visitors
+// who are not signed in, and lets users share books on it.
+type PublicLibraryController struct {
+	store    PublicLibraryStore
+	settings PublicLibrarySettings
+}
+
+func NewPublicLibraryController(store PublicLibraryStore, settings PublicLibrarySettings) *PublicLibraryController {
+	return &PublicLibraryController{store: store, settings: settings}
+}
+
+// ShareBook shares a book on the public library.
+// POST /api/books/:id/public
+func (pc *PublicLibraryController) ShareBook(c *gin.Context) {
+	pc.setBookPublic(c, true)
+}
+
+// UnshareBook stops sharing a book on the public library. The book is still
+// listed when the favourites or tags it has are.
+// DELETE /api/books/:id/public
+func (pc *PublicLibraryController) UnshareBook(c *gin.Context) {
+	pc.setBookPublic(c, false)
+}
+
+func (pc *PublicLibraryController) setBookPublic(c *gin.Context, isPublic bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	book, err := pc.store.GetBookByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ownedByUser(c, book.UserID)) {
+		respondNotFound(c, "book")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "get book")
+		return
+	}
+
+	if err := pc.store.SetBookPublic(id, isPublic); err != nil {
+		respondInternalError(c, err, "set book public")
+		return
+	}
+	book.IsPublic = isPublic
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "book-public-button", book)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": book.ID, "is_public": book.IsPublic})
+}
+
+// LibraryPage lists the books on the public library.
+// GET /public
+func (pc *PublicLibraryController) LibraryPage(c *gin.Context) {
+	if !pc.settings.GetPublicLibraryEnabled() {
+		c.String(http.StatusNotFound, "Not found")
+		return
+	}
+
+	books, err := pc.store.GetPublicBooks(pc.settings.GetPublicLibraryFilter())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error loading books")
+		return
+	}
+
+	c.HTML(http.StatusOK, "public-library", gin.H{
+		"Title":     pc.settings.GetPublicLibraryTitle(),
+		"Books":     books,
+		"Analytics": GetAnalyticsTemplateData(c),
+	})
+}
+
+// BookPage shows a book on the public library with its highlights. Notes are
+// left out, as they are often personal.
+// GET /public/books/:id
+func (pc *PublicLibraryController) BookPage(c *gin.Context) {
+	if !pc.settings.GetPublicLibraryEnabled() {
+		c.String(http.StatusNotFound, "Not found")
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	book, err := pc.store.GetPublicBook(id, pc.settings.GetPublicLibraryFilter())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "Book not found")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error loading book")
+		return
+	}
+
+	c.HTML(http.StatusOK, "public-book", gin.H{
+		"Title":     pc.settings.GetPublicLibraryTitle(),
+		"Book":      book,
+		"Analytics": GetAnalyticsTemplateData(c),
+	})
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+type fakePublicLibrarySettings struct {
+	enabled bool
+	filter  entities.PublicLibraryFilter
+}
+
+func (s *fakePublicLibrarySettings) GetPublicLibraryEnabled() bool { return s.enabled }
+func (s *fakePublicLibrarySettings) GetPublicLibraryTitle() string { return "Library" }
+func (s *fakePublicLibrarySettings) GetPublicLibraryFilter() entities.PublicLibraryFilter {
+	return s.filter
+}
+
+func TestPublicLibraryController(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	shared := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Highlights: []entities.Highlight{
+		{Text: "Waste no more time arguing", Note: "a private thought"},
+	}}
+	private := &entities.Book{Title: "Diary", Author: "Me", IsFavorite: true}
+	require.NoError(t, db.SaveBook(shared))
+	require.NoError(t, db.SaveBook(private))
+
+	settings := &fakePublicLibrarySettings{enabled: true}
+	controller := NewPublicLibraryController(db, settings)
+	router := gin.New()
+	router.SetHTMLTemplate(template.Must(template.New("").Parse(
+		`{{ define "public-library" }}{{ range .Books }}{{ .Title }};{{ end }}{{ end }}` +
+			`{{ define "public-book" }}{{ range .Book.Highlights }}{{ .Text }}{{ end }}{{ end }}`)))
+	router.POST("/api/books/:id/public", controller.ShareBook)
+	router.DELETE("/api/books/:id/public", controller.UnshareBook)
+	router.GET("/public", controller.LibraryPage)
+	router.GET("/public/books/:id", controller.BookPage)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/public")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String(), "nothing is shared yet")
+
+	w = do(http.MethodPost, fmt.Sprintf("/api/books/%d/public", shared.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"is_public":true`)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/api/books/9999/public").Code)
+
+	assert.Equal(t, "Meditations;", do(http.MethodGet, "/public").Body.String())
+
+	settings.filter.Favourites = true
+	assert.Equal(t, "Diary;Meditations;", do(http.MethodGet, "/public").Body.String())
+
+	w = do(http.MethodGet, fmt.Sprintf("/public/books/%d", shared.ID))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Waste no more time arguing", w.Body.String())
+
+	settings.filter.Favourites = false
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, fmt.Sprintf("/public/books/%d", private.ID)).Code)
+
+	require.Equal(t, http.StatusOK, do(http.MethodDelete, fmt.Sprintf("/api/books/%d/public", shared.ID)).Code)
+	assert.Empty(t, do(http.MethodGet, "/public").Body.String())
+
+	settings.enabled = false
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/public").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, fmt.Sprintf("/public/books/%d", shared.ID)).Code)
+}
+
+func TestPublicLibraryController_ShareOtherUsersBook(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Diary", Author: "Someone Else", UserID: 8}
+	require.NoError(t, db.SaveBook(book))
+
+	controller := NewPublicLibraryController(db, &fakePublicLibrarySettings{enabled: true})
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(auth.ContextKeyUserID, uint(7))
+	})
+	router.POST("/api/books/:id/public", controller.ShareBook)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/books/%d/public", book.ID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	saved, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.False(t, saved.IsPublic)
+}
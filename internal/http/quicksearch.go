@@ -0,0 +1,192 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+const (
+	defaultQuickSearchLimit = 10
+	maxQuickSearchLimit     = 50
+
+	// quickSearchTitleLength is how much of a highlight, note or word context is shown.
+	quickSearchTitleLength = 120
+
+	// quickSearchActionType is the type of results that open a page.
+	quickSearchActionType = "action"
+)
+
+// QuickSearchStore defines database operations for the command palette.
+type QuickSearchStore interface {
+	QuickSearch(query string, userID uint, limit int) ([]entities.SearchHit, error)
+}
+
+// QuickSearchAction is a page the command palette can open.
+type QuickSearchAction struct {
+	Label string // Message key of the page's name
+	URL   string
+}
+
+// QuickSearchResult is an entry of the command palette.
+type QuickSearchResult struct {
+	Type      string `json:"type"`       // book, highlight, tag, word or action
+	TypeLabel string `json:"type_label"` // The type in the user's language
+	ID        uint   `json:"id,omitempty"`
+	Title     string `json:"title"`
+	Subtitle  string `json:"subtitle,omitempty"`
+	URL       string `json:"url"`
+
+	score int
+}
+
+// QuickSearchController answers the command palette's queries.
+type QuickSearchController struct {
+	store   QuickSearchStore
+	actions []QuickSearchAction
+}
+
+func NewQuickSearchController(store QuickSearchStore, actions []QuickSearchAction) *QuickSearchController {
+	return &QuickSearchController{store: store, actions: actions}
+}
+
+// Search returns the books, highlights, tags, vocabulary words and pages
+// matching q, best matches first. Words match by their start, so the results
+// can follow each keystroke. Without q it lists the pages.
+// GET /api/quicksearch?q=dune&limit=10
+func (qc *QuickSearchController) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	limit := defaultQuickSearchLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= maxQuickSearchLimit {
+			limit = l
+		}
+	}
+
+	hits, err := qc.store.QuickSearch(query, GetUserID(c), limit)
+	if err != nil {
+		respondInternalError(c, err, "quick search")
+		return
+	}
+
+	t := GetTranslator(c)
+	results := make([]QuickSearchResult, 0, len(hits)+len(qc.actions))
+	// Result URLs are root-relative; clients get them with the base path
+	for _, hit := range hits {
+		result := quickSearchHitResult(hit, t.T("quicksearch.type_"+hit.Kind))
+		result.URL = basePath(c) + result.URL
+		results = append(results, result)
+	}
+	for _, action := range qc.actions {
+		name := t.T(action.Label)
+		score := actionScore(name, query)
+		if score == 0 {
+			continue
+		}
+		results = append(results, QuickSearchResult{
+			Type:      quickSearchActionType,
+			TypeLabel: t.T("quicksearch.type_action"),
+			Title:     name,
+			URL:       basePath(c) + action.URL,
+			score:     score,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":   query,
+		"results": results,
+	})
+}
+
+func quickSearchHitResult(hit entities.SearchHit, typeLabel string) QuickSearchResult {
+	result := QuickSearchResult{
+		Type:      hit.Kind,
+		TypeLabel: typeLabel,
+		ID:        hit.ID,
+		Title:     hit.Title,
+		Subtitle:  hit.Detail,
+		score:     hit.Score,
+	}
+	switch hit.Kind {
+	case entities.SearchKindBook:
+		result.URL = fmt.Sprintf("/ui/books/%d", hit.ID)
+	case entities.SearchKindHighlight:
+		result.Title = quickSearchSnippet(hit.Title)
+		result.Subtitle = quickSearchSnippet(hit.Detail)
+		result.URL = fmt.Sprintf("/ui/books/%d#highlight-%d", hit.ParentID, hit.ID)
+	case entities.SearchKindTag:
+		result.URL = fmt.Sprintf("/?tag=%d", hit.ID)
+	case entities.SearchKindWord:
+		result.Subtitle = quickSearchSnippet(hit.Detail)
+		result.URL = fmt.Sprintf("/vocabulary#word-%d", hit.ID)
+	}
+	return result
+}
+
+// quickSearchSnippet shortens a highlight or note to a line.
+func quickSearchSnippet(text string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= quickSearchTitleLength {
+		return string(runes)
+	}
+	return string(runes[:quickSearchTitleLength]) + "…"
+}
+
+// actionScore rates a page name against the query like the database rates
+// titles, 0 when it does not match. Without a query every page matches.
+func actionScore(name, query string) int {
+	name, query = strings.ToLower(name), strings.ToLower(query)
+	switch {
+	case query == "":
+		return 1
+	case name == query:
+		return 100
+	case strings.HasPrefix(name, query):
+		return 75
+	case strings.Contains(name, " "+query):
+		return 50
+	}
+	return 0
+}
+
+// quickSearchActions lists the pages of the enabled features.
+func quickSearchActions(cfg RouterConfig) []QuickSearchAction {
+	actions := []QuickSearchAction{
+		{Label: "nav.books", URL: "/"},
+		{Label: "books.export_all", URL: "/ui/download-all"},
+		{Label: "nav.settings", URL: "/settings"},
+	}
+	optional := []struct {
+		enabled bool
+		action  QuickSearchAction
+	}{
+		{cfg.CollectionStore != nil, QuickSearchAction{Label: "nav.collections", URL: "/collections"}},
+		{cfg.SavedViewStore != nil, QuickSearchAction{Label: "nav.views", URL: "/views"}},
+		{cfg.SeriesStore != nil, QuickSearchAction{Label: "nav.series", URL: "/ui/series"}},
+		{cfg.CaptureStore != nil, QuickSearchAction{Label: "nav.capture", URL: "/capture"}},
+		{cfg.FavouritesStore != nil, QuickSearchAction{Label: "nav.favourites", URL: "/favourites"}},
+		{cfg.VocabularyStore != nil, QuickSearchAction{Label: "nav.vocabulary", URL: "/vocabulary"}},
+		{cfg.TrashStore != nil, QuickSearchAction{Label: "nav.trash", URL: "/trash"}},
+		{cfg.MetadataReviewStore != nil, QuickSearchAction{Label: "nav.metadata_review", URL: "/metadata/reviews"}},
+		{cfg.AuthService != nil && cfg.AuthService.IsAuthEnabled(), QuickSearchAction{Label: "nav.profile", URL: "/profile"}},
+	}
+	for _, o := range optional {
+		if o.enabled {
+			actions = append(actions, o.action)
+		}
+	}
+	return actions
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestQuickSearchController(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Highlights: []entities.Highlight{
+		{Text: "The impediment to action advances action.", Note: "Obstacles"},
+	}}
+	require.NoError(t, db.SaveBook(book))
+	tag, err := db.CreateTag("stoicism", 0)
+	require.NoError(t, err)
+
+	controller := NewQuickSearchController(db, quickSearchActions(RouterConfig{}))
+	router := gin.New()
+	router.Use(LanguageMiddleware(nil))
+	router.GET("/api/quicksearch", controller.Search)
+
+	search := func(query, language string) []QuickSearchResult {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/quicksearch?q="+query, nil)
+		req.Header.Set("Accept-Language", language)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response struct {
+			Results []QuickSearchResult `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Results
+	}
+
+	results := search("medit", "en")
+	require.Len(t, results, 1)
+	assert.Equal(t, QuickSearchResult{
+		Type: "book", TypeLabel: "Book", ID: book.ID,
+		Title: "Meditations", Subtitle: "Marcus Aurelius", URL: fmt.Sprintf("/ui/books/%d", book.ID),
+	}, results[0])
+
+	results = search("impediment", "de")
+	require.Len(t, results, 1)
+	assert.Equal(t, "Markierung", results[0].TypeLabel)
+	assert.Equal(t, fmt.Sprintf("/ui/books/%d#highlight-%d", book.ID, book.Highlights[0].ID), results[0].URL)
+
+	results = search("sto", "en")
+	require.Len(t, results, 1)
+	assert.Equal(t, fmt.Sprintf("/?tag=%d", tag.ID), results[0].URL)
+
+	// Pages are matched by their name in the user's language
+	results = search("einst", "de")
+	require.Len(t, results, 1)
+	assert.Equal(t, QuickSearchResult{Type: "action", TypeLabel: "Gehe zu", Title: "Einstellungen", URL: "/settings"}, results[0])
+
+	results = search("", "en")
+	assert.Len(t, results, 3, "without a query the pages are listed")
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/covers"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/quotecard"
+)
+
+// QuoteCardStore defines database operations for rendering quote cards.
+type QuoteCardStore interface {
+	GetHighlightByID(id uint) (*entities.Highlight, error)
+	GetBookByID(id uint) (*entities.Book, error)
+}
+
+// QuoteCardCovers opens a book's cached cover.
+type QuoteCardCovers interface {
+	OpenCover(userID, bookID uint, coverURL string) (*covers.Cover, error)
+}
+
+// QuoteCardsController renders highlights as shareable quote card images.
+type QuoteCardsController struct {
+	store  QuoteCardStore
+	covers QuoteCardCovers
+}
+
+func NewQuoteCardsController(store QuoteCardStore) *QuoteCardsController {
+	return &QuoteCardsController{store: store}
+}
+
+// WithCovers lets cards show the book cover when asked for with ?cover=true.
+func (qc *QuoteCardsController) WithCovers(covers QuoteCardCovers) *QuoteCardsController {
+	qc.covers = covers
+	return qc
+}
+
+// GetCard renders a highlight as a quote card. Query parameters: format (png
+// or svg, default png), template (see quotecard.Templates, default light) and
+// cover (true to include the book cover, when cached covers are enabled).
+// Add download=true to get the card as an attachment.
+// GET /api/highlights/:id/card
+func (qc *QuoteCardsController) GetCard(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", quotecard.FormatPNG))
+	if format != quotecard.FormatPNG && format != quotecard.FormatSVG {
+		respondBadRequest(c, quotecard.ErrUnknownFormat.Error())
+		return
+	}
+	tmpl, err := quotecard.TemplateByName(strings.ToLower(c.Query("template")))
+	if err != nil {
+		respondBadRequest(c, "template must be one of: "+strings.Join(quotecard.TemplateNames(), ", "))
+		return
+	}
+	withCover, err := parseOptionalBool(c, "cover")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	highlight, err := qc.store.GetHighlightByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "highlight")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "get highlight")
+		return
+	}
+	book, err := qc.store.GetBookByID(highlight.BookID)
+	if err != nil {
+		respondInternalError(c, err, "get book")
+		return
+	}
+
+	card := quotecard.Card{Text: highlight.Text, Title: book.Title, Author: book.Author}
+	if withCover != nil && *withCover {
+		card.Cover = qc.cover(book)
+	}
+
+	data, contentType, err := quotecard.Render(card, tmpl, format)
+	if err != nil {
+		respondInternalError(c, err, "render quote card")
+		return
+	}
+
+	disposition := "inline"
+	if c.Query("download") == "true" {
+		disposition = "attachment"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`%s; filename="quote-%d-%s.%s"`, disposition, highlight.ID, tmpl.Name, format))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// cover returns the book's cached cover image, or nil when there is none
+func (qc *QuoteCardsController) cover(book *entities.Book) []byte {
+	if qc.covers == nil || book.CoverURL == "" {
+		return nil
+	}
+	cover, err := qc.covers.OpenCover(book.UserID, book.ID, book.CoverURL)
+	if err != nil || cover == nil {
+		return nil
+	}
+	defer cover.Close()
+	data, err := io.ReadAll(cover)
+	if err != nil {
+		return nil
+	}
+	return data
+}
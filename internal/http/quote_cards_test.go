@@ -0,0 +1,52 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestQuoteCardsController_GetCard(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Highlights: []entities.Highlight{
+		{Text: "The impediment to action advances action. What stands in the way becomes the way."},
+	}}
+	require.NoError(t, db.SaveBook(book))
+	highlightID := book.Highlights[0].ID
+
+	router := gin.New()
+	router.GET("/api/highlights/:id/card", NewQuoteCardsController(db).GetCard)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(fmt.Sprintf("/api/highlights/%d/card", highlightID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "\x89PNG"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "inline")
+
+	w = get(fmt.Sprintf("/api/highlights/%d/card?format=svg&template=dark&download=true", highlightID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "Marcus Aurelius")
+	assert.Contains(t, w.Header().Get("Content-Disposition"), fmt.Sprintf(`attachment; filename="quote-%d-dark.svg"`, highlightID))
+
+	assert.Equal(t, http.StatusBadRequest, get(fmt.Sprintf("/api/highlights/%d/card?format=gif", highlightID)).Code)
+	assert.Equal(t, http.StatusBadRequest, get(fmt.Sprintf("/api/highlights/%d/card?template=neon", highlightID)).Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/highlights/9999/card").Code)
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikestefanello/backlite"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/tasks"
+)
+
+// ReenrichStore defines database operations for whole-library re-enrichment.
+type ReenrichStore interface {
+	GetBookIDs() ([]uint, error)
+	IsSyncRunning(syncType entities.SyncType) (bool, error)
+	StartSyncProgress(syncType entities.SyncType, totalItems int) (*entities.SyncProgress, error)
+	CompleteSyncProgress(syncType entities.SyncType, status entities.SyncStatus, errorMsg string) error
+	GetSyncProgress(syncType entities.SyncType) (*entities.SyncProgress, error)
+}
+
+// ReenrichController re-enriches every book through the task queue and
+// streams the progress with Server-Sent Events.
+type ReenrichController struct {
+	store        ReenrichStore
+	taskClient   *tasks.Client
+	pollInterval time.Duration
+}
+
+func NewReenrichController(store ReenrichStore, taskClient *tasks.Client) *ReenrichController {
+	return &ReenrichController{store: store, taskClient: taskClient, pollInterval: time.Second}
+}
+
+// ReenrichProgress is the payload of re-enrichment progress events.
+type ReenrichProgress struct {
+	SyncStatusResponse
+	Status string `json:"status"` // running, completed, failed or idle
+	Error  string `json:"error,omitempty"`
+}
+
+// StartReenrich handles POST /api/books/re-enrich
+// It enqueues one enrichment task per book, including books that already have
+// metadata, and records the run in the sync progress table.
+func (rc *ReenrichController) StartReenrich(c *gin.Context) {
+	for _, syncType := range []entities.SyncType{entities.SyncTypeReenrich, entities.SyncTypeMetadata} {
+		if running, err := rc.store.IsSyncRunning(syncType); err == nil && running {
+			respondError(c, http.StatusConflict, "metadata sync is already in progress")
+			return
+		}
+	}
+
+	bookIDs, err := rc.store.GetBookIDs()
+	if err != nil {
+		respondInternalError(c, err, "list books")
+		return
+	}
+
+	if _, err := rc.store.StartSyncProgress(entities.SyncTypeReenrich, len(bookIDs)); err != nil {
+		respondInternalError(c, err, "start re-enrichment")
+		return
+	}
+	if len(bookIDs) == 0 {
+		_ = rc.store.CompleteSyncProgress(entities.SyncTypeReenrich, entities.SyncStatusCompleted, "")
+	} else {
+		batch := make([]backlite.Task, len(bookIDs))
+		for i, id := range bookIDs {
+			batch[i] = tasks.EnrichBookTask{BookID: id, SyncType: entities.SyncTypeReenrich}
+		}
+		if _, err := rc.taskClient.Add(batch...).Save(); err != nil {
+			_ = rc.store.CompleteSyncProgress(entities.SyncTypeReenrich, entities.SyncStatusFailed, "failed to enqueue tasks")
+			respondInternalError(c, err, "enqueue re-enrichment tasks")
+			return
+		}
+	}
+	log.Printf("Enqueued re-enrichment of %d books", len(bookIDs))
+
+	if isHTMXRequest(c) {
+		c.Header("Content-Type", "text/html")
+		c.String(http.StatusOK, reenrichProgressHTML)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "re-enrichment started",
+		"total":   len(bookIDs),
+	})
+}
+
+// reenrichProgressHTML shows the progress of a re-enrichment run, updated by
+// the events stream
+const reenrichProgressHTML = `<div class="sync-progress" id="reenrich-status" data-reenrich-events="/api/books/re-enrich/events">
+	<div class="sync-progress-header">
+		<span class="spinner"></span>
+		<span data-reenrich-label>Re-enriching books...</span>
+	</div>
+	<div class="sync-progress-bar">
+		<div class="sync-progress-fill" data-reenrich-fill style="width: 0%"></div>
+	</div>
+	<div class="sync-progress-details">
+		<span data-reenrich-count></span>
+		<span class="sync-current-item" data-reenrich-item></span>
+	</div>
+</div>`
+
+// StreamProgress handles GET /api/books/re-enrich/events
+// It sends a "progress" event whenever the run advances and a final "done"
+// event once it has completed or failed, then closes the stream. When no run
+// is in progress a single "done" event is sent.
+func (rc *ReenrichController) StreamProgress(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable proxy buffering, e.g. nginx
+
+	ticker := time.NewTicker(rc.pollInterval)
+	defer ticker.Stop()
+
+	var lastUpdate time.Time
+	for {
+		progress, updatedAt := rc.progress()
+		if progress.Status != string(entities.SyncStatusRunning) {
+			c.SSEvent("done", progress)
+			c.Writer.Flush()
+			return
+		}
+		if !updatedAt.Equal(lastUpdate) {
+			lastUpdate = updatedAt
+			c.SSEvent("progress", progress)
+			c.Writer.Flush()
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// progress reads the current re-enrichment progress and when it last changed.
+// Runs that stalled are failed first.
+func (rc *ReenrichController) progress() (ReenrichProgress, time.Time) {
+	if _, err := rc.store.IsSyncRunning(entities.SyncTypeReenrich); err != nil {
+		return ReenrichProgress{Status: "idle", Error: err.Error()}, time.Time{}
+	}
+	record, err := rc.store.GetSyncProgress(entities.SyncTypeReenrich)
+	if err != nil {
+		return ReenrichProgress{Status: "idle"}, time.Time{}
+	}
+
+	progress := ReenrichProgress{
+		SyncStatusResponse: SyncStatusResponse{
+			Running:     record.Status == entities.SyncStatusRunning,
+			TotalItems:  record.TotalItems,
+			Processed:   record.Processed,
+			Succeeded:   record.Succeeded,
+			Failed:      record.Failed,
+			Skipped:     record.Skipped,
+			CurrentItem: record.CurrentItem,
+		},
+		Status: string(record.Status),
+		Error:  record.Error,
+	}
+	if record.TotalItems > 0 {
+		progress.Progress = float64(record.Processed) / float64(record.TotalItems) * 100
+	}
+	return progress, record.UpdatedAt
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func setupReenrichTest(t *testing.T) (*database.Database, *gin.Engine) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dbPath := "./test_reenrich_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(dbPath)
+	})
+
+	controller := NewReenrichController(db, nil)
+	controller.pollInterval = 10 * time.Millisecond
+	router := gin.New()
+	router.GET("/api/books/re-enrich/events", controller.StreamProgress)
+	return db, router
+}
+
+func TestReenrichController_StreamProgress(t *testing.T) {
+	t.Run("streams progress until the run completes", func(t *testing.T) {
+		db, router := setupReenrichTest(t)
+		_, err := db.StartSyncProgress(entities.SyncTypeReenrich, 2)
+		require.NoError(t, err)
+		require.NoError(t, db.RecordSyncItem(entities.SyncTypeReenrich, entities.SyncOutcomeSucceeded, "Dune"))
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = db.RecordSyncItem(entities.SyncTypeReenrich, entities.SyncOutcomeSkipped, "Emma")
+		}()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/books/re-enrich/events", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		assert.Contains(t, body, "event:progress\n")
+		assert.Contains(t, body, `"current_item":"Dune"`)
+		assert.Contains(t, body, "event:done\n")
+		assert.Contains(t, body, `"status":"completed"`)
+		assert.Less(t, strings.Index(body, "event:progress"), strings.Index(body, "event:done"))
+	})
+
+	t.Run("reports idle when nothing runs", func(t *testing.T) {
+		_, router := setupReenrichTest(t)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/books/re-enrich/events", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Contains(t, w.Body.String(), "event:done\n")
+		assert.Contains(t, w.Body.String(), `"status":"idle"`)
+	})
+}
@@ -2,17 +2,21 @@ package http
 
 import (
 	"html/template"
+	"log"
+	"path/filepath"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/mrlokans/assistant/internal/auth"
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/markdown"
+	"github.com/mrlokans/assistant/internal/utils"
 )
 
 // TagInfo holds tag ID and name for template rendering.
 type TagInfo struct {
-	ID   uint
-	Name string
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
 }
 
 // collectBookTags gathers all unique tags from a book and its highlights.
@@ -39,14 +43,48 @@ func collectBookTags(book entities.Book) []TagInfo {
 	return tags
 }
 
+// templateFuncs returns the custom template functions besides the
+// language-bound ones of localizedHTML
+func templateFuncs(staticAssets *StaticAssets, basePath string) template.FuncMap {
+	return template.FuncMap{
+		"asset": staticAssets.URL,
+		// Root-relative links start with the base path: href="{{ base }}/settings"
+		"base":            func() string { return basePath },
+		"collectBookTags": collectBookTags,
+		"colorName":       utils.ColorName,
+		"markdown":        markdown.ToHTML,
+		"subtract": func(a, b int) int {
+			return a - b
+		},
+		"add": func(a, b int) int {
+			return a + b
+		},
+	}
+}
+
 // NewRouter creates and configures the HTTP router with all endpoints.
 // Uses RouterConfig to receive all dependencies, improving testability
 // and reducing parameter count.
 func NewRouter(cfg RouterConfig) *gin.Engine {
 	router := gin.New()
+	// Spill multipart file parts above 8 MB to disk instead of holding them in memory
+	router.MaxMultipartMemory = 8 << 20
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 
+	// Only trusted proxies may set the client address, scheme and host
+	if len(cfg.TrustedProxies) > 0 {
+		if err := router.SetTrustedProxies(trustedProxyList(cfg.TrustedProxies)); err != nil {
+			panic(err)
+		}
+	}
+	router.Use(ForwardedHeadersMiddleware(cfg.TrustedProxies))
+	router.Use(BasePathMiddleware(cfg.BasePath))
+
+	// Refuse oversized bodies with 413 before they fill memory or disk
+	router.Use(BodyLimitMiddleware(bodyLimits(cfg)))
+	router.Use(CompressionMiddleware())
+
 	// Analytics middleware must run first to set context for SecurityHeadersMiddleware CSP
 	if cfg.PlausibleStore != nil {
 		router.Use(AnalyticsContextMiddleware(cfg.PlausibleStore))
@@ -55,10 +93,21 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 	// Apply security headers (reads analytics script URL from context if set)
 	router.Use(auth.SecurityHeadersMiddleware())
 
+	// The Moon+ Reader WebDAV share authenticates with HTTP Basic only, so it
+	// is exempt from CSRF checks
+	var basicAuthPrefixes []string
+	if cfg.MoonReaderWebDAVDir != "" {
+		basicAuthPrefixes = append(basicAuthPrefixes, MoonReaderWebDAVPrefix)
+	}
+	// Podcast apps only send HTTP Basic credentials
+	if cfg.PodcastStore != nil && cfg.PodcastAudio != nil {
+		basicAuthPrefixes = append(basicAuthPrefixes, PodcastPrefix)
+	}
+
 	// Apply CSRF protection if auth is enabled
 	// CSRF must run before session so that session context is preserved
 	if len(cfg.CSRFSecret) > 0 {
-		router.Use(auth.CSRFMiddleware(cfg.CSRFSecret, cfg.SecureCookies, cfg.AuthService))
+		router.Use(auth.CSRFMiddleware(cfg.CSRFSecret, cfg.SecureCookies, cfg.AuthService, basicAuthPrefixes...))
 	}
 
 	// Apply session middleware if enabled
@@ -69,6 +118,12 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 
 	// Apply auth middleware if enabled
 	if cfg.AuthMiddleware != nil {
+		for _, prefix := range basicAuthPrefixes {
+			cfg.AuthMiddleware.WithBasicAuth(prefix)
+		}
+		if cfg.PublicLibraryStore != nil && cfg.SettingsStore != nil {
+			cfg.AuthMiddleware.WithPublicPrefix(PublicLibraryPrefix)
+		}
 		router.Use(cfg.AuthMiddleware.Handler())
 	} else {
 		// No auth - inject default user ID
@@ -82,34 +137,57 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 	// Inject auth data for templates
 	router.Use(AuthContextMiddleware(cfg.AuthConfig.Mode))
 
+	// Resolve the user's timezone and locale on demand
+	var languages LanguageStore
+	if cfg.SettingsStore != nil {
+		router.Use(PreferencesContextMiddleware(cfg.SettingsStore))
+		languages = cfg.SettingsStore
+	}
+
+	// Pick the language pages are rendered in
+	router.Use(LanguageMiddleware(languages))
+
+	// Resolve the user's theme and library layout on demand
+	if cfg.UIPreferencesStore != nil {
+		router.Use(UIPreferencesContextMiddleware(cfg.UIPreferencesStore))
+	}
+
 	// Apply demo mode middleware if enabled
 	if cfg.DemoMiddleware != nil && cfg.DemoMiddleware.IsEnabled() {
 		router.Use(cfg.DemoMiddleware.InjectContext())
 		router.Use(cfg.DemoMiddleware.Handler())
 	}
 
-	// Define custom template functions
-	funcMap := template.FuncMap{
-		"collectBookTags": collectBookTags,
-		"subtract": func(a, b int) int {
-			return a - b
-		},
-		"add": func(a, b int) int {
-			return a + b
-		},
+	// Routes for administrators only; without auth everyone is the administrator.
+	// Created after the last router.Use, as groups copy the middleware added so far
+	admin := router.Group("")
+	if cfg.AuthMiddleware != nil {
+		admin.Use(cfg.AuthMiddleware.RequireRole(entities.UserRoleAdmin))
 	}
 
-	// Load HTML templates with custom functions
-	tmpl := template.Must(template.New("").Funcs(funcMap).ParseGlob(cfg.TemplatesPath + "/*.html"))
-	router.SetHTMLTemplate(tmpl)
+	staticAssets := NewStaticAssets(cfg.StaticPath).WithBasePath(cfg.BasePath)
+
+	// Load HTML templates with custom functions, once per UI language
+	htmlRender, err := newLocalizedHTML(cfg.TemplatesPath+"/*.html", templateFuncs(staticAssets, cfg.BasePath))
+	if err != nil {
+		panic(err)
+	}
+	router.HTMLRender = htmlRender
 
-	// Serve static files
-	router.Static("/static", cfg.StaticPath)
+	// Serve static files; fingerprinted URLs from the asset template function are cached for good
+	router.Group("/static", staticAssets.CacheMiddleware()).Static("/", cfg.StaticPath)
+
+	// The service worker is served from the root so its scope covers every page
+	router.GET("/sw.js", func(c *gin.Context) {
+		c.Header("Cache-Control", "no-cache")
+		c.File(filepath.Join(cfg.StaticPath, "sw.js"))
+	})
 
 	// Register auth routes if auth service is available
 	if cfg.AuthService != nil && cfg.AuthService.IsAuthEnabled() {
 		authController, err := auth.NewAuthController(cfg.AuthService, cfg.SessionManager, cfg.TemplatesPath, cfg.AuthConfig)
 		if err == nil {
+			authController.WithBasePath(cfg.BasePath)
 			authController.RegisterRoutes(router)
 
 			// API token management endpoints
@@ -121,9 +199,23 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 			profileController := NewProfileController(cfg.AuthService)
 			router.GET("/profile", profileController.ProfilePage)
 			router.POST("/profile/password", profileController.ChangePassword)
+			router.POST("/profile/preferences", profileController.UpdatePreferences)
 			router.POST("/profile/token", profileController.GenerateToken)
 			router.POST("/profile/token/regenerate", profileController.RegenerateToken)
 			router.DELETE("/profile/token", profileController.RevokeToken)
+			router.POST("/profile/2fa/setup", profileController.BeginTwoFactor)
+			router.POST("/profile/2fa/confirm", profileController.ConfirmTwoFactor)
+			router.POST("/profile/2fa/recovery-codes", profileController.RegenerateRecoveryCodes)
+			router.POST("/profile/2fa/disable", profileController.DisableTwoFactor)
+
+			// Admin user management
+			if cfg.AuthMiddleware != nil {
+				usersController := NewUsersController(cfg.AuthService)
+				users := router.Group("/api/users", cfg.AuthMiddleware.RequireRole(entities.UserRoleAdmin))
+				users.GET("", usersController.ListUsers)
+				users.PUT("/:id/2fa", usersController.SetTwoFactorPolicy)
+				users.DELETE("/:id/2fa", usersController.ResetTwoFactor)
+			}
 		}
 	}
 
@@ -132,13 +224,29 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 	readwiseImporter := NewReadwiseAPIImportController(cfg.BookExporter, cfg.ReadwiseToken, cfg.AuditService)
 	moonReaderImporter := NewMoonReaderImportController(cfg.BookExporter, cfg.AuditService)
 	readwiseCSVImporter := NewReadwiseCSVImportController(cfg.BookExporter, cfg.AuditService)
-	appleBooksImporter := NewAppleBooksImportController(cfg.BookExporter, cfg.AuditService)
+	appleBooksImporter := NewAppleBooksImportController(cfg.BookExporter, cfg.AuditService).
+		WithUploads(cfg.UploadStore).WithMaxFileSize(cfg.MaxBackupSize)
 	kindleImporter := NewKindleImportController(cfg.BookExporter, cfg.AuditService)
 	booksController := NewBooksController(cfg.BookReader)
-	uiController := NewUIController(cfg.BookReader, cfg.TagStore, cfg.VocabularyStore)
+	uiController := NewUIController(cfg.BookReader, cfg.TagStore, cfg.VocabularyStore).
+		WithHighlightOfTheDay(cfg.HighlightListStore != nil).
+		WithJournal(cfg.BookNoteStore != nil)
+	if cfg.BookHighlightStore != nil {
+		uiController.WithBookHighlights(cfg.BookHighlightStore)
+	}
+	if cfg.BookListStore != nil {
+		booksController.WithBookList(cfg.BookListStore)
+		uiController.WithBookList(cfg.BookListStore)
+	}
+	if cfg.SavedViewStore != nil {
+		uiController.WithSavedViews(cfg.SavedViewStore)
+	}
 	var metadataController *MetadataController
 	if cfg.MetadataEnricher != nil {
 		metadataController = NewMetadataController(cfg.MetadataEnricher, cfg.SyncProgress, cfg.TaskClient)
+		if cfg.ManualBookStore != nil {
+			metadataController.WithManualBookStore(cfg.ManualBookStore)
+		}
 	}
 	var coversController *CoversController
 	if cfg.CoverCache != nil {
@@ -152,7 +260,10 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 		cfg.MoonReaderOutputDir,
 		cfg.TaskClient != nil,
 		cfg.TaskWorkers,
-	)
+	).WithUploads(cfg.UploadStore).WithMaxBackupSize(cfg.MaxBackupSize).WithMoonReaderWebDAV(cfg.MoonReaderWebDAVDir != "")
+
+	// Book lists, covers and exports answer conditional requests with 304 Not Modified
+	conditionalGet := ConditionalGetMiddleware()
 
 	// Health endpoints
 	router.GET("/health", health.Status)
@@ -164,24 +275,115 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 
 	// Import endpoints
 	router.POST("/import/moonreader", moonReaderImporter.Import)
+	if cfg.MoonReaderWebDAVDir != "" {
+		webDAV, err := NewMoonReaderWebDAVController(cfg.MoonReaderWebDAVDir, settingsController.importMoonReaderBackupFile, cfg.AuditService)
+		if err != nil {
+			log.Printf("WARNING: Moon+ Reader WebDAV share disabled: %v", err)
+		} else {
+			webDAV.RegisterRoutes(router)
+		}
+	}
 	router.POST("/api/v2/highlights", readwiseImporter.Import)
 
 	// Books API endpoints
-	router.GET("/api/books", booksController.GetAllBooks)
+	router.GET("/api/books", conditionalGet, booksController.GetAllBooks)
 	router.GET("/api/books/search", booksController.GetBookByTitleAndAuthor)
 	router.GET("/api/books/stats", booksController.GetBookStats)
 
+	// Aggregated book details for the detail page
+	if cfg.BookDetailsStore != nil {
+		bookDetailsController := NewBookDetailsController(cfg.BookDetailsStore).WithCachedCovers(cfg.CoverCache != nil)
+		router.GET("/api/books/:id/full", conditionalGet, bookDetailsController.GetBookDetails)
+	}
+
+	// Book highlights a page at a time, for books with thousands of them
+	if cfg.BookHighlightStore != nil {
+		bookHighlightsController := NewBookHighlightsController(cfg.BookHighlightStore, cfg.VocabularyStore)
+		router.GET("/api/books/:id/highlights", bookHighlightsController.ListHighlights)
+		router.GET("/ui/books/:id/highlights", bookHighlightsController.HighlightsPage)
+	}
+
+	// Archiving books out of the way without deleting them
+	if cfg.BookArchiveStore != nil {
+		bookArchiveController := NewBookArchiveController(cfg.BookArchiveStore)
+		router.POST("/api/books/:id/archive", bookArchiveController.ArchiveBook)
+		router.DELETE("/api/books/:id/archive", bookArchiveController.UnarchiveBook)
+	}
+
+	// Reading journal of dated notes about a book as a whole
+	if cfg.BookNoteStore != nil {
+		bookNotesController := NewBookNotesController(cfg.BookNoteStore)
+		router.GET("/api/books/:id/notes", bookNotesController.ListNotes)
+		router.POST("/api/books/:id/notes", bookNotesController.CreateNote)
+		router.PATCH("/api/books/:id/notes/:noteId", bookNotesController.UpdateNote)
+		router.DELETE("/api/books/:id/notes/:noteId", bookNotesController.DeleteNote)
+	}
+
+	// BibTeX and CSL-JSON citations of a book, a tag or a collection
+	if cfg.CitationStore != nil {
+		citationsController := NewCitationsController(cfg.CitationStore)
+		router.GET("/api/books/:id/citation", citationsController.BookCitation)
+		router.GET("/api/citations", citationsController.ExportCitations)
+	}
+
+	// Manual book metadata editing, with suggestions from the metadata provider
+	if cfg.BookEditStore != nil {
+		bookEditController := NewBookEditController(cfg.BookEditStore)
+		if cfg.CoverCache != nil {
+			bookEditController.WithCoverInvalidator(cfg.CoverCache)
+		}
+		router.PATCH("/api/books/:id", bookEditController.UpdateBook)
+		if cfg.MetadataEnricher != nil {
+			bookEditController.WithEnricher(cfg.MetadataEnricher)
+			router.GET("/api/books/:id/suggestions", bookEditController.GetSuggestions)
+		}
+	}
+
 	// Book metadata enrichment endpoints
 	if metadataController != nil {
 		router.POST("/api/books/:id/enrich", metadataController.EnrichBook)
 		router.PATCH("/api/books/:id/isbn", metadataController.UpdateISBN)
 		router.POST("/api/books/enrich-all", metadataController.EnrichAllMissing)
 		router.GET("/api/sync/metadata/status", metadataController.GetSyncStatus)
+		if cfg.ManualBookStore != nil {
+			router.POST("/api/books/manual", metadataController.CreateManualBook)
+		}
+		if cfg.ReenrichStore != nil && cfg.TaskClient != nil {
+			reenrichController := NewReenrichController(cfg.ReenrichStore, cfg.TaskClient)
+			router.POST("/api/books/re-enrich", reenrichController.StartReenrich)
+			router.GET("/api/books/re-enrich/events", reenrichController.StreamProgress)
+		}
+	}
+
+	// Metadata matches held for review
+	if cfg.MetadataReviewStore != nil {
+		reviewController := NewMetadataReviewController(cfg.MetadataReviewStore)
+		if cfg.CoverCache != nil {
+			reviewController.WithCoverInvalidator(cfg.CoverCache)
+		}
+		router.GET("/api/metadata/reviews", reviewController.ListReviews)
+		router.POST("/api/metadata/reviews/:id/accept", reviewController.AcceptReview)
+		router.POST("/api/metadata/reviews/:id/reject", reviewController.RejectReview)
+		router.GET("/metadata/reviews", reviewController.ReviewsPage)
+	}
+
+	// Sync ownership, release of stuck syncs and import/export lock status
+	if cfg.SyncLockStore != nil {
+		syncLocksController := NewSyncLocksController(cfg.SyncLockStore)
+		admin.GET("/api/admin/syncs", syncLocksController.ListSyncs)
+		admin.POST("/api/admin/syncs/:type/release", syncLocksController.ReleaseSync)
+		admin.GET("/api/admin/locks", syncLocksController.ListLocks)
+	}
+
+	// Demo dataset for trying the app out
+	if cfg.DemoSeeder != nil {
+		demoSeedController := NewDemoSeedController(cfg.DemoSeeder)
+		admin.POST("/api/admin/seed-demo", demoSeedController.SeedDemo)
 	}
 
 	// Book cover endpoint
 	if coversController != nil {
-		router.GET("/api/books/:id/cover", coversController.GetCover)
+		router.GET("/api/books/:id/cover", conditionalGet, coversController.GetCover)
 	}
 
 	// Tag management endpoints
@@ -208,6 +410,217 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 		router.DELETE("/api/highlights/:id/permanent", deleteController.DeleteHighlightPermanently)
 	}
 
+	// Chunked upload endpoints for large import files
+	if cfg.UploadStore != nil {
+		uploadController := NewUploadController(cfg.UploadStore)
+		router.POST("/api/uploads", uploadController.Create)
+		router.GET("/api/uploads/:id", uploadController.Get)
+		router.HEAD("/api/uploads/:id", uploadController.Head)
+		router.PATCH("/api/uploads/:id", uploadController.WriteChunk)
+		router.DELETE("/api/uploads/:id", uploadController.Delete)
+	}
+
+	// Manual highlight entry
+	if cfg.CaptureStore != nil {
+		captureController := NewCaptureController(cfg.CaptureStore).WithOCR(cfg.OCREngine != nil)
+		router.POST("/api/books/:id/highlights", captureController.CreateHighlight)
+		router.GET("/capture", captureController.CapturePage)
+	}
+
+	// Author pages with Wikidata details, aliases and merging
+	if cfg.AuthorStore != nil {
+		authorsController := NewAuthorsController(cfg.AuthorStore)
+		router.GET("/api/authors", authorsController.ListAuthors)
+		router.GET("/api/authors/:id", authorsController.GetAuthor)
+		router.GET("/ui/authors/:id", authorsController.AuthorPage)
+		router.POST("/api/authors/:id/merge", authorsController.MergeAuthors)
+		router.POST("/api/authors/:id/aliases", authorsController.AddAlias)
+		router.DELETE("/api/authors/:id/aliases/:aliasId", authorsController.RemoveAlias)
+		if cfg.AuthorEnricher != nil {
+			authorsController.WithEnricher(cfg.AuthorEnricher)
+			router.POST("/api/authors/:id/enrich", authorsController.EnrichAuthor)
+		}
+	}
+
+	// Books grouped by series
+	if cfg.SeriesStore != nil {
+		seriesController := NewSeriesController(cfg.SeriesStore)
+		router.GET("/api/series", seriesController.ListSeries)
+		router.GET("/ui/series", seriesController.SeriesPage)
+	}
+
+	// User-defined, ordered book collections
+	if cfg.CollectionStore != nil {
+		collectionsController := NewCollectionsController(cfg.CollectionStore)
+		router.GET("/api/collections", collectionsController.ListCollections)
+		router.POST("/api/collections", collectionsController.CreateCollection)
+		router.GET("/api/collections/:id", collectionsController.GetCollection)
+		router.PATCH("/api/collections/:id", collectionsController.UpdateCollection)
+		router.DELETE("/api/collections/:id", collectionsController.DeleteCollection)
+		router.POST("/api/collections/:id/books/:bookId/move", collectionsController.MoveBook)
+		router.POST("/api/books/:id/collections", collectionsController.AddBookToCollection)
+		router.DELETE("/api/books/:id/collections/:collectionId", collectionsController.RemoveBookFromCollection)
+		router.GET("/collections", collectionsController.CollectionsPage)
+		router.GET("/ui/collections/:id", collectionsController.CollectionPage)
+		router.GET("/ui/books/:id/collections", collectionsController.BookCollections)
+	}
+
+	// Sources that contributed the highlights of a book
+	if cfg.BookSourceStore != nil {
+		bookSourcesController := NewBookSourcesController(cfg.BookSourceStore)
+		router.GET("/api/books/:id/sources", bookSourcesController.GetBookSources)
+		router.GET("/ui/books/:id/sources", bookSourcesController.BookSources)
+	}
+
+	// Per-user UI settings
+	if cfg.UIPreferencesStore != nil {
+		uiPreferencesController := NewUIPreferencesController(cfg.UIPreferencesStore)
+		router.GET("/api/preferences", uiPreferencesController.GetPreferences)
+		router.PUT("/api/preferences", uiPreferencesController.UpdatePreferences)
+	}
+
+	// Command palette
+	if cfg.QuickSearchStore != nil {
+		quickSearchController := NewQuickSearchController(cfg.QuickSearchStore, quickSearchActions(cfg))
+		router.GET("/api/quicksearch", quickSearchController.Search)
+	}
+
+	// Saved searches (smart views)
+	if cfg.SavedViewStore != nil {
+		savedViewsController := NewSavedViewsController(cfg.SavedViewStore)
+		router.GET("/api/views", savedViewsController.ListViews)
+		router.POST("/api/views", savedViewsController.CreateView)
+		router.GET("/api/views/:id", savedViewsController.GetView)
+		router.PATCH("/api/views/:id", savedViewsController.UpdateView)
+		router.DELETE("/api/views/:id", savedViewsController.DeleteView)
+		router.GET("/api/views/:id/highlights", savedViewsController.ListViewHighlights)
+		router.GET("/views", savedViewsController.ViewsPage)
+		router.GET("/ui/views/:id", savedViewsController.ViewPage)
+	}
+
+	// OCR of photographed book pages
+	if cfg.OCREngine != nil {
+		ocrController := NewOCRController(cfg.OCREngine, cfg.OCRMaxImageSize).WithTimeout(cfg.OCRTimeout)
+		router.POST("/api/ocr", ocrController.Recognize)
+	}
+
+	// Tag CSV export and import
+	if cfg.TagCSVStore != nil {
+		tagCSVController := NewTagCSVController(cfg.TagCSVStore, cfg.AuditService)
+		router.GET("/api/tags/export", tagCSVController.Export)
+		router.POST("/api/tags/import", tagCSVController.ImportJSON)
+		router.POST("/settings/tags/import", tagCSVController.Import)
+	}
+
+	// Goodreads/StoryGraph library import
+	if cfg.LibraryImportStore != nil {
+		libraryImporter := NewLibraryImportController(cfg.LibraryImportStore, cfg.AuditService)
+		router.POST("/settings/library/import", libraryImporter.Import)
+		router.POST("/import/library", libraryImporter.ImportJSON)
+	}
+
+	// Trash endpoints
+	if cfg.TrashStore != nil {
+		trashController := NewTrashController(cfg.TrashStore, cfg.AuditService, cfg.TrashRetentionDays)
+		router.GET("/api/trash", trashController.ListTrash)
+		router.DELETE("/api/trash", trashController.EmptyTrash)
+		router.POST("/api/trash/books/:id/restore", trashController.RestoreBook)
+		router.POST("/api/trash/highlights/:id/restore", trashController.RestoreHighlight)
+		router.GET("/trash", trashController.TrashPage)
+	}
+
+	// Tombstone endpoints (permanently deleted entities blocking re-import)
+	if cfg.TombstoneStore != nil {
+		tombstoneController := NewTombstoneController(cfg.TombstoneStore, cfg.AuditService)
+		router.GET("/api/tombstones", tombstoneController.ListTombstones)
+		router.DELETE("/api/tombstones/:id", tombstoneController.DeleteTombstone)
+	}
+
+	// What changed in the library between two dates
+	if cfg.LibraryDiffStore != nil {
+		diffController := NewLibraryDiffController(cfg.LibraryDiffStore)
+		router.GET("/api/library/diff", diffController.GetDiff)
+	}
+
+	// Highlight listing with filters
+	if cfg.HighlightListStore != nil {
+		highlightsController := NewHighlightsController(cfg.HighlightListStore)
+		router.GET("/api/highlights", highlightsController.ListHighlights)
+		router.GET("/api/highlights/random", highlightsController.RandomHighlight)
+		router.GET("/api/highlights/categories", highlightsController.ListCategories)
+		router.GET("/ui/highlights/daily", highlightsController.HighlightOfTheDay)
+	}
+
+	// Highlight edit history endpoints
+	if cfg.HighlightHistoryStore != nil {
+		historyController := NewHighlightHistoryController(cfg.HighlightHistoryStore)
+		router.GET("/api/highlights/conflicts", historyController.ListConflicts)
+		router.GET("/api/highlights/:id/history", historyController.GetHistory)
+		router.POST("/api/highlights/:id/history/:versionId/revert", historyController.RevertToVersion)
+	}
+
+	// Cross-source duplicate highlights report
+	if cfg.HighlightDuplicateStore != nil {
+		duplicatesController := NewHighlightDuplicatesController(cfg.HighlightDuplicateStore, cfg.AuditService)
+		admin.GET("/api/admin/duplicates", duplicatesController.ListDuplicates)
+		admin.POST("/api/admin/duplicates/merge", duplicatesController.MergeDuplicates)
+	}
+
+	// Markdown note editor
+	if cfg.NoteStore != nil {
+		notesController := NewNotesController(cfg.NoteStore)
+		router.GET("/ui/highlights/:id/note", notesController.NoteEditor)
+		router.GET("/ui/highlights/:id/note/view", notesController.NoteView)
+		router.PUT("/api/highlights/:id/note", notesController.UpdateNote)
+		router.POST("/api/notes/preview", notesController.PreviewNote)
+	}
+
+	// Read-only public library, served without authentication
+	if cfg.PublicLibraryStore != nil && cfg.SettingsStore != nil {
+		publicLibraryController := NewPublicLibraryController(cfg.PublicLibraryStore, cfg.SettingsStore)
+		router.POST("/api/books/:id/public", publicLibraryController.ShareBook)
+		router.DELETE("/api/books/:id/public", publicLibraryController.UnshareBook)
+		router.GET(PublicLibraryPrefix, publicLibraryController.LibraryPage)
+		router.GET(PublicLibraryPrefix+"/books/:id", publicLibraryController.BookPage)
+	}
+
+	// Shareable quote card images
+	if cfg.QuoteCardStore != nil {
+		quoteCardsController := NewQuoteCardsController(cfg.QuoteCardStore)
+		if cfg.CoverCache != nil {
+			quoteCardsController.WithCovers(cfg.CoverCache)
+		}
+		router.GET("/api/highlights/:id/card", quoteCardsController.GetCard)
+	}
+
+	// Podcast feeds of spoken highlights
+	if cfg.PodcastStore != nil && cfg.PodcastAudio != nil {
+		podcastController := NewPodcastController(cfg.PodcastStore, cfg.PodcastAudio)
+		router.GET(PodcastPrefix+"/books/:id/feed.xml", podcastController.BookFeed)
+		router.GET(PodcastPrefix+"/tags/:id/feed.xml", podcastController.TagFeed)
+		router.GET(PodcastPrefix+"/highlights/:id/audio.mp3", podcastController.Audio)
+	}
+
+	// Cross-references between highlights
+	if cfg.HighlightLinkStore != nil {
+		linksController := NewHighlightLinksController(cfg.HighlightLinkStore).WithQuoteCards(cfg.QuoteCardStore != nil)
+		if cfg.SettingsStore != nil {
+			linksController.WithDeepLinks(cfg.SettingsStore.GetDeepLinkTemplate)
+		}
+		router.GET("/api/highlights/:id/links", linksController.GetLinks)
+		router.POST("/api/highlights/:id/links", linksController.CreateLink)
+		router.DELETE("/api/highlights/:id/links/:linkId", linksController.DeleteLink)
+		router.GET("/ui/highlights/:id", linksController.HighlightPage)
+		router.GET("/ui/highlights/:id/links/candidates", linksController.LinkCandidates)
+	}
+
+	// GraphQL endpoint for dashboards that need arbitrary query shapes
+	if cfg.GraphQLStore != nil {
+		graphqlController := NewGraphQLController(cfg.GraphQLStore)
+		router.GET("/graphql", graphqlController.Query)
+		router.POST("/graphql", graphqlController.Query)
+	}
+
 	// Task management endpoints
 	if cfg.TaskClient != nil {
 		tasksController := NewTasksController(cfg.TaskClient)
@@ -216,6 +629,15 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 		router.POST("/api/tasks/:type/run", tasksController.RunTask)
 	}
 
+	// Live import, highlight, task and sync events, and synthetic ones for testing receivers
+	if cfg.EventBroker != nil {
+		eventsController := NewEventsController(cfg.EventBroker)
+		router.GET("/api/events", eventsController.Stream)
+
+		eventSimulatorController := NewEventSimulatorController(cfg.EventBroker)
+		admin.POST("/api/admin/events/simulate", eventSimulatorController.Simulate)
+	}
+
 	// Favourites endpoints
 	if cfg.FavouritesStore != nil {
 		favouritesController := NewFavouritesController(cfg.FavouritesStore)
@@ -223,44 +645,79 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 		router.DELETE("/api/highlights/:id/favourite", favouritesController.RemoveFavourite)
 		router.GET("/api/highlights/favourites", favouritesController.ListFavourites)
 		router.GET("/api/highlights/favourites/count", favouritesController.GetFavouriteCount)
+		router.POST("/api/books/:id/favourite", favouritesController.AddBookFavourite)
+		router.DELETE("/api/books/:id/favourite", favouritesController.RemoveBookFavourite)
+		router.GET("/api/books/favourites", favouritesController.ListFavouriteBooks)
 		router.GET("/favourites", favouritesController.FavouritesPage)
 	}
 
 	// Vocabulary endpoints
 	if cfg.VocabularyStore != nil {
-		vocabController := NewVocabularyController(cfg.VocabularyStore, cfg.DictionaryClient, cfg.TaskClient)
+		vocabController := NewVocabularyController(cfg.VocabularyStore, cfg.DictionaryClient, cfg.TaskClient).
+			WithReview(cfg.VocabularyReviewStore != nil)
+		if cfg.VocabularyMergeStore != nil {
+			vocabController.WithMerging(cfg.VocabularyMergeStore)
+		}
+		if cfg.SettingsStore != nil {
+			vocabController.WithDictionaryProviders(cfg.SettingsStore.GetDictionaryProviderEnabled)
+		}
 		router.GET("/api/vocabulary", vocabController.ListWords)
 		router.GET("/api/vocabulary/words", vocabController.GetWordsList)
 		router.POST("/api/vocabulary", vocabController.AddWord)
 		router.GET("/api/vocabulary/stats", vocabController.GetVocabularyStats)
 		router.GET("/api/vocabulary/search", vocabController.SearchWords)
+		router.GET("/api/vocabulary/candidates", vocabController.ListCandidates)
+		router.GET("/api/vocabulary/dictionaries", vocabController.ListDictionaries)
+		router.POST("/api/vocabulary/extract", vocabController.ExtractCandidates)
 		router.GET("/api/vocabulary/:id", vocabController.GetWord)
 		router.PATCH("/api/vocabulary/:id", vocabController.UpdateWord)
 		router.DELETE("/api/vocabulary/:id", vocabController.DeleteWord)
 		router.POST("/api/vocabulary/:id/enrich", vocabController.EnrichWord)
+		router.POST("/api/vocabulary/:id/confirm", vocabController.ConfirmWord)
 		router.POST("/api/vocabulary/enrich-all", vocabController.EnrichAllWords)
+		router.POST("/api/vocabulary/retry-failed", vocabController.RetryFailedWords)
 		router.GET("/api/highlights/:id/vocabulary", vocabController.GetWordsByHighlight)
+		router.POST("/api/highlights/:id/vocabulary", vocabController.AddHighlightWord)
+		router.GET("/api/books/:id/vocabulary", vocabController.GetWordsByBook)
 		router.GET("/vocabulary", vocabController.VocabularyPage)
+
+		if cfg.VocabularyMergeStore != nil {
+			mergeController := NewVocabularyMergeController(cfg.VocabularyMergeStore)
+			router.POST("/api/vocabulary/consolidate", mergeController.ConsolidateWords)
+			router.POST("/api/vocabulary/:id/merge", mergeController.MergeWords)
+		}
+
+		if cfg.VocabularyReviewStore != nil {
+			reviewController := NewVocabularyReviewController(cfg.VocabularyReviewStore)
+			router.GET("/api/vocabulary/review", reviewController.GetReviewSession)
+			router.POST("/api/vocabulary/:id/review", reviewController.ReviewWord)
+			router.GET("/vocabulary/review", reviewController.ReviewPage)
+		}
 	}
 
 	// UI routes
 	router.GET("/", uiController.BooksPage)
 	router.GET("/ui/books/:id", uiController.BookPage)
-	router.GET("/ui/books/:id/download", uiController.DownloadMarkdown)
+	router.GET("/ui/books/:id/download", conditionalGet, uiController.DownloadMarkdown)
 	router.GET("/ui/books/search", uiController.SearchBooks)
-	router.GET("/ui/download-all", uiController.DownloadAllMarkdown)
+	router.GET("/ui/download-all", conditionalGet, uiController.DownloadAllMarkdown)
 
 	// Settings routes
 	router.GET("/settings", settingsController.SettingsPage)
-	router.POST("/settings/oauth/dropbox/init", settingsController.InitDropboxAuth)
-	router.GET("/settings/oauth/dropbox/callback", settingsController.DropboxCallback)
+	router.POST("/settings/oauth/dropbox/init", settingsController.InitOAuth(entities.OAuthProviderDropbox))
+	router.GET("/settings/oauth/dropbox/callback", settingsController.OAuthCallback(entities.OAuthProviderDropbox))
 	router.POST("/settings/oauth/dropbox/check", settingsController.CheckDropboxToken)
 	router.POST("/settings/oauth/dropbox/disconnect", settingsController.DisconnectDropbox)
 	router.POST("/settings/moonreader/import", settingsController.ImportMoonReaderBackup)
+	router.POST("/settings/moonreader/upload", settingsController.ImportMoonReaderFile)
 	router.POST("/settings/readwise/import-csv", readwiseCSVImporter.Import)
+	router.POST("/settings/readwise/import-library", readwiseCSVImporter.ImportLibrary)
+	router.POST("/import/readwise/library", readwiseCSVImporter.ImportLibraryJSON)
 	router.POST("/settings/applebooks/import", appleBooksImporter.Import)
 	router.POST("/settings/kindle/import", kindleImporter.Import)
 	router.POST("/import/kindle", kindleImporter.ImportJSON)
+	router.POST("/settings/kindle/import-notebook", kindleImporter.ImportNotebook)
+	router.POST("/import/kindle/notebook", kindleImporter.ImportNotebookJSON)
 
 	// Demo mode status endpoint (always available)
 	demoController := NewDemoController(cfg.DemoMiddleware)
@@ -287,6 +744,46 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 		router.GET("/settings/obsidian/status", obsidianSyncController.GetStatus)
 	}
 
+	// Named export targets (if ExportTargetStore and ExportTargetScheduler are available)
+	if cfg.ExportTargetStore != nil && cfg.ExportTargetScheduler != nil {
+		exportTargetsController := NewExportTargetsController(cfg.ExportTargetStore, cfg.ExportTargetScheduler)
+		router.GET("/api/export-targets", exportTargetsController.ListTargets)
+		router.POST("/api/export-targets", exportTargetsController.CreateTarget)
+		router.POST("/api/export-targets/run", exportTargetsController.RunAll)
+		router.GET("/api/export-targets/:id", exportTargetsController.GetTarget)
+		router.PUT("/api/export-targets/:id", exportTargetsController.UpdateTarget)
+		router.DELETE("/api/export-targets/:id", exportTargetsController.DeleteTarget)
+		router.POST("/api/export-targets/:id/run", exportTargetsController.RunTarget)
+	}
+
+	// Unified settings API (if SettingsStore is available)
+	if cfg.SettingsStore != nil {
+		generalSettingsController := NewGeneralSettingsController(cfg.SettingsStore)
+		if cfg.ObsidianSyncScheduler != nil {
+			generalSettingsController.WithRescheduler(cfg.ObsidianSyncScheduler,
+				entities.SettingKeyObsidianSyncEnabled,
+				entities.SettingKeyObsidianSyncExportDir,
+				entities.SettingKeyObsidianSyncSchedule)
+		}
+		if cfg.VaultWatcher != nil {
+			generalSettingsController.WithRescheduler(cfg.VaultWatcher,
+				entities.SettingKeyObsidianSyncTwoWay,
+				entities.SettingKeyObsidianSyncExportDir,
+				entities.SettingKeyObsidianSyncFormat)
+		}
+		if cfg.TelegramReviewScheduler != nil {
+			generalSettingsController.WithRescheduler(cfg.TelegramReviewScheduler,
+				entities.SettingKeyTelegramReviewSchedule,
+				entities.SettingKeyDefaultTimezone)
+		}
+		router.GET("/api/settings", generalSettingsController.ListSettings)
+		router.GET("/api/settings/profile", generalSettingsController.ExportProfile)
+		router.POST("/api/settings/profile", generalSettingsController.ImportProfile)
+		router.GET("/api/settings/:key", generalSettingsController.GetSetting)
+		router.PUT("/api/settings/:key", generalSettingsController.UpdateSetting)
+		router.DELETE("/api/settings/:key", generalSettingsController.ResetSetting)
+	}
+
 	// Readwise sync settings routes (if SettingsStore and ReadwiseClient are available)
 	if cfg.SettingsStore != nil && cfg.ReadwiseClient != nil {
 		readwiseSyncController := NewReadwiseSyncController(cfg.SettingsStore, cfg.ReadwiseSyncScheduler, cfg.ReadwiseClient)
@@ -298,6 +795,31 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 		router.GET("/settings/readwise/status", readwiseSyncController.GetStatus)
 	}
 
+	// Telegram bot settings routes (if SettingsStore and TelegramClient are available)
+	if cfg.SettingsStore != nil && cfg.TelegramClient != nil {
+		telegramController := NewTelegramController(cfg.SettingsStore, cfg.TelegramReviewScheduler, cfg.TelegramClient)
+		router.GET("/settings/telegram", telegramController.GetSettings)
+		router.POST("/settings/telegram/save", telegramController.UpdateSettings)
+		router.POST("/settings/telegram/reset", telegramController.ResetSettings)
+		router.POST("/settings/telegram/validate-token", telegramController.ValidateToken)
+		router.POST("/settings/telegram/send-review", telegramController.SendReview)
+	}
+
+	// Upgrade status routes (schema changes and data backfills)
+	if cfg.UpgradeStatusStore != nil {
+		upgradeController := NewUpgradeStatusController(cfg.UpgradeStatusStore)
+		admin.GET("/upgrade", upgradeController.UpgradeStatusPage)
+		admin.GET("/api/upgrade/status", upgradeController.GetStatus)
+	}
+
+	// Database health and maintenance jobs (integrity check, vacuum, cleanups)
+	if cfg.MaintenanceStore != nil {
+		maintenanceController := NewMaintenanceController(cfg.MaintenanceStore)
+		admin.GET("/admin/health", maintenanceController.HealthPage)
+		admin.GET("/api/admin/maintenance", maintenanceController.GetStatus)
+		admin.POST("/api/admin/maintenance/:task", maintenanceController.RunTask)
+	}
+
 	// Audit log routes (admin-only, requires AuditService)
 	if cfg.AuditService != nil {
 		auditController := NewAuditController(cfg.AuditService)
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/demo"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
+)
+
+// Administration endpoints are refused to users without the admin role
+func TestRouter_AdminRoutesRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := database.NewDatabase(filepath.Join(t.TempDir(), "router.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	authConfig := config.Auth{Mode: config.AuthModeLocal, BcryptCost: 4}
+	authService := auth.NewService(db.DB, authConfig)
+	tokenFor := func(username string, role entities.UserRole) string {
+		user, err := authService.CreateUser(username, username+"@example.com", "password12345", role)
+		require.NoError(t, err)
+		token, err := authService.GenerateToken(user.ID)
+		require.NoError(t, err)
+		return token
+	}
+	adminToken := tokenFor("admin", entities.UserRoleAdmin)
+	userToken := tokenFor("reader", entities.UserRoleEditor)
+
+	router := NewRouter(RouterConfig{
+		Database:                db,
+		TemplatesPath:           "../../templates",
+		StaticPath:              "../../static",
+		AuthService:             authService,
+		AuthMiddleware:          auth.NewMiddleware(authService, nil, authConfig),
+		AuthConfig:              authConfig,
+		SyncLockStore:           db,
+		MaintenanceStore:        db,
+		DemoSeeder:              demo.NewSeeder(db),
+		HighlightDuplicateStore: db,
+		UpgradeStatusStore:      db,
+		EventBroker:             events.NewBroker(),
+	})
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/admin/syncs"},
+		{http.MethodPost, "/api/admin/syncs/metadata/release"},
+		{http.MethodGet, "/api/admin/locks"},
+		{http.MethodGet, "/admin/health"},
+		{http.MethodGet, "/api/admin/maintenance"},
+		{http.MethodPost, "/api/admin/maintenance/unknown"},
+		{http.MethodPost, "/api/admin/seed-demo"},
+		{http.MethodGet, "/api/admin/duplicates"},
+		{http.MethodPost, "/api/admin/duplicates/merge"},
+		{http.MethodGet, "/upgrade"},
+		{http.MethodGet, "/api/upgrade/status"},
+		{http.MethodPost, "/api/admin/events/simulate"},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			request := func(token string) int {
+				req := httptest.NewRequest(route.method, route.path, nil)
+				req.Header.Set("Authorization", "Bearer "+token)
+				req.Header.Set("Accept", "application/json")
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				return w.Code
+			}
+			assert.Equal(t, http.StatusForbidden, request(userToken))
+			assert.NotEqual(t, http.StatusForbidden, request(adminToken))
+		})
+	}
+}
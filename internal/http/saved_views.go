@@ -0,0 +1,310 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// savedViewPageSize is the number of highlights on a page of a saved view
+const savedViewPageSize = 50
+
+// SavedViewGetter looks up a saved view, for using it as an export filter.
+type SavedViewGetter interface {
+	GetSavedView(id uint) (*entities.SavedView, error)
+}
+
+// SavedViewStore defines database operations for saved views.
+type SavedViewStore interface {
+	SavedViewGetter
+	GetSavedViews(userID uint) ([]entities.SavedView, error)
+	CreateSavedView(view *entities.SavedView) error
+	UpdateSavedView(view *entities.SavedView) error
+	DeleteSavedView(id uint) error
+	ListHighlights(filter entities.HighlightFilter, limit, offset int) ([]entities.Highlight, int64, error)
+}
+
+// SavedViewsController manages saved highlight searches, or smart views.
+type SavedViewsController struct {
+	store SavedViewStore
+}
+
+func NewSavedViewsController(store SavedViewStore) *SavedViewsController {
+	return &SavedViewsController{store: store}
+}
+
+// SavedViewRequest is the request body for creating or updating a saved view.
+// Tags are a list in JSON and a comma-separated list in forms; dates are
+// YYYY-MM-DD or RFC 3339, and a plain "to" date includes the whole day.
+type SavedViewRequest struct {
+	Name           string   `json:"name" form:"name"`
+	Query          string   `json:"query" form:"query"`
+	Tags           []string `json:"tags" form:"tags"`
+	Source         string   `json:"source" form:"source"`
+	FavouritesOnly bool     `json:"favourites_only" form:"favourites_only"`
+	From           string   `json:"from" form:"from"`
+	To             string   `json:"to" form:"to"`
+}
+
+// ListViews returns the user's saved views.
+// GET /api/views
+func (vc *SavedViewsController) ListViews(c *gin.Context) {
+	views, err := vc.store.GetSavedViews(GetUserID(c))
+	if err != nil {
+		respondInternalError(c, err, "list saved views")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"views": views, "count": len(views)})
+}
+
+// CreateView saves a search as a named view.
+// POST /api/views
+func (vc *SavedViewsController) CreateView(c *gin.Context) {
+	view, ok := vc.bindView(c)
+	if !ok {
+		return
+	}
+	view.UserID = GetUserID(c)
+
+	err := vc.store.CreateSavedView(view)
+	if errors.Is(err, database.ErrSavedViewNameTaken) {
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "create saved view")
+		return
+	}
+	respondCreated(c, view)
+}
+
+// GetView returns a saved view's criteria.
+// GET /api/views/:id
+func (vc *SavedViewsController) GetView(c *gin.Context) {
+	view, ok := vc.loadView(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// UpdateView renames a saved view and replaces its criteria.
+// PATCH /api/views/:id
+func (vc *SavedViewsController) UpdateView(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	view, ok := vc.bindView(c)
+	if !ok {
+		return
+	}
+	view.ID = id
+
+	err := vc.store.UpdateSavedView(view)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondNotFound(c, "saved view")
+		return
+	case errors.Is(err, database.ErrSavedViewNameTaken):
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		respondInternalError(c, err, "update saved view")
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// DeleteView deletes a saved view.
+// DELETE /api/views/:id
+func (vc *SavedViewsController) DeleteView(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := vc.store.DeleteSavedView(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "saved view")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "delete saved view")
+		return
+	}
+	respondSuccess(c, "saved view deleted")
+}
+
+// ListViewHighlights returns the highlights matching a saved view, sorted like
+// ListHighlights.
+// GET /api/views/:id/highlights?sort=&limit=&offset=
+func (vc *SavedViewsController) ListViewHighlights(c *gin.Context) {
+	view, ok := vc.loadView(c)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	filter := view.HighlightFilter()
+	var err error
+	if filter.Sort, err = parseSort(c, GetUIPreferences(c).HighlightSort, entities.HighlightSorts); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	highlights, total, err := vc.store.ListHighlights(filter, limit, offset)
+	if err != nil {
+		respondInternalError(c, err, "list saved view highlights")
+		return
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:       highlights,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		HasMore:    int64(offset+len(highlights)) < total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	})
+}
+
+// ViewsPage lists the user's saved views with a form for a new one.
+// GET /views
+func (vc *SavedViewsController) ViewsPage(c *gin.Context) {
+	views, err := vc.store.GetSavedViews(GetUserID(c))
+	if err != nil {
+		respondInternalError(c, err, "list saved views")
+		return
+	}
+
+	c.HTML(http.StatusOK, "views", gin.H{
+		"Views":     views,
+		"FromDate":  "",
+		"ToDate":    "",
+		"Auth":      GetAuthTemplateData(c),
+		"UI":        GetUIPreferences(c),
+		"Demo":      GetDemoTemplateData(c),
+		"Analytics": GetAnalyticsTemplateData(c),
+	})
+}
+
+// ViewPage renders the highlights matching a saved view, a page at a time in
+// the user's highlight order.
+// GET /ui/views/:id?offset=
+func (vc *SavedViewsController) ViewPage(c *gin.Context) {
+	view, ok := vc.loadView(c)
+	if !ok {
+		return
+	}
+
+	offset := 0
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+	filter := view.HighlightFilter()
+	filter.Sort = GetUIPreferences(c).HighlightSort
+	highlights, total, err := vc.store.ListHighlights(filter, savedViewPageSize, offset)
+	if err != nil {
+		respondInternalError(c, err, "list saved view highlights")
+		return
+	}
+
+	prefs := GetUserPreferences(c)
+	data := gin.H{
+		"View":        view,
+		"Highlights":  highlights,
+		"Total":       total,
+		"Offset":      offset,
+		"PrevOffset":  max(0, offset-savedViewPageSize),
+		"NextOffset":  offset + savedViewPageSize,
+		"HasMore":     int64(offset+len(highlights)) < total,
+		"FromDate":    "",
+		"ToDate":      "",
+		"Preferences": prefs,
+		"Auth":        GetAuthTemplateData(c),
+		"UI":          GetUIPreferences(c),
+		"Demo":        GetDemoTemplateData(c),
+		"Analytics":   GetAnalyticsTemplateData(c),
+	}
+	// The form shows the inclusive last day, the filter keeps the day after it
+	if view.From != nil {
+		data["FromDate"] = view.From.In(prefs.Location).Format("2006-01-02")
+	}
+	if view.To != nil {
+		data["ToDate"] = view.To.Add(-time.Nanosecond).In(prefs.Location).Format("2006-01-02")
+	}
+	c.HTML(http.StatusOK, "view", data)
+}
+
+// bindView reads a saved view from the request, responding with an error if
+// it is not valid
+func (vc *SavedViewsController) bindView(c *gin.Context) (*entities.SavedView, bool) {
+	var req SavedViewRequest
+	if err := c.ShouldBind(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		respondBadRequest(c, "name is required")
+		return nil, false
+	}
+
+	view := &entities.SavedView{
+		Name:           req.Name,
+		Query:          strings.TrimSpace(req.Query),
+		Source:         strings.TrimSpace(req.Source),
+		FavouritesOnly: req.FavouritesOnly,
+	}
+	for _, v := range req.Tags {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				view.Tags = append(view.Tags, tag)
+			}
+		}
+	}
+
+	var err error
+	if view.From, err = parseDateBound(c, "from", req.From, false); err != nil {
+		respondBadRequest(c, err.Error())
+		return nil, false
+	}
+	if view.To, err = parseDateBound(c, "to", req.To, true); err != nil {
+		respondBadRequest(c, err.Error())
+		return nil, false
+	}
+	if view.From != nil && view.To != nil && !view.From.Before(*view.To) {
+		respondBadRequest(c, "from must be before to")
+		return nil, false
+	}
+	return view, true
+}
+
+func (vc *SavedViewsController) loadView(c *gin.Context) (*entities.SavedView, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	view, err := vc.store.GetSavedView(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondNotFound(c, "saved view")
+		return nil, false
+	}
+	if err != nil {
+		respondInternalError(c, err, "get saved view")
+		return nil, false
+	}
+	return view, true
+}
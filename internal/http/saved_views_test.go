@@ -0,0 +1,71 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestSavedViewsController_CreateAndList(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	book := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Source: entities.Source{Name: "kindle"}, Highlights: []entities.Highlight{
+		{Text: "Waste no more time arguing what a good man should be", HighlightedAt: day, IsFavorite: true, Source: entities.Source{Name: "kindle"}},
+		{Text: "You have power over your mind", HighlightedAt: day, Source: entities.Source{Name: "kindle"}},
+	}}
+	require.NoError(t, db.SaveBook(book))
+	tag, err := db.GetOrCreateTag("stoicism", DefaultUserID)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToBook(book.ID, tag.ID))
+
+	controller := NewSavedViewsController(db)
+	router := gin.New()
+	router.GET("/api/views", controller.ListViews)
+	router.POST("/api/views", controller.CreateView)
+	router.GET("/api/views/:id/highlights", controller.ListViewHighlights)
+
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/views", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := create(`{"name":"Stoic favourites","tags":["Stoicism"],"source":"kindle","favourites_only":true,"from":"2024-05-01","to":"2024-05-01"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var view entities.SavedView
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &view))
+
+	assert.Equal(t, http.StatusConflict, create(`{"name":"stoic favourites"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, create(`{"name":"Backwards","from":"2024-05-02","to":"2024-05-01"}`).Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/views/%d/highlights", view.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var page struct {
+		Data  []entities.Highlight `json:"data"`
+		Total int64                `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, int64(1), page.Total)
+	require.Len(t, page.Data, 1)
+	assert.True(t, page.Data[0].IsFavorite)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/views", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+}
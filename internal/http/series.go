@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// SeriesStore defines database operations for the series view.
+type SeriesStore interface {
+	GetSeries(userID uint) ([]entities.Series, error)
+}
+
+// SeriesController serves books grouped by series.
+type SeriesController struct {
+	store SeriesStore
+}
+
+func NewSeriesController(store SeriesStore) *SeriesController {
+	return &SeriesController{store: store}
+}
+
+// ListSeries returns the user's series with their books in reading order.
+// GET /api/series
+func (sc *SeriesController) ListSeries(c *gin.Context) {
+	series, err := sc.store.GetSeries(auth.GetUserID(c))
+	if err != nil {
+		respondInternalError(c, err, "list series")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"series": series, "count": len(series)})
+}
+
+// SeriesPage renders the user's series with their books in reading order.
+// GET /ui/series
+func (sc *SeriesController) SeriesPage(c *gin.Context) {
+	series, err := sc.store.GetSeries(auth.GetUserID(c))
+	if err != nil {
+		respondInternalError(c, err, "list series")
+		return
+	}
+
+	c.HTML(http.StatusOK, "series", gin.H{
+		"Series":    series,
+		"Auth":      GetAuthTemplateData(c),
+		"UI":        GetUIPreferences(c),
+		"Demo":      GetDemoTemplateData(c),
+		"Analytics": GetAnalyticsTemplateData(c),
+	})
+}
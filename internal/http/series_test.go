@@ -0,0 +1,40 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestSeriesController_ListSeries(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune Messiah", Author: "Frank Herbert", Series: "Dune", SeriesIndex: 2}))
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune", Author: "Frank Herbert", Series: "Dune", SeriesIndex: 1}))
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Ulysses", Author: "James Joyce"}))
+
+	router := gin.New()
+	router.GET("/api/series", NewSeriesController(db).ListSeries)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/series", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Series []entities.Series `json:"series"`
+		Count  int               `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 1, resp.Count)
+	require.Len(t, resp.Series[0].Books, 2)
+	assert.Equal(t, "Dune", resp.Series[0].Books[0].Title)
+}
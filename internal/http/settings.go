@@ -1,17 +1,14 @@
 package http
 
 import (
-	"crypto/rand"
-	"crypto/sha256"
-	"encoding/base64"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log"
 	"net/http"
-	"net/url"
+	"os"
 	"path/filepath"
-	"strings"
-	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,20 +16,32 @@ import (
 	"github.com/mrlokans/assistant/internal/entities"
 	"github.com/mrlokans/assistant/internal/exporters"
 	"github.com/mrlokans/assistant/internal/moonreader"
+	"github.com/mrlokans/assistant/internal/oauth2"
+	"github.com/mrlokans/assistant/internal/oauth2/providers"
 	"github.com/mrlokans/assistant/internal/settingsstore"
 	"github.com/mrlokans/assistant/internal/tokenstore"
+	"github.com/mrlokans/assistant/internal/uploads"
 )
 
 const (
-	dropboxAuthURL  = "https://www.dropbox.com/oauth2/authorize"
-	dropboxTokenURL = "https://api.dropboxapi.com/oauth2/token"
-	dropboxUserURL  = "https://api.dropboxapi.com/2/users/get_current_account"
+	// Default maximum size of a Moon+ Reader backup sent as a regular multipart
+	// upload (50 MB); larger backups go through the chunked upload API
+	maxMoonReaderBackupSize = 50 * 1024 * 1024
+
+	dropboxUserURL = "https://api.dropboxapi.com/2/users/get_current_account"
 )
 
+// errDropboxReconnect means the stored Dropbox token can no longer be used or
+// refreshed, so Dropbox has to be connected again.
+var errDropboxReconnect = errors.New("dropbox access has expired or was revoked")
+
 type SettingsController struct {
 	DatabasePath  string
 	DropboxAppKey string
 
+	// Refreshes expired Dropbox tokens; nil without an app key
+	dropboxProvider oauth2.Provider
+
 	// MoonReader configuration
 	MoonReaderDropboxPath  string
 	MoonReaderDatabasePath string
@@ -41,20 +50,45 @@ type SettingsController struct {
 	// Settings store for persistent settings
 	settingsStore *settingsstore.SettingsStore
 
+	// Chunked uploads of large backup files (optional)
+	uploads *uploads.Store
+
+	// Largest backup accepted as a regular multipart upload
+	maxBackupSize int64
+
+	// Whether Moon+ Reader can upload backups over WebDAV
+	moonReaderWebDAV bool
+
 	// Task queue info
 	TasksEnabled bool
 	TaskWorkers  int
 
-	// In-memory store for PKCE state (code_verifier keyed by state)
-	// In production, consider using a more persistent store
-	pkceStore   map[string]pkceData
-	pkceStoreMu sync.RWMutex
+	// Connects the OAuth providers in its registry from the settings page
+	oauthFlow *oauth2.WebFlow
+}
+
+// settingsOAuthProvider describes a provider that can be connected from the
+// settings page
+type settingsOAuthProvider struct {
+	Label         string
+	NotConfigured string // Shown when the provider is not registered
 }
 
-type pkceData struct {
-	codeVerifier string
-	redirectURI  string
-	createdAt    time.Time
+var settingsOAuthProviders = map[entities.OAuthProvider]settingsOAuthProvider{
+	entities.OAuthProviderDropbox: {
+		Label:         "Dropbox",
+		NotConfigured: "Dropbox App Key not configured. Set DROPBOX_APP_KEY environment variable.",
+	},
+}
+
+func oauthProviderInfo(name entities.OAuthProvider) settingsOAuthProvider {
+	if info, ok := settingsOAuthProviders[name]; ok {
+		return info
+	}
+	return settingsOAuthProvider{
+		Label:         string(name),
+		NotConfigured: fmt.Sprintf("%s is not configured.", name),
+	}
 }
 
 type DropboxStatus struct {
@@ -72,19 +106,32 @@ func NewSettingsController(databasePath string, dropboxAppKey string, moonReader
 	db, err := database.NewDatabase(databasePath)
 	var store *settingsstore.SettingsStore
 	if err == nil {
-		store = settingsstore.New(db)
+		store = settingsstore.New(db).WithConfigValues(map[string]string{
+			entities.SettingKeyMoonReaderDropboxPath:  moonReaderDropboxPath,
+			entities.SettingKeyMoonReaderDatabasePath: moonReaderDatabasePath,
+			entities.SettingKeyMoonReaderOutputDir:    moonReaderOutputDir,
+		})
+	}
+
+	registry := oauth2.NewRegistry()
+	var dropboxProvider oauth2.Provider
+	if dropboxAppKey != "" {
+		dropboxProvider = providers.NewDropboxProvider(dropboxAppKey)
+		registry.Register(dropboxProvider)
 	}
 
 	return &SettingsController{
 		DatabasePath:           databasePath,
 		DropboxAppKey:          dropboxAppKey,
+		dropboxProvider:        dropboxProvider,
 		MoonReaderDropboxPath:  moonReaderDropboxPath,
 		MoonReaderDatabasePath: moonReaderDatabasePath,
 		MoonReaderOutputDir:    moonReaderOutputDir,
 		settingsStore:          store,
 		TasksEnabled:           tasksEnabled,
 		TaskWorkers:            taskWorkers,
-		pkceStore:              make(map[string]pkceData),
+		oauthFlow:              oauth2.NewWebFlow(registry),
+		maxBackupSize:          maxMoonReaderBackupSize,
 	}
 }
 
@@ -96,233 +143,92 @@ func (c *SettingsController) SettingsPage(ctx *gin.Context) {
 		"DropboxStatus":     status,
 		"TasksEnabled":      c.TasksEnabled,
 		"TaskWorkers":       c.TaskWorkers,
+		"MoonReaderWebDAV":  c.moonReaderWebDAV,
+		"WebDAVURL":         MoonReaderWebDAVPrefix + "/",
 		"Auth":              GetAuthTemplateData(ctx),
+		"UI":                GetUIPreferences(ctx),
 		"Demo":              GetDemoTemplateData(ctx),
 		"Analytics":         GetAnalyticsTemplateData(ctx),
 	})
 }
 
-func (c *SettingsController) InitDropboxAuth(ctx *gin.Context) {
-	if c.DropboxAppKey == "" {
-		ctx.HTML(http.StatusBadRequest, "settings-error", gin.H{
-			"Error": "Dropbox App Key not configured. Set DROPBOX_APP_KEY environment variable.",
-		})
-		return
-	}
-
-	// Generate PKCE code verifier and challenge
-	codeVerifier, err := generateCodeVerifier()
-	if err != nil {
-		ctx.HTML(http.StatusInternalServerError, "settings-error", gin.H{
-			"Error": "Failed to generate security code",
-		})
-		return
-	}
-	codeChallenge := generateCodeChallenge(codeVerifier)
-
-	// Generate state for CSRF protection
-	state, err := generateState()
-	if err != nil {
-		ctx.HTML(http.StatusInternalServerError, "settings-error", gin.H{
-			"Error": "Failed to generate security state",
-		})
-		return
-	}
-
-	// Build redirect URI from current request, respecting reverse proxy headers
-	scheme := "http"
-	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
-		scheme = "https"
-	}
-	host := getEffectiveHost(ctx)
-	redirectURI := fmt.Sprintf("%s://%s/settings/oauth/dropbox/callback", scheme, host)
-
-	// Store PKCE data
-	c.pkceStoreMu.Lock()
-	c.pkceStore[state] = pkceData{
-		codeVerifier: codeVerifier,
-		redirectURI:  redirectURI,
-		createdAt:    time.Now(),
-	}
-	c.pkceStoreMu.Unlock()
-
-	// Clean up old PKCE entries (older than 10 minutes)
-	go c.cleanupOldPKCE()
-
-	// Build authorization URL
-	params := url.Values{}
-	params.Set("client_id", c.DropboxAppKey)
-	params.Set("response_type", "code")
-	params.Set("code_challenge", codeChallenge)
-	params.Set("code_challenge_method", "S256")
-	params.Set("state", state)
-	params.Set("token_access_type", "offline")
-	params.Set("redirect_uri", redirectURI)
-
-	authURL := dropboxAuthURL + "?" + params.Encode()
-
-	ctx.Redirect(http.StatusFound, authURL)
-}
-
-func (c *SettingsController) DropboxCallback(ctx *gin.Context) {
-	// Check for errors
-	if errParam := ctx.Query("error"); errParam != "" {
-		errDesc := ctx.Query("error_description")
-		ctx.HTML(http.StatusBadRequest, "settings-callback", gin.H{
-			"Success": false,
-			"Error":   fmt.Sprintf("%s: %s", errParam, errDesc),
-		})
-		return
-	}
-
-	state := ctx.Query("state")
-	code := ctx.Query("code")
-
-	if state == "" || code == "" {
-		ctx.HTML(http.StatusBadRequest, "settings-callback", gin.H{
-			"Success": false,
-			"Error":   "Missing state or authorization code",
-		})
-		return
-	}
-
-	// Retrieve and validate PKCE data
-	c.pkceStoreMu.Lock()
-	data, ok := c.pkceStore[state]
-	if ok {
-		delete(c.pkceStore, state)
-	}
-	c.pkceStoreMu.Unlock()
-
-	if !ok {
-		ctx.HTML(http.StatusBadRequest, "settings-callback", gin.H{
-			"Success": false,
-			"Error":   "Invalid or expired state. Please try again.",
-		})
-		return
-	}
-
-	// Exchange code for token
-	tokenData := url.Values{}
-	tokenData.Set("grant_type", "authorization_code")
-	tokenData.Set("code", code)
-	tokenData.Set("client_id", c.DropboxAppKey)
-	tokenData.Set("code_verifier", data.codeVerifier)
-	tokenData.Set("redirect_uri", data.redirectURI)
-
-	req, err := http.NewRequest("POST", dropboxTokenURL, strings.NewReader(tokenData.Encode()))
-	if err != nil {
-		ctx.HTML(http.StatusInternalServerError, "settings-callback", gin.H{
-			"Success": false,
-			"Error":   "Failed to create token request",
-		})
-		return
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		ctx.HTML(http.StatusInternalServerError, "settings-callback", gin.H{
-			"Success": false,
-			"Error":   "Failed to exchange authorization code",
-		})
-		return
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		ctx.HTML(http.StatusInternalServerError, "settings-callback", gin.H{
-			"Success": false,
-			"Error":   "Failed to read token response",
-		})
-		return
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		var errResp struct {
-			Error            string `json:"error"`
-			ErrorDescription string `json:"error_description"`
+// InitOAuth starts connecting an OAuth provider by redirecting to its
+// authorization page
+func (c *SettingsController) InitOAuth(name entities.OAuthProvider) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Build redirect URI from current request, respecting trusted reverse proxy headers and the base path
+		redirectURI := requestBaseURL(ctx) + "/settings/oauth/" + string(name) + "/callback"
+
+		authURL, err := c.oauthFlow.Start(name, redirectURI)
+		if errors.Is(err, oauth2.ErrProviderNotFound) {
+			ctx.HTML(http.StatusBadRequest, "settings-error", gin.H{
+				"Error": oauthProviderInfo(name).NotConfigured,
+			})
+			return
 		}
-		if json.Unmarshal(body, &errResp) == nil {
-			ctx.HTML(http.StatusBadRequest, "settings-callback", gin.H{
-				"Success": false,
-				"Error":   fmt.Sprintf("Token exchange failed: %s - %s", errResp.Error, errResp.ErrorDescription),
+		if err != nil {
+			ctx.HTML(http.StatusInternalServerError, "settings-error", gin.H{
+				"Error": "Failed to start authorization",
 			})
 			return
 		}
-		ctx.HTML(http.StatusBadRequest, "settings-callback", gin.H{
-			"Success": false,
-			"Error":   fmt.Sprintf("Token exchange failed with status %d", resp.StatusCode),
-		})
-		return
-	}
-
-	var tokenResp struct {
-		AccessToken  string `json:"access_token"`
-		TokenType    string `json:"token_type"`
-		ExpiresIn    int    `json:"expires_in"`
-		RefreshToken string `json:"refresh_token"`
-		Scope        string `json:"scope"`
-		UID          string `json:"uid"`
-		AccountID    string `json:"account_id"`
-	}
 
-	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		ctx.HTML(http.StatusInternalServerError, "settings-callback", gin.H{
-			"Success": false,
-			"Error":   "Failed to parse token response",
-		})
-		return
+		ctx.Redirect(http.StatusFound, authURL)
 	}
+}
 
-	// Calculate expiry time
-	var expiresAt *time.Time
-	if tokenResp.ExpiresIn > 0 {
-		exp := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
-		expiresAt = &exp
-	}
+// OAuthCallback completes connecting an OAuth provider when it redirects back
+// after authorization, and saves the tokens
+func (c *SettingsController) OAuthCallback(name entities.OAuthProvider) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		provider := oauthProviderInfo(name).Label
 
-	// Save token to database
-	store, err := tokenstore.New(tokenstore.Config{
-		DatabasePath: c.DatabasePath,
-	})
-	if err != nil {
-		ctx.HTML(http.StatusInternalServerError, "settings-callback", gin.H{
-			"Success": false,
-			"Error":   fmt.Sprintf("Failed to open token store: %v", err),
+		store, err := tokenstore.New(tokenstore.Config{
+			DatabasePath: c.DatabasePath,
 		})
-		return
-	}
-	defer store.Close()
+		if err != nil {
+			ctx.HTML(http.StatusInternalServerError, "settings-callback", gin.H{
+				"Provider": provider,
+				"Success":  false,
+				"Error":    fmt.Sprintf("Failed to open token store: %v", err),
+			})
+			return
+		}
+		defer store.Close()
 
-	token := &entities.DecryptedToken{
-		Provider:     entities.OAuthProviderDropbox,
-		AccountID:    tokenResp.AccountID,
-		AccessToken:  tokenResp.AccessToken,
-		RefreshToken: tokenResp.RefreshToken,
-		TokenType:    tokenResp.TokenType,
-		ExpiresAt:    expiresAt,
-		Scope:        tokenResp.Scope,
-	}
+		result, err := c.oauthFlow.Callback(ctx.Request.Context(), name, ctx.Request.URL.Query(), store)
+		if err != nil {
+			status := http.StatusBadRequest
+			message := err.Error()
+			switch {
+			case errors.Is(err, oauth2.ErrMissingCode):
+				message = "Missing state or authorization code"
+			case errors.Is(err, oauth2.ErrInvalidState):
+				message = "Invalid or expired state. Please try again."
+			case errors.Is(err, oauth2.ErrAuthorizationDenied):
+				// Shows the provider's error as is
+			default:
+				log.Printf("Failed to connect %s: %v", provider, err)
+				status = http.StatusInternalServerError
+			}
+			ctx.HTML(status, "settings-callback", gin.H{
+				"Provider": provider,
+				"Success":  false,
+				"Error":    message,
+			})
+			return
+		}
 
-	if err := store.SaveToken(token); err != nil {
-		ctx.HTML(http.StatusInternalServerError, "settings-callback", gin.H{
-			"Success": false,
-			"Error":   fmt.Sprintf("Failed to save token: %v", err),
+		ctx.HTML(http.StatusOK, "settings-callback", gin.H{
+			"Provider":  provider,
+			"Success":   true,
+			"AccountID": result.AccountID,
 		})
-		return
 	}
-
-	ctx.HTML(http.StatusOK, "settings-callback", gin.H{
-		"Success":   true,
-		"AccountID": tokenResp.AccountID,
-	})
 }
 
 func (c *SettingsController) CheckDropboxToken(ctx *gin.Context) {
-	status := c.getDropboxStatusWithValidation()
+	status := c.getDropboxStatusWithValidation(ctx.Request.Context())
 	ctx.HTML(http.StatusOK, "dropbox-status", status)
 }
 
@@ -361,6 +267,28 @@ func (c *SettingsController) DisconnectDropbox(ctx *gin.Context) {
 	})
 }
 
+// WithUploads allows Moon+ Reader backups to be provided as completed chunked
+// uploads via the backup_upload_id form value.
+func (c *SettingsController) WithUploads(store *uploads.Store) *SettingsController {
+	c.uploads = store
+	return c
+}
+
+// WithMaxBackupSize sets the largest Moon+ Reader backup accepted as a
+// regular multipart upload. Sizes of 0 or less keep the default.
+func (c *SettingsController) WithMaxBackupSize(size int64) *SettingsController {
+	if size > 0 {
+		c.maxBackupSize = size
+	}
+	return c
+}
+
+// WithMoonReaderWebDAV shows the WebDAV address Moon+ Reader can back up to.
+func (c *SettingsController) WithMoonReaderWebDAV(enabled bool) *SettingsController {
+	c.moonReaderWebDAV = enabled
+	return c
+}
+
 type MoonReaderImportResult struct {
 	Success       bool              `json:"success"`
 	Error         string            `json:"error,omitempty"`
@@ -369,6 +297,7 @@ type MoonReaderImportResult struct {
 	BooksExported int               `json:"books_exported"`
 	ExportedFiles map[string]string `json:"exported_files,omitempty"`
 	Errors        []string          `json:"errors,omitempty"`
+	Reconnect     bool              `json:"reconnect,omitempty"` // Dropbox has to be connected again
 }
 
 func (c *SettingsController) ImportMoonReaderBackup(ctx *gin.Context) {
@@ -385,8 +314,8 @@ func (c *SettingsController) ImportMoonReaderBackup(ctx *gin.Context) {
 	}
 	defer store.Close()
 
-	token, err := store.GetTokenByProvider(entities.OAuthProviderDropbox)
-	if err != nil || token == nil {
+	source, err := c.dropboxTokenSource(store)
+	if err != nil {
 		ctx.HTML(http.StatusBadRequest, "import-result", &MoonReaderImportResult{
 			Success: false,
 			Error:   "Dropbox not connected. Please connect Dropbox first.",
@@ -394,33 +323,126 @@ func (c *SettingsController) ImportMoonReaderBackup(ctx *gin.Context) {
 		return
 	}
 
-	// Convert paths to absolute
-	absOutputDir, err := filepath.Abs(c.MoonReaderOutputDir)
+	// Import from Dropbox
+	dropboxPath := c.MoonReaderDropboxPath
+	if c.settingsStore != nil {
+		dropboxPath = c.settingsStore.GetMoonReaderDropboxPath()
+	}
+	var dbPath string
+	var cleanup func()
+	err = withDropboxToken(ctx.Request.Context(), source, func(accessToken string) error {
+		var extractErr error
+		dbPath, cleanup, _, extractErr = moonreader.NewDropboxBackupExtractor(accessToken).
+			WithBasePath(dropboxPath).ExtractLatestDatabase()
+		return extractErr
+	})
+	if errors.Is(err, errDropboxReconnect) {
+		log.Printf("Dropbox import needs reconnecting: %v", err)
+		// Rendered with 200 so htmx swaps the reconnect prompt into the page
+		ctx.HTML(http.StatusOK, "import-result", &MoonReaderImportResult{
+			Success:   false,
+			Error:     "Dropbox access has expired or was revoked. Reconnect Dropbox to import backups again.",
+			Reconnect: true,
+		})
+		return
+	}
 	if err != nil {
 		ctx.HTML(http.StatusInternalServerError, "import-result", &MoonReaderImportResult{
 			Success: false,
-			Error:   fmt.Sprintf("Invalid output directory: %v", err),
+			Error:   fmt.Sprintf("Failed to download backup from Dropbox: %v", err),
 		})
 		return
 	}
+	defer cleanup()
+
+	result, status := c.importMoonReaderDatabase(dbPath)
+	if !result.Success {
+		ctx.HTML(status, "import-result", result)
+		return
+	}
+
+	// Update last used timestamp
+	_ = store.UpdateLastUsed(entities.OAuthProviderDropbox, source.AccountID())
 
-	absDBPath, err := filepath.Abs(c.MoonReaderDatabasePath)
+	ctx.HTML(http.StatusOK, "import-result", result)
+}
+
+// ImportMoonReaderFile imports an uploaded Moon+ Reader backup (.mrpro or .mrstd).
+// The backup is validated by extracting its notes database before anything is imported.
+// POST /settings/moonreader/upload
+func (c *SettingsController) ImportMoonReaderFile(ctx *gin.Context) {
+	tempDir, err := os.MkdirTemp("", "moonreader-upload-*")
 	if err != nil {
 		ctx.HTML(http.StatusInternalServerError, "import-result", &MoonReaderImportResult{
 			Success: false,
-			Error:   fmt.Sprintf("Invalid database path: %v", err),
+			Error:   "Failed to create temporary directory",
+		})
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	backupPath := filepath.Join(tempDir, "backup.mrpro")
+	if _, err := receiveImportFile(ctx, c.uploads, "backup", UploadKindMoonReaderBackup, backupPath, c.maxBackupSize); err != nil {
+		ctx.HTML(importFileStatus(err), "import-result", &MoonReaderImportResult{
+			Success: false,
+			Error:   fmt.Sprintf("Backup file: %v", err),
 		})
 		return
 	}
 
+	result, status := c.importMoonReaderBackupFile(backupPath)
+	ctx.HTML(status, "import-result", result)
+}
+
+// importMoonReaderBackupFile validates a Moon+ Reader backup by extracting its
+// notes database, then imports the notes.
+func (c *SettingsController) importMoonReaderBackupFile(backupPath string) (*MoonReaderImportResult, int) {
+	dbPath, extractDir, err := moonreader.NewBackupExtractor(filepath.Dir(backupPath)).ExtractDatabase(backupPath)
+	if err != nil {
+		return &MoonReaderImportResult{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid Moon+ Reader backup: %v", err),
+		}, http.StatusBadRequest
+	}
+	defer os.RemoveAll(extractDir)
+
+	return c.importMoonReaderDatabase(dbPath)
+}
+
+// importMoonReaderDatabase merges notes from an extracted Moon+ Reader backup database
+// into the local notes database and exports them to markdown.
+// Returns the result and the HTTP status to render it with.
+func (c *SettingsController) importMoonReaderDatabase(dbPath string) (*MoonReaderImportResult, int) {
+	outputDir, notesDBPath := c.MoonReaderOutputDir, c.MoonReaderDatabasePath
+	if c.settingsStore != nil {
+		outputDir = c.settingsStore.GetMoonReaderOutputDir()
+		notesDBPath = c.settingsStore.GetMoonReaderDatabasePath()
+	}
+
+	// Convert paths to absolute
+	absOutputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return &MoonReaderImportResult{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid output directory: %v", err),
+		}, http.StatusInternalServerError
+	}
+
+	absDBPath, err := filepath.Abs(notesDBPath)
+	if err != nil {
+		return &MoonReaderImportResult{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid database path: %v", err),
+		}, http.StatusInternalServerError
+	}
+
 	// Initialize local database
 	accessor, err := moonreader.NewLocalDBAccessor(absDBPath)
 	if err != nil {
-		ctx.HTML(http.StatusInternalServerError, "import-result", &MoonReaderImportResult{
+		return &MoonReaderImportResult{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to initialize local database: %v", err),
-		})
-		return
+		}, http.StatusInternalServerError
 	}
 	defer accessor.Close()
 
@@ -429,29 +451,14 @@ func (c *SettingsController) ImportMoonReaderBackup(ctx *gin.Context) {
 		ExportedFiles: make(map[string]string),
 	}
 
-	// Import from Dropbox
-	extractor := moonreader.NewDropboxBackupExtractor(token.AccessToken)
-	extractor.WithBasePath(c.MoonReaderDropboxPath)
-
-	dbPath, cleanup, _, err := extractor.ExtractLatestDatabase()
-	if err != nil {
-		ctx.HTML(http.StatusInternalServerError, "import-result", &MoonReaderImportResult{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to download backup from Dropbox: %v", err),
-		})
-		return
-	}
-	defer cleanup()
-
 	// Read notes from backup
 	reader := moonreader.NewBackupDBReader(dbPath)
 	notes, err := reader.GetNotes()
 	if err != nil {
-		ctx.HTML(http.StatusInternalServerError, "import-result", &MoonReaderImportResult{
+		return &MoonReaderImportResult{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to read notes from backup: %v", err),
-		})
-		return
+		}, http.StatusInternalServerError
 	}
 
 	result.Highlights = len(notes)
@@ -466,11 +473,10 @@ func (c *SettingsController) ImportMoonReaderBackup(ctx *gin.Context) {
 	// Upsert notes to local database
 	if len(notes) > 0 {
 		if err := accessor.UpsertNotes(notes); err != nil {
-			ctx.HTML(http.StatusInternalServerError, "import-result", &MoonReaderImportResult{
+			return &MoonReaderImportResult{
 				Success: false,
 				Error:   fmt.Sprintf("Failed to save notes: %v", err),
-			})
-			return
+			}, http.StatusInternalServerError
 		}
 	}
 
@@ -480,23 +486,31 @@ func (c *SettingsController) ImportMoonReaderBackup(ctx *gin.Context) {
 		result.Errors = append(result.Errors, fmt.Sprintf("Failed to get notes by book: %v", err))
 	} else if len(notesByBook) > 0 {
 		books := moonreader.ConvertToEntities(notesByBook)
-		mdExporter := exporters.NewMarkdownExporter(absOutputDir)
-		exportResult, err := mdExporter.Export(books)
+		format, filenameStyle, language := exporters.FormatMarkdown, exporters.FilenameStyleTitle, ""
+		if c.settingsStore != nil {
+			format = c.settingsStore.GetMoonReaderOutputFormat()
+			filenameStyle = c.settingsStore.GetExportFilenameStyle()
+			language = c.settingsStore.GetDefaultLanguage()
+		}
+		fileExporter, err := exporters.NewFileExporter(format, absOutputDir)
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("Export error: %v", err))
+			return result, http.StatusOK
+		}
+		fileExporter.SetFilenameStyle(filenameStyle)
+		fileExporter.SetLanguage(language)
+		if exportResult, err := fileExporter.Export(books); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Export error: %v", err))
 		} else {
 			result.BooksExported = exportResult.BooksProcessed
 			// Build exported files map from books
 			for _, book := range books {
-				result.ExportedFiles[book.Title] = filepath.Join(absOutputDir, book.Source.Name, book.Title+".md")
+				result.ExportedFiles[book.Title] = exporters.ExportedBookPath(absOutputDir, format, &book)
 			}
 		}
 	}
 
-	// Update last used timestamp
-	_ = store.UpdateLastUsed(entities.OAuthProviderDropbox, token.AccountID)
-
-	ctx.HTML(http.StatusOK, "import-result", result)
+	return result, http.StatusOK
 }
 
 func (c *SettingsController) getDropboxStatus() *DropboxStatus {
@@ -515,16 +529,17 @@ func (c *SettingsController) getDropboxStatus() *DropboxStatus {
 
 	token := tokens[0]
 	return &DropboxStatus{
-		Connected:  true,
-		AccountID:  token.AccountID,
-		ExpiresAt:  token.ExpiresAt,
-		IsExpired:  token.IsExpired(),
+		Connected: true,
+		AccountID: token.AccountID,
+		ExpiresAt: token.ExpiresAt,
+		// An expired token is refreshed on next use when it can be
+		IsExpired:  token.IsExpired() && (token.RefreshToken == "" || c.dropboxProvider == nil),
 		LastUsedAt: token.LastUsedAt,
 	}
 }
 
-// Validates with Dropbox API
-func (c *SettingsController) getDropboxStatusWithValidation() *DropboxStatus {
+// Validates with Dropbox API, refreshing an expired token first
+func (c *SettingsController) getDropboxStatusWithValidation(ctx context.Context) *DropboxStatus {
 	store, err := tokenstore.New(tokenstore.Config{
 		DatabasePath: c.DatabasePath,
 	})
@@ -533,48 +548,9 @@ func (c *SettingsController) getDropboxStatusWithValidation() *DropboxStatus {
 	}
 	defer store.Close()
 
-	token, err := store.GetTokenByProvider(entities.OAuthProviderDropbox)
-	if err != nil || token == nil {
-		return &DropboxStatus{Connected: false}
-	}
-
-	// Validate token by calling Dropbox API
-	req, err := http.NewRequest("POST", dropboxUserURL, nil)
-	if err != nil {
-		return &DropboxStatus{
-			Connected: true,
-			AccountID: token.AccountID,
-			IsExpired: true,
-		}
-	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return &DropboxStatus{
-			Connected: true,
-			AccountID: token.AccountID,
-			IsExpired: true,
-		}
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return &DropboxStatus{
-			Connected: true,
-			AccountID: token.AccountID,
-			IsExpired: true,
-		}
-	}
-
-	body, err := io.ReadAll(resp.Body)
+	source, err := c.dropboxTokenSource(store)
 	if err != nil {
-		return &DropboxStatus{
-			Connected: true,
-			AccountID: token.AccountID,
-			IsExpired: false,
-		}
+		return &DropboxStatus{Connected: false}
 	}
 
 	var userInfo struct {
@@ -584,68 +560,97 @@ func (c *SettingsController) getDropboxStatusWithValidation() *DropboxStatus {
 			DisplayName string `json:"display_name"`
 		} `json:"name"`
 	}
-
-	if err := json.Unmarshal(body, &userInfo); err != nil {
+	err = withDropboxToken(ctx, source, func(accessToken string) error {
+		return fetchDropboxAccount(ctx, accessToken, &userInfo)
+	})
+	if err != nil {
+		// Only a token that cannot be used or refreshed needs reconnecting
 		return &DropboxStatus{
 			Connected: true,
-			AccountID: token.AccountID,
-			IsExpired: false,
+			AccountID: source.AccountID(),
+			IsExpired: errors.Is(err, errDropboxReconnect),
 		}
 	}
 
 	// Update last used timestamp
-	_ = store.UpdateLastUsed(entities.OAuthProviderDropbox, token.AccountID)
+	_ = store.UpdateLastUsed(entities.OAuthProviderDropbox, source.AccountID())
 
 	return &DropboxStatus{
 		Connected:   true,
 		AccountID:   userInfo.AccountID,
 		Email:       userInfo.Email,
 		DisplayName: userInfo.Name.DisplayName,
-		ExpiresAt:   token.ExpiresAt,
+		ExpiresAt:   source.ExpiresAt(),
 		IsExpired:   false,
 	}
 }
 
-func (c *SettingsController) cleanupOldPKCE() {
-	c.pkceStoreMu.Lock()
-	defer c.pkceStoreMu.Unlock()
+// fetchDropboxAccount loads the Dropbox account of an access token into account.
+func fetchDropboxAccount(ctx context.Context, accessToken string, account any) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", dropboxUserURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
 
-	cutoff := time.Now().Add(-10 * time.Minute)
-	for state, data := range c.pkceStore {
-		if data.createdAt.Before(cutoff) {
-			delete(c.pkceStore, state)
-		}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
 	}
-}
+	defer resp.Body.Close()
 
-func generateCodeVerifier() (string, error) {
-	bytes := make([]byte, 32)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
+	if resp.StatusCode == http.StatusUnauthorized {
+		return moonreader.ErrDropboxUnauthorized
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("dropbox API error (status %d)", resp.StatusCode)
 	}
-	return base64.RawURLEncoding.EncodeToString(bytes), nil
+	return json.NewDecoder(resp.Body).Decode(account)
 }
 
-func generateCodeChallenge(verifier string) string {
-	hash := sha256.Sum256([]byte(verifier))
-	return base64.RawURLEncoding.EncodeToString(hash[:])
+// dropboxTokenSource returns the source of the connected Dropbox account's
+// access token. The stored token is refreshed when it expires, which needs
+// the app key; without one it is used as is.
+func (c *SettingsController) dropboxTokenSource(store *tokenstore.TokenStore) (oauth2.TokenSource, error) {
+	token, err := store.GetTokenByProvider(entities.OAuthProviderDropbox)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, oauth2.ErrTokenNotFound
+	}
+	if c.dropboxProvider == nil {
+		return oauth2.NewStaticTokenSource(token.AccessToken, token.AccountID), nil
+	}
+	return oauth2.NewStoredTokenSource(c.dropboxProvider, store, token.AccountID), nil
 }
 
-func generateState() (string, error) {
-	bytes := make([]byte, 16)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
+// withDropboxToken calls fn with a valid Dropbox access token. Dropbox may
+// revoke a token before its recorded expiry, so a rejected token is refreshed
+// and fn retried once. Errors wrap errDropboxReconnect when no working token
+// can be had.
+func withDropboxToken(ctx context.Context, source oauth2.TokenSource, fn func(accessToken string) error) error {
+	accessToken, err := source.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errDropboxReconnect, err)
+	}
+	err = fn(accessToken)
+	if !errors.Is(err, moonreader.ErrDropboxUnauthorized) {
+		return err
 	}
-	return base64.RawURLEncoding.EncodeToString(bytes), nil
-}
 
-// getEffectiveHost returns the host that the client sees, considering reverse proxy headers.
-func getEffectiveHost(c *gin.Context) string {
-	if forwardedHost := c.GetHeader("X-Forwarded-Host"); forwardedHost != "" {
-		if idx := strings.Index(forwardedHost, ","); idx != -1 {
-			forwardedHost = strings.TrimSpace(forwardedHost[:idx])
+	if err := source.ForceRefresh(ctx); err != nil {
+		return fmt.Errorf("%w: %v", errDropboxReconnect, err)
+	}
+	if accessToken, err = source.Token(ctx); err != nil {
+		return fmt.Errorf("%w: %v", errDropboxReconnect, err)
+	}
+	if err := fn(accessToken); err != nil {
+		if errors.Is(err, moonreader.ErrDropboxUnauthorized) {
+			return fmt.Errorf("%w: %v", errDropboxReconnect, err)
 		}
-		return forwardedHost
+		return err
 	}
-	return c.Request.Host
+	return nil
 }
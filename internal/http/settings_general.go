@@ -0,0 +1,155 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+)
+
+// Rescheduler is implemented by schedulers that re-read their settings on demand
+type Rescheduler interface {
+	Reschedule() error
+}
+
+// GeneralSettingsController serves the unified settings API for runtime-tunable
+// options. Every setting falls back to its environment variable, then its default.
+type GeneralSettingsController struct {
+	store        *settingsstore.SettingsStore
+	reschedulers map[string][]Rescheduler
+}
+
+// NewGeneralSettingsController creates a new controller
+func NewGeneralSettingsController(store *settingsstore.SettingsStore) *GeneralSettingsController {
+	return &GeneralSettingsController{
+		store:        store,
+		reschedulers: make(map[string][]Rescheduler),
+	}
+}
+
+// WithRescheduler reschedules r whenever one of keys changes
+func (c *GeneralSettingsController) WithRescheduler(r Rescheduler, keys ...string) *GeneralSettingsController {
+	for _, key := range keys {
+		c.reschedulers[key] = append(c.reschedulers[key], r)
+	}
+	return c
+}
+
+// SettingsGroup is a titled group of settings on the settings page
+type SettingsGroup struct {
+	Name     string
+	Settings []settingsstore.SettingValue
+}
+
+// UpdateSettingRequest is the request body for PUT /api/settings/:key
+type UpdateSettingRequest struct {
+	Value *string `form:"value" json:"value"`
+}
+
+// ListSettings returns all settings, or the settings fragment for HTMX requests
+// GET /api/settings
+func (c *GeneralSettingsController) ListSettings(ctx *gin.Context) {
+	if isHTMXRequest(ctx) {
+		c.renderSettings(ctx, "", "")
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"settings": c.store.ListSettings()})
+}
+
+// GetSetting returns one setting with its effective value and source
+// GET /api/settings/:key
+func (c *GeneralSettingsController) GetSetting(ctx *gin.Context) {
+	value, err := c.store.GetSettingValue(ctx.Param("key"))
+	if err != nil {
+		respondNotFound(ctx, "setting")
+		return
+	}
+	ctx.JSON(http.StatusOK, value)
+}
+
+// UpdateSetting saves a database override for a setting
+// PUT /api/settings/:key
+func (c *GeneralSettingsController) UpdateSetting(ctx *gin.Context) {
+	key := ctx.Param("key")
+
+	var req UpdateSettingRequest
+	if err := ctx.ShouldBind(&req); err != nil || req.Value == nil {
+		c.respondError(ctx, http.StatusBadRequest, "value is required")
+		return
+	}
+
+	if err := c.store.UpdateSetting(key, *req.Value); err != nil {
+		c.respondStoreError(ctx, err, "update setting")
+		return
+	}
+	c.respondChanged(ctx, key, "Setting saved")
+}
+
+// ResetSetting removes the database override, reverting to env/default
+// DELETE /api/settings/:key
+func (c *GeneralSettingsController) ResetSetting(ctx *gin.Context) {
+	key := ctx.Param("key")
+	if err := c.store.ResetSetting(key); err != nil {
+		c.respondStoreError(ctx, err, "reset setting")
+		return
+	}
+	c.respondChanged(ctx, key, "Setting reset")
+}
+
+func (c *GeneralSettingsController) respondChanged(ctx *gin.Context, key, message string) {
+	for _, r := range c.reschedulers[key] {
+		if err := r.Reschedule(); err != nil {
+			log.Printf("Failed to reschedule after %s changed: %v", key, err)
+			c.respondError(ctx, http.StatusInternalServerError, "Setting saved but failed to reschedule: "+err.Error())
+			return
+		}
+	}
+
+	if isHTMXRequest(ctx) {
+		c.renderSettings(ctx, message, "")
+		return
+	}
+	value, _ := c.store.GetSettingValue(key)
+	ctx.JSON(http.StatusOK, value)
+}
+
+func (c *GeneralSettingsController) respondStoreError(ctx *gin.Context, err error, action string) {
+	switch {
+	case errors.Is(err, settingsstore.ErrUnknownSetting):
+		c.respondError(ctx, http.StatusNotFound, "setting not found")
+	case errors.Is(err, settingsstore.ErrInvalidSettingValue):
+		c.respondError(ctx, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Internal error (%s): %v", action, err)
+		c.respondError(ctx, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+func (c *GeneralSettingsController) respondError(ctx *gin.Context, status int, message string) {
+	if isHTMXRequest(ctx) {
+		// htmx only swaps 2xx responses; the fragment shows the error itself
+		c.renderSettings(ctx, "", message)
+		return
+	}
+	respondError(ctx, status, message)
+}
+
+// renderSettings renders the settings fragment grouped in definition order
+func (c *GeneralSettingsController) renderSettings(ctx *gin.Context, message, errMsg string) {
+	var groups []SettingsGroup
+	for _, value := range c.store.ListSettings() {
+		if len(groups) == 0 || groups[len(groups)-1].Name != value.Group {
+			groups = append(groups, SettingsGroup{Name: value.Group})
+		}
+		last := &groups[len(groups)-1]
+		last.Settings = append(last.Settings, value)
+	}
+
+	ctx.HTML(http.StatusOK, "general-settings", gin.H{
+		"Groups":  groups,
+		"Message": message,
+		"Error":   errMsg,
+	})
+}
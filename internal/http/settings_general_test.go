@@ -0,0 +1,174 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRescheduler struct {
+	calls int
+	err   error
+}
+
+func (m *mockRescheduler) Reschedule() error {
+	m.calls++
+	return m.err
+}
+
+func setupGeneralSettingsRouter(t *testing.T, rescheduler *mockRescheduler) (*gin.Engine, func()) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dbPath := "./test_general_settings_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+
+	controller := NewGeneralSettingsController(settingsstore.New(db)).
+		WithRescheduler(rescheduler, entities.SettingKeyObsidianSyncSchedule)
+
+	router := gin.New()
+	router.GET("/api/settings", controller.ListSettings)
+	router.GET("/api/settings/profile", controller.ExportProfile)
+	router.POST("/api/settings/profile", controller.ImportProfile)
+	router.GET("/api/settings/:key", controller.GetSetting)
+	router.PUT("/api/settings/:key", controller.UpdateSetting)
+	router.DELETE("/api/settings/:key", controller.ResetSetting)
+
+	cleanup := func() {
+		db.Close()
+		os.Remove(dbPath)
+	}
+	return router, cleanup
+}
+
+func TestGeneralSettingsController_ListSettings(t *testing.T) {
+	router, cleanup := setupGeneralSettingsRouter(t, &mockRescheduler{})
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/settings", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Settings []settingsstore.SettingValue `json:"settings"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Settings, len(settingsstore.SettingDefinitions))
+}
+
+func TestGeneralSettingsController_UpdateAndReset(t *testing.T) {
+	rescheduler := &mockRescheduler{}
+	router, cleanup := setupGeneralSettingsRouter(t, rescheduler)
+	defer cleanup()
+
+	path := "/api/settings/" + entities.SettingKeyObsidianSyncSchedule
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(`{"value": "*/15 * * * *"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var value settingsstore.SettingValue
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &value))
+	assert.Equal(t, "*/15 * * * *", value.Value)
+	assert.Equal(t, "database", value.Source)
+	assert.Equal(t, 1, rescheduler.calls)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, path, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &value))
+	assert.Equal(t, "default", value.Source)
+	assert.Equal(t, 2, rescheduler.calls)
+}
+
+func TestGeneralSettingsController_UpdateErrors(t *testing.T) {
+	rescheduler := &mockRescheduler{}
+	router, cleanup := setupGeneralSettingsRouter(t, rescheduler)
+	defer cleanup()
+
+	tests := []struct {
+		name   string
+		key    string
+		body   string
+		status int
+	}{
+		{"missing value", entities.SettingKeyMetadataAutoEnrich, `{}`, http.StatusBadRequest},
+		{"invalid bool", entities.SettingKeyMetadataAutoEnrich, `{"value": "maybe"}`, http.StatusBadRequest},
+		{"invalid cron", entities.SettingKeyObsidianSyncSchedule, `{"value": "hourly"}`, http.StatusBadRequest},
+		{"unknown key", "readwise_sync_token", `{"value": "secret"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/api/settings/"+tt.key, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+	assert.Zero(t, rescheduler.calls)
+}
+
+func TestGeneralSettingsController_RescheduleFailure(t *testing.T) {
+	router, cleanup := setupGeneralSettingsRouter(t, &mockRescheduler{err: errors.New("bad schedule")})
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/settings/"+entities.SettingKeyObsidianSyncSchedule, strings.NewReader(`{"value": "0 0 * * *"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGeneralSettingsController_Profile(t *testing.T) {
+	rescheduler := &mockRescheduler{}
+	router, cleanup := setupGeneralSettingsRouter(t, rescheduler)
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/settings/profile", strings.NewReader(
+		"version: 1\nsettings:\n  obsidian_sync_schedule: \"*/30 * * * *\"\n  readwise_sync_token: secret\ntags: [reading]\n"))
+	req.Header.Set("Content-Type", "application/yaml")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result settingsstore.ProfileImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 1, result.Settings)
+	assert.Equal(t, 1, result.Tags)
+	assert.Len(t, result.Skipped, 1, "secrets are never imported")
+	assert.Equal(t, 1, rescheduler.calls)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/settings/profile?format=json&include=tags", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+
+	var profile settingsstore.Profile
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &profile))
+	assert.Equal(t, map[string]string{entities.SettingKeyObsidianSyncSchedule: "*/30 * * * *"}, profile.Settings)
+	assert.Equal(t, []string{"reading"}, profile.Tags)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/settings/profile?include=books", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/settings/profile", strings.NewReader("not json")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+)
+
+// ExportProfile downloads the settings saved in the database as a profile,
+// without secrets. include lists extras: tags, sources and tombstones.
+// GET /api/settings/profile?format=yaml&include=tags,sources,tombstones
+func (c *GeneralSettingsController) ExportProfile(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", settingsstore.ProfileFormatYAML)
+	if !slices.Contains(settingsstore.ProfileFormats, format) {
+		respondBadRequest(ctx, fmt.Sprintf("format must be one of %s", strings.Join(settingsstore.ProfileFormats, ", ")))
+		return
+	}
+	opts, err := parseProfileOptions(ctx.Query("include"))
+	if err != nil {
+		respondBadRequest(ctx, err.Error())
+		return
+	}
+
+	profile, err := c.store.ExportProfile(GetUserID(ctx), opts)
+	if err != nil {
+		respondInternalError(ctx, err, "export settings profile")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := settingsstore.EncodeProfile(&buf, profile, format); err != nil {
+		respondInternalError(ctx, err, "encode settings profile")
+		return
+	}
+
+	contentType := "application/json"
+	if format == settingsstore.ProfileFormatYAML {
+		contentType = "application/yaml"
+	}
+	filename := fmt.Sprintf("settings-profile-%s.%s", time.Now().Format("2006-01-02"), format)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// ImportProfile applies a profile sent as the request body, in the format
+// given by ?format= or else by the Content-Type (YAML or JSON).
+// POST /api/settings/profile
+func (c *GeneralSettingsController) ImportProfile(ctx *gin.Context) {
+	format := ctx.Query("format")
+	if format == "" {
+		format = settingsstore.ProfileFormatJSON
+		if strings.Contains(ctx.ContentType(), "yaml") {
+			format = settingsstore.ProfileFormatYAML
+		}
+	}
+
+	profile, err := settingsstore.DecodeProfile(ctx.Request.Body, format)
+	if err != nil {
+		respondBadRequest(ctx, err.Error())
+		return
+	}
+
+	result, err := c.store.ImportProfile(GetUserID(ctx), profile)
+	if err != nil {
+		respondInternalError(ctx, err, "import settings profile")
+		return
+	}
+
+	// Schedules may have changed; each scheduler re-reads its settings once
+	var rescheduled []Rescheduler
+	for key := range profile.Settings {
+		for _, r := range c.reschedulers[key] {
+			if slices.Contains(rescheduled, r) {
+				continue
+			}
+			rescheduled = append(rescheduled, r)
+			if err := r.Reschedule(); err != nil {
+				log.Printf("Failed to reschedule after importing a settings profile: %v", err)
+			}
+		}
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// parseProfileOptions reads the comma-separated extras of a profile export
+func parseProfileOptions(include string) (settingsstore.ProfileOptions, error) {
+	var opts settingsstore.ProfileOptions
+	for _, part := range strings.Split(include, ",") {
+		switch strings.TrimSpace(part) {
+		case "":
+		case "tags":
+			opts.Tags = true
+		case "sources":
+			opts.Sources = true
+		case "tombstones":
+			opts.Tombstones = true
+		default:
+			return opts, errors.New("include must list tags, sources or tombstones")
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/scheduler"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+	"github.com/mrlokans/assistant/internal/telegram"
+)
+
+// TelegramController handles Telegram bot settings and the daily review
+type TelegramController struct {
+	settingsStore *settingsstore.SettingsStore
+	scheduler     *scheduler.TelegramReviewScheduler
+	client        *telegram.Client
+}
+
+// NewTelegramController creates a new controller
+func NewTelegramController(store *settingsstore.SettingsStore, sched *scheduler.TelegramReviewScheduler, client *telegram.Client) *TelegramController {
+	return &TelegramController{
+		settingsStore: store,
+		scheduler:     sched,
+		client:        client,
+	}
+}
+
+// TelegramSettingsResponse is the response for GET /settings/telegram
+type TelegramSettingsResponse struct {
+	Config    settingsstore.TelegramConfigInfo `json:"config"`
+	NextRun   *time.Time                       `json:"next_run,omitempty"`
+	IsRunning bool                             `json:"is_running"`
+	Presets   []SchedulePreset                 `json:"presets"`
+}
+
+// GetSettings returns current Telegram bot settings
+func (c *TelegramController) GetSettings(ctx *gin.Context) {
+	response := TelegramSettingsResponse{
+		Config: c.settingsStore.GetTelegramConfigInfo(),
+		Presets: []SchedulePreset{
+			{Label: "Daily at 7am", Value: "0 7 * * *", Description: "Sends the review every morning at 07:00"},
+			{Label: "Daily at 9am", Value: "0 9 * * *", Description: "Sends the review every morning at 09:00"},
+			{Label: "Daily at noon", Value: "0 12 * * *", Description: "Sends the review every day at 12:00"},
+			{Label: "Daily at 9pm", Value: "0 21 * * *", Description: "Sends the review every evening at 21:00"},
+			{Label: "Weekdays at 9am", Value: "0 9 * * 1-5", Description: "Sends the review Monday to Friday at 09:00"},
+			{Label: "Weekly on Sunday", Value: "0 9 * * 0", Description: "Sends the review every Sunday at 09:00"},
+		},
+	}
+	if c.scheduler != nil {
+		response.NextRun = c.scheduler.GetNextRunTime()
+		response.IsRunning = c.scheduler.IsRunning()
+	}
+
+	if strings.Contains(ctx.GetHeader("Accept"), "application/json") {
+		ctx.JSON(http.StatusOK, response)
+	} else {
+		ctx.HTML(http.StatusOK, "telegram-settings", response)
+	}
+}
+
+// UpdateTelegramSettingsRequest is the request body for POST /settings/telegram/save
+type UpdateTelegramSettingsRequest struct {
+	Enabled        *bool  `form:"enabled" json:"enabled"`
+	Token          string `form:"token" json:"token"`
+	ChatID         string `form:"chat_id" json:"chat_id"`
+	ReviewSchedule string `form:"review_schedule" json:"review_schedule"`
+}
+
+// UpdateSettings saves Telegram bot settings. The polling worker picks up the
+// new settings on its next poll; the review schedule is applied immediately.
+func (c *TelegramController) UpdateSettings(ctx *gin.Context) {
+	var req UpdateTelegramSettingsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		c.renderResult(ctx, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if req.Token != "" {
+		if err := c.settingsStore.SetTelegramToken(strings.TrimSpace(req.Token)); err != nil {
+			c.renderResult(ctx, http.StatusInternalServerError, "Failed to save token: "+err.Error())
+			return
+		}
+	}
+
+	if chatID := strings.TrimSpace(req.ChatID); chatID != "" {
+		id, err := strconv.ParseInt(chatID, 10, 64)
+		if err != nil || id == 0 {
+			c.renderResult(ctx, http.StatusBadRequest, "Chat ID must be a number")
+			return
+		}
+		if err := c.settingsStore.SetTelegramChatID(id); err != nil {
+			c.renderResult(ctx, http.StatusInternalServerError, "Failed to save chat ID: "+err.Error())
+			return
+		}
+	}
+
+	if req.ReviewSchedule != "" {
+		if err := settingsstore.ValidateCronSchedule(req.ReviewSchedule); err != nil {
+			c.renderResult(ctx, http.StatusBadRequest, "Invalid cron schedule: "+err.Error())
+			return
+		}
+		if err := c.settingsStore.SetTelegramReviewSchedule(req.ReviewSchedule); err != nil {
+			c.renderResult(ctx, http.StatusInternalServerError, "Failed to save schedule: "+err.Error())
+			return
+		}
+	}
+
+	if req.Enabled != nil {
+		if err := c.settingsStore.SetTelegramEnabled(*req.Enabled); err != nil {
+			c.renderResult(ctx, http.StatusInternalServerError, "Failed to save enabled state: "+err.Error())
+			return
+		}
+	}
+
+	if c.scheduler != nil {
+		if err := c.scheduler.Reschedule(); err != nil {
+			c.renderResult(ctx, http.StatusInternalServerError, "Settings saved but failed to reschedule: "+err.Error())
+			return
+		}
+	}
+
+	c.renderResult(ctx, http.StatusOK, "")
+}
+
+// ResetSettings clears database overrides, reverting to env/defaults
+func (c *TelegramController) ResetSettings(ctx *gin.Context) {
+	if err := c.settingsStore.ClearTelegramSettings(); err != nil {
+		c.renderResult(ctx, http.StatusInternalServerError, "Failed to reset settings: "+err.Error())
+		return
+	}
+
+	if c.scheduler != nil {
+		_ = c.scheduler.Reschedule()
+	}
+
+	c.renderResult(ctx, http.StatusOK, "")
+}
+
+// ValidateToken checks the bot token with Telegram
+func (c *TelegramController) ValidateToken(ctx *gin.Context) {
+	token := strings.TrimSpace(ctx.PostForm("token"))
+	if token == "" {
+		token = c.settingsStore.GetTelegramToken()
+	}
+	if token == "" {
+		ctx.HTML(http.StatusOK, "telegram-token-validation", gin.H{"valid": false, "error": "No token provided or configured"})
+		return
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	bot, err := c.client.GetMe(reqCtx, token)
+	if errors.Is(err, telegram.ErrInvalidToken) {
+		ctx.HTML(http.StatusOK, "telegram-token-validation", gin.H{"valid": false, "error": "Invalid bot token"})
+		return
+	}
+	if err != nil {
+		ctx.HTML(http.StatusOK, "telegram-token-validation", gin.H{"valid": false, "error": err.Error()})
+		return
+	}
+
+	ctx.HTML(http.StatusOK, "telegram-token-validation", gin.H{"valid": true, "message": "Connected as @" + bot.Username})
+}
+
+// SendReview sends the daily review now, to check the chat setup
+func (c *TelegramController) SendReview(ctx *gin.Context) {
+	if c.scheduler == nil {
+		c.renderResult(ctx, http.StatusInternalServerError, "Scheduler not available")
+		return
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := c.scheduler.RunNow(reqCtx); err != nil {
+		c.renderResult(ctx, http.StatusBadGateway, "Failed to send review: "+err.Error())
+		return
+	}
+
+	ctx.HTML(http.StatusOK, "telegram-result", gin.H{
+		"Success": true,
+		"Message": "Review sent",
+	})
+}
+
+// renderResult renders the settings result fragment; an empty errMsg means success
+func (c *TelegramController) renderResult(ctx *gin.Context, status int, errMsg string) {
+	ctx.HTML(status, "telegram-result", gin.H{
+		"Success": errMsg == "",
+		"Error":   errMsg,
+	})
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/moonreader"
+	"github.com/mrlokans/assistant/internal/oauth2"
+	"github.com/mrlokans/assistant/internal/tokenstore"
+)
+
+// fakeTokenSource hands out "token-<n>", where n counts refreshes.
+type fakeTokenSource struct {
+	refreshes  int
+	refreshErr error
+}
+
+func (s *fakeTokenSource) Token(ctx context.Context) (string, error) {
+	return []string{"token-0", "token-1", "token-2"}[s.refreshes], nil
+}
+
+func (s *fakeTokenSource) ForceRefresh(ctx context.Context) error {
+	if s.refreshErr != nil {
+		return s.refreshErr
+	}
+	s.refreshes++
+	return nil
+}
+
+func (s *fakeTokenSource) IsValid() bool         { return true }
+func (s *fakeTokenSource) ExpiresAt() *time.Time { return nil }
+func (s *fakeTokenSource) AccountID() string     { return "dbid:1" }
+
+func TestWithDropboxToken(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid token is used as is", func(t *testing.T) {
+		source := &fakeTokenSource{}
+		var used []string
+		err := withDropboxToken(ctx, source, func(accessToken string) error {
+			used = append(used, accessToken)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"token-0"}, used)
+	})
+
+	t.Run("rejected token is refreshed and the call retried", func(t *testing.T) {
+		source := &fakeTokenSource{}
+		var used []string
+		err := withDropboxToken(ctx, source, func(accessToken string) error {
+			used = append(used, accessToken)
+			if accessToken == "token-0" {
+				return moonreader.ErrDropboxUnauthorized
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"token-0", "token-1"}, used)
+	})
+
+	t.Run("failed refresh asks to reconnect", func(t *testing.T) {
+		source := &fakeTokenSource{refreshErr: errors.New("invalid_grant")}
+		err := withDropboxToken(ctx, source, func(accessToken string) error {
+			return moonreader.ErrDropboxUnauthorized
+		})
+		assert.ErrorIs(t, err, errDropboxReconnect)
+	})
+
+	t.Run("token rejected after refresh asks to reconnect", func(t *testing.T) {
+		source := &fakeTokenSource{}
+		err := withDropboxToken(ctx, source, func(accessToken string) error {
+			return moonreader.ErrDropboxUnauthorized
+		})
+		assert.ErrorIs(t, err, errDropboxReconnect)
+		assert.Equal(t, 1, source.refreshes, "refreshed only once")
+	})
+
+	t.Run("other errors are returned", func(t *testing.T) {
+		source := &fakeTokenSource{}
+		notFound := errors.New("no backup files found")
+		err := withDropboxToken(ctx, source, func(accessToken string) error {
+			return notFound
+		})
+		assert.ErrorIs(t, err, notFound)
+		assert.NotErrorIs(t, err, errDropboxReconnect)
+	})
+}
+
+// fakeOAuthProvider accepts the code "good" when it comes with a verifier.
+type fakeOAuthProvider struct{}
+
+func (p *fakeOAuthProvider) Name() entities.OAuthProvider { return entities.OAuthProviderGoogle }
+
+func (p *fakeOAuthProvider) Config() oauth2.ProviderConfig {
+	return oauth2.ProviderConfig{ClientID: "client", AuthURL: "https://auth.example.com/authorize"}
+}
+
+func (p *fakeOAuthProvider) BuildAuthURL(redirectURL string) (string, string, string, error) {
+	return oauth2.BuildAuthCodeURL(p.Config(), redirectURL, nil)
+}
+
+func (p *fakeOAuthProvider) ExchangeCode(ctx context.Context, code, codeVerifier, redirectURL string) (*oauth2.TokenResponse, error) {
+	if code != "good" || codeVerifier == "" {
+		return nil, errors.New("invalid_grant")
+	}
+	return &oauth2.TokenResponse{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 3600, AccountID: "acc-1"}, nil
+}
+
+func (p *fakeOAuthProvider) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.TokenResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *fakeOAuthProvider) GetAccountInfo(ctx context.Context, accessToken string) (string, error) {
+	return "acc-1", nil
+}
+
+func TestSettingsOAuthFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv(tokenstore.EnvEncryptionKey, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	dbPath := filepath.Join(t.TempDir(), "tokens.db")
+
+	registry := oauth2.NewRegistry()
+	registry.Register(&fakeOAuthProvider{})
+	controller := &SettingsController{DatabasePath: dbPath, oauthFlow: oauth2.NewWebFlow(registry)}
+
+	renderer, err := newLocalizedHTML("../../templates/*.html", templateFuncs(NewStaticAssets("../../static"), ""))
+	require.NoError(t, err)
+	router := gin.New()
+	router.HTMLRender = renderer
+	for _, name := range []entities.OAuthProvider{entities.OAuthProviderGoogle, entities.OAuthProviderDropbox} {
+		router.POST("/settings/oauth/"+string(name)+"/init", controller.InitOAuth(name))
+		router.GET("/settings/oauth/"+string(name)+"/callback", controller.OAuthCallback(name))
+	}
+
+	request := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, nil)
+		req.Host = "example.com"
+		router.ServeHTTP(w, req)
+		return w
+	}
+	start := func() url.Values {
+		w := request(http.MethodPost, "/settings/oauth/google/init")
+		require.Equal(t, http.StatusFound, w.Code)
+		location, err := url.Parse(w.Header().Get("Location"))
+		require.NoError(t, err)
+		return location.Query()
+	}
+
+	t.Run("unconfigured provider", func(t *testing.T) {
+		w := request(http.MethodPost, "/settings/oauth/dropbox/init")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "DROPBOX_APP_KEY")
+	})
+
+	t.Run("authorization redirects back to the callback", func(t *testing.T) {
+		params := start()
+		assert.Equal(t, "http://example.com/settings/oauth/google/callback", params.Get("redirect_uri"))
+		assert.Equal(t, "S256", params.Get("code_challenge_method"))
+		assert.NotEmpty(t, params.Get("state"))
+	})
+
+	t.Run("callback saves the token once", func(t *testing.T) {
+		state := start().Get("state")
+		callback := "/settings/oauth/google/callback?code=good&state=" + url.QueryEscape(state)
+
+		w := request(http.MethodGet, callback)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "acc-1")
+
+		store, err := tokenstore.New(tokenstore.Config{DatabasePath: dbPath})
+		require.NoError(t, err)
+		defer store.Close()
+		token, err := store.GetTokenByProvider(entities.OAuthProviderGoogle)
+		require.NoError(t, err)
+		assert.Equal(t, "access", token.AccessToken)
+		assert.Equal(t, "refresh", token.RefreshToken)
+
+		w = request(http.MethodGet, callback)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid or expired state")
+	})
+
+	t.Run("state of another provider is rejected", func(t *testing.T) {
+		state := start().Get("state")
+		w := request(http.MethodGet, "/settings/oauth/dropbox/callback?code=good&state="+url.QueryEscape(state))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("denied authorization", func(t *testing.T) {
+		w := request(http.MethodGet, "/settings/oauth/google/callback?error=access_denied&error_description=user+declined")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "user declined")
+	})
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StaticAssets fingerprints the files served under /static so pages can link to
+// versioned URLs (/static/style.css?v=1a2b3c4d5e6f) that browsers cache for good:
+// a changed file gets a new URL. Fingerprints are computed once, at startup.
+type StaticAssets struct {
+	hashes   map[string]string // slash-separated path below the static root -> content hash
+	basePath string            // URL prefix the app is served under
+}
+
+// NewStaticAssets hashes every file below root. Files that cannot be read are
+// served without a fingerprint.
+func NewStaticAssets(root string) *StaticAssets {
+	assets := &StaticAssets{hashes: make(map[string]string)}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		hash, err := hashFile(path)
+		if err != nil {
+			log.Printf("Failed to fingerprint static asset %s: %v", path, err)
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		assets.hashes[filepath.ToSlash(rel)] = hash
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to fingerprint static assets in %s: %v", root, err)
+	}
+	return assets
+}
+
+// WithBasePath makes asset URLs start with the URL prefix the app is served under.
+func (a *StaticAssets) WithBasePath(basePath string) *StaticAssets {
+	a.basePath = basePath
+	return a
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12], nil
+}
+
+// URL returns the versioned URL of a static file, e.g. asset "style.css" in a template.
+func (a *StaticAssets) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if hash, ok := a.hashes[name]; ok {
+		return a.basePath + "/static/" + name + "?v=" + hash
+	}
+	return a.basePath + "/static/" + name
+}
+
+// CacheMiddleware marks requests for the current version of a static file as
+// immutable. Unversioned and outdated URLs are revalidated on every use instead.
+// It expects the static file path in the "filepath" route parameter.
+func (a *StaticAssets) CacheMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.TrimPrefix(c.Param("filepath"), "/")
+		if v := c.Query("v"); v != "" && v == a.hashes[name] {
+			c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			c.Header("Cache-Control", "no-cache")
+		}
+		c.Next()
+	}
+}
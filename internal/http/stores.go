@@ -39,6 +39,17 @@ type Store interface {
 	GetFavouriteHighlights(userID uint, limit, offset int) ([]entities.Highlight, int64, error)
 	GetFavouriteHighlightsByBook(bookID uint) ([]entities.Highlight, error)
 	GetFavouriteCount(userID uint) (int64, error)
+	SetBookFavourite(bookID uint, isFavourite bool) error
+	GetFavouriteBooks(userID uint) ([]entities.Book, error)
+	GetHighlightHistory(highlightID uint) ([]entities.HighlightVersion, error)
+	RevertHighlight(highlightID, versionID uint) (*entities.Highlight, error)
+	GetHighlightConflicts() ([]entities.HighlightConflict, error)
+	GetTrashedBooks() ([]entities.TrashedBook, error)
+	GetTrashedHighlights() ([]entities.TrashedHighlight, error)
+	RestoreBook(id uint) (*entities.Book, error)
+	RestoreHighlight(id uint) (*entities.Highlight, error)
+	EmptyTrash() (booksPurged, highlightsPurged int64, err error)
+	GetMigrations() ([]entities.SchemaMigration, error)
 
 	// Tags
 	CreateTag(name string, userID uint) (*entities.Tag, error)
@@ -80,18 +91,160 @@ type Store interface {
 //   - Book/highlight tag associations
 //   - Tag search and suggestions
 //
+// TagCSVStore (tags_csv.go):
+//   - All tag assignments of books and highlights
+//   - Applying assignments in bulk, optionally replacing the existing ones
+//
 // DeleteStore (delete.go):
 //   - Soft and permanent delete for books/highlights
 //   - Entity retrieval for pre-delete checks
 //
+// MetadataReviewStore (metadata_reviews.go):
+//   - Uncertain metadata matches held for review
+//   - Accepting a match into its book or rejecting it
+//
+// TrashStore (trash.go):
+//   - Soft-deleted books and highlights
+//   - Restore and empty trash
+//
+// LibraryImportStore (import_library.go):
+//   - Book lookup and stub creation for Goodreads/StoryGraph exports
+//   - Rating/read date updates and shelf tags
+//
+// CaptureStore (capture.go):
+//   - Manual highlight creation with tags
+//   - Highlight lookup by external ID for idempotent retries
+//   - Book list for the quick-capture page
+//
+// AuthorStore (authors.go):
+//   - Authors with book counts
+//   - Author with aliases and their books with highlights
+//   - Author merging and alias management
+//
+// SeriesStore (series.go):
+//   - Books grouped by series in reading order
+//
+// CollectionStore (collections.go):
+//   - Collection CRUD with book counts
+//   - Adding, removing and reordering books in a collection
+//
+// BookSourceStore (book_sources.go):
+//   - Highlight counts and import times per source of a book
+//
+// SavedViewStore (saved_views.go):
+//   - Saved view CRUD
+//   - Highlight listing filtered by a view's criteria
+//
+// UIPreferencesStore (ui_preferences.go):
+//   - Per-user theme, page size, sort and view mode
+//
+// QuickSearchStore (quicksearch.go):
+//   - Ranked prefix search of books, highlights, tags and words in the full-text index
+//
+// ManualBookStore (metadata.go):
+//   - ISBN duplicate check and book creation for books added by hand
+//
+// UpgradeStatusStore (upgrade.go):
+//   - Schema changes and data backfill progress
+//
+// MaintenanceStore (maintenance.go):
+//   - Integrity check, vacuum, orphan tag and expired session cleanup runs
+//   - Latest and recent recorded runs
+//
 // FavouritesStore (favourites.go):
-//   - Favourite toggle and retrieval
+//   - Favourite toggle and retrieval for highlights and books
 //   - Paginated favourite lists
 //
 // VocabularyStore (vocabulary.go):
 //   - Word CRUD operations
+//   - Words of a highlight or book
 //   - Definition management
 //   - Enrichment status tracking
 //
+// VocabularyReviewStore (vocabulary_review.go):
+//   - Words due for review
+//   - Review schedule updates
+//
+// VocabularyMergeStore (vocabulary_merge.go):
+//   - Words sharing a lemma
+//   - Additional word contexts
+//   - Merging and consolidating duplicate words
+//
+// BookDetailsStore (book_details.go):
+//   - Book with highlights and tags
+//   - Vocabulary words of a book
+//   - Related books sharing the author or tags
+//
+// BookHighlightStore (book_highlights.go):
+//   - Book without its highlights
+//   - Highlight counts per stored color
+//   - Cursor-paginated highlights of a book in reading order
+//
+// BookListStore (ui.go):
+//   - Books with their highlight counters but without highlights
+//
+// BookArchiveStore (book_archive.go):
+//   - Archiving and unarchiving a book
+//
+// BookNoteStore (book_notes.go):
+//   - Book with its journal
+//   - Creating, listing, updating and deleting journal notes
+//
+// CitationStore (citations.go):
+//   - Book without its highlights
+//   - Books with a tag
+//   - Collection with its books
+//
+// ReenrichStore (reenrich.go):
+//   - All book IDs
+//   - Sync progress start, completion and status for re-enrichment runs
+//
+// SyncLockStore (sync_locks.go):
+//   - Sync progress records with their owning worker and heartbeat
+//   - Releasing a running sync
+//   - Held import and export locks
+//
+// BookEditStore (book_edit.go):
+//   - Book lookup by ID and by title+author (edits must keep them unique)
+//   - Metadata column updates
+//
+// HighlightListStore (highlights.go):
+//   - Highlight listing filtered by date, source, tags, favourite, note, category and book
+//   - Random highlight and highlight of the day
+//   - Highlight counts per classifier category
+//
+// HighlightHistoryStore (highlight_history.go):
+//   - Previous highlight text/note versions
+//   - Revert to a recorded version
+//   - Re-import conflicts with local edits
+//
+// HighlightDuplicateStore (highlight_duplicates.go):
+//   - Near-duplicate highlights of a book across sources
+//   - Merge into the richer record, deleting the other permanently
+//
+// NoteStore (notes.go):
+//   - Highlight lookup and note updates (recorded in edit history)
+//
+// PublicLibraryStore (public_library.go):
+//   - Per-book sharing and the books shown on the public library
+//
+// QuoteCardStore (quote_cards.go):
+//   - Highlight and book lookup for quote card images
+//
+// PodcastStore (podcast.go):
+//   - Book, tag and highlight lookup for podcast feeds
+//   - Highlights by tag, including those of tagged books
+//
+// HighlightLinkStore (highlight_links.go):
+//   - Typed links between highlights, with backlinks
+//   - Highlight search for picking a link target
+//
+// ExportTargetStore (export_targets.go):
+//   - Named export target CRUD with lookup by name
+//
+// GraphQLStore (graphql.go):
+//   - Paginated books, highlights, tags and vocabulary for top-level queries
+//   - Batch loaders for nested fields (books, highlights, counts and words by parent IDs)
+//
 // These interfaces follow the Interface Segregation Principle:
 // each controller only depends on the methods it actually uses.
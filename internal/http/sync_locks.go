@@ -0,0 +1,75 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// SyncLockStore lists syncs with their owners and releases stuck ones, and
+// lists the import and export locks.
+type SyncLockStore interface {
+	GetAllSyncProgress() ([]entities.SyncProgress, error)
+	InterruptSyncProgress(syncType entities.SyncType, reason string) (bool, error)
+	GetLocks() ([]database.LockStatus, error)
+}
+
+// SyncLocksController shows which worker holds each sync and lets an admin
+// release a sync that is stuck, e.g. after a worker hung without exiting.
+type SyncLocksController struct {
+	store SyncLockStore
+}
+
+func NewSyncLocksController(store SyncLockStore) *SyncLocksController {
+	return &SyncLocksController{store: store}
+}
+
+// ListSyncs returns the progress, owner and last heartbeat of every sync.
+// GET /api/admin/syncs
+func (sc *SyncLocksController) ListSyncs(c *gin.Context) {
+	syncs, err := sc.store.GetAllSyncProgress()
+	if err != nil {
+		respondInternalError(c, err, "list syncs")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"syncs": syncs, "count": len(syncs)})
+}
+
+// ReleaseSync fails a running sync regardless of its heartbeat, so a new run
+// can start. The worker holding it is not stopped.
+// POST /api/admin/syncs/:type/release
+func (sc *SyncLocksController) ReleaseSync(c *gin.Context) {
+	syncType := entities.SyncType(c.Param("type"))
+	if !slices.Contains(entities.SyncTypes, syncType) {
+		respondBadRequest(c, fmt.Sprintf("unknown sync type %q", syncType))
+		return
+	}
+
+	released, err := sc.store.InterruptSyncProgress(syncType, "released by an administrator")
+	if err != nil {
+		respondInternalError(c, err, "release sync")
+		return
+	}
+	if !released {
+		respondNotFound(c, "running sync")
+		return
+	}
+	respondSuccess(c, "sync released")
+}
+
+// ListLocks returns the held import and export locks with their holders.
+// A lock marked abandoned is taken over by the next run that needs it.
+// GET /api/admin/locks
+func (sc *SyncLocksController) ListLocks(c *gin.Context) {
+	locks, err := sc.store.GetLocks()
+	if err != nil {
+		respondInternalError(c, err, "list locks")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"locks": locks, "count": len(locks)})
+}
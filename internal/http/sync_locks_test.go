@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestSyncLocksController_ReleaseSync(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dbPath := "./test_sync_locks.db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	controller := NewSyncLocksController(db)
+	router := gin.New()
+	router.GET("/api/admin/syncs", controller.ListSyncs)
+	router.POST("/api/admin/syncs/:type/release", controller.ReleaseSync)
+
+	post := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, post("/api/admin/syncs/nope/release").Code)
+	assert.Equal(t, http.StatusNotFound, post("/api/admin/syncs/metadata/release").Code)
+
+	_, err = db.StartSyncProgress(entities.SyncTypeMetadata, 10)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/syncs", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"running"`)
+	assert.Contains(t, w.Body.String(), `"owner":`)
+
+	w = post("/api/admin/syncs/metadata/release")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	running, err := db.IsSyncRunning(entities.SyncTypeMetadata)
+	require.NoError(t, err)
+	assert.False(t, running)
+	progress, err := db.GetSyncProgress(entities.SyncTypeMetadata)
+	require.NoError(t, err)
+	assert.Equal(t, "released by an administrator", progress.Error)
+}
+
+func TestSyncLocksController_ListLocks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dbPath := "./test_advisory_locks.db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+	defer func() {
+		db.Close()
+		os.Remove(dbPath)
+	}()
+
+	router := gin.New()
+	router.GET("/api/admin/locks", NewSyncLocksController(db).ListLocks)
+
+	lock, err := db.TryAcquireLock(entities.LockImport, "kindle import")
+	require.NoError(t, err)
+	defer lock.Release()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/locks", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"import"`)
+	assert.Contains(t, w.Body.String(), `"operation":"kindle import"`)
+	assert.Contains(t, w.Body.String(), `"abandoned":false`)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+}
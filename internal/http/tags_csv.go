@@ -0,0 +1,243 @@
+package http
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/audit"
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+const maxTagCSVSize = 20 * 1024 * 1024 // 20 MB
+
+// tagCSVHeader lists the columns of a tag CSV. highlight_text is there to make
+// the rows readable in a spreadsheet and is ignored on import.
+var tagCSVHeader = []string{"type", "book_title", "book_author", "highlight_id", "highlight_text", "tag"}
+
+// tagCSVExcerptLength is how much highlight text the export includes
+const tagCSVExcerptLength = 120
+
+// TagCSVStore lists and applies tag assignments in bulk.
+type TagCSVStore interface {
+	GetTagAssignments(userID uint) ([]entities.TagAssignment, error)
+	ApplyTagAssignments(userID uint, assignments []entities.TagAssignment, replace bool) (*entities.TagAssignmentResult, error)
+}
+
+// TagCSVController exports every tag assignment as CSV and applies an edited
+// CSV back, for cleaning up tags in a spreadsheet.
+type TagCSVController struct {
+	store        TagCSVStore
+	auditService *audit.Service
+}
+
+func NewTagCSVController(store TagCSVStore, auditService *audit.Service) *TagCSVController {
+	return &TagCSVController{
+		store:        store,
+		auditService: auditService,
+	}
+}
+
+type TagCSVImportResult struct {
+	Success bool     `json:"success"`
+	Error   string   `json:"error,omitempty"`
+	Replace bool     `json:"replace"`
+	Total   int      `json:"total"`
+	Added   int      `json:"added"`
+	Removed int      `json:"removed"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// Export downloads every tag on books and highlights, one row per tag.
+// GET /api/tags/export
+func (tc *TagCSVController) Export(c *gin.Context) {
+	assignments, err := tc.store.GetTagAssignments(DefaultUserID)
+	if err != nil {
+		respondInternalError(c, err, "get tag assignments")
+		return
+	}
+
+	filename := fmt.Sprintf("tags-%s.csv", time.Now().Format("2006-01-02"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+	if err := writeTagCSV(c.Writer, assignments); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// Import handles the settings page upload.
+// POST /settings/tags/import
+func (tc *TagCSVController) Import(c *gin.Context) {
+	status, result := tc.importTags(c)
+	c.HTML(status, "tags-import-result", result)
+}
+
+// ImportJSON is the JSON API variant of Import.
+// POST /api/tags/import
+func (tc *TagCSVController) ImportJSON(c *gin.Context) {
+	status, result := tc.importTags(c)
+	c.JSON(status, result)
+}
+
+// importTags applies an uploaded tag CSV. Tags are added to what is there, or
+// with replace=true the CSV becomes the complete set of tags.
+func (tc *TagCSVController) importTags(c *gin.Context) (int, *TagCSVImportResult) {
+	replace := false
+	if v := c.PostForm("replace"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return http.StatusBadRequest, &TagCSVImportResult{Error: "replace must be true or false"}
+		}
+		replace = parsed
+	}
+
+	file, header, err := c.Request.FormFile("csv_file")
+	if err != nil {
+		return http.StatusBadRequest, &TagCSVImportResult{Error: "No CSV file provided"}
+	}
+	defer file.Close()
+
+	if header.Size > maxTagCSVSize {
+		return http.StatusRequestEntityTooLarge, &TagCSVImportResult{
+			Error: fmt.Sprintf("File too large (max %d MB)", maxTagCSVSize/(1024*1024)),
+		}
+	}
+
+	assignments, lines, parseErrors, err := parseTagCSV(io.LimitReader(file, maxTagCSVSize+1))
+	if err != nil {
+		return http.StatusBadRequest, &TagCSVImportResult{Error: fmt.Sprintf("Failed to parse CSV: %v", err)}
+	}
+
+	applied, err := tc.store.ApplyTagAssignments(DefaultUserID, assignments, replace)
+	if tc.auditService != nil {
+		desc := fmt.Sprintf("Imported %d tag assignments from CSV", len(assignments))
+		tc.auditService.LogImport(auth.GetUserID(c), "tags_csv", desc, 0, 0, err)
+	}
+	if err != nil {
+		return http.StatusInternalServerError, &TagCSVImportResult{Error: fmt.Sprintf("Failed to import: %v", err)}
+	}
+
+	for _, i := range applied.Unmatched {
+		parseErrors = append(parseErrors, fmt.Sprintf("Line %d: skipped - %s not found", lines[i], assignments[i].Target))
+	}
+	return http.StatusOK, &TagCSVImportResult{
+		Success: true,
+		Replace: replace,
+		Total:   len(assignments),
+		Added:   applied.Added,
+		Removed: applied.Removed,
+		Errors:  parseErrors,
+	}
+}
+
+func writeTagCSV(w io.Writer, assignments []entities.TagAssignment) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(tagCSVHeader); err != nil {
+		return err
+	}
+	for _, assignment := range assignments {
+		highlightID := ""
+		if assignment.HighlightID != 0 {
+			highlightID = strconv.FormatUint(uint64(assignment.HighlightID), 10)
+		}
+		record := []string{
+			string(assignment.Target),
+			assignment.BookTitle,
+			assignment.BookAuthor,
+			highlightID,
+			tagCSVExcerpt(assignment.HighlightText),
+			assignment.Tag,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// parseTagCSV reads a tag CSV, returning the assignments with the line each
+// came from. Rows that cannot be used are reported and skipped.
+func parseTagCSV(r io.Reader) ([]entities.TagAssignment, []int, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	headerIndex := make(map[string]int)
+	for i, h := range header {
+		headerIndex[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, h := range []string{"type", "book_title", "book_author", "highlight_id", "tag"} {
+		if _, ok := headerIndex[h]; !ok {
+			return nil, nil, nil, fmt.Errorf("missing required header: %s", h)
+		}
+	}
+
+	var assignments []entities.TagAssignment
+	var lines []int
+	var errors []string
+	lineNum := 1
+	for {
+		lineNum++
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Line %d: %v", lineNum, err))
+			continue
+		}
+
+		assignment := entities.TagAssignment{
+			Target:     entities.TagTarget(strings.ToLower(getCSVValue(record, headerIndex, "type"))),
+			BookTitle:  getCSVValue(record, headerIndex, "book_title"),
+			BookAuthor: getCSVValue(record, headerIndex, "book_author"),
+			Tag:        getCSVValue(record, headerIndex, "tag"),
+		}
+		if assignment.Tag == "" {
+			errors = append(errors, fmt.Sprintf("Line %d: skipped - missing tag", lineNum))
+			continue
+		}
+		switch assignment.Target {
+		case entities.TagTargetBook:
+			if assignment.BookTitle == "" {
+				errors = append(errors, fmt.Sprintf("Line %d: skipped - missing book title", lineNum))
+				continue
+			}
+		case entities.TagTargetHighlight:
+			id, err := strconv.ParseUint(getCSVValue(record, headerIndex, "highlight_id"), 10, 0)
+			if err != nil || id == 0 {
+				errors = append(errors, fmt.Sprintf("Line %d: skipped - invalid highlight_id", lineNum))
+				continue
+			}
+			assignment.HighlightID = uint(id)
+		default:
+			errors = append(errors, fmt.Sprintf("Line %d: skipped - type must be book or highlight", lineNum))
+			continue
+		}
+
+		assignments = append(assignments, assignment)
+		lines = append(lines, lineNum)
+	}
+	return assignments, lines, errors, nil
+}
+
+// tagCSVExcerpt shortens highlight text to a single line for the export
+func tagCSVExcerpt(text string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= tagCSVExcerptLength {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:tagCSVExcerptLength])) + "…"
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func postTagCSV(t *testing.T, router *gin.Engine, data string, replace bool) *httptest.ResponseRecorder {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("csv_file", "tags.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteField("replace", fmt.Sprint(replace)))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tags/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTagCSVController_ExportImport(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Highlights: []entities.Highlight{{Text: "The obstacle\nis the way"}}}
+	require.NoError(t, db.SaveBook(book))
+	tag, err := db.GetOrCreateTag("Philosophy", DefaultUserID)
+	require.NoError(t, err)
+	require.NoError(t, db.AddTagToBook(book.ID, tag.ID))
+	require.NoError(t, db.AddTagToHighlight(book.Highlights[0].ID, tag.ID))
+
+	controller := NewTagCSVController(db, nil)
+	router := gin.New()
+	router.GET("/api/tags/export", controller.Export)
+	router.POST("/api/tags/import", controller.ImportJSON)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tags/export", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	highlightRow := fmt.Sprintf("highlight,Meditations,Marcus Aurelius,%d,The obstacle is the way,Philosophy", book.Highlights[0].ID)
+	assert.Equal(t, "type,book_title,book_author,highlight_id,highlight_text,tag\n"+
+		"book,Meditations,Marcus Aurelius,,,Philosophy\n"+
+		highlightRow+"\n", w.Body.String())
+
+	// Rename the tag in a spreadsheet and upload the CSV back
+	edited := strings.ReplaceAll(w.Body.String(), "Philosophy", "philosophy/stoicism") + "book,Unknown,Nobody,,,x\nhighlight,,,abc,,x\n"
+	w = postTagCSV(t, router, edited, true)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result TagCSVImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 2, result.Added)
+	assert.Equal(t, 2, result.Removed)
+	assert.Equal(t, []string{"Line 5: skipped - invalid highlight_id", "Line 4: skipped - book not found"}, result.Errors)
+
+	tags, err := db.GetTagsForUser(DefaultUserID)
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	assert.Equal(t, "philosophy/stoicism", tags[0].Name)
+
+	w = postTagCSV(t, router, "tag\nx\n", false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -50,6 +50,26 @@ func (tc *TasksController) ListTaskTypes(c *gin.Context) {
 			Description: "Enrich all books missing metadata",
 			Queue:       "enrich_all_books",
 		},
+		{
+			Type:        "enrich_authors",
+			Description: "Look up authors on Wikidata for photos, life years and bios",
+			Queue:       "enrich_authors",
+		},
+		{
+			Type:        "extract_vocabulary",
+			Description: "Suggest rare words from new highlights as vocabulary candidates",
+			Queue:       "extract_vocabulary",
+		},
+		{
+			Type:        "classify_highlights",
+			Description: "Sort new highlights into categories such as advice, definitions, quotes and data points",
+			Queue:       "classify_highlights",
+		},
+		{
+			Type:        "detect_book_languages",
+			Description: "Detect the language of books from their highlights",
+			Queue:       "detect_book_languages",
+		},
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -89,6 +109,10 @@ type RunTaskRequest struct {
 	BookID uint `json:"book_id,omitempty" form:"book_id"`
 	// UserID is optional for enrich_all_books task
 	UserID uint `json:"user_id,omitempty" form:"user_id"`
+	// Reclassify makes classify_highlights classify every highlight again
+	Reclassify bool `json:"reclassify,omitempty" form:"reclassify"`
+	// Redetect makes detect_book_languages detect the language of every book again
+	Redetect bool `json:"redetect,omitempty" form:"redetect"`
 }
 
 // RunTask handles POST /api/tasks/:type/run
@@ -117,6 +141,18 @@ func (tc *TasksController) RunTask(c *gin.Context) {
 	case "enrich_all_books":
 		task = tasks.EnrichAllBooksTask{UserID: req.UserID}
 
+	case "enrich_authors":
+		task = tasks.EnrichAuthorsTask{}
+
+	case "extract_vocabulary":
+		task = tasks.ExtractVocabularyTask{}
+
+	case "classify_highlights":
+		task = tasks.ClassifyHighlightsTask{Reclassify: req.Reclassify}
+
+	case "detect_book_languages":
+		task = tasks.DetectBookLanguagesTask{Redetect: req.Redetect}
+
 	default:
 		tc.respondTaskError(c, fmt.Sprintf("unknown task type: %s", taskType))
 		return
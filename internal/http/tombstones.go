@@ -0,0 +1,146 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/audit"
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/entities"
+	"gorm.io/gorm"
+)
+
+// TombstoneStore defines database operations for records of permanently deleted
+// entities, which make imports skip matching books and highlights.
+type TombstoneStore interface {
+	ListTombstones(entityType, query string, limit, offset int) ([]entities.DeletedEntity, int64, error)
+	DeleteTombstone(id uint) (*entities.DeletedEntity, error)
+}
+
+type TombstoneController struct {
+	store        TombstoneStore
+	auditService *audit.Service
+}
+
+func NewTombstoneController(store TombstoneStore, auditService *audit.Service) *TombstoneController {
+	return &TombstoneController{store: store, auditService: auditService}
+}
+
+// tombstoneQuery holds the list filters, shared by the list and delete handlers
+// so HTMX re-renders keep the current search.
+type tombstoneQuery struct {
+	EntityType string
+	Query      string
+	Page       int
+	Limit      int
+}
+
+func parseTombstoneQuery(c *gin.Context) tombstoneQuery {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 25
+	}
+
+	entityType := c.Query("type")
+	if entityType != entities.DeletedEntityBook && entityType != entities.DeletedEntityHighlight {
+		entityType = ""
+	}
+
+	return tombstoneQuery{
+		EntityType: entityType,
+		Query:      c.Query("q"),
+		Page:       page,
+		Limit:      limit,
+	}
+}
+
+func (tc *TombstoneController) loadTombstones(q tombstoneQuery) (gin.H, error) {
+	tombstones, total, err := tc.store.ListTombstones(q.EntityType, q.Query, q.Limit, (q.Page-1)*q.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (int(total) + q.Limit - 1) / q.Limit
+	if totalPages < 1 {
+		totalPages = 1
+	}
+	if q.Page > totalPages && total > 0 {
+		// The last entry of the last page was removed; show the new last page
+		q.Page = totalPages
+		return tc.loadTombstones(q)
+	}
+	return gin.H{
+		"Tombstones":  tombstones,
+		"Total":       total,
+		"CurrentPage": q.Page,
+		"TotalPages":  totalPages,
+		"EntityType":  q.EntityType,
+		"Query":       q.Query,
+	}, nil
+}
+
+// ListTombstones returns records of permanently deleted books and highlights.
+// GET /api/tombstones?type=book|highlight&q=...&page=1&limit=25
+func (tc *TombstoneController) ListTombstones(c *gin.Context) {
+	q := parseTombstoneQuery(c)
+	data, err := tc.loadTombstones(q)
+	if err != nil {
+		respondInternalError(c, err, "list tombstones")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "tombstone-list", data)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tombstones":  data["Tombstones"],
+		"page":        data["CurrentPage"],
+		"limit":       q.Limit,
+		"total_pages": data["TotalPages"],
+		"total":       data["Total"],
+	})
+}
+
+// DeleteTombstone removes a deletion record so the book or highlight can be imported again.
+// DELETE /api/tombstones/:id
+func (tc *TombstoneController) DeleteTombstone(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	tombstone, err := tc.store.DeleteTombstone(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondNotFound(c, "tombstone")
+			return
+		}
+		respondInternalError(c, err, "delete tombstone")
+		return
+	}
+
+	if tc.auditService != nil {
+		tc.auditService.LogDelete(auth.GetUserID(c), "tombstone", id, tombstone.Label(), true)
+	}
+
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, gin.H{"message": "tombstone removed", "tombstone": tombstone})
+		return
+	}
+
+	data, err := tc.loadTombstones(parseTombstoneQuery(c))
+	if err != nil {
+		respondInternalError(c, err, "list tombstones")
+		return
+	}
+	data["Message"] = "\"" + tombstone.Label() + "\" can be imported again"
+	c.HTML(http.StatusOK, "tombstone-list", data)
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockTombstoneStore struct {
+	tombstones []entities.DeletedEntity
+	total      int64
+	entityType string
+	query      string
+	limit      int
+	offset     int
+	deletedID  uint
+}
+
+func (m *mockTombstoneStore) ListTombstones(entityType, query string, limit, offset int) ([]entities.DeletedEntity, int64, error) {
+	m.entityType, m.query, m.limit, m.offset = entityType, query, limit, offset
+	return m.tombstones, m.total, nil
+}
+
+func (m *mockTombstoneStore) DeleteTombstone(id uint) (*entities.DeletedEntity, error) {
+	for _, tombstone := range m.tombstones {
+		if tombstone.ID == id {
+			m.deletedID = id
+			return &tombstone, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func setupTombstoneRouter(store *mockTombstoneStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	controller := NewTombstoneController(store, nil)
+	router := gin.New()
+	router.GET("/api/tombstones", controller.ListTombstones)
+	router.DELETE("/api/tombstones/:id", controller.DeleteTombstone)
+	return router
+}
+
+func TestTombstoneController(t *testing.T) {
+	newStore := func() *mockTombstoneStore {
+		return &mockTombstoneStore{tombstones: []entities.DeletedEntity{
+			{ID: 4, EntityType: entities.DeletedEntityBook, EntityKey: "Dune|Frank Herbert"},
+		}, total: 1}
+	}
+
+	t.Run("lists with filters", func(t *testing.T) {
+		store := newStore()
+		store.total = 30
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/tombstones?type=book&q=dune&page=2&limit=10", nil)
+		setupTombstoneRouter(store).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Tombstones []entities.DeletedEntity `json:"tombstones"`
+			Total      int64                    `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Tombstones, 1)
+		assert.Equal(t, int64(30), response.Total)
+		assert.Equal(t, "book", store.entityType)
+		assert.Equal(t, "dune", store.query)
+		assert.Equal(t, 10, store.limit)
+		assert.Equal(t, 10, store.offset)
+	})
+
+	t.Run("ignores unknown type", func(t *testing.T) {
+		store := newStore()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/tombstones?type=tag", nil)
+		setupTombstoneRouter(store).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, store.entityType)
+		assert.Equal(t, 25, store.limit)
+	})
+
+	t.Run("clamps page past the end", func(t *testing.T) {
+		store := newStore()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/tombstones?page=5", nil)
+		setupTombstoneRouter(store).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Zero(t, store.offset)
+	})
+
+	t.Run("removes tombstone", func(t *testing.T) {
+		store := newStore()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/tombstones/4", nil)
+		setupTombstoneRouter(store).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, uint(4), store.deletedID)
+	})
+
+	t.Run("missing tombstone", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/tombstones/99", nil)
+		setupTombstoneRouter(newStore()).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/audit"
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/entities"
+	"gorm.io/gorm"
+)
+
+// TrashStore defines database operations for the trash of soft-deleted entities.
+type TrashStore interface {
+	GetTrashedBooks() ([]entities.TrashedBook, error)
+	GetTrashedHighlights() ([]entities.TrashedHighlight, error)
+	RestoreBook(id uint) (*entities.Book, error)
+	RestoreHighlight(id uint) (*entities.Highlight, error)
+	EmptyTrash() (booksPurged, highlightsPurged int64, err error)
+}
+
+type TrashController struct {
+	store         TrashStore
+	auditService  *audit.Service
+	retentionDays int
+}
+
+func NewTrashController(store TrashStore, auditService *audit.Service, retentionDays int) *TrashController {
+	return &TrashController{store: store, auditService: auditService, retentionDays: retentionDays}
+}
+
+func (tc *TrashController) loadTrash() (gin.H, error) {
+	books, err := tc.store.GetTrashedBooks()
+	if err != nil {
+		return nil, err
+	}
+	highlights, err := tc.store.GetTrashedHighlights()
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"Books":         books,
+		"Highlights":    highlights,
+		"RetentionDays": tc.retentionDays,
+	}, nil
+}
+
+// ListTrash returns soft-deleted books and highlights.
+// GET /api/trash
+func (tc *TrashController) ListTrash(c *gin.Context) {
+	data, err := tc.loadTrash()
+	if err != nil {
+		respondInternalError(c, err, "list trash")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "trash-list", data)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"books":          data["Books"],
+		"highlights":     data["Highlights"],
+		"retention_days": tc.retentionDays,
+	})
+}
+
+// RestoreBook moves a book and the highlights deleted with it back into the library.
+// POST /api/trash/books/:id/restore
+func (tc *TrashController) RestoreBook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	book, err := tc.store.RestoreBook(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondNotFound(c, "book in trash")
+			return
+		}
+		respondInternalError(c, err, "restore book")
+		return
+	}
+
+	if tc.auditService != nil {
+		tc.auditService.LogRestore(auth.GetUserID(c), "book", id, book.Title)
+	}
+
+	tc.respondUpdated(c, "Book restored", gin.H{"message": "book restored", "book": book})
+}
+
+// RestoreHighlight moves a highlight back into the library.
+// POST /api/trash/highlights/:id/restore
+func (tc *TrashController) RestoreHighlight(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	highlight, err := tc.store.RestoreHighlight(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondNotFound(c, "highlight in trash")
+			return
+		}
+		respondInternalError(c, err, "restore highlight")
+		return
+	}
+
+	if tc.auditService != nil {
+		highlightText := highlight.Text
+		if len(highlightText) > 50 {
+			highlightText = highlightText[:50] + "..."
+		}
+		tc.auditService.LogRestore(auth.GetUserID(c), "highlight", id, highlightText)
+	}
+
+	tc.respondUpdated(c, "Highlight restored", gin.H{"message": "highlight restored", "highlight": highlight})
+}
+
+// EmptyTrash permanently deletes everything in the trash.
+// DELETE /api/trash
+func (tc *TrashController) EmptyTrash(c *gin.Context) {
+	books, highlights, err := tc.store.EmptyTrash()
+	if err != nil {
+		respondInternalError(c, err, "empty trash")
+		return
+	}
+
+	if tc.auditService != nil {
+		tc.auditService.LogDelete(auth.GetUserID(c), "trash", 0,
+			fmt.Sprintf("%d books, %d highlights", books, highlights), true)
+	}
+
+	tc.respondUpdated(c, "Trash emptied", gin.H{
+		"message":           "trash emptied",
+		"books_purged":      books,
+		"highlights_purged": highlights,
+	})
+}
+
+// TrashPage renders the trash page.
+// GET /trash
+func (tc *TrashController) TrashPage(c *gin.Context) {
+	data, err := tc.loadTrash()
+	if err != nil {
+		respondInternalError(c, err, "load trash page")
+		return
+	}
+
+	data["Auth"] = GetAuthTemplateData(c)
+	data["UI"] = GetUIPreferences(c)
+	data["Demo"] = GetDemoTemplateData(c)
+	data["Analytics"] = GetAnalyticsTemplateData(c)
+	c.HTML(http.StatusOK, "trash", data)
+}
+
+// respondUpdated re-renders the trash list for HTMX requests, or returns the JSON payload.
+func (tc *TrashController) respondUpdated(c *gin.Context, message string, payload gin.H) {
+	if !isHTMXRequest(c) {
+		c.JSON(http.StatusOK, payload)
+		return
+	}
+
+	data, err := tc.loadTrash()
+	if err != nil {
+		respondInternalError(c, err, "list trash")
+		return
+	}
+	data["Message"] = message
+	c.HTML(http.StatusOK, "trash-list", data)
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockTrashStore struct {
+	books           []entities.TrashedBook
+	highlights      []entities.TrashedHighlight
+	restoredBookID  uint
+	restoredHighlID uint
+	emptied         bool
+}
+
+func (m *mockTrashStore) GetTrashedBooks() ([]entities.TrashedBook, error) {
+	return m.books, nil
+}
+
+func (m *mockTrashStore) GetTrashedHighlights() ([]entities.TrashedHighlight, error) {
+	return m.highlights, nil
+}
+
+func (m *mockTrashStore) RestoreBook(id uint) (*entities.Book, error) {
+	for _, b := range m.books {
+		if b.Book.ID == id {
+			m.restoredBookID = id
+			return &b.Book, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockTrashStore) RestoreHighlight(id uint) (*entities.Highlight, error) {
+	for _, h := range m.highlights {
+		if h.Highlight.ID == id {
+			m.restoredHighlID = id
+			return &h.Highlight, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockTrashStore) EmptyTrash() (int64, int64, error) {
+	m.emptied = true
+	return int64(len(m.books)), int64(len(m.highlights)), nil
+}
+
+func setupTrashRouter(store *mockTrashStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	controller := NewTrashController(store, nil, 30)
+	router := gin.New()
+	router.GET("/api/trash", controller.ListTrash)
+	router.DELETE("/api/trash", controller.EmptyTrash)
+	router.POST("/api/trash/books/:id/restore", controller.RestoreBook)
+	router.POST("/api/trash/highlights/:id/restore", controller.RestoreHighlight)
+	return router
+}
+
+func TestTrashController(t *testing.T) {
+	newStore := func() *mockTrashStore {
+		return &mockTrashStore{
+			books:      []entities.TrashedBook{{Book: entities.Book{ID: 1, Title: "Deleted Book"}, HighlightCount: 3}},
+			highlights: []entities.TrashedHighlight{{Highlight: entities.Highlight{ID: 7, Text: "Deleted"}, BookTitle: "Other"}},
+		}
+	}
+
+	t.Run("lists trash", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/trash", nil)
+		setupTrashRouter(newStore()).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Books         []entities.TrashedBook      `json:"books"`
+			Highlights    []entities.TrashedHighlight `json:"highlights"`
+			RetentionDays int                         `json:"retention_days"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Books, 1)
+		assert.Len(t, response.Highlights, 1)
+		assert.Equal(t, 30, response.RetentionDays)
+	})
+
+	t.Run("restores book and highlight", func(t *testing.T) {
+		store := newStore()
+		router := setupTrashRouter(store)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/trash/books/1/restore", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, uint(1), store.restoredBookID)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/trash/highlights/7/restore", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, uint(7), store.restoredHighlID)
+	})
+
+	t.Run("returns 404 for items not in trash", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/trash/books/99/restore", nil)
+		setupTrashRouter(newStore()).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("empties trash", func(t *testing.T) {
+		store := newStore()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/trash", nil)
+		setupTrashRouter(store).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, store.emptied)
+		assert.Contains(t, w.Body.String(), `"books_purged":1`)
+	})
+}
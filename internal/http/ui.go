@@ -3,20 +3,37 @@ package http
 import (
 	"archive/zip"
 	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
 	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/langdetect"
 )
 
+// BookListStore lists books for pages that show their highlight counters but
+// not the highlights themselves.
+type BookListStore interface {
+	GetBooksWithoutHighlights() ([]entities.Book, error)
+}
+
 type UIController struct {
-	reader          exporters.BookReader
-	tagStore        TagStore
-	vocabularyStore VocabularyStore
+	reader            exporters.BookReader
+	bookList          BookListStore
+	tagStore          TagStore
+	vocabularyStore   VocabularyStore
+	savedViews        SavedViewGetter
+	bookHighlights    BookHighlightStore
+	highlightOfTheDay bool
+	journal           bool
 }
 
 func NewUIController(reader exporters.BookReader, tagStore TagStore, vocabularyStore VocabularyStore) *UIController {
@@ -27,11 +44,43 @@ func NewUIController(reader exporters.BookReader, tagStore TagStore, vocabularyS
 	}
 }
 
+// WithHighlightOfTheDay shows the highlight of the day card on the home page.
+func (controller *UIController) WithHighlightOfTheDay(enabled bool) *UIController {
+	controller.highlightOfTheDay = enabled
+	return controller
+}
+
+// WithJournal shows the journal tab on book pages.
+func (controller *UIController) WithJournal(enabled bool) *UIController {
+	controller.journal = enabled
+	return controller
+}
+
+// WithBookHighlights makes book pages load their highlights a page at a
+// time instead of all at once.
+func (controller *UIController) WithBookHighlights(store BookHighlightStore) *UIController {
+	controller.bookHighlights = store
+	return controller
+}
+
+// WithBookList makes the library page load books without their highlights.
+func (controller *UIController) WithBookList(store BookListStore) *UIController {
+	controller.bookList = store
+	return controller
+}
+
+// WithSavedViews lets downloads use a saved view as their filter.
+func (controller *UIController) WithSavedViews(views SavedViewGetter) *UIController {
+	controller.savedViews = views
+	return controller
+}
+
+// BooksPage renders the library in the user's sort order and layout, one
+// page of the user's page size at a time.
 func (controller *UIController) BooksPage(c *gin.Context) {
 	tagIDStr := c.Query("tag")
 	var selectedTagID uint
-	var books []any
-	var highlightsCount int
+	var books []entities.Book
 	filterByTag := false
 
 	if tagIDStr != "" && controller.tagStore != nil {
@@ -39,28 +88,44 @@ func (controller *UIController) BooksPage(c *gin.Context) {
 		if err == nil {
 			selectedTagID = uint(tagID)
 			filterByTag = true
-			filteredBooks, err := controller.tagStore.GetBooksByTag(selectedTagID, 0)
+			books, err = controller.tagStore.GetBooksByTag(selectedTagID, 0)
 			if err != nil {
 				c.String(http.StatusInternalServerError, "Error loading books: %s", err.Error())
 				return
 			}
-			for _, b := range filteredBooks {
-				highlightsCount += len(b.Highlights)
-				books = append(books, b)
-			}
 		}
 	}
 
 	if !filterByTag {
-		allBooks, err := controller.reader.GetAllBooks()
+		var err error
+		books, err = controller.listBooks()
 		if err != nil {
 			c.String(http.StatusInternalServerError, "Error loading books: %s", err.Error())
 			return
 		}
-		for _, b := range allBooks {
-			highlightsCount += len(b.Highlights)
-			books = append(books, b)
-		}
+	}
+
+	// Archived books are out of the way unless asked for
+	includeArchived := c.Query("include_archived") == "true"
+	unarchived, archivedCount := withoutArchived(books)
+	if !includeArchived {
+		books = unarchived
+	}
+
+	// Shelves count the books shown before picking one of them
+	shelves := languageShelves(books)
+	selectedLanguage := c.Query("language")
+	if selectedLanguage != "" {
+		books = withLanguage(books, selectedLanguage)
+	}
+
+	ui := GetUIPreferences(c)
+	sortBooks(books, ui.Sort)
+	favouritesFirst(books)
+
+	var highlightsCount int
+	for _, b := range books {
+		highlightsCount += b.HighlightCount
 	}
 
 	// Get all tags for filter UI
@@ -72,15 +137,137 @@ func (controller *UIController) BooksPage(c *gin.Context) {
 		}
 	}
 
+	page, totalPages := 1, (len(books)+ui.PageSize-1)/ui.PageSize
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 1 {
+		page = min(p, max(totalPages, 1))
+	}
+	pageBooks := books[min((page-1)*ui.PageSize, len(books)):min(page*ui.PageSize, len(books))]
+
 	c.HTML(http.StatusOK, "books", gin.H{
-		"Books":           books,
-		"TotalBooks":      len(books),
-		"TotalHighlights": highlightsCount,
-		"Tags":            tags,
-		"SelectedTagID":   selectedTagID,
-		"Auth":            GetAuthTemplateData(c),
-		"Demo":            GetDemoTemplateData(c),
-		"Analytics":       GetAnalyticsTemplateData(c),
+		"Books":             pageBooks,
+		"TotalBooks":        len(books),
+		"TotalHighlights":   highlightsCount,
+		"CurrentPage":       page,
+		"TotalPages":        totalPages,
+		"Tags":              tags,
+		"SelectedTagID":     selectedTagID,
+		"LanguageShelves":   shelves,
+		"SelectedLanguage":  selectedLanguage,
+		"ArchivedBooks":     archivedCount,
+		"IncludeArchived":   includeArchived,
+		"HighlightOfTheDay": controller.highlightOfTheDay && !filterByTag && selectedLanguage == "",
+		"Auth":              GetAuthTemplateData(c),
+		"UI":                ui,
+		"Demo":              GetDemoTemplateData(c),
+		"Analytics":         GetAnalyticsTemplateData(c),
+	})
+}
+
+// listBooks returns every book, without highlights when a BookListStore is set.
+func (controller *UIController) listBooks() ([]entities.Book, error) {
+	if controller.bookList != nil {
+		return controller.bookList.GetBooksWithoutHighlights()
+	}
+	return controller.reader.GetAllBooks()
+}
+
+// LanguageShelf is a library filter for the books in one language.
+type LanguageShelf struct {
+	Code  string
+	Name  string
+	Count int
+}
+
+// languageShelves returns a shelf for each language the books are in, largest
+// first. Books in a single language need no shelves, so none are returned.
+func languageShelves(books []entities.Book) []LanguageShelf {
+	counts := make(map[string]int)
+	for _, book := range books {
+		if book.Language != "" {
+			counts[book.Language]++
+		}
+	}
+	if len(counts) < 2 {
+		return nil
+	}
+
+	shelves := make([]LanguageShelf, 0, len(counts))
+	for code, count := range counts {
+		shelves = append(shelves, LanguageShelf{Code: code, Name: langdetect.Name(code), Count: count})
+	}
+	slices.SortFunc(shelves, func(a, b LanguageShelf) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Code, b.Code)
+	})
+	return shelves
+}
+
+// sortBooks orders books by one of entities.BookSorts, keeping the stored
+// order for entities.BookSortAdded and ties
+func sortBooks(books []entities.Book, order string) {
+	var compare func(a, b entities.Book) int
+	switch order {
+	case entities.BookSortTitle:
+		compare = func(a, b entities.Book) int {
+			return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		}
+	case entities.BookSortAuthor:
+		compare = func(a, b entities.Book) int {
+			return strings.Compare(strings.ToLower(a.Author), strings.ToLower(b.Author))
+		}
+	case entities.BookSortRecent:
+		compare = func(a, b entities.Book) int {
+			return lastHighlightedAt(b).Compare(lastHighlightedAt(a))
+		}
+	case entities.BookSortHighlights:
+		compare = func(a, b entities.Book) int {
+			return b.HighlightCount - a.HighlightCount
+		}
+	case entities.BookSortImported:
+		compare = func(a, b entities.Book) int {
+			return b.CreatedAt.Compare(a.CreatedAt)
+		}
+	case entities.BookSortPublished:
+		compare = func(a, b entities.Book) int {
+			// Unknown years go last
+			switch {
+			case a.PublicationYear == 0 && b.PublicationYear != 0:
+				return 1
+			case b.PublicationYear == 0 && a.PublicationYear != 0:
+				return -1
+			default:
+				return b.PublicationYear - a.PublicationYear
+			}
+		}
+	default:
+		return
+	}
+	slices.SortStableFunc(books, compare)
+}
+
+// lastHighlightedAt returns when the book's latest highlight was made, or the
+// zero time for books without dated highlights
+func lastHighlightedAt(book entities.Book) time.Time {
+	if book.LastHighlightedAt == nil {
+		return time.Time{}
+	}
+	return *book.LastHighlightedAt
+}
+
+// favouritesFirst moves favourite books to the top of the list, keeping the
+// order within favourites and within the other books
+func favouritesFirst(books []entities.Book) {
+	slices.SortStableFunc(books, func(a, b entities.Book) int {
+		switch {
+		case a.IsFavorite == b.IsFavorite:
+			return 0
+		case a.IsFavorite:
+			return -1
+		default:
+			return 1
+		}
 	})
 }
 
@@ -92,38 +279,86 @@ func (controller *UIController) BookPage(c *gin.Context) {
 		return
 	}
 
+	if controller.bookHighlights != nil {
+		controller.pagedBookPage(c, uint(id))
+		return
+	}
+
 	book, err := controller.reader.GetBookByID(uint(id))
 	if err != nil {
 		c.String(http.StatusNotFound, "Book not found")
 		return
 	}
 
+	// Color filter chips are built from all highlights before filtering
+	colors := highlightColorCounts(book.Highlights)
+	totalHighlights := len(book.Highlights)
+	selectedColor := strings.ToLower(c.Query("color"))
+	if selectedColor != "" {
+		book.Highlights = filterHighlightsByColor(book.Highlights, selectedColor)
+	}
+
+	words := bookWords(controller.vocabularyStore, book.ID)
+	controller.renderBookPage(c, book, colors, selectedColor, totalHighlights,
+		bookHighlightsTemplateData(c, book.ID, bookHighlightPage{Highlights: book.Highlights}, selectedColor, words), words)
+}
+
+// pagedBookPage renders a book page with the first page of its highlights;
+// the page loads the rest as they are scrolled to.
+func (controller *UIController) pagedBookPage(c *gin.Context, id uint) {
+	book, err := controller.bookHighlights.GetBookWithoutHighlights(id)
+	if err != nil {
+		c.String(http.StatusNotFound, "Book not found")
+		return
+	}
+
+	colors, totalHighlights, err := bookHighlightColorCounts(controller.bookHighlights, book.ID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error loading highlights: %s", err.Error())
+		return
+	}
+	selectedColor := strings.ToLower(c.Query("color"))
+	page, err := loadBookHighlightPage(controller.bookHighlights, book.ID, selectedColor, 0, bookHighlightsPageSize)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error loading highlights: %s", err.Error())
+		return
+	}
+
+	words := bookWords(controller.vocabularyStore, book.ID)
+	controller.renderBookPage(c, book, colors, selectedColor, totalHighlights,
+		bookHighlightsTemplateData(c, book.ID, page, selectedColor, words), words)
+}
+
+func (controller *UIController) renderBookPage(c *gin.Context, book *entities.Book, colors []HighlightColorCount, selectedColor string, totalHighlights int, highlights gin.H, words []entities.Word) {
 	c.HTML(http.StatusOK, "book", gin.H{
-		"Book":      book,
-		"Auth":      GetAuthTemplateData(c),
-		"Demo":      GetDemoTemplateData(c),
-		"Analytics": GetAnalyticsTemplateData(c),
+		"Book":            book,
+		"Colors":          colors,
+		"SelectedColor":   selectedColor,
+		"TotalHighlights": totalHighlights,
+		"HighlightPage":   highlights,
+		"Vocabulary":      controller.vocabularyStore != nil,
+		"Journal":         controller.journal,
+		"Words":           words,
+		"Preferences":     GetUserPreferences(c),
+		"Auth":            GetAuthTemplateData(c),
+		"UI":              GetUIPreferences(c),
+		"Demo":            GetDemoTemplateData(c),
+		"Analytics":       GetAnalyticsTemplateData(c),
 	})
 }
 
+// SearchBooks renders the books matching q in the user's sort order, leaving
+// out archived books unless include_archived=true
 func (controller *UIController) SearchBooks(c *gin.Context) {
 	query := c.Query("q")
 
-	var books []any
+	var books []entities.Book
 	var err error
 
 	if query == "" {
-		allBooks, e := controller.reader.GetAllBooks()
-		err = e
-		for _, b := range allBooks {
-			books = append(books, b)
-		}
+		books, err = controller.listBooks()
 	} else {
-		searchedBooks, e := controller.reader.SearchBooks(query)
-		err = e
-		for _, b := range searchedBooks {
-			books = append(books, b)
-		}
+		books, err = controller.reader.SearchBooks(query)
 	}
 
 	if err != nil {
@@ -131,6 +366,10 @@ func (controller *UIController) SearchBooks(c *gin.Context) {
 		return
 	}
 
+	if c.Query("include_archived") != "true" {
+		books, _ = withoutArchived(books)
+	}
+	sortBooks(books, GetUIPreferences(c).Sort)
 	c.HTML(http.StatusOK, "book-list", books)
 }
 
@@ -148,16 +387,34 @@ func (controller *UIController) DownloadMarkdown(c *gin.Context) {
 		return
 	}
 
-	markdown := exporters.GenerateMarkdown(book)
+	filter, err := controller.exportFilter(c)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	// A book downloaded on its own is exported even when archived
+	filter.IncludeArchived = true
+	if !filter.IsEmpty() {
+		// Keep the book even when nothing matches, so the download is never missing
+		filtered := filter.Apply([]entities.Book{*book})
+		book.Highlights = nil
+		if len(filtered) > 0 {
+			book.Highlights = filtered[0].Highlights
+		}
+	}
+
+	format := c.DefaultQuery("format", exporters.FormatMarkdown)
+	content, err := exporters.GenerateBook(format, book)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Unsupported format: %s", format)
+		return
+	}
 
-	// Sanitize filename
-	filename := strings.ReplaceAll(book.Title, "/", "-")
-	filename = strings.ReplaceAll(filename, "\\", "-")
-	filename = fmt.Sprintf("%s.md", filename)
+	filename := path.Base(filepath.ToSlash(exporters.BookFilePath(format, book)))
 
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
-	c.Header("Content-Type", "text/markdown; charset=utf-8")
-	c.String(http.StatusOK, markdown)
+	c.Header("Content-Type", exporters.FormatContentType(format))
+	c.String(http.StatusOK, content)
 }
 
 func (controller *UIController) DownloadAllMarkdown(c *gin.Context) {
@@ -167,40 +424,43 @@ func (controller *UIController) DownloadAllMarkdown(c *gin.Context) {
 		return
 	}
 
+	format := c.DefaultQuery("format", exporters.FormatMarkdown)
+	if !slices.Contains(exporters.Formats, format) {
+		c.String(http.StatusBadRequest, "Unsupported format: %s", format)
+		return
+	}
+
+	filter, err := controller.exportFilter(c)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	books = filter.Apply(books)
+
 	// Create ZIP in memory
 	buf := new(bytes.Buffer)
 	zipWriter := zip.NewWriter(buf)
 
-	for _, book := range books {
-		markdown := exporters.GenerateMarkdown(&book)
-
-		// Determine folder based on source
-		sourceFolder := "unknown"
-		if book.Source.Name != "" {
-			sourceFolder = book.Source.Name
-		}
-
-		// Sanitize filename
-		filename := strings.ReplaceAll(book.Title, "/", "-")
-		filename = strings.ReplaceAll(filename, "\\", "-")
-		filename = strings.ReplaceAll(filename, ":", "-")
-		filepath := fmt.Sprintf("highlights/%s/%s.md", sourceFolder, filename)
+	// Files are laid out as the scheduled export writes them, under highlights/
+	filePaths := exporters.BookFilePaths(format, books)
+	for i, book := range books {
+		content, _ := exporters.GenerateBook(format, &book)
 
-		writer, err := zipWriter.Create(filepath)
+		writer, err := zipWriter.Create(path.Join("highlights", filepath.ToSlash(filePaths[i])))
 		if err != nil {
 			continue
 		}
-		_, _ = writer.Write([]byte(markdown))
+		_, _ = writer.Write([]byte(content))
 	}
 
 	// Add vocabulary file if store is available
 	if controller.vocabularyStore != nil {
 		words, _, err := controller.vocabularyStore.GetAllWords(0, 0, 0)
 		if err == nil && len(words) > 0 {
-			vocabularyMarkdown := exporters.GenerateVocabularyMarkdown(words)
-			writer, err := zipWriter.Create("highlights/vocabulary.md")
+			vocabulary, _ := exporters.GenerateVocabulary(format, words)
+			writer, err := zipWriter.Create(path.Join("highlights", filepath.ToSlash(exporters.VocabularyFilePath(format))))
 			if err == nil {
-				_, _ = writer.Write([]byte(vocabularyMarkdown))
+				_, _ = writer.Write([]byte(vocabulary))
 			}
 		}
 	}
@@ -214,3 +474,63 @@ func (controller *UIController) DownloadAllMarkdown(c *gin.Context) {
 	c.Header("Content-Type", "application/zip")
 	c.Data(http.StatusOK, "application/zip", buf.Bytes())
 }
+
+// exportFilter reads the export filter query parameters, or with view=<id>
+// takes the criteria of a saved view instead
+func (controller *UIController) exportFilter(c *gin.Context) (exporters.ExportFilter, error) {
+	if c.Query("view") == "" || controller.savedViews == nil {
+		return parseExportFilter(c)
+	}
+	id, err := strconv.ParseUint(c.Query("view"), 10, 32)
+	if err != nil {
+		return exporters.ExportFilter{}, errors.New("invalid view")
+	}
+	view, err := controller.savedViews.GetSavedView(uint(id))
+	if err != nil {
+		return exporters.ExportFilter{}, errors.New("saved view not found")
+	}
+	return exporters.ViewFilter(view), nil
+}
+
+// parseExportFilter reads the export filter query parameters: tag, collection
+// and source as repeated parameters or comma-separated lists, favourite=true,
+// q to search text and notes, since and until as YYYY-MM-DD or RFC 3339, and
+// include_archived=true to export archived books too.
+func parseExportFilter(c *gin.Context) (exporters.ExportFilter, error) {
+	filter := exporters.ExportFilter{
+		Tags:        queryList(c, "tag"),
+		Collections: queryList(c, "collection"),
+		Sources:     queryList(c, "source"),
+		Query:       strings.TrimSpace(c.Query("q")),
+	}
+
+	favourite, err := parseOptionalBool(c, "favourite")
+	if err != nil {
+		return filter, err
+	}
+	filter.FavoritesOnly = favourite != nil && *favourite
+	if filter.IncludeArchived, err = parseIncludeArchived(c); err != nil {
+		return filter, err
+	}
+
+	if filter.Since, err = parseFilterDate(c, "since", false); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseFilterDate(c, "until", true); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// queryList collects a query parameter given repeatedly or as a comma-separated list
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, v := range c.QueryArray(name) {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+const (
+	uiPreferencesStoreContextKey = "ui_preferences_store"
+	uiPreferencesContextKey      = "ui_preferences"
+)
+
+// UIPreferencesStore defines database operations for per-user UI settings.
+type UIPreferencesStore interface {
+	GetUIPreferences(userID uint) (entities.UIPreferences, error)
+	SaveUIPreferences(prefs *entities.UIPreferences) error
+}
+
+// UIPreferencesContextMiddleware lets handlers and templates look up the
+// current user's theme, page size, sorts and view mode with GetUIPreferences.
+// Must run after authentication.
+func UIPreferencesContextMiddleware(store UIPreferencesStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(uiPreferencesStoreContextKey, store)
+		c.Next()
+	}
+}
+
+// GetUIPreferences returns the current user's UI settings, looked up once per
+// request. Without UIPreferencesContextMiddleware it returns the defaults.
+func GetUIPreferences(c *gin.Context) entities.UIPreferences {
+	if prefs, exists := c.Get(uiPreferencesContextKey); exists {
+		if p, ok := prefs.(entities.UIPreferences); ok {
+			return p
+		}
+	}
+
+	userID := auth.GetUserID(c)
+	prefs := entities.DefaultUIPreferences(userID)
+	if value, exists := c.Get(uiPreferencesStoreContextKey); exists {
+		if store, ok := value.(UIPreferencesStore); ok {
+			if stored, err := store.GetUIPreferences(userID); err == nil {
+				prefs = stored
+			}
+		}
+	}
+	c.Set(uiPreferencesContextKey, prefs)
+	return prefs
+}
+
+// UIPreferencesController reads and changes the current user's UI settings.
+type UIPreferencesController struct {
+	store UIPreferencesStore
+}
+
+func NewUIPreferencesController(store UIPreferencesStore) *UIPreferencesController {
+	return &UIPreferencesController{store: store}
+}
+
+// UIPreferencesRequest changes some of the UI settings; omitted ones are kept.
+type UIPreferencesRequest struct {
+	Theme         *string `json:"theme" form:"theme"`
+	PageSize      *int    `json:"page_size" form:"page_size"`
+	Sort          *string `json:"sort" form:"sort"`
+	HighlightSort *string `json:"highlight_sort" form:"highlight_sort"`
+	ViewMode      *string `json:"view_mode" form:"view_mode"`
+}
+
+// GetPreferences returns the current user's UI settings.
+// GET /api/preferences
+func (pc *UIPreferencesController) GetPreferences(c *gin.Context) {
+	prefs, err := pc.store.GetUIPreferences(GetUserID(c))
+	if err != nil {
+		respondInternalError(c, err, "get UI preferences")
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences changes the current user's UI settings. HTMX requests get
+// the page reloaded so the new settings apply.
+// PUT /api/preferences
+func (pc *UIPreferencesController) UpdatePreferences(c *gin.Context) {
+	var req UIPreferencesRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondBadRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	prefs, err := pc.store.GetUIPreferences(GetUserID(c))
+	if err != nil {
+		respondInternalError(c, err, "get UI preferences")
+		return
+	}
+	if req.Theme != nil {
+		prefs.Theme = *req.Theme
+	}
+	if req.PageSize != nil {
+		prefs.PageSize = *req.PageSize
+	}
+	if req.Sort != nil {
+		prefs.Sort = *req.Sort
+	}
+	if req.HighlightSort != nil {
+		prefs.HighlightSort = *req.HighlightSort
+	}
+	if req.ViewMode != nil {
+		prefs.ViewMode = *req.ViewMode
+	}
+
+	if err := pc.store.SaveUIPreferences(&prefs); err != nil {
+		if errors.Is(err, database.ErrInvalidUIPreferences) {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		respondInternalError(c, err, "save UI preferences")
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+	}
+	c.JSON(http.StatusOK, prefs)
+}
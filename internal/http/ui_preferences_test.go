@@ -0,0 +1,104 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestUIPreferencesController(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	controller := NewUIPreferencesController(db)
+	router := gin.New()
+	router.GET("/api/preferences", controller.GetPreferences)
+	router.PUT("/api/preferences", controller.UpdatePreferences)
+
+	send := func(method, body, contentType string) (*httptest.ResponseRecorder, entities.UIPreferences) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/api/preferences", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		router.ServeHTTP(w, req)
+		var prefs entities.UIPreferences
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prefs))
+		}
+		return w, prefs
+	}
+
+	w, prefs := send(http.MethodGet, "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, entities.ThemeSystem, prefs.Theme)
+	assert.Equal(t, entities.DefaultPageSize, prefs.PageSize)
+	assert.Equal(t, entities.BookSortAdded, prefs.Sort)
+	assert.Equal(t, entities.ViewModeList, prefs.ViewMode)
+
+	// Omitted settings are kept
+	w, prefs = send(http.MethodPut, `{"theme": "dark", "page_size": 25}`, "application/json")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, entities.ThemeDark, prefs.Theme)
+	assert.Equal(t, 25, prefs.PageSize)
+	assert.Equal(t, entities.BookSortAdded, prefs.Sort)
+
+	// The profile page submits a form
+	w, prefs = send(http.MethodPut, "sort=title&highlight_sort=published&view_mode=grid", "application/x-www-form-urlencoded")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, entities.ThemeDark, prefs.Theme)
+	assert.Equal(t, entities.BookSortTitle, prefs.Sort)
+	assert.Equal(t, entities.HighlightSortPublished, prefs.HighlightSort)
+	assert.Equal(t, entities.ViewModeGrid, prefs.ViewMode)
+
+	_, prefs = send(http.MethodGet, "", "")
+	assert.Equal(t, entities.ViewModeGrid, prefs.ViewMode)
+	assert.Equal(t, 25, prefs.PageSize)
+
+	w, _ = send(http.MethodPut, `{"theme": "sepia"}`, "application/json")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = send(http.MethodPut, `{"page_size": 5000}`, "application/json")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSortBooks(t *testing.T) {
+	books := func() []entities.Book {
+		return []entities.Book{
+			{Title: "dune", Author: "Herbert", PublicationYear: 1965, HighlightCount: 1,
+				CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{Title: "Babel", Author: "Kuang", HighlightCount: 3,
+				CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{Title: "Circe", Author: "Miller", PublicationYear: 2018, HighlightCount: 2,
+				CreatedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		}
+	}
+	titles := func(books []entities.Book) []string {
+		var titles []string
+		for _, b := range books {
+			titles = append(titles, b.Title)
+		}
+		return titles
+	}
+
+	for sort, want := range map[string][]string{
+		entities.BookSortAdded:      {"dune", "Babel", "Circe"},
+		entities.BookSortTitle:      {"Babel", "Circe", "dune"},
+		entities.BookSortAuthor:     {"dune", "Babel", "Circe"},
+		entities.BookSortHighlights: {"Babel", "Circe", "dune"},
+		entities.BookSortImported:   {"dune", "Circe", "Babel"},
+		entities.BookSortPublished:  {"Circe", "dune", "Babel"},
+	} {
+		b := books()
+		sortBooks(b, sort)
+		assert.Equal(t, want, titles(b), sort)
+	}
+}
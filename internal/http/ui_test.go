@@ -135,6 +135,68 @@ func TestUIController_DownloadMarkdown(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "> Test highlight")
 	})
 
+	t.Run("renders the requested format", func(t *testing.T) {
+		db, exporter, cleanup := setupUITestDB(t)
+		defer cleanup()
+
+		require.NoError(t, db.SaveBook(&entities.Book{
+			Title:      "Org Book",
+			Author:     "Author",
+			Highlights: []entities.Highlight{{Text: "Org highlight"}},
+		}))
+
+		controller := NewUIController(exporter, nil, nil)
+
+		router := gin.New()
+		router.GET("/ui/books/:id/download", controller.DownloadMarkdown)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/ui/books/1/download?format=org", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "Org Book.org")
+		assert.Contains(t, w.Body.String(), "#+title: Org Book")
+		assert.Contains(t, w.Body.String(), "#+begin_quote\nOrg highlight\n#+end_quote")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/ui/books/1/download?format=docx", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("filters highlights", func(t *testing.T) {
+		db, exporter, cleanup := setupUITestDB(t)
+		defer cleanup()
+
+		require.NoError(t, db.SaveBook(&entities.Book{
+			Title:  "Filtered Book",
+			Author: "Author",
+			Highlights: []entities.Highlight{
+				{Text: "Favourite highlight", IsFavorite: true},
+				{Text: "Ordinary highlight"},
+			},
+		}))
+
+		controller := NewUIController(exporter, nil, nil)
+
+		router := gin.New()
+		router.GET("/ui/books/:id/download", controller.DownloadMarkdown)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/ui/books/1/download?favourite=true", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Favourite highlight")
+		assert.NotContains(t, w.Body.String(), "Ordinary highlight")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/ui/books/1/download?since=last-week", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	t.Run("sanitizes filename with slashes", func(t *testing.T) {
 		db, exporter, cleanup := setupUITestDB(t)
 		defer cleanup()
@@ -230,6 +292,39 @@ func TestUIController_DownloadAllMarkdown(t *testing.T) {
 		assert.True(t, hasAppleBook, "Should have apple_books book")
 	})
 
+	t.Run("filters books by source", func(t *testing.T) {
+		db, exporter, cleanup := setupUITestDB(t)
+		defer cleanup()
+
+		require.NoError(t, db.SaveBook(&entities.Book{
+			Title:      "Kindle Book",
+			Author:     "Author",
+			Source:     entities.Source{Name: "kindle"},
+			Highlights: []entities.Highlight{{Text: "From Kindle"}},
+		}))
+		require.NoError(t, db.SaveBook(&entities.Book{
+			Title:      "Apple Book",
+			Author:     "Author",
+			Source:     entities.Source{Name: "apple_books"},
+			Highlights: []entities.Highlight{{Text: "From Apple Books"}},
+		}))
+
+		controller := NewUIController(exporter, nil, nil)
+
+		router := gin.New()
+		router.GET("/ui/books/download/all", controller.DownloadAllMarkdown)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/ui/books/download/all?source=kindle", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		zipReader, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		require.NoError(t, err)
+		require.Len(t, zipReader.File, 1)
+		assert.Equal(t, "highlights/kindle/Kindle Book.md", zipReader.File[0].Name)
+	})
+
 	t.Run("zip files contain markdown content", func(t *testing.T) {
 		db, exporter, cleanup := setupUITestDB(t)
 		defer cleanup()
@@ -349,3 +444,19 @@ func TestNewUIController(t *testing.T) {
 func createTestTemplate() *template.Template {
 	return template.Must(template.New("book-list").Parse("{{.}}"))
 }
+
+func TestLanguageShelves(t *testing.T) {
+	books := []entities.Book{
+		{Title: "Dune", Language: "en"},
+		{Title: "Der Prozess", Language: "de"},
+		{Title: "Emma", Language: "en"},
+		{Title: "Untitled"},
+	}
+
+	shelves := languageShelves(books)
+	require.Len(t, shelves, 2)
+	assert.Equal(t, LanguageShelf{Code: "en", Name: "English", Count: 2}, shelves[0])
+	assert.Equal(t, LanguageShelf{Code: "de", Name: "Deutsch", Count: 1}, shelves[1])
+
+	assert.Empty(t, languageShelves(books[:1]), "a single language needs no shelves")
+}
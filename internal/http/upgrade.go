@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// UpgradeStatusStore defines database operations for upgrade migration tracking.
+type UpgradeStatusStore interface {
+	GetMigrations() ([]entities.SchemaMigration, error)
+}
+
+type UpgradeStatusController struct {
+	store UpgradeStatusStore
+}
+
+func NewUpgradeStatusController(store UpgradeStatusStore) *UpgradeStatusController {
+	return &UpgradeStatusController{store: store}
+}
+
+func (uc *UpgradeStatusController) loadStatus() (gin.H, error) {
+	migrations, err := uc.store.GetMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var backfills, schemaChanges []entities.SchemaMigration
+	inProgress := 0
+	for _, m := range migrations {
+		if m.Kind == entities.MigrationKindBackfill {
+			backfills = append(backfills, m)
+			if m.Status == entities.MigrationStatusPending || m.Status == entities.MigrationStatusRunning {
+				inProgress++
+			}
+		} else {
+			schemaChanges = append(schemaChanges, m)
+		}
+	}
+
+	return gin.H{
+		"Backfills":     backfills,
+		"SchemaChanges": schemaChanges,
+		"InProgress":    inProgress,
+	}, nil
+}
+
+// GetStatus returns recorded schema changes and data backfill progress.
+// GET /api/upgrade/status
+func (uc *UpgradeStatusController) GetStatus(c *gin.Context) {
+	data, err := uc.loadStatus()
+	if err != nil {
+		respondInternalError(c, err, "get upgrade status")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "upgrade-status-list", data)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"backfills":      data["Backfills"],
+		"schema_changes": data["SchemaChanges"],
+		"in_progress":    data["InProgress"],
+	})
+}
+
+// UpgradeStatusPage renders the upgrade status page.
+// GET /upgrade
+func (uc *UpgradeStatusController) UpgradeStatusPage(c *gin.Context) {
+	data, err := uc.loadStatus()
+	if err != nil {
+		respondInternalError(c, err, "load upgrade status page")
+		return
+	}
+
+	data["Auth"] = GetAuthTemplateData(c)
+	data["UI"] = GetUIPreferences(c)
+	data["Demo"] = GetDemoTemplateData(c)
+	data["Analytics"] = GetAnalyticsTemplateData(c)
+	c.HTML(http.StatusOK, "upgrade-status", data)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUpgradeStatusStore struct {
+	migrations []entities.SchemaMigration
+	err        error
+}
+
+func (m *mockUpgradeStatusStore) GetMigrations() ([]entities.SchemaMigration, error) {
+	return m.migrations, m.err
+}
+
+func TestUpgradeStatusController_GetStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("separates backfills from schema changes", func(t *testing.T) {
+		store := &mockUpgradeStatusStore{migrations: []entities.SchemaMigration{
+			{Name: "backfill:highlight_origin_hash", Kind: entities.MigrationKindBackfill, Status: entities.MigrationStatusRunning, TotalItems: 10, Processed: 4},
+			{Name: "schema:highlights.origin_hash", Kind: entities.MigrationKindSchema, Status: entities.MigrationStatusCompleted},
+		}}
+		router := gin.New()
+		router.GET("/api/upgrade/status", NewUpgradeStatusController(store).GetStatus)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/upgrade/status", nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Backfills     []entities.SchemaMigration `json:"backfills"`
+			SchemaChanges []entities.SchemaMigration `json:"schema_changes"`
+			InProgress    int                        `json:"in_progress"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Backfills, 1)
+		assert.Equal(t, 4, response.Backfills[0].Processed)
+		require.Len(t, response.SchemaChanges, 1)
+		assert.Equal(t, 1, response.InProgress)
+	})
+
+	t.Run("returns 500 on store error", func(t *testing.T) {
+		store := &mockUpgradeStatusStore{err: errors.New("db down")}
+		router := gin.New()
+		router.GET("/api/upgrade/status", NewUpgradeStatusController(store).GetStatus)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/upgrade/status", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,278 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/uploads"
+)
+
+// Upload kinds accepted by the chunked upload API, with the file extensions allowed for each.
+const (
+	UploadKindMoonReaderBackup   = "moonreader_backup"
+	UploadKindAppleBooksDatabase = "applebooks_database"
+)
+
+// errImportFileTooLarge is returned by receiveImportFile for files over the size limit.
+var errImportFileTooLarge = errors.New("file too large")
+
+var uploadKindExtensions = map[string][]string{
+	UploadKindMoonReaderBackup:   {".mrpro", ".mrstd", ".zip"},
+	UploadKindAppleBooksDatabase: {".sqlite", ".db", ""},
+}
+
+// UploadController implements resumable chunked uploads following the tus protocol's
+// core flow: create an upload with its total size, PATCH chunks at the current offset,
+// and HEAD to find where to resume after a dropped connection. Completed uploads are
+// referenced by ID from the import forms instead of sending the file again.
+type UploadController struct {
+	store *uploads.Store
+}
+
+func NewUploadController(store *uploads.Store) *UploadController {
+	return &UploadController{store: store}
+}
+
+type createUploadRequest struct {
+	Kind     string `json:"kind" binding:"required"`
+	Filename string `json:"filename" binding:"required"`
+	Size     int64  `json:"size" binding:"required"`
+}
+
+// Create registers a new upload.
+// POST /api/uploads
+func (uc *UploadController) Create(c *gin.Context) {
+	var req createUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "kind, filename and size are required")
+		return
+	}
+
+	if err := validateUploadFilename(req.Kind, req.Filename); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	upload, err := uc.store.Create(GetUserID(c), req.Kind, req.Filename, req.Size)
+	if errors.Is(err, uploads.ErrInvalidSize) {
+		respondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("file too large (max %s)", formatMegabytes(uc.store.MaxSize())))
+		return
+	}
+	if errors.Is(err, uploads.ErrQuotaExceeded) {
+		respondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf(
+			"upload quota exceeded: unfinished uploads may take up to %s, finish or cancel them first", formatMegabytes(uc.store.UserQuota())))
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err, "create upload")
+		return
+	}
+
+	c.Header("Location", "/api/uploads/"+upload.ID)
+	setUploadHeaders(c, upload)
+	respondCreated(c, upload)
+}
+
+// Get returns an upload's progress.
+// GET /api/uploads/:id
+func (uc *UploadController) Get(c *gin.Context) {
+	upload, ok := uc.getUpload(c)
+	if !ok {
+		return
+	}
+	setUploadHeaders(c, upload)
+	c.JSON(http.StatusOK, upload)
+}
+
+// Head reports an upload's offset in headers so clients know where to resume.
+// HEAD /api/uploads/:id
+func (uc *UploadController) Head(c *gin.Context) {
+	upload, err := uc.store.Get(c.Param("id"))
+	if err == nil && upload.UserID != GetUserID(c) {
+		err = uploads.ErrNotFound
+	}
+	if err != nil {
+		c.Status(statusForUploadError(err))
+		return
+	}
+	setUploadHeaders(c, upload)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+}
+
+// WriteChunk appends the request body at the offset given in the Upload-Offset header.
+// The body is streamed to disk without buffering.
+// PATCH /api/uploads/:id
+func (uc *UploadController) WriteChunk(c *gin.Context) {
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		respondBadRequest(c, "valid Upload-Offset header is required")
+		return
+	}
+	if _, ok := uc.getUpload(c); !ok {
+		return
+	}
+
+	upload, err := uc.store.WriteChunk(c.Param("id"), offset, c.Request.Body)
+	if upload != nil {
+		setUploadHeaders(c, upload)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, uploads.ErrNotFound):
+			respondNotFound(c, "upload")
+		case errors.Is(err, uploads.ErrOffsetMismatch), errors.Is(err, uploads.ErrBusy):
+			respondError(c, http.StatusConflict, err.Error())
+		case errors.Is(err, uploads.ErrTooLarge):
+			respondError(c, http.StatusRequestEntityTooLarge, err.Error())
+		case upload != nil:
+			// Connection dropped mid-chunk; received bytes are kept for resuming
+			respondBadRequest(c, "chunk interrupted, resume from Upload-Offset")
+		default:
+			respondInternalError(c, err, "write upload chunk")
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Delete cancels an upload and removes its data.
+// DELETE /api/uploads/:id
+func (uc *UploadController) Delete(c *gin.Context) {
+	if _, ok := uc.getUpload(c); !ok {
+		return
+	}
+	if err := uc.store.Remove(c.Param("id")); err != nil {
+		if errors.Is(err, uploads.ErrNotFound) {
+			respondNotFound(c, "upload")
+			return
+		}
+		respondInternalError(c, err, "delete upload")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// getUpload loads the upload named in the URL. Uploads belonging to other users
+// are reported as not found.
+func (uc *UploadController) getUpload(c *gin.Context) (*uploads.Upload, bool) {
+	upload, err := uc.store.Get(c.Param("id"))
+	if err == nil && upload.UserID != GetUserID(c) {
+		err = uploads.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, uploads.ErrNotFound) {
+			respondNotFound(c, "upload")
+			return nil, false
+		}
+		respondInternalError(c, err, "get upload")
+		return nil, false
+	}
+	return upload, true
+}
+
+func setUploadHeaders(c *gin.Context, upload *uploads.Upload) {
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Size, 10))
+}
+
+func statusForUploadError(err error) int {
+	if errors.Is(err, uploads.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func validateUploadFilename(kind, filename string) error {
+	extensions, ok := uploadKindExtensions[kind]
+	if !ok {
+		return fmt.Errorf("unsupported upload kind: %s", kind)
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, allowed := range extensions {
+		if ext == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid file type for %s: %s", kind, filename)
+}
+
+// receiveImportFile stores the file for a form field in destPath. The file is either
+// a completed chunked upload of the signed-in user referenced by the "<field>_upload_id"
+// form value, or a regular multipart file no larger than maxSize. Returns the original
+// filename.
+func receiveImportFile(ctx *gin.Context, store *uploads.Store, fieldName, kind, destPath string, maxSize int64) (string, error) {
+	if uploadID := ctx.PostForm(fieldName + "_upload_id"); uploadID != "" {
+		if store == nil {
+			return "", fmt.Errorf("chunked uploads are not enabled")
+		}
+		upload, err := store.Get(uploadID)
+		if err != nil || upload.UserID != GetUserID(ctx) {
+			return "", fmt.Errorf("upload not found")
+		}
+		if upload.Kind != kind {
+			return "", fmt.Errorf("upload is not a %s", kind)
+		}
+		if _, err := store.Take(uploadID, destPath); err != nil {
+			if errors.Is(err, uploads.ErrIncomplete) {
+				return "", fmt.Errorf("upload is not complete")
+			}
+			return "", fmt.Errorf("failed to save file")
+		}
+		return upload.Filename, nil
+	}
+
+	file, header, err := ctx.Request.FormFile(fieldName)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return "", fmt.Errorf("%w (max %s), use chunked upload", errImportFileTooLarge, formatMegabytes(maxSize))
+	}
+	if err != nil {
+		return "", fmt.Errorf("file not provided")
+	}
+	defer file.Close()
+
+	// Check file size
+	if header.Size > maxSize {
+		return "", fmt.Errorf("%w (max %s), use chunked upload", errImportFileTooLarge, formatMegabytes(maxSize))
+	}
+
+	if err := validateUploadFilename(kind, header.Filename); err != nil {
+		return "", err
+	}
+
+	destFile, err := os.Create(destPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file")
+	}
+	defer destFile.Close()
+
+	// Copy with size limit
+	written, err := io.Copy(destFile, io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to save file")
+	}
+	if written > maxSize {
+		return "", fmt.Errorf("%w (max %s), use chunked upload", errImportFileTooLarge, formatMegabytes(maxSize))
+	}
+
+	return header.Filename, nil
+}
+
+// importFileStatus is the response status for a receiveImportFile error.
+func importFileStatus(err error) int {
+	if errors.Is(err, errImportFileTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,294 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/uploads"
+)
+
+func setupUploadRouter(t *testing.T, maxSize int64) (*gin.Engine, *uploads.Store) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store, err := uploads.NewStore(t.TempDir(), maxSize)
+	require.NoError(t, err)
+
+	controller := NewUploadController(store)
+	router := gin.New()
+	router.POST("/api/uploads", controller.Create)
+	router.GET("/api/uploads/:id", controller.Get)
+	router.HEAD("/api/uploads/:id", controller.Head)
+	router.PATCH("/api/uploads/:id", controller.WriteChunk)
+	router.DELETE("/api/uploads/:id", controller.Delete)
+	return router, store
+}
+
+func createTestUpload(t *testing.T, router *gin.Engine, kind, filename string, size int) uploads.Upload {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"kind": kind, "filename": filename, "size": size})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/uploads", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var upload uploads.Upload
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &upload))
+	return upload
+}
+
+func patchChunk(router *gin.Engine, id, offset, data string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPatch, "/api/uploads/"+id, strings.NewReader(data))
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", offset)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUploadController_ChunkedUpload(t *testing.T) {
+	router, store := setupUploadRouter(t, 1024)
+	upload := createTestUpload(t, router, UploadKindMoonReaderBackup, "backup.mrpro", 10)
+
+	w := patchChunk(router, upload.ID, "0", "01234")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Upload-Offset"))
+
+	// Resuming at a stale offset reports where to continue
+	w = patchChunk(router, upload.ID, "0", "01234")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Upload-Offset"))
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodHead, "/api/uploads/"+upload.ID, nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Upload-Offset"))
+	assert.Equal(t, "10", w.Header().Get("Upload-Length"))
+
+	w = patchChunk(router, upload.ID, "5", "56789")
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	dest := filepath.Join(t.TempDir(), "backup.mrpro")
+	_, err := store.Take(upload.ID, dest)
+	require.NoError(t, err)
+	data, _ := os.ReadFile(dest)
+	assert.Equal(t, "0123456789", string(data))
+}
+
+func TestUploadController_Create(t *testing.T) {
+	router, _ := setupUploadRouter(t, 1024)
+
+	tests := []struct {
+		name           string
+		body           map[string]any
+		expectedStatus int
+	}{
+		{"unknown kind", map[string]any{"kind": "other", "filename": "file.db", "size": 10}, http.StatusBadRequest},
+		{"wrong extension", map[string]any{"kind": UploadKindAppleBooksDatabase, "filename": "file.exe", "size": 10}, http.StatusBadRequest},
+		{"missing size", map[string]any{"kind": UploadKindAppleBooksDatabase, "filename": "file.db"}, http.StatusBadRequest},
+		{"too large", map[string]any{"kind": UploadKindAppleBooksDatabase, "filename": "file.db", "size": 2048}, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/uploads", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestUploadController_Create_UserQuota(t *testing.T) {
+	router, store := setupUploadRouter(t, 1024)
+	store.WithUserQuota(1024)
+	createTestUpload(t, router, UploadKindAppleBooksDatabase, "annotations.sqlite", 1000)
+
+	body, _ := json.Marshal(map[string]any{"kind": UploadKindAppleBooksDatabase, "filename": "books.sqlite", "size": 100})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/uploads", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "upload quota exceeded")
+}
+
+func TestUploadController_RejectsDataBeyondDeclaredSize(t *testing.T) {
+	router, _ := setupUploadRouter(t, 1024)
+	upload := createTestUpload(t, router, UploadKindAppleBooksDatabase, "book.sqlite", 4)
+
+	w := patchChunk(router, upload.ID, "0", "too long")
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestUploadController_Delete(t *testing.T) {
+	router, _ := setupUploadRouter(t, 1024)
+	upload := createTestUpload(t, router, UploadKindAppleBooksDatabase, "book.sqlite", 4)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/api/uploads/"+upload.ID, nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/uploads/"+upload.ID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUploadController_OtherUsersUploadsNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, err := uploads.NewStore(t.TempDir(), 1024)
+	require.NoError(t, err)
+	upload, err := store.Create(7, UploadKindAppleBooksDatabase, "book.sqlite", 4)
+	require.NoError(t, err)
+
+	controller := NewUploadController(store)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(auth.ContextKeyUserID, uint(8))
+	})
+	router.GET("/api/uploads/:id", controller.Get)
+	router.HEAD("/api/uploads/:id", controller.Head)
+	router.PATCH("/api/uploads/:id", controller.WriteChunk)
+	router.DELETE("/api/uploads/:id", controller.Delete)
+
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPatch, http.MethodDelete} {
+		t.Run(method, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(method, "/api/uploads/"+upload.ID, strings.NewReader("data"))
+			req.Header.Set("Upload-Offset", "0")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		})
+	}
+
+	// The owner's upload is untouched
+	current, err := store.Get(upload.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), current.Offset)
+}
+
+func TestAppleBooksImport_FromChunkedUploads(t *testing.T) {
+	store, err := uploads.NewStore(t.TempDir(), 10*1024*1024)
+	require.NoError(t, err)
+	router := setupTestRouter(NewAppleBooksImportController(nil, nil).WithUploads(store))
+
+	tempDir := t.TempDir()
+	annotationPath := filepath.Join(tempDir, "annotations.sqlite")
+	createValidAnnotationDB(t, annotationPath)
+	bookPath := filepath.Join(tempDir, "books.sqlite")
+	createValidBookDB(t, bookPath)
+
+	uploadFile := func(path string) string {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		upload, err := store.Create(0, UploadKindAppleBooksDatabase, filepath.Base(path), int64(len(data)))
+		require.NoError(t, err)
+		_, err = store.WriteChunk(upload.ID, 0, bytes.NewReader(data))
+		require.NoError(t, err)
+		return upload.ID
+	}
+	annotationID := uploadFile(annotationPath)
+	bookID := uploadFile(bookPath)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("annotation_db_upload_id", annotationID))
+	require.NoError(t, writer.WriteField("book_db_upload_id", bookID))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/applebooks/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Both databases are empty, so the import succeeds without books
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "SUCCESS")
+
+	// Imported uploads are consumed
+	_, err = store.Get(annotationID)
+	assert.ErrorIs(t, err, uploads.ErrNotFound)
+}
+
+func TestAppleBooksImport_IncompleteChunkedUpload(t *testing.T) {
+	store, err := uploads.NewStore(t.TempDir(), 1024)
+	require.NoError(t, err)
+	router := setupTestRouter(NewAppleBooksImportController(nil, nil).WithUploads(store))
+
+	upload, err := store.Create(0, UploadKindAppleBooksDatabase, "annotations.sqlite", 100)
+	require.NoError(t, err)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("annotation_db_upload_id", upload.ID))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/applebooks/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "upload is not complete")
+}
+
+func TestAppleBooksImport_OtherUsersChunkedUpload(t *testing.T) {
+	store, err := uploads.NewStore(t.TempDir(), 10*1024*1024)
+	require.NoError(t, err)
+
+	annotationPath := filepath.Join(t.TempDir(), "annotations.sqlite")
+	createValidAnnotationDB(t, annotationPath)
+	data, err := os.ReadFile(annotationPath)
+	require.NoError(t, err)
+	upload, err := store.Create(7, UploadKindAppleBooksDatabase, "annotations.sqlite", int64(len(data)))
+	require.NoError(t, err)
+	_, err = store.WriteChunk(upload.ID, 0, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(auth.ContextKeyUserID, uint(8))
+	})
+	router.SetHTMLTemplate(template.Must(template.New("applebooks-import-result").Parse(`ERROR: {{ .Error }}`)))
+	router.POST("/settings/applebooks/import", NewAppleBooksImportController(nil, nil).WithUploads(store).Import)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("annotation_db_upload_id", upload.ID))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/applebooks/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "upload not found")
+
+	// The owner's upload is not consumed
+	_, err = store.Get(upload.ID)
+	assert.NoError(t, err)
+}
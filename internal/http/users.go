@@ -1,10 +1,14 @@
 package http
 
 import (
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/i18n"
+	"github.com/mrlokans/assistant/internal/localtime"
 )
 
 // ProfileController handles user profile operations.
@@ -37,20 +41,23 @@ func (pc *ProfileController) ProfilePage(c *gin.Context) {
 	hasToken := user.TokenHash != ""
 
 	c.HTML(http.StatusOK, "profile", gin.H{
-		"User":      user,
-		"HasToken":  hasToken,
-		"Auth":      GetAuthTemplateData(c),
-		"Analytics": GetAnalyticsTemplateData(c),
+		"User":              user,
+		"HasToken":          hasToken,
+		"RecoveryCodesLeft": auth.RemainingRecoveryCodes(user),
+		"Auth":              GetAuthTemplateData(c),
+		"UI":                GetUIPreferences(c),
+		"Analytics":         GetAnalyticsTemplateData(c),
 	})
 }
 
 // ChangePassword handles password change requests.
 func (pc *ProfileController) ChangePassword(c *gin.Context) {
+	tr := GetTranslator(c)
 	userID := auth.GetUserID(c)
 	if userID == 0 {
 		c.HTML(http.StatusUnauthorized, "password-result", gin.H{
 			"Success": false,
-			"Error":   "Not authenticated",
+			"Error":   tr.T("profile.not_authenticated"),
 		})
 		return
 	}
@@ -62,7 +69,7 @@ func (pc *ProfileController) ChangePassword(c *gin.Context) {
 	if newPassword != confirmPassword {
 		c.HTML(http.StatusBadRequest, "password-result", gin.H{
 			"Success": false,
-			"Error":   "New passwords do not match",
+			"Error":   tr.T("profile.passwords_mismatch"),
 		})
 		return
 	}
@@ -70,16 +77,16 @@ func (pc *ProfileController) ChangePassword(c *gin.Context) {
 	if len(newPassword) < 8 {
 		c.HTML(http.StatusBadRequest, "password-result", gin.H{
 			"Success": false,
-			"Error":   "Password must be at least 8 characters",
+			"Error":   tr.T("profile.password_too_short"),
 		})
 		return
 	}
 
 	err := pc.authService.ChangePassword(userID, currentPassword, newPassword)
 	if err != nil {
-		errMsg := "Failed to change password"
+		errMsg := tr.T("profile.password_failed")
 		if err == auth.ErrInvalidPassword {
-			errMsg = "Current password is incorrect"
+			errMsg = tr.T("profile.password_incorrect")
 		}
 		c.HTML(http.StatusBadRequest, "password-result", gin.H{
 			"Success": false,
@@ -93,6 +100,58 @@ func (pc *ProfileController) ChangePassword(c *gin.Context) {
 	})
 }
 
+// UpdatePreferences saves the user's timezone, locale and UI language.
+// POST /profile/preferences
+func (pc *ProfileController) UpdatePreferences(c *gin.Context) {
+	tr := GetTranslator(c)
+	userID := auth.GetUserID(c)
+	if userID == 0 {
+		c.HTML(http.StatusUnauthorized, "preferences-result", gin.H{
+			"Success": false,
+			"Error":   tr.T("profile.not_authenticated"),
+		})
+		return
+	}
+
+	language := c.PostForm("language")
+	err := pc.authService.UpdatePreferences(userID, c.PostForm("timezone"), c.PostForm("locale"), language)
+	switch {
+	case errors.Is(err, localtime.ErrInvalidTimezone):
+		c.HTML(http.StatusBadRequest, "preferences-result", gin.H{
+			"Success": false,
+			"Error":   tr.T("profile.invalid_timezone"),
+		})
+		return
+	case errors.Is(err, localtime.ErrInvalidLocale):
+		c.HTML(http.StatusBadRequest, "preferences-result", gin.H{
+			"Success": false,
+			"Error":   tr.T("profile.invalid_locale"),
+		})
+		return
+	case errors.Is(err, i18n.ErrUnsupportedLanguage):
+		c.HTML(http.StatusBadRequest, "preferences-result", gin.H{
+			"Success": false,
+			"Error":   tr.T("profile.invalid_language"),
+		})
+		return
+	case err != nil:
+		log.Printf("Failed to update preferences: %v", err)
+		c.HTML(http.StatusInternalServerError, "preferences-result", gin.H{
+			"Success": false,
+			"Error":   tr.T("profile.preferences_failed"),
+		})
+		return
+	}
+
+	// Reload so a new language applies to the whole page, not just this message
+	if language != GetLanguage(c) {
+		c.Header("HX-Refresh", "true")
+	}
+	c.HTML(http.StatusOK, "preferences-result", gin.H{
+		"Success": true,
+	})
+}
+
 // GenerateToken creates a new API token for the user.
 func (pc *ProfileController) GenerateToken(c *gin.Context) {
 	userID := auth.GetUserID(c)
@@ -144,3 +203,184 @@ func (pc *ProfileController) RevokeToken(c *gin.Context) {
 		"Revoked": true,
 	})
 }
+
+// BeginTwoFactor generates a TOTP secret and shows its QR code with a confirmation form.
+// Two-factor responses render with 200 so htmx swaps errors into the page too.
+func (pc *ProfileController) BeginTwoFactor(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == 0 {
+		c.HTML(http.StatusOK, "two-factor-result", gin.H{"Error": "Not authenticated"})
+		return
+	}
+
+	enrollment, err := pc.authService.BeginTOTPEnrollment(userID)
+	if err != nil {
+		c.HTML(http.StatusOK, "two-factor-result", gin.H{"Error": twoFactorErrorMessage(err)})
+		return
+	}
+	pc.renderEnrollment(c, enrollment, "")
+}
+
+// ConfirmTwoFactor enables two-factor with the first code and shows the recovery codes.
+func (pc *ProfileController) ConfirmTwoFactor(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == 0 {
+		c.HTML(http.StatusOK, "two-factor-result", gin.H{"Error": "Not authenticated"})
+		return
+	}
+
+	codes, err := pc.authService.ConfirmTOTPEnrollment(userID, c.PostForm("code"))
+	if errors.Is(err, auth.ErrInvalidTOTPCode) {
+		enrollment, enrollErr := pc.authService.GetTOTPEnrollment(userID)
+		if enrollErr == nil {
+			pc.renderEnrollment(c, enrollment, "Invalid authentication code")
+			return
+		}
+	}
+	if err != nil {
+		c.HTML(http.StatusOK, "two-factor-result", gin.H{"Error": twoFactorErrorMessage(err)})
+		return
+	}
+
+	c.HTML(http.StatusOK, "two-factor-result", gin.H{
+		"Enabled":       true,
+		"RecoveryCodes": codes,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes.
+func (pc *ProfileController) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == 0 {
+		c.HTML(http.StatusOK, "two-factor-result", gin.H{"Error": "Not authenticated"})
+		return
+	}
+
+	codes, err := pc.authService.RegenerateRecoveryCodes(userID)
+	if err != nil {
+		c.HTML(http.StatusOK, "two-factor-result", gin.H{"Error": twoFactorErrorMessage(err)})
+		return
+	}
+	c.HTML(http.StatusOK, "two-factor-result", gin.H{"RecoveryCodes": codes})
+}
+
+// DisableTwoFactor turns off two-factor after confirming the user's password.
+func (pc *ProfileController) DisableTwoFactor(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == 0 {
+		c.HTML(http.StatusOK, "two-factor-result", gin.H{"Error": "Not authenticated"})
+		return
+	}
+
+	if err := pc.authService.DisableTOTP(userID, c.PostForm("password")); err != nil {
+		c.HTML(http.StatusOK, "two-factor-result", gin.H{"Error": twoFactorErrorMessage(err)})
+		return
+	}
+	c.HTML(http.StatusOK, "two-factor-result", gin.H{"Disabled": true})
+}
+
+func (pc *ProfileController) renderEnrollment(c *gin.Context, enrollment *auth.TOTPEnrollment, errMsg string) {
+	qrCode, err := auth.TOTPQRCode(enrollment.URI)
+	if err != nil {
+		log.Printf("Failed to render TOTP QR code: %v", err)
+	}
+	c.HTML(http.StatusOK, "two-factor-enroll", gin.H{
+		"Secret": enrollment.Secret,
+		"QRCode": qrCode,
+		"Error":  errMsg,
+	})
+}
+
+func twoFactorErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, auth.ErrInvalidPassword):
+		return "Password is incorrect"
+	case errors.Is(err, auth.ErrTOTPRequired):
+		return "Two-factor authentication is required for your account by an administrator"
+	case errors.Is(err, auth.ErrTOTPAlreadyEnabled), errors.Is(err, auth.ErrTOTPNotEnabled),
+		errors.Is(err, auth.ErrTOTPNotEnrolling), errors.Is(err, auth.ErrInvalidTOTPCode):
+		return err.Error()
+	default:
+		log.Printf("Two-factor error: %v", err)
+		return "Something went wrong, please try again"
+	}
+}
+
+// UsersController provides admin-only user management endpoints.
+type UsersController struct {
+	authService *auth.Service
+}
+
+// NewUsersController creates a new UsersController.
+func NewUsersController(authService *auth.Service) *UsersController {
+	return &UsersController{authService: authService}
+}
+
+// TwoFactorPolicyRequest is the request body for PUT /api/users/:id/2fa
+type TwoFactorPolicyRequest struct {
+	Required *bool `json:"required"`
+}
+
+// ListUsers returns all users with their two-factor status.
+// GET /api/users
+func (uc *UsersController) ListUsers(c *gin.Context) {
+	users, err := uc.authService.ListUsers()
+	if err != nil {
+		respondInternalError(c, err, "list users")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// SetTwoFactorPolicy requires or stops requiring two-factor for a user.
+// PUT /api/users/:id/2fa
+func (uc *UsersController) SetTwoFactorPolicy(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req TwoFactorPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Required == nil {
+		respondBadRequest(c, "required is a mandatory boolean")
+		return
+	}
+
+	if err := uc.authService.SetTOTPRequired(userID, *req.Required); err != nil {
+		uc.respondUserError(c, err, "set two-factor policy")
+		return
+	}
+	uc.respondUser(c, userID)
+}
+
+// ResetTwoFactor removes a user's two-factor enrollment, e.g. after a lost device.
+// DELETE /api/users/:id/2fa
+func (uc *UsersController) ResetTwoFactor(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := uc.authService.ResetTOTP(userID); err != nil {
+		uc.respondUserError(c, err, "reset two-factor")
+		return
+	}
+	uc.respondUser(c, userID)
+}
+
+func (uc *UsersController) respondUser(c *gin.Context, userID uint) {
+	user, err := uc.authService.GetUserByID(userID)
+	if err != nil {
+		uc.respondUserError(c, err, "get user")
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (uc *UsersController) respondUserError(c *gin.Context, err error, action string) {
+	if errors.Is(err, auth.ErrUserNotFound) {
+		respondNotFound(c, "user")
+		return
+	}
+	respondInternalError(c, err, action)
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrlokans/assistant/internal/auth"
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupUsersRouter(t *testing.T) (*gin.Engine, *auth.Service, func()) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dbPath := "./test_users_" + strings.ReplaceAll(t.Name(), "/", "_") + ".db"
+	db, err := database.NewDatabase(dbPath)
+	require.NoError(t, err)
+
+	service := auth.NewService(db.DB, config.Auth{BcryptCost: 4})
+	controller := NewUsersController(service)
+
+	router := gin.New()
+	router.GET("/api/users", controller.ListUsers)
+	router.PUT("/api/users/:id/2fa", controller.SetTwoFactorPolicy)
+	router.DELETE("/api/users/:id/2fa", controller.ResetTwoFactor)
+
+	cleanup := func() {
+		db.Close()
+		os.Remove(dbPath)
+	}
+	return router, service, cleanup
+}
+
+func TestUsersController_SetTwoFactorPolicy(t *testing.T) {
+	router, service, cleanup := setupUsersRouter(t)
+	defer cleanup()
+
+	user, err := service.CreateUser("reader", "reader@example.com", "password12345", entities.UserRoleViewer)
+	require.NoError(t, err)
+
+	path := fmt.Sprintf("/api/users/%d/2fa", user.ID)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(`{"required": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var updated entities.User
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.True(t, updated.TOTPRequired)
+	assert.False(t, updated.TOTPEnabled)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, path, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.True(t, updated.TOTPRequired, "reset keeps the policy")
+}
+
+func TestUsersController_Errors(t *testing.T) {
+	router, _, cleanup := setupUsersRouter(t)
+	defer cleanup()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"missing required", http.MethodPut, "/api/users/1/2fa", `{}`, http.StatusBadRequest},
+		{"invalid id", http.MethodPut, "/api/users/abc/2fa", `{"required": true}`, http.StatusBadRequest},
+		{"unknown user", http.MethodPut, "/api/users/999/2fa", `{"required": true}`, http.StatusNotFound},
+		{"reset unknown user", http.MethodDelete, "/api/users/999/2fa", ``, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+}
@@ -3,17 +3,20 @@ package http
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mrlokans/assistant/internal/dictionary"
 	"github.com/mrlokans/assistant/internal/entities"
 	"github.com/mrlokans/assistant/internal/tasks"
+	"github.com/mrlokans/assistant/internal/wordfreq"
 )
 
 // VocabularyStore defines database operations for vocabulary management.
 type VocabularyStore interface {
 	AddWord(word *entities.Word) error
 	GetAllWords(userID uint, limit, offset int) ([]entities.Word, int64, error)
+	ListWords(filter entities.WordFilter, limit, offset int) ([]entities.Word, int64, error)
 	GetWordByID(id uint) (*entities.Word, error)
 	UpdateWord(word *entities.Word) error
 	DeleteWord(id uint) error
@@ -26,14 +29,18 @@ type VocabularyStore interface {
 	SearchWords(query string, userID uint, limit int) ([]entities.Word, error)
 	GetVocabularyStats(userID uint) (total, pending, enriched, failed int64, err error)
 	GetWordsByStatus(userID uint, status entities.WordStatus, limit, offset int) ([]entities.Word, int64, error)
+	RequeueFailedWords(userID uint) ([]uint, error)
 	GetHighlightByID(id uint) (*entities.Highlight, error)
 	GetBookByID(id uint) (*entities.Book, error)
 }
 
 type VocabularyController struct {
-	store      VocabularyStore
-	dictClient dictionary.Client
-	taskClient *tasks.Client
+	store         VocabularyStore
+	dictClient    dictionary.Client
+	taskClient    *tasks.Client
+	reviewEnabled bool
+	mergeStore    VocabularyMergeStore
+	dictEnabled   func(provider string) bool
 }
 
 func NewVocabularyController(store VocabularyStore, dictClient dictionary.Client, taskClient *tasks.Client) *VocabularyController {
@@ -44,6 +51,26 @@ func NewVocabularyController(store VocabularyStore, dictClient dictionary.Client
 	}
 }
 
+// WithReview links the vocabulary page to the review session.
+func (vc *VocabularyController) WithReview(enabled bool) *VocabularyController {
+	vc.reviewEnabled = enabled
+	return vc
+}
+
+// WithMerging adds new forms of a saved word, such as "running" for "run", to
+// the saved word as another context instead of saving them separately.
+func (vc *VocabularyController) WithMerging(store VocabularyMergeStore) *VocabularyController {
+	vc.mergeStore = store
+	return vc
+}
+
+// WithDictionaryProviders reports which dictionary providers are turned on in
+// the provider listing.
+func (vc *VocabularyController) WithDictionaryProviders(enabled func(provider string) bool) *VocabularyController {
+	vc.dictEnabled = enabled
+	return vc
+}
+
 // AddWordRequest is the request body for adding a word.
 type AddWordRequest struct {
 	Word        string `json:"word" binding:"required"`
@@ -53,8 +80,9 @@ type AddWordRequest struct {
 	AutoEnrich  bool   `json:"auto_enrich,omitempty"`
 }
 
-// ListWords returns paginated vocabulary list.
-// GET /api/vocabulary
+// ListWords returns paginated vocabulary list, optionally filtered by status
+// and difficulty band and sorted by difficulty.
+// GET /api/vocabulary?status=&difficulty=easy|medium|hard&sort=newest|hardest|easiest
 func (vc *VocabularyController) ListWords(c *gin.Context) {
 	limit := 50
 	offset := 0
@@ -70,20 +98,12 @@ func (vc *VocabularyController) ListWords(c *gin.Context) {
 		}
 	}
 
-	// Filter by status if provided
-	statusFilter := c.Query("status")
-
-	var words []entities.Word
-	var total int64
-	var err error
-
-	if statusFilter != "" {
-		status := entities.WordStatus(statusFilter)
-		words, total, err = vc.store.GetWordsByStatus(DefaultUserID, status, limit, offset)
-	} else {
-		words, total, err = vc.store.GetAllWords(DefaultUserID, limit, offset)
+	filter, ok := parseWordFilter(c)
+	if !ok {
+		return
 	}
 
+	words, total, err := vc.store.ListWords(filter, limit, offset)
 	if err != nil {
 		respondInternalError(c, err, "list words")
 		return
@@ -109,6 +129,33 @@ func (vc *VocabularyController) ListWords(c *gin.Context) {
 	})
 }
 
+// parseWordFilter reads the status, difficulty band and sort of a vocabulary
+// listing, responding with an error if they are not valid
+func parseWordFilter(c *gin.Context) (entities.WordFilter, bool) {
+	filter := entities.WordFilter{
+		UserID: DefaultUserID,
+		Status: entities.WordStatus(c.Query("status")),
+		Sort:   entities.WordSort(c.Query("sort")),
+	}
+
+	if band := c.Query("difficulty"); band != "" {
+		low, high, ok := wordfreq.Band(band).Range()
+		if !ok {
+			respondBadRequest(c, "difficulty must be easy, medium or hard")
+			return filter, false
+		}
+		filter.MinDifficulty, filter.MaxDifficulty = low, high
+	}
+
+	switch filter.Sort {
+	case "", entities.WordSortNewest, entities.WordSortHardest, entities.WordSortEasiest:
+	default:
+		respondBadRequest(c, "sort must be newest, hardest or easiest")
+		return filter, false
+	}
+	return filter, true
+}
+
 // GetWordsList returns lightweight word list (word + status only).
 // GET /api/vocabulary/words
 func (vc *VocabularyController) GetWordsList(c *gin.Context) {
@@ -152,15 +199,7 @@ func (vc *VocabularyController) AddWord(c *gin.Context) {
 	if req.HighlightID != nil {
 		highlight, err := vc.store.GetHighlightByID(*req.HighlightID)
 		if err == nil {
-			word.HighlightID = req.HighlightID
-			word.SourceHighlightText = highlight.Text
-
-			book, _ := vc.store.GetBookByID(highlight.BookID)
-			if book != nil {
-				word.BookID = &book.ID
-				word.SourceBookTitle = book.Title
-				word.SourceBookAuthor = book.Author
-			}
+			vc.linkHighlight(word, highlight)
 		}
 	} else if req.BookID != nil {
 		book, err := vc.store.GetBookByID(*req.BookID)
@@ -171,6 +210,67 @@ func (vc *VocabularyController) AddWord(c *gin.Context) {
 		}
 	}
 
+	vc.saveWord(c, word, req.AutoEnrich)
+}
+
+// AddHighlightWordRequest is the request body for adding a word selected in a highlight.
+type AddHighlightWordRequest struct {
+	Word       string `json:"word" form:"word" binding:"required"`
+	AutoEnrich bool   `json:"auto_enrich,omitempty" form:"auto_enrich"`
+}
+
+// AddHighlightWord adds a word or short phrase selected in a highlight, with
+// the sentence it appears in as its context.
+// POST /api/highlights/:id/vocabulary
+func (vc *VocabularyController) AddHighlightWord(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req AddHighlightWordRequest
+	if err := c.ShouldBind(&req); err != nil || strings.TrimSpace(req.Word) == "" {
+		respondBadRequest(c, "word is required")
+		return
+	}
+
+	highlight, err := vc.store.GetHighlightByID(id)
+	if err != nil {
+		respondNotFound(c, "highlight")
+		return
+	}
+
+	word := &entities.Word{
+		Word:   strings.TrimSpace(req.Word),
+		Status: entities.WordStatusPending,
+	}
+	vc.linkHighlight(word, highlight)
+
+	vc.saveWord(c, word, req.AutoEnrich)
+}
+
+// linkHighlight links a word to the highlight it was found in and its book,
+// using the highlight's sentence as context unless one was given
+func (vc *VocabularyController) linkHighlight(word *entities.Word, highlight *entities.Highlight) {
+	highlightID := highlight.ID
+	word.HighlightID = &highlightID
+	word.SourceHighlightText = highlight.Text
+	if word.Context == "" {
+		word.Context = wordfreq.SentenceContaining(highlight.Text, word.Word)
+	}
+
+	book, _ := vc.store.GetBookByID(highlight.BookID)
+	if book != nil {
+		word.BookID = &book.ID
+		word.SourceBookTitle = book.Title
+		word.SourceBookAuthor = book.Author
+	}
+}
+
+// saveWord stores a new word unless the same word was already saved from the
+// same source, and responds with it. With merging, a word whose lemma is
+// already saved is added to that word as another context.
+func (vc *VocabularyController) saveWord(c *gin.Context, word *entities.Word, autoEnrich bool) {
 	// Check for duplicate
 	existing, _ := vc.store.FindWordBySource(word.Word, word.SourceBookTitle, word.SourceBookAuthor, word.SourceHighlightText, word.UserID)
 	if existing != nil {
@@ -178,13 +278,20 @@ func (vc *VocabularyController) AddWord(c *gin.Context) {
 		return
 	}
 
+	if vc.mergeStore != nil {
+		if sameLemma, _ := vc.mergeStore.FindWordByLemma(wordfreq.Lemma(word.Word), word.UserID); sameLemma != nil {
+			vc.addWordContext(c, sameLemma.ID, word)
+			return
+		}
+	}
+
 	if err := vc.store.AddWord(word); err != nil {
 		respondInternalError(c, err, "add word")
 		return
 	}
 
 	// Auto-enrich if requested and task queue available
-	if req.AutoEnrich && vc.taskClient != nil {
+	if autoEnrich && vc.taskClient != nil {
 		_, _ = vc.taskClient.Add(tasks.EnrichWordTask{WordID: word.ID}).Save()
 	}
 
@@ -196,6 +303,38 @@ func (vc *VocabularyController) AddWord(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"word": word})
 }
 
+// addWordContext records a word as another context of the saved word with
+// the same lemma, and responds with the saved word
+func (vc *VocabularyController) addWordContext(c *gin.Context, wordID uint, word *entities.Word) {
+	err := vc.mergeStore.AddWordContext(&entities.WordContext{
+		WordID:              wordID,
+		HighlightID:         word.HighlightID,
+		BookID:              word.BookID,
+		Form:                word.Word,
+		Context:             word.Context,
+		SourceBookTitle:     word.SourceBookTitle,
+		SourceBookAuthor:    word.SourceBookAuthor,
+		SourceHighlightText: word.SourceHighlightText,
+	})
+	if err != nil {
+		respondInternalError(c, err, "add word context")
+		return
+	}
+
+	merged, err := vc.store.GetWordByID(wordID)
+	if err != nil {
+		respondInternalError(c, err, "load word")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "word-card", merged)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"word": merged, "merged": true})
+}
+
 // GetWord returns a word with all definitions.
 // GET /api/vocabulary/:id
 func (vc *VocabularyController) GetWord(c *gin.Context) {
@@ -327,8 +466,12 @@ func (vc *VocabularyController) EnrichWord(c *gin.Context) {
 		return
 	}
 
-	// Synchronous enrichment if no task queue
-	result, err := vc.dictClient.Lookup(c.Request.Context(), word.Word)
+	// Synchronous enrichment if no task queue, in the language of the word's book
+	var language string
+	if word.Book != nil {
+		language = word.Book.Language
+	}
+	result, err := vc.dictClient.Lookup(c.Request.Context(), word.Word, language)
 	if err != nil {
 		_ = vc.store.UpdateWordStatus(id, entities.WordStatusFailed, err.Error())
 		respondInternalError(c, err, "dictionary lookup")
@@ -368,6 +511,107 @@ func (vc *VocabularyController) EnrichAllWords(c *gin.Context) {
 	respondAccepted(c, "batch enrichment task queued", nil)
 }
 
+// RetryFailedWords sets every failed word back to pending and queues its
+// enrichment, with a fresh set of attempts.
+// POST /api/vocabulary/retry-failed
+func (vc *VocabularyController) RetryFailedWords(c *gin.Context) {
+	if vc.taskClient == nil {
+		respondError(c, http.StatusServiceUnavailable, "task queue not available")
+		return
+	}
+
+	ids, err := vc.store.RequeueFailedWords(DefaultUserID)
+	if err != nil {
+		respondInternalError(c, err, "requeue failed words")
+		return
+	}
+
+	for _, id := range ids {
+		if _, err := vc.taskClient.Add(tasks.EnrichWordTask{WordID: id}).Save(); err != nil {
+			respondInternalError(c, err, "queue enrichment task")
+			return
+		}
+	}
+
+	respondAccepted(c, "failed words requeued", gin.H{"count": len(ids)})
+}
+
+// ListCandidates returns auto-extracted words awaiting confirmation.
+// GET /api/vocabulary/candidates
+func (vc *VocabularyController) ListCandidates(c *gin.Context) {
+	words, total, err := vc.store.GetWordsByStatus(DefaultUserID, entities.WordStatusCandidate, 100, 0)
+	if err != nil {
+		respondInternalError(c, err, "list vocabulary candidates")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "vocabulary-candidates", gin.H{
+			"Candidates":     words,
+			"CandidateCount": total,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"words": words,
+		"total": total,
+	})
+}
+
+// ConfirmWord accepts a candidate into the vocabulary and queues its enrichment.
+// POST /api/vocabulary/:id/confirm
+func (vc *VocabularyController) ConfirmWord(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	word, err := vc.store.GetWordByID(id)
+	if err != nil {
+		respondNotFound(c, "word")
+		return
+	}
+
+	if word.Status != entities.WordStatusCandidate {
+		respondBadRequest(c, "word is not a candidate")
+		return
+	}
+
+	if err := vc.store.UpdateWordStatus(id, entities.WordStatusPending, ""); err != nil {
+		respondInternalError(c, err, "confirm word")
+		return
+	}
+	word.Status = entities.WordStatusPending
+
+	if vc.taskClient != nil {
+		_, _ = vc.taskClient.Add(tasks.EnrichWordTask{WordID: id}).Save()
+	}
+
+	if isHTMXRequest(c) {
+		c.String(http.StatusOK, "")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"word": word})
+}
+
+// ExtractCandidates queues a scan of new highlights for vocabulary candidates.
+// POST /api/vocabulary/extract
+func (vc *VocabularyController) ExtractCandidates(c *gin.Context) {
+	if vc.taskClient == nil {
+		respondError(c, http.StatusServiceUnavailable, "task queue not available")
+		return
+	}
+
+	if _, err := vc.taskClient.Add(tasks.ExtractVocabularyTask{}).Save(); err != nil {
+		respondInternalError(c, err, "queue vocabulary extraction task")
+		return
+	}
+
+	respondAccepted(c, "vocabulary extraction task queued", nil)
+}
+
 // GetWordsByHighlight returns words for a specific highlight.
 // GET /api/highlights/:id/vocabulary
 func (vc *VocabularyController) GetWordsByHighlight(c *gin.Context) {
@@ -385,6 +629,59 @@ func (vc *VocabularyController) GetWordsByHighlight(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"words": words})
 }
 
+// GetWordsByBook returns the words saved from a book's highlights.
+// GET /api/books/:id/vocabulary
+func (vc *VocabularyController) GetWordsByBook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	words, err := vc.store.GetWordsByBook(id)
+	if err != nil {
+		respondInternalError(c, err, "get words by book")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		words = confirmedWords(words)
+		c.HTML(http.StatusOK, "vocabulary-list", gin.H{
+			"Words": words,
+			"Total": int64(len(words)),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"words": words})
+}
+
+// dictionaryProvider is a dictionary provider with its terms and status
+type dictionaryProvider struct {
+	dictionary.ProviderInfo
+	Enabled bool `json:"enabled"`
+	Active  bool `json:"active"` // Used for lookups
+}
+
+// ListDictionaries returns the dictionary providers with the attribution and
+// license their definitions must be shared under.
+// GET /api/vocabulary/dictionaries
+func (vc *VocabularyController) ListDictionaries(c *gin.Context) {
+	providers := make([]dictionaryProvider, 0, len(dictionary.Providers))
+	for _, name := range dictionary.Providers {
+		info, ok := dictionary.Info(name)
+		if !ok {
+			info = dictionary.ProviderInfo{Name: name, Label: name}
+		}
+		providers = append(providers, dictionaryProvider{
+			ProviderInfo: info,
+			Enabled:      vc.dictEnabled == nil || vc.dictEnabled(name),
+			Active:       vc.dictClient != nil && vc.dictClient.Name() == name,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"providers": providers})
+}
+
 // GetVocabularyStats returns vocabulary statistics.
 // GET /api/vocabulary/stats
 func (vc *VocabularyController) GetVocabularyStats(c *gin.Context) {
@@ -440,15 +737,22 @@ func (vc *VocabularyController) VocabularyPage(c *gin.Context) {
 	}
 
 	_, pending, enriched, failed, _ := vc.store.GetVocabularyStats(DefaultUserID)
+	candidates, candidateCount, _ := vc.store.GetWordsByStatus(DefaultUserID, entities.WordStatusCandidate, 100, 0)
 
 	c.HTML(http.StatusOK, "vocabulary", gin.H{
-		"Words":     words,
-		"Total":     total,
-		"Pending":   pending,
-		"Enriched":  enriched,
-		"Failed":    failed,
-		"Auth":      GetAuthTemplateData(c),
-		"Demo":      GetDemoTemplateData(c),
-		"Analytics": GetAnalyticsTemplateData(c),
+		"Words":          words,
+		"Total":          total,
+		"Pending":        pending,
+		"Enriched":       enriched,
+		"Failed":         failed,
+		"Candidates":     candidates,
+		"CandidateCount": candidateCount,
+		"CanExtract":     vc.taskClient != nil,
+		"CanReview":      vc.reviewEnabled,
+		"CanMerge":       vc.mergeStore != nil,
+		"Auth":           GetAuthTemplateData(c),
+		"UI":             GetUIPreferences(c),
+		"Demo":           GetDemoTemplateData(c),
+		"Analytics":      GetAnalyticsTemplateData(c),
 	})
 }
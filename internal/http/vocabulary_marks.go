@@ -0,0 +1,97 @@
+package http
+
+import (
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// confirmedWords leaves out auto-extracted candidates, which are reviewed on
+// the vocabulary page
+func confirmedWords(words []entities.Word) []entities.Word {
+	return slices.DeleteFunc(words, func(w entities.Word) bool {
+		return w.Status == entities.WordStatusCandidate
+	})
+}
+
+// markVocabulary returns the text of each highlight that contains one of the
+// words, HTML-escaped with the words wrapped in <mark>. Highlights without any
+// of the words are left out.
+func markVocabulary(highlights []entities.Highlight, words []entities.Word) map[uint]template.HTML {
+	pattern := vocabularyPattern(words)
+	if pattern == nil {
+		return nil
+	}
+
+	marked := make(map[uint]template.HTML)
+	for _, h := range highlights {
+		if html, ok := markWords(h.Text, pattern); ok {
+			marked[h.ID] = html
+		}
+	}
+	return marked
+}
+
+// vocabularyPattern matches any of the words, ignoring case, or is nil when
+// there are none
+func vocabularyPattern(words []entities.Word) *regexp.Regexp {
+	var alternatives []string
+	seen := make(map[string]bool)
+	for _, w := range words {
+		text := strings.ToLower(strings.TrimSpace(w.Word))
+		if text == "" || seen[text] {
+			continue
+		}
+		seen[text] = true
+		alternatives = append(alternatives, regexp.QuoteMeta(text))
+	}
+	if len(alternatives) == 0 {
+		return nil
+	}
+
+	// Longer words first, so a phrase wins over a word within it
+	slices.SortFunc(alternatives, func(a, b string) int { return len(b) - len(a) })
+	return regexp.MustCompile(`(?i)` + strings.Join(alternatives, "|"))
+}
+
+// markWords wraps whole-word matches of the pattern in text, reporting whether
+// there were any
+func markWords(text string, pattern *regexp.Regexp) (template.HTML, bool) {
+	var b strings.Builder
+	last := 0
+	for _, m := range pattern.FindAllStringIndex(text, -1) {
+		if !isWordBoundary(text, m[0], m[1]) {
+			continue
+		}
+		b.WriteString(template.HTMLEscapeString(text[last:m[0]]))
+		b.WriteString(`<mark class="vocabulary-mark">`)
+		b.WriteString(template.HTMLEscapeString(text[m[0]:m[1]]))
+		b.WriteString(`</mark>`)
+		last = m[1]
+	}
+	if last == 0 {
+		return "", false
+	}
+	b.WriteString(template.HTMLEscapeString(text[last:]))
+	return template.HTML(b.String()), true
+}
+
+// isWordBoundary reports whether text[start:end] is not part of a longer word
+func isWordBoundary(text string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(r) {
+		return false
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// VocabularyMergeStore defines database operations for consolidating vocabulary
+// words saved in several forms or from several books.
+type VocabularyMergeStore interface {
+	FindWordByLemma(lemma string, userID uint) (*entities.Word, error)
+	AddWordContext(wordContext *entities.WordContext) error
+	MergeWords(targetID uint, wordIDs []uint) (*entities.Word, error)
+	ConsolidateWords(userID uint) (int, error)
+}
+
+// VocabularyMergeController merges duplicate vocabulary words.
+type VocabularyMergeController struct {
+	store VocabularyMergeStore
+}
+
+func NewVocabularyMergeController(store VocabularyMergeStore) *VocabularyMergeController {
+	return &VocabularyMergeController{store: store}
+}
+
+// MergeWordsRequest lists the words to merge into the word of the URL.
+// Form submissions send a single word_id.
+type MergeWordsRequest struct {
+	WordIDs []uint `json:"word_ids" form:"word_id"`
+}
+
+// MergeWords merges other words into this one, e.g. the same word saved in
+// another form or from another book. Their contexts and sources are kept on
+// this word and the merged words are deleted.
+// POST /api/vocabulary/:id/merge
+func (mc *VocabularyMergeController) MergeWords(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req MergeWordsRequest
+	if err := c.ShouldBind(&req); err != nil || len(req.WordIDs) == 0 {
+		respondBadRequest(c, "word_ids is required")
+		return
+	}
+
+	word, err := mc.store.MergeWords(id, req.WordIDs)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondNotFound(c, "word")
+		return
+	case errors.Is(err, database.ErrMergeAcrossUsers):
+		respondBadRequest(c, err.Error())
+		return
+	case err != nil:
+		respondInternalError(c, err, "merge words")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		c.HTML(http.StatusOK, "word-card", word)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"word": word})
+}
+
+// ConsolidateWords merges every group of words sharing a lemma, such as
+// "run" and "running", into one word.
+// POST /api/vocabulary/consolidate
+func (mc *VocabularyMergeController) ConsolidateWords(c *gin.Context) {
+	merged, err := mc.store.ConsolidateWords(DefaultUserID)
+	if err != nil {
+		respondInternalError(c, err, "consolidate words")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"merged": merged})
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestVocabularyController_AddsNewFormsToSavedWord(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	first := &entities.Book{Title: "Born to Run", Highlights: []entities.Highlight{{Text: "Humans evolved to run."}}}
+	second := &entities.Book{Title: "Endurance", Highlights: []entities.Highlight{{Text: "They kept running for days."}}}
+	require.NoError(t, db.SaveBook(first))
+	require.NoError(t, db.SaveBook(second))
+
+	router := gin.New()
+	router.POST("/api/highlights/:id/vocabulary", NewVocabularyController(db, nil, nil).WithMerging(db).AddHighlightWord)
+
+	add := func(highlightID uint, word string) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("/api/highlights/%d/vocabulary", highlightID)
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(`{"word":"`+word+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := add(first.Highlights[0].ID, "run")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = add(second.Highlights[0].ID, "running")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Word   entities.Word `json:"word"`
+		Merged bool          `json:"merged"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Merged)
+	assert.Equal(t, "run", resp.Word.Word)
+	require.Len(t, resp.Word.Contexts, 1)
+	assert.Equal(t, "running", resp.Word.Contexts[0].Form)
+	assert.Equal(t, "They kept running for days.", resp.Word.Contexts[0].Context)
+	assert.Equal(t, "Endurance", resp.Word.Contexts[0].SourceBookTitle)
+
+	var count int64
+	require.NoError(t, db.DB.Model(&entities.Word{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestVocabularyMergeController(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	run := &entities.Word{Word: "run", Status: entities.WordStatusPending}
+	running := &entities.Word{Word: "running", Status: entities.WordStatusPending, SourceBookTitle: "Endurance"}
+	ran := &entities.Word{Word: "ran", Status: entities.WordStatusPending, SourceBookTitle: "Dune"}
+	for _, word := range []*entities.Word{run, running, ran} {
+		require.NoError(t, db.AddWord(word))
+	}
+
+	controller := NewVocabularyMergeController(db)
+	router := gin.New()
+	router.POST("/api/vocabulary/:id/merge", controller.MergeWords)
+	router.POST("/api/vocabulary/consolidate", controller.ConsolidateWords)
+
+	post := func(url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, post(fmt.Sprintf("/api/vocabulary/%d/merge", run.ID), `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, post("/api/vocabulary/999/merge", fmt.Sprintf(`{"word_ids":[%d]}`, running.ID)).Code)
+
+	w := post(fmt.Sprintf("/api/vocabulary/%d/merge", run.ID), fmt.Sprintf(`{"word_ids":[%d]}`, running.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Word entities.Word `json:"word"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Word.Contexts, 1)
+	assert.Equal(t, "Endurance", resp.Word.Contexts[0].SourceBookTitle)
+
+	w = post("/api/vocabulary/consolidate", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"merged":1}`, w.Body.String())
+
+	merged, err := db.GetWordByID(run.ID)
+	require.NoError(t, err)
+	assert.Len(t, merged.Contexts, 2)
+}
@@ -0,0 +1,179 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/srs"
+)
+
+// reviewSessionSize is the default number of words in a review session
+const reviewSessionSize = 20
+
+// VocabularyReviewStore defines database operations for reviewing vocabulary.
+type VocabularyReviewStore interface {
+	GetDueWords(filter entities.WordFilter, now time.Time, limit int) ([]entities.Word, int64, error)
+	GetWordByID(id uint) (*entities.Word, error)
+	SaveWordReview(word *entities.Word) error
+}
+
+// VocabularyReviewController practices vocabulary with spaced repetition: due
+// words are shown one at a time and each answer schedules the next review.
+type VocabularyReviewController struct {
+	store VocabularyReviewStore
+}
+
+func NewVocabularyReviewController(store VocabularyReviewStore) *VocabularyReviewController {
+	return &VocabularyReviewController{store: store}
+}
+
+// ReviewRequest is the request body for grading a review. The grade is again,
+// hard, good or easy, or SM-2's 0 to 5.
+type ReviewRequest struct {
+	Grade string `json:"grade" form:"grade"`
+}
+
+// reviewGrade is an answer button on a review card
+type reviewGrade struct {
+	Name  string
+	Label string
+	Grade srs.Grade
+	Days  int // Days until the next review with this answer
+}
+
+var reviewGrades = []reviewGrade{
+	{Name: "again", Label: "Again", Grade: srs.GradeAgain},
+	{Name: "hard", Label: "Hard", Grade: srs.GradeHard},
+	{Name: "good", Label: "Good", Grade: srs.GradeGood},
+	{Name: "easy", Label: "Easy", Grade: srs.GradeEasy},
+}
+
+// GetReviewSession returns the words due for review, overdue ones first and
+// then new words.
+// GET /api/vocabulary/review?difficulty=easy|medium|hard&limit=
+func (rc *VocabularyReviewController) GetReviewSession(c *gin.Context) {
+	filter, ok := parseWordFilter(c)
+	if !ok {
+		return
+	}
+
+	limit := reviewSessionSize
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	words, due, err := rc.store.GetDueWords(filter, time.Now(), limit)
+	if err != nil {
+		respondInternalError(c, err, "get due words")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"words": words,
+		"due":   due,
+	})
+}
+
+// ReviewWord grades a review of a word and schedules its next review for the
+// start of a later day in the user's timezone. HTMX requests get the next card.
+// POST /api/vocabulary/:id/review
+func (rc *VocabularyReviewController) ReviewWord(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ReviewRequest
+	_ = c.ShouldBind(&req)
+	grade, ok := srs.ParseGrade(req.Grade)
+	if !ok {
+		respondBadRequest(c, "grade must be again, hard, good, easy or 0 to 5")
+		return
+	}
+
+	word, err := rc.store.GetWordByID(id)
+	if err != nil {
+		respondNotFound(c, "word")
+		return
+	}
+	if word.Status == entities.WordStatusCandidate {
+		respondBadRequest(c, "word is not in the vocabulary yet")
+		return
+	}
+
+	prefs := GetUserPreferences(c)
+	now := prefs.Now()
+	next := srs.Next(reviewState(word), grade)
+	due := prefs.StartOfDay(now).AddDate(0, 0, next.Interval).UTC()
+	reviewedAt := now.UTC()
+
+	word.ReviewEase = next.Ease
+	word.ReviewInterval = next.Interval
+	word.ReviewRepetitions = next.Repetitions
+	word.ReviewDueAt = &due
+	word.ReviewedAt = &reviewedAt
+	if err := rc.store.SaveWordReview(word); err != nil {
+		respondInternalError(c, err, "save word review")
+		return
+	}
+
+	if isHTMXRequest(c) {
+		rc.renderCard(c, "review-card")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"word": word})
+}
+
+// ReviewPage renders a review session, one word at a time.
+// GET /vocabulary/review?difficulty=easy|medium|hard
+func (rc *VocabularyReviewController) ReviewPage(c *gin.Context) {
+	rc.renderCard(c, "vocabulary-review")
+}
+
+// renderCard renders the next due word with the given template
+func (rc *VocabularyReviewController) renderCard(c *gin.Context, name string) {
+	filter, ok := parseWordFilter(c)
+	if !ok {
+		return
+	}
+
+	words, due, err := rc.store.GetDueWords(filter, time.Now(), 1)
+	if err != nil {
+		respondInternalError(c, err, "get due words")
+		return
+	}
+
+	data := gin.H{
+		"Due":        due,
+		"Difficulty": c.Query("difficulty"),
+		"Auth":       GetAuthTemplateData(c),
+		"UI":         GetUIPreferences(c),
+		"Demo":       GetDemoTemplateData(c),
+		"Analytics":  GetAnalyticsTemplateData(c),
+	}
+	if len(words) > 0 {
+		word := &words[0]
+		state := reviewState(word)
+		grades := make([]reviewGrade, len(reviewGrades))
+		for i, g := range reviewGrades {
+			g.Days = srs.Next(state, g.Grade).Interval
+			grades[i] = g
+		}
+		data["Word"] = word
+		data["Grades"] = grades
+	}
+	c.HTML(http.StatusOK, name, data)
+}
+
+func reviewState(word *entities.Word) srs.State {
+	return srs.State{
+		Ease:        word.ReviewEase,
+		Interval:    word.ReviewInterval,
+		Repetitions: word.ReviewRepetitions,
+	}
+}
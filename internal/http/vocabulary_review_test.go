@@ -0,0 +1,66 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestVocabularyReviewController_ReviewWord(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	word := &entities.Word{Word: "lagniappe", Status: entities.WordStatusEnriched}
+	require.NoError(t, db.AddWord(word))
+
+	controller := NewVocabularyReviewController(db)
+	router := gin.New()
+	router.GET("/api/vocabulary/review", controller.GetReviewSession)
+	router.POST("/api/vocabulary/:id/review", controller.ReviewWord)
+
+	session := func() int64 {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/vocabulary/review?difficulty=hard", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Due int64 `json:"due"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Due
+	}
+	review := func(grade string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/vocabulary/%d/review", word.ID), strings.NewReader(`{"grade":"`+grade+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, int64(1), session(), "a word never reviewed is due")
+
+	assert.Equal(t, http.StatusBadRequest, review("perfect").Code)
+
+	w := review("good")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var reviewed struct {
+		Word entities.Word `json:"word"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reviewed))
+	assert.Equal(t, 1, reviewed.Word.ReviewInterval)
+	assert.Equal(t, 1, reviewed.Word.ReviewRepetitions)
+	require.NotNil(t, reviewed.Word.ReviewDueAt)
+	assert.True(t, reviewed.Word.ReviewDueAt.After(time.Now()))
+	assert.True(t, reviewed.Word.ReviewDueAt.Before(time.Now().Add(48*time.Hour)))
+
+	assert.Zero(t, session(), "reviewed words wait until they are due")
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/dictionary"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/tasks"
+)
+
+func TestVocabularyController_AddHighlightWord(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	book := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Highlights: []entities.Highlight{
+		{Text: "Everything is ephemeral. Both that which remembers and that which is remembered."},
+	}}
+	require.NoError(t, db.SaveBook(book))
+
+	controller := NewVocabularyController(db, nil, nil)
+	router := gin.New()
+	router.POST("/api/highlights/:id/vocabulary", controller.AddHighlightWord)
+	router.GET("/api/books/:id/vocabulary", controller.GetWordsByBook)
+
+	add := func(word string) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("/api/highlights/%d/vocabulary", book.Highlights[0].ID)
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(`{"word":"`+word+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := add(" Ephemeral ")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Word entities.Word `json:"word"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "Ephemeral", created.Word.Word)
+	assert.Equal(t, "Everything is ephemeral.", created.Word.Context)
+	assert.Equal(t, "Meditations", created.Word.SourceBookTitle)
+	require.NotNil(t, created.Word.BookID)
+	assert.Equal(t, book.ID, *created.Word.BookID)
+
+	assert.Equal(t, http.StatusConflict, add("Ephemeral").Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/books/%d/vocabulary", book.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed struct {
+		Words []entities.Word `json:"words"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Words, 1)
+	assert.Equal(t, "Ephemeral", listed.Words[0].Word)
+}
+
+func TestMarkVocabulary(t *testing.T) {
+	highlights := []entities.Highlight{
+		{ID: 1, Text: "An ephemeral <joy>, ephemerality aside. Ephemeral indeed"},
+		{ID: 2, Text: "Nothing to see here"},
+		{ID: 3, Text: "The sine qua non of it"},
+	}
+	words := []entities.Word{{Word: "ephemeral"}, {Word: "sine qua non"}, {Word: "sine"}}
+
+	marked := markVocabulary(highlights, words)
+
+	assert.Equal(t, `An <mark class="vocabulary-mark">ephemeral</mark> &lt;joy&gt;, ephemerality aside. <mark class="vocabulary-mark">Ephemeral</mark> indeed`, string(marked[1]))
+	assert.NotContains(t, marked, uint(2))
+	assert.Equal(t, `The <mark class="vocabulary-mark">sine qua non</mark> of it`, string(marked[3]))
+	assert.Nil(t, markVocabulary(highlights, nil))
+}
+
+func TestVocabularyController_ListWordsByDifficulty(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	for _, w := range []string{"garden", "lagniappe", "pusillanimous"} {
+		require.NoError(t, db.AddWord(&entities.Word{Word: w, Status: entities.WordStatusPending}))
+	}
+
+	router := gin.New()
+	router.GET("/api/vocabulary", NewVocabularyController(db, nil, nil).ListWords)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/vocabulary?difficulty=hard&sort=hardest", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var listed struct {
+		Words []entities.Word `json:"words"`
+		Total int64           `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, int64(2), listed.Total)
+	require.Len(t, listed.Words, 2)
+	assert.Equal(t, "pusillanimous", listed.Words[0].Word)
+
+	for _, query := range []string{"difficulty=impossible", "sort=alphabetical"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/vocabulary?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestVocabularyController_RetryFailedWords(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	word := &entities.Word{Word: "lagniappe", UserID: DefaultUserID, Status: entities.WordStatusPending}
+	require.NoError(t, db.AddWord(word))
+	require.NoError(t, db.RecordEnrichmentFailure(word.ID, 4, entities.WordStatusFailed, "unexpected status: 503"))
+
+	w := httptest.NewRecorder()
+	router := gin.New()
+	router.POST("/api/vocabulary/retry-failed", NewVocabularyController(db, nil, nil).RetryFailedWords)
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/vocabulary/retry-failed", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "needs the task queue")
+
+	taskClient, err := tasks.NewClient(filepath.Join(t.TempDir(), "test.db"), tasks.DefaultConfig())
+	require.NoError(t, err)
+	defer taskClient.Close()
+
+	w = httptest.NewRecorder()
+	router = gin.New()
+	router.POST("/api/vocabulary/retry-failed", NewVocabularyController(db, nil, taskClient).RetryFailedWords)
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/vocabulary/retry-failed", nil))
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"count":1`)
+
+	retrieved, err := db.GetWordByID(word.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.WordStatusPending, retrieved.Status)
+	assert.Zero(t, retrieved.EnrichmentAttempts)
+}
+
+func TestVocabularyController_ListDictionaries(t *testing.T) {
+	controller := NewVocabularyController(nil, dictionary.NewFreeDictionaryClient(), nil).
+		WithDictionaryProviders(func(provider string) bool { return false })
+	router := gin.New()
+	router.GET("/api/vocabulary/dictionaries", controller.ListDictionaries)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/vocabulary/dictionaries", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Providers []struct {
+			Name        string   `json:"name"`
+			Attribution string   `json:"attribution"`
+			License     string   `json:"license"`
+			Languages   []string `json:"languages"`
+			Enabled     bool     `json:"enabled"`
+			Active      bool     `json:"active"`
+		} `json:"providers"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Providers, 1)
+	assert.Equal(t, "freedictionary", resp.Providers[0].Name)
+	assert.Equal(t, "CC BY-SA 3.0", resp.Providers[0].License)
+	assert.Equal(t, []string{"en"}, resp.Providers[0].Languages)
+	assert.NotEmpty(t, resp.Providers[0].Attribution)
+	assert.False(t, resp.Providers[0].Enabled)
+	assert.True(t, resp.Providers[0].Active)
+}
+
+func TestVocabularyController_GetWordShowsAttribution(t *testing.T) {
+	db, _, cleanup := setupBooksTestDB(t)
+	defer cleanup()
+
+	word := &entities.Word{Word: "ephemeral", Status: entities.WordStatusEnriched}
+	require.NoError(t, db.AddWord(word))
+	require.NoError(t, db.SaveDefinitions(word.ID, []entities.WordDefinition{{
+		PartOfSpeech: "adjective",
+		Definition:   "Lasting for a short period of time",
+		Source:       "freedictionary",
+		Attribution:  "Wiktionary contributors, via the Free Dictionary API",
+		License:      "CC BY-SA 3.0",
+		LicenseURL:   "https://creativecommons.org/licenses/by-sa/3.0",
+		SourceURL:    "https://en.wiktionary.org/wiki/ephemeral",
+	}}))
+
+	renderer, err := newLocalizedHTML("../../templates/*.html", templateFuncs(NewStaticAssets("../../static"), ""))
+	require.NoError(t, err)
+	router := gin.New()
+	router.HTMLRender = renderer
+	router.GET("/api/vocabulary/:id", NewVocabularyController(db, nil, nil).GetWord)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/vocabulary/%d", word.ID), nil)
+	req.Header.Set("HX-Request", "true")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `<a href="https://en.wiktionary.org/wiki/ephemeral" target="_blank" rel="noopener">Wiktionary contributors, via the Free Dictionary API</a>`)
+	assert.Contains(t, w.Body.String(), `>CC BY-SA 3.0</a>`)
+}
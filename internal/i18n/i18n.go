@@ -0,0 +1,261 @@
+// Package i18n translates the web UI and export indexes.
+//
+// Messages live in JSON catalogs embedded from the locales directory, one
+// per language, keyed by dotted names such as "nav.books". A message is
+// either a string or, when it depends on a count, an object of plural forms
+// ("one" and "other", plus "few" and "many" for Russian). Messages are
+// fmt format strings; plural messages get the count as their first argument.
+// Missing messages fall back to English, then to the key itself.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/localtime"
+)
+
+// DefaultLanguage is used when neither the user nor the browser asks for a
+// supported language
+const DefaultLanguage = "en"
+
+// ErrUnsupportedLanguage is returned for languages without a catalog
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// Language is a language the UI is translated into
+type Language struct {
+	Code string // ISO 639-1 code such as "de"
+	Name string // Native name, shown in language pickers
+}
+
+// Languages lists the translations, English first
+var Languages = []Language{
+	{Code: "en", Name: "English"},
+	{Code: "de", Name: "Deutsch"},
+	{Code: "ru", Name: "Русский"},
+}
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalog maps message keys to their plural forms; plain messages only have
+// the "other" form
+type catalog map[string]map[string]string
+
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[string]catalog {
+	catalogs := make(map[string]catalog, len(Languages))
+	for _, lang := range Languages {
+		data, err := localeFiles.ReadFile(path.Join("locales", lang.Code+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: read %s catalog: %v", lang.Code, err))
+		}
+		cat, err := parseCatalog(data)
+		if err != nil {
+			panic(fmt.Sprintf("i18n: parse %s catalog: %v", lang.Code, err))
+		}
+		catalogs[lang.Code] = cat
+	}
+	return catalogs
+}
+
+func parseCatalog(data []byte) (catalog, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	cat := make(catalog, len(raw))
+	for key, value := range raw {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			cat[key] = map[string]string{"other": text}
+			continue
+		}
+		var forms map[string]string
+		if err := json.Unmarshal(value, &forms); err != nil {
+			return nil, fmt.Errorf("message %q is neither a string nor plural forms", key)
+		}
+		if forms["other"] == "" && forms["many"] == "" {
+			return nil, fmt.Errorf("message %q has no other form", key)
+		}
+		cat[key] = forms
+	}
+	return cat, nil
+}
+
+// Codes returns the codes of the supported languages
+func Codes() []string {
+	codes := make([]string, len(Languages))
+	for i, lang := range Languages {
+		codes[i] = lang.Code
+	}
+	return codes
+}
+
+// Supported reports whether the UI is translated into a language
+func Supported(code string) bool {
+	_, ok := catalogs[code]
+	return ok
+}
+
+// Validate checks that code is a supported language or empty
+func Validate(code string) error {
+	if code == "" || Supported(code) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedLanguage, code)
+}
+
+// Has reports whether the English catalog, which all others fall back to,
+// has a message
+func Has(key string) bool {
+	_, ok := catalogs[DefaultLanguage][key]
+	return ok
+}
+
+// Match returns the supported language of a language tag such as "de-AT",
+// or "" when there is none
+func Match(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if Supported(tag) {
+		return tag
+	}
+	return ""
+}
+
+// MatchAcceptLanguage returns the supported language the browser prefers
+// most according to an Accept-Language header, or "" when there is none
+func MatchAcceptLanguage(header string) string {
+	type candidate struct {
+		lang    string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if lang := Match(tag); lang != "" && quality > 0 {
+			candidates = append(candidates, candidate{lang, quality})
+		}
+	}
+
+	// Stable, so equally weighted languages keep the browser's order
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[0].lang
+}
+
+// Translator looks up the messages of one language
+type Translator struct {
+	lang     string
+	messages catalog
+}
+
+// For returns the translator of a language, English for unsupported ones
+func For(lang string) *Translator {
+	if !Supported(lang) {
+		lang = DefaultLanguage
+	}
+	return &Translator{lang: lang, messages: catalogs[lang]}
+}
+
+// Language returns the code of the translator's language
+func (t *Translator) Language() string {
+	return t.lang
+}
+
+// T returns a message formatted with args
+func (t *Translator) T(key string, args ...any) string {
+	return t.format(t.form(key, "other"), key, args)
+}
+
+// N returns the plural form of a message for n, formatted with n followed by
+// args
+func (t *Translator) N(key string, n int, args ...any) string {
+	return t.format(t.form(key, pluralForm(t.lang, n)), key, append([]any{n}, args...))
+}
+
+// FormatDate formats the date of t the way the language writes it
+func (t *Translator) FormatDate(date time.Time) string {
+	return localtime.Preferences{Locale: t.lang}.FormatDate(date)
+}
+
+// FormatDateTime formats the date and time of t the way the language writes it
+func (t *Translator) FormatDateTime(date time.Time) string {
+	return localtime.Preferences{Locale: t.lang}.FormatDateTime(date)
+}
+
+func (t *Translator) form(key, form string) string {
+	for _, messages := range []catalog{t.messages, catalogs[DefaultLanguage]} {
+		forms, ok := messages[key]
+		if !ok {
+			continue
+		}
+		if text, ok := forms[form]; ok {
+			return text
+		}
+		// English only has "one" and "other"
+		if text, ok := forms["other"]; ok {
+			return text
+		}
+		return forms["many"]
+	}
+	return ""
+}
+
+func (t *Translator) format(text, key string, args []any) string {
+	if text == "" {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// pluralForm returns the CLDR plural category of n in a language
+func pluralForm(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case "ru":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
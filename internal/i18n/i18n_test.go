@@ -0,0 +1,90 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogsAreComplete(t *testing.T) {
+	english := catalogs[DefaultLanguage]
+	for _, lang := range Languages {
+		cat, ok := catalogs[lang.Code]
+		require.True(t, ok, lang.Code)
+		for key, forms := range english {
+			translated, ok := cat[key]
+			if !assert.True(t, ok, "%s is missing %s", lang.Code, key) {
+				continue
+			}
+			if len(forms) > 1 {
+				for _, form := range pluralForms(lang.Code) {
+					assert.Contains(t, translated, form, "%s %s", lang.Code, key)
+				}
+			}
+		}
+		for key := range cat {
+			assert.Contains(t, english, key, "%s has %s, which English does not", lang.Code, key)
+		}
+	}
+}
+
+func pluralForms(lang string) []string {
+	if lang == "ru" {
+		return []string{"one", "few", "many"}
+	}
+	return []string{"one", "other"}
+}
+
+func TestTranslator(t *testing.T) {
+	en, de, ru := For("en"), For("de"), For("ru")
+
+	assert.Equal(t, "Books", en.T("nav.books"))
+	assert.Equal(t, "Bücher", de.T("nav.books"))
+	assert.Equal(t, "Книги", ru.T("nav.books"))
+	assert.Equal(t, "Delete tag 'stoic'? This will remove it from all books and highlights.", en.T("books.delete_tag_confirm", "stoic"))
+
+	assert.Equal(t, "1 highlight", en.N("common.highlights", 1))
+	assert.Equal(t, "0 highlights", en.N("common.highlights", 0))
+	assert.Equal(t, "1 Buch", de.N("common.books", 1))
+	assert.Equal(t, "5 Bücher", de.N("common.books", 5))
+	assert.Equal(t, "21 книга", ru.N("common.books", 21))
+	assert.Equal(t, "3 книги", ru.N("common.books", 3))
+	assert.Equal(t, "12 книг", ru.N("common.books", 12))
+	assert.Equal(t, "25 книг", ru.N("common.books", 25))
+
+	// Unknown messages show their key
+	assert.Equal(t, "nav.unknown", de.T("nav.unknown"))
+
+	// Unsupported languages get English
+	assert.Equal(t, "en", For("fr").Language())
+	assert.Equal(t, "Books", For("").T("nav.books"))
+}
+
+func TestTranslator_FormatDate(t *testing.T) {
+	date := time.Date(2024, 3, 9, 14, 5, 0, 0, time.UTC)
+	assert.Equal(t, "Mar 9, 2024", For("en").FormatDate(date))
+	assert.Equal(t, "09.03.2024", For("de").FormatDate(date))
+	assert.Equal(t, "09.03.2024 14:05", For("ru").FormatDateTime(date))
+}
+
+func TestMatch(t *testing.T) {
+	assert.Equal(t, "de", Match("de-AT"))
+	assert.Equal(t, "ru", Match(" RU_ru "))
+	assert.Equal(t, "", Match("fr"))
+	assert.Equal(t, "", Match(""))
+
+	assert.Equal(t, "de", MatchAcceptLanguage("fr-FR,fr;q=0.9,de;q=0.8,en;q=0.7"))
+	assert.Equal(t, "ru", MatchAcceptLanguage("en;q=0.5, ru"))
+	assert.Equal(t, "en", MatchAcceptLanguage("en-US,de"))
+	assert.Equal(t, "", MatchAcceptLanguage("fr-FR,de;q=0"))
+	assert.Equal(t, "", MatchAcceptLanguage(""))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(""))
+	assert.NoError(t, Validate("ru"))
+	assert.ErrorIs(t, Validate("fr"), ErrUnsupportedLanguage)
+	assert.Equal(t, []string{"en", "de", "ru"}, Codes())
+}
@@ -55,11 +55,32 @@
 //
 //   - ReadwiseConverter: Readwise API JSON format
 //   - ReadwiseCSVConverter: Readwise CSV export format
+//   - ReadwiseMarkdownConverter: Readwise markdown export, a file per book
 //   - MoonReaderConverter: Moon+ Reader JSON format
+//   - KindleNotebookConverter: Kindle app notebook HTML export
 //
-// For sources that already provide book-level grouping (like Kindle or Apple Books),
+// For sources that already provide book-level grouping (like Kindle clippings or Apple Books),
 // use Pipeline.ImportBooks() directly instead of implementing a Converter.
 //
+// # Streaming Imports
+//
+// Very large sources (such as a My Clippings.txt collected over years) are imported with
+// StreamPipeline, which saves books in batches, each within one transaction, instead of
+// holding every highlight in memory. The exporter writes files once the whole stream is
+// saved, because a book can be spread over several batches:
+//
+//	stream := importers.KindleClippingsStream(file, kindle.DefaultBatchSize, time.UTC)
+//	result, err := importers.NewStreamPipeline(batchExporter).Import(stream)
+//
+// # Multi-file Imports
+//
+// A Readwise "Full Library" export is a zip of markdown and CSV files. ParseReadwiseLibrary
+// routes each file through its converter and keeps per-file results, and the books of all
+// files are exported together, so the whole archive is recorded as one import session:
+//
+//	library, err := importers.ParseReadwiseLibrary(file, size)
+//	result, err := exporter.ExportFiles(library.Books, library.Files)
+//
 // # Example Usage
 //
 //	pipeline := importers.NewPipeline(exporter)
@@ -0,0 +1,90 @@
+package importers
+
+import (
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/kindle"
+)
+
+// KindleNotebookConverter converts a Kindle app "Notebook export" to the common format.
+type KindleNotebookConverter struct {
+	Notebook *kindle.Notebook
+}
+
+// NewKindleNotebookConverter creates a converter for a parsed Kindle notebook export.
+func NewKindleNotebookConverter(notebook *kindle.Notebook) *KindleNotebookConverter {
+	return &KindleNotebookConverter{Notebook: notebook}
+}
+
+// Convert implements Converter interface.
+// Notes follow the highlight they annotate in the export, so a note is attached
+// to the preceding highlight at the same position. Notes without a matching
+// highlight become note-only entries. Bookmarks carry no text and are skipped.
+func (c *KindleNotebookConverter) Convert() ([]RawHighlight, Source) {
+	source := Source{Name: "kindle"}
+	if c.Notebook == nil {
+		return nil, source
+	}
+
+	highlights := make([]RawHighlight, 0, len(c.Notebook.Entries))
+	var last *kindle.NotebookEntry
+
+	for i := range c.Notebook.Entries {
+		entry := &c.Notebook.Entries[i]
+
+		switch entry.Type {
+		case kindle.EntryTypeHighlight:
+			highlights = append(highlights, c.toRawHighlight(*entry))
+			last = entry
+
+		case kindle.EntryTypeNote:
+			if last != nil && samePosition(*last, *entry) && highlights[len(highlights)-1].Note == "" {
+				highlights[len(highlights)-1].Note = entry.Text
+				continue
+			}
+			h := c.toRawHighlight(*entry)
+			h.Text = ""
+			h.Note = entry.Text
+			h.Color = ""
+			h.Style = entities.HighlightStyleNoteOnly
+			highlights = append(highlights, h)
+			last = nil
+		}
+	}
+
+	return highlights, source
+}
+
+func (c *KindleNotebookConverter) toRawHighlight(entry kindle.NotebookEntry) RawHighlight {
+	h := RawHighlight{
+		BookTitle:    c.Notebook.Title,
+		BookAuthor:   c.Notebook.Author,
+		Text:         entry.Text,
+		Page:         entry.Page,
+		Chapter:      entry.Chapter,
+		Color:        normalizeColor(entry.Color),
+		Style:        entities.HighlightStyleHighlight,
+		ExternalID:   c.Notebook.EntryID(entry),
+		LocationType: entities.LocationTypeNone,
+	}
+
+	// Prefer location over page for Kindle
+	if entry.Location > 0 {
+		h.LocationType = entities.LocationTypeLocation
+		h.LocationValue = entry.Location
+	} else if entry.Page > 0 {
+		h.LocationType = entities.LocationTypePage
+		h.LocationValue = entry.Page
+	}
+
+	return h
+}
+
+func samePosition(a, b kindle.NotebookEntry) bool {
+	if a.Location > 0 || b.Location > 0 {
+		return a.Location == b.Location
+	}
+	return a.Page == b.Page
+}
+
+// Compile-time interface check
+var _ Converter = (*KindleNotebookConverter)(nil)
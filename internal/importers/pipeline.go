@@ -11,6 +11,8 @@ import (
 type RawHighlight struct {
 	BookTitle     string
 	BookAuthor    string
+	BookURL       string // Article address, for web sources
+	BookASIN      string // Amazon identifier of a Kindle book, used to find its cover
 	Text          string
 	Note          string
 	Page          int
@@ -41,7 +43,9 @@ type Source struct {
 // Implementations:
 //   - ReadwiseConverter (readwise.go) - Readwise API JSON format
 //   - ReadwiseCSVConverter (readwise_csv.go) - Readwise CSV export format
+//   - ReadwiseMarkdownConverter (readwise_markdown.go) - Readwise markdown export, a file per book
 //   - MoonReaderConverter (moonreader.go) - Moon+ Reader JSON format
+//   - KindleNotebookConverter (kindle_notebook.go) - Kindle app notebook HTML export
 //
 // Adding a new import source:
 //  1. Create a new file (e.g., kobo.go)
@@ -107,6 +111,16 @@ func (p *Pipeline) ImportBooks(books []entities.Book) (services.ImportResult, er
 	return services.ImportResult(exportResult), nil
 }
 
+// ConvertToBooks runs a converter and groups its highlights by book without exporting.
+// Use this when the caller exports through its own exporter.
+func ConvertToBooks(converter Converter) []entities.Book {
+	highlights, source := converter.Convert()
+	if len(highlights) == 0 {
+		return nil
+	}
+	return groupHighlightsByBook(highlights, source)
+}
+
 // groupHighlightsByBook groups raw highlights by book (title + author).
 func groupHighlightsByBook(highlights []RawHighlight, source Source) []entities.Book {
 	bookMap := make(map[string]*entities.Book)
@@ -124,6 +138,12 @@ func groupHighlightsByBook(highlights []RawHighlight, source Source) []entities.
 			}
 			bookMap[key] = book
 		}
+		if book.URL == "" {
+			book.URL = h.BookURL
+		}
+		if book.ASIN == "" {
+			book.ASIN = h.BookASIN
+		}
 
 		highlight := entities.Highlight{
 			Text:          h.Text,
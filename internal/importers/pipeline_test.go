@@ -1,9 +1,13 @@
 package importers
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/kindle"
 	"github.com/mrlokans/assistant/internal/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -181,3 +185,109 @@ func TestRawHighlight_GroupKey(t *testing.T) {
 	assert.Equal(t, h1.GroupKey(), h2.GroupKey())
 	assert.NotEqual(t, h1.GroupKey(), h3.GroupKey())
 }
+
+func TestKindleNotebookConverter(t *testing.T) {
+	notebook := &kindle.Notebook{
+		Title:  "Test Book",
+		Author: "Test Author",
+		Entries: []kindle.NotebookEntry{
+			{Type: kindle.EntryTypeHighlight, Color: "yellow", Chapter: "Chapter 1", Page: 3, Location: 105, Text: "Highlighted text"},
+			{Type: kindle.EntryTypeNote, Chapter: "Chapter 1", Page: 3, Location: 105, Text: "My note"},
+			{Type: kindle.EntryTypeBookmark, Location: 200},
+			{Type: kindle.EntryTypeNote, Chapter: "Chapter 2", Location: 260, Text: "Standalone note"},
+		},
+	}
+
+	converter := NewKindleNotebookConverter(notebook)
+	result, source := converter.Convert()
+
+	require.Len(t, result, 2)
+	assert.Equal(t, "kindle", source.Name)
+	assert.Equal(t, "Test Book", result[0].BookTitle)
+	assert.Equal(t, "Test Author", result[0].BookAuthor)
+	assert.Equal(t, "Highlighted text", result[0].Text)
+	assert.Equal(t, "My note", result[0].Note)
+	assert.Equal(t, "#FFFF00", result[0].Color)
+	assert.Equal(t, "Chapter 1", result[0].Chapter)
+	assert.Equal(t, entities.LocationTypeLocation, result[0].LocationType)
+	assert.Equal(t, 105, result[0].LocationValue)
+
+	assert.Equal(t, entities.HighlightStyleNoteOnly, result[1].Style)
+	assert.Equal(t, "Standalone note", result[1].Note)
+	assert.NotEqual(t, result[0].ExternalID, result[1].ExternalID)
+}
+
+type mockBatchExporter struct {
+	batches  [][]entities.Book
+	exported []uint
+	nextID   uint
+	ids      map[string]uint
+	failAt   int
+}
+
+func (m *mockBatchExporter) SaveBatch(books []entities.Book) (exporters.ExportResult, error) {
+	m.batches = append(m.batches, books)
+	if m.failAt > 0 && len(m.batches) == m.failAt {
+		return exporters.ExportResult{BooksFailed: len(books)}, errors.New("disk full")
+	}
+
+	result := exporters.ExportResult{BooksProcessed: len(books)}
+	for i := range books {
+		key := books[i].Title + "|" + books[i].Author
+		if m.ids[key] == 0 {
+			m.nextID++
+			m.ids[key] = m.nextID
+		}
+		books[i].ID = m.ids[key]
+		result.HighlightsProcessed += len(books[i].Highlights)
+	}
+	return result, nil
+}
+
+func (m *mockBatchExporter) ExportSaved(bookIDs []uint) error {
+	m.exported = bookIDs
+	return nil
+}
+
+const streamClippings = `Book A (Author 1)
+- Your Highlight at location 10-11 | Added on Saturday, 26 March 2016 14:59:39
+
+First
+==========
+Book B (Author 2)
+- Your Highlight at location 20-21 | Added on Saturday, 26 March 2016 15:00:00
+
+Second
+==========
+Book A (Author 1)
+- Your Highlight at location 30-31 | Added on Saturday, 26 March 2016 15:01:00
+
+Third
+==========
+`
+
+func TestStreamPipeline_Import(t *testing.T) {
+	exporter := &mockBatchExporter{ids: map[string]uint{}}
+
+	stream := KindleClippingsStream(strings.NewReader(streamClippings), 2, nil)
+	result, err := NewStreamPipeline(exporter).Import(stream)
+
+	require.NoError(t, err)
+	assert.Len(t, exporter.batches, 2)
+	assert.Equal(t, 2, result.BooksProcessed, "Book A spans both batches but is counted once")
+	assert.Equal(t, 3, result.HighlightsProcessed)
+	assert.Equal(t, []uint{1, 2}, exporter.exported)
+}
+
+func TestStreamPipeline_Import_BatchError(t *testing.T) {
+	exporter := &mockBatchExporter{ids: map[string]uint{}, failAt: 2}
+
+	stream := KindleClippingsStream(strings.NewReader(streamClippings), 2, nil)
+	result, err := NewStreamPipeline(exporter).Import(stream)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disk full")
+	assert.Equal(t, 2, result.BooksProcessed, "the first batch stays saved")
+	assert.Equal(t, 1, result.BooksFailed)
+	assert.Nil(t, exporter.exported)
+}
@@ -44,6 +44,7 @@ func (c *ReadwiseCSVConverter) Convert() ([]RawHighlight, Source) {
 		h := RawHighlight{
 			BookTitle:  row.BookTitle,
 			BookAuthor: row.BookAuthor,
+			BookASIN:   row.AmazonBookID,
 			Text:       row.Highlight,
 			Note:       row.Note,
 			Color:      normalizeColor(row.Color),
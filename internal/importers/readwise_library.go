@@ -0,0 +1,137 @@
+package importers
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// MaxReadwiseLibraryFileSize caps each file unpacked from a Readwise library
+// export, so a crafted archive cannot exhaust memory.
+const MaxReadwiseLibraryFileSize = 50 * 1024 * 1024 // 50 MB
+
+// Formats of the files of a Readwise library export
+const (
+	ReadwiseFormatMarkdown = "markdown"
+	ReadwiseFormatCSV      = "csv"
+)
+
+// ErrNoReadwiseFiles is returned for archives without markdown or CSV files.
+var ErrNoReadwiseFiles = errors.New("no markdown or CSV files found in the archive")
+
+// ReadwiseLibrary is a Readwise full library export: the books of every
+// markdown and CSV file of the archive, and what each file yielded.
+type ReadwiseLibrary struct {
+	Books    []entities.Book
+	Files    []entities.ImportFileResult
+	Warnings []string // Rows skipped within files that were otherwise read
+}
+
+// Highlights counts the highlights of all books.
+func (l *ReadwiseLibrary) Highlights() int {
+	total := 0
+	for _, book := range l.Books {
+		total += len(book.Highlights)
+	}
+	return total
+}
+
+// ParseReadwiseLibrary reads a Readwise "Full Library" zip export, routing
+// each markdown file through ReadwiseMarkdownConverter and each CSV file
+// through ReadwiseCSVConverter. A file that cannot be read is recorded with
+// its error and the others are still imported; other files are ignored.
+func ParseReadwiseLibrary(r io.ReaderAt, size int64) (*ReadwiseLibrary, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+
+	library := &ReadwiseLibrary{}
+	for _, file := range archive.File {
+		format := readwiseFileFormat(file)
+		if format == "" {
+			continue
+		}
+
+		result := entities.ImportFileResult{Name: file.Name, Format: format}
+		books, warnings, err := parseReadwiseLibraryFile(file, format)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		for _, warning := range warnings {
+			library.Warnings = append(library.Warnings, fmt.Sprintf("%s: %s", file.Name, warning))
+		}
+		for _, book := range books {
+			result.Books++
+			result.Highlights += len(book.Highlights)
+		}
+		library.Books = append(library.Books, books...)
+		library.Files = append(library.Files, result)
+	}
+
+	if len(library.Files) == 0 {
+		return nil, ErrNoReadwiseFiles
+	}
+	return library, nil
+}
+
+// readwiseFileFormat returns the format of an archived file, or "" for
+// directories, macOS metadata and files of other types.
+func readwiseFileFormat(file *zip.File) string {
+	if file.FileInfo().IsDir() || strings.HasPrefix(file.Name, "__MACOSX/") {
+		return ""
+	}
+	base := path.Base(file.Name)
+	if strings.HasPrefix(base, ".") {
+		return ""
+	}
+	switch strings.ToLower(path.Ext(base)) {
+	case ".md", ".markdown":
+		return ReadwiseFormatMarkdown
+	case ".csv":
+		return ReadwiseFormatCSV
+	default:
+		return ""
+	}
+}
+
+func parseReadwiseLibraryFile(file *zip.File, format string) ([]entities.Book, []string, error) {
+	if file.UncompressedSize64 > MaxReadwiseLibraryFileSize {
+		return nil, nil, fmt.Errorf("file too large (max %d MB)", MaxReadwiseLibraryFileSize/(1024*1024))
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unpack: %w", err)
+	}
+	defer rc.Close()
+
+	// The header size can lie, so the limit is enforced while reading too
+	data, err := io.ReadAll(io.LimitReader(rc, MaxReadwiseLibraryFileSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unpack: %w", err)
+	}
+	if len(data) > MaxReadwiseLibraryFileSize {
+		return nil, nil, fmt.Errorf("file too large (max %d MB)", MaxReadwiseLibraryFileSize/(1024*1024))
+	}
+
+	if format == ReadwiseFormatCSV {
+		rows, warnings, err := ParseReadwiseCSV(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, err
+		}
+		return ConvertToBooks(NewReadwiseCSVConverter(rows)), warnings, nil
+	}
+
+	book, err := ParseReadwiseMarkdown(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	return ConvertToBooks(NewReadwiseMarkdownConverter(book)), nil, nil
+}
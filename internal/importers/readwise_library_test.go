@@ -0,0 +1,150 @@
+package importers
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+const readwiseMarkdownBook = `# Atomic Habits
+
+![rw-book-cover](https://images-na.ssl-images-amazon.com/images/I/51.jpg)
+
+## Metadata
+- Author: [[James Clear]]
+- Full Title: Atomic Habits: An Easy & Proven Way to Build Good Habits
+- Category: #books
+
+## Highlights
+- Habits are the compound interest of self-improvement. ([Location 215](https://readwise.io/to_kindle?action=open&asin=B07D23CFGR&location=215))
+    - Note: Key idea
+    - Tags: [[habits]]
+- You do not rise to the level of your goals.
+You fall to the level of your systems. ([Page 27](https://readwise.io/to_kindle?action=open&asin=B07D23CFGR&location=400))
+
+## New highlights added March 3, 2024 at 10:00 AM
+### Chapter 2
+- Every action is a vote for the type of person you wish to become. ([View Highlight](https://read.readwise.io/read/01h))
+    - **Note:** Identity
+`
+
+func TestParseReadwiseMarkdown(t *testing.T) {
+	book, err := ParseReadwiseMarkdown(strings.NewReader(readwiseMarkdownBook))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Atomic Habits: An Easy & Proven Way to Build Good Habits", book.Title)
+	assert.Equal(t, "James Clear", book.Author)
+	require.Len(t, book.Highlights, 3)
+
+	assert.Equal(t, ReadwiseMarkdownHighlight{
+		Text:         "Habits are the compound interest of self-improvement.",
+		Note:         "Key idea",
+		LocationType: "location",
+		Location:     215,
+	}, book.Highlights[0])
+	assert.Equal(t, "You do not rise to the level of your goals.\nYou fall to the level of your systems.", book.Highlights[1].Text)
+	assert.Equal(t, "page", book.Highlights[1].LocationType)
+	assert.Equal(t, 27, book.Highlights[1].Location)
+	assert.Equal(t, "Every action is a vote for the type of person you wish to become.", book.Highlights[2].Text)
+	assert.Equal(t, "Identity", book.Highlights[2].Note)
+	assert.Equal(t, "Chapter 2", book.Highlights[2].Chapter)
+
+	assert.Empty(t, book.URL)
+
+	_, err = ParseReadwiseMarkdown(strings.NewReader("just some notes\n"))
+	assert.ErrorIs(t, err, ErrNotReadwiseMarkdown)
+}
+
+func TestParseReadwiseMarkdown_Article(t *testing.T) {
+	book, err := ParseReadwiseMarkdown(strings.NewReader(`# The Bitter Lesson
+
+## Metadata
+- Author: [[Rich Sutton]]
+- Category: #articles
+- URL: http://www.incompleteideas.net/IncIdeas/BitterLesson.html
+
+## Highlights
+- Search and learning scale with computation. ([View Highlight](https://read.readwise.io/read/01h))
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "http://www.incompleteideas.net/IncIdeas/BitterLesson.html", book.URL)
+
+	books := ConvertToBooks(NewReadwiseMarkdownConverter(book))
+	require.Len(t, books, 1)
+	assert.Equal(t, book.URL, books[0].URL)
+}
+
+func TestReadwiseMarkdownConverter(t *testing.T) {
+	book, err := ParseReadwiseMarkdown(strings.NewReader(readwiseMarkdownBook))
+	require.NoError(t, err)
+
+	books := ConvertToBooks(NewReadwiseMarkdownConverter(book))
+	require.Len(t, books, 1)
+	assert.Equal(t, "James Clear", books[0].Author)
+	assert.Equal(t, "readwise", books[0].Source.Name)
+	assert.Equal(t, "B07D23CFGR", books[0].ASIN)
+	require.Len(t, books[0].Highlights, 3)
+	assert.Equal(t, entities.LocationTypeLocation, books[0].Highlights[0].LocationType)
+	assert.Equal(t, 215, books[0].Highlights[0].LocationValue)
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestParseReadwiseLibrary(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"Readwise/Books/Atomic Habits.md": readwiseMarkdownBook,
+		"Readwise/Articles/Broken.md":     "no title here\n",
+		"readwise-data.csv": "Highlight,Book Title,Book Author,Location Type,Location\n" +
+			"Stay hungry,Speeches,Steve Jobs,location,10\n" +
+			",Missing,Nobody,location,1\n",
+		"Readwise/cover.png":            "binary",
+		"__MACOSX/Readwise/._Broken.md": "metadata",
+	})
+
+	library, err := ParseReadwiseLibrary(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	require.Len(t, library.Files, 3)
+	results := make(map[string]entities.ImportFileResult)
+	for _, file := range library.Files {
+		results[file.Name] = file
+	}
+
+	assert.Equal(t, entities.ImportFileResult{Name: "Readwise/Books/Atomic Habits.md", Format: ReadwiseFormatMarkdown, Books: 1, Highlights: 3},
+		results["Readwise/Books/Atomic Habits.md"])
+	assert.Equal(t, entities.ImportFileResult{Name: "readwise-data.csv", Format: ReadwiseFormatCSV, Books: 1, Highlights: 1},
+		results["readwise-data.csv"])
+	assert.Equal(t, ErrNotReadwiseMarkdown.Error(), results["Readwise/Articles/Broken.md"].Error)
+
+	assert.Len(t, library.Books, 2)
+	assert.Equal(t, 4, library.Highlights())
+	require.Len(t, library.Warnings, 1)
+	assert.Contains(t, library.Warnings[0], "readwise-data.csv: Line 3")
+}
+
+func TestParseReadwiseLibrary_NoFiles(t *testing.T) {
+	data := buildZip(t, map[string]string{"cover.png": "binary"})
+	_, err := ParseReadwiseLibrary(bytes.NewReader(data), int64(len(data)))
+	assert.ErrorIs(t, err, ErrNoReadwiseFiles)
+
+	_, err = ParseReadwiseLibrary(strings.NewReader("not a zip"), 9)
+	assert.Error(t, err)
+}
@@ -0,0 +1,194 @@
+package importers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/utils"
+)
+
+// ReadwiseMarkdownBook is one book of a Readwise markdown export, as written
+// to the Obsidian/markdown export: a file per book with a "## Metadata" and a
+// "## Highlights" section.
+type ReadwiseMarkdownBook struct {
+	Title      string
+	Author     string
+	URL        string // Address of an article, from "- URL: ..."
+	ASIN       string // Kindle book ID, from the "Location" links of its highlights
+	Highlights []ReadwiseMarkdownHighlight
+}
+
+// ReadwiseMarkdownHighlight is one highlight of a Readwise markdown export.
+type ReadwiseMarkdownHighlight struct {
+	Text         string
+	Note         string
+	Chapter      string
+	LocationType string // "location", "page", "order" or "time", as in the CSV export
+	Location     int
+}
+
+// ErrNotReadwiseMarkdown is returned for markdown files without a book title.
+var ErrNotReadwiseMarkdown = errors.New("not a Readwise markdown export")
+
+var (
+	// "([Location 215](https://readwise.io/to_kindle?...))" or "(Page 12)"
+	readwiseLocationSuffix = regexp.MustCompile(`\s*\(\[?(Location|Page|Order|Time)\s+(\d+)\]?(?:\([^)]*\))?\)\s*$`)
+	// "asin=B07D23CFGR" in a "https://readwise.io/to_kindle?..." location link
+	readwiseASINParam = regexp.MustCompile(`[?&]asin=([A-Z0-9]{10})\b`)
+	// "([View Highlight](https://read.readwise.io/...))"
+	readwiseViewSuffix = regexp.MustCompile(`\s*\(\[View Highlight\]\([^)]*\)\)\s*$`)
+	// "- Note: ..." or "- **Note:** ..." below a highlight
+	readwiseNoteLine = regexp.MustCompile(`^-\s+(?:\*\*)?Note:(?:\*\*)?\s*(.*)$`)
+	// "- Tags: ..." below a highlight, not imported
+	readwiseTagsLine = regexp.MustCompile(`^-\s+(?:\*\*)?Tags:`)
+	// "- Author: [[James Clear]]" and the like in the metadata section
+	readwiseMetadataLine = regexp.MustCompile(`^-\s+([A-Za-z ]+):\s*(.*)$`)
+)
+
+// ParseReadwiseMarkdown parses one book of a Readwise markdown export.
+func ParseReadwiseMarkdown(r io.Reader) (*ReadwiseMarkdownBook, error) {
+	book := &ReadwiseMarkdownBook{}
+	var section, chapter string
+	var current *ReadwiseMarkdownHighlight
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		text := strings.TrimSpace(current.Text)
+		text = readwiseViewSuffix.ReplaceAllString(text, "")
+		if m := readwiseLocationSuffix.FindStringSubmatch(text); m != nil {
+			current.LocationType = strings.ToLower(m[1])
+			current.Location, _ = strconv.Atoi(m[2])
+			if asin := readwiseASINParam.FindStringSubmatch(m[0]); asin != nil && book.ASIN == "" {
+				book.ASIN = asin[1]
+			}
+			text = text[:len(text)-len(m[0])]
+		}
+		current.Text = strings.TrimSpace(text)
+		if current.Text != "" {
+			book.Highlights = append(book.Highlights, *current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		indented := trimmed != "" && line != trimmed
+
+		switch {
+		case strings.HasPrefix(line, "# "):
+			flush()
+			if book.Title == "" {
+				book.Title = strings.TrimSpace(line[2:])
+			}
+			continue
+		case strings.HasPrefix(line, "## "):
+			flush()
+			section = "other"
+			heading := strings.ToLower(line[3:])
+			if strings.Contains(heading, "metadata") {
+				section = "metadata"
+			} else if strings.Contains(heading, "highlights") {
+				// "## Highlights" and "## New highlights added <date>"
+				section = "highlights"
+			}
+			continue
+		}
+
+		switch section {
+		case "metadata":
+			m := readwiseMetadataLine.FindStringSubmatch(trimmed)
+			if m == nil {
+				continue
+			}
+			value := stripWikiLinks(m[2])
+			switch strings.ToLower(m[1]) {
+			case "author":
+				book.Author = value
+			case "full title":
+				if value != "" {
+					book.Title = value
+				}
+			case "url":
+				if utils.IsWebURL(value) {
+					book.URL = value
+				}
+			}
+		case "highlights":
+			switch {
+			case strings.HasPrefix(line, "### "):
+				flush()
+				chapter = strings.TrimSpace(line[4:])
+			case strings.HasPrefix(line, "- "):
+				flush()
+				current = &ReadwiseMarkdownHighlight{Text: line[2:], Chapter: chapter}
+			case current == nil || trimmed == "":
+			case indented && readwiseNoteLine.MatchString(trimmed):
+				current.Note = strings.TrimSpace(readwiseNoteLine.FindStringSubmatch(trimmed)[1])
+			case indented && readwiseTagsLine.MatchString(trimmed):
+			case current.Note != "":
+				// Further lines of a multi-line note
+				current.Note += "\n" + trimmed
+			default:
+				// Further lines of a multi-paragraph highlight
+				current.Text += "\n" + trimmed
+			}
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read markdown: %w", err)
+	}
+
+	if book.Title == "" {
+		return nil, ErrNotReadwiseMarkdown
+	}
+	return book, nil
+}
+
+// stripWikiLinks turns "[[James Clear]] and [[Jane Doe]]" into "James Clear and Jane Doe"
+func stripWikiLinks(s string) string {
+	return strings.TrimSpace(strings.NewReplacer("[[", "", "]]", "").Replace(s))
+}
+
+// ReadwiseMarkdownConverter converts a book of a Readwise markdown export to
+// the common format.
+type ReadwiseMarkdownConverter struct {
+	Book *ReadwiseMarkdownBook
+}
+
+// NewReadwiseMarkdownConverter creates a converter for a Readwise markdown book.
+func NewReadwiseMarkdownConverter(book *ReadwiseMarkdownBook) *ReadwiseMarkdownConverter {
+	return &ReadwiseMarkdownConverter{Book: book}
+}
+
+// Convert implements Converter interface.
+func (c *ReadwiseMarkdownConverter) Convert() ([]RawHighlight, Source) {
+	highlights := make([]RawHighlight, 0, len(c.Book.Highlights))
+	for _, h := range c.Book.Highlights {
+		highlights = append(highlights, RawHighlight{
+			BookTitle:     c.Book.Title,
+			BookAuthor:    c.Book.Author,
+			BookURL:       c.Book.URL,
+			BookASIN:      c.Book.ASIN,
+			Text:          h.Text,
+			Note:          h.Note,
+			Chapter:       h.Chapter,
+			LocationType:  parseLocationType(h.LocationType),
+			LocationValue: h.Location,
+		})
+	}
+	return highlights, Source{Name: "readwise"}
+}
+
+// Compile-time interface check
+var _ Converter = (*ReadwiseMarkdownConverter)(nil)
@@ -0,0 +1,110 @@
+package importers
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/kindle"
+	"github.com/mrlokans/assistant/internal/services"
+)
+
+// BookStream produces books in batches, calling flush for each batch as soon
+// as it has been read. A book may appear in several batches.
+type BookStream func(flush func(books []entities.Book) error) error
+
+// BatchExporter persists a streamed import batch by batch.
+// Implemented by exporters.DatabaseMarkdownExporter.
+type BatchExporter interface {
+	// SaveBatch saves one batch in a single transaction and sets the book IDs.
+	SaveBatch(books []entities.Book) (exporters.ExportResult, error)
+	// ExportSaved writes files for the saved books once the stream is finished.
+	ExportSaved(bookIDs []uint) error
+}
+
+// ImportTracker records the progress of a whole streamed import. A
+// BatchExporter may implement it; exporters.DatabaseMarkdownExporter does.
+type ImportTracker interface {
+	BeginImport(userID uint, totalBooks int) *exporters.ImportRun
+}
+
+// ImportLocker excludes other imports for the whole of a streamed import.
+// A BatchExporter may implement it; exporters.DatabaseMarkdownExporter does.
+type ImportLocker interface {
+	LockImports() (release func(), err error)
+}
+
+// StreamPipeline imports sources too large to hold in memory at once.
+// Unlike Pipeline, it never materializes the whole import: each batch is saved
+// and released before the next one is read.
+type StreamPipeline struct {
+	exporter BatchExporter
+}
+
+// NewStreamPipeline creates a streaming import pipeline with the given exporter.
+func NewStreamPipeline(exporter BatchExporter) *StreamPipeline {
+	return &StreamPipeline{exporter: exporter}
+}
+
+// Import saves every batch of the stream and then exports the saved books.
+// Batches saved before an error stay saved; the returned result counts them.
+func (p *StreamPipeline) Import(stream BookStream) (result services.ImportResult, err error) {
+	var bookIDs []uint
+	seen := make(map[uint]bool)
+
+	if locker, ok := p.exporter.(ImportLocker); ok {
+		release, err := locker.LockImports()
+		if err != nil {
+			return result, err
+		}
+		defer release()
+	}
+
+	var run *exporters.ImportRun
+	if tracker, ok := p.exporter.(ImportTracker); ok {
+		run = tracker.BeginImport(0, 0)
+		defer func() { run.Finish(exporters.ExportResult(result), err) }()
+	}
+
+	err = stream(func(books []entities.Book) error {
+		batchResult, err := p.exporter.SaveBatch(books)
+		result.BooksFailed += batchResult.BooksFailed
+		result.HighlightsFailed += batchResult.HighlightsFailed
+		if err != nil {
+			return fmt.Errorf("failed to save batch: %w", err)
+		}
+		result.HighlightsProcessed += batchResult.HighlightsProcessed
+
+		for _, book := range books {
+			// Books skipped as permanently deleted have no ID
+			if book.ID == 0 || seen[book.ID] {
+				continue
+			}
+			seen[book.ID] = true
+			bookIDs = append(bookIDs, book.ID)
+		}
+		result.BooksProcessed = len(bookIDs)
+		run.Progress(exporters.ExportResult(result))
+		return nil
+	})
+	result.BooksProcessed = len(bookIDs)
+	if err != nil {
+		return result, err
+	}
+
+	if err := p.exporter.ExportSaved(bookIDs); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// KindleClippingsStream streams books from a Kindle "My Clippings.txt" file
+// in batches of batchSize clippings. Clipping dates are read as times in loc,
+// or UTC when loc is nil.
+func KindleClippingsStream(r io.Reader, batchSize int, loc *time.Location) BookStream {
+	return func(flush func(books []entities.Book) error) error {
+		return kindle.NewParser().WithLocation(loc).ParseBatches(r, batchSize, flush)
+	}
+}
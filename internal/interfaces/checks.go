@@ -60,3 +60,7 @@ var _ metadata.ProgressReporter = (*sync.Repository)(nil)
 var _ importers.Converter = (*importers.ReadwiseConverter)(nil)
 var _ importers.Converter = (*importers.ReadwiseCSVConverter)(nil)
 var _ importers.Converter = (*importers.MoonReaderConverter)(nil)
+var _ importers.Converter = (*importers.KindleNotebookConverter)(nil)
+
+// BatchExporter implementations
+var _ importers.BatchExporter = (*exporters.DatabaseMarkdownExporter)(nil)
@@ -0,0 +1,156 @@
+package kindle
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// languageProfile describes the labels a Kindle set to a given language writes
+// into My Clippings.txt. All labels are lowercase.
+type languageProfile struct {
+	name string
+
+	// Metadata line labels following "- ", e.g. "your highlight"
+	highlight string
+	note      string
+	bookmark  string
+
+	// Prefixes in front of the date, e.g. "added on"
+	addedOn []string
+
+	// Localized month names, January first. Empty for English, whose dates
+	// are parsed with datePatterns.
+	months [12]string
+}
+
+var languageProfiles = []languageProfile{
+	{
+		name:      "en",
+		highlight: "your highlight",
+		note:      "your note",
+		bookmark:  "your bookmark",
+		addedOn:   []string{"added on"},
+	},
+	{
+		// "- Ihre Markierung auf Seite 12 | bei Position 170-171 | Hinzugefügt am Montag, 1. Januar 2024 10:00:00"
+		name:      "de",
+		highlight: "ihre markierung",
+		note:      "ihre notiz",
+		bookmark:  "ihr lesezeichen",
+		addedOn:   []string{"hinzugefügt am"},
+		months: [12]string{"januar", "februar", "märz", "april", "mai", "juni",
+			"juli", "august", "september", "oktober", "november", "dezember"},
+	},
+	{
+		// "- Tu subrayado en la página 12 | posición 170-171 | Añadido el lunes, 1 de enero de 2024 10:00:00"
+		name:      "es",
+		highlight: "tu subrayado",
+		note:      "tu nota",
+		bookmark:  "tu marcador",
+		addedOn:   []string{"añadido el"},
+		months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	},
+	{
+		// "- Votre surlignement sur la page 12 | emplacement 170-171 | Ajouté le lundi 1 janvier 2024 10:00:00"
+		name:      "fr",
+		highlight: "votre surlignement",
+		note:      "votre note",
+		bookmark:  "votre signet",
+		addedOn:   []string{"ajouté le"},
+		months: [12]string{"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	},
+	{
+		// "- La tua evidenziazione a pagina 12 | posizione 170-171 | Aggiunto in data lunedì 1 gennaio 2024 10:00:00"
+		name:      "it",
+		highlight: "la tua evidenziazione",
+		note:      "la tua nota",
+		bookmark:  "il tuo segnalibro",
+		addedOn:   []string{"aggiunto in data", "aggiunto il"},
+		months: [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno",
+			"luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+	},
+}
+
+// Localized dates put the day before the month and use a 24-hour clock; the
+// weekday in front is ignored:
+// "Montag, 1. Januar 2024 10:00:00", "lunes, 1 de enero de 2024 0:03:51",
+// "lundi 1er janvier 2024 10:00:00"
+var localizedDatePattern = regexp.MustCompile(`(\d{1,2})(?:\.|er)?\s+(?:de\s+)?(\p{L}+)\s+(?:de\s+)?(\d{4}),?\s+(\d{1,2}:\d{2}:\d{2})`)
+
+// detectEntry matches a metadata line against the language profiles and
+// returns the profile and entry type.
+func detectEntry(line string) (*languageProfile, EntryType, bool) {
+	if !strings.HasPrefix(line, "- ") {
+		return nil, "", false
+	}
+	label := strings.ToLower(strings.TrimPrefix(line, "- "))
+
+	for i := range languageProfiles {
+		profile := &languageProfiles[i]
+		switch {
+		case strings.HasPrefix(label, profile.highlight):
+			return profile, EntryTypeHighlight, true
+		case strings.HasPrefix(label, profile.note):
+			return profile, EntryTypeNote, true
+		case strings.HasPrefix(label, profile.bookmark):
+			return profile, EntryTypeBookmark, true
+		}
+	}
+	return nil, "", false
+}
+
+// parseDate extracts the date following the profile's "added on" label. Kindle
+// dates carry no zone, so they are read as times in loc.
+func (lp *languageProfile) parseDate(line string, loc *time.Location) (time.Time, bool) {
+	lower := strings.ToLower(line)
+	for _, prefix := range lp.addedOn {
+		idx := strings.Index(lower, prefix)
+		if idx == -1 {
+			continue
+		}
+		if lp.months[0] == "" {
+			return parseEnglishDate(strings.TrimSpace(line[idx+len(prefix):]), loc)
+		}
+		return lp.parseLocalizedDate(lower[idx+len(prefix):], loc)
+	}
+	return time.Time{}, false
+}
+
+func (lp *languageProfile) parseLocalizedDate(s string, loc *time.Location) (time.Time, bool) {
+	matches := localizedDatePattern.FindStringSubmatch(s)
+	if matches == nil {
+		return time.Time{}, false
+	}
+
+	month := 0
+	for i, name := range lp.months {
+		if matches[2] == name {
+			month = i + 1
+			break
+		}
+	}
+	if month == 0 {
+		return time.Time{}, false
+	}
+
+	day, _ := strconv.Atoi(matches[1])
+	t, err := time.ParseInLocation("2006-1-2 15:04:05", matches[3]+"-"+strconv.Itoa(month)+"-"+strconv.Itoa(day)+" "+matches[4], loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func parseEnglishDate(s string, loc *time.Location) (time.Time, bool) {
+	for _, pattern := range datePatterns {
+		t, err := time.ParseInLocation(pattern, s, loc)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,234 @@
+package kindle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ErrNotNotebook is returned when the input has no Kindle notebook structure.
+var ErrNotNotebook = errors.New("not a Kindle notebook export")
+
+// Notebook is a parsed "Notebook export" HTML file produced by the Kindle iOS/Android apps.
+type Notebook struct {
+	Title   string
+	Author  string
+	Entries []NotebookEntry
+}
+
+// NotebookEntry represents a single highlight, note or bookmark from a notebook export.
+type NotebookEntry struct {
+	Type     EntryType
+	Color    string // Color name as exported by Kindle (yellow, blue, pink, orange)
+	Chapter  string
+	Page     int
+	Location int
+	Text     string
+}
+
+// NotebookParser parses Kindle notebook HTML exports.
+type NotebookParser struct{}
+
+func NewNotebookParser() *NotebookParser {
+	return &NotebookParser{}
+}
+
+var (
+	// "Highlight(yellow) - Page 3 · Location 105", "Highlight (blue) - Location 42",
+	// "Note - Chapter 1 > Page 3 · Location 106", "Bookmark - Location 200"
+	noteHeadingPattern = regexp.MustCompile(`(?i)^(Highlight|Note|Bookmark)\s*(?:\(([^)]*)\))?\s*-\s*(.*)$`)
+
+	notebookPagePattern     = regexp.MustCompile(`(?i)\bPage\s+(\d+)`)
+	notebookLocationPattern = regexp.MustCompile(`(?i)\bLocation\s+(\d+)`)
+
+	// Kindle marks the highlight color with a span class such as "highlight_yellow"
+	highlightColorClass = regexp.MustCompile(`^highlight_(\w+)$`)
+)
+
+// notebookBlock is the text content of one classified <div> in the export.
+type notebookBlock struct {
+	class string
+	text  string
+	color string
+}
+
+// Parse reads a Kindle notebook export.
+// The exports are not well-formed (note text divs are closed with </h3>),
+// so blocks are delimited by the start of the next classified div.
+func (p *NotebookParser) Parse(r io.Reader) (*Notebook, error) {
+	blocks, err := readNotebookBlocks(r)
+	if err != nil {
+		return nil, err
+	}
+
+	notebook := &Notebook{}
+	var heading *NotebookEntry
+	var section string
+	isNotebook := false
+
+	for _, block := range blocks {
+		switch block.class {
+		case "notebookFor":
+			isNotebook = true
+		case "bookTitle":
+			isNotebook = true
+			notebook.Title = block.text
+		case "authors":
+			notebook.Author = block.text
+		case "sectionHeading":
+			section = block.text
+		case "noteHeading":
+			heading = parseNoteHeading(block.text, section)
+			if heading != nil && heading.Color == "" {
+				heading.Color = block.color
+			}
+			if heading != nil && heading.Type == EntryTypeBookmark {
+				notebook.Entries = append(notebook.Entries, *heading)
+				heading = nil
+			}
+		case "noteText":
+			if heading == nil {
+				continue
+			}
+			heading.Text = block.text
+			notebook.Entries = append(notebook.Entries, *heading)
+			heading = nil
+		}
+	}
+
+	if !isNotebook || notebook.Title == "" {
+		return nil, ErrNotNotebook
+	}
+	return notebook, nil
+}
+
+// readNotebookBlocks tokenizes the HTML and collects the text of every div with a class.
+func readNotebookBlocks(r io.Reader) ([]notebookBlock, error) {
+	tokenizer := html.NewTokenizer(r)
+	var blocks []notebookBlock
+	var current *notebookBlock
+	var text strings.Builder
+
+	flush := func() {
+		if current != nil {
+			current.text = normalizeNotebookText(text.String())
+			blocks = append(blocks, *current)
+		}
+		current = nil
+		text.Reset()
+	}
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, err
+			}
+			flush()
+			return blocks, nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			class := attr(token, "class")
+			switch token.Data {
+			case "div":
+				if class != "" {
+					flush()
+					current = &notebookBlock{class: class}
+				}
+			case "span":
+				if m := highlightColorClass.FindStringSubmatch(class); m != nil && current != nil {
+					current.color = strings.ToLower(m[1])
+				}
+			case "br":
+				text.WriteString("\n")
+			case "style", "script":
+				// Skip embedded stylesheets and scripts
+				tokenizer.Next()
+			}
+
+		case html.TextToken:
+			if current != nil {
+				// Source line breaks are formatting; only <br> separates lines
+				text.WriteString(strings.ReplaceAll(string(tokenizer.Text()), "\n", " "))
+			}
+		}
+	}
+}
+
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// parseNoteHeading parses a heading such as "Highlight(yellow) - Chapter 1 > Page 3 · Location 105".
+// Returns nil for headings that are not highlights, notes or bookmarks.
+func parseNoteHeading(heading, section string) *NotebookEntry {
+	m := noteHeadingPattern.FindStringSubmatch(heading)
+	if m == nil {
+		return nil
+	}
+
+	entry := &NotebookEntry{
+		Type:    EntryTypeHighlight,
+		Color:   strings.ToLower(strings.TrimSpace(m[2])),
+		Chapter: section,
+	}
+	switch strings.ToLower(m[1]) {
+	case "note":
+		entry.Type = EntryTypeNote
+	case "bookmark":
+		entry.Type = EntryTypeBookmark
+	}
+
+	position := m[3]
+	if idx := strings.LastIndex(position, ">"); idx >= 0 {
+		if chapter := strings.TrimSpace(position[:idx]); chapter != "" {
+			entry.Chapter = chapter
+		}
+		position = position[idx+1:]
+	}
+	if pm := notebookPagePattern.FindStringSubmatch(position); pm != nil {
+		entry.Page, _ = strconv.Atoi(pm[1])
+	}
+	if lm := notebookLocationPattern.FindStringSubmatch(position); lm != nil {
+		entry.Location, _ = strconv.Atoi(lm[1])
+	}
+
+	return entry
+}
+
+// normalizeNotebookText collapses whitespace while keeping <br> line breaks.
+func normalizeNotebookText(s string) string {
+	lines := strings.Split(s, "\n")
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// EntryID returns a stable identifier for an entry. Notebook exports carry no
+// timestamps, so the ID is derived from the book title, entry type, position
+// and a hash of the text, which tells apart entries at the same location.
+func (n *Notebook) EntryID(entry NotebookEntry) string {
+	loc := entry.Location
+	if loc == 0 {
+		loc = entry.Page
+	}
+	hash := sha256.Sum256([]byte(entry.Text))
+	return fmt.Sprintf("kindle-notebook-%s-%s-%d-%s", sanitizeForID(n.Title), entry.Type, loc, hex.EncodeToString(hash[:4]))
+}
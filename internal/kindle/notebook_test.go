@@ -0,0 +1,106 @@
+package kindle
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNotebookParser_Parse(t *testing.T) {
+	file, err := os.Open("testdata/notebook_export.html")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer file.Close()
+
+	notebook, err := NewNotebookParser().Parse(file)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if notebook.Title != "Meditations" {
+		t.Errorf("Expected title 'Meditations', got '%s'", notebook.Title)
+	}
+	if notebook.Author != "Marcus Aurelius" {
+		t.Errorf("Expected author 'Marcus Aurelius', got '%s'", notebook.Author)
+	}
+	if len(notebook.Entries) != 5 {
+		t.Fatalf("Expected 5 entries, got %d", len(notebook.Entries))
+	}
+
+	first := notebook.Entries[0]
+	if first.Type != EntryTypeHighlight || first.Color != "yellow" {
+		t.Errorf("Expected yellow highlight, got %s %s", first.Color, first.Type)
+	}
+	if first.Page != 3 || first.Location != 105 {
+		t.Errorf("Expected page 3 location 105, got page %d location %d", first.Page, first.Location)
+	}
+	if first.Chapter != "Book One" {
+		t.Errorf("Expected chapter from section heading 'Book One', got '%s'", first.Chapter)
+	}
+	if !strings.HasPrefix(first.Text, "From my grandfather Verus") {
+		t.Errorf("Unexpected text: %s", first.Text)
+	}
+
+	note := notebook.Entries[1]
+	if note.Type != EntryTypeNote || note.Text != "Start of the gratitude list" {
+		t.Errorf("Expected note entry, got %s '%s'", note.Type, note.Text)
+	}
+
+	second := notebook.Entries[2]
+	if second.Color != "blue" || second.Chapter != "Book Two" {
+		t.Errorf("Expected blue highlight in 'Book Two', got %s in '%s'", second.Color, second.Chapter)
+	}
+	expected := "Begin the morning by saying to thyself,\nI shall meet with the busy-body."
+	if second.Text != expected {
+		t.Errorf("Expected line break preserved, got %q", second.Text)
+	}
+
+	if notebook.Entries[3].Type != EntryTypeBookmark || notebook.Entries[3].Location != 250 {
+		t.Errorf("Expected bookmark at location 250, got %+v", notebook.Entries[3])
+	}
+}
+
+func TestNotebookParser_RejectsOtherHTML(t *testing.T) {
+	_, err := NewNotebookParser().Parse(strings.NewReader("<html><body><p>Hello</p></body></html>"))
+	if err != ErrNotNotebook {
+		t.Errorf("Expected ErrNotNotebook, got %v", err)
+	}
+}
+
+func TestParseNoteHeading(t *testing.T) {
+	tests := []struct {
+		heading string
+		entry   *NotebookEntry
+	}{
+		{"Highlight (pink) - Location 42", &NotebookEntry{Type: EntryTypeHighlight, Color: "pink", Chapter: "Section", Location: 42}},
+		{"Highlight(orange) - Part 1 > Chapter 2 > Page 7 · Location 88", &NotebookEntry{Type: EntryTypeHighlight, Color: "orange", Chapter: "Part 1 > Chapter 2", Page: 7, Location: 88}},
+		{"Note - Page xii · Location 12", &NotebookEntry{Type: EntryTypeNote, Chapter: "Section", Location: 12}},
+		{"Something else", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.heading, func(t *testing.T) {
+			entry := parseNoteHeading(tt.heading, "Section")
+			if tt.entry == nil {
+				if entry != nil {
+					t.Errorf("Expected nil, got %+v", entry)
+				}
+				return
+			}
+			if entry == nil || *entry != *tt.entry {
+				t.Errorf("Expected %+v, got %+v", tt.entry, entry)
+			}
+		})
+	}
+}
+
+func TestNotebook_EntryID(t *testing.T) {
+	notebook := &Notebook{Title: "Meditations"}
+	first := NotebookEntry{Type: EntryTypeHighlight, Location: 105, Text: "From my grandfather Verus"}
+	second := NotebookEntry{Type: EntryTypeHighlight, Location: 105, Text: "From my mother, piety"}
+
+	if notebook.EntryID(first) == notebook.EntryID(second) {
+		t.Errorf("Expected different IDs for entries at the same location, got %s", notebook.EntryID(first))
+	}
+}
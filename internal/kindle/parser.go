@@ -35,36 +35,52 @@ type ClippingEntry struct {
 }
 
 // Parser parses Kindle My Clippings.txt format
-type Parser struct{}
+type Parser struct {
+	location *time.Location
+}
 
 func NewParser() *Parser {
-	return &Parser{}
+	return &Parser{location: time.UTC}
+}
+
+// WithLocation reads clipping dates as times in loc, the zone of the Kindle's
+// clock. Dates are read as UTC by default. A nil loc keeps the current zone.
+func (p *Parser) WithLocation(loc *time.Location) *Parser {
+	if loc != nil {
+		p.location = loc
+	}
+	return p
 }
 
 const entrySeparator = "=========="
 
+// DefaultBatchSize is the number of clippings grouped into books per batch by ParseBatches.
+const DefaultBatchSize = 500
+
 // Regex patterns for parsing metadata lines
 var (
-	// Matches: "- Your Highlight on page 8 | Location 64-64 | Added on Tuesday, April 15, 2025 10:16:21 PM"
+	// Metadata lines look like:
+	// "- Your Highlight on page 8 | Location 64-64 | Added on Tuesday, April 15, 2025 10:16:21 PM"
 	// or: "- Your Note on page 31 | Location 307 | Added on Tuesday, April 15, 2025 11:33:26 PM"
 	// or: "- Your Highlight at location 784-785 | Added on Saturday, 26 March 2016 18:37:26"
 	// or: "- Your Bookmark at location 346 | Added on Saturday, 26 March 2016 15:46:21"
-	metadataPattern = regexp.MustCompile(`^- Your (Highlight|Note|Bookmark)`)
+	// Non-English devices translate the labels, see languageProfiles.
 
-	// Page patterns: "on page 8" or "on page 207-207"
-	pagePattern = regexp.MustCompile(`(?i)(?:on )?page (\d+)(?:-(\d+))?`)
+	// Page patterns: "on page 8", "page 207-207", "Seite 12", "página 12", "pagina 12"
+	pagePattern = regexp.MustCompile(`(?i)(?:on )?(?:page|seite|página|pagina) (\d+)(?:-(\d+))?`)
 
-	// Location patterns: "Location 64-64" or "location 1406-1407" or "at location 784-785"
-	locationPattern = regexp.MustCompile(`(?i)(?:at )?location (\d+)(?:-(\d+))?`)
+	// Location patterns: "Location 64-64", "at location 784-785", "Position 170-171",
+	// "posición 170", "emplacement 170", "posizione 170"
+	locationPattern = regexp.MustCompile(`(?i)(?:at )?(?:location|position|posición|emplacement|posizione) (\d+)(?:-(\d+))?`)
 
-	// Date patterns - multiple formats observed in the wild
-	// "Added on Tuesday, April 15, 2025 10:16:21 PM"
-	// "Added on Saturday, 26 March 2016 14:59:39"
+	// English date patterns - multiple formats observed in the wild
+	// "Tuesday, April 15, 2025 10:16:21 PM"
+	// "Saturday, 26 March 2016 14:59:39"
 	datePatterns = []string{
-		"Added on Monday, January 2, 2006 3:04:05 PM",
-		"Added on Monday, January 2, 2006 15:04:05",
-		"Added on Monday, 2 January 2006 3:04:05 PM",
-		"Added on Monday, 2 January 2006 15:04:05",
+		"Monday, January 2, 2006 3:04:05 PM",
+		"Monday, January 2, 2006 15:04:05",
+		"Monday, 2 January 2006 3:04:05 PM",
+		"Monday, 2 January 2006 15:04:05",
 	}
 
 	// Title with author: "Book Title (Author Name)"
@@ -84,9 +100,55 @@ func (p *Parser) Parse(r io.Reader) ([]entities.Book, error) {
 
 // ParseEntries parses individual clipping entries from the reader
 func (p *Parser) ParseEntries(r io.Reader) ([]ClippingEntry, error) {
+	var entries []ClippingEntry
+	err := p.streamEntries(r, func(entry ClippingEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ParseBatches reads clippings incrementally and calls fn with the books of every
+// batchSize entries, so memory use does not grow with the file. A book spread
+// over the file is passed in several batches. Notes stay in the batch of the
+// highlight they follow so they can still be attached to it.
+func (p *Parser) ParseBatches(r io.Reader, batchSize int, fn func(books []entities.Book) error) error {
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+
+	var batch []ClippingEntry
+	flush := func() error {
+		books := p.groupEntriesIntoBooks(batch)
+		batch = batch[:0]
+		if len(books) == 0 {
+			return nil
+		}
+		return fn(books)
+	}
+
+	err := p.streamEntries(r, func(entry ClippingEntry) error {
+		if len(batch) >= batchSize && entry.Type != EntryTypeNote {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		batch = append(batch, entry)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// streamEntries calls fn for every valid entry as soon as it has been read.
+func (p *Parser) streamEntries(r io.Reader, fn func(entry ClippingEntry) error) error {
 	scanner := bufio.NewScanner(r)
 
-	var entries []ClippingEntry
 	var currentLines []string
 
 	for scanner.Scan() {
@@ -96,9 +158,11 @@ func (p *Parser) ParseEntries(r io.Reader) ([]ClippingEntry, error) {
 			if len(currentLines) > 0 {
 				entry, err := p.parseEntry(currentLines)
 				if err == nil && entry != nil {
-					entries = append(entries, *entry)
+					if err := fn(*entry); err != nil {
+						return err
+					}
 				}
-				currentLines = nil
+				currentLines = currentLines[:0]
 			}
 			continue
 		}
@@ -107,18 +171,18 @@ func (p *Parser) ParseEntries(r io.Reader) ([]ClippingEntry, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading clippings: %w", err)
+		return fmt.Errorf("error reading clippings: %w", err)
 	}
 
 	// Handle last entry if file doesn't end with separator
 	if len(currentLines) > 0 {
 		entry, err := p.parseEntry(currentLines)
 		if err == nil && entry != nil {
-			entries = append(entries, *entry)
+			return fn(*entry)
 		}
 	}
 
-	return entries, nil
+	return nil
 }
 
 func (p *Parser) parseEntry(lines []string) (*ClippingEntry, error) {
@@ -136,14 +200,14 @@ func (p *Parser) parseEntry(lines []string) (*ClippingEntry, error) {
 
 	// Second line: Metadata (type, page, location, date)
 	metadataLine := strings.TrimSpace(lines[1])
-	if !metadataPattern.MatchString(metadataLine) {
+	profile, entryType, ok := detectEntry(metadataLine)
+	if !ok {
 		return nil, fmt.Errorf("invalid metadata line")
 	}
 
-	entryType := parseEntryType(metadataLine)
 	page, pageEnd := parsePageRange(metadataLine)
 	location, locationEnd := parseLocationRange(metadataLine)
-	addedAt := parseDate(metadataLine)
+	addedAt, _ := profile.parseDate(metadataLine, p.location)
 
 	// Remaining lines (after blank line): Text content
 	// Format is: title, metadata, blank line, content
@@ -195,20 +259,6 @@ func parseTitleAuthor(line string) (title, author string) {
 	return strings.TrimSpace(line), ""
 }
 
-func parseEntryType(line string) EntryType {
-	lower := strings.ToLower(line)
-	switch {
-	case strings.Contains(lower, "your highlight"):
-		return EntryTypeHighlight
-	case strings.Contains(lower, "your note"):
-		return EntryTypeNote
-	case strings.Contains(lower, "your bookmark"):
-		return EntryTypeBookmark
-	default:
-		return EntryTypeHighlight
-	}
-}
-
 func parsePageRange(line string) (page, pageEnd int) {
 	matches := pagePattern.FindStringSubmatch(line)
 	if len(matches) >= 2 {
@@ -231,26 +281,6 @@ func parseLocationRange(line string) (location, locationEnd int) {
 	return
 }
 
-func parseDate(line string) time.Time {
-	// Extract the date part after "Added on"
-	idx := strings.Index(strings.ToLower(line), "added on")
-	if idx == -1 {
-		return time.Time{}
-	}
-
-	dateStr := "Added on" + line[idx+8:]
-	dateStr = strings.TrimSpace(dateStr)
-
-	for _, pattern := range datePatterns {
-		t, err := time.Parse(pattern, dateStr)
-		if err == nil {
-			return t
-		}
-	}
-
-	return time.Time{}
-}
-
 func (p *Parser) groupEntriesIntoBooks(entries []ClippingEntry) []entities.Book {
 	// Group entries by book (title + author combination)
 	bookMap := make(map[string]*entities.Book)
@@ -1,6 +1,7 @@
 package kindle
 
 import (
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -397,6 +398,180 @@ func TestParseTitleAuthor(t *testing.T) {
 	}
 }
 
+func TestParser_ParseBatches(t *testing.T) {
+	f, err := os.Open("testdata/with_notes.txt")
+	if err != nil {
+		t.Fatalf("failed to open test file: %v", err)
+	}
+	defer f.Close()
+
+	var batches [][]entities.Book
+	err = NewParser().ParseBatches(f, 1, func(books []entities.Book) error {
+		batches = append(batches, books)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Notes stay in the batch of the highlight they follow
+	if len(batches) != 2 {
+		t.Fatalf("expected 2 batches, got %d", len(batches))
+	}
+	first := batches[0]
+	if len(first) != 1 || len(first[0].Highlights) != 1 {
+		t.Fatalf("expected 1 book with 1 highlight in the first batch, got %+v", first)
+	}
+	if !strings.Contains(first[0].Highlights[0].Note, "This is the key insight") {
+		t.Errorf("expected note attached in the first batch, got '%s'", first[0].Highlights[0].Note)
+	}
+	if got := len(batches[1][0].Highlights); got != 2 {
+		t.Errorf("expected highlight and standalone note in the second batch, got %d", got)
+	}
+}
+
+func TestParser_ParseBatches_StopsOnError(t *testing.T) {
+	f, err := os.Open("testdata/sample_clippings.txt")
+	if err != nil {
+		t.Fatalf("failed to open test file: %v", err)
+	}
+	defer f.Close()
+
+	calls := 0
+	errStop := errors.New("stop")
+	err = NewParser().ParseBatches(f, 1, func(books []entities.Book) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected callback error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected parsing to stop after the first batch, got %d calls", calls)
+	}
+}
+
+func TestParser_ParseEntries_Localized(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    string
+		entryType   EntryType
+		page        int
+		location    int
+		locationEnd int
+		addedAt     time.Time
+	}{
+		{
+			name:        "german highlight",
+			metadata:    "- Ihre Markierung bei Position 123-124 | Hinzugefügt am Montag, 1. Januar 2024 10:00:00",
+			entryType:   EntryTypeHighlight,
+			location:    123,
+			locationEnd: 124,
+			addedAt:     time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "german highlight with page",
+			metadata:    "- Ihre Markierung auf Seite 12 | bei Position 170-171 | Hinzugefügt am Sonntag, 5. März 2017 21:43:35",
+			entryType:   EntryTypeHighlight,
+			page:        12,
+			location:    170,
+			locationEnd: 171,
+			addedAt:     time.Date(2017, 3, 5, 21, 43, 35, 0, time.UTC),
+		},
+		{
+			name:      "german note",
+			metadata:  "- Ihre Notiz bei Position 123 | Hinzugefügt am Montag, 1. Januar 2024 10:01:00",
+			entryType: EntryTypeNote,
+			location:  123,
+			addedAt:   time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC),
+		},
+		{
+			name:        "spanish highlight",
+			metadata:    "- Tu subrayado en la página 12 | posición 170-171 | Añadido el lunes, 1 de enero de 2024 10:00:00",
+			entryType:   EntryTypeHighlight,
+			page:        12,
+			location:    170,
+			locationEnd: 171,
+			addedAt:     time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "french highlight",
+			metadata:    "- Votre surlignement sur la page 12 | emplacement 170-171 | Ajouté le samedi 17 février 2024 08:15:00",
+			entryType:   EntryTypeHighlight,
+			page:        12,
+			location:    170,
+			locationEnd: 171,
+			addedAt:     time.Date(2024, 2, 17, 8, 15, 0, 0, time.UTC),
+		},
+		{
+			name:      "french note",
+			metadata:  "- Votre note à l'emplacement 171 | Ajouté le samedi 17 février 2024 08:16:00",
+			entryType: EntryTypeNote,
+			location:  171,
+			addedAt:   time.Date(2024, 2, 17, 8, 16, 0, 0, time.UTC),
+		},
+		{
+			name:        "italian highlight",
+			metadata:    "- La tua evidenziazione a pagina 12 | posizione 170-171 | Aggiunto in data lunedì 1 gennaio 2024 10:00:00",
+			entryType:   EntryTypeHighlight,
+			page:        12,
+			location:    170,
+			locationEnd: 171,
+			addedAt:     time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "Der Prozess (Franz Kafka)\n" + tt.metadata + "\n\nJemand mußte Josef K. verleumdet haben\n==========\n"
+
+			entries, err := NewParser().ParseEntries(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 entry, got %d", len(entries))
+			}
+
+			entry := entries[0]
+			if entry.Type != tt.entryType {
+				t.Errorf("expected type %s, got %s", tt.entryType, entry.Type)
+			}
+			if entry.Page != tt.page {
+				t.Errorf("expected page %d, got %d", tt.page, entry.Page)
+			}
+			if entry.Location != tt.location || entry.LocationEnd != tt.locationEnd {
+				t.Errorf("expected location %d-%d, got %d-%d", tt.location, tt.locationEnd, entry.Location, entry.LocationEnd)
+			}
+			if !entry.AddedAt.Equal(tt.addedAt) {
+				t.Errorf("expected added at %v, got %v", tt.addedAt, entry.AddedAt)
+			}
+		})
+	}
+}
+
+func TestParser_ParseEntries_LocalizedBookmark(t *testing.T) {
+	input := `Der Prozess (Franz Kafka)
+- Ihr Lesezeichen bei Position 45 | Hinzugefügt am Montag, 1. Januar 2024 10:00:00
+
+
+==========
+Le Petit Prince (Antoine de Saint-Exupéry)
+- Votre signet à l'emplacement 45 | Ajouté le lundi 1 janvier 2024 10:00:00
+
+
+==========
+`
+
+	entries, err := NewParser().ParseEntries(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected bookmarks to be skipped, got %d entries", len(entries))
+	}
+}
+
 func TestParseDate(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -410,11 +585,35 @@ func TestParseDate(t *testing.T) {
 			input:    "- Your Highlight on page 92 | location 1406-1407 | Added on Saturday, 26 March 2016 14:59:39",
 			expected: time.Date(2016, 3, 26, 14, 59, 39, 0, time.UTC),
 		},
+		{
+			input:    "- Ihre Markierung bei Position 123-124 | Hinzugefügt am Freitag, 1. März 2024 10:00:00",
+			expected: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			input:    "- Tu nota en la posición 123 | Añadido el martes, 3 de octubre de 2017 0:03:51",
+			expected: time.Date(2017, 10, 3, 0, 3, 51, 0, time.UTC),
+		},
+		{
+			input:    "- Votre surlignement à l'emplacement 12 | Ajouté le lundi 1er janvier 2024 10:00:00",
+			expected: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			input:    "- La tua evidenziazione alla posizione 12 | Aggiunto in data domenica 18 agosto 2019 21:05:30",
+			expected: time.Date(2019, 8, 18, 21, 5, 30, 0, time.UTC),
+		},
+		{
+			input:    "- Ihre Markierung bei Position 123 | Hinzugefügt am Montag, 1. Foo 2024 10:00:00",
+			expected: time.Time{},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			result := parseDate(tt.input)
+			profile, _, ok := detectEntry(tt.input)
+			if !ok {
+				t.Fatalf("expected %q to match a language profile", tt.input)
+			}
+			result, _ := profile.parseDate(tt.input, time.UTC)
 			if !result.Equal(tt.expected) {
 				t.Errorf("expected %v, got %v", tt.expected, result)
 			}
@@ -472,3 +671,30 @@ func TestParseLocationRange(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_WithLocation(t *testing.T) {
+	input := `The_Power_of_Now (Eckhart Tolle)
+- Your Highlight on page 8 | Location 64-64 | Added on Tuesday, April 15, 2025 10:16:21 PM
+
+would change for the better.
+==========
+`
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	entries, err := NewParser().WithLocation(berlin).ParseEntries(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	// Kindle dates are local time on the device, so 22:16 in Berlin is 20:16 UTC
+	expected := time.Date(2025, 4, 15, 20, 16, 21, 0, time.UTC)
+	if !entries[0].AddedAt.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, entries[0].AddedAt.UTC())
+	}
+}
@@ -0,0 +1,232 @@
+// Package langdetect guesses the language of a text from character trigrams.
+//
+// Each language in data/ is profiled by its most frequent trigrams, ranked from
+// most to least common. A text is assigned the language whose profile is
+// closest to its own by out-of-place distance (Cavnar & Trenkle, 1994).
+// Languages with a script of their own, such as Greek or Japanese, are
+// recognized by their script alone.
+//
+// # Usage
+//
+//	lang := langdetect.Detect(highlight.Text) // "de"
+//	fmt.Println(langdetect.Name(lang))        // "Deutsch"
+package langdetect
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// minLetters is the number of letters below which a text is too short to tell.
+const minLetters = 20
+
+// profileSize is the number of trigrams kept per profile.
+const profileSize = 300
+
+//go:embed data/*.txt
+var samples embed.FS
+
+var names = map[string]string{
+	"ar": "العربية",
+	"de": "Deutsch",
+	"el": "Ελληνικά",
+	"en": "English",
+	"es": "Español",
+	"fr": "Français",
+	"he": "עברית",
+	"it": "Italiano",
+	"ja": "日本語",
+	"ko": "한국어",
+	"nl": "Nederlands",
+	"pl": "Polski",
+	"pt": "Português",
+	"ru": "Русский",
+	"sv": "Svenska",
+	"uk": "Українська",
+	"zh": "中文",
+}
+
+// scripts maps scripts used by a single supported language to that language.
+var scripts = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+}
+
+var (
+	profilesOnce sync.Once
+	profiles     map[string]map[string]int
+)
+
+func loadProfiles() {
+	entries, err := samples.ReadDir("data")
+	if err != nil {
+		panic(err)
+	}
+	profiles = make(map[string]map[string]int, len(entries))
+	for _, entry := range entries {
+		data, err := samples.ReadFile(path.Join("data", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		lang := strings.TrimSuffix(entry.Name(), ".txt")
+		profiles[lang] = rankTrigrams(string(data), profileSize)
+	}
+}
+
+// Detect returns the ISO 639-1 code of the language the text is most likely
+// written in, or "" when the text is too short or in an unknown script.
+func Detect(text string) string {
+	letters := 0
+	counts := make(map[string]int)
+	han := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scripts {
+			if unicode.Is(s.table, r) {
+				counts[s.lang]++
+				break
+			}
+		}
+		if unicode.Is(unicode.Han, r) {
+			han++
+		}
+	}
+	// Ideographs are dense, so a few of them carry as much as a sentence
+	if letters < minLetters && han < minLetters/4 {
+		return ""
+	}
+
+	// Japanese mixes kana with Han characters; Chinese uses Han alone
+	if counts["ja"] > 0 && counts["ja"]*10 >= letters {
+		return "ja"
+	}
+	if han*2 > letters {
+		return "zh"
+	}
+	for lang, n := range counts {
+		if n*2 > letters {
+			return lang
+		}
+	}
+
+	profilesOnce.Do(loadProfiles)
+	cyrillic := countScript(text, unicode.Cyrillic)*2 > letters
+	latin := countScript(text, unicode.Latin)*2 > letters
+	if !cyrillic && !latin {
+		return ""
+	}
+
+	ranked := rankTrigrams(text, profileSize)
+	best, bestDistance := "", -1
+	for lang, profile := range profiles {
+		// Comparing scripts first keeps short texts from matching the wrong alphabet
+		if isCyrillic(lang) != cyrillic {
+			continue
+		}
+		d := distance(ranked, profile)
+		if bestDistance < 0 || d < bestDistance || (d == bestDistance && lang < best) {
+			best, bestDistance = lang, d
+		}
+	}
+	return best
+}
+
+// Name returns the name of a language in that language, or the code itself
+// for languages this package does not know.
+func Name(code string) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return code
+}
+
+// Languages returns the codes of every language Detect can return, sorted.
+func Languages() []string {
+	codes := make([]string, 0, len(names))
+	for code := range names {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func isCyrillic(lang string) bool {
+	return lang == "ru" || lang == "uk"
+}
+
+func countScript(text string, table *unicode.RangeTable) int {
+	n := 0
+	for _, r := range text {
+		if unicode.Is(table, r) {
+			n++
+		}
+	}
+	return n
+}
+
+// rankTrigrams returns the limit most frequent trigrams of the text mapped to
+// their rank. Words are padded with spaces so word starts and ends count.
+func rankTrigrams(text string, limit int) map[string]int {
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			counts[string(runes[i:i+3])]++
+		}
+	}
+
+	trigrams := make([]string, 0, len(counts))
+	for trigram := range counts {
+		trigrams = append(trigrams, trigram)
+	}
+	sort.Slice(trigrams, func(i, j int) bool {
+		if counts[trigrams[i]] != counts[trigrams[j]] {
+			return counts[trigrams[i]] > counts[trigrams[j]]
+		}
+		return trigrams[i] < trigrams[j]
+	})
+	if len(trigrams) > limit {
+		trigrams = trigrams[:limit]
+	}
+
+	ranks := make(map[string]int, len(trigrams))
+	for i, trigram := range trigrams {
+		ranks[trigram] = i
+	}
+	return ranks
+}
+
+// distance sums how far each trigram of the text is from its rank in the
+// profile, counting trigrams missing from the profile as maximally far.
+func distance(text, profile map[string]int) int {
+	total := 0
+	for trigram, rank := range text {
+		r, ok := profile[trigram]
+		if !ok {
+			total += profileSize
+			continue
+		}
+		if r > rank {
+			total += r - rank
+		} else {
+			total += rank - r
+		}
+	}
+	return total
+}
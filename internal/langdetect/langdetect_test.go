@@ -0,0 +1,51 @@
+package langdetect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		want string
+		text string
+	}{
+		{"en", "The only way to deal with an unfree world is to become so absolutely free that your very existence is an act of rebellion."},
+		{"en", "Memory is a strange thing; it does not work like I thought it did."},
+		{"de", "Man sieht nur mit dem Herzen gut. Das Wesentliche ist für die Augen unsichtbar."},
+		{"de", "Als Gregor Samsa eines Morgens aus unruhigen Träumen erwachte, fand er sich in seinem Bett zu einem ungeheueren Ungeziefer verwandelt."},
+		{"fr", "Aujourd'hui, maman est morte. Ou peut-être hier, je ne sais pas."},
+		{"es", "En un lugar de la Mancha, de cuyo nombre no quiero acordarme, no ha mucho tiempo que vivía un hidalgo."},
+		{"it", "Considerate la vostra semenza: fatti non foste a viver come bruti, ma per seguir virtute e canoscenza."},
+		{"pt", "Tudo vale a pena quando a alma não é pequena. Quem quer passar além do Bojador tem que passar além da dor."},
+		{"nl", "Ik weet niet of het goed is wat ik doe, maar ik weet wel dat ik het moet proberen voordat het te laat is."},
+		{"sv", "Det finns inget dåligt väder, bara dåliga kläder, sa hon och tog på sig jackan innan hon gick ut."},
+		{"pl", "Litwo! Ojczyzno moja! ty jesteś jak zdrowie. Ile cię trzeba cenić, ten tylko się dowie, kto cię stracił."},
+		{"ru", "Рукописи не горят. Никогда и ничего не просите, особенно у тех, кто сильнее вас."},
+		{"uk", "Як умру, то поховайте мене на могилі серед степу широкого, на Вкраїні милій."},
+		{"el", "Όταν ξεκινάς για την Ιθάκη, να εύχεσαι νά ’ναι μακρύς ο δρόμος, γεμάτος περιπέτειες."},
+		{"ja", "吾輩は猫である。名前はまだ無い。どこで生れたかとんと見当がつかぬ。"},
+		{"zh", "学而时习之，不亦说乎？有朋自远方来，不亦乐乎？"},
+		{"ko", "죽는 날까지 하늘을 우러러 한 점 부끄럼이 없기를, 잎새에 이는 바람에도 나는 괴로워했다."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, Detect(tt.text), tt.text)
+		})
+	}
+}
+
+func TestDetect_TooShort(t *testing.T) {
+	assert.Equal(t, "", Detect(""))
+	assert.Equal(t, "", Detect("Hello there"))
+	assert.Equal(t, "", Detect("1984 — 42, 7.5%"))
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "Deutsch", Name("de"))
+	assert.Equal(t, "English", Name("en"))
+	assert.Equal(t, "xx", Name("xx"))
+	assert.Contains(t, Languages(), "ru")
+}
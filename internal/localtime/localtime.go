@@ -0,0 +1,172 @@
+// Package localtime applies a user's timezone and locale to dates.
+//
+// Timestamps are stored in UTC. Sources that record local times without a
+// zone, such as Kindle clippings, are read in the user's timezone; days (for
+// the highlight of the day and the daily review) start at the user's local
+// midnight; and dates are shown in the user's locale.
+package localtime
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	// Embed the zone database; the Docker image ships without tzdata
+	_ "time/tzdata"
+)
+
+var (
+	// ErrInvalidTimezone is returned for names that are not IANA zones
+	ErrInvalidTimezone = errors.New("invalid timezone")
+	// ErrInvalidLocale is returned for malformed locale tags
+	ErrInvalidLocale = errors.New("invalid locale")
+)
+
+// localePattern matches BCP 47 language tags such as "en", "en-GB" or "pt-BR"
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// Preferences is the timezone and locale dates are shown and read in.
+// The zero value uses UTC and ISO 8601 dates.
+type Preferences struct {
+	Location *time.Location
+	Locale   string // BCP 47 tag such as "en-GB"; empty for ISO 8601 dates
+}
+
+// New returns the preferences for an IANA timezone name and a locale tag.
+// Invalid or empty values fall back to UTC and ISO 8601 dates.
+func New(timezone, locale string) Preferences {
+	prefs := Preferences{Location: time.UTC}
+	if loc, err := LoadLocation(timezone); err == nil {
+		prefs.Location = loc
+	}
+	if ValidateLocale(locale) == nil {
+		prefs.Locale = locale
+	}
+	return prefs
+}
+
+// LoadLocation returns the zone for an IANA timezone name such as
+// "Europe/Berlin". An empty name is UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	// time.LoadLocation treats "Local" as the server's zone, which is not a
+	// preference a user can rely on
+	if name == "Local" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, name)
+	}
+	return loc, nil
+}
+
+// ValidateTimezone checks that name is an IANA timezone or empty
+func ValidateTimezone(name string) error {
+	_, err := LoadLocation(name)
+	return err
+}
+
+// ValidateLocale checks that tag is a BCP 47 language tag or empty
+func ValidateLocale(tag string) error {
+	if tag == "" || localePattern.MatchString(tag) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidLocale, tag)
+}
+
+func (p Preferences) location() *time.Location {
+	if p.Location == nil {
+		return time.UTC
+	}
+	return p.Location
+}
+
+// In returns t in the preferred timezone
+func (p Preferences) In(t time.Time) time.Time {
+	return t.In(p.location())
+}
+
+// Now returns the current time in the preferred timezone
+func (p Preferences) Now() time.Time {
+	return time.Now().In(p.location())
+}
+
+// StartOfDay returns local midnight of the day t falls on
+func (p Preferences) StartOfDay(t time.Time) time.Time {
+	t = p.In(t)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// FormatDate formats the local date of t for the preferred locale
+func (p Preferences) FormatDate(t time.Time) string {
+	return p.In(t).Format(p.layouts().date)
+}
+
+// FormatDateTime formats the local date and time of t for the preferred locale
+func (p Preferences) FormatDateTime(t time.Time) string {
+	return p.In(t).Format(p.layouts().dateTime)
+}
+
+type layouts struct {
+	date     string
+	dateTime string
+}
+
+var (
+	isoLayouts = layouts{"2006-01-02", "2006-01-02 15:04"}
+
+	// Regions that write dates differently from the rest of their language
+	regionLayouts = map[string]layouts{
+		"en-gb": {"2 Jan 2006", "2 Jan 2006 15:04"},
+		"en-ie": {"2 Jan 2006", "2 Jan 2006 15:04"},
+		"en-au": {"2 Jan 2006", "2 Jan 2006 3:04 pm"},
+		"en-nz": {"2 Jan 2006", "2 Jan 2006 3:04 pm"},
+		"en-in": {"2 Jan 2006", "2 Jan 2006 3:04 pm"},
+		"en-za": {"2006/01/02", "2006/01/02 15:04"},
+		"en-ca": {"2006-01-02", "2006-01-02 3:04 PM"},
+		"nl-be": {"2/01/2006", "2/01/2006 15:04"},
+	}
+
+	// Languages on their own follow their main region, "en" being US English
+	languageLayouts = map[string]layouts{
+		"en": {"Jan 2, 2006", "Jan 2, 2006 3:04 PM"},
+		"de": {"02.01.2006", "02.01.2006 15:04"},
+		"ru": {"02.01.2006", "02.01.2006 15:04"},
+		"uk": {"02.01.2006", "02.01.2006 15:04"},
+		"pl": {"02.01.2006", "02.01.2006 15:04"},
+		"cs": {"2. 1. 2006", "2. 1. 2006 15:04"},
+		"fi": {"2.1.2006", "2.1.2006 15.04"},
+		"nb": {"02.01.2006", "02.01.2006 15:04"},
+		"da": {"02.01.2006", "02.01.2006 15.04"},
+		"tr": {"02.01.2006", "02.01.2006 15:04"},
+		"fr": {"02/01/2006", "02/01/2006 15:04"},
+		"es": {"02/01/2006", "02/01/2006 15:04"},
+		"it": {"02/01/2006", "02/01/2006 15:04"},
+		"pt": {"02/01/2006", "02/01/2006 15:04"},
+		"nl": {"02-01-2006", "02-01-2006 15:04"},
+		"sv": {"2006-01-02", "2006-01-02 15:04"},
+		"ja": {"2006/01/02", "2006/01/02 15:04"},
+		"zh": {"2006/01/02", "2006/01/02 15:04"},
+		"ko": {"2006. 01. 02.", "2006. 01. 02. 15:04"},
+	}
+)
+
+// layouts returns the date layouts of the locale, by region first, then by
+// language. Unknown locales get ISO 8601 dates.
+func (p Preferences) layouts() layouts {
+	tag := strings.ToLower(p.Locale)
+	if l, ok := regionLayouts[tag]; ok {
+		return l
+	}
+	language, _, _ := strings.Cut(tag, "-")
+	if l, ok := languageLayouts[language]; ok {
+		return l
+	}
+	return isoLayouts
+}
@@ -0,0 +1,63 @@
+package localtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	prefs := New("Europe/Berlin", "de-DE")
+	assert.Equal(t, "Europe/Berlin", prefs.Location.String())
+	assert.Equal(t, "de-DE", prefs.Locale)
+
+	prefs = New("Mars/Olympus", "not a locale")
+	assert.Equal(t, time.UTC, prefs.Location)
+	assert.Empty(t, prefs.Locale)
+
+	assert.Equal(t, time.UTC, New("", "").Location)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, ValidateTimezone(""))
+	assert.NoError(t, ValidateTimezone("America/New_York"))
+	assert.ErrorIs(t, ValidateTimezone("Local"), ErrInvalidTimezone)
+	assert.ErrorIs(t, ValidateTimezone("Nowhere/City"), ErrInvalidTimezone)
+
+	assert.NoError(t, ValidateLocale(""))
+	assert.NoError(t, ValidateLocale("pt-BR"))
+	assert.ErrorIs(t, ValidateLocale("en_US.UTF-8"), ErrInvalidLocale)
+}
+
+func TestStartOfDay(t *testing.T) {
+	prefs := New("Europe/Berlin", "")
+
+	// 23:30 UTC is already the next day in Berlin
+	day := prefs.StartOfDay(time.Date(2025, 6, 1, 23, 30, 0, 0, time.UTC))
+	assert.Equal(t, "2025-06-02T00:00:00+02:00", day.Format(time.RFC3339))
+}
+
+func TestFormat(t *testing.T) {
+	ts := time.Date(2025, 3, 7, 21, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		timezone, locale string
+		date, dateTime   string
+	}{
+		{"", "", "2025-03-07", "2025-03-07 21:05"},
+		{"America/New_York", "en-US", "Mar 7, 2025", "Mar 7, 2025 4:05 PM"},
+		{"Europe/London", "en-GB", "7 Mar 2025", "7 Mar 2025 21:05"},
+		{"Europe/Berlin", "de", "07.03.2025", "07.03.2025 22:05"},
+		{"Asia/Tokyo", "ja-JP", "2025/03/08", "2025/03/08 06:05"},
+		{"UTC", "xx-YY", "2025-03-07", "2025-03-07 21:05"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			prefs := New(tt.timezone, tt.locale)
+			require.Equal(t, tt.date, prefs.FormatDate(ts))
+			assert.Equal(t, tt.dateTime, prefs.FormatDateTime(ts))
+		})
+	}
+}
@@ -0,0 +1,336 @@
+// Package markdown renders highlight notes written in Markdown to HTML.
+//
+// Only a small subset is supported: paragraphs, headings, block quotes, lists,
+// fenced code, horizontal rules, emphasis, inline code and links. All text is
+// escaped and only a fixed set of tags is ever emitted, so the output is safe to
+// embed in pages without a separate sanitizing pass.
+package markdown
+
+import (
+	"html/template"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	headingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	unorderedPattern = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	orderedPattern   = regexp.MustCompile(`^\s{0,3}\d{1,9}[.)]\s+(.*)$`)
+	rulePattern      = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	fencePattern     = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+)
+
+// continuationIndent marks a line continuing the previous list item
+const continuationIndent = "  "
+
+// Normalize prepares a note for storage: line endings become \n, control
+// characters other than tabs and newlines are dropped, and trailing whitespace
+// is trimmed. The note is stored as Markdown and only rendered when displayed.
+func Normalize(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	src = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, src)
+
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// ToHTML renders a Markdown note as sanitized HTML. Headings are demoted by two
+// levels so a note never outranks the headings of the page it appears on, and
+// single line breaks are kept, as notes are usually typed in a plain textarea.
+func ToHTML(src string) template.HTML {
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(Normalize(src), "\n"))
+	return template.HTML(b.String())
+}
+
+func renderBlocks(b *strings.Builder, lines []string) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		b.WriteString("<p>")
+		for i, line := range paragraph {
+			if i > 0 {
+				b.WriteString("<br>\n")
+			}
+			renderInline(b, strings.TrimSpace(line))
+		}
+		b.WriteString("</p>\n")
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case fencePattern.MatchString(line):
+			flush()
+			fence := fencePattern.FindStringSubmatch(line)[1]
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString("<pre><code>")
+			b.WriteString(template.HTMLEscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+
+		case headingPattern.MatchString(trimmed):
+			flush()
+			m := headingPattern.FindStringSubmatch(trimmed)
+			tag := "h" + strconv.Itoa(min(len(m[1])+2, 6))
+			b.WriteString("<" + tag + ">")
+			renderInline(b, m[2])
+			b.WriteString("</" + tag + ">\n")
+
+		case rulePattern.MatchString(line):
+			flush()
+			b.WriteString("<hr>\n")
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quoted []string
+			for ; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(t, ">") {
+					break
+				}
+				t = strings.TrimPrefix(t, ">")
+				quoted = append(quoted, strings.TrimPrefix(t, " "))
+			}
+			i--
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case unorderedPattern.MatchString(line), orderedPattern.MatchString(line):
+			flush()
+			pattern, tag := unorderedPattern, "ul"
+			if !unorderedPattern.MatchString(line) {
+				pattern, tag = orderedPattern, "ol"
+			}
+			i = renderList(b, lines, i, pattern, tag) - 1
+
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+}
+
+// renderList renders the list starting at lines[start] and returns the index of
+// the first line after it. Indented lines continue the previous item.
+func renderList(b *strings.Builder, lines []string, start int, pattern *regexp.Regexp, tag string) int {
+	var items [][]string
+	i := start
+	for ; i < len(lines); i++ {
+		if m := pattern.FindStringSubmatch(lines[i]); m != nil {
+			items = append(items, []string{m[1]})
+			continue
+		}
+		if strings.HasPrefix(lines[i], continuationIndent) && strings.TrimSpace(lines[i]) != "" {
+			items[len(items)-1] = append(items[len(items)-1], lines[i])
+			continue
+		}
+		break
+	}
+
+	b.WriteString("<" + tag + ">\n")
+	for _, item := range items {
+		b.WriteString("<li>")
+		for j, line := range item {
+			if j > 0 {
+				b.WriteString("<br>\n")
+			}
+			renderInline(b, strings.TrimSpace(line))
+		}
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// renderInline renders emphasis, code spans and links within a line of text.
+func renderInline(b *strings.Builder, text string) {
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_[]()#+-.!>~", rune(rest[1])):
+			b.WriteString(template.HTMLEscapeString(rest[1:2]))
+			i += 2
+			continue
+
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				b.WriteString("<code>")
+				b.WriteString(template.HTMLEscapeString(rest[1 : end+1]))
+				b.WriteString("</code>")
+				i += end + 2
+				continue
+			}
+
+		case strings.HasPrefix(rest, "**"), strings.HasPrefix(rest, "__"):
+			if n := renderDelimited(b, text, i, rest[:2], "strong"); n > 0 {
+				i += n
+				continue
+			}
+
+		case strings.HasPrefix(rest, "~~"):
+			if n := renderDelimited(b, text, i, "~~", "del"); n > 0 {
+				i += n
+				continue
+			}
+
+		case rest[0] == '*', rest[0] == '_':
+			if n := renderDelimited(b, text, i, rest[:1], "em"); n > 0 {
+				i += n
+				continue
+			}
+
+		case rest[0] == '[':
+			if n := renderLink(b, rest); n > 0 {
+				i += n
+				continue
+			}
+
+		case strings.HasPrefix(rest, "http://"), strings.HasPrefix(rest, "https://"):
+			if i == 0 || !isWordByte(text[i-1]) {
+				n := autolinkLength(rest)
+				writeLink(b, rest[:n], func() { b.WriteString(template.HTMLEscapeString(rest[:n])) })
+				i += n
+				continue
+			}
+		}
+
+		b.WriteString(template.HTMLEscapeString(rest[:1]))
+		i++
+	}
+}
+
+// renderDelimited renders text[i:] as tag when it starts with a delimited span such
+// as **bold**, returning the number of bytes consumed, or 0 if it does not.
+// Underscores inside words (snake_case) are left alone.
+func renderDelimited(b *strings.Builder, text string, i int, delim, tag string) int {
+	if delim[0] == '_' && i > 0 && isWordByte(text[i-1]) {
+		return 0
+	}
+	body := text[i+len(delim):]
+	end := -1
+	for from := 0; from < len(body); {
+		j := strings.Index(body[from:], delim)
+		if j < 0 {
+			break
+		}
+		j += from
+		// A single * or _ does not close on the start of a double one
+		if len(delim) == 1 && strings.HasPrefix(body[j:], delim+delim) {
+			from = j + 2
+			continue
+		}
+		end = j
+		break
+	}
+	if end <= 0 {
+		return 0
+	}
+	inner := body[:end]
+	if strings.TrimSpace(inner) != inner {
+		return 0
+	}
+	after := i + len(delim) + end + len(delim)
+	if delim[0] == '_' && after < len(text) && isWordByte(text[after]) {
+		return 0
+	}
+
+	b.WriteString("<" + tag + ">")
+	renderInline(b, inner)
+	b.WriteString("</" + tag + ">")
+	return after - i
+}
+
+// renderLink renders a [text](url) link at the start of s and returns the number
+// of bytes consumed, or 0 if s does not start with one.
+func renderLink(b *strings.Builder, s string) int {
+	closeText := strings.Index(s, "](")
+	if closeText < 0 {
+		return 0
+	}
+	closeURL := strings.IndexByte(s[closeText+2:], ')')
+	if closeURL < 0 {
+		return 0
+	}
+	text := s[1:closeText]
+	href := strings.TrimSpace(s[closeText+2 : closeText+2+closeURL])
+	if text == "" || strings.ContainsAny(text, "[]") {
+		return 0
+	}
+
+	writeLink(b, href, func() { renderInline(b, text) })
+	return closeText + 2 + closeURL + 1
+}
+
+// writeLink writes an anchor for href, or just its text when the URL is not safe.
+func writeLink(b *strings.Builder, href string, text func()) {
+	if !SafeURL(href) {
+		text()
+		return
+	}
+	b.WriteString(`<a href="`)
+	b.WriteString(template.HTMLEscapeString(href))
+	b.WriteString(`" rel="nofollow noopener noreferrer" target="_blank">`)
+	text()
+	b.WriteString("</a>")
+}
+
+// autolinkLength returns the length of the bare URL at the start of s, leaving
+// out trailing punctuation that more likely ends the sentence.
+func autolinkLength(s string) int {
+	n := strings.IndexFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == '<' || r == '>' })
+	if n < 0 {
+		n = len(s)
+	}
+	for n > 0 && strings.ContainsRune(".,;:!?)'\"", rune(s[n-1])) {
+		n--
+	}
+	return n
+}
+
+// SafeURL reports whether a link target may be rendered: http, https and mailto
+// URLs, and paths within the application.
+func SafeURL(href string) bool {
+	if strings.HasPrefix(href, "/") {
+		return !strings.HasPrefix(href, "//") && !strings.HasPrefix(href, `/\`)
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	}
+	return false
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,136 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "paragraphs keep single line breaks",
+			input:    "first line\nsecond line\n\nnext paragraph",
+			expected: "<p>first line<br>\nsecond line</p>\n<p>next paragraph</p>\n",
+		},
+		{
+			name:     "emphasis and code",
+			input:    "**bold**, *italic*, _also italic_, ~~gone~~ and `x < y`",
+			expected: "<p><strong>bold</strong>, <em>italic</em>, <em>also italic</em>, <del>gone</del> and <code>x &lt; y</code></p>\n",
+		},
+		{
+			name:     "nested emphasis",
+			input:    "**very *much* so**",
+			expected: "<p><strong>very <em>much</em> so</strong></p>\n",
+		},
+		{
+			name:     "underscores inside words",
+			input:    "snake_case_name",
+			expected: "<p>snake_case_name</p>\n",
+		},
+		{
+			name:     "unmatched delimiters are literal",
+			input:    "2 * 3 and **open",
+			expected: "<p>2 * 3 and **open</p>\n",
+		},
+		{
+			name:     "escaped characters",
+			input:    `\*not italic\*`,
+			expected: "<p>*not italic*</p>\n",
+		},
+		{
+			name:     "headings are demoted",
+			input:    "# Title\n### Small",
+			expected: "<h3>Title</h3>\n<h5>Small</h5>\n",
+		},
+		{
+			name:     "lists",
+			input:    "- one\n- two\n  continued\n\n1. first\n2) second",
+			expected: "<ul>\n<li>one</li>\n<li>two<br>\ncontinued</li>\n</ul>\n<ol>\n<li>first</li>\n<li>second</li>\n</ol>\n",
+		},
+		{
+			name:     "block quote",
+			input:    "> quoted **text**\n> more",
+			expected: "<blockquote>\n<p>quoted <strong>text</strong><br>\nmore</p>\n</blockquote>\n",
+		},
+		{
+			name:     "fenced code is not formatted",
+			input:    "```\n**raw** <b>\n```",
+			expected: "<pre><code>**raw** &lt;b&gt;</code></pre>\n",
+		},
+		{
+			name:     "horizontal rule",
+			input:    "above\n\n---\n\nbelow",
+			expected: "<p>above</p>\n<hr>\n<p>below</p>\n",
+		},
+		{
+			name:     "links",
+			input:    "[docs](https://example.com/a?b=1&c=2) and /books/1",
+			expected: `<p><a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer" target="_blank">docs</a> and /books/1</p>` + "\n",
+		},
+		{
+			name:     "bare URLs are linked without trailing punctuation",
+			input:    "See https://example.com/page.",
+			expected: `<p>See <a href="https://example.com/page" rel="nofollow noopener noreferrer" target="_blank">https://example.com/page</a>.</p>` + "\n",
+		},
+		{
+			name:     "CRLF line endings",
+			input:    "one\r\ntwo\r\n",
+			expected: "<p>one<br>\ntwo</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(ToHTML(tt.input)))
+		})
+	}
+}
+
+func TestToHTML_Sanitizes(t *testing.T) {
+	inputs := []string{
+		`<script>alert(1)</script>`,
+		`<img src=x onerror="alert(1)">`,
+		`[click](javascript:alert(1))`,
+		`[click](JavaScript:alert(1))`,
+		`[click](data:text/html;base64,PHNjcmlwdD4=)`,
+		`[click](//evil.example)`,
+		`[x](https://example.com/" onmouseover="alert(1))`,
+		"**<b onclick=alert(1)>**",
+	}
+
+	for _, input := range inputs {
+		out := string(ToHTML(input))
+		assert.NotContains(t, out, "<script", input)
+		assert.NotContains(t, out, "<img", input)
+		assert.NotContains(t, out, "<b ", input)
+		assert.NotContains(t, strings.ToLower(out), `href="javascript`, input)
+		assert.NotContains(t, out, `href="data`, input)
+		assert.NotContains(t, out, `href="//`, input)
+		assert.NotContains(t, out, `" onmouseover`, input)
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	assert.True(t, SafeURL("https://example.com"))
+	assert.True(t, SafeURL("http://example.com/path"))
+	assert.True(t, SafeURL("mailto:me@example.com"))
+	assert.True(t, SafeURL("/books/1"))
+
+	assert.False(t, SafeURL("javascript:alert(1)"))
+	assert.False(t, SafeURL("//example.com"))
+	assert.False(t, SafeURL("/\\example.com"))
+	assert.False(t, SafeURL("ftp://example.com"))
+	assert.False(t, SafeURL("relative/path"))
+	assert.False(t, SafeURL("https://"))
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "line one\nline two", Normalize("\r\nline one  \r\nline two\x00\x07\n\n"))
+	assert.Equal(t, "keeps\n\n\tindent", Normalize("keeps\n\n\tindent"))
+}
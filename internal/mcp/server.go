@@ -0,0 +1,221 @@
+// Package mcp serves the highlights library to LLM clients over the Model Context
+// Protocol. The server speaks JSON-RPC 2.0 over stdin/stdout, one message per
+// line, so it is only reachable by the local process that launched it, e.g.
+// Claude Desktop configured to run "assistant serve-mcp".
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ProtocolVersion is the MCP revision the server implements. Clients asking for
+// another supported revision get that one instead.
+const ProtocolVersion = "2025-03-26"
+
+var supportedProtocolVersions = []string{"2024-11-05", ProtocolVersion}
+
+// maxMessageSize bounds a single JSON-RPC message read from the client.
+const maxMessageSize = 4 << 20
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// Store defines the database operations behind the tools and resources.
+type Store interface {
+	SearchHighlightsForUser(userID uint, query, book string, limit int) ([]entities.Highlight, error)
+	ListBooks(userID uint, search string, limit, offset int) ([]entities.Book, error)
+	GetBookByID(id uint) (*entities.Book, error)
+	CreateHighlight(highlight *entities.Highlight) error
+	GetHighlightByID(id uint) (*entities.Highlight, error)
+	GetOrCreateTag(name string, userID uint) (*entities.Tag, error)
+	AddTagToHighlight(highlightID, tagID uint) error
+	GetTagsForUser(userID uint) ([]entities.Tag, error)
+}
+
+// Server answers MCP requests from a single client.
+type Server struct {
+	store    Store
+	version  string
+	readOnly bool
+	userID   uint // Library the client sees; 0 for every book, as without auth
+}
+
+// NewServer creates a server reporting the given application version.
+func NewServer(store Store, version string) *Server {
+	return &Server{store: store, version: version}
+}
+
+// WithUser limits the client to the books of one user, as required when
+// authentication is enabled.
+func (s *Server) WithUser(userID uint) *Server {
+	s.userID = userID
+	return s
+}
+
+// WithReadOnly hides the tools that change the library.
+func (s *Server) WithReadOnly(readOnly bool) *Server {
+	s.readOnly = readOnly
+	return s
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+func invalidParams(format string, args ...any) *rpcError {
+	return &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// Serve reads requests from in and writes responses to out until in is closed or
+// ctx is cancelled. Notifications get no response.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		resp := s.handleMessage(line)
+		if resp == nil {
+			continue
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) handleMessage(line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "invalid JSON"}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if len(req.ID) == 0 {
+			return nil
+		}
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: codeInvalidRequest, Message: "not a JSON-RPC 2.0 request"}}
+	}
+
+	result, err := s.handle(req.Method, req.Params)
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	resp := &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			log.Printf("MCP: %s failed: %v", req.Method, err)
+			rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = rpcErr
+	}
+	return resp
+}
+
+func (s *Server) handle(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(params, &p)
+		version := ProtocolVersion
+		if slices.Contains(supportedProtocolVersions, p.ProtocolVersion) {
+			version = p.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities": map[string]any{
+				"tools":     map[string]any{},
+				"resources": map[string]any{},
+			},
+			"serverInfo": map[string]any{"name": "book-highlights", "version": s.version},
+			"instructions": "Search and read the user's book highlights and notes. " +
+				"Quote highlights verbatim and name the book they come from.",
+		}, nil
+
+	case "ping":
+		return map[string]any{}, nil
+
+	case "tools/list":
+		return map[string]any{"tools": s.tools()}, nil
+
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams("invalid tools/call params")
+		}
+		return s.callTool(p.Name, p.Arguments)
+
+	case "resources/list":
+		return s.listResources()
+
+	case "resources/templates/list":
+		return map[string]any{"resourceTemplates": []map[string]any{{
+			"uriTemplate": bookURIPrefix + "{id}",
+			"name":        "Book highlights",
+			"description": "A book with all its highlights and notes, as Markdown",
+			"mimeType":    "text/markdown",
+		}}}, nil
+
+	case "resources/read":
+		var p struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams("invalid resources/read params")
+		}
+		return s.readResource(p.URI)
+	}
+
+	if strings.HasPrefix(method, "notifications/") {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + method}
+}
@@ -0,0 +1,190 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func setupTestServer(t *testing.T) (*Server, *database.Database, *entities.Book) {
+	t.Helper()
+	db, err := database.NewDatabase(filepath.Join(t.TempDir(), "mcp.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	book := &entities.Book{Title: "Meditations", Author: "Marcus Aurelius", Highlights: []entities.Highlight{
+		{Text: "You have power over your mind, not outside events.", Note: "Stoic core", LocationType: entities.LocationTypePage, LocationValue: 12},
+		{Text: "Waste no more time arguing what a good man should be.", LocationType: entities.LocationTypePage, LocationValue: 40},
+	}}
+	require.NoError(t, db.SaveBook(book))
+	require.NoError(t, db.SaveBook(&entities.Book{Title: "Dune", Author: "Frank Herbert", Highlights: []entities.Highlight{
+		{Text: "Fear is the mind-killer."},
+	}}))
+
+	return NewServer(db, "test"), db, book
+}
+
+// exchange sends newline-delimited requests and returns the decoded responses.
+func exchange(t *testing.T, server *Server, requests ...string) []map[string]any {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, server.Serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")+"\n"), &out))
+
+	var responses []map[string]any
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var resp map[string]any
+		require.NoError(t, decoder.Decode(&resp))
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// toolText returns the text content of a tools/call response.
+func toolText(t *testing.T, resp map[string]any) string {
+	t.Helper()
+	result, ok := resp["result"].(map[string]any)
+	require.True(t, ok, "response has no result: %v", resp)
+	content := result["content"].([]any)
+	require.Len(t, content, 1)
+	return content[0].(map[string]any)["text"].(string)
+}
+
+func TestServer_InitializeAndList(t *testing.T) {
+	server, _, _ := setupTestServer(t)
+
+	responses := exchange(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":"three","method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"unknown/method"}`,
+		`not json`,
+	)
+	require.Len(t, responses, 5, "notifications get no response")
+
+	initResult := responses[0]["result"].(map[string]any)
+	assert.Equal(t, "2024-11-05", initResult["protocolVersion"])
+	assert.Equal(t, "test", initResult["serverInfo"].(map[string]any)["version"])
+
+	var names []string
+	for _, tool := range responses[1]["result"].(map[string]any)["tools"].([]any) {
+		names = append(names, tool.(map[string]any)["name"].(string))
+	}
+	assert.Equal(t, []string{"search_highlights", "list_books", "get_book", "list_tags", "add_highlight"}, names)
+
+	assert.Equal(t, "three", responses[2]["id"])
+	resources := responses[2]["result"].(map[string]any)["resources"].([]any)
+	require.Len(t, resources, 2)
+	assert.Equal(t, "Dune by Frank Herbert", resources[0].(map[string]any)["name"])
+
+	assert.Equal(t, float64(codeMethodNotFound), responses[3]["error"].(map[string]any)["code"])
+	assert.Equal(t, float64(codeParseError), responses[4]["error"].(map[string]any)["code"])
+}
+
+func TestServer_Tools(t *testing.T) {
+	server, db, book := setupTestServer(t)
+
+	responses := exchange(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search_highlights","arguments":{"query":"mind","book":"aurelius"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_book","arguments":{"id":999}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"add_highlight","arguments":{"book_id":`+jsonNumber(book.ID)+`,"text":"The best revenge is not to be like that.","page":57,"tags":["stoic"]}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"list_tags"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"search_highlights","arguments":{"text":"mind"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"resources/read","params":{"uri":"book://`+jsonNumber(book.ID)+`"}}`,
+	)
+	require.Len(t, responses, 6)
+
+	var found []HighlightRecord
+	require.NoError(t, json.Unmarshal([]byte(toolText(t, responses[0])), &found))
+	require.Len(t, found, 1)
+	assert.Equal(t, "Meditations", found[0].BookTitle)
+	assert.Equal(t, "Stoic core", found[0].Note)
+	assert.Equal(t, 12, found[0].Page)
+
+	assert.Equal(t, true, responses[1]["result"].(map[string]any)["isError"])
+	assert.Contains(t, toolText(t, responses[1]), "No book with ID 999")
+
+	var added HighlightRecord
+	require.NoError(t, json.Unmarshal([]byte(toolText(t, responses[2])), &added))
+	assert.Equal(t, []string{"stoic"}, added.Tags)
+	assert.Equal(t, 57, added.Page)
+	saved, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+	assert.Len(t, saved.Highlights, 3)
+
+	assert.JSONEq(t, `["stoic"]`, toolText(t, responses[3]))
+
+	assert.Equal(t, float64(codeInvalidParams), responses[4]["error"].(map[string]any)["code"])
+
+	contents := responses[5]["result"].(map[string]any)["contents"].([]any)
+	assert.Contains(t, contents[0].(map[string]any)["text"], "The best revenge is not to be like that.")
+}
+
+func TestServer_ReadOnly(t *testing.T) {
+	server, _, book := setupTestServer(t)
+	server.WithReadOnly(true)
+
+	responses := exchange(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"add_highlight","arguments":{"book_id":`+jsonNumber(book.ID)+`,"text":"x"}}}`,
+	)
+	require.Len(t, responses, 2)
+
+	for _, tool := range responses[0]["result"].(map[string]any)["tools"].([]any) {
+		assert.NotEqual(t, "add_highlight", tool.(map[string]any)["name"])
+	}
+	assert.Equal(t, float64(codeInvalidParams), responses[1]["error"].(map[string]any)["code"])
+}
+
+func TestServer_User(t *testing.T) {
+	server, db, other := setupTestServer(t)
+	own := &entities.Book{Title: "Letters from a Stoic", Author: "Seneca", UserID: 2, Highlights: []entities.Highlight{
+		{Text: "We suffer more in imagination than in reality; the mind is restless.", UserID: 2},
+	}}
+	require.NoError(t, db.SaveBook(own))
+	server.WithUser(2)
+
+	responses := exchange(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search_highlights","arguments":{"query":"mind"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"list_books"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_book","arguments":{"id":`+jsonNumber(other.ID)+`}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"add_highlight","arguments":{"book_id":`+jsonNumber(other.ID)+`,"text":"Not mine"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"resources/read","params":{"uri":"book://`+jsonNumber(other.ID)+`"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"resources/list"}`,
+	)
+	require.Len(t, responses, 6)
+
+	var found []HighlightRecord
+	require.NoError(t, json.Unmarshal([]byte(toolText(t, responses[0])), &found))
+	require.Len(t, found, 1)
+	assert.Equal(t, own.ID, found[0].BookID)
+
+	var books []BookRecord
+	require.NoError(t, json.Unmarshal([]byte(toolText(t, responses[1])), &books))
+	require.Len(t, books, 1)
+	assert.Equal(t, own.ID, books[0].ID)
+
+	assert.Contains(t, toolText(t, responses[2]), "No book with ID")
+	assert.Contains(t, toolText(t, responses[3]), "No book with ID")
+	saved, err := db.GetBookByID(other.ID)
+	require.NoError(t, err)
+	assert.Len(t, saved.Highlights, 2)
+
+	assert.Equal(t, float64(codeInvalidParams), responses[4]["error"].(map[string]any)["code"])
+	assert.Len(t, responses[5]["result"].(map[string]any)["resources"].([]any), 1)
+}
+
+func jsonNumber(id uint) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
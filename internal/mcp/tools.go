@@ -0,0 +1,417 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+)
+
+// Result limits for the search_highlights and list_books tools.
+const (
+	defaultToolLimit = 20
+	maxToolLimit     = 100
+)
+
+// bookURIPrefix prefixes the URI of a book resource, e.g. book://42.
+const bookURIPrefix = "book://"
+
+// tool describes a tool for tools/list. InputSchema is a JSON Schema object.
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	readOnly    bool
+}
+
+func objectSchema(required []string, properties map[string]any) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+var tools = []tool{
+	{
+		Name:        "search_highlights",
+		Description: "Search highlights and notes by text. Returns matching highlights with their book, most recent first.",
+		InputSchema: objectSchema([]string{"query"}, map[string]any{
+			"query": map[string]any{"type": "string", "description": "Text to look for in highlights and notes"},
+			"book":  map[string]any{"type": "string", "description": "Only highlights from books whose title or author contains this text"},
+			"limit": map[string]any{"type": "integer", "description": "Maximum number of highlights (default 20, at most 100)"},
+		}),
+		readOnly: true,
+	},
+	{
+		Name:        "list_books",
+		Description: "List books in the library by title, optionally filtered by title or author.",
+		InputSchema: objectSchema(nil, map[string]any{
+			"search": map[string]any{"type": "string", "description": "Text to look for in titles and authors"},
+			"limit":  map[string]any{"type": "integer", "description": "Maximum number of books (default 20, at most 100)"},
+		}),
+		readOnly: true,
+	},
+	{
+		Name:        "get_book",
+		Description: "Get a book with all its highlights, notes and tags.",
+		InputSchema: objectSchema([]string{"id"}, map[string]any{
+			"id": map[string]any{"type": "integer", "description": "Book ID, as returned by list_books or search_highlights"},
+		}),
+		readOnly: true,
+	},
+	{
+		Name:        "list_tags",
+		Description: "List the tags used on books and highlights.",
+		InputSchema: objectSchema(nil, map[string]any{}),
+		readOnly:    true,
+	},
+	{
+		Name:        "add_highlight",
+		Description: "Add a highlight or note to a book in the library.",
+		InputSchema: objectSchema([]string{"book_id", "text"}, map[string]any{
+			"book_id": map[string]any{"type": "integer", "description": "Book ID, as returned by list_books"},
+			"text":    map[string]any{"type": "string", "description": "Highlighted passage"},
+			"note":    map[string]any{"type": "string", "description": "Note on the passage (Markdown)"},
+			"page":    map[string]any{"type": "integer", "description": "Page number"},
+			"chapter": map[string]any{"type": "string", "description": "Chapter title"},
+			"tags":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Tag names"},
+		}),
+	},
+}
+
+// tools returns the tools available to the client.
+func (s *Server) tools() []tool {
+	available := make([]tool, 0, len(tools))
+	for _, t := range tools {
+		if t.readOnly || !s.readOnly {
+			available = append(available, t)
+		}
+	}
+	return available
+}
+
+// HighlightRecord is a highlight with its book, as returned by the tools.
+type HighlightRecord struct {
+	ID            uint       `json:"id"`
+	BookID        uint       `json:"book_id"`
+	BookTitle     string     `json:"book_title,omitempty"`
+	BookAuthor    string     `json:"book_author,omitempty"`
+	Text          string     `json:"text"`
+	Note          string     `json:"note,omitempty"`
+	Chapter       string     `json:"chapter,omitempty"`
+	Page          int        `json:"page,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+	Favourite     bool       `json:"favourite,omitempty"`
+	HighlightedAt *time.Time `json:"highlighted_at,omitempty"`
+}
+
+// BookRecord is a book as returned by the tools.
+type BookRecord struct {
+	ID         uint              `json:"id"`
+	Title      string            `json:"title"`
+	Author     string            `json:"author,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Highlights []HighlightRecord `json:"highlights,omitempty"`
+}
+
+func newHighlightRecord(h entities.Highlight) HighlightRecord {
+	record := HighlightRecord{
+		ID:         h.ID,
+		BookID:     h.BookID,
+		BookTitle:  h.Book.Title,
+		BookAuthor: h.Book.Author,
+		Text:       h.Text,
+		Note:       h.Note,
+		Chapter:    h.Chapter,
+		Favourite:  h.IsFavorite,
+	}
+	if h.LocationType == entities.LocationTypePage {
+		record.Page = h.LocationValue
+	}
+	if !h.HighlightedAt.IsZero() {
+		record.HighlightedAt = &h.HighlightedAt
+	}
+	for _, tag := range h.Tags {
+		record.Tags = append(record.Tags, tag.Name)
+	}
+	return record
+}
+
+func newBookRecord(b entities.Book) BookRecord {
+	record := BookRecord{ID: b.ID, Title: b.Title, Author: b.Author}
+	for _, tag := range b.Tags {
+		record.Tags = append(record.Tags, tag.Name)
+	}
+	return record
+}
+
+// toolResult is the result of tools/call. Failures the model can act on, such
+// as an unknown book, are reported as results with IsError set.
+type toolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func textResult(text string) *toolResult {
+	return &toolResult{Content: []textContent{{Type: "text", Text: text}}}
+}
+
+func errorResult(format string, args ...any) *toolResult {
+	result := textResult(fmt.Sprintf(format, args...))
+	result.IsError = true
+	return result
+}
+
+func jsonResult(v any) (*toolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return textResult(string(data)), nil
+}
+
+func (s *Server) callTool(name string, arguments json.RawMessage) (any, error) {
+	var t *tool
+	for i := range tools {
+		if tools[i].Name == name {
+			t = &tools[i]
+		}
+	}
+	if t == nil || (s.readOnly && !t.readOnly) {
+		return nil, invalidParams("unknown tool: %s", name)
+	}
+
+	if len(arguments) == 0 || string(arguments) == "null" {
+		arguments = json.RawMessage("{}")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(arguments))
+	decoder.DisallowUnknownFields()
+
+	switch name {
+	case "search_highlights":
+		var args struct {
+			Query string `json:"query"`
+			Book  string `json:"book"`
+			Limit int    `json:"limit"`
+		}
+		if err := decoder.Decode(&args); err != nil {
+			return nil, invalidParams("invalid arguments: %v", err)
+		}
+		return s.searchHighlights(args.Query, args.Book, args.Limit)
+
+	case "list_books":
+		var args struct {
+			Search string `json:"search"`
+			Limit  int    `json:"limit"`
+		}
+		if err := decoder.Decode(&args); err != nil {
+			return nil, invalidParams("invalid arguments: %v", err)
+		}
+		books, err := s.store.ListBooks(s.userID, strings.TrimSpace(args.Search), toolLimit(args.Limit), 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list books: %w", err)
+		}
+		records := make([]BookRecord, 0, len(books))
+		for _, b := range books {
+			records = append(records, newBookRecord(b))
+		}
+		return jsonResult(records)
+
+	case "get_book":
+		var args struct {
+			ID uint `json:"id"`
+		}
+		if err := decoder.Decode(&args); err != nil {
+			return nil, invalidParams("invalid arguments: %v", err)
+		}
+		book, err := s.getBook(args.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorResult("No book with ID %d", args.ID), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get book: %w", err)
+		}
+		record := newBookRecord(*book)
+		for _, h := range book.Highlights {
+			highlight := newHighlightRecord(h)
+			highlight.BookTitle, highlight.BookAuthor = "", ""
+			record.Highlights = append(record.Highlights, highlight)
+		}
+		return jsonResult(record)
+
+	case "list_tags":
+		tags, err := s.store.GetTagsForUser(s.userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags: %w", err)
+		}
+		names := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+		return jsonResult(names)
+
+	default: // add_highlight
+		var args struct {
+			BookID  uint     `json:"book_id"`
+			Text    string   `json:"text"`
+			Note    string   `json:"note"`
+			Page    int      `json:"page"`
+			Chapter string   `json:"chapter"`
+			Tags    []string `json:"tags"`
+		}
+		if err := decoder.Decode(&args); err != nil {
+			return nil, invalidParams("invalid arguments: %v", err)
+		}
+		return s.addHighlight(args.BookID, args.Text, args.Note, args.Page, args.Chapter, args.Tags)
+	}
+}
+
+func toolLimit(limit int) int {
+	if limit <= 0 {
+		return defaultToolLimit
+	}
+	return min(limit, maxToolLimit)
+}
+
+func (s *Server) searchHighlights(query, book string, limit int) (*toolResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return errorResult("query must not be empty"), nil
+	}
+
+	highlights, err := s.store.SearchHighlightsForUser(s.userID, query, strings.TrimSpace(book), toolLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search highlights: %w", err)
+	}
+
+	records := make([]HighlightRecord, 0, len(highlights))
+	for _, h := range highlights {
+		records = append(records, newHighlightRecord(h))
+	}
+	return jsonResult(records)
+}
+
+// getBook returns a book of the client's user. Other users' books are not
+// found, so their IDs reveal nothing.
+func (s *Server) getBook(id uint) (*entities.Book, error) {
+	book, err := s.store.GetBookByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.userID != 0 && book.UserID != s.userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return book, nil
+}
+
+func (s *Server) addHighlight(bookID uint, text, note string, page int, chapter string, tags []string) (*toolResult, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errorResult("text must not be empty"), nil
+	}
+
+	book, err := s.getBook(bookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errorResult("No book with ID %d", bookID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	highlight := &entities.Highlight{
+		BookID:        book.ID,
+		UserID:        book.UserID,
+		Text:          text,
+		Note:          strings.TrimSpace(note),
+		Chapter:       strings.TrimSpace(chapter),
+		Style:         entities.HighlightStyleHighlight,
+		LocationType:  entities.LocationTypeNone,
+		HighlightedAt: time.Now(),
+		Source:        entities.Source{Name: "manual"},
+	}
+	if page > 0 {
+		highlight.LocationType = entities.LocationTypePage
+		highlight.LocationValue = page
+	}
+	if err := s.store.CreateHighlight(highlight); err != nil {
+		return nil, fmt.Errorf("failed to create highlight: %w", err)
+	}
+
+	for _, name := range tags {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		tag, err := s.store.GetOrCreateTag(name, book.UserID)
+		if err != nil {
+			log.Printf("MCP: failed to create tag %q: %v", name, err)
+			continue
+		}
+		if err := s.store.AddTagToHighlight(highlight.ID, tag.ID); err != nil {
+			log.Printf("MCP: failed to tag highlight %d with %q: %v", highlight.ID, name, err)
+		}
+	}
+
+	if saved, err := s.store.GetHighlightByID(highlight.ID); err == nil {
+		highlight = saved
+	}
+	highlight.Book = *book
+	return jsonResult(newHighlightRecord(*highlight))
+}
+
+// listResources lists every book as a resource. Clients read them on demand.
+func (s *Server) listResources() (any, error) {
+	books, err := s.store.ListBooks(s.userID, "", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list books: %w", err)
+	}
+	resources := make([]map[string]any, 0, len(books))
+	for _, b := range books {
+		name := b.Title
+		if b.Author != "" {
+			name += " by " + b.Author
+		}
+		resources = append(resources, map[string]any{
+			"uri":      bookURIPrefix + strconv.FormatUint(uint64(b.ID), 10),
+			"name":     name,
+			"mimeType": "text/markdown",
+		})
+	}
+	return map[string]any{"resources": resources}, nil
+}
+
+// readResource returns a book with its highlights as Markdown.
+func (s *Server) readResource(uri string) (any, error) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(uri, bookURIPrefix), 10, 32)
+	if !strings.HasPrefix(uri, bookURIPrefix) || err != nil {
+		return nil, invalidParams("unknown resource: %s", uri)
+	}
+
+	book, err := s.getBook(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, invalidParams("unknown resource: %s", uri)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	return map[string]any{"contents": []map[string]any{{
+		"uri":      uri,
+		"mimeType": "text/markdown",
+		"text":     exporters.GenerateMarkdown(book),
+	}}}, nil
+}
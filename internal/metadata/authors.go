@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// AuthorProvider looks authors up by name.
+type AuthorProvider interface {
+	SearchAuthor(ctx context.Context, name string) (*AuthorMetadata, error)
+}
+
+// AuthorStore defines the author operations needed for enrichment.
+type AuthorStore interface {
+	GetAuthorByID(id uint) (*entities.Author, error)
+	// UpdateAuthorMetadata saves what was found; nil records a lookup that found nothing.
+	// Returns the author's ID, which changes when the author is merged into
+	// another record of the same person.
+	UpdateAuthorMetadata(id uint, info *AuthorMetadata) (uint, error)
+}
+
+// AuthorEnricher enriches author records from Wikidata.
+type AuthorEnricher struct {
+	provider AuthorProvider
+	store    AuthorStore
+}
+
+// NewAuthorEnricher creates a new author enricher.
+func NewAuthorEnricher(provider AuthorProvider, store AuthorStore) *AuthorEnricher {
+	return &AuthorEnricher{provider: provider, store: store}
+}
+
+// EnrichAuthor looks an author up by name and saves the photo, life years,
+// bio, canonical name and aliases found. The returned author may be another
+// record when the store merged the author into it. Returns ErrAuthorNotFound when there
+// is no match; the lookup is still recorded so it is not repeated
+// automatically.
+func (e *AuthorEnricher) EnrichAuthor(ctx context.Context, authorID uint) (*entities.Author, error) {
+	author, err := e.store.GetAuthorByID(authorID)
+	if err != nil {
+		return nil, fmt.Errorf("get author: %w", err)
+	}
+
+	info, err := e.provider.SearchAuthor(ctx, author.Name)
+	if errors.Is(err, ErrAuthorNotFound) {
+		if _, err := e.store.UpdateAuthorMetadata(authorID, nil); err != nil {
+			return nil, fmt.Errorf("update author: %w", err)
+		}
+		return nil, ErrAuthorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	savedID, err := e.store.UpdateAuthorMetadata(authorID, info)
+	if err != nil {
+		return nil, fmt.Errorf("update author: %w", err)
+	}
+	return e.store.GetAuthorByID(savedID)
+}
@@ -0,0 +1,121 @@
+package metadata
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ReviewThreshold is the confidence below which a match is held for review
+// instead of being applied.
+const ReviewThreshold = 0.75
+
+// ReviewQueue holds matches too uncertain to apply until they are reviewed.
+type ReviewQueue interface {
+	QueueMetadataReview(review *entities.MetadataReview) error
+}
+
+// MatchConfidence scores how likely the metadata describes the book, from 0
+// to 1. An ISBN names a single edition and scores 1; a title search scores by
+// how many words of the title and author the match shares with the book.
+func MatchConfidence(book *entities.Book, metadata *BookMetadata, searchMethod string) float64 {
+	if searchMethod == "isbn" {
+		return 1
+	}
+
+	title, _, _ := SplitTitleSeries(book.Title)
+	matchedTitle, _, _ := SplitTitleSeries(metadata.Title)
+	titleScore := wordSimilarity(mainTitle(title), mainTitle(matchedTitle))
+
+	// An author missing on either side neither confirms nor rules out the match
+	authorScore := 0.5
+	if strings.TrimSpace(book.Author) != "" && strings.TrimSpace(metadata.Author) != "" {
+		authorScore = wordSimilarity(book.Author, metadata.Author)
+	}
+
+	return math.Round((0.6*titleScore+0.4*authorScore)*100) / 100
+}
+
+// mainTitle drops a subtitle, which stores and catalogues often disagree on.
+func mainTitle(title string) string {
+	if i := strings.Index(title, ":"); i > 0 {
+		return title[:i]
+	}
+	return title
+}
+
+// wordSimilarity is the Dice coefficient of the words of a and b, ignoring
+// case, punctuation and initials, so "Clear, James" matches "James Clear".
+func wordSimilarity(a, b string) float64 {
+	wordsA, wordsB := wordSet(a), wordSet(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(wordsA)+len(wordsB))
+}
+
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) > 1 {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+// reviewFromUpdates moves the updates of a match into a review, keeping only
+// a cover found by a cover source, which does not depend on the match.
+func reviewFromUpdates(book *entities.Book, metadata *BookMetadata, updates *BookUpdateFields, fieldsUpdated []string, coverSource string) (*entities.MetadataReview, []string, []string) {
+	review := &entities.MetadataReview{
+		BookID:        book.ID,
+		UserID:        book.UserID,
+		Status:        entities.MetadataReviewPending,
+		Source:        "openlibrary",
+		MatchedTitle:  metadata.Title,
+		MatchedAuthor: metadata.Author,
+	}
+
+	var applied, held []string
+	for _, field := range fieldsUpdated {
+		switch field {
+		case "cover_url":
+			if coverSource != "" {
+				applied = append(applied, field)
+				continue
+			}
+			review.CoverURL = *updates.CoverURL
+			updates.CoverURL = nil
+		case "isbn":
+			review.ISBN = *updates.ISBN
+			updates.ISBN = nil
+		case "publisher":
+			review.Publisher = *updates.Publisher
+			updates.Publisher = nil
+		case "publication_year":
+			review.PublicationYear = *updates.PublicationYear
+			updates.PublicationYear = nil
+		case "series":
+			review.Series = *updates.Series
+			updates.Series = nil
+		case "series_index":
+			review.SeriesIndex = *updates.SeriesIndex
+			updates.SeriesIndex = nil
+		default:
+			applied = append(applied, field)
+			continue
+		}
+		held = append(held, field)
+	}
+	return review, applied, held
+}
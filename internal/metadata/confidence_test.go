@@ -0,0 +1,119 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestMatchConfidence(t *testing.T) {
+	tests := []struct {
+		name         string
+		book         entities.Book
+		metadata     BookMetadata
+		searchMethod string
+		min, max     float64
+	}{
+		{"isbn", entities.Book{Title: "Dune"}, BookMetadata{Title: "Something Else"}, "isbn", 1, 1},
+		{"exact", entities.Book{Title: "Clean Code", Author: "Robert Martin"}, BookMetadata{Title: "Clean Code", Author: "Robert C. Martin"}, "title", 1, 1},
+		{"subtitle and author order", entities.Book{Title: "Atomic Habits: An Easy & Proven Way to Build Good Habits", Author: "Clear, James"}, BookMetadata{Title: "Atomic Habits", Author: "James Clear"}, "title", 1, 1},
+		{"unknown author", entities.Book{Title: "Meditations"}, BookMetadata{Title: "Meditations", Author: "Marcus Aurelius"}, "title", 0.8, 0.8},
+		{"other author", entities.Book{Title: "Meditations", Author: "Marcus Aurelius"}, BookMetadata{Title: "Meditations", Author: "Hank Green"}, "title", 0.6, 0.6},
+		{"other book", entities.Book{Title: "The Road", Author: "Cormac McCarthy"}, BookMetadata{Title: "The Road to Serfdom", Author: "Friedrich Hayek"}, "title", 0, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confidence := MatchConfidence(&tt.book, &tt.metadata, tt.searchMethod)
+			if confidence < tt.min || confidence > tt.max {
+				t.Errorf("MatchConfidence() = %.2f, expected between %.2f and %.2f", confidence, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+type mockReviewQueue struct {
+	reviews []*entities.MetadataReview
+}
+
+func (q *mockReviewQueue) QueueMetadataReview(review *entities.MetadataReview) error {
+	q.reviews = append(q.reviews, review)
+	return nil
+}
+
+func TestEnrichBook_HoldsUncertainMatchForReview(t *testing.T) {
+	book := &entities.Book{ID: 1, UserID: 1, Title: "The Road", Author: "Cormac McCarthy", ASIN: "B000OCXFQU"}
+
+	provider := &mockMetadataProvider{
+		searchByTitleResult: &BookMetadata{
+			Title:           "The Road to Serfdom",
+			Author:          "Friedrich Hayek",
+			ISBN:            "9780226320557",
+			Publisher:       "University of Chicago Press",
+			PublicationYear: 1944,
+			CoverURL:        "https://covers.openlibrary.org/b/isbn/9780226320557-L.jpg",
+		},
+	}
+
+	updater := &mockBookUpdater{book: book}
+	queue := &mockReviewQueue{}
+	enricher := NewEnricher(provider, updater)
+	enricher.SetReviewQueue(queue)
+	enricher.SetCoverSources(&stubCoverSource{name: CoverSourceAmazon, coverURL: "https://images.example.com/B000OCXFQU.jpg"})
+
+	result, err := enricher.EnrichBook(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("EnrichBook failed: %v", err)
+	}
+
+	if result.Confidence >= ReviewThreshold {
+		t.Errorf("expected a low confidence, got %.2f", result.Confidence)
+	}
+	if len(result.FieldsUpdated) != 1 || result.FieldsUpdated[0] != "cover_url" {
+		t.Errorf("expected only the ASIN cover to be applied, got %v", result.FieldsUpdated)
+	}
+	if book.Publisher != "" || book.PublicationYear != 0 || book.ISBN != "" {
+		t.Errorf("expected the match to be held, book is %+v", book)
+	}
+	if len(result.ReviewFields) != 3 {
+		t.Errorf("expected isbn, publisher and year to be held, got %v", result.ReviewFields)
+	}
+
+	if len(queue.reviews) != 1 {
+		t.Fatalf("expected one queued review, got %d", len(queue.reviews))
+	}
+	review := queue.reviews[0]
+	if review.BookID != 1 || review.Publisher != "University of Chicago Press" || review.PublicationYear != 1944 || review.ISBN != "9780226320557" {
+		t.Errorf("unexpected review %+v", review)
+	}
+	if review.CoverURL != "" {
+		t.Errorf("expected the provider cover to be dropped in favour of the ASIN cover, got %q", review.CoverURL)
+	}
+	if review.MatchedTitle != "The Road to Serfdom" || review.SearchMethod != "title" {
+		t.Errorf("unexpected match details %+v", review)
+	}
+}
+
+func TestEnrichBook_AppliesConfidentMatch(t *testing.T) {
+	book := &entities.Book{ID: 1, Title: "Clean Code", Author: "Robert Martin"}
+
+	provider := &mockMetadataProvider{
+		searchByTitleResult: &BookMetadata{Title: "Clean Code", Author: "Robert C. Martin", Publisher: "Prentice Hall"},
+	}
+
+	queue := &mockReviewQueue{}
+	enricher := NewEnricher(provider, &mockBookUpdater{book: book})
+	enricher.SetReviewQueue(queue)
+
+	result, err := enricher.EnrichBook(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("EnrichBook failed: %v", err)
+	}
+	if result.Book.Publisher != "Prentice Hall" {
+		t.Errorf("expected the publisher to be applied, got %q", result.Book.Publisher)
+	}
+	if len(queue.reviews) != 0 || len(result.ReviewFields) != 0 {
+		t.Errorf("expected nothing held for review, got %v", result.ReviewFields)
+	}
+}
@@ -0,0 +1,163 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// Names of the cover sources.
+const (
+	CoverSourceAmazon = "amazon"
+	CoverSourceITunes = "itunes"
+)
+
+// CoverSource finds a book's cover from an identifier its import source gave
+// it, such as a Kindle ASIN. Unlike a title search, these find the edition
+// that was actually read.
+type CoverSource interface {
+	Name() string
+	// FindCover returns the cover URL for the book, or "" when the book has no
+	// identifier the source can use or the source has no cover for it.
+	FindCover(ctx context.Context, book *entities.Book) (string, error)
+}
+
+// DefaultCoverSources returns the cover sources tried before the metadata
+// provider's cover, most reliable first.
+func DefaultCoverSources() []CoverSource {
+	return []CoverSource{NewAmazonCoverSource(), NewITunesCoverSource()}
+}
+
+var asinPattern = regexp.MustCompile(`^[A-Z0-9]{10}$`)
+
+// minAmazonCoverSize tells covers apart from the 1x1 placeholder image Amazon
+// serves for ASINs it has no cover for.
+const minAmazonCoverSize = 1024
+
+// AmazonCoverSource builds cover URLs from a book's ASIN, as stored by Kindle
+// and Readwise imports.
+type AmazonCoverSource struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewAmazonCoverSource creates a cover source for Amazon product images.
+func NewAmazonCoverSource() *AmazonCoverSource {
+	return &AmazonCoverSource{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    "https://images-na.ssl-images-amazon.com/images/P",
+	}
+}
+
+func (s *AmazonCoverSource) Name() string {
+	return CoverSourceAmazon
+}
+
+// FindCover returns the large product image of the book's ASIN, checking that
+// Amazon has one.
+func (s *AmazonCoverSource) FindCover(ctx context.Context, book *entities.Book) (string, error) {
+	asin := strings.ToUpper(strings.TrimSpace(book.ASIN))
+	if !asinPattern.MatchString(asin) {
+		return "", nil
+	}
+
+	coverURL := fmt.Sprintf("%s/%s.01.LZZZZZZZ.jpg", s.baseURL, asin)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, coverURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "HighlightsManager/1.0")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch cover: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	size, err := io.Copy(io.Discard, io.LimitReader(resp.Body, minAmazonCoverSize))
+	if err != nil {
+		return "", fmt.Errorf("read cover: %w", err)
+	}
+	if size < minAmazonCoverSize {
+		return "", nil
+	}
+	return coverURL, nil
+}
+
+var appleAssetIDPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// ITunesCoverSource looks up the artwork of books bought in Apple Books by
+// their asset ID through the iTunes lookup API. Books added to Apple Books as
+// files have no store ID and are skipped.
+// API docs: https://developer.apple.com/library/archive/documentation/AudioVideo/Conceptual/iTuneSearchAPI/
+type ITunesCoverSource struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewITunesCoverSource creates a cover source for Apple Books store artwork.
+func NewITunesCoverSource() *ITunesCoverSource {
+	return &ITunesCoverSource{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    "https://itunes.apple.com",
+	}
+}
+
+func (s *ITunesCoverSource) Name() string {
+	return CoverSourceITunes
+}
+
+type iTunesLookupResponse struct {
+	Results []struct {
+		ArtworkURL100 string `json:"artworkUrl100"`
+	} `json:"results"`
+}
+
+// FindCover returns the store artwork of the book's Apple Books asset ID,
+// scaled up from the 100px thumbnail the API links to.
+func (s *ITunesCoverSource) FindCover(ctx context.Context, book *entities.Book) (string, error) {
+	if book.Source.Name != "apple_books" || !appleAssetIDPattern.MatchString(book.ExternalID) {
+		return "", nil
+	}
+
+	lookupURL := fmt.Sprintf("%s/lookup?id=%s", s.baseURL, url.QueryEscape(book.ExternalID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lookupURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "HighlightsManager/1.0")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch artwork: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var lookup iTunesLookupResponse
+	if err := json.NewDecoder(resp.Body).Decode(&lookup); err != nil {
+		return "", fmt.Errorf("decode response: %w", err)
+	}
+	if len(lookup.Results) == 0 || lookup.Results[0].ArtworkURL100 == "" {
+		return "", nil
+	}
+	return strings.Replace(lookup.Results[0].ArtworkURL100, "100x100bb", "600x600bb", 1), nil
+}
@@ -0,0 +1,108 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestAmazonCoverSource_FindCover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/B07D23CFGR.01.LZZZZZZZ.jpg":
+			_, _ = w.Write([]byte(strings.Repeat("x", 4096)))
+		case "/B000000000.01.LZZZZZZZ.jpg":
+			// Amazon answers unknown ASINs with a 1x1 GIF
+			_, _ = w.Write([]byte("GIF89a"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source := &AmazonCoverSource{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		baseURL:    server.URL,
+	}
+
+	tests := []struct {
+		asin     string
+		expected string
+	}{
+		{"B07D23CFGR", server.URL + "/B07D23CFGR.01.LZZZZZZZ.jpg"},
+		{" b07d23cfgr ", server.URL + "/B07D23CFGR.01.LZZZZZZZ.jpg"},
+		{"B000000000", ""},
+		{"B999999999", ""},
+		{"", ""},
+		{"not-an-asin", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.asin, func(t *testing.T) {
+			coverURL, err := source.FindCover(context.Background(), &entities.Book{ASIN: tt.asin})
+			if err != nil {
+				t.Fatalf("FindCover failed: %v", err)
+			}
+			if coverURL != tt.expected {
+				t.Errorf("FindCover(%q) = %q, expected %q", tt.asin, coverURL, tt.expected)
+			}
+		})
+	}
+}
+
+func TestITunesCoverSource_FindCover(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/lookup" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("id") == "1160006914" {
+			_, _ = w.Write([]byte(`{"resultCount":1,"results":[{"artworkUrl100":"https://is1-ssl.mzstatic.com/image/thumb/Publication/cover.jpg/100x100bb.jpg"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"resultCount":0,"results":[]}`))
+	}))
+	defer server.Close()
+
+	source := &ITunesCoverSource{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		baseURL:    server.URL,
+	}
+	appleBooks := entities.Source{Name: "apple_books"}
+
+	coverURL, err := source.FindCover(context.Background(), &entities.Book{ExternalID: "1160006914", Source: appleBooks})
+	if err != nil {
+		t.Fatalf("FindCover failed: %v", err)
+	}
+	if coverURL != "https://is1-ssl.mzstatic.com/image/thumb/Publication/cover.jpg/600x600bb.jpg" {
+		t.Errorf("unexpected cover URL %q", coverURL)
+	}
+
+	coverURL, err = source.FindCover(context.Background(), &entities.Book{ExternalID: "42", Source: appleBooks})
+	if err != nil || coverURL != "" {
+		t.Errorf("expected no cover for an unknown ID, got %q, %v", coverURL, err)
+	}
+
+	// Side-loaded books and other sources have no store ID to look up
+	requests = 0
+	for _, book := range []*entities.Book{
+		{ExternalID: "8F2A4C1E7D3B9A06E5F1C2D8B4A7E3F0", Source: appleBooks},
+		{ExternalID: "1160006914", Source: entities.Source{Name: "kindle"}},
+	} {
+		coverURL, err := source.FindCover(context.Background(), book)
+		if err != nil || coverURL != "" {
+			t.Errorf("expected no cover, got %q, %v", coverURL, err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("expected no lookups, got %d", requests)
+	}
+}
@@ -2,11 +2,17 @@ package metadata
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/mrlokans/assistant/internal/entities"
 )
 
+// ErrInvalidISBN is returned when an ISBN does not have 10 or 13 digits after removing separators.
+var ErrInvalidISBN = errors.New("invalid ISBN")
+
 // MetadataProvider defines the interface for fetching book metadata.
 type MetadataProvider interface {
 	SearchByISBN(ctx context.Context, isbn string) (*BookMetadata, error)
@@ -39,6 +45,8 @@ type BookUpdateFields struct {
 	CoverURL        *string
 	Publisher       *string
 	PublicationYear *int
+	Series          *string
+	SeriesIndex     *float64
 }
 
 // EnrichmentResult contains the result of an enrichment operation.
@@ -46,7 +54,10 @@ type EnrichmentResult struct {
 	Book          *entities.Book `json:"book"`
 	FieldsUpdated []string       `json:"fields_updated"`
 	Source        string         `json:"source"`
-	SearchMethod  string         `json:"search_method"` // "isbn" or "title"
+	SearchMethod  string         `json:"search_method"`           // "isbn" or "title", empty when only a cover was found
+	CoverSource   string         `json:"cover_source,omitempty"`  // Cover source that found the cover, if not the metadata provider
+	Confidence    float64        `json:"confidence"`              // 0-1, see MatchConfidence; 0 when only a cover was found
+	ReviewFields  []string       `json:"review_fields,omitempty"` // Fields held for review instead of being updated
 }
 
 // Enricher handles book metadata enrichment from external sources.
@@ -55,6 +66,8 @@ type Enricher struct {
 	db               BookUpdater
 	coverInvalidator CoverInvalidator
 	progressReporter ProgressReporter
+	coverSources     []CoverSource
+	reviewQueue      ReviewQueue
 }
 
 // NewEnricher creates a new Enricher with the given metadata provider and database.
@@ -75,36 +88,101 @@ func (e *Enricher) SetProgressReporter(reporter ProgressReporter) {
 	e.progressReporter = reporter
 }
 
+// SetCoverSources sets the sources asked for a book's cover, in order, before
+// falling back to the cover the metadata provider found (optional).
+func (e *Enricher) SetCoverSources(sources ...CoverSource) {
+	e.coverSources = sources
+}
+
+// SetReviewQueue holds title matches scoring below ReviewThreshold for review
+// instead of applying them (optional). Without a queue every match is applied.
+func (e *Enricher) SetReviewQueue(queue ReviewQueue) {
+	e.reviewQueue = queue
+}
+
+// holdForReview queues the updates of an uncertain match for review, leaving
+// in updates only what is applied right away. Returns the fields still
+// updated, the fields held and the confidence of the match.
+func (e *Enricher) holdForReview(book *entities.Book, metadata *BookMetadata, searchMethod, coverSource string, updates *BookUpdateFields, fieldsUpdated []string) ([]string, []string, float64, error) {
+	if searchMethod == "" {
+		return fieldsUpdated, nil, 0, nil
+	}
+	confidence := MatchConfidence(book, metadata, searchMethod)
+	if e.reviewQueue == nil || confidence >= ReviewThreshold {
+		return fieldsUpdated, nil, confidence, nil
+	}
+
+	review, applied, held := reviewFromUpdates(book, metadata, updates, fieldsUpdated, coverSource)
+	if len(held) == 0 {
+		return applied, nil, confidence, nil
+	}
+	review.Confidence = confidence
+	review.SearchMethod = searchMethod
+	if err := e.reviewQueue.QueueMetadataReview(review); err != nil {
+		return nil, nil, confidence, fmt.Errorf("queue metadata review: %w", err)
+	}
+	return applied, held, confidence, nil
+}
+
+// findCover returns the first cover found by the cover sources and the name
+// of the source. A failing source is skipped in favour of the next.
+func (e *Enricher) findCover(ctx context.Context, book *entities.Book) (string, string) {
+	for _, source := range e.coverSources {
+		coverURL, err := source.FindCover(ctx, book)
+		if err == nil && coverURL != "" {
+			return coverURL, source.Name()
+		}
+	}
+	return "", ""
+}
+
+// LookupISBN fetches metadata for an ISBN without touching the database.
+// Used to pre-fill books added by hand, e.g. from a scanned barcode.
+// Returns ErrInvalidISBN or ErrISBNChecksum for ISBNs that fail validation.
+func (e *Enricher) LookupISBN(ctx context.Context, isbn string) (*BookMetadata, error) {
+	isbn, err := ParseISBN(isbn)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := e.searchISBN(ctx, isbn)
+	if err != nil {
+		return nil, fmt.Errorf("metadata search failed: %w", err)
+	}
+	if metadata.ISBN == "" {
+		metadata.ISBN = isbn
+	}
+	return metadata, nil
+}
+
 // EnrichBook fetches metadata for a book and updates it in the database.
 // It tries ISBN first (if available), then falls back to title+author search.
+// A cover found by a cover source from the book's ASIN or store ID is
+// preferred over the provider's, which may be of another edition.
 func (e *Enricher) EnrichBook(ctx context.Context, bookID uint) (*EnrichmentResult, error) {
 	book, err := e.db.GetBookByID(bookID)
 	if err != nil {
 		return nil, fmt.Errorf("get book: %w", err)
 	}
 
-	var metadata *BookMetadata
-	var searchMethod string
-
-	// Try ISBN first if available
-	if book.ISBN != "" {
-		metadata, err = e.provider.SearchByISBN(ctx, book.ISBN)
-		if err == nil {
-			searchMethod = "isbn"
+	coverURL, coverSource := e.findCover(ctx, book)
+	metadata, searchMethod, err := e.search(ctx, book)
+	if err != nil {
+		if coverURL == "" {
+			return nil, err
 		}
+		metadata, searchMethod = &BookMetadata{}, ""
 	}
-
-	// Fall back to title+author search
-	if metadata == nil {
-		metadata, err = e.provider.SearchByTitle(ctx, book.Title, book.Author)
-		if err != nil {
-			return nil, fmt.Errorf("metadata search failed: %w", err)
-		}
-		searchMethod = "title"
+	if coverURL != "" {
+		metadata.CoverURL = coverURL
 	}
 
 	// Apply metadata updates
 	updates, fieldsUpdated := e.buildUpdates(book, metadata)
+	fieldsUpdated, reviewFields, confidence, err := e.holdForReview(book, metadata, searchMethod, coverSource, &updates, fieldsUpdated)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(fieldsUpdated) > 0 {
 		// Invalidate cached cover if cover URL changed
@@ -128,12 +206,58 @@ func (e *Enricher) EnrichBook(ctx context.Context, bookID uint) (*EnrichmentResu
 		FieldsUpdated: fieldsUpdated,
 		Source:        "openlibrary",
 		SearchMethod:  searchMethod,
+		CoverSource:   coverSource,
+		Confidence:    confidence,
+		ReviewFields:  reviewFields,
 	}, nil
 }
 
+// search fetches metadata for a book, trying its ISBN first (if available)
+// and falling back to title+author search. Returns the search method used.
+func (e *Enricher) search(ctx context.Context, book *entities.Book) (*BookMetadata, string, error) {
+	if book.ISBN != "" {
+		metadata, err := e.searchISBN(ctx, book.ISBN)
+		if err == nil && metadata != nil {
+			return metadata, "isbn", nil
+		}
+	}
+
+	metadata, err := e.provider.SearchByTitle(ctx, book.Title, book.Author)
+	if err != nil {
+		return nil, "", fmt.Errorf("metadata search failed: %w", err)
+	}
+	return metadata, "title", nil
+}
+
+// searchISBN looks up an ISBN, trying its other form (ISBN-13 or ISBN-10)
+// when the provider has nothing for the first, as some editions are only
+// listed under one of them.
+func (e *Enricher) searchISBN(ctx context.Context, isbn string) (*BookMetadata, error) {
+	metadata, err := e.provider.SearchByISBN(ctx, isbn)
+	if err == nil && metadata != nil {
+		return metadata, nil
+	}
+
+	isbn = NormalizeISBN(isbn)
+	other, isbn10 := ISBNForms(isbn)
+	if other == isbn {
+		other = isbn10
+	}
+	if other == "" || ctx.Err() != nil {
+		return metadata, err
+	}
+	return e.provider.SearchByISBN(ctx, other)
+}
+
 // EnrichBookWithISBN searches by ISBN first, and if found, updates the book with ISBN and metadata.
-// If ISBN search fails, falls back to title+author search.
+// If ISBN search fails, falls back to title+author search. ISBNs that fail
+// validation are rejected with ErrInvalidISBN or ErrISBNChecksum.
 func (e *Enricher) EnrichBookWithISBN(ctx context.Context, bookID uint, isbn string) (*EnrichmentResult, error) {
+	isbn, err := ParseISBN(isbn)
+	if err != nil {
+		return nil, err
+	}
+
 	book, err := e.db.GetBookByID(bookID)
 	if err != nil {
 		return nil, fmt.Errorf("get book: %w", err)
@@ -143,7 +267,7 @@ func (e *Enricher) EnrichBookWithISBN(ctx context.Context, bookID uint, isbn str
 	var searchMethod string
 
 	// Try ISBN search first
-	metadata, err = e.provider.SearchByISBN(ctx, isbn)
+	metadata, err = e.searchISBN(ctx, isbn)
 	if err == nil && metadata != nil {
 		searchMethod = "isbn"
 		// ISBN search succeeded - ensure the provided ISBN is included in updates
@@ -171,6 +295,10 @@ func (e *Enricher) EnrichBookWithISBN(ctx context.Context, bookID uint, isbn str
 			fieldsUpdated = append(fieldsUpdated, "isbn")
 		}
 	}
+	fieldsUpdated, reviewFields, confidence, err := e.holdForReview(book, metadata, searchMethod, "", &updates, fieldsUpdated)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(fieldsUpdated) > 0 {
 		// Invalidate cached cover if cover URL changed
@@ -194,6 +322,8 @@ func (e *Enricher) EnrichBookWithISBN(ctx context.Context, bookID uint, isbn str
 		FieldsUpdated: fieldsUpdated,
 		Source:        "openlibrary",
 		SearchMethod:  searchMethod,
+		Confidence:    confidence,
+		ReviewFields:  reviewFields,
 	}, nil
 }
 
@@ -208,15 +338,36 @@ func contains(slice []string, item string) bool {
 
 // BulkEnrichmentResult contains the summary of a bulk enrichment operation.
 type BulkEnrichmentResult struct {
-	TotalBooks int      `json:"total_books"`
-	Enriched   int      `json:"enriched"`
-	Failed     int      `json:"failed"`
-	Skipped    int      `json:"skipped"`
-	Errors     []string `json:"errors,omitempty"`
+	TotalBooks  int      `json:"total_books"`
+	Enriched    int      `json:"enriched"`
+	Failed      int      `json:"failed"`
+	Skipped     int      `json:"skipped"`
+	NeedsReview int      `json:"needs_review"` // Books whose match was held for review
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// BulkEnrichmentOptions tunes a bulk enrichment run.
+type BulkEnrichmentOptions struct {
+	// ResumeAfterID skips books with an ID up to and including it, to continue
+	// an interrupted run. Books are processed in ID order.
+	ResumeAfterID uint
+
+	// Delay is the minimum pause between books, on top of any provider rate limit.
+	Delay time.Duration
+
+	// OnBook is called after each book with its position in the run (1-based)
+	// and the enrichment error, if any.
+	OnBook func(done, total int, book entities.Book, err error)
 }
 
 // EnrichAllMissing enriches all books that are missing metadata (cover, publisher, or year).
 func (e *Enricher) EnrichAllMissing(ctx context.Context) (*BulkEnrichmentResult, error) {
+	return e.EnrichMissing(ctx, BulkEnrichmentOptions{})
+}
+
+// EnrichMissing enriches books that are missing metadata, with options for
+// resuming, rate limiting and progress callbacks.
+func (e *Enricher) EnrichMissing(ctx context.Context, opts BulkEnrichmentOptions) (*BulkEnrichmentResult, error) {
 	// Check if a sync is already running (and isn't stale)
 	if e.progressReporter != nil {
 		running, err := e.progressReporter.IsSyncRunning()
@@ -228,11 +379,19 @@ func (e *Enricher) EnrichAllMissing(ctx context.Context) (*BulkEnrichmentResult,
 		}
 	}
 
-	books, err := e.db.GetBooksMissingMetadata()
+	allBooks, err := e.db.GetBooksMissingMetadata()
 	if err != nil {
 		return nil, fmt.Errorf("get books missing metadata: %w", err)
 	}
 
+	sort.Slice(allBooks, func(i, j int) bool { return allBooks[i].ID < allBooks[j].ID })
+	books := allBooks[:0]
+	for _, book := range allBooks {
+		if book.ID > opts.ResumeAfterID {
+			books = append(books, book)
+		}
+	}
+
 	result := &BulkEnrichmentResult{
 		TotalBooks: len(books),
 	}
@@ -245,6 +404,13 @@ func (e *Enricher) EnrichAllMissing(ctx context.Context) (*BulkEnrichmentResult,
 	}
 
 	for i, book := range books {
+		if i > 0 && opts.Delay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(opts.Delay):
+			}
+		}
+
 		select {
 		case <-ctx.Done():
 			result.Errors = append(result.Errors, "operation cancelled")
@@ -267,17 +433,22 @@ func (e *Enricher) EnrichAllMissing(ctx context.Context) (*BulkEnrichmentResult,
 		}
 
 		enrichResult, err := e.EnrichBook(ctx, book.ID)
-		if err != nil {
+		switch {
+		case err != nil:
 			result.Failed++
 			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", book.Title, err))
-			continue
-		}
-
-		if len(enrichResult.FieldsUpdated) > 0 {
+		case len(enrichResult.FieldsUpdated) > 0:
 			result.Enriched++
-		} else {
+		default:
 			result.Skipped++
 		}
+		if err == nil && len(enrichResult.ReviewFields) > 0 {
+			result.NeedsReview++
+		}
+
+		if opts.OnBook != nil {
+			opts.OnBook(i+1, len(books), book, err)
+		}
 	}
 
 	// Mark sync as complete
@@ -298,9 +469,9 @@ func (e *Enricher) buildUpdates(book *entities.Book, metadata *BookMetadata) (Bo
 	var updates BookUpdateFields
 	var fieldsUpdated []string
 
-	// Update ISBN if we found one and book doesn't have one
-	if book.ISBN == "" && metadata.ISBN != "" {
-		updates.ISBN = &metadata.ISBN
+	// Update ISBN if we found a valid one and book doesn't have one
+	if isbn, err := ParseISBN(metadata.ISBN); book.ISBN == "" && err == nil {
+		updates.ISBN = &isbn
 		fieldsUpdated = append(fieldsUpdated, "isbn")
 	}
 
@@ -322,5 +493,15 @@ func (e *Enricher) buildUpdates(book *entities.Book, metadata *BookMetadata) (Bo
 		fieldsUpdated = append(fieldsUpdated, "publication_year")
 	}
 
+	// Update series if not set; the index belongs to the series it came with
+	if book.Series == "" && metadata.Series != "" {
+		updates.Series = &metadata.Series
+		fieldsUpdated = append(fieldsUpdated, "series")
+		if metadata.SeriesIndex > 0 {
+			updates.SeriesIndex = &metadata.SeriesIndex
+			fieldsUpdated = append(fieldsUpdated, "series_index")
+		}
+	}
+
 	return updates, fieldsUpdated
 }
@@ -199,7 +199,7 @@ func TestEnrichBookWithISBN(t *testing.T) {
 	updater := &mockBookUpdater{book: book}
 	enricher := NewEnricher(provider, updater)
 
-	result, err := enricher.EnrichBookWithISBN(context.Background(), 1, "1234567890")
+	result, err := enricher.EnrichBookWithISBN(context.Background(), 1, "123456789X")
 	if err != nil {
 		t.Fatalf("EnrichBookWithISBN failed: %v", err)
 	}
@@ -208,8 +208,8 @@ func TestEnrichBookWithISBN(t *testing.T) {
 		t.Errorf("expected search method 'isbn', got %q", result.SearchMethod)
 	}
 
-	if result.Book.ISBN != "1234567890" {
-		t.Errorf("expected ISBN '1234567890', got %q", result.Book.ISBN)
+	if result.Book.ISBN != "123456789X" {
+		t.Errorf("expected ISBN '123456789X', got %q", result.Book.ISBN)
 	}
 }
 
@@ -237,7 +237,7 @@ func TestEnrichBookWithISBN_FallbackToTitle(t *testing.T) {
 	updater := &mockBookUpdater{book: book}
 	enricher := NewEnricher(provider, updater)
 
-	result, err := enricher.EnrichBookWithISBN(context.Background(), 1, "1234567890")
+	result, err := enricher.EnrichBookWithISBN(context.Background(), 1, "123456789X")
 	if err != nil {
 		t.Fatalf("EnrichBookWithISBN failed: %v", err)
 	}
@@ -274,7 +274,7 @@ func TestEnrichBookWithISBN_DoesNotSaveISBNOnFailure(t *testing.T) {
 	updater := &mockBookUpdater{book: book}
 	enricher := NewEnricher(provider, updater)
 
-	_, err := enricher.EnrichBookWithISBN(context.Background(), 1, "1234567890")
+	_, err := enricher.EnrichBookWithISBN(context.Background(), 1, "123456789X")
 	if err == nil {
 		t.Fatal("expected error when both searches fail")
 	}
@@ -285,6 +285,31 @@ func TestEnrichBookWithISBN_DoesNotSaveISBNOnFailure(t *testing.T) {
 	}
 }
 
+func TestLookupISBN(t *testing.T) {
+	provider := &mockMetadataProvider{
+		searchByISBNResult: &BookMetadata{Title: "Dune", Author: "Frank Herbert"},
+	}
+	enricher := NewEnricher(provider, &mockBookUpdater{})
+
+	metadata, err := enricher.LookupISBN(context.Background(), "978-0-441-17271-9")
+	if err != nil {
+		t.Fatalf("LookupISBN failed: %v", err)
+	}
+	if metadata.ISBN != "9780441172719" {
+		t.Errorf("expected normalized ISBN '9780441172719', got %q", metadata.ISBN)
+	}
+
+	if _, err := enricher.LookupISBN(context.Background(), "12345"); !errors.Is(err, ErrInvalidISBN) {
+		t.Errorf("expected ErrInvalidISBN, got %v", err)
+	}
+
+	provider.searchByISBNResult = nil
+	provider.searchByISBNError = errors.New("ISBN not found")
+	if _, err := enricher.LookupISBN(context.Background(), "9780441172719"); err == nil {
+		t.Error("expected error when ISBN is not found")
+	}
+}
+
 func TestBuildUpdates_OnlyEmptyFields(t *testing.T) {
 	book := &entities.Book{
 		ID:              1,
@@ -325,3 +350,170 @@ func TestBuildUpdates_OnlyEmptyFields(t *testing.T) {
 		t.Error("cover_url should be in fieldsUpdated")
 	}
 }
+
+func TestBuildUpdates_Series(t *testing.T) {
+	enricher := NewEnricher(nil, nil)
+	metadata := &BookMetadata{Series: "Dune Chronicles", SeriesIndex: 1}
+
+	updates, _ := enricher.buildUpdates(&entities.Book{Title: "Dune"}, metadata)
+	if updates.Series == nil || *updates.Series != "Dune Chronicles" {
+		t.Error("series should be set when the book has none")
+	}
+	if updates.SeriesIndex == nil || *updates.SeriesIndex != 1 {
+		t.Error("series index should be set with the series")
+	}
+
+	updates, _ = enricher.buildUpdates(&entities.Book{Title: "Dune", Series: "Dune", SeriesIndex: 1}, metadata)
+	if updates.Series != nil || updates.SeriesIndex != nil {
+		t.Error("series set by hand should not be replaced")
+	}
+}
+
+func TestEnrichMissing_ResumesInIDOrder(t *testing.T) {
+	provider := &mockMetadataProvider{
+		searchByTitleResult: &BookMetadata{Publisher: "Penguin"},
+	}
+	updater := &mockBookUpdater{
+		book:                 &entities.Book{ID: 1, Title: "Any"},
+		booksMissingMetadata: []entities.Book{{ID: 3, Title: "C"}, {ID: 1, Title: "A"}, {ID: 2, Title: "B"}},
+	}
+	enricher := NewEnricher(provider, updater)
+
+	var processed []uint
+	result, err := enricher.EnrichMissing(context.Background(), BulkEnrichmentOptions{
+		ResumeAfterID: 1,
+		OnBook: func(done, total int, book entities.Book, err error) {
+			if total != 2 {
+				t.Errorf("expected 2 books in run, got %d", total)
+			}
+			processed = append(processed, book.ID)
+		},
+	})
+	if err != nil {
+		t.Fatalf("EnrichMissing failed: %v", err)
+	}
+	if result.TotalBooks != 2 {
+		t.Errorf("expected 2 total books, got %d", result.TotalBooks)
+	}
+	if len(processed) != 2 || processed[0] != 2 || processed[1] != 3 {
+		t.Errorf("expected books [2 3], got %v", processed)
+	}
+}
+
+func TestSuggestMetadata(t *testing.T) {
+	book := &entities.Book{
+		ID:        1,
+		Title:     "Clean Code",
+		Author:    "Robert Martin",
+		Publisher: "Prentice Hall",
+	}
+
+	provider := &mockMetadataProvider{
+		searchByTitleResult: &BookMetadata{
+			Title:           "Clean Code",
+			Author:          "Robert C. Martin",
+			ISBN:            "978-0132350884",
+			Publisher:       "Prentice Hall",
+			PublicationYear: 2008,
+		},
+	}
+
+	updater := &mockBookUpdater{book: book}
+	enricher := NewEnricher(provider, updater)
+
+	result, err := enricher.SuggestMetadata(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("SuggestMetadata failed: %v", err)
+	}
+
+	if updater.updatedFields != nil {
+		t.Errorf("expected no fields to be saved, got %v", updater.updatedFields)
+	}
+
+	proposed := make(map[string]any)
+	for _, s := range result.Suggestions {
+		proposed[s.Field] = s.Proposed
+	}
+	expected := map[string]any{"author": "Robert C. Martin", "isbn": "9780132350884", "publication_year": 2008}
+	if len(proposed) != len(expected) {
+		t.Fatalf("expected suggestions %v, got %v", expected, proposed)
+	}
+	for field, value := range expected {
+		if proposed[field] != value {
+			t.Errorf("expected %s to be %v, got %v", field, value, proposed[field])
+		}
+	}
+}
+
+type stubCoverSource struct {
+	name     string
+	coverURL string
+	err      error
+}
+
+func (s *stubCoverSource) Name() string {
+	return s.name
+}
+
+func (s *stubCoverSource) FindCover(ctx context.Context, book *entities.Book) (string, error) {
+	return s.coverURL, s.err
+}
+
+func TestEnrichBook_PrefersCoverSources(t *testing.T) {
+	book := &entities.Book{ID: 1, Title: "Atomic Habits", Author: "James Clear", ASIN: "B07D23CFGR"}
+
+	provider := &mockMetadataProvider{
+		searchByTitleResult: &BookMetadata{
+			Title:     "Atomic Habits",
+			Publisher: "Avery",
+			CoverURL:  "https://covers.openlibrary.org/b/id/other-edition-L.jpg",
+		},
+	}
+
+	updater := &mockBookUpdater{book: book}
+	enricher := NewEnricher(provider, updater)
+	enricher.SetCoverSources(
+		&stubCoverSource{name: "failing", err: errors.New("timeout")},
+		&stubCoverSource{name: "empty"},
+		&stubCoverSource{name: CoverSourceAmazon, coverURL: "https://images.example.com/B07D23CFGR.jpg"},
+	)
+
+	result, err := enricher.EnrichBook(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("EnrichBook failed: %v", err)
+	}
+
+	if result.CoverSource != CoverSourceAmazon {
+		t.Errorf("expected cover source %q, got %q", CoverSourceAmazon, result.CoverSource)
+	}
+	if result.Book.CoverURL != "https://images.example.com/B07D23CFGR.jpg" {
+		t.Errorf("expected the ASIN cover, got %q", result.Book.CoverURL)
+	}
+	if result.Book.Publisher != "Avery" {
+		t.Errorf("expected publisher from the provider, got %q", result.Book.Publisher)
+	}
+}
+
+func TestEnrichBook_CoverWithoutMetadata(t *testing.T) {
+	book := &entities.Book{ID: 1, Title: "Obscure Novella", Author: "Unknown Author", ASIN: "B000000001"}
+
+	provider := &mockMetadataProvider{
+		searchByTitleError: errors.New("no results found"),
+	}
+
+	updater := &mockBookUpdater{book: book}
+	enricher := NewEnricher(provider, updater)
+	enricher.SetCoverSources(&stubCoverSource{name: CoverSourceAmazon, coverURL: "https://images.example.com/B000000001.jpg"})
+
+	result, err := enricher.EnrichBook(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("EnrichBook failed: %v", err)
+	}
+
+	if result.SearchMethod != "" {
+		t.Errorf("expected no search method, got %q", result.SearchMethod)
+	}
+	if result.Book.CoverURL != "https://images.example.com/B000000001.jpg" {
+		t.Errorf("expected the ASIN cover, got %q", result.Book.CoverURL)
+	}
+}
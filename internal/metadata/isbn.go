@@ -0,0 +1,129 @@
+package metadata
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrISBNChecksum is returned when the check digit of an ISBN does not match
+// its other digits, which usually means a mistyped digit.
+var ErrISBNChecksum = errors.New("ISBN check digit does not match")
+
+// ParseISBN cleans up an ISBN and verifies its check digit. Separators, an
+// "ISBN" prefix and a lowercase x are accepted, and a leading zero dropped by a
+// spreadsheet is restored when that makes the checksum match. Returns
+// ErrInvalidISBN unless 10 or 13 digits remain and ErrISBNChecksum when the
+// check digit is wrong.
+func ParseISBN(value string) (string, error) {
+	isbn := strings.ToUpper(strings.TrimSpace(value))
+	isbn = strings.TrimPrefix(strings.TrimPrefix(isbn, "ISBN-13"), "ISBN-10")
+	isbn = strings.TrimPrefix(isbn, "ISBN")
+	isbn = strings.NewReplacer("-", "", " ", "", ":", "").Replace(isbn)
+
+	if (len(isbn) == 9 || len(isbn) == 12) && ValidISBN("0"+isbn) {
+		isbn = "0" + isbn
+	}
+	if len(isbn) != 10 && len(isbn) != 13 {
+		return "", ErrInvalidISBN
+	}
+	if _, ok := isbnCheckDigit(isbn); !ok {
+		return "", ErrInvalidISBN
+	}
+	if !ValidISBN(isbn) {
+		return "", ErrISBNChecksum
+	}
+	return isbn, nil
+}
+
+// ValidISBN reports whether isbn is an ISBN-10 or ISBN-13 without separators
+// whose check digit matches.
+func ValidISBN(isbn string) bool {
+	check, ok := isbnCheckDigit(isbn)
+	return ok && isbn[len(isbn)-1] == check
+}
+
+// ISBN10To13 converts a valid ISBN-10 to its ISBN-13. Returns "" for anything
+// else.
+func ISBN10To13(isbn string) string {
+	if len(isbn) != 10 || !ValidISBN(isbn) {
+		return ""
+	}
+	isbn13 := "978" + isbn[:9] + "0"
+	check, _ := isbnCheckDigit(isbn13)
+	return isbn13[:12] + string(check)
+}
+
+// ISBN13To10 converts a valid ISBN-13 to its ISBN-10. Returns "" for anything
+// else, including ISBN-13s with the 979 prefix, which have no ISBN-10.
+func ISBN13To10(isbn string) string {
+	if len(isbn) != 13 || !strings.HasPrefix(isbn, "978") || !ValidISBN(isbn) {
+		return ""
+	}
+	isbn10 := isbn[3:12] + "0"
+	check, _ := isbnCheckDigit(isbn10)
+	return isbn10[:9] + string(check)
+}
+
+// ISBNForms returns the ISBN-13 and ISBN-10 of a valid ISBN in either form.
+// The ISBN-10 is "" for 979 ISBNs; both are "" for invalid ISBNs.
+func ISBNForms(isbn string) (isbn13, isbn10 string) {
+	switch {
+	case len(isbn) == 10 && ValidISBN(isbn):
+		return ISBN10To13(isbn), isbn
+	case len(isbn) == 13 && ValidISBN(isbn):
+		return isbn, ISBN13To10(isbn)
+	}
+	return "", ""
+}
+
+// sameISBN reports whether two ISBNs name the same book, in either form.
+func sameISBN(a, b string) bool {
+	a, b = NormalizeISBN(a), NormalizeISBN(b)
+	if a13, _ := ISBNForms(a); a13 != "" {
+		b13, _ := ISBNForms(b)
+		return a13 == b13
+	}
+	return a == b
+}
+
+// isbnCheckDigit computes the check digit an ISBN should end with from its
+// other digits. Returns false when the ISBN has the wrong length or contains
+// anything but digits, apart from an X check digit on an ISBN-10.
+func isbnCheckDigit(isbn string) (byte, bool) {
+	switch len(isbn) {
+	case 10:
+		sum := 0
+		for i := 0; i < 9; i++ {
+			if isbn[i] < '0' || isbn[i] > '9' {
+				return 0, false
+			}
+			sum += int(isbn[i]-'0') * (10 - i)
+		}
+		if last := isbn[9]; (last < '0' || last > '9') && last != 'X' {
+			return 0, false
+		}
+		check := (11 - sum%11) % 11
+		if check == 10 {
+			return 'X', true
+		}
+		return strconv.Itoa(check)[0], true
+	case 13:
+		sum := 0
+		for i := 0; i < 12; i++ {
+			if isbn[i] < '0' || isbn[i] > '9' {
+				return 0, false
+			}
+			digit := int(isbn[i] - '0')
+			if i%2 == 1 {
+				digit *= 3
+			}
+			sum += digit
+		}
+		if isbn[12] < '0' || isbn[12] > '9' {
+			return 0, false
+		}
+		return strconv.Itoa((10 - sum%10) % 10)[0], true
+	}
+	return 0, false
+}
@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseISBN(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		err      error
+	}{
+		{"978-0-13-468599-1", "9780134685991", nil},
+		{"ISBN 0-13-468599-7", "0134685997", nil},
+		{"ISBN-13: 978-0-441-17271-9", "9780441172719", nil},
+		{"080442957x", "080442957X", nil},
+		{"80442957X", "080442957X", nil}, // Leading zero dropped by a spreadsheet
+		{"979-10-90636-07-1", "9791090636071", nil},
+		{"978-0-13-468599-2", "", ErrISBNChecksum},
+		{"0134685996", "", ErrISBNChecksum},
+		{"97801346859X1", "", ErrInvalidISBN},
+		{"B07D23CFGR", "", ErrInvalidISBN},
+		{"12345", "", ErrInvalidISBN},
+		{"", "", ErrInvalidISBN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseISBN(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseISBN(%q) error = %v, expected %v", tt.input, err, tt.err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseISBN(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestISBNForms(t *testing.T) {
+	tests := []struct {
+		input  string
+		isbn13 string
+		isbn10 string
+	}{
+		{"0134685997", "9780134685991", "0134685997"},
+		{"9780134685991", "9780134685991", "0134685997"},
+		{"080442957X", "9780804429573", "080442957X"},
+		{"9780804429573", "9780804429573", "080442957X"},
+		{"9791090636071", "9791090636071", ""}, // 979 ISBNs have no ISBN-10
+		{"0134685996", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			isbn13, isbn10 := ISBNForms(tt.input)
+			if isbn13 != tt.isbn13 || isbn10 != tt.isbn10 {
+				t.Errorf("ISBNForms(%q) = %q, %q, expected %q, %q", tt.input, isbn13, isbn10, tt.isbn13, tt.isbn10)
+			}
+		})
+	}
+}
+
+type isbnRecordingProvider struct {
+	mockMetadataProvider
+	known    string
+	searched []string
+}
+
+func (p *isbnRecordingProvider) SearchByISBN(ctx context.Context, isbn string) (*BookMetadata, error) {
+	p.searched = append(p.searched, isbn)
+	if isbn != p.known {
+		return nil, errors.New("ISBN not found")
+	}
+	return &BookMetadata{Title: "Effective Java"}, nil
+}
+
+func TestLookupISBN_TriesOtherForm(t *testing.T) {
+	provider := &isbnRecordingProvider{known: "0134685997"}
+	enricher := NewEnricher(provider, &mockBookUpdater{})
+
+	metadata, err := enricher.LookupISBN(context.Background(), "978-0-13-468599-1")
+	if err != nil {
+		t.Fatalf("LookupISBN failed: %v", err)
+	}
+	if metadata.Title != "Effective Java" {
+		t.Errorf("expected metadata of the ISBN-10, got %q", metadata.Title)
+	}
+	if len(provider.searched) != 2 || provider.searched[1] != "0134685997" {
+		t.Errorf("expected a retry with the ISBN-10, searched %v", provider.searched)
+	}
+
+	provider.searched = nil
+	if _, err := enricher.LookupISBN(context.Background(), "978-0-13-468599-2"); !errors.Is(err, ErrISBNChecksum) {
+		t.Errorf("expected ErrISBNChecksum, got %v", err)
+	}
+	if len(provider.searched) != 0 {
+		t.Errorf("expected no search for an invalid ISBN, searched %v", provider.searched)
+	}
+}
@@ -22,6 +22,8 @@ type BookMetadata struct {
 	Description     string   `json:"description,omitempty"`
 	Subjects        []string `json:"subjects,omitempty"`
 	PageCount       int      `json:"page_count,omitempty"`
+	Series          string   `json:"series,omitempty"`
+	SeriesIndex     float64  `json:"series_index,omitempty"`
 	OpenLibraryKey  string   `json:"open_library_key,omitempty"`
 }
 
@@ -66,7 +68,7 @@ func NewOpenLibraryClient() *OpenLibraryClient {
 
 // SearchByISBN looks up a book by its ISBN and returns metadata.
 func (c *OpenLibraryClient) SearchByISBN(ctx context.Context, isbn string) (*BookMetadata, error) {
-	isbn = normalizeISBN(isbn)
+	isbn = NormalizeISBN(isbn)
 	if isbn == "" {
 		return nil, fmt.Errorf("invalid ISBN")
 	}
@@ -326,6 +328,11 @@ func (c *OpenLibraryClient) convertToMetadata(book *openLibraryBook, isbn string
 		metadata.Subjects = book.Subjects
 	}
 
+	// Extract series (first one)
+	if len(book.Series) > 0 {
+		metadata.Series, metadata.SeriesIndex = ParseSeries(book.Series[0])
+	}
+
 	return metadata
 }
 
@@ -358,6 +365,11 @@ func (c *OpenLibraryClient) enrichMetadataFromEdition(metadata *BookMetadata, ed
 	if metadata.PublicationYear == 0 && edition.PublishDate != "" {
 		metadata.PublicationYear = extractYear(edition.PublishDate)
 	}
+
+	// Extract series if missing
+	if metadata.Series == "" && len(edition.Series) > 0 {
+		metadata.Series, metadata.SeriesIndex = ParseSeries(edition.Series[0])
+	}
 }
 
 func (c *OpenLibraryClient) convertSearchDocToMetadata(doc *openLibrarySearchDoc) *BookMetadata {
@@ -395,8 +407,8 @@ func (c *OpenLibraryClient) convertSearchDocToMetadata(doc *openLibrarySearchDoc
 	return metadata
 }
 
-// normalizeISBN removes hyphens and spaces from ISBN.
-func normalizeISBN(isbn string) string {
+// NormalizeISBN removes hyphens and spaces from an ISBN. Returns "" unless 10 or 13 characters remain.
+func NormalizeISBN(isbn string) string {
 	isbn = strings.ReplaceAll(isbn, "-", "")
 	isbn = strings.ReplaceAll(isbn, " ", "")
 	isbn = strings.TrimSpace(isbn)
@@ -456,6 +468,7 @@ type openLibraryBook struct {
 	NumberOfPages int         `json:"number_of_pages"`
 	Description   any         `json:"description"` // Can be string or {type, value}
 	Subjects      []string    `json:"subjects"`
+	Series        []string    `json:"series"`
 	Covers        []int       `json:"covers"`
 }
 
@@ -488,5 +501,6 @@ type openLibraryEdition struct {
 	ISBN10        []string `json:"isbn_10"`
 	ISBN13        []string `json:"isbn_13"`
 	NumberOfPages int      `json:"number_of_pages"`
+	Series        []string `json:"series"`
 	Covers        []int    `json:"covers"`
 }
@@ -27,9 +27,9 @@ func TestNormalizeISBN(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			result := normalizeISBN(tt.input)
+			result := NormalizeISBN(tt.input)
 			if result != tt.expected {
-				t.Errorf("normalizeISBN(%q) = %q, expected %q", tt.input, result, tt.expected)
+				t.Errorf("NormalizeISBN(%q) = %q, expected %q", tt.input, result, tt.expected)
 			}
 		})
 	}
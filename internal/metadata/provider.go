@@ -0,0 +1,22 @@
+package metadata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProviderOpenLibrary is the name of the OpenLibrary metadata provider.
+const ProviderOpenLibrary = "openlibrary"
+
+// Providers lists the provider names accepted by NewProvider.
+var Providers = []string{ProviderOpenLibrary}
+
+// NewProvider creates the metadata provider with the given name.
+func NewProvider(name string) (MetadataProvider, error) {
+	switch name {
+	case ProviderOpenLibrary:
+		return NewOpenLibraryClient(), nil
+	default:
+		return nil, fmt.Errorf("unknown metadata provider %q (available: %s)", name, strings.Join(Providers, ", "))
+	}
+}
@@ -0,0 +1,55 @@
+package metadata
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// seriesPatterns match a series name followed by the book's position, as
+// OpenLibrary and Goodreads write them: "Foundation series ; 1",
+// "Discworld -- 12", "Dune, #1", "The Expanse #3", "Dune Chronicles, book 1".
+var seriesPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(.+?)\s*(?:;|--|,)\s*(?:#|no\.?\s*|vol\.?\s*|volume\s+|book\s+|bk\.?\s*)?(\d+(?:\.\d+)?)\s*$`),
+	regexp.MustCompile(`^(.+?)\s+(?:#|no\.\s*|vol\.\s*|volume\s+|book\s+)(\d+(?:\.\d+)?)\s*$`),
+}
+
+// ParseSeries splits a series statement into the series name and the book's
+// position in it. The position is 0 when the statement has none, e.g.
+// "Penguin classics".
+func ParseSeries(statement string) (string, float64) {
+	statement = strings.Join(strings.Fields(statement), " ")
+	statement = strings.Trim(statement, "()[] ")
+	if statement == "" {
+		return "", 0
+	}
+	for _, pattern := range seriesPatterns {
+		match := pattern.FindStringSubmatch(strings.ToLower(statement))
+		if match == nil {
+			continue
+		}
+		index, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			continue
+		}
+		// Keep the original case of the name
+		return strings.TrimSpace(statement[:len(match[1])]), index
+	}
+	return statement, 0
+}
+
+// titleSeriesPattern matches a series suffix on a title, as Goodreads writes
+// them: "Dune (Dune, #1)".
+var titleSeriesPattern = regexp.MustCompile(`^(.+?)\s*\(([^()]+,\s*#\d+(?:\.\d+)?)\)\s*$`)
+
+// SplitTitleSeries splits a title with a series suffix such as
+// "Dune (Dune, #1)" into the title and the series name and position. Titles
+// without one are returned unchanged.
+func SplitTitleSeries(title string) (string, string, float64) {
+	match := titleSeriesPattern.FindStringSubmatch(title)
+	if match == nil {
+		return title, "", 0
+	}
+	series, index := ParseSeries(match[2])
+	return strings.TrimSpace(match[1]), series, index
+}
@@ -0,0 +1,54 @@
+package metadata
+
+import "testing"
+
+func TestParseSeries(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedName  string
+		expectedIndex float64
+	}{
+		{"Foundation series ; 1", "Foundation series", 1},
+		{"Discworld -- 12", "Discworld", 12},
+		{"Dune, #1", "Dune", 1},
+		{"The Expanse #3", "The Expanse", 3},
+		{"Dune Chronicles, book 1", "Dune Chronicles", 1},
+		{"(The Witcher, #0.5)", "The Witcher", 0.5},
+		{"Harry Potter, Vol. 2", "Harry Potter", 2},
+		{"Penguin classics", "Penguin classics", 0},
+		{"", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			name, index := ParseSeries(tt.input)
+			if name != tt.expectedName || index != tt.expectedIndex {
+				t.Errorf("ParseSeries(%q) = (%q, %g), expected (%q, %g)", tt.input, name, index, tt.expectedName, tt.expectedIndex)
+			}
+		})
+	}
+}
+
+func TestSplitTitleSeries(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedTitle  string
+		expectedSeries string
+		expectedIndex  float64
+	}{
+		{"Dune (Dune, #1)", "Dune", "Dune", 1},
+		{"The Last Wish (The Witcher, #0.5)", "The Last Wish", "The Witcher", 0.5},
+		{"Sapiens: A Brief History of Humankind", "Sapiens: A Brief History of Humankind", "", 0},
+		{"Meditations (Penguin Classics)", "Meditations (Penguin Classics)", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			title, series, index := SplitTitleSeries(tt.input)
+			if title != tt.expectedTitle || series != tt.expectedSeries || index != tt.expectedIndex {
+				t.Errorf("SplitTitleSeries(%q) = (%q, %q, %g), expected (%q, %q, %g)",
+					tt.input, title, series, index, tt.expectedTitle, tt.expectedSeries, tt.expectedIndex)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// FieldSuggestion is a book field whose value from the metadata provider
+// differs from the stored one.
+type FieldSuggestion struct {
+	Field    string `json:"field"` // JSON name of the book field, e.g. "publisher"
+	Current  any    `json:"current"`
+	Proposed any    `json:"proposed"`
+}
+
+// SuggestionResult lists the changes the provider proposes for a book.
+type SuggestionResult struct {
+	Book         *entities.Book    `json:"book"`
+	Suggestions  []FieldSuggestion `json:"suggestions"`
+	Source       string            `json:"source"`
+	SearchMethod string            `json:"search_method"` // "isbn" or "title"
+}
+
+// SuggestMetadata fetches metadata for a book like EnrichBook, but returns the
+// differences field by field instead of saving them, so each can be accepted
+// or rejected. Fields the provider has no value for are left out.
+func (e *Enricher) SuggestMetadata(ctx context.Context, bookID uint) (*SuggestionResult, error) {
+	book, err := e.db.GetBookByID(bookID)
+	if err != nil {
+		return nil, fmt.Errorf("get book: %w", err)
+	}
+
+	metadata, searchMethod, err := e.search(ctx, book)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SuggestionResult{
+		Book:         book,
+		Suggestions:  diffMetadata(book, metadata),
+		Source:       "openlibrary",
+		SearchMethod: searchMethod,
+	}, nil
+}
+
+// diffMetadata compares a book with fetched metadata
+func diffMetadata(book *entities.Book, metadata *BookMetadata) []FieldSuggestion {
+	suggestions := []FieldSuggestion{}
+	suggestText := func(field, current, proposed string) {
+		proposed = strings.TrimSpace(proposed)
+		if proposed != "" && proposed != strings.TrimSpace(current) {
+			suggestions = append(suggestions, FieldSuggestion{Field: field, Current: current, Proposed: proposed})
+		}
+	}
+
+	suggestText("title", book.Title, metadata.Title)
+	suggestText("author", book.Author, metadata.Author)
+	if isbn, err := ParseISBN(metadata.ISBN); err == nil && !sameISBN(isbn, book.ISBN) {
+		suggestions = append(suggestions, FieldSuggestion{Field: "isbn", Current: book.ISBN, Proposed: isbn})
+	}
+	suggestText("cover_url", book.CoverURL, metadata.CoverURL)
+	suggestText("publisher", book.Publisher, metadata.Publisher)
+	if metadata.PublicationYear > 0 && metadata.PublicationYear != book.PublicationYear {
+		suggestions = append(suggestions, FieldSuggestion{Field: "publication_year", Current: book.PublicationYear, Proposed: metadata.PublicationYear})
+	}
+	suggestText("series", book.Series, metadata.Series)
+	if metadata.Series != "" && metadata.SeriesIndex > 0 && metadata.SeriesIndex != book.SeriesIndex {
+		suggestions = append(suggestions, FieldSuggestion{Field: "series_index", Current: book.SeriesIndex, Proposed: metadata.SeriesIndex})
+	}
+	return suggestions
+}
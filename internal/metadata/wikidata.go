@@ -0,0 +1,261 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrAuthorNotFound is returned when Wikidata has no person matching an author name.
+var ErrAuthorNotFound = errors.New("author not found on Wikidata")
+
+// AuthorMetadata contains author information from Wikidata and Wikipedia.
+type AuthorMetadata struct {
+	WikidataID   string   `json:"wikidata_id"`
+	Name         string   `json:"name"`              // English label, used as the canonical name
+	Aliases      []string `json:"aliases,omitempty"` // English aliases and the name in the native language
+	PhotoURL     string   `json:"photo_url,omitempty"`
+	BirthYear    int      `json:"birth_year,omitempty"`
+	DeathYear    int      `json:"death_year,omitempty"`
+	Bio          string   `json:"bio,omitempty"` // Wikipedia summary, or the Wikidata description without one
+	WikipediaURL string   `json:"wikipedia_url,omitempty"`
+}
+
+// Wikidata properties and items used to describe authors
+const (
+	wikidataInstanceOf = "P31"
+	wikidataHuman      = "Q5"
+	wikidataImage      = "P18"
+	wikidataBirthDate  = "P569"
+	wikidataDeathDate  = "P570"
+	wikidataNativeName = "P1559"
+
+	// wikidataCandidates is how many search results are checked for a person
+	wikidataCandidates = 5
+)
+
+// WikidataClient looks authors up on Wikidata, with a short bio from Wikipedia.
+type WikidataClient struct {
+	httpClient   *http.Client
+	wikidataURL  string
+	wikipediaURL string
+	commonsURL   string
+	rateLimiter  *rateLimiter
+}
+
+// NewWikidataClient creates a new Wikidata API client with rate limiting.
+func NewWikidataClient() *WikidataClient {
+	return &WikidataClient{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		wikidataURL:  "https://www.wikidata.org",
+		wikipediaURL: "https://en.wikipedia.org",
+		commonsURL:   "https://commons.wikimedia.org",
+		rateLimiter:  newRateLimiter(time.Second), // 1 request per second
+	}
+}
+
+type wikidataSearchResponse struct {
+	Search []struct {
+		ID string `json:"id"`
+	} `json:"search"`
+}
+
+type wikidataText struct {
+	Value string `json:"value"`
+}
+
+type wikidataEntity struct {
+	ID           string                    `json:"id"`
+	Labels       map[string]wikidataText   `json:"labels"`
+	Descriptions map[string]wikidataText   `json:"descriptions"`
+	Aliases      map[string][]wikidataText `json:"aliases"`
+	Claims       map[string][]struct {
+		Mainsnak struct {
+			Datavalue struct {
+				Value json.RawMessage `json:"value"`
+			} `json:"datavalue"`
+		} `json:"mainsnak"`
+	} `json:"claims"`
+	Sitelinks map[string]struct {
+		Title string `json:"title"`
+	} `json:"sitelinks"`
+}
+
+type wikidataEntitiesResponse struct {
+	Entities map[string]wikidataEntity `json:"entities"`
+}
+
+// SearchAuthor finds the person best matching an author name. Returns
+// ErrAuthorNotFound when none of the top search results is a person.
+func (c *WikidataClient) SearchAuthor(ctx context.Context, name string) (*AuthorMetadata, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("author name is required")
+	}
+
+	params := url.Values{}
+	params.Set("action", "wbsearchentities")
+	params.Set("search", name)
+	params.Set("language", "en")
+	params.Set("type", "item")
+	params.Set("limit", strconv.Itoa(wikidataCandidates))
+	params.Set("format", "json")
+
+	var search wikidataSearchResponse
+	if err := c.getJSON(ctx, c.wikidataURL+"/w/api.php?"+params.Encode(), &search); err != nil {
+		return nil, fmt.Errorf("search Wikidata: %w", err)
+	}
+	if len(search.Search) == 0 {
+		return nil, ErrAuthorNotFound
+	}
+
+	ids := make([]string, len(search.Search))
+	for i, result := range search.Search {
+		ids[i] = result.ID
+	}
+	params = url.Values{}
+	params.Set("action", "wbgetentities")
+	params.Set("ids", strings.Join(ids, "|"))
+	params.Set("props", "labels|descriptions|aliases|claims|sitelinks")
+	params.Set("languages", "en")
+	params.Set("sitefilter", "enwiki")
+	params.Set("format", "json")
+
+	var entities wikidataEntitiesResponse
+	if err := c.getJSON(ctx, c.wikidataURL+"/w/api.php?"+params.Encode(), &entities); err != nil {
+		return nil, fmt.Errorf("fetch Wikidata entities: %w", err)
+	}
+
+	// Search results are ordered by relevance; take the first person
+	for _, id := range ids {
+		entity, ok := entities.Entities[id]
+		if !ok || !entity.isHuman() {
+			continue
+		}
+		author := c.convertToAuthor(&entity)
+		if author.WikipediaURL != "" {
+			if summary, err := c.fetchSummary(ctx, entity.Sitelinks["enwiki"].Title); err == nil && summary != "" {
+				author.Bio = summary
+			}
+		}
+		return author, nil
+	}
+	return nil, ErrAuthorNotFound
+}
+
+func (e *wikidataEntity) isHuman() bool {
+	for _, claim := range e.Claims[wikidataInstanceOf] {
+		var item struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(claim.Mainsnak.Datavalue.Value, &item) == nil && item.ID == wikidataHuman {
+			return true
+		}
+	}
+	return false
+}
+
+// claimYear returns the year of the first date claim of a property
+func (e *wikidataEntity) claimYear(property string) int {
+	for _, claim := range e.Claims[property] {
+		var date struct {
+			Time string `json:"time"` // e.g. +1828-09-09T00:00:00Z
+		}
+		if json.Unmarshal(claim.Mainsnak.Datavalue.Value, &date) != nil || len(date.Time) < 2 {
+			continue
+		}
+		yearPart, _, _ := strings.Cut(date.Time[1:], "-")
+		if year, err := strconv.Atoi(yearPart); err == nil && year > 0 {
+			return year
+		}
+	}
+	return 0
+}
+
+func (c *WikidataClient) convertToAuthor(entity *wikidataEntity) *AuthorMetadata {
+	author := &AuthorMetadata{
+		WikidataID: entity.ID,
+		Name:       entity.Labels["en"].Value,
+		BirthYear:  entity.claimYear(wikidataBirthDate),
+		DeathYear:  entity.claimYear(wikidataDeathDate),
+		Bio:        entity.Descriptions["en"].Value,
+	}
+
+	seen := map[string]bool{strings.ToLower(author.Name): true}
+	addAlias := func(alias string) {
+		alias = strings.TrimSpace(alias)
+		if alias != "" && !seen[strings.ToLower(alias)] {
+			seen[strings.ToLower(alias)] = true
+			author.Aliases = append(author.Aliases, alias)
+		}
+	}
+	for _, alias := range entity.Aliases["en"] {
+		addAlias(alias.Value)
+	}
+	for _, claim := range entity.Claims[wikidataNativeName] {
+		var native struct {
+			Text string `json:"text"`
+		}
+		if json.Unmarshal(claim.Mainsnak.Datavalue.Value, &native) == nil {
+			addAlias(native.Text)
+		}
+	}
+
+	for _, claim := range entity.Claims[wikidataImage] {
+		var file string
+		if json.Unmarshal(claim.Mainsnak.Datavalue.Value, &file) == nil && file != "" {
+			author.PhotoURL = fmt.Sprintf("%s/wiki/Special:FilePath/%s?width=300",
+				c.commonsURL, url.PathEscape(strings.ReplaceAll(file, " ", "_")))
+			break
+		}
+	}
+
+	if title := entity.Sitelinks["enwiki"].Title; title != "" {
+		author.WikipediaURL = c.wikipediaURL + "/wiki/" + url.PathEscape(strings.ReplaceAll(title, " ", "_"))
+	}
+	return author
+}
+
+// fetchSummary returns the introduction of a Wikipedia article
+func (c *WikidataClient) fetchSummary(ctx context.Context, title string) (string, error) {
+	var summary struct {
+		Extract string `json:"extract"`
+	}
+	endpoint := c.wikipediaURL + "/api/rest_v1/page/summary/" + url.PathEscape(strings.ReplaceAll(title, " ", "_"))
+	if err := c.getJSON(ctx, endpoint, &summary); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(summary.Extract), nil
+}
+
+func (c *WikidataClient) getJSON(ctx context.Context, endpoint string, target any) error {
+	c.rateLimiter.wait()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "HighlightsManager/1.0 (https://github.com/mrlokans/assistant)")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
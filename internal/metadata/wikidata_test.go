@@ -0,0 +1,100 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const wikidataEntitiesFixture = `{"entities": {
+	"Q1": {"id": "Q1", "labels": {"en": {"value": "War and Peace"}},
+		"claims": {"P31": [{"mainsnak": {"datavalue": {"value": {"id": "Q7725634"}}}}]}},
+	"Q7243": {"id": "Q7243",
+		"labels": {"en": {"value": "Leo Tolstoy"}},
+		"descriptions": {"en": {"value": "Russian writer (1828–1910)"}},
+		"aliases": {"en": [{"value": "Lev Tolstoy"}, {"value": "leo tolstoy"}]},
+		"claims": {
+			"P31": [{"mainsnak": {"datavalue": {"value": {"id": "Q5"}}}}],
+			"P18": [{"mainsnak": {"datavalue": {"value": "L.N.Tolstoy Prokudin-Gorsky.jpg"}}}],
+			"P569": [{"mainsnak": {"datavalue": {"value": {"time": "+1828-09-09T00:00:00Z"}}}}],
+			"P570": [{"mainsnak": {"datavalue": {"value": {"time": "+1910-11-20T00:00:00Z"}}}}],
+			"P1559": [{"mainsnak": {"datavalue": {"value": {"text": "Лев Николаевич Толстой", "language": "ru"}}}}]
+		},
+		"sitelinks": {"enwiki": {"title": "Leo Tolstoy"}}}
+}}`
+
+func newTestWikidataClient(handler http.HandlerFunc) (*WikidataClient, func()) {
+	server := httptest.NewServer(handler)
+	client := &WikidataClient{
+		httpClient:   &http.Client{Timeout: 5 * time.Second},
+		wikidataURL:  server.URL,
+		wikipediaURL: server.URL,
+		commonsURL:   server.URL,
+		rateLimiter:  newRateLimiter(0),
+	}
+	return client, server.Close
+}
+
+func TestWikidataSearchAuthor(t *testing.T) {
+	client, cleanup := newTestWikidataClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/w/api.php" && r.URL.Query().Get("action") == "wbsearchentities":
+			if r.URL.Query().Get("search") != "Tolstoy" {
+				t.Errorf("unexpected search %q", r.URL.Query().Get("search"))
+			}
+			_, _ = w.Write([]byte(`{"search": [{"id": "Q1"}, {"id": "Q7243"}]}`))
+		case r.URL.Path == "/w/api.php" && r.URL.Query().Get("action") == "wbgetentities":
+			_, _ = w.Write([]byte(wikidataEntitiesFixture))
+		case r.URL.Path == "/api/rest_v1/page/summary/Leo_Tolstoy":
+			_, _ = w.Write([]byte(`{"extract": "Count Lev Nikolayevich Tolstoy was a Russian writer."}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	author, err := client.SearchAuthor(context.Background(), "Tolstoy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if author.WikidataID != "Q7243" {
+		t.Errorf("expected the first person in the results, got %q", author.WikidataID)
+	}
+	if author.Name != "Leo Tolstoy" {
+		t.Errorf("expected name 'Leo Tolstoy', got %q", author.Name)
+	}
+	if author.BirthYear != 1828 || author.DeathYear != 1910 {
+		t.Errorf("expected years 1828–1910, got %d–%d", author.BirthYear, author.DeathYear)
+	}
+	if author.Bio != "Count Lev Nikolayevich Tolstoy was a Russian writer." {
+		t.Errorf("expected the Wikipedia summary as bio, got %q", author.Bio)
+	}
+	if len(author.Aliases) != 2 || author.Aliases[0] != "Lev Tolstoy" || author.Aliases[1] != "Лев Николаевич Толстой" {
+		t.Errorf("unexpected aliases %q", author.Aliases)
+	}
+	expectedPhoto := client.commonsURL + "/wiki/Special:FilePath/L.N.Tolstoy_Prokudin-Gorsky.jpg?width=300"
+	if author.PhotoURL != expectedPhoto {
+		t.Errorf("expected photo %q, got %q", expectedPhoto, author.PhotoURL)
+	}
+	if author.WikipediaURL != client.wikipediaURL+"/wiki/Leo_Tolstoy" {
+		t.Errorf("unexpected Wikipedia URL %q", author.WikipediaURL)
+	}
+}
+
+func TestWikidataSearchAuthor_NotFound(t *testing.T) {
+	client, cleanup := newTestWikidataClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"search": []}`))
+	})
+	defer cleanup()
+
+	_, err := client.SearchAuthor(context.Background(), "Nobody")
+	if !errors.Is(err, ErrAuthorNotFound) {
+		t.Errorf("expected ErrAuthorNotFound, got %v", err)
+	}
+}
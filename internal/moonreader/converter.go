@@ -41,6 +41,7 @@ func ConvertNoteToHighlight(note *LocalNote) entities.Highlight {
 		Color:         note.GetColorHex(),
 		Chapter:       note.Bookmark,
 		ExternalID:    note.ExternalID,
+		LocationRef:   note.Position,
 	}
 
 	// Set style based on formatting
@@ -3,6 +3,7 @@ package moonreader
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,6 +20,10 @@ const (
 	defaultDropboxPath = "/Apps/Books/.Moon+/Backup"
 )
 
+// ErrDropboxUnauthorized is returned when Dropbox rejects the access token,
+// e.g. because it expired or was revoked.
+var ErrDropboxUnauthorized = errors.New("dropbox rejected the access token")
+
 // DropboxClient handles interactions with Dropbox API
 type DropboxClient struct {
 	accessToken string
@@ -77,7 +82,7 @@ func (c *DropboxClient) ListBackupFiles() ([]DropboxFileEntry, error) {
 	// Filter for backup files only
 	var backupFiles []DropboxFileEntry
 	for _, entry := range allEntries {
-		if entry.Tag == "file" && isBackupFile(entry.Name) {
+		if entry.Tag == "file" && IsBackupFile(entry.Name) {
 			backupFiles = append(backupFiles, entry)
 		}
 	}
@@ -119,8 +124,7 @@ func (c *DropboxClient) listFolder() ([]DropboxFileEntry, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("dropbox API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, dropboxAPIError(resp)
 	}
 
 	var listResp dropboxListFolderResponse
@@ -167,8 +171,7 @@ func (c *DropboxClient) listFolderContinue(cursor string) (dropboxListFolderResp
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return dropboxListFolderResponse{}, fmt.Errorf("dropbox API error (status %d): %s", resp.StatusCode, string(body))
+		return dropboxListFolderResponse{}, dropboxAPIError(resp)
 	}
 
 	var listResp dropboxListFolderResponse
@@ -224,8 +227,7 @@ func (c *DropboxClient) DownloadFile(dropboxPath, localPath string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("dropbox API error (status %d): %s", resp.StatusCode, string(body))
+		return dropboxAPIError(resp)
 	}
 
 	// Ensure parent directory exists
@@ -248,6 +250,15 @@ func (c *DropboxClient) DownloadFile(dropboxPath, localPath string) error {
 	return nil
 }
 
+// dropboxAPIError reads the error response of a failed Dropbox API call.
+func dropboxAPIError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%w (status %d): %s", ErrDropboxUnauthorized, resp.StatusCode, string(body))
+	}
+	return fmt.Errorf("dropbox API error (status %d): %s", resp.StatusCode, string(body))
+}
+
 // DownloadLatestBackup downloads the latest backup file to a temporary location
 // Returns the path to the downloaded file and the temp directory (caller must clean up)
 func (c *DropboxClient) DownloadLatestBackup() (filePath string, tempDir string, modTime time.Time, err error) {
@@ -270,8 +281,8 @@ func (c *DropboxClient) DownloadLatestBackup() (filePath string, tempDir string,
 	return localPath, tempDir, backup.ServerModified, nil
 }
 
-// isBackupFile checks if a filename is a MoonReader backup file
-func isBackupFile(name string) bool {
+// IsBackupFile checks if a filename is a MoonReader backup file
+func IsBackupFile(name string) bool {
 	lower := strings.ToLower(name)
 	return strings.HasSuffix(lower, ".mrstd") || strings.HasSuffix(lower, ".mrpro")
 }
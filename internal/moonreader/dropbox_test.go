@@ -96,7 +96,7 @@ func TestIsBackupFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isBackupFile(tt.filename)
+			result := IsBackupFile(tt.filename)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
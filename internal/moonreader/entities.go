@@ -1,6 +1,7 @@
 package moonreader
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/mrlokans/assistant/internal/utils"
@@ -19,6 +20,9 @@ type MoonReaderNote struct {
 	Original       string // original (highlighted text)
 	Underline      int    // underline flag
 	Strikethrough  int    // strikethrough flag
+	LastChapter    int64  // lastChapter, chapter index of the highlight
+	LastSplitIndex int64  // lastSplitIndex, page split within the chapter
+	LastPosition   int64  // lastPosition, character offset within the split
 }
 
 // GetTime converts the millisecond timestamp to a time.Time
@@ -53,6 +57,15 @@ func (n *MoonReaderNote) GetText() string {
 	return n.Note
 }
 
+// GetPosition returns the reading position of the highlight as
+// "chapter@split#position", or "" for backups without positions.
+func (n *MoonReaderNote) GetPosition() string {
+	if n.LastChapter == 0 && n.LastSplitIndex == 0 && n.LastPosition == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d@%d#%d", n.LastChapter, n.LastSplitIndex, n.LastPosition)
+}
+
 // GetAuthor attempts to extract author from the filename
 func (n *MoonReaderNote) GetAuthor() string {
 	return utils.ExtractAuthorFromFilename(n.Filename, n.BookTitle)
@@ -72,6 +85,7 @@ type LocalNote struct {
 	Original      string    // original (highlighted text)
 	Underline     bool      // underline flag
 	Strikethrough bool      // strikethrough flag
+	Position      string    // position, see MoonReaderNote.GetPosition
 }
 
 // GetText returns the highlight text, preferring original over note
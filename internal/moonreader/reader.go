@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
@@ -27,6 +28,17 @@ func (r *BackupDBReader) GetNotes() ([]*MoonReaderNote, error) {
 	}
 	defer db.Close()
 
+	// Older backups have no reading positions
+	positionColumns := "0, 0, 0"
+	var hasPositions int
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('notes') WHERE name = 'lastPosition'`).Scan(&hasPositions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect notes table: %w", err)
+	}
+	if hasPositions > 0 {
+		positionColumns = "lastChapter, lastSplitIndex, lastPosition"
+	}
+
 	query := `
 		SELECT
 			_id,
@@ -38,7 +50,8 @@ func (r *BackupDBReader) GetNotes() ([]*MoonReaderNote, error) {
 			note,
 			original,
 			underline,
-			strikethrough
+			strikethrough,
+			` + positionColumns + `
 		FROM notes;
 	`
 
@@ -53,6 +66,7 @@ func (r *BackupDBReader) GetNotes() ([]*MoonReaderNote, error) {
 		note := &MoonReaderNote{}
 		var bookmark, noteText, original sql.NullString
 		var underline, strikethrough sql.NullInt64
+		var lastChapter, lastSplitIndex, lastPosition sql.NullInt64
 
 		err := rows.Scan(
 			&note.ID,
@@ -65,6 +79,9 @@ func (r *BackupDBReader) GetNotes() ([]*MoonReaderNote, error) {
 			&original,
 			&underline,
 			&strikethrough,
+			&lastChapter,
+			&lastSplitIndex,
+			&lastPosition,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
@@ -79,6 +96,9 @@ func (r *BackupDBReader) GetNotes() ([]*MoonReaderNote, error) {
 		if strikethrough.Valid {
 			note.Strikethrough = int(strikethrough.Int64)
 		}
+		note.LastChapter = lastChapter.Int64
+		note.LastSplitIndex = lastSplitIndex.Int64
+		note.LastPosition = lastPosition.Int64
 
 		notes = append(notes, note)
 	}
@@ -141,7 +161,8 @@ func (a *LocalDBAccessor) ensureSchema() error {
 			note TEXT,
 			original TEXT,
 			underline NUMERIC NOT NULL,
-			strikethrough NUMERIC NOT NULL
+			strikethrough NUMERIC NOT NULL,
+			position TEXT NOT NULL DEFAULT ''
 		);`,
 	}
 
@@ -151,6 +172,12 @@ func (a *LocalDBAccessor) ensureSchema() error {
 		}
 	}
 
+	// Databases created before positions were stored
+	_, err := a.db.Exec(`ALTER TABLE moonreader_notes ADD COLUMN position TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column") {
+		return fmt.Errorf("failed to add position column: %w", err)
+	}
+
 	return nil
 }
 
@@ -164,8 +191,8 @@ func (a *LocalDBAccessor) UpsertNotes(notes []*MoonReaderNote) error {
 
 	stmt, err := tx.Prepare(`
 		INSERT INTO moonreader_notes
-			(exported_id, book_title, filename, color, time, bookmark, note, original, underline, strikethrough)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			(exported_id, book_title, filename, color, time, bookmark, note, original, underline, strikethrough, position)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT (exported_id) DO UPDATE SET
 			book_title=excluded.book_title,
 			filename=excluded.filename,
@@ -175,7 +202,8 @@ func (a *LocalDBAccessor) UpsertNotes(notes []*MoonReaderNote) error {
 			note=excluded.note,
 			original=excluded.original,
 			underline=excluded.underline,
-			strikethrough=excluded.strikethrough
+			strikethrough=excluded.strikethrough,
+			position=excluded.position
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
@@ -194,6 +222,7 @@ func (a *LocalDBAccessor) UpsertNotes(notes []*MoonReaderNote) error {
 			note.Original,
 			note.Underline,
 			note.Strikethrough,
+			note.GetPosition(),
 		)
 		if err != nil {
 			return fmt.Errorf("failed to upsert note %d: %w", note.ID, err)
@@ -216,7 +245,8 @@ func (a *LocalDBAccessor) GetNotes() ([]*LocalNote, error) {
 			note,
 			original,
 			underline,
-			strikethrough
+			strikethrough,
+			position
 		FROM moonreader_notes;
 	`
 
@@ -243,6 +273,7 @@ func (a *LocalDBAccessor) GetNotes() ([]*LocalNote, error) {
 			&original,
 			&underline,
 			&strikethrough,
+			&note.Position,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
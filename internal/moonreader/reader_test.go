@@ -44,6 +44,9 @@ func TestLocalDBAccessor_UpsertAndGetNotes(t *testing.T) {
 			Original:       "Highlighted text",
 			Underline:      1,
 			Strikethrough:  0,
+			LastChapter:    3,
+			LastSplitIndex: 0,
+			LastPosition:   1542,
 		},
 		{
 			ID:             2,
@@ -74,6 +77,8 @@ func TestLocalDBAccessor_UpsertAndGetNotes(t *testing.T) {
 	assert.Equal(t, "Highlighted text", retrieved[0].Original)
 	assert.True(t, retrieved[0].Underline)
 	assert.False(t, retrieved[0].Strikethrough)
+	assert.Equal(t, "3@0#1542", retrieved[0].Position)
+	assert.Empty(t, retrieved[1].Position)
 
 	// Test upsert (update existing)
 	notes[0].Original = "Updated highlight"
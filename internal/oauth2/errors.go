@@ -7,4 +7,8 @@ var (
 	ErrNoRefreshToken   = errors.New("no refresh token available")
 	ErrTokenExpired     = errors.New("token expired")
 	ErrProviderNotFound = errors.New("provider not registered")
+
+	ErrAuthorizationDenied = errors.New("authorization denied")
+	ErrMissingCode         = errors.New("missing state or authorization code")
+	ErrInvalidState        = errors.New("invalid or expired state")
 )
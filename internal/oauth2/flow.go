@@ -246,35 +246,8 @@ func (h *FlowHandler) exchangeAndSave(
 	return result, nil
 }
 
-// WebFlowConfig configures web-based OAuth2 flows
-type WebFlowConfig struct {
-	RedirectURL string
-	State       string // If empty, a random state will be generated
-}
-
-// StartWebFlow initiates a web-based OAuth2 flow
-// Returns the authorization URL and state for later verification
-func (h *FlowHandler) StartWebFlow(cfg WebFlowConfig) (authURL, state, codeVerifier string, err error) {
-	authURL, codeVerifier, state, err = h.provider.BuildAuthURL(cfg.RedirectURL)
-	if cfg.State != "" {
-		// Replace generated state with provided state
-		// Note: The provider's BuildAuthURL already includes state in the URL,
-		// so this would require rebuilding or string replacement
-		// For simplicity, we document that custom state requires URL manipulation
-		state = cfg.State
-	}
-	return
-}
-
-// CompleteWebFlow completes a web-based OAuth2 flow after receiving the callback
-func (h *FlowHandler) CompleteWebFlow(
-	ctx context.Context,
-	code, codeVerifier, redirectURL, expectedState, receivedState string,
-) (*FlowResult, error) {
-	// Verify state
-	if expectedState != "" && receivedState != expectedState {
-		return nil, fmt.Errorf("state mismatch: expected %q, got %q", expectedState, receivedState)
-	}
-
+// ExchangeCode completes a flow started elsewhere, such as a manual flow or a
+// web flow, by exchanging the authorization code and saving the tokens
+func (h *FlowHandler) ExchangeCode(ctx context.Context, code, codeVerifier, redirectURL string) (*FlowResult, error) {
 	return h.exchangeAndSave(ctx, code, codeVerifier, redirectURL, nil)
 }
@@ -86,7 +86,7 @@ func (r *Registry) Get(name entities.OAuthProvider) (Provider, error) {
 
 	p, ok := r.providers[name]
 	if !ok {
-		return nil, fmt.Errorf("provider %q not registered", name)
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, name)
 	}
 	return p, nil
 }
@@ -2,15 +2,11 @@ package providers
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/mrlokans/assistant/internal/entities"
@@ -53,33 +49,8 @@ func (p *DropboxProvider) Config() oauth2.ProviderConfig {
 }
 
 func (p *DropboxProvider) BuildAuthURL(redirectURL string) (authURL, codeVerifier, state string, err error) {
-	// Generate PKCE code verifier and challenge
-	codeVerifier, err = generateCodeVerifier()
-	if err != nil {
-		return "", "", "", fmt.Errorf("failed to generate code verifier: %w", err)
-	}
-	codeChallenge := generateCodeChallenge(codeVerifier)
-
-	// Generate state for CSRF protection
-	state, err = generateState()
-	if err != nil {
-		return "", "", "", fmt.Errorf("failed to generate state: %w", err)
-	}
-
-	params := url.Values{}
-	params.Set("client_id", p.appKey)
-	params.Set("response_type", "code")
-	params.Set("code_challenge", codeChallenge)
-	params.Set("code_challenge_method", "S256")
-	params.Set("state", state)
-	params.Set("token_access_type", "offline") // Get refresh token
-
-	if redirectURL != "" {
-		params.Set("redirect_uri", redirectURL)
-	}
-
-	authURL = dropboxAuthURL + "?" + params.Encode()
-	return authURL, codeVerifier, state, nil
+	// Offline access gets a refresh token
+	return oauth2.BuildAuthCodeURL(p.Config(), redirectURL, url.Values{"token_access_type": {"offline"}})
 }
 
 func (p *DropboxProvider) ExchangeCode(ctx context.Context, code, codeVerifier, redirectURL string) (*oauth2.TokenResponse, error) {
@@ -93,56 +64,11 @@ func (p *DropboxProvider) ExchangeCode(ctx context.Context, code, codeVerifier,
 		data.Set("redirect_uri", redirectURL)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", dropboxTokenURL, strings.NewReader(data.Encode()))
+	tokenResp, err := oauth2.RequestToken(ctx, p.httpClient, dropboxTokenURL, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create token request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to exchange code: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		var errResp struct {
-			Error            string `json:"error"`
-			ErrorDescription string `json:"error_description"`
-		}
-		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("token exchange failed: %s - %s", errResp.Error, errResp.ErrorDescription)
-		}
-		return nil, fmt.Errorf("token exchange failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var tokenResp struct {
-		AccessToken  string `json:"access_token"`
-		TokenType    string `json:"token_type"`
-		ExpiresIn    int    `json:"expires_in"`
-		RefreshToken string `json:"refresh_token"`
-		Scope        string `json:"scope"`
-		UID          string `json:"uid"`
-		AccountID    string `json:"account_id"`
-	}
-
-	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return nil, fmt.Errorf("failed to parse token response: %w", err)
+		return nil, fmt.Errorf("token exchange failed: %w", err)
 	}
-
-	return &oauth2.TokenResponse{
-		AccessToken:  tokenResp.AccessToken,
-		RefreshToken: tokenResp.RefreshToken,
-		TokenType:    tokenResp.TokenType,
-		ExpiresIn:    tokenResp.ExpiresIn,
-		Scope:        tokenResp.Scope,
-		AccountID:    tokenResp.AccountID,
-	}, nil
+	return tokenResp, nil
 }
 
 func (p *DropboxProvider) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.TokenResponse, error) {
@@ -151,50 +77,13 @@ func (p *DropboxProvider) RefreshToken(ctx context.Context, refreshToken string)
 	data.Set("refresh_token", refreshToken)
 	data.Set("client_id", p.appKey)
 
-	req, err := http.NewRequestWithContext(ctx, "POST", dropboxTokenURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create refresh request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to refresh token: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	tokenResp, err := oauth2.RequestToken(ctx, p.httpClient, dropboxTokenURL, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		var errResp struct {
-			Error            string `json:"error"`
-			ErrorDescription string `json:"error_description"`
-		}
-		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
-			return nil, fmt.Errorf("token refresh failed: %s - %s", errResp.Error, errResp.ErrorDescription)
-		}
-		return nil, fmt.Errorf("token refresh failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("token refresh failed: %w", err)
 	}
 
-	var tokenResp struct {
-		AccessToken string `json:"access_token"`
-		TokenType   string `json:"token_type"`
-		ExpiresIn   int    `json:"expires_in"`
-	}
-
-	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return nil, fmt.Errorf("failed to parse refresh response: %w", err)
-	}
-
-	// Dropbox refresh doesn't return a new refresh token
-	return &oauth2.TokenResponse{
-		AccessToken: tokenResp.AccessToken,
-		TokenType:   tokenResp.TokenType,
-		ExpiresIn:   tokenResp.ExpiresIn,
-	}, nil
+	// Dropbox refresh doesn't return a new refresh token, callers keep the old one
+	return tokenResp, nil
 }
 
 func (p *DropboxProvider) GetAccountInfo(ctx context.Context, accessToken string) (string, error) {
@@ -225,30 +114,6 @@ func (p *DropboxProvider) GetAccountInfo(ctx context.Context, accessToken string
 	return accountResp.AccountID, nil
 }
 
-// generateCodeVerifier creates a random code verifier for PKCE
-func generateCodeVerifier() (string, error) {
-	bytes := make([]byte, 32)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
-	}
-	return base64.RawURLEncoding.EncodeToString(bytes), nil
-}
-
-// generateCodeChallenge creates a code challenge from the verifier using S256
-func generateCodeChallenge(verifier string) string {
-	hash := sha256.Sum256([]byte(verifier))
-	return base64.RawURLEncoding.EncodeToString(hash[:])
-}
-
-// generateState creates a random state value for CSRF protection
-func generateState() (string, error) {
-	bytes := make([]byte, 16)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
-	}
-	return base64.RawURLEncoding.EncodeToString(bytes), nil
-}
-
 // RegisterDropbox registers the Dropbox provider with the given app key
 func RegisterDropbox(appKey string) {
 	if appKey == "" {
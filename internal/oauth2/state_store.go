@@ -0,0 +1,73 @@
+package oauth2
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// DefaultStateTTL is how long a started web flow waits for its callback
+const DefaultStateTTL = 10 * time.Minute
+
+// PendingFlow is a started authorization flow waiting for its callback
+type PendingFlow struct {
+	Provider     entities.OAuthProvider
+	CodeVerifier string
+	RedirectURL  string
+	CreatedAt    time.Time
+}
+
+// StateStore keeps pending web flows in memory, keyed by their state value.
+// A flow is taken once, so a callback cannot be replayed, and expires after
+// the TTL.
+type StateStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	pending map[string]PendingFlow
+}
+
+// NewStateStore creates a state store whose flows expire after ttl
+func NewStateStore(ttl time.Duration) *StateStore {
+	return &StateStore{
+		ttl:     ttl,
+		pending: make(map[string]PendingFlow),
+	}
+}
+
+// Put records a started flow, dropping flows that have expired
+func (s *StateStore) Put(state string, flow PendingFlow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, pending := range s.pending {
+		if s.expired(pending) {
+			delete(s.pending, key)
+		}
+	}
+	if flow.CreatedAt.IsZero() {
+		flow.CreatedAt = time.Now()
+	}
+	s.pending[state] = flow
+}
+
+// Take removes and returns the flow started with state. It reports false for
+// unknown and expired states.
+func (s *StateStore) Take(state string) (PendingFlow, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flow, ok := s.pending[state]
+	if !ok {
+		return PendingFlow{}, false
+	}
+	delete(s.pending, state)
+	if s.expired(flow) {
+		return PendingFlow{}, false
+	}
+	return flow, true
+}
+
+func (s *StateStore) expired(flow PendingFlow) bool {
+	return time.Since(flow.CreatedAt) > s.ttl
+}
@@ -0,0 +1,79 @@
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/tokenstore"
+)
+
+// WebFlow runs authorization code flows for web handlers. Start sends the user
+// to a provider and Callback completes the flow when the provider redirects
+// back, for any provider in the registry.
+type WebFlow struct {
+	registry *Registry
+	states   *StateStore
+}
+
+// NewWebFlow creates a web flow for the providers in registry
+func NewWebFlow(registry *Registry) *WebFlow {
+	return &WebFlow{
+		registry: registry,
+		states:   NewStateStore(DefaultStateTTL),
+	}
+}
+
+// Provider returns a registered provider
+func (f *WebFlow) Provider(name entities.OAuthProvider) (Provider, error) {
+	return f.registry.Get(name)
+}
+
+// Start begins a flow and returns the authorization URL to redirect the user
+// to. The provider redirects back to redirectURL, which must be registered
+// with the provider.
+func (f *WebFlow) Start(name entities.OAuthProvider, redirectURL string) (string, error) {
+	provider, err := f.registry.Get(name)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, codeVerifier, state, err := provider.BuildAuthURL(redirectURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to build auth URL: %w", err)
+	}
+
+	f.states.Put(state, PendingFlow{
+		Provider:     name,
+		CodeVerifier: codeVerifier,
+		RedirectURL:  redirectURL,
+	})
+	return authURL, nil
+}
+
+// Callback completes a flow from the query of the provider's redirect,
+// exchanging the code and saving the tokens to store
+func (f *WebFlow) Callback(ctx context.Context, name entities.OAuthProvider, query url.Values, store *tokenstore.TokenStore) (*FlowResult, error) {
+	if errParam := query.Get("error"); errParam != "" {
+		return nil, fmt.Errorf("%w: %s: %s", ErrAuthorizationDenied, errParam, query.Get("error_description"))
+	}
+
+	state := query.Get("state")
+	code := query.Get("code")
+	if state == "" || code == "" {
+		return nil, ErrMissingCode
+	}
+
+	pending, ok := f.states.Take(state)
+	if !ok || pending.Provider != name {
+		return nil, ErrInvalidState
+	}
+
+	provider, err := f.registry.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFlowHandler(provider, store).ExchangeCode(ctx, code, pending.CodeVerifier, pending.RedirectURL)
+}
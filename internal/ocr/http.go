@@ -0,0 +1,105 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPEngine sends images to an OCR web service. The image is posted as the
+// multipart field "file"; the service responds with plain text or with JSON
+// containing a "text" field.
+type HTTPEngine struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTPEngine creates an engine for the service at url. The token, if set,
+// is sent as a bearer token.
+func NewHTTPEngine(url, token string, timeout time.Duration) *HTTPEngine {
+	return &HTTPEngine{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Recognize implements Engine.
+func (e *HTTPEngine) Recognize(ctx context.Context, image []byte) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "page"+imageExtension(image))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	if _, err := part.Write(image); err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, &body)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("OCR service request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("read OCR service response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OCR service returned status %d", resp.StatusCode)
+	}
+
+	text := string(data)
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var result struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return "", fmt.Errorf("decode OCR service response: %w", err)
+		}
+		text = result.Text
+	}
+
+	if text = CleanText(text); text == "" {
+		return "", ErrNoText
+	}
+	return text, nil
+}
+
+// imageExtension guesses the file extension from the image contents so
+// services that check upload names accept the file.
+func imageExtension(image []byte) string {
+	switch http.DetectContentType(image) {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
+
+// Compile-time interface check
+var _ Engine = (*HTTPEngine)(nil)
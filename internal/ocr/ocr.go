@@ -0,0 +1,137 @@
+// Package ocr recognizes text in photos of book pages so paper-book passages
+// can be saved as highlights without typing them.
+//
+// Two backends are supported:
+//   - tesseract: runs a local Tesseract binary
+//   - http: posts the image to an OCR web service
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Register decoders for uploaded photos
+	_ "image/jpeg"
+	"image/png"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/config"
+)
+
+// Backend names accepted in the OCR_BACKEND setting.
+const (
+	BackendTesseract = "tesseract"
+	BackendHTTP      = "http"
+)
+
+var (
+	// ErrNoText is returned when no text was recognized in the image.
+	ErrNoText = errors.New("no text recognized")
+
+	// ErrUnsupportedImage is returned when an image cannot be decoded for cropping.
+	ErrUnsupportedImage = errors.New("unsupported image format")
+
+	// ErrImageTooLarge is returned when an image has too many pixels to decode
+	// for cropping.
+	ErrImageTooLarge = fmt.Errorf("image too large (max %d megapixels)", MaxCropPixels/1_000_000)
+)
+
+// MaxCropPixels is the largest image Crop decodes. A small compressed file
+// can declare huge dimensions, and decoding allocates memory for every pixel.
+const MaxCropPixels = 50_000_000
+
+// Engine recognizes text in an image.
+type Engine interface {
+	Recognize(ctx context.Context, image []byte) (string, error)
+}
+
+// New creates the engine selected by cfg.Backend.
+// Returns nil without an error when OCR is not configured.
+func New(cfg config.OCR) (Engine, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+
+	switch strings.ToLower(cfg.Backend) {
+	case "":
+		return nil, nil
+	case BackendTesseract:
+		return NewTesseractEngine(cfg.TesseractPath, cfg.Language, timeout), nil
+	case BackendHTTP:
+		if cfg.ServiceURL == "" {
+			return nil, fmt.Errorf("OCR_SERVICE_URL is required for the %s backend", BackendHTTP)
+		}
+		return NewHTTPEngine(cfg.ServiceURL, cfg.ServiceToken, timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown OCR backend: %s", cfg.Backend)
+	}
+}
+
+// Crop returns the part of the image inside rect as PNG. The rectangle is
+// clipped to the image bounds; an empty rectangle returns the image unchanged.
+// Images larger than MaxCropPixels are rejected with ErrImageTooLarge.
+func Crop(data []byte, rect image.Rectangle) ([]byte, error) {
+	if rect.Empty() {
+		return data, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxCropPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	bounds := img.Bounds()
+	rect = rect.Add(bounds.Min).Intersect(bounds)
+	if rect.Empty() {
+		return nil, fmt.Errorf("crop area is outside the image")
+	}
+
+	sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, sub.SubImage(rect)); err != nil {
+		return nil, fmt.Errorf("encode cropped image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+var (
+	hyphenatedLineBreak = regexp.MustCompile(`(\p{L})-\n(\p{Ll})`)
+	paragraphBreak      = regexp.MustCompile(`\n\s*\n`)
+)
+
+// CleanText turns OCR output of a printed page into highlight text: words
+// hyphenated across lines are joined, lines within a paragraph are merged and
+// paragraphs are separated by a blank line.
+func CleanText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\f", "\n")
+	text = hyphenatedLineBreak.ReplaceAllString(text, "$1$2")
+
+	paragraphs := paragraphBreak.Split(text, -1)
+	cleaned := make([]string, 0, len(paragraphs))
+	for _, p := range paragraphs {
+		if p = strings.Join(strings.Fields(p), " "); p != "" {
+			cleaned = append(cleaned, p)
+		}
+	}
+	return strings.Join(cleaned, "\n\n")
+}
@@ -0,0 +1,168 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/config"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(width-1, height-1, color.Black)
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// pngHeader returns the start of a PNG declaring the given dimensions, enough
+// for image.DecodeConfig but not for decoding pixels.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12] = 8 // Bit depth
+	ihdr[13] = 0 // Grayscale
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)-4))
+	buf.Write(ihdr)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	return buf.Bytes()
+}
+
+func TestCleanText(t *testing.T) {
+	input := "It was the best of times, it was the worst\nof times, it was the age of wis-\ndom.\n\n  Second   paragraph.\f"
+	expected := "It was the best of times, it was the worst of times, it was the age of wisdom.\n\nSecond paragraph."
+	assert.Equal(t, expected, CleanText(input))
+
+	// Hyphens before a capitalized word are kept
+	assert.Equal(t, "Anglo- Saxon", CleanText("Anglo-\nSaxon"))
+	assert.Empty(t, CleanText(" \n\n \f"))
+}
+
+func TestCrop(t *testing.T) {
+	data := testPNG(t, 100, 80)
+
+	cropped, err := Crop(data, image.Rect(10, 20, 60, 50))
+	require.NoError(t, err)
+	img, _, err := image.Decode(bytes.NewReader(cropped))
+	require.NoError(t, err)
+	assert.Equal(t, 50, img.Bounds().Dx())
+	assert.Equal(t, 30, img.Bounds().Dy())
+
+	// Crop areas extending past the edge are clipped
+	cropped, err = Crop(data, image.Rect(90, 70, 200, 200))
+	require.NoError(t, err)
+	img, _, err = image.Decode(bytes.NewReader(cropped))
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(10, 10), img.Bounds().Size())
+
+	unchanged, err := Crop(data, image.Rectangle{})
+	require.NoError(t, err)
+	assert.Equal(t, data, unchanged)
+
+	_, err = Crop(data, image.Rect(200, 200, 300, 300))
+	assert.Error(t, err)
+
+	_, err = Crop([]byte("not an image"), image.Rect(0, 0, 10, 10))
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+
+	// Oversized images are rejected from the header, before any pixels are decoded
+	_, err = Crop(pngHeader(60000, 60000), image.Rect(0, 0, 10, 10))
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+}
+
+func TestNew(t *testing.T) {
+	engine, err := New(config.OCR{})
+	require.NoError(t, err)
+	assert.Nil(t, engine)
+
+	engine, err = New(config.OCR{Backend: "tesseract"})
+	require.NoError(t, err)
+	assert.IsType(t, &TesseractEngine{}, engine)
+
+	_, err = New(config.OCR{Backend: "http"})
+	assert.Error(t, err, "http backend needs a service URL")
+
+	_, err = New(config.OCR{Backend: "cloud"})
+	assert.Error(t, err)
+}
+
+func TestHTTPEngine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		assert.Equal(t, "page.png", header.Filename)
+		assert.NotEmpty(t, data)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"text": "Recognized\nline"}`))
+	}))
+	defer server.Close()
+
+	engine := NewHTTPEngine(server.URL, "secret", 5*time.Second)
+	text, err := engine.Recognize(context.Background(), testPNG(t, 10, 10))
+	require.NoError(t, err)
+	assert.Equal(t, "Recognized line", text)
+}
+
+func TestHTTPEngine_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/empty" {
+			_, _ = w.Write([]byte("  \n"))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPEngine(server.URL+"/empty", "", 5*time.Second).Recognize(context.Background(), testPNG(t, 10, 10))
+	assert.ErrorIs(t, err, ErrNoText)
+
+	_, err = NewHTTPEngine(server.URL, "", 5*time.Second).Recognize(context.Background(), testPNG(t, 10, 10))
+	assert.Error(t, err)
+}
+
+func TestTesseractEngine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script in place of tesseract")
+	}
+
+	// Stand-in for tesseract that checks its arguments and prints fixed text
+	script := filepath.Join(t.TempDir(), "tesseract")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+[ -s "$1" ] && [ "$2" = "stdout" ] && [ "$3" = "-l" ] || exit 1
+printf 'Page of a\nbook in %s.\n' "$4"
+`), 0o755))
+
+	engine := NewTesseractEngine(script, "eng+deu", 5*time.Second)
+	text, err := engine.Recognize(context.Background(), testPNG(t, 10, 10))
+	require.NoError(t, err)
+	assert.Equal(t, "Page of a book in eng+deu.", text)
+
+	_, err = NewTesseractEngine(filepath.Join(t.TempDir(), "missing"), "", time.Second).Recognize(context.Background(), testPNG(t, 10, 10))
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// TesseractEngine runs the Tesseract command line tool.
+type TesseractEngine struct {
+	path     string
+	language string
+	timeout  time.Duration
+}
+
+// NewTesseractEngine creates an engine for the Tesseract binary at path
+// (looked up in PATH when empty) with language codes such as "eng+deu".
+func NewTesseractEngine(path, language string, timeout time.Duration) *TesseractEngine {
+	if path == "" {
+		path = "tesseract"
+	}
+	if language == "" {
+		language = "eng"
+	}
+	return &TesseractEngine{path: path, language: language, timeout: timeout}
+}
+
+// Recognize implements Engine.
+func (e *TesseractEngine) Recognize(ctx context.Context, image []byte) (string, error) {
+	// Tesseract detects the image format from the file contents, not the name
+	file, err := os.CreateTemp("", "ocr-*.img")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(image); err != nil {
+		file.Close()
+		return "", fmt.Errorf("write temp file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("write temp file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.path, file.Name(), "stdout", "-l", e.language)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("tesseract: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("tesseract: %w", err)
+	}
+
+	text := CleanText(stdout.String())
+	if text == "" {
+		return "", ErrNoText
+	}
+	return text, nil
+}
+
+// Compile-time interface check
+var _ Engine = (*TesseractEngine)(nil)
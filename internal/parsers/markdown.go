@@ -7,7 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mrlokans/assistant/internal/entities"
 )
@@ -116,6 +118,7 @@ func (parser *MarkdownParser) ParseMarkdownFile(filePath string) (*entities.Book
 	if err := parser.parseHighlights(scanner, book); err != nil {
 		return nil, fmt.Errorf("failed to parse highlights: %w", err)
 	}
+	removeHighlightTags(book)
 
 	return book, nil
 }
@@ -152,13 +155,34 @@ func (parser *MarkdownParser) parseYAMLFrontmatter(scanner *bufio.Scanner, book
 			parts := strings.SplitN(line, ":", 2)
 			if len(parts) == 2 {
 				key := strings.TrimSpace(parts[0])
-				value := strings.TrimSpace(parts[1])
+				value := unquoteYAML(strings.TrimSpace(parts[1]))
 
 				switch key {
 				case "title", "book_title":
 					book.Title = value
 				case "author", "book_author":
 					book.Author = value
+				case "content_source":
+					if value != "unknown" {
+						book.Source = entities.Source{Name: value}
+					}
+				case "series":
+					book.Series = value
+				case "series_index":
+					book.SeriesIndex, _ = strconv.ParseFloat(value, 64)
+				case "rating":
+					book.Rating, _ = strconv.ParseFloat(value, 64)
+				case "date_read":
+					if t, err := time.Parse("2006-01-02", value); err == nil {
+						book.DateRead = &t
+					}
+				case "tags":
+					// Collected here and narrowed to the book's own tags in removeHighlightTags
+					for _, name := range parseYAMLList(value) {
+						if name != "highlights" && name != "books" {
+							book.Tags = append(book.Tags, entities.Tag{Name: name})
+						}
+					}
 				}
 			}
 		}
@@ -171,6 +195,47 @@ func (parser *MarkdownParser) parseYAMLFrontmatter(scanner *bufio.Scanner, book
 	return nil
 }
 
+// unquoteYAML strips the double quotes the exporter puts around strings
+func unquoteYAML(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+		return strings.ReplaceAll(value[1:len(value)-1], "\\\"", "\"")
+	}
+	return value
+}
+
+// parseYAMLList parses a flow sequence such as "[highlights, books, stoic]"
+func parseYAMLList(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = unquoteYAML(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// removeHighlightTags drops the frontmatter tags that come from highlights:
+// the exporter lists book and highlight tags together.
+func removeHighlightTags(book *entities.Book) {
+	if len(book.Tags) == 0 {
+		return
+	}
+	highlightTags := make(map[string]bool)
+	for _, h := range book.Highlights {
+		for _, tag := range h.Tags {
+			highlightTags[tag.Name] = true
+		}
+	}
+	var bookTags []entities.Tag
+	for _, tag := range book.Tags {
+		if !highlightTags[tag.Name] {
+			bookTags = append(bookTags, tag)
+		}
+	}
+	book.Tags = bookTags
+}
+
 func (parser *MarkdownParser) parseMarkdownHeader(titleLine string, scanner *bufio.Scanner, book *entities.Book) error {
 	// Extract title from the first line (remove "# " prefix)
 	book.Title = strings.TrimSpace(strings.TrimPrefix(titleLine, "# "))
@@ -226,6 +291,32 @@ func (parser *MarkdownParser) parseMarkdownHeader(titleLine string, scanner *buf
 	return nil
 }
 
+// Obsidian callouts written by exporters.GenerateMarkdown:
+//
+//	> [!quote] ⭐ 2024-03-01 21:15 • Chapter 2
+//	> Highlight text
+//	>
+//	> **Note:** Note text
+//	>
+//	> *📝 underlined*
+//	>
+//	> Tags: #stoic #philosophy
+var (
+	calloutHeaderPattern = regexp.MustCompile(`^> \[!(\w+)\] ?(.*)$`)
+	calloutNotePattern   = regexp.MustCompile(`^\*\*Note:\*\* ?(.*)$`)
+	calloutStylePattern  = regexp.MustCompile(`^\*(?:📝 underlined|❌ crossed out)(?: • (?:📝 underlined|❌ crossed out))*\*$`)
+	calloutLinkPattern   = regexp.MustCompile(`^[A-Z][a-z ]+: \[\[.*\]\]$`)
+)
+
+// calloutColors reverses utils.ColorToCalloutType. "quote" is also the
+// callout of uncolored highlights, so it maps to no color.
+var calloutColors = map[string]string{
+	"note":    "#FF00FF00",
+	"warning": "#FFFF0000",
+	"info":    "#FF0000FF",
+	"tip":     "#FFFF00FF",
+}
+
 func (parser *MarkdownParser) parseHighlights(scanner *bufio.Scanner, book *entities.Book) error {
 	// Regex patterns for different highlight formats
 	// Format 1: ### (taken_at: 2025-02-13T07:34:47+01:00)
@@ -234,47 +325,82 @@ func (parser *MarkdownParser) parseHighlights(scanner *bufio.Scanner, book *enti
 	timestampPattern := regexp.MustCompile(`^### (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?)$`)
 	// Format 3: ### (Page: 0)
 	pagePattern := regexp.MustCompile(`^### \(Page: (\d+)\)$`)
+	// Format 4: the callouts of this app's own export, see calloutHeaderPattern
 
 	var currentHighlight *entities.Highlight
-	var highlightText strings.Builder
+	var highlightText, noteText strings.Builder
+	inCallout, inNote := false, false
+
+	saveHighlight := func() {
+		if currentHighlight != nil {
+			currentHighlight.Text = strings.TrimSpace(highlightText.String())
+			currentHighlight.Note = strings.TrimSpace(noteText.String())
+			book.Highlights = append(book.Highlights, *currentHighlight)
+		}
+		currentHighlight = nil
+		highlightText.Reset()
+		noteText.Reset()
+		inCallout, inNote = false, false
+	}
 
 	for scanner.Scan() {
 		line := scanner.Text()
 
 		// Check if this is a new highlight header
-		if matches := takenAtPattern.FindStringSubmatch(line); matches != nil {
-			// Save previous highlight if exists
-			if currentHighlight != nil {
-				currentHighlight.Text = strings.TrimSpace(highlightText.String())
-				book.Highlights = append(book.Highlights, *currentHighlight)
+		if matches := calloutHeaderPattern.FindStringSubmatch(line); matches != nil {
+			saveHighlight()
+			currentHighlight = parseCalloutHeader(matches[1], matches[2])
+			inCallout = true
+		} else if inCallout {
+			if line != ">" && !strings.HasPrefix(line, "> ") {
+				// The callout ends at the first line outside it, e.g. a block ID
+				saveHighlight()
+				continue
 			}
-
-			// Start new highlight
+			content := strings.TrimPrefix(strings.TrimPrefix(line, ">"), " ")
+			switch {
+			case calloutNotePattern.MatchString(content):
+				inNote = true
+				noteText.WriteString(calloutNotePattern.FindStringSubmatch(content)[1])
+			case calloutStylePattern.MatchString(content):
+				inNote = false
+				if strings.Contains(content, "underlined") {
+					currentHighlight.Style = entities.HighlightStyleUnderline
+				}
+				if strings.Contains(content, "crossed out") {
+					currentHighlight.Style = entities.HighlightStyleStrikethrough
+				}
+			case strings.HasPrefix(content, "Tags: #"):
+				inNote = false
+				for _, name := range strings.Fields(strings.TrimPrefix(content, "Tags: ")) {
+					currentHighlight.Tags = append(currentHighlight.Tags, entities.Tag{Name: strings.TrimPrefix(name, "#")})
+				}
+			case calloutLinkPattern.MatchString(content):
+				// Links to other highlights refer to IDs of the exporting database
+				inNote = false
+			case inNote:
+				noteText.WriteString("\n" + content)
+			default:
+				if highlightText.Len() > 0 {
+					highlightText.WriteString("\n")
+				}
+				highlightText.WriteString(content)
+			}
+		} else if matches := takenAtPattern.FindStringSubmatch(line); matches != nil {
+			saveHighlight()
 			currentHighlight = &entities.Highlight{
 				Time: matches[1],
 				Page: 0, // Page info not available in this format
 			}
-			highlightText.Reset()
 		} else if matches := timestampPattern.FindStringSubmatch(line); matches != nil {
-			// Save previous highlight if exists
-			if currentHighlight != nil {
-				currentHighlight.Text = strings.TrimSpace(highlightText.String())
-				book.Highlights = append(book.Highlights, *currentHighlight)
-			}
-
+			saveHighlight()
 			// Start new highlight with timestamp format
 			currentHighlight = &entities.Highlight{
 				Time: matches[1],
 				Page: 0, // Page info not available in this format
 			}
-			highlightText.Reset()
 		} else if matches := pagePattern.FindStringSubmatch(line); matches != nil {
-			// Save previous highlight if exists
-			if currentHighlight != nil {
-				currentHighlight.Text = strings.TrimSpace(highlightText.String())
-				book.Highlights = append(book.Highlights, *currentHighlight)
-			}
-
+			saveHighlight()
 			// Start new highlight with page format
 			page := 0
 			// For now, just set page to 0 since we don't need exact page numbers for comparison
@@ -282,7 +408,6 @@ func (parser *MarkdownParser) parseHighlights(scanner *bufio.Scanner, book *enti
 				Time: "unknown", // No timestamp in this format
 				Page: page,
 			}
-			highlightText.Reset()
 		} else if strings.HasPrefix(line, "## ") {
 			// Skip section headers like "## Highlights:"
 			continue
@@ -299,14 +424,39 @@ func (parser *MarkdownParser) parseHighlights(scanner *bufio.Scanner, book *enti
 	}
 
 	// Save the last highlight
-	if currentHighlight != nil {
-		currentHighlight.Text = strings.TrimSpace(highlightText.String())
-		book.Highlights = append(book.Highlights, *currentHighlight)
-	}
+	saveHighlight()
 
 	return nil
 }
 
+// parseCalloutHeader reads the callout type and the "⭐ 2024-03-01 21:15 • Chapter"
+// header of an exported highlight.
+func parseCalloutHeader(calloutType, header string) *entities.Highlight {
+	highlight := &entities.Highlight{Color: calloutColors[calloutType]}
+	switch calloutType {
+	case "success":
+		highlight.Style = entities.HighlightStyleUnderline
+	case "failure":
+		highlight.Style = entities.HighlightStyleStrikethrough
+	}
+
+	if rest, ok := strings.CutPrefix(header, "⭐ "); ok {
+		highlight.IsFavorite = true
+		header = rest
+	}
+	timestamp, chapter, _ := strings.Cut(header, " • ")
+	highlight.Chapter = strings.TrimSpace(chapter)
+
+	timestamp = strings.TrimSpace(timestamp)
+	if t, err := time.Parse("2006-01-02 15:04", timestamp); err == nil {
+		highlight.HighlightedAt = t
+		highlight.Time = timestamp //nolint:staticcheck // Kept for the formats above
+	} else if timestamp != "(no date)" {
+		highlight.Time = timestamp //nolint:staticcheck // Kept for the formats above
+	}
+	return highlight
+}
+
 func (parser *MarkdownParser) CompareWithDatabase(markdownBooks []entities.Book, dbBooks []entities.Book) ComparisonResult {
 	result := ComparisonResult{
 		MarkdownBooks:  len(markdownBooks),
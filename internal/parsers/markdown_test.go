@@ -1,9 +1,13 @@
 package parsers
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -129,3 +133,71 @@ func TestMarkdownParser(t *testing.T) {
 		assert.False(t, parser.BookExists(nonExistentBook), "Should not find non-existent book file")
 	})
 }
+
+func TestMarkdownParser_RoundTripsExport(t *testing.T) {
+	highlightedAt := time.Date(2024, 3, 1, 21, 15, 0, 0, time.UTC)
+	dateRead := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+	book := entities.Book{
+		Title:       `The "Inner" Citadel`,
+		Author:      "Pierre Hadot",
+		Series:      "Stoic Studies",
+		SeriesIndex: 2,
+		Rating:      4.5,
+		DateRead:    &dateRead,
+		Source:      entities.Source{Name: "kindle"},
+		Tags:        []entities.Tag{{Name: "philosophy"}},
+		Highlights: []entities.Highlight{
+			{
+				Text:          "First paragraph\n\nSecond paragraph",
+				Note:          "A note\nover two lines",
+				Chapter:       "Chapter 2",
+				HighlightedAt: highlightedAt,
+				IsFavorite:    true,
+				Color:         "#FF0000FF",
+				Tags:          []entities.Tag{{Name: "stoic"}},
+			},
+			{
+				Text:          "Underlined passage",
+				HighlightedAt: highlightedAt.Add(time.Hour),
+				Style:         entities.HighlightStyleUnderline,
+			},
+			{Text: "Undated", Style: entities.HighlightStyleStrikethrough},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "book.md")
+	require.NoError(t, os.WriteFile(path, []byte(exporters.GenerateMarkdown(&book)), 0644))
+
+	parsed, err := NewMarkdownParser(filepath.Dir(path)).ParseMarkdownFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, book.Title, parsed.Title)
+	assert.Equal(t, book.Author, parsed.Author)
+	assert.Equal(t, "kindle", parsed.Source.Name)
+	assert.Equal(t, "Stoic Studies", parsed.Series)
+	assert.Equal(t, 2.0, parsed.SeriesIndex)
+	assert.Equal(t, 4.5, parsed.Rating)
+	require.NotNil(t, parsed.DateRead)
+	assert.True(t, dateRead.Equal(*parsed.DateRead))
+	require.Len(t, parsed.Tags, 1)
+	assert.Equal(t, "philosophy", parsed.Tags[0].Name)
+
+	require.Len(t, parsed.Highlights, 3)
+	first := parsed.Highlights[0]
+	assert.Equal(t, "First paragraph\n\nSecond paragraph", first.Text)
+	assert.Equal(t, "A note\nover two lines", first.Note)
+	assert.Equal(t, "Chapter 2", first.Chapter)
+	assert.True(t, highlightedAt.Equal(first.HighlightedAt))
+	assert.True(t, first.IsFavorite)
+	assert.Equal(t, "#FF0000FF", first.Color)
+	require.Len(t, first.Tags, 1)
+	assert.Equal(t, "stoic", first.Tags[0].Name)
+
+	assert.Equal(t, "Underlined passage", parsed.Highlights[1].Text)
+	assert.Equal(t, entities.HighlightStyleUnderline, parsed.Highlights[1].Style)
+	assert.Empty(t, parsed.Highlights[1].Note)
+
+	assert.Equal(t, "Undated", parsed.Highlights[2].Text)
+	assert.Equal(t, entities.HighlightStyleStrikethrough, parsed.Highlights[2].Style)
+	assert.True(t, parsed.Highlights[2].HighlightedAt.IsZero())
+}
@@ -0,0 +1,218 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// "HELLO WORLD" at 1-M, the worked example from the specification tutorials
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	assert.Equal(t, want, reedSolomonRemainder(data, reedSolomonDivisor(10)))
+}
+
+func TestEncodeData(t *testing.T) {
+	codewords := encodeData([]byte("hi"), 1)
+	require.Len(t, codewords, 16)
+	// 0100 | 00000010 | 01101000 | 01101001 | 0000 then pad bytes
+	assert.Equal(t, []byte{0x40, 0x26, 0x86, 0x90, 0xEC, 0x11}, codewords[:6])
+}
+
+func TestEncodeVersionSelection(t *testing.T) {
+	tests := []struct {
+		length  int
+		version int
+	}{
+		{1, 1},
+		{14, 1},
+		{15, 2},
+		{120, 7},
+		{213, 10},
+	}
+
+	for _, tt := range tests {
+		code, err := Encode(strings.Repeat("a", tt.length))
+		require.NoError(t, err)
+		assert.Equal(t, tt.version, code.Version, "length %d", tt.length)
+		assert.Equal(t, tt.version*4+17, code.Size)
+	}
+
+	_, err := Encode(strings.Repeat("a", 214))
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestEncodeFunctionPatterns(t *testing.T) {
+	uri := "otpauth://totp/Highlights:alice?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=Highlights"
+	code, err := Encode(uri)
+	require.NoError(t, err)
+
+	// Finder pattern corners and centres
+	for _, corner := range [][2]int{{0, 0}, {code.Size - 7, 0}, {0, code.Size - 7}} {
+		x, y := corner[0], corner[1]
+		assert.True(t, code.Dark(x, y))
+		assert.False(t, code.Dark(x+1, y+1))
+		assert.True(t, code.Dark(x+3, y+3))
+	}
+	assert.True(t, code.Dark(8, code.Size-8), "dark module")
+
+	// Both copies of the format information must agree
+	var first, second int
+	for i := 0; i <= 5; i++ {
+		first |= b2i(code.Dark(8, i)) << i
+	}
+	first |= b2i(code.Dark(8, 7))<<6 | b2i(code.Dark(8, 8))<<7 | b2i(code.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		first |= b2i(code.Dark(14-i, 8)) << i
+	}
+	for i := 0; i < 8; i++ {
+		second |= b2i(code.Dark(code.Size-1-i, 8)) << i
+	}
+	for i := 8; i < 15; i++ {
+		second |= b2i(code.Dark(8, code.Size-15+i)) << i
+	}
+	assert.Equal(t, first, second)
+	assert.Equal(t, 0b00, (first^0x5412)>>13, "error correction level M")
+}
+
+func TestVersionInformation(t *testing.T) {
+	code, err := Encode(strings.Repeat("a", 120))
+	require.NoError(t, err)
+	require.Equal(t, 7, code.Version)
+
+	var bits int
+	for i := 0; i < 18; i++ {
+		bits |= b2i(code.Dark(code.Size-11+i%3, i/3)) << i
+	}
+	assert.Equal(t, 0x07C94, bits)
+}
+
+func TestSVG(t *testing.T) {
+	code, err := Encode("hello")
+	require.NoError(t, err)
+
+	svg := code.SVG()
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.Contains(t, svg, `viewBox="0 0 29 29"`)
+	assert.Contains(t, svg, "M4,4h1v1h-1z")
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// decode reads the text back from a code the way a scanner would: format
+// information, unmasking, the zigzag codeword order, de-interleaving and the
+// byte mode segment. Every block must pass its Reed-Solomon check.
+func decode(t *testing.T, code *Code) string {
+	t.Helper()
+
+	// First copy of the format information, least significant bit first
+	formatPositions := [][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}}
+	var format int
+	for i, p := range formatPositions {
+		format |= b2i(code.Dark(p[0], p[1])) << i
+	}
+	format ^= 0x5412
+	require.Equal(t, 0b00, format>>13, "error correction level M")
+	mask := format >> 10 & 0b111
+
+	// Function modules depend only on the version
+	reference := &Code{Version: code.Version, Size: code.Size, modules: newGrid(code.Size), isFunction: newGrid(code.Size)}
+	reference.drawFunctionPatterns()
+
+	masks := []func(x, y int) bool{
+		func(x, y int) bool { return (x+y)%2 == 0 },
+		func(x, y int) bool { return y%2 == 0 },
+		func(x, y int) bool { return x%3 == 0 },
+		func(x, y int) bool { return (x+y)%3 == 0 },
+		func(x, y int) bool { return (x/3+y/2)%2 == 0 },
+		func(x, y int) bool { return x*y%2+x*y%3 == 0 },
+		func(x, y int) bool { return (x*y%2+x*y%3)%2 == 0 },
+		func(x, y int) bool { return ((x+y)%2+x*y%3)%2 == 0 },
+	}
+
+	var bits []bool
+	for right := code.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < code.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = code.Size - 1 - vert
+				}
+				if !reference.isFunction[y][x] {
+					bits = append(bits, code.Dark(x, y) != masks[mask](x, y))
+				}
+			}
+		}
+	}
+	codewords := make([]byte, len(bits)/8)
+	for i := range codewords {
+		for _, bit := range bits[i*8 : i*8+8] {
+			codewords[i] = codewords[i]<<1 | byte(b2i(bit))
+		}
+	}
+
+	layout := layouts[code.Version-1]
+	blocks := make([][]byte, layout.shortBlocks+layout.longBlocks)
+	next := 0
+	for i := 0; i <= layout.shortData; i++ {
+		for b := range blocks {
+			if i < layout.shortData || b >= layout.shortBlocks {
+				blocks[b] = append(blocks[b], codewords[next])
+				next++
+			}
+		}
+	}
+	divisor := reedSolomonDivisor(layout.ecPerBlock)
+	var data []byte
+	for b, block := range blocks {
+		ec := make([]byte, 0, layout.ecPerBlock)
+		for i := 0; i < layout.ecPerBlock; i++ {
+			ec = append(ec, codewords[next+i*len(blocks)+b])
+		}
+		require.Equal(t, reedSolomonRemainder(block, divisor), ec, "block %d fails its Reed-Solomon check", b)
+		data = append(data, block...)
+	}
+
+	readBits := func(offset, n int) int {
+		value := 0
+		for i := offset; i < offset+n; i++ {
+			value = value<<1 | int(data[i/8]>>(7-i%8)&1)
+		}
+		return value
+	}
+	require.Equal(t, 0b0100, readBits(0, 4), "byte mode")
+	length := readBits(4, countBits(code.Version))
+	text := make([]byte, length)
+	for i := range text {
+		text[i] = byte(readBits(4+countBits(code.Version)+8*i, 8))
+	}
+	return string(text)
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	texts := []string{
+		"",
+		"hello",
+		"otpauth://totp/Highlights:alice?secret=JBSWY3DPEHPK3PXP&issuer=Highlights",
+		strings.Repeat("Ünïcödé ", 12), // version 8, with long blocks
+		strings.Repeat("x", 213),       // largest supported
+	}
+
+	for _, text := range texts {
+		code, err := Encode(text)
+		require.NoError(t, err)
+		assert.Equal(t, text, decode(t, code), "version %d", code.Version)
+	}
+}
@@ -0,0 +1,106 @@
+package quotecard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Register decoders for cover images
+	_ "image/jpeg"
+	"image/png"
+)
+
+// pngScales are the bitmap font magnifications tried from largest to smallest
+// until the quote fits
+var pngScales = []int{6, 5, 4, 3}
+
+const pngAttributionScale = 3
+
+// RenderPNG renders a quote card as a PNG image. A cover that cannot be
+// decoded is left out.
+func RenderPNG(card Card, tmpl Template) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(tmpl.Background), image.Point{}, draw.Src)
+	fillRect(img, image.Rect(0, 0, 12, Height), tmpl.Accent)
+
+	var cover image.Image
+	if len(card.Cover) > 0 {
+		cover, _, _ = image.Decode(bytes.NewReader(card.Cover))
+	}
+	if cover != nil {
+		drawCover(img, cover, image.Rect(margin, (Height-coverHeight)/2, margin+coverWidth, (Height+coverHeight)/2))
+	}
+
+	attributionHeight := 2 * lineAdvance * pngAttributionScale
+	area := quoteArea(cover != nil, attributionHeight)
+
+	text := toASCII(card.Text)
+	var lines []string
+	var scale int
+	for _, scale = range pngScales {
+		var fits bool
+		if lines, fits = wrapText(text, area.Width/(glyphAdvance*scale), area.Height/(lineAdvance*scale)); fits {
+			break
+		}
+	}
+
+	top := area.Y + (area.Height-lineAdvance*scale*len(lines))/2
+	for i, line := range lines {
+		drawText(img, area.X, top+lineAdvance*scale*i, toASCII(line), scale, tmpl.Foreground)
+	}
+
+	if line := attribution(card); line != "" {
+		line = toASCII(truncateLine(line, area.Width/(glyphAdvance*pngAttributionScale)))
+		y := Height - margin - glyphHeight*pngAttributionScale
+		fillRect(img, image.Rect(area.X, y-lineAdvance*pngAttributionScale, area.X+80, y-lineAdvance*pngAttributionScale+3), tmpl.Accent)
+		drawText(img, area.X, y, line, pngAttributionScale, tmpl.Accent)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawText draws ASCII text with the bitmap font, its top left corner at x, y
+func drawText(img *image.RGBA, x, y int, text string, scale int, c color.RGBA) {
+	for _, r := range text {
+		columns := glyph(r)
+		for col, bits := range columns {
+			for row := 0; row < glyphHeight; row++ {
+				if bits&(1<<row) == 0 {
+					continue
+				}
+				px, py := x+col*scale, y+row*scale
+				fillRect(img, image.Rect(px, py, px+scale, py+scale), c)
+			}
+		}
+		x += glyphAdvance * scale
+	}
+}
+
+// drawCover scales a cover to fit the box, keeping its aspect ratio, and
+// centres it there
+func drawCover(img *image.RGBA, cover image.Image, box image.Rectangle) {
+	src := cover.Bounds()
+	if src.Dx() == 0 || src.Dy() == 0 {
+		return
+	}
+	ratio := min(float64(box.Dx())/float64(src.Dx()), float64(box.Dy())/float64(src.Dy()))
+	w, h := int(float64(src.Dx())*ratio), int(float64(src.Dy())*ratio)
+	x0, y0 := box.Min.X+(box.Dx()-w)/2, box.Min.Y+(box.Dy()-h)/2
+
+	// Nearest-neighbour sampling is enough for a thumbnail
+	for y := 0; y < h; y++ {
+		sy := src.Min.Y + int(float64(y)/ratio)
+		for x := 0; x < w; x++ {
+			sx := src.Min.X + int(float64(x)/ratio)
+			img.Set(x0+x, y0+y, cover.At(sx, sy))
+		}
+	}
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
@@ -0,0 +1,205 @@
+// Package quotecard renders highlights as shareable quote cards: the quote,
+// the book title and author, and optionally the book cover, on a 1200x630
+// image (the size social networks use for link previews).
+//
+// Cards are rendered as SVG, which keeps the text selectable and supports any
+// script, or as PNG drawn with a built-in bitmap font, which only covers
+// ASCII. Typographic quotes and dashes are replaced by their ASCII look-alikes
+// and other characters by "?" in PNG cards.
+package quotecard
+
+import (
+	"errors"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+)
+
+// Card dimensions in pixels
+const (
+	Width  = 1200
+	Height = 630
+
+	margin      = 80
+	coverWidth  = 200
+	coverHeight = 300
+	coverGap    = 60
+)
+
+// Output formats
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+var (
+	// ErrUnknownTemplate is returned for a template name not in Templates.
+	ErrUnknownTemplate = errors.New("unknown quote card template")
+	// ErrUnknownFormat is returned for a format other than FormatPNG and FormatSVG.
+	ErrUnknownFormat = errors.New("quote card format must be png or svg")
+)
+
+// Card is the content of a quote card.
+type Card struct {
+	Text   string
+	Title  string
+	Author string
+	Cover  []byte // Encoded JPEG, PNG or GIF image, optional
+}
+
+// Template is the look of a quote card.
+type Template struct {
+	Name       string
+	Background color.RGBA
+	Foreground color.RGBA
+	Accent     color.RGBA // Quote mark, rule and attribution
+	FontFamily string     // SVG font stack
+}
+
+// Templates lists the available quote card templates; the first is the default.
+var Templates = []Template{
+	{
+		Name:       "light",
+		Background: color.RGBA{0xFA, 0xFA, 0xF9, 0xFF},
+		Foreground: color.RGBA{0x1C, 0x19, 0x17, 0xFF},
+		Accent:     color.RGBA{0xB4, 0x53, 0x09, 0xFF},
+		FontFamily: "Georgia, 'Times New Roman', serif",
+	},
+	{
+		Name:       "dark",
+		Background: color.RGBA{0x18, 0x18, 0x1B, 0xFF},
+		Foreground: color.RGBA{0xF4, 0xF4, 0xF5, 0xFF},
+		Accent:     color.RGBA{0xFA, 0xCC, 0x15, 0xFF},
+		FontFamily: "'Helvetica Neue', Arial, sans-serif",
+	},
+	{
+		Name:       "sepia",
+		Background: color.RGBA{0xF4, 0xEC, 0xD8, 0xFF},
+		Foreground: color.RGBA{0x43, 0x34, 0x22, 0xFF},
+		Accent:     color.RGBA{0x8B, 0x5E, 0x34, 0xFF},
+		FontFamily: "'Palatino Linotype', Palatino, serif",
+	},
+}
+
+// TemplateNames lists the names of Templates.
+func TemplateNames() []string {
+	names := make([]string, len(Templates))
+	for i, t := range Templates {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// TemplateByName returns the named template, or the default one for an empty name.
+func TemplateByName(name string) (Template, error) {
+	if name == "" {
+		return Templates[0], nil
+	}
+	for _, t := range Templates {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return Template{}, ErrUnknownTemplate
+}
+
+// Render renders a quote card in the given format and returns it with its
+// content type.
+func Render(card Card, tmpl Template, format string) ([]byte, string, error) {
+	switch format {
+	case FormatPNG:
+		data, err := RenderPNG(card, tmpl)
+		return data, "image/png", err
+	case FormatSVG:
+		return RenderSVG(card, tmpl), "image/svg+xml", nil
+	default:
+		return nil, "", ErrUnknownFormat
+	}
+}
+
+// textArea is the box the quote is laid out in, left of the attribution
+type textArea struct {
+	X, Y, Width, Height int
+}
+
+// quoteArea returns the box for the quote text, leaving room for the cover
+// and for the attribution lines of the given height below it
+func quoteArea(withCover bool, attributionHeight int) textArea {
+	x := margin
+	if withCover {
+		x += coverWidth + coverGap
+	}
+	return textArea{X: x, Y: margin, Width: Width - margin - x, Height: Height - 2*margin - attributionHeight}
+}
+
+// attribution returns the line naming the book, e.g. "— Walden, Henry David Thoreau"
+func attribution(card Card) string {
+	parts := make([]string, 0, 2)
+	if title := strings.TrimSpace(card.Title); title != "" {
+		parts = append(parts, title)
+	}
+	if author := strings.TrimSpace(card.Author); author != "" {
+		parts = append(parts, author)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "— " + strings.Join(parts, ", ")
+}
+
+// wrapText breaks text into at most maxLines lines of at most maxChars
+// characters, at spaces where possible. Whitespace, including line breaks, is
+// collapsed. Text that does not fit is cut and ends with an ellipsis; fits
+// reports whether all of it fit.
+func wrapText(text string, maxChars, maxLines int) (lines []string, fits bool) {
+	if maxChars < 1 || maxLines < 1 {
+		return nil, false
+	}
+
+	var line string
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > maxChars {
+			// Break words longer than a line
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:maxChars]))
+			word = string(runes[maxChars:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= maxChars:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	if len(lines) <= maxLines {
+		return lines, true
+	}
+	lines = lines[:maxLines]
+	// Leave room for the ellipsis, which PNG cards draw as three dots
+	last := []rune(lines[maxLines-1])
+	if len(last) > maxChars-3 {
+		last = last[:max(0, maxChars-3)]
+	}
+	lines[maxLines-1] = strings.TrimRight(string(last), " ,.;:") + "…"
+	return lines, false
+}
+
+// truncateLine shortens a single line to maxChars characters with an ellipsis
+func truncateLine(text string, maxChars int) string {
+	runes := []rune(text)
+	if len(runes) <= maxChars || maxChars < 1 {
+		return text
+	}
+	return strings.TrimRight(string(runes[:max(0, maxChars-3)]), " ,") + "…"
+}
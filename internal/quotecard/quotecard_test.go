@@ -0,0 +1,81 @@
+package quotecard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapText(t *testing.T) {
+	lines, fits := wrapText("The obstacle  is\nthe way", 10, 3)
+	assert.True(t, fits)
+	assert.Equal(t, []string{"The", "obstacle", "is the way"}, lines)
+
+	lines, fits = wrapText("Waste no more time arguing about what a good man should be", 12, 2)
+	assert.False(t, fits)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Waste no", lines[0])
+	assert.Equal(t, "more time…", lines[1])
+
+	lines, fits = wrapText("Antidisestablishmentarianism", 10, 3)
+	assert.True(t, fits)
+	assert.Equal(t, []string{"Antidisest", "ablishment", "arianism"}, lines)
+}
+
+func TestToASCII(t *testing.T) {
+	assert.Equal(t, `"It's - ok..." ?`, toASCII("“It’s — ok…” ж"))
+}
+
+func TestTemplateByName(t *testing.T) {
+	tmpl, err := TemplateByName("")
+	require.NoError(t, err)
+	assert.Equal(t, "light", tmpl.Name)
+
+	tmpl, err = TemplateByName("dark")
+	require.NoError(t, err)
+	assert.Equal(t, "dark", tmpl.Name)
+
+	_, err = TemplateByName("neon")
+	assert.ErrorIs(t, err, ErrUnknownTemplate)
+}
+
+func TestRenderSVG(t *testing.T) {
+	tmpl, _ := TemplateByName("sepia")
+	svg := string(RenderSVG(Card{Text: "Beware the <barrenness> of a busy life", Title: "Apology", Author: "Socrates"}, tmpl))
+
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.Contains(t, svg, "&lt;barrenness&gt;")
+	assert.Contains(t, svg, "— Apology, Socrates")
+	assert.Contains(t, svg, `fill="#f4ecd8"`)
+	assert.NotContains(t, svg, "<image")
+}
+
+func TestRenderPNG(t *testing.T) {
+	cover := image.NewRGBA(image.Rect(0, 0, 20, 30))
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 20; x++ {
+			cover.Set(x, y, color.RGBA{0xFF, 0, 0, 0xFF})
+		}
+	}
+	var coverPNG bytes.Buffer
+	require.NoError(t, png.Encode(&coverPNG, cover))
+
+	tmpl, _ := TemplateByName("dark")
+	data, err := RenderPNG(Card{Text: strings.Repeat("Know thyself. ", 80), Title: "Phaedrus", Author: "Plato", Cover: coverPNG.Bytes()}, tmpl)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, Width, Height), img.Bounds())
+	assert.Equal(t, color.RGBA{0xFF, 0, 0, 0xFF}, color.RGBAModel.Convert(img.At(margin+coverWidth/2, Height/2)), "cover is drawn")
+	assert.Equal(t, tmpl.Background, color.RGBAModel.Convert(img.At(Width-10, 10)))
+
+	_, _, err = Render(Card{Text: "x"}, tmpl, "gif")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
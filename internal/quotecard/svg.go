@@ -0,0 +1,84 @@
+package quotecard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image/color"
+	"net/http"
+	"strings"
+)
+
+// svgFontSizes are tried from largest to smallest until the quote fits
+var svgFontSizes = []int{56, 48, 40, 34, 28, 24}
+
+const (
+	svgCharWidth     = 0.52 // Average character width, in ems
+	svgLineHeight    = 1.35 // Line height, in ems
+	svgAttributionPx = 28
+)
+
+// RenderSVG renders a quote card as an SVG document.
+func RenderSVG(card Card, tmpl Template) []byte {
+	cover := svgCoverURI(card.Cover)
+	area := quoteArea(cover != "", 2*svgAttributionPx)
+
+	var lines []string
+	var size int
+	for _, size = range svgFontSizes {
+		maxChars := int(float64(area.Width) / (svgCharWidth * float64(size)))
+		maxLines := int(float64(area.Height) / (svgLineHeight * float64(size)))
+		var fits bool
+		if lines, fits = wrapText(card.Text, maxChars, maxLines); fits {
+			break
+		}
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", Width, Height, Width, Height)
+	fmt.Fprintf(&builder, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", svgColor(tmpl.Background))
+	fmt.Fprintf(&builder, `<rect x="0" y="0" width="12" height="%d" fill="%s"/>`+"\n", Height, svgColor(tmpl.Accent))
+
+	if cover != "" {
+		fmt.Fprintf(&builder, `<image x="%d" y="%d" width="%d" height="%d" preserveAspectRatio="xMidYMid meet" href="%s"/>`+"\n",
+			margin, (Height-coverHeight)/2, coverWidth, coverHeight, cover)
+	}
+
+	// Quote text, vertically centred in its area
+	lineHeight := svgLineHeight * float64(size)
+	top := float64(area.Y) + (float64(area.Height)-lineHeight*float64(len(lines)))/2
+	fmt.Fprintf(&builder, `<text font-family="%s" font-size="%d" fill="%s">`+"\n",
+		html.EscapeString(tmpl.FontFamily), size, svgColor(tmpl.Foreground))
+	for i, line := range lines {
+		fmt.Fprintf(&builder, `<tspan x="%d" y="%.0f">%s</tspan>`+"\n", area.X, top+lineHeight*float64(i)+float64(size), html.EscapeString(line))
+	}
+	fmt.Fprintf(&builder, "</text>\n")
+
+	if line := attribution(card); line != "" {
+		maxChars := int(float64(area.Width) / (svgCharWidth * svgAttributionPx))
+		y := Height - margin
+		fmt.Fprintf(&builder, `<rect x="%d" y="%d" width="80" height="3" fill="%s"/>`+"\n", area.X, y-2*svgAttributionPx, svgColor(tmpl.Accent))
+		fmt.Fprintf(&builder, `<text x="%d" y="%d" font-family="%s" font-size="%d" font-style="italic" fill="%s">%s</text>`+"\n",
+			area.X, y, html.EscapeString(tmpl.FontFamily), svgAttributionPx, svgColor(tmpl.Accent), html.EscapeString(truncateLine(line, maxChars)))
+	}
+
+	fmt.Fprintf(&builder, "</svg>\n")
+	return []byte(builder.String())
+}
+
+// svgCoverURI embeds a cover image as a data URI, or returns "" when there is
+// no cover or it is not an image
+func svgCoverURI(cover []byte) string {
+	if len(cover) == 0 {
+		return ""
+	}
+	mimeType := http.DetectContentType(cover)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return ""
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(cover)
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -3,10 +3,13 @@ package readwise
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -15,15 +18,17 @@ const (
 	authAPIURL   = "https://readwise.io/api/v2/auth/"
 
 	defaultTimeout     = 30 * time.Second
-	maxRetries         = 3
+	maxRetries         = 5
 	initialRetryDelay  = 1 * time.Second
 	maxRetryDelay      = 30 * time.Second
+	maxRetryAfter      = 5 * time.Minute // Upper bound for server-requested waits
 	retryBackoffFactor = 2
 )
 
 // Client interfaces with the Readwise Export API
 type Client struct {
 	httpClient *http.Client
+	exportURL  string // Overrides exportAPIURL (used in tests)
 }
 
 // NewClient creates a new Readwise API client
@@ -35,6 +40,12 @@ func NewClient() *Client {
 	}
 }
 
+// WithExportURL points the client at a different Export API endpoint, such as a test server
+func (c *Client) WithExportURL(exportURL string) *Client {
+	c.exportURL = exportURL
+	return c
+}
+
 // ExportResponse represents the response from the Readwise Export API
 type ExportResponse struct {
 	Count          int        `json:"count"`
@@ -112,9 +123,14 @@ func (c *Client) ValidateToken(ctx context.Context, token string) error {
 	return nil
 }
 
-// Export fetches highlights from the Readwise Export API with optional pagination and incremental sync
+// Export fetches highlights from the Readwise Export API with optional pagination and incremental sync.
+// Rate-limited and server-error responses are retried, honouring the Retry-After header with jitter.
 func (c *Client) Export(ctx context.Context, token string, updatedAfter *time.Time, cursor string) (*ExportResponse, error) {
-	u, err := url.Parse(exportAPIURL)
+	endpoint := c.exportURL
+	if endpoint == "" {
+		endpoint = exportAPIURL
+	}
+	u, err := url.Parse(endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
@@ -133,7 +149,7 @@ func (c *Client) Export(ctx context.Context, token string, updatedAfter *time.Ti
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		if attempt > 0 {
-			delay := calculateRetryDelay(attempt)
+			delay := retryDelayFor(lastErr, attempt)
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
@@ -155,24 +171,41 @@ func (c *Client) Export(ctx context.Context, token string, updatedAfter *time.Ti
 	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
-// ExportAll fetches all highlights by paginating through all pages
-func (c *Client) ExportAll(ctx context.Context, token string, updatedAfter *time.Time) ([]BookData, error) {
-	var allBooks []BookData
-	var cursor string
+// PageHandler processes one page of export results. Returning an error stops pagination.
+type PageHandler func(page *ExportResponse) error
 
+// ExportPages fetches export pages one at a time starting at cursor ("" for the first page),
+// passing each to handle before requesting the next. Callers can persist
+// page.NextPageCursor in handle to resume an interrupted export later.
+func (c *Client) ExportPages(ctx context.Context, token string, updatedAfter *time.Time, cursor string, handle PageHandler) error {
 	for {
 		resp, err := c.Export(ctx, token, updatedAfter, cursor)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		allBooks = append(allBooks, resp.Results...)
+		if err := handle(resp); err != nil {
+			return err
+		}
 
 		if resp.NextPageCursor == nil || *resp.NextPageCursor == "" {
-			break
+			return nil
 		}
 		cursor = *resp.NextPageCursor
 	}
+}
+
+// ExportAll fetches all highlights by paginating through all pages
+func (c *Client) ExportAll(ctx context.Context, token string, updatedAfter *time.Time) ([]BookData, error) {
+	var allBooks []BookData
+
+	err := c.ExportPages(ctx, token, updatedAfter, "", func(page *ExportResponse) error {
+		allBooks = append(allBooks, page.Results...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	return allBooks, nil
 }
@@ -195,7 +228,7 @@ func (c *Client) doExportRequest(ctx context.Context, url, token string) (*Expor
 		return nil, ErrInvalidToken
 	}
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, ErrRateLimited
+		return nil, &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
 	}
 	if resp.StatusCode >= 500 {
 		return nil, &ServerError{StatusCode: resp.StatusCode}
@@ -224,12 +257,57 @@ func calculateRetryDelay(attempt int) time.Duration {
 	return delay
 }
 
-func isRetryableError(err error) bool {
-	if err == ErrRateLimited {
-		return true
+// retryDelayFor returns how long to wait before the given retry attempt.
+// A server-provided Retry-After takes precedence over exponential backoff;
+// both are jittered so concurrent clients don't retry in lockstep.
+func retryDelayFor(err error, attempt int) time.Duration {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+		delay := rateLimitErr.RetryAfter
+		if delay > maxRetryAfter {
+			delay = maxRetryAfter
+		}
+		// Never retry earlier than requested; add up to 10% on top
+		return delay + jitter(delay/10)
+	}
+
+	// Equal jitter: half the backoff is fixed, the other half random
+	delay := calculateRetryDelay(attempt)
+	return delay/2 + jitter(delay/2)
+}
+
+// jitter returns a random duration in [0, max).
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// parseRetryAfter parses a Retry-After header given either as seconds or as an HTTP date.
+// Returns 0 if the header is missing or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d
+		}
 	}
-	if _, ok := err.(*ServerError); ok {
+	return 0
+}
+
+func isRetryableError(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
 		return true
 	}
-	return false
+	var serverErr *ServerError
+	return errors.As(err, &serverErr)
 }
@@ -3,6 +3,8 @@ package readwise
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -216,7 +218,7 @@ func TestClient_RateLimitRetry(t *testing.T) {
 	// Test that rate limit triggers retry
 	ctx := context.Background()
 	_, err := client.doExportRequest(ctx, server.URL, "test-token")
-	if err != ErrRateLimited {
+	if !errors.Is(err, ErrRateLimited) {
 		t.Errorf("expected ErrRateLimited on first request")
 	}
 
@@ -227,6 +229,141 @@ func TestClient_RateLimitRetry(t *testing.T) {
 	}
 }
 
+func TestClient_RateLimitRetryAfterHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "42")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		httpClient: server.Client(),
+	}
+
+	_, err := client.doExportRequest(context.Background(), server.URL, "test-token")
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if rateLimitErr.RetryAfter != 42*time.Second {
+		t.Errorf("expected RetryAfter 42s, got %v", rateLimitErr.RetryAfter)
+	}
+}
+
+func TestClient_ExportPagesResumesFromCursor(t *testing.T) {
+	cursor2, cursor3 := "p2", "p3"
+	pages := map[string]ExportResponse{
+		"":   {Results: []BookData{{UserBookID: 1}}, NextPageCursor: &cursor2},
+		"p2": {Results: []BookData{{UserBookID: 2}}, NextPageCursor: &cursor3},
+		"p3": {Results: []BookData{{UserBookID: 3}}},
+	}
+	var requested []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("pageCursor")
+		requested = append(requested, cursor)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pages[cursor])
+	}))
+	defer server.Close()
+
+	client := &Client{
+		httpClient: server.Client(),
+		exportURL:  server.URL,
+	}
+
+	var bookIDs []int
+	err := client.ExportPages(context.Background(), "test-token", nil, "p2", func(page *ExportResponse) error {
+		for _, book := range page.Results {
+			bookIDs = append(bookIDs, book.UserBookID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportPages failed: %v", err)
+	}
+
+	if len(requested) != 2 || requested[0] != "p2" || requested[1] != "p3" {
+		t.Errorf("expected requests for [p2 p3], got %v", requested)
+	}
+	if len(bookIDs) != 2 || bookIDs[0] != 2 || bookIDs[1] != 3 {
+		t.Errorf("expected books [2 3], got %v", bookIDs)
+	}
+}
+
+func TestClient_ExportPagesStopsOnHandlerError(t *testing.T) {
+	next := "p2"
+	requestCount := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ExportResponse{NextPageCursor: &next})
+	}))
+	defer server.Close()
+
+	client := &Client{
+		httpClient: server.Client(),
+		exportURL:  server.URL,
+	}
+
+	handlerErr := errors.New("save failed")
+	err := client.ExportPages(context.Background(), "test-token", nil, "", func(page *ExportResponse) error {
+		return handlerErr
+	})
+	if !errors.Is(err, handlerErr) {
+		t.Errorf("expected handler error, got %v", err)
+	}
+	if requestCount != 1 {
+		t.Errorf("expected 1 request, got %d", requestCount)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-5", 0},
+		{"soon", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		got := parseRetryAfter(tt.value, now)
+		if got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelayFor(t *testing.T) {
+	// Retry-After is honoured, never undercut, and capped
+	delay := retryDelayFor(&RateLimitError{RetryAfter: 10 * time.Second}, 1)
+	if delay < 10*time.Second || delay > 11*time.Second {
+		t.Errorf("expected delay within [10s, 11s], got %v", delay)
+	}
+
+	delay = retryDelayFor(&RateLimitError{RetryAfter: time.Hour}, 1)
+	if delay < maxRetryAfter || delay > maxRetryAfter+maxRetryAfter/10 {
+		t.Errorf("expected delay capped near %v, got %v", maxRetryAfter, delay)
+	}
+
+	// Without Retry-After, backoff is jittered within [base/2, base]
+	for attempt := 1; attempt <= 3; attempt++ {
+		base := calculateRetryDelay(attempt)
+		delay := retryDelayFor(&ServerError{StatusCode: 503}, attempt)
+		if delay < base/2 || delay > base {
+			t.Errorf("retryDelayFor(attempt %d) = %v, want within [%v, %v]", attempt, delay, base/2, base)
+		}
+	}
+}
+
 func TestCalculateRetryDelay(t *testing.T) {
 	tests := []struct {
 		attempt int
@@ -254,6 +391,8 @@ func TestIsRetryableError(t *testing.T) {
 		want bool
 	}{
 		{ErrRateLimited, true},
+		{&RateLimitError{RetryAfter: time.Second}, true},
+		{fmt.Errorf("wrapped: %w", &ServerError{StatusCode: 502}), true},
 		{&ServerError{StatusCode: 500}, true},
 		{&ServerError{StatusCode: 503}, true},
 		{ErrInvalidToken, false},
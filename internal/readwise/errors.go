@@ -3,6 +3,7 @@ package readwise
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // ErrInvalidToken indicates the provided API token is invalid
@@ -11,6 +12,24 @@ var ErrInvalidToken = errors.New("invalid or expired Readwise token")
 // ErrRateLimited indicates the API rate limit was exceeded
 var ErrRateLimited = errors.New("readwise API rate limit exceeded")
 
+// RateLimitError is returned when the API responds with HTTP 429.
+// RetryAfter holds the server-requested wait from the Retry-After header, or 0 if absent.
+// It matches ErrRateLimited with errors.Is.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s (retry after %v)", ErrRateLimited.Error(), e.RetryAfter)
+	}
+	return ErrRateLimited.Error()
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
 // ServerError represents a 5xx error from the Readwise API
 type ServerError struct {
 	StatusCode int
@@ -0,0 +1,23 @@
+package scheduler
+
+import (
+	"github.com/mrlokans/assistant/internal/events"
+)
+
+// SyncEvent is the payload of the sync events published by the schedulers.
+type SyncEvent struct {
+	Sync        string `json:"sync"` // readwise_sync, obsidian_sync or export_target
+	Description string `json:"description,omitempty"`
+	Books       int    `json:"books,omitempty"`
+	Highlights  int    `json:"highlights,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// publishSyncResult announces the outcome of a sync run
+func publishSyncResult(broker *events.Broker, sync, description string, err error) {
+	event := SyncEvent{Sync: sync, Description: description}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	broker.Publish(events.TypeSyncCompleted, event)
+}
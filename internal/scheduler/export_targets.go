@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/audit"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
+	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+	"github.com/robfig/cron/v3"
+)
+
+// ExportTargetRun is the outcome of running one export target
+type ExportTargetRun struct {
+	TargetID uint                   `json:"target_id"`
+	Name     string                 `json:"name"`
+	Result   exporters.ExportResult `json:"result"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// ExportTargetScheduler runs named export targets on their own cron schedules
+type ExportTargetScheduler struct {
+	db           *database.Database
+	auditService *audit.Service
+	events       *events.Broker
+
+	cron      *cron.Cron
+	entries   map[uint]cron.EntryID
+	mu        sync.RWMutex
+	isRunning bool
+
+	// runMu serializes runs so scheduled and manual exports never write the same files at once
+	runMu sync.Mutex
+}
+
+// NewExportTargetScheduler creates a new scheduler instance
+func NewExportTargetScheduler(db *database.Database, auditService *audit.Service) *ExportTargetScheduler {
+	return &ExportTargetScheduler{
+		db:           db,
+		auditService: auditService,
+	}
+}
+
+// SetEventBroker sets where the results of export target runs are published.
+func (s *ExportTargetScheduler) SetEventBroker(broker *events.Broker) {
+	s.events = broker
+}
+
+// Start schedules every export target that has a cron schedule
+func (s *ExportTargetScheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isRunning {
+		return nil
+	}
+
+	targets, err := s.db.ListExportTargets()
+	if err != nil {
+		return fmt.Errorf("failed to load export targets: %w", err)
+	}
+
+	s.cron = cron.New(cron.WithParser(cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)))
+	s.entries = make(map[uint]cron.EntryID)
+	for _, target := range targets {
+		if target.Schedule == "" {
+			continue
+		}
+		if err := settingsstore.ValidateCronSchedule(target.Schedule); err != nil {
+			log.Printf("Export targets: skipping %q, invalid cron schedule '%s': %v", target.Name, target.Schedule, err)
+			continue
+		}
+
+		id := target.ID
+		entryID, err := s.cron.AddFunc(target.Schedule, func() {
+			_, _ = s.RunTarget(id)
+		})
+		if err != nil {
+			log.Printf("Export targets: failed to schedule %q: %v", target.Name, err)
+			continue
+		}
+		s.entries[id] = entryID
+	}
+
+	s.cron.Start()
+	s.isRunning = true
+	log.Printf("Export targets scheduler: started with %d scheduled of %d targets", len(s.entries), len(targets))
+
+	go func() {
+		<-ctx.Done()
+		s.Stop()
+	}()
+
+	return nil
+}
+
+// Stop gracefully stops the scheduler, waiting for running exports to finish
+func (s *ExportTargetScheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isRunning {
+		return
+	}
+
+	<-s.cron.Stop().Done()
+	s.isRunning = false
+
+	log.Printf("Export targets scheduler: stopped")
+}
+
+// Reschedule reloads the targets (call after they change)
+func (s *ExportTargetScheduler) Reschedule() error {
+	s.Stop()
+	return s.Start(context.Background())
+}
+
+// ValidateSchedule checks a target's cron schedule; an empty schedule is valid
+func (s *ExportTargetScheduler) ValidateSchedule(schedule string) error {
+	if schedule == "" {
+		return nil
+	}
+	return settingsstore.ValidateCronSchedule(schedule)
+}
+
+// GetNextRunTime returns when a target runs next, or nil if it is not scheduled
+func (s *ExportTargetScheduler) GetNextRunTime(targetID uint) *time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.isRunning {
+		return nil
+	}
+	entryID, ok := s.entries[targetID]
+	if !ok {
+		return nil
+	}
+	next := s.cron.Entry(entryID).Next
+	return &next
+}
+
+// RunTarget exports to one target now and records the outcome on it
+func (s *ExportTargetScheduler) RunTarget(id uint) (ExportTargetRun, error) {
+	target, err := s.db.GetExportTarget(id)
+	if err != nil {
+		return ExportTargetRun{TargetID: id}, err
+	}
+	return s.run(target), nil
+}
+
+// RunAll exports to every target in name order
+func (s *ExportTargetScheduler) RunAll() ([]ExportTargetRun, error) {
+	targets, err := s.db.ListExportTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]ExportTargetRun, 0, len(targets))
+	for i := range targets {
+		runs = append(runs, s.run(&targets[i]))
+	}
+	return runs, nil
+}
+
+func (s *ExportTargetScheduler) run(target *entities.ExportTarget) ExportTargetRun {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	log.Printf("Export target %q: starting %s export to %s", target.Name, target.Format, target.Path)
+	startTime := time.Now()
+	run := ExportTargetRun{TargetID: target.ID, Name: target.Name}
+
+	// The lock also keeps out exports of other processes sharing the database
+	var result exporters.ExportResult
+	lock, err := s.db.AcquireLock(entities.LockExport, fmt.Sprintf("export target %q", target.Name))
+	if err == nil {
+		settings := settingsstore.New(s.db)
+		result, err = exporters.RunTarget(s.db, target, settings.GetExportFilenameStyle(), settings.GetDefaultLanguage())
+		lock.Release()
+	}
+	run.Result = result
+
+	status, message := entities.ExportTargetStatusSuccess, fmt.Sprintf("Exported %d books, %d highlights in %v",
+		result.BooksProcessed, result.HighlightsProcessed, time.Since(startTime).Round(time.Millisecond))
+	if err != nil {
+		status, message = entities.ExportTargetStatusFailed, fmt.Sprintf("Export failed: %v", err)
+		run.Error = err.Error()
+	}
+	log.Printf("Export target %q: %s", target.Name, message)
+
+	if recordErr := s.db.RecordExportTargetRun(target.ID, status, message, time.Now()); recordErr != nil {
+		log.Printf("Export target %q: failed to record run: %v", target.Name, recordErr)
+	}
+	if s.auditService != nil {
+		s.auditService.LogSync(0, "export_target", fmt.Sprintf("%s: %s", target.Name, message), err)
+	}
+	publishSyncResult(s.events, "export_target", fmt.Sprintf("%s: %s", target.Name, message), err)
+
+	return run
+}
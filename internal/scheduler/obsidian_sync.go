@@ -9,6 +9,8 @@ import (
 
 	"github.com/mrlokans/assistant/internal/audit"
 	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
 	"github.com/mrlokans/assistant/internal/exporters"
 	"github.com/mrlokans/assistant/internal/settingsstore"
 	"github.com/robfig/cron/v3"
@@ -19,6 +21,8 @@ type ObsidianSyncScheduler struct {
 	db            *database.Database
 	settingsStore *settingsstore.SettingsStore
 	auditService  *audit.Service
+	events        *events.Broker
+	vaultWatcher  *VaultWatcher
 
 	cron       *cron.Cron
 	entryID    cron.EntryID
@@ -37,6 +41,17 @@ func NewObsidianSyncScheduler(db *database.Database, settingsStore *settingsstor
 	}
 }
 
+// SetEventBroker sets where sync results are published.
+func (s *ObsidianSyncScheduler) SetEventBroker(broker *events.Broker) {
+	s.events = broker
+}
+
+// SetVaultWatcher makes every export first save the notes edited in the vault,
+// so an export does not overwrite edits the watcher has not seen yet.
+func (s *ObsidianSyncScheduler) SetVaultWatcher(watcher *VaultWatcher) {
+	s.vaultWatcher = watcher
+}
+
 // Start begins the scheduler if sync is enabled
 func (s *ObsidianSyncScheduler) Start(ctx context.Context) error {
 	s.mu.Lock()
@@ -177,7 +192,21 @@ func (s *ObsidianSyncScheduler) runSync() {
 		return
 	}
 
-	log.Printf("Obsidian sync: starting export to %s", config.ExportDir)
+	lock, err := s.db.AcquireLock(entities.LockExport, "obsidian sync")
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to take the export lock: %v", err)
+		log.Printf("Obsidian sync: %s", errMsg)
+		_ = s.settingsStore.SetObsidianSyncStatus("failed", errMsg)
+		s.logAudit("obsidian_sync", errMsg, err)
+		return
+	}
+	defer lock.Release()
+
+	if s.vaultWatcher != nil {
+		s.vaultWatcher.syncLocked()
+	}
+
+	log.Printf("Obsidian sync: starting %s export to %s", config.Format, config.ExportDir)
 	startTime := time.Now()
 
 	// Get all books from database
@@ -197,8 +226,17 @@ func (s *ObsidianSyncScheduler) runSync() {
 		return
 	}
 
-	// Create markdown exporter with the configured export directory
-	exporter := exporters.NewMarkdownExporter(config.ExportDir)
+	// Create the exporter for the configured format and export directory
+	exporter, err := exporters.NewFileExporter(config.Format, config.ExportDir)
+	if err != nil {
+		errMsg := fmt.Sprintf("Invalid export format: %v", err)
+		log.Printf("Obsidian sync: %s", errMsg)
+		_ = s.settingsStore.SetObsidianSyncStatus("failed", errMsg)
+		s.logAudit("obsidian_sync", errMsg, err)
+		return
+	}
+	exporter.SetFilenameStyle(s.settingsStore.GetExportFilenameStyle())
+	exporter.SetLanguage(s.settingsStore.GetDefaultLanguage())
 	result, err := exporter.Export(books)
 	if err != nil {
 		errMsg := fmt.Sprintf("Export failed: %v", err)
@@ -229,7 +267,9 @@ func (s *ObsidianSyncScheduler) runSync() {
 	s.logAudit("obsidian_sync", successMsg, nil)
 }
 
+// logAudit records the outcome of a sync in the audit log and publishes it
 func (s *ObsidianSyncScheduler) logAudit(action, description string, err error) {
+	publishSyncResult(s.events, action, description, err)
 	if s.auditService == nil {
 		return
 	}
@@ -11,8 +11,10 @@ import (
 	"github.com/mrlokans/assistant/internal/audit"
 	"github.com/mrlokans/assistant/internal/database"
 	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
 	"github.com/mrlokans/assistant/internal/readwise"
 	"github.com/mrlokans/assistant/internal/settingsstore"
+	"github.com/mrlokans/assistant/internal/utils"
 	"github.com/robfig/cron/v3"
 )
 
@@ -22,6 +24,7 @@ type ReadwiseSyncScheduler struct {
 	settingsStore *settingsstore.SettingsStore
 	client        *readwise.Client
 	auditService  *audit.Service
+	events        *events.Broker
 
 	cron       *cron.Cron
 	entryID    cron.EntryID
@@ -42,6 +45,11 @@ func NewReadwiseSyncScheduler(db *database.Database, settingsStore *settingsstor
 	}
 }
 
+// SetEventBroker sets where sync progress and results are published.
+func (s *ReadwiseSyncScheduler) SetEventBroker(broker *events.Broker) {
+	s.events = broker
+}
+
 // Start begins the scheduler if sync is enabled
 func (s *ReadwiseSyncScheduler) Start(ctx context.Context) error {
 	s.mu.Lock()
@@ -207,32 +215,24 @@ func (s *ReadwiseSyncScheduler) runSync() {
 	log.Printf("Readwise sync: starting import from Readwise API")
 	startTime := time.Now()
 
-	// Get last sync time for incremental sync
-	lastSyncAt := s.settingsStore.GetReadwiseSyncLastAt()
-	if lastSyncAt != nil {
-		log.Printf("Readwise sync: incremental sync from %s", lastSyncAt.Format(time.RFC3339))
+	// Resume an interrupted sync from its last page, otherwise start an incremental
+	// sync from the last sync time
+	state := s.settingsStore.GetReadwiseSyncResumeState()
+	if state != nil {
+		log.Printf("Readwise sync: resuming interrupted sync started at %s", state.StartedAt.Format(time.RFC3339))
 	} else {
-		log.Printf("Readwise sync: full sync (no previous sync found)")
-	}
-
-	// Fetch data from Readwise API
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
-
-	books, err := s.client.ExportAll(ctx, config.Token, lastSyncAt)
-	if err != nil {
-		errMsg := fmt.Sprintf("Failed to fetch from Readwise API: %v", err)
-		log.Printf("Readwise sync: %s", errMsg)
-		_ = s.settingsStore.SetReadwiseSyncStatus("failed", errMsg, 0)
-		s.logAudit("readwise_sync", errMsg, err)
-		return
+		state = &settingsstore.ReadwiseSyncResumeState{
+			UpdatedAfter: s.settingsStore.GetReadwiseSyncLastAt(),
+			StartedAt:    startTime.UTC(),
+		}
+		if err := s.settingsStore.SetReadwiseSyncResumeState(*state); err != nil {
+			log.Printf("Readwise sync: warning - failed to save resume state: %v", err)
+		}
 	}
-
-	if len(books) == 0 {
-		log.Printf("Readwise sync: no new books/highlights to import")
-		_ = s.settingsStore.SetReadwiseSyncStatus("success", "No new data to import", 0)
-		s.logAudit("readwise_sync", "No new data to import", nil)
-		return
+	if state.UpdatedAfter != nil {
+		log.Printf("Readwise sync: incremental sync from %s", state.UpdatedAfter.Format(time.RFC3339))
+	} else {
+		log.Printf("Readwise sync: full sync (no previous sync found)")
 	}
 
 	// Get or create the Readwise source
@@ -245,18 +245,56 @@ func (s *ReadwiseSyncScheduler) runSync() {
 		return
 	}
 
-	// Convert and save books
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// Fetch and save page by page, recording the next cursor after each page is stored
 	var totalHighlights int
 	var booksProcessed int
-	for _, bookData := range books {
-		book := convertReadwiseBook(bookData, source.ID)
-		totalHighlights += len(book.Highlights)
+	err = s.client.ExportPages(ctx, config.Token, state.UpdatedAfter, state.Cursor, func(page *readwise.ExportResponse) error {
+		for _, bookData := range page.Results {
+			book := convertReadwiseBook(bookData, source.ID)
+			totalHighlights += len(book.Highlights)
+
+			if err := s.db.SaveBook(&book); err != nil {
+				log.Printf("Readwise sync: warning - failed to save book '%s': %v", book.Title, err)
+				continue
+			}
+			booksProcessed++
+		}
+
+		s.events.Publish(events.TypeSyncProgress, SyncEvent{Sync: "readwise_sync", Books: booksProcessed, Highlights: totalHighlights})
 
-		if err := s.db.SaveBook(&book); err != nil {
-			log.Printf("Readwise sync: warning - failed to save book '%s': %v", book.Title, err)
-			continue
+		if page.NextPageCursor != nil {
+			state.Cursor = *page.NextPageCursor
+			if err := s.settingsStore.SetReadwiseSyncResumeState(*state); err != nil {
+				log.Printf("Readwise sync: warning - failed to save resume state: %v", err)
+			}
 		}
-		booksProcessed++
+		return nil
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to fetch from Readwise API after importing %d books: %v", booksProcessed, err)
+		log.Printf("Readwise sync: %s (next run will resume)", errMsg)
+		_ = s.settingsStore.SetReadwiseSyncStatus("failed", errMsg, totalHighlights)
+		s.logAudit("readwise_sync", errMsg, err)
+		return
+	}
+
+	// Highlights updated while this sync ran may have been missed, so the next
+	// incremental sync starts from when this one (or the resumed one) started
+	if err := s.settingsStore.SetReadwiseSyncLastAt(state.StartedAt); err != nil {
+		log.Printf("Readwise sync: warning - failed to save last sync time: %v", err)
+	}
+	if err := s.settingsStore.ClearReadwiseSyncResumeState(); err != nil {
+		log.Printf("Readwise sync: warning - failed to clear resume state: %v", err)
+	}
+
+	if booksProcessed == 0 && totalHighlights == 0 {
+		log.Printf("Readwise sync: no new books/highlights to import")
+		_ = s.settingsStore.SetReadwiseSyncStatus("success", "No new data to import", 0)
+		s.logAudit("readwise_sync", "No new data to import", nil)
+		return
 	}
 
 	duration := time.Since(startTime)
@@ -267,7 +305,9 @@ func (s *ReadwiseSyncScheduler) runSync() {
 	s.logAudit("readwise_sync", successMsg, nil)
 }
 
+// logAudit records the outcome of a sync in the audit log and publishes it
 func (s *ReadwiseSyncScheduler) logAudit(action, description string, err error) {
+	publishSyncResult(s.events, action, description, err)
 	if s.auditService == nil {
 		return
 	}
@@ -284,6 +324,10 @@ func convertReadwiseBook(data readwise.BookData, sourceID uint) entities.Book {
 		ExternalID: strconv.Itoa(data.UserBookID),
 		SourceID:   sourceID,
 	}
+	if utils.IsWebURL(data.SourceURL) {
+		// Articles link to their page; books get app links such as kindle:// or none
+		book.URL = data.SourceURL
+	}
 
 	for _, h := range data.Highlights {
 		highlight := convertReadwiseHighlight(h, sourceID)
@@ -310,6 +354,9 @@ func convertReadwiseHighlight(data readwise.HighlightData, sourceID uint) entiti
 		ExternalID:    strconv.Itoa(data.ID),
 		SourceID:      sourceID,
 	}
+	if data.URL != nil && utils.IsWebURL(*data.URL) {
+		highlight.URL = *data.URL
+	}
 
 	return highlight
 }
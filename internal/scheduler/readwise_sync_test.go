@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/crypto"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/readwise"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+)
+
+func setupReadwiseSync(t *testing.T, handler http.HandlerFunc) (*ReadwiseSyncScheduler, *settingsstore.SettingsStore) {
+	t.Helper()
+	db, err := database.NewDatabase(filepath.Join(t.TempDir(), "readwise_sync.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	key, err := crypto.GenerateKeyBytes()
+	require.NoError(t, err)
+	encryptor, err := crypto.NewEncryptor(key)
+	require.NoError(t, err)
+	store := settingsstore.New(db).WithEncryptor(encryptor)
+	require.NoError(t, store.SetReadwiseSyncEnabled(true))
+	require.NoError(t, store.SetReadwiseSyncToken("test-token"))
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := readwise.NewClient().WithExportURL(server.URL)
+	return NewReadwiseSyncScheduler(db, store, client, nil), store
+}
+
+// A sync resumed after an interruption continues from the saved cursor and,
+// once done, advances the incremental sync time to when the interrupted sync
+// started rather than to when it finished.
+func TestReadwiseSync_ResumedSyncAdvancesLastAtToStart(t *testing.T) {
+	lastAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	startedAt := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+
+	var requests []string
+	scheduler, store := setupReadwiseSync(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query().Get("pageCursor"))
+		assert.Equal(t, lastAt.Format(time.RFC3339), r.URL.Query().Get("updatedAfter"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(readwise.ExportResponse{Results: []readwise.BookData{{
+			UserBookID: 7,
+			Title:      "Dune",
+			Author:     "Frank Herbert",
+			Highlights: []readwise.HighlightData{{ID: 1, Text: "Fear is the mind-killer"}},
+		}}})
+	})
+	require.NoError(t, store.SetReadwiseSyncLastAt(lastAt))
+	require.NoError(t, store.SetReadwiseSyncResumeState(settingsstore.ReadwiseSyncResumeState{
+		Cursor:       "page-2",
+		UpdatedAfter: &lastAt,
+		StartedAt:    startedAt,
+	}))
+
+	scheduler.runSync()
+
+	assert.Equal(t, []string{"page-2"}, requests)
+	assert.Nil(t, store.GetReadwiseSyncResumeState())
+	status := store.GetReadwiseSyncStatus()
+	assert.Equal(t, "success", status.Status)
+	assert.Equal(t, 1, status.HighlightsSynced)
+	if got := store.GetReadwiseSyncLastAt(); assert.NotNil(t, got) {
+		assert.True(t, startedAt.Equal(*got), "last sync time = %v, expected %v", *got, startedAt)
+	}
+}
+
+// A failed sync keeps both the incremental sync time and the resume state, so
+// the next run picks up the same export again.
+func TestReadwiseSync_FailureKeepsLastAt(t *testing.T) {
+	lastAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	scheduler, store := setupReadwiseSync(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	require.NoError(t, store.SetReadwiseSyncLastAt(lastAt))
+
+	scheduler.runSync()
+
+	status := store.GetReadwiseSyncStatus()
+	assert.Equal(t, "failed", status.Status)
+	if got := store.GetReadwiseSyncLastAt(); assert.NotNil(t, got) {
+		assert.True(t, lastAt.Equal(*got), "last sync time = %v, expected %v", *got, lastAt)
+	}
+	if state := store.GetReadwiseSyncResumeState(); assert.NotNil(t, state) && assert.NotNil(t, state.UpdatedAfter) {
+		assert.True(t, lastAt.Equal(*state.UpdatedAfter))
+	}
+}
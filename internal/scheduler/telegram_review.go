@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/settingsstore"
+	"github.com/mrlokans/assistant/internal/telegram"
+	"github.com/robfig/cron/v3"
+)
+
+// TelegramReviewScheduler sends the daily review of random highlights to Telegram
+type TelegramReviewScheduler struct {
+	settingsStore *settingsstore.SettingsStore
+	bot           *telegram.Bot
+
+	cron      *cron.Cron
+	entryID   cron.EntryID
+	mu        sync.RWMutex
+	isRunning bool
+}
+
+// NewTelegramReviewScheduler creates a new scheduler instance
+func NewTelegramReviewScheduler(settingsStore *settingsstore.SettingsStore, bot *telegram.Bot) *TelegramReviewScheduler {
+	return &TelegramReviewScheduler{
+		settingsStore: settingsStore,
+		bot:           bot,
+		cron:          cron.New(cron.WithParser(cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow))),
+	}
+}
+
+// Start begins the scheduler if the bot is enabled and has a chat to send to
+func (s *TelegramReviewScheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isRunning {
+		return nil
+	}
+
+	config := s.settingsStore.GetTelegramConfig()
+
+	if !config.Enabled {
+		log.Printf("Telegram review scheduler: disabled")
+		return nil
+	}
+
+	if config.Token == "" || config.ChatID == 0 {
+		log.Printf("Telegram review scheduler: token or chat ID not configured, skipping")
+		return nil
+	}
+
+	if err := settingsstore.ValidateCronSchedule(config.ReviewSchedule); err != nil {
+		return fmt.Errorf("invalid cron schedule '%s': %w", config.ReviewSchedule, err)
+	}
+
+	// The review goes out at the scheduled time in the default timezone
+	zone := s.settingsStore.GetDefaultPreferences().Location.String()
+	entryID, err := s.cron.AddFunc("CRON_TZ="+zone+" "+config.ReviewSchedule, func() {
+		s.runReview()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule review job: %w", err)
+	}
+	s.entryID = entryID
+
+	s.cron.Start()
+	s.isRunning = true
+
+	nextRun, _ := settingsstore.GetNextRunTime(config.ReviewSchedule)
+	log.Printf("Telegram review scheduler: started with schedule '%s' (%s, %s). Next run: %v",
+		config.ReviewSchedule,
+		settingsstore.GetCronDescription(config.ReviewSchedule),
+		zone,
+		nextRun)
+
+	go func() {
+		<-ctx.Done()
+		s.Stop()
+	}()
+
+	return nil
+}
+
+// Stop gracefully stops the scheduler
+func (s *TelegramReviewScheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isRunning {
+		return
+	}
+
+	ctx := s.cron.Stop()
+	<-ctx.Done()
+	s.cron.Remove(s.entryID)
+
+	s.isRunning = false
+
+	log.Printf("Telegram review scheduler: stopped")
+}
+
+// Reschedule updates the schedule (call after settings change)
+func (s *TelegramReviewScheduler) Reschedule() error {
+	s.Stop()
+	return s.Start(context.Background())
+}
+
+// RunNow sends a review immediately and returns the send error, if any
+func (s *TelegramReviewScheduler) RunNow(ctx context.Context) error {
+	return s.bot.SendDailyReview(ctx)
+}
+
+// IsRunning returns whether the scheduler is active
+func (s *TelegramReviewScheduler) IsRunning() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isRunning
+}
+
+// GetNextRunTime returns when the next review will be sent
+func (s *TelegramReviewScheduler) GetNextRunTime() *time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.isRunning {
+		return nil
+	}
+
+	for _, entry := range s.cron.Entries() {
+		if entry.ID == s.entryID {
+			t := entry.Next
+			return &t
+		}
+	}
+	return nil
+}
+
+func (s *TelegramReviewScheduler) runReview() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := s.bot.SendDailyReview(ctx); err != nil {
+		log.Printf("Telegram review: failed to send daily review: %v", err)
+		return
+	}
+	log.Printf("Telegram review: daily review sent")
+}
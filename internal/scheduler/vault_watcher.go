@@ -0,0 +1,319 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/audit"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/events"
+	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/parsers"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+)
+
+// DefaultVaultPollInterval is how often the vault is checked for edited files
+const DefaultVaultPollInterval = 30 * time.Second
+
+// VaultWatcher syncs notes edited in the exported markdown files back into the
+// database. Only the "**Note:**" lines of each highlight callout are synced;
+// the rest of a file is still overwritten by the next export.
+//
+// Each markdown export records the notes it wrote (see exporters.ExportedNotes).
+// A note changed in the file is saved when the highlight's note in the database
+// still matches the exported one; when both changed, the edit is reported as a
+// conflict and the database keeps its note. As the exported notes are kept in
+// the vault, edits made while the app was stopped are synced at startup.
+type VaultWatcher struct {
+	db            *database.Database
+	settingsStore *settingsstore.SettingsStore
+	auditService  *audit.Service
+	events        *events.Broker
+
+	// Interval between checks of the vault, DefaultVaultPollInterval by default
+	Interval time.Duration
+
+	mu         sync.Mutex
+	isRunning  bool
+	cancelFunc context.CancelFunc
+	done       chan struct{}
+
+	scanMu   sync.Mutex
+	dir      string
+	modTimes map[string]time.Time // Modification time of each file when last checked
+}
+
+// VaultSyncResult counts what a check of the vault did
+type VaultSyncResult struct {
+	NotesUpdated int
+	Conflicts    int
+}
+
+// NewVaultWatcher creates a new watcher instance
+func NewVaultWatcher(db *database.Database, settingsStore *settingsstore.SettingsStore, auditService *audit.Service) *VaultWatcher {
+	return &VaultWatcher{
+		db:            db,
+		settingsStore: settingsStore,
+		auditService:  auditService,
+		Interval:      DefaultVaultPollInterval,
+	}
+}
+
+// SetEventBroker sets where sync results are published.
+func (w *VaultWatcher) SetEventBroker(broker *events.Broker) {
+	w.events = broker
+}
+
+// Start begins watching if two-way sync is enabled
+func (w *VaultWatcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.isRunning {
+		return nil
+	}
+
+	config := w.settingsStore.GetObsidianSyncConfig()
+	if !config.TwoWay {
+		log.Printf("Vault watcher: disabled")
+		return nil
+	}
+	if config.ExportDir == "" {
+		log.Printf("Vault watcher: export directory not configured, skipping")
+		return nil
+	}
+	if config.Format != "" && config.Format != exporters.FormatMarkdown {
+		log.Printf("Vault watcher: only markdown exports can be synced back, skipping (format %s)", config.Format)
+		return nil
+	}
+
+	w.scanMu.Lock()
+	w.dir = config.ExportDir
+	w.modTimes = make(map[string]time.Time)
+	w.scanMu.Unlock()
+
+	var cancelCtx context.Context
+	cancelCtx, w.cancelFunc = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+	w.isRunning = true
+
+	go w.run(cancelCtx, w.done)
+
+	log.Printf("Vault watcher: watching %s every %v", config.ExportDir, w.Interval)
+	return nil
+}
+
+// Stop stops watching and waits for a running check to finish
+func (w *VaultWatcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.isRunning {
+		return
+	}
+
+	w.cancelFunc()
+	<-w.done
+
+	w.isRunning = false
+	w.cancelFunc = nil
+
+	log.Printf("Vault watcher: stopped")
+}
+
+// Reschedule restarts the watcher with the current settings (call after settings change)
+func (w *VaultWatcher) Reschedule() error {
+	w.Stop()
+	return w.Start(context.Background())
+}
+
+// IsRunning returns whether the watcher is active
+func (w *VaultWatcher) IsRunning() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.isRunning
+}
+
+func (w *VaultWatcher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	w.poll()
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// poll checks the vault unless an export is writing to it
+func (w *VaultWatcher) poll() {
+	lock, err := w.db.TryAcquireLock(entities.LockExport, "vault watcher")
+	if errors.Is(err, database.ErrLockHeld) {
+		return // Checked again on the next tick
+	}
+	if err != nil {
+		log.Printf("Vault watcher: failed to take the export lock: %v", err)
+		return
+	}
+	defer lock.Release()
+
+	w.syncLocked()
+}
+
+// syncLocked saves the notes edited in the vault since the last check. The
+// caller holds the export lock, so no export writes to the vault meanwhile.
+func (w *VaultWatcher) syncLocked() VaultSyncResult {
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	var result VaultSyncResult
+	if w.modTimes == nil {
+		return result
+	}
+
+	var exported map[string]map[string]string // Loaded once a file changed
+	synced := make(map[string]map[string]string)
+	seen := make(map[string]bool)
+	err := filepath.Walk(w.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+		seen[path] = true
+
+		if modTime, known := w.modTimes[path]; known && modTime.Equal(info.ModTime()) {
+			return nil
+		}
+		w.modTimes[path] = info.ModTime()
+
+		if exported == nil {
+			var loadErr error
+			if exported, loadErr = exporters.ExportedNotes(w.dir); loadErr != nil {
+				log.Printf("Vault watcher: %v", loadErr)
+				delete(w.modTimes, path) // Checked again on the next tick
+				return filepath.SkipAll
+			}
+		}
+		rel, relErr := filepath.Rel(w.dir, path)
+		if relErr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		notes, ok := exported[rel]
+		if !ok {
+			// Index and vocabulary files, and files exported before notes were recorded
+			return nil
+		}
+
+		book, parseErr := parsers.NewMarkdownParser(w.dir).ParseMarkdownFile(path)
+		if parseErr != nil || len(book.Highlights) == 0 {
+			return nil
+		}
+		if updated := w.syncBook(book, notes, &result); len(updated) > 0 {
+			synced[rel] = updated
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Vault watcher: failed to walk %s: %v", w.dir, err)
+	}
+
+	// Saved notes are now the exported ones, so they don't count as edited later
+	if len(synced) > 0 {
+		if err := exporters.UpdateExportedNotes(w.dir, synced); err != nil {
+			log.Printf("Vault watcher: failed to record synced notes: %v", err)
+		}
+	}
+
+	// Forget deleted files
+	for path := range w.modTimes {
+		if !seen[path] {
+			delete(w.modTimes, path)
+		}
+	}
+
+	if result.NotesUpdated > 0 || result.Conflicts > 0 {
+		description := fmt.Sprintf("Synced %d notes from the vault, %d conflicts", result.NotesUpdated, result.Conflicts)
+		log.Printf("Vault watcher: %s", description)
+		publishSyncResult(w.events, "vault_sync", description, nil)
+	}
+	return result
+}
+
+// syncBook compares the notes of a parsed file with the notes it was exported
+// with and with the database. It returns the notes saved to the database, by
+// highlight text.
+func (w *VaultWatcher) syncBook(parsed *entities.Book, exported map[string]string, result *VaultSyncResult) map[string]string {
+	book, err := w.db.GetBookByTitleAndAuthor(parsed.Title, parsed.Author)
+	if err != nil {
+		return nil // Not imported, or renamed since the export
+	}
+
+	byText := make(map[string]*entities.Highlight, len(book.Highlights))
+	for i := range book.Highlights {
+		byText[strings.TrimSpace(book.Highlights[i].Text)] = &book.Highlights[i]
+	}
+
+	saved := make(map[string]string)
+	for _, h := range parsed.Highlights {
+		text := strings.TrimSpace(h.Text)
+		highlight, ok := byText[text]
+		if !ok {
+			continue
+		}
+		exportedNote, ok := exported[text]
+		fileNote := strings.TrimSpace(h.Note)
+		if !ok || fileNote == exportedNote {
+			continue // Not edited in the file; the next export writes the database's note
+		}
+
+		dbNote := strings.TrimSpace(highlight.Note)
+		switch {
+		case dbNote == fileNote:
+			// Already in sync
+			saved[text] = fileNote
+		case dbNote != exportedNote:
+			result.Conflicts++
+			description := fmt.Sprintf("Note of a highlight in \"%s\" was edited in the vault and in the app; kept the app's note. Vault note: %q",
+				book.Title, fileNote)
+			log.Printf("Vault watcher: %s", description)
+			w.logAudit(description, fmt.Errorf("conflicting note edits for highlight %d", highlight.ID))
+		default:
+			current, err := w.db.GetHighlightByID(highlight.ID)
+			if err != nil {
+				log.Printf("Vault watcher: failed to load highlight %d: %v", highlight.ID, err)
+				continue
+			}
+			current.Note = fileNote
+			if err := w.db.UpdateHighlight(current); err != nil {
+				log.Printf("Vault watcher: failed to save note of highlight %d: %v", highlight.ID, err)
+				continue
+			}
+			saved[text] = fileNote
+			result.NotesUpdated++
+		}
+	}
+	return saved
+}
+
+// logAudit records a conflict in the audit log and publishes it
+func (w *VaultWatcher) logAudit(description string, err error) {
+	publishSyncResult(w.events, "vault_sync", description, err)
+	if w.auditService == nil {
+		return
+	}
+	w.auditService.LogSync(0, "vault_sync", description, err)
+}
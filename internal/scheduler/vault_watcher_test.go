@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+)
+
+type vaultFixture struct {
+	db        *database.Database
+	dir       string
+	highlight uint   // Highlight whose note is edited
+	file      string // Exported file of its book
+}
+
+// setupVault saves a book with a noted highlight and exports it to a vault
+func setupVault(t *testing.T) *vaultFixture {
+	t.Helper()
+	db, err := database.NewDatabase(filepath.Join(t.TempDir(), "vault.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	book := &entities.Book{Title: "Dune", Author: "Frank Herbert", Source: entities.Source{Name: "kindle"}, Highlights: []entities.Highlight{
+		{Text: "Fear is the mind-killer", Note: "Litany"},
+		{Text: "The spice must flow"},
+	}}
+	require.NoError(t, db.SaveBook(book))
+	saved, err := db.GetBookByID(book.ID)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	_, err = exporters.NewMarkdownExporter(dir).Export([]entities.Book{*saved})
+	require.NoError(t, err)
+
+	fixture := &vaultFixture{db: db, dir: dir, file: exporters.ExportedBookPath(dir, exporters.FormatMarkdown, saved)}
+	for _, h := range saved.Highlights {
+		if h.Text == "Fear is the mind-killer" {
+			fixture.highlight = h.ID
+		}
+	}
+	return fixture
+}
+
+func (f *vaultFixture) watcher() *VaultWatcher {
+	w := NewVaultWatcher(f.db, nil, nil)
+	w.dir = f.dir
+	w.modTimes = make(map[string]time.Time)
+	return w
+}
+
+// editFile replaces the note in the exported file, moving its modification
+// time forward so the change is noticed
+func (f *vaultFixture) editFile(t *testing.T, note string) {
+	t.Helper()
+	data, err := os.ReadFile(f.file)
+	require.NoError(t, err)
+	edited := strings.Replace(string(data), "**Note:** Litany", "**Note:** "+note, 1)
+	require.NotEqual(t, string(data), edited)
+	require.NoError(t, os.WriteFile(f.file, []byte(edited), 0644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(f.file, later, later))
+}
+
+func (f *vaultFixture) editDB(t *testing.T, note string) {
+	t.Helper()
+	h, err := f.db.GetHighlightByID(f.highlight)
+	require.NoError(t, err)
+	h.Note = note
+	require.NoError(t, f.db.UpdateHighlight(h))
+}
+
+func (f *vaultFixture) dbNote(t *testing.T) string {
+	t.Helper()
+	h, err := f.db.GetHighlightByID(f.highlight)
+	require.NoError(t, err)
+	return h.Note
+}
+
+func TestVaultWatcher_FileEditIsSaved(t *testing.T) {
+	f := setupVault(t)
+	w := f.watcher()
+	assert.Equal(t, VaultSyncResult{}, w.syncLocked())
+
+	f.editFile(t, "Litany against fear")
+	assert.Equal(t, VaultSyncResult{NotesUpdated: 1}, w.syncLocked())
+	assert.Equal(t, "Litany against fear", f.dbNote(t))
+
+	// The saved note counts as exported, so a later edit in the app is not a conflict
+	f.editDB(t, "Bene Gesserit litany")
+	later := time.Now().Add(2 * time.Minute)
+	require.NoError(t, os.Chtimes(f.file, later, later))
+	assert.Equal(t, VaultSyncResult{}, f.watcher().syncLocked())
+	assert.Equal(t, "Bene Gesserit litany", f.dbNote(t))
+}
+
+func TestVaultWatcher_DatabaseEditIsKept(t *testing.T) {
+	f := setupVault(t)
+	f.editDB(t, "Bene Gesserit litany")
+
+	assert.Equal(t, VaultSyncResult{}, f.watcher().syncLocked())
+	assert.Equal(t, "Bene Gesserit litany", f.dbNote(t))
+}
+
+func TestVaultWatcher_BothEditedIsConflict(t *testing.T) {
+	f := setupVault(t)
+	f.editDB(t, "Bene Gesserit litany")
+	f.editFile(t, "Litany against fear")
+
+	assert.Equal(t, VaultSyncResult{Conflicts: 1}, f.watcher().syncLocked())
+	assert.Equal(t, "Bene Gesserit litany", f.dbNote(t))
+}
+
+// A file edited while the app was stopped is synced by the first check after
+// the restart, as the exported notes are kept in the vault
+func TestVaultWatcher_EditBeforeRestartIsSaved(t *testing.T) {
+	f := setupVault(t)
+	f.editFile(t, "Litany against fear")
+
+	assert.Equal(t, VaultSyncResult{NotesUpdated: 1}, f.watcher().syncLocked())
+	assert.Equal(t, "Litany against fear", f.dbNote(t))
+}
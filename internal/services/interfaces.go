@@ -23,6 +23,8 @@ type ExportResult struct {
 	HighlightsProcessed int
 	BooksFailed         int
 	HighlightsFailed    int
+	BooksSkipped        int
+	HighlightsSkipped   int
 }
 
 // ImportResult contains the outcome of an import operation.
@@ -31,4 +33,6 @@ type ImportResult struct {
 	HighlightsProcessed int
 	BooksFailed         int
 	HighlightsFailed    int
+	BooksSkipped        int
+	HighlightsSkipped   int
 }
@@ -0,0 +1,563 @@
+package settingsstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/deeplink"
+	"github.com/mrlokans/assistant/internal/dictionary"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/exporters"
+	"github.com/mrlokans/assistant/internal/i18n"
+	"github.com/mrlokans/assistant/internal/localtime"
+)
+
+// Value types of runtime-tunable settings
+const (
+	SettingTypeString = "string"
+	SettingTypeBool   = "bool"
+	SettingTypeCron   = "cron"
+	SettingTypeChoice = "choice"
+
+	// SettingTypeTimezone is an IANA timezone name such as "Europe/Berlin"
+	SettingTypeTimezone = "timezone"
+	// SettingTypeLocale is a BCP 47 locale tag such as "en-GB"
+	SettingTypeLocale = "locale"
+	// SettingTypeURLTemplate is a deep link template, see package deeplink
+	SettingTypeURLTemplate = "url_template"
+)
+
+// LanguageAuto shows the interface in the browser's language
+const LanguageAuto = "auto"
+
+// deepLinkDisabled turns off the deep links of a source with a default template
+const deepLinkDisabled = "none"
+
+var (
+	// ErrUnknownSetting is returned for keys that are not in SettingDefinitions
+	ErrUnknownSetting = errors.New("unknown setting")
+	// ErrInvalidSettingValue is returned when a value fails validation
+	ErrInvalidSettingValue = errors.New("invalid setting value")
+)
+
+// SettingDefinition describes a runtime-tunable option and where it falls back to
+type SettingDefinition struct {
+	Key             string   `json:"key"`
+	Group           string   `json:"group"`
+	Label           string   `json:"label"`
+	Description     string   `json:"description,omitempty"`
+	Type            string   `json:"type"`
+	EnvVars         []string `json:"env_vars,omitempty"` // Checked in order when not saved in the database
+	Default         string   `json:"default"`
+	Choices         []string `json:"choices,omitempty"`
+	RequiresRestart bool     `json:"requires_restart,omitempty"`
+}
+
+// SettingValue is the effective value of a setting with its source
+type SettingValue struct {
+	SettingDefinition
+	Value  string `json:"value"`
+	Source string `json:"source"` // "database", "environment", "config", "default"
+}
+
+// SettingDefinitions lists the settings exposed through the unified settings API.
+// Integrations with credentials (Readwise, Telegram, Plausible) keep their own pages.
+var SettingDefinitions = []SettingDefinition{
+	{
+		Key:         entities.SettingKeyObsidianSyncExportDir,
+		Group:       "Exports",
+		Label:       "Export directory",
+		Description: "Directory markdown files are exported to, usually inside an Obsidian vault",
+		Type:        SettingTypeString,
+		EnvVars:     []string{"OBSIDIAN_EXPORT_DIR", "OBSIDIAN_VAULT_DIR"},
+	},
+	{
+		Key:         entities.SettingKeyObsidianSyncEnabled,
+		Group:       "Exports",
+		Label:       "Auto-export",
+		Description: "Export to the directory above on a schedule",
+		Type:        SettingTypeBool,
+		EnvVars:     []string{"OBSIDIAN_SYNC_ENABLED"},
+		Default:     "false",
+	},
+	{
+		Key:         entities.SettingKeyObsidianSyncSchedule,
+		Group:       "Exports",
+		Label:       "Auto-export schedule",
+		Description: "Cron schedule for automatic exports",
+		Type:        SettingTypeCron,
+		EnvVars:     []string{"OBSIDIAN_SYNC_SCHEDULE"},
+		Default:     "0 * * * *",
+	},
+	{
+		Key:         entities.SettingKeyObsidianSyncFormat,
+		Group:       "Exports",
+		Label:       "Export format",
+		Description: "Obsidian markdown, Logseq pages or org-mode files (org-roam)",
+		Type:        SettingTypeChoice,
+		EnvVars:     []string{"OBSIDIAN_SYNC_FORMAT"},
+		Default:     exporters.FormatMarkdown,
+		Choices:     exporters.Formats,
+	},
+	{
+		Key:         entities.SettingKeyObsidianSyncTwoWay,
+		Group:       "Exports",
+		Label:       "Two-way sync",
+		Description: "Watch the export directory and save notes edited in the exported markdown files back to their highlights",
+		Type:        SettingTypeBool,
+		EnvVars:     []string{"OBSIDIAN_SYNC_TWO_WAY"},
+		Default:     "false",
+	},
+	{
+		Key:         entities.SettingKeyExportFilenameStyle,
+		Group:       "Exports",
+		Label:       "File names",
+		Description: "Name exported files after the book title, or a lower-case slug of it. Existing files are renamed on the next export",
+		Type:        SettingTypeChoice,
+		EnvVars:     []string{"EXPORT_FILENAME_STYLE"},
+		Default:     exporters.FilenameStyleTitle,
+		Choices:     exporters.FilenameStyles,
+	},
+	{
+		Key:         entities.SettingKeyTelegramReviewSchedule,
+		Group:       "Exports",
+		Label:       "Daily digest schedule",
+		Description: "Cron schedule for the Telegram review of random highlights",
+		Type:        SettingTypeCron,
+		EnvVars:     []string{"TELEGRAM_REVIEW_SCHEDULE"},
+		Default:     "0 9 * * *",
+	},
+	{
+		Key:         entities.SettingKeyMoonReaderDropboxPath,
+		Group:       "Moon+ Reader",
+		Label:       "Dropbox backup path",
+		Description: "Dropbox folder Moon+ Reader writes its backups to",
+		Type:        SettingTypeString,
+		EnvVars:     []string{"MOONREADER_DROPBOX_PATH"},
+		Default:     "/Apps/Books/.Moon+/Backup",
+	},
+	{
+		Key:         entities.SettingKeyMoonReaderDatabasePath,
+		Group:       "Moon+ Reader",
+		Label:       "Local notes database",
+		Description: "Where imported Moon+ Reader notes are kept",
+		Type:        SettingTypeString,
+		EnvVars:     []string{"MOONREADER_DATABASE_PATH"},
+		Default:     config.DefaultMoonReaderDatabasePath,
+	},
+	{
+		Key:         entities.SettingKeyMoonReaderOutputDir,
+		Group:       "Moon+ Reader",
+		Label:       "Markdown output directory",
+		Description: "Directory Moon+ Reader imports are exported to",
+		Type:        SettingTypeString,
+		EnvVars:     []string{"MOONREADER_OUTPUT_DIR"},
+		Default:     "./markdown",
+	},
+	{
+		Key:         entities.SettingKeyMoonReaderOutputFormat,
+		Group:       "Moon+ Reader",
+		Label:       "Output format",
+		Description: "Format of the files Moon+ Reader imports are exported as",
+		Type:        SettingTypeChoice,
+		EnvVars:     []string{"MOONREADER_OUTPUT_FORMAT"},
+		Default:     exporters.FormatMarkdown,
+		Choices:     exporters.Formats,
+	},
+	{
+		Key:         entities.SettingKeyMetadataAutoEnrich,
+		Group:       "Enrichment",
+		Label:       "Enrich new books",
+		Description: "Look up covers and metadata for books missing them after each import",
+		Type:        SettingTypeBool,
+		EnvVars:     []string{"METADATA_AUTO_ENRICH"},
+		Default:     "false",
+	},
+	{
+		Key:         entities.SettingKeyVocabularyAutoExtract,
+		Group:       "Enrichment",
+		Label:       "Suggest vocabulary",
+		Description: "Suggest rare words from newly imported highlights",
+		Type:        SettingTypeBool,
+		EnvVars:     []string{"VOCABULARY_AUTO_EXTRACT"},
+		Default:     "false",
+	},
+	{
+		Key:             entities.SettingKeyDictionaryProvider,
+		Group:           "Enrichment",
+		Label:           "Dictionary provider",
+		Description:     "Service used to look up word definitions",
+		Type:            SettingTypeChoice,
+		EnvVars:         []string{"DICTIONARY_PROVIDER"},
+		Default:         dictionary.ProviderFreeDictionary,
+		Choices:         dictionary.Providers,
+		RequiresRestart: true,
+	},
+	{
+		Key:         entities.SettingKeyDictionaryFreeDictionaryEnabled,
+		Group:       "Enrichment",
+		Label:       "Free Dictionary API",
+		Description: "Look up definitions with the Free Dictionary API. Its definitions come from Wiktionary under CC BY-SA 3.0, which requires attribution when they are shared",
+		Type:        SettingTypeBool,
+		EnvVars:     []string{"DICTIONARY_FREEDICTIONARY_ENABLED"},
+		Default:     "true",
+	},
+	{
+		Key:         entities.SettingKeyDefaultTimezone,
+		Group:       "Regional",
+		Label:       "Timezone",
+		Description: "IANA timezone such as Europe/Berlin, for users without their own. Kindle dates are read in it and days start at its midnight",
+		Type:        SettingTypeTimezone,
+		EnvVars:     []string{"DEFAULT_TIMEZONE", "TZ"},
+		Default:     "UTC",
+	},
+	{
+		Key:         entities.SettingKeyDefaultLocale,
+		Group:       "Regional",
+		Label:       "Locale",
+		Description: "Language tag such as en-GB or de, for how dates are shown to users without their own. Dates are shown as 2006-01-02 when empty",
+		Type:        SettingTypeLocale,
+		EnvVars:     []string{"DEFAULT_LOCALE"},
+	},
+	{
+		Key:         entities.SettingKeyDefaultLanguage,
+		Group:       "Regional",
+		Label:       "Language",
+		Description: "Language of the interface and export indexes for users without their own; auto follows the browser",
+		Type:        SettingTypeChoice,
+		EnvVars:     []string{"DEFAULT_LANGUAGE"},
+		Default:     LanguageAuto,
+		Choices:     append([]string{LanguageAuto}, i18n.Codes()...),
+	},
+	{
+		Key:         entities.SettingKeyDeepLinkKindle,
+		Group:       "Deep links",
+		Label:       "Kindle",
+		Description: "URL opening a Kindle highlight in the Kindle app; needs the book's ASIN. Set to none to hide the link",
+		Type:        SettingTypeURLTemplate,
+		EnvVars:     []string{"DEEP_LINK_KINDLE"},
+		Default:     deeplink.Defaults["kindle"],
+	},
+	{
+		Key:         entities.SettingKeyDeepLinkAppleBooks,
+		Group:       "Deep links",
+		Label:       "Apple Books",
+		Description: "URL opening an Apple Books highlight at its EPUB CFI. Set to none to hide the link",
+		Type:        SettingTypeURLTemplate,
+		EnvVars:     []string{"DEEP_LINK_APPLE_BOOKS"},
+		Default:     deeplink.Defaults["apple_books"],
+	},
+	{
+		Key:         entities.SettingKeyDeepLinkMoonReader,
+		Group:       "Deep links",
+		Label:       "Moon+ Reader",
+		Description: "URL opening a Moon+ Reader highlight, e.g. through an automation app; {location_ref} is the chapter@split#position Moon+ Reader stores",
+		Type:        SettingTypeURLTemplate,
+		EnvVars:     []string{"DEEP_LINK_MOONREADER"},
+	},
+	{
+		Key:         entities.SettingKeyPublicLibraryEnabled,
+		Group:       "Public library",
+		Label:       "Public library",
+		Description: "Show a read-only selection of books at /public, without signing in",
+		Type:        SettingTypeBool,
+		EnvVars:     []string{"PUBLIC_LIBRARY_ENABLED"},
+		Default:     "false",
+	},
+	{
+		Key:         entities.SettingKeyPublicLibraryTitle,
+		Group:       "Public library",
+		Label:       "Title",
+		Description: "Heading of the public library pages",
+		Type:        SettingTypeString,
+		EnvVars:     []string{"PUBLIC_LIBRARY_TITLE"},
+		Default:     "Library",
+	},
+	{
+		Key:         entities.SettingKeyPublicLibraryFavourites,
+		Group:       "Public library",
+		Label:       "Include favourites",
+		Description: "List favourite books besides the ones shared from their page",
+		Type:        SettingTypeBool,
+		EnvVars:     []string{"PUBLIC_LIBRARY_FAVOURITES"},
+		Default:     "false",
+	},
+	{
+		Key:         entities.SettingKeyPublicLibraryTags,
+		Group:       "Public library",
+		Label:       "Include tags",
+		Description: "Comma-separated book tags whose books are listed too; reset to list none",
+		Type:        SettingTypeString,
+		EnvVars:     []string{"PUBLIC_LIBRARY_TAGS"},
+	},
+}
+
+// findDefinition returns the definition for key
+func findDefinition(key string) (SettingDefinition, error) {
+	for _, def := range SettingDefinitions {
+		if def.Key == key {
+			return def, nil
+		}
+	}
+	return SettingDefinition{}, fmt.Errorf("%w: %s", ErrUnknownSetting, key)
+}
+
+// resolve returns the effective value of def (database > env > default)
+func (s *SettingsStore) resolve(def SettingDefinition) SettingValue {
+	value := SettingValue{SettingDefinition: def, Value: def.Default, Source: "default"}
+
+	if setting, err := s.db.GetSetting(def.Key); err == nil && setting.Value != "" {
+		value.Value, value.Source = setting.Value, "database"
+	} else {
+		for _, env := range def.EnvVars {
+			if envVal := os.Getenv(env); envVal != "" {
+				value.Value, value.Source = envVal, "environment"
+				break
+			}
+		}
+		if configured := s.configValues[def.Key]; value.Source == "default" && configured != "" && configured != def.Default {
+			value.Value, value.Source = configured, "config"
+		}
+	}
+
+	if def.Type == SettingTypeBool {
+		value.Value = strconv.FormatBool(value.Value == "true" || value.Value == "1")
+	}
+	return value
+}
+
+// ListSettings returns the effective value of every runtime-tunable setting
+func (s *SettingsStore) ListSettings() []SettingValue {
+	values := make([]SettingValue, 0, len(SettingDefinitions))
+	for _, def := range SettingDefinitions {
+		values = append(values, s.resolve(def))
+	}
+	return values
+}
+
+// GetSettingValue returns the effective value of one setting
+func (s *SettingsStore) GetSettingValue(key string) (SettingValue, error) {
+	def, err := findDefinition(key)
+	if err != nil {
+		return SettingValue{}, err
+	}
+	return s.resolve(def), nil
+}
+
+// UpdateSetting validates value and saves it to the database
+func (s *SettingsStore) UpdateSetting(key, value string) error {
+	def, err := findDefinition(key)
+	if err != nil {
+		return err
+	}
+
+	value = strings.TrimSpace(value)
+	switch def.Type {
+	case SettingTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be true or false", ErrInvalidSettingValue, key)
+		}
+		value = strconv.FormatBool(b)
+	case SettingTypeCron:
+		if err := ValidateCronSchedule(value); err != nil {
+			return fmt.Errorf("%w: invalid cron schedule: %v", ErrInvalidSettingValue, err)
+		}
+	case SettingTypeChoice:
+		if !slices.Contains(def.Choices, value) {
+			return fmt.Errorf("%w: %s must be one of %s", ErrInvalidSettingValue, key, strings.Join(def.Choices, ", "))
+		}
+	case SettingTypeTimezone:
+		if value == "" || localtime.ValidateTimezone(value) != nil {
+			return fmt.Errorf("%w: %s must be an IANA timezone such as Europe/Berlin", ErrInvalidSettingValue, key)
+		}
+	case SettingTypeLocale:
+		if value == "" || localtime.ValidateLocale(value) != nil {
+			return fmt.Errorf("%w: %s must be a language tag such as en-GB", ErrInvalidSettingValue, key)
+		}
+	case SettingTypeURLTemplate:
+		if value == "" {
+			return fmt.Errorf("%w: %s must not be empty; set it to %s to hide the link", ErrInvalidSettingValue, key, deepLinkDisabled)
+		}
+		if err := deeplink.Validate(value); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSettingValue, err)
+		}
+	default:
+		if value == "" {
+			return fmt.Errorf("%w: %s must not be empty; reset it to use the default", ErrInvalidSettingValue, key)
+		}
+	}
+
+	return s.db.SetSetting(key, value)
+}
+
+// ResetSetting removes the database override, reverting to env/default
+func (s *SettingsStore) ResetSetting(key string) error {
+	if _, err := findDefinition(key); err != nil {
+		return err
+	}
+	return s.db.DeleteSetting(key)
+}
+
+// GetMoonReaderDropboxPath returns the Dropbox folder with Moon+ Reader backups
+func (s *SettingsStore) GetMoonReaderDropboxPath() string {
+	return s.stringSetting(entities.SettingKeyMoonReaderDropboxPath)
+}
+
+// GetMoonReaderDatabasePath returns the local Moon+ Reader notes database path
+func (s *SettingsStore) GetMoonReaderDatabasePath() string {
+	return s.stringSetting(entities.SettingKeyMoonReaderDatabasePath)
+}
+
+// GetMoonReaderOutputDir returns the markdown directory for Moon+ Reader imports
+func (s *SettingsStore) GetMoonReaderOutputDir() string {
+	return s.stringSetting(entities.SettingKeyMoonReaderOutputDir)
+}
+
+// GetExportFilenameStyle returns how book titles become exported file names
+func (s *SettingsStore) GetExportFilenameStyle() string {
+	return s.stringSetting(entities.SettingKeyExportFilenameStyle)
+}
+
+// GetMoonReaderOutputFormat returns the export format for Moon+ Reader imports
+func (s *SettingsStore) GetMoonReaderOutputFormat() string {
+	return s.stringSetting(entities.SettingKeyMoonReaderOutputFormat)
+}
+
+// GetMetadataAutoEnrich returns whether books are enriched after imports
+func (s *SettingsStore) GetMetadataAutoEnrich() bool {
+	return s.stringSetting(entities.SettingKeyMetadataAutoEnrich) == "true"
+}
+
+// GetVocabularyAutoExtract returns whether vocabulary is suggested after imports
+func (s *SettingsStore) GetVocabularyAutoExtract() bool {
+	return s.stringSetting(entities.SettingKeyVocabularyAutoExtract) == "true"
+}
+
+// deepLinkSettings maps source names to the settings of their deep link templates
+var deepLinkSettings = map[string]string{
+	"kindle":      entities.SettingKeyDeepLinkKindle,
+	"apple_books": entities.SettingKeyDeepLinkAppleBooks,
+	"moonreader":  entities.SettingKeyDeepLinkMoonReader,
+}
+
+// GetDeepLinkTemplate returns the deep link template of a source, "" when
+// the source has none or its links are turned off
+func (s *SettingsStore) GetDeepLinkTemplate(sourceName string) string {
+	key, ok := deepLinkSettings[sourceName]
+	if !ok {
+		return ""
+	}
+	template := s.stringSetting(key)
+	if template == deepLinkDisabled {
+		return ""
+	}
+	return template
+}
+
+// GetDictionaryProvider returns the dictionary provider name
+func (s *SettingsStore) GetDictionaryProvider() string {
+	return s.stringSetting(entities.SettingKeyDictionaryProvider)
+}
+
+// dictionaryProviderSettings maps dictionary providers to the settings turning them on or off
+var dictionaryProviderSettings = map[string]string{
+	dictionary.ProviderFreeDictionary: entities.SettingKeyDictionaryFreeDictionaryEnabled,
+}
+
+// GetDictionaryProviderEnabled returns whether lookups through the dictionary
+// provider are allowed. Providers without a setting are always enabled
+func (s *SettingsStore) GetDictionaryProviderEnabled(provider string) bool {
+	key, ok := dictionaryProviderSettings[provider]
+	if !ok {
+		return true
+	}
+	return s.stringSetting(key) == "true"
+}
+
+// GetPublicLibraryEnabled returns whether the public library is shown
+func (s *SettingsStore) GetPublicLibraryEnabled() bool {
+	return s.stringSetting(entities.SettingKeyPublicLibraryEnabled) == "true"
+}
+
+// GetPublicLibraryTitle returns the heading of the public library pages
+func (s *SettingsStore) GetPublicLibraryTitle() string {
+	return s.stringSetting(entities.SettingKeyPublicLibraryTitle)
+}
+
+// GetPublicLibraryFilter returns which books the public library lists besides
+// the ones shared one by one
+func (s *SettingsStore) GetPublicLibraryFilter() entities.PublicLibraryFilter {
+	filter := entities.PublicLibraryFilter{
+		Favourites: s.stringSetting(entities.SettingKeyPublicLibraryFavourites) == "true",
+	}
+	for _, tag := range strings.Split(s.stringSetting(entities.SettingKeyPublicLibraryTags), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	return filter
+}
+
+// GetDefaultPreferences returns the timezone and locale of users without their own
+func (s *SettingsStore) GetDefaultPreferences() localtime.Preferences {
+	return localtime.New(
+		s.stringSetting(entities.SettingKeyDefaultTimezone),
+		s.stringSetting(entities.SettingKeyDefaultLocale),
+	)
+}
+
+// GetUserPreferences returns the user's timezone and locale, each falling back
+// to the default when the user has not set it. User 0, used when
+// authentication is disabled, always gets the defaults.
+func (s *SettingsStore) GetUserPreferences(userID uint) localtime.Preferences {
+	prefs := s.GetDefaultPreferences()
+	if userID == 0 {
+		return prefs
+	}
+	user, err := s.db.GetUserByID(userID)
+	if err != nil {
+		return prefs
+	}
+	if loc, err := localtime.LoadLocation(user.Timezone); user.Timezone != "" && err == nil {
+		prefs.Location = loc
+	}
+	if user.Locale != "" && localtime.ValidateLocale(user.Locale) == nil {
+		prefs.Locale = user.Locale
+	}
+	return prefs
+}
+
+// GetDefaultLanguage returns the UI language of users without their own, or ""
+// to follow the browser
+func (s *SettingsStore) GetDefaultLanguage() string {
+	language := s.stringSetting(entities.SettingKeyDefaultLanguage)
+	if !i18n.Supported(language) {
+		return ""
+	}
+	return language
+}
+
+// GetUserLanguage returns the user's UI language, falling back to the
+// default. An empty result means the browser's language is used.
+func (s *SettingsStore) GetUserLanguage(userID uint) string {
+	if userID != 0 {
+		if user, err := s.db.GetUserByID(userID); err == nil && i18n.Supported(user.Language) {
+			return user.Language
+		}
+	}
+	return s.GetDefaultLanguage()
+}
+
+func (s *SettingsStore) stringSetting(key string) string {
+	value, err := s.GetSettingValue(key)
+	if err != nil {
+		return ""
+	}
+	return value.Value
+}
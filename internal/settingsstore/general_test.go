@@ -0,0 +1,182 @@
+package settingsstore
+
+import (
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSettings(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db)
+
+	values := store.ListSettings()
+	require.Len(t, values, len(SettingDefinitions))
+	for _, value := range values {
+		assert.NotEmpty(t, value.Group, value.Key)
+		assert.NotEmpty(t, value.Label, value.Key)
+	}
+}
+
+func TestSettingValuePriority(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db)
+
+	key := entities.SettingKeyMoonReaderOutputDir
+
+	value, err := store.GetSettingValue(key)
+	require.NoError(t, err)
+	assert.Equal(t, "./markdown", value.Value)
+	assert.Equal(t, "default", value.Source)
+
+	t.Setenv("MOONREADER_OUTPUT_DIR", "/env/markdown")
+	value, err = store.GetSettingValue(key)
+	require.NoError(t, err)
+	assert.Equal(t, "/env/markdown", value.Value)
+	assert.Equal(t, "environment", value.Source)
+
+	require.NoError(t, store.UpdateSetting(key, " /db/markdown "))
+	assert.Equal(t, "/db/markdown", store.GetMoonReaderOutputDir())
+
+	require.NoError(t, store.ResetSetting(key))
+	assert.Equal(t, "/env/markdown", store.GetMoonReaderOutputDir())
+}
+
+func TestSettingValueConfigFallback(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db).WithConfigValues(map[string]string{
+		entities.SettingKeyVocabularyAutoExtract: "true",
+		entities.SettingKeyMoonReaderOutputDir:   "./markdown",
+	})
+
+	value, err := store.GetSettingValue(entities.SettingKeyVocabularyAutoExtract)
+	require.NoError(t, err)
+	assert.Equal(t, "config", value.Source)
+	assert.True(t, store.GetVocabularyAutoExtract())
+
+	t.Setenv("VOCABULARY_AUTO_EXTRACT", "false")
+	assert.False(t, store.GetVocabularyAutoExtract(), "the environment takes precedence")
+
+	require.NoError(t, store.UpdateSetting(entities.SettingKeyVocabularyAutoExtract, "true"))
+	assert.True(t, store.GetVocabularyAutoExtract(), "saved values take precedence")
+
+	value, err = store.GetSettingValue(entities.SettingKeyMoonReaderOutputDir)
+	require.NoError(t, err)
+	assert.Equal(t, "default", value.Source, "configured defaults are reported as defaults")
+}
+
+func TestUpdateSettingValidation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db)
+
+	t.Run("bool", func(t *testing.T) {
+		assert.ErrorIs(t, store.UpdateSetting(entities.SettingKeyMetadataAutoEnrich, "sometimes"), ErrInvalidSettingValue)
+		require.NoError(t, store.UpdateSetting(entities.SettingKeyMetadataAutoEnrich, "1"))
+		assert.True(t, store.GetMetadataAutoEnrich())
+	})
+
+	t.Run("cron", func(t *testing.T) {
+		assert.ErrorIs(t, store.UpdateSetting(entities.SettingKeyObsidianSyncSchedule, "every hour"), ErrInvalidSettingValue)
+		require.NoError(t, store.UpdateSetting(entities.SettingKeyObsidianSyncSchedule, "*/30 * * * *"))
+		assert.Equal(t, "*/30 * * * *", store.GetObsidianSyncSchedule())
+	})
+
+	t.Run("choice", func(t *testing.T) {
+		assert.ErrorIs(t, store.UpdateSetting(entities.SettingKeyDictionaryProvider, "oxford"), ErrInvalidSettingValue)
+		assert.Equal(t, "freedictionary", store.GetDictionaryProvider())
+	})
+
+	t.Run("empty string", func(t *testing.T) {
+		assert.ErrorIs(t, store.UpdateSetting(entities.SettingKeyMoonReaderDropboxPath, "  "), ErrInvalidSettingValue)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		assert.ErrorIs(t, store.UpdateSetting("readwise_sync_token", "secret"), ErrUnknownSetting)
+		assert.ErrorIs(t, store.ResetSetting("nope"), ErrUnknownSetting)
+		_, err := store.GetSettingValue("nope")
+		assert.ErrorIs(t, err, ErrUnknownSetting)
+	})
+}
+
+func TestBoolSettingFromEnv(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db)
+
+	assert.False(t, store.GetVocabularyAutoExtract())
+	t.Setenv("VOCABULARY_AUTO_EXTRACT", "1")
+	assert.True(t, store.GetVocabularyAutoExtract())
+}
+
+func TestGetDictionaryProviderEnabled(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db)
+
+	assert.True(t, store.GetDictionaryProviderEnabled("freedictionary"))
+	require.NoError(t, store.UpdateSetting(entities.SettingKeyDictionaryFreeDictionaryEnabled, "false"))
+	assert.False(t, store.GetDictionaryProviderEnabled("freedictionary"))
+
+	assert.True(t, store.GetDictionaryProviderEnabled("unknown"))
+}
+
+func TestGetPublicLibraryFilter(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db)
+
+	assert.Equal(t, entities.PublicLibraryFilter{}, store.GetPublicLibraryFilter())
+
+	require.NoError(t, store.UpdateSetting(entities.SettingKeyPublicLibraryFavourites, "true"))
+	require.NoError(t, store.UpdateSetting(entities.SettingKeyPublicLibraryTags, "stoicism, , essays"))
+	assert.Equal(t, entities.PublicLibraryFilter{Favourites: true, Tags: []string{"stoicism", "essays"}}, store.GetPublicLibraryFilter())
+}
+
+func TestGetUserPreferences(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db)
+
+	assert.ErrorIs(t, store.UpdateSetting(entities.SettingKeyDefaultTimezone, "Mars/Olympus"), ErrInvalidSettingValue)
+	assert.ErrorIs(t, store.UpdateSetting(entities.SettingKeyDefaultLocale, "not a locale"), ErrInvalidSettingValue)
+	require.NoError(t, store.UpdateSetting(entities.SettingKeyDefaultTimezone, "Europe/Berlin"))
+	require.NoError(t, store.UpdateSetting(entities.SettingKeyDefaultLocale, "de-DE"))
+
+	defaults := store.GetUserPreferences(0)
+	assert.Equal(t, "Europe/Berlin", defaults.Location.String())
+	assert.Equal(t, "de-DE", defaults.Locale)
+
+	user := &entities.User{Username: "reader", Email: "reader@example.com", PasswordHash: "x", Timezone: "Asia/Tokyo"}
+	require.NoError(t, db.DB.Create(user).Error)
+
+	prefs := store.GetUserPreferences(user.ID)
+	assert.Equal(t, "Asia/Tokyo", prefs.Location.String())
+	assert.Equal(t, "de-DE", prefs.Locale, "the default locale is used when the user has none")
+}
+
+func TestGetUserLanguage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db)
+
+	user := &entities.User{Username: "reader", Email: "reader@example.com", PasswordHash: "x"}
+	require.NoError(t, db.DB.Create(user).Error)
+
+	// Follows the browser by default
+	assert.Equal(t, "", store.GetUserLanguage(0))
+	assert.Equal(t, "", store.GetUserLanguage(user.ID))
+
+	assert.ErrorIs(t, store.UpdateSetting(entities.SettingKeyDefaultLanguage, "fr"), ErrInvalidSettingValue)
+	require.NoError(t, store.UpdateSetting(entities.SettingKeyDefaultLanguage, "de"))
+	assert.Equal(t, "de", store.GetUserLanguage(0))
+	assert.Equal(t, "de", store.GetUserLanguage(user.ID))
+
+	require.NoError(t, db.DB.Model(user).Update("language", "ru").Error)
+	assert.Equal(t, "ru", store.GetUserLanguage(user.ID))
+}
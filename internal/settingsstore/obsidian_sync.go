@@ -15,6 +15,8 @@ type ObsidianSyncConfig struct {
 	Enabled   bool   `json:"enabled"`
 	ExportDir string `json:"export_dir"`
 	Schedule  string `json:"schedule"`
+	Format    string `json:"format"`
+	TwoWay    bool   `json:"two_way"` // Sync notes edited in the exported files back
 }
 
 // ObsidianSyncConfigInfo includes source information for each field
@@ -129,6 +131,16 @@ func (s *SettingsStore) GetObsidianSyncSchedule() string {
 	return "0 * * * *"
 }
 
+// GetObsidianSyncFormat returns the export file format (database > env > default)
+func (s *SettingsStore) GetObsidianSyncFormat() string {
+	return s.stringSetting(entities.SettingKeyObsidianSyncFormat)
+}
+
+// GetObsidianSyncTwoWay returns whether notes edited in the vault are synced back
+func (s *SettingsStore) GetObsidianSyncTwoWay() bool {
+	return s.stringSetting(entities.SettingKeyObsidianSyncTwoWay) == "true"
+}
+
 // GetObsidianSyncScheduleSource returns the source of the schedule setting
 func (s *SettingsStore) GetObsidianSyncScheduleSource() string {
 	setting, err := s.db.GetSetting(entities.SettingKeyObsidianSyncSchedule)
@@ -152,6 +164,8 @@ func (s *SettingsStore) GetObsidianSyncConfig() ObsidianSyncConfig {
 		Enabled:   s.GetObsidianSyncEnabled(),
 		ExportDir: s.GetObsidianSyncExportDir(),
 		Schedule:  s.GetObsidianSyncSchedule(),
+		Format:    s.GetObsidianSyncFormat(),
+		TwoWay:    s.GetObsidianSyncTwoWay(),
 	}
 }
 
@@ -0,0 +1,299 @@
+package settingsstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// ProfileVersion is the version of the profile format written by ExportProfile
+const ProfileVersion = 1
+
+// Profile file formats
+const (
+	ProfileFormatJSON = "json"
+	ProfileFormatYAML = "yaml"
+)
+
+// ProfileFormats lists the formats a profile can be written in
+var ProfileFormats = []string{ProfileFormatYAML, ProfileFormatJSON}
+
+// ErrInvalidProfile is returned when a profile cannot be read
+var ErrInvalidProfile = errors.New("invalid settings profile")
+
+// integrationProfileKeys are the non-secret settings of integrations with
+// their own pages, copied with a profile besides SettingDefinitions.
+// Sync status and resume state are specific to an instance and left out.
+var integrationProfileKeys = []string{
+	entities.SettingKeyPlausibleEnabled,
+	entities.SettingKeyPlausibleDomain,
+	entities.SettingKeyPlausibleScriptURL,
+	entities.SettingKeyPlausibleExtensions,
+	entities.SettingKeyReadwiseSyncEnabled,
+	entities.SettingKeyReadwiseSyncSchedule,
+	entities.SettingKeyTelegramEnabled,
+	entities.SettingKeyTelegramChatID,
+}
+
+// Profile is a portable copy of an instance's configuration, used to set up
+// a new instance or keep a test instance in line with production. Secrets
+// such as API tokens are never included.
+type Profile struct {
+	Version    int                `json:"version" yaml:"version"`
+	ExportedAt time.Time          `json:"exported_at" yaml:"exported_at"`
+	Settings   map[string]string  `json:"settings" yaml:"settings"` // Only values saved in the database; env and defaults stay with each instance
+	Tags       []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Sources    []ProfileSource    `json:"sources,omitempty" yaml:"sources,omitempty"`
+	Tombstones []ProfileTombstone `json:"tombstones,omitempty" yaml:"tombstones,omitempty"`
+}
+
+// ProfileSource is a highlight source of a profile
+type ProfileSource struct {
+	Name        string `json:"name" yaml:"name"`
+	DisplayName string `json:"display_name" yaml:"display_name"`
+}
+
+// ProfileTombstone is a deletion record of a profile, which keeps the
+// deleted book or highlight from being imported again
+type ProfileTombstone struct {
+	EntityType  string    `json:"entity_type" yaml:"entity_type"`
+	EntityKey   string    `json:"entity_key" yaml:"entity_key"`
+	ContentHash string    `json:"content_hash,omitempty" yaml:"content_hash,omitempty"`
+	Source      string    `json:"source,omitempty" yaml:"source,omitempty"` // Source name, as IDs differ between instances
+	DeletedAt   time.Time `json:"deleted_at" yaml:"deleted_at"`
+}
+
+// ProfileOptions selects what a profile includes besides settings
+type ProfileOptions struct {
+	Tags       bool
+	Sources    bool
+	Tombstones bool
+}
+
+// ProfileImportResult counts what importing a profile changed
+type ProfileImportResult struct {
+	Settings   int      `json:"settings"`
+	Tags       int      `json:"tags"`
+	Sources    int      `json:"sources"`
+	Tombstones int      `json:"tombstones"`
+	Skipped    []string `json:"skipped,omitempty"` // Settings left out, with the reason
+}
+
+// isProfileSetting reports whether key is copied with a profile
+func isProfileSetting(key string) bool {
+	if IsSecretSetting(key) {
+		return false
+	}
+	if _, err := findDefinition(key); err == nil {
+		return true
+	}
+	return slices.Contains(integrationProfileKeys, key)
+}
+
+// ExportProfile returns the settings saved in the database and, as selected
+// by opts, the tags, sources and deletion records of userID.
+func (s *SettingsStore) ExportProfile(userID uint, opts ProfileOptions) (*Profile, error) {
+	profile := &Profile{Version: ProfileVersion, ExportedAt: time.Now().UTC(), Settings: make(map[string]string)}
+
+	settings, err := s.db.GetAllSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	for _, setting := range settings {
+		if setting.Value != "" && isProfileSetting(setting.Key) {
+			profile.Settings[setting.Key] = setting.Value
+		}
+	}
+
+	if opts.Tags {
+		tags, err := s.db.GetTagsForUser(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tags: %w", err)
+		}
+		for _, tag := range tags {
+			profile.Tags = append(profile.Tags, tag.Name)
+		}
+		sort.Strings(profile.Tags)
+	}
+
+	if !opts.Sources && !opts.Tombstones {
+		return profile, nil
+	}
+	sources, err := s.db.GetAllSources()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sources: %w", err)
+	}
+	if opts.Sources {
+		for _, source := range sources {
+			profile.Sources = append(profile.Sources, ProfileSource{Name: source.Name, DisplayName: source.DisplayName})
+		}
+	}
+
+	if opts.Tombstones {
+		sourceNames := make(map[uint]string, len(sources))
+		for _, source := range sources {
+			sourceNames[source.ID] = source.Name
+		}
+		tombstones, err := s.db.GetTombstones(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load deletion records: %w", err)
+		}
+		for _, tombstone := range tombstones {
+			profile.Tombstones = append(profile.Tombstones, ProfileTombstone{
+				EntityType:  tombstone.EntityType,
+				EntityKey:   tombstone.EntityKey,
+				ContentHash: tombstone.ContentHash,
+				Source:      sourceNames[tombstone.SourceID],
+				DeletedAt:   tombstone.DeletedAt,
+			})
+		}
+	}
+
+	return profile, nil
+}
+
+// ImportProfile applies a profile for userID. Settings are validated like
+// updates through the settings API; invalid and unknown ones are skipped.
+// Tags, sources and deletion records are added when missing, never removed.
+func (s *SettingsStore) ImportProfile(userID uint, profile *Profile) (*ProfileImportResult, error) {
+	result := &ProfileImportResult{}
+
+	keys := make([]string, 0, len(profile.Settings))
+	for key := range profile.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := profile.Settings[key]
+		switch {
+		case !isProfileSetting(key):
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: not a profile setting", key))
+			continue
+		case slices.Contains(integrationProfileKeys, key):
+			if err := s.db.SetSetting(key, value); err != nil {
+				return result, fmt.Errorf("failed to save %s: %w", key, err)
+			}
+		default:
+			err := s.UpdateSetting(key, value)
+			if errors.Is(err, ErrInvalidSettingValue) {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", key, err))
+				continue
+			}
+			if err != nil {
+				return result, fmt.Errorf("failed to save %s: %w", key, err)
+			}
+		}
+		result.Settings++
+	}
+
+	if len(profile.Tags) > 0 {
+		existing, err := s.db.GetTagsForUser(userID)
+		if err != nil {
+			return result, fmt.Errorf("failed to load tags: %w", err)
+		}
+		known := make(map[string]bool, len(existing))
+		for _, tag := range existing {
+			known[strings.ToLower(tag.Name)] = true
+		}
+		for _, name := range profile.Tags {
+			name = strings.TrimSpace(name)
+			if name == "" || known[strings.ToLower(name)] {
+				continue
+			}
+			if _, err := s.db.CreateTag(name, userID); err != nil {
+				return result, fmt.Errorf("failed to create tag %s: %w", name, err)
+			}
+			known[strings.ToLower(name)] = true
+			result.Tags++
+		}
+	}
+
+	for _, source := range profile.Sources {
+		if source.Name == "" {
+			continue
+		}
+		created, err := s.db.EnsureSource(source.Name, source.DisplayName)
+		if err != nil {
+			return result, fmt.Errorf("failed to create source %s: %w", source.Name, err)
+		}
+		if created {
+			result.Sources++
+		}
+	}
+
+	for _, tombstone := range profile.Tombstones {
+		if tombstone.EntityType == "" || tombstone.EntityKey == "" {
+			continue
+		}
+		record := &entities.DeletedEntity{
+			UserID:      userID,
+			EntityType:  tombstone.EntityType,
+			EntityKey:   tombstone.EntityKey,
+			ContentHash: tombstone.ContentHash,
+			DeletedAt:   tombstone.DeletedAt,
+		}
+		if tombstone.Source != "" {
+			if source, err := s.db.GetSourceByName(tombstone.Source); err == nil {
+				record.SourceID = source.ID
+			}
+		}
+		added, err := s.db.AddTombstone(record)
+		if err != nil {
+			return result, fmt.Errorf("failed to add deletion record: %w", err)
+		}
+		if added {
+			result.Tombstones++
+		}
+	}
+
+	return result, nil
+}
+
+// EncodeProfile writes a profile in format, YAML or JSON
+func EncodeProfile(w io.Writer, profile *Profile, format string) error {
+	switch format {
+	case ProfileFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(profile)
+	case ProfileFormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(profile); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unknown profile format %q (available: %s)", format, strings.Join(ProfileFormats, ", "))
+	}
+}
+
+// DecodeProfile reads a profile in format, YAML or JSON
+func DecodeProfile(r io.Reader, format string) (*Profile, error) {
+	var profile Profile
+	var err error
+	switch format {
+	case ProfileFormatJSON:
+		err = json.NewDecoder(r).Decode(&profile)
+	case ProfileFormatYAML:
+		err = yaml.NewDecoder(r).Decode(&profile)
+	default:
+		return nil, fmt.Errorf("unknown profile format %q (available: %s)", format, strings.Join(ProfileFormats, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+	}
+	if profile.Version > ProfileVersion {
+		return nil, fmt.Errorf("%w: version %d is newer than this server supports (%d)", ErrInvalidProfile, profile.Version, ProfileVersion)
+	}
+	return &profile, nil
+}
@@ -0,0 +1,86 @@
+package settingsstore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+func TestProfileRoundTrip(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db).WithEncryptor(newTestEncryptor(t))
+
+	require.NoError(t, store.UpdateSetting(entities.SettingKeyExportFilenameStyle, "slug"))
+	require.NoError(t, store.SetReadwiseSyncSchedule("0 6 * * *"))
+	require.NoError(t, store.SetReadwiseSyncToken("secret-token"))
+	require.NoError(t, db.SetSetting(entities.SettingKeyObsidianSyncLastStatus, "success"))
+	_, err := db.CreateTag("philosophy", 0)
+	require.NoError(t, err)
+	kindle, err := db.GetSourceByName("kindle")
+	require.NoError(t, err)
+	_, err = db.AddTombstone(&entities.DeletedEntity{EntityType: "book", EntityKey: "Dune|Frank Herbert", SourceID: kindle.ID})
+	require.NoError(t, err)
+
+	profile, err := store.ExportProfile(0, ProfileOptions{Tags: true, Sources: true, Tombstones: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		entities.SettingKeyExportFilenameStyle:  "slug",
+		entities.SettingKeyReadwiseSyncSchedule: "0 6 * * *",
+	}, profile.Settings, "secrets and sync status are left out")
+	assert.Equal(t, []string{"philosophy"}, profile.Tags)
+	assert.NotEmpty(t, profile.Sources)
+	require.Len(t, profile.Tombstones, 1)
+	assert.Equal(t, "kindle", profile.Tombstones[0].Source)
+
+	for _, format := range ProfileFormats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, EncodeProfile(&buf, profile, format))
+			decoded, err := DecodeProfile(&buf, format)
+			require.NoError(t, err)
+
+			target, targetCleanup := setupTestDB(t)
+			defer targetCleanup()
+			result, err := New(target).ImportProfile(0, decoded)
+			require.NoError(t, err)
+			assert.Equal(t, 2, result.Settings)
+			assert.Equal(t, 1, result.Tags)
+			assert.Zero(t, result.Sources, "default sources exist already")
+			assert.Equal(t, 1, result.Tombstones)
+			assert.Empty(t, result.Skipped)
+
+			assert.Equal(t, "slug", New(target).GetExportFilenameStyle())
+			deleted, err := target.IsBookDeleted("Dune", "Frank Herbert", 0)
+			require.NoError(t, err)
+			assert.True(t, deleted)
+
+			// Importing again changes nothing
+			result, err = New(target).ImportProfile(0, decoded)
+			require.NoError(t, err)
+			assert.Zero(t, result.Tags)
+			assert.Zero(t, result.Tombstones)
+		})
+	}
+}
+
+func TestImportProfile_SkipsInvalidSettings(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	result, err := New(db).ImportProfile(0, &Profile{Settings: map[string]string{
+		entities.SettingKeyObsidianSyncSchedule: "hourly",
+		entities.SettingKeyTelegramToken:        "secret",
+		"no_such_setting":                       "x",
+	}})
+	require.NoError(t, err)
+	assert.Zero(t, result.Settings)
+	assert.Len(t, result.Skipped, 3)
+
+	_, err = DecodeProfile(bytes.NewBufferString(`{"version": 99}`), ProfileFormatJSON)
+	assert.ErrorIs(t, err, ErrInvalidProfile)
+}
@@ -1,6 +1,7 @@
 package settingsstore
 
 import (
+	"encoding/json"
 	"os"
 	"strconv"
 	"time"
@@ -34,6 +35,15 @@ type ReadwiseSyncStatus struct {
 	Status           string     `json:"status,omitempty"`            // "success", "failed", "running", ""
 	Message          string     `json:"message,omitempty"`           // Error message or stats summary
 	HighlightsSynced int        `json:"highlights_synced,omitempty"` // Count from last sync
+	ResumePending    bool       `json:"resume_pending,omitempty"`    // An interrupted sync will resume on the next run
+}
+
+// ReadwiseSyncResumeState records the progress of an in-flight sync so an
+// interrupted export can continue from the last fetched page instead of restarting.
+type ReadwiseSyncResumeState struct {
+	Cursor       string     `json:"cursor"`                  // Next page cursor; empty to start from the first page
+	UpdatedAfter *time.Time `json:"updated_after,omitempty"` // Incremental boundary the export was started with
+	StartedAt    time.Time  `json:"started_at"`
 }
 
 // GetReadwiseSyncEnabled returns whether sync is enabled (database > env > default)
@@ -73,9 +83,8 @@ func (s *SettingsStore) SetReadwiseSyncEnabled(enabled bool) error {
 // GetReadwiseSyncToken returns the API token (database > env > "")
 func (s *SettingsStore) GetReadwiseSyncToken() string {
 	// Try database first
-	setting, err := s.db.GetSetting(entities.SettingKeyReadwiseSyncToken)
-	if err == nil && setting.Value != "" {
-		return setting.Value
+	if token := s.getSecret(entities.SettingKeyReadwiseSyncToken); token != "" {
+		return token
 	}
 
 	// Try environment variable
@@ -104,9 +113,9 @@ func (s *SettingsStore) HasReadwiseSyncToken() bool {
 	return s.GetReadwiseSyncToken() != ""
 }
 
-// SetReadwiseSyncToken saves the token to database
+// SetReadwiseSyncToken encrypts and saves the token to database
 func (s *SettingsStore) SetReadwiseSyncToken(token string) error {
-	return s.db.SetSetting(entities.SettingKeyReadwiseSyncToken, token)
+	return s.setSecret(entities.SettingKeyReadwiseSyncToken, token)
 }
 
 // GetReadwiseSyncSchedule returns the cron schedule (database > env > default)
@@ -196,16 +205,14 @@ func (s *SettingsStore) GetReadwiseSyncStatus() ReadwiseSyncStatus {
 		}
 	}
 
+	status.ResumePending = s.GetReadwiseSyncResumeState() != nil
+
 	return status
 }
 
-// SetReadwiseSyncStatus updates the sync status
+// SetReadwiseSyncStatus updates the outcome of the latest sync. It leaves the
+// incremental sync time alone, see SetReadwiseSyncLastAt.
 func (s *SettingsStore) SetReadwiseSyncStatus(status, message string, highlightsSynced int) error {
-	now := time.Now().UTC().Format(time.RFC3339)
-
-	if err := s.db.SetSetting(entities.SettingKeyReadwiseSyncLastAt, now); err != nil {
-		return err
-	}
 	if err := s.db.SetSetting(entities.SettingKeyReadwiseSyncLastStatus, status); err != nil {
 		return err
 	}
@@ -215,6 +222,12 @@ func (s *SettingsStore) SetReadwiseSyncStatus(status, message string, highlights
 	return s.db.SetSetting(entities.SettingKeyReadwiseSyncHighlightsSynced, strconv.Itoa(highlightsSynced))
 }
 
+// SetReadwiseSyncLastAt records when the last successful sync started; the next
+// sync only fetches highlights updated after it
+func (s *SettingsStore) SetReadwiseSyncLastAt(t time.Time) error {
+	return s.db.SetSetting(entities.SettingKeyReadwiseSyncLastAt, t.UTC().Format(time.RFC3339))
+}
+
 // GetReadwiseSyncLastAt returns the last successful sync timestamp (used for incremental sync)
 func (s *SettingsStore) GetReadwiseSyncLastAt() *time.Time {
 	setting, err := s.db.GetSetting(entities.SettingKeyReadwiseSyncLastAt)
@@ -228,6 +241,33 @@ func (s *SettingsStore) GetReadwiseSyncLastAt() *time.Time {
 	return &ts
 }
 
+// GetReadwiseSyncResumeState returns the saved progress of an interrupted sync, or nil if none
+func (s *SettingsStore) GetReadwiseSyncResumeState() *ReadwiseSyncResumeState {
+	setting, err := s.db.GetSetting(entities.SettingKeyReadwiseSyncResumeState)
+	if err != nil || setting.Value == "" {
+		return nil
+	}
+	var state ReadwiseSyncResumeState
+	if err := json.Unmarshal([]byte(setting.Value), &state); err != nil {
+		return nil
+	}
+	return &state
+}
+
+// SetReadwiseSyncResumeState saves the progress of the running sync
+func (s *SettingsStore) SetReadwiseSyncResumeState(state ReadwiseSyncResumeState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.db.SetSetting(entities.SettingKeyReadwiseSyncResumeState, string(data))
+}
+
+// ClearReadwiseSyncResumeState removes the saved progress once a sync completes
+func (s *SettingsStore) ClearReadwiseSyncResumeState() error {
+	return s.db.DeleteSetting(entities.SettingKeyReadwiseSyncResumeState)
+}
+
 // ClearReadwiseSyncSettings clears all database overrides, reverting to env/default
 func (s *SettingsStore) ClearReadwiseSyncSettings() error {
 	keys := []string{
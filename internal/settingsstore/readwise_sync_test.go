@@ -13,7 +13,7 @@ import (
 func TestReadwiseSyncEnabled(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
-	store := New(db)
+	store := New(db).WithEncryptor(newTestEncryptor(t))
 
 	// Default should be false
 	assert.False(t, store.GetReadwiseSyncEnabled())
@@ -37,7 +37,7 @@ func TestReadwiseSyncEnabled(t *testing.T) {
 func TestReadwiseSyncEnabledWithEnv(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
-	store := New(db)
+	store := New(db).WithEncryptor(newTestEncryptor(t))
 
 	// Set environment variable
 	os.Setenv("READWISE_SYNC_ENABLED", "true")
@@ -58,7 +58,7 @@ func TestReadwiseSyncEnabledWithEnv(t *testing.T) {
 func TestReadwiseSyncToken(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
-	store := New(db)
+	store := New(db).WithEncryptor(newTestEncryptor(t))
 
 	// Clear any existing env vars
 	originalToken := os.Getenv("READWISE_TOKEN")
@@ -86,7 +86,7 @@ func TestReadwiseSyncToken(t *testing.T) {
 func TestReadwiseSyncTokenWithEnv(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
-	store := New(db)
+	store := New(db).WithEncryptor(newTestEncryptor(t))
 
 	// Set environment variable
 	os.Setenv("READWISE_TOKEN", "env-token-abc")
@@ -108,7 +108,7 @@ func TestReadwiseSyncTokenWithEnv(t *testing.T) {
 func TestReadwiseSyncSchedule(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
-	store := New(db)
+	store := New(db).WithEncryptor(newTestEncryptor(t))
 
 	// Default should be every 6 hours
 	assert.Equal(t, "0 */6 * * *", store.GetReadwiseSyncSchedule())
@@ -125,7 +125,7 @@ func TestReadwiseSyncSchedule(t *testing.T) {
 func TestReadwiseSyncScheduleWithEnv(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
-	store := New(db)
+	store := New(db).WithEncryptor(newTestEncryptor(t))
 
 	// Set environment variable
 	os.Setenv("READWISE_SYNC_SCHEDULE", "0 0 * * *")
@@ -146,7 +146,7 @@ func TestReadwiseSyncScheduleWithEnv(t *testing.T) {
 func TestReadwiseSyncConfig(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
-	store := New(db)
+	store := New(db).WithEncryptor(newTestEncryptor(t))
 
 	// Set all values
 	require.NoError(t, store.SetReadwiseSyncEnabled(true))
@@ -172,7 +172,7 @@ func TestReadwiseSyncConfig(t *testing.T) {
 func TestReadwiseSyncStatus(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
-	store := New(db)
+	store := New(db).WithEncryptor(newTestEncryptor(t))
 
 	// Initially no status
 	status := store.GetReadwiseSyncStatus()
@@ -185,6 +185,8 @@ func TestReadwiseSyncStatus(t *testing.T) {
 	err := store.SetReadwiseSyncStatus("success", "Imported 5 books with 100 highlights", 100)
 	require.NoError(t, err)
 
+	require.NoError(t, store.SetReadwiseSyncLastAt(time.Now()))
+
 	status = store.GetReadwiseSyncStatus()
 	assert.NotNil(t, status.LastSyncAt)
 	assert.Equal(t, "success", status.Status)
@@ -193,6 +195,7 @@ func TestReadwiseSyncStatus(t *testing.T) {
 
 	// Verify timestamp is recent
 	assert.True(t, time.Since(*status.LastSyncAt) < time.Minute)
+	lastSyncAt := *status.LastSyncAt
 
 	// Set failed status
 	err = store.SetReadwiseSyncStatus("failed", "Invalid token", 0)
@@ -202,29 +205,63 @@ func TestReadwiseSyncStatus(t *testing.T) {
 	assert.Equal(t, "failed", status.Status)
 	assert.Equal(t, "Invalid token", status.Message)
 	assert.Zero(t, status.HighlightsSynced)
+
+	// A failure keeps the time of the last successful sync
+	require.NotNil(t, status.LastSyncAt)
+	assert.True(t, lastSyncAt.Equal(*status.LastSyncAt))
 }
 
 func TestReadwiseSyncLastAt(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
-	store := New(db)
+	store := New(db).WithEncryptor(newTestEncryptor(t))
 
 	// Initially nil
 	assert.Nil(t, store.GetReadwiseSyncLastAt())
 
-	// After setting status, should have a timestamp
-	err := store.SetReadwiseSyncStatus("success", "test", 50)
-	require.NoError(t, err)
+	// Setting the status alone does not advance the incremental sync time
+	require.NoError(t, store.SetReadwiseSyncStatus("failed", "test", 0))
+	assert.Nil(t, store.GetReadwiseSyncLastAt())
+
+	startedAt := time.Date(2024, 5, 1, 10, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	require.NoError(t, store.SetReadwiseSyncLastAt(startedAt))
 
 	lastAt := store.GetReadwiseSyncLastAt()
-	assert.NotNil(t, lastAt)
-	assert.True(t, time.Since(*lastAt) < time.Minute)
+	require.NotNil(t, lastAt)
+	assert.True(t, startedAt.Equal(*lastAt))
+}
+
+func TestReadwiseSyncResumeState(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db).WithEncryptor(newTestEncryptor(t))
+
+	// Initially nothing to resume
+	assert.Nil(t, store.GetReadwiseSyncResumeState())
+	assert.False(t, store.GetReadwiseSyncStatus().ResumePending)
+
+	since := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SetReadwiseSyncResumeState(ReadwiseSyncResumeState{
+		Cursor:       "page-3",
+		UpdatedAfter: &since,
+		StartedAt:    time.Now().UTC(),
+	}))
+
+	state := store.GetReadwiseSyncResumeState()
+	require.NotNil(t, state)
+	assert.Equal(t, "page-3", state.Cursor)
+	require.NotNil(t, state.UpdatedAfter)
+	assert.True(t, since.Equal(*state.UpdatedAfter))
+	assert.True(t, store.GetReadwiseSyncStatus().ResumePending)
+
+	require.NoError(t, store.ClearReadwiseSyncResumeState())
+	assert.Nil(t, store.GetReadwiseSyncResumeState())
 }
 
 func TestClearReadwiseSyncSettings(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
-	store := New(db)
+	store := New(db).WithEncryptor(newTestEncryptor(t))
 
 	// Clear any existing env vars
 	originalToken := os.Getenv("READWISE_TOKEN")
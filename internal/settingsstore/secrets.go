@@ -0,0 +1,87 @@
+package settingsstore
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// encryptedPrefix marks setting values encrypted with the token encryption key.
+// Values without it are plaintext rows written before encryption was enabled.
+const encryptedPrefix = "enc:v1:"
+
+// ErrNoEncryptor is returned when a secret is read or written without an encryptor
+var ErrNoEncryptor = errors.New("settings encryption is not configured")
+
+// SecretSettingKeys lists settings that are encrypted at rest. New API keys
+// and passwords should be added here and read with getSecret/setSecret.
+var SecretSettingKeys = []string{
+	entities.SettingKeyReadwiseSyncToken,
+	entities.SettingKeyTelegramToken,
+}
+
+// IsSecretSetting reports whether key is encrypted at rest
+func IsSecretSetting(key string) bool {
+	return slices.Contains(SecretSettingKeys, key)
+}
+
+// getSecret returns the decrypted value of a secret setting; "" if it is not
+// saved or cannot be decrypted (e.g. the key file changed)
+func (s *SettingsStore) getSecret(key string) string {
+	setting, err := s.db.GetSetting(key)
+	if err != nil || setting.Value == "" {
+		return ""
+	}
+
+	ciphertext, encrypted := strings.CutPrefix(setting.Value, encryptedPrefix)
+	if !encrypted {
+		return setting.Value
+	}
+	if s.encryptor == nil {
+		log.Printf("Settings: cannot read %s: %v", key, ErrNoEncryptor)
+		return ""
+	}
+	plaintext, err := s.encryptor.Decrypt(ciphertext)
+	if err != nil {
+		log.Printf("Settings: cannot decrypt %s (was the encryption key changed?): %v", key, err)
+		return ""
+	}
+	return plaintext
+}
+
+// setSecret encrypts and saves a secret setting
+func (s *SettingsStore) setSecret(key, value string) error {
+	if s.encryptor == nil {
+		return fmt.Errorf("save %s: %w", key, ErrNoEncryptor)
+	}
+	ciphertext, err := s.encryptor.Encrypt(value)
+	if err != nil {
+		return fmt.Errorf("encrypt %s: %w", key, err)
+	}
+	return s.db.SetSetting(key, encryptedPrefix+ciphertext)
+}
+
+// EncryptPlaintextSecrets encrypts secret settings that were saved as plaintext
+// before encryption was enabled. Returns the number of settings encrypted.
+func (s *SettingsStore) EncryptPlaintextSecrets() (int, error) {
+	if s.encryptor == nil {
+		return 0, ErrNoEncryptor
+	}
+
+	encrypted := 0
+	for _, key := range SecretSettingKeys {
+		setting, err := s.db.GetSetting(key)
+		if err != nil || setting.Value == "" || strings.HasPrefix(setting.Value, encryptedPrefix) {
+			continue
+		}
+		if err := s.setSecret(key, setting.Value); err != nil {
+			return encrypted, err
+		}
+		encrypted++
+	}
+	return encrypted, nil
+}
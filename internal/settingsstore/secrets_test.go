@@ -0,0 +1,82 @@
+package settingsstore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsEncryptedAtRest(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db).WithEncryptor(newTestEncryptor(t))
+
+	require.NoError(t, store.SetTelegramToken("123:secret"))
+
+	setting, err := db.GetSetting(entities.SettingKeyTelegramToken)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(setting.Value, encryptedPrefix))
+	assert.NotContains(t, setting.Value, "secret")
+
+	assert.Equal(t, "123:secret", store.GetTelegramToken())
+	assert.Equal(t, "database", store.GetTelegramTokenSource())
+}
+
+func TestSecretsRequireEncryptor(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	err := New(db).SetReadwiseSyncToken("secret")
+	assert.ErrorIs(t, err, ErrNoEncryptor)
+
+	// Encrypted values cannot be read without the key
+	require.NoError(t, New(db).WithEncryptor(newTestEncryptor(t)).SetReadwiseSyncToken("secret"))
+	assert.Empty(t, New(db).GetReadwiseSyncToken())
+}
+
+func TestSecretsWithDifferentKey(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, New(db).WithEncryptor(newTestEncryptor(t)).SetReadwiseSyncToken("secret"))
+	t.Setenv("READWISE_TOKEN", "from-env")
+
+	// An undecryptable value falls back to the environment
+	assert.Equal(t, "from-env", New(db).WithEncryptor(newTestEncryptor(t)).GetReadwiseSyncToken())
+}
+
+func TestEncryptPlaintextSecrets(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Rows saved before encryption was enabled
+	require.NoError(t, db.SetSetting(entities.SettingKeyReadwiseSyncToken, "legacy-readwise"))
+	require.NoError(t, db.SetSetting(entities.SettingKeyTelegramToken, "legacy-telegram"))
+
+	store := New(db)
+	assert.Equal(t, "legacy-readwise", store.GetReadwiseSyncToken(), "plaintext rows stay readable")
+
+	_, err := store.EncryptPlaintextSecrets()
+	assert.ErrorIs(t, err, ErrNoEncryptor)
+
+	store.WithEncryptor(newTestEncryptor(t))
+	count, err := store.EncryptPlaintextSecrets()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	setting, err := db.GetSetting(entities.SettingKeyReadwiseSyncToken)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(setting.Value, encryptedPrefix))
+	assert.Equal(t, "legacy-readwise", store.GetReadwiseSyncToken())
+	assert.Equal(t, "legacy-telegram", store.GetTelegramToken())
+
+	count, err = store.EncryptPlaintextSecrets()
+	require.NoError(t, err)
+	assert.Zero(t, count, "already encrypted values are left alone")
+
+	assert.True(t, IsSecretSetting(entities.SettingKeyTelegramToken))
+	assert.False(t, IsSecretSetting(entities.SettingKeyTelegramChatID))
+}
@@ -1,14 +1,34 @@
 package settingsstore
 
 import (
+	"github.com/mrlokans/assistant/internal/crypto"
 	"github.com/mrlokans/assistant/internal/database"
 )
 
 // Priority: database > environment > default
 type SettingsStore struct {
 	db *database.Database
+
+	// encryptor protects secret settings at rest (optional, see secrets.go)
+	encryptor *crypto.Encryptor
+
+	// configValues are settings from the loaded configuration (optional, see general.go)
+	configValues map[string]string
 }
 
 func New(db *database.Database) *SettingsStore {
 	return &SettingsStore{db: db}
 }
+
+// WithEncryptor enables encryption of secret settings
+func (s *SettingsStore) WithEncryptor(encryptor *crypto.Encryptor) *SettingsStore {
+	s.encryptor = encryptor
+	return s
+}
+
+// WithConfigValues sets values from the loaded configuration, by setting key,
+// used when a setting is neither saved nor set in the environment
+func (s *SettingsStore) WithConfigValues(values map[string]string) *SettingsStore {
+	s.configValues = values
+	return s
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/mrlokans/assistant/internal/crypto"
 	"github.com/mrlokans/assistant/internal/database"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,6 +24,15 @@ func setupTestDB(t *testing.T) (*database.Database, func()) {
 	return db, cleanup
 }
 
+func newTestEncryptor(t *testing.T) *crypto.Encryptor {
+	t.Helper()
+	key, err := crypto.GenerateKeyBytes()
+	require.NoError(t, err)
+	encryptor, err := crypto.NewEncryptor(key)
+	require.NoError(t, err)
+	return encryptor
+}
+
 func TestNew(t *testing.T) {
 	t.Run("creates settings store with database", func(t *testing.T) {
 		db, cleanup := setupTestDB(t)
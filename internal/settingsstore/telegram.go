@@ -0,0 +1,226 @@
+package settingsstore
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// TelegramConfig represents the effective configuration for the Telegram bot
+type TelegramConfig struct {
+	Enabled        bool   `json:"enabled"`
+	Token          string `json:"token"`
+	ChatID         int64  `json:"chat_id"`
+	ReviewSchedule string `json:"review_schedule"`
+}
+
+// TelegramConfigInfo includes source information for each field
+type TelegramConfigInfo struct {
+	Enabled       bool   `json:"enabled"`
+	EnabledSource string `json:"enabled_source"` // "database", "environment", "default"
+
+	Token       string `json:"token"` // Masked for display
+	TokenSource string `json:"token_source"`
+	HasToken    bool   `json:"has_token"`
+
+	ChatID       int64  `json:"chat_id"`
+	ChatIDSource string `json:"chat_id_source"`
+
+	ReviewSchedule       string `json:"review_schedule"`
+	ReviewScheduleSource string `json:"review_schedule_source"`
+}
+
+// GetTelegramEnabled returns whether the bot is enabled (database > env > default)
+func (s *SettingsStore) GetTelegramEnabled() bool {
+	setting, err := s.db.GetSetting(entities.SettingKeyTelegramEnabled)
+	if err == nil && setting.Value != "" {
+		return setting.Value == "true" || setting.Value == "1"
+	}
+
+	if envVal := os.Getenv("TELEGRAM_BOT_ENABLED"); envVal != "" {
+		return envVal == "true" || envVal == "1"
+	}
+
+	// Default: disabled
+	return false
+}
+
+// GetTelegramEnabledSource returns the source of the enabled setting
+func (s *SettingsStore) GetTelegramEnabledSource() string {
+	setting, err := s.db.GetSetting(entities.SettingKeyTelegramEnabled)
+	if err == nil && setting.Value != "" {
+		return "database"
+	}
+	if envVal := os.Getenv("TELEGRAM_BOT_ENABLED"); envVal != "" {
+		return "environment"
+	}
+	return "default"
+}
+
+// SetTelegramEnabled saves the enabled setting to database
+func (s *SettingsStore) SetTelegramEnabled(enabled bool) error {
+	return s.db.SetSetting(entities.SettingKeyTelegramEnabled, strconv.FormatBool(enabled))
+}
+
+// GetTelegramToken returns the bot token (database > env > "")
+func (s *SettingsStore) GetTelegramToken() string {
+	if token := s.getSecret(entities.SettingKeyTelegramToken); token != "" {
+		return token
+	}
+
+	if envVal := os.Getenv("TELEGRAM_BOT_TOKEN"); envVal != "" {
+		return envVal
+	}
+
+	return ""
+}
+
+// GetTelegramTokenSource returns the source of the token setting
+func (s *SettingsStore) GetTelegramTokenSource() string {
+	setting, err := s.db.GetSetting(entities.SettingKeyTelegramToken)
+	if err == nil && setting.Value != "" {
+		return "database"
+	}
+	if envVal := os.Getenv("TELEGRAM_BOT_TOKEN"); envVal != "" {
+		return "environment"
+	}
+	return "default"
+}
+
+// SetTelegramToken encrypts and saves the bot token to database
+func (s *SettingsStore) SetTelegramToken(token string) error {
+	return s.setSecret(entities.SettingKeyTelegramToken, token)
+}
+
+// GetTelegramChatID returns the chat the bot answers and sends reviews to
+// (database > env > 0). Zero means no chat is authorized yet.
+func (s *SettingsStore) GetTelegramChatID() int64 {
+	setting, err := s.db.GetSetting(entities.SettingKeyTelegramChatID)
+	if err == nil && setting.Value != "" {
+		if id, err := strconv.ParseInt(setting.Value, 10, 64); err == nil {
+			return id
+		}
+	}
+
+	if envVal := os.Getenv("TELEGRAM_CHAT_ID"); envVal != "" {
+		if id, err := strconv.ParseInt(envVal, 10, 64); err == nil {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// GetTelegramChatIDSource returns the source of the chat ID setting
+func (s *SettingsStore) GetTelegramChatIDSource() string {
+	setting, err := s.db.GetSetting(entities.SettingKeyTelegramChatID)
+	if err == nil && setting.Value != "" {
+		return "database"
+	}
+	if envVal := os.Getenv("TELEGRAM_CHAT_ID"); envVal != "" {
+		return "environment"
+	}
+	return "default"
+}
+
+// SetTelegramChatID saves the chat ID to database
+func (s *SettingsStore) SetTelegramChatID(chatID int64) error {
+	return s.db.SetSetting(entities.SettingKeyTelegramChatID, strconv.FormatInt(chatID, 10))
+}
+
+// GetTelegramReviewSchedule returns the daily review cron schedule (database > env > default)
+func (s *SettingsStore) GetTelegramReviewSchedule() string {
+	setting, err := s.db.GetSetting(entities.SettingKeyTelegramReviewSchedule)
+	if err == nil && setting.Value != "" {
+		return setting.Value
+	}
+
+	if envVal := os.Getenv("TELEGRAM_REVIEW_SCHEDULE"); envVal != "" {
+		return envVal
+	}
+
+	// Default: every morning at 9am
+	return "0 9 * * *"
+}
+
+// GetTelegramReviewScheduleSource returns the source of the review schedule setting
+func (s *SettingsStore) GetTelegramReviewScheduleSource() string {
+	setting, err := s.db.GetSetting(entities.SettingKeyTelegramReviewSchedule)
+	if err == nil && setting.Value != "" {
+		return "database"
+	}
+	if envVal := os.Getenv("TELEGRAM_REVIEW_SCHEDULE"); envVal != "" {
+		return "environment"
+	}
+	return "default"
+}
+
+// SetTelegramReviewSchedule saves the review schedule to database
+func (s *SettingsStore) SetTelegramReviewSchedule(schedule string) error {
+	return s.db.SetSetting(entities.SettingKeyTelegramReviewSchedule, schedule)
+}
+
+// GetTelegramUpdateOffset returns the ID of the next update to fetch, so
+// messages are not handled twice across restarts
+func (s *SettingsStore) GetTelegramUpdateOffset() int64 {
+	setting, err := s.db.GetSetting(entities.SettingKeyTelegramUpdateOffset)
+	if err != nil || setting.Value == "" {
+		return 0
+	}
+	offset, err := strconv.ParseInt(setting.Value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return offset
+}
+
+// SetTelegramUpdateOffset saves the ID of the next update to fetch
+func (s *SettingsStore) SetTelegramUpdateOffset(offset int64) error {
+	return s.db.SetSetting(entities.SettingKeyTelegramUpdateOffset, strconv.FormatInt(offset, 10))
+}
+
+// GetTelegramConfig returns the effective configuration
+func (s *SettingsStore) GetTelegramConfig() TelegramConfig {
+	return TelegramConfig{
+		Enabled:        s.GetTelegramEnabled(),
+		Token:          s.GetTelegramToken(),
+		ChatID:         s.GetTelegramChatID(),
+		ReviewSchedule: s.GetTelegramReviewSchedule(),
+	}
+}
+
+// GetTelegramConfigInfo returns the configuration with source information
+func (s *SettingsStore) GetTelegramConfigInfo() TelegramConfigInfo {
+	token := s.GetTelegramToken()
+
+	return TelegramConfigInfo{
+		Enabled:              s.GetTelegramEnabled(),
+		EnabledSource:        s.GetTelegramEnabledSource(),
+		Token:                maskToken(token),
+		TokenSource:          s.GetTelegramTokenSource(),
+		HasToken:             token != "",
+		ChatID:               s.GetTelegramChatID(),
+		ChatIDSource:         s.GetTelegramChatIDSource(),
+		ReviewSchedule:       s.GetTelegramReviewSchedule(),
+		ReviewScheduleSource: s.GetTelegramReviewScheduleSource(),
+	}
+}
+
+// ClearTelegramSettings clears all database overrides, reverting to env/default.
+// The update offset is kept so old messages are not handled again.
+func (s *SettingsStore) ClearTelegramSettings() error {
+	keys := []string{
+		entities.SettingKeyTelegramEnabled,
+		entities.SettingKeyTelegramToken,
+		entities.SettingKeyTelegramChatID,
+		entities.SettingKeyTelegramReviewSchedule,
+	}
+	for _, key := range keys {
+		if err := s.db.DeleteSetting(key); err != nil {
+			// Ignore not found errors
+			continue
+		}
+	}
+	return nil
+}
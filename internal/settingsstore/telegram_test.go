@@ -0,0 +1,44 @@
+package settingsstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramConfig(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := New(db).WithEncryptor(newTestEncryptor(t))
+
+	// Defaults
+	config := store.GetTelegramConfig()
+	assert.False(t, config.Enabled)
+	assert.Empty(t, config.Token)
+	assert.Zero(t, config.ChatID)
+	assert.Equal(t, "0 9 * * *", config.ReviewSchedule)
+
+	// Environment
+	t.Setenv("TELEGRAM_BOT_TOKEN", "123456:ABCDEFGHIJKL")
+	t.Setenv("TELEGRAM_CHAT_ID", "-100200300")
+	assert.Equal(t, int64(-100200300), store.GetTelegramChatID())
+	assert.Equal(t, "environment", store.GetTelegramChatIDSource())
+
+	// Database overrides environment
+	require.NoError(t, store.SetTelegramChatID(42))
+	require.NoError(t, store.SetTelegramEnabled(true))
+	info := store.GetTelegramConfigInfo()
+	assert.True(t, info.Enabled)
+	assert.Equal(t, int64(42), info.ChatID)
+	assert.Equal(t, "database", info.ChatIDSource)
+	assert.Equal(t, "1234****IJKL", info.Token)
+	assert.Equal(t, "environment", info.TokenSource)
+
+	// Reset keeps the update offset
+	require.NoError(t, store.SetTelegramUpdateOffset(77))
+	require.NoError(t, store.ClearTelegramSettings())
+	assert.False(t, store.GetTelegramEnabled())
+	assert.Equal(t, int64(-100200300), store.GetTelegramChatID())
+	assert.Equal(t, int64(77), store.GetTelegramUpdateOffset())
+}
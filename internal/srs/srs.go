@@ -0,0 +1,93 @@
+// Package srs schedules vocabulary reviews with the SM-2 spaced repetition
+// algorithm.
+//
+// Each item has an ease factor, the number of reviews in a row it was
+// recalled, and the interval in days until its next review. Recalling an item
+// grows its interval (1 day, 6 days, then the previous interval times the
+// ease); forgetting it starts over at 1 day. The grade also nudges the ease,
+// so items that are often hard come back sooner.
+//
+// # Usage
+//
+//	grade, ok := srs.ParseGrade("good")
+//	next := srs.Next(srs.State{Ease: word.ReviewEase, Interval: word.ReviewInterval, Repetitions: word.ReviewRepetitions}, grade)
+//	due := startOfToday.AddDate(0, 0, next.Interval)
+package srs
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Grade is how well an item was recalled, on SM-2's scale of 0 to 5.
+// Grades below GradeHard count as forgotten.
+type Grade int
+
+const (
+	GradeAgain Grade = 0 // Forgotten
+	GradeHard  Grade = 3 // Recalled with serious difficulty
+	GradeGood  Grade = 4 // Recalled after some hesitation
+	GradeEasy  Grade = 5 // Recalled easily
+)
+
+const (
+	// DefaultEase is the ease factor of an item that was never reviewed.
+	DefaultEase = 2.5
+	// MinEase is the lowest ease factor, so hard items still get longer intervals.
+	MinEase = 1.3
+)
+
+var gradeNames = map[string]Grade{
+	"again": GradeAgain,
+	"hard":  GradeHard,
+	"good":  GradeGood,
+	"easy":  GradeEasy,
+}
+
+// ParseGrade reads a grade given by name (again, hard, good, easy) or as a
+// number from 0 to 5.
+func ParseGrade(s string) (Grade, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if grade, ok := gradeNames[s]; ok {
+		return grade, true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 5 {
+		return 0, false
+	}
+	return Grade(n), true
+}
+
+// State is an item's review schedule.
+type State struct {
+	Ease        float64 // Ease factor; values below MinEase are taken as unset
+	Interval    int     // Days from the last review to the next one
+	Repetitions int     // Reviews in a row the item was recalled
+}
+
+// Next returns the schedule after a review with the grade.
+func Next(state State, grade Grade) State {
+	if state.Ease < MinEase {
+		state.Ease = DefaultEase
+	}
+
+	if grade < GradeHard {
+		state.Repetitions = 0
+		state.Interval = 1
+	} else {
+		switch state.Repetitions {
+		case 0:
+			state.Interval = 1
+		case 1:
+			state.Interval = 6
+		default:
+			state.Interval = int(math.Round(float64(max(state.Interval, 1)) * state.Ease))
+		}
+		state.Repetitions++
+	}
+
+	q := float64(5 - grade)
+	state.Ease = max(MinEase, state.Ease+0.1-q*(0.08+q*0.02))
+	return state
+}
@@ -0,0 +1,54 @@
+package srs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGrade(t *testing.T) {
+	for input, want := range map[string]Grade{"again": GradeAgain, " Good ": GradeGood, "easy": GradeEasy, "2": 2} {
+		grade, ok := ParseGrade(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, want, grade, input)
+	}
+	for _, input := range []string{"", "perfect", "6", "-1"} {
+		_, ok := ParseGrade(input)
+		assert.False(t, ok, input)
+	}
+}
+
+func TestNext(t *testing.T) {
+	t.Run("intervals grow with each recall", func(t *testing.T) {
+		state := State{}
+		var intervals []int
+		for range 4 {
+			state = Next(state, GradeGood)
+			intervals = append(intervals, state.Interval)
+		}
+		assert.Equal(t, []int{1, 6, 15, 38}, intervals)
+		assert.Equal(t, 4, state.Repetitions)
+		assert.InDelta(t, DefaultEase, state.Ease, 1e-9, "good keeps the ease")
+	})
+
+	t.Run("forgetting starts over and lowers the ease", func(t *testing.T) {
+		state := Next(State{Ease: 2.5, Interval: 15, Repetitions: 3}, GradeAgain)
+		assert.Equal(t, 1, state.Interval)
+		assert.Zero(t, state.Repetitions)
+		assert.InDelta(t, 1.7, state.Ease, 1e-9)
+	})
+
+	t.Run("easy raises the ease", func(t *testing.T) {
+		state := Next(State{Ease: 2.5, Interval: 6, Repetitions: 2}, GradeEasy)
+		assert.Equal(t, 15, state.Interval)
+		assert.InDelta(t, 2.6, state.Ease, 1e-9)
+	})
+
+	t.Run("ease never drops below the minimum", func(t *testing.T) {
+		state := State{Ease: MinEase}
+		for range 3 {
+			state = Next(state, GradeAgain)
+		}
+		assert.Equal(t, MinEase, state.Ease)
+	})
+}
@@ -2,10 +2,22 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"time"
 )
 
+// ErrNotFound is returned when a file does not exist in storage
+var ErrNotFound = errors.New("file not found")
+
+// IsNotFound reports whether err means a file does not exist in storage
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
 // FileInfo contains metadata about a file or directory in cloud storage
 type FileInfo struct {
 	Name        string
@@ -46,21 +58,27 @@ func DownloadToFile(ctx context.Context, client Client, remotePath, localPath st
 	}
 	defer reader.Close()
 
-	// Import os here to avoid circular dependencies
 	return writeToFile(localPath, reader)
 }
 
-// writeToFile writes reader content to a local file
+// writeToFile writes reader content to a local file, replacing it only once
+// the content is complete
 func writeToFile(path string, reader io.Reader) error {
-	// Use standard library to write file
-	// This avoids importing "os" at package level for better testability
-	var osCreate = func(name string) (io.WriteCloser, error) {
-		// We need to use a type assertion or direct os import
-		// For simplicity, we'll make this a separate helper
-		return nil, nil
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".download_")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath) // No-op after the rename
+
+	if _, err := io.Copy(tmpFile, reader); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
 	}
-	_ = osCreate // unused, will be implemented properly
-	return nil
+	return os.Rename(tmpPath, path)
 }
 
 // ListRecursive lists all files recursively from a path
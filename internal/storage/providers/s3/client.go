@@ -0,0 +1,355 @@
+// Package s3 implements storage.Client for Amazon S3 and S3-compatible object
+// stores such as MinIO, using the S3 REST API with Signature Version 4.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/storage"
+)
+
+// Backend is the STORAGE_BACKEND value that keeps files in S3.
+const Backend = "s3"
+
+// Config configures an S3 client
+type Config struct {
+	Endpoint        string // e.g. "https://s3.eu-central-1.amazonaws.com" or "http://minio:9000"
+	Region          string
+	Bucket          string
+	Prefix          string // Prepended to every key, e.g. "highlights/"
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool // Address the bucket in the URL path instead of the host name
+}
+
+// Client implements storage.Client for an S3 bucket. Paths are object keys
+// relative to the configured prefix; "directories" are key prefixes ending in
+// a slash.
+type Client struct {
+	cfg        Config
+	endpoint   *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewClient creates a new S3 storage client
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Prefix != "" {
+		cfg.Prefix = strings.Trim(cfg.Prefix, "/") + "/"
+	}
+
+	return &Client{
+		cfg:      cfg,
+		endpoint: endpoint,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+		now: time.Now,
+	}, nil
+}
+
+// FromConfig creates the client selected by cfg.Backend.
+// Returns nil without an error when files are kept on the local filesystem.
+func FromConfig(cfg config.Storage) (storage.Client, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case "", "local":
+		return nil, nil
+	case Backend:
+		if cfg.S3Bucket == "" {
+			return nil, fmt.Errorf("S3_BUCKET is required for the %s storage backend", Backend)
+		}
+		return NewClient(Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			Prefix:          cfg.S3Prefix,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// listBucketResult is the response of ListObjectsV2
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+		ETag         string    `xml:"ETag"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (c *Client) List(ctx context.Context, path string) ([]storage.FileInfo, error) {
+	prefix := c.key(path)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	var entries []storage.FileInfo
+	query := url.Values{}
+	query.Set("list-type", "2")
+	query.Set("prefix", prefix)
+	query.Set("delimiter", "/")
+
+	for {
+		resp, err := c.do(ctx, http.MethodGet, "", query, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse list response: %w", err)
+		}
+
+		for _, p := range result.CommonPrefixes {
+			entryPath := c.relative(strings.TrimSuffix(p.Prefix, "/"))
+			entries = append(entries, storage.FileInfo{
+				Name:  baseName(entryPath),
+				Path:  entryPath,
+				IsDir: true,
+			})
+		}
+		for _, obj := range result.Contents {
+			if obj.Key == prefix {
+				continue // Placeholder object of the "directory" itself
+			}
+			entryPath := c.relative(obj.Key)
+			entries = append(entries, storage.FileInfo{
+				Name:        baseName(entryPath),
+				Path:        entryPath,
+				Size:        obj.Size,
+				ModifiedAt:  obj.LastModified,
+				ID:          obj.Key,
+				ContentHash: strings.Trim(obj.ETag, `"`),
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return entries, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (c *Client) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.key(path), nil, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", path, err)
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) Upload(ctx context.Context, path string, content io.Reader) error {
+	// S3 needs the object size up front; anything but files and in-memory
+	// readers is buffered to learn it
+	size, ok := contentLength(content)
+	if !ok {
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return fmt.Errorf("failed to read content: %w", err)
+		}
+		content, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	resp, err := c.do(ctx, http.MethodPut, c.key(path), nil, content, size)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", path, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *Client) Delete(ctx context.Context, path string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.key(path), nil, nil, 0)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", path, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *Client) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := c.GetMetadata(ctx, path)
+	if err == nil {
+		return true, nil
+	}
+	if storage.IsNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (c *Client) GetMetadata(ctx context.Context, path string) (*storage.FileInfo, error) {
+	key := c.key(path)
+	resp, err := c.do(ctx, http.MethodHead, key, nil, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata of %s: %w", path, err)
+	}
+	resp.Body.Close()
+
+	info := &storage.FileInfo{
+		Name:        baseName(path),
+		Path:        c.relative(key),
+		Size:        resp.ContentLength,
+		ID:          key,
+		ContentHash: strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModifiedAt = modified
+	}
+	return info, nil
+}
+
+// do sends a signed request for an object key, or for the bucket when key is
+// empty. Responses other than 2xx are returned as errors; a missing object
+// wraps storage.ErrNotFound.
+func (c *Client) do(ctx context.Context, method, key string, query url.Values, body io.Reader, size int64) (*http.Response, error) {
+	target := *c.endpoint
+	bucketPath := "/" + c.cfg.Bucket
+	if !c.cfg.PathStyle {
+		target.Host = c.cfg.Bucket + "." + target.Host
+		bucketPath = ""
+	}
+	target.Path = c.endpoint.Path + bucketPath + "/" + key
+	target.RawPath = escapePath(c.endpoint.Path) + bucketPath + "/" + escapePath(key)
+	target.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	c.sign(req, body != nil)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var apiErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	_ = xml.Unmarshal(data, &apiErr)
+
+	if resp.StatusCode == http.StatusNotFound && apiErr.Code != "NoSuchBucket" {
+		return nil, storage.ErrNotFound
+	}
+	if apiErr.Code != "" {
+		return nil, fmt.Errorf("status %d: %s - %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+	}
+	return nil, fmt.Errorf("status %d", resp.StatusCode)
+}
+
+// key maps a storage path to an object key
+func (c *Client) key(path string) string {
+	return c.cfg.Prefix + strings.TrimPrefix(path, "/")
+}
+
+// relative maps an object key back to a storage path
+func (c *Client) relative(key string) string {
+	return strings.TrimPrefix(key, c.cfg.Prefix)
+}
+
+func baseName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// contentLength reports the remaining size of files and in-memory readers
+func contentLength(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		return info.Size() - offset, true
+	}
+	return 0, false
+}
+
+// escapePath URI-encodes each segment of a path as Signature Version 4 expects
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery encodes a query with sorted keys, as Signature Version 4 expects
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	var pairs []string
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, escape(key)+"="+escape(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// escape percent-encodes everything but unreserved characters (RFC 3986)
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ('A' <= ch && ch <= 'Z') || ('a' <= ch && ch <= 'z') || ('0' <= ch && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", ch)
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/storage"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(Config{
+		Endpoint:        server.URL,
+		Bucket:          "highlights",
+		Prefix:          "vault",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_ListPagination(t *testing.T) {
+	var tokens []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/highlights/", r.URL.Path)
+		assert.Equal(t, "vault/books/", r.URL.Query().Get("prefix"))
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+		tokens = append(tokens, r.URL.Query().Get("continuation-token"))
+
+		w.Header().Set("Content-Type", "application/xml")
+		if r.URL.Query().Get("continuation-token") == "" {
+			io.WriteString(w, `<ListBucketResult>
+  <Contents><Key>vault/books/</Key><Size>0</Size></Contents>
+  <Contents><Key>vault/books/Dune.md</Key><Size>120</Size><LastModified>2024-05-01T08:00:00.000Z</LastModified><ETag>"abc"</ETag></Contents>
+  <CommonPrefixes><Prefix>vault/books/archive/</Prefix></CommonPrefixes>
+  <IsTruncated>true</IsTruncated>
+  <NextContinuationToken>page 2/+</NextContinuationToken>
+</ListBucketResult>`)
+			return
+		}
+		io.WriteString(w, `<ListBucketResult>
+  <Contents><Key>vault/books/Meditations.md</Key><Size>80</Size><ETag>"def"</ETag></Contents>
+  <IsTruncated>false</IsTruncated>
+</ListBucketResult>`)
+	})
+
+	entries, err := client.List(context.Background(), "books")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "page 2/+"}, tokens, "the continuation token is sent back verbatim")
+	require.Len(t, entries, 3)
+	assert.Equal(t, storage.FileInfo{Name: "archive", Path: "books/archive", IsDir: true}, entries[0])
+	assert.Equal(t, "books/Dune.md", entries[1].Path)
+	assert.Equal(t, int64(120), entries[1].Size)
+	assert.Equal(t, "abc", entries[1].ContentHash)
+	assert.False(t, entries[1].ModifiedAt.IsZero())
+	assert.Equal(t, "Meditations.md", entries[2].Name)
+}
+
+func TestClient_NotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		if r.Method != http.MethodHead {
+			io.WriteString(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+		}
+	})
+
+	exists, err := client.Exists(context.Background(), "missing.md")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = client.Download(context.Background(), "missing.md")
+	assert.True(t, storage.IsNotFound(err), "got %v", err)
+}
+
+func TestClient_NoSuchBucket(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `<Error><Code>NoSuchBucket</Code><Message>The specified bucket does not exist</Message></Error>`)
+	})
+
+	_, err := client.Download(context.Background(), "notes.md")
+	require.Error(t, err)
+	assert.False(t, storage.IsNotFound(err), "a missing bucket is a configuration error, not a missing file")
+	assert.Contains(t, err.Error(), "NoSuchBucket")
+
+	_, err = client.List(context.Background(), "")
+	require.Error(t, err)
+	assert.False(t, storage.IsNotFound(err))
+}
+
+func TestClient_SpecialCharacterKeys(t *testing.T) {
+	var requestURI, path, body string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		path = r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	})
+
+	name := "books/Ça & co (2nd ed.)+notes~1.md"
+	require.NoError(t, client.Upload(context.Background(), name, strings.NewReader("# Notes")))
+
+	assert.Equal(t, "/highlights/vault/books/%C3%87a%20%26%20co%20%282nd%20ed.%29%2Bnotes~1.md", requestURI,
+		"every reserved character is percent-encoded, as the signature expects")
+	assert.Equal(t, "/highlights/vault/"+name, path)
+	assert.Equal(t, "# Notes", body)
+}
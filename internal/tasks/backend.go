@@ -0,0 +1,67 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mikestefanello/backlite"
+)
+
+// Backend stores queued tasks and runs them on workers. The Client adds
+// task events and retry defaults on top of it, so queues and tasks are
+// written once against backlite's types whatever the backend.
+type Backend interface {
+	// Register adds a queue. Called before Start.
+	Register(queue backlite.Queue)
+
+	// Start begins processing tasks without blocking.
+	Start(ctx context.Context)
+
+	// Stop waits for running tasks until ctx is done. Returns true if all finished.
+	Stop(ctx context.Context) bool
+
+	// Enqueue saves tasks to run once wait has passed and returns their IDs.
+	Enqueue(ctx context.Context, wait time.Duration, tasks ...backlite.Task) ([]string, error)
+
+	// Status returns the status of a task by ID.
+	Status(ctx context.Context, taskID string) (backlite.TaskStatus, error)
+
+	// Close releases the backend's connections. Called after Stop.
+	Close() error
+}
+
+// BackendFactory creates a backend from the task queue configuration.
+type BackendFactory func(mainDBPath string, cfg Config) (Backend, error)
+
+var backendFactories = map[string]BackendFactory{
+	BackendSQLite: newSQLiteBackend,
+}
+
+// Backends lists the backend names built into this binary, in the order they
+// were registered.
+var Backends = []string{BackendSQLite}
+
+// registerBackend makes an optional backend selectable by name. Backends
+// behind build tags call it from init.
+func registerBackend(name string, factory BackendFactory) {
+	backendFactories[name] = factory
+	Backends = append(Backends, name)
+}
+
+// ParseBackend normalizes a TASKS_BACKEND value and checks that the backend is
+// built in. An empty value selects the sqlite backend.
+func ParseBackend(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return BackendSQLite, nil
+	}
+	if _, ok := backendFactories[name]; ok {
+		return name, nil
+	}
+	if name == BackendAsynq {
+		return "", fmt.Errorf("task backend %q is not built in, rebuild with -tags asynq (available: %s)", name, strings.Join(Backends, ", "))
+	}
+	return "", fmt.Errorf("unknown task backend %q (available: %s)", name, strings.Join(Backends, ", "))
+}
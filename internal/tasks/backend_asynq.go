@@ -0,0 +1,184 @@
+//go:build asynq
+
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/mikestefanello/backlite"
+)
+
+func init() {
+	registerBackend(BackendAsynq, newAsynqBackend)
+}
+
+// asynqBackend keeps tasks in Redis and runs them with an asynq server. Each
+// backlite queue becomes an asynq queue and task type of the same name, so
+// several instances pointed at one Redis share the work.
+type asynqBackend struct {
+	redis     asynq.RedisConnOpt
+	client    *asynq.Client
+	inspector *asynq.Inspector
+	mux       *asynq.ServeMux
+	workers   int
+
+	mu      sync.Mutex
+	server  *asynq.Server
+	queues  []string
+	backoff map[string]time.Duration
+}
+
+func newAsynqBackend(_ string, cfg Config) (Backend, error) {
+	if cfg.RedisURL == "" {
+		return nil, errors.New("the asynq task backend needs TASKS_REDIS_URL")
+	}
+	redis, err := asynq.ParseRedisURI(cfg.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TASKS_REDIS_URL: %w", err)
+	}
+
+	return &asynqBackend{
+		redis:     redis,
+		client:    asynq.NewClient(redis),
+		inspector: asynq.NewInspector(redis),
+		mux:       asynq.NewServeMux(),
+		workers:   cfg.Workers,
+		backoff:   make(map[string]time.Duration),
+	}, nil
+}
+
+func (b *asynqBackend) Register(queue backlite.Queue) {
+	config := queue.Config()
+
+	b.mu.Lock()
+	b.queues = append(b.queues, config.Name)
+	b.backoff[config.Name] = config.Backoff
+	b.mu.Unlock()
+
+	b.mux.HandleFunc(config.Name, func(ctx context.Context, task *asynq.Task) error {
+		return queue.Process(ctx, task.Payload())
+	})
+}
+
+func (b *asynqBackend) Start(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	queues := make(map[string]int, len(b.queues))
+	for _, name := range b.queues {
+		queues[name] = 1
+	}
+	b.server = asynq.NewServer(b.redis, asynq.Config{
+		Concurrency:    b.workers,
+		Queues:         queues,
+		RetryDelayFunc: b.retryDelay,
+	})
+	if err := b.server.Start(b.mux); err != nil {
+		log.Printf("[TASK ERROR] failed to start the asynq server: %v", err)
+	}
+}
+
+// retryDelay waits the backoff of the task's queue between attempts, like backlite
+func (b *asynqBackend) retryDelay(n int, err error, task *asynq.Task) time.Duration {
+	b.mu.Lock()
+	backoff := b.backoff[task.Type()]
+	b.mu.Unlock()
+	if backoff > 0 {
+		return backoff
+	}
+	return asynq.DefaultRetryDelayFunc(n, err, task)
+}
+
+func (b *asynqBackend) Stop(ctx context.Context) bool {
+	b.mu.Lock()
+	server := b.server
+	b.mu.Unlock()
+	if server == nil {
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		server.Shutdown()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (b *asynqBackend) Enqueue(ctx context.Context, wait time.Duration, tasks ...backlite.Task) ([]string, error) {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		payload, err := json.Marshal(task)
+		if err != nil {
+			return ids, fmt.Errorf("failed to encode task: %w", err)
+		}
+
+		config := task.Config()
+		opts := []asynq.Option{asynq.Queue(config.Name)}
+		if config.MaxAttempts > 0 {
+			opts = append(opts, asynq.MaxRetry(config.MaxAttempts-1))
+		}
+		if config.Timeout > 0 {
+			opts = append(opts, asynq.Timeout(config.Timeout))
+		}
+		if config.Retention != nil && !config.Retention.OnlyFailed && config.Retention.Duration > 0 {
+			opts = append(opts, asynq.Retention(config.Retention.Duration))
+		}
+		if wait > 0 {
+			opts = append(opts, asynq.ProcessIn(wait))
+		}
+
+		info, err := b.client.EnqueueContext(ctx, asynq.NewTask(config.Name, payload), opts...)
+		if err != nil {
+			return ids, fmt.Errorf("failed to enqueue %s task: %w", config.Name, err)
+		}
+		ids = append(ids, info.ID)
+	}
+	return ids, nil
+}
+
+// Status looks the task up in every registered queue, since task IDs do not
+// carry the queue name.
+func (b *asynqBackend) Status(ctx context.Context, taskID string) (backlite.TaskStatus, error) {
+	b.mu.Lock()
+	queues := append([]string(nil), b.queues...)
+	b.mu.Unlock()
+
+	for _, queue := range queues {
+		info, err := b.inspector.GetTaskInfo(queue, taskID)
+		if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+			continue
+		}
+		if err != nil {
+			return backlite.TaskStatusNotFound, err
+		}
+		switch info.State {
+		case asynq.TaskStateActive:
+			return backlite.TaskStatusRunning, nil
+		case asynq.TaskStateCompleted:
+			return backlite.TaskStatusSuccess, nil
+		case asynq.TaskStateArchived:
+			// Archived tasks ran out of retries
+			return backlite.TaskStatusFailure, nil
+		default:
+			return backlite.TaskStatusPending, nil
+		}
+	}
+	return backlite.TaskStatusNotFound, nil
+}
+
+func (b *asynqBackend) Close() error {
+	return errors.Join(b.inspector.Close(), b.client.Close())
+}
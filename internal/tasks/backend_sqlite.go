@@ -0,0 +1,107 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/mikestefanello/backlite"
+)
+
+// sqliteBackend runs tasks with backlite on a dedicated SQLite database.
+// Pending tasks are picked up again after a restart.
+type sqliteBackend struct {
+	client *backlite.Client
+	db     *sql.DB
+}
+
+func newSQLiteBackend(mainDBPath string, cfg Config) (Backend, error) {
+	tasksDBPath := sqliteTasksPath(mainDBPath, cfg)
+
+	// Open dedicated SQLite connection for tasks with WAL mode
+	db, err := sql.Open("sqlite3", tasksDBPath+"?_journal=WAL&_timeout=5000&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tasks database: %w", err)
+	}
+
+	// Configure connection pool for concurrent workers
+	db.SetMaxOpenConns(cfg.Workers + 5)
+	db.SetMaxIdleConns(cfg.Workers + 2)
+	db.SetConnMaxLifetime(time.Hour)
+
+	// Create backlite client
+	client, err := backlite.NewClient(backlite.ClientConfig{
+		DB:              db,
+		NumWorkers:      cfg.Workers,
+		ReleaseAfter:    cfg.ReleaseAfter,
+		CleanupInterval: cfg.CleanupInterval,
+		Logger:          &stdLogger{},
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create backlite client: %w", err)
+	}
+
+	// Install schema
+	if err := client.Install(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to install backlite schema: %w", err)
+	}
+
+	return &sqliteBackend{client: client, db: db}, nil
+}
+
+// sqliteTasksPath returns where the sqlite backend keeps its tasks
+func sqliteTasksPath(mainDBPath string, cfg Config) string {
+	if cfg.DBPath != "" {
+		return cfg.DBPath
+	}
+	dir := filepath.Dir(mainDBPath)
+	base := filepath.Base(mainDBPath)
+	ext := filepath.Ext(base)
+	name := base[:len(base)-len(ext)]
+	return filepath.Join(dir, name+"-tasks"+ext)
+}
+
+func (b *sqliteBackend) Register(queue backlite.Queue) {
+	b.client.Register(queue)
+}
+
+func (b *sqliteBackend) Start(ctx context.Context) {
+	b.client.Start(ctx)
+}
+
+func (b *sqliteBackend) Stop(ctx context.Context) bool {
+	return b.client.Stop(ctx)
+}
+
+func (b *sqliteBackend) Enqueue(ctx context.Context, wait time.Duration, tasks ...backlite.Task) ([]string, error) {
+	op := b.client.Add(tasks...).Ctx(ctx)
+	if wait > 0 {
+		op = op.Wait(wait)
+	}
+	return op.Save()
+}
+
+func (b *sqliteBackend) Status(ctx context.Context, taskID string) (backlite.TaskStatus, error) {
+	return b.client.Status(ctx, taskID)
+}
+
+func (b *sqliteBackend) Close() error {
+	return b.db.Close()
+}
+
+// stdLogger implements backlite.Logger using standard library log.
+type stdLogger struct{}
+
+func (l *stdLogger) Info(message string, params ...any) {
+	log.Printf("[TASK] "+message, params...)
+}
+
+func (l *stdLogger) Error(message string, params ...any) {
+	log.Printf("[TASK ERROR] "+message, params...)
+}
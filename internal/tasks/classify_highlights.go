@@ -0,0 +1,119 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/mikestefanello/backlite"
+	"github.com/mrlokans/assistant/internal/classify"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// classifyBatchSize is the number of highlights classified per database round-trip.
+const classifyBatchSize = 200
+
+// HighlightClassifierStore defines the interface for highlight classification operations.
+type HighlightClassifierStore interface {
+	GetHighlightsAfterID(afterID uint, limit int) ([]entities.Highlight, error)
+	SetHighlightCategories(highlightID uint, categories []entities.HighlightCategory, classifier string) error
+	GetSetting(key string) (*entities.Setting, error)
+	SetSetting(key, value string) error
+}
+
+// ClassifyHighlightsTask sorts highlights added since the last run into coarse
+// categories such as advice or definitions. With Reclassify, every highlight
+// is classified again, e.g. after switching classifiers.
+type ClassifyHighlightsTask struct {
+	Reclassify bool `json:"reclassify,omitempty"`
+}
+
+func (t ClassifyHighlightsTask) Config() backlite.QueueConfig {
+	return backlite.QueueConfig{
+		Name:        "classify_highlights",
+		MaxAttempts: 1,
+		Backoff:     time.Minute,
+		Timeout:     30 * time.Minute,
+		Retention: &backlite.Retention{
+			Duration:   24 * time.Hour,
+			OnlyFailed: false,
+			Data:       &backlite.RetainData{OnlyFailed: true},
+		},
+	}
+}
+
+// ClassifyHighlightsProcessor creates a processor for highlight classification.
+// Progress is stored as the last classified highlight ID, so each highlight is
+// classified once. classifier may be nil when classification is not configured,
+// in which case tasks fail.
+func ClassifyHighlightsProcessor(store HighlightClassifierStore, classifier classify.Classifier) backlite.QueueProcessor[ClassifyHighlightsTask] {
+	return func(ctx context.Context, task ClassifyHighlightsTask) error {
+		if classifier == nil {
+			return errors.New("highlight classification is not configured (set CLASSIFIER_BACKEND)")
+		}
+
+		var lastID uint
+		if !task.Reclassify {
+			lastID = loadClassifyCursor(store)
+		}
+		var classified, categorized int
+
+		for {
+			highlights, err := store.GetHighlightsAfterID(lastID, classifyBatchSize)
+			if err != nil {
+				return fmt.Errorf("get highlights after %d: %w", lastID, err)
+			}
+			if len(highlights) == 0 {
+				break
+			}
+
+			for i := range highlights {
+				if ctx.Err() != nil {
+					log.Printf("[TASK] Context cancelled, classified %d highlights", classified)
+					return ctx.Err()
+				}
+
+				categories, err := classifier.Classify(ctx, highlights[i].Text)
+				if err != nil {
+					return fmt.Errorf("classify highlight %d: %w", highlights[i].ID, err)
+				}
+				if err := store.SetHighlightCategories(highlights[i].ID, categories, classifier.Name()); err != nil {
+					return fmt.Errorf("save categories of highlight %d: %w", highlights[i].ID, err)
+				}
+				if len(categories) > 0 {
+					categorized++
+				}
+				lastID = highlights[i].ID
+				classified++
+			}
+
+			if err := store.SetSetting(entities.SettingKeyClassifyLastHighlightID, strconv.FormatUint(uint64(lastID), 10)); err != nil {
+				return fmt.Errorf("save classification cursor: %w", err)
+			}
+		}
+
+		log.Printf("[TASK] Classified %d highlights, %d fit a category", classified, categorized)
+		return nil
+	}
+}
+
+// loadClassifyCursor returns the ID of the last classified highlight, or 0 if none was stored.
+func loadClassifyCursor(store HighlightClassifierStore) uint {
+	setting, err := store.GetSetting(entities.SettingKeyClassifyLastHighlightID)
+	if err != nil || setting.Value == "" {
+		return 0
+	}
+
+	lastID, err := strconv.ParseUint(setting.Value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(lastID)
+}
+
+func NewClassifyHighlightsQueue(store HighlightClassifierStore, classifier classify.Classifier) backlite.Queue {
+	return backlite.NewQueue(ClassifyHighlightsProcessor(store, classifier))
+}
@@ -0,0 +1,78 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/classify"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHighlightClassifierStore struct {
+	fakeVocabularyExtractor
+	categories map[uint][]entities.HighlightCategory
+}
+
+func (f *fakeHighlightClassifierStore) SetHighlightCategories(highlightID uint, categories []entities.HighlightCategory, classifier string) error {
+	f.categories[highlightID] = categories
+	return nil
+}
+
+func TestClassifyHighlightsProcessor(t *testing.T) {
+	store := &fakeHighlightClassifierStore{
+		fakeVocabularyExtractor: fakeVocabularyExtractor{
+			highlights: []entities.Highlight{
+				{ID: 1, Text: "Always carry a notebook."},
+				{ID: 2, Text: "The rain kept falling."},
+			},
+			settings: map[string]string{},
+		},
+		categories: map[uint][]entities.HighlightCategory{},
+	}
+	processor := ClassifyHighlightsProcessor(store, classify.NewRuleClassifier())
+
+	require.NoError(t, processor(context.Background(), ClassifyHighlightsTask{}))
+	assert.Equal(t, []entities.HighlightCategory{entities.HighlightCategoryAdvice}, store.categories[1])
+	assert.Contains(t, store.categories, uint(2))
+	assert.Empty(t, store.categories[2])
+	assert.Equal(t, "2", store.settings[entities.SettingKeyClassifyLastHighlightID])
+
+	// Only highlights added since the last run are classified
+	store.categories = map[uint][]entities.HighlightCategory{}
+	store.highlights = append(store.highlights, entities.Highlight{ID: 3, Text: "Inflation reached 9 percent."})
+	require.NoError(t, processor(context.Background(), ClassifyHighlightsTask{}))
+	assert.Len(t, store.categories, 1)
+	assert.Equal(t, []entities.HighlightCategory{entities.HighlightCategoryDataPoint}, store.categories[3])
+
+	// Reclassifying starts over
+	require.NoError(t, processor(context.Background(), ClassifyHighlightsTask{Reclassify: true}))
+	assert.Len(t, store.categories, 3)
+}
+
+type failingClassifier struct{}
+
+func (failingClassifier) Name() string { return "failing" }
+
+func (failingClassifier) Classify(context.Context, string) ([]entities.HighlightCategory, error) {
+	return nil, errors.New("service unavailable")
+}
+
+func TestClassifyHighlightsProcessor_Errors(t *testing.T) {
+	store := &fakeHighlightClassifierStore{
+		fakeVocabularyExtractor: fakeVocabularyExtractor{
+			highlights: []entities.Highlight{{ID: 1, Text: "Text"}},
+			settings:   map[string]string{},
+		},
+		categories: map[uint][]entities.HighlightCategory{},
+	}
+
+	assert.Error(t, ClassifyHighlightsProcessor(store, nil)(context.Background(), ClassifyHighlightsTask{}))
+
+	err := ClassifyHighlightsProcessor(store, failingClassifier{})(context.Background(), ClassifyHighlightsTask{})
+	assert.ErrorContains(t, err, "service unavailable")
+	// The cursor stays put so the highlight is tried again
+	assert.NotContains(t, store.settings, entities.SettingKeyClassifyLastHighlightID)
+}
@@ -2,80 +2,92 @@ package tasks
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
+	"encoding/json"
 	"log"
-	"path/filepath"
 	"sync"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/mikestefanello/backlite"
+	"github.com/mrlokans/assistant/internal/events"
 )
 
-// Client wraps backlite to provide task queue functionality.
+// Client runs task queues on the backend selected by Config.Backend.
 type Client struct {
-	client *backlite.Client
-	db     *sql.DB
-	config Config
+	backend Backend
+	config  Config
+	events  *events.Broker
 
 	mu      sync.RWMutex
 	started bool
 }
 
-// NewClient creates a new task queue client with a dedicated SQLite database.
-// The database is stored alongside the main database with a "-tasks" suffix.
+// NewClient creates a new task queue client with the backend selected by
+// cfg.Backend. The sqlite backend keeps tasks in a dedicated database at
+// cfg.DBPath, by default alongside the main database with a "-tasks" suffix.
 func NewClient(mainDBPath string, cfg Config) (*Client, error) {
-	// Create tasks database path alongside main DB
-	dir := filepath.Dir(mainDBPath)
-	base := filepath.Base(mainDBPath)
-	ext := filepath.Ext(base)
-	name := base[:len(base)-len(ext)]
-	tasksDBPath := filepath.Join(dir, name+"-tasks"+ext)
-
-	// Open dedicated SQLite connection for tasks with WAL mode
-	db, err := sql.Open("sqlite3", tasksDBPath+"?_journal=WAL&_timeout=5000&_busy_timeout=5000")
+	name, err := ParseBackend(cfg.Backend)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open tasks database: %w", err)
+		return nil, err
 	}
-
-	// Configure connection pool for concurrent workers
-	db.SetMaxOpenConns(cfg.Workers + 5)
-	db.SetMaxIdleConns(cfg.Workers + 2)
-	db.SetConnMaxLifetime(time.Hour)
-
-	// Create backlite client
-	client, err := backlite.NewClient(backlite.ClientConfig{
-		DB:              db,
-		NumWorkers:      cfg.Workers,
-		ReleaseAfter:    cfg.ReleaseAfter,
-		CleanupInterval: cfg.CleanupInterval,
-		Logger:          &stdLogger{},
-	})
+	backend, err := backendFactories[name](mainDBPath, cfg)
 	if err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to create backlite client: %w", err)
+		return nil, err
 	}
+	return NewClientWithBackend(backend, cfg), nil
+}
 
-	// Install schema
-	if err := client.Install(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to install backlite schema: %w", err)
+// NewClientWithBackend creates a client running on an already created backend.
+func NewClientWithBackend(backend Backend, cfg Config) *Client {
+	return &Client{
+		backend: backend,
+		config:  cfg,
 	}
+}
 
-	return &Client{
-		client: client,
-		db:     db,
-		config: cfg,
-	}, nil
+// SetEventBroker sets where finished tasks are announced.
+// Must be called before Register().
+func (c *Client) SetEventBroker(broker *events.Broker) {
+	c.events = broker
 }
 
 // Register registers task queues with the client.
 // Must be called before Start().
 func (c *Client) Register(queues ...backlite.Queue) {
 	for _, q := range queues {
-		c.client.Register(q)
+		if c.events != nil {
+			q = &observedQueue{Queue: q, events: c.events}
+		}
+		c.backend.Register(q)
+	}
+}
+
+// TaskEvent is the payload of task events.
+type TaskEvent struct {
+	Queue string          `json:"queue"`
+	Task  json.RawMessage `json:"task"`
+	Error string          `json:"error,omitempty"`
+}
+
+// observedQueue publishes the outcome of every task attempt of a queue
+type observedQueue struct {
+	backlite.Queue
+	events *events.Broker
+}
+
+func (q *observedQueue) Process(ctx context.Context, payload []byte) error {
+	err := q.Queue.Process(ctx, payload)
+
+	event := TaskEvent{Queue: q.Config().Name}
+	if json.Valid(payload) {
+		event.Task = payload
+	}
+	if err != nil {
+		event.Error = err.Error()
+		q.events.Publish(events.TypeTaskFailed, event)
+	} else {
+		q.events.Publish(events.TypeTaskCompleted, event)
 	}
+	return err
 }
 
 // Start begins processing tasks. This is non-blocking and should be called
@@ -90,7 +102,7 @@ func (c *Client) Start(ctx context.Context) {
 	c.mu.Unlock()
 
 	log.Printf("Task queue started with %d workers", c.config.Workers)
-	c.client.Start(ctx)
+	c.backend.Start(ctx)
 }
 
 // Stop gracefully shuts down the task queue, waiting for active tasks to complete.
@@ -104,7 +116,7 @@ func (c *Client) Stop(ctx context.Context) bool {
 	c.mu.RUnlock()
 
 	log.Println("Stopping task queue...")
-	success := c.client.Stop(ctx)
+	success := c.backend.Stop(ctx)
 	if success {
 		log.Println("Task queue stopped gracefully")
 	} else {
@@ -115,39 +127,56 @@ func (c *Client) Stop(ctx context.Context) bool {
 
 // Close releases all resources. Should be called after Stop().
 func (c *Client) Close() error {
-	if c.db != nil {
-		return c.db.Close()
-	}
-	return nil
+	return c.backend.Close()
 }
 
 // Add starts an operation to enqueue one or more tasks.
-func (c *Client) Add(tasks ...backlite.Task) *backlite.TaskAddOp {
-	return c.client.Add(tasks...)
+func (c *Client) Add(tasks ...backlite.Task) *TaskAddOp {
+	return &TaskAddOp{backend: c.backend, tasks: tasks, ctx: context.Background()}
+}
+
+// Schedule enqueues a task to be processed once wait has passed.
+func (c *Client) Schedule(task backlite.Task, wait time.Duration) error {
+	_, err := c.Add(task).Wait(wait).Save()
+	return err
+}
+
+// RetryPolicy returns the retry policy for the configured retries.
+func (c *Client) RetryPolicy() RetryPolicy {
+	return RetryPolicyFromConfig(c.config)
 }
 
 // Status returns the status of a task by ID.
 func (c *Client) Status(ctx context.Context, taskID string) (backlite.TaskStatus, error) {
-	return c.client.Status(ctx, taskID)
+	return c.backend.Status(ctx, taskID)
 }
 
-// DB returns the underlying database connection for use with backlite UI.
-func (c *Client) DB() *sql.DB {
-	return c.db
+// TaskAddOp enqueues tasks once Save is called.
+type TaskAddOp struct {
+	backend Backend
+	tasks   []backlite.Task
+	ctx     context.Context
+	wait    time.Duration
 }
 
-// Client returns the underlying backlite client for advanced operations.
-func (c *Client) Client() *backlite.Client {
-	return c.client
+// Ctx sets the context of the enqueue operation.
+func (op *TaskAddOp) Ctx(ctx context.Context) *TaskAddOp {
+	op.ctx = ctx
+	return op
 }
 
-// stdLogger implements backlite.Logger using standard library log.
-type stdLogger struct{}
+// Wait delays processing of the tasks until duration has passed.
+func (op *TaskAddOp) Wait(duration time.Duration) *TaskAddOp {
+	op.wait = duration
+	return op
+}
 
-func (l *stdLogger) Info(message string, params ...any) {
-	log.Printf("[TASK] "+message, params...)
+// At delays processing of the tasks until processAt.
+func (op *TaskAddOp) At(processAt time.Time) *TaskAddOp {
+	return op.Wait(time.Until(processAt))
 }
 
-func (l *stdLogger) Error(message string, params ...any) {
-	log.Printf("[TASK ERROR] "+message, params...)
+// Save enqueues the tasks and returns their IDs.
+func (op *TaskAddOp) Save() ([]string, error) {
+	return op.backend.Enqueue(op.ctx, op.wait, op.tasks...)
 }
@@ -2,12 +2,14 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/mikestefanello/backlite"
+	"github.com/mrlokans/assistant/internal/events"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,6 +34,84 @@ func TestNewClient(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestNewClient_TasksDBPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("data", "highlights-tasks.db"), sqliteTasksPath(filepath.Join("data", "highlights.db"), Config{}))
+	assert.Equal(t, "/queue/tasks.db", sqliteTasksPath("data/highlights.db", Config{DBPath: "/queue/tasks.db"}))
+}
+
+func TestNewClient_UnknownBackend(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Backend = "carrier-pigeon"
+
+	_, err := NewClient(filepath.Join(t.TempDir(), "test.db"), cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "available: sqlite")
+}
+
+func TestParseBackend(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		err      string
+	}{
+		{"", BackendSQLite, ""},
+		{" SQLite ", BackendSQLite, ""},
+		{"asynq", "", "rebuild with -tags asynq"},
+		{"redis", "", "unknown task backend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			backend, err := ParseBackend(tt.input)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, backend)
+		})
+	}
+}
+
+// fakeBackend records what the client passes to its backend
+type fakeBackend struct {
+	queues []backlite.Queue
+	waits  []time.Duration
+	tasks  []backlite.Task
+}
+
+func (b *fakeBackend) Register(queue backlite.Queue) { b.queues = append(b.queues, queue) }
+func (b *fakeBackend) Start(ctx context.Context)     {}
+func (b *fakeBackend) Stop(ctx context.Context) bool { return true }
+func (b *fakeBackend) Close() error                  { return nil }
+func (b *fakeBackend) Status(ctx context.Context, taskID string) (backlite.TaskStatus, error) {
+	return backlite.TaskStatusSuccess, nil
+}
+
+func (b *fakeBackend) Enqueue(ctx context.Context, wait time.Duration, tasks ...backlite.Task) ([]string, error) {
+	b.waits = append(b.waits, wait)
+	b.tasks = append(b.tasks, tasks...)
+	return []string{"id"}, nil
+}
+
+func TestClientWithBackend(t *testing.T) {
+	backend := &fakeBackend{}
+	client := NewClientWithBackend(backend, DefaultConfig())
+	client.SetEventBroker(events.NewBroker())
+
+	client.Register(backlite.NewQueue(func(ctx context.Context, task TestTask) error { return nil }))
+	require.Len(t, backend.queues, 1)
+	assert.IsType(t, &observedQueue{}, backend.queues[0], "queues are wrapped to publish task events")
+
+	ids, err := client.Add(TestTask{Value: "now"}).Save()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id"}, ids)
+	require.NoError(t, client.Schedule(TestTask{Value: "later"}, time.Minute))
+
+	assert.Equal(t, []time.Duration{0, time.Minute}, backend.waits)
+	assert.Equal(t, []backlite.Task{TestTask{Value: "now"}, TestTask{Value: "later"}}, backend.tasks)
+}
+
 func TestClientStartStop(t *testing.T) {
 	tmpDir := t.TempDir()
 	dbPath := filepath.Join(tmpDir, "test.db")
@@ -143,3 +223,31 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, time.Hour, cfg.CleanupInterval)
 	assert.Equal(t, 24*time.Hour, cfg.RetentionDuration)
 }
+
+func TestObservedQueue(t *testing.T) {
+	broker := events.NewBroker()
+	ch, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	queue := &observedQueue{
+		Queue: backlite.NewQueue(func(ctx context.Context, task EnrichBookTask) error {
+			if task.BookID == 0 {
+				return errors.New("no book")
+			}
+			return nil
+		}),
+		events: broker,
+	}
+
+	require.NoError(t, queue.Process(context.Background(), []byte(`{"book_id":7}`)))
+	event := <-ch
+	assert.Equal(t, events.TypeTaskCompleted, event.Type)
+	data := event.Data.(TaskEvent)
+	assert.Equal(t, "enrich_book", data.Queue)
+	assert.JSONEq(t, `{"book_id":7}`, string(data.Task))
+
+	assert.Error(t, queue.Process(context.Background(), []byte(`{"book_id":0}`)))
+	event = <-ch
+	assert.Equal(t, events.TypeTaskFailed, event.Type)
+	assert.Equal(t, "no book", event.Data.(TaskEvent).Error)
+}
@@ -2,8 +2,28 @@ package tasks
 
 import "time"
 
+// Backend names accepted in the TASKS_BACKEND setting.
+const (
+	// BackendSQLite keeps queued tasks in a SQLite database, so they survive restarts.
+	BackendSQLite = "sqlite"
+
+	// BackendAsynq keeps queued tasks in Redis through asynq, so several instances
+	// can share one queue. Only available in builds with the asynq build tag.
+	BackendAsynq = "asynq"
+)
+
 // Config holds configuration for the task queue system.
 type Config struct {
+	// Backend is where queued tasks are kept. Default: sqlite
+	Backend string
+
+	// DBPath is the SQLite database of the sqlite backend.
+	// Default: next to the main database, with a "-tasks" suffix
+	DBPath string
+
+	// RedisURL is the Redis server of the asynq backend, e.g. redis://localhost:6379/0
+	RedisURL string
+
 	// Workers is the number of concurrent task workers. Default: 2
 	Workers int
 
@@ -29,6 +49,7 @@ type Config struct {
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
+		Backend:           BackendSQLite,
 		Workers:           2,
 		MaxRetries:        3,
 		RetryDelay:        1 * time.Minute,
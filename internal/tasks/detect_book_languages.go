@@ -0,0 +1,51 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mikestefanello/backlite"
+)
+
+// BookLanguageStore defines the interface for book language detection.
+type BookLanguageStore interface {
+	DetectBookLanguages(ctx context.Context, redetect bool) (int, error)
+}
+
+// DetectBookLanguagesTask detects the language of books from their highlights.
+// Only books without a language are looked at, unless Redetect is set.
+type DetectBookLanguagesTask struct {
+	Redetect bool `json:"redetect,omitempty"`
+}
+
+func (t DetectBookLanguagesTask) Config() backlite.QueueConfig {
+	return backlite.QueueConfig{
+		Name:        "detect_book_languages",
+		MaxAttempts: 1,
+		Backoff:     time.Minute,
+		Timeout:     15 * time.Minute,
+		Retention: &backlite.Retention{
+			Duration:   24 * time.Hour,
+			OnlyFailed: false,
+			Data:       &backlite.RetainData{OnlyFailed: true},
+		},
+	}
+}
+
+// DetectBookLanguagesProcessor creates a processor for book language detection.
+func DetectBookLanguagesProcessor(store BookLanguageStore) backlite.QueueProcessor[DetectBookLanguagesTask] {
+	return func(ctx context.Context, task DetectBookLanguagesTask) error {
+		changed, err := store.DetectBookLanguages(ctx, task.Redetect)
+		if err != nil {
+			return fmt.Errorf("detect book languages: %w", err)
+		}
+		log.Printf("[TASK] Detected the language of %d books", changed)
+		return nil
+	}
+}
+
+func NewDetectBookLanguagesQueue(store BookLanguageStore) backlite.Queue {
+	return backlite.NewQueue(DetectBookLanguagesProcessor(store))
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/mikestefanello/backlite"
+	"github.com/mrlokans/assistant/internal/entities"
 	"github.com/mrlokans/assistant/internal/metadata"
 )
 
@@ -32,19 +33,50 @@ func (t EnrichAllBooksTask) Config() backlite.QueueConfig {
 	}
 }
 
+// SyncCheckpointer saves how far a resumable sync got.
+type SyncCheckpointer interface {
+	GetSyncProgress(syncType entities.SyncType) (*entities.SyncProgress, error)
+	SetSyncProgressLastItem(syncType entities.SyncType, itemID uint) error
+}
+
 // EnrichAllBooksProcessor creates a processor function for EnrichAllBooksTask.
-// It uses the enricher's EnrichAllMissing method which handles progress tracking.
-func EnrichAllBooksProcessor(enricher *metadata.Enricher) backlite.QueueProcessor[EnrichAllBooksTask] {
+// It uses the enricher's EnrichMissing method which handles progress tracking.
+// The last finished book is checkpointed, so a run cut short, e.g. by a
+// restart, continues after it the next time.
+func EnrichAllBooksProcessor(enricher *metadata.Enricher, checkpoints SyncCheckpointer) backlite.QueueProcessor[EnrichAllBooksTask] {
 	return func(ctx context.Context, task EnrichAllBooksTask) error {
 		if enricher == nil {
 			return fmt.Errorf("enricher not configured")
 		}
 
-		result, err := enricher.EnrichAllMissing(ctx)
+		opts := metadata.BulkEnrichmentOptions{}
+		if checkpoints != nil {
+			if progress, err := checkpoints.GetSyncProgress(entities.SyncTypeMetadata); err == nil && progress.LastItemID > 0 {
+				opts.ResumeAfterID = progress.LastItemID
+				log.Printf("[TASK] Resuming metadata enrichment after book #%d", progress.LastItemID)
+			}
+			opts.OnBook = func(done, total int, book entities.Book, err error) {
+				// A book cut short by cancellation is retried on the next run
+				if ctx.Err() == nil {
+					if err := checkpoints.SetSyncProgressLastItem(entities.SyncTypeMetadata, book.ID); err != nil {
+						log.Printf("[TASK] Failed to save enrichment progress: %v", err)
+					}
+				}
+			}
+		}
+
+		result, err := enricher.EnrichMissing(ctx, opts)
 		if err != nil {
 			return fmt.Errorf("enrich all books: %w", err)
 		}
 
+		// The whole list was processed, so the next run starts from the beginning
+		if checkpoints != nil {
+			if err := checkpoints.SetSyncProgressLastItem(entities.SyncTypeMetadata, 0); err != nil {
+				log.Printf("[TASK] Failed to reset enrichment progress: %v", err)
+			}
+		}
+
 		log.Printf("[TASK] Enrichment complete: %d total, %d enriched, %d skipped, %d failed",
 			result.TotalBooks, result.Enriched, result.Skipped, result.Failed)
 
@@ -53,6 +85,6 @@ func EnrichAllBooksProcessor(enricher *metadata.Enricher) backlite.QueueProcesso
 }
 
 // NewEnrichAllBooksQueue creates a backlite queue for bulk enrichment tasks.
-func NewEnrichAllBooksQueue(enricher *metadata.Enricher) backlite.Queue {
-	return backlite.NewQueue(EnrichAllBooksProcessor(enricher))
+func NewEnrichAllBooksQueue(enricher *metadata.Enricher, checkpoints SyncCheckpointer) backlite.Queue {
+	return backlite.NewQueue(EnrichAllBooksProcessor(enricher, checkpoints))
 }
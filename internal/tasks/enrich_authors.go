@@ -0,0 +1,78 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mikestefanello/backlite"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/metadata"
+)
+
+// EnrichAuthorsTask looks up every author not yet enriched on Wikidata.
+type EnrichAuthorsTask struct{}
+
+// AuthorLister returns the authors waiting for enrichment.
+type AuthorLister interface {
+	GetAuthorsToEnrich() ([]entities.Author, error)
+}
+
+// Config returns the queue configuration for author enrichment tasks.
+func (t EnrichAuthorsTask) Config() backlite.QueueConfig {
+	return backlite.QueueConfig{
+		Name:        "enrich_authors",
+		MaxAttempts: 3,
+		Backoff:     5 * time.Minute,
+		Timeout:     60 * time.Minute, // Wikidata is queried about one author per few seconds
+		Retention: &backlite.Retention{
+			Duration:   24 * time.Hour,
+			OnlyFailed: false,
+			Data:       &backlite.RetainData{OnlyFailed: true},
+		},
+	}
+}
+
+// EnrichAuthorsProcessor creates a processor function for EnrichAuthorsTask.
+// Authors Wikidata has no match for are marked as looked up and not retried;
+// other failures leave the author pending for the next run.
+func EnrichAuthorsProcessor(enricher *metadata.AuthorEnricher, authors AuthorLister) backlite.QueueProcessor[EnrichAuthorsTask] {
+	return func(ctx context.Context, task EnrichAuthorsTask) error {
+		if enricher == nil {
+			return fmt.Errorf("author enricher not configured")
+		}
+
+		pending, err := authors.GetAuthorsToEnrich()
+		if err != nil {
+			return fmt.Errorf("get authors to enrich: %w", err)
+		}
+
+		enriched, notFound, failed := 0, 0, 0
+		for _, author := range pending {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			_, err := enricher.EnrichAuthor(ctx, author.ID)
+			switch {
+			case err == nil:
+				enriched++
+			case errors.Is(err, metadata.ErrAuthorNotFound):
+				notFound++
+			default:
+				failed++
+				log.Printf("[TASK] Failed to enrich author %q: %v", author.Name, err)
+			}
+		}
+
+		log.Printf("[TASK] Author enrichment complete: %d total, %d enriched, %d not found, %d failed",
+			len(pending), enriched, notFound, failed)
+		return nil
+	}
+}
+
+// NewEnrichAuthorsQueue creates a backlite queue for author enrichment tasks.
+func NewEnrichAuthorsQueue(enricher *metadata.AuthorEnricher, authors AuthorLister) backlite.Queue {
+	return backlite.NewQueue(EnrichAuthorsProcessor(enricher, authors))
+}
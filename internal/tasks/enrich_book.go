@@ -7,12 +7,22 @@ import (
 	"time"
 
 	"github.com/mikestefanello/backlite"
+	"github.com/mrlokans/assistant/internal/entities"
 	"github.com/mrlokans/assistant/internal/metadata"
 )
 
 // EnrichBookTask enriches a single book's metadata from external sources.
 type EnrichBookTask struct {
 	BookID uint `json:"book_id"`
+
+	// SyncType, when set, counts the outcome in that sync's progress. Failures
+	// are then counted instead of retried, so the sync finishes.
+	SyncType entities.SyncType `json:"sync_type,omitempty"`
+}
+
+// SyncItemRecorder counts books enriched as part of a sync.
+type SyncItemRecorder interface {
+	RecordSyncItem(syncType entities.SyncType, outcome entities.SyncOutcome, currentItem string) error
 }
 
 // Config returns the queue configuration for book enrichment tasks.
@@ -31,14 +41,22 @@ func (t EnrichBookTask) Config() backlite.QueueConfig {
 }
 
 // EnrichBookProcessor creates a processor function for EnrichBookTask.
-// The processor needs access to the metadata enricher to perform the actual work.
-func EnrichBookProcessor(enricher *metadata.Enricher) backlite.QueueProcessor[EnrichBookTask] {
+// The processor needs access to the metadata enricher to perform the actual work,
+// and to the progress recorder (optional) for tasks that are part of a sync.
+func EnrichBookProcessor(enricher *metadata.Enricher, progress SyncItemRecorder) backlite.QueueProcessor[EnrichBookTask] {
 	return func(ctx context.Context, task EnrichBookTask) error {
 		if enricher == nil {
 			return fmt.Errorf("enricher not configured")
 		}
 
 		result, err := enricher.EnrichBook(ctx, task.BookID)
+		if task.SyncType != "" && progress != nil {
+			recordEnrichment(progress, task, result, err)
+			if err != nil {
+				log.Printf("[TASK] Failed to enrich book %d: %v", task.BookID, err)
+				return nil
+			}
+		}
 		if err != nil {
 			return fmt.Errorf("enrich book %d: %w", task.BookID, err)
 		}
@@ -55,7 +73,21 @@ func EnrichBookProcessor(enricher *metadata.Enricher) backlite.QueueProcessor[En
 	}
 }
 
+// recordEnrichment counts an enriched book in its sync's progress
+func recordEnrichment(progress SyncItemRecorder, task EnrichBookTask, result *metadata.EnrichmentResult, err error) {
+	outcome, item := entities.SyncOutcomeFailed, fmt.Sprintf("book %d", task.BookID)
+	if err == nil {
+		outcome, item = entities.SyncOutcomeSkipped, result.Book.Title
+		if len(result.FieldsUpdated) > 0 {
+			outcome = entities.SyncOutcomeSucceeded
+		}
+	}
+	if err := progress.RecordSyncItem(task.SyncType, outcome, item); err != nil {
+		log.Printf("[TASK] Failed to record %s progress for book %d: %v", task.SyncType, task.BookID, err)
+	}
+}
+
 // NewEnrichBookQueue creates a backlite queue for book enrichment tasks.
-func NewEnrichBookQueue(enricher *metadata.Enricher) backlite.Queue {
-	return backlite.NewQueue(EnrichBookProcessor(enricher))
+func NewEnrichBookQueue(enricher *metadata.Enricher, progress SyncItemRecorder) backlite.Queue {
+	return backlite.NewQueue(EnrichBookProcessor(enricher, progress))
 }
@@ -2,6 +2,7 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -16,18 +17,22 @@ type WordEnricher interface {
 	GetWordByID(id uint) (*entities.Word, error)
 	SaveDefinitions(wordID uint, definitions []entities.WordDefinition) error
 	UpdateWordStatus(id uint, status entities.WordStatus, errorMsg string) error
+	RecordEnrichmentFailure(id uint, attempts int, status entities.WordStatus, errorMsg string) error
 	GetPendingWords(limit int) ([]entities.Word, error)
 }
 
 // EnrichWordTask enriches a single word with dictionary definitions.
+// Attempt counts the lookups of the word that already failed.
 type EnrichWordTask struct {
-	WordID uint `json:"word_id"`
+	WordID  uint `json:"word_id"`
+	Attempt int  `json:"attempt,omitempty"`
 }
 
 func (t EnrichWordTask) Config() backlite.QueueConfig {
 	return backlite.QueueConfig{
-		Name:        "enrich_word",
-		MaxAttempts: 3,
+		Name: "enrich_word",
+		// Retries are scheduled by the processor, backing off per RetryPolicy
+		MaxAttempts: 1,
 		Backoff:     30 * time.Second,
 		Timeout:     1 * time.Minute,
 		Retention: &backlite.Retention{
@@ -38,24 +43,28 @@ func (t EnrichWordTask) Config() backlite.QueueConfig {
 	}
 }
 
-// EnrichWordProcessor creates a processor for word enrichment.
-func EnrichWordProcessor(store WordEnricher, dictClient dictionary.Client) backlite.QueueProcessor[EnrichWordTask] {
+// EnrichWordProcessor creates a processor for word enrichment. A failed
+// lookup is scheduled again after a growing delay until the policy's attempts
+// are used up, and only then is the word marked failed. Words the dictionary
+// does not know, words in a language it does not cover, and lookups through a
+// disabled provider fail right away. Words are looked up in the language
+// detected for their book.
+func EnrichWordProcessor(store WordEnricher, dictClient dictionary.Client, scheduler Scheduler, policy RetryPolicy) backlite.QueueProcessor[EnrichWordTask] {
 	return func(ctx context.Context, task EnrichWordTask) error {
 		word, err := store.GetWordByID(task.WordID)
 		if err != nil {
 			return fmt.Errorf("get word %d: %w", task.WordID, err)
 		}
 
-		result, err := dictClient.Lookup(ctx, word.Word)
+		result, err := dictClient.Lookup(ctx, word.Word, wordLanguage(word))
+		if err == nil {
+			err = store.SaveDefinitions(task.WordID, result.Definitions)
+		}
 		if err != nil {
-			if updateErr := store.UpdateWordStatus(task.WordID, entities.WordStatusFailed, err.Error()); updateErr != nil {
-				log.Printf("[TASK] Failed to update word status: %v", updateErr)
+			if retryEnrichment(store, scheduler, policy, word, task.Attempt+1, err) {
+				return nil
 			}
-			return fmt.Errorf("lookup word %q: %w", word.Word, err)
-		}
-
-		if err := store.SaveDefinitions(task.WordID, result.Definitions); err != nil {
-			return fmt.Errorf("save definitions for word %d: %w", task.WordID, err)
+			return fmt.Errorf("enrich word %q: %w", word.Word, err)
 		}
 
 		if err := store.UpdateWordStatus(task.WordID, entities.WordStatusEnriched, ""); err != nil {
@@ -67,8 +76,42 @@ func EnrichWordProcessor(store WordEnricher, dictClient dictionary.Client) backl
 	}
 }
 
-func NewEnrichWordQueue(store WordEnricher, dictClient dictionary.Client) backlite.Queue {
-	return backlite.NewQueue(EnrichWordProcessor(store, dictClient))
+func NewEnrichWordQueue(store WordEnricher, dictClient dictionary.Client, client *Client) backlite.Queue {
+	return backlite.NewQueue(EnrichWordProcessor(store, dictClient, client, client.RetryPolicy()))
+}
+
+// retryEnrichment records a failed enrichment of the word and schedules the
+// next attempt if the policy allows one, returning whether it did. Otherwise
+// the word is marked failed.
+func retryEnrichment(store WordEnricher, scheduler Scheduler, policy RetryPolicy, word *entities.Word, attempts int, err error) bool {
+	if scheduler != nil && policy.ShouldRetry(attempts) &&
+		!errors.Is(err, dictionary.ErrWordNotFound) && !errors.Is(err, dictionary.ErrProviderDisabled) &&
+		!errors.Is(err, dictionary.ErrUnsupportedLanguage) {
+		wait := policy.Delay(attempts)
+		scheduleErr := scheduler.Schedule(EnrichWordTask{WordID: word.ID, Attempt: attempts}, wait)
+		if scheduleErr == nil {
+			if updateErr := store.RecordEnrichmentFailure(word.ID, attempts, entities.WordStatusPending, err.Error()); updateErr != nil {
+				log.Printf("[TASK] Failed to update word status: %v", updateErr)
+			}
+			log.Printf("[TASK] Enriching word %q failed (attempt %d of %d), retrying in %s: %v", word.Word, attempts, policy.MaxAttempts, wait, err)
+			return true
+		}
+		log.Printf("[TASK] Failed to schedule retry for word %q: %v", word.Word, scheduleErr)
+	}
+
+	if updateErr := store.RecordEnrichmentFailure(word.ID, attempts, entities.WordStatusFailed, err.Error()); updateErr != nil {
+		log.Printf("[TASK] Failed to update word status: %v", updateErr)
+	}
+	return false
+}
+
+// wordLanguage returns the language detected for the book the word was read
+// in, or "" when unknown.
+func wordLanguage(word *entities.Word) string {
+	if word.Book == nil {
+		return ""
+	}
+	return word.Book.Language
 }
 
 // EnrichAllPendingWordsTask enriches all words with pending status.
@@ -88,32 +131,34 @@ func (t EnrichAllPendingWordsTask) Config() backlite.QueueConfig {
 	}
 }
 
-func EnrichAllPendingWordsProcessor(store WordEnricher, dictClient dictionary.Client) backlite.QueueProcessor[EnrichAllPendingWordsTask] {
+// EnrichAllPendingWordsProcessor looks up every pending word once. Words that
+// fail are retried on their own through the enrich_word queue.
+func EnrichAllPendingWordsProcessor(store WordEnricher, dictClient dictionary.Client, scheduler Scheduler, policy RetryPolicy) backlite.QueueProcessor[EnrichAllPendingWordsTask] {
 	return func(ctx context.Context, task EnrichAllPendingWordsTask) error {
 		words, err := store.GetPendingWords(0) // 0 = no limit
 		if err != nil {
 			return fmt.Errorf("get pending words: %w", err)
 		}
 
-		var enriched, failed int
+		var enriched, retrying, failed int
 		for _, word := range words {
 			select {
 			case <-ctx.Done():
-				log.Printf("[TASK] Context cancelled, enriched %d words, %d failed", enriched, failed)
+				log.Printf("[TASK] Context cancelled, enriched %d words, %d retrying, %d failed", enriched, retrying, failed)
 				return ctx.Err()
 			default:
 			}
 
-			result, err := dictClient.Lookup(ctx, word.Word)
-			if err != nil {
-				_ = store.UpdateWordStatus(word.ID, entities.WordStatusFailed, err.Error())
-				failed++
-				continue
+			result, err := dictClient.Lookup(ctx, word.Word, wordLanguage(&word))
+			if err == nil {
+				err = store.SaveDefinitions(word.ID, result.Definitions)
 			}
-
-			if err := store.SaveDefinitions(word.ID, result.Definitions); err != nil {
-				_ = store.UpdateWordStatus(word.ID, entities.WordStatusFailed, err.Error())
-				failed++
+			if err != nil {
+				if retryEnrichment(store, scheduler, policy, &word, 1, err) {
+					retrying++
+				} else {
+					failed++
+				}
 				continue
 			}
 
@@ -121,11 +166,11 @@ func EnrichAllPendingWordsProcessor(store WordEnricher, dictClient dictionary.Cl
 			enriched++
 		}
 
-		log.Printf("[TASK] Enriched %d words, %d failed out of %d total", enriched, failed, len(words))
+		log.Printf("[TASK] Enriched %d words, %d retrying, %d failed out of %d total", enriched, retrying, failed, len(words))
 		return nil
 	}
 }
 
-func NewEnrichAllPendingWordsQueue(store WordEnricher, dictClient dictionary.Client) backlite.Queue {
-	return backlite.NewQueue(EnrichAllPendingWordsProcessor(store, dictClient))
+func NewEnrichAllPendingWordsQueue(store WordEnricher, dictClient dictionary.Client, client *Client) backlite.Queue {
+	return backlite.NewQueue(EnrichAllPendingWordsProcessor(store, dictClient, client, client.RetryPolicy()))
 }
@@ -0,0 +1,196 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mikestefanello/backlite"
+	"github.com/mrlokans/assistant/internal/dictionary"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWordEnricher struct {
+	words map[uint]*entities.Word
+}
+
+func (f *fakeWordEnricher) GetWordByID(id uint) (*entities.Word, error) {
+	word, ok := f.words[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *word
+	return &copied, nil
+}
+
+func (f *fakeWordEnricher) SaveDefinitions(wordID uint, definitions []entities.WordDefinition) error {
+	f.words[wordID].Definitions = definitions
+	return nil
+}
+
+func (f *fakeWordEnricher) UpdateWordStatus(id uint, status entities.WordStatus, errorMsg string) error {
+	return f.RecordEnrichmentFailure(id, 0, status, errorMsg)
+}
+
+func (f *fakeWordEnricher) RecordEnrichmentFailure(id uint, attempts int, status entities.WordStatus, errorMsg string) error {
+	f.words[id].Status = status
+	f.words[id].EnrichmentAttempts = attempts
+	f.words[id].EnrichmentError = errorMsg
+	return nil
+}
+
+func (f *fakeWordEnricher) GetPendingWords(limit int) ([]entities.Word, error) {
+	var words []entities.Word
+	for _, word := range f.words {
+		if word.Status == entities.WordStatusPending {
+			words = append(words, *word)
+		}
+	}
+	return words, nil
+}
+
+type fakeDictionary struct {
+	err       error
+	languages []string
+}
+
+func (f *fakeDictionary) Lookup(ctx context.Context, word, language string) (*dictionary.LookupResult, error) {
+	f.languages = append(f.languages, language)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &dictionary.LookupResult{
+		Word:        word,
+		Definitions: []entities.WordDefinition{{PartOfSpeech: "adjective", Definition: "hard to understand"}},
+	}, nil
+}
+
+func (f *fakeDictionary) Name() string {
+	return "fake"
+}
+
+type scheduledTask struct {
+	task backlite.Task
+	wait time.Duration
+}
+
+type fakeScheduler struct {
+	scheduled []scheduledTask
+}
+
+func (f *fakeScheduler) Schedule(task backlite.Task, wait time.Duration) error {
+	f.scheduled = append(f.scheduled, scheduledTask{task: task, wait: wait})
+	return nil
+}
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+
+func newFakeWordEnricher() *fakeWordEnricher {
+	return &fakeWordEnricher{words: map[uint]*entities.Word{
+		1: {ID: 1, Word: "abstruse", Status: entities.WordStatusPending},
+	}}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicyFromConfig(Config{MaxRetries: 3, RetryDelay: time.Minute})
+	assert.Equal(t, 4, policy.MaxAttempts)
+
+	assert.Equal(t, time.Minute, policy.Delay(1))
+	assert.Equal(t, 2*time.Minute, policy.Delay(2))
+	assert.Equal(t, 4*time.Minute, policy.Delay(3))
+	assert.Equal(t, time.Hour, policy.Delay(20), "capped at MaxDelay")
+
+	assert.True(t, policy.ShouldRetry(3))
+	assert.False(t, policy.ShouldRetry(4))
+}
+
+func TestEnrichWordProcessor_RetriesTransientFailure(t *testing.T) {
+	store := newFakeWordEnricher()
+	scheduler := &fakeScheduler{}
+	dict := &fakeDictionary{err: fmt.Errorf("unexpected status: %d", 503)}
+	process := EnrichWordProcessor(store, dict, scheduler, testRetryPolicy)
+
+	require.NoError(t, process(context.Background(), EnrichWordTask{WordID: 1}))
+	require.Len(t, scheduler.scheduled, 1)
+	assert.Equal(t, EnrichWordTask{WordID: 1, Attempt: 1}, scheduler.scheduled[0].task)
+	assert.Equal(t, time.Minute, scheduler.scheduled[0].wait)
+	assert.Equal(t, entities.WordStatusPending, store.words[1].Status)
+	assert.Equal(t, 1, store.words[1].EnrichmentAttempts)
+	assert.Equal(t, "unexpected status: 503", store.words[1].EnrichmentError)
+
+	require.NoError(t, process(context.Background(), EnrichWordTask{WordID: 1, Attempt: 1}))
+	require.Len(t, scheduler.scheduled, 2)
+	assert.Equal(t, 2*time.Minute, scheduler.scheduled[1].wait, "the wait doubles")
+
+	// The last attempt marks the word failed
+	err := process(context.Background(), EnrichWordTask{WordID: 1, Attempt: 2})
+	assert.Error(t, err)
+	assert.Len(t, scheduler.scheduled, 2)
+	assert.Equal(t, entities.WordStatusFailed, store.words[1].Status)
+	assert.Equal(t, 3, store.words[1].EnrichmentAttempts)
+
+	// A later success clears the failure
+	dict.err = nil
+	require.NoError(t, process(context.Background(), EnrichWordTask{WordID: 1}))
+	assert.Equal(t, entities.WordStatusEnriched, store.words[1].Status)
+	assert.Zero(t, store.words[1].EnrichmentAttempts)
+	assert.Empty(t, store.words[1].EnrichmentError)
+	assert.Len(t, store.words[1].Definitions, 1)
+}
+
+func TestEnrichWordProcessor_UnknownWordFailsRightAway(t *testing.T) {
+	store := newFakeWordEnricher()
+	scheduler := &fakeScheduler{}
+	dict := &fakeDictionary{err: fmt.Errorf("%w: abstruse", dictionary.ErrWordNotFound)}
+
+	err := EnrichWordProcessor(store, dict, scheduler, testRetryPolicy)(context.Background(), EnrichWordTask{WordID: 1})
+	assert.ErrorIs(t, err, dictionary.ErrWordNotFound)
+	assert.Empty(t, scheduler.scheduled)
+	assert.Equal(t, entities.WordStatusFailed, store.words[1].Status)
+	assert.Equal(t, 1, store.words[1].EnrichmentAttempts)
+}
+
+func TestEnrichWordProcessor_DisabledProviderFailsRightAway(t *testing.T) {
+	store := newFakeWordEnricher()
+	scheduler := &fakeScheduler{}
+	dict := dictionary.WithEnabled(&fakeDictionary{}, func(provider string) bool { return provider != "fake" })
+
+	err := EnrichWordProcessor(store, dict, scheduler, testRetryPolicy)(context.Background(), EnrichWordTask{WordID: 1})
+	assert.ErrorIs(t, err, dictionary.ErrProviderDisabled)
+	assert.Empty(t, scheduler.scheduled)
+	assert.Equal(t, entities.WordStatusFailed, store.words[1].Status)
+	assert.Empty(t, store.words[1].Definitions)
+}
+
+func TestEnrichWordProcessor_LooksUpInBookLanguage(t *testing.T) {
+	store := newFakeWordEnricher()
+	store.words[1].Book = &entities.Book{Title: "Der Prozess", Language: "de"}
+	dict := &fakeDictionary{}
+
+	require.NoError(t, EnrichWordProcessor(store, dict, &fakeScheduler{}, testRetryPolicy)(context.Background(), EnrichWordTask{WordID: 1}))
+	assert.Equal(t, []string{"de"}, dict.languages)
+
+	// The Free Dictionary only has English entries, so there is nothing to retry
+	scheduler := &fakeScheduler{}
+	store.words[1].Status = entities.WordStatusPending
+	err := EnrichWordProcessor(store, dictionary.NewFreeDictionaryClient(), scheduler, testRetryPolicy)(context.Background(), EnrichWordTask{WordID: 1})
+	assert.ErrorIs(t, err, dictionary.ErrUnsupportedLanguage)
+	assert.Empty(t, scheduler.scheduled)
+	assert.Equal(t, entities.WordStatusFailed, store.words[1].Status)
+}
+
+func TestEnrichAllPendingWordsProcessor_RetriesFailedWords(t *testing.T) {
+	store := newFakeWordEnricher()
+	scheduler := &fakeScheduler{}
+	dict := &fakeDictionary{err: errors.New("fetch definition: connection reset")}
+
+	process := EnrichAllPendingWordsProcessor(store, dict, scheduler, testRetryPolicy)
+	require.NoError(t, process(context.Background(), EnrichAllPendingWordsTask{}))
+	require.Len(t, scheduler.scheduled, 1)
+	assert.Equal(t, EnrichWordTask{WordID: 1, Attempt: 1}, scheduler.scheduled[0].task)
+	assert.Equal(t, entities.WordStatusPending, store.words[1].Status)
+}
@@ -0,0 +1,136 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/mikestefanello/backlite"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/wordfreq"
+)
+
+// extractBatchSize is the number of highlights scanned per database round-trip.
+const extractBatchSize = 200
+
+// VocabularyExtractor defines the interface for vocabulary extraction operations.
+type VocabularyExtractor interface {
+	GetHighlightsAfterID(afterID uint, limit int) ([]entities.Highlight, error)
+	FindWordByText(word string, userID uint) (*entities.Word, error)
+	AddWord(word *entities.Word) error
+	GetSetting(key string) (*entities.Setting, error)
+	SetSetting(key, value string) error
+}
+
+// ExtractVocabularyTask scans highlights added since the last run for rare words
+// and stores them as vocabulary candidates awaiting confirmation. Highlights of
+// books detected to be in a language other than English are skipped.
+type ExtractVocabularyTask struct{}
+
+func (t ExtractVocabularyTask) Config() backlite.QueueConfig {
+	return backlite.QueueConfig{
+		Name:        "extract_vocabulary",
+		MaxAttempts: 1,
+		Backoff:     time.Minute,
+		Timeout:     15 * time.Minute,
+		Retention: &backlite.Retention{
+			Duration:   24 * time.Hour,
+			OnlyFailed: false,
+			Data:       &backlite.RetainData{OnlyFailed: true},
+		},
+	}
+}
+
+// ExtractVocabularyProcessor creates a processor for vocabulary extraction.
+// Progress is stored as the last scanned highlight ID, so each highlight is scanned once
+// and candidates dismissed by the user are not suggested again.
+func ExtractVocabularyProcessor(store VocabularyExtractor) backlite.QueueProcessor[ExtractVocabularyTask] {
+	return func(ctx context.Context, task ExtractVocabularyTask) error {
+		lastID := loadExtractCursor(store)
+		opts := wordfreq.DefaultOptions()
+		var scanned, added int
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("[TASK] Context cancelled, scanned %d highlights, added %d candidates", scanned, added)
+				return ctx.Err()
+			default:
+			}
+
+			highlights, err := store.GetHighlightsAfterID(lastID, extractBatchSize)
+			if err != nil {
+				return fmt.Errorf("get highlights after %d: %w", lastID, err)
+			}
+			if len(highlights) == 0 {
+				break
+			}
+
+			for i := range highlights {
+				// The frequency list is English, so every word of another language looks rare
+				if language := highlights[i].Book.Language; language == "" || language == "en" {
+					added += addCandidates(store, &highlights[i], opts)
+				}
+				lastID = highlights[i].ID
+			}
+			scanned += len(highlights)
+
+			if err := store.SetSetting(entities.SettingKeyVocabularyExtractLastHighlightID, strconv.FormatUint(uint64(lastID), 10)); err != nil {
+				return fmt.Errorf("save extraction cursor: %w", err)
+			}
+		}
+
+		log.Printf("[TASK] Scanned %d highlights, added %d vocabulary candidates", scanned, added)
+		return nil
+	}
+}
+
+// addCandidates stores rare words from a highlight as candidates and returns how many were added.
+func addCandidates(store VocabularyExtractor, highlight *entities.Highlight, opts wordfreq.Options) int {
+	added := 0
+	for _, candidate := range wordfreq.Extract(highlight.Text, opts) {
+		if existing, _ := store.FindWordByText(candidate.Word, highlight.UserID); existing != nil {
+			continue
+		}
+
+		highlightID := highlight.ID
+		bookID := highlight.BookID
+		word := &entities.Word{
+			UserID:              highlight.UserID,
+			Word:                candidate.Word,
+			HighlightID:         &highlightID,
+			BookID:              &bookID,
+			Context:             candidate.Context,
+			Status:              entities.WordStatusCandidate,
+			SourceBookTitle:     highlight.Book.Title,
+			SourceBookAuthor:    highlight.Book.Author,
+			SourceHighlightText: highlight.Text,
+		}
+		if err := store.AddWord(word); err != nil {
+			log.Printf("[TASK] Failed to add vocabulary candidate %q: %v", candidate.Word, err)
+			continue
+		}
+		added++
+	}
+	return added
+}
+
+// loadExtractCursor returns the ID of the last scanned highlight, or 0 if none was stored.
+func loadExtractCursor(store VocabularyExtractor) uint {
+	setting, err := store.GetSetting(entities.SettingKeyVocabularyExtractLastHighlightID)
+	if err != nil || setting.Value == "" {
+		return 0
+	}
+
+	lastID, err := strconv.ParseUint(setting.Value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(lastID)
+}
+
+func NewExtractVocabularyQueue(store VocabularyExtractor) backlite.Queue {
+	return backlite.NewQueue(ExtractVocabularyProcessor(store))
+}
@@ -0,0 +1,96 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVocabularyExtractor struct {
+	highlights []entities.Highlight
+	words      []entities.Word
+	settings   map[string]string
+}
+
+func (f *fakeVocabularyExtractor) GetHighlightsAfterID(afterID uint, limit int) ([]entities.Highlight, error) {
+	var result []entities.Highlight
+	for _, h := range f.highlights {
+		if h.ID > afterID {
+			result = append(result, h)
+		}
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeVocabularyExtractor) FindWordByText(word string, userID uint) (*entities.Word, error) {
+	for i := range f.words {
+		if strings.EqualFold(f.words[i].Word, word) {
+			return &f.words[i], nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeVocabularyExtractor) AddWord(word *entities.Word) error {
+	word.ID = uint(len(f.words) + 1)
+	f.words = append(f.words, *word)
+	return nil
+}
+
+func (f *fakeVocabularyExtractor) GetSetting(key string) (*entities.Setting, error) {
+	value, ok := f.settings[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &entities.Setting{Key: key, Value: value}, nil
+}
+
+func (f *fakeVocabularyExtractor) SetSetting(key, value string) error {
+	f.settings[key] = value
+	return nil
+}
+
+func TestExtractVocabularyProcessor(t *testing.T) {
+	store := &fakeVocabularyExtractor{
+		highlights: []entities.Highlight{
+			{ID: 1, BookID: 7, Text: "Its beauty was ephemeral.", Book: entities.Book{Title: "Book", Author: "Author"}},
+			{ID: 2, BookID: 7, Text: "The people were walking home."},
+		},
+		words:    []entities.Word{{Word: "numinous", Status: entities.WordStatusEnriched}},
+		settings: map[string]string{},
+	}
+	processor := ExtractVocabularyProcessor(store)
+
+	require.NoError(t, processor(context.Background(), ExtractVocabularyTask{}))
+
+	require.Len(t, store.words, 2)
+	candidate := store.words[1]
+	assert.Equal(t, "ephemeral", candidate.Word)
+	assert.Equal(t, entities.WordStatusCandidate, candidate.Status)
+	assert.Equal(t, uint(1), *candidate.HighlightID)
+	assert.Equal(t, uint(7), *candidate.BookID)
+	assert.Equal(t, "Book", candidate.SourceBookTitle)
+	assert.Equal(t, "2", store.settings[entities.SettingKeyVocabularyExtractLastHighlightID])
+
+	// Already scanned highlights and known words are skipped on the next run
+	store.highlights = append(store.highlights,
+		entities.Highlight{ID: 3, Text: "Something numinous and ephemeral."})
+	require.NoError(t, processor(context.Background(), ExtractVocabularyTask{}))
+	assert.Len(t, store.words, 2)
+	assert.Equal(t, "3", store.settings[entities.SettingKeyVocabularyExtractLastHighlightID])
+
+	// Books in other languages are not checked against the English word list
+	store.highlights = append(store.highlights,
+		entities.Highlight{ID: 4, BookID: 8, Text: "Eine ungeheuerliche Verwandlung.", Book: entities.Book{Title: "Die Verwandlung", Language: "de"}})
+	require.NoError(t, processor(context.Background(), ExtractVocabularyTask{}))
+	assert.Len(t, store.words, 2)
+	assert.Equal(t, "4", store.settings[entities.SettingKeyVocabularyExtractLastHighlightID])
+}
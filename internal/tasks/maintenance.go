@@ -0,0 +1,81 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mikestefanello/backlite"
+
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// DatabaseMaintainer provides the ability to run and record database maintenance jobs.
+type DatabaseMaintainer interface {
+	RunMaintenance(task entities.MaintenanceTask) (*entities.MaintenanceRun, error)
+}
+
+// DatabaseMaintenanceTask runs database maintenance jobs, all of them when
+// Tasks is empty.
+type DatabaseMaintenanceTask struct {
+	Tasks []entities.MaintenanceTask `json:"tasks,omitempty"`
+}
+
+// Config returns the queue configuration for database maintenance tasks.
+func (t DatabaseMaintenanceTask) Config() backlite.QueueConfig {
+	return backlite.QueueConfig{
+		Name:        "database_maintenance",
+		MaxAttempts: 1,
+		Backoff:     time.Minute,
+		Timeout:     30 * time.Minute,
+		Retention: &backlite.Retention{
+			Duration:   24 * time.Hour,
+			OnlyFailed: false,
+			Data:       &backlite.RetainData{OnlyFailed: true},
+		},
+	}
+}
+
+// DatabaseMaintenanceProcessor creates a processor function for DatabaseMaintenanceTask.
+// Every job runs even when an earlier one fails; each outcome is recorded
+// by the maintainer.
+func DatabaseMaintenanceProcessor(maintainer DatabaseMaintainer) backlite.QueueProcessor[DatabaseMaintenanceTask] {
+	return func(ctx context.Context, task DatabaseMaintenanceTask) error {
+		if maintainer == nil {
+			return fmt.Errorf("database maintainer not configured")
+		}
+
+		jobs := task.Tasks
+		if len(jobs) == 0 {
+			jobs = entities.MaintenanceTasks
+		}
+
+		failed := 0
+		for _, job := range jobs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			run, err := maintainer.RunMaintenance(job)
+			if err != nil {
+				log.Printf("[TASK] Database maintenance %s: %v", job, err)
+				failed++
+				continue
+			}
+			if run.Status == entities.MaintenanceStatusFailed {
+				failed++
+			}
+			log.Printf("[TASK] Database maintenance %s: %s (%s)", job, run.Result, run.Status)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d maintenance jobs failed", failed, len(jobs))
+		}
+		return nil
+	}
+}
+
+// NewDatabaseMaintenanceQueue creates a backlite queue for database maintenance tasks.
+func NewDatabaseMaintenanceQueue(maintainer DatabaseMaintainer) backlite.Queue {
+	return backlite.NewQueue(DatabaseMaintenanceProcessor(maintainer))
+}
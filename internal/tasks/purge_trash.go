@@ -0,0 +1,62 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mikestefanello/backlite"
+)
+
+// TrashPurger provides the ability to permanently delete old trash entries.
+type TrashPurger interface {
+	PurgeTrash(olderThan time.Time) (booksPurged, highlightsPurged int64, err error)
+}
+
+// PurgeTrashTask permanently deletes books and highlights that have been in the trash
+// longer than the configured retention period.
+type PurgeTrashTask struct {
+	RetentionDays int `json:"retention_days"`
+}
+
+// Config returns the queue configuration for trash purge tasks.
+func (t PurgeTrashTask) Config() backlite.QueueConfig {
+	return backlite.QueueConfig{
+		Name:        "purge_trash",
+		MaxAttempts: 3,
+		Backoff:     5 * time.Minute,
+		Timeout:     5 * time.Minute,
+		Retention: &backlite.Retention{
+			Duration:   24 * time.Hour,
+			OnlyFailed: false,
+			Data:       &backlite.RetainData{OnlyFailed: true},
+		},
+	}
+}
+
+// PurgeTrashProcessor creates a processor function for PurgeTrashTask.
+func PurgeTrashProcessor(purger TrashPurger) backlite.QueueProcessor[PurgeTrashTask] {
+	return func(ctx context.Context, task PurgeTrashTask) error {
+		if purger == nil {
+			return fmt.Errorf("trash purger not configured")
+		}
+		if task.RetentionDays <= 0 {
+			return fmt.Errorf("invalid trash retention: %d days", task.RetentionDays)
+		}
+
+		cutoff := time.Now().Add(-time.Duration(task.RetentionDays) * 24 * time.Hour)
+		books, highlights, err := purger.PurgeTrash(cutoff)
+		if err != nil {
+			return fmt.Errorf("purge trash: %w", err)
+		}
+
+		log.Printf("[TASK] Purged %d books and %d highlights from trash older than %d days", books, highlights, task.RetentionDays)
+		return nil
+	}
+}
+
+// NewPurgeTrashQueue creates a backlite queue for trash purge tasks.
+func NewPurgeTrashQueue(purger TrashPurger) backlite.Queue {
+	return backlite.NewQueue(PurgeTrashProcessor(purger))
+}
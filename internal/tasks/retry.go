@@ -0,0 +1,56 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/mikestefanello/backlite"
+)
+
+// maxRetryDelay caps the exponential backoff of RetryPolicy
+const maxRetryDelay = time.Hour
+
+// RetryPolicy decides when a failed task is tried again. The wait doubles
+// with every failed attempt, starting at BaseDelay and capped at MaxDelay.
+// Backlite only knows a fixed backoff, so queues using a policy schedule the
+// next attempt themselves.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// RetryPolicyFromConfig returns the policy for the configured retries: the
+// first attempt plus MaxRetries more, waiting RetryDelay, 2×RetryDelay, ...
+func RetryPolicyFromConfig(cfg Config) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 1 + max(cfg.MaxRetries, 0),
+		BaseDelay:   cfg.RetryDelay,
+		MaxDelay:    maxRetryDelay,
+	}
+}
+
+// ShouldRetry reports whether another attempt follows the given number of
+// failed attempts.
+func (p RetryPolicy) ShouldRetry(attempts int) bool {
+	return attempts < p.MaxAttempts
+}
+
+// Delay returns how long to wait after the given number of failed attempts.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// Scheduler enqueues a task to run after a delay.
+type Scheduler interface {
+	Schedule(task backlite.Task, wait time.Duration) error
+}
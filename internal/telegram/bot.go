@@ -0,0 +1,317 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+)
+
+const (
+	// DailyReviewSize is the number of highlights in the daily review message.
+	DailyReviewSize = 3
+
+	// maxBookList caps the books listed by /capture; the rest are found by title.
+	maxBookList = 20
+
+	idlePollInterval  = 30 * time.Second // Wait while the bot is disabled or unconfigured
+	errorPollInterval = 10 * time.Second // Wait after a failed getUpdates request
+)
+
+const helpText = `Commands:
+/random - a random highlight
+/capture - list books to add a highlight to
+/capture <book number or title> - pick the book, then send the highlight text
+/cancel - stop capturing`
+
+// Store defines the database operations used by the bot.
+type Store interface {
+	GetRandomHighlights(limit int) ([]entities.Highlight, error)
+	GetAllBooks() ([]entities.Book, error)
+	GetBookByID(id uint) (*entities.Book, error)
+	CreateHighlight(highlight *entities.Highlight) error
+}
+
+// Bot answers commands from the configured chat. Only one chat is served: the
+// highlights are private, so other chats are told their chat ID and refused.
+type Bot struct {
+	store         Store
+	settingsStore *settingsstore.SettingsStore
+	client        *Client
+
+	mu       sync.Mutex
+	captures map[int64]uint // Chat ID -> book awaiting highlight text
+}
+
+// NewBot creates a new bot
+func NewBot(store Store, settingsStore *settingsstore.SettingsStore, client *Client) *Bot {
+	return &Bot{
+		store:         store,
+		settingsStore: settingsStore,
+		client:        client,
+		captures:      make(map[int64]uint),
+	}
+}
+
+// Run long-polls Telegram for messages until ctx is cancelled. Settings are
+// re-read on every poll, so enabling the bot or changing the token in the
+// settings page takes effect without a restart.
+func (b *Bot) Run(ctx context.Context) {
+	log.Printf("Telegram bot: worker started")
+	defer log.Printf("Telegram bot: worker stopped")
+
+	for {
+		wait := b.poll(ctx)
+		if wait == 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// poll fetches and handles one batch of updates, returning how long to wait
+// before the next poll
+func (b *Bot) poll(ctx context.Context) time.Duration {
+	config := b.settingsStore.GetTelegramConfig()
+	if !config.Enabled || config.Token == "" {
+		return idlePollInterval
+	}
+
+	updates, err := b.client.GetUpdates(ctx, config.Token, b.settingsStore.GetTelegramUpdateOffset(), PollTimeout)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Telegram bot: failed to fetch updates: %v", err)
+		}
+		if errors.Is(err, ErrInvalidToken) {
+			return idlePollInterval
+		}
+		return errorPollInterval
+	}
+
+	for _, update := range updates {
+		if update.Message != nil {
+			b.handleMessage(ctx, config, update.Message)
+		}
+		if err := b.settingsStore.SetTelegramUpdateOffset(update.UpdateID + 1); err != nil {
+			log.Printf("Telegram bot: warning - failed to save update offset: %v", err)
+		}
+	}
+	return 0
+}
+
+func (b *Bot) handleMessage(ctx context.Context, config settingsstore.TelegramConfig, msg *Message) {
+	chatID := msg.Chat.ID
+	if config.ChatID == 0 || chatID != config.ChatID {
+		b.reply(ctx, config.Token, chatID, fmt.Sprintf(
+			"This chat is not authorized. To use this bot, set the Telegram chat ID to %d in Settings.", chatID))
+		return
+	}
+
+	text := strings.TrimSpace(msg.Text)
+	command, args := parseCommand(text)
+
+	var response string
+	switch command {
+	case "":
+		response = b.saveCapture(chatID, text)
+	case "/start", "/help":
+		response = helpText
+	case "/random":
+		response = b.randomHighlight()
+	case "/capture":
+		response = b.startCapture(chatID, args)
+	case "/cancel":
+		b.setCapture(chatID, 0)
+		response = "Capture cancelled."
+	default:
+		response = "Unknown command.\n\n" + helpText
+	}
+	b.reply(ctx, config.Token, chatID, response)
+}
+
+func (b *Bot) randomHighlight() string {
+	highlights, err := b.store.GetRandomHighlights(1)
+	if err != nil {
+		log.Printf("Telegram bot: failed to get random highlight: %v", err)
+		return "Failed to load a highlight, please try again later."
+	}
+	if len(highlights) == 0 {
+		return "No highlights yet."
+	}
+	return formatHighlight(highlights[0])
+}
+
+// startCapture picks the book for the next message. Without arguments it lists
+// recent books; a number selects a book by ID and any other text searches titles.
+func (b *Bot) startCapture(chatID int64, args string) string {
+	if id, err := strconv.ParseUint(args, 10, 64); err == nil {
+		book, err := b.store.GetBookByID(uint(id))
+		if err != nil {
+			return fmt.Sprintf("Book %d not found. Send /capture to list books.", id)
+		}
+		return b.selectBook(chatID, book)
+	}
+
+	books, err := b.store.GetAllBooks()
+	if err != nil {
+		log.Printf("Telegram bot: failed to list books: %v", err)
+		return "Failed to load books, please try again later."
+	}
+	if len(books) == 0 {
+		return "No books yet. Add one in the web app first."
+	}
+
+	if args != "" {
+		query := strings.ToLower(args)
+		matches := books[:0]
+		for _, book := range books {
+			if strings.Contains(strings.ToLower(book.Title), query) {
+				matches = append(matches, book)
+			}
+		}
+		if len(matches) == 0 {
+			return fmt.Sprintf("No books match %q.", args)
+		}
+		if len(matches) == 1 {
+			return b.selectBook(chatID, &matches[0])
+		}
+		books = matches
+	} else {
+		sort.Slice(books, func(i, j int) bool {
+			return books[i].UpdatedAt.After(books[j].UpdatedAt)
+		})
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Pick a book with /capture <number>:\n")
+	for i, book := range books {
+		if i == maxBookList {
+			fmt.Fprintf(&sb, "\n\n…and %d more. Use /capture <title> to search.", len(books)-maxBookList)
+			break
+		}
+		fmt.Fprintf(&sb, "\n%d. %s", book.ID, bookLabel(&book))
+	}
+	return sb.String()
+}
+
+func (b *Bot) selectBook(chatID int64, book *entities.Book) string {
+	b.setCapture(chatID, book.ID)
+	return fmt.Sprintf("Send the highlight text for %s, or /cancel.", bookLabel(book))
+}
+
+// saveCapture stores a plain message as a highlight of the selected book
+func (b *Bot) saveCapture(chatID int64, text string) string {
+	b.mu.Lock()
+	bookID := b.captures[chatID]
+	b.mu.Unlock()
+
+	if bookID == 0 {
+		return "Send /capture to pick a book first.\n\n" + helpText
+	}
+	if text == "" {
+		return "Only text messages can be saved as highlights."
+	}
+
+	book, err := b.store.GetBookByID(bookID)
+	if err != nil {
+		b.setCapture(chatID, 0)
+		return "The selected book no longer exists. Send /capture to pick another."
+	}
+
+	highlight := &entities.Highlight{
+		BookID:        book.ID,
+		UserID:        book.UserID,
+		Text:          text,
+		Style:         entities.HighlightStyleHighlight,
+		LocationType:  entities.LocationTypeNone,
+		HighlightedAt: time.Now(),
+		Source:        entities.Source{Name: "manual"},
+	}
+	if err := b.store.CreateHighlight(highlight); err != nil {
+		log.Printf("Telegram bot: failed to save highlight for book %d: %v", book.ID, err)
+		return "Failed to save the highlight, please try again."
+	}
+
+	b.setCapture(chatID, 0)
+	return fmt.Sprintf("Saved to %s.", bookLabel(book))
+}
+
+func (b *Bot) setCapture(chatID int64, bookID uint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if bookID == 0 {
+		delete(b.captures, chatID)
+		return
+	}
+	b.captures[chatID] = bookID
+}
+
+// SendDailyReview sends a few random highlights to the configured chat
+func (b *Bot) SendDailyReview(ctx context.Context) error {
+	config := b.settingsStore.GetTelegramConfig()
+	if config.Token == "" || config.ChatID == 0 {
+		return errors.New("telegram token or chat ID not configured")
+	}
+
+	highlights, err := b.store.GetRandomHighlights(DailyReviewSize)
+	if err != nil {
+		return fmt.Errorf("failed to get highlights: %w", err)
+	}
+	if len(highlights) == 0 {
+		return nil
+	}
+
+	parts := make([]string, 0, len(highlights)+1)
+	prefs := b.settingsStore.GetDefaultPreferences()
+	parts = append(parts, "Daily review · "+prefs.FormatDate(prefs.Now()))
+	for _, highlight := range highlights {
+		parts = append(parts, formatHighlight(highlight))
+	}
+	return b.client.SendMessage(ctx, config.Token, config.ChatID, strings.Join(parts, "\n\n———\n\n"))
+}
+
+func (b *Bot) reply(ctx context.Context, token string, chatID int64, text string) {
+	if err := b.client.SendMessage(ctx, token, chatID, text); err != nil {
+		log.Printf("Telegram bot: failed to send message: %v", err)
+	}
+}
+
+// parseCommand splits "/command@botname args" into the command and its
+// arguments. The command is empty for plain messages.
+func parseCommand(text string) (string, string) {
+	if !strings.HasPrefix(text, "/") {
+		return "", ""
+	}
+	command, args, _ := strings.Cut(text, " ")
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), strings.TrimSpace(args)
+}
+
+func formatHighlight(highlight entities.Highlight) string {
+	var sb strings.Builder
+	sb.WriteString(highlight.Text)
+	if highlight.Note != "" {
+		sb.WriteString("\n\nNote: " + highlight.Note)
+	}
+	sb.WriteString("\n\n— " + bookLabel(&highlight.Book))
+	return sb.String()
+}
+
+func bookLabel(book *entities.Book) string {
+	if book.Author == "" {
+		return book.Title
+	}
+	return book.Title + ", " + book.Author
+}
@@ -0,0 +1,212 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/crypto"
+	"github.com/mrlokans/assistant/internal/database"
+	"github.com/mrlokans/assistant/internal/entities"
+	"github.com/mrlokans/assistant/internal/settingsstore"
+)
+
+const testToken = "123456:test-token"
+
+// fakeTelegram serves queued updates from getUpdates and records sent messages.
+type fakeTelegram struct {
+	mu      sync.Mutex
+	updates []Update
+	sent    []sentMessage
+}
+
+type sentMessage struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.URL.Path, "/bot"+testToken+"/") {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 401, "description": "Unauthorized"})
+		return
+	}
+
+	var result any = true
+	switch strings.TrimPrefix(r.URL.Path, "/bot"+testToken+"/") {
+	case "getUpdates":
+		var params struct {
+			Offset int64 `json:"offset"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		pending := []Update{}
+		for _, update := range f.updates {
+			if update.UpdateID >= params.Offset {
+				pending = append(pending, update)
+			}
+		}
+		result = pending
+	case "sendMessage":
+		var msg sentMessage
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		f.sent = append(f.sent, msg)
+	case "getMe":
+		result = User{ID: 1, Username: "highlights_bot"}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func (f *fakeTelegram) send(updateID, chatID int64, text string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, Update{UpdateID: updateID, Message: &Message{Chat: Chat{ID: chatID}, Text: text}})
+}
+
+func (f *fakeTelegram) lastMessage(t *testing.T) sentMessage {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.NotEmpty(t, f.sent)
+	return f.sent[len(f.sent)-1]
+}
+
+func setupBot(t *testing.T) (*Bot, *fakeTelegram, *database.Database, *entities.Book) {
+	t.Helper()
+	db, err := database.NewDatabase(filepath.Join(t.TempDir(), "telegram.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	book := &entities.Book{
+		Title:      "Meditations",
+		Author:     "Marcus Aurelius",
+		Highlights: []entities.Highlight{{Text: "The impediment to action advances action."}},
+	}
+	require.NoError(t, db.SaveBook(book))
+
+	key, err := crypto.GenerateKeyBytes()
+	require.NoError(t, err)
+	encryptor, err := crypto.NewEncryptor(key)
+	require.NoError(t, err)
+
+	store := settingsstore.New(db).WithEncryptor(encryptor)
+	require.NoError(t, store.SetTelegramEnabled(true))
+	require.NoError(t, store.SetTelegramToken(testToken))
+	require.NoError(t, store.SetTelegramChatID(42))
+
+	fake := &fakeTelegram{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := NewClient()
+	client.baseURL = server.URL
+	return NewBot(db, store, client), fake, db, book
+}
+
+func TestBot_Random(t *testing.T) {
+	bot, fake, _, _ := setupBot(t)
+
+	fake.send(1, 42, "/random")
+	assert.Zero(t, bot.poll(context.Background()))
+
+	msg := fake.lastMessage(t)
+	assert.Equal(t, int64(42), msg.ChatID)
+	assert.Contains(t, msg.Text, "The impediment to action advances action.")
+	assert.Contains(t, msg.Text, "Meditations, Marcus Aurelius")
+	assert.Equal(t, int64(2), bot.settingsStore.GetTelegramUpdateOffset())
+}
+
+func TestBot_RejectsOtherChats(t *testing.T) {
+	bot, fake, _, _ := setupBot(t)
+
+	fake.send(1, 99, "/random")
+	bot.poll(context.Background())
+
+	msg := fake.lastMessage(t)
+	assert.Equal(t, int64(99), msg.ChatID)
+	assert.Contains(t, msg.Text, "not authorized")
+	assert.Contains(t, msg.Text, "99")
+}
+
+func TestBot_Capture(t *testing.T) {
+	bot, fake, db, book := setupBot(t)
+	ctx := context.Background()
+
+	fake.send(1, 42, "Text without a book")
+	bot.poll(ctx)
+	assert.Contains(t, fake.lastMessage(t).Text, "/capture to pick a book first")
+
+	fake.send(2, 42, "/capture medit")
+	bot.poll(ctx)
+	assert.Contains(t, fake.lastMessage(t).Text, "Send the highlight text for Meditations")
+
+	fake.send(3, 42, "You have power over your mind, not outside events.")
+	bot.poll(ctx)
+	assert.Equal(t, "Saved to Meditations, Marcus Aurelius.", fake.lastMessage(t).Text)
+
+	highlights, err := db.GetHighlightsForBook(book.ID)
+	require.NoError(t, err)
+	require.Len(t, highlights, 2)
+
+	// The selection is cleared after saving
+	fake.send(4, 42, "Another message")
+	bot.poll(ctx)
+	assert.Contains(t, fake.lastMessage(t).Text, "/capture to pick a book first")
+}
+
+func TestBot_CaptureListsBooks(t *testing.T) {
+	bot, fake, _, book := setupBot(t)
+
+	fake.send(1, 42, "/capture@highlights_bot")
+	bot.poll(context.Background())
+
+	text := fake.lastMessage(t).Text
+	assert.Contains(t, text, "/capture <number>")
+	assert.Contains(t, text, fmt.Sprintf("%d. Meditations, Marcus Aurelius", book.ID))
+}
+
+func TestBot_SendDailyReview(t *testing.T) {
+	bot, fake, _, _ := setupBot(t)
+
+	require.NoError(t, bot.SendDailyReview(context.Background()))
+
+	msg := fake.lastMessage(t)
+	assert.Equal(t, int64(42), msg.ChatID)
+	assert.True(t, strings.HasPrefix(msg.Text, "Daily review"))
+	assert.Contains(t, msg.Text, "Meditations")
+}
+
+func TestBot_IdlesWhenDisabled(t *testing.T) {
+	bot, fake, _, _ := setupBot(t)
+	require.NoError(t, bot.settingsStore.SetTelegramEnabled(false))
+
+	fake.send(1, 42, "/random")
+	assert.Equal(t, idlePollInterval, bot.poll(context.Background()))
+	assert.Empty(t, fake.sent)
+}
+
+func TestClient_InvalidToken(t *testing.T) {
+	server := httptest.NewServer(&fakeTelegram{})
+	defer server.Close()
+
+	client := NewClient()
+	client.baseURL = server.URL
+
+	_, err := client.GetMe(context.Background(), "wrong")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	user, err := client.GetMe(context.Background(), testToken)
+	require.NoError(t, err)
+	assert.Equal(t, "highlights_bot", user.Username)
+}
@@ -0,0 +1,157 @@
+// Package telegram implements a small Telegram bot for reviewing and capturing
+// highlights from a phone: /random sends a random highlight, /capture adds a
+// highlight to a chosen book, and a scheduled daily review sends a few
+// highlights to the configured chat.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	apiBaseURL = "https://api.telegram.org"
+
+	// PollTimeout is how long a getUpdates request waits for new messages.
+	PollTimeout = 50 * time.Second
+
+	// maxMessageLength is the Telegram limit for a single message, in characters.
+	maxMessageLength = 4096
+)
+
+var ErrInvalidToken = errors.New("invalid Telegram bot token")
+
+// Client interfaces with the Telegram Bot API
+type Client struct {
+	httpClient *http.Client
+	baseURL    string // Overrides apiBaseURL (used in tests)
+}
+
+// NewClient creates a new Telegram Bot API client
+func NewClient() *Client {
+	return &Client{
+		// Long enough for a long-polling getUpdates request
+		httpClient: &http.Client{Timeout: PollTimeout + 10*time.Second},
+		baseURL:    apiBaseURL,
+	}
+}
+
+// Update is an incoming update from getUpdates. Only messages are requested.
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// Message is a Telegram chat message
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+// Chat identifies the conversation a message belongs to
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// User is the bot account returned by getMe
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+}
+
+// GetMe returns the bot account, which also checks that the token is valid
+func (c *Client) GetMe(ctx context.Context, token string) (*User, error) {
+	var user User
+	if err := c.call(ctx, token, "getMe", nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUpdates long-polls for messages with an ID of at least offset
+func (c *Client) GetUpdates(ctx context.Context, token string, offset int64, timeout time.Duration) ([]Update, error) {
+	params := map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}
+	var updates []Update
+	if err := c.call(ctx, token, "getUpdates", params, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// SendMessage sends a plain text message to a chat, truncating it to the
+// Telegram message length limit
+func (c *Client) SendMessage(ctx context.Context, token string, chatID int64, text string) error {
+	if runes := []rune(text); len(runes) > maxMessageLength {
+		text = string(runes[:maxMessageLength-1]) + "…"
+	}
+	params := map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}
+	return c.call(ctx, token, "sendMessage", params, nil)
+}
+
+func (c *Client) call(ctx context.Context, token, method string, params any, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	endpoint := c.baseURL + "/bot" + token + "/" + method
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// The URL contains the token, so report only the method
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	// Telegram answers unknown tokens with 401 or 404
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusNotFound {
+		return ErrInvalidToken
+	}
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("%s: unexpected status %d", method, resp.StatusCode)
+	}
+	if !apiResp.OK {
+		return fmt.Errorf("%s failed: %s (code %d)", method, apiResp.Description, apiResp.ErrorCode)
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(apiResp.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	return nil
+}
@@ -31,16 +31,9 @@ type Config struct {
 }
 
 func New(cfg Config) (*TokenStore, error) {
-	// Resolve encryption key
-	key, err := resolveEncryptionKey(cfg)
+	encryptor, err := NewEncryptor(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve encryption key: %w", err)
-	}
-
-	// Create encryptor
-	encryptor, err := crypto.NewEncryptorFromBase64(key)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create encryptor: %w", err)
+		return nil, err
 	}
 
 	// Open database
@@ -62,6 +55,21 @@ func New(cfg Config) (*TokenStore, error) {
 	}, nil
 }
 
+// NewEncryptor creates an encryptor with the token encryption key, so other
+// stores can protect secrets with the same key. DatabasePath is not used.
+func NewEncryptor(cfg Config) (*crypto.Encryptor, error) {
+	key, err := resolveEncryptionKey(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve encryption key: %w", err)
+	}
+
+	encryptor, err := crypto.NewEncryptorFromBase64(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encryptor: %w", err)
+	}
+	return encryptor, nil
+}
+
 // Key priority: explicit config > env var > key file (auto-generated if missing)
 func resolveEncryptionKey(cfg Config) (string, error) {
 	// Priority 1: Explicitly provided key
@@ -0,0 +1,108 @@
+package tts
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cache keeps the spoken MP3 of each highlight on disk. Files are named after
+// the highlight and a hash of what was spoken, so editing a highlight or its
+// note produces a new file and the old one is removed.
+type Cache struct {
+	cacheDir string
+	engine   Engine
+	variant  string
+
+	// Highlights are spoken one at a time, so a podcast app downloading a
+	// whole feed does not overload the backend.
+	mu sync.Mutex
+}
+
+// NewCache creates a cache at the specified directory. Variant is mixed into
+// the file names, e.g. the voice, so changing it produces new files.
+func NewCache(cacheDir string, engine Engine, variant string) (*Cache, error) {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	return &Cache{cacheDir: cacheDir, engine: engine, variant: variant}, nil
+}
+
+// Audio returns the path of the MP3 for a highlight, speaking the script
+// first when it is not cached.
+func (c *Cache) Audio(ctx context.Context, highlightID uint, script string) (string, error) {
+	path := c.path(highlightID, script)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Spoken while waiting for the lock
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	audio, err := c.engine.Synthesize(ctx, script)
+	if err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so a partial file is never served
+	tmp, err := os.CreateTemp(c.cacheDir, "tts-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(audio); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("write audio: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("write audio: %w", err)
+	}
+
+	if err := c.Invalidate(highlightID); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("save audio: %w", err)
+	}
+	return path, nil
+}
+
+// Size returns the size of the cached MP3 for a highlight, 0 when it has not
+// been spoken yet.
+func (c *Cache) Size(highlightID uint, script string) int64 {
+	info, err := os.Stat(c.path(highlightID, script))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// Invalidate removes the cached MP3 files of a highlight.
+func (c *Cache) Invalidate(highlightID uint) error {
+	pattern := filepath.Join(c.cacheDir, fmt.Sprintf("highlight_%d_*.mp3", highlightID))
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+
+	for _, match := range matches {
+		if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Cache) path(highlightID uint, script string) string {
+	hash := sha256.Sum256([]byte(c.variant + "\x00" + script))
+	return filepath.Join(c.cacheDir, fmt.Sprintf("highlight_%d_%x.mp3", highlightID, hash[:8]))
+}
@@ -0,0 +1,48 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// CommandEngine runs a shell command that reads the text on stdin and writes
+// MP3 audio to stdout, e.g. "espeak-ng --stdout | lame --quiet - -".
+type CommandEngine struct {
+	command string
+	timeout time.Duration
+}
+
+// NewCommandEngine creates an engine running command with sh -c.
+func NewCommandEngine(command string, timeout time.Duration) *CommandEngine {
+	return &CommandEngine{command: command, timeout: timeout}
+}
+
+// Synthesize implements Engine.
+func (e *CommandEngine) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", e.command)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("tts command: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("tts command: %w", err)
+	}
+
+	if stdout.Len() == 0 {
+		return nil, ErrNoAudio
+	}
+	return stdout.Bytes(), nil
+}
+
+// Compile-time interface check
+var _ Engine = (*CommandEngine)(nil)
@@ -0,0 +1,124 @@
+package tts
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Feed is a podcast whose episodes are spoken highlights.
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // Page of the book or tag in the web UI
+	ImageURL    string // Cover art, optional
+	Author      string
+	Episodes    []Episode
+}
+
+// Episode is one spoken highlight.
+type Episode struct {
+	GUID        string
+	Title       string
+	Description string
+	AudioURL    string
+	Size        int64 // Bytes of the MP3, 0 when not spoken yet
+	Published   time.Time
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	ITunes  string     `xml:"xmlns:itunes,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Generator   string    `xml:"generator"`
+	LastBuild   string    `xml:"lastBuildDate,omitempty"`
+	Author      string    `xml:"itunes:author,omitempty"`
+	Image       *rssImage `xml:"itunes:image,omitempty"`
+	Category    rssITunes `xml:"itunes:category"`
+	Explicit    string    `xml:"itunes:explicit"`
+	Block       string    `xml:"itunes:block"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type rssITunes struct {
+	Text string `xml:"text,attr"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Description string       `xml:"description,omitempty"`
+	GUID        rssGUID      `xml:"guid"`
+	PubDate     string       `xml:"pubDate,omitempty"`
+	Enclosure   rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// WriteRSS writes the feed as an RSS 2.0 podcast feed.
+func (f *Feed) WriteRSS(w io.Writer) error {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		Author:      f.Author,
+		Generator:   "Book Highlights Manager",
+		Explicit:    "false",
+		// Private feeds must not be listed in podcast directories
+		Block:    "yes",
+		Category: rssITunes{Text: "Education"},
+	}
+	if f.ImageURL != "" {
+		channel.Image = &rssImage{Href: f.ImageURL}
+	}
+
+	var latest time.Time
+	for _, episode := range f.Episodes {
+		item := rssItem{
+			Title:       episode.Title,
+			Description: episode.Description,
+			GUID:        rssGUID{Value: episode.GUID},
+			Enclosure:   rssEnclosure{URL: episode.AudioURL, Length: episode.Size, Type: "audio/mpeg"},
+		}
+		if !episode.Published.IsZero() {
+			item.PubDate = episode.Published.UTC().Format(time.RFC1123Z)
+			if episode.Published.After(latest) {
+				latest = episode.Published
+			}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	if !latest.IsZero() {
+		channel.LastBuild = latest.UTC().Format(time.RFC1123Z)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(rss{
+		Version: "2.0",
+		ITunes:  "http://www.itunes.com/dtds/podcast-1.0.dtd",
+		Channel: channel,
+	})
+}
@@ -0,0 +1,87 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxAudioSize caps the audio read from the speech service.
+const maxAudioSize = 64 << 20
+
+// HTTPEngine sends text to a speech service with the request format of
+// OpenAI's /v1/audio/speech endpoint, which local servers such as
+// openedai-speech and Kokoro-FastAPI also accept. The response body is the
+// MP3 audio.
+type HTTPEngine struct {
+	url    string
+	token  string
+	model  string
+	voice  string
+	client *http.Client
+}
+
+// NewHTTPEngine creates an engine for the service at url. The token, if set,
+// is sent as a bearer token.
+func NewHTTPEngine(url, token, model, voice string, timeout time.Duration) *HTTPEngine {
+	if model == "" {
+		model = "tts-1"
+	}
+	if voice == "" {
+		voice = "alloy"
+	}
+	return &HTTPEngine{
+		url:    url,
+		token:  token,
+		model:  model,
+		voice:  voice,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Synthesize implements Engine.
+func (e *HTTPEngine) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{
+		"model":           e.model,
+		"voice":           e.voice,
+		"input":           text,
+		"response_format": "mp3",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("speech service request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAudioSize))
+	if err != nil {
+		return nil, fmt.Errorf("read speech service response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("speech service returned status %d", resp.StatusCode)
+	}
+	if len(data) == 0 {
+		return nil, ErrNoAudio
+	}
+	return data, nil
+}
+
+// Compile-time interface check
+var _ Engine = (*HTTPEngine)(nil)
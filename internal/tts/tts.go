@@ -0,0 +1,67 @@
+// Package tts turns highlights into spoken MP3 files and podcast feeds, so
+// highlights can be listened to like episodes of a podcast.
+//
+// Two backends are supported:
+//   - command: runs a shell command reading text on stdin and writing MP3 to stdout
+//   - http: posts the text to an OpenAI-compatible speech endpoint
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+// Backend names accepted in the TTS_BACKEND setting.
+const (
+	BackendCommand = "command"
+	BackendHTTP    = "http"
+)
+
+// ErrNoAudio is returned when a backend produced no audio.
+var ErrNoAudio = errors.New("no audio produced")
+
+// Engine speaks text as MP3 audio.
+type Engine interface {
+	Synthesize(ctx context.Context, text string) ([]byte, error)
+}
+
+// New creates the engine selected by cfg.Backend.
+// Returns nil without an error when text-to-speech is not configured.
+func New(cfg config.TTS) (Engine, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+
+	switch strings.ToLower(cfg.Backend) {
+	case "":
+		return nil, nil
+	case BackendCommand:
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("TTS_COMMAND is required for the %s backend", BackendCommand)
+		}
+		return NewCommandEngine(cfg.Command, timeout), nil
+	case BackendHTTP:
+		if cfg.ServiceURL == "" {
+			return nil, fmt.Errorf("TTS_SERVICE_URL is required for the %s backend", BackendHTTP)
+		}
+		return NewHTTPEngine(cfg.ServiceURL, cfg.ServiceToken, cfg.Model, cfg.Voice, timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown TTS backend: %s", cfg.Backend)
+	}
+}
+
+// Script is what is read out for a highlight: its text, then its note.
+func Script(highlight *entities.Highlight) string {
+	text := strings.Join(strings.Fields(highlight.Text), " ")
+	if note := strings.Join(strings.Fields(highlight.Note), " "); note != "" {
+		text += "\n\nNote: " + note
+	}
+	return text
+}
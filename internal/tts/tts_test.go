@@ -0,0 +1,149 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrlokans/assistant/internal/config"
+	"github.com/mrlokans/assistant/internal/entities"
+)
+
+type fakeEngine struct {
+	calls int
+}
+
+func (e *fakeEngine) Synthesize(_ context.Context, text string) ([]byte, error) {
+	e.calls++
+	return []byte("ID3" + text), nil
+}
+
+func TestNew(t *testing.T) {
+	engine, err := New(config.TTS{})
+	assert.NoError(t, err)
+	assert.Nil(t, engine)
+
+	_, err = New(config.TTS{Backend: BackendCommand})
+	assert.Error(t, err)
+
+	_, err = New(config.TTS{Backend: BackendHTTP})
+	assert.Error(t, err)
+
+	_, err = New(config.TTS{Backend: "festival"})
+	assert.Error(t, err)
+
+	engine, err = New(config.TTS{Backend: BackendCommand, Command: "cat"})
+	require.NoError(t, err)
+	assert.IsType(t, &CommandEngine{}, engine)
+}
+
+func TestScript(t *testing.T) {
+	assert.Equal(t, "The quick brown fox.", Script(&entities.Highlight{Text: "  The quick\nbrown  fox. "}))
+	assert.Equal(t, "Text.\n\nNote: Remember this.", Script(&entities.Highlight{Text: "Text.", Note: "Remember  this."}))
+}
+
+func TestCommandEngine(t *testing.T) {
+	engine := NewCommandEngine("tr a-z A-Z", 10*time.Second)
+	audio, err := engine.Synthesize(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", string(audio))
+
+	_, err = NewCommandEngine("true", 10*time.Second).Synthesize(context.Background(), "hello")
+	assert.ErrorIs(t, err, ErrNoAudio)
+
+	_, err = NewCommandEngine("echo broken >&2; exit 1", 10*time.Second).Synthesize(context.Background(), "hello")
+	assert.ErrorContains(t, err, "broken")
+}
+
+func TestHTTPEngine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "tts-1", body["model"])
+		assert.Equal(t, "nova", body["voice"])
+		assert.Equal(t, "mp3", body["response_format"])
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte("ID3" + body["input"]))
+	}))
+	defer server.Close()
+
+	engine := NewHTTPEngine(server.URL, "secret", "", "nova", 10*time.Second)
+	audio, err := engine.Synthesize(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "ID3hello", string(audio))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	_, err = NewHTTPEngine(failing.URL, "", "", "", 10*time.Second).Synthesize(context.Background(), "hello")
+	assert.Error(t, err)
+}
+
+func TestCache(t *testing.T) {
+	engine := &fakeEngine{}
+	cache, err := NewCache(t.TempDir(), engine, "alloy")
+	require.NoError(t, err)
+
+	assert.Zero(t, cache.Size(1, "first"))
+
+	path, err := cache.Audio(context.Background(), 1, "first")
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "ID3first", string(data))
+	assert.Equal(t, int64(len(data)), cache.Size(1, "first"))
+
+	// Served from the cache
+	again, err := cache.Audio(context.Background(), 1, "first")
+	require.NoError(t, err)
+	assert.Equal(t, path, again)
+	assert.Equal(t, 1, engine.calls)
+
+	// An edited highlight replaces the old file
+	edited, err := cache.Audio(context.Background(), 1, "edited")
+	require.NoError(t, err)
+	assert.NotEqual(t, path, edited)
+	assert.NoFileExists(t, path)
+	assert.Equal(t, 2, engine.calls)
+}
+
+func TestFeed_WriteRSS(t *testing.T) {
+	feed := &Feed{
+		Title:       "Highlights: Dune",
+		Description: "Highlights of Dune by Frank Herbert",
+		Link:        "https://example.com/ui/books/1",
+		ImageURL:    "https://example.com/cover.jpg",
+		Author:      "Frank Herbert",
+		Episodes: []Episode{{
+			GUID:      "highlight-7",
+			Title:     "Fear is the mind-killer",
+			AudioURL:  "https://example.com/podcast/highlights/7/audio.mp3",
+			Size:      1234,
+			Published: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, feed.WriteRSS(&buf))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "<?xml"))
+	assert.Contains(t, out, `xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"`)
+	assert.Contains(t, out, "<title>Highlights: Dune</title>")
+	assert.Contains(t, out, `<itunes:image href="https://example.com/cover.jpg"></itunes:image>`)
+	assert.Contains(t, out, `<enclosure url="https://example.com/podcast/highlights/7/audio.mp3" length="1234" type="audio/mpeg"></enclosure>`)
+	assert.Contains(t, out, `<guid isPermaLink="false">highlight-7</guid>`)
+	assert.Contains(t, out, "<pubDate>Fri, 01 Mar 2024 10:00:00 +0000</pubDate>")
+	assert.Contains(t, out, "<itunes:block>yes</itunes:block>")
+}
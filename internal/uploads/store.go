@@ -0,0 +1,460 @@
+package uploads
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/storage"
+)
+
+var (
+	ErrNotFound       = errors.New("upload not found")
+	ErrOffsetMismatch = errors.New("upload offset mismatch")
+	ErrTooLarge       = errors.New("upload exceeds declared size")
+	ErrIncomplete     = errors.New("upload is not complete")
+	ErrInvalidSize    = errors.New("invalid upload size")
+	ErrBusy           = errors.New("upload is receiving another chunk")
+	ErrQuotaExceeded  = errors.New("upload quota exceeded")
+)
+
+var uploadIDPattern = regexp.MustCompile(`^[a-f0-9]{32}$`)
+
+// Upload describes a resumable upload. Data is appended chunk by chunk until
+// Offset reaches Size.
+type Upload struct {
+	ID        string    `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Kind      string    `json:"kind"`
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Complete reports whether all declared bytes have been received.
+func (u *Upload) Complete() bool {
+	return u.Offset == u.Size
+}
+
+// Store keeps partial uploads on disk so they survive restarts and can be
+// resumed from the last received byte. Each upload is a data file plus a
+// JSON metadata file in the store directory.
+type Store struct {
+	dir       string
+	maxSize   int64
+	userQuota int64 // Combined size of a user's unfinished uploads, 0 for no limit
+	mu        sync.Mutex
+	inFlight  map[string]bool // uploads currently receiving a chunk
+
+	// Completed uploads are moved here when set, such as to an S3 bucket
+	remote storage.Client
+}
+
+// RemotePrefix is the directory completed uploads are kept in when a storage
+// client is used.
+const RemotePrefix = "uploads"
+
+// NewStore creates an upload store at the specified directory.
+// maxSize limits the declared size of a single upload.
+func NewStore(dir string, maxSize int64) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create upload dir: %w", err)
+	}
+	return &Store{dir: dir, maxSize: maxSize, inFlight: make(map[string]bool)}, nil
+}
+
+// WithUserQuota limits the combined declared size of each user's unfinished
+// uploads, so one user cannot fill the disk. 0 removes the limit.
+func (s *Store) WithUserQuota(quota int64) *Store {
+	s.userQuota = quota
+	return s
+}
+
+// WithStore moves the data of completed uploads to a storage client, such as
+// an S3 bucket, until they are taken. Data of uploads still receiving chunks
+// stays on disk, as object stores cannot append to files.
+func (s *Store) WithStore(remote storage.Client) *Store {
+	s.remote = remote
+	return s
+}
+
+// MaxSize returns the largest upload the store accepts.
+func (s *Store) MaxSize() int64 {
+	return s.maxSize
+}
+
+// UserQuota returns how much space each user's unfinished uploads may take, 0 for no limit.
+func (s *Store) UserQuota() int64 {
+	return s.userQuota
+}
+
+// Create registers a new upload of the given size for a user and creates its
+// empty data file. Returns ErrQuotaExceeded when the user's other unfinished
+// uploads leave too little of their quota.
+func (s *Store) Create(userID uint, kind, filename string, size int64) (*Upload, error) {
+	if size <= 0 || size > s.maxSize {
+		return nil, ErrInvalidSize
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	upload := &Upload{
+		ID:        id,
+		UserID:    userID,
+		Kind:      kind,
+		Filename:  filepath.Base(filename),
+		Size:      size,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.userQuota > 0 {
+		used, err := s.usage(userID)
+		if err != nil {
+			return nil, err
+		}
+		if used+size > s.userQuota {
+			return nil, ErrQuotaExceeded
+		}
+	}
+
+	file, err := os.OpenFile(s.dataPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("create upload file: %w", err)
+	}
+	file.Close()
+
+	if err := s.writeMeta(upload); err != nil {
+		os.Remove(s.dataPath(id))
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Get returns the upload with the given ID.
+func (s *Store) Get(id string) (*Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readMeta(id)
+}
+
+// WriteChunk appends data from r at offset, which must equal the number of
+// bytes received so far. Data beyond the declared size is rejected. A chunk
+// interrupted mid-stream keeps the bytes that arrived, so the client can
+// resume from the returned offset.
+func (s *Store) WriteChunk(id string, offset int64, r io.Reader) (*Upload, error) {
+	s.mu.Lock()
+	upload, err := s.readMeta(id)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	if s.inFlight[id] {
+		s.mu.Unlock()
+		return upload, ErrBusy
+	}
+	if offset != upload.Offset {
+		s.mu.Unlock()
+		return upload, ErrOffsetMismatch
+	}
+	s.inFlight[id] = true
+	s.mu.Unlock()
+
+	// The lock is not held while copying so slow clients don't block other uploads
+	written, copyErr := s.appendData(upload, r)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.readMeta(id); err != nil {
+		delete(s.inFlight, id)
+		return nil, err // Removed while the chunk was being written
+	}
+	upload.Offset += written
+	upload.UpdatedAt = time.Now()
+	if err := s.writeMeta(upload); err != nil {
+		delete(s.inFlight, id)
+		return nil, err
+	}
+
+	if s.remote != nil && upload.Complete() {
+		// Still in flight, so the upload is not taken meanwhile
+		s.mu.Unlock()
+		s.moveToRemote(upload)
+		s.mu.Lock()
+		if _, err := s.readMeta(id); err != nil {
+			_ = s.remote.Delete(context.Background(), s.remotePath(id)) // Removed meanwhile
+		}
+	}
+	delete(s.inFlight, id)
+	return upload, copyErr
+}
+
+// moveToRemote uploads the data of a completed upload to the storage client
+// and removes it from disk. On failure the data stays on disk, where Take
+// still finds it.
+func (s *Store) moveToRemote(upload *Upload) {
+	file, err := os.Open(s.dataPath(upload.ID))
+	if err != nil {
+		log.Printf("WARNING: Failed to move upload %s to storage: %v", upload.ID, err)
+		return
+	}
+	err = s.remote.Upload(context.Background(), s.remotePath(upload.ID), file)
+	file.Close()
+	if err != nil {
+		log.Printf("WARNING: Failed to move upload %s to storage: %v", upload.ID, err)
+		return
+	}
+	os.Remove(s.dataPath(upload.ID))
+}
+
+// appendData writes r to the end of the upload's data file, stopping at the declared size.
+func (s *Store) appendData(upload *Upload, r io.Reader) (int64, error) {
+	file, err := os.OpenFile(s.dataPath(upload.ID), os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("open upload file: %w", err)
+	}
+	defer file.Close()
+
+	// Discard any bytes past the recorded offset left by a crash mid-write
+	if err := file.Truncate(upload.Offset); err != nil {
+		return 0, fmt.Errorf("truncate upload file: %w", err)
+	}
+	if _, err := file.Seek(upload.Offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("seek upload file: %w", err)
+	}
+
+	remaining := upload.Size - upload.Offset
+	written, err := io.Copy(file, io.LimitReader(r, remaining+1))
+	if written > remaining {
+		if err := file.Truncate(upload.Size); err != nil {
+			return 0, fmt.Errorf("truncate upload file: %w", err)
+		}
+		return remaining, ErrTooLarge
+	}
+	return written, err
+}
+
+// Take moves the data of a completed upload to destPath and forgets the upload.
+func (s *Store) Take(id, destPath string) (*Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, err := s.readMeta(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.inFlight[id] || !upload.Complete() {
+		return upload, ErrIncomplete
+	}
+
+	if err := s.takeData(id, destPath); err != nil {
+		return nil, fmt.Errorf("move upload file: %w", err)
+	}
+	if err := os.Remove(s.metaPath(id)); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// takeData moves the data of a completed upload to destPath, from disk or
+// from the storage client.
+func (s *Store) takeData(id, destPath string) error {
+	if _, err := os.Stat(s.dataPath(id)); err == nil || s.remote == nil {
+		return moveFile(s.dataPath(id), destPath)
+	}
+
+	ctx := context.Background()
+	if err := storage.DownloadToFile(ctx, s.remote, s.remotePath(id), destPath); err != nil {
+		return err
+	}
+	if err := s.remote.Delete(ctx, s.remotePath(id)); err != nil {
+		log.Printf("WARNING: Failed to delete upload %s from storage: %v", id, err)
+	}
+	return nil
+}
+
+// Remove deletes an upload and its data.
+func (s *Store) Remove(id string) error {
+	if !uploadIDPattern.MatchString(id) {
+		return ErrNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.metaPath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := os.Remove(s.dataPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if s.remote != nil {
+		err := s.remote.Delete(context.Background(), s.remotePath(id))
+		if err != nil && !storage.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveStale deletes uploads that have not received data for longer than maxAge.
+func (s *Store) RemoveStale(maxAge time.Duration) (int, error) {
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, match := range matches {
+		id := filepath.Base(match[:len(match)-len(".json")])
+		upload, err := s.Get(id)
+		if err != nil || upload.UpdatedAt.After(cutoff) || s.isInFlight(id) {
+			continue
+		}
+		if err := s.Remove(id); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Usage returns the combined declared size of a user's unfinished uploads.
+func (s *Store) Usage(userID uint) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usage(userID)
+}
+
+// usage must be called with s.mu held.
+func (s *Store) usage(userID uint) (int64, error) {
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	var used int64
+	for _, match := range matches {
+		upload, err := s.readMeta(strings.TrimSuffix(filepath.Base(match), ".json"))
+		if err != nil || upload.UserID != userID {
+			continue
+		}
+		used += upload.Size
+	}
+	return used, nil
+}
+
+func (s *Store) isInFlight(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inFlight[id]
+}
+
+func (s *Store) dataPath(id string) string {
+	return filepath.Join(s.dir, id+".part")
+}
+
+func (s *Store) remotePath(id string) string {
+	return path.Join(RemotePrefix, id)
+}
+
+func (s *Store) metaPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *Store) readMeta(id string) (*Upload, error) {
+	if !uploadIDPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+
+	data, err := os.ReadFile(s.metaPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("read upload metadata: %w", err)
+	}
+
+	var upload Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, fmt.Errorf("decode upload metadata: %w", err)
+	}
+	return &upload, nil
+}
+
+// writeMeta replaces the metadata file atomically so a crash never leaves it half-written.
+func (s *Store) writeMeta(upload *Upload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return fmt.Errorf("encode upload metadata: %w", err)
+	}
+
+	tmp := s.metaPath(upload.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write upload metadata: %w", err)
+	}
+	if err := os.Rename(tmp, s.metaPath(upload.ID)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write upload metadata: %w", err)
+	}
+	return nil
+}
+
+// moveFile renames src to dst, falling back to a copy when they are on different filesystems.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate upload id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
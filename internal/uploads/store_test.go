@@ -0,0 +1,284 @@
+package uploads
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrlokans/assistant/internal/storage"
+)
+
+func TestNewStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "uploads")
+
+	if _, err := NewStore(dir, 1024); err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		t.Error("upload directory was not created")
+	}
+}
+
+func TestCreate_RejectsInvalidSize(t *testing.T) {
+	store, _ := NewStore(t.TempDir(), 10)
+
+	for _, size := range []int64{0, -1, 11} {
+		if _, err := store.Create(1, "test", "file.bin", size); !errors.Is(err, ErrInvalidSize) {
+			t.Errorf("size %d: expected ErrInvalidSize, got %v", size, err)
+		}
+	}
+}
+
+func TestCreate_EnforcesUserQuota(t *testing.T) {
+	store, _ := NewStore(t.TempDir(), 10)
+	store.WithUserQuota(15)
+
+	first, err := store.Create(1, "test", "first.bin", 10)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := store.Create(1, "test", "second.bin", 6); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+
+	// Other users have a quota of their own
+	if _, err := store.Create(2, "test", "other.bin", 10); err != nil {
+		t.Errorf("Create for another user failed: %v", err)
+	}
+
+	// Finished uploads no longer count
+	if _, err := store.WriteChunk(first.ID, 0, strings.NewReader("0123456789")); err != nil {
+		t.Fatalf("WriteChunk failed: %v", err)
+	}
+	if _, err := store.Take(first.ID, filepath.Join(t.TempDir(), "first.bin")); err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	if used, _ := store.Usage(1); used != 0 {
+		t.Errorf("expected no usage after Take, got %d", used)
+	}
+	if _, err := store.Create(1, "test", "second.bin", 6); err != nil {
+		t.Errorf("Create after Take failed: %v", err)
+	}
+}
+
+func TestWriteChunk_ResumesAtOffset(t *testing.T) {
+	store, _ := NewStore(t.TempDir(), 1024)
+	dest := filepath.Join(t.TempDir(), "file.bin")
+
+	upload, err := store.Create(1, "test", "../../file.bin", 11)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if upload.Filename != "file.bin" {
+		t.Errorf("expected filename to be sanitized, got %s", upload.Filename)
+	}
+
+	upload, err = store.WriteChunk(upload.ID, 0, strings.NewReader("hello "))
+	if err != nil {
+		t.Fatalf("WriteChunk failed: %v", err)
+	}
+	if upload.Offset != 6 {
+		t.Errorf("expected offset 6, got %d", upload.Offset)
+	}
+
+	// A retried chunk at a stale offset is rejected with the current offset
+	current, err := store.WriteChunk(upload.ID, 0, strings.NewReader("hello "))
+	if !errors.Is(err, ErrOffsetMismatch) {
+		t.Fatalf("expected ErrOffsetMismatch, got %v", err)
+	}
+	if current.Offset != 6 {
+		t.Errorf("expected current offset 6, got %d", current.Offset)
+	}
+
+	if _, err := store.Take(upload.ID, dest); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("expected ErrIncomplete before the last chunk, got %v", err)
+	}
+
+	upload, err = store.WriteChunk(upload.ID, 6, strings.NewReader("world"))
+	if err != nil {
+		t.Fatalf("WriteChunk failed: %v", err)
+	}
+	if !upload.Complete() {
+		t.Error("expected upload to be complete")
+	}
+
+	if _, err := store.Take(upload.ID, dest); err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	data, _ := os.ReadFile(dest)
+	if string(data) != "hello world" {
+		t.Errorf("expected 'hello world', got %q", data)
+	}
+
+	// A taken upload is no longer tracked
+	if _, err := store.Get(upload.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after Take, got %v", err)
+	}
+}
+
+func TestWriteChunk_RejectsDataBeyondSize(t *testing.T) {
+	store, _ := NewStore(t.TempDir(), 1024)
+	upload, _ := store.Create(1, "test", "file.bin", 4)
+
+	upload, err := store.WriteChunk(upload.ID, 0, strings.NewReader("too long"))
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if upload.Offset != 4 {
+		t.Errorf("expected offset capped at 4, got %d", upload.Offset)
+	}
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if _, err := store.Take(upload.ID, dest); err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	data, _ := os.ReadFile(dest)
+	if string(data) != "too " {
+		t.Errorf("expected data truncated to declared size, got %q", data)
+	}
+}
+
+func TestGet_UnknownOrInvalidID(t *testing.T) {
+	store, _ := NewStore(t.TempDir(), 1024)
+
+	for _, id := range []string{"0123456789abcdef0123456789abcdef", "../../etc/passwd"} {
+		if _, err := store.Get(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("id %q: expected ErrNotFound, got %v", id, err)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewStore(dir, 1024)
+	upload, _ := store.Create(1, "test", "file.bin", 4)
+
+	if err := store.Remove(upload.ID); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := store.Get(upload.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after removal, got %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected upload files to be deleted, found %d", len(entries))
+	}
+}
+
+func TestRemoveStale(t *testing.T) {
+	store, _ := NewStore(t.TempDir(), 1024)
+	stale, _ := store.Create(1, "test", "old.bin", 4)
+	fresh, _ := store.Create(1, "test", "new.bin", 4)
+
+	// Backdate the stale upload
+	upload, _ := store.Get(stale.ID)
+	upload.UpdatedAt = time.Now().Add(-48 * time.Hour)
+	if err := store.writeMeta(upload); err != nil {
+		t.Fatalf("writeMeta failed: %v", err)
+	}
+
+	removed, err := store.RemoveStale(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("RemoveStale failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 stale upload removed, got %d", removed)
+	}
+	if _, err := store.Get(stale.ID); !errors.Is(err, ErrNotFound) {
+		t.Error("expected stale upload to be removed")
+	}
+	if _, err := store.Get(fresh.ID); err != nil {
+		t.Errorf("expected fresh upload to be kept, got %v", err)
+	}
+}
+
+// memoryStorage is a storage.Client keeping files in a map.
+type memoryStorage struct {
+	files map[string][]byte
+}
+
+func (m *memoryStorage) List(ctx context.Context, path string) ([]storage.FileInfo, error) {
+	return nil, nil
+}
+
+func (m *memoryStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	data, ok := m.files[path]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryStorage) Upload(ctx context.Context, path string, content io.Reader) error {
+	data, err := io.ReadAll(content)
+	m.files[path] = data
+	return err
+}
+
+func (m *memoryStorage) Delete(ctx context.Context, path string) error {
+	delete(m.files, path)
+	return nil
+}
+
+func (m *memoryStorage) Exists(ctx context.Context, path string) (bool, error) {
+	_, ok := m.files[path]
+	return ok, nil
+}
+
+func (m *memoryStorage) GetMetadata(ctx context.Context, path string) (*storage.FileInfo, error) {
+	return nil, storage.ErrNotFound
+}
+
+func TestWithStore_MovesCompletedUploads(t *testing.T) {
+	dir := t.TempDir()
+	remote := &memoryStorage{files: map[string][]byte{}}
+	store, _ := NewStore(dir, 1024)
+	store.WithStore(remote)
+
+	upload, _ := store.Create(1, "test", "file.bin", 8)
+	if _, err := store.WriteChunk(upload.ID, 0, strings.NewReader("0123")); err != nil {
+		t.Fatalf("WriteChunk failed: %v", err)
+	}
+	if len(remote.files) != 0 {
+		t.Error("partial upload should stay on disk")
+	}
+
+	if _, err := store.WriteChunk(upload.ID, 4, strings.NewReader("4567")); err != nil {
+		t.Fatalf("WriteChunk failed: %v", err)
+	}
+	if string(remote.files["uploads/"+upload.ID]) != "01234567" {
+		t.Errorf("expected completed upload in storage, got %v", remote.files)
+	}
+	if _, err := os.Stat(store.dataPath(upload.ID)); !os.IsNotExist(err) {
+		t.Error("expected data file to be removed from disk")
+	}
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if _, err := store.Take(upload.ID, dest); err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "01234567" {
+		t.Errorf("expected taken data, got %q", data)
+	}
+	if len(remote.files) != 0 {
+		t.Error("expected taken upload to be deleted from storage")
+	}
+
+	// Removing a completed upload deletes it from storage too
+	other, _ := store.Create(1, "test", "other.bin", 2)
+	_, _ = store.WriteChunk(other.ID, 0, strings.NewReader("ab"))
+	if err := store.Remove(other.ID); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if len(remote.files) != 0 {
+		t.Error("expected removed upload to be deleted from storage")
+	}
+}
@@ -3,7 +3,9 @@ package utils
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 )
 
 // InternalColorToHexARGB converts MoonReader's signed integer color representation
@@ -41,3 +43,94 @@ func ColorToCalloutType(hexColor string) string {
 	}
 	return "quote"
 }
+
+// HighlightColorNames lists the color names returned by ColorName, in display order.
+var HighlightColorNames = []string{"yellow", "orange", "red", "pink", "purple", "blue", "green"}
+
+// ColorName maps a stored highlight color to a color name so highlights from
+// different sources can be grouped and filtered by color. Sources store colors
+// as names ("yellow" from Readwise), RGB hex ("#FFFF00" from Apple Books) or
+// ARGB hex ("#FFFFFF00" from Moon+ Reader). Returns "" for empty, gray or
+// unparseable colors.
+func ColorName(color string) string {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color == "" {
+		return ""
+	}
+	for _, name := range HighlightColorNames {
+		if color == name {
+			return name
+		}
+	}
+
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 8 {
+		hex = hex[2:] // Drop the alpha channel
+	}
+	if len(hex) != 6 {
+		return ""
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return ""
+	}
+
+	r := float64((rgb>>16)&0xFF) / 255
+	g := float64((rgb>>8)&0xFF) / 255
+	b := float64(rgb&0xFF) / 255
+	hue, saturation, lightness := rgbToHSL(r, g, b)
+
+	if saturation < 0.2 {
+		return ""
+	}
+
+	switch {
+	case hue < 15 || hue >= 345:
+		// Light reds such as #FFC0CB read as pink
+		if lightness > 0.7 {
+			return "pink"
+		}
+		return "red"
+	case hue < 45:
+		return "orange"
+	case hue < 70:
+		return "yellow"
+	case hue < 170:
+		return "green"
+	case hue < 260:
+		return "blue"
+	case hue < 315:
+		return "purple"
+	default:
+		return "pink"
+	}
+}
+
+// rgbToHSL converts RGB components in [0, 1] to hue in degrees and saturation/lightness in [0, 1].
+func rgbToHSL(r, g, b float64) (float64, float64, float64) {
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	lightness := (maxC + minC) / 2
+
+	delta := maxC - minC
+	if delta == 0 {
+		return 0, 0, lightness
+	}
+
+	saturation := delta / (1 - math.Abs(2*lightness-1))
+
+	var hue float64
+	switch maxC {
+	case r:
+		hue = math.Mod((g-b)/delta, 6)
+	case g:
+		hue = (b-r)/delta + 2
+	default:
+		hue = (r-g)/delta + 4
+	}
+	hue *= 60
+	if hue < 0 {
+		hue += 360
+	}
+	return hue, saturation, lightness
+}
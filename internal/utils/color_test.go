@@ -109,3 +109,31 @@ func TestColorToCalloutType(t *testing.T) {
 		})
 	}
 }
+
+func TestColorName(t *testing.T) {
+	tests := []struct {
+		name     string
+		color    string
+		expected string
+	}{
+		{"readwise name", "Yellow", "yellow"},
+		{"apple books yellow", "#FFFF00", "yellow"},
+		{"apple books pink", "#FF69B4", "pink"},
+		{"apple books purple", "#800080", "purple"},
+		{"readwise csv pink", "#FFC0CB", "pink"},
+		{"readwise csv orange", "#FFA500", "orange"},
+		{"moonreader yellow argb", "#FFFFFF00", "yellow"},
+		{"moonreader blue argb", "#FF0000FF", "blue"},
+		{"moonreader green argb", "#FF00FF00", "green"},
+		{"red", "#FF0000", "red"},
+		{"gray is unnamed", "#808080", ""},
+		{"empty", "", ""},
+		{"invalid", "#XYZ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ColorName(tt.color))
+		})
+	}
+}
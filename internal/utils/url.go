@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// IsWebURL reports whether raw is an absolute http or https URL, such as
+// an article address, as opposed to app links like kindle:// or relative paths.
+func IsWebURL(raw string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
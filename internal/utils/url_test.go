@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsWebURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"https://example.com/article", true},
+		{"http://example.com/a?b=c#:~:text=quote", true},
+		{" https://example.com ", true},
+		{"kindle://book?action=open&asin=B00", false},
+		{"javascript:alert(1)", false},
+		{"/ui/books/1", false},
+		{"https://", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsWebURL(tt.input))
+		})
+	}
+}
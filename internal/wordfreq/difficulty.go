@@ -0,0 +1,78 @@
+package wordfreq
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Band groups Difficulty scores for filtering.
+type Band string
+
+const (
+	BandEasy   Band = "easy"   // The more common half of the frequency list
+	BandMedium Band = "medium" // The less common half of the frequency list
+	BandHard   Band = "hard"   // Not on the frequency list
+)
+
+// Bands lists the difficulty bands from easiest to hardest.
+var Bands = []Band{BandEasy, BandMedium, BandHard}
+
+const (
+	// MaxDifficulty is the highest Difficulty score.
+	MaxDifficulty = 100
+
+	easyDifficulty   = 40 // Highest score in the easy band
+	listedDifficulty = 80 // Highest score of a word on the list
+	rareDifficulty   = 85 // Lowest score of a word not on the list
+)
+
+// Difficulty scores how hard a word or phrase is likely to be, from 1 for the
+// most common word to MaxDifficulty. Words on the frequency list score up to 80
+// by rank; rare words score 85 and up, longer ones higher. A phrase scores as
+// its hardest word, and text without letters scores 0.
+func Difficulty(text string) int {
+	score := 0
+	for _, token := range tokenize(text) {
+		token = strings.Trim(token, "'’")
+		token = strings.TrimSuffix(token, "'s")
+		token = strings.TrimSuffix(token, "’s")
+		if token != "" {
+			score = max(score, wordDifficulty(token))
+		}
+	}
+	return score
+}
+
+func wordDifficulty(word string) int {
+	if rank, ok := Rank(word); ok {
+		return min(1+(rank-1)*(listedDifficulty-1)/max(Size()-1, 1), listedDifficulty)
+	}
+	return min(rareDifficulty+max(utf8.RuneCountInString(word)-6, 0), MaxDifficulty)
+}
+
+// BandOf returns the band a Difficulty score falls in.
+func BandOf(score int) Band {
+	switch {
+	case score > listedDifficulty:
+		return BandHard
+	case score > easyDifficulty:
+		return BandMedium
+	default:
+		return BandEasy
+	}
+}
+
+// Range returns the lowest and highest Difficulty scores in the band, and false
+// if the band is not one of Bands.
+func (b Band) Range() (int, int, bool) {
+	switch b {
+	case BandEasy:
+		return 1, easyDifficulty, true
+	case BandMedium:
+		return easyDifficulty + 1, listedDifficulty, true
+	case BandHard:
+		return listedDifficulty + 1, MaxDifficulty, true
+	default:
+		return 0, 0, false
+	}
+}
@@ -0,0 +1,138 @@
+package wordfreq
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Candidate is a rare word found in a piece of text.
+type Candidate struct {
+	Word       string // Lowercased word as it appeared in the text
+	Context    string // Sentence the word was found in
+	Difficulty int    // Difficulty score of the word
+}
+
+// Options controls rare-word extraction.
+type Options struct {
+	MinLength     int // Words shorter than this are ignored
+	MaxCandidates int // Maximum candidates returned per text, hardest first (0 = unlimited)
+}
+
+// DefaultOptions returns the extraction options used for highlight scanning.
+func DefaultOptions() Options {
+	return Options{
+		MinLength:     6,
+		MaxCandidates: 3,
+	}
+}
+
+// Extract returns rare words found in text, in order of appearance.
+// Proper nouns (capitalized words that don't start a sentence), contractions and
+// words on the frequency list are skipped. Each word is returned once. When
+// there are more than MaxCandidates, the hardest ones are kept.
+func Extract(text string, opts Options) []Candidate {
+	var candidates []Candidate
+	seen := make(map[string]bool)
+
+	for _, sentence := range splitSentences(text) {
+		for i, token := range tokenize(sentence) {
+			word, ok := normalizeToken(token, i == 0, opts.MinLength)
+			if !ok || seen[word] {
+				continue
+			}
+			seen[word] = true
+
+			if IsCommon(word) {
+				continue
+			}
+
+			candidates = append(candidates, Candidate{Word: word, Context: sentence, Difficulty: Difficulty(word)})
+		}
+	}
+
+	if opts.MaxCandidates > 0 && len(candidates) > opts.MaxCandidates {
+		hardest := slices.Clone(candidates)
+		slices.SortStableFunc(hardest, func(a, b Candidate) int { return b.Difficulty - a.Difficulty })
+		hardest = hardest[:opts.MaxCandidates]
+		candidates = slices.DeleteFunc(candidates, func(c Candidate) bool {
+			return !slices.ContainsFunc(hardest, func(h Candidate) bool { return h.Word == c.Word })
+		})
+	}
+
+	return candidates
+}
+
+// SentenceContaining returns the first sentence of text that contains the word
+// or phrase, ignoring case, or "" if none does.
+func SentenceContaining(text, phrase string) string {
+	phrase = strings.ToLower(strings.TrimSpace(phrase))
+	if phrase == "" {
+		return ""
+	}
+	for _, sentence := range splitSentences(text) {
+		if strings.Contains(strings.ToLower(sentence), phrase) {
+			return sentence
+		}
+	}
+	return ""
+}
+
+// normalizeToken lowercases a token and reports whether it is eligible for extraction.
+func normalizeToken(token string, sentenceStart bool, minLength int) (string, bool) {
+	token = strings.Trim(token, "'’")
+	token = strings.TrimSuffix(token, "'s")
+	token = strings.TrimSuffix(token, "’s")
+	if strings.ContainsAny(token, "'’") {
+		return "", false
+	}
+	if len([]rune(token)) < minLength {
+		return "", false
+	}
+
+	runes := []rune(token)
+	if unicode.IsUpper(runes[0]) && !sentenceStart {
+		return "", false
+	}
+	for _, r := range runes[1:] {
+		if unicode.IsUpper(r) {
+			return "", false // Acronyms and CamelCase names
+		}
+	}
+
+	return strings.ToLower(token), true
+}
+
+// tokenize splits text into letter-only tokens, keeping inner apostrophes.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\'' && r != '’'
+	})
+}
+
+// splitSentences splits text on sentence-ending punctuation.
+func splitSentences(text string) []string {
+	var sentences []string
+	var current strings.Builder
+
+	flush := func() {
+		s := strings.TrimSpace(current.String())
+		if s != "" {
+			sentences = append(sentences, s)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		current.WriteRune(r)
+		if r == '.' || r == '!' || r == '?' || r == '\n' {
+			if i+1 >= len(runes) || unicode.IsSpace(runes[i+1]) {
+				flush()
+			}
+		}
+	}
+	flush()
+
+	return sentences
+}
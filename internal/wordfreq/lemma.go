@@ -0,0 +1,148 @@
+package wordfreq
+
+import "strings"
+
+// irregularLemmas maps irregular inflections to their base form.
+var irregularLemmas = map[string]string{
+	"was": "be", "were": "be", "been": "be", "is": "be", "am": "be", "are": "be",
+	"ran": "run", "went": "go", "gone": "go", "took": "take", "taken": "take",
+	"gave": "give", "given": "give", "wrote": "write", "written": "write",
+	"spoke": "speak", "spoken": "speak", "thought": "think", "brought": "bring",
+	"bought": "buy", "caught": "catch", "taught": "teach", "sought": "seek",
+	"fought": "fight", "found": "find", "made": "make", "said": "say",
+	"knew": "know", "known": "know", "saw": "see", "seen": "see",
+	"felt": "feel", "kept": "keep", "left": "leave", "meant": "mean",
+	"began": "begin", "begun": "begin", "sang": "sing", "sung": "sing",
+	"drank": "drink", "drunk": "drink", "ate": "eat", "eaten": "eat",
+	"fell": "fall", "fallen": "fall", "chose": "choose", "chosen": "choose",
+	"broke": "break", "broken": "break", "forgot": "forget", "forgotten": "forget",
+	"held": "hold", "stood": "stand", "understood": "understand",
+	"struck": "strike", "slept": "sleep", "crept": "creep", "wept": "weep",
+	"swept": "sweep", "fled": "flee", "fed": "feed", "bled": "bleed",
+	"wove": "weave", "woven": "weave", "strove": "strive", "striven": "strive",
+	"arose": "arise", "arisen": "arise", "bore": "bear", "borne": "bear",
+	"tore": "tear", "torn": "tear", "wore": "wear", "worn": "wear",
+	"swore": "swear", "sworn": "swear", "shook": "shake", "shaken": "shake",
+	"children": "child", "men": "man", "women": "woman", "mice": "mouse",
+	"feet": "foot", "teeth": "tooth", "geese": "goose", "people": "person",
+}
+
+// Lemma returns the dictionary form of an English word or phrase, such as
+// "run" for "running" or "obfuscate" for "obfuscated", lowercased. Each word
+// of a phrase is reduced. Only plural and verb endings are removed: stripped
+// forms are checked against the frequency list first, and rarer words fall back
+// to suffix rules, which may miss some irregular spellings.
+func Lemma(text string) string {
+	words := strings.Fields(strings.ToLower(text))
+	for i, word := range words {
+		words[i] = lemmaOf(word)
+	}
+	return strings.Join(words, " ")
+}
+
+func lemmaOf(word string) string {
+	ranksOnce.Do(loadRanks)
+
+	if lemma, ok := irregularLemmas[word]; ok {
+		return lemma
+	}
+	if isListed(word) || len(word) <= 3 {
+		return word
+	}
+	if singular, ok := singularOf(word); ok {
+		return singular
+	}
+	return verbBase(word)
+}
+
+func isListed(word string) bool {
+	_, ok := ranks[word]
+	return ok
+}
+
+// singularOf strips a plural ending. Plurals are never reduced further, so
+// nouns like "herrings" and "beginnings" keep their "-ing".
+func singularOf(word string) (string, bool) {
+	switch {
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return "", false
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return strings.TrimSuffix(word, "ies") + "y", true
+	case strings.HasSuffix(word, "es"):
+		stem := strings.TrimSuffix(word, "es")
+		if isListed(stem) || strings.HasSuffix(stem, "ss") || strings.HasSuffix(stem, "ch") ||
+			strings.HasSuffix(stem, "sh") || strings.HasSuffix(stem, "x") || strings.HasSuffix(stem, "zz") {
+			return stem, true
+		}
+		return strings.TrimSuffix(word, "s"), true
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s"), true
+	}
+	return "", false
+}
+
+// verbBase strips an "-ed" or "-ing" ending, following the first steps of the
+// Porter stemmer but restoring a final "e" where the base form likely has one.
+func verbBase(word string) string {
+	var stem string
+	switch {
+	case strings.HasSuffix(word, "ied") && len(word) > 4:
+		return strings.TrimSuffix(word, "ied") + "y"
+	case strings.HasSuffix(word, "eed"):
+		return word
+	case strings.HasSuffix(word, "ed"):
+		stem = strings.TrimSuffix(word, "ed")
+	case strings.HasSuffix(word, "ing"):
+		stem = strings.TrimSuffix(word, "ing")
+	default:
+		return word
+	}
+	if len(stem) < 2 || !hasVowel(stem) {
+		return word
+	}
+
+	for _, base := range []string{undouble(stem), stem + "e", stem} {
+		if base != "" && isListed(base) {
+			return base
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(stem, "at"), strings.HasSuffix(stem, "bl"), strings.HasSuffix(stem, "iz"),
+		strings.HasSuffix(stem, "iv"), strings.HasSuffix(stem, "uc"), strings.HasSuffix(stem, "ur"):
+		return stem + "e"
+	case undouble(stem) != "" && !strings.ContainsAny(stem[len(stem)-1:], "lsz"):
+		return undouble(stem)
+	case isShortSyllable(stem):
+		return stem + "e"
+	}
+	return stem
+}
+
+func isVowel(ch byte) bool {
+	return strings.IndexByte("aeiou", ch) >= 0
+}
+
+func hasVowel(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if isVowel(s[i]) || (s[i] == 'y' && i > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// isShortSyllable reports whether a one-syllable stem ends consonant-vowel-
+// consonant, like "hop" from "hoping", so its base form ends in "e".
+func isShortSyllable(stem string) bool {
+	n := len(stem)
+	if n < 3 || n > 4 {
+		return false
+	}
+	last, vowel, first := stem[n-1], stem[n-2], stem[n-3]
+	if isVowel(last) || strings.IndexByte("wxy", last) >= 0 || !isVowel(vowel) || isVowel(first) {
+		return false
+	}
+	// A second vowel earlier on means more than one syllable
+	return n == 3 || !isVowel(stem[0])
+}
@@ -0,0 +1,133 @@
+// Package wordfreq provides an embedded English word frequency list, a
+// rare-word extractor used to suggest vocabulary entries from highlights, and
+// difficulty scores for sorting and filtering vocabulary.
+//
+// The list in data/en.txt is ordered from most to least common. Words that are
+// not on the list (after basic inflection stripping) are considered rare.
+//
+// # Usage
+//
+//	candidates := wordfreq.Extract(highlight.Text, wordfreq.DefaultOptions())
+//	for _, c := range candidates {
+//		fmt.Println(c.Word, c.Context)
+//	}
+package wordfreq
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+//go:embed data/en.txt
+var englishList string
+
+var (
+	ranksOnce sync.Once
+	ranks     map[string]int
+)
+
+func loadRanks() {
+	fields := strings.Fields(englishList)
+	ranks = make(map[string]int, len(fields))
+	for i, w := range fields {
+		w = strings.ToLower(w)
+		if _, exists := ranks[w]; !exists {
+			ranks[w] = i + 1
+		}
+	}
+}
+
+// Rank returns the 1-based frequency rank of a word and whether it is on the list.
+// Inflected forms (plurals, -ed, -ing, -ly, ...) resolve to the rank of their base form.
+func Rank(word string) (int, bool) {
+	ranksOnce.Do(loadRanks)
+
+	word = strings.ToLower(word)
+	if r, ok := ranks[word]; ok {
+		return r, true
+	}
+	for _, base := range baseForms(word) {
+		if r, ok := ranks[base]; ok {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// IsCommon reports whether a word is on the frequency list.
+func IsCommon(word string) bool {
+	_, ok := Rank(word)
+	return ok
+}
+
+// Size returns the number of distinct words on the frequency list.
+func Size() int {
+	ranksOnce.Do(loadRanks)
+	return len(ranks)
+}
+
+// baseForms returns candidate base forms for an inflected word, most likely first.
+func baseForms(word string) []string {
+	var forms []string
+	add := func(stem string) {
+		if len(stem) >= 2 {
+			forms = append(forms, stem)
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		add(strings.TrimSuffix(word, "ies") + "y")
+	case strings.HasSuffix(word, "es"):
+		add(strings.TrimSuffix(word, "es"))
+		add(strings.TrimSuffix(word, "s"))
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		add(strings.TrimSuffix(word, "s"))
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ied"):
+		add(strings.TrimSuffix(word, "ied") + "y")
+	case strings.HasSuffix(word, "ed"):
+		stem := strings.TrimSuffix(word, "ed")
+		add(stem)
+		add(stem + "e")
+		add(undouble(stem))
+	}
+
+	if strings.HasSuffix(word, "ing") {
+		stem := strings.TrimSuffix(word, "ing")
+		add(stem)
+		add(stem + "e")
+		add(undouble(stem))
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ily"):
+		add(strings.TrimSuffix(word, "ily") + "y")
+	case strings.HasSuffix(word, "ly"):
+		add(strings.TrimSuffix(word, "ly"))
+	}
+
+	for _, suffix := range []string{"est", "er"} {
+		if strings.HasSuffix(word, suffix) {
+			stem := strings.TrimSuffix(word, suffix)
+			add(stem)
+			add(stem + "e")
+			add(undouble(stem))
+			break
+		}
+	}
+
+	return forms
+}
+
+// undouble removes a doubled final consonant ("stopp" -> "stop").
+func undouble(stem string) string {
+	n := len(stem)
+	if n < 3 || stem[n-1] != stem[n-2] {
+		return ""
+	}
+	return stem[:n-1]
+}
@@ -0,0 +1,141 @@
+package wordfreq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRank(t *testing.T) {
+	rank, ok := Rank("the")
+	assert.True(t, ok)
+	assert.Equal(t, 1, rank)
+
+	_, ok = Rank("ephemeral")
+	assert.False(t, ok)
+
+	assert.Greater(t, Size(), 3000)
+}
+
+func TestRank_InflectedForms(t *testing.T) {
+	tests := []string{"books", "houses", "cities", "walked", "stopped", "making", "running", "quickly", "happily", "bigger"}
+
+	for _, word := range tests {
+		t.Run(word, func(t *testing.T) {
+			assert.True(t, IsCommon(word), "%q should resolve to a common base form", word)
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	t.Run("returns rare words with their sentence", func(t *testing.T) {
+		text := "The garden was quiet. Its beauty felt ephemeral and strangely numinous."
+
+		candidates := Extract(text, Options{MinLength: 6})
+
+		assert.Len(t, candidates, 2)
+		assert.Equal(t, "ephemeral", candidates[0].Word)
+		assert.Equal(t, "Its beauty felt ephemeral and strangely numinous.", candidates[0].Context)
+		assert.Equal(t, "numinous", candidates[1].Word)
+	})
+
+	t.Run("skips proper nouns mid-sentence", func(t *testing.T) {
+		candidates := Extract("We travelled with Gatsby's friends to Westchester.", Options{MinLength: 6})
+		assert.Empty(t, candidates)
+	})
+
+	t.Run("keeps capitalized word at sentence start", func(t *testing.T) {
+		candidates := Extract("Perspicacious readers notice everything.", Options{MinLength: 6})
+		assert.Len(t, candidates, 1)
+		assert.Equal(t, "perspicacious", candidates[0].Word)
+	})
+
+	t.Run("skips short words and duplicates", func(t *testing.T) {
+		candidates := Extract("A zany lagniappe. Another lagniappe!", Options{MinLength: 6})
+		assert.Len(t, candidates, 1)
+		assert.Equal(t, "lagniappe", candidates[0].Word)
+	})
+
+	t.Run("respects max candidates", func(t *testing.T) {
+		text := "Obstreperous, lugubrious and pusillanimous sycophants."
+		candidates := Extract(text, Options{MinLength: 6, MaxCandidates: 2})
+		assert.Len(t, candidates, 2)
+	})
+
+	t.Run("keeps the hardest words in order of appearance", func(t *testing.T) {
+		text := "Lugubrious and pusillanimous sycophants."
+		candidates := Extract(text, Options{MinLength: 6, MaxCandidates: 2})
+		require.Len(t, candidates, 2)
+		assert.Equal(t, "lugubrious", candidates[0].Word)
+		assert.Equal(t, "pusillanimous", candidates[1].Word)
+		assert.Greater(t, candidates[1].Difficulty, candidates[0].Difficulty)
+	})
+
+	t.Run("returns nothing for common text", func(t *testing.T) {
+		assert.Empty(t, Extract("The people were walking through the city together.", DefaultOptions()))
+	})
+}
+
+func TestSentenceContaining(t *testing.T) {
+	text := "The garden was quiet. Its beauty felt Ephemeral and strangely numinous!\nNothing else."
+
+	assert.Equal(t, "Its beauty felt Ephemeral and strangely numinous!", SentenceContaining(text, "ephemeral"))
+	assert.Equal(t, "The garden was quiet.", SentenceContaining(text, "garden was"))
+	assert.Empty(t, SentenceContaining(text, "lagniappe"))
+	assert.Empty(t, SentenceContaining(text, " "))
+}
+
+func TestDifficulty(t *testing.T) {
+	common := Difficulty("the")
+	listed := Difficulty("garden")
+	rare := Difficulty("lagniappe")
+	rarer := Difficulty("pusillanimous")
+
+	assert.Equal(t, 1, common)
+	assert.Greater(t, listed, common)
+	assert.LessOrEqual(t, listed, 80)
+	assert.Equal(t, 88, rare)
+	assert.Greater(t, rarer, rare)
+	assert.Equal(t, rare, Difficulty("The lagniappe's garden"), "a phrase scores as its hardest word")
+	assert.Zero(t, Difficulty("  42 "))
+
+	assert.Equal(t, BandEasy, BandOf(common))
+	assert.Equal(t, BandHard, BandOf(rare))
+	low, high, ok := BandMedium.Range()
+	assert.True(t, ok)
+	assert.Equal(t, BandMedium, BandOf(low))
+	assert.Equal(t, BandMedium, BandOf(high))
+	_, _, ok = Band("impossible").Range()
+	assert.False(t, ok)
+}
+
+func TestLemma(t *testing.T) {
+	tests := map[string]string{
+		"running":      "run",
+		"Run":          "run",
+		"ran":          "run",
+		"cities":       "city",
+		"obfuscated":   "obfuscate",
+		"obfuscating":  "obfuscate",
+		"lugubrious":   "lugubrious",
+		"sycophants":   "sycophant",
+		"equivocated":  "equivocate",
+		"burnished":    "burnish",
+		"glimmering":   "glimmer",
+		"thesis":       "thesis",
+		"red herrings": "red herring",
+		"houses":       "house",
+		"boxes":        "box",
+		"making":       "make",
+		"stopped":      "stop",
+		"player":       "player",
+		"quickly":      "quickly",
+	}
+
+	for word, want := range tests {
+		t.Run(word, func(t *testing.T) {
+			assert.Equal(t, want, Lemma(word))
+		})
+	}
+}
@@ -18,7 +18,14 @@ var (
 func main() {
 	// If no arguments or "serve" command, run the HTTP server
 	if len(os.Args) < 2 || os.Args[1] == "serve" {
-		cfg := config.NewConfig()
+		cfg, err := config.NewConfig()
+		if err == nil {
+			err = cfg.ExportFileSettings()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 		entrypoint.Run(cfg, Version)
 		return
 	}
@@ -71,6 +78,17 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "reconcile":
+		cmd := cli.NewReconcileCommand()
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 	case "applebooks-import":
 		cmd := cli.NewAppleBooksImportCommand()
 		if err := cmd.ParseFlags(args); err != nil {
@@ -93,6 +111,72 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "enrich-metadata":
+		cmd := cli.NewEnrichMetadataCommand()
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "migrate-covers":
+		cmd := cli.NewMigrateCoversCommand()
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "highlights":
+		cmd := cli.NewHighlightsCommand()
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "export-targets":
+		cmd := cli.NewExportTargetsCommand()
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "settings-profile":
+		cmd := cli.NewSettingsProfileCommand()
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "serve-mcp":
+		cmd := cli.NewServeMCPCommand(Version)
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 	case "-h", "--help", "help":
 		printUsage()
 
@@ -111,7 +195,14 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "  moonreader-dropbox  Sync MoonReader highlights from Dropbox\n")
 	fmt.Fprintf(os.Stderr, "  dropbox-auth        Perform Dropbox OAuth flow to get access token\n")
 	fmt.Fprintf(os.Stderr, "  parse-markdown      Parse markdown files recursively from a directory\n")
+	fmt.Fprintf(os.Stderr, "  reconcile           Compare the markdown export with the database and fix differences\n")
 	fmt.Fprintf(os.Stderr, "  applebooks-import   Import highlights from Apple Books (macOS only)\n")
 	fmt.Fprintf(os.Stderr, "  kindle-import       Import highlights from Kindle 'My Clippings.txt'\n")
+	fmt.Fprintf(os.Stderr, "  highlights          Search, sample or export highlights from the database\n")
+	fmt.Fprintf(os.Stderr, "  export-targets      List, add, remove or run named export targets\n")
+	fmt.Fprintf(os.Stderr, "  settings-profile    Export settings as a YAML/JSON profile or apply one\n")
+	fmt.Fprintf(os.Stderr, "  enrich-metadata     Fetch missing covers and book metadata (resumable)\n")
+	fmt.Fprintf(os.Stderr, "  migrate-covers      Move cached covers from the local directory to S3 storage\n")
+	fmt.Fprintf(os.Stderr, "  serve-mcp           Serve highlights to MCP clients (e.g. Claude Desktop) over stdio\n")
 	fmt.Fprintf(os.Stderr, "\nUse '%s <command> -h' for help on a specific command.\n", os.Args[0])
 }
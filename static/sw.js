@@ -0,0 +1,73 @@
+// Service worker for the installable app. The quick-capture page and static
+// assets are cached so highlights can be typed without a connection; the
+// capture page queues them and posts them to the API once back online.
+const CACHE_NAME = 'highlights-v1';
+// URLs are relative to this script, which is served from the app's root, so
+// they keep working when the app is served under a path prefix
+const BASE_PATH = new URL('./', self.location).pathname;
+const PRECACHE_URLS = [
+    'capture',
+    'static/style.css',
+    'static/manifest.json',
+    'static/icons/icon.svg',
+    'https://unpkg.com/htmx.org@2.0.4'
+];
+
+self.addEventListener('install', event => {
+    event.waitUntil(
+        caches.open(CACHE_NAME).then(cache =>
+            // A failed URL (e.g. /capture redirecting to login) must not block installation
+            Promise.all(PRECACHE_URLS.map(url => cache.add(url).catch(() => {})))
+        ).then(() => self.skipWaiting())
+    );
+});
+
+self.addEventListener('activate', event => {
+    event.waitUntil(
+        caches.keys().then(keys =>
+            Promise.all(keys.filter(key => key !== CACHE_NAME).map(key => caches.delete(key)))
+        ).then(() => self.clients.claim())
+    );
+});
+
+self.addEventListener('fetch', event => {
+    const request = event.request;
+    if (request.method !== 'GET') {
+        return;
+    }
+    const url = new URL(request.url);
+
+    // Pages: network first so books and tokens stay fresh, cached copy when offline
+    if (request.mode === 'navigate') {
+        event.respondWith(
+            fetch(request).then(response => {
+                if (response.ok && !response.redirected && url.pathname === BASE_PATH + 'capture') {
+                    const copy = response.clone();
+                    caches.open(CACHE_NAME).then(cache => cache.put('capture', copy));
+                }
+                return response;
+            }).catch(() =>
+                caches.match(request, { ignoreSearch: true }).then(cached => cached || caches.match('capture'))
+            )
+        );
+        return;
+    }
+
+    // Static assets: serve from cache, refresh in the background. Pages link to
+    // fingerprinted URLs (?v=...), so an older copy is used while offline.
+    if (url.pathname.startsWith(BASE_PATH + 'static/') || url.origin === 'https://unpkg.com') {
+        event.respondWith(
+            caches.open(CACHE_NAME).then(cache =>
+                cache.match(request, { ignoreSearch: true }).then(cached => {
+                    const network = fetch(request).then(response => {
+                        if (response.ok) {
+                            cache.put(request, response.clone());
+                        }
+                        return response;
+                    }).catch(() => cached);
+                    return cached || network;
+                })
+            )
+        );
+    }
+});